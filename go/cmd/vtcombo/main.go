@@ -26,6 +26,7 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"vitess.io/vitess/go/vt/env"
@@ -63,6 +64,8 @@ var (
 		"If true, vtcombo will use the flags defined in topo/server.go to open topo server")
 	plannerVersion           = flag.String("planner-version", "", "Sets the default planner to use when the session has not changed it. Valid values are: V3, Gen4, Gen4Greedy and Gen4Fallback. Gen4Fallback tries the gen4 planner and falls back to the V3 planner if the gen4 fails.")
 	plannerVersionDeprecated = flag.String("planner_version", "", "Deprecated flag. Use planner-version instead")
+	persistentDataDir        = flag.String("persistent_data_dir", "", "Directory where vtcombo saves its keyspaces, vschemas and routing rules, so that it restarts with them. "+
+		"When it contains a saved topology, it is used instead of the one given by --proto_topo or --json_topo. With --start_mysql, the MySQL files are kept in this directory too.")
 
 	tpb             vttestpb.VTTestTopology
 	ts              *topo.Server
@@ -128,6 +131,19 @@ func main() {
 	// get recreated.
 	originalTopology := proto.Clone(&tpb).(*vttestpb.VTTestTopology)
 
+	// Restart with the topology vtcombo had when it stopped, if it was saved.
+	restored := false
+	if *persistentDataDir != "" {
+		var err error
+		if restored, err = vtcombo.LoadPersistentTopology(*persistentDataDir, &tpb); err != nil {
+			log.Errorf("Failed to load the persistent topology: %v", err)
+			exit.Return(1)
+		}
+		if *startMysql {
+			os.Setenv("VTDATAROOT", *persistentDataDir)
+		}
+	}
+
 	// default cell to "test" if unspecified
 	if len(tpb.Cells) == 0 {
 		tpb.Cells = append(tpb.Cells, "test")
@@ -187,8 +203,39 @@ func main() {
 		}
 		exit.Return(1)
 	}
+	if restored {
+		if err := vtcombo.RestorePersistentVSchemas(context.Background(), ts, *persistentDataDir, tpb.Cells); err != nil {
+			log.Errorf("Failed to restore the persistent vschemas: %v", err)
+			if *startMysql {
+				mysqld.Shutdown(context.TODO(), cnf, true)
+			}
+			exit.Return(1)
+		}
+	}
+
+	// stateMu protects the topology and the next tablet uid, which change
+	// when keyspaces are created, dropped or resharded.
+	var stateMu sync.Mutex
+	// saveState saves the topology in the persistent data directory, if
+	// any. stateMu must be held.
+	saveState := func() {
+		if *persistentDataDir == "" {
+			return
+		}
+		if err := vtcombo.SavePersistentState(context.Background(), ts, &tpb, *persistentDataDir); err != nil {
+			log.Errorf("Failed to save the persistent topology: %v", err)
+		}
+	}
+	lockAndSaveState := func() {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		saveState()
+	}
 
 	globalCreateDb = func(ctx context.Context, ks *vttestpb.Keyspace) error {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+
 		// Check if we're recreating a keyspace that was previously deleted by looking
 		// at the original topology definition.
 		//
@@ -208,10 +255,14 @@ func main() {
 		}
 		uid = newUID
 		tpb.Keyspaces = append(tpb.Keyspaces, ks)
+		saveState()
 		return nil
 	}
 
 	globalDropDb = func(ctx context.Context, ksName string) error {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+
 		if err := vtcombo.DeleteKs(ctx, ts, ksName, mysqld, &tpb); err != nil {
 			return err
 		}
@@ -221,9 +272,36 @@ func main() {
 			return err
 		}
 
+		saveState()
 		return nil
 	}
 
+	globalTopoServer = ts
+	globalReshard = func(ctx context.Context, keyspace string, shards []string) error {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+
+		newUID, err := vtcombo.Reshard(ctx, ts, &tpb, mysqld, &dbconfigs.GlobalDBConfigs, keyspace, shards, uid)
+		if err != nil {
+			return err
+		}
+		uid = newUID
+		saveState()
+		return nil
+	}
+
+	// Save the topology now, when the vschemas or routing rules change, and
+	// on shutdown.
+	if *persistentDataDir != "" {
+		lockAndSaveState()
+		watchCtx, cancelWatch := context.WithCancel(context.Background())
+		go vtcombo.WatchPersistentState(watchCtx, ts, tpb.Cells[0], lockAndSaveState)
+		servenv.OnTerm(func() {
+			cancelWatch()
+			lockAndSaveState()
+		})
+	}
+
 	// Now that we have fully initialized the tablets, rebuild the keyspace graph.
 	for _, ks := range tpb.Keyspaces {
 		err := topotools.RebuildKeyspace(context.Background(), logutil.NewConsoleLogger(), ts, ks.GetName(), tpb.Cells, false)
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtcombo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file implements the HTTP API that lets the tests of an application
+// fail tablets, reparent shards and reshard keyspaces while they run:
+//
+//	POST /vttest/tablet/fail?alias=test-0000000001
+//	POST /vttest/tablet/recover?alias=test-0000000001
+//	POST /vttest/reparent?keyspace=ks&shard=-80[&new_primary=test-0000000002]
+//	POST /vttest/reshard?keyspace=ks&shards=-40,40-80,80-
+const simulationPrefix = "/vttest/"
+
+var (
+	// simulationMu serializes the simulated events, as they change the
+	// tablets of the cluster.
+	simulationMu sync.Mutex
+
+	globalTopoServer *topo.Server
+	globalReshard    func(ctx context.Context, keyspace string, shards []string) error
+)
+
+// simulationError is returned for invalid requests.
+type simulationError struct {
+	msg string
+}
+
+func (e simulationError) Error() string {
+	return e.msg
+}
+
+func handleSimulation(action string, handlerFunc func(r *http.Request) error) {
+	http.HandleFunc(simulationPrefix+action, func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		simulationMu.Lock()
+		err := handlerFunc(r)
+		simulationMu.Unlock()
+		if err != nil {
+			log.Errorf("simulation %v failed: %v", action, err)
+			status := http.StatusInternalServerError
+			if _, ok := err.(simulationError); ok {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Write([]byte("OK\n"))
+	})
+}
+
+// requiredParam returns the value of a form parameter, which must be set.
+func requiredParam(r *http.Request, name string) (string, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return "", simulationError{fmt.Sprintf("missing %v parameter", name)}
+	}
+	return value, nil
+}
+
+func tabletAliasParam(r *http.Request, name string) (*topodatapb.TabletAlias, error) {
+	value, err := requiredParam(r, name)
+	if err != nil {
+		return nil, err
+	}
+	alias, err := topoproto.ParseTabletAlias(value)
+	if err != nil {
+		return nil, simulationError{err.Error()}
+	}
+	return alias, nil
+}
+
+func initSimulation() {
+	handleSimulation("tablet/fail", func(r *http.Request) error {
+		alias, err := tabletAliasParam(r, "alias")
+		if err != nil {
+			return err
+		}
+		return vtcombo.FailTablet(alias)
+	})
+
+	handleSimulation("tablet/recover", func(r *http.Request) error {
+		alias, err := tabletAliasParam(r, "alias")
+		if err != nil {
+			return err
+		}
+		return vtcombo.RecoverTablet(alias)
+	})
+
+	handleSimulation("reparent", func(r *http.Request) error {
+		keyspace, err := requiredParam(r, "keyspace")
+		if err != nil {
+			return err
+		}
+		shard, err := requiredParam(r, "shard")
+		if err != nil {
+			return err
+		}
+		var newPrimary *topodatapb.TabletAlias
+		if r.FormValue("new_primary") != "" {
+			if newPrimary, err = tabletAliasParam(r, "new_primary"); err != nil {
+				return err
+			}
+		}
+		return vtcombo.ReparentShard(r.Context(), globalTopoServer, keyspace, shard, newPrimary)
+	})
+
+	handleSimulation("reshard", func(r *http.Request) error {
+		keyspace, err := requiredParam(r, "keyspace")
+		if err != nil {
+			return err
+		}
+		shards, err := requiredParam(r, "shards")
+		if err != nil {
+			return err
+		}
+		return globalReshard(r.Context(), keyspace, strings.Split(shards, ","))
+	})
+}
+
+func init() {
+	servenv.OnRun(initSimulation)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"flag"
@@ -25,6 +26,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/shlex"
+
 	"vitess.io/vitess/go/exit"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/log"
@@ -40,8 +43,10 @@ import (
 // The default values used by these flags cannot be taken from wrangler and
 // actionnode modules, as we don't want to depend on them at all.
 var (
-	actionTimeout = flag.Duration("action_timeout", time.Hour, "timeout for the total command")
-	server        = flag.String("server", "", "server to use for connection")
+	actionTimeout   = flag.Duration("action_timeout", time.Hour, "timeout for the total command")
+	server          = flag.String("server", "", "server to use for connection")
+	commandFile     = flag.String("command-file", "", "file of commands to execute sequentially on the same connection instead of the command of the arguments, one per line with its arguments quoted like in a shell, and # comments. --action_timeout applies to each command")
+	continueOnError = flag.Bool("continue-on-error", false, "with --command-file, execute the next commands when one fails instead of stopping")
 )
 
 // checkDeprecations runs quick and dirty checks to see whether any command or flag are deprecated.
@@ -83,6 +88,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *commandFile != "" {
+		if err := runCommandFile(logger, *commandFile); err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), *actionTimeout)
 	defer cancel()
 
@@ -104,3 +117,70 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// readCommandFile returns the commands of a command file, with their
+// arguments, and the numbers of their lines.
+func readCommandFile(name string) (commands [][]string, lines []int, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		args, err := shlex.Split(scanner.Text())
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		commands = append(commands, args)
+		lines = append(lines, line)
+	}
+	return commands, lines, scanner.Err()
+}
+
+// runCommandFile executes the commands of a command file sequentially on
+// the same connection, and reports the status of each of them on stderr. It
+// stops at the first command that fails, unless --continue-on-error is set,
+// and returns an error if any command failed.
+func runCommandFile(logger logutil.Logger, name string) error {
+	commands, lines, err := readCommandFile(name)
+	if err != nil {
+		return err
+	}
+
+	conn, err := vtctlclient.Dial(*server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	failed := 0
+	for i, args := range commands {
+		checkDeprecations(args)
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), *actionTimeout)
+		err := conn.RunCommandAndWait(ctx, args, func(e *logutilpb.Event) {
+			logutil.LogEvent(logger, e)
+		})
+		cancel()
+
+		status := "OK"
+		if err != nil {
+			failed++
+			status = "Error: " + strings.Replace(err.Error(), "remote error: ", "", -1)
+		}
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s:%d %s %s (%v)\n", i+1, len(commands), name, lines[i], args[0], status, time.Since(start).Round(time.Millisecond))
+		if err != nil && !*continueOnError {
+			return fmt.Errorf("%s:%d: %s failed, %d command(s) not executed", name, lines[i], args[0], len(commands)-i-1)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands of %s failed", failed, len(commands), name)
+	}
+	return nil
+}
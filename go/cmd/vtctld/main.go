@@ -17,9 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
+
 	"vitess.io/vitess/go/exit"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtctld"
 )
 
@@ -49,6 +52,11 @@ func main() {
 	// Register http debug/health
 	vtctld.RegisterDebugHealthHandler(ts)
 
+	// Record the version of vtctld in the topo for the version skew checks.
+	servenv.OnRun(func() {
+		servenv.OnTermSync(topotools.RegisterComponentVersion(context.Background(), ts, topotools.ComponentVtctld, servenv.ListeningURL.Host, ""))
+	})
+
 	// Start schema manager service.
 	initSchema()
 
@@ -0,0 +1,34 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseKeyspaceName splits a positional argument of the form
+// <keyspace.name> into the keyspace and the name of an object of that
+// keyspace. what names the object in the error message.
+func ParseKeyspaceName(arg string, what string) (keyspace string, name string, err error) {
+	parts := strings.Split(arg, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid format for <keyspace.%s>: %s", what, arg)
+	}
+
+	return parts[0], parts[1], nil
+}
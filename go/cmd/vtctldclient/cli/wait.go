@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// WaitPollInterval is the interval between two polls of the status of an
+// asynchronous operation by Wait.
+var WaitPollInterval = 2 * time.Second
+
+// WaitOptions holds the --wait and --wait-timeout flags of the commands that
+// start an asynchronous operation, which return once the operation started
+// by default.
+type WaitOptions struct {
+	// Wait makes the command return only once the operation is done.
+	Wait bool
+	// Timeout is the maximum time to wait for the operation.
+	Timeout time.Duration
+}
+
+// AddWaitFlags adds the --wait and --wait-timeout flags to a command. what
+// describes the operation the command waits for, e.g. "the backup is
+// complete".
+func AddWaitFlags(cmd *cobra.Command, opts *WaitOptions, what string) {
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false, fmt.Sprintf("Returns only once %s, polling its status and writing its progress to stderr.", what))
+	cmd.Flags().DurationVar(&opts.Timeout, "wait-timeout", time.Hour, "The maximum time to wait with --wait. The command fails if the operation is not done by then, but the operation keeps going.")
+}
+
+// Wait polls the status of an asynchronous operation until poll reports
+// that it is done, or fails. Each progress returned by poll that differs
+// from the previous one is written to w.
+func Wait(ctx context.Context, opts WaitOptions, w io.Writer, poll func(ctx context.Context) (done bool, progress string, err error)) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(WaitPollInterval)
+	defer ticker.Stop()
+
+	var last string
+	timedOut := func() error {
+		if last != "" {
+			return fmt.Errorf("timed out waiting after %v, the last status was: %s", opts.Timeout, last)
+		}
+		return fmt.Errorf("timed out waiting after %v", opts.Timeout)
+	}
+	for {
+		done, progress, err := poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return timedOut()
+			}
+			return err
+		}
+		if progress != "" && progress != last {
+			fmt.Fprintln(w, progress)
+			last = progress
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return timedOut()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// CreateArchival creates an archival that vtctld runs.
+	CreateArchival = &cobra.Command{
+		Use:   "CreateArchival --table <table> --where <predicate> [--target-keyspace <keyspace>] [--target-table <table>] [--target-dir <dir>] [--batch-size <rows>] [--max-tps <batches>] [--paused] <keyspace.archival>",
+		Short: "Creates an archival, which moves the rows of a table that match a predicate to an archive table or to the backup storage.",
+		Long: `Creates an archival, which moves the rows of a table that match a predicate to an archive table or to the backup storage.
+
+Every --archival_check_interval, vtctld runs the archivals that are not paused,
+until no row of their table matches their predicate. The rows are selected on
+the shard primaries in the order of the primary key, --batch-size rows at a
+time. Each batch is copied to --target-table, which must have the columns and
+the primary key of the table, or written as a CSV file to --target-dir of the
+backup storage of vtctld, which ImportData can load. The copy is verified, and
+only then are the rows deleted from the table.
+
+The predicate is evaluated by MySQL, e.g. 'created_at < now() - interval 90 day'.
+Use GetArchivals to follow the progress, and PauseArchival and ResumeArchival to
+stop and restart an archival.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandCreateArchival,
+	}
+	// GetArchivals shows the archivals of a keyspace.
+	GetArchivals = &cobra.Command{
+		Use:                   "GetArchivals <keyspace>",
+		Short:                 "Returns the archivals of the keyspace, with their progress.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetArchivals,
+	}
+	// PauseArchival pauses an archival.
+	PauseArchival = &cobra.Command{
+		Use:                   "PauseArchival <keyspace.archival>",
+		Short:                 "Pauses an archival. vtctld stops it after the batch it is archiving.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandPauseArchival,
+	}
+	// ResumeArchival resumes a paused archival.
+	ResumeArchival = &cobra.Command{
+		Use:                   "ResumeArchival <keyspace.archival>",
+		Short:                 "Resumes a paused archival at the next check of vtctld.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandResumeArchival,
+	}
+	// DeleteArchival deletes an archival.
+	DeleteArchival = &cobra.Command{
+		Use:                   "DeleteArchival <keyspace.archival>",
+		Short:                 "Deletes an archival. The rows it archived are kept.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDeleteArchival,
+	}
+)
+
+var createArchivalOptions = struct {
+	Table          string
+	Where          string
+	TargetKeyspace string
+	TargetTable    string
+	TargetDir      string
+	BatchSize      int
+	MaxTPS         int64
+	Paused         bool
+}{}
+
+func commandCreateArchival(cmd *cobra.Command, args []string) error {
+	keyspace, name, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "archival")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.CreateArchival(commandCtx, &vtctldatapb.CreateArchivalRequest{
+		Keyspace: keyspace,
+		Archival: &vtctldatapb.Archival{
+			Name:           name,
+			Table:          createArchivalOptions.Table,
+			Where:          createArchivalOptions.Where,
+			TargetKeyspace: createArchivalOptions.TargetKeyspace,
+			TargetTable:    createArchivalOptions.TargetTable,
+			TargetDir:      createArchivalOptions.TargetDir,
+			BatchSize:      int64(createArchivalOptions.BatchSize),
+			MaxTps:         createArchivalOptions.MaxTPS,
+			Paused:         createArchivalOptions.Paused,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Archival)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandGetArchivals(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetArchivals(commandCtx, &vtctldatapb.GetArchivalsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Archivals)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandPauseArchival(cmd *cobra.Command, args []string) error {
+	return setArchivalPaused(cmd, true)
+}
+
+func commandResumeArchival(cmd *cobra.Command, args []string) error {
+	return setArchivalPaused(cmd, false)
+}
+
+func setArchivalPaused(cmd *cobra.Command, paused bool) error {
+	keyspace, name, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "archival")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetArchivalPaused(commandCtx, &vtctldatapb.SetArchivalPausedRequest{
+		Keyspace: keyspace,
+		Name:     name,
+		Paused:   paused,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Archival)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandDeleteArchival(cmd *cobra.Command, args []string) error {
+	keyspace, name, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "archival")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	_, err = client.DeleteArchival(commandCtx, &vtctldatapb.DeleteArchivalRequest{
+		Keyspace: keyspace,
+		Name:     name,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted archival %s\n", cmd.Flags().Arg(0))
+
+	return nil
+}
+
+func init() {
+	CreateArchival.Flags().StringVar(&createArchivalOptions.Table, "table", "", "The table whose rows are archived.")
+	CreateArchival.Flags().StringVar(&createArchivalOptions.Where, "where", "", "The predicate of the rows to archive, as a SQL expression evaluated by MySQL.")
+	CreateArchival.Flags().StringVar(&createArchivalOptions.TargetKeyspace, "target-keyspace", "", "The keyspace of the target table. Defaults to the keyspace of the archival.")
+	CreateArchival.Flags().StringVar(&createArchivalOptions.TargetTable, "target-table", "", "The table the rows are copied to.")
+	CreateArchival.Flags().StringVar(&createArchivalOptions.TargetDir, "target-dir", "", "The directory of the backup storage the rows are written to as CSV files, instead of a target table.")
+	CreateArchival.Flags().IntVar(&createArchivalOptions.BatchSize, "batch-size", 1000, "The number of rows archived at a time from each shard.")
+	CreateArchival.Flags().Int64Var(&createArchivalOptions.MaxTPS, "max-tps", 0, "The maximum number of batches archived per second. 0 does not throttle the batches.")
+	CreateArchival.Flags().BoolVar(&createArchivalOptions.Paused, "paused", false, "Creates the archival paused.")
+	CreateArchival.MarkFlagRequired("table")
+	CreateArchival.MarkFlagRequired("where")
+	Root.AddCommand(CreateArchival)
+
+	Root.AddCommand(GetArchivals)
+	Root.AddCommand(PauseArchival)
+	Root.AddCommand(ResumeArchival)
+	Root.AddCommand(DeleteArchival)
+}
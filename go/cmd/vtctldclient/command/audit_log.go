@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// GetAuditLog makes a GetAuditLog gRPC call to a vtctld.
+var GetAuditLog = &cobra.Command{
+	Use:   "GetAuditLog [--limit <n>] [--command <command>]",
+	Short: "Lists the most recent commands recorded in the audit log of vtctld, latest first.",
+	Long: `Lists the most recent commands recorded in the audit log of vtctld, latest first.
+
+Each entry holds the command, its arguments, or its request in JSON, its caller
+and remote address, its start and end time, and its result. The commands of
+the legacy vtctl client are recorded as vtctl.<command>. Only the commands that
+change the cluster are recorded, unless vtctld runs with
+--audit_log_read_only_commands, and the SQL literals and passwords of their
+arguments are redacted.
+
+The entries are read from the topo sink of the audit log if vtctld has one, see
+--audit_log_sinks, so that they include the commands received by all the
+vtctlds of the cluster. Otherwise, they are the ones kept in memory by the
+vtctld server.`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	RunE:                  commandGetAuditLog,
+}
+
+var getAuditLogOptions = struct {
+	Limit   uint32
+	Command string
+}{}
+
+func commandGetAuditLog(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetAuditLog(commandCtx, &vtctldatapb.GetAuditLogRequest{
+		Limit:   getAuditLogOptions.Limit,
+		Command: getAuditLogOptions.Command,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	GetAuditLog.Flags().Uint32Var(&getAuditLogOptions.Limit, "limit", 100, "The maximum number of entries to return.")
+	GetAuditLog.Flags().StringVar(&getAuditLogOptions.Command, "command", "", "Only return the entries of this command, e.g. PlannedReparentShard or vtctl.Reshard.")
+	Root.AddCommand(GetAuditLog)
+}
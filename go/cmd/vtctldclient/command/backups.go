@@ -17,8 +17,11 @@ limitations under the License.
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -29,13 +32,14 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
+	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 var (
 	// Backup makes a Backup gRPC call to a vtctld.
 	Backup = &cobra.Command{
-		Use:                   "Backup [--concurrency <concurrency>] [--allow-primary] <tablet_alias>",
+		Use:                   "Backup [--concurrency <concurrency>] [--allow-primary] [--detach [--wait] [--wait-timeout <duration>]] <tablet_alias>",
 		Short:                 "Uses the BackupStorage service on the given tablet to create and store a new backup.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
@@ -43,18 +47,21 @@ var (
 	}
 	// BackupShard makes a BackupShard gRPC call to a vtctld.
 	BackupShard = &cobra.Command{
-		Use:   "BackupShard [--concurrency <concurrency>] [--allow-primary] <keyspace/shard>",
+		Use:   "BackupShard [--concurrency <concurrency>] [--allow-primary] [--detach [--wait] [--wait-timeout <duration>]] <keyspace/shard>",
 		Short: "Finds the most up-to-date REPLICA, RDONLY, or SPARE tablet in the given shard and uses the BackupStorage service on that tablet to create and store a new backup.",
 		Long: `Finds the most up-to-date REPLICA, RDONLY, or SPARE tablet in the given shard and uses the BackupStorage service on that tablet to create and store a new backup.
 
-If no replica-type tablet can be found, the backup can be taken on the primary if --allow-primary is specified.`,
+If no replica-type tablet can be found, the backup can be taken on the primary if --allow-primary is specified.
+
+With --detach, the command returns once the backup has started, and the backup keeps running in vtctld. With --wait, it
+then polls the backups of the shard until the new backup is complete.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandBackupShard,
 	}
 	// GetBackups makes a GetBackups gRPC call to a vtctld.
 	GetBackups = &cobra.Command{
-		Use:                   "GetBackups [--limit <limit>] [--json] <keyspace/shard>",
+		Use:                   "GetBackups [--limit <limit>] [--detailed [--detailed-limit <limit>]] [--json] <keyspace/shard>",
 		Short:                 "Lists backups for the given shard.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
@@ -81,6 +88,8 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 var backupOptions = struct {
 	AllowPrimary bool
 	Concurrency  uint64
+	Detach       bool
+	cli.WaitOptions
 }{}
 
 func commandBackup(cmd *cobra.Command, args []string) error {
@@ -88,34 +97,45 @@ func commandBackup(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if backupOptions.Wait && !backupOptions.Detach {
+		return errors.New("--wait requires --detach, a backup that is not detached is always waited for")
+	}
 
 	cli.FinishedParsing(cmd)
 
+	var previous map[string]bool
+	if backupOptions.Wait {
+		resp, err := client.GetTablet(commandCtx, &vtctldatapb.GetTabletRequest{TabletAlias: tabletAlias})
+		if err != nil {
+			return err
+		}
+		if previous, err = backupNames(resp.Tablet.Keyspace, resp.Tablet.Shard); err != nil {
+			return err
+		}
+	}
+
 	stream, err := client.Backup(commandCtx, &vtctldatapb.BackupRequest{
 		TabletAlias:  tabletAlias,
 		AllowPrimary: backupOptions.AllowPrimary,
 		Concurrency:  backupOptions.Concurrency,
+		Detach:       backupOptions.Detach,
 	})
 	if err != nil {
 		return err
 	}
 
-	for {
-		resp, err := stream.Recv()
-		switch err {
-		case nil:
-			fmt.Printf("%s/%s (%s): %v\n", resp.Keyspace, resp.Shard, topoproto.TabletAliasString(resp.TabletAlias), resp.Event)
-		case io.EOF:
-			return nil
-		default:
-			return err
-		}
+	last, err := printBackupEvents(stream)
+	if err != nil || !backupOptions.Wait {
+		return err
 	}
+	return waitForBackup(backupOptions.WaitOptions, last, previous)
 }
 
 var backupShardOptions = struct {
 	AllowPrimary bool
 	Concurrency  uint64
+	Detach       bool
+	cli.WaitOptions
 }{}
 
 func commandBackupShard(cmd *cobra.Command, args []string) error {
@@ -123,35 +143,124 @@ func commandBackupShard(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if backupShardOptions.Wait && !backupShardOptions.Detach {
+		return errors.New("--wait requires --detach, a backup that is not detached is always waited for")
+	}
 
 	cli.FinishedParsing(cmd)
 
+	var previous map[string]bool
+	if backupShardOptions.Wait {
+		if previous, err = backupNames(keyspace, shard); err != nil {
+			return err
+		}
+	}
+
 	stream, err := client.BackupShard(commandCtx, &vtctldatapb.BackupShardRequest{
 		Keyspace:     keyspace,
 		Shard:        shard,
 		AllowPrimary: backupOptions.AllowPrimary,
 		Concurrency:  backupOptions.Concurrency,
+		Detach:       backupShardOptions.Detach,
 	})
 	if err != nil {
 		return err
 	}
 
+	last, err := printBackupEvents(stream)
+	if err != nil || !backupShardOptions.Wait {
+		return err
+	}
+	return waitForBackup(backupShardOptions.WaitOptions, last, previous)
+}
+
+// printBackupEvents prints the events of a backup stream, and returns the
+// last one.
+func printBackupEvents(stream interface {
+	Recv() (*vtctldatapb.BackupResponse, error)
+}) (*vtctldatapb.BackupResponse, error) {
+	var last *vtctldatapb.BackupResponse
 	for {
 		resp, err := stream.Recv()
 		switch err {
 		case nil:
 			fmt.Printf("%s/%s (%s): %v\n", resp.Keyspace, resp.Shard, topoproto.TabletAliasString(resp.TabletAlias), resp.Event)
+			last = resp
 		case io.EOF:
-			return nil
+			if last == nil {
+				return nil, errors.New("the backup stream ended without any event")
+			}
+			return last, nil
 		default:
-			return err
+			return nil, err
 		}
 	}
 }
 
+// backupNames returns the names of the backups of a shard.
+func backupNames(keyspace, shard string) (map[string]bool, error) {
+	resp, err := client.GetBackups(commandCtx, &vtctldatapb.GetBackupsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(resp.Backups))
+	for _, backup := range resp.Backups {
+		names[backup.Name] = true
+	}
+	return names, nil
+}
+
+// waitForBackup waits for the detached backup that was started, which is
+// the first backup of its tablet that is not among the previous backups of
+// its shard, to be complete.
+func waitForBackup(opts cli.WaitOptions, started *vtctldatapb.BackupResponse, previous map[string]bool) error {
+	alias := topoproto.TabletAliasString(started.TabletAlias)
+	var name string
+	return cli.Wait(commandCtx, opts, os.Stderr, func(ctx context.Context) (bool, string, error) {
+		resp, err := client.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
+			Keyspace: started.Keyspace,
+			Shard:    started.Shard,
+			Detailed: true,
+			// The backup is among the most recent ones, so only their
+			// MANIFEST files are read.
+			DetailedLimit: 10,
+		})
+		if err != nil {
+			return false, "", err
+		}
+		for _, backup := range resp.Backups {
+			if previous[backup.Name] || topoproto.TabletAliasString(backup.TabletAlias) != alias {
+				continue
+			}
+			if name == "" {
+				name = backup.Name
+			}
+			if backup.Name != name {
+				continue
+			}
+			switch backup.Status {
+			case mysqlctlpb.BackupInfo_COMPLETE, mysqlctlpb.BackupInfo_VALID:
+				return true, fmt.Sprintf("backup %s is complete", name), nil
+			case mysqlctlpb.BackupInfo_INVALID:
+				return false, "", fmt.Errorf("backup %s is invalid", name)
+			}
+			return false, fmt.Sprintf("backup %s is in progress", name), nil
+		}
+		if name != "" {
+			return false, "", fmt.Errorf("backup %s was removed before it was complete, see the logs of vtctld and of tablet %s for its error", name, alias)
+		}
+		return false, fmt.Sprintf("waiting for the backup of tablet %s to start", alias), nil
+	})
+}
+
 var getBackupsOptions = struct {
-	Limit      uint32
-	OutputJSON bool
+	Limit         uint32
+	Detailed      bool
+	DetailedLimit uint32
+	OutputJSON    bool
 }{}
 
 func commandGetBackups(cmd *cobra.Command, args []string) error {
@@ -163,15 +272,17 @@ func commandGetBackups(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	resp, err := client.GetBackups(commandCtx, &vtctldatapb.GetBackupsRequest{
-		Keyspace: keyspace,
-		Shard:    shard,
-		Limit:    getBackupsOptions.Limit,
+		Keyspace:      keyspace,
+		Shard:         shard,
+		Limit:         getBackupsOptions.Limit,
+		Detailed:      getBackupsOptions.Detailed,
+		DetailedLimit: getBackupsOptions.DetailedLimit,
 	})
 	if err != nil {
 		return err
 	}
 
-	if getBackupsOptions.OutputJSON {
+	if getBackupsOptions.OutputJSON || getBackupsOptions.Detailed {
 		data, err := cli.MarshalJSON(resp)
 		if err != nil {
 			return err
@@ -255,13 +366,19 @@ func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
 func init() {
 	Backup.Flags().BoolVar(&backupOptions.AllowPrimary, "allow-primary", false, "Allow the primary of a shard to be used for the backup. WARNING: If using the builtin backup engine, this will shutdown mysqld on the primary and stop writes for the duration of the backup.")
 	Backup.Flags().Uint64Var(&backupOptions.Concurrency, "concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously.")
+	Backup.Flags().BoolVar(&backupOptions.Detach, "detach", false, "Returns once the backup has started, instead of streaming its events until it is done. The backup keeps running in vtctld.")
+	cli.AddWaitFlags(Backup, &backupOptions.WaitOptions, "the detached backup is complete")
 	Root.AddCommand(Backup)
 
 	BackupShard.Flags().BoolVar(&backupShardOptions.AllowPrimary, "allow-primary", false, "Allow the primary of a shard to be used for the backup. WARNING: If using the builtin backup engine, this will shutdown mysqld on the primary and stop writes for the duration of the backup.")
 	BackupShard.Flags().Uint64Var(&backupShardOptions.Concurrency, "concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously.")
+	BackupShard.Flags().BoolVar(&backupShardOptions.Detach, "detach", false, "Returns once the backup has started, instead of streaming its events until it is done. The backup keeps running in vtctld.")
+	cli.AddWaitFlags(BackupShard, &backupShardOptions.WaitOptions, "the detached backup is complete")
 	Root.AddCommand(BackupShard)
 
 	GetBackups.Flags().Uint32VarP(&getBackupsOptions.Limit, "limit", "l", 0, "Retrieve only the most recent N backups.")
+	GetBackups.Flags().BoolVar(&getBackupsOptions.Detailed, "detailed", false, "Reads the manifest of the backups to report their status and engine, in JSON format.")
+	GetBackups.Flags().Uint32Var(&getBackupsOptions.DetailedLimit, "detailed-limit", 0, "With --detailed, only reports the status and engine of the most recent N backups.")
 	GetBackups.Flags().BoolVarP(&getBackupsOptions.OutputJSON, "json", "j", false, "Output backup info in JSON format rather than a list of backups.")
 	Root.AddCommand(GetBackups)
 
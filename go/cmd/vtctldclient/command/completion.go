@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// Completion generates the shell completion scripts.
+var Completion = &cobra.Command{
+	Use:   "completion <bash|zsh|fish>",
+	Short: "Outputs the shell completion script of vtctldclient for bash, zsh or fish.",
+	Long: `Outputs the shell completion script of vtctldclient for bash, zsh or fish.
+
+Besides the commands and their flags, the keyspaces, the shards and the tablet
+aliases given as arguments are completed with the ones of the vtctld server of
+the --server flag of the command line being completed.
+
+To load the completions in the current bash session:
+
+	source <(vtctldclient completion bash)
+
+To load them in every zsh session, add the script to a directory of $fpath:
+
+	vtctldclient completion zsh > "${fpath[1]}/_vtctldclient"
+
+To load them in every fish session:
+
+	vtctldclient completion fish > ~/.config/fish/completions/vtctldclient.fish`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish"},
+	Args:                  cobra.ExactValidArgs(1),
+	// The completion scripts are generated locally, without a vtctld server.
+	PersistentPreRunE:  func(cmd *cobra.Command, args []string) error { return nil },
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:               commandCompletion,
+}
+
+func commandCompletion(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	switch cmd.Flags().Arg(0) {
+	case "bash":
+		return Root.GenBashCompletion(os.Stdout)
+	case "zsh":
+		return Root.GenZshCompletion(os.Stdout)
+	case "fish":
+		return Root.GenFishCompletion(os.Stdout, true)
+	}
+	return fmt.Errorf("unsupported shell: %s", cmd.Flags().Arg(0))
+}
+
+// completionTimeout bounds the calls to vtctld made to complete an argument,
+// so that an unreachable server does not hang the shell.
+const completionTimeout = 5 * time.Second
+
+// completer returns the values an argument can take that start with
+// toComplete, fetched from vtctld.
+type completer func(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, cobra.ShellCompDirective, error)
+
+// completeArgs completes the positional arguments of a command, the i-th
+// argument with the i-th completer. A nil completer does not complete its
+// argument. If variadic is set, the last completer also completes all the
+// arguments after it.
+func completeArgs(variadic bool, completers ...completer) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		i := len(args)
+		if i >= len(completers) {
+			if !variadic {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			i = len(completers) - 1
+		}
+		if completers[i] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return runCompleter(completers[i], toComplete)
+	}
+}
+
+func runCompleter(complete completer, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if server == "" && VtctldClientProtocol != "local" {
+		cobra.CompDebugln("cannot complete without --server", false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	client, err := newClient()
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	values, directive, err := complete(ctx, client, toComplete)
+	if err != nil {
+		cobra.CompErrorln(err.Error())
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return values, directive
+}
+
+func completeKeyspaces(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, cobra.ShellCompDirective, error) {
+	keyspaces, err := keyspaceNames(ctx, client, toComplete)
+	return keyspaces, cobra.ShellCompDirectiveNoFileComp, err
+}
+
+// completeShards completes <keyspace/shard> arguments: the keyspaces, and
+// then the shards of the keyspace once it is followed by a slash.
+func completeShards(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, cobra.ShellCompDirective, error) {
+	keyspace, _, found := strings.Cut(toComplete, "/")
+	if !found {
+		keyspaces, err := keyspaceNames(ctx, client, toComplete)
+		for i := range keyspaces {
+			keyspaces[i] += "/"
+		}
+		return keyspaces, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace, err
+	}
+
+	resp, err := client.FindAllShardsInKeyspace(ctx, &vtctldatapb.FindAllShardsInKeyspaceRequest{Keyspace: keyspace})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp, err
+	}
+	var shards []string
+	for name := range resp.Shards {
+		shards = append(shards, keyspace+"/"+name)
+	}
+	return filterCompletions(shards, toComplete), cobra.ShellCompDirectiveNoFileComp, nil
+}
+
+func completeTabletAliases(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, cobra.ShellCompDirective, error) {
+	resp, err := client.GetTablets(ctx, &vtctldatapb.GetTabletsRequest{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp, err
+	}
+	aliases := make([]string, 0, len(resp.Tablets))
+	for _, tablet := range resp.Tablets {
+		aliases = append(aliases, topoproto.TabletAliasString(tablet.Alias))
+	}
+	return filterCompletions(aliases, toComplete), cobra.ShellCompDirectiveNoFileComp, nil
+}
+
+func completeFeatureGates(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, cobra.ShellCompDirective, error) {
+	resp, err := client.GetFeatureGates(ctx, &vtctldatapb.GetFeatureGatesRequest{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp, err
+	}
+	names := make([]string, 0, len(resp.FeatureGates))
+	for _, fg := range resp.FeatureGates {
+		names = append(names, fg.Name)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp, nil
+}
+
+func keyspaceNames(ctx context.Context, client vtctldclient.VtctldClient, toComplete string) ([]string, error) {
+	resp, err := client.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	keyspaces := make([]string, 0, len(resp.Keyspaces))
+	for _, keyspace := range resp.Keyspaces {
+		keyspaces = append(keyspaces, keyspace.Name)
+	}
+	return filterCompletions(keyspaces, toComplete), nil
+}
+
+// filterCompletions returns the sorted values that start with toComplete.
+func filterCompletions(values []string, toComplete string) []string {
+	var filtered []string
+	for _, value := range values {
+		if strings.HasPrefix(value, toComplete) {
+			filtered = append(filtered, value)
+		}
+	}
+	sort.Strings(filtered)
+	return filtered
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		ApplyVSchema, DeleteKeyspace, ExecDBA, FindAllShardsInKeyspace, GetArchivals, GetDBAScripts,
+		GetKeyspace, GetKeyspaceSettings, GetPartitionPolicies, GetSequence, GetSrvKeyspacePartitions,
+		GetSrvKeyspaces, GetTableSizes, GetVSchema, GetVSchemaHistory, GetWorkflows, ReloadSchemaKeyspace,
+		RemoveKeyspaceCell, RestoreDroppedTable, RollbackVSchema, SetKeyspaceDurabilityPolicy,
+		SetKeyspaceServedFrom, SetKeyspaceSettings, SetKeyspaceShardingInfo, SetSrvKeyspacePartition,
+		UpdateSequence, ValidateKeyspace, ValidateSchemaKeyspace, ValidateVersionKeyspace,
+	} {
+		cmd.ValidArgsFunction = completeArgs(false, completeKeyspaces)
+	}
+	RebuildKeyspaceGraph.ValidArgsFunction = completeArgs(true, completeKeyspaces)
+	SetFeatureGate.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 2 {
+			return filterCompletions([]string{"true", "false", "default"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeArgs(false, completeKeyspaces, completeFeatureGates)(cmd, args, toComplete)
+	}
+
+	for _, cmd := range []*cobra.Command{
+		BackupShard, EmergencyReparentShard, GetBackups, GetShard, PlannedReparentShard, RefreshStateByShard,
+		ReloadSchemaShard, RemoveBackup, RemoveShardCell, SetShardIsPrimaryServing, SetShardReadOnly,
+		SetShardTabletControl, ShardReplicationPositions, SourceShardAdd, SourceShardDelete, ValidateShard,
+	} {
+		cmd.ValidArgsFunction = completeArgs(false, completeShards)
+	}
+	for _, cmd := range []*cobra.Command{InitShardPrimary, ShardReplicationAdd, ShardReplicationRemove} {
+		cmd.ValidArgsFunction = completeArgs(false, completeShards, completeTabletAliases)
+	}
+	ShardReplicationFix.ValidArgsFunction = completeArgs(false, nil, completeShards)
+	DeleteShards.ValidArgsFunction = completeArgs(true, completeShards)
+
+	for _, cmd := range []*cobra.Command{
+		Backup, ChangeTabletType, DrainTablet, ExecuteFetchAsApp, ExecuteFetchAsDBA, ExecuteHook, ExplainQuery,
+		GetPermissions, GetSchema, GetTablet, GetTabletVersion, PingTablet, RefreshState, ReloadSchema,
+		ReparentTablet, RestoreFromBackup, RunHealthCheck, SetWritable, SleepTablet, StartReplication,
+		StopReplication, TabletExternallyReparented,
+	} {
+		cmd.ValidArgsFunction = completeArgs(false, completeTabletAliases)
+	}
+	DeleteTablets.ValidArgsFunction = completeArgs(true, completeTabletAliases)
+	for _, cmd := range []*cobra.Command{GetRuntimeFlags, GetVersionInfo, SetRuntimeFlag} {
+		_ = cmd.RegisterFlagCompletionFunc("tablet", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return runCompleter(completeTabletAliases, toComplete)
+		})
+	}
+
+	Root.AddCommand(Completion)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ExecDBA runs a SQL script as the DBA user on tablets of a keyspace.
+	ExecDBA = &cobra.Command{
+		Use:   "ExecDBA {--sql <sql> | --sql-file <file>} [--name <name>] [--tablet-aliases <aliases>] [--tablet-types <types>] [--tags <key:value,...>] [--concurrency 1] [--dry-run] [--require-approval] <keyspace>",
+		Short: "Runs a SQL script as the DBA user on the tablets of the keyspace that match all the selectors.",
+		Long: `Runs a SQL script as the DBA user on the tablets of the keyspace that match all the selectors.
+
+Each selected tablet runs the statements of the script in order, and stops at
+the first statement that fails. At most --concurrency tablets run the script at
+the same time. The result of each statement on each tablet is output as JSON.
+
+With --dry-run, the statements and the selected tablets are output, and the
+script is neither run nor recorded.
+
+The script is submitted by the caller that vtctld authenticates with its gRPC
+auth plugin, see --grpc_auth_mode, so vtctld must run with one. With
+--require-approval, the script is recorded as pending, and only runs once
+another caller approves it with ApproveDBAScript.
+
+The scripts are kept in the topo with an audit log of their submission, their
+approval and their outcome on each tablet, see GetDBAScripts.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandExecDBA,
+	}
+	// ApproveDBAScript approves and runs a pending DBA script.
+	ApproveDBAScript = &cobra.Command{
+		Use:                   "ApproveDBAScript <keyspace.script>",
+		Short:                 "Approves a DBA script submitted with --require-approval by another caller, and runs it.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandApproveDBAScript,
+	}
+	// GetDBAScripts shows the DBA scripts of a keyspace.
+	GetDBAScripts = &cobra.Command{
+		Use:                   "GetDBAScripts <keyspace>",
+		Short:                 "Returns the DBA scripts of the keyspace, with their audit log.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetDBAScripts,
+	}
+)
+
+var execDBAOptions = struct {
+	SQL             string
+	SQLFile         string
+	Name            string
+	TabletAliases   []string
+	TabletTypes     []string
+	Tags            cli.StringMapValue
+	Concurrency     int
+	DryRun          bool
+	RequireApproval bool
+}{}
+
+func commandExecDBA(cmd *cobra.Command, args []string) error {
+	sql := execDBAOptions.SQL
+	if execDBAOptions.SQLFile != "" {
+		if sql != "" {
+			return fmt.Errorf("only one of --sql and --sql-file may be specified")
+		}
+		data, err := os.ReadFile(execDBAOptions.SQLFile)
+		if err != nil {
+			return err
+		}
+		sql = string(data)
+	}
+	tabletAliases, err := cli.TabletAliasesFromPosArgs(execDBAOptions.TabletAliases)
+	if err != nil {
+		return err
+	}
+	tabletTypes := make([]topodatapb.TabletType, 0, len(execDBAOptions.TabletTypes))
+	for _, tabletTypeStr := range execDBAOptions.TabletTypes {
+		tabletType, err := topoproto.ParseTabletType(tabletTypeStr)
+		if err != nil {
+			return err
+		}
+		tabletTypes = append(tabletTypes, tabletType)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ExecDBA(commandCtx, &vtctldatapb.ExecDBARequest{
+		Keyspace:        cmd.Flags().Arg(0),
+		Name:            execDBAOptions.Name,
+		Sql:             sql,
+		TabletAliases:   tabletAliases,
+		TabletTypes:     tabletTypes,
+		Tags:            execDBAOptions.Tags.StringMapValue,
+		Concurrency:     int32(execDBAOptions.Concurrency),
+		DryRun:          execDBAOptions.DryRun,
+		RequireApproval: execDBAOptions.RequireApproval,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	return dbaScriptError(resp.Script, resp.Results)
+}
+
+func commandApproveDBAScript(cmd *cobra.Command, args []string) error {
+	keyspace, name, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "script")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ApproveDBAScript(commandCtx, &vtctldatapb.ApproveDBAScriptRequest{
+		Keyspace: keyspace,
+		Name:     name,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	return dbaScriptError(resp.Script, resp.Results)
+}
+
+func commandGetDBAScripts(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetDBAScripts(commandCtx, &vtctldatapb.GetDBAScriptsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Scripts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// dbaScriptError returns an error if a DBA script failed on some of its
+// tablets, after its results were output.
+func dbaScriptError(script *vtctldatapb.DBAScript, results []*vtctldatapb.DBAScriptTabletResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("DBA script %s failed on %d of %d tablets", script.Name, failed, len(results))
+	}
+	return nil
+}
+
+func init() {
+	ExecDBA.Flags().StringVar(&execDBAOptions.SQL, "sql", "", "The SQL script, as statements separated by semicolons.")
+	ExecDBA.Flags().StringVar(&execDBAOptions.SQLFile, "sql-file", "", "The local file that holds the SQL script.")
+	ExecDBA.Flags().StringVar(&execDBAOptions.Name, "name", "", "The name of the script, unique in the keyspace. Defaults to the submission time.")
+	ExecDBA.Flags().StringSliceVar(&execDBAOptions.TabletAliases, "tablet-aliases", nil, "The aliases of the tablets that run the script.")
+	ExecDBA.Flags().StringSliceVar(&execDBAOptions.TabletTypes, "tablet-types", nil, "The types of the tablets that run the script.")
+	ExecDBA.Flags().Var(&execDBAOptions.Tags, "tags", "The key:value pairs of the tags of the tablets that run the script.")
+	ExecDBA.Flags().IntVar(&execDBAOptions.Concurrency, "concurrency", 1, "The maximum number of tablets that run the script at the same time.")
+	ExecDBA.Flags().BoolVar(&execDBAOptions.DryRun, "dry-run", false, "Outputs the statements and the tablets that would run them, without running or recording the script.")
+	ExecDBA.Flags().BoolVar(&execDBAOptions.RequireApproval, "require-approval", false, "Records the script as pending, so that it only runs once another caller approves it with ApproveDBAScript.")
+	Root.AddCommand(ExecDBA)
+
+	Root.AddCommand(ApproveDBAScript)
+
+	Root.AddCommand(GetDBAScripts)
+}
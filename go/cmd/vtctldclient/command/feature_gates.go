@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetFeatureGates makes a GetFeatureGates gRPC call to a vtctld.
+	GetFeatureGates = &cobra.Command{
+		Use:   "GetFeatureGates [--keyspace <keyspace>]",
+		Short: "Lists the feature gates, with their overrides in the keyspaces.",
+		Long: `Lists the feature gates, with their overrides in the keyspaces.
+
+A feature gate turns a risky feature on and off. Alpha features are disabled by
+default, and beta features are enabled by default. The --feature_gates flag of
+the vtgates and the tablets overrides the defaults, and the overrides of a
+keyspace, set with SetFeatureGate, win over the flag in the keyspace.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetFeatureGates,
+	}
+	// SetFeatureGate makes a SetFeatureGate gRPC call to a vtctld.
+	SetFeatureGate = &cobra.Command{
+		Use:   "SetFeatureGate <keyspace> <gate> <true|false|default>",
+		Short: "Turns a feature gate on or off in a keyspace, or clears the override of the keyspace with default.",
+		Long: `Turns a feature gate on or off in a keyspace, or clears the override of the
+keyspace with default, so that the --feature_gates of the vtgates and the
+tablets applies to the keyspace again.
+
+The override is saved in the keyspace settings, which the vtgates read every
+--keyspace_settings_refresh_interval. The tablets read it when a feature
+starts, e.g. when a VReplication stream starts replicating.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(3),
+		RunE:                  commandSetFeatureGate,
+	}
+)
+
+var getFeatureGatesOptions = struct {
+	Keyspace string
+}{}
+
+func commandGetFeatureGates(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetFeatureGates(commandCtx, &vtctldatapb.GetFeatureGatesRequest{
+		Keyspace: getFeatureGatesOptions.Keyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func commandSetFeatureGate(cmd *cobra.Command, args []string) error {
+	req := &vtctldatapb.SetFeatureGateRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Name:     cmd.Flags().Arg(1),
+	}
+	if value := cmd.Flags().Arg(2); value == "default" {
+		req.Clear = true
+	} else {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, expected true, false or default", value)
+		}
+		req.Enabled = enabled
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetFeatureGate(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.FeatureGate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	GetFeatureGates.Flags().StringVar(&getFeatureGatesOptions.Keyspace, "keyspace", "", "Only return the overrides of this keyspace.")
+	Root.AddCommand(GetFeatureGates)
+
+	Root.AddCommand(SetFeatureGate)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/vtctl/importdata"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ImportData imports files from the backup storage into a table.
+	ImportData = &cobra.Command{
+		Use:   "ImportData [--format csv|parquet] [--columns <col1,col2,...>] [--batch-size <rows>] [--max-tps <inserts>] [--checkpoint-file <path>] [--failed-rows-file <path> [--max-failed-rows <rows>]] <keyspace> <table> <dir>",
+		Short: "Imports the rows of CSV or Parquet files from the backup storage into a table.",
+		Long: `Imports the rows of CSV or Parquet files from the backup storage into a table.
+
+The files are read from <dir> in the storage selected by --backup_storage_implementation.
+Each backup of <dir> holds one file named "data", and the backups are imported in
+the order of their names. This is the layout of the exports of
+SELECT ... INTO OUTFILE S3, whose CSV exports can be imported as they are.
+
+The columns of CSV files are named by their first line, unless --columns is
+given, and \N is NULL. The rows are routed to their shard with the sharding
+vindex of the table, and inserted on the shard primaries, --batch-size rows at a
+time. Tables that own lookup vindexes cannot be imported.
+
+With --checkpoint-file, the progress is saved after each chunk, and an
+interrupted import is resumed from it when run again. The rows of the chunk
+that was being inserted may be imported twice, in which case they fail as
+duplicates.
+
+With --failed-rows-file, the rows that fail because of their values are
+appended to this CSV file, after their file, row number and error, and the
+import goes on until more than --max-failed-rows rows failed. Otherwise the
+first failed row stops the import.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(3),
+		RunE:                  commandImportData,
+	}
+)
+
+var importDataOptions = struct {
+	Format         string
+	Columns        []string
+	BatchSize      int
+	MaxTPS         int64
+	CheckpointFile string
+	FailedRowsFile string
+	MaxFailedRows  int64
+}{}
+
+func commandImportData(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	table := cmd.Flags().Arg(1)
+	cfg := &importdata.Config{
+		Dir:            cmd.Flags().Arg(2),
+		Format:         importDataOptions.Format,
+		Columns:        importDataOptions.Columns,
+		BatchSize:      importDataOptions.BatchSize,
+		MaxTPS:         importDataOptions.MaxTPS,
+		CheckpointFile: importDataOptions.CheckpointFile,
+		FailedRowsFile: importDataOptions.FailedRowsFile,
+		MaxFailedRows:  importDataOptions.MaxFailedRows,
+	}
+
+	cli.FinishedParsing(cmd)
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+
+	vschema, err := client.GetVSchema(commandCtx, &vtctldatapb.GetVSchemaRequest{
+		Keyspace: keyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.FindAllShardsInKeyspace(commandCtx, &vtctldatapb.FindAllShardsInKeyspaceRequest{
+		Keyspace: keyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	target := &importdata.Target{
+		Keyspace: keyspace,
+		Table:    table,
+		VSchema:  vschema.VSchema,
+		Shards:   make(map[string]*topodatapb.Shard),
+	}
+	primaries := make(map[string]*topodatapb.TabletAlias)
+	for name, shard := range resp.Shards {
+		if shard.Shard == nil || !shard.Shard.IsPrimaryServing {
+			continue
+		}
+		if shard.Shard.PrimaryAlias == nil {
+			return fmt.Errorf("shard %s/%s has no primary", keyspace, name)
+		}
+		target.Shards[name] = shard.Shard
+		primaries[name] = shard.Shard.PrimaryAlias
+	}
+	if len(primaries) == 0 {
+		return fmt.Errorf("keyspace %s has no serving shards", keyspace)
+	}
+
+	schema, err := client.GetSchema(commandCtx, &vtctldatapb.GetSchemaRequest{
+		TabletAlias: primaries[sortedShardNames(primaries)[0]],
+		Tables:      []string{table},
+	})
+	if err != nil {
+		return err
+	}
+	if len(schema.Schema.TableDefinitions) != 1 {
+		return fmt.Errorf("table %s not found in keyspace %s", table, keyspace)
+	}
+	target.Fields = schema.Schema.TableDefinitions[0].Fields
+
+	insert := func(ctx context.Context, shard string, query string) error {
+		_, err := client.ExecuteFetchAsApp(ctx, &vtctldatapb.ExecuteFetchAsAppRequest{
+			TabletAlias: primaries[shard],
+			Query:       query,
+			MaxRows:     1,
+		})
+		return err
+	}
+
+	stats, err := importdata.Run(commandCtx, bs, target, cfg, insert)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(stats)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	ImportData.Flags().StringVar(&importDataOptions.Format, "format", importdata.FormatCSV, "Format of the files, csv or parquet.")
+	ImportData.Flags().StringSliceVar(&importDataOptions.Columns, "columns", nil, "Names of the columns of CSV files that have no header line.")
+	ImportData.Flags().IntVar(&importDataOptions.BatchSize, "batch-size", 1000, "Number of rows read and inserted at a time.")
+	ImportData.Flags().Int64Var(&importDataOptions.MaxTPS, "max-tps", 0, "Maximum number of inserts per second. If 0, the inserts are not throttled.")
+	ImportData.Flags().StringVar(&importDataOptions.CheckpointFile, "checkpoint-file", "", "Local file where the progress is saved, and from which an interrupted import is resumed.")
+	ImportData.Flags().StringVar(&importDataOptions.FailedRowsFile, "failed-rows-file", "", "Local CSV file where the rows that cannot be imported are appended.")
+	ImportData.Flags().Int64Var(&importDataOptions.MaxFailedRows, "max-failed-rows", 1000, "Number of failed rows past which the import stops. Only used with --failed-rows-file.")
+	Root.AddCommand(ImportData)
+}
@@ -19,6 +19,9 @@ package command
 import (
 	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -34,6 +37,24 @@ import (
 )
 
 var (
+	// AnalyzeShardDistribution makes an AnalyzeShardDistribution gRPC call to a vtctld.
+	AnalyzeShardDistribution = &cobra.Command{
+		Use:   "AnalyzeShardDistribution --keyspace <keyspace> --proposed-shards <count> [--tables <table>,...] [--sample-size <rows>]",
+		Short: "Estimates how the rows of a keyspace would be distributed across a number of shards, before it is resharded.",
+		Long: `Estimates how the rows of a keyspace would be distributed across a number of shards, before it is resharded.
+
+The rows of the tables of the keyspace are sampled on an rdonly tablet of each shard, or on a replica
+if the shard has no rdonly tablet. The keyspace ids of the sampled rows are computed by the primary
+vindexes of their tables, and the rows and bytes of the tables are extrapolated to the shards of
+even key ranges whose key ranges hold them.
+
+The skew of a proposed shard is the ratio of its estimated rows to the average of the proposed
+shards: a shard with a skew well above 1 would be a hot shard. The shards that could not be sampled
+are reported as a warning.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandAnalyzeShardDistribution,
+	}
 	// CreateKeyspace makes a CreateKeyspace gRPC call to a vtctld.
 	CreateKeyspace = &cobra.Command{
 		Use:   "CreateKeyspace <keyspace> [--force|-f] [--type KEYSPACE_TYPE] [--base-keyspace KEYSPACE --snapshot-timestamp TIME] [--served-from DB_TYPE:KEYSPACE ...]  [--durability-policy <policy_name>]",
@@ -76,6 +97,14 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetKeyspace,
 	}
+	// GetKeyspaceSettings shows the settings vtgates apply to a keyspace.
+	GetKeyspaceSettings = &cobra.Command{
+		Use:                   "GetKeyspaceSettings <keyspace>",
+		Short:                 "Returns the settings vtgates apply to the queries they route to the given keyspace.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetKeyspaceSettings,
+	}
 	// GetKeyspaces makes a GetKeyspaces gRPC call to a vtctld.
 	GetKeyspaces = &cobra.Command{
 		Use:                   "GetKeyspaces",
@@ -107,6 +136,43 @@ SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandSetKeyspaceDurabilityPolicy,
 	}
+	// SetKeyspaceSettings changes the settings vtgates apply to a keyspace.
+	SetKeyspaceSettings = &cobra.Command{
+		Use:   "SetKeyspaceSettings [--no-scatter=<true|false>] [--default-workload=<OLTP|OLAP|DBA>] [--shed-percent=<percent>] [--shed-priority=<priority>] [--table-gc-lifecycle=<states>] [--table-gc-hold=<duration>] [--availability-objective=<fraction>] [--latency-objective=<fraction>] [--latency-threshold=<duration>] <keyspace>",
+		Short: "Changes the settings vtgates and tablets apply to the given keyspace.",
+		Long: `Changes the settings vtgates apply to the queries they route to the given keyspace,
+and the settings its tablets apply to the garbage collection of its dropped tables.
+
+Only the settings passed as flags are changed. Vtgates read the settings from
+the topology every --keyspace_settings_refresh_interval, and apply them without
+a restart.
+
+--no-scatter rejects queries that scatter across the shards of the keyspace,
+unless they use the ALLOW_SCATTER directive. --default-workload sets the
+workload of new MySQL protocol sessions that connect to the keyspace.
+
+--shed-percent rejects a percentage of the queries of --shed-priority or a
+lower priority, to protect the keyspace while it is overloaded. The queries
+fail with a RESOURCE_EXHAUSTED error, and can be retried. The queries of
+transactions that already opened connections to tablets are not shed.
+
+--table-gc-lifecycle sets the states (hold, purge, evac, drop) that the tables
+dropped in the keyspace go through, overriding --table_gc_lifecycle of the
+tablets. --table-gc-hold sets how long the tables dropped by Online DDL are
+held before they are purged, overriding --retain_online_ddl_tables of the
+tablets. While a dropped table is held, RestoreDroppedTable can restore it.
+Tablets pick up both settings before they next collect dropped tables.
+
+--availability-objective and --latency-objective set the service level
+objectives of the queries routed to the keyspace: the fraction of them that
+must not fail with a server error, and the fraction of them that must complete
+within --latency-threshold. They override --slo_availability_objective,
+--slo_latency_objective and --slo_latency_threshold of the vtgates, which
+report how fast the queries burn the error budget of the objectives.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetKeyspaceSettings,
+	}
 	// SetKeyspaceServedFrom makes a SetKeyspaceServedFrom gRPC call to a vtcltd.
 	SetKeyspaceServedFrom = &cobra.Command{
 		Use:                   "SetKeyspaceServedFrom [--source <keyspace>] [--remove] [--cells=<cells>] <keyspace> <tablet_type>",
@@ -145,6 +211,52 @@ SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
 	}
 )
 
+var analyzeShardDistributionOptions = struct {
+	Keyspace       string
+	ProposedShards int32
+	Tables         []string
+	SampleSize     int64
+}{}
+
+func commandAnalyzeShardDistribution(cmd *cobra.Command, args []string) error {
+	if analyzeShardDistributionOptions.ProposedShards <= 0 {
+		return fmt.Errorf("--proposed-shards must be positive, got %d", analyzeShardDistributionOptions.ProposedShards)
+	}
+	if analyzeShardDistributionOptions.SampleSize <= 0 {
+		return fmt.Errorf("--sample-size must be positive, got %d", analyzeShardDistributionOptions.SampleSize)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.AnalyzeShardDistribution(commandCtx, &vtctldatapb.AnalyzeShardDistributionRequest{
+		Keyspace:       analyzeShardDistributionOptions.Keyspace,
+		ProposedShards: analyzeShardDistributionOptions.ProposedShards,
+		Tables:         analyzeShardDistributionOptions.Tables,
+		SampleSize:     analyzeShardDistributionOptions.SampleSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	if len(resp.Errors) > 0 {
+		shards := make([]string, 0, len(resp.Errors))
+		for shard := range resp.Errors {
+			shards = append(shards, shard)
+		}
+		sort.Strings(shards)
+		fmt.Fprintf(os.Stderr, "WARNING: these shards could not be sampled, the estimates are partial: %s\n", strings.Join(shards, ", "))
+	}
+
+	return nil
+}
+
 var createKeyspaceOptions = struct {
 	Force             bool
 	AllowEmptyVSchema bool
@@ -340,6 +452,83 @@ func commandRemoveKeyspaceCell(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetKeyspaceSettings(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetKeyspaceSettings(commandCtx, &vtctldatapb.GetKeyspaceSettingsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Settings)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var setKeyspaceSettingsOptions = struct {
+	NoScatter        bool
+	DefaultWorkload  string
+	ShedPercent      int
+	ShedPriority     int
+	TableGCLifecycle string
+	TableGCHold      string
+
+	AvailabilityObjective float64
+	LatencyObjective      float64
+	LatencyThreshold      string
+}{}
+
+func commandSetKeyspaceSettings(cmd *cobra.Command, args []string) error {
+	// The flags of the settings to change are named like the settings, with
+	// dashes.
+	var fields []string
+	for _, name := range []string{"no-scatter", "default-workload", "shed-percent", "shed-priority", "table-gc-lifecycle", "table-gc-hold", "availability-objective", "latency-objective", "latency-threshold"} {
+		if cmd.Flags().Changed(name) {
+			fields = append(fields, strings.ReplaceAll(name, "-", "_"))
+		}
+	}
+	if len(fields) == 0 {
+		return errors.New("at least one of --no-scatter, --default-workload, --shed-percent, --shed-priority, --table-gc-lifecycle, --table-gc-hold, --availability-objective, --latency-objective or --latency-threshold is required")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetKeyspaceSettings(commandCtx, &vtctldatapb.SetKeyspaceSettingsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Settings: &vtctldatapb.KeyspaceSettings{
+			NoScatter:             setKeyspaceSettingsOptions.NoScatter,
+			DefaultWorkload:       setKeyspaceSettingsOptions.DefaultWorkload,
+			ShedPercent:           int32(setKeyspaceSettingsOptions.ShedPercent),
+			ShedPriority:          int32(setKeyspaceSettingsOptions.ShedPriority),
+			TableGcLifecycle:      setKeyspaceSettingsOptions.TableGCLifecycle,
+			TableGcHold:           setKeyspaceSettingsOptions.TableGCHold,
+			AvailabilityObjective: setKeyspaceSettingsOptions.AvailabilityObjective,
+			LatencyObjective:      setKeyspaceSettingsOptions.LatencyObjective,
+			LatencyThreshold:      setKeyspaceSettingsOptions.LatencyThreshold,
+		},
+		Fields: fields,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Settings)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 var setKeyspaceDurabilityPolicyOptions = struct {
 	DurabilityPolicy string
 }{}
@@ -462,6 +651,14 @@ func commandValidateVersionKeyspace(cmd *cobra.Command, args []string) error {
 }
 
 func init() {
+	AnalyzeShardDistribution.Flags().StringVarP(&analyzeShardDistributionOptions.Keyspace, "keyspace", "k", "", "The keyspace whose rows to sample.")
+	AnalyzeShardDistribution.MarkFlagRequired("keyspace")
+	AnalyzeShardDistribution.Flags().Int32Var(&analyzeShardDistributionOptions.ProposedShards, "proposed-shards", 0, "The number of shards, of even key ranges, that the keyspace would be resharded into.")
+	AnalyzeShardDistribution.MarkFlagRequired("proposed-shards")
+	AnalyzeShardDistribution.Flags().StringSliceVar(&analyzeShardDistributionOptions.Tables, "tables", nil, "The tables to sample. Defaults to all the tables whose primary vindex computes the keyspace ids from the column values.")
+	AnalyzeShardDistribution.Flags().Int64Var(&analyzeShardDistributionOptions.SampleSize, "sample-size", 10000, "The number of rows to sample from each table on each shard.")
+	Root.AddCommand(AnalyzeShardDistribution)
+
 	CreateKeyspace.Flags().BoolVarP(&createKeyspaceOptions.Force, "force", "f", false, "Proceeds even if the keyspace already exists. Does not overwrite the existing keyspace record.")
 	CreateKeyspace.Flags().BoolVarP(&createKeyspaceOptions.AllowEmptyVSchema, "allow-empty-vschema", "e", false, "Allows a new keyspace to have no vschema.")
 	CreateKeyspace.Flags().Var(&createKeyspaceOptions.ServedFromsMap, "served-from", "Specifies a set of db_type:keyspace pairs used to serve traffic for the keyspace.")
@@ -477,6 +674,7 @@ func init() {
 
 	Root.AddCommand(FindAllShardsInKeyspace)
 	Root.AddCommand(GetKeyspace)
+	Root.AddCommand(GetKeyspaceSettings)
 	Root.AddCommand(GetKeyspaces)
 
 	RemoveKeyspaceCell.Flags().BoolVarP(&removeKeyspaceCellOptions.Force, "force", "f", false, "Proceed even if the cell's topology server cannot be reached. The assumption is that you turned down the entire cell, and just need to update the global topo data.")
@@ -491,6 +689,17 @@ func init() {
 	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins.")
 	Root.AddCommand(SetKeyspaceDurabilityPolicy)
 
+	SetKeyspaceSettings.Flags().BoolVar(&setKeyspaceSettingsOptions.NoScatter, "no-scatter", false, "Reject queries that scatter across the shards of the keyspace, unless they use the ALLOW_SCATTER directive.")
+	SetKeyspaceSettings.Flags().StringVar(&setKeyspaceSettingsOptions.DefaultWorkload, "default-workload", "", "The workload (OLTP, OLAP or DBA) of new MySQL protocol sessions that connect to the keyspace. Empty uses the vtgate default.")
+	SetKeyspaceSettings.Flags().IntVar(&setKeyspaceSettingsOptions.ShedPercent, "shed-percent", 0, "The percentage of the queries of --shed-priority or a lower priority that vtgates reject, to protect the keyspace while it is overloaded. 0 stops shedding queries.")
+	SetKeyspaceSettings.Flags().IntVar(&setKeyspaceSettingsOptions.ShedPriority, "shed-priority", 0, "The highest priority, between 1 and 100, of the queries that are shed. 0 sheds only the queries of the lowest priority, 100.")
+	SetKeyspaceSettings.Flags().StringVar(&setKeyspaceSettingsOptions.TableGCLifecycle, "table-gc-lifecycle", "", "The comma separated states (hold, purge, evac, drop) that the tables dropped in the keyspace go through. Empty uses the --table_gc_lifecycle of the tablets.")
+	SetKeyspaceSettings.Flags().StringVar(&setKeyspaceSettingsOptions.TableGCHold, "table-gc-hold", "", "How long the tables dropped by Online DDL are held, and can be restored, before they are purged, e.g. 72h. Empty uses the --retain_online_ddl_tables of the tablets.")
+	SetKeyspaceSettings.Flags().Float64Var(&setKeyspaceSettingsOptions.AvailabilityObjective, "availability-objective", 0, "The fraction of the queries routed to the keyspace that must not fail with a server error, e.g. 0.999. 0 uses the --slo_availability_objective of the vtgates.")
+	SetKeyspaceSettings.Flags().Float64Var(&setKeyspaceSettingsOptions.LatencyObjective, "latency-objective", 0, "The fraction of the queries routed to the keyspace that must complete within --latency-threshold, e.g. 0.99. 0 uses the --slo_latency_objective of the vtgates.")
+	SetKeyspaceSettings.Flags().StringVar(&setKeyspaceSettingsOptions.LatencyThreshold, "latency-threshold", "", "The latency of the queries that --latency-objective counts as fast enough, e.g. 100ms. Empty uses the --slo_latency_threshold of the vtgates.")
+	Root.AddCommand(SetKeyspaceSettings)
+
 	SetKeyspaceShardingInfo.Flags().BoolVarP(&setKeyspaceShardingInfoOptions.Force, "force", "f", false, "Updates fields even if they are already set. Use caution before passing force to this command.")
 	Root.AddCommand(SetKeyspaceShardingInfo)
 
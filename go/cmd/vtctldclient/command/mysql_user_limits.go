@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetMySQLUserLimits shows the limits vtgates apply to MySQL users.
+	GetMySQLUserLimits = &cobra.Command{
+		Use:                   "GetMySQLUserLimits [<user>]",
+		Short:                 "Returns the limits vtgates apply to the connections and the queries of the given MySQL user, or of all the MySQL users that have some.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MaximumNArgs(1),
+		RunE:                  commandGetMySQLUserLimits,
+	}
+	// SetMySQLUserLimits changes the limits vtgates apply to a MySQL user.
+	SetMySQLUserLimits = &cobra.Command{
+		Use:   "SetMySQLUserLimits [--max-connections=<count>] [--max-concurrent-queries=<count>] <user>",
+		Short: "Changes the limits vtgates apply to the connections and the queries of the given MySQL user.",
+		Long: `Changes the limits vtgates apply to the connections and the queries of the given MySQL user.
+
+Only the limits passed as flags are changed, and 0 removes a limit. Vtgates read
+the limits from the topology every --mysql_user_limits_refresh_interval, and
+apply them to the connections and the queries they receive, without a restart.
+The limits take precedence over the limits of the auth server of the vtgates.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetMySQLUserLimits,
+	}
+)
+
+func commandGetMySQLUserLimits(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	user := cmd.Flags().Arg(0)
+	resp, err := client.GetMySQLUserLimits(commandCtx, &vtctldatapb.GetMySQLUserLimitsRequest{
+		User: user,
+	})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if user != "" {
+		data, err = cli.MarshalJSON(resp.Limits[user])
+	} else {
+		data, err = cli.MarshalJSON(resp.Limits)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var setMySQLUserLimitsOptions = struct {
+	MaxConnections       int
+	MaxConcurrentQueries int
+}{}
+
+func commandSetMySQLUserLimits(cmd *cobra.Command, args []string) error {
+	var fields []string
+	if cmd.Flags().Changed("max-connections") {
+		fields = append(fields, "max_connections")
+	}
+	if cmd.Flags().Changed("max-concurrent-queries") {
+		fields = append(fields, "max_concurrent_queries")
+	}
+	if len(fields) == 0 {
+		return errors.New("at least one of --max-connections or --max-concurrent-queries is required")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetMySQLUserLimits(commandCtx, &vtctldatapb.SetMySQLUserLimitsRequest{
+		User: cmd.Flags().Arg(0),
+		Limits: &vtctldatapb.MySQLUserLimits{
+			MaxConnections:       int32(setMySQLUserLimitsOptions.MaxConnections),
+			MaxConcurrentQueries: int32(setMySQLUserLimitsOptions.MaxConcurrentQueries),
+		},
+		Fields: fields,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Limits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(GetMySQLUserLimits)
+
+	SetMySQLUserLimits.Flags().IntVar(&setMySQLUserLimitsOptions.MaxConnections, "max-connections", 0, "The maximum number of MySQL connections of the user to each vtgate. 0 applies the limit of the auth server of the vtgates.")
+	SetMySQLUserLimits.Flags().IntVar(&setMySQLUserLimitsOptions.MaxConcurrentQueries, "max-concurrent-queries", 0, "The maximum number of queries of the user that each vtgate executes at the same time. 0 applies the limit of the auth server of the vtgates.")
+	Root.AddCommand(SetMySQLUserLimits)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// SetPartitionPolicy sets the partition policy of a table.
+	SetPartitionPolicy = &cobra.Command{
+		Use:   "SetPartitionPolicy --interval <day|week|month> --precreate <partitions> [--retention <intervals>] <keyspace.table>",
+		Short: "Sets the partition policy of a RANGE partitioned table, whose partitions the primary tablets then rotate.",
+		Long: `Sets the partition policy of a RANGE partitioned table, whose partitions the primary tablets then rotate.
+
+Every --partition_management_interval, the primary tablets submit the Online DDL
+migrations that add the partitions of the next --precreate intervals after the
+last partition of the table, and drop the partitions whose rows are all older
+than --retention intervals. The migrations run with the --fast-range-rotation
+option, as plain ADD PARTITION and DROP PARTITION statements.
+
+The table must be partitioned by RANGE COLUMNS on a DATE or DATETIME column, or
+by RANGE on TO_DAYS() or UNIX_TIMESTAMP() of a column, and must not end with a
+MAXVALUE partition. The added partitions are named after the start of their
+interval, e.g. p20221017 or p202210 for monthly partitions.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetPartitionPolicy,
+	}
+	// GetPartitionPolicies shows the partition policies of a keyspace.
+	GetPartitionPolicies = &cobra.Command{
+		Use:                   "GetPartitionPolicies <keyspace>",
+		Short:                 "Returns the partition policies of the tables of the keyspace.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetPartitionPolicies,
+	}
+	// DeletePartitionPolicy deletes the partition policy of a table.
+	DeletePartitionPolicy = &cobra.Command{
+		Use:                   "DeletePartitionPolicy <keyspace.table>",
+		Short:                 "Deletes the partition policy of a table. The partitions of the table are kept.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDeletePartitionPolicy,
+	}
+)
+
+var setPartitionPolicyOptions = struct {
+	Interval  string
+	Precreate int
+	Retention int
+}{}
+
+func commandSetPartitionPolicy(cmd *cobra.Command, args []string) error {
+	keyspace, table, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "table")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetPartitionPolicy(commandCtx, &vtctldatapb.SetPartitionPolicyRequest{
+		Keyspace: keyspace,
+		PartitionPolicy: &vtctldatapb.PartitionPolicy{
+			Table:     table,
+			Interval:  setPartitionPolicyOptions.Interval,
+			Precreate: int64(setPartitionPolicyOptions.Precreate),
+			Retention: int64(setPartitionPolicyOptions.Retention),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.PartitionPolicy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandGetPartitionPolicies(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetPartitionPolicies(commandCtx, &vtctldatapb.GetPartitionPoliciesRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.PartitionPolicies)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandDeletePartitionPolicy(cmd *cobra.Command, args []string) error {
+	keyspace, table, err := cli.ParseKeyspaceName(cmd.Flags().Arg(0), "table")
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	_, err = client.DeletePartitionPolicy(commandCtx, &vtctldatapb.DeletePartitionPolicyRequest{
+		Keyspace: keyspace,
+		Table:    table,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted the partition policy of %s\n", cmd.Flags().Arg(0))
+
+	return nil
+}
+
+func init() {
+	SetPartitionPolicy.Flags().StringVar(&setPartitionPolicyOptions.Interval, "interval", "", "The range of time of each partition: day, week or month.")
+	SetPartitionPolicy.Flags().IntVar(&setPartitionPolicyOptions.Precreate, "precreate", 0, "The number of partitions kept ahead of the current interval.")
+	SetPartitionPolicy.Flags().IntVar(&setPartitionPolicyOptions.Retention, "retention", 0, "The number of past intervals whose partitions are kept. 0 drops no partition.")
+	SetPartitionPolicy.MarkFlagRequired("interval")
+	SetPartitionPolicy.MarkFlagRequired("precreate")
+	Root.AddCommand(SetPartitionPolicy)
+
+	Root.AddCommand(GetPartitionPolicies)
+	Root.AddCommand(DeletePartitionPolicy)
+}
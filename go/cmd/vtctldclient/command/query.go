@@ -17,14 +17,20 @@ limitations under the License.
 package command
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
@@ -46,6 +52,18 @@ var (
 		RunE:                  commandExecuteFetchAsDBA,
 		Aliases:               []string{"ExecuteFetchAsDba"},
 	}
+	// ExplainQuery makes an ExplainQuery gRPC call to a vtctld.
+	ExplainQuery = &cobra.Command{
+		Use:   "ExplainQuery [--analyze] [--format <format>] [--bind-variables <json>] [--timeout <duration>] [--json|-j] <tablet alias> <query>",
+		Short: "Runs EXPLAIN, or EXPLAIN ANALYZE, for the given query on the remote tablet.",
+		Long: `Runs EXPLAIN, or EXPLAIN ANALYZE, for the given query on the remote tablet.
+
+The query runs as the App user in a read-only transaction, and is killed after --timeout, capped by the --explain_query_timeout of the tablet.
+EXPLAIN ANALYZE executes the query, so it is only allowed for SELECT statements.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandExplainQuery,
+	}
 )
 
 var executeFetchAsAppOptions = struct {
@@ -138,6 +156,91 @@ func commandExecuteFetchAsDBA(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var explainQueryOptions = struct {
+	Analyze       bool
+	Format        string
+	BindVariables string
+	Timeout       time.Duration
+	JSON          bool
+}{}
+
+func commandExplainQuery(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	bindVariables, err := parseBindVariables(explainQueryOptions.BindVariables)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	req := &tabletmanagerdatapb.ExplainQueryRequest{
+		Query:         cmd.Flags().Arg(1),
+		BindVariables: bindVariables,
+		Analyze:       explainQueryOptions.Analyze,
+		Format:        explainQueryOptions.Format,
+	}
+	if explainQueryOptions.Timeout > 0 {
+		req.Timeout = protoutil.DurationToProto(explainQueryOptions.Timeout)
+	}
+
+	resp, err := client.ExplainQuery(commandCtx, &vtctldatapb.ExplainQueryRequest{
+		TabletAlias:         alias,
+		ExplainQueryRequest: req,
+	})
+	if err != nil {
+		return err
+	}
+
+	if explainQueryOptions.JSON {
+		data, err := cli.MarshalJSON(resp.ExplainQueryResponse)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
+	fmt.Printf("%s\n", resp.ExplainQueryResponse.Query)
+	cli.WriteQueryResultTable(cmd.OutOrStdout(), sqltypes.Proto3ToResult(resp.ExplainQueryResponse.Result))
+	return nil
+}
+
+// parseBindVariables parses a JSON object of bind variable names to values.
+func parseBindVariables(data string) (map[string]*querypb.BindVariable, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(data))
+	decoder.UseNumber()
+	values := map[string]any{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("cannot parse --bind-variables: %w", err)
+	}
+
+	bindVariables := make(map[string]*querypb.BindVariable, len(values))
+	for name, value := range values {
+		if number, ok := value.(json.Number); ok {
+			if i, err := number.Int64(); err == nil {
+				value = i
+			} else if f, err := number.Float64(); err == nil {
+				value = f
+			}
+		}
+		bv, err := sqltypes.BuildBindVariable(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for bind variable %s: %w", name, err)
+		}
+		bindVariables[name] = bv
+	}
+	return bindVariables, nil
+}
+
 func init() {
 	ExecuteFetchAsApp.Flags().Int64Var(&executeFetchAsAppOptions.MaxRows, "max-rows", 10_000, "The maximum number of rows to fetch from the remote tablet.")
 	ExecuteFetchAsApp.Flags().BoolVar(&executeFetchAsAppOptions.UsePool, "use-pool", false, "Use the tablet connection pool instead of creating a fresh connection.")
@@ -149,4 +252,11 @@ func init() {
 	ExecuteFetchAsDBA.Flags().BoolVar(&executeFetchAsDBAOptions.ReloadSchema, "reload-schema", false, "Instructs the tablet to reload its schema after executing the query.")
 	ExecuteFetchAsDBA.Flags().BoolVarP(&executeFetchAsDBAOptions.JSON, "json", "j", false, "Output the results in JSON instead of a human-readable table.")
 	Root.AddCommand(ExecuteFetchAsDBA)
+
+	ExplainQuery.Flags().BoolVar(&explainQueryOptions.Analyze, "analyze", false, "Runs EXPLAIN ANALYZE, which executes the query, instead of EXPLAIN.")
+	ExplainQuery.Flags().StringVar(&explainQueryOptions.Format, "format", "", "The EXPLAIN output format, one of TRADITIONAL, JSON or TREE. Only TREE is supported with --analyze.")
+	ExplainQuery.Flags().StringVar(&explainQueryOptions.BindVariables, "bind-variables", "", "A JSON object of the bind variables of the query, e.g. '{\"id\": 1}'.")
+	ExplainQuery.Flags().DurationVar(&explainQueryOptions.Timeout, "timeout", 0, "The time after which the query is killed on the tablet. Defaults to, and is capped by, the --explain_query_timeout of the tablet.")
+	ExplainQuery.Flags().BoolVarP(&explainQueryOptions.JSON, "json", "j", false, "Output the results in JSON instead of a human-readable table.")
+	Root.AddCommand(ExplainQuery)
 }
@@ -40,6 +40,11 @@ var (
 	server        string
 	actionTimeout time.Duration
 
+	// sharedClients, when set, keeps the connections to the vtctld servers
+	// open across commands, by server, instead of each command connecting on
+	// its own. The shell sets it, so that its commands reuse a connection.
+	sharedClients map[string]vtctldclient.VtctldClient
+
 	// Root is the main entrypoint to the vtctldclient CLI.
 	Root = &cobra.Command{
 		Use:   "vtctldclient",
@@ -47,6 +52,11 @@ var (
 		// We use PersistentPreRun to set up the tracer, grpc client, and
 		// command context for every command.
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			// The shell completion requests create their own clients, see
+			// completeArgs.
+			if isCompletionRequest(cmd) {
+				return nil
+			}
 			traceCloser = trace.StartTracing("vtctldclient")
 			if VtctldClientProtocol != "local" {
 				if err := ensureServerArg(); err != nil {
@@ -54,7 +64,7 @@ var (
 				}
 			}
 
-			client, err = vtctldclient.New(VtctldClientProtocol, server)
+			client, err = newClient()
 
 			ctx := cmd.Context()
 			if ctx == nil {
@@ -66,8 +76,11 @@ var (
 		// Similarly, PersistentPostRun cleans up the resources spawned by
 		// PersistentPreRun.
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if isCompletionRequest(cmd) {
+				return nil
+			}
 			commandCancel()
-			err := client.Close()
+			err := closeClient(client)
 			trace.LogErrorsWhenClosing(traceCloser)
 			return err
 		},
@@ -94,6 +107,37 @@ func ensureServerArg() error {
 	return nil
 }
 
+// newClient returns a client of the vtctld server, which is shared with the
+// other commands if sharedClients is set.
+func newClient() (vtctldclient.VtctldClient, error) {
+	if sharedClients == nil {
+		return vtctldclient.New(VtctldClientProtocol, server)
+	}
+	if c, ok := sharedClients[server]; ok {
+		return c, nil
+	}
+	c, err := vtctldclient.New(VtctldClientProtocol, server)
+	if err != nil {
+		return nil, err
+	}
+	sharedClients[server] = c
+	return c, nil
+}
+
+// closeClient closes a client returned by newClient, unless it is shared.
+func closeClient(c vtctldclient.VtctldClient) error {
+	if sharedClients != nil {
+		return nil
+	}
+	return c.Close()
+}
+
+// isCompletionRequest returns whether the command is the hidden command that
+// the shell completion scripts run to complete a command line.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd
+}
+
 func init() {
 	Root.PersistentFlags().StringVar(&server, "server", "", "server to use for connection (required)")
 	Root.PersistentFlags().DurationVar(&actionTimeout, "action_timeout", time.Hour, "timeout for the total command")
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetRuntimeFlags makes a GetRuntimeFlags gRPC call to a vtctld.
+	GetRuntimeFlags = &cobra.Command{
+		Use:   "GetRuntimeFlags {--tablet <alias> | --address <host:port>}",
+		Short: "Lists the flags of a vttablet or vtgate that can be changed while it runs, with their last changes.",
+		Long: `Lists the flags of a vttablet or vtgate that can be changed while it runs, with
+their last changes.
+
+The vttablet or vtgate must run the runtimeflags gRPC service, e.g. with
+--service_map grpc-runtimeflags. A vtgate is given by the address of its gRPC
+server.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetRuntimeFlags,
+	}
+	// SetRuntimeFlag makes a SetRuntimeFlag gRPC call to a vtctld.
+	SetRuntimeFlag = &cobra.Command{
+		Use:   "SetRuntimeFlag {--tablet <alias> | --address <host:port>} <flag> <value>",
+		Short: "Changes a flag of a vttablet or vtgate while it runs, without a restart.",
+		Long: `Changes a flag of a vttablet or vtgate while it runs, without a restart.
+
+Only the flags listed by GetRuntimeFlags can be changed, e.g. the pool sizes and
+the timeouts of vttablet. The change is applied right away, logged, and kept in
+the audit log of the process, but it is lost when the process restarts: update
+the flags of the process too to keep it.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandSetRuntimeFlag,
+	}
+)
+
+// runtimeFlagsTarget holds the flags selecting the process of the runtime flag
+// commands.
+type runtimeFlagsTarget struct {
+	Tablet  string
+	Address string
+}
+
+func (target *runtimeFlagsTarget) parse() (alias *topodatapb.TabletAlias, address string, err error) {
+	switch {
+	case target.Tablet != "" && target.Address != "":
+		return nil, "", fmt.Errorf("cannot set both --tablet and --address")
+	case target.Tablet != "":
+		alias, err = topoproto.ParseTabletAlias(target.Tablet)
+		return alias, "", err
+	case target.Address != "":
+		return nil, target.Address, nil
+	default:
+		return nil, "", fmt.Errorf("must set --tablet or --address")
+	}
+}
+
+func (target *runtimeFlagsTarget) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&target.Tablet, "tablet", "", "The alias of the tablet.")
+	cmd.Flags().StringVar(&target.Address, "address", "", "The gRPC address (host:port) of the vtgate or vttablet.")
+}
+
+var (
+	getRuntimeFlagsOptions runtimeFlagsTarget
+	setRuntimeFlagOptions  runtimeFlagsTarget
+)
+
+func commandGetRuntimeFlags(cmd *cobra.Command, args []string) error {
+	alias, address, err := getRuntimeFlagsOptions.parse()
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetRuntimeFlags(commandCtx, &vtctldatapb.GetRuntimeFlagsRequest{
+		TabletAlias: alias,
+		Address:     address,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func commandSetRuntimeFlag(cmd *cobra.Command, args []string) error {
+	alias, address, err := setRuntimeFlagOptions.parse()
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetRuntimeFlag(commandCtx, &vtctldatapb.SetRuntimeFlagRequest{
+		TabletAlias: alias,
+		Address:     address,
+		Name:        cmd.Flags().Arg(0),
+		Value:       cmd.Flags().Arg(1),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Change)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	getRuntimeFlagsOptions.addFlags(GetRuntimeFlags)
+	Root.AddCommand(GetRuntimeFlags)
+
+	setRuntimeFlagOptions.addFlags(SetRuntimeFlag)
+	Root.AddCommand(SetRuntimeFlag)
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,20 +30,41 @@ import (
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/wrangler"
 
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 var (
+	// AnalyzeIndexUsage makes an AnalyzeIndexUsage gRPC call to a vtctld.
+	AnalyzeIndexUsage = &cobra.Command{
+		Use:   "AnalyzeIndexUsage --keyspace <keyspace> [--max-selectivity <selectivity>] [--all]",
+		Short: "Reports the unused and low-selectivity indexes of a keyspace, from the index statistics of all its tablets.",
+		Long: `Reports the unused and low-selectivity indexes of a keyspace, from the index statistics of all its tablets.
+
+An index is unused if no tablet of the keyspace read rows through it since its mysqld started,
+according to performance_schema. An index is of low selectivity if it is not unique, and the
+ratio of its cardinality to the rows of its table, summed across the shard primaries, is under
+--max-selectivity.
+
+Only the unused and low-selectivity indexes are reported, unless --all is set. The tablets whose
+statistics could not be read are reported as a warning.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandAnalyzeIndexUsage,
+	}
 	// ApplySchema makes an ApplySchema gRPC call to a vtctld.
 	ApplySchema = &cobra.Command{
-		Use:   "ApplySchema [--allow-long-unavailability] [--ddl-strategy <strategy>] [--uuid <uuid> ...] [--migration-context <context>] [--wait-replicas-timeout <duration>] [--skip-preflight] [--caller-id <caller_id>] {--sql-file <file> | --sql <sql>} <keyspace>",
+		Use:   "ApplySchema [--allow-long-unavailability] [--ddl-strategy <strategy>] [--uuid <uuid> ...] [--migration-context <context>] [--wait-replicas-timeout <duration>] [--skip-preflight] [--caller-id <caller_id>] [--wait [--wait-for <status>] [--wait-timeout <duration>]] {--sql-file <file> | --sql <sql>} <keyspace>",
 		Short: "Applies the schema change to the specified keyspace on every primary, running in parallel on all shards. The changes are then propagated to replicas via replication.",
 		Long: `Applies the schema change to the specified keyspace on every primary, running in parallel on all shards. The changes are then propagated to replicas via replication.
 
@@ -48,6 +72,7 @@ If --allow-long-unavailability is set, schema changes affecting a large number o
 --ddl-strategy is used to instruct migrations via vreplication, gh-ost or pt-osc with optional parameters.
 --migration-context allows the user to specify a custom migration context for online DDL migrations.
 If --skip-preflight, SQL goes directly to shards without going through sanity checks.
+With --wait, the command returns only once the Online DDL migrations are running or complete, per --wait-for, on all the shards.
 
 The --uuid and --sql flags are repeatable, so they can be passed multiple times to build a list of values.
 For --uuid, this is used like "--uuid $first_uuid --uuid $second_uuid".
@@ -59,6 +84,20 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandApplySchema,
 	}
+	// GenerateDDL makes GetTablets and GetSchema gRPC calls to a vtctld, and
+	// optionally an ApplySchema gRPC call.
+	GenerateDDL = &cobra.Command{
+		Use:   "GenerateDDL {--from-keyspace <keyspace> | --from-file <file>} --to-keyspace <keyspace> [--submit] [--ddl-strategy <strategy>]",
+		Short: "Generates the DDL that converges the schema of a keyspace to the schema of another keyspace, or of a file.",
+		Long: `Generates the DDL that converges the schema of --to-keyspace to the schema of --from-keyspace, or to the
+CREATE TABLE and CREATE VIEW statements of --from-file, and prints it in the order in which it must be applied.
+
+The schema of a keyspace is read from the primary of one of its shards. If --submit is set, the DDL is applied
+to --to-keyspace with --ddl-strategy, and the UUIDs of the migrations are printed.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGenerateDDL,
+	}
 	// GetSchema makes a GetSchema gRPC call to a vtctld.
 	GetSchema = &cobra.Command{
 		Use:                   "GetSchema [--tables TABLES ...] [--exclude-tables EXCLUDE_TABLES ...] [{--table-names-only | --table-sizes-only}] [--include-views] alias",
@@ -67,6 +106,19 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetSchema,
 	}
+	// GetTableSizes makes a GetTableSizes gRPC call to a vtctld.
+	GetTableSizes = &cobra.Command{
+		Use:   "GetTableSizes [--tables TABLES ...] [--exclude-tables EXCLUDE_TABLES ...] <keyspace>",
+		Short: "Displays the approximate sizes and row counts of the tables of a keyspace, summed across its shards.",
+		Long: `Displays the approximate sizes and row counts of the tables of a keyspace, summed across its shards,
+and the sizes of the tables in every shard.
+
+The sizes are the estimates of the information_schema of the shard primaries, so no table is scanned.
+Every shard of the keyspace must have a primary.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetTableSizes,
+	}
 	// ReloadSchema makes a ReloadSchema gRPC call to a vtctld.
 	ReloadSchema = &cobra.Command{
 		Use:                   "ReloadSchema <tablet_alias>",
@@ -91,8 +143,76 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandReloadSchemaShard,
 	}
+	// RestoreDroppedTable makes a RestoreDroppedTable gRPC call to a vtctld.
+	RestoreDroppedTable = &cobra.Command{
+		Use:   "RestoreDroppedTable [--uuid <uuid>] <keyspace> <table>",
+		Short: "Restores a table that Online DDL dropped, while the table garbage collector still holds it.",
+		Long: `Restores a table that Online DDL dropped, while the table garbage collector still holds it.
+
+Online DDL drops a table by renaming it to a HOLD table, which the table garbage collector
+of the tablets purges once the table GC hold of the keyspace, or --retain_online_ddl_tables,
+has passed. Until then, RestoreDroppedTable renames the HOLD table back on the primary of
+every shard. Tables dropped with the direct strategy can't be restored.
+
+By default, the table is restored from its last drop. --uuid restores it from the drop of a
+given migration. The table is restored either on every shard or on none, unless a rename
+fails part way.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandRestoreDroppedTable,
+	}
 )
 
+var analyzeIndexUsageOptions = struct {
+	Keyspace       string
+	MaxSelectivity float64
+	All            bool
+}{}
+
+func commandAnalyzeIndexUsage(cmd *cobra.Command, args []string) error {
+	if analyzeIndexUsageOptions.MaxSelectivity <= 0 || analyzeIndexUsageOptions.MaxSelectivity > 1 {
+		return fmt.Errorf("--max-selectivity must be between 0 and 1, got %v", analyzeIndexUsageOptions.MaxSelectivity)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.AnalyzeIndexUsage(commandCtx, &vtctldatapb.AnalyzeIndexUsageRequest{
+		Keyspace:       analyzeIndexUsageOptions.Keyspace,
+		MaxSelectivity: analyzeIndexUsageOptions.MaxSelectivity,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !analyzeIndexUsageOptions.All {
+		indexes := resp.Indexes[:0]
+		for _, index := range resp.Indexes {
+			if index.Unused || index.LowSelectivity {
+				indexes = append(indexes, index)
+			}
+		}
+		resp.Indexes = indexes
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	if len(resp.Errors) > 0 {
+		aliases := make([]string, 0, len(resp.Errors))
+		for alias := range resp.Errors {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		fmt.Fprintf(os.Stderr, "WARNING: the index statistics of these tablets could not be read, the report is partial: %s\n", strings.Join(aliases, ", "))
+	}
+
+	return nil
+}
+
 var applySchemaOptions = struct {
 	AllowLongUnavailability bool
 	SQL                     []string
@@ -103,6 +223,8 @@ var applySchemaOptions = struct {
 	WaitReplicasTimeout     time.Duration
 	SkipPreflight           bool
 	CallerID                string
+	WaitFor                 string
+	cli.WaitOptions
 }{}
 
 func commandApplySchema(cmd *cobra.Command, args []string) error {
@@ -126,6 +248,11 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	switch schema.OnlineDDLStatus(applySchemaOptions.WaitFor) {
+	case schema.OnlineDDLStatusRunning, schema.OnlineDDLStatusComplete:
+	default:
+		return fmt.Errorf("invalid --wait-for status %q, expected running or complete", applySchemaOptions.WaitFor)
+	}
 
 	cli.FinishedParsing(cmd)
 
@@ -151,10 +278,186 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	fmt.Println(strings.Join(resp.UuidList, "\n"))
+	if !applySchemaOptions.Wait || len(resp.UuidList) == 0 {
+		return nil
+	}
+	return waitForMigrations(ks, resp.UuidList, schema.OnlineDDLStatus(applySchemaOptions.WaitFor))
+}
+
+// waitForMigrations waits for the Online DDL migrations of a keyspace to
+// reach a status on the primary tablets of all its shards. It fails as soon
+// as a migration fails or is cancelled on a shard.
+func waitForMigrations(keyspace string, uuids []string, status schema.OnlineDDLStatus) error {
+	quoted := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		quoted[i] = sqltypes.EncodeStringSQL(uuid)
+	}
+	query := fmt.Sprintf("select migration_uuid, migration_status, progress, message from _vt.schema_migrations where migration_uuid in (%s)", strings.Join(quoted, ", "))
+
+	return cli.Wait(commandCtx, applySchemaOptions.WaitOptions, os.Stderr, func(ctx context.Context) (bool, string, error) {
+		resp, err := client.GetTablets(ctx, &vtctldatapb.GetTabletsRequest{
+			Keyspace:   keyspace,
+			TabletType: topodatapb.TabletType_PRIMARY,
+		})
+		if err != nil {
+			return false, "", err
+		}
+		if len(resp.Tablets) == 0 {
+			return false, "", fmt.Errorf("no primary tablet in keyspace %s", keyspace)
+		}
+
+		statuses := map[schema.OnlineDDLStatus]int{}
+		var progress []string
+		for _, tablet := range resp.Tablets {
+			qrResp, err := client.ExecuteFetchAsDBA(ctx, &vtctldatapb.ExecuteFetchAsDBARequest{
+				TabletAlias: tablet.Alias,
+				Query:       query,
+				MaxRows:     int64(len(uuids)),
+			})
+			if err != nil {
+				return false, "", err
+			}
+			qr := sqltypes.Proto3ToResult(qrResp.Result)
+			// A migration that is not in the table yet is still requested.
+			statuses[schema.OnlineDDLStatusRequested] += len(uuids) - len(qr.Rows)
+			for _, row := range qr.Named().Rows {
+				uuid, rowStatus := row.AsString("migration_uuid", ""), schema.OnlineDDLStatus(row.AsString("migration_status", ""))
+				switch rowStatus {
+				case schema.OnlineDDLStatusFailed, schema.OnlineDDLStatusCancelled:
+					return false, "", fmt.Errorf("migration %s is %s on shard %s: %s", uuid, rowStatus, tablet.Shard, row.AsString("message", ""))
+				case schema.OnlineDDLStatusRunning:
+					progress = append(progress, fmt.Sprintf("%s on shard %s: %s%%", uuid, tablet.Shard, row.AsString("progress", "0")))
+				}
+				statuses[rowStatus]++
+			}
+		}
+
+		var done int
+		switch status {
+		case schema.OnlineDDLStatusRunning:
+			done = statuses[schema.OnlineDDLStatusRunning] + statuses[schema.OnlineDDLStatusComplete]
+		default:
+			done = statuses[schema.OnlineDDLStatusComplete]
+		}
+		var counts []string
+		for rowStatus, count := range statuses {
+			if count > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", count, rowStatus))
+			}
+		}
+		sort.Strings(counts)
+		summary := fmt.Sprintf("migrations on %d shards: %s", len(resp.Tablets), strings.Join(counts, ", "))
+		if len(progress) > 0 {
+			sort.Strings(progress)
+			summary += " (progress of the running ones: " + strings.Join(progress, ", ") + ")"
+		}
+		return done == len(uuids)*len(resp.Tablets), summary, nil
+	})
+}
+
+var generateDDLOptions = struct {
+	FromKeyspace string
+	FromFile     string
+	ToKeyspace   string
+	Submit       bool
+	DDLStrategy  string
+}{}
+
+func commandGenerateDDL(cmd *cobra.Command, args []string) error {
+	if (generateDDLOptions.FromKeyspace == "") == (generateDDLOptions.FromFile == "") {
+		return errors.New("exactly one of --from-keyspace and --from-file must be specified")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	var (
+		desired *schemadiff.Schema
+		err     error
+	)
+	if generateDDLOptions.FromFile != "" {
+		data, err := ioutil.ReadFile(generateDDLOptions.FromFile)
+		if err != nil {
+			return err
+		}
+
+		desired, err = schemadiff.NewSchemaFromSQL(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid schema in %s: %w", generateDDLOptions.FromFile, err)
+		}
+	} else {
+		desired, err = getKeyspaceSchema(generateDDLOptions.FromKeyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	current, err := getKeyspaceSchema(generateDDLOptions.ToKeyspace)
+	if err != nil {
+		return err
+	}
+
+	ddls, err := schematools.GenerateDDL(current, desired)
+	if err != nil {
+		return err
+	}
+
+	for _, ddl := range ddls {
+		fmt.Printf("%s;\n", ddl)
+	}
+
+	if !generateDDLOptions.Submit || len(ddls) == 0 {
+		return nil
+	}
+
+	resp, err := client.ApplySchema(commandCtx, &vtctldatapb.ApplySchemaRequest{
+		Keyspace:    generateDDLOptions.ToKeyspace,
+		Sql:         ddls,
+		DdlStrategy: generateDDLOptions.DDLStrategy,
+	})
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(strings.Join(resp.UuidList, "\n"))
 	return nil
 }
 
+// getKeyspaceSchema returns the schema of the primary of one of the shards of a
+// keyspace.
+func getKeyspaceSchema(keyspace string) (*schemadiff.Schema, error) {
+	tablets, err := client.GetTablets(commandCtx, &vtctldatapb.GetTabletsRequest{
+		Keyspace:   keyspace,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(tablets.Tablets) == 0 {
+		return nil, fmt.Errorf("keyspace %s has no primary tablet to read its schema from", keyspace)
+	}
+
+	sort.Slice(tablets.Tablets, func(i, j int) bool {
+		return tablets.Tablets[i].Shard < tablets.Tablets[j].Shard
+	})
+
+	resp, err := client.GetSchema(commandCtx, &vtctldatapb.GetSchemaRequest{
+		TabletAlias:     tablets.Tablets[0].Alias,
+		IncludeViews:    true,
+		TableSchemaOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keyspaceSchema, err := schematools.ToSchemadiffSchema(resp.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the schema of keyspace %s: %w", keyspace, err)
+	}
+
+	return keyspaceSchema, nil
+}
+
 var getSchemaOptions = struct {
 	Tables          []string
 	ExcludeTables   []string
@@ -211,6 +514,33 @@ func commandGetSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var getTableSizesOptions = struct {
+	Tables        []string
+	ExcludeTables []string
+}{}
+
+func commandGetTableSizes(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetTableSizes(commandCtx, &vtctldatapb.GetTableSizesRequest{
+		Keyspace:      cmd.Flags().Arg(0),
+		Tables:        getTableSizesOptions.Tables,
+		ExcludeTables: getTableSizesOptions.ExcludeTables,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandReloadSchema(cmd *cobra.Command, args []string) error {
 	tabletAlias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -285,7 +615,39 @@ func commandReloadSchemaShard(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+var restoreDroppedTableOptions = struct {
+	UUID string
+}{}
+
+func commandRestoreDroppedTable(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RestoreDroppedTable(commandCtx, &vtctldatapb.RestoreDroppedTableRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Table:    cmd.Flags().Arg(1),
+		Uuid:     restoreDroppedTableOptions.UUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func init() {
+	AnalyzeIndexUsage.Flags().StringVarP(&analyzeIndexUsageOptions.Keyspace, "keyspace", "k", "", "The keyspace whose indexes to analyze.")
+	AnalyzeIndexUsage.MarkFlagRequired("keyspace")
+	AnalyzeIndexUsage.Flags().Float64Var(&analyzeIndexUsageOptions.MaxSelectivity, "max-selectivity", 0.01, "The selectivity under which a non-unique index is reported as a low-selectivity index.")
+	AnalyzeIndexUsage.Flags().BoolVar(&analyzeIndexUsageOptions.All, "all", false, "Report all the indexes of the keyspace, not only the unused and low-selectivity ones.")
+	Root.AddCommand(AnalyzeIndexUsage)
+
 	ApplySchema.Flags().BoolVar(&applySchemaOptions.AllowLongUnavailability, "allow-long-unavailability", false, "Allow large schema changes which incur a longer unavailability of the database.")
 	ApplySchema.Flags().StringVar(&applySchemaOptions.DDLStrategy, "ddl-strategy", string(schema.DDLStrategyDirect), "Online DDL strategy, compatible with @@ddl_strategy session variable (examples: 'gh-ost', 'pt-osc', 'gh-ost --max-load=Threads_running=100'.")
 	ApplySchema.Flags().StringSliceVar(&applySchemaOptions.UUIDList, "uuid", nil, "Optional, comma-delimited, repeatable, explicit UUIDs for migration. If given, must match number of DDL changes.")
@@ -295,9 +657,20 @@ func init() {
 	ApplySchema.Flags().StringVar(&applySchemaOptions.CallerID, "caller-id", "", "Effective caller ID used for the operation and should map to an ACL name which grants this identity the necessary permissions to perform the operation (this is only necessary when strict table ACLs are used).")
 	ApplySchema.Flags().StringSliceVar(&applySchemaOptions.SQL, "sql", nil, "Semicolon-delimited, repeatable SQL commands to apply. Exactly one of --sql|--sql-file is required.")
 	ApplySchema.Flags().StringVar(&applySchemaOptions.SQLFile, "sql-file", "", "Path to a file containing semicolon-delimited SQL commands to apply. Exactly one of --sql|--sql-file is required.")
+	cli.AddWaitFlags(ApplySchema, &applySchemaOptions.WaitOptions, "the Online DDL migrations reach the --wait-for status on all the shards")
+	ApplySchema.Flags().StringVar(&applySchemaOptions.WaitFor, "wait-for", string(schema.OnlineDDLStatusComplete), "The status of the Online DDL migrations that --wait waits for: running or complete. A migration that fails or is cancelled fails the command.")
 
 	Root.AddCommand(ApplySchema)
 
+	GenerateDDL.Flags().StringVar(&generateDDLOptions.FromKeyspace, "from-keyspace", "", "The keyspace whose schema to converge to. Exactly one of --from-keyspace|--from-file is required.")
+	GenerateDDL.Flags().StringVar(&generateDDLOptions.FromFile, "from-file", "", "Path to a file containing the CREATE TABLE and CREATE VIEW statements of the schema to converge to. Exactly one of --from-keyspace|--from-file is required.")
+	GenerateDDL.Flags().StringVar(&generateDDLOptions.ToKeyspace, "to-keyspace", "", "The keyspace whose schema to converge.")
+	GenerateDDL.MarkFlagRequired("to-keyspace")
+	GenerateDDL.Flags().BoolVar(&generateDDLOptions.Submit, "submit", false, "Apply the generated DDL to --to-keyspace.")
+	GenerateDDL.Flags().StringVar(&generateDDLOptions.DDLStrategy, "ddl-strategy", string(schema.DDLStrategyVitess), "Online DDL strategy of the submitted DDL, compatible with @@ddl_strategy session variable.")
+
+	Root.AddCommand(GenerateDDL)
+
 	GetSchema.Flags().StringSliceVar(&getSchemaOptions.Tables, "tables", nil, "List of tables to display the schema for. Each is either an exact match, or a regular expression of the form `/regexp/`.")
 	GetSchema.Flags().StringSliceVar(&getSchemaOptions.ExcludeTables, "exclude-tables", nil, "List of tables to exclude from the result. Each is either an exact match, or a regular expression of the form `/regexp/`.")
 	GetSchema.Flags().BoolVar(&getSchemaOptions.IncludeViews, "include-views", false, "Includes views in the output in addition to base tables.")
@@ -307,6 +680,10 @@ func init() {
 
 	Root.AddCommand(GetSchema)
 
+	GetTableSizes.Flags().StringSliceVar(&getTableSizesOptions.Tables, "tables", nil, "List of tables to display the sizes of. Each is either an exact match, or a regular expression of the form `/regexp/`.")
+	GetTableSizes.Flags().StringSliceVar(&getTableSizesOptions.ExcludeTables, "exclude-tables", nil, "List of tables to exclude from the result. Each is either an exact match, or a regular expression of the form `/regexp/`.")
+	Root.AddCommand(GetTableSizes)
+
 	Root.AddCommand(ReloadSchema)
 
 	ReloadSchemaKeyspace.Flags().Uint32Var(&reloadSchemaKeyspaceOptions.Concurrency, "concurrency", 10, "Number of tablets to reload in parallel. Set to zero for unbounded concurrency.")
@@ -316,4 +693,7 @@ func init() {
 	ReloadSchemaShard.Flags().Uint32Var(&reloadSchemaShardOptions.Concurrency, "concurrency", 10, "Number of tablets to reload in parallel. Set to zero for unbounded concurrency.")
 	ReloadSchemaShard.Flags().BoolVar(&reloadSchemaShardOptions.IncludePrimary, "include-primary", false, "Also reload the primary tablet.")
 	Root.AddCommand(ReloadSchemaShard)
+
+	RestoreDroppedTable.Flags().StringVar(&restoreDroppedTableOptions.UUID, "uuid", "", "The UUID of the Online DDL migration that dropped the table. Defaults to the last migration that dropped it.")
+	Root.AddCommand(RestoreDroppedTable)
 }
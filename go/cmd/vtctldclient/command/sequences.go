@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetSequence reads the state of a sequence table on every shard primary.
+	GetSequence = &cobra.Command{
+		Use:                   "GetSequence <keyspace> <sequence_table>",
+		Short:                 "Shows the next value, block size and remaining values of a sequence table on each shard primary.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandGetSequence,
+	}
+	// UpdateSequence updates a sequence table on every shard primary.
+	UpdateSequence = &cobra.Command{
+		Use:   "UpdateSequence [--cache <block_size>] [--next-value <value> | --increment <count>] <keyspace> <sequence_table>",
+		Short: "Changes the block size of a sequence table, or bumps its next value, on each shard primary.",
+		Long: `Changes the block size of a sequence table, or bumps its next value, on each shard primary.
+
+--cache sets the number of values a tablet allocates from the sequence table at
+a time. --next-value moves the next value forward; it never moves it backwards,
+so values that were already handed out are not reused. --increment moves the
+next value forward by the given number of values.
+
+Each shard primary updates the sequence table in a transaction that locks its
+row, so the update cannot race with the tablets allocating values from it.
+
+Tablets keep serving values from the block they have already cached; the new
+settings take effect when that block is exhausted.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandUpdateSequence,
+	}
+)
+
+// sequenceWarnThreshold is the fraction of the int64 range past which a
+// sequence is reported as close to exhaustion.
+const sequenceWarnThreshold = 0.9
+
+type sequenceState struct {
+	Shard     string `json:"shard"`
+	Tablet    string `json:"tablet"`
+	NextID    int64  `json:"next_id"`
+	Cache     int64  `json:"cache"`
+	Remaining int64  `json:"remaining"`
+}
+
+func commandGetSequence(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	table := sqlparser.String(sqlparser.NewIdentifierCS(cmd.Flags().Arg(1)))
+
+	cli.FinishedParsing(cmd)
+
+	states, err := readSequence(keyspace, table)
+	if err != nil {
+		return err
+	}
+
+	return printSequence(table, states)
+}
+
+var updateSequenceOptions = struct {
+	Cache     int64
+	NextValue int64
+	Increment int64
+}{}
+
+func commandUpdateSequence(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	table := cmd.Flags().Arg(1)
+
+	if cmd.Flags().Changed("cache") && updateSequenceOptions.Cache < 1 {
+		return fmt.Errorf("--cache must be positive, got %d", updateSequenceOptions.Cache)
+	}
+	if cmd.Flags().Changed("next-value") && updateSequenceOptions.NextValue < 1 {
+		return fmt.Errorf("--next-value must be positive, got %d", updateSequenceOptions.NextValue)
+	}
+	if cmd.Flags().Changed("increment") && updateSequenceOptions.Increment < 1 {
+		return fmt.Errorf("--increment must be positive, got %d", updateSequenceOptions.Increment)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.UpdateSequence(commandCtx, &vtctldatapb.UpdateSequenceRequest{
+		Keyspace:  keyspace,
+		Table:     table,
+		Cache:     updateSequenceOptions.Cache,
+		NextValue: updateSequenceOptions.NextValue,
+		Increment: updateSequenceOptions.Increment,
+	})
+	if err != nil {
+		return err
+	}
+
+	states := make([]*sequenceState, 0, len(resp.States))
+	for _, state := range resp.States {
+		states = append(states, &sequenceState{
+			Shard:     state.Shard,
+			Tablet:    topoproto.TabletAliasString(state.TabletAlias),
+			NextID:    state.NextId,
+			Cache:     state.Cache,
+			Remaining: math.MaxInt64 - state.NextId,
+		})
+	}
+
+	return printSequence(sqlparser.String(sqlparser.NewIdentifierCS(table)), states)
+}
+
+func readSequence(keyspace string, table string) ([]*sequenceState, error) {
+	primaries, err := shardPrimaries(keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("select next_id, cache from %s where id = 0", table)
+	states := make([]*sequenceState, 0, len(primaries))
+	for _, shard := range sortedShardNames(primaries) {
+		resp, err := client.ExecuteFetchAsDBA(commandCtx, &vtctldatapb.ExecuteFetchAsDBARequest{
+			TabletAlias: primaries[shard],
+			Query:       query,
+			MaxRows:     1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sequence %s on %s/%s: %w", table, keyspace, shard, err)
+		}
+
+		qr := sqltypes.Proto3ToResult(resp.Result)
+		if len(qr.Rows) != 1 {
+			return nil, fmt.Errorf("unexpected rows reading sequence %s on %s/%s: %d", table, keyspace, shard, len(qr.Rows))
+		}
+
+		nextID, err := qr.Rows[0][0].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		cache, err := qr.Rows[0][1].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+
+		states = append(states, &sequenceState{
+			Shard:     shard,
+			Tablet:    topoproto.TabletAliasString(primaries[shard]),
+			NextID:    nextID,
+			Cache:     cache,
+			Remaining: math.MaxInt64 - nextID,
+		})
+	}
+
+	return states, nil
+}
+
+func printSequence(table string, states []*sequenceState) error {
+	data, err := cli.MarshalJSON(states)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	for _, state := range states {
+		if float64(state.NextID) >= sequenceWarnThreshold*math.MaxInt64 {
+			fmt.Printf("WARNING: sequence %s on shard %s is approaching exhaustion: %d values remaining\n", table, state.Shard, state.Remaining)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(GetSequence)
+
+	UpdateSequence.Flags().Int64Var(&updateSequenceOptions.Cache, "cache", 0, "Number of values tablets allocate from the sequence table at a time.")
+	UpdateSequence.Flags().Int64Var(&updateSequenceOptions.NextValue, "next-value", 0, "Moves the next value of the sequence forward to at least this value.")
+	UpdateSequence.Flags().Int64Var(&updateSequenceOptions.Increment, "increment", 0, "Moves the next value of the sequence forward by this many values.")
+	Root.AddCommand(UpdateSequence)
+}
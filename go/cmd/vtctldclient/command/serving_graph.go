@@ -18,10 +18,12 @@ package command
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
@@ -43,6 +45,19 @@ var (
 		Args:                  cobra.ArbitraryArgs,
 		RunE:                  commandGetSrvKeyspaceNames,
 	}
+	// GetSrvKeyspacePartitions makes a GetSrvKeyspacePartitions gRPC call to a vtctld.
+	GetSrvKeyspacePartitions = &cobra.Command{
+		Use:   "GetSrvKeyspacePartitions <keyspace> [<cell> ...]",
+		Short: "Outputs a JSON mapping of cell=>served-type partitions of the SrvKeyspace of the given keyspace, and the problems found in them. Omit cells to query all cells.",
+		Long: `Outputs a JSON mapping of cell=>served-type partitions of the SrvKeyspace of the given keyspace, and the problems found in them. Omit cells to query all cells.
+
+A partition has problems if the key ranges of its shards do not cover the whole key space or overlap,
+or if its shards do not exist or have other key ranges in their shard records. The command fails if
+any partition has problems; they can be repaired with SetSrvKeyspacePartition.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandGetSrvKeyspacePartitions,
+	}
 	// GetSrvKeyspaces makes a GetSrvKeyspaces gRPC call to a vtctld.
 	GetSrvKeyspaces = &cobra.Command{
 		Use:                   "GetSrvKeyspaces <keyspace> [<cell> ...]",
@@ -83,6 +98,21 @@ var (
 		Args:                  cobra.NoArgs,
 		RunE:                  commandRebuildVSchemaGraph,
 	}
+	// SetSrvKeyspacePartition makes a SetSrvKeyspacePartition gRPC call to a vtctld.
+	SetSrvKeyspacePartition = &cobra.Command{
+		Use:   "SetSrvKeyspacePartition [--cells=c1,c2,...] [--dry-run] <keyspace> <tablet_type> <shard> [<shard> ...]",
+		Short: "Replaces the shards of the partition of the given served type in the SrvKeyspaces of the given keyspace.",
+		Long: `Replaces the shards of the partition of the given served type in the SrvKeyspaces of the given keyspace.
+
+The key ranges of the shards, read from their shard records, must cover the whole key space without
+overlapping. The SrvKeyspaces of all the cells that have one are updated, unless --cells is specified.
+
+This is meant to repair a corrupted serving graph. The shard records are not changed, so the next
+RebuildKeyspaceGraph rebuilds the partitions from them.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(3),
+		RunE:                  commandSetSrvKeyspacePartition,
+	}
 )
 
 func commandDeleteSrvVSchema(cmd *cobra.Command, args []string) error {
@@ -116,6 +146,36 @@ func commandGetSrvKeyspaceNames(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetSrvKeyspacePartitions(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	keyspace := cmd.Flags().Arg(0)
+	cells := cmd.Flags().Args()[1:]
+
+	resp, err := client.GetSrvKeyspacePartitions(commandCtx, &vtctldatapb.GetSrvKeyspacePartitionsRequest{
+		Keyspace: keyspace,
+		Cells:    cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Partitions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	for cell, partitions := range resp.Partitions {
+		if len(partitions.Errors) > 0 {
+			return fmt.Errorf("found problems in the partitions of the SrvKeyspace of %s in cell %s: %s", keyspace, cell, strings.Join(partitions.Errors, "; "))
+		}
+	}
+
+	return nil
+}
+
 func commandGetSrvKeyspaces(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -231,10 +291,48 @@ func commandRebuildVSchemaGraph(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var setSrvKeyspacePartitionOptions = struct {
+	Cells  []string
+	DryRun bool
+}{}
+
+func commandSetSrvKeyspacePartition(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	tabletType, err := topoproto.ParseTabletType(cmd.Flags().Arg(1))
+	if err != nil {
+		return err
+	}
+
+	shards := cmd.Flags().Args()[2:]
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetSrvKeyspacePartition(commandCtx, &vtctldatapb.SetSrvKeyspacePartitionRequest{
+		Keyspace:   keyspace,
+		Cells:      setSrvKeyspacePartitionOptions.Cells,
+		TabletType: tabletType,
+		Shards:     shards,
+		DryRun:     setSrvKeyspacePartitionOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.SrvKeyspaces)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func init() {
 	Root.AddCommand(DeleteSrvVSchema)
 
 	Root.AddCommand(GetSrvKeyspaceNames)
+	Root.AddCommand(GetSrvKeyspacePartitions)
 	Root.AddCommand(GetSrvKeyspaces)
 	Root.AddCommand(GetSrvVSchema)
 	Root.AddCommand(GetSrvVSchemas)
@@ -245,4 +343,8 @@ func init() {
 
 	RebuildVSchemaGraph.Flags().StringSliceVarP(&rebuildVSchemaGraphOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to look for tablets.")
 	Root.AddCommand(RebuildVSchemaGraph)
+
+	SetSrvKeyspacePartition.Flags().StringSliceVarP(&setSrvKeyspacePartitionOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update. Defaults to all the cells that have a SrvKeyspace for the keyspace.")
+	SetSrvKeyspacePartition.Flags().BoolVar(&setSrvKeyspacePartitionOptions.DryRun, "dry-run", false, "Shows the updated SrvKeyspaces without saving them.")
+	Root.AddCommand(SetSrvKeyspacePartition)
 }
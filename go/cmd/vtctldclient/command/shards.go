@@ -18,11 +18,14 @@ package command
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
@@ -77,6 +80,23 @@ that shard.`,
 		Args:                  cobra.ExactArgs(2),
 		RunE:                  commandSetShardIsPrimaryServing,
 	}
+	// SetShardReadOnly makes a SetShardReadOnly gRPC call to a vtctld.
+	SetShardReadOnly = &cobra.Command{
+		Use:   "SetShardReadOnly [--duration <duration>] [--undo] <keyspace/shard>",
+		Short: "Makes the primary of a shard reject the queries that write, while it keeps executing the queries that read.",
+		Long: `Makes the primary of a shard reject the queries that write, while it keeps executing
+the queries that read, for use during incident containment and before cutovers.
+
+The writes fail with a FAILED_PRECONDITION error, and MySQL error 1874 (ER_INNODB_READ_ONLY)
+through vtgate. They are rejected for --duration, or until the shard is made writable again
+with --undo if it is not set. The writes of vreplication and online DDL are not rejected.
+
+The primary does not persist it: a new primary of the shard, or the primary after it restarts,
+executes the queries that write.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetShardReadOnly,
+	}
 	// SetShardTabletControl makes a SetShardTabletControl gRPC call to a vtctld.
 	SetShardTabletControl = &cobra.Command{
 		Use:   "SetShardTabletControl [--cells=c1,c2...] [--denied-tables=t1,t2,...] [--remove] [--disable-query-service[=0|false]] <keyspace/shard> <tablet_type>",
@@ -308,6 +328,42 @@ func commandSetShardIsPrimaryServing(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var setShardReadOnlyOptions = struct {
+	Duration time.Duration
+	Undo     bool
+}{}
+
+func commandSetShardReadOnly(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return fmt.Errorf("cannot parse keyspace/shard: %w", err)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.SetShardReadOnlyRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+		Undo:     setShardReadOnlyOptions.Undo,
+	}
+	if setShardReadOnlyOptions.Duration != 0 {
+		req.Duration = protoutil.DurationToProto(setShardReadOnlyOptions.Duration)
+	}
+
+	resp, err := client.SetShardReadOnly(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 var setShardTabletControlOptions = struct {
 	Cells               []string
 	DeniedTables        []string
@@ -547,6 +603,37 @@ func commandSourceShardDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// shardPrimaries returns the primary tablet alias of each shard in the
+// keyspace, keyed by shard name.
+func shardPrimaries(keyspace string) (map[string]*topodatapb.TabletAlias, error) {
+	resp, err := client.FindAllShardsInKeyspace(commandCtx, &vtctldatapb.FindAllShardsInKeyspaceRequest{
+		Keyspace: keyspace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	primaries := make(map[string]*topodatapb.TabletAlias, len(resp.Shards))
+	for name, shard := range resp.Shards {
+		if shard.Shard == nil || shard.Shard.PrimaryAlias == nil {
+			return nil, fmt.Errorf("shard %s/%s has no primary", keyspace, name)
+		}
+		primaries[name] = shard.Shard.PrimaryAlias
+	}
+
+	return primaries, nil
+}
+
+func sortedShardNames(primaries map[string]*topodatapb.TabletAlias) []string {
+	names := make([]string, 0, len(primaries))
+	for name := range primaries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	CreateShard.Flags().BoolVarP(&createShardOptions.Force, "force", "f", false, "Overwrite an existing shard record, if one exists.")
 	CreateShard.Flags().BoolVarP(&createShardOptions.IncludeParent, "include-parent", "p", false, "Creates the parent keyspace record if does not already exist.")
@@ -565,6 +652,10 @@ func init() {
 
 	Root.AddCommand(SetShardIsPrimaryServing)
 
+	SetShardReadOnly.Flags().DurationVar(&setShardReadOnlyOptions.Duration, "duration", 0, "How long the shard is read-only. If not set, the shard is read-only until it is made writable again with --undo.")
+	SetShardReadOnly.Flags().BoolVar(&setShardReadOnlyOptions.Undo, "undo", false, "Makes the shard writable again.")
+	Root.AddCommand(SetShardReadOnly)
+
 	SetShardTabletControl.Flags().StringSliceVarP(&setShardTabletControlOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update.")
 	SetShardTabletControl.Flags().StringSliceVar(&setShardTabletControlOptions.DeniedTables, "denied-tables", nil, "Specifies a comma-separated list of tables to add to the denylist (for MoveTables). Each table name is either an exact match, or a regular expression of the form '/regexp/'.")
 	SetShardTabletControl.Flags().BoolVarP(&setShardTabletControlOptions.Remove, "remove", "r", false, "Removes the specified cells for MoveTables operations.")
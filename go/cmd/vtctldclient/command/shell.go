@@ -0,0 +1,381 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+)
+
+// Shell runs vtctldclient commands interactively.
+var Shell = &cobra.Command{
+	Use:   "shell [--history-file <file>]",
+	Short: "Runs vtctldclient commands read from the terminal, one per line, against the vtctld server.",
+	Long: `Runs vtctldclient commands read from the terminal, one per line, against the vtctld server.
+
+Each line is a vtctldclient command line without the "vtctldclient" prefix, e.g.
+"GetTablets --keyspace commerce". The flags given to the shell, like --server,
+apply to all the commands, and the flags given on a line only apply to its
+command.
+
+The commands share a connection to each vtctld server. The tab key completes
+the commands, their flags, and the keyspaces, shards and tablet aliases given
+as arguments, fetched from the vtctld server.
+
+The up and down arrows recall the previous lines, which are also appended to
+the history file. Besides the vtctldclient commands, the shell understands:
+
+	help [<command>]  outputs the list of commands, or the help of a command
+	history           outputs the numbered lines of the history file
+	!<n>              runs line n of the history again
+	exit, quit        leaves the shell, like Ctrl-D
+
+When the standard input is not a terminal, the commands are read from it
+without prompt, e.g. to run a script of commands.`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	// The commands of the shell connect to vtctld on their own, see
+	// sharedClients.
+	PersistentPreRunE:  func(cmd *cobra.Command, args []string) error { return nil },
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:               commandShell,
+}
+
+var shellOptions = struct {
+	HistoryFile string
+}{}
+
+const shellPrompt = "vtctldclient> "
+
+func commandShell(cmd *cobra.Command, args []string) error {
+	if err := ensureServerArg(); err != nil && VtctldClientProtocol != "local" {
+		return err
+	}
+	cli.FinishedParsing(cmd)
+
+	sharedClients = map[string]vtctldclient.VtctldClient{}
+	defer func() {
+		for _, c := range sharedClients {
+			c.Close()
+		}
+		sharedClients = nil
+	}()
+
+	sh := &shell{
+		historyFile: shellOptions.HistoryFile,
+		flags:       snapshotFlags(Root),
+	}
+	if history, err := readShellHistory(sh.historyFile); err == nil {
+		sh.history = history
+	} else if !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "cannot read the history file: %v\n", err)
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return sh.runScript(os.Stdin)
+	}
+	return sh.runTerminal(fd)
+}
+
+// shell reads vtctldclient command lines and runs them with the root
+// command, restoring the flags of all the commands between two lines.
+type shell struct {
+	historyFile string
+	history     []string
+	flags       map[*pflag.Flag]string
+}
+
+func (sh *shell) runScript(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if done := sh.runLine(scanner.Text(), os.Stdout); done {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func (sh *shell) runTerminal(fd int) error {
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return sh.complete(line, pos, terminal)
+	}
+	for {
+		// The terminal is only in raw mode while reading a line, so that the
+		// output of the commands is not altered.
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		if width, height, err := term.GetSize(fd); err == nil {
+			_ = terminal.SetSize(width, height)
+		}
+		line, err := terminal.ReadLine()
+		_ = term.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if done := sh.runLine(line, os.Stdout); done {
+			return nil
+		}
+	}
+}
+
+// runLine runs a line of the shell, and returns whether the shell is done.
+func (sh *shell) runLine(line string, w io.Writer) (done bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return false
+	}
+	if strings.HasPrefix(line, "!") {
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 1 || n > len(sh.history) {
+			fmt.Fprintf(w, "no line %s in the history\n", line[1:])
+			return false
+		}
+		line = sh.history[n-1]
+		fmt.Fprintln(w, line)
+	}
+	sh.addHistory(line)
+
+	args, err := shlex.Split(line)
+	if err != nil {
+		fmt.Fprintf(w, "cannot parse the line: %v\n", err)
+		return false
+	}
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	case "history":
+		for i, entry := range sh.history {
+			fmt.Fprintf(w, "%5d  %s\n", i+1, entry)
+		}
+		return false
+	case "shell", "completion":
+		fmt.Fprintf(w, "%s cannot run in the shell\n", args[0])
+		return false
+	}
+
+	defer sh.restoreFlags()
+	Root.SetArgs(args)
+	if err := Root.Execute(); err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+	}
+	return false
+}
+
+// complete completes the word before the cursor of a line, and outputs the
+// completions to w when they have no common prefix longer than the word.
+func (sh *shell) complete(line string, pos int, w io.Writer) (newLine string, newPos int, ok bool) {
+	before := line[:pos]
+	args := strings.Fields(before)
+	if len(args) == 0 || strings.HasSuffix(before, " ") {
+		args = append(args, "")
+	}
+	word := args[len(args)-1]
+	completions, directive := sh.completions(args)
+	if len(completions) == 0 || directive&cobra.ShellCompDirectiveError != 0 {
+		return line, pos, true
+	}
+
+	completion := completions[0]
+	if len(completions) == 1 {
+		if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+			completion += " "
+		}
+	} else {
+		for _, c := range completions[1:] {
+			for !strings.HasPrefix(c, completion) {
+				completion = completion[:len(completion)-1]
+			}
+		}
+		if len(completion) <= len(word) {
+			fmt.Fprintln(w, strings.Join(completions, "  "))
+			return line, pos, true
+		}
+	}
+	before = before[:len(before)-len(word)] + completion
+	return before + line[pos:], len(before), true
+}
+
+// completions returns the completions of the last argument of a line, with
+// the completion functions of the commands, and the directive of the
+// completion, see cobra.ShellCompRequestCmd.
+func (sh *shell) completions(args []string) ([]string, cobra.ShellCompDirective) {
+	var out bytes.Buffer
+	defer func() {
+		Root.SetOut(nil)
+		Root.SetErr(nil)
+		// The hidden completion command is added to the root command by
+		// each completion request.
+		for _, cmd := range Root.Commands() {
+			if cmd.Name() == cobra.ShellCompRequestCmd {
+				Root.RemoveCommand(cmd)
+			}
+		}
+		sh.restoreFlags()
+	}()
+	Root.SetOut(&out)
+	Root.SetErr(io.Discard)
+	Root.SetArgs(append([]string{cobra.ShellCompNoDescRequestCmd}, args...))
+	if err := Root.Execute(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// The completions are output one per line, followed by :<directive>.
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	directive, err := strconv.Atoi(strings.TrimPrefix(lines[len(lines)-1], ":"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return lines[:len(lines)-1], cobra.ShellCompDirective(directive)
+}
+
+func (sh *shell) addHistory(line string) {
+	sh.history = append(sh.history, line)
+	if sh.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(sh.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+func readShellHistory(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+// snapshotFlags returns the values of the flags of a command and of all its
+// subcommands, so that the flags given on a line of the shell do not leak
+// into the next lines.
+func snapshotFlags(cmd *cobra.Command) map[*pflag.Flag]string {
+	flags := map[*pflag.Flag]string{}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, flagSet := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.LocalNonPersistentFlags()} {
+			flagSet.VisitAll(func(f *pflag.Flag) {
+				flags[f] = f.Value.String()
+			})
+		}
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(cmd)
+	return flags
+}
+
+// restoreFlags restores the flags of all the commands to their values when
+// the shell started. The flags added since then, like the help flags, are
+// restored to their defaults.
+func (sh *shell) restoreFlags() {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		// A command run by the shell is not done parsing its flags on the
+		// next line, see cli.FinishedParsing.
+		cmd.SilenceUsage = false
+		for _, flagSet := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.LocalNonPersistentFlags()} {
+			flagSet.VisitAll(func(f *pflag.Flag) {
+				if !f.Changed {
+					return
+				}
+				value, ok := sh.flags[f]
+				if !ok {
+					value = f.DefValue
+				}
+				if err := restoreFlag(f, value); err != nil {
+					fmt.Fprintf(os.Stderr, "cannot restore flag --%s: %v\n", f.Name, err)
+				}
+			})
+		}
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(Root)
+}
+
+func restoreFlag(f *pflag.Flag, value string) error {
+	f.Changed = false
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		var values []string
+		if value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"); value != "" {
+			var err error
+			if values, err = csv.NewReader(strings.NewReader(value)).Read(); err != nil {
+				return err
+			}
+		}
+		return slice.Replace(values)
+	}
+	return f.Value.Set(value)
+}
+
+func defaultShellHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".vtctldclient_history")
+}
+
+func init() {
+	Shell.Flags().StringVar(&shellOptions.HistoryFile, "history-file", defaultShellHistoryFile(), "The file the lines of the shell are appended to. An empty value keeps no history.")
+	Root.AddCommand(Shell)
+}
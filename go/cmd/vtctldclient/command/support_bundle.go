@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtctl/supportbundle"
+)
+
+// CollectSupportBundle collects the state of the cluster into an archive.
+var CollectSupportBundle = &cobra.Command{
+	Use:   "CollectSupportBundle [--output <file>] [--keyspaces <keyspaces>] [--vtgate-addresses <host:port,...>] [--log-lines 1000] [--concurrency 8] [--http-timeout 10s]",
+	Short: "Collects the topology, the workflows and the debug pages, logs and versions of the tablets and vtgates into a sanitized archive.",
+	Long: `Collects the topology, the workflows and the debug pages, logs and versions of the tablets and vtgates into a sanitized archive.
+
+The archive is a gzipped tarball meant to be shared with support or with the
+maintainers. It holds the cells, the routing rules, the keyspaces with their
+shards, vschemas, SrvKeyspaces and workflows, the tablets, and the
+/debug/vars, /debug/status and last --log-lines lines of the logs of each
+tablet and of each vtgate of --vtgate-addresses, which are fetched from their
+HTTP servers. The values of the flags, the variables and the fields that look
+like secrets, e.g. passwords and tokens, are redacted.
+
+A part of the cluster that cannot be collected, e.g. an unreachable tablet, is
+reported and recorded in the bundle.json of the archive, but does not fail the
+command.`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	RunE:                  commandCollectSupportBundle,
+}
+
+var collectSupportBundleOptions = struct {
+	Output          string
+	Keyspaces       []string
+	VtgateAddresses []string
+	LogLines        int
+	Concurrency     int
+	HTTPTimeout     time.Duration
+}{}
+
+func commandCollectSupportBundle(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	output := collectSupportBundleOptions.Output
+	if output == "" {
+		output = fmt.Sprintf("vitess-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	summary, err := supportbundle.Collect(commandCtx, client, f, supportbundle.Options{
+		Keyspaces:       collectSupportBundleOptions.Keyspaces,
+		VtgateAddresses: collectSupportBundleOptions.VtgateAddresses,
+		LogLines:        collectSupportBundleOptions.LogLines,
+		Concurrency:     collectSupportBundleOptions.Concurrency,
+		HTTPTimeout:     collectSupportBundleOptions.HTTPTimeout,
+		ClientVersion:   servenv.AppVersion.String(),
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	for _, collectErr := range summary.Errors {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", collectErr)
+	}
+	fmt.Printf("Collected %d keyspaces and %d tablets into %s.\n", len(summary.Keyspaces), summary.Tablets, output)
+	return nil
+}
+
+func init() {
+	CollectSupportBundle.Flags().StringVarP(&collectSupportBundleOptions.Output, "output", "o", "", "The file the archive is written to, which must not exist. Defaults to vitess-support-bundle-<time>.tar.gz in the current directory.")
+	CollectSupportBundle.Flags().StringSliceVar(&collectSupportBundleOptions.Keyspaces, "keyspaces", nil, "The keyspaces whose topology, workflows and tablets are collected. Defaults to all the keyspaces.")
+	CollectSupportBundle.Flags().StringSliceVar(&collectSupportBundleOptions.VtgateAddresses, "vtgate-addresses", nil, "The host:port of the HTTP servers of the vtgates to collect, which are not recorded in the topology.")
+	CollectSupportBundle.Flags().IntVar(&collectSupportBundleOptions.LogLines, "log-lines", 1000, "The number of recent log lines collected from each tablet and vtgate, through their /debug/logs page. 0 skips the logs.")
+	CollectSupportBundle.Flags().IntVar(&collectSupportBundleOptions.Concurrency, "concurrency", 8, "The maximum number of tablets and vtgates whose pages are fetched at the same time.")
+	CollectSupportBundle.Flags().DurationVar(&collectSupportBundleOptions.HTTPTimeout, "http-timeout", 10*time.Second, "The timeout of each fetch of a page of a tablet or a vtgate.")
+	Root.AddCommand(CollectSupportBundle)
+}
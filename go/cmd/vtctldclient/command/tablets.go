@@ -18,6 +18,7 @@ package command
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -54,6 +55,25 @@ NOTE: This command automatically updates the serving graph.`,
 		Args:                  cobra.MinimumNArgs(1),
 		RunE:                  commandDeleteTablets,
 	}
+	// DrainTablet makes a DrainTablet gRPC call to a vtctld.
+	DrainTablet = &cobra.Command{
+		Use:   "DrainTablet [--wait-timeout <duration>] [--undo] <alias>",
+		Short: "Drains a tablet of its queries and changes its type to DRAINED.",
+		Long: `Drains a tablet of its queries and changes its type to DRAINED.
+
+The tablet is marked as draining in its health stream, so that vtgates stop sending
+it new queries, while the queries and transactions in flight finish. Once there are
+none left, the type of the tablet is changed to DRAINED, and it can be taken down for
+maintenance without failing any query.
+
+If the tablet is still busy after --wait-timeout, the command fails and the tablet keeps
+draining. Use --undo to have vtgates send it queries again.
+
+Only REPLICA and RDONLY tablets can be drained.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDrainTablet,
+	}
 	// ExecuteHook makes an ExecuteHook gRPC call to a vtctld.
 	ExecuteHook = &cobra.Command{
 		Use:   "ExecuteHook <alias> <hook_name> [<param1=value1> ...]",
@@ -144,6 +164,25 @@ Valid output formats are "awk" and "json".`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandRefreshStateByShard,
 	}
+	// RollingRestart makes a RollingRestart gRPC call to a vtctld.
+	RollingRestart = &cobra.Command{
+		Use:   "RollingRestart --keyspace <keyspace> [--component vttablet] [--shards <shard1,shard2,...>] [--cells <cell1,cell2,...>] [--concurrency <concurrency>] [--restart-hook <hook>] [--drain-timeout <duration>] [--healthy-timeout <duration>] [--wait-replicas-timeout <duration>]",
+		Short: "Restarts the tablets of a keyspace one at a time in every shard, draining each tablet first and waiting for it to be healthy again.",
+		Long: `Restarts the tablets of a keyspace one at a time in every shard, draining each tablet first and waiting for it to be healthy again.
+
+Tablets are restarted by running the restart hook in the vthook directory of the vtctld, with the
+--tablet_alias, --component, --keyspace, --shard and --hostname of the tablet. The hook should restart
+the tablet, for example through systemd or kubernetes, and return once the tablet was stopped.
+
+The replicas of a shard are restarted first. Its primary is restarted last, after a planned reparent
+of the shard to one of its replicas, and comes back as a replica. The shard is not reparented back to it.
+
+The rolling restart stops at the first tablet that fails to restart; the tablets that are being restarted
+in other shards are not interrupted.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandRollingRestart,
+	}
 	// RunHealthCheck makes a RunHealthCheck gRPC call to a vtctld.
 	RunHealthCheck = &cobra.Command{
 		Use:                   "RunHealthCheck <tablet_alias>",
@@ -265,6 +304,38 @@ func commandDeleteTablets(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var drainTabletOptions = struct {
+	WaitTimeout time.Duration
+	Undo        bool
+}{}
+
+func commandDrainTablet(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.DrainTablet(commandCtx, &vtctldatapb.DrainTabletRequest{
+		TabletAlias: alias,
+		WaitTimeout: protoutil.DurationToProto(drainTabletOptions.WaitTimeout),
+		Undo:        drainTabletOptions.Undo,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandExecuteHook(cmd *cobra.Command, args []string) error {
 	tabletAlias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -506,6 +577,49 @@ func commandRefreshStateByShard(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var rollingRestartOptions = struct {
+	Keyspace            string
+	Component           string
+	Shards              []string
+	Cells               []string
+	Concurrency         uint32
+	RestartHook         string
+	DrainTimeout        time.Duration
+	HealthyTimeout      time.Duration
+	WaitReplicasTimeout time.Duration
+}{}
+
+func commandRollingRestart(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	stream, err := client.RollingRestart(commandCtx, &vtctldatapb.RollingRestartRequest{
+		Keyspace:            rollingRestartOptions.Keyspace,
+		Shards:              rollingRestartOptions.Shards,
+		Component:           rollingRestartOptions.Component,
+		Cells:               rollingRestartOptions.Cells,
+		Concurrency:         rollingRestartOptions.Concurrency,
+		RestartHook:         rollingRestartOptions.RestartHook,
+		DrainTimeout:        protoutil.DurationToProto(rollingRestartOptions.DrainTimeout),
+		HealthyTimeout:      protoutil.DurationToProto(rollingRestartOptions.HealthyTimeout),
+		WaitReplicasTimeout: protoutil.DurationToProto(rollingRestartOptions.WaitReplicasTimeout),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		switch err {
+		case nil:
+			fmt.Printf("%s/%s (%s): %v\n", resp.Keyspace, resp.Shard, topoproto.TabletAliasString(resp.TabletAlias), resp.Event)
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
 func commandRunHealthCheck(cmd *cobra.Command, args []string) error {
 	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -595,6 +709,10 @@ func init() {
 	DeleteTablets.Flags().BoolVarP(&deleteTabletsOptions.AllowPrimary, "allow-primary", "p", false, "Allow the primary tablet of a shard to be deleted. Use with caution.")
 	Root.AddCommand(DeleteTablets)
 
+	DrainTablet.Flags().DurationVar(&drainTabletOptions.WaitTimeout, "wait-timeout", 30*time.Second, "How long to wait for the queries and transactions in flight on the tablet to finish.")
+	DrainTablet.Flags().BoolVar(&drainTabletOptions.Undo, "undo", false, "Stop draining the tablet, so that vtgates send it queries again. Does not change the type of the tablet.")
+	Root.AddCommand(DrainTablet)
+
 	Root.AddCommand(ExecuteHook)
 	Root.AddCommand(GetPermissions)
 	Root.AddCommand(GetTablet)
@@ -614,6 +732,18 @@ func init() {
 	RefreshStateByShard.Flags().StringSliceVarP(&refreshStateByShardOptions.Cells, "cells", "c", nil, "If specified, only call RefreshState on tablets in the specified cells. If empty, all cells are considered.")
 	Root.AddCommand(RefreshStateByShard)
 
+	RollingRestart.Flags().StringVarP(&rollingRestartOptions.Keyspace, "keyspace", "k", "", "The keyspace whose tablets to restart.")
+	RollingRestart.MarkFlagRequired("keyspace")
+	RollingRestart.Flags().StringVar(&rollingRestartOptions.Component, "component", "vttablet", "The component to restart. Only vttablet is supported.")
+	RollingRestart.Flags().StringSliceVar(&rollingRestartOptions.Shards, "shards", nil, "If specified, only restart the tablets of these shards. If empty, the tablets of all the shards of the keyspace are restarted.")
+	RollingRestart.Flags().StringSliceVarP(&rollingRestartOptions.Cells, "cells", "c", nil, "If specified, only restart the tablets in these cells. If empty, all cells are considered.")
+	RollingRestart.Flags().Uint32Var(&rollingRestartOptions.Concurrency, "concurrency", 1, "The number of shards whose tablets are restarted at the same time. The tablets of a shard are always restarted one at a time.")
+	RollingRestart.Flags().StringVar(&rollingRestartOptions.RestartHook, "restart-hook", "restart_tablet", "The vthook that restarts a tablet.")
+	RollingRestart.Flags().DurationVar(&rollingRestartOptions.DrainTimeout, "drain-timeout", 30*time.Second, "How long to wait for the queries and transactions in flight on a tablet to finish before restarting it.")
+	RollingRestart.Flags().DurationVar(&rollingRestartOptions.HealthyTimeout, "healthy-timeout", 5*time.Minute, "How long to wait for a tablet to be healthy again after restarting it.")
+	RollingRestart.Flags().DurationVar(&rollingRestartOptions.WaitReplicasTimeout, "wait-replicas-timeout", 30*time.Second, "Time to wait for replicas to catch up in the planned reparents done before restarting the primaries.")
+	Root.AddCommand(RollingRestart)
+
 	Root.AddCommand(RunHealthCheck)
 	Root.AddCommand(SetWritable)
 	Root.AddCommand(SleepTablet)
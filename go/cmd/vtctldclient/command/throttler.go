@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Throttler is the parent command of the commands that view and update
+	// the tablet throttlers of a keyspace.
+	Throttler = &cobra.Command{
+		Use:   "Throttler --keyspace <keyspace> [command]",
+		Short: "Views and updates the tablet throttlers of all the tablets in a keyspace.",
+		Long: `Views and updates the tablet throttlers of all the tablets in a keyspace.
+
+Tablets whose throttler is not enabled or disabled like most tablets of the keyspace,
+or doesn't have their threshold, are reported as divergent.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+	}
+	// ThrottlerStatus makes a GetThrottlerStatus gRPC call to a vtctld.
+	ThrottlerStatus = &cobra.Command{
+		Use:                   "Status",
+		Short:                 "Gets the status of the throttlers of all the tablets in the keyspace.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandThrottlerStatus,
+	}
+	// ThrottlerEnable makes an UpdateThrottlerConfig gRPC call to a vtctld.
+	ThrottlerEnable = &cobra.Command{
+		Use:   "Enable",
+		Short: "Enables the throttlers of all the tablets in the keyspace.",
+		Long: `Enables the throttlers of all the tablets in the keyspace.
+
+The throttlers must have been started with --enable_lag_throttler. The change is lost
+when a tablet restarts.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandThrottlerEnable,
+	}
+	// ThrottlerDisable makes an UpdateThrottlerConfig gRPC call to a vtctld.
+	ThrottlerDisable = &cobra.Command{
+		Use:   "Disable",
+		Short: "Disables the throttlers of all the tablets in the keyspace.",
+		Long: `Disables the throttlers of all the tablets in the keyspace, so that they let all
+checks through.
+
+The change is lost when a tablet restarts.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandThrottlerDisable,
+	}
+	// ThrottlerSetThreshold makes an UpdateThrottlerConfig gRPC call to a vtctld.
+	ThrottlerSetThreshold = &cobra.Command{
+		Use:   "SetThreshold <threshold>",
+		Short: "Sets the threshold of the throttlers of all the tablets in the keyspace.",
+		Long: `Sets the threshold of the throttlers of all the tablets in the keyspace, in seconds
+of replication lag, or in the unit of --throttle_metrics_query.
+
+The change is lost when a tablet restarts.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandThrottlerSetThreshold,
+	}
+)
+
+var throttlerOptions = struct {
+	Keyspace string
+}{}
+
+func commandThrottlerStatus(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetThrottlerStatus(commandCtx, &vtctldatapb.GetThrottlerStatusRequest{
+		Keyspace: throttlerOptions.Keyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	warnDivergentThrottlers(resp.DivergentTablets)
+
+	return nil
+}
+
+func commandThrottlerEnable(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	return updateThrottlerConfig(&vtctldatapb.UpdateThrottlerConfigRequest{
+		Keyspace: throttlerOptions.Keyspace,
+		Enable:   true,
+	})
+}
+
+func commandThrottlerDisable(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	return updateThrottlerConfig(&vtctldatapb.UpdateThrottlerConfigRequest{
+		Keyspace: throttlerOptions.Keyspace,
+		Disable:  true,
+	})
+}
+
+func commandThrottlerSetThreshold(cmd *cobra.Command, args []string) error {
+	threshold, err := strconv.ParseFloat(cmd.Flags().Arg(0), 64)
+	if err != nil {
+		return fmt.Errorf("invalid threshold %s: %w", cmd.Flags().Arg(0), err)
+	}
+	if threshold <= 0 {
+		return fmt.Errorf("threshold must be positive, got %v", threshold)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	return updateThrottlerConfig(&vtctldatapb.UpdateThrottlerConfigRequest{
+		Keyspace:  throttlerOptions.Keyspace,
+		Threshold: threshold,
+	})
+}
+
+// updateThrottlerConfig updates the throttlers of a keyspace, prints their
+// statuses, and fails if the throttlers of some tablets were not updated.
+func updateThrottlerConfig(req *vtctldatapb.UpdateThrottlerConfigRequest) error {
+	resp, err := client.UpdateThrottlerConfig(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	warnDivergentThrottlers(resp.DivergentTablets)
+
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("failed to update the throttlers of %d tablets in keyspace %s", len(resp.Errors), req.Keyspace)
+	}
+
+	return nil
+}
+
+func warnDivergentThrottlers(aliases []string) {
+	if len(aliases) > 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: the throttlers of these tablets diverge from the rest of the keyspace: %s\n", strings.Join(aliases, ", "))
+	}
+}
+
+func init() {
+	Throttler.PersistentFlags().StringVarP(&throttlerOptions.Keyspace, "keyspace", "k", "", "The keyspace of the tablets whose throttlers to view or update.")
+	Throttler.MarkPersistentFlagRequired("keyspace")
+
+	Throttler.AddCommand(ThrottlerStatus)
+	Throttler.AddCommand(ThrottlerEnable)
+	Throttler.AddCommand(ThrottlerDisable)
+	Throttler.AddCommand(ThrottlerSetThreshold)
+	Root.AddCommand(Throttler)
+}
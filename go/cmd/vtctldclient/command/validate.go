@@ -19,12 +19,16 @@ package command
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
@@ -54,6 +58,24 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandValidateShard,
 	}
+	// ValidateVersionSkew makes a ValidateVersionSkew gRPC call to a vtctld.
+	ValidateVersionSkew = &cobra.Command{
+		Use:   "ValidateVersionSkew [--max-major-skew 1] [--stale-after 1h]",
+		Short: "Validates that the versions of the vtgates, vttablets, vtctlds and vtorcs of the cluster are compatible.",
+		Long: `Validates that the versions of the vtgates, vttablets, vtctlds and vtorcs of the cluster are compatible.
+
+Each component records its version in the topo when it starts, and refreshes
+it periodically. The validation fails if the major versions of the components
+are more than --max-major-skew apart, or if a component runs a newer major
+version than a component that must be upgraded before it: the vtctlds and the
+vtorcs first, then the vttablets, then the vtgates.
+
+The versions that were not refreshed for --stale-after, e.g. the ones of the
+components that crashed, are ignored.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandValidateVersionSkew,
+	}
 )
 
 var validateOptions = struct {
@@ -137,6 +159,38 @@ func commandValidateShard(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var validateVersionSkewOptions = struct {
+	MaxMajorSkew int32
+	StaleAfter   time.Duration
+}{}
+
+func commandValidateVersionSkew(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ValidateVersionSkew(commandCtx, &vtctldatapb.ValidateVersionSkewRequest{
+		MaxMajorSkew: validateVersionSkewOptions.MaxMajorSkew,
+		StaleAfter:   protoutil.DurationToProto(validateVersionSkewOptions.StaleAfter),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range resp.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if len(resp.Results) > 0 {
+		buf := &strings.Builder{}
+		for _, result := range resp.Results {
+			fmt.Fprintf(buf, "- %s\n", result)
+		}
+		fmt.Printf("Validation results:\n%s", buf.String())
+		return errors.New("the version skew of the cluster is not supported; see above for details")
+	}
+
+	fmt.Printf("Validation of the versions of %d components complete; no issues found.\n", len(resp.Versions))
+	return nil
+}
+
 func consumeValidationResults(resp *vtctldatapb.ValidateResponse, buf *strings.Builder) error {
 	for _, result := range resp.Results {
 		fmt.Fprintf(buf, "- %s\n", result)
@@ -202,4 +256,8 @@ func init() {
 	Root.AddCommand(Validate)
 	Root.AddCommand(ValidateKeyspace)
 	Root.AddCommand(ValidateShard)
+
+	ValidateVersionSkew.Flags().Int32Var(&validateVersionSkewOptions.MaxMajorSkew, "max-major-skew", topotools.DefaultMaxMajorVersionSkew, "The supported difference between the major versions of the components.")
+	ValidateVersionSkew.Flags().DurationVar(&validateVersionSkewOptions.StaleAfter, "stale-after", topotools.DefaultComponentVersionStaleAfter, "The age after which the version recorded by a component that did not refresh it is ignored.")
+	Root.AddCommand(ValidateVersionSkew)
 }
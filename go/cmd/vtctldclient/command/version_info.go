@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// GetVersionInfo makes a GetVersionInfo gRPC call to a vtctld.
+var GetVersionInfo = &cobra.Command{
+	Use:   "GetVersionInfo [--tablet <alias> | --address <host:port>]",
+	Short: "Prints the build metadata and the capabilities of a component.",
+	Long: `Prints the build metadata and the capabilities of a component: of a tablet, of
+the vtgate or other component serving gRPC at an address, or of the vtctld
+itself if neither is given.
+
+The capabilities are the optional features the component supports, e.g.
+"vdiff2". Check them, rather than the version, to know whether an operation is
+available.`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	RunE:                  commandGetVersionInfo,
+}
+
+var getVersionInfoOptions = struct {
+	Tablet  string
+	Address string
+}{}
+
+func commandGetVersionInfo(cmd *cobra.Command, args []string) error {
+	var (
+		alias *topodatapb.TabletAlias
+		err   error
+	)
+	switch {
+	case getVersionInfoOptions.Tablet != "" && getVersionInfoOptions.Address != "":
+		return fmt.Errorf("cannot set both --tablet and --address")
+	case getVersionInfoOptions.Tablet != "":
+		alias, err = topoproto.ParseTabletAlias(getVersionInfoOptions.Tablet)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVersionInfo(commandCtx, &vtctldatapb.GetVersionInfoRequest{
+		TabletAlias: alias,
+		Address:     getVersionInfoOptions.Address,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.VersionInfo)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	GetVersionInfo.Flags().StringVar(&getVersionInfoOptions.Tablet, "tablet", "", "The alias of the tablet.")
+	GetVersionInfo.Flags().StringVar(&getVersionInfoOptions.Address, "address", "", "The gRPC address (host:port) of the component.")
+	Root.AddCommand(GetVersionInfo)
+}
@@ -40,12 +40,29 @@ var (
 	}
 	// ApplyVSchema makes an ApplyVSchema gRPC call to a vtctld.
 	ApplyVSchema = &cobra.Command{
-		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run] <keyspace>",
+		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run] [--validate-against-schema] <keyspace>",
 		Short:                 "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandApplyVSchema,
 	}
+	// GetVSchemaHistory lists the saved versions of a keyspace's vschema.
+	GetVSchemaHistory = &cobra.Command{
+		Use:                   "GetVSchemaHistory [--version <version>] <keyspace>",
+		Short:                 "Lists the saved versions of a keyspace's VSchema, with their author, timestamp and change summary.",
+		Long:                  "Lists the saved versions of a keyspace's VSchema, with their author, timestamp and change summary.\nWith --version, prints that version of the VSchema instead.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetVSchemaHistory,
+	}
+	// RollbackVSchema restores a saved version of a keyspace's vschema.
+	RollbackVSchema = &cobra.Command{
+		Use:                   "RollbackVSchema --to-version <version> [--cells=c1,c2,...] [--skip-rebuild] <keyspace>",
+		Short:                 "Restores a saved version of a keyspace's VSchema, as listed by GetVSchemaHistory.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRollbackVSchema,
+	}
 )
 
 var applyVSchemaOptions = struct {
@@ -56,6 +73,8 @@ var applyVSchemaOptions = struct {
 	DryRun      bool
 	SkipRebuild bool
 	Cells       []string
+
+	ValidateAgainstSchema bool
 }{}
 
 func commandApplyVSchema(cmd *cobra.Command, args []string) error {
@@ -71,10 +90,11 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 	}
 
 	req := &vtctldatapb.ApplyVSchemaRequest{
-		Keyspace:    cmd.Flags().Arg(0),
-		SkipRebuild: applyVSchemaOptions.SkipRebuild,
-		Cells:       applyVSchemaOptions.Cells,
-		DryRun:      applyVSchemaOptions.DryRun,
+		Keyspace:              cmd.Flags().Arg(0),
+		SkipRebuild:           applyVSchemaOptions.SkipRebuild,
+		Cells:                 applyVSchemaOptions.Cells,
+		DryRun:                applyVSchemaOptions.DryRun,
+		ValidateAgainstSchema: applyVSchemaOptions.ValidateAgainstSchema,
 	}
 
 	var err error
@@ -121,6 +141,69 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var getVSchemaHistoryOptions = struct {
+	Version int64
+}{}
+
+func commandGetVSchemaHistory(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVSchemaHistory(commandCtx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Version:  getVSchemaHistoryOptions.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if getVSchemaHistoryOptions.Version != 0 {
+		data, err = cli.MarshalJSON(resp.VSchema)
+	} else {
+		data, err = cli.MarshalJSON(resp)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var rollbackVSchemaOptions = struct {
+	ToVersion   int64
+	SkipRebuild bool
+	Cells       []string
+}{}
+
+func commandRollbackVSchema(cmd *cobra.Command, args []string) error {
+	if rollbackVSchemaOptions.ToVersion <= 0 {
+		return fmt.Errorf("--to-version is required when calling the RollbackVSchema command")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RollbackVSchema(commandCtx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace:    cmd.Flags().Arg(0),
+		ToVersion:   rollbackVSchemaOptions.ToVersion,
+		SkipRebuild: rollbackVSchemaOptions.SkipRebuild,
+		Cells:       rollbackVSchemaOptions.Cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.VSchema)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored VSchema object:\n%s\n", data)
+
+	return nil
+}
+
 func commandGetVSchema(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -151,7 +234,16 @@ func init() {
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.DryRun, "dry-run", false, "If set, do not save the altered vschema, simply echo to console.")
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
 	ApplyVSchema.Flags().StringSliceVar(&applyVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after application. Ignored if --skip-rebuild is set.")
+	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.ValidateAgainstSchema, "validate-against-schema", false, "Reject the vschema if it references tables, vindex columns, sequences or authoritative columns that do not exist on the shard primaries.")
 	Root.AddCommand(ApplyVSchema)
 
 	Root.AddCommand(GetVSchema)
+
+	GetVSchemaHistory.Flags().Int64Var(&getVSchemaHistoryOptions.Version, "version", 0, "Print this version of the VSchema instead of the list of versions.")
+	Root.AddCommand(GetVSchemaHistory)
+
+	RollbackVSchema.Flags().Int64Var(&rollbackVSchemaOptions.ToVersion, "to-version", 0, "The version of the VSchema to restore.")
+	RollbackVSchema.Flags().BoolVar(&rollbackVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
+	RollbackVSchema.Flags().StringSliceVar(&rollbackVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after application. Ignored if --skip-rebuild is set.")
+	Root.AddCommand(RollbackVSchema)
 }
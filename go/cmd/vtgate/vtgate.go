@@ -36,6 +36,7 @@ import (
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtgate"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -160,6 +161,9 @@ func main() {
 		// Flags are parsed now. Parse the template using the actual flag value and overwrite the current template.
 		discovery.ParseTabletURLTemplateFromFlag()
 		addStatusParts(vtg)
+
+		// Record the version of vtgate in the topo for the version skew checks.
+		servenv.OnTermSync(topotools.RegisterComponentVersion(context.Background(), ts, topotools.ComponentVtgate, servenv.ListeningURL.Host, *cell))
 	})
 	servenv.OnClose(func() {
 		_ = vtg.Gateway().Close(context.Background())
@@ -84,13 +84,13 @@ func (c *callerIDClient) Execute(ctx context.Context, session *vtgatepb.Session,
 	return c.fallbackClient.Execute(ctx, session, sql, bindVariables)
 }
 
-func (c *callerIDClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (c *callerIDClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if len(sqlList) == 1 {
 		if ok, err := c.checkCallerID(ctx, sqlList[0]); ok {
 			return session, nil, err
 		}
 	}
-	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList)
+	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList, stopOnError)
 }
 
 func (c *callerIDClient) StreamExecute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
@@ -123,7 +123,7 @@ func (c *echoClient) StreamExecute(ctx context.Context, session *vtgatepb.Sessio
 	return c.fallbackClient.StreamExecute(ctx, session, sql, bindVariables, callback)
 }
 
-func (c *echoClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (c *echoClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if len(sqlList) > 0 && strings.HasPrefix(sqlList[0], EchoPrefix) {
 		var queryResponse []sqltypes.QueryResponse
 		if bindVariablesList == nil {
@@ -140,7 +140,7 @@ func (c *echoClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session
 		}
 		return session, queryResponse, nil
 	}
-	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList)
+	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList, stopOnError)
 }
 
 func (c *echoClient) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, callback func([]*binlogdatapb.VEvent) error) error {
@@ -121,7 +121,7 @@ func (c *errorClient) Execute(ctx context.Context, session *vtgatepb.Session, sq
 	return c.fallbackClient.Execute(ctx, session, sql, bindVariables)
 }
 
-func (c *errorClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (c *errorClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if len(sqlList) == 1 {
 		if err := requestToPartialError(sqlList[0], session); err != nil {
 			return session, nil, err
@@ -130,7 +130,7 @@ func (c *errorClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Sessio
 			return session, nil, err
 		}
 	}
-	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList)
+	return c.fallbackClient.ExecuteBatch(ctx, session, sqlList, bindVariablesList, stopOnError)
 }
 
 func (c *errorClient) StreamExecute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
@@ -18,6 +18,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
@@ -44,8 +45,8 @@ func (c fallbackClient) Execute(ctx context.Context, session *vtgatepb.Session,
 	return c.fallback.Execute(ctx, session, sql, bindVariables)
 }
 
-func (c fallbackClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
-	return c.fallback.ExecuteBatch(ctx, session, sqlList, bindVariablesList)
+func (c fallbackClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+	return c.fallback.ExecuteBatch(ctx, session, sqlList, bindVariablesList, stopOnError)
 }
 
 func (c fallbackClient) StreamExecute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
@@ -60,6 +61,14 @@ func (c fallbackClient) CloseSession(ctx context.Context, session *vtgatepb.Sess
 	return c.fallback.CloseSession(ctx, session)
 }
 
+func (c fallbackClient) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	return c.fallback.CheckoutSession(ctx, session, timeout)
+}
+
+func (c fallbackClient) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	return c.fallback.CheckinSession(ctx, token)
+}
+
 func (c fallbackClient) ResolveTransaction(ctx context.Context, dtid string) error {
 	return c.fallback.ResolveTransaction(ctx, dtid)
 }
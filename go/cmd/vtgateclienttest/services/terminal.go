@@ -19,6 +19,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"context"
 
@@ -49,7 +50,7 @@ func (c *terminalClient) Execute(ctx context.Context, session *vtgatepb.Session,
 	return session, nil, errTerminal
 }
 
-func (c *terminalClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (c *terminalClient) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if len(sqlList) == 1 {
 		if sqlList[0] == "quit://" {
 			log.Fatal("Received quit:// query. Going down.")
@@ -70,6 +71,14 @@ func (c *terminalClient) CloseSession(ctx context.Context, session *vtgatepb.Ses
 	return errTerminal
 }
 
+func (c *terminalClient) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	return "", errTerminal
+}
+
+func (c *terminalClient) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	return nil, errTerminal
+}
+
 func (c *terminalClient) ResolveTransaction(ctx context.Context, dtid string) error {
 	return errTerminal
 }
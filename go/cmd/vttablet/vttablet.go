@@ -20,6 +20,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"os"
 
@@ -36,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/tabletmanager"
 	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
@@ -52,6 +54,7 @@ var (
 	tableACLConfigReloadInterval = flag.Duration("table-acl-config-reload-interval", 0, "Ticker to reload ACLs. Duration flag, format e.g.: 30s. Default: do not reload")
 	tabletPath                   = flag.String("tablet-path", "", "tablet alias")
 	tabletConfig                 = flag.String("tablet_config", "", "YAML file config for tablet")
+	dbSecretsFile                = flag.String("db_secrets_file", "", "YAML file with the credentials and the SSL settings of the connections to the databases, in the db and externalConnections sections of the tablet config, such as the replication credentials of an external database. It is loaded after --tablet_config, so that the secrets can be kept apart from the rest of the config")
 
 	tm *tabletmanager.TabletManager
 )
@@ -108,6 +111,7 @@ func main() {
 		VREngine:            vreplication.NewEngine(config, ts, tabletAlias.Cell, mysqld, qsc.LagThrottler()),
 		VDiffEngine:         vdiff.NewEngine(config, ts, tablet),
 		MetadataManager:     &mysqlctl.MetadataManager{},
+		Unmanaged:           config.Unmanaged,
 	}
 	if err := tm.Start(tablet, config.Healthcheck.IntervalSeconds.Get()); err != nil {
 		log.Exitf("failed to parse --tablet-path or initialize DB credentials: %v", err)
@@ -121,6 +125,11 @@ func main() {
 		ts.Close()
 	})
 
+	// Record the version of vttablet in the topo for the version skew checks.
+	servenv.OnRun(func() {
+		servenv.OnTermSync(topotools.RegisterComponentVersion(context.Background(), ts, topotools.ComponentVttablet, topoproto.TabletAliasString(tabletAlias), tabletAlias.Cell))
+	})
+
 	servenv.RunDefault()
 }
 
@@ -141,6 +150,11 @@ func initConfig(tabletAlias *topodatapb.TabletAlias) (*tabletenv.TabletConfig, *
 			log.Exitf("error parsing config file %s: %v", bytes, err)
 		}
 	}
+	if *dbSecretsFile != "" {
+		if err := loadDBSecrets(*dbSecretsFile, config); err != nil {
+			log.Exitf("error loading secrets file %s: %v", *dbSecretsFile, err)
+		}
+	}
 	gotBytes, _ := yaml2.Marshal(config)
 	log.Infof("Loaded config file %s successfully:\n%s", *tabletConfig, gotBytes)
 
@@ -170,6 +184,30 @@ func initConfig(tabletAlias *topodatapb.TabletAlias) (*tabletenv.TabletConfig, *
 	return config, mycnf
 }
 
+// loadDBSecrets merges the connection settings of the secrets file into the
+// config. Only the db and externalConnections sections are read, and the file
+// contents are never logged.
+func loadDBSecrets(file string, config *tabletenv.TabletConfig) error {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	secrets := struct {
+		DB                  *dbconfigs.DBConfigs            `json:"db,omitempty"`
+		ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
+	}{
+		DB:                  config.DB,
+		ExternalConnections: config.ExternalConnections,
+	}
+	if err := yaml2.Unmarshal(bytes, &secrets); err != nil {
+		// The parse error may quote the secrets.
+		return errors.New("the file is not valid YAML")
+	}
+	config.DB = secrets.DB
+	config.ExternalConnections = secrets.ExternalConnections
+	return nil
+}
+
 // extractOnlineDDL extracts the gh-ost binary from this executable. gh-ost is appended
 // to vttablet executable by `make build` and via ricebox
 func extractOnlineDDL() error {
@@ -45,13 +45,14 @@ type topoFlags struct {
 }
 
 var (
-	basePort  int
-	config    vttest.Config
-	doSeed    bool
-	mycnf     string
-	protoTopo string
-	seed      vttest.SeedConfig
-	topo      topoFlags
+	basePort          int
+	config            vttest.Config
+	doSeed            bool
+	exportSnapshotDir string
+	mycnf             string
+	protoTopo         string
+	seed              vttest.SeedConfig
+	topo              topoFlags
 )
 
 func init() {
@@ -88,11 +89,12 @@ func init() {
 		"If this flag is set, the MySQL data directory is not cleaned up"+
 			" when LocalCluster.TearDown() is called. This is useful for running"+
 			" vttestserver as a database container in local developer environments. Note"+
-			" that db migration files (--schema_dir option) and seeding of"+
-			" random data (--initialize_with_random_data option) will only run during"+
-			" cluster startup if the data directory does not already exist. vschema"+
-			" migrations are run every time the cluster starts, since persistence"+
-			" for the topology server has not been implemented yet")
+			" that db migration files (--schema_dir option), seeding of"+
+			" random data (--initialize_with_random_data option) and fixtures"+
+			" (--fixtures_dir option) will only run during"+
+			" cluster startup if the data directory does not already exist. vtcombo"+
+			" saves its keyspaces, vschemas and routing rules in the data directory,"+
+			" so that the cluster restarts with them.")
 
 	flag.BoolVar(&doSeed, "initialize_with_random_data", false,
 		"If this flag is each table-shard will be initialized"+
@@ -120,6 +122,18 @@ func init() {
 			" if --initialize_with_random_data is true. Only applies to fields"+
 			" that can contain NULL values.")
 
+	flag.StringVar(&config.FixturesDir, "fixtures_dir", "",
+		"Directory of the data to seed the cluster with, after the schema files"+
+			" are executed. Within this dir, there should be a subdir for each"+
+			" keyspace. Within each keyspace dir, the .sql files are executed,"+
+			" and the rows of the .csv files replace the rows of the table named"+
+			" after them. A directory written with --export_snapshot_dir can be used.")
+
+	flag.StringVar(&exportSnapshotDir, "export_snapshot_dir", "",
+		"If set, the rows of every table are written to .csv files in this"+
+			" directory when vttestserver shuts down, so that the next run can"+
+			" load them with --fixtures_dir.")
+
 	flag.StringVar(&config.MySQLBindHost, "mysql_bind_host", "localhost",
 		"which host to bind vtgate mysql listener to")
 
@@ -260,6 +274,13 @@ func main() {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
+
+	if exportSnapshotDir != "" {
+		log.Infof("Exporting a snapshot of the data to %s...", exportSnapshotDir)
+		if err := cluster.ExportSnapshot(exportSnapshotDir); err != nil {
+			log.Errorf("Failed to export the snapshot: %v", err)
+		}
+	}
 }
 
 func runCluster() (vttest.LocalCluster, error) {
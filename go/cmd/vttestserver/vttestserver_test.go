@@ -123,6 +123,50 @@ func TestPersistentMode(t *testing.T) {
 	assert.Equal(t, expectedRows, res.Rows)
 }
 
+func TestFixturesAndSnapshots(t *testing.T) {
+	args := os.Args
+	conf := config
+	defer resetFlags(args, conf)
+
+	cluster, err := startCluster("--fixtures_dir=data/fixtures")
+	require.NoError(t, err)
+	defer cluster.TearDown()
+
+	// the rows of the fixtures go through vtgate, to the right shards
+	var res *sqltypes.Result
+	err = execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) (err error) {
+		res, err = conn.ExecuteFetch("SELECT * FROM customers order by id", 10, false)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]sqltypes.Value{
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("gopherson"), sqltypes.NULL},
+		{sqltypes.NewInt64(2), sqltypes.NewVarChar("o'brien, jr"), sqltypes.MakeTrusted(sqltypes.Int16, []byte("42"))},
+	}, res.Rows)
+
+	// a snapshot can be loaded again as fixtures
+	dir := t.TempDir()
+	require.NoError(t, cluster.ExportSnapshot(dir))
+	exported, err := os.ReadFile(path.Join(dir, "app_customer", "customers.csv"))
+	require.NoError(t, err)
+	fixtures, err := os.ReadFile("data/fixtures/app_customer/customers.csv")
+	require.NoError(t, err)
+	assert.Equal(t, string(fixtures), string(exported))
+
+	err = execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) error {
+		_, err := conn.ExecuteFetch("delete from customers", 10, false)
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, cluster.LoadFixtures(dir))
+	err = execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) (err error) {
+		res, err = conn.ExecuteFetch("SELECT count(*) FROM customers", 1, false)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "INT64(2)", res.Rows[0][0].String())
+}
+
 func TestForeignKeysAndDDLModes(t *testing.T) {
 	args := os.Args
 	conf := config
@@ -190,6 +234,54 @@ func TestCanVtGateExecute(t *testing.T) {
 	assertVtGateExecute(t, cluster)
 }
 
+func TestSimulationAPI(t *testing.T) {
+	args := os.Args
+	conf := config
+	defer resetFlags(args, conf)
+
+	cluster, err := startCluster()
+	require.NoError(t, err)
+	defer cluster.TearDown()
+
+	err = execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) error {
+		_, err := conn.ExecuteFetch("insert into customers (id, name) values (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd')", 1, false)
+		return err
+	})
+	require.NoError(t, err)
+
+	selectCustomers := func() (res *sqltypes.Result, err error) {
+		err = execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) (err error) {
+			res, err = conn.ExecuteFetch("select id from customers order by id", 10, false)
+			return err
+		})
+		return res, err
+	}
+	expectedRows := [][]sqltypes.Value{
+		{sqltypes.NewInt64(1)}, {sqltypes.NewInt64(2)}, {sqltypes.NewInt64(3)}, {sqltypes.NewInt64(4)},
+	}
+
+	// the rows are kept when the keyspace is resharded
+	require.NoError(t, cluster.Reshard("app_customer", []string{"-40", "40-80", "80-c0", "c0-"}))
+	assert.Eventually(t, func() bool {
+		res, err := selectCustomers()
+		return err == nil && assert.ObjectsAreEqual(expectedRows, res.Rows)
+	}, 30*time.Second, 100*time.Millisecond)
+
+	// writes succeed again once vtgate sees the new primary
+	require.NoError(t, cluster.ReparentShard("app_customer", "-40", ""))
+	assert.Eventually(t, func() bool {
+		return execOnCluster(cluster, "app_customer", func(conn *mysql.Conn) error {
+			_, err := conn.ExecuteFetch("insert into customers (id, name) values (5, 'e')", 1, false)
+			return err
+		}) == nil
+	}, 30*time.Second, 100*time.Millisecond)
+
+	assert.Error(t, cluster.FailTablet("test-0000009999"))
+	require.NoError(t, cluster.FailTablet("test-0000000002"))
+	require.NoError(t, cluster.RecoverTablet("test-0000000002"))
+	assert.Error(t, cluster.Reshard("app_customer", []string{"-80"}))
+}
+
 func TestExternalTopoServerConsul(t *testing.T) {
 	args := os.Args
 	conf := config
@@ -76,6 +76,12 @@ type AuthServerStaticEntry struct {
 	UserData            string
 	SourceHost          string
 	Groups              []string
+	// MaxConnections and MaxConcurrentQueries limit the connections and the
+	// concurrent queries of the user, if the server enforces them. Zero
+	// means unlimited. The limits of the first entry of the user that sets
+	// them apply to all its connections.
+	MaxConnections       int
+	MaxConcurrentQueries int
 }
 
 // InitAuthServerStatic Handles initializing the AuthServerStatic if necessary.
@@ -233,6 +239,24 @@ func (a *AuthServerStatic) UserEntryWithCacheHash(conn *Conn, salt []byte, user
 	return &StaticUserData{}, AuthRejected, NewSQLError(ERAccessDeniedError, SSAccessDeniedError, "Access denied for user '%v'", user)
 }
 
+// UserLimits is part of the UserLimitsProvider interface.
+func (a *AuthServerStatic) UserLimits(user string) UserLimits {
+	a.mu.Lock()
+	entries := a.entries[user]
+	a.mu.Unlock()
+
+	var limits UserLimits
+	for _, entry := range entries {
+		if limits.MaxConnections == 0 {
+			limits.MaxConnections = entry.MaxConnections
+		}
+		if limits.MaxConcurrentQueries == 0 {
+			limits.MaxConcurrentQueries = entry.MaxConcurrentQueries
+		}
+	}
+	return limits
+}
+
 // AuthMethods returns the AuthMethod instances this auth server can handle.
 func (a *AuthServerStatic) AuthMethods() []AuthMethod {
 	return a.methods
@@ -331,6 +355,9 @@ func validateConfig(config map[string][]*AuthServerStaticEntry) error {
 			if entry.SourceHost != "" && entry.SourceHost != localhostName {
 				return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid SourceHost found (only localhost is supported): %v", entry.SourceHost)
 			}
+			if entry.MaxConnections < 0 || entry.MaxConcurrentQueries < 0 {
+				return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid user limits found (they must not be negative): %d connections, %d concurrent queries", entry.MaxConnections, entry.MaxConcurrentQueries)
+			}
 		}
 	}
 	return nil
@@ -286,3 +286,32 @@ func TestStaticPasswords(t *testing.T) {
 		})
 	}
 }
+
+func TestStaticUserLimits(t *testing.T) {
+	jsonConfig := `{
+		"app": [
+			{"Password": "123", "SourceHost": "localhost"},
+			{"Password": "456", "MaxConnections": 10, "MaxConcurrentQueries": 2},
+			{"Password": "789", "MaxConnections": 20}
+		],
+		"admin": [{"Password": "123"}]
+	}`
+	auth := NewAuthServerStatic("", jsonConfig, 0)
+	defer auth.close()
+
+	if got, want := auth.UserLimits("app"), (UserLimits{MaxConnections: 10, MaxConcurrentQueries: 2}); got != want {
+		t.Errorf("UserLimits(app) = %+v, want %+v", got, want)
+	}
+	if got := auth.UserLimits("admin"); got != (UserLimits{}) {
+		t.Errorf("UserLimits(admin) = %+v, want no limits", got)
+	}
+	if got := auth.UserLimits("unknown"); got != (UserLimits{}) {
+		t.Errorf("UserLimits(unknown) = %+v, want no limits", got)
+	}
+
+	config := make(map[string][]*AuthServerStaticEntry)
+	err := ParseConfig([]byte(`{"app": [{"Password": "123", "MaxConnections": -1}]}`), &config)
+	if err == nil {
+		t.Fatalf("negative limits should have errored, but didn't")
+	}
+}
@@ -48,11 +48,16 @@ func (c *Conn) parseComBinlogDumpGTID(data []byte) (logFile string, logPos uint6
 	if !ok {
 		return logFile, logPos, position, readPacketErr
 	}
-	if gtid := string(data[pos : pos+int(dataSize)]); gtid != "" {
-		position, err = DecodePosition(gtid)
+	if pos+int(dataSize) > len(data) {
+		return logFile, logPos, position, readPacketErr
+	}
+	// The GTID set is sent as the SID block of a PREVIOUS_GTIDS_EVENT.
+	if dataSize > 0 {
+		gtidSet, err := NewMysql56GTIDSetFromSIDBlock(data[pos : pos+int(dataSize)])
 		if err != nil {
 			return logFile, logPos, position, err
 		}
+		position = Position{GTIDSet: gtidSet}
 	}
 
 	return logFile, logPos, position, nil
@@ -87,6 +87,9 @@ type BinlogEvent interface {
 	// Timestamp returns the timestamp from the event header.
 	Timestamp() uint32
 
+	// ServerID returns the server_id from the event header.
+	ServerID() uint32
+
 	// Format returns a BinlogFormat struct based on the event data.
 	// This is only valid if IsFormatDescription() returns true.
 	Format() (BinlogFormat, error)
@@ -138,6 +141,19 @@ type BinlogEvent interface {
 	Bytes() []byte
 }
 
+// LogicalClockEvent is implemented by the binlog events whose GTID_EVENT
+// carries the logical timestamps of the transaction, as written by MySQL 5.7
+// and later. Two transactions can be applied in parallel if the
+// last_committed of the later one is lower than the sequence_number of the
+// earlier one, which is how multi-threaded replicas schedule transactions
+// with replica_parallel_type=LOGICAL_CLOCK.
+type LogicalClockEvent interface {
+	// LogicalTimestamps returns the last_committed and sequence_number
+	// of a GTID_EVENT. ok is false if the event has no logical timestamps.
+	// The sequence numbers restart in every binary log file.
+	LogicalTimestamps(BinlogFormat) (lastCommitted, sequenceNumber int64, ok bool)
+}
+
 // BinlogFormat contains relevant data from the FORMAT_DESCRIPTION_EVENT.
 // This structure is passed to subsequent event types to let them know how to
 // parse themselves.
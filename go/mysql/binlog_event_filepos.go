@@ -140,6 +140,10 @@ func (ev filePosFakeEvent) NextPosition() uint32 {
 	return 0
 }
 
+func (ev filePosFakeEvent) ServerID() uint32 {
+	return 0
+}
+
 func (ev filePosFakeEvent) IsValid() bool {
 	return true
 }
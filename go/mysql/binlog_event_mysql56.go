@@ -66,6 +66,24 @@ func (ev mysql56BinlogEvent) GTID(f BinlogFormat) (GTID, bool, error) {
 	return Mysql56GTID{Server: sid, Sequence: gno}, false /* hasBegin */, nil
 }
 
+// LogicalTimestamps implements LogicalClockEvent.LogicalTimestamps().
+//
+// Expected format, after the GTID:
+//   # bytes   field
+//   1         logical timestamp type code (2)
+//   8         last_committed
+//   8         sequence_number
+func (ev mysql56BinlogEvent) LogicalTimestamps(f BinlogFormat) (int64, int64, bool) {
+	data := ev.Bytes()[f.HeaderLength:]
+	const offset = 1 + 16 + 8
+	if !ev.IsGTID() || len(data) < offset+1+8+8 || data[offset] != 2 {
+		return 0, 0, false
+	}
+	lastCommitted := int64(binary.LittleEndian.Uint64(data[offset+1 : offset+1+8]))
+	sequenceNumber := int64(binary.LittleEndian.Uint64(data[offset+1+8 : offset+1+8+8]))
+	return lastCommitted, sequenceNumber, true
+}
+
 // PreviousGTIDs implements BinlogEvent.PreviousGTIDs().
 func (ev mysql56BinlogEvent) PreviousGTIDs(f BinlogFormat) (Position, error) {
 	data := ev.Bytes()[f.HeaderLength:]
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Sample event data for MySQL 5.6.
@@ -98,6 +99,32 @@ func TestMysql56GTID(t *testing.T) {
 	}
 }
 
+func TestMysql56LogicalTimestamps(t *testing.T) {
+	format, err := mysql56FormatEvent.Format()
+	require.NoError(t, err)
+
+	// A MySQL 5.6 GTID_EVENT has no logical timestamps.
+	input, _, err := mysql56GTIDEvent.StripChecksum(format)
+	require.NoError(t, err)
+	_, _, ok := input.(LogicalClockEvent).LogicalTimestamps(format)
+	assert.False(t, ok)
+
+	// The same event as written by MySQL 5.7, with last_committed 3 and
+	// sequence_number 5, followed by a checksum.
+	data := append([]byte{}, mysql56GTIDEvent.Bytes()[:44]...)
+	data = append(data, 0x2, 0x3, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x5, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0)
+	input, _, err = NewMysql56BinlogEvent(data).StripChecksum(format)
+	require.NoError(t, err)
+	lastCommitted, sequenceNumber, ok := input.(LogicalClockEvent).LogicalTimestamps(format)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), lastCommitted)
+	assert.Equal(t, int64(5), sequenceNumber)
+
+	// Other events have no logical timestamps.
+	_, _, ok = mysql56QueryEvent.(LogicalClockEvent).LogicalTimestamps(format)
+	assert.False(t, ok)
+}
+
 func TestMysql56ParseGTID(t *testing.T) {
 	input := "00010203-0405-0607-0809-0A0B0C0D0E0F:56789"
 	want := Mysql56GTID{
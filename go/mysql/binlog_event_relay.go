@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file contains the methods to rewrite the binlog events relayed to
+// a replica, e.g. by the binlog server of vttablet. The events they return
+// have no checksum: WriteBinlogEvent adds it if the replica expects it.
+
+// RowsEventStmtEndFlag is the flag of the last rows event of a statement.
+const RowsEventStmtEndFlag = 0x0001
+
+// IsRowsQuery returns true if the event is a ROWS_QUERY_EVENT, which carries
+// the statement of the rows events that follow it.
+func IsRowsQuery(ev BinlogEvent) bool {
+	return ev.Bytes()[4] == eRowsQueryEvent
+}
+
+// IsHeartbeat returns true if the event is a HEARTBEAT_EVENT, see
+// NewHeartbeatEvent.
+func IsHeartbeat(ev BinlogEvent) bool {
+	return ev.Bytes()[4] == eHeartbeatEvent
+}
+
+// BinlogEventWithoutChecksum returns a copy of the event without its
+// checksum. A FORMAT_DESCRIPTION_EVENT always keeps the room for its
+// checksum, so its checksum algorithm is set to off instead.
+func BinlogEventWithoutChecksum(f BinlogFormat, ev BinlogEvent) (BinlogEvent, error) {
+	if ev.IsFormatDescription() {
+		data := append([]byte(nil), ev.Bytes()...)
+		if len(data) < int(f.HeaderLength)+5 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "FORMAT_DESCRIPTION_EVENT too short: %v bytes", len(data))
+		}
+		data[len(data)-5] = BinlogChecksumAlgOff
+		copy(data[len(data)-4:], []byte{0, 0, 0, 0})
+		return NewMysql56BinlogEvent(data), nil
+	}
+	ev, _, err := ev.StripChecksum(f)
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(nil), ev.Bytes()...)
+	binary.LittleEndian.PutUint32(data[9:13], uint32(len(data)))
+	return NewMysql56BinlogEvent(data), nil
+}
+
+// rowsEventFlagsPos returns the position of the flags of a rows event.
+func rowsEventFlagsPos(f BinlogFormat, data []byte) int {
+	if f.HeaderSize(data[4]) == 6 {
+		return int(f.HeaderLength) + 4
+	}
+	return int(f.HeaderLength) + 6
+}
+
+// RowsEventFlags returns the flags of a rows event.
+func RowsEventFlags(f BinlogFormat, ev BinlogEvent) uint16 {
+	data := ev.Bytes()
+	pos := rowsEventFlagsPos(f, data)
+	return binary.LittleEndian.Uint16(data[pos : pos+2])
+}
+
+// SetRowsEventFlags returns a copy of a rows event without checksum, with
+// other flags.
+func SetRowsEventFlags(f BinlogFormat, ev BinlogEvent, flags uint16) (BinlogEvent, error) {
+	ev, err := BinlogEventWithoutChecksum(f, ev)
+	if err != nil {
+		return nil, err
+	}
+	data := ev.Bytes()
+	binary.LittleEndian.PutUint16(data[rowsEventFlagsPos(f, data):], flags)
+	return ev, nil
+}
+
+// rowsEventBitmapsPos returns the position of the column bitmaps of a rows
+// event, and their length.
+func rowsEventBitmapsPos(f BinlogFormat, data []byte) (int, int) {
+	typ := data[4]
+	pos := rowsEventFlagsPos(f, data) + 2
+	if typ == eWriteRowsEventV2 || typ == eUpdateRowsEventV2 || typ == eDeleteRowsEventV2 {
+		// This extraDataLength contains the 2 bytes length.
+		pos += int(binary.LittleEndian.Uint16(data[pos : pos+2]))
+	}
+	columnCount := int(data[pos])
+	return pos + 1, (columnCount + 7) / 8
+}
+
+// RewriteRowsEvent returns a copy of a rows event without checksum, with the
+// flags and the rows of rows. The rows must have been read from the event,
+// e.g. rows may only have some of them.
+func RewriteRowsEvent(f BinlogFormat, ev BinlogEvent, rows Rows) (BinlogEvent, error) {
+	ev, err := BinlogEventWithoutChecksum(f, ev)
+	if err != nil {
+		return nil, err
+	}
+	data := ev.Bytes()
+	typ := data[4]
+	// The column count is followed by one bitmap for the insert and delete
+	// events, and by two for the update events.
+	pos, bitmapLen := rowsEventBitmapsPos(f, data)
+	pos += bitmapLen
+	if typ == eUpdateRowsEventV1 || typ == eUpdateRowsEventV2 {
+		pos += bitmapLen
+	}
+	if pos > len(data) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "rows event too short: %v bytes", len(data))
+	}
+
+	result := append([]byte(nil), data[:pos]...)
+	return appendRows(f, result, rows), nil
+}
+
+// UpdateRowsEventAs returns a copy of an UPDATE_ROWS_EVENT without checksum,
+// turned into a WRITE_ROWS_EVENT of the after images of the rows of rows if
+// write is set, or else into a DELETE_ROWS_EVENT of their before images, with
+// the flags of rows. The rows must have been read from the event.
+func UpdateRowsEventAs(f BinlogFormat, ev BinlogEvent, rows Rows, write bool) (BinlogEvent, error) {
+	ev, err := BinlogEventWithoutChecksum(f, ev)
+	if err != nil {
+		return nil, err
+	}
+	data := ev.Bytes()
+	var typ byte
+	switch data[4] {
+	case eUpdateRowsEventV1:
+		typ = eDeleteRowsEventV1
+		if write {
+			typ = eWriteRowsEventV1
+		}
+	case eUpdateRowsEventV2:
+		typ = eDeleteRowsEventV2
+		if write {
+			typ = eWriteRowsEventV2
+		}
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "not an UPDATE_ROWS_EVENT: %v", data[4])
+	}
+	// The bitmap of the before images is followed by the one of the after
+	// images.
+	pos, bitmapLen := rowsEventBitmapsPos(f, data)
+	if pos+2*bitmapLen > len(data) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "rows event too short: %v bytes", len(data))
+	}
+
+	result := append([]byte(nil), data[:pos]...)
+	result[4] = typ
+	images := make([]Row, len(rows.Rows))
+	if write {
+		result = append(result, data[pos+bitmapLen:pos+2*bitmapLen]...)
+		for i, row := range rows.Rows {
+			images[i] = Row{NullColumns: row.NullColumns, Data: row.Data}
+		}
+	} else {
+		result = append(result, data[pos:pos+bitmapLen]...)
+		for i, row := range rows.Rows {
+			images[i] = Row{NullIdentifyColumns: row.NullIdentifyColumns, Identify: row.Identify}
+		}
+	}
+	rows.Rows = images
+	return appendRows(f, result, rows), nil
+}
+
+// appendRows returns the rows event of the header and the bitmaps of result,
+// with the flags and the rows of rows.
+func appendRows(f BinlogFormat, result []byte, rows Rows) BinlogEvent {
+	binary.LittleEndian.PutUint16(result[rowsEventFlagsPos(f, result):], rows.Flags)
+	for _, row := range rows.Rows {
+		result = append(result, row.NullIdentifyColumns.data...)
+		result = append(result, row.Identify...)
+		result = append(result, row.NullColumns.data...)
+		result = append(result, row.Data...)
+	}
+	binary.LittleEndian.PutUint32(result[9:13], uint32(len(result)))
+	return NewMysql56BinlogEvent(result)
+}
+
+// RewriteQueryEvent returns a copy of a query event without checksum, with
+// another statement.
+func RewriteQueryEvent(f BinlogFormat, ev BinlogEvent, sql string) (BinlogEvent, error) {
+	ev, err := BinlogEventWithoutChecksum(f, ev)
+	if err != nil {
+		return nil, err
+	}
+	q, err := ev.Query(f)
+	if err != nil {
+		return nil, err
+	}
+	data := ev.Bytes()
+	if !strings.HasSuffix(string(data), q.SQL) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "the statement of the query event is not at its end: %q", q.SQL)
+	}
+	result := append([]byte(nil), data[:len(data)-len(q.SQL)]...)
+	result = append(result, sql...)
+	binary.LittleEndian.PutUint32(result[9:13], uint32(len(result)))
+	return NewMysql56BinlogEvent(result), nil
+}
+
+// RotateEventLogFile returns the binary log file and the position a
+// ROTATE_EVENT without checksum rotates to.
+func RotateEventLogFile(f BinlogFormat, ev BinlogEvent) (string, uint64) {
+	data := ev.Bytes()
+	if len(data) < int(f.HeaderLength)+8 {
+		return "", 0
+	}
+	return string(data[f.HeaderLength+8:]), binary.LittleEndian.Uint64(data[f.HeaderLength:])
+}
+
+// NewHeartbeatEvent returns a HEARTBEAT_EVENT without checksum, which tells
+// a replica the current position of its source when the source has no other
+// event to send.
+func NewHeartbeatEvent(f BinlogFormat, serverID uint32, logFile string, logPos uint32) BinlogEvent {
+	s := &FakeBinlogStream{ServerID: serverID, LogPosition: logPos}
+	data := s.Packetize(BinlogFormat{HeaderLength: f.HeaderLength}, eHeartbeatEvent, 0, []byte(logFile))
+	return NewMysql56BinlogEvent(data)
+}
+
+// WriteBinlogEvent writes a binlog event without checksum to a replica,
+// during a ComBinlogDumpGTID. If checksum is true, a CRC32 checksum is
+// added to the event, and the checksum algorithm of a
+// FORMAT_DESCRIPTION_EVENT is set to CRC32.
+func (c *Conn) WriteBinlogEvent(ev BinlogEvent, checksum bool) error {
+	event := ev.Bytes()
+	length := 1 + len(event)
+	if checksum && !ev.IsFormatDescription() {
+		length += 4
+	}
+	data, pos := c.startEphemeralPacketWithHeader(length)
+	pos = writeByte(data, pos, OKPacket)
+	start := pos
+	pos += copy(data[pos:], event)
+	binary.LittleEndian.PutUint32(data[start+9:], uint32(length-1))
+	if checksum {
+		if ev.IsFormatDescription() {
+			data[pos-5] = BinlogChecksumAlgCRC32
+			pos -= 4
+		}
+		binary.LittleEndian.PutUint32(data[pos:], crc32.ChecksumIEEE(data[start:pos]))
+	}
+	if err := c.writeEphemeralPacket(); err != nil {
+		return NewSQLError(CRServerGone, SSUnknownSQLState, "%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteBinlogEvents(t *testing.T) {
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+
+	tm := &TableMap{
+		Database:  "my_database",
+		Name:      "my_table",
+		Types:     []byte{TypeLong, TypeVarchar},
+		CanBeNull: NewServerBitmap(2),
+		Metadata:  []uint16{0, 384},
+	}
+	rows := Rows{
+		DataColumns: NewServerBitmap(2),
+		Rows: []Row{{
+			NullColumns: NewServerBitmap(2),
+			Data:        []byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 'a'},
+		}, {
+			NullColumns: NewServerBitmap(2),
+			Data:        []byte{0x02, 0x00, 0x00, 0x00, 0x02, 0x00, 'b', 'c'},
+		}},
+	}
+	rows.DataColumns.Set(0, true)
+	rows.DataColumns.Set(1, true)
+
+	event, err := BinlogEventWithoutChecksum(f, NewWriteRowsEvent(f, s, 42, rows))
+	require.NoError(t, err)
+	assert.True(t, event.IsValid())
+	got, err := event.Rows(f, tm)
+	require.NoError(t, err)
+	got.Flags = RowsEventStmtEndFlag
+	got.Rows = got.Rows[1:]
+	rewritten, err := RewriteRowsEvent(f, NewWriteRowsEvent(f, s, 42, rows), got)
+	require.NoError(t, err)
+	assert.True(t, rewritten.IsValid())
+	assert.True(t, rewritten.IsWriteRows())
+	assert.Equal(t, uint64(42), rewritten.TableID(f))
+	rewrittenRows, err := rewritten.Rows(f, tm)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(RowsEventStmtEndFlag), rewrittenRows.Flags)
+	values, err := rewrittenRows.StringValuesForTests(tm, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "bc"}, values)
+	require.Len(t, rewrittenRows.Rows, 1)
+
+	flagged, err := SetRowsEventFlags(f, rewritten, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0), RowsEventFlags(f, flagged))
+	assert.Equal(t, uint16(RowsEventStmtEndFlag), RowsEventFlags(f, rewritten))
+
+	query, err := RewriteQueryEvent(f, NewQueryEvent(f, s, Query{Database: "my_database", SQL: "create table t(id int)"}), "BEGIN")
+	require.NoError(t, err)
+	assert.True(t, query.IsValid())
+	q, err := query.Query(f)
+	require.NoError(t, err)
+	assert.Equal(t, "my_database", q.Database)
+	assert.Equal(t, "BEGIN", q.SQL)
+
+	fde, err := BinlogEventWithoutChecksum(f, NewFormatDescriptionEvent(f, s))
+	require.NoError(t, err)
+	format, err := fde.Format()
+	require.NoError(t, err)
+	assert.Equal(t, byte(BinlogChecksumAlgOff), format.ChecksumAlgorithm)
+
+	rotate, err := BinlogEventWithoutChecksum(f, NewRotateEvent(f, s, 4, "binlog.000002"))
+	require.NoError(t, err)
+	logFile, logPos := RotateEventLogFile(f, rotate)
+	assert.Equal(t, "binlog.000002", logFile)
+	assert.Equal(t, uint64(4), logPos)
+
+	heartbeat := NewHeartbeatEvent(f, 1, "binlog.000002", 1234)
+	assert.True(t, heartbeat.IsValid())
+	assert.True(t, IsHeartbeat(heartbeat))
+	assert.Equal(t, uint32(1234), heartbeat.NextPosition())
+	assert.Equal(t, "binlog.000002", string(heartbeat.Bytes()[f.HeaderLength:]))
+}
+
+func TestUpdateRowsEventAs(t *testing.T) {
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+
+	tm := &TableMap{
+		Database:  "my_database",
+		Name:      "my_table",
+		Types:     []byte{TypeLong, TypeVarchar},
+		CanBeNull: NewServerBitmap(2),
+		Metadata:  []uint16{0, 384},
+	}
+	rows := Rows{
+		IdentifyColumns: NewServerBitmap(2),
+		DataColumns:     NewServerBitmap(2),
+		Rows: []Row{{
+			NullIdentifyColumns: NewServerBitmap(2),
+			NullColumns:         NewServerBitmap(2),
+			Identify:            []byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 'a'},
+			Data:                []byte{0x02, 0x00, 0x00, 0x00, 0x02, 0x00, 'b', 'c'},
+		}},
+	}
+	rows.IdentifyColumns.Set(0, true)
+	rows.IdentifyColumns.Set(1, true)
+	rows.DataColumns.Set(0, true)
+	rows.DataColumns.Set(1, true)
+	update, err := BinlogEventWithoutChecksum(f, NewUpdateRowsEvent(f, s, 42, rows))
+	require.NoError(t, err)
+	got, err := update.Rows(f, tm)
+	require.NoError(t, err)
+
+	write, err := UpdateRowsEventAs(f, update, got, true)
+	require.NoError(t, err)
+	assert.True(t, write.IsValid())
+	assert.True(t, write.IsWriteRows())
+	assert.Equal(t, uint64(42), write.TableID(f))
+	writeRows, err := write.Rows(f, tm)
+	require.NoError(t, err)
+	require.Len(t, writeRows.Rows, 1)
+	values, err := writeRows.StringValuesForTests(tm, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "bc"}, values)
+
+	got.Flags = RowsEventStmtEndFlag
+	del, err := UpdateRowsEventAs(f, update, got, false)
+	require.NoError(t, err)
+	assert.True(t, del.IsValid())
+	assert.True(t, del.IsDeleteRows())
+	assert.Equal(t, uint16(RowsEventStmtEndFlag), RowsEventFlags(f, del))
+	delRows, err := del.Rows(f, tm)
+	require.NoError(t, err)
+	require.Len(t, delRows.Rows, 1)
+	identifies, err := delRows.StringIdentifiesForTests(tm, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "a"}, identifies)
+
+	_, err = UpdateRowsEventAs(f, NewWriteRowsEvent(f, s, 42, rows), got, true)
+	assert.ErrorContains(t, err, "not an UPDATE_ROWS_EVENT")
+}
+
+func TestWriteBinlogEvent(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+	fde, err := BinlogEventWithoutChecksum(f, NewFormatDescriptionEvent(f, s))
+	require.NoError(t, err)
+	xid, err := BinlogEventWithoutChecksum(f, NewXIDEvent(f, s))
+	require.NoError(t, err)
+
+	for _, checksum := range []bool{false, true} {
+		for _, ev := range []BinlogEvent{fde, xid} {
+			require.NoError(t, sConn.WriteBinlogEvent(ev, checksum))
+			data, err := cConn.ReadPacket()
+			require.NoError(t, err)
+			require.Equal(t, byte(OKPacket), data[0])
+			relayed := NewMysql56BinlogEvent(data[1:])
+			require.True(t, relayed.IsValid())
+
+			if ev.IsFormatDescription() {
+				format, err := relayed.Format()
+				require.NoError(t, err)
+				if checksum {
+					assert.Equal(t, byte(BinlogChecksumAlgCRC32), format.ChecksumAlgorithm)
+				} else {
+					assert.Equal(t, byte(BinlogChecksumAlgOff), format.ChecksumAlgorithm)
+				}
+				assert.Equal(t, len(ev.Bytes()), len(relayed.Bytes()))
+			} else if checksum {
+				assert.Equal(t, len(ev.Bytes())+4, len(relayed.Bytes()))
+			} else {
+				assert.Equal(t, ev.Bytes(), relayed.Bytes())
+			}
+			if checksum {
+				event := relayed.Bytes()
+				assert.Equal(t, crc32.ChecksumIEEE(event[:len(event)-4]), binary.LittleEndian.Uint32(event[len(event)-4:]))
+			}
+		}
+	}
+}
@@ -329,6 +329,23 @@ func (c *Conn) stopFlushTimer() {
 	}
 }
 
+// FlushBuffer sends the buffered response to the client right away,
+// instead of after --mysql_server_flush_delay. A handler that streams a
+// result calls it after each batch of rows, so that the client receives
+// the rows as they are produced. The write blocks while the client does
+// not read, which holds the stream back, up to the write timeout of the
+// listener.
+func (c *Conn) FlushBuffer() error {
+	c.bufMu.Lock()
+	defer c.bufMu.Unlock()
+
+	if c.bufferedWriter == nil || c.bufferedWriter.Buffered() == 0 {
+		return nil
+	}
+	c.stopFlushTimer()
+	return c.bufferedWriter.Flush()
+}
+
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
 // depending on which buffer size was passed to newServerConn.
 func (c *Conn) getReader() io.Reader {
@@ -678,6 +695,12 @@ func (c *Conn) RemoteAddr() net.Addr {
 	return c.conn.RemoteAddr()
 }
 
+// SchemaName returns the default database name the client connected with.
+// It does not reflect later 'USE' statements.
+func (c *Conn) SchemaName() string {
+	return c.schemaName
+}
+
 // ID returns the MySQL connection ID for this connection.
 func (c *Conn) ID() int64 {
 	return int64(c.ConnectionID)
@@ -696,6 +719,41 @@ func (c *Conn) Close() {
 	}
 }
 
+// CloseWithError writes an error packet to the client, as the response to
+// its next command, and closes the connection. It can be called from a
+// different go routine than the one that serves the connection, while the
+// connection waits for the next command, to let the client know why it is
+// disconnected, like MySQL does when it disconnects idle clients.
+func (c *Conn) CloseWithError(err *SQLError) {
+	if c.closed.Get() {
+		return
+	}
+	// The packet is written directly, as the go routine that serves the
+	// connection owns its buffers.
+	message := err.Message
+	length := 1 + 2 + 1 + 5 + len(message)
+	data := make([]byte, packetHeaderSize+length)
+	data[0] = byte(length)
+	data[1] = byte(length >> 8)
+	data[2] = byte(length >> 16)
+	data[3] = 1
+	pos := writeByte(data, packetHeaderSize, ErrPacket)
+	pos = writeUint16(data, pos, uint16(err.Num))
+	pos = writeByte(data, pos, '#')
+	state := err.State
+	if len(state) != 5 {
+		state = SSUnknownSQLState
+	}
+	pos = writeEOFString(data, pos, state)
+	_ = writeEOFString(data, pos, message)
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	if _, werr := c.conn.Write(data); werr != nil {
+		log.Warningf("Cannot write the error packet to %s before closing it: %v", c, werr)
+	}
+	c.Close()
+}
+
 // IsClosed returns true if this connection was ever closed by the
 // Close() method.  Note if the other side closes the connection, but
 // Close() wasn't called, this will return false.
@@ -915,6 +973,14 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		if !c.writeErrorAndLog(ERUnknownComError, SSNetError, "command handling not implemented yet: %v", data[0]) {
 			return false
 		}
+	case ComRegisterReplica:
+		// The replicas register before they dump the binary logs, which
+		// the handler may refuse.
+		c.recycleReadPacket()
+		if err := c.writeOKPacket(&PacketOK{}); err != nil {
+			log.Errorf("Error writing ComRegisterReplica result to %s: %v", c, err)
+			return false
+		}
 	case ComBinlogDumpGTID:
 		return c.handleComBinlogDumpGTID(handler, data)
 	default:
@@ -929,7 +995,9 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 }
 
 func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue bool) {
-	defer c.recycleReadPacket()
+	_, _, position, err := c.parseComBinlogDumpGTID(data)
+	// The handler writes the binlog events while it dumps them.
+	c.recycleReadPacket()
 
 	c.startWriterBuffering()
 	defer func() {
@@ -939,12 +1007,17 @@ func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue b
 		}
 	}()
 
-	_, _, position, err := c.parseComBinlogDumpGTID(data)
 	if err != nil {
 		log.Errorf("conn %v: parseComBinlogDumpGTID failed: %v", c.ID(), err)
-		kontinue = false
+		return false
+	}
+	if err := handler.ComBinlogDumpGTID(c, position.GTIDSet); err != nil {
+		log.Errorf("conn %v: ComBinlogDumpGTID failed: %v", c.ID(), err)
+		if werr := c.writeErrorPacketFromError(err); werr != nil {
+			log.Errorf("Error writing ComBinlogDumpGTID error to %s: %v", c, werr)
+			return false
+		}
 	}
-	handler.ComBinlogDumpGTID(c, position.GTIDSet)
 
 	return true
 }
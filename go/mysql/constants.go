@@ -213,6 +213,9 @@ const (
 	// ComBinlogDump is COM_BINLOG_DUMP.
 	ComBinlogDump = 0x12
 
+	// ComRegisterReplica is COM_REGISTER_SLAVE.
+	ComRegisterReplica = 0x15
+
 	// ComSemiSyncAck is SEMI_SYNC_ACK.
 	ComSemiSyncAck = 0xef
 
@@ -557,6 +560,12 @@ const (
 
 	// server not available
 	ERServerIsntAvailable = 3168
+
+	// memory capacity exceeded
+	ERCapacityExceeded = 3170
+
+	// client disconnected because of inactivity
+	ERClientInteractionTimeout = 4031
 )
 
 // Sql states for errors.
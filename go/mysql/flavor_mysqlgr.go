@@ -218,6 +218,41 @@ func parseReplicationApplierLag(res *ReplicationStatus, row []sqltypes.Value) {
 	}
 }
 
+// GroupMember is the state of a mysqld in its replication group.
+type GroupMember struct {
+	// State is the MEMBER_STATE of the mysqld: ONLINE, RECOVERING, OFFLINE,
+	// ERROR or UNREACHABLE.
+	State string
+	// Primary is set if the mysqld is the primary of its group.
+	Primary bool
+}
+
+// ShowGroupMember returns the state of the mysqld in its replication group.
+// It returns ErrNoGroupStatus if the mysqld is not a member of a group.
+func (c *Conn) ShowGroupMember() (GroupMember, error) {
+	query := `SELECT
+		MEMBER_STATE,
+		MEMBER_ROLE
+	FROM
+		performance_schema.replication_group_members
+	WHERE
+		MEMBER_ID=@@server_uuid`
+	var member GroupMember
+	err := fetchStatusForGroupReplication(c, query, func(values []sqltypes.Value) error {
+		parseGroupMember(&member, values)
+		return nil
+	})
+	if err != nil {
+		return GroupMember{}, err
+	}
+	return member, nil
+}
+
+func parseGroupMember(member *GroupMember, row []sqltypes.Value) {
+	member.State = row[0].ToString()                /* MEMBER_STATE */
+	member.Primary = row[1].ToString() == "PRIMARY" /* MEMBER_ROLE */
+}
+
 func fetchStatusForGroupReplication(c *Conn, query string, onResult func([]sqltypes.Value) error) error {
 	qr, err := c.ExecuteFetch(query, 100, true /* wantfields */)
 	if err != nil {
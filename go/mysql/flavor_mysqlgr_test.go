@@ -51,3 +51,21 @@ func TestMysqlGRReplicationApplierLagParse(t *testing.T) {
 	parseReplicationApplierLag(&res, row)
 	assert.Equal(t, uint(100), res.ReplicationLagSeconds)
 }
+
+func TestMysqlGRParseGroupMember(t *testing.T) {
+	member := GroupMember{}
+	row := []sqltypes.Value{
+		sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("ONLINE")),
+		sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("PRIMARY")),
+	}
+	parseGroupMember(&member, row)
+	assert.Equal(t, "ONLINE", member.State)
+	assert.Equal(t, true, member.Primary)
+	row = []sqltypes.Value{
+		sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("RECOVERING")),
+		sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("SECONDARY")),
+	}
+	parseGroupMember(&member, row)
+	assert.Equal(t, "RECOVERING", member.State)
+	assert.Equal(t, false, member.Primary)
+}
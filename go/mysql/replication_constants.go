@@ -190,11 +190,10 @@ const (
 	eDeleteRowsEventV1 = 25
 	// Unused
 	//eIncidentEvent          = 26
-	//eHeartbeatEvent         = 27
+	eHeartbeatEvent = 27
 	// Unused
 	//eIgnorableEvent         = 28
-	// Unused
-	//eRowsQueryEvent         = 29
+	eRowsQueryEvent     = 29
 	eWriteRowsEventV2   = 30
 	eUpdateRowsEventV2  = 31
 	eDeleteRowsEventV2  = 32
@@ -127,6 +127,28 @@ func TestComBinlogDumpGTID(t *testing.T) {
 	if !reflect.DeepEqual(data, expectedData) {
 		t.Errorf("ComBinlogDumpGTID returned unexpected data:\n%v\nwas expecting:\n%v", data, expectedData)
 	}
+	sConn.sequence = 0
+
+	// Write ComBinlogDumpGTID packet with a GTID set, read it, parse it.
+	parsed, err := parseMysql56GTIDSet("00010203-0405-0607-0809-0a0b0c0d0e0f:1-5")
+	if err != nil {
+		t.Fatalf("parseMysql56GTIDSet failed: %v", err)
+	}
+	gtidSet := parsed.(Mysql56GTIDSet)
+	if err := cConn.WriteComBinlogDumpGTID(0x01020304, "", 4, 0, gtidSet.SIDBlock()); err != nil {
+		t.Fatalf("WriteComBinlogDumpGTID failed: %v", err)
+	}
+	data, err = sConn.ReadPacket()
+	if err != nil {
+		t.Fatalf("sConn.ReadPacket - ComBinlogDumpGTID failed: %v", err)
+	}
+	_, _, position, err := sConn.parseComBinlogDumpGTID(data)
+	if err != nil {
+		t.Fatalf("parseComBinlogDumpGTID failed: %v", err)
+	}
+	if !gtidSet.Equal(position.GTIDSet) {
+		t.Errorf("parseComBinlogDumpGTID returned %v, was expecting %v", position.GTIDSet, gtidSet)
+	}
 }
 
 func TestSendSemiSyncAck(t *testing.T) {
@@ -457,6 +457,15 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	c.User = user
 	c.UserData = userData
 
+	if limiter, ok := l.handler.(ConnectionLimiter); ok {
+		if err := limiter.AcquireConnection(c); err != nil {
+			log.Warningf("Refusing connection %s of user %s: %v", c, user, err)
+			c.writeErrorPacketFromError(err)
+			return
+		}
+		defer limiter.ReleaseConnection(c)
+	}
+
 	if c.User != "" {
 		connCountPerUser.Add(c.User, 1)
 		defer connCountPerUser.Add(c.User, -1)
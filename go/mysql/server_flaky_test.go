@@ -183,13 +183,16 @@ func (th *testHandler) ComQuery(c *Conn, query string, callback func(*sqltypes.R
 				},
 			},
 		})
-	case "50ms delay":
+	case "50ms delay", "flushed 50ms delay":
 		callback(&sqltypes.Result{
 			Fields: []*querypb.Field{{
 				Name: "result",
 				Type: querypb.Type_VARCHAR,
 			}},
 		})
+		if query == "flushed 50ms delay" {
+			c.FlushBuffer()
+		}
 		time.Sleep(50 * time.Millisecond)
 		callback(&sqltypes.Result{
 			Rows: [][]sqltypes.Value{{
@@ -1407,3 +1410,45 @@ func TestServerFlush(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, row)
 }
+
+func TestServerFlushBuffer(t *testing.T) {
+	defer func(saved time.Duration) { *mysqlServerFlushDelay = saved }(*mysqlServerFlushDelay)
+	*mysqlServerFlushDelay = time.Second
+
+	th := &testHandler{}
+
+	l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false)
+	require.NoError(t, err)
+	defer l.Close()
+	go l.Accept()
+
+	host, port := getHostPort(t, l.Addr())
+	params := &ConnParams{
+		Host: host,
+		Port: port,
+	}
+
+	c, err := Connect(context.Background(), params)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The fields are flushed by the handler, without waiting for the flush delay.
+	start := time.Now()
+	err = c.ExecuteStreamFetch("flushed 50ms delay")
+	require.NoError(t, err)
+
+	_, err = c.Fields()
+	require.NoError(t, err)
+	if duration, want := time.Since(start), 40*time.Millisecond; duration > want {
+		assert.Fail(t, "duration is too high", "duration: %v, want < %v", duration, want)
+	}
+
+	row, err := c.FetchNext(nil)
+	require.NoError(t, err)
+	want := []sqltypes.Value{sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte("delayed"))}
+	assert.Equal(t, want, row)
+
+	row, err = c.FetchNext(nil)
+	require.NoError(t, err)
+	assert.Nil(t, row)
+}
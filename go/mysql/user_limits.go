@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+// UserLimits are the limits of the connections and of the queries of a
+// MySQL user. Zero means unlimited.
+type UserLimits struct {
+	// MaxConnections is the maximum number of connections of the user.
+	MaxConnections int
+	// MaxConcurrentQueries is the maximum number of queries of the user
+	// that are executed at the same time, across all its connections.
+	MaxConcurrentQueries int
+}
+
+// UserLimitsProvider is implemented by the AuthServers that configure the
+// limits of their users.
+type UserLimitsProvider interface {
+	// UserLimits returns the limits of a user.
+	UserLimits(user string) UserLimits
+}
+
+// ConnectionLimiter is implemented by the Handlers that limit the
+// connections of the users.
+type ConnectionLimiter interface {
+	// AcquireConnection is called once the user of the connection is
+	// authenticated, before the handshake completes. If it returns an
+	// error, the connection is refused with it. Otherwise,
+	// ReleaseConnection is called when the connection is closed.
+	AcquireConnection(c *Conn) error
+
+	// ReleaseConnection is called when a connection that was acquired is
+	// closed.
+	ReleaseConnection(c *Conn)
+}
+
+// NewTooManyUserConnectionsError returns the error of a connection refused
+// because its user has too many connections.
+func NewTooManyUserConnectionsError(user string) *SQLError {
+	return NewSQLError(ERTooManyUserConnections, SSClientError, "User %s already has more than 'max_user_connections' active connections", user)
+}
+
+// NewUserLimitReachedError returns the error of a query refused because its
+// user exceeded one of its limits.
+func NewUserLimitReachedError(user, limit string, value int) *SQLError {
+	return NewSQLError(ERUserLimitReached, SSClientError, "User '%s' has exceeded the '%s' resource (current value: %d)", user, limit, value)
+}
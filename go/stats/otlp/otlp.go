@@ -0,0 +1,441 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package otlp adds support for pushing stats to an OpenTelemetry collector,
+// or any other receiver of the OTLP/HTTP metrics protocol, in its JSON
+// encoding.
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	otlpMetricsEndpoint = flag.String("otlp_metrics_endpoint", "", "URL of the OTLP/HTTP metrics endpoint the stats are pushed to with --stats_backend otlp, e.g. http://localhost:4318/v1/metrics")
+	otlpMetricsHeaders  = flag.String("otlp_metrics_headers", "", "Comma-separated list of the headers of the requests to --otlp_metrics_endpoint. Example: header1:value1,header2:value2")
+	otlpMetricsTimeout  = flag.Duration("otlp_metrics_timeout", 10*time.Second, "Timeout of the requests to --otlp_metrics_endpoint")
+)
+
+const (
+	// scopeName is the name of the instrumentation scope of the metrics.
+	scopeName = "vitess.io/vitess/go/stats"
+
+	// aggregationTemporalityCumulative is the AGGREGATION_TEMPORALITY_CUMULATIVE
+	// value of the AggregationTemporality enum: the stats are reported as
+	// their value since the start of the process.
+	aggregationTemporalityCumulative = 2
+)
+
+// The following types are the JSON encoding of the ExportMetricsServiceRequest
+// message of the OTLP protocol and the messages it contains. Following the
+// protobuf JSON mapping, the 64-bit integers are encoded as strings.
+
+type exportMetricsServiceRequest struct {
+	ResourceMetrics []*resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource        `json:"resource"`
+	ScopeMetrics []*scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   instrumentationScope `json:"scope"`
+	Metrics []*metric            `json:"metrics"`
+}
+
+type instrumentationScope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+type sum struct {
+	DataPoints             []*numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                `json:"aggregationTemporality"`
+	IsMonotonic            bool               `json:"isMonotonic"`
+}
+
+type gauge struct {
+	DataPoints []*numberDataPoint `json:"dataPoints"`
+}
+
+type histogram struct {
+	DataPoints             []*histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsInt             string     `json:"asInt,omitempty"`
+	AsDouble          *float64   `json:"asDouble,omitempty"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+// otlpBackend implements stats.PushBackend
+type otlpBackend struct {
+	// The namespace is the name of the binary (vtgate, vttablet, etc.). It is
+	// the service.name attribute of the resource, and prefixes the names of
+	// the metrics.
+	namespace string
+	// The resource attributes other than service.name, from the common tags.
+	commonTags map[string]string
+	endpoint   string
+	headers    map[string]string
+	client     *http.Client
+	// startTime is the start of the cumulative values of the stats.
+	startTime time.Time
+}
+
+// dataCollector tracks state for a single pass of stats reporting / data collection.
+type dataCollector struct {
+	settings  *otlpBackend
+	startTime string
+	time      string
+	metrics   []*metric
+}
+
+// Init attempts to create a singleton otlpBackend and register it as a PushBackend.
+// If it fails to create one, this is a noop.
+func Init(namespace string) {
+	// Needs to happen in servenv.OnRun() instead of init because it requires flag parsing and logging
+	servenv.OnRun(func() {
+		InitWithoutServenv(namespace)
+	})
+}
+
+// InitWithoutServenv initializes the otlp backend without servenv
+func InitWithoutServenv(namespace string) {
+	if *otlpMetricsEndpoint == "" {
+		return
+	}
+
+	backend := &otlpBackend{
+		namespace:  namespace,
+		commonTags: stats.ParseCommonTags(*stats.CommonTags),
+		endpoint:   *otlpMetricsEndpoint,
+		headers:    stats.ParseCommonTags(*otlpMetricsHeaders),
+		client:     &http.Client{Timeout: *otlpMetricsTimeout},
+		startTime:  time.Now(),
+	}
+
+	stats.RegisterPushBackend("otlp", backend)
+
+	http.HandleFunc("/debug/otlp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if b, err := json.MarshalIndent(backend.getRequest(time.Now()), "", "  "); err != nil {
+			w.Write([]byte(err.Error()))
+		} else {
+			w.Write(b)
+		}
+	})
+}
+
+// PushAll pushes all stats to the OTLP endpoint.
+func (backend *otlpBackend) PushAll() error {
+	body, err := json.Marshal(backend.getRequest(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, backend.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range backend.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := backend.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OTLP endpoint %s returned %s: %s", backend.endpoint, resp.Status, msg)
+	}
+	return nil
+}
+
+// getRequest fetches all stats as an OTLP export request.
+// This is separated from PushAll() so it can be reused for the /debug/otlp handler.
+func (backend *otlpBackend) getRequest(now time.Time) *exportMetricsServiceRequest {
+	dc := &dataCollector{
+		settings:  backend,
+		startTime: strconv.FormatInt(backend.startTime.UnixNano(), 10),
+		time:      strconv.FormatInt(now.UnixNano(), 10),
+	}
+	expvar.Do(func(kv expvar.KeyValue) {
+		dc.addExpVar(kv)
+	})
+
+	attributes := []keyValue{stringAttribute("service.name", backend.namespace)}
+	for _, k := range sortedKeys(backend.commonTags) {
+		if k != "service.name" {
+			attributes = append(attributes, stringAttribute(k, backend.commonTags[k]))
+		}
+	}
+	return &exportMetricsServiceRequest{
+		ResourceMetrics: []*resourceMetrics{{
+			Resource: resource{Attributes: attributes},
+			ScopeMetrics: []*scopeMetrics{{
+				Scope:   instrumentationScope{Name: scopeName},
+				Metrics: dc.metrics,
+			}},
+		}},
+	}
+}
+
+func stringAttribute(key, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: value}}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metricName returns the name of the metric of a stat: the snake case name of
+// the stat prefixed by the namespace, like the Prometheus metrics.
+func (dc *dataCollector) metricName(name string) string {
+	name = stats.GetSnakeName(name)
+	if dc.settings.namespace == "" {
+		return name
+	}
+	return dc.settings.namespace + "_" + name
+}
+
+// addExpVar adds the metric of an expvar. How an expvar is translated depends
+// on its type: the counters are monotonic sums, the gauges are gauges and the
+// timings and histograms are histograms, with their labels as attributes.
+// The durations are reported in seconds. Other expvars are not exported.
+func (dc *dataCollector) addExpVar(kv expvar.KeyValue) {
+	k := kv.Key
+	switch v := kv.Value.(type) {
+	case *stats.Counter:
+		dc.addSum(k, v.Help(), "", map[string]int64{"": v.Get()}, nil)
+	case *stats.CounterFunc:
+		dc.addSum(k, v.Help(), "", map[string]int64{"": v.F()}, nil)
+	case *stats.CountersWithSingleLabel:
+		dc.addSum(k, v.Help(), "", v.Counts(), []string{v.Label()})
+	case *stats.CountersWithMultiLabels:
+		dc.addSum(k, v.Help(), "", v.Counts(), v.Labels())
+	case *stats.CountersFuncWithMultiLabels:
+		dc.addSum(k, v.Help(), "", v.Counts(), v.Labels())
+	case *stats.CounterDuration:
+		dc.addDoubleMetric(k, v.Help(), v.Get().Seconds(), true)
+	case *stats.CounterDurationFunc:
+		dc.addDoubleMetric(k, v.Help(), v.F().Seconds(), true)
+	case *stats.Gauge:
+		dc.addGauge(k, v.Help(), map[string]int64{"": v.Get()}, nil)
+	case *stats.GaugeFunc:
+		dc.addGauge(k, v.Help(), map[string]int64{"": v.F()}, nil)
+	case *stats.GaugesWithSingleLabel:
+		dc.addGauge(k, v.Help(), v.Counts(), []string{v.Label()})
+	case *stats.GaugesWithMultiLabels:
+		dc.addGauge(k, v.Help(), v.Counts(), v.Labels())
+	case *stats.GaugesFuncWithMultiLabels:
+		dc.addGauge(k, v.Help(), v.Counts(), v.Labels())
+	case *stats.GaugeFloat64:
+		dc.addDoubleMetric(k, v.Help(), v.Get(), false)
+	case stats.FloatFunc:
+		dc.addDoubleMetric(k, v.Help(), v(), false)
+	case *stats.GaugeDuration:
+		dc.addDoubleMetric(k, v.Help(), v.Get().Seconds(), false)
+	case *stats.GaugeDurationFunc:
+		dc.addDoubleMetric(k, v.Help(), v.F().Seconds(), false)
+	case *stats.MultiTimings:
+		dc.addTimings(k, v.Help(), v.Labels(), &v.Timings)
+	case *stats.Timings:
+		dc.addTimings(k, v.Help(), []string{v.Label()}, v)
+	case *stats.Histogram:
+		dc.addHistogram(k, v.Help(), "", []*histogramDataPoint{dc.histogramDataPoint(v, nil, 1)})
+	}
+}
+
+// attributes breaks apart the vitess stat representation of label values
+// ("."-separated list) into attributes.
+func attributes(labelNames []string, labelValsCombined string) []keyValue {
+	if len(labelNames) == 0 {
+		return nil
+	}
+	labelVals := strings.Split(labelValsCombined, ".")
+	attrs := make([]keyValue, 0, len(labelNames))
+	for i, v := range labelVals {
+		if i < len(labelNames) {
+			attrs = append(attrs, stringAttribute(stats.GetSnakeName(labelNames[i]), v))
+		}
+	}
+	return attrs
+}
+
+func (dc *dataCollector) intDataPoints(counts map[string]int64, labels []string, startTime string) []*numberDataPoint {
+	dataPoints := make([]*numberDataPoint, 0, len(counts))
+	for _, labelVals := range sortedKeys(counts) {
+		dataPoints = append(dataPoints, &numberDataPoint{
+			Attributes:        attributes(labels, labelVals),
+			StartTimeUnixNano: startTime,
+			TimeUnixNano:      dc.time,
+			AsInt:             strconv.FormatInt(counts[labelVals], 10),
+		})
+	}
+	return dataPoints
+}
+
+func (dc *dataCollector) addSum(name, help, unit string, counts map[string]int64, labels []string) {
+	dc.metrics = append(dc.metrics, &metric{
+		Name:        dc.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Sum: &sum{
+			DataPoints:             dc.intDataPoints(counts, labels, dc.startTime),
+			AggregationTemporality: aggregationTemporalityCumulative,
+			IsMonotonic:            true,
+		},
+	})
+}
+
+func (dc *dataCollector) addGauge(name, help string, counts map[string]int64, labels []string) {
+	dc.metrics = append(dc.metrics, &metric{
+		Name:        dc.metricName(name),
+		Description: help,
+		Gauge:       &gauge{DataPoints: dc.intDataPoints(counts, labels, "")},
+	})
+}
+
+// addDoubleMetric adds a metric without labels with a float value, which is a
+// monotonic sum if cumulative is set, or a gauge. The float metrics of vitess
+// are durations in seconds, except for the generic GaugeFloat64 and FloatFunc.
+func (dc *dataCollector) addDoubleMetric(name, help string, value float64, cumulative bool) {
+	m := &metric{
+		Name:        dc.metricName(name),
+		Description: help,
+	}
+	dataPoint := &numberDataPoint{
+		TimeUnixNano: dc.time,
+		AsDouble:     &value,
+	}
+	if cumulative {
+		m.Unit = "s"
+		dataPoint.StartTimeUnixNano = dc.startTime
+		m.Sum = &sum{
+			DataPoints:             []*numberDataPoint{dataPoint},
+			AggregationTemporality: aggregationTemporalityCumulative,
+			IsMonotonic:            true,
+		}
+	} else {
+		m.Gauge = &gauge{DataPoints: []*numberDataPoint{dataPoint}}
+	}
+	dc.metrics = append(dc.metrics, m)
+}
+
+// addTimings adds a vitess Timings stat as a histogram in seconds.
+func (dc *dataCollector) addTimings(name, help string, labels []string, timings *stats.Timings) {
+	histograms := timings.Histograms()
+	dataPoints := make([]*histogramDataPoint, 0, len(histograms))
+	for _, labelVals := range sortedKeys(histograms) {
+		dataPoints = append(dataPoints, dc.histogramDataPoint(histograms[labelVals], attributes(labels, labelVals), float64(time.Second)))
+	}
+	dc.addHistogram(name, help, "s", dataPoints)
+}
+
+func (dc *dataCollector) addHistogram(name, help, unit string, dataPoints []*histogramDataPoint) {
+	dc.metrics = append(dc.metrics, &metric{
+		Name:        dc.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Histogram: &histogram{
+			DataPoints:             dataPoints,
+			AggregationTemporality: aggregationTemporalityCumulative,
+		},
+	})
+}
+
+// histogramDataPoint converts a vitess Histogram, whose buckets count the
+// values up to and including their cutoff like the OTLP buckets, with its
+// values divided by divideBy.
+func (dc *dataCollector) histogramDataPoint(h *stats.Histogram, attrs []keyValue, divideBy float64) *histogramDataPoint {
+	buckets := h.Buckets()
+	bucketCounts := make([]string, len(buckets))
+	var count int64
+	for i, bucket := range buckets {
+		bucketCounts[i] = strconv.FormatInt(bucket, 10)
+		count += bucket
+	}
+	cutoffs := h.Cutoffs()
+	explicitBounds := make([]float64, len(cutoffs))
+	for i, cutoff := range cutoffs {
+		explicitBounds[i] = float64(cutoff) / divideBy
+	}
+	return &histogramDataPoint{
+		Attributes:        attrs,
+		StartTimeUnixNano: dc.startTime,
+		TimeUnixNano:      dc.time,
+		Count:             strconv.FormatInt(count, 10),
+		Sum:               float64(h.Total()) / divideBy,
+		BucketCounts:      bucketCounts,
+		ExplicitBounds:    explicitBounds,
+	}
+}
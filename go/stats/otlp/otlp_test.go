@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+)
+
+func newTestBackend(endpoint string) *otlpBackend {
+	return &otlpBackend{
+		namespace:  "vtgate",
+		commonTags: map[string]string{"cell": "zone1"},
+		endpoint:   endpoint,
+		headers:    map[string]string{"Api-Key": "secret"},
+		client:     &http.Client{Timeout: time.Second},
+		startTime:  time.Unix(1661000000, 0),
+	}
+}
+
+// findMetric returns the metric of a request by name.
+func findMetric(t *testing.T, req *exportMetricsServiceRequest, name string) *metric {
+	t.Helper()
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == name {
+			return m
+		}
+	}
+	require.Failf(t, "metric not found", name)
+	return nil
+}
+
+func TestCounters(t *testing.T) {
+	c := stats.NewCountersWithMultiLabels("OTLPQueries", "queries", []string{"Keyspace", "TabletType"})
+	c.Add([]string{"ks", "primary"}, 3)
+	c.Add([]string{"ks", "replica"}, 1)
+
+	req := newTestBackend("").getRequest(time.Unix(1661000010, 0))
+	m := findMetric(t, req, "vtgate_otlp_queries")
+	assert.Equal(t, "queries", m.Description)
+	require.NotNil(t, m.Sum)
+	assert.True(t, m.Sum.IsMonotonic)
+	assert.Equal(t, aggregationTemporalityCumulative, m.Sum.AggregationTemporality)
+	require.Len(t, m.Sum.DataPoints, 2)
+	dp := m.Sum.DataPoints[0]
+	assert.Equal(t, []keyValue{stringAttribute("keyspace", "ks"), stringAttribute("tablet_type", "primary")}, dp.Attributes)
+	assert.Equal(t, "3", dp.AsInt)
+	assert.Equal(t, "1661000000000000000", dp.StartTimeUnixNano)
+	assert.Equal(t, "1661000010000000000", dp.TimeUnixNano)
+	assert.Equal(t, "1", m.Sum.DataPoints[1].AsInt)
+
+	assert.Equal(t, []keyValue{stringAttribute("service.name", "vtgate"), stringAttribute("cell", "zone1")}, req.ResourceMetrics[0].Resource.Attributes)
+}
+
+func TestGauges(t *testing.T) {
+	stats.NewGaugeFunc("OTLPGauge", "gauge", func() int64 { return 0 })
+	stats.NewGaugeDuration("OTLPGaugeDuration", "duration").Set(1500 * time.Millisecond)
+
+	req := newTestBackend("").getRequest(time.Now())
+	m := findMetric(t, req, "vtgate_otlp_gauge")
+	require.NotNil(t, m.Gauge)
+	require.Len(t, m.Gauge.DataPoints, 1)
+	assert.Empty(t, m.Gauge.DataPoints[0].StartTimeUnixNano)
+
+	// A zero value is encoded.
+	b, err := json.Marshal(m.Gauge.DataPoints[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"asInt":"0"`)
+
+	m = findMetric(t, req, "vtgate_otlp_gauge_duration")
+	require.NotNil(t, m.Gauge)
+	assert.Equal(t, 1.5, *m.Gauge.DataPoints[0].AsDouble)
+}
+
+func TestTimings(t *testing.T) {
+	timings := stats.NewTimings("OTLPTimings", "timings", "Operation")
+	timings.Add("Execute", 2*time.Millisecond)
+	timings.Add("Execute", 2*time.Second)
+
+	m := findMetric(t, newTestBackend("").getRequest(time.Now()), "vtgate_otlp_timings")
+	assert.Equal(t, "s", m.Unit)
+	require.NotNil(t, m.Histogram)
+	require.Len(t, m.Histogram.DataPoints, 1)
+	dp := m.Histogram.DataPoints[0]
+	assert.Equal(t, []keyValue{stringAttribute("operation", "Execute")}, dp.Attributes)
+	assert.Equal(t, "2", dp.Count)
+	assert.InDelta(t, 2.002, dp.Sum, 1e-9)
+	assert.Equal(t, []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}, dp.ExplicitBounds)
+	assert.Equal(t, []string{"0", "0", "1", "0", "0", "0", "0", "0", "1", "0", "0"}, dp.BucketCounts)
+}
+
+func TestPushAll(t *testing.T) {
+	stats.NewCounter("OTLPPushed", "pushed").Add(1)
+
+	var req exportMetricsServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Api-Key"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &req))
+	}))
+	defer server.Close()
+
+	require.NoError(t, newTestBackend(server.URL).PushAll())
+	assert.Equal(t, "1", findMetric(t, &req, "vtgate_otlp_pushed").Sum.DataPoints[0].AsInt)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	err := newTestBackend(failing.URL).PushAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request: invalid request")
+}
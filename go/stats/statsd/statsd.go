@@ -26,6 +26,10 @@ type StatsBackend struct {
 	namespace    string
 	statsdClient *statsd.Client
 	sampleRate   float64
+	// counts is set for DogStatsD, which aggregates the counts it receives
+	// over each flush interval, so that the counters are sent as the delta
+	// since the previous push rather than their cumulative value.
+	counts *counterDeltas
 }
 
 var (
@@ -33,6 +37,39 @@ var (
 	buildGitRecOnce sync.Once
 )
 
+// counterDeltas keeps the values of the counters sent by the previous push,
+// by name and tags.
+type counterDeltas struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func newCounterDeltas() *counterDeltas {
+	return &counterDeltas{values: make(map[string]int64)}
+}
+
+// delta returns the increase of a counter since the previous call. A counter
+// that decreased was reset, and its whole value is returned.
+func (cd *counterDeltas) delta(name string, tags []string, value int64) int64 {
+	key := name + "|" + strings.Join(tags, ",")
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	previous, ok := cd.values[key]
+	cd.values[key] = value
+	if !ok || value < previous {
+		return value
+	}
+	return value - previous
+}
+
+// count sends the value of a counter.
+func (sb StatsBackend) count(name string, value int64, tags []string) error {
+	if sb.counts != nil {
+		value = sb.counts.delta(name, tags, value)
+	}
+	return sb.statsdClient.Count(name, value, tags, sb.sampleRate)
+}
+
 // makeLabel builds a tag list with a single label + value.
 func makeLabel(labelName string, labelVal string) []string {
 	return []string{fmt.Sprintf("%s:%s", labelName, labelVal)}
@@ -65,7 +102,9 @@ func Init(namespace string) {
 	})
 }
 
-// InitWithoutServenv initializes the statsd using the namespace but without servenv
+// InitWithoutServenv initializes the statsd using the namespace but without servenv.
+// It registers two push backends, selected with --stats_backend: "statsd",
+// and "dogstatsd" for the DogStatsD agent of Datadog.
 func InitWithoutServenv(namespace string) {
 	if *statsdAddress == "" {
 		log.Info("statsdAddress is empty")
@@ -84,6 +123,11 @@ func InitWithoutServenv(namespace string) {
 	sb.statsdClient = statsdC
 	sb.sampleRate = *statsdSampleRate
 	stats.RegisterPushBackend("statsd", sb)
+	// The DogStatsD backend shares the client, and only differs by how it
+	// sends the counters.
+	dsb := sb
+	dsb.counts = newCounterDeltas()
+	stats.RegisterPushBackend("dogstatsd", dsb)
 	stats.RegisterTimerHook(func(statsName, name string, value int64, timings *stats.Timings) {
 		tags := makeLabels(strings.Split(timings.Label(), "."), name)
 		if err := statsdC.TimeInMilliseconds(statsName, float64(value), tags, sb.sampleRate); err != nil {
@@ -101,7 +145,7 @@ func (sb StatsBackend) addExpVar(kv expvar.KeyValue) {
 	k := kv.Key
 	switch v := kv.Value.(type) {
 	case *stats.Counter:
-		if err := sb.statsdClient.Count(k, v.Get(), nil, sb.sampleRate); err != nil {
+		if err := sb.count(k, v.Get(), nil); err != nil {
 			log.Errorf("Failed to add Counter %v for key %v", v, k)
 		}
 	case *stats.Gauge:
@@ -138,19 +182,19 @@ func (sb StatsBackend) addExpVar(kv expvar.KeyValue) {
 		}
 	case *stats.CountersWithSingleLabel:
 		for labelVal, val := range v.Counts() {
-			if err := sb.statsdClient.Count(k, val, makeLabel(v.Label(), labelVal), sb.sampleRate); err != nil {
+			if err := sb.count(k, val, makeLabel(v.Label(), labelVal)); err != nil {
 				log.Errorf("Failed to add CountersWithSingleLabel %v for key %v", v, k)
 			}
 		}
 	case *stats.CountersWithMultiLabels:
 		for labelVals, val := range v.Counts() {
-			if err := sb.statsdClient.Count(k, val, makeLabels(v.Labels(), labelVals), sb.sampleRate); err != nil {
+			if err := sb.count(k, val, makeLabels(v.Labels(), labelVals)); err != nil {
 				log.Errorf("Failed to add CountersFuncWithMultiLabels %v for key %v", v, k)
 			}
 		}
 	case *stats.CountersFuncWithMultiLabels:
 		for labelVals, val := range v.Counts() {
-			if err := sb.statsdClient.Count(k, val, makeLabels(v.Labels(), labelVals), sb.sampleRate); err != nil {
+			if err := sb.count(k, val, makeLabels(v.Labels(), labelVals)); err != nil {
 				log.Errorf("Failed to add CountersFuncWithMultiLabels %v for key %v", v, k)
 			}
 		}
@@ -245,6 +245,36 @@ func TestStatsdCountersWithMultiLabels(t *testing.T) {
 	}
 }
 
+func TestDogStatsdCounterDeltas(t *testing.T) {
+	sb, server := getBackend(t)
+	defer server.Close()
+	sb.counts = newCounterDeltas()
+	name := "dogstatsd_counter_name"
+	s := stats.NewCountersWithMultiLabels(name, "help", []string{"label1", "label2"})
+	push := func() string {
+		sb.addExpVar(expvar.KeyValue{Key: name, Value: expvar.Get(name)})
+		if err := sb.statsdClient.Flush(); err != nil {
+			t.Errorf("Error flushing: %s", err)
+		}
+		bytes := make([]byte, 4096)
+		n, err := server.Read(bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(bytes[:n])
+	}
+
+	s.Add([]string{"foo", "bar"}, 3)
+	assert.Equal(t, "test.dogstatsd_counter_name:3|c|#label1:foo,label2:bar", push())
+	// The counts since the previous push are sent.
+	s.Add([]string{"foo", "bar"}, 2)
+	assert.Equal(t, "test.dogstatsd_counter_name:2|c|#label1:foo,label2:bar", push())
+	// A counter that was reset sends its whole value.
+	s.ResetAll()
+	s.Add([]string{"foo", "bar"}, 1)
+	assert.Equal(t, "test.dogstatsd_counter_name:1|c|#label1:foo,label2:bar", push())
+}
+
 func TestStatsdCountersFuncWithMultiLabels(t *testing.T) {
 	sb, server := getBackend(t)
 	defer server.Close()
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ulid implements Universally Unique Lexicographically Sortable
+// Identifiers, as specified in https://github.com/ulid/spec.
+//
+// A ULID is 128 bits long: a 48-bit timestamp in milliseconds since the Unix
+// epoch, followed by 80 random bits. Its canonical form is 26 characters of
+// Crockford's base32, which sort in the same order as the timestamps.
+package ulid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Len is the length of the canonical string form of a ULID.
+const Len = 26
+
+// maxTime is the largest timestamp of a ULID.
+const maxTime = 1<<48 - 1
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// decoding maps the characters of the Crockford alphabet, in upper and lower
+// case, to their value. Invalid characters are 0xFF.
+var decoding [256]byte
+
+func init() {
+	for i := range decoding {
+		decoding[i] = 0xFF
+	}
+	for i := 0; i < len(crockford); i++ {
+		decoding[crockford[i]] = byte(i)
+		decoding[crockford[i]|0x20] = byte(i)
+	}
+}
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier.
+type ULID [16]byte
+
+// New returns a new ULID for the time t, whose random part is read from
+// crypto/rand.
+func New(t time.Time) (ULID, error) {
+	var id ULID
+	ms := t.UnixMilli()
+	if ms < 0 || ms > maxTime {
+		return id, fmt.Errorf("time %v cannot be encoded in a ULID", t)
+	}
+	id.setTime(uint64(ms))
+	if _, err := rand.Read(id[6:]); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+func (id *ULID) setTime(ms uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], ms)
+	copy(id[:6], b[2:])
+}
+
+// Time returns the time encoded in the ULID, with millisecond precision.
+func (id ULID) Time() time.Time {
+	var b [8]byte
+	copy(b[2:], id[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(b[:])))
+}
+
+// Entropy returns the random part of the ULID.
+func (id ULID) Entropy() []byte {
+	return id[6:]
+}
+
+// String returns the canonical form of the ULID.
+func (id ULID) String() string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var out [Len]byte
+	// The 128 bits are encoded as 130 bits, the first character only
+	// holding 3 bits.
+	for i := Len - 1; i >= 0; i-- {
+		out[i] = crockford[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// Parse parses the canonical form of a ULID. It is case-insensitive.
+func Parse(s string) (ULID, error) {
+	var id ULID
+	if len(s) != Len {
+		return id, fmt.Errorf("invalid ULID length %d: %q", len(s), s)
+	}
+	// The first character encodes the 3 most significant bits.
+	if decoding[s[0]] > 7 {
+		return id, fmt.Errorf("invalid ULID: %q", s)
+	}
+	var hi, lo uint64
+	for i := 0; i < Len; i++ {
+		v := decoding[s[i]]
+		if v == 0xFF {
+			return id, fmt.Errorf("invalid ULID: %q", s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return id, nil
+}
+
+// FromBytes returns the ULID of its 16-byte binary form.
+func FromBytes(b []byte) (ULID, error) {
+	var id ULID
+	if len(b) != len(id) {
+		return id, fmt.Errorf("invalid ULID length %d: %x", len(b), b)
+	}
+	copy(id[:], b)
+	return id, nil
+}
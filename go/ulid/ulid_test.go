@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ulid
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseString(t *testing.T) {
+	id, err := Parse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1469922850259), id.Time().UnixMilli())
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", id.String())
+
+	lower, err := Parse(strings.ToLower("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	require.NoError(t, err)
+	assert.Equal(t, id, lower)
+
+	max, err := Parse("7ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+	require.NoError(t, err)
+	assert.Equal(t, ULID{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, max)
+
+	for _, bad := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "80000000000000000000000000"} {
+		_, err := Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNew(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	var ids []string
+	for i := 0; i < 10; i++ {
+		id, err := New(now.Add(time.Duration(i) * time.Millisecond))
+		require.NoError(t, err)
+		assert.True(t, now.Add(time.Duration(i)*time.Millisecond).Equal(id.Time()))
+		parsed, err := Parse(id.String())
+		require.NoError(t, err)
+		assert.Equal(t, id, parsed)
+
+		fromBytes, err := FromBytes(id[:])
+		require.NoError(t, err)
+		assert.Equal(t, id, fromBytes)
+		ids = append(ids, id.String())
+	}
+	assert.True(t, sort.StringsAreSorted(ids))
+
+	_, err := New(time.UnixMilli(-1))
+	assert.Error(t, err)
+}
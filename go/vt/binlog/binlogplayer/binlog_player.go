@@ -86,6 +86,9 @@ type Stats struct {
 	heartbeatMutex sync.Mutex
 	heartbeat      int64
 
+	copyLastPKsMutex sync.Mutex
+	copyLastPKs      map[string]string
+
 	ReplicationLagSeconds sync2.AtomicInt64
 	History               *history.History
 
@@ -99,6 +102,11 @@ type Stats struct {
 	ErrorCounts    *stats.CountersWithMultiLabels
 	NoopQueryCount *stats.CountersWithSingleLabel
 
+	// TableCopyRowCounts and TableCopyBytes count the rows and bytes
+	// copied per table in the copy phase.
+	TableCopyRowCounts *stats.CountersWithSingleLabel
+	TableCopyBytes     *stats.CountersWithSingleLabel
+
 	VReplicationLags     *stats.Timings
 	VReplicationLagRates *stats.Rates
 }
@@ -117,6 +125,27 @@ func (bps *Stats) Heartbeat() int64 {
 	return bps.heartbeat
 }
 
+// SetCopyLastPK sets the last PK copied for a table in the copy phase.
+func (bps *Stats) SetCopyLastPK(table, lastPK string) {
+	bps.copyLastPKsMutex.Lock()
+	defer bps.copyLastPKsMutex.Unlock()
+	if bps.copyLastPKs == nil {
+		bps.copyLastPKs = make(map[string]string)
+	}
+	bps.copyLastPKs[table] = lastPK
+}
+
+// CopyLastPKs gets the last PKs copied per table in the copy phase.
+func (bps *Stats) CopyLastPKs() map[string]string {
+	bps.copyLastPKsMutex.Lock()
+	defer bps.copyLastPKsMutex.Unlock()
+	lastPKs := make(map[string]string, len(bps.copyLastPKs))
+	for table, lastPK := range bps.copyLastPKs {
+		lastPKs[table] = lastPK
+	}
+	return lastPKs
+}
+
 // SetLastPosition sets the last replication position.
 func (bps *Stats) SetLastPosition(pos mysql.Position) {
 	bps.lastPositionMutex.Lock()
@@ -155,6 +184,8 @@ func NewStats() *Stats {
 	bps.QueryCount = stats.NewCountersWithSingleLabel("", "", "Phase", "")
 	bps.CopyRowCount = stats.NewCounter("", "")
 	bps.CopyLoopCount = stats.NewCounter("", "")
+	bps.TableCopyRowCounts = stats.NewCountersWithSingleLabel("", "", "Table", "")
+	bps.TableCopyBytes = stats.NewCountersWithSingleLabel("", "", "Table", "")
 	bps.ErrorCounts = stats.NewCountersWithMultiLabels("", "", []string{"type"})
 	bps.NoopQueryCount = stats.NewCountersWithSingleLabel("", "", "Statement", "")
 	bps.VReplicationLags = stats.NewTimings("", "", "")
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package awssecretsmanager implements the 'aws-secretsmanager'
+// CredentialsServer of dbconfigs, which reads the db credentials from a
+// secret of AWS Secrets Manager. The AWS credentials are taken from the
+// environment, as described at
+// https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html
+package awssecretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	secretID = flag.String("db-credentials-aws-secret-id", "", "Name or ARN of the AWS Secrets Manager secret holding the db credentials JSON blob, in the same format as --db-credentials-file")
+	region   = flag.String("db-credentials-aws-region", "", "AWS region of the Secrets Manager secret; defaults to the region of the environment")
+	timeout  = flag.Duration("db-credentials-aws-timeout", 10*time.Second, "Timeout for AWS Secrets Manager API operations")
+	cacheTTL = flag.Duration("db-credentials-aws-ttl", 5*time.Minute, "How long to cache DB credentials from AWS Secrets Manager before reading the secret again, to get its rotations")
+)
+
+// CredentialsServer implements dbconfigs.CredentialsServer using a secret of
+// AWS Secrets Manager. The secret is read again once the cache TTL expired,
+// so the rotations of the secret are picked up without a restart. If the
+// secret can't be read, the cached credentials are still used.
+type CredentialsServer struct {
+	mu            sync.Mutex
+	client        *secretsmanager.SecretsManager
+	dbCredentials map[string][]string
+	expiry        time.Time
+}
+
+// GetUserAndPassword is part of the dbconfigs.CredentialsServer interface
+func (cs *CredentialsServer) GetUserAndPassword(user string) (string, string, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.dbCredentials == nil || time.Now().After(cs.expiry) {
+		if err := cs.load(); err != nil {
+			if cs.dbCredentials == nil {
+				// Errors might be transient, so we use ErrUnknownUser to get retries.
+				log.Errorf("Error reading the db credentials from AWS Secrets Manager, will retry: %v", err)
+				return "", "", dbconfigs.ErrUnknownUser
+			}
+			log.Warningf("Error reading the db credentials from AWS Secrets Manager, using the cached ones: %v", err)
+		}
+	}
+
+	passwd, ok := cs.dbCredentials[user]
+	if !ok || len(passwd) == 0 {
+		return "", "", dbconfigs.ErrUnknownUser
+	}
+	return user, passwd[0], nil
+}
+
+func (cs *CredentialsServer) load() error {
+	if *secretID == "" {
+		return errors.New("no AWS Secrets Manager secret specified")
+	}
+
+	if cs.client == nil {
+		config := aws.NewConfig()
+		if *region != "" {
+			config = config.WithRegion(*region)
+		}
+		session, err := session.NewSession(config)
+		if err != nil {
+			return err
+		}
+		cs.client = secretsmanager.New(session)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	out, err := cs.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(*secretID),
+	})
+	if err != nil {
+		return err
+	}
+	if out.SecretString == nil {
+		return errors.New("the secret has no string value")
+	}
+
+	dbCredentials := make(map[string][]string)
+	if err := json.Unmarshal([]byte(*out.SecretString), &dbCredentials); err != nil {
+		return err
+	}
+	cs.dbCredentials = dbCredentials
+	cs.expiry = time.Now().Add(*cacheTTL)
+	return nil
+}
+
+func init() {
+	dbconfigs.AllCredentialsServers["aws-secretsmanager"] = &CredentialsServer{}
+}
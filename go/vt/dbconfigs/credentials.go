@@ -40,7 +40,7 @@ import (
 
 var (
 	// generic flags
-	dbCredentialsServer = flag.String("db-credentials-server", "file", "db credentials server type ('file' - file implementation; 'vault' - HashiCorp Vault implementation)")
+	dbCredentialsServer = flag.String("db-credentials-server", "file", "db credentials server type ('file' - file implementation; 'vault' - HashiCorp Vault implementation; 'vault-database' - HashiCorp Vault database secrets engine implementation, with rotated credentials; 'aws-secretsmanager' - AWS Secrets Manager implementation, in the binaries that link it)")
 
 	// 'file' implementation flags
 	dbCredentialsFile = flag.String("db-credentials-file", "", "db credentials file; send SIGHUP to reload this file")
@@ -161,58 +161,17 @@ func (vcs *VaultCredentialsServer) GetUserAndPassword(user string) (string, stri
 		return user, vcs.dbCredsCache[user][0], nil
 	}
 
-	if *vaultAddr == "" {
-		return "", "", errors.New("No Vault server specified")
-	}
-
-	token, err := readFromFile(*vaultTokenFile)
-	if err != nil {
-		return "", "", errors.New("No Vault token in provided filename")
-	}
-	secretID, err := readFromFile(*vaultRoleSecretIDFile)
-	if err != nil {
-		return "", "", errors.New("No Vault secret_id in provided filename")
-	}
-
-	// From here on, errors might be transient, so we use ErrUnknownUser
-	// for everything, so we get retries
 	if vcs.vaultClient == nil {
-		config := vaultapi.NewConfig()
-
-		// All these can be overriden by environment
-		//   so we need to check if they have been set by NewConfig
-		if config.Address == "" {
-			config.Address = *vaultAddr
-		}
-		if config.Timeout == (0 * time.Second) {
-			config.Timeout = *vaultTimeout
-		}
-		if config.CACert == "" {
-			config.CACert = *vaultCACert
-		}
-		if config.Token == "" {
-			config.Token = token
-		}
-		if config.AppRoleCredentials.RoleID == "" {
-			config.AppRoleCredentials.RoleID = *vaultRoleID
-		}
-		if config.AppRoleCredentials.SecretID == "" {
-			config.AppRoleCredentials.SecretID = secretID
-		}
-		if config.AppRoleCredentials.MountPoint == "" {
-			config.AppRoleCredentials.MountPoint = *vaultRoleMountPoint
-		}
-
-		if config.CACert != "" {
-			// If we provide a CA, ensure we actually use it
-			config.InsecureSSL = false
+		token, secretID, err := readVaultAuth()
+		if err != nil {
+			return "", "", err
 		}
 
-		var err error
-		vcs.vaultClient, err = vaultapi.NewClient(config)
-		if err != nil || vcs.vaultClient == nil {
+		// From here on, errors might be transient, so we use ErrUnknownUser
+		// for everything, so we get retries
+		vcs.vaultClient, err = newVaultClient(token, secretID)
+		if err != nil {
 			log.Errorf("Error in vault client initialization, will retry: %v", err)
-			vcs.vaultClient = nil
 			return "", "", ErrUnknownUser
 		}
 	}
@@ -244,6 +203,68 @@ func (vcs *VaultCredentialsServer) GetUserAndPassword(user string) (string, stri
 	return user, dbCreds[user][0], nil
 }
 
+// readVaultAuth returns the Vault token and AppRole secret_id read from the
+// files given by the db-credentials-vault flags.
+func readVaultAuth() (string, string, error) {
+	if *vaultAddr == "" {
+		return "", "", errors.New("No Vault server specified")
+	}
+
+	token, err := readFromFile(*vaultTokenFile)
+	if err != nil {
+		return "", "", errors.New("No Vault token in provided filename")
+	}
+	secretID, err := readFromFile(*vaultRoleSecretIDFile)
+	if err != nil {
+		return "", "", errors.New("No Vault secret_id in provided filename")
+	}
+	return token, secretID, nil
+}
+
+// newVaultClient returns a Vault client configured with the
+// db-credentials-vault flags.
+func newVaultClient(token, secretID string) (*vaultapi.Client, error) {
+	config := vaultapi.NewConfig()
+
+	// All these can be overriden by environment
+	//   so we need to check if they have been set by NewConfig
+	if config.Address == "" {
+		config.Address = *vaultAddr
+	}
+	if config.Timeout == (0 * time.Second) {
+		config.Timeout = *vaultTimeout
+	}
+	if config.CACert == "" {
+		config.CACert = *vaultCACert
+	}
+	if config.Token == "" {
+		config.Token = token
+	}
+	if config.AppRoleCredentials.RoleID == "" {
+		config.AppRoleCredentials.RoleID = *vaultRoleID
+	}
+	if config.AppRoleCredentials.SecretID == "" {
+		config.AppRoleCredentials.SecretID = secretID
+	}
+	if config.AppRoleCredentials.MountPoint == "" {
+		config.AppRoleCredentials.MountPoint = *vaultRoleMountPoint
+	}
+
+	if config.CACert != "" {
+		// If we provide a CA, ensure we actually use it
+		config.InsecureSSL = false
+	}
+
+	client, err := vaultapi.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("no Vault client")
+	}
+	return client, nil
+}
+
 func readFromFile(filePath string) (string, error) {
 	if filePath == "" {
 		return "", nil
@@ -276,6 +297,7 @@ func withCredentials(cp *mysql.ConnParams) (*mysql.ConnParams, error) {
 func init() {
 	AllCredentialsServers["file"] = &FileCredentialsServer{}
 	AllCredentialsServers["vault"] = &VaultCredentialsServer{}
+	AllCredentialsServers["vault-database"] = newVaultDatabaseCredentialsServer()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGHUP)
@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbconfigs
+
+// This file contains the 'vault-database' CredentialsServer, which gets
+// dynamic MySQL credentials from the database secrets engine of Vault.
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	vaultapi "github.com/aquarapid/vaultlib"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	vaultDatabaseMount = flag.String("db-credentials-vault-database-mount", "database", "Mount path of the Vault database secrets engine used by the 'vault-database' credentials server. The credentials of a user are read from the role with the same name, e.g. database/creds/vt_app")
+
+	vaultDatabaseCredentials = stats.NewCountersWithSingleLabel(
+		"VaultDatabaseCredentials",
+		"Number of operations on the leases of the MySQL credentials from the Vault database secrets engine",
+		"Operation")
+)
+
+// vaultDatabaseRefreshInterval is how often the leases are checked for
+// renewal in the background.
+const vaultDatabaseRefreshInterval = 10 * time.Second
+
+// VaultDatabaseCredentialsServer implements CredentialsServer using the
+// database secrets engine of HashiCorp Vault. Vault creates a MySQL user with
+// a new password for each lease. The leases are renewed once two thirds of
+// their duration elapsed, and new credentials are fetched once they can't be
+// renewed anymore, so the credentials are rotated without a restart. The
+// connections opened with the previous credentials are left open.
+//
+// Vault is called without holding the lock of the server, so that a slow
+// Vault does not block the users of the current leases.
+type VaultDatabaseCredentialsServer struct {
+	mu     sync.Mutex
+	leases map[string]*vaultDatabaseLease
+	// refreshing holds the users whose lease is being refreshed, with a
+	// channel closed once it is done.
+	refreshing map[string]chan struct{}
+	ticker     *time.Ticker
+
+	// request sends a request to the Vault HTTP API. It is replaced in tests.
+	request func(method, path string, payload any) (json.RawMessage, error)
+	// now is replaced in tests.
+	now func() time.Time
+
+	clientMu sync.Mutex
+	client   *vaultapi.Client
+}
+
+// vaultDatabaseLease is a lease of MySQL credentials.
+type vaultDatabaseLease struct {
+	user      string
+	password  string
+	leaseID   string
+	renewable bool
+	duration  time.Duration
+	expiry    time.Time
+	renewAt   time.Time
+}
+
+// vaultLeaseResponse is the response of Vault to a read of credentials or to
+// the renewal of a lease.
+type vaultLeaseResponse struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int64  `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+}
+
+func newVaultDatabaseCredentialsServer() *VaultDatabaseCredentialsServer {
+	vcs := &VaultDatabaseCredentialsServer{
+		leases:     make(map[string]*vaultDatabaseLease),
+		refreshing: make(map[string]chan struct{}),
+		now:        time.Now,
+	}
+	vcs.request = vcs.vaultRequest
+	return vcs
+}
+
+// GetUserAndPassword is part of the CredentialsServer interface
+func (vcs *VaultDatabaseCredentialsServer) GetUserAndPassword(user string) (string, string, error) {
+	vcs.mu.Lock()
+	if vcs.ticker == nil {
+		vcs.ticker = time.NewTicker(vaultDatabaseRefreshInterval)
+		go func() {
+			for range vcs.ticker.C {
+				vcs.refreshAll()
+			}
+		}()
+	}
+	vcs.mu.Unlock()
+
+	lease, err := vcs.refresh(user)
+	if err != nil {
+		if errors.Is(err, ErrUnknownUser) {
+			return "", "", err
+		}
+		// Errors might be transient, so we use ErrUnknownUser to get retries.
+		log.Errorf("Error getting the credentials of %v from Vault, will retry: %v", user, err)
+		return "", "", ErrUnknownUser
+	}
+	return lease.user, lease.password, nil
+}
+
+// refreshAll renews the leases that are due for renewal.
+func (vcs *VaultDatabaseCredentialsServer) refreshAll() {
+	vcs.mu.Lock()
+	now := vcs.now()
+	var due []string
+	for user, lease := range vcs.leases {
+		if !now.Before(lease.renewAt) {
+			due = append(due, user)
+		}
+	}
+	vcs.mu.Unlock()
+
+	for _, user := range due {
+		if _, err := vcs.refresh(user); err != nil {
+			log.Errorf("Error refreshing the credentials of %v from Vault, will retry: %v", user, err)
+		}
+	}
+}
+
+// refresh returns the current lease of user, renewing it or fetching new
+// credentials if needed. If this fails, the current lease is still used
+// until it expires. Only one refresh of a user runs at a time, and the
+// current lease is returned while it runs, if it is still valid.
+func (vcs *VaultDatabaseCredentialsServer) refresh(user string) (*vaultDatabaseLease, error) {
+	vcs.mu.Lock()
+	for {
+		now := vcs.now()
+		lease := vcs.leases[user]
+		if lease != nil && now.Before(lease.renewAt) {
+			vcs.mu.Unlock()
+			return lease, nil
+		}
+		done, ok := vcs.refreshing[user]
+		if !ok {
+			break
+		}
+		if lease != nil && now.Before(lease.expiry) {
+			vcs.mu.Unlock()
+			return lease, nil
+		}
+		// There is no valid lease to use until the refresh in progress is
+		// done.
+		vcs.mu.Unlock()
+		<-done
+		vcs.mu.Lock()
+	}
+	done := make(chan struct{})
+	vcs.refreshing[user] = done
+	lease := vcs.leases[user]
+	vcs.mu.Unlock()
+
+	refreshed, err := vcs.refreshLease(user, lease, vcs.now())
+
+	vcs.mu.Lock()
+	defer vcs.mu.Unlock()
+	if refreshed != nil {
+		vcs.leases[user] = refreshed
+	}
+	delete(vcs.refreshing, user)
+	close(done)
+	return refreshed, err
+}
+
+// refreshLease returns the lease to use instead of the current lease of
+// user, if any, by renewing it or fetching new credentials from Vault.
+func (vcs *VaultDatabaseCredentialsServer) refreshLease(user string, lease *vaultDatabaseLease, now time.Time) (*vaultDatabaseLease, error) {
+	var err error
+	if lease != nil && lease.renewable && now.Before(lease.expiry) {
+		var renewed *vaultDatabaseLease
+		if renewed, err = vcs.renew(lease, now); err == nil {
+			return renewed, nil
+		}
+		log.Warningf("Failed to renew the Vault lease of the credentials of %v, fetching new credentials: %v", user, err)
+	}
+
+	fetched, err := vcs.fetch(user, now)
+	if err == nil {
+		if lease != nil {
+			log.Infof("Rotated the credentials of %v from Vault, the new MySQL user is %v", user, fetched.user)
+		}
+		return fetched, nil
+	}
+	vaultDatabaseCredentials.Add("Error", 1)
+	if lease != nil && now.Before(lease.expiry) {
+		log.Warningf("Failed to fetch new credentials of %v from Vault, the current ones are valid until %v: %v", user, lease.expiry, err)
+		return lease, nil
+	}
+	return nil, err
+}
+
+// fetch reads new credentials for user from Vault.
+func (vcs *VaultDatabaseCredentialsServer) fetch(user string, now time.Time) (*vaultDatabaseLease, error) {
+	body, err := vcs.request("GET", fmt.Sprintf("/v1/%s/creds/%s", strings.Trim(*vaultDatabaseMount, "/"), user), nil)
+	if err != nil {
+		return nil, err
+	}
+	var rsp vaultLeaseResponse
+	if err := json.Unmarshal(body, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.Data.Username == "" {
+		log.Warningf("Vault lookup for user not found: %v", user)
+		return nil, ErrUnknownUser
+	}
+	vaultDatabaseCredentials.Add("Fetch", 1)
+
+	lease := &vaultDatabaseLease{
+		user:      rsp.Data.Username,
+		password:  rsp.Data.Password,
+		leaseID:   rsp.LeaseID,
+		renewable: rsp.Renewable,
+	}
+	lease.duration = time.Duration(rsp.LeaseDuration) * time.Second
+	lease.setDuration(now, lease.duration)
+	return lease, nil
+}
+
+// renew extends the lease of the credentials.
+func (vcs *VaultDatabaseCredentialsServer) renew(lease *vaultDatabaseLease, now time.Time) (*vaultDatabaseLease, error) {
+	body, err := vcs.request("PUT", "/v1/sys/leases/renew", map[string]string{"lease_id": lease.leaseID})
+	if err != nil {
+		return nil, err
+	}
+	var rsp vaultLeaseResponse
+	if err := json.Unmarshal(body, &rsp); err != nil {
+		return nil, err
+	}
+	// Vault doesn't extend a lease past its max TTL. Once the lease can't be
+	// extended by its full duration anymore, new credentials are needed.
+	duration := time.Duration(rsp.LeaseDuration) * time.Second
+	if duration < lease.duration {
+		return nil, fmt.Errorf("lease %v reached its max TTL", lease.leaseID)
+	}
+	vaultDatabaseCredentials.Add("Renew", 1)
+
+	renewed := *lease
+	renewed.renewable = rsp.Renewable
+	renewed.setDuration(now, duration)
+	return &renewed, nil
+}
+
+func (lease *vaultDatabaseLease) setDuration(now time.Time, duration time.Duration) {
+	lease.expiry = now.Add(duration)
+	lease.renewAt = now.Add(duration * 2 / 3)
+}
+
+// vaultRequest sends a request to the Vault HTTP API with the client
+// configured by the db-credentials-vault flags.
+func (vcs *VaultDatabaseCredentialsServer) vaultRequest(method, path string, payload any) (json.RawMessage, error) {
+	vcs.clientMu.Lock()
+	if vcs.client == nil {
+		token, secretID, err := readVaultAuth()
+		if err != nil {
+			vcs.clientMu.Unlock()
+			return nil, err
+		}
+		// Use the client for all the next requests.
+		if vcs.client, err = newVaultClient(token, secretID); err != nil {
+			vcs.clientMu.Unlock()
+			return nil, err
+		}
+	}
+	client := vcs.client
+	vcs.clientMu.Unlock()
+	return client.RawRequest(method, path, payload)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbconfigs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault emulates the database secrets engine of Vault, with a max TTL
+// of 3 lease durations.
+type fakeVault struct {
+	now      *time.Time
+	down     bool
+	leases   int
+	renewals []string
+	created  map[string]time.Time
+}
+
+const fakeVaultLeaseDuration = 60
+
+func (fv *fakeVault) request(method, path string, payload any) (json.RawMessage, error) {
+	if fv.down {
+		return nil, errors.New("connection refused")
+	}
+	switch {
+	case method == "GET" && path == "/v1/database/creds/vt_app":
+		fv.leases++
+		leaseID := fmt.Sprintf("database/creds/vt_app/%d", fv.leases)
+		fv.created[leaseID] = *fv.now
+		return json.RawMessage(fmt.Sprintf(`{"lease_id": %q, "lease_duration": %d, "renewable": true, "data": {"username": "v-vt_app-%d", "password": "pass%d"}}`,
+			leaseID, fakeVaultLeaseDuration, fv.leases, fv.leases)), nil
+	case method == "PUT" && path == "/v1/sys/leases/renew":
+		leaseID := payload.(map[string]string)["lease_id"]
+		fv.renewals = append(fv.renewals, leaseID)
+		maxExpiry := fv.created[leaseID].Add(3 * fakeVaultLeaseDuration * time.Second)
+		duration := fakeVaultLeaseDuration * time.Second
+		if remaining := maxExpiry.Sub(*fv.now); remaining < duration {
+			duration = remaining
+		}
+		return json.RawMessage(fmt.Sprintf(`{"lease_id": %q, "lease_duration": %d, "renewable": true}`, leaseID, int64(duration.Seconds()))), nil
+	}
+	return nil, fmt.Errorf("Vault http call %v returned 400", path)
+}
+
+func TestVaultDatabaseCredentialsServer(t *testing.T) {
+	now := time.Unix(1000, 0)
+	fv := &fakeVault{now: &now, created: make(map[string]time.Time)}
+	vcs := newVaultDatabaseCredentialsServer()
+	vcs.request = fv.request
+	vcs.now = func() time.Time { return now }
+
+	getCredentials := func() (string, string) {
+		lease, err := vcs.refresh("vt_app")
+		require.NoError(t, err)
+		return lease.user, lease.password
+	}
+
+	user, password := getCredentials()
+	assert.Equal(t, "v-vt_app-1", user)
+	assert.Equal(t, "pass1", password)
+
+	// The lease is renewed once 2/3 of its duration elapsed.
+	now = now.Add(30 * time.Second)
+	user, _ = getCredentials()
+	assert.Equal(t, "v-vt_app-1", user)
+	assert.Empty(t, fv.renewals)
+
+	now = now.Add(15 * time.Second)
+	user, _ = getCredentials()
+	assert.Equal(t, "v-vt_app-1", user)
+	assert.Equal(t, []string{"database/creds/vt_app/1"}, fv.renewals)
+
+	// New credentials are fetched once the lease can't be renewed for its
+	// full duration anymore, because of its max TTL.
+	now = now.Add(45 * time.Second)
+	getCredentials()
+	assert.Len(t, fv.renewals, 2)
+	now = now.Add(45 * time.Second)
+	user, password = getCredentials()
+	assert.Equal(t, "v-vt_app-2", user)
+	assert.Equal(t, "pass2", password)
+
+	// The current credentials are used until they expire if Vault is down.
+	fv.down = true
+	now = now.Add(50 * time.Second)
+	user, _ = getCredentials()
+	assert.Equal(t, "v-vt_app-2", user)
+
+	now = now.Add(20 * time.Second)
+	_, _, err := vcs.GetUserAndPassword("vt_app")
+	assert.Equal(t, ErrUnknownUser, err)
+
+	fv.down = false
+	user, _ = getCredentials()
+	assert.Equal(t, "v-vt_app-3", user)
+
+	_, err = vcs.refresh("vt_unknown")
+	assert.Error(t, err)
+}
+
+func TestVaultDatabaseCredentialsServerSlowVault(t *testing.T) {
+	now := time.Unix(1000, 0)
+	fv := &fakeVault{now: &now, created: make(map[string]time.Time)}
+	vcs := newVaultDatabaseCredentialsServer()
+	vcs.request = fv.request
+	var mu sync.Mutex
+	vcs.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	_, err := vcs.refresh("vt_app")
+	require.NoError(t, err)
+
+	// The renewal of the lease hangs.
+	called, release := make(chan struct{}), make(chan struct{})
+	vcs.request = func(method, path string, payload any) (json.RawMessage, error) {
+		close(called)
+		<-release
+		return fv.request(method, path, payload)
+	}
+	mu.Lock()
+	now = now.Add(45 * time.Second)
+	mu.Unlock()
+	go vcs.refreshAll()
+	<-called
+
+	// The current lease is still returned while it is being renewed.
+	got := make(chan string)
+	go func() {
+		user, _, err := vcs.GetUserAndPassword("vt_app")
+		assert.NoError(t, err)
+		got <- user
+	}()
+	select {
+	case user := <-got:
+		assert.Equal(t, "v-vt_app-1", user)
+	case <-time.After(10 * time.Second):
+		t.Fatal("GetUserAndPassword is blocked by the renewal of the lease")
+	}
+
+	close(release)
+	require.Eventually(t, func() bool {
+		vcs.mu.Lock()
+		defer vcs.mu.Unlock()
+		return len(vcs.refreshing) == 0
+	}, 10*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"database/creds/vt_app/1"}, fv.renewals)
+}
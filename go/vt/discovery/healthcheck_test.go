@@ -707,6 +707,69 @@ func TestRemoveTablet(t *testing.T) {
 	assert.Empty(t, a, "wrong result, expected empty list")
 }
 
+// TestHealthCheckDrainingTablet tests that a draining tablet is not sent new
+// queries, but can still be reached by its alias.
+func TestHealthCheckDrainingTablet(t *testing.T) {
+	ts := memorytopo.NewServer("cell")
+	hc := createTestHc(ts)
+	defer hc.Close()
+	tablet := createTestTablet(0, "cell", "a")
+	tablet.Type = topodatapb.TabletType_REPLICA
+	input := make(chan *querypb.StreamHealthResponse)
+	createFakeConn(tablet, input)
+
+	resultChan := hc.Subscribe()
+	hc.AddTablet(tablet)
+	// there will be a first result, get and discard it
+	<-resultChan
+
+	target := &querypb.Target{Keyspace: "k", Shard: "s", TabletType: topodatapb.TabletType_REPLICA}
+	shr := &querypb.StreamHealthResponse{
+		TabletAlias:   tablet.Alias,
+		Target:        target,
+		Serving:       true,
+		RealtimeStats: &querypb.RealtimeStats{ReplicationLagSeconds: 1, CpuUsage: 0.2},
+	}
+	input <- shr
+	<-resultChan
+	assert.Len(t, hc.GetHealthyTabletStats(target), 1)
+
+	// the tablet starts draining
+	shr = &querypb.StreamHealthResponse{
+		TabletAlias:   tablet.Alias,
+		Target:        target,
+		Serving:       true,
+		Draining:      true,
+		RealtimeStats: &querypb.RealtimeStats{ReplicationLagSeconds: 1, CpuUsage: 0.2},
+	}
+	want := &TabletHealth{
+		Tablet:   tablet,
+		Target:   target,
+		Serving:  false,
+		Draining: true,
+		Stats:    &querypb.RealtimeStats{ReplicationLagSeconds: 1, CpuUsage: 0.2},
+	}
+	input <- shr
+	result := <-resultChan
+	mustMatch(t, want, result, "Wrong TabletHealth data")
+	assert.Empty(t, hc.GetHealthyTabletStats(target), "a draining tablet must not be healthy")
+	_, err := hc.TabletConnection(tablet.Alias, target)
+	assert.NoError(t, err, "a draining tablet must be reachable by its alias")
+
+	// the tablet stops draining
+	shr = &querypb.StreamHealthResponse{
+		TabletAlias:   tablet.Alias,
+		Target:        target,
+		Serving:       true,
+		RealtimeStats: &querypb.RealtimeStats{ReplicationLagSeconds: 1, CpuUsage: 0.2},
+	}
+	input <- shr
+	result = <-resultChan
+	assert.True(t, result.Serving)
+	assert.False(t, result.Draining)
+	assert.Len(t, hc.GetHealthyTabletStats(target), 1)
+}
+
 // TestGetHealthyTablets tests the functionality of GetHealthyTabletStats.
 func TestGetHealthyTablets(t *testing.T) {
 	ts := memorytopo.NewServer("cell")
@@ -42,6 +42,7 @@ type TabletHealth struct {
 	PrimaryTermStartTime int64
 	LastError            error
 	Serving              bool
+	Draining             bool
 }
 
 func (th *TabletHealth) MarshalJSON() ([]byte, error) {
@@ -52,6 +53,7 @@ func (th *TabletHealth) MarshalJSON() ([]byte, error) {
 		Target                              *query.Target
 		Up                                  bool
 		Serving                             bool
+		Draining                            bool
 		PrimaryTermStartTime                int64
 		TabletExternallyReparentedTimestamp int64
 		Stats                               *query.RealtimeStats
@@ -68,7 +70,8 @@ func (th *TabletHealth) MarshalJSON() ([]byte, error) {
 		// TODO: remove Up in v15
 		Up: true,
 
-		Serving: th.Serving,
+		Serving:  th.Serving,
+		Draining: th.Draining,
 
 		// We copy the PrimaryTermStartTime value onto TabletExternallyReparentedTimestamp to
 		// ensure backward compatibility.
@@ -87,6 +90,7 @@ func (th *TabletHealth) DeepEqual(other *TabletHealth) bool {
 	return proto.Equal(th.Tablet, other.Tablet) &&
 		proto.Equal(th.Target, other.Target) &&
 		th.Serving == other.Serving &&
+		th.Draining == other.Draining &&
 		th.PrimaryTermStartTime == other.PrimaryTermStartTime &&
 		proto.Equal(th.Stats, other.Stats) &&
 		((th.LastError == nil && other.LastError == nil) ||
@@ -58,6 +58,9 @@ type tabletHealthCheck struct {
 	Target *query.Target
 	// Serving describes if the tablet can be serving traffic.
 	Serving bool
+	// Draining is true if the tablet is drained before maintenance. It is
+	// not sent new queries, and is not serving.
+	Draining bool
 	// PrimaryTermStartTime is the last time at which
 	// this tablet was either elected the primary, or received
 	// a TabletExternallyReparented event. It is set to 0 if the
@@ -94,6 +97,7 @@ func (thc *tabletHealthCheck) SimpleCopy() *TabletHealth {
 		LastError:            thc.LastError,
 		PrimaryTermStartTime: thc.PrimaryTermStartTime,
 		Serving:              thc.Serving,
+		Draining:             thc.Draining,
 	}
 }
 
@@ -176,6 +180,11 @@ func (thc *tabletHealthCheck) processResponse(hc *HealthCheckImpl, shr *query.St
 		healthErr = fmt.Errorf("vttablet error: %v", shr.RealtimeStats.HealthError)
 		serving = false
 	}
+	// a draining tablet is not sent new queries, but the transactions in
+	// flight on it still reach it by its alias.
+	if shr.Draining {
+		serving = false
+	}
 
 	if shr.TabletAlias != nil && !proto.Equal(shr.TabletAlias, thc.Tablet.Alias) {
 		// TabletAlias change means that the host:port has been taken over by another tablet
@@ -186,16 +195,19 @@ func (thc *tabletHealthCheck) processResponse(hc *HealthCheckImpl, shr *query.St
 
 	prevTarget := thc.Target
 	// check whether this is a trivial update so as to update healthy map
-	trivialUpdate := thc.LastError == nil && thc.Serving && shr.RealtimeStats.HealthError == "" && shr.Serving &&
+	trivialUpdate := thc.LastError == nil && thc.Serving && shr.RealtimeStats.HealthError == "" && shr.Serving && !shr.Draining &&
 		prevTarget.TabletType != topodata.TabletType_PRIMARY && prevTarget.TabletType == shr.Target.TabletType && thc.isTrivialReplagChange(shr.RealtimeStats)
 	thc.lastResponseTimestamp = time.Now()
 	thc.Target = shr.Target
 	thc.PrimaryTermStartTime = shr.TabletExternallyReparentedTimestamp
 	thc.Stats = shr.RealtimeStats
 	thc.LastError = healthErr
+	thc.Draining = shr.Draining
 	reason := "healthCheck update"
 	if healthErr != nil {
 		reason = "healthCheck update error: " + healthErr.Error()
+	} else if shr.Draining {
+		reason = "healthCheck update: tablet is draining"
 	}
 	thc.setServingState(serving, reason)
 
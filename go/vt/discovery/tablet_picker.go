@@ -17,14 +17,19 @@ limitations under the License.
 package discovery
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
 
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
@@ -36,11 +41,25 @@ import (
 
 	"context"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
 )
 
+const (
+	// TabletPickerPreferredTag is the tag of the tablets that the tablet
+	// picker tries first when it is "true", e.g. the replicas dedicated to
+	// vreplication and VDiff.
+	TabletPickerPreferredTag = "vreplication"
+	// TabletPickerWeightTag is the tag of the weight of a tablet in the random
+	// selection of the tablet picker, 1 by default. The tablets with a weight
+	// of 0 are only picked if no other tablet is available.
+	TabletPickerWeightTag = "vreplication_weight"
+)
+
+var tabletPickerMaxReplicationLag = flag.Duration("tablet_picker_max_replication_lag", 0, "If set, the tablet picker of vreplication and VDiff skips the non-primary tablets whose replication lag is above this value. Disabled by default.")
+
 var (
 	tabletPickerRetryDelay   = 30 * time.Second
 	muTabletPickerRetryDelay sync.Mutex
@@ -116,25 +135,7 @@ func (tp *TabletPicker) PickForStreaming(ctx context.Context) (*topodatapb.Table
 		default:
 		}
 		candidates := tp.GetMatchingTablets(ctx)
-		if tp.inOrder {
-			// Sort candidates slice such that tablets appear in same tablet type order as in tp.tabletTypes
-			orderMap := map[topodatapb.TabletType]int{}
-			for i, t := range tp.tabletTypes {
-				orderMap[t] = i
-			}
-			sort.Slice(candidates, func(i, j int) bool {
-				if orderMap[candidates[i].Type] == orderMap[candidates[j].Type] {
-					// identical tablet types: randomize order of tablets for this type
-					return rand.Intn(2) == 0 // 50% chance
-				}
-				return orderMap[candidates[i].Type] < orderMap[candidates[j].Type]
-			})
-		} else {
-			// Randomize candidates
-			rand.Shuffle(len(candidates), func(i, j int) {
-				candidates[i], candidates[j] = candidates[j], candidates[i]
-			})
-		}
+		tp.orderCandidates(candidates)
 		if len(candidates) == 0 {
 			// if no candidates were found, sleep and try again
 			tp.incNoTabletFoundStat()
@@ -149,11 +150,18 @@ func (tp *TabletPicker) PickForStreaming(ctx context.Context) (*topodatapb.Table
 			}
 			continue
 		}
+		lagging := false
 		for _, ti := range candidates {
 			// try to connect to tablet
 			if conn, err := tabletconn.GetDialer()(ti.Tablet, true); err == nil {
+				err := checkReplicationLag(ctx, conn, ti.Tablet)
 				// OK to use ctx here because it is not actually used by the underlying Close implementation
 				_ = conn.Close(ctx)
+				if err != nil {
+					log.Warningf("skipping tablet %v: %v", ti.Alias, err)
+					lagging = true
+					continue
+				}
 				log.Infof("tablet picker found tablet %s", ti.Tablet.String())
 				return ti.Tablet, nil
 			}
@@ -162,7 +170,98 @@ func (tp *TabletPicker) PickForStreaming(ctx context.Context) (*topodatapb.Table
 		}
 		// Got here? Means we iterated all tablets and did not find a healthy one
 		tp.incNoTabletFoundStat()
+		if lagging {
+			// wait for the replication lag of the tablets to go down
+			timer := time.NewTimer(GetTabletPickerRetryDelay())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// orderCandidates sorts the candidate tablets in the order in which they are
+// tried: by tablet type if the tablet types are in order, then the preferred
+// tablets first, then in a random order weighted by the weights of the tablets.
+func (tp *TabletPicker) orderCandidates(candidates []*topo.TabletInfo) {
+	orderMap := map[topodatapb.TabletType]int{}
+	for i, t := range tp.tabletTypes {
+		orderMap[t] = i
+	}
+	// The weighted random order is the order of the keys rand^(1/weight),
+	// which are negative for the tablets with a weight of 0.
+	keys := make(map[*topo.TabletInfo]float64, len(candidates))
+	for _, ti := range candidates {
+		weight := tabletWeight(ti.Tablet)
+		if weight == 0 {
+			keys[ti] = rand.Float64() - 1
+		} else {
+			keys[ti] = math.Pow(rand.Float64(), 1/weight)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i], candidates[j]
+		if tp.inOrder && orderMap[ti.Type] != orderMap[tj.Type] {
+			return orderMap[ti.Type] < orderMap[tj.Type]
+		}
+		if pi, pj := isPreferredTablet(ti.Tablet), isPreferredTablet(tj.Tablet); pi != pj {
+			return pi
+		}
+		return keys[ti] > keys[tj]
+	})
+}
+
+func isPreferredTablet(tablet *topodatapb.Tablet) bool {
+	return tablet.Tags[TabletPickerPreferredTag] == "true"
+}
+
+// tabletWeight returns the weight of a tablet, 1 if it has none or if it is
+// invalid.
+func tabletWeight(tablet *topodatapb.Tablet) float64 {
+	value, ok := tablet.Tags[TabletPickerWeightTag]
+	if !ok {
+		return 1
+	}
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight < 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+		log.Warningf("invalid %v tag of tablet %v: %v", TabletPickerWeightTag, topoproto.TabletAliasString(tablet.Alias), value)
+		return 1
+	}
+	return weight
+}
+
+// checkReplicationLag returns an error if the replication lag of a non-primary
+// tablet is above --tablet_picker_max_replication_lag, or if it is unhealthy.
+func checkReplicationLag(ctx context.Context, conn queryservice.QueryService, tablet *topodatapb.Tablet) error {
+	if *tabletPickerMaxReplicationLag <= 0 || tablet.Type == topodatapb.TabletType_PRIMARY {
+		return nil
+	}
+	shortCtx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+	var realtimeStats *querypb.RealtimeStats
+	err := conn.StreamHealth(shortCtx, func(shr *querypb.StreamHealthResponse) error {
+		realtimeStats = shr.RealtimeStats
+		if realtimeStats == nil {
+			realtimeStats = &querypb.RealtimeStats{}
+		}
+		return io.EOF
+	})
+	if realtimeStats == nil {
+		if err == nil || err == io.EOF {
+			err = fmt.Errorf("no health response")
+		}
+		return err
+	}
+	if realtimeStats.HealthError != "" {
+		return fmt.Errorf("unhealthy: %v", realtimeStats.HealthError)
+	}
+	if lag := time.Duration(realtimeStats.ReplicationLagSeconds) * time.Second; lag > *tabletPickerMaxReplicationLag {
+		return fmt.Errorf("replication lag %v is above %v", lag, *tabletPickerMaxReplicationLag)
 	}
+	return nil
 }
 
 // GetMatchingTablets returns a list of TabletInfo for tablets
@@ -419,6 +419,106 @@ func TestPickError(t *testing.T) {
 	require.Greater(t, globalTPStats.noTabletFoundError.Counts()["cell.ks.0.replica"], int64(0))
 }
 
+func TestPickPreferredTablet(t *testing.T) {
+	te := newPickerTestEnv(t, []string{"cell"})
+	other := addTablet(te, 100, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, other)
+	want := addTablet(te, 101, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, want)
+	want = setTabletTags(te, want, map[string]string{TabletPickerPreferredTag: "true"})
+
+	tp, err := NewTabletPicker(te.topoServ, te.cells, te.keyspace, te.shard, "replica")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		tablet, err := tp.PickForStreaming(context.Background())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(want, tablet), "Pick: %v, want %v", tablet, want)
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	te := newPickerTestEnv(t, []string{"cell"})
+	unused := addTablet(te, 100, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, unused)
+	unused = setTabletTags(te, unused, map[string]string{TabletPickerWeightTag: "0"})
+	light := addTablet(te, 101, topodatapb.TabletType_REPLICA, "cell", true, true)
+	heavy := addTablet(te, 102, topodatapb.TabletType_REPLICA, "cell", true, true)
+	heavy = setTabletTags(te, heavy, map[string]string{TabletPickerWeightTag: "9"})
+
+	tp, err := NewTabletPicker(te.topoServ, te.cells, te.keyspace, te.shard, "replica")
+	require.NoError(t, err)
+	// The tablet of weight 0 is never picked, and the heavy tablet is picked
+	// about 9 times as often as the light one.
+	picks := make(map[uint32]int)
+	for i := 0; i < 200; i++ {
+		tablet, err := tp.PickForStreaming(context.Background())
+		require.NoError(t, err)
+		picks[tablet.Alias.Uid]++
+	}
+	assert.Zero(t, picks[unused.Alias.Uid])
+	assert.Greater(t, picks[light.Alias.Uid], 0)
+	assert.Greater(t, picks[heavy.Alias.Uid], 3*picks[light.Alias.Uid])
+
+	// The tablet of weight 0 is still picked when it is the only one.
+	deleteTablet(t, te, light)
+	deleteTablet(t, te, heavy)
+	tablet, err := tp.PickForStreaming(context.Background())
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(unused, tablet), "Pick: %v, want %v", tablet, unused)
+}
+
+func TestPickWithReplicationLag(t *testing.T) {
+	defer func(saved time.Duration) { *tabletPickerMaxReplicationLag = saved }(*tabletPickerMaxReplicationLag)
+	*tabletPickerMaxReplicationLag = 10 * time.Second
+	delay := GetTabletPickerRetryDelay()
+	defer SetTabletPickerRetryDelay(delay)
+	SetTabletPickerRetryDelay(11 * time.Millisecond)
+
+	te := newPickerTestEnv(t, []string{"cell"})
+	lagging := addTablet(te, 100, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, lagging)
+	setReplicationLag(te, lagging, 30)
+	want := addTablet(te, 101, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, want)
+	setReplicationLag(te, want, 5)
+
+	tp, err := NewTabletPicker(te.topoServ, te.cells, te.keyspace, te.shard, "replica")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		tablet, err := tp.PickForStreaming(context.Background())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(want, tablet), "Pick: %v, want %v", tablet, want)
+	}
+
+	// No tablet is picked while all of them lag.
+	setReplicationLag(te, want, 15)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = tp.PickForStreaming(ctx)
+	require.EqualError(t, err, "context has expired")
+}
+
+func setTabletTags(te *pickerTestEnv, tablet *topodatapb.Tablet, tags map[string]string) *topodatapb.Tablet {
+	updated, err := te.topoServ.UpdateTabletFields(context.Background(), tablet.Alias, func(tablet *topodatapb.Tablet) error {
+		tablet.Tags = tags
+		return nil
+	})
+	require.NoError(te.t, err)
+	return updated
+}
+
+func setReplicationLag(te *pickerTestEnv, tablet *topodatapb.Tablet, lagSeconds uint32) {
+	_ = createFixedHealthConn(tablet, &querypb.StreamHealthResponse{
+		Serving: true,
+		Target: &querypb.Target{
+			Keyspace:   te.keyspace,
+			Shard:      te.shard,
+			TabletType: tablet.Type,
+		},
+		RealtimeStats: &querypb.RealtimeStats{ReplicationLagSeconds: lagSeconds},
+	})
+}
+
 type pickerTestEnv struct {
 	t        *testing.T
 	keyspace string
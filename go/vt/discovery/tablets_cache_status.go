@@ -65,6 +65,9 @@ func (tcs *TabletsCacheStatus) StatusAsHTML() template.HTML {
 		if ts.LastError != nil {
 			color = "red"
 			extra = fmt.Sprintf(" (%v)", ts.LastError)
+		} else if ts.Draining {
+			color = "red"
+			extra = " (Draining)"
 		} else if !ts.Serving {
 			color = "red"
 			extra = " (Not Serving)"
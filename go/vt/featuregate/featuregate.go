@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featuregate holds the feature gates of the risky features, which
+// can be turned on and off for a whole component with --feature_gates, and
+// for a keyspace with the overrides of its keyspace settings in the topo, so
+// that a feature can be rolled out one keyspace at a time and rolled back
+// without a new binary.
+package featuregate
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stage is the maturity of the feature of a gate, which decides whether the
+// gate is enabled by default.
+type Stage string
+
+const (
+	// Alpha features are disabled by default.
+	Alpha = Stage("alpha")
+	// Beta features are enabled by default.
+	Beta = Stage("beta")
+)
+
+// Gate is the feature gate of a risky feature.
+type Gate struct {
+	name        string
+	stage       Stage
+	description string
+}
+
+// Name returns the name of the gate, e.g. "ParallelVPlayer".
+func (g *Gate) Name() string {
+	return g.name
+}
+
+// Stage returns the stage of the feature of the gate.
+func (g *Gate) Stage() Stage {
+	return g.stage
+}
+
+// Description returns what the feature of the gate does.
+func (g *Gate) Description() string {
+	return g.description
+}
+
+// EnabledByDefault returns whether the gate is enabled when neither
+// --feature_gates nor the keyspace settings set it.
+func (g *Gate) EnabledByDefault() bool {
+	return g.stage != Alpha
+}
+
+// Enabled returns whether the gate is enabled for a keyspace, given the
+// feature gate overrides of its settings, which may be nil. The override of
+// the keyspace wins over --feature_gates, which wins over the default of the
+// gate.
+func (g *Gate) Enabled(overrides map[string]bool) bool {
+	if enabled, ok := overrides[g.name]; ok {
+		return enabled
+	}
+	if enabled, ok := flagGates.get(g.name); ok {
+		return enabled
+	}
+	return g.EnabledByDefault()
+}
+
+var (
+	gatesMu sync.Mutex
+	gates   = map[string]*Gate{}
+)
+
+// register adds a gate. It panics if a gate of the same name exists.
+func register(name string, stage Stage, description string) *Gate {
+	gatesMu.Lock()
+	defer gatesMu.Unlock()
+	if _, ok := gates[name]; ok {
+		panic(fmt.Sprintf("feature gate %s is registered twice", name))
+	}
+	g := &Gate{name: name, stage: stage, description: description}
+	gates[name] = g
+	return g
+}
+
+// Lookup returns the gate of the given name, or nil if there is none.
+func Lookup(name string) *Gate {
+	gatesMu.Lock()
+	defer gatesMu.Unlock()
+	return gates[name]
+}
+
+// Gates returns all the gates, sorted by name.
+func Gates() []*Gate {
+	gatesMu.Lock()
+	defer gatesMu.Unlock()
+	list := make([]*Gate, 0, len(gates))
+	for _, g := range gates {
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+// gateValues is the value of --feature_gates, which turns gates on and off
+// for all the keyspaces of a component.
+type gateValues struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+var flagGates = &gateValues{}
+
+func init() {
+	flag.Var(flagGates, "feature_gates", "Comma separated list of feature gates to turn on or off in all the keyspaces, e.g. ParallelVPlayer=false,DMLBatch=true. The feature gate overrides of the settings of a keyspace win over this flag.")
+}
+
+func (v *gateValues) get(name string) (enabled bool, ok bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	enabled, ok = v.values[name]
+	return enabled, ok
+}
+
+// String is part of the flag.Value interface.
+func (v *gateValues) String() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	names := make([]string, 0, len(v.values))
+	for name := range v.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s=%t", name, v.values[name])
+	}
+	return strings.Join(names, ",")
+}
+
+// Set is part of the flag.Value interface.
+func (v *gateValues) Set(value string) error {
+	values := map[string]bool{}
+	for _, gate := range strings.Split(value, ",") {
+		gate = strings.TrimSpace(gate)
+		if gate == "" {
+			continue
+		}
+		name, enabled, found := strings.Cut(gate, "=")
+		if !found {
+			return fmt.Errorf("invalid feature gate %q, expected <name>=<true|false>", gate)
+		}
+		if Lookup(name) == nil {
+			return fmt.Errorf("unknown feature gate %s", name)
+		}
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s: %q", name, enabled)
+		}
+		values[name] = b
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values = values
+	return nil
+}
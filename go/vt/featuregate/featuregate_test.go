@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	alpha := &Gate{name: "TestAlpha", stage: Alpha}
+	beta := &Gate{name: "TestBeta", stage: Beta}
+	gates[alpha.name], gates[beta.name] = alpha, beta
+	defer func() {
+		delete(gates, alpha.name)
+		delete(gates, beta.name)
+		require.NoError(t, flagGates.Set(""))
+	}()
+
+	assert.False(t, alpha.Enabled(nil))
+	assert.True(t, beta.Enabled(nil))
+
+	require.NoError(t, flagGates.Set("TestAlpha=true, TestBeta=false"))
+	assert.Equal(t, "TestAlpha=true,TestBeta=false", flagGates.String())
+	assert.True(t, alpha.Enabled(nil))
+	assert.False(t, beta.Enabled(map[string]bool{"TestAlpha": false}))
+
+	// The overrides of the keyspace win over the flag.
+	overrides := map[string]bool{"TestAlpha": false, "TestBeta": true}
+	assert.False(t, alpha.Enabled(overrides))
+	assert.True(t, beta.Enabled(overrides))
+}
+
+func TestSetFlag(t *testing.T) {
+	defer func() { require.NoError(t, flagGates.Set("")) }()
+
+	assert.EqualError(t, flagGates.Set("DMLBatch"), `invalid feature gate "DMLBatch", expected <name>=<true|false>`)
+	assert.EqualError(t, flagGates.Set("NoSuchGate=true"), "unknown feature gate NoSuchGate")
+	assert.EqualError(t, flagGates.Set("DMLBatch=maybe"), `invalid value of feature gate DMLBatch: "maybe"`)
+
+	require.NoError(t, flagGates.Set("DMLBatch=false"))
+	assert.False(t, DMLBatch.Enabled(nil))
+	assert.True(t, ParallelVPlayer.Enabled(nil))
+}
+
+func TestGates(t *testing.T) {
+	assert.Equal(t, []*Gate{DMLBatch, ParallelVPlayer}, Gates())
+	assert.Equal(t, ParallelVPlayer, Lookup("ParallelVPlayer"))
+	assert.Nil(t, Lookup("NoSuchGate"))
+	assert.Panics(t, func() { register("DMLBatch", Alpha, "") })
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregate
+
+// The gates are all declared here, rather than next to their features, so
+// that every component knows all of them, e.g. to validate the overrides
+// set through vtctld.
+var (
+	// DMLBatch gates the execution of the UPDATE and DELETE statements in
+	// batches by vtgate, with the DML_BATCH_SIZE directive, in the keyspace
+	// of the table.
+	DMLBatch = register("DMLBatch", Beta, "Execute the UPDATE and DELETE statements with the DML_BATCH_SIZE directive in batches at vtgate.")
+	// ParallelVPlayer gates the parallel apply of independent transactions
+	// by the vplayers of the target keyspace, with
+	// --vreplication_parallel_apply_workers.
+	ParallelVPlayer = register("ParallelVPlayer", Beta, "Apply the independent transactions of the source in parallel in the vplayers of the target keyspace, with --vreplication_parallel_apply_workers.")
+)
@@ -142,6 +142,11 @@ func SecureDialOption(cert, key, ca, crl, name string) (grpc.DialOption, error)
 
 	// Create the creds server options.
 	creds := credentials.NewTLS(config)
+	if *vttls.CertificateReloadInterval > 0 {
+		creds = grpccommon.NewReloadingTLS(config, func() (*tls.Config, error) {
+			return vttls.ClientConfig(vttls.VerifyIdentity, cert, key, ca, crl, name, tls.VersionTLS12)
+		})
+	}
 	return grpc.WithTransportCredentials(creds), nil
 }
 
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpccommon
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadingTLS are TLS transport credentials that get a new TLS config for
+// each handshake, so the connections use the current certificates and CAs
+// once they are rotated.
+type reloadingTLS struct {
+	credentials.TransportCredentials
+	newConfig          func() (*tls.Config, error)
+	serverNameOverride string
+}
+
+// NewReloadingTLS returns TLS transport credentials that call newConfig for
+// each handshake. config is the initial TLS config, which is used to
+// describe the credentials.
+func NewReloadingTLS(config *tls.Config, newConfig func() (*tls.Config, error)) credentials.TransportCredentials {
+	return &reloadingTLS{
+		TransportCredentials: credentials.NewTLS(config),
+		newConfig:            newConfig,
+	}
+}
+
+func (c *reloadingTLS) credentials() (credentials.TransportCredentials, error) {
+	config, err := c.newConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.serverNameOverride != "" {
+		config = config.Clone()
+		config.ServerName = c.serverNameOverride
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ClientHandshake is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLS) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	return creds.ClientHandshake(ctx, authority, conn)
+}
+
+// ServerHandshake is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLS) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	return creds.ServerHandshake(conn)
+}
+
+// Clone is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLS) Clone() credentials.TransportCredentials {
+	return &reloadingTLS{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		newConfig:            c.newConfig,
+		serverNameOverride:   c.serverNameOverride,
+	}
+}
+
+// OverrideServerName is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLS) OverrideServerName(serverNameOverride string) error {
+	c.serverNameOverride = serverNameOverride
+	return c.TransportCredentials.OverrideServerName(serverNameOverride)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logutil
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// tailBlockSize is the size of the blocks read backwards from the end of a
+// log file by TailLog.
+const tailBlockSize = 64 * 1024
+
+// LogFile returns the path of the current log file of a level, INFO, WARNING,
+// ERROR or FATAL, i.e. the symlink glog maintains in the log directory.
+func LogFile(level string) (string, error) {
+	valid := false
+	for _, l := range levels {
+		valid = valid || l == level
+	}
+	if !valid {
+		return "", fmt.Errorf("invalid log level %q, expected one of %v", level, levels)
+	}
+
+	dir := os.TempDir()
+	if f := flag.Lookup("log_dir"); f != nil && f.Value.String() != "" {
+		dir = f.Value.String()
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s.%s", filepath.Base(os.Args[0]), level)), nil
+}
+
+// TailLog returns at most the last n lines of the current log file of a
+// level, see LogFile.
+func TailLog(level string, n int) ([]byte, error) {
+	path, err := LogFile(level)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return tail(f, n)
+}
+
+// tail returns at most the last n lines of f, reading it backwards so that
+// only the end of a large file is read.
+func tail(f io.ReadSeeker, n int) ([]byte, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	offset := size
+	for offset > 0 {
+		// The last byte of the file is usually the newline that ends the last
+		// line, hence the n+1 newlines.
+		if bytes.Count(data, []byte{'\n'}) > n {
+			break
+		}
+		blockSize := int64(tailBlockSize)
+		if offset < blockSize {
+			blockSize = offset
+		}
+		offset -= blockSize
+		block := make([]byte, blockSize)
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(f, block); err != nil {
+			return nil, err
+		}
+		data = append(block, data...)
+	}
+
+	lines := bytes.SplitAfter(data, []byte{'\n'})
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return bytes.Join(lines, nil), nil
+}
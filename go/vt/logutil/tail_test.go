@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTail(t *testing.T) {
+	var long strings.Builder
+	for i := 1; i <= 10000; i++ {
+		fmt.Fprintf(&long, "line %d of the log\n", i)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		n    int
+		want string
+	}{
+		{
+			name: "empty file",
+			data: "",
+			n:    3,
+			want: "",
+		},
+		{
+			name: "fewer lines than asked",
+			data: "a\nb\n",
+			n:    3,
+			want: "a\nb\n",
+		},
+		{
+			name: "last line without newline",
+			data: "a\nb\nc",
+			n:    2,
+			want: "b\nc",
+		},
+		{
+			name: "across blocks",
+			data: long.String(),
+			n:    2,
+			want: "line 9999 of the log\nline 10000 of the log\n",
+		},
+		{
+			name: "zero lines",
+			data: "a\nb\n",
+			n:    0,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tail(bytes.NewReader([]byte(tt.data)), tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	got, err := tail(bytes.NewReader([]byte(long.String())), 5000)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(got), "line 5001 of the log\n"))
+	assert.Equal(t, 5000, strings.Count(string(got), "\n"))
+}
+
+func TestLogFile(t *testing.T) {
+	_, err := LogFile("DEBUG")
+	assert.Error(t, err)
+
+	path, err := LogFile("INFO")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(path, ".INFO"), path)
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file handles the encryption of the files of the builtin backup engine.
+// The files are encrypted with envelope encryption: a new AES-256 data key is
+// generated for each backup, the files are encrypted with it using AES-GCM,
+// and the data key is stored in the MANIFEST, wrapped by a BackupKMS.
+
+var (
+	backupEncryptionKMS     = flag.String("backup_encryption_kms", "", "if set, the builtin backup engine encrypts the backup files with a data key wrapped by this key management service (e.g. file). Restores use the key management service that was used to take the backup.")
+	backupEncryptionKeyFile = flag.String("backup_encryption_key_file", "", "path to the hex-encoded 32 bytes key used by the file key management service to wrap the backup data keys")
+)
+
+const (
+	backupEncryptionAlgorithm = "AES-256-GCM"
+	backupDataKeySize         = 32
+	// backupEncryptionChunkSize is the size of the plaintext of the chunks
+	// the files are encrypted in.
+	backupEncryptionChunkSize = 64 * 1024
+
+	chunkFlagNone  byte = 0
+	chunkFlagFinal byte = 1
+)
+
+// BackupKMS is a key management service which wraps the data keys of the
+// encrypted backups.
+type BackupKMS interface {
+	// WrapKey encrypts a data key. It returns the wrapped key, and the id
+	// of the key encryption key used to wrap it.
+	WrapKey(ctx context.Context, dataKey []byte) (wrappedKey []byte, keyID string, err error)
+
+	// UnwrapKey decrypts a data key wrapped by WrapKey.
+	UnwrapKey(ctx context.Context, wrappedKey []byte, keyID string) ([]byte, error)
+}
+
+// BackupKMSMap contains the registered implementations of BackupKMS.
+var BackupKMSMap = make(map[string]BackupKMS)
+
+// BackupEncryption describes how the files of a backup are encrypted. It is
+// stored in the MANIFEST.
+type BackupEncryption struct {
+	// Algorithm is the algorithm the files are encrypted with.
+	Algorithm string
+
+	// KMS is the name of the BackupKMS which wrapped the data key.
+	KMS string
+
+	// KeyID identifies the key encryption key that wrapped the data key.
+	KeyID string
+
+	// WrappedKey is the wrapped data key.
+	WrappedKey []byte
+}
+
+func getBackupKMS(name string) (BackupKMS, error) {
+	kms, ok := BackupKMSMap[name]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "unknown backup encryption KMS %q", name)
+	}
+	return kms, nil
+}
+
+// newBackupEncryption generates a new data key for a backup, if the backup
+// encryption is enabled. It returns the data key and its description for the
+// MANIFEST, or nil if the backup is not encrypted.
+func newBackupEncryption(ctx context.Context) ([]byte, *BackupEncryption, error) {
+	if *backupEncryptionKMS == "" {
+		return nil, nil, nil
+	}
+	kms, err := getBackupKMS(*backupEncryptionKMS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataKey := make([]byte, backupDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, vterrors.Wrap(err, "cannot generate the backup data key")
+	}
+	wrappedKey, keyID, err := kms.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "cannot wrap the backup data key with %v", *backupEncryptionKMS)
+	}
+	return dataKey, &BackupEncryption{
+		Algorithm:  backupEncryptionAlgorithm,
+		KMS:        *backupEncryptionKMS,
+		KeyID:      keyID,
+		WrappedKey: wrappedKey,
+	}, nil
+}
+
+// dataKey unwraps the data key of the backup.
+func (enc *BackupEncryption) dataKey(ctx context.Context) ([]byte, error) {
+	if enc.Algorithm != backupEncryptionAlgorithm {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unsupported backup encryption algorithm %q", enc.Algorithm)
+	}
+	kms, err := getBackupKMS(enc.KMS)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := kms.UnwrapKey(ctx, enc.WrappedKey, enc.KeyID)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot unwrap the backup data key with %v", enc.KMS)
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the n-th chunk of a file: the random nonce
+// of the file, xored with n.
+func chunkNonce(fileNonce []byte, n uint64) []byte {
+	nonce := make([]byte, len(fileNonce))
+	copy(nonce, fileNonce)
+	offset := len(nonce) - 8
+	binary.BigEndian.PutUint64(nonce[offset:], binary.BigEndian.Uint64(nonce[offset:])^n)
+	return nonce
+}
+
+// encryptWriter encrypts the data written to it in chunks. The file starts
+// with a random nonce, followed by the chunks. Each chunk is a flag byte,
+// which marks the final chunk, the 4 bytes length of the sealed chunk, and
+// the sealed chunk. The flag is authenticated, so a truncated file is
+// detected on decryption.
+type encryptWriter struct {
+	w      io.Writer
+	gcm    cipher.AEAD
+	nonce  []byte
+	n      uint64
+	buf    []byte
+	header bool
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:     w,
+		gcm:   gcm,
+		nonce: nonce,
+		buf:   make([]byte, 0, backupEncryptionChunkSize),
+	}, nil
+}
+
+// Write is part of the io.Writer interface.
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(ew.buf) == backupEncryptionChunkSize {
+			if err := ew.writeChunk(chunkFlagNone); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ew.buf[len(ew.buf):backupEncryptionChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the final chunk. It doesn't close the underlying writer.
+func (ew *encryptWriter) Close() error {
+	return ew.writeChunk(chunkFlagFinal)
+}
+
+func (ew *encryptWriter) writeChunk(chunkFlag byte) error {
+	if !ew.header {
+		if _, err := ew.w.Write(ew.nonce); err != nil {
+			return err
+		}
+		ew.header = true
+	}
+	sealed := ew.gcm.Seal(nil, chunkNonce(ew.nonce, ew.n), ew.buf, []byte{chunkFlag})
+	ew.n++
+	ew.buf = ew.buf[:0]
+
+	var header [5]byte
+	header[0] = chunkFlag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := ew.w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := ew.w.Write(sealed)
+	return err
+}
+
+// decryptReader decrypts the data written by an encryptWriter.
+type decryptReader struct {
+	r     *bufio.Reader
+	gcm   cipher.AEAD
+	nonce []byte
+	n     uint64
+	buf   []byte
+	final bool
+}
+
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	dr := &decryptReader{
+		r:     bufio.NewReaderSize(r, backupEncryptionChunkSize+gcm.Overhead()+5),
+		gcm:   gcm,
+		nonce: make([]byte, gcm.NonceSize()),
+	}
+	if _, err := io.ReadFull(dr.r, dr.nonce); err != nil {
+		return nil, vterrors.Wrap(err, "cannot read the nonce of the encrypted file")
+	}
+	return dr, nil
+}
+
+// Read is part of the io.Reader interface.
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.final {
+			return 0, io.EOF
+		}
+		if err := dr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+func (dr *decryptReader) readChunk() error {
+	var header [5]byte
+	if _, err := io.ReadFull(dr.r, header[:]); err != nil {
+		if err == io.EOF {
+			return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "the encrypted file is truncated")
+		}
+		return err
+	}
+	chunkFlag := header[0]
+	size := binary.BigEndian.Uint32(header[1:])
+	if chunkFlag > chunkFlagFinal || size > uint32(backupEncryptionChunkSize+dr.gcm.Overhead()) {
+		return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "the encrypted file is corrupted")
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(dr.r, sealed); err != nil {
+		return err
+	}
+	plain, err := dr.gcm.Open(sealed[:0], chunkNonce(dr.nonce, dr.n), sealed, []byte{chunkFlag})
+	if err != nil {
+		return vterrors.Wrap(err, "cannot decrypt the file, it is corrupted or encrypted with another key")
+	}
+	dr.n++
+	dr.buf = plain
+	if chunkFlag == chunkFlagFinal {
+		dr.final = true
+		if _, err := dr.r.Peek(1); err == nil {
+			return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "the encrypted file has trailing data")
+		} else if err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileBackupKMS is a BackupKMS which wraps the data keys with a key read
+// from a local file, with AES-GCM.
+type fileBackupKMS struct{}
+
+func (fileBackupKMS) key() ([]byte, string, error) {
+	if *backupEncryptionKeyFile == "" {
+		return nil, "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no backup_encryption_key_file specified")
+	}
+	data, err := os.ReadFile(*backupEncryptionKeyFile)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != backupDataKeySize {
+		return nil, "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "%v must contain a hex-encoded 32 bytes key", *backupEncryptionKeyFile)
+	}
+	// The key id is a fingerprint of the key, to detect a restore with
+	// another key.
+	fingerprint := sha256.Sum256(key)
+	return key, hex.EncodeToString(fingerprint[:8]), nil
+}
+
+// WrapKey is part of the BackupKMS interface.
+func (kms fileBackupKMS) WrapKey(ctx context.Context, dataKey []byte) ([]byte, string, error) {
+	key, keyID, err := kms.key()
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", err
+	}
+	return gcm.Seal(nonce, nonce, dataKey, nil), keyID, nil
+}
+
+// UnwrapKey is part of the BackupKMS interface.
+func (kms fileBackupKMS) UnwrapKey(ctx context.Context, wrappedKey []byte, keyID string) ([]byte, error) {
+	key, currentKeyID, err := kms.key()
+	if err != nil {
+		return nil, err
+	}
+	if keyID != currentKeyID {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the backup was encrypted with the key %v, not with the key %v of %v", keyID, currentKeyID, *backupEncryptionKeyFile)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < gcm.NonceSize() {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid wrapped key")
+	}
+	nonce, sealed := wrappedKey[:gcm.NonceSize()], wrappedKey[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func init() {
+	BackupKMSMap["file"] = fileBackupKMS{}
+}
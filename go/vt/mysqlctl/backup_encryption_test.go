@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptForTest(t *testing.T, key, data []byte) []byte {
+	var buf bytes.Buffer
+	ew, err := newEncryptWriter(&buf, key)
+	require.NoError(t, err)
+	// Write in uneven pieces, to cross the chunk boundaries.
+	for len(data) > 0 {
+		n := len(data)
+		if n > 1000 {
+			n = 1000
+		}
+		_, err := ew.Write(data[:n])
+		require.NoError(t, err)
+		data = data[n:]
+	}
+	require.NoError(t, ew.Close())
+	return buf.Bytes()
+}
+
+func decryptForTest(key, encrypted []byte) ([]byte, error) {
+	dr, err := newDecryptReader(bytes.NewReader(encrypted), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(dr)
+}
+
+func TestBackupEncryptionRoundTrip(t *testing.T) {
+	key := make([]byte, backupDataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, backupEncryptionChunkSize, backupEncryptionChunkSize + 1, 3*backupEncryptionChunkSize + 17} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		encrypted := encryptForTest(t, key, data)
+		if size > 16 {
+			assert.NotContains(t, string(encrypted), string(data))
+		}
+		decrypted, err := decryptForTest(key, encrypted)
+		require.NoError(t, err, "size %v", size)
+		assert.Equal(t, data, decrypted, "size %v", size)
+	}
+}
+
+func TestBackupEncryptionCorruption(t *testing.T) {
+	key := make([]byte, backupDataKeySize)
+	data := bytes.Repeat([]byte("vitess"), backupEncryptionChunkSize)
+	encrypted := encryptForTest(t, key, data)
+
+	// A truncated file, even at a chunk boundary, is detected.
+	chunkEnd := 12 + 5 + backupEncryptionChunkSize + 16
+	_, err := decryptForTest(key, encrypted[:chunkEnd])
+	assert.ErrorContains(t, err, "the encrypted file is truncated")
+	_, err = decryptForTest(key, encrypted[:len(encrypted)-1])
+	assert.Error(t, err)
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[chunkEnd-1] ^= 1
+	_, err = decryptForTest(key, tampered)
+	assert.ErrorContains(t, err, "cannot decrypt the file")
+
+	otherKey := bytes.Repeat([]byte{1}, backupDataKeySize)
+	_, err = decryptForTest(otherKey, encrypted)
+	assert.ErrorContains(t, err, "cannot decrypt the file")
+
+	_, err = decryptForTest(key, append(encrypted, 0))
+	assert.ErrorContains(t, err, "trailing data")
+}
+
+func TestFileBackupKMS(t *testing.T) {
+	defer func(kms, keyFile string) {
+		*backupEncryptionKMS = kms
+		*backupEncryptionKeyFile = keyFile
+	}(*backupEncryptionKMS, *backupEncryptionKeyFile)
+
+	dir := t.TempDir()
+	keyFile := path.Join(dir, "backup.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f\n"), 0600))
+	*backupEncryptionKeyFile = keyFile
+
+	// The backups aren't encrypted by default.
+	dataKey, encryption, err := newBackupEncryption(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, dataKey)
+	assert.Nil(t, encryption)
+
+	*backupEncryptionKMS = "file"
+	dataKey, encryption, err = newBackupEncryption(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, dataKey, backupDataKeySize)
+	assert.Equal(t, "AES-256-GCM", encryption.Algorithm)
+	assert.Equal(t, "file", encryption.KMS)
+	assert.NotContains(t, string(encryption.WrappedKey), string(dataKey))
+
+	unwrapped, err := encryption.dataKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// The restore fails with another key.
+	require.NoError(t, os.WriteFile(keyFile, bytes.Repeat([]byte("ab"), backupDataKeySize), 0600))
+	_, err = encryption.dataKey(context.Background())
+	assert.ErrorContains(t, err, "the backup was encrypted with the key "+encryption.KeyID)
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("short"), 0600))
+	_, _, err = newBackupEncryption(context.Background())
+	assert.ErrorContains(t, err, "must contain a hex-encoded 32 bytes key")
+
+	*backupEncryptionKMS = "unknown"
+	_, _, err = newBackupEncryption(context.Background())
+	assert.ErrorContains(t, err, `unknown backup encryption KMS "unknown"`)
+}
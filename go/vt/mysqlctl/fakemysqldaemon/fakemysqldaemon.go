@@ -90,6 +90,12 @@ type FakeMysqlDaemon struct {
 	// PrimaryStatusError is used by PrimaryStatus
 	PrimaryStatusError error
 
+	// CurrentGroupMember is returned by GroupMember
+	CurrentGroupMember mysql.GroupMember
+
+	// GroupMemberError is used by GroupMember
+	GroupMemberError error
+
 	// CurrentSourceHost is returned by ReplicationStatus
 	CurrentSourceHost string
 
@@ -160,6 +166,9 @@ type FakeMysqlDaemon struct {
 	// FetchSuperQueryResults is used by FetchSuperQuery
 	FetchSuperQueryMap map[string]*sqltypes.Result
 
+	// FetchReadOnlyQueryMap is used by FetchReadOnlyQuery
+	FetchReadOnlyQueryMap map[string]*sqltypes.Result
+
 	// BinlogPlayerEnabled is used by {Enable,Disable}BinlogPlayer
 	BinlogPlayerEnabled sync2.AtomicBool
 
@@ -302,6 +311,14 @@ func (fmd *FakeMysqlDaemon) PrimaryStatus(ctx context.Context) (mysql.PrimarySta
 	}, nil
 }
 
+// GroupMember is part of the MysqlDaemon interface
+func (fmd *FakeMysqlDaemon) GroupMember(ctx context.Context) (mysql.GroupMember, error) {
+	if fmd.GroupMemberError != nil {
+		return mysql.GroupMember{}, fmd.GroupMemberError
+	}
+	return fmd.CurrentGroupMember, nil
+}
+
 // GetGTIDPurged is part of the MysqlDaemon interface
 func (fmd *FakeMysqlDaemon) GetGTIDPurged(ctx context.Context) (mysql.Position, error) {
 	return mysql.Position{}, nil
@@ -514,6 +531,15 @@ func (fmd *FakeMysqlDaemon) FetchSuperQuery(ctx context.Context, query string) (
 	return qr, nil
 }
 
+// FetchReadOnlyQuery returns the results from the map, if any
+func (fmd *FakeMysqlDaemon) FetchReadOnlyQuery(ctx context.Context, query string, maxrows int) (*sqltypes.Result, error) {
+	qr, ok := fmd.FetchReadOnlyQueryMap[query]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query: %v", query)
+	}
+	return qr, nil
+}
+
 // EnableBinlogPlayback is part of the MysqlDaemon interface
 func (fmd *FakeMysqlDaemon) EnableBinlogPlayback() error {
 	fmd.BinlogPlayerEnabled.Set(true)
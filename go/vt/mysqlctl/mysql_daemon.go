@@ -64,6 +64,7 @@ type MysqlDaemon interface {
 	ResetReplicationParameters(ctx context.Context) error
 	GetBinlogInformation(ctx context.Context) (binlogFormat string, logEnabled bool, logReplicaUpdate bool, binlogRowImage string, err error)
 	GetGTIDMode(ctx context.Context) (gtidMode string, err error)
+	GroupMember(ctx context.Context) (mysql.GroupMember, error)
 
 	// reparenting related methods
 	ResetReplication(ctx context.Context) error
@@ -108,6 +109,10 @@ type MysqlDaemon interface {
 	// FetchSuperQuery executes one query, returns the result
 	FetchSuperQuery(ctx context.Context, query string) (*sqltypes.Result, error)
 
+	// FetchReadOnlyQuery executes one query as the app user in a read-only
+	// transaction that is rolled back, returns the result
+	FetchReadOnlyQuery(ctx context.Context, query string, maxrows int) (*sqltypes.Result, error)
+
 	// EnableBinlogPlayback enables playback of binlog events
 	EnableBinlogPlayback() error
 
@@ -102,6 +102,30 @@ func (mysqld *Mysqld) FetchSuperQuery(ctx context.Context, query string) (*sqlty
 	return qr, nil
 }
 
+// FetchReadOnlyQuery returns the results of executing a query as the app
+// user, in a read-only transaction that is rolled back afterwards. The query
+// is killed if ctx expires.
+func (mysqld *Mysqld) FetchReadOnlyQuery(ctx context.Context, query string, maxrows int) (*sqltypes.Result, error) {
+	conn, connErr := getPoolReconnect(ctx, mysqld.appPool)
+	if connErr != nil {
+		return nil, connErr
+	}
+	defer conn.Recycle()
+	if _, err := mysqld.executeFetchContext(ctx, conn, "start transaction read only", 1, false); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if conn.IsClosed() {
+			return
+		}
+		if _, err := conn.ExecuteFetch("rollback", 1, false); err != nil {
+			conn.Close()
+		}
+	}()
+	log.V(6).Infof("fetch read-only %v", query)
+	return mysqld.executeFetchContext(ctx, conn, query, maxrows, true)
+}
+
 // executeFetchContext calls ExecuteFetch() on the given connection,
 // while respecting Context deadline and cancellation.
 func (mysqld *Mysqld) executeFetchContext(ctx context.Context, conn *dbconnpool.PooledDBConnection, query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
@@ -343,6 +343,17 @@ func (mysqld *Mysqld) PrimaryStatus(ctx context.Context) (mysql.PrimaryStatus, e
 	return conn.ShowPrimaryStatus()
 }
 
+// GroupMember returns the state of the mysqld in its replication group.
+func (mysqld *Mysqld) GroupMember(ctx context.Context) (mysql.GroupMember, error) {
+	conn, err := getPoolReconnect(ctx, mysqld.dbaPool)
+	if err != nil {
+		return mysql.GroupMember{}, err
+	}
+	defer conn.Recycle()
+
+	return conn.ShowGroupMember()
+}
+
 // GetGTIDPurged returns the gtid purged statuses
 func (mysqld *Mysqld) GetGTIDPurged(ctx context.Context) (mysql.Position, error) {
 	conn, err := getPoolReconnect(ctx, mysqld.dbaPool)
@@ -72,7 +72,9 @@ func tableListSQL(tables []string) (string, error) {
 }
 
 // GetSchema returns the schema for database for tables listed in
-// tables. If tables is empty, return the schema for all tables.
+// tables. If tables is empty, return the schema for all tables. If
+// request.TableSizesOnly is set, only the names, types, sizes and row counts
+// of the tables are returned.
 func (mysqld *Mysqld) GetSchema(ctx context.Context, dbName string, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error) {
 	sd := &tabletmanagerdatapb.SchemaDefinition{}
 	backtickDBName := sqlescape.EscapeID(dbName)
@@ -92,6 +94,13 @@ func (mysqld *Mysqld) GetSchema(ctx context.Context, dbName string, request *tab
 		return nil, err
 	}
 
+	if request.TableSizesOnly {
+		// The sizes and row counts are estimates of information_schema, so
+		// neither the tables nor their definitions have to be read.
+		sd.TableDefinitions = tds
+		return sd, nil
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -1,14 +1,18 @@
 package mysqlctl
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconnpool"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 )
 
 var queryMap map[string]*sqltypes.Result
@@ -75,3 +79,32 @@ func TestColumnList(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, `[name:"col1" type:VARCHAR]`, fmt.Sprintf("%+v", fields))
 }
+
+func TestGetSchemaTableSizesOnly(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery("SELECT 1", &sqltypes.Result{})
+	db.AddQuery("SHOW CREATE DATABASE IF NOT EXISTS `test`", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("Database|Create Database", "varchar|varchar"),
+		"test|CREATE DATABASE `test`",
+	))
+	db.AddQuery("SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = 'test' AND table_type = 'BASE TABLE'", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows", "varchar|varchar|uint64|uint64"),
+		"t2|BASE TABLE|16384|10",
+		"t1|BASE TABLE|32768|200",
+	))
+
+	dbaPool := dbconnpool.NewConnectionPool("DbaConnPool", 1, time.Minute, 0)
+	dbaPool.Open(db.ConnParams())
+	defer dbaPool.Close()
+	mysqld := &Mysqld{dbaPool: dbaPool}
+
+	// The schemas and columns of the tables are not read: fakesqldb rejects
+	// the queries that it wasn't told about.
+	sd, err := mysqld.GetSchema(context.Background(), "test", &tabletmanagerdatapb.GetSchemaRequest{TableSizesOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, []*tabletmanagerdatapb.TableDefinition{
+		{Name: "t1", Type: "BASE TABLE", DataLength: 32768, RowCount: 200},
+		{Name: "t2", Type: "BASE TABLE", DataLength: 16384, RowCount: 10},
+	}, sd.TableDefinitions)
+}
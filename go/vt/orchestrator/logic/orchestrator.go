@@ -142,6 +142,9 @@ func acceptSignals() {
 						}
 					}
 				}()
+				if stopComponentVersion != nil {
+					stopComponentVersion()
+				}
 				log.Infof("Shutting down orchestrator")
 				os.Exit(0)
 			}
@@ -21,6 +21,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -29,6 +31,7 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/vt/orchestrator/config"
 
 	"vitess.io/vitess/go/vt/orchestrator/db"
@@ -46,6 +49,10 @@ var (
 	clustersToWatch   = flag.String("clusters_to_watch", "", "Comma-separated list of keyspaces or keyspace/shards that this instance will monitor and repair. Defaults to all clusters in the topology. Example: \"ks1,ks2/-80\"")
 	shutdownWaitTime  = flag.Duration("shutdown_wait_time", 30*time.Second, "maximum time to wait for vtorc to release all the locks that it is holding before shutting down on SIGTERM")
 	shardsLockCounter int32
+
+	// stopComponentVersion removes the version of vtorc from the topo when
+	// it shuts down.
+	stopComponentVersion func()
 )
 
 // OpenTabletDiscovery opens the vitess topo if enables and returns a ticker
@@ -55,6 +62,8 @@ func OpenTabletDiscovery() <-chan time.Time {
 	ts = topo.Open()
 	// TODO(sougou): remove ts and push some functions into inst.
 	inst.TopoServ = ts
+	// Record the version of vtorc in the topo for the version skew checks.
+	stopComponentVersion = topotools.RegisterComponentVersion(context.Background(), ts, topotools.ComponentVtorc, vtorcID(), "")
 	// Clear existing cache and perform a new refresh.
 	if _, err := db.ExecOrchestrator("delete from vitess_tablet"); err != nil {
 		log.Errore(err)
@@ -66,6 +75,21 @@ func OpenTabletDiscovery() <-chan time.Time {
 	return time.Tick(15 * time.Second) //nolint SA1015: using time.Tick leaks the underlying ticker
 }
 
+// vtorcID returns the host:port of the HTTP server of vtorc, which identifies
+// it among the components that record their version in the topo.
+func vtorcID() string {
+	host, port, err := net.SplitHostPort(config.Config.ListenAddress)
+	if err != nil {
+		host, port = "", config.Config.ListenAddress
+	}
+	if host == "" {
+		if host, err = netutil.FullyQualifiedHostname(); err != nil {
+			host, _ = os.Hostname()
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
 // RefreshTablets reloads the tablets from topo.
 func RefreshTablets(forceRefresh bool) {
 	refreshTabletsUsing(func(instanceKey *inst.InstanceKey) {
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocompat_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtgate/grpcvtgateservice"
+	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
+	"vitess.io/vitess/go/vt/vttablet/grpctmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	tabletmanagerservicepb "vitess.io/vitess/go/vt/proto/tabletmanagerservice"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtgateservicepb "vitess.io/vitess/go/vt/proto/vtgateservice"
+)
+
+// These tests send the golden requests of the previous release to the current
+// servers, as its clients would, and have the current clients read its golden
+// responses.
+
+// serve starts a gRPC server on a local port, and returns its port and a
+// connection to it.
+func serve(t *testing.T, register func(s *grpc.Server)) (int32, *grpc.ClientConn) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	register(s)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return int32(listener.Addr().(*net.TCPAddr).Port), conn
+}
+
+// fakeVTGateService records the sessions of the queries.
+type fakeVTGateService struct {
+	vtgateservice.VTGateService
+	sessions []*vtgatepb.Session
+}
+
+func (f *fakeVTGateService) Execute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) (*vtgatepb.Session, *sqltypes.Result, error) {
+	f.sessions = append(f.sessions, session)
+	return session, &sqltypes.Result{}, nil
+}
+
+func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+	f.sessions = append(f.sessions, session)
+	return session, nil, nil
+}
+
+func (f *fakeVTGateService) StreamExecute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
+	f.sessions = append(f.sessions, session)
+	return nil
+}
+
+func (f *fakeVTGateService) HandlePanic(err *error) {}
+
+func TestVTGateCompat(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeVTGateService{}
+	_, conn := serve(t, func(s *grpc.Server) { grpcvtgateservice.RegisterForTest(s, fake) })
+	client := vtgateservicepb.NewVitessClient(conn)
+
+	_, err := client.Execute(ctx, readGolden(t, "vtgate.ExecuteRequest", "keyspace_shard").(*vtgatepb.ExecuteRequest))
+	require.NoError(t, err)
+	_, err = client.Execute(ctx, readGolden(t, "vtgate.ExecuteRequest", "session").(*vtgatepb.ExecuteRequest))
+	require.NoError(t, err)
+	_, err = client.ExecuteBatch(ctx, readGolden(t, "vtgate.ExecuteBatchRequest", "keyspace_shard").(*vtgatepb.ExecuteBatchRequest))
+	require.NoError(t, err)
+	stream, err := client.StreamExecute(ctx, readGolden(t, "vtgate.StreamExecuteRequest", "keyspace_shard").(*vtgatepb.StreamExecuteRequest))
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Error(t, err)
+
+	want := []*vtgatepb.Session{{
+		Autocommit:   true,
+		TargetString: "ks/-80@replica",
+		Options:      &querypb.ExecuteOptions{IncludedFields: querypb.ExecuteOptions_ALL, Workload: querypb.ExecuteOptions_OLAP},
+	}, {
+		Autocommit:   true,
+		TargetString: "ks@primary",
+		Options:      &querypb.ExecuteOptions{IncludedFields: querypb.ExecuteOptions_TYPE_ONLY},
+	}, {
+		Autocommit:   true,
+		TargetString: "ks@rdonly",
+	}, {
+		Autocommit:   true,
+		TargetString: "ks/80-@replica",
+	}}
+	utils.MustMatch(t, want, fake.sessions)
+}
+
+// oldTabletManager answers with the golden responses of the previous release.
+type oldTabletManager struct {
+	tabletmanagerservicepb.UnimplementedTabletManagerServer
+	t *testing.T
+}
+
+func (tm *oldTabletManager) DemotePrimary(context.Context, *tabletmanagerdatapb.DemotePrimaryRequest) (*tabletmanagerdatapb.DemotePrimaryResponse, error) {
+	return readGolden(tm.t, "tabletmanagerdata.DemotePrimaryResponse", "primary_status").(*tabletmanagerdatapb.DemotePrimaryResponse), nil
+}
+
+func (tm *oldTabletManager) StopReplicationAndGetStatus(context.Context, *tabletmanagerdatapb.StopReplicationAndGetStatusRequest) (*tabletmanagerdatapb.StopReplicationAndGetStatusResponse, error) {
+	return readGolden(tm.t, "tabletmanagerdata.StopReplicationAndGetStatusResponse", "status").(*tabletmanagerdatapb.StopReplicationAndGetStatusResponse), nil
+}
+
+func TestTabletManagerCompat(t *testing.T) {
+	ctx := context.Background()
+	port, _ := serve(t, func(s *grpc.Server) {
+		tabletmanagerservicepb.RegisterTabletManagerServer(s, &oldTabletManager{t: t})
+	})
+	tablet := &topodatapb.Tablet{Hostname: "localhost", PortMap: map[string]int32{"grpc": port}}
+	client := grpctmclient.NewClient()
+	defer client.Close()
+
+	primaryStatus, err := client.DemotePrimary(ctx, tablet)
+	require.NoError(t, err)
+	assert.Equal(t, status.Position, primaryStatus.Position)
+	assert.Equal(t, status.FilePosition, primaryStatus.FilePosition)
+
+	hybridStatus, stopStatus, err := client.StopReplicationAndGetStatus(ctx, tablet, 0)
+	require.NoError(t, err)
+	utils.MustMatch(t, status, hybridStatus)
+	utils.MustMatch(t, status, stopStatus.Before)
+	utils.MustMatch(t, status, stopStatus.After)
+}
+
+func TestVtctldCompat(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: alias, Keyspace: "ks", Shard: "-80", Type: topodatapb.TabletType_REPLICA}))
+	_, conn := serve(t, func(s *grpc.Server) { grpcvtctldserver.StartServer(s, ts) })
+	client := vtctlservicepb.NewVtctldClient(conn)
+
+	ks, err := client.GetKeyspace(ctx, readGolden(t, "vtctldata.GetKeyspaceRequest", "keyspace").(*vtctldatapb.GetKeyspaceRequest))
+	require.NoError(t, err)
+	assert.Equal(t, "ks", ks.Keyspace.Name)
+
+	tablet, err := client.GetTablet(ctx, readGolden(t, "vtctldata.GetTabletRequest", "alias").(*vtctldatapb.GetTabletRequest))
+	require.NoError(t, err)
+	utils.MustMatch(t, alias, tablet.Tablet.Alias)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocompat_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
+)
+
+// The golden files of a release are the serialized requests and responses of
+// its components, in testdata/<release>/<message>/<case>.bin. The ones of the
+// previous major release must decode into the messages of the current one
+// without losing a field, which TestGolden checks. When a release is cut, its
+// golden files are generated from goldens with
+//
+//	go test ./go/vt/protocompat -run TestGolden -args -generate testdata/v15
+//
+// and the ones of the release before it are removed.
+var generate = flag.String("generate", "", "write the golden files of goldens to this directory instead of checking the ones of the previous release")
+
+// previousRelease is the directory of the golden files of the previous major
+// release.
+const previousRelease = "testdata/v14"
+
+var (
+	callerID = &vtrpcpb.CallerID{Principal: "app", Component: "backend", Subcomponent: "orders"}
+	alias    = &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}
+	status   = &replicationdatapb.Status{
+		Position:              "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-42",
+		RelayLogPosition:      "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-45",
+		FilePosition:          "FilePos/vt-0000000100-bin.000001:4242",
+		IoState:               int32(2),
+		SqlState:              int32(2),
+		SourceHost:            "zone1-0000000101",
+		SourcePort:            3306,
+		ConnectRetry:          10,
+		SourceUuid:            "16b1039f-22b6-11ed-b765-0a43f95f28a3",
+		ReplicationLagSeconds: 1,
+	}
+)
+
+// goldens are the requests and responses written to the golden files of a
+// release, by message and case. They should only set the fields of the
+// release, and cover the deprecated fields that the release still sets.
+var goldens = map[string]map[string]proto.Message{
+	"vtgate.ExecuteRequest": {
+		"keyspace_shard": &vtgatepb.ExecuteRequest{
+			CallerId:      callerID,
+			Query:         &querypb.BoundQuery{Sql: "select id from t where id = :id", BindVariables: map[string]*querypb.BindVariable{"id": {Type: querypb.Type_INT64, Value: []byte("1")}}},
+			TabletType:    topodatapb.TabletType_REPLICA,
+			KeyspaceShard: "ks/-80",
+			Options:       &querypb.ExecuteOptions{IncludedFields: querypb.ExecuteOptions_ALL, Workload: querypb.ExecuteOptions_OLAP},
+		},
+		"session": &vtgatepb.ExecuteRequest{
+			CallerId: callerID,
+			Session: &vtgatepb.Session{
+				TargetString: "ks@primary",
+				Autocommit:   true,
+				Options:      &querypb.ExecuteOptions{IncludedFields: querypb.ExecuteOptions_TYPE_ONLY},
+			},
+			Query: &querypb.BoundQuery{Sql: "insert into t(id) values (1)"},
+		},
+	},
+	"vtgate.ExecuteBatchRequest": {
+		"keyspace_shard": &vtgatepb.ExecuteBatchRequest{
+			CallerId:      callerID,
+			Queries:       []*querypb.BoundQuery{{Sql: "select 1"}, {Sql: "select 2"}},
+			TabletType:    topodatapb.TabletType_RDONLY,
+			KeyspaceShard: "ks",
+			AsTransaction: true,
+		},
+	},
+	"vtgate.StreamExecuteRequest": {
+		"keyspace_shard": &vtgatepb.StreamExecuteRequest{
+			CallerId:      callerID,
+			Query:         &querypb.BoundQuery{Sql: "select * from t"},
+			TabletType:    topodatapb.TabletType_REPLICA,
+			KeyspaceShard: "ks/80-",
+		},
+	},
+	"vtgate.Session": {
+		"transaction": &vtgatepb.Session{
+			InTransaction: true,
+			ShardSessions: []*vtgatepb.Session_ShardSession{{
+				Target:        &querypb.Target{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY},
+				TransactionId: 1234,
+				TabletAlias:   alias,
+			}},
+			TargetString:    "ks@primary",
+			TransactionMode: vtgatepb.TransactionMode_MULTI,
+			SystemVariables: map[string]string{"sql_mode": "''"},
+			SessionUUID:     "a2bd9c8e-22b6-11ed-8b1e-0a43f95f28a3",
+		},
+	},
+	"tabletmanagerdata.DemotePrimaryResponse": {
+		"primary_status": &tabletmanagerdatapb.DemotePrimaryResponse{
+			DeprecatedPosition: status.Position,
+			PrimaryStatus:      &replicationdatapb.PrimaryStatus{Position: status.Position, FilePosition: status.FilePosition},
+		},
+	},
+	"tabletmanagerdata.StopReplicationAndGetStatusResponse": {
+		"status": &tabletmanagerdatapb.StopReplicationAndGetStatusResponse{
+			HybridStatus: status,
+			Status:       &replicationdatapb.StopReplicationStatus{Before: status, After: status},
+		},
+	},
+	"tabletmanagerdata.SetReplicationSourceRequest": {
+		"semi_sync": &tabletmanagerdatapb.SetReplicationSourceRequest{
+			Parent:                alias,
+			TimeCreatedNs:         1661000000000000000,
+			ForceStartReplication: true,
+			WaitPosition:          status.Position,
+			SemiSync:              true,
+		},
+	},
+	"tabletmanagerdata.ExecuteFetchAsDbaRequest": {
+		"reload_schema": &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:          []byte("alter table t add column c int"),
+			DbName:         "vt_ks",
+			MaxRows:        10000,
+			DisableBinlogs: true,
+			ReloadSchema:   true,
+		},
+	},
+	"vtctldata.GetKeyspaceRequest": {
+		"keyspace": &vtctldatapb.GetKeyspaceRequest{Keyspace: "ks"},
+	},
+	"vtctldata.GetTabletRequest": {
+		"alias": &vtctldatapb.GetTabletRequest{TabletAlias: alias},
+	},
+	"vtctldata.PlannedReparentShardRequest": {
+		"new_primary": &vtctldatapb.PlannedReparentShardRequest{
+			Keyspace:            "ks",
+			Shard:               "-80",
+			NewPrimary:          alias,
+			WaitReplicasTimeout: &vttimepb.Duration{Seconds: 30},
+		},
+	},
+	"vtctldata.EmergencyReparentShardRequest": {
+		"ignore_replicas": &vtctldatapb.EmergencyReparentShardRequest{
+			Keyspace:                  "ks",
+			Shard:                     "-80",
+			IgnoreReplicas:            []*topodatapb.TabletAlias{{Cell: "zone2", Uid: 200}},
+			WaitReplicasTimeout:       &vttimepb.Duration{Seconds: 30},
+			PreventCrossCellPromotion: true,
+		},
+	},
+	"vtctldata.ApplySchemaRequest": {
+		"online_ddl": &vtctldatapb.ApplySchemaRequest{
+			Keyspace:         "ks",
+			Sql:              []string{"alter table t add column c int"},
+			DdlStrategy:      "vitess --postpone-completion",
+			MigrationContext: "deploy-42",
+			SkipPreflight:    true,
+			CallerId:         callerID,
+		},
+	},
+	"vtctldata.GetSchemaRequest": {
+		"tables": &vtctldatapb.GetSchemaRequest{
+			TabletAlias:   alias,
+			Tables:        []string{"t", "/^u/"},
+			ExcludeTables: []string{"_vt_*"},
+			IncludeViews:  true,
+		},
+	},
+}
+
+// readGolden reads a golden file of the previous release into a message of
+// the current release.
+func readGolden(t *testing.T, message, name string) proto.Message {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(previousRelease, message, name+".bin"))
+	require.NoError(t, err)
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(message))
+	require.NoError(t, err, "message %s of the previous release was removed", message)
+	m := mt.New().Interface()
+	require.NoError(t, proto.Unmarshal(data, m))
+	return m
+}
+
+func TestGolden(t *testing.T) {
+	if *generate != "" {
+		for message, cases := range goldens {
+			dir := filepath.Join(*generate, message)
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			for name, m := range cases {
+				data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name+".bin"), data, 0644))
+			}
+		}
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(previousRelease, "*", "*.bin"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		message := filepath.Base(filepath.Dir(file))
+		name := strings.TrimSuffix(filepath.Base(file), ".bin")
+		t.Run(message+"/"+name, func(t *testing.T) {
+			m := readGolden(t, message, name)
+
+			// A field of the previous release that was removed or renumbered
+			// decodes as an unknown field.
+			assert.Empty(t, unknownFields(m.ProtoReflect()), "fields of the previous release are unknown")
+
+			// A field whose type changed encodes differently.
+			data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+			require.NoError(t, err)
+			golden, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, golden, data, "the encoding of the previous release changed")
+		})
+	}
+}
+
+// unknownFields returns the names of the messages with unknown fields in m,
+// recursively.
+func unknownFields(m protoreflect.Message) []string {
+	var names []string
+	if len(m.GetUnknown()) > 0 {
+		names = append(names, string(m.Descriptor().FullName()))
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			for i := 0; i < v.List().Len(); i++ {
+				names = append(names, unknownFields(v.List().Get(i).Message())...)
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				names = append(names, unknownFields(v.Message())...)
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			names = append(names, unknownFields(v.Message())...)
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protocompat adapts the deprecated fields of the gRPC APIs, which the
+// clients and the servers of the previous major release may still set instead
+// of their replacements, so that the components of a cluster can be upgraded
+// one at a time.
+//
+// Every adaptation of a deprecated field belongs here, rather than in the
+// RPC implementations, so that it is tested against the golden requests and
+// responses of the previous release in testdata, and removed in one place
+// when the previous release no longer sets the field.
+package protocompat
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// VTGateSession returns the session of a request to vtgate, adapted from the
+// fields of the request that the session replaces: the target, given by the
+// keyspace_shard and the tablet_type, and the options. A nil session is an
+// autocommit session. The fields only apply when the session does not set
+// them.
+func VTGateSession(session *vtgatepb.Session, keyspaceShard string, tabletType topodatapb.TabletType, options *querypb.ExecuteOptions) *vtgatepb.Session {
+	if session == nil {
+		session = &vtgatepb.Session{Autocommit: true}
+	}
+	// The target is not set without a tablet type, which would not parse.
+	if session.TargetString == "" && tabletType != topodatapb.TabletType_UNKNOWN {
+		session.TargetString = keyspaceShard + "@" + topoproto.TabletTypeLString(tabletType)
+	}
+	if session.Options == nil {
+		session.Options = options
+	}
+	return session
+}
+
+// DemotePrimaryStatus returns the status of the demoted primary of a
+// DemotePrimary response. The tablets of the older releases only return its
+// position, in the deprecated position field.
+func DemotePrimaryStatus(response *tabletmanagerdatapb.DemotePrimaryResponse) *replicationdatapb.PrimaryStatus {
+	if response.PrimaryStatus != nil {
+		return response.PrimaryStatus
+	}
+	return &replicationdatapb.PrimaryStatus{
+		Position: response.DeprecatedPosition, //nolint
+	}
+}
+
+// StopReplicationStatus returns the hybrid status and the status of a
+// StopReplicationAndGetStatus response. The tablets of the older releases only
+// return the deprecated hybrid status, whose replication threads are in the
+// state before stopping the replication and whose positions are the ones after
+// it, so it is used for both the before and the after statuses.
+func StopReplicationStatus(response *tabletmanagerdatapb.StopReplicationAndGetStatusResponse) (*replicationdatapb.Status, *replicationdatapb.StopReplicationStatus) {
+	hybridStatus := response.HybridStatus //nolint
+	if response.Status != nil {
+		return hybridStatus, &replicationdatapb.StopReplicationStatus{
+			Before: response.Status.Before,
+			After:  response.Status.After,
+		}
+	}
+	if hybridStatus == nil {
+		return nil, &replicationdatapb.StopReplicationStatus{}
+	}
+	return hybridStatus, &replicationdatapb.StopReplicationStatus{
+		Before: hybridStatus,
+		After:  proto.Clone(hybridStatus).(*replicationdatapb.Status),
+	}
+}
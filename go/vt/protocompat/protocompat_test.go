@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/test/utils"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestVTGateSession(t *testing.T) {
+	options := &querypb.ExecuteOptions{IncludedFields: querypb.ExecuteOptions_ALL}
+
+	utils.MustMatch(t, &vtgatepb.Session{Autocommit: true, TargetString: "ks/-80@replica", Options: options},
+		VTGateSession(nil, "ks/-80", topodatapb.TabletType_REPLICA, options))
+	// Without a tablet type, the target is not set.
+	utils.MustMatch(t, &vtgatepb.Session{Autocommit: true},
+		VTGateSession(nil, "ks", topodatapb.TabletType_UNKNOWN, nil))
+	// The session wins over the deprecated fields.
+	session := &vtgatepb.Session{TargetString: "ks@primary", Options: &querypb.ExecuteOptions{}}
+	utils.MustMatch(t, &vtgatepb.Session{TargetString: "ks@primary", Options: &querypb.ExecuteOptions{}},
+		VTGateSession(session, "other", topodatapb.TabletType_RDONLY, options))
+}
+
+func TestDemotePrimaryStatus(t *testing.T) {
+	status := &replicationdatapb.PrimaryStatus{Position: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-42", FilePosition: "FilePos/bin.000001:4"}
+	assert.Equal(t, status, DemotePrimaryStatus(&tabletmanagerdatapb.DemotePrimaryResponse{PrimaryStatus: status}))
+
+	utils.MustMatch(t, &replicationdatapb.PrimaryStatus{Position: status.Position},
+		DemotePrimaryStatus(&tabletmanagerdatapb.DemotePrimaryResponse{DeprecatedPosition: status.Position}))
+}
+
+func TestStopReplicationStatus(t *testing.T) {
+	before := &replicationdatapb.Status{Position: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-41", IoState: 2}
+	after := &replicationdatapb.Status{Position: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-42"}
+	hybrid := &replicationdatapb.Status{Position: after.Position, IoState: 2}
+
+	hybridStatus, status := StopReplicationStatus(&tabletmanagerdatapb.StopReplicationAndGetStatusResponse{
+		HybridStatus: hybrid,
+		Status:       &replicationdatapb.StopReplicationStatus{Before: before, After: after},
+	})
+	assert.Equal(t, hybrid, hybridStatus)
+	utils.MustMatch(t, &replicationdatapb.StopReplicationStatus{Before: before, After: after}, status)
+
+	// The older tablets only return the hybrid status.
+	hybridStatus, status = StopReplicationStatus(&tabletmanagerdatapb.StopReplicationAndGetStatusResponse{HybridStatus: hybrid})
+	assert.Equal(t, hybrid, hybridStatus)
+	utils.MustMatch(t, &replicationdatapb.StopReplicationStatus{Before: hybrid, After: hybrid}, status)
+
+	hybridStatus, status = StopReplicationStatus(&tabletmanagerdatapb.StopReplicationAndGetStatusResponse{})
+	assert.Nil(t, hybridStatus)
+	utils.MustMatch(t, &replicationdatapb.StopReplicationStatus{}, status)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcruntimeflagsclient contains the gRPC version of the runtime
+// flags client protocol.
+package grpcruntimeflagsclient
+
+import (
+	"context"
+	"flag"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	runtimeflagsdatapb "vitess.io/vitess/go/vt/proto/runtimeflagsdata"
+	runtimeflagsservicepb "vitess.io/vitess/go/vt/proto/runtimeflagsservice"
+)
+
+var (
+	cert = flag.String("runtimeflags_client_grpc_cert", "", "the cert to use to connect")
+	key  = flag.String("runtimeflags_client_grpc_key", "", "the key to use to connect")
+	ca   = flag.String("runtimeflags_client_grpc_ca", "", "the server ca to use to validate servers when connecting")
+	crl  = flag.String("runtimeflags_client_grpc_crl", "", "the server crl to use to validate server certificates when connecting")
+	name = flag.String("runtimeflags_client_grpc_server_name", "", "the server name to use to validate server certificate")
+)
+
+// Client is a client of the RuntimeFlags service of a vtgate or vttablet.
+type Client struct {
+	conn       *grpc.ClientConn
+	gRPCClient runtimeflagsservicepb.RuntimeFlagsClient
+}
+
+// New dials the RuntimeFlags service at the given address (host:port).
+func New(addr string) (*Client, error) {
+	opt, err := grpcclient.SecureDialOption(*cert, *key, *ca, *crl, *name)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpcclient.Dial(addr, grpcclient.FailFast(false), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn, runtimeflagsservicepb.NewRuntimeFlagsClient(conn)}, nil
+}
+
+// GetRuntimeFlags returns the runtime flags of the server, with their last
+// changes.
+func (c *Client) GetRuntimeFlags(ctx context.Context) (*runtimeflagsdatapb.GetRuntimeFlagsResponse, error) {
+	response, err := c.gRPCClient.GetRuntimeFlags(ctx, &runtimeflagsdatapb.GetRuntimeFlagsRequest{})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response, nil
+}
+
+// SetRuntimeFlag changes a runtime flag of the server, and returns the change.
+func (c *Client) SetRuntimeFlag(ctx context.Context, name, value, changedBy string) (*runtimeflagsdatapb.RuntimeFlagChange, error) {
+	response, err := c.gRPCClient.SetRuntimeFlag(ctx, &runtimeflagsdatapb.SetRuntimeFlagRequest{
+		Name:      name,
+		Value:     value,
+		ChangedBy: changedBy,
+	})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response.Change, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() {
+	c.conn.Close()
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcruntimeflagsserver contains the gRPC implementation of the server
+// side of the runtime flags service.
+package grpcruntimeflagsserver
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/runtimeflags"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	runtimeflagsdatapb "vitess.io/vitess/go/vt/proto/runtimeflagsdata"
+	runtimeflagsservicepb "vitess.io/vitess/go/vt/proto/runtimeflagsservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Server is the gRPC server implementation of the RuntimeFlags service.
+type Server struct {
+	runtimeflagsservicepb.UnimplementedRuntimeFlagsServer
+}
+
+// GetRuntimeFlags implements the gRPC server interface.
+func (s *Server) GetRuntimeFlags(ctx context.Context, request *runtimeflagsdatapb.GetRuntimeFlagsRequest) (_ *runtimeflagsdatapb.GetRuntimeFlagsResponse, err error) {
+	defer servenv.HandlePanic("runtimeflags", &err)
+
+	if err := checkAccess(ctx, acl.DEBUGGING); err != nil {
+		return nil, err
+	}
+
+	resp := &runtimeflagsdatapb.GetRuntimeFlagsResponse{}
+	for _, f := range runtimeflags.Flags() {
+		resp.Flags = append(resp.Flags, &runtimeflagsdatapb.RuntimeFlag{
+			Name:         f.Name,
+			Value:        f.Value,
+			DefaultValue: f.DefaultValue,
+			Usage:        f.Usage,
+		})
+	}
+	for _, change := range runtimeflags.Changes() {
+		resp.Changes = append(resp.Changes, changeToProto(&change))
+	}
+	return resp, nil
+}
+
+// SetRuntimeFlag implements the gRPC server interface.
+func (s *Server) SetRuntimeFlag(ctx context.Context, request *runtimeflagsdatapb.SetRuntimeFlagRequest) (_ *runtimeflagsdatapb.SetRuntimeFlagResponse, err error) {
+	defer servenv.HandlePanic("runtimeflags", &err)
+
+	if err := checkAccess(ctx, acl.ADMIN); err != nil {
+		return nil, err
+	}
+
+	// The caller is the one authenticated by the server. The client it acts
+	// for, if any, is chosen by the caller, so it is only recorded next to it.
+	changedBy, ok := servenv.AuthenticatedUserFromContext(ctx)
+	if !ok {
+		changedBy = "unauthenticated caller"
+		if p, ok := peer.FromContext(ctx); ok {
+			changedBy = p.Addr.String()
+		}
+	}
+	if request.ChangedBy != "" {
+		changedBy = fmt.Sprintf("%s on behalf of %s", changedBy, request.ChangedBy)
+	}
+	change, err := runtimeflags.Set(request.Name, request.Value, changedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &runtimeflagsdatapb.SetRuntimeFlagResponse{
+		Change: changeToProto(change),
+	}, nil
+}
+
+// checkAccess checks that the caller has the role, like the
+// /debug/runtime_flags page does for its HTTP requests.
+func checkAccess(ctx context.Context, role string) error {
+	actor, _ := servenv.AuthenticatedUserFromContext(ctx)
+	if err := acl.CheckAccessActor(actor, role); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "access denied to the runtime flags: %v", err)
+	}
+	return nil
+}
+
+func changeToProto(change *runtimeflags.Change) *runtimeflagsdatapb.RuntimeFlagChange {
+	return &runtimeflagsdatapb.RuntimeFlagChange{
+		Time:      protoutil.TimeToProto(change.Time),
+		Name:      change.Name,
+		OldValue:  change.OldValue,
+		NewValue:  change.NewValue,
+		ChangedBy: change.ChangedBy,
+	}
+}
+
+// RegisterServer registers a new runtime flags server instance with the gRPC
+// server, and reports its capability.
+func RegisterServer(s *grpc.Server) {
+	runtimeflagsservicepb.RegisterRuntimeFlagsServer(s, &Server{})
+	servenv.RegisterCapability(runtimeflags.Capability)
+}
+
+func init() {
+	servenv.OnRun(func() {
+		if servenv.GRPCCheckServiceMap("runtimeflags") {
+			RegisterServer(servenv.GRPCServer)
+		}
+	})
+}
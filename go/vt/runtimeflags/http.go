@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeflags
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// This file registers the /debug/runtime_flags handler. A GET returns the
+// runtime flags and their last changes as JSON, and a POST with the name and
+// the value parameters changes a flag, e.g.
+// curl -d name=queryserver-config-pool-size -d value=32 host:port/debug/runtime_flags
+
+type response struct {
+	Flags   []Flag   `json:",omitempty"`
+	Changes []Change `json:",omitempty"`
+	Change  *Change  `json:",omitempty"`
+}
+
+func init() {
+	servenv.OnInit(func() {
+		http.HandleFunc("/debug/runtime_flags", handleHTTP)
+	})
+}
+
+func handleHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp response
+	if r.Method == http.MethodPost {
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		// The change is recorded with the user of the client certificate,
+		// like the one the gRPC service records, or else the remote address.
+		changedBy, ok := servenv.AuthenticatedUserFromHTTP(r)
+		if !ok {
+			changedBy = r.RemoteAddr
+		}
+		change, err := Set(r.FormValue("name"), r.FormValue("value"), changedBy)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp.Change = change
+	} else {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		resp.Flags = Flags()
+		resp.Changes = Changes()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtimeflags holds the whitelist of the flags of a process that can
+// be changed while it runs, e.g. the pool sizes and the timeouts of vttablet,
+// so that tuning them does not need a restart. The flags are changed through
+// the /debug/runtime_flags page or the RuntimeFlags gRPC service
+// (grpcruntimeflagsserver), and every change is logged and kept in an audit
+// log.
+package runtimeflags
+
+import (
+	"flag"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// maxChanges is the number of changes kept in the audit log.
+const maxChanges = 100
+
+// Capability is the capability of the processes which serve the RuntimeFlags
+// gRPC service.
+const Capability = "runtime-flags"
+
+// ApplyFunc applies the new value of a flag, in the canonical form of the
+// flag.Value, to the running components. It may be nil for the flags whose
+// value is read from the flag variable every time it is used.
+type ApplyFunc func(value string) error
+
+// Flag is a runtime flag, as returned by Flags.
+type Flag struct {
+	Name         string
+	Value        string
+	DefaultValue string
+	Usage        string
+}
+
+// Change is a change of a runtime flag, as recorded in the audit log.
+type Change struct {
+	Time      time.Time
+	Name      string
+	OldValue  string
+	NewValue  string
+	ChangedBy string
+}
+
+var (
+	mu sync.Mutex
+	// appliers are the whitelisted flags, with their ApplyFunc.
+	appliers = map[string]ApplyFunc{}
+	// changes is the audit log, oldest first.
+	changes []Change
+
+	changeCount = stats.NewCountersWithSingleLabel("RuntimeFlagChanges", "Number of changes of the runtime flags", "Flag")
+)
+
+func init() {
+	// The verbosity and the stderr threshold of the logs are read by glog
+	// every time it logs.
+	Register("v", nil)
+	Register("stderrthreshold", nil)
+}
+
+// Register whitelists a flag of the command line, so that it can be changed
+// while the process runs. Registering a flag again replaces its ApplyFunc, e.g.
+// when a component is recreated.
+func Register(name string, apply ApplyFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	appliers[name] = apply
+}
+
+// Flags returns the whitelisted flags which are defined in the process, sorted
+// by name.
+func Flags() []Flag {
+	mu.Lock()
+	defer mu.Unlock()
+	flags := make([]Flag, 0, len(appliers))
+	for name := range appliers {
+		f := flag.Lookup(name)
+		if f == nil {
+			continue
+		}
+		flags = append(flags, Flag{
+			Name:         f.Name,
+			Value:        f.Value.String(),
+			DefaultValue: f.DefValue,
+			Usage:        f.Usage,
+		})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// Changes returns the last changes of the runtime flags, oldest first.
+func Changes() []Change {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Change(nil), changes...)
+}
+
+// Set changes a whitelisted flag and applies its new value. If the value cannot
+// be applied, the flag keeps its old value. changedBy describes who changes the
+// flag, for the audit log.
+func Set(name, value, changedBy string) (*Change, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	apply, ok := appliers[name]
+	f := flag.Lookup(name)
+	if !ok || f == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "flag %s cannot be changed at runtime", name)
+	}
+
+	oldValue := f.Value.String()
+	// The flag.Value of some types changes even when Set fails, e.g. an int
+	// becomes 0, so the old value is restored on any error.
+	restore := func() {
+		if err := f.Value.Set(oldValue); err != nil {
+			log.Errorf("Cannot restore the value %q of flag %s: %v", oldValue, name, err)
+		}
+	}
+	if err := f.Value.Set(value); err != nil {
+		restore()
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value %q of flag %s: %v", value, name, err)
+	}
+	newValue := f.Value.String()
+	if apply != nil {
+		if err := apply(newValue); err != nil {
+			restore()
+			return nil, vterrors.Wrapf(err, "cannot apply the value %q of flag %s", newValue, name)
+		}
+	}
+
+	change := Change{
+		Time:      time.Now(),
+		Name:      name,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		ChangedBy: changedBy,
+	}
+	changes = append(changes, change)
+	if len(changes) > maxChanges {
+		changes = changes[len(changes)-maxChanges:]
+	}
+	changeCount.Add(name, 1)
+	log.Infof("Runtime flag %s changed from %q to %q by %s", name, oldValue, newValue, changedBy)
+	return &change, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeflags
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFlag = flag.Int("runtimeflags_test_flag", 10, "a flag for the tests")
+
+func TestSet(t *testing.T) {
+	var applied []string
+	Register("runtimeflags_test_flag", func(value string) error {
+		if value == "13" {
+			return errors.New("unlucky")
+		}
+		applied = append(applied, value)
+		return nil
+	})
+	defer func() {
+		*testFlag = 10
+		changes = nil
+	}()
+
+	change, err := Set("runtimeflags_test_flag", "20", "me")
+	require.NoError(t, err)
+	assert.Equal(t, 20, *testFlag)
+	assert.Equal(t, []string{"20"}, applied)
+	assert.Equal(t, "runtimeflags_test_flag", change.Name)
+	assert.Equal(t, "10", change.OldValue)
+	assert.Equal(t, "20", change.NewValue)
+	assert.Equal(t, "me", change.ChangedBy)
+
+	// The flag keeps its value if the new one is invalid or cannot be applied.
+	_, err = Set("runtimeflags_test_flag", "twenty", "me")
+	assert.ErrorContains(t, err, `invalid value "twenty" of flag runtimeflags_test_flag`)
+	_, err = Set("runtimeflags_test_flag", "13", "me")
+	assert.ErrorContains(t, err, `cannot apply the value "13" of flag runtimeflags_test_flag: unlucky`)
+	assert.Equal(t, 20, *testFlag)
+
+	// Only the whitelisted flags can be changed.
+	_, err = Set("test.v", "true", "me")
+	assert.EqualError(t, err, "flag test.v cannot be changed at runtime")
+	_, err = Set("no_such_flag", "1", "me")
+	assert.EqualError(t, err, "flag no_such_flag cannot be changed at runtime")
+
+	assert.Equal(t, []Change{*change}, Changes())
+
+	for i := 0; i < maxChanges+1; i++ {
+		_, err := Set("runtimeflags_test_flag", "30", "me")
+		require.NoError(t, err)
+	}
+	assert.Len(t, Changes(), maxChanges)
+}
+
+func TestFlags(t *testing.T) {
+	Register("runtimeflags_test_flag", nil)
+	Register("runtimeflags_undefined_flag", nil)
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(appliers, "runtimeflags_undefined_flag")
+	}()
+
+	var names []string
+	for _, f := range Flags() {
+		names = append(names, f.Name)
+		if f.Name == "runtimeflags_test_flag" {
+			assert.Equal(t, Flag{Name: f.Name, Value: "10", DefaultValue: "10", Usage: "a flag for the tests"}, f)
+		}
+	}
+	assert.Equal(t, []string{"runtimeflags_test_flag", "stderrthreshold", "v"}, names)
+}
+
+func TestHandleHTTP(t *testing.T) {
+	Register("runtimeflags_test_flag", nil)
+	defer func() {
+		*testFlag = 10
+		changes = nil
+	}()
+
+	form := url.Values{"name": {"runtimeflags_test_flag"}, "value": {"42"}}
+	req := httptest.NewRequest(http.MethodPost, "/debug/runtime_flags", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handleHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 42, *testFlag)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Change)
+	assert.Equal(t, "42", resp.Change.NewValue)
+	assert.Equal(t, req.RemoteAddr, resp.Change.ChangedBy)
+
+	// The user of a verified client certificate is recorded rather than the
+	// remote address.
+	form.Set("value", "43")
+	req = httptest.NewRequest(http.MethodPost, "/debug/runtime_flags", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "alice"}}}}}
+	w = httptest.NewRecorder()
+	handleHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Change)
+	assert.Equal(t, "alice", resp.Change.ChangedBy)
+
+	w = httptest.NewRecorder()
+	handleHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime_flags", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Flags, Flag{Name: "runtimeflags_test_flag", Value: "43", DefaultValue: "10", Usage: "a flag for the tests"})
+	require.Len(t, resp.Changes, 2)
+	assert.Equal(t, "runtimeflags_test_flag", resp.Changes[0].Name)
+}
@@ -18,6 +18,8 @@ package servenv
 
 import (
 	"context"
+	"crypto/x509"
+	"net/http"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -33,6 +35,43 @@ type Authenticator interface {
 	Authenticate(ctx context.Context, fullMethod string) (context.Context, error)
 }
 
+// authenticatedUserKey is the context key for the user authenticated by an
+// Authenticator.
+type authenticatedUserKey struct{}
+
+// NewAuthenticatedUserContext returns a copy of ctx that carries the user an
+// Authenticator authenticated the call as.
+func NewAuthenticatedUserContext(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, authenticatedUserKey{}, user)
+}
+
+// AuthenticatedUserFromContext returns the user the gRPC auth plugin of the
+// server authenticated the call as. It returns false when the server runs
+// without an auth plugin, see --grpc_auth_mode.
+func AuthenticatedUserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(authenticatedUserKey{}).(string)
+	return user, ok && user != ""
+}
+
+// AuthenticatedUserFromHTTP returns the user the verified client certificate
+// of an HTTP request authenticates, see CertificateUser. It returns false when
+// the request was not made with a verified client certificate.
+func AuthenticatedUserFromHTTP(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return CertificateUser(r.TLS.VerifiedChains[0][0]), true
+}
+
+// CertificateUser returns the user a client certificate authenticates: its
+// common name, or its whole subject if it has none.
+func CertificateUser(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
 // authPlugins is a registry of AuthPlugin initializers.
 var authPlugins = make(map[string]func() (Authenticator, error))
 
@@ -145,6 +145,11 @@ func createGRPCServer() {
 
 		// create the creds server options
 		creds := credentials.NewTLS(config)
+		if *vttls.CertificateReloadInterval > 0 {
+			creds = grpccommon.NewReloadingTLS(config, func() (*tls.Config, error) {
+				return vttls.ServerConfig(*GRPCCert, *GRPCKey, *GRPCCA, *GRPCCRL, *GRPCServerCA, tls.VersionTLS12)
+			})
+		}
 		if *GRPCEnableOptionalTLS {
 			log.Warning("Optional TLS is active. Plain-text connections will be accepted")
 			creds = grpcoptionaltls.New(creds)
@@ -214,6 +219,13 @@ func interceptors() []grpc.ServerOption {
 
 	trace.AddGrpcServerOptions(interceptors.Add)
 
+	if err := loadGRPCServerInterceptorPlugins(*GRPCServerInterceptorPlugins); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := addGRPCServerInterceptors(interceptors, *GRPCServerInterceptors); err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	return interceptors.Build()
 }
 
@@ -335,6 +347,11 @@ func (collector *serverInterceptorBuilder) AddUnary(u grpc.UnaryServerIntercepto
 	collector.unaryInterceptors = append(collector.unaryInterceptors, u)
 }
 
+// AddStream adds a single stream interceptor to the builder
+func (collector *serverInterceptorBuilder) AddStream(s grpc.StreamServerInterceptor) {
+	collector.streamInterceptors = append(collector.streamInterceptors, s)
+}
+
 // Build returns DialOptions to add to the grpc.Dial call
 func (collector *serverInterceptorBuilder) Build() []grpc.ServerOption {
 	log.Infof("Building interceptors with %d unary interceptors and %d stream interceptors", len(collector.unaryInterceptors), len(collector.streamInterceptors))
@@ -58,7 +58,7 @@ func (ma *MtlsAuthPlugin) Authenticate(ctx context.Context, fullMethod string) (
 	for _, substring := range ma.clientCertSubstrings {
 		for _, cert := range tlsInfo.State.PeerCertificates {
 			if strings.Contains(cert.Subject.String(), substring) {
-				return ctx, nil
+				return NewAuthenticatedUserContext(ctx, CertificateUser(cert)), nil
 			}
 		}
 	}
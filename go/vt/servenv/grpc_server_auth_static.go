@@ -63,7 +63,7 @@ func (sa *StaticAuthPlugin) Authenticate(ctx context.Context, fullMethod string)
 		password := md["password"][0]
 		for _, authEntry := range sa.entries {
 			if username == authEntry.Username && password == authEntry.Password {
-				return ctx, nil
+				return NewAuthenticatedUserContext(ctx, username), nil
 			}
 		}
 		return nil, status.Errorf(codes.PermissionDenied, "auth failure: caller %q provided invalid credentials", username)
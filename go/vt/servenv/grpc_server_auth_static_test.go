@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestStaticAuthPluginAuthenticatedUser(t *testing.T) {
+	plugin := &StaticAuthPlugin{entries: []StaticAuthConfigEntry{{Username: "alice", Password: "secret"}}}
+
+	_, ok := AuthenticatedUserFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", "alice", "password", "secret"))
+	ctx, err := plugin.Authenticate(ctx, "/vtctlservice.Vtctld/ExecDBA")
+	require.NoError(t, err)
+	user, ok := AuthenticatedUserFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", user)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", "alice", "password", "wrong"))
+	_, err = plugin.Authenticate(ctx, "/vtctlservice.Vtctld/ExecDBA")
+	assert.Error(t, err)
+}
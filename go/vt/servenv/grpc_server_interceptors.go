@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"flag"
+	"fmt"
+	"plugin"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// This file handles the gRPC server interceptors provided by users, e.g. for
+// authentication, quotas or request logging. An interceptor is registered by
+// name, either by a plugin_*.go file compiled into the binary, or by the init
+// function of a Go plugin loaded with --grpc_server_interceptor_plugins:
+//
+// func init() {
+//   servenv.RegisterGRPCServerInterceptor("quota", func() (servenv.GRPCServerInterceptor, error) {
+//     return servenv.GRPCServerInterceptor{Unary: quotaUnaryInterceptor}, nil
+//   })
+// }
+//
+// and applied, in the order of --grpc_server_interceptors, to all the calls of
+// the gRPC server of the binary, after the authentication plugin and tracing.
+
+var (
+	// GRPCServerInterceptors is the list of the registered interceptors to apply to the gRPC server.
+	GRPCServerInterceptors = flag.String("grpc_server_interceptors", "", "Comma-separated list of the registered gRPC server interceptors to apply, in order, to all the gRPC calls")
+
+	// GRPCServerInterceptorPlugins is the list of the Go plugins to load to register interceptors.
+	GRPCServerInterceptorPlugins = flag.String("grpc_server_interceptor_plugins", "", "Comma-separated list of the paths of Go plugins to load at startup. The plugins register gRPC server interceptors when they are loaded")
+)
+
+// GRPCServerInterceptor is a middleware for the calls of the gRPC server. Either
+// interceptor may be nil if the middleware only applies to one kind of call.
+type GRPCServerInterceptor struct {
+	Stream grpc.StreamServerInterceptor
+	Unary  grpc.UnaryServerInterceptor
+}
+
+var (
+	grpcServerInterceptorsMu sync.Mutex
+	// grpcServerInterceptorFactories is a registry of GRPCServerInterceptor initializers.
+	grpcServerInterceptorFactories = make(map[string]func() (GRPCServerInterceptor, error))
+)
+
+// RegisterGRPCServerInterceptor registers a GRPCServerInterceptor initializer
+// by name. The initializer is only called if the interceptor is listed in
+// --grpc_server_interceptors, once the flags are parsed.
+func RegisterGRPCServerInterceptor(name string, factory func() (GRPCServerInterceptor, error)) {
+	grpcServerInterceptorsMu.Lock()
+	defer grpcServerInterceptorsMu.Unlock()
+
+	if _, ok := grpcServerInterceptorFactories[name]; ok {
+		log.Fatalf("gRPC server interceptor named %v already exists", name)
+	}
+	grpcServerInterceptorFactories[name] = factory
+}
+
+// loadGRPCServerInterceptorPlugins loads the Go plugins at the given
+// comma-separated paths.
+func loadGRPCServerInterceptorPlugins(paths string) error {
+	for _, path := range splitList(paths) {
+		log.Infof("loading gRPC server interceptor plugin %v", path)
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("failed to load gRPC server interceptor plugin %v: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// addGRPCServerInterceptors adds the registered interceptors with the given
+// comma-separated names to the builder, in order.
+func addGRPCServerInterceptors(builder *serverInterceptorBuilder, names string) error {
+	for _, name := range splitList(names) {
+		grpcServerInterceptorsMu.Lock()
+		factory, ok := grpcServerInterceptorFactories[name]
+		grpcServerInterceptorsMu.Unlock()
+		if !ok {
+			return fmt.Errorf("no gRPC server interceptor named %v registered", name)
+		}
+
+		interceptor, err := factory()
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC server interceptor %v: %v", name, err)
+		}
+		log.Infof("enabling gRPC server interceptor %v", name)
+		if interceptor.Stream != nil {
+			builder.AddStream(interceptor.Stream)
+		}
+		if interceptor.Unary != nil {
+			builder.AddUnary(interceptor.Unary)
+		}
+	}
+	return nil
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
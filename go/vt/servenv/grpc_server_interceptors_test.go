@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAddGRPCServerInterceptors(t *testing.T) {
+	var calls []string
+	RegisterGRPCServerInterceptor("test_log", func() (GRPCServerInterceptor, error) {
+		return GRPCServerInterceptor{
+			Stream: func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				calls = append(calls, "log "+info.FullMethod)
+				return handler(srv, stream)
+			},
+			Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				calls = append(calls, "log "+info.FullMethod)
+				return handler(ctx, req)
+			},
+		}, nil
+	})
+	RegisterGRPCServerInterceptor("test_quota", func() (GRPCServerInterceptor, error) {
+		return GRPCServerInterceptor{
+			Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				calls = append(calls, "quota")
+				return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded")
+			},
+		}, nil
+	})
+	RegisterGRPCServerInterceptor("test_broken", func() (GRPCServerInterceptor, error) {
+		return GRPCServerInterceptor{}, errors.New("missing config")
+	})
+
+	builder := &serverInterceptorBuilder{}
+	require.NoError(t, addGRPCServerInterceptors(builder, "test_log, test_quota"))
+	assert.Len(t, builder.streamInterceptors, 1)
+	assert.Len(t, builder.unaryInterceptors, 2)
+
+	err := addGRPCServerInterceptors(&serverInterceptorBuilder{}, "test_unknown")
+	assert.EqualError(t, err, "no gRPC server interceptor named test_unknown registered")
+	err = addGRPCServerInterceptors(&serverInterceptorBuilder{}, "test_broken")
+	assert.EqualError(t, err, "failed to initialize gRPC server interceptor test_broken: missing config")
+
+	// The interceptors are applied in order to the calls of the server.
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(builder.Build()...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	defer server.Stop()
+
+	cc, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	_, err = healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"log /grpc.health.v1.Health/Check", "quota"}, calls)
+}
+
+func TestLoadGRPCServerInterceptorPlugins(t *testing.T) {
+	require.NoError(t, loadGRPCServerInterceptorPlugins(""))
+	err := loadGRPCServerInterceptorPlugins("/nonexistent/interceptor.so")
+	assert.ErrorContains(t, err, "failed to load gRPC server interceptor plugin /nonexistent/interceptor.so")
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcversionclient contains the gRPC version of the client of the
+// Version service, which all the components serve.
+package grpcversionclient
+
+import (
+	"context"
+	"flag"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	versiondatapb "vitess.io/vitess/go/vt/proto/versiondata"
+	versionservicepb "vitess.io/vitess/go/vt/proto/versionservice"
+)
+
+var (
+	cert = flag.String("version_client_grpc_cert", "", "the cert to use to connect")
+	key  = flag.String("version_client_grpc_key", "", "the key to use to connect")
+	ca   = flag.String("version_client_grpc_ca", "", "the server ca to use to validate servers when connecting")
+	crl  = flag.String("version_client_grpc_crl", "", "the server crl to use to validate server certificates when connecting")
+	name = flag.String("version_client_grpc_server_name", "", "the server name to use to validate server certificate")
+)
+
+// Client is a client of the Version service of a component.
+type Client struct {
+	conn       *grpc.ClientConn
+	gRPCClient versionservicepb.VersionClient
+}
+
+// New dials the Version service at the given address (host:port).
+func New(addr string) (*Client, error) {
+	opt, err := grpcclient.SecureDialOption(*cert, *key, *ca, *crl, *name)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpcclient.Dial(addr, grpcclient.FailFast(false), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn, versionservicepb.NewVersionClient(conn)}, nil
+}
+
+// GetVersionInfo returns the build metadata and the capabilities of the
+// server.
+func (c *Client) GetVersionInfo(ctx context.Context) (*versiondatapb.VersionInfo, error) {
+	response, err := c.gRPCClient.GetVersionInfo(ctx, &versiondatapb.GetVersionInfoRequest{})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response.VersionInfo, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() {
+	c.conn.Close()
+}
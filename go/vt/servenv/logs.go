@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+// defaultLogLines is the number of lines returned by /debug/logs without a
+// lines parameter.
+const defaultLogLines = 1000
+
+// This file registers the /debug/logs handler, which returns the last lines
+// of the current log file of a level, e.g. /debug/logs?level=ERROR&lines=100.
+// The level defaults to INFO, whose file holds the lines of all the levels.
+
+func init() {
+	OnInit(func() {
+		http.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+			if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+				acl.SendError(w, err)
+				return
+			}
+
+			level := r.FormValue("level")
+			if level == "" {
+				level = "INFO"
+			}
+			lines := defaultLogLines
+			if value := r.FormValue("lines"); value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					http.Error(w, fmt.Sprintf("invalid lines: %q", value), http.StatusBadRequest)
+					return
+				}
+				lines = n
+			}
+
+			logutil.Flush()
+			data, err := logutil.TailLog(level, lines)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				http.Error(w, "the logs are not written to a file in --log_dir", http.StatusNotFound)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write(data)
+		})
+	})
+}
@@ -233,9 +233,10 @@ func RunDefault() {
 // arguments are expected.
 func ParseFlags(cmd string) {
 	_flag.Parse()
+	component = cmd
 
 	if *Version {
-		AppVersion.Print()
+		printVersion()
 		os.Exit(0)
 	}
 
@@ -249,9 +250,10 @@ func ParseFlags(cmd string) {
 // ParseFlagsWithArgs initializes flags and returns the positional arguments
 func ParseFlagsWithArgs(cmd string) []string {
 	_flag.Parse()
+	component = cmd
 
 	if *Version {
-		AppVersion.Print()
+		printVersion()
 		os.Exit(0)
 	}
 
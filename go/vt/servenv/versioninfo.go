@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	versiondatapb "vitess.io/vitess/go/vt/proto/versiondata"
+	versionservicepb "vitess.io/vitess/go/vt/proto/versionservice"
+)
+
+var (
+	capabilitiesMu sync.Mutex
+	capabilities   = map[string]bool{}
+
+	// component is the name of the binary, as passed to ParseFlags.
+	component string
+)
+
+// RegisterCapability adds a capability to the ones reported by the Version
+// service and --version, e.g. "vdiff2". The packages implementing an optional
+// feature register its capability in their init, so that the clients of a
+// component can check whether it supports the feature rather than compare its
+// version.
+func RegisterCapability(name string) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	capabilities[name] = true
+}
+
+// Capabilities returns the registered capabilities, sorted.
+func Capabilities() []string {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	names := make([]string, 0, len(capabilities))
+	for name := range capabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VersionInfo returns the build metadata of the binary, with its capabilities.
+func VersionInfo() *versiondatapb.VersionInfo {
+	name := component
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	return &versiondatapb.VersionInfo{
+		Component:      name,
+		Version:        AppVersion.version,
+		BuildGitRev:    AppVersion.buildGitRev,
+		BuildGitBranch: AppVersion.buildGitBranch,
+		BuildTime:      AppVersion.buildTimePretty,
+		BuildHost:      AppVersion.buildHost,
+		BuildUser:      AppVersion.buildUser,
+		GoVersion:      AppVersion.goVersion,
+		Goos:           AppVersion.goOS,
+		Goarch:         AppVersion.goArch,
+		Capabilities:   Capabilities(),
+	}
+}
+
+// printVersion prints the version of the binary, and its capabilities, for
+// --version.
+func printVersion() {
+	AppVersion.Print()
+	if names := Capabilities(); len(names) > 0 {
+		fmt.Printf("Capabilities: %s\n", strings.Join(names, ", "))
+	}
+}
+
+// versionServer is the gRPC server implementation of the Version service.
+type versionServer struct {
+	versionservicepb.UnimplementedVersionServer
+}
+
+// GetVersionInfo implements the gRPC server interface.
+func (s *versionServer) GetVersionInfo(context.Context, *versiondatapb.GetVersionInfoRequest) (*versiondatapb.GetVersionInfoResponse, error) {
+	return &versiondatapb.GetVersionInfoResponse{VersionInfo: VersionInfo()}, nil
+}
+
+// RegisterVersionServer registers the Version service with the gRPC server.
+// It is registered on the gRPC server of every component when it runs.
+func RegisterVersionServer(s *grpc.Server) {
+	versionservicepb.RegisterVersionServer(s, &versionServer{})
+}
+
+func init() {
+	OnRun(func() {
+		if GRPCServer != nil {
+			RegisterVersionServer(GRPCServer)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionInfo(t *testing.T) {
+	RegisterCapability("test-z")
+	RegisterCapability("test-a")
+	RegisterCapability("test-z")
+
+	info := VersionInfo()
+	assert.Equal(t, AppVersion.version, info.Version)
+	assert.Equal(t, AppVersion.goVersion, info.GoVersion)
+	assert.NotEmpty(t, info.Component)
+	assert.Subset(t, info.Capabilities, []string{"test-a", "test-z"})
+	assert.IsIncreasing(t, info.Capabilities)
+}
@@ -326,6 +326,7 @@ type (
 		Columns    Columns
 		Rows       InsertRows
 		OnDup      OnDup
+		Returning  SelectExprs
 	}
 
 	// Ignore represents whether ignore was specified or not
@@ -345,6 +346,7 @@ type (
 		Where      *Where
 		OrderBy    OrderBy
 		Limit      *Limit
+		Returning  SelectExprs
 	}
 
 	// Delete represents a DELETE statement.
@@ -359,6 +361,7 @@ type (
 		Where      *Where
 		OrderBy    OrderBy
 		Limit      *Limit
+		Returning  SelectExprs
 	}
 
 	// Set represents a SET statement.
@@ -461,6 +464,12 @@ type (
 
 		// AutoIncSpec is set for AddAutoIncDDLAction.
 		AutoIncSpec *AutoIncSpec
+
+		// IfNotExists, SequenceStart and SequenceCache are set for CreateSequenceDDLAction.
+		// A zero SequenceStart or SequenceCache means the value was not specified.
+		IfNotExists   bool
+		SequenceStart int
+		SequenceCache int
 	}
 
 	// ShowMigrationLogs represents a SHOW VITESS_MIGRATION '<uuid>' LOGS statement
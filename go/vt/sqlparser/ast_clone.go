@@ -1071,6 +1071,7 @@ func CloneRefOfDelete(n *Delete) *Delete {
 	out.Where = CloneRefOfWhere(n.Where)
 	out.OrderBy = CloneOrderBy(n.OrderBy)
 	out.Limit = CloneRefOfLimit(n.Limit)
+	out.Returning = CloneSelectExprs(n.Returning)
 	return &out
 }
 
@@ -1402,6 +1403,7 @@ func CloneRefOfInsert(n *Insert) *Insert {
 	out.Columns = CloneColumns(n.Columns)
 	out.Rows = CloneInsertRows(n.Rows)
 	out.OnDup = CloneOnDup(n.OnDup)
+	out.Returning = CloneSelectExprs(n.Returning)
 	return &out
 }
 
@@ -2801,6 +2803,7 @@ func CloneRefOfUpdate(n *Update) *Update {
 	out.Where = CloneRefOfWhere(n.Where)
 	out.OrderBy = CloneOrderBy(n.OrderBy)
 	out.Limit = CloneRefOfLimit(n.Limit)
+	out.Returning = CloneSelectExprs(n.Returning)
 	return &out
 }
 
@@ -1664,7 +1664,10 @@ func EqualsRefOfAlterVschema(a, b *AlterVschema) bool {
 		EqualsTableName(a.Table, b.Table) &&
 		EqualsRefOfVindexSpec(a.VindexSpec, b.VindexSpec) &&
 		EqualsSliceOfIdentifierCI(a.VindexCols, b.VindexCols) &&
-		EqualsRefOfAutoIncSpec(a.AutoIncSpec, b.AutoIncSpec)
+		EqualsRefOfAutoIncSpec(a.AutoIncSpec, b.AutoIncSpec) &&
+		a.IfNotExists == b.IfNotExists &&
+		a.SequenceStart == b.SequenceStart &&
+		a.SequenceCache == b.SequenceCache
 }
 
 // EqualsRefOfAndExpr does deep equals between the two objects.
@@ -2143,7 +2146,8 @@ func EqualsRefOfDelete(a, b *Delete) bool {
 		EqualsPartitions(a.Partitions, b.Partitions) &&
 		EqualsRefOfWhere(a.Where, b.Where) &&
 		EqualsOrderBy(a.OrderBy, b.OrderBy) &&
-		EqualsRefOfLimit(a.Limit, b.Limit)
+		EqualsRefOfLimit(a.Limit, b.Limit) &&
+		EqualsSelectExprs(a.Returning, b.Returning)
 }
 
 // EqualsRefOfDerivedTable does deep equals between the two objects.
@@ -2536,7 +2540,8 @@ func EqualsRefOfInsert(a, b *Insert) bool {
 		EqualsPartitions(a.Partitions, b.Partitions) &&
 		EqualsColumns(a.Columns, b.Columns) &&
 		EqualsInsertRows(a.Rows, b.Rows) &&
-		EqualsOnDup(a.OnDup, b.OnDup)
+		EqualsOnDup(a.OnDup, b.OnDup) &&
+		EqualsSelectExprs(a.Returning, b.Returning)
 }
 
 // EqualsRefOfInsertExpr does deep equals between the two objects.
@@ -4139,7 +4144,8 @@ func EqualsRefOfUpdate(a, b *Update) bool {
 		EqualsUpdateExprs(a.Exprs, b.Exprs) &&
 		EqualsRefOfWhere(a.Where, b.Where) &&
 		EqualsOrderBy(a.OrderBy, b.OrderBy) &&
-		EqualsRefOfLimit(a.Limit, b.Limit)
+		EqualsRefOfLimit(a.Limit, b.Limit) &&
+		EqualsSelectExprs(a.Returning, b.Returning)
 }
 
 // EqualsRefOfUpdateExpr does deep equals between the two objects.
@@ -121,7 +121,9 @@ func (node *Insert) Format(buf *TrackedBuffer) {
 			node.Comments, node.Ignore.ToString(),
 			node.Table, node.Partitions, node.Columns, node.Rows, node.OnDup)
 	}
-
+	if node.Returning != nil {
+		buf.astPrintf(node, " returning %v", node.Returning)
+	}
 }
 
 // Format formats the node.
@@ -151,6 +153,9 @@ func (node *Update) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "update %v%s%v set %v%v%v%v",
 		node.Comments, node.Ignore.ToString(), node.TableExprs,
 		node.Exprs, node.Where, node.OrderBy, node.Limit)
+	if node.Returning != nil {
+		buf.astPrintf(node, " returning %v", node.Returning)
+	}
 }
 
 // Format formats the node.
@@ -166,6 +171,9 @@ func (node *Delete) Format(buf *TrackedBuffer) {
 		buf.astPrintf(node, "%v ", node.Targets)
 	}
 	buf.astPrintf(node, "from %v%v%v%v%v", node.TableExprs, node.Partitions, node.Where, node.OrderBy, node.Limit)
+	if node.Returning != nil {
+		buf.astPrintf(node, " returning %v", node.Returning)
+	}
 }
 
 // Format formats the node.
@@ -254,6 +262,18 @@ func (node *AlterVschema) Format(buf *TrackedBuffer) {
 		buf.astPrintf(node, "alter vschema add sequence %v", node.Table)
 	case AddAutoIncDDLAction:
 		buf.astPrintf(node, "alter vschema on %v add auto_increment %v", node.Table, node.AutoIncSpec)
+	case CreateSequenceDDLAction:
+		notExists := ""
+		if node.IfNotExists {
+			notExists = " if not exists"
+		}
+		buf.astPrintf(node, "create sequence%s %v", notExists, node.Table)
+		if node.SequenceStart != 0 {
+			buf.astPrintf(node, " start with %d", node.SequenceStart)
+		}
+		if node.SequenceCache != 0 {
+			buf.astPrintf(node, " cache %d", node.SequenceCache)
+		}
 	default:
 		buf.astPrintf(node, "%s table %v", node.Action.ToString(), node.Table)
 	}
@@ -975,6 +995,10 @@ func (node *Release) Format(buf *TrackedBuffer) {
 
 // Format formats the node.
 func (node *ExplainStmt) Format(buf *TrackedBuffer) {
+	if node.Type == VExplainAnalyzeType {
+		buf.astPrintf(node, "%s %v", VExplainAnalyzeStr, node.Statement)
+		return
+	}
 	format := ""
 	switch node.Type {
 	case EmptyType:
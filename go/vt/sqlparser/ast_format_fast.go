@@ -189,7 +189,10 @@ func (node *Insert) formatFast(buf *TrackedBuffer) {
 		node.OnDup.formatFast(buf)
 
 	}
-
+	if node.Returning != nil {
+		buf.WriteString(" returning ")
+		node.Returning.formatFast(buf)
+	}
 }
 
 // formatFast formats the node.
@@ -235,6 +238,10 @@ func (node *Update) formatFast(buf *TrackedBuffer) {
 
 	node.Limit.formatFast(buf)
 
+	if node.Returning != nil {
+		buf.WriteString(" returning ")
+		node.Returning.formatFast(buf)
+	}
 }
 
 // formatFast formats the node.
@@ -257,6 +264,10 @@ func (node *Delete) formatFast(buf *TrackedBuffer) {
 	node.Where.formatFast(buf)
 	node.OrderBy.formatFast(buf)
 	node.Limit.formatFast(buf)
+	if node.Returning != nil {
+		buf.WriteString(" returning ")
+		node.Returning.formatFast(buf)
+	}
 }
 
 // formatFast formats the node.
@@ -378,6 +389,23 @@ func (node *AlterVschema) formatFast(buf *TrackedBuffer) {
 		node.Table.formatFast(buf)
 		buf.WriteString(" add auto_increment ")
 		node.AutoIncSpec.formatFast(buf)
+	case CreateSequenceDDLAction:
+		notExists := ""
+		if node.IfNotExists {
+			notExists = " if not exists"
+		}
+		buf.WriteString("create sequence")
+		buf.WriteString(notExists)
+		buf.WriteByte(' ')
+		node.Table.formatFast(buf)
+		if node.SequenceStart != 0 {
+			buf.WriteString(" start with ")
+			buf.WriteString(fmt.Sprintf("%d", node.SequenceStart))
+		}
+		if node.SequenceCache != 0 {
+			buf.WriteString(" cache ")
+			buf.WriteString(fmt.Sprintf("%d", node.SequenceCache))
+		}
 	default:
 		buf.WriteString(node.Action.ToString())
 		buf.WriteString(" table ")
@@ -1298,6 +1326,12 @@ func (node *Release) formatFast(buf *TrackedBuffer) {
 
 // formatFast formats the node.
 func (node *ExplainStmt) formatFast(buf *TrackedBuffer) {
+	if node.Type == VExplainAnalyzeType {
+		buf.WriteString(VExplainAnalyzeStr)
+		buf.WriteByte(' ')
+		node.Statement.formatFast(buf)
+		return
+	}
 	format := ""
 	switch node.Type {
 	case EmptyType:
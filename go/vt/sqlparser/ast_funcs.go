@@ -1085,6 +1085,8 @@ func (action DDLAction) ToString() string {
 		return AddSequenceStr
 	case AddAutoIncDDLAction:
 		return AddAutoIncStr
+	case CreateSequenceDDLAction:
+		return CreateSequenceStr
 	default:
 		return "Unknown DDL Action"
 	}
@@ -1593,6 +1595,8 @@ func (ty ExplainType) ToString() string {
 		return TraditionalStr
 	case AnalyzeType:
 		return AnalyzeStr
+	case VExplainAnalyzeType:
+		return VExplainAnalyzeStr
 	default:
 		return "Unknown ExplainType"
 	}
@@ -2159,6 +2159,11 @@ func (a *application) rewriteRefOfDelete(parent SQLNode, node *Delete, replacer
 	}) {
 		return false
 	}
+	if !a.rewriteSelectExprs(node, node.Returning, func(newNode, parent SQLNode) {
+		parent.(*Delete).Returning = newNode.(SelectExprs)
+	}) {
+		return false
+	}
 	if a.post != nil {
 		a.cur.replacer = replacer
 		a.cur.parent = parent
@@ -3136,6 +3141,11 @@ func (a *application) rewriteRefOfInsert(parent SQLNode, node *Insert, replacer
 	}) {
 		return false
 	}
+	if !a.rewriteSelectExprs(node, node.Returning, func(newNode, parent SQLNode) {
+		parent.(*Insert).Returning = newNode.(SelectExprs)
+	}) {
+		return false
+	}
 	if a.post != nil {
 		a.cur.replacer = replacer
 		a.cur.parent = parent
@@ -7302,6 +7312,11 @@ func (a *application) rewriteRefOfUpdate(parent SQLNode, node *Update, replacer
 	}) {
 		return false
 	}
+	if !a.rewriteSelectExprs(node, node.Returning, func(newNode, parent SQLNode) {
+		parent.(*Update).Returning = newNode.(SelectExprs)
+	}) {
+		return false
+	}
 	if a.post != nil {
 		a.cur.replacer = replacer
 		a.cur.parent = parent
@@ -460,6 +460,9 @@ func (er *astRewriter) sysVarRewrite(cursor *Cursor, node *Variable) {
 		sysvars.ClientFoundRows.Name,
 		sysvars.DDLStrategy.Name,
 		sysvars.Names.Name,
+		sysvars.Priority.Name,
+		sysvars.QueryMemoryLimit.Name,
+		sysvars.QueryRetry.Name,
 		sysvars.TransactionMode.Name,
 		sysvars.ReadAfterWriteGTID.Name,
 		sysvars.ReadAfterWriteTimeOut.Name,
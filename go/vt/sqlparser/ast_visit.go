@@ -1276,6 +1276,9 @@ func VisitRefOfDelete(in *Delete, f Visit) error {
 	if err := VisitRefOfLimit(in.Limit, f); err != nil {
 		return err
 	}
+	if err := VisitSelectExprs(in.Returning, f); err != nil {
+		return err
+	}
 	return nil
 }
 func VisitRefOfDerivedTable(in *DerivedTable, f Visit) error {
@@ -1712,6 +1715,9 @@ func VisitRefOfInsert(in *Insert, f Visit) error {
 	if err := VisitOnDup(in.OnDup, f); err != nil {
 		return err
 	}
+	if err := VisitSelectExprs(in.Returning, f); err != nil {
+		return err
+	}
 	return nil
 }
 func VisitRefOfInsertExpr(in *InsertExpr, f Visit) error {
@@ -3595,6 +3601,9 @@ func VisitRefOfUpdate(in *Update, f Visit) error {
 	if err := VisitRefOfLimit(in.Limit, f); err != nil {
 		return err
 	}
+	if err := VisitSelectExprs(in.Returning, f); err != nil {
+		return err
+	}
 	return nil
 }
 func VisitRefOfUpdateExpr(in *UpdateExpr, f Visit) error {
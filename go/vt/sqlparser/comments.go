@@ -20,6 +20,11 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 const (
@@ -28,7 +33,7 @@ const (
 	DirectiveMultiShardAutocommit = "MULTI_SHARD_AUTOCOMMIT"
 	// DirectiveSkipQueryPlanCache skips query plan cache when set.
 	DirectiveSkipQueryPlanCache = "SKIP_QUERY_PLAN_CACHE"
-	// DirectiveQueryTimeout sets a query timeout in vtgate. Only supported for SELECTS.
+	// DirectiveQueryTimeout sets a query timeout in vtgate and vttablet, in milliseconds.
 	DirectiveQueryTimeout = "QUERY_TIMEOUT_MS"
 	// DirectiveScatterErrorsAsWarnings enables partial success scatter select queries
 	DirectiveScatterErrorsAsWarnings = "SCATTER_ERRORS_AS_WARNINGS"
@@ -42,6 +47,27 @@ const (
 	DirectiveAllowHashJoin = "ALLOW_HASH_JOIN"
 	// DirectiveQueryPlanner lets the user specify per query which planner should be used
 	DirectiveQueryPlanner = "PLANNER"
+	// DirectiveNoScatter makes the query fail instead of executing a plan that includes scatter queries.
+	DirectiveNoScatter = "NO_SCATTER"
+	// DirectiveWorkload sets the workload (OLTP, OLAP or DBA) of the query on vttablet.
+	DirectiveWorkload = "WORKLOAD"
+	// DirectivePriority sets the priority of the query on vttablet, between 0 (highest) and 100 (lowest).
+	DirectivePriority = "PRIORITY"
+	// DirectiveDMLBatchSize makes vtgate execute an UPDATE or a DELETE in batches of this many rows, each committed on its own.
+	DirectiveDMLBatchSize = "DML_BATCH_SIZE"
+	// DirectiveDMLBatchColumn sets the column whose values the batches of DML_BATCH_SIZE are ranges of.
+	DirectiveDMLBatchColumn = "DML_BATCH_COLUMN"
+	// DirectiveTenant labels the query with a tenant for the usage accounting of vtgate.
+	DirectiveTenant = "TENANT"
+)
+
+const (
+	// MinPriority is the highest priority of a query.
+	MinPriority = 0
+	// MaxPriority is the lowest priority of a query.
+	MaxPriority = 100
+	// DefaultPriority is the priority of a query that was not given one.
+	DefaultPriority = 50
 )
 
 func isNonSpace(r rune) bool {
@@ -363,6 +389,76 @@ func AllowScatterDirective(stmt Statement) bool {
 	return comments != nil && comments.Directives().IsSet(DirectiveAllowScatter)
 }
 
+// TenantDirective returns the tenant label of the query, or "" if it is not set.
+func TenantDirective(stmt Statement) string {
+	commented, ok := stmt.(Commented)
+	if !ok {
+		return ""
+	}
+	return commented.GetParsedComments().Directives().GetString(DirectiveTenant, "")
+}
+
+// QueryHints are the execution hints set by the comment directives of a
+// statement. They are parsed once, when the statement is planned by vtgate,
+// and sent to vttablet in the ExecuteOptions of the statement. A hint takes
+// precedence over the session variables and the flags of vtgate and vttablet
+// for the statement it is set on.
+type QueryHints struct {
+	// QueryTimeoutMs is the timeout of the statement, 0 if not set.
+	QueryTimeoutMs int64
+	// Workload overrides the workload of the session on vttablet.
+	Workload querypb.ExecuteOptions_Workload
+	// Priority is the priority of the statement, empty if not set.
+	Priority string
+	// NoScatter disallows plans that include scatter queries.
+	NoScatter bool
+}
+
+// GetQueryHints returns the hints set by the comment directives of the
+// statement, or nil if it has none.
+func GetQueryHints(stmt Statement) (*QueryHints, error) {
+	commented, ok := stmt.(Commented)
+	if !ok {
+		return nil, nil
+	}
+	directives := commented.GetParsedComments().Directives()
+	if len(directives) == 0 {
+		return nil, nil
+	}
+
+	hints := &QueryHints{}
+	if val, ok := directives[DirectiveQueryTimeout]; ok {
+		timeout, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || timeout < 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for %s: %s", DirectiveQueryTimeout, val)
+		}
+		hints.QueryTimeoutMs = timeout
+	}
+	if val, ok := directives[DirectiveWorkload]; ok {
+		workload, ok := querypb.ExecuteOptions_Workload_value[strings.ToUpper(directives.GetString(DirectiveWorkload, ""))]
+		if !ok || workload == int32(querypb.ExecuteOptions_UNSPECIFIED) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for %s: %s, expected OLTP, OLAP or DBA", DirectiveWorkload, val)
+		}
+		hints.Workload = querypb.ExecuteOptions_Workload(workload)
+	}
+	if val, ok := directives[DirectivePriority]; ok {
+		priority, err := strconv.Atoi(val)
+		if err != nil || priority < MinPriority || priority > MaxPriority {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for %s: %s, expected an integer between %d and %d", DirectivePriority, val, MinPriority, MaxPriority)
+		}
+		hints.Priority = strconv.Itoa(priority)
+	}
+	hints.NoScatter = directives.IsSet(DirectiveNoScatter)
+	if hints.NoScatter && directives.IsSet(DirectiveAllowScatter) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s and %s cannot be set on the same query", DirectiveNoScatter, DirectiveAllowScatter)
+	}
+
+	if *hints == (QueryHints{}) {
+		return nil, nil
+	}
+	return hints, nil
+}
+
 func CommentsForStatement(stmt Statement) Comments {
 	if commented, ok := stmt.(Commented); ok {
 		return commented.GetParsedComments().comments
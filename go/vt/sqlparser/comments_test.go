@@ -22,6 +22,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
 func TestSplitComments(t *testing.T) {
@@ -479,3 +482,71 @@ func TestIgnoreMaxMaxMemoryRowsDirective(t *testing.T) {
 		})
 	}
 }
+
+func TestTenantDirective(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"select /*vt+ TENANT=acme */ * from users", "acme"},
+		{"insert /*vt+ TENANT=acme */ into users(id) values (1)", "acme"},
+		{"update /*vt+ TENANT=42 */ users set name = 1", "42"},
+		{"delete from users", ""},
+		{"show create table users", ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := Parse(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, TenantDirective(stmt))
+		})
+	}
+}
+
+func TestGetQueryHints(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected *QueryHints
+		err      string
+	}{{
+		query: "select * from users",
+	}, {
+		query: "select /*vt+ SKIP_QUERY_PLAN_CACHE=1 */ * from users",
+	}, {
+		query:    "select /*vt+ QUERY_TIMEOUT_MS=100 WORKLOAD=olap PRIORITY=10 NO_SCATTER */ * from users",
+		expected: &QueryHints{QueryTimeoutMs: 100, Workload: querypb.ExecuteOptions_OLAP, Priority: "10", NoScatter: true},
+	}, {
+		query:    "update /*vt+ WORKLOAD=dba */ users set name = 1",
+		expected: &QueryHints{Workload: querypb.ExecuteOptions_DBA},
+	}, {
+		query:    "delete /*vt+ PRIORITY=0 */ from users",
+		expected: &QueryHints{Priority: "0"},
+	}, {
+		query: "select /*vt+ QUERY_TIMEOUT_MS=abc */ * from users",
+		err:   "invalid value for QUERY_TIMEOUT_MS: abc",
+	}, {
+		query: "select /*vt+ WORKLOAD=batch */ * from users",
+		err:   "invalid value for WORKLOAD: batch, expected OLTP, OLAP or DBA",
+	}, {
+		query: "select /*vt+ PRIORITY=101 */ * from users",
+		err:   "invalid value for PRIORITY: 101, expected an integer between 0 and 100",
+	}, {
+		query: "select /*vt+ NO_SCATTER ALLOW_SCATTER */ * from users",
+		err:   "NO_SCATTER and ALLOW_SCATTER cannot be set on the same query",
+	}}
+
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := Parse(test.query)
+			require.NoError(t, err)
+			got, err := GetQueryHints(stmt)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
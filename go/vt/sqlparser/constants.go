@@ -64,6 +64,7 @@ const (
 	DropColVindexStr    = "on table drop vindex"
 	AddSequenceStr      = "add sequence"
 	AddAutoIncStr       = "add auto_increment"
+	CreateSequenceStr   = "create sequence"
 
 	// Partition and subpartition type strings
 	HashTypeStr  = "hash"
@@ -242,12 +243,13 @@ const (
 	TxReadWrite = "read write"
 
 	// Explain formats
-	EmptyStr       = ""
-	TreeStr        = "tree"
-	JSONStr        = "json"
-	VitessStr      = "vitess"
-	TraditionalStr = "traditional"
-	AnalyzeStr     = "analyze"
+	EmptyStr           = ""
+	TreeStr            = "tree"
+	JSONStr            = "json"
+	VitessStr          = "vitess"
+	TraditionalStr     = "traditional"
+	AnalyzeStr         = "analyze"
+	VExplainAnalyzeStr = "vexplain analyze"
 
 	// Lock Types
 	ReadStr             = "read"
@@ -442,6 +444,7 @@ const (
 	AddSequenceDDLAction
 	AddAutoIncDDLAction
 	RevertDDLAction
+	CreateSequenceDDLAction
 )
 
 // Constants for scope of variables
@@ -708,6 +711,7 @@ const (
 	VitessType
 	TraditionalType
 	AnalyzeType
+	VExplainAnalyzeType
 )
 
 // Constant for Enum Type - SelectIntoType
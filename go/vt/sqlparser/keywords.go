@@ -149,6 +149,7 @@ var keywords = []keyword{
 	{"both", BOTH},
 	{"by", BY},
 	{"byte", BYTE},
+	{"cache", CACHE},
 	{"call", CALL},
 	{"cancel", CANCEL},
 	{"cascade", CASCADE},
@@ -642,6 +643,7 @@ var keywords = []keyword{
 	{"varcharacter", UNUSED},
 	{"variance", VARIANCE},
 	{"varying", UNUSED},
+	{"vexplain", VEXPLAIN},
 	{"vgtid_executed", VGTID_EXECUTED},
 	{"virtual", VIRTUAL},
 	{"vindex", VINDEX},
@@ -949,6 +949,15 @@ var (
 		output: "insert into `user`(`format`, `tree`, `vitess`) values ('Chuck', 42, 'Barry')",
 	}, {
 		input: "insert into customer() values ()",
+	}, {
+		input: "insert /* returning */ into a(b, c) values (1, 2), (3, 4) returning id, b + 1 as x",
+	}, {
+		input:  "insert /* returning on set */ into a set b = 1 returning *",
+		output: "insert /* returning on set */ into a(b) values (1) returning *",
+	}, {
+		input: "insert /* returning on duplicate */ into a values (1, 2) on duplicate key update b = 2 returning a.*",
+	}, {
+		input: "insert /* returning on select */ into a select b from c returning id",
 	}, {
 		input: "update /* simple */ a set b = 3",
 	}, {
@@ -963,6 +972,10 @@ var (
 		input: "update /* order */ a set b = 3 order by c desc",
 	}, {
 		input: "update /* limit */ a set b = 3 limit c",
+	}, {
+		input: "update /* returning */ a set b = 3 where c = 4 order by d asc limit 1 returning b, c",
+	}, {
+		input: "update /* returning */ a set b = 3 returning b",
 	}, {
 		input: "update /* bool in update */ a set b = true",
 	}, {
@@ -997,6 +1010,15 @@ var (
 		input: "delete /* order */ from a order by b desc",
 	}, {
 		input: "delete /* limit */ from a limit b",
+	}, {
+		input: "delete /* returning */ from a where b = 1 returning *",
+	}, {
+		input: "delete /* returning */ from a returning b",
+	}, {
+		input: "delete /* returning with alias */ from a as `returning` returning `returning`.b",
+	}, {
+		input:  "select b as returning from a",
+		output: "select b as `returning` from a",
 	}, {
 		input:  "delete /* alias where */ t.* from a as t where t.id = 2",
 		output: "delete /* alias where */ t from a as t where t.id = 2",
@@ -1529,6 +1551,19 @@ var (
 		// Alter Vschema does not reach the vttablets, so we don't need to run the normalizer test
 		input:                "alter vschema on a add auto_increment id using a_seq",
 		ignoreNormalizerTest: true,
+	}, {
+		input:                "create sequence a_seq",
+		ignoreNormalizerTest: true,
+	}, {
+		input:                "create sequence if not exists ks.a_seq start with 1000 cache 100",
+		ignoreNormalizerTest: true,
+	}, {
+		input:                "create /* comment */ sequence a_seq start 5",
+		output:               "create sequence a_seq start with 5",
+		ignoreNormalizerTest: true,
+	}, {
+		input:                "create sequence a_seq cache 10",
+		ignoreNormalizerTest: true,
 	}, {
 		// Alter Vschema does not reach the vttablets, so we don't need to run the normalizer test
 		input:                "alter vschema on ks.a add auto_increment id using a_seq",
@@ -2071,6 +2106,16 @@ var (
 		input: "explain format = traditional select * from t",
 	}, {
 		input: "explain analyze select * from t",
+	}, {
+		input: "vexplain analyze select * from t",
+	}, {
+		input:  "VEXPLAIN ANALYZE select * from t where id = 1",
+		output: "vexplain analyze select * from t where id = 1",
+	}, {
+		input: "vexplain analyze delete from t where id = 1",
+	}, {
+		input:  "select vexplain from t",
+		output: "select `vexplain` from t",
 	}, {
 		input: "explain format = tree select * from t",
 	}, {
@@ -212,6 +212,11 @@ func bindVariable(yylex yyLexer, bvar string) {
 }
 
 // These precedence rules are there to handle shift-reduce conflicts.
+// RETURNING is a non-reserved keyword that can follow a table name or a select expression of a DML,
+// where it could also be read as their alias. Reducing an empty alias has a higher precedence
+// than shifting RETURNING, so that it starts the RETURNING clause. An alias named returning needs AS.
+%nonassoc <str> RETURNING
+%nonassoc <str> EMPTY_ALIAS
 %nonassoc <str> MEMBER
 // FUNCTION_CALL_NON_KEYWORD is used to resolve shift-reduce conflicts occuring due to function_call_generic symbol and
 // having special parsing for functions whose names are non-reserved keywords. The shift-reduce conflict occurrs because
@@ -297,7 +302,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %token <str> MAXVALUE PARTITION REORGANIZE LESS THAN PROCEDURE TRIGGER
 %token <str> VINDEX VINDEXES DIRECTORY NAME UPGRADE
 %token <str> STATUS VARIABLES WARNINGS CASCADED DEFINER OPTION SQL UNDEFINED
-%token <str> SEQUENCE MERGE TEMPORARY TEMPTABLE INVOKER SECURITY FIRST AFTER LAST
+%token <str> SEQUENCE CACHE MERGE TEMPORARY TEMPTABLE INVOKER SECURITY FIRST AFTER LAST
 
 // Migration tokens
 %token <str> VITESS_MIGRATION CANCEL RETRY COMPLETE CLEANUP THROTTLE UNTHROTTLE EXPIRE RATIO
@@ -356,13 +361,13 @@ func bindVariable(yylex yyLexer, bvar string) {
 %token <str> INACTIVE INVISIBLE LOCKED MASTER_COMPRESSION_ALGORITHMS MASTER_PUBLIC_KEY_PATH MASTER_TLS_CIPHERSUITES MASTER_ZSTD_COMPRESSION_LEVEL
 %token <str> NESTED NETWORK_NAMESPACE NOWAIT NULLS OJ OLD OPTIONAL ORDINALITY ORGANIZATION OTHERS PARTIAL PATH PERSIST PERSIST_ONLY PRECEDING PRIVILEGE_CHECKS_USER PROCESS
 %token <str> RANDOM REFERENCE REQUIRE_ROW_FORMAT RESOURCE RESPECT RESTART RETAIN REUSE ROLE SECONDARY SECONDARY_ENGINE SECONDARY_ENGINE_ATTRIBUTE SECONDARY_LOAD SECONDARY_UNLOAD SIMPLE SKIP SRID
-%token <str> THREAD_PRIORITY TIES UNBOUNDED VCPU VISIBLE RETURNING
+%token <str> THREAD_PRIORITY TIES UNBOUNDED VCPU VISIBLE
 
 // Performance Schema Functions
 %token <str> FORMAT_BYTES FORMAT_PICO_TIME PS_CURRENT_THREAD_ID PS_THREAD_ID
 
 // Explain tokens
-%token <str> FORMAT TREE VITESS TRADITIONAL
+%token <str> FORMAT TREE VITESS TRADITIONAL VEXPLAIN
 
 // Lock type tokens
 %token <str> LOCAL LOW_PRIORITY
@@ -381,7 +386,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %token <str> PARTITIONS LINEAR RANGE LIST SUBPARTITION SUBPARTITIONS HASH
 
 %type <partitionByType> range_or_list
-%type <integer> partitions_opt algorithm_opt subpartitions_opt partition_max_rows partition_min_rows
+%type <integer> sequence_start_opt sequence_cache_opt partitions_opt algorithm_opt subpartitions_opt partition_max_rows partition_min_rows
 %type <statement> command
 %type <selStmt> query_expression_parens query_expression query_expression_body select_statement query_primary select_stmt_with_into
 %type <statement> explain_statement explainable_statement
@@ -439,7 +444,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %type <str> cache_opt separator_opt flush_option for_channel_opt maxvalue
 %type <matchExprOption> match_option
 %type <boolean> distinct_opt union_op replace_opt local_opt
-%type <selectExprs> select_expression_list select_expression_list_opt
+%type <selectExprs> select_expression_list select_expression_list_opt returning_opt
 %type <selectExpr> select_expression
 %type <strs> select_options flush_option_list
 %type <str> select_option algorithm_view security_view security_view_opt
@@ -876,7 +881,7 @@ query_primary:
   }
 
 insert_statement:
-  insert_or_replace comment_opt ignore_opt into_table_name opt_partition_clause insert_data on_dup_opt
+  insert_or_replace comment_opt ignore_opt into_table_name opt_partition_clause insert_data on_dup_opt returning_opt
   {
     // insert_data returns a *Insert pre-filled with Columns & Values
     ins := $6
@@ -886,9 +891,10 @@ insert_statement:
     ins.Table = $4
     ins.Partitions = $5
     ins.OnDup = OnDup($7)
+    ins.Returning = $8
     $$ = ins
   }
-| insert_or_replace comment_opt ignore_opt into_table_name opt_partition_clause SET update_list on_dup_opt
+| insert_or_replace comment_opt ignore_opt into_table_name opt_partition_clause SET update_list on_dup_opt returning_opt
   {
     cols := make(Columns, 0, len($7))
     vals := make(ValTuple, 0, len($8))
@@ -896,7 +902,7 @@ insert_statement:
       cols = append(cols, updateList.Name.Name)
       vals = append(vals, updateList.Expr)
     }
-    $$ = &Insert{Action: $1, Comments: Comments($2).Parsed(), Ignore: $3, Table: $4, Partitions: $5, Columns: cols, Rows: Values{vals}, OnDup: OnDup($8)}
+    $$ = &Insert{Action: $1, Comments: Comments($2).Parsed(), Ignore: $3, Table: $4, Partitions: $5, Columns: cols, Rows: Values{vals}, OnDup: OnDup($8), Returning: $9}
   }
 
 insert_or_replace:
@@ -910,15 +916,15 @@ insert_or_replace:
   }
 
 update_statement:
-  with_clause_opt UPDATE comment_opt ignore_opt table_references SET update_list where_expression_opt order_by_opt limit_opt
+  with_clause_opt UPDATE comment_opt ignore_opt table_references SET update_list where_expression_opt order_by_opt limit_opt returning_opt
   {
-    $$ = &Update{With: $1, Comments: Comments($3).Parsed(), Ignore: $4, TableExprs: $5, Exprs: $7, Where: NewWhere(WhereClause, $8), OrderBy: $9, Limit: $10}
+    $$ = &Update{With: $1, Comments: Comments($3).Parsed(), Ignore: $4, TableExprs: $5, Exprs: $7, Where: NewWhere(WhereClause, $8), OrderBy: $9, Limit: $10, Returning: $11}
   }
 
 delete_statement:
-  with_clause_opt DELETE comment_opt ignore_opt FROM table_name as_opt_id opt_partition_clause where_expression_opt order_by_opt limit_opt
+  with_clause_opt DELETE comment_opt ignore_opt FROM table_name as_opt_id opt_partition_clause where_expression_opt order_by_opt limit_opt returning_opt
   {
-    $$ = &Delete{With: $1, Comments: Comments($3).Parsed(), Ignore: $4, TableExprs: TableExprs{&AliasedTableExpr{Expr:$6, As: $7}}, Partitions: $8, Where: NewWhere(WhereClause, $9), OrderBy: $10, Limit: $11}
+    $$ = &Delete{With: $1, Comments: Comments($3).Parsed(), Ignore: $4, TableExprs: TableExprs{&AliasedTableExpr{Expr:$6, As: $7}}, Partitions: $8, Where: NewWhere(WhereClause, $9), OrderBy: $10, Limit: $11, Returning: $12}
   }
 | with_clause_opt DELETE comment_opt ignore_opt FROM table_name_list USING table_references where_expression_opt
   {
@@ -1081,6 +1087,32 @@ create_statement:
     $1.CreateOptions = $2
     $$ = $1
   }
+| CREATE comment_opt SEQUENCE not_exists_opt table_name sequence_start_opt sequence_cache_opt
+  {
+    $$ = &AlterVschema{Action: CreateSequenceDDLAction, Table: $5, IfNotExists: $4, SequenceStart: $6, SequenceCache: $7}
+  }
+
+sequence_start_opt:
+  {
+    $$ = 0
+  }
+| START WITH INTEGRAL
+  {
+    $$ = convertStringToInt($3)
+  }
+| START INTEGRAL
+  {
+    $$ = convertStringToInt($2)
+  }
+
+sequence_cache_opt:
+  {
+    $$ = 0
+  }
+| CACHE INTEGRAL
+  {
+    $$ = convertStringToInt($2)
+  }
 
 replace_opt:
   {
@@ -4269,6 +4301,10 @@ explain_statement:
   {
     $$ = &ExplainStmt{Type: $2, Statement: $3}
   }
+| VEXPLAIN ANALYZE explainable_statement
+  {
+    $$ = &ExplainStmt{Type: VExplainAnalyzeType, Statement: $3}
+  }
 
 other_statement:
   REPAIR skip_to_end
@@ -4627,6 +4663,7 @@ select_expression:
   }
 
 as_ci_opt:
+  %prec EMPTY_ALIAS
   {
     $$ = IdentifierCI{}
   }
@@ -4819,6 +4856,7 @@ as_opt:
   { $$ = struct{}{} }
 
 as_opt_id:
+  %prec EMPTY_ALIAS
   {
     $$ = NewIdentifierCS("")
   }
@@ -6896,6 +6934,15 @@ on_dup_opt:
     $$ = $5
   }
 
+returning_opt:
+  {
+    $$ = nil
+  }
+| RETURNING select_expression_list
+  {
+    $$ = $2
+  }
+
 tuple_list:
   tuple_or_empty
   {
@@ -7310,6 +7357,7 @@ non_reserved_keyword:
 | BOOLEAN
 | BUCKETS
 | BYTE
+| CACHE
 | CANCEL
 | CASCADE
 | CASCADED
@@ -7631,6 +7679,7 @@ non_reserved_keyword:
 | VARIABLES
 | VARIANCE %prec FUNCTION_CALL_NON_KEYWORD
 | VCPU
+| VEXPLAIN
 | VGTID_EXECUTED
 | VIEW
 | VINDEX
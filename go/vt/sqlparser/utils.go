@@ -67,6 +67,29 @@ func QueryMatchesTemplates(query string, queryTemplates []string) (match bool, e
 	return false, nil
 }
 
+// QueryFingerprint returns the fingerprint of a query: its normalized form,
+// without its comments, with its literals replaced by bind variables and the
+// AND expressions of its WHERE clause sorted. The queries that only differ by
+// these share their fingerprint.
+func QueryFingerprint(query string) (string, error) {
+	query, err := NormalizeAlphabetically(query)
+	if err != nil {
+		return "", err
+	}
+	stmt, reservedVars, err := Parse2(query)
+	if err != nil {
+		return "", err
+	}
+	if commented, ok := stmt.(Commented); ok {
+		commented.SetComments(nil)
+	}
+	bv := make(map[string]*querypb.BindVariable)
+	if err := Normalize(stmt, NewReservedVars("", reservedVars), bv); err != nil {
+		return "", err
+	}
+	return String(stmt), nil
+}
+
 // NormalizeAlphabetically rewrites given query such that:
 // - WHERE 'AND' expressions are reordered alphabetically
 func NormalizeAlphabetically(query string) (normalized string, err error) {
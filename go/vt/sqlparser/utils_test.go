@@ -144,3 +144,31 @@ func TestQueryMatchesTemplates(t *testing.T) {
 		assert.Equal(t, tc.out, match)
 	}
 }
+
+func TestQueryFingerprint(t *testing.T) {
+	testcases := []struct {
+		in  string
+		out string
+	}{{
+		in:  "select * from tbl where a=3",
+		out: "select * from tbl where a = :1",
+	}, {
+		in:  "select /*vt+ PLANNER=gen4 */ * from tbl where b='x' and a=3",
+		out: "select * from tbl where a = :1 and b = :2",
+	}, {
+		in:  "select * from tbl where a in (1, 2, 3) limit 10",
+		out: "select * from tbl where a in ::1 limit :2",
+	}, {
+		in:  "update tbl set a = 1 where id = 2",
+		out: "update tbl set a = :1 where id = :2",
+	}}
+
+	for _, tc := range testcases {
+		fingerprint, err := QueryFingerprint(tc.in)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.out, fingerprint)
+	}
+
+	_, err := QueryFingerprint("select from")
+	assert.Error(t, err)
+}
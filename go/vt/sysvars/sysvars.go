@@ -48,8 +48,11 @@ var (
 	Autocommit                  = SystemVariable{Name: "autocommit", IsBoolean: true, Default: on}
 	Charset                     = SystemVariable{Name: "charset", Default: utf8mb4, IdentifierAsString: true}
 	ClientFoundRows             = SystemVariable{Name: "client_found_rows", IsBoolean: true, Default: off}
+	QueryRetry                  = SystemVariable{Name: "query_retry", IsBoolean: true, Default: on}
+	QueryMemoryLimit            = SystemVariable{Name: "query_memory_limit"}
 	SessionEnableSystemSettings = SystemVariable{Name: "enable_system_settings", IsBoolean: true, Default: on}
 	Names                       = SystemVariable{Name: "names", Default: utf8mb4, IdentifierAsString: true}
+	Priority                    = SystemVariable{Name: "priority"}
 	SessionUUID                 = SystemVariable{Name: "session_uuid", IdentifierAsString: true}
 	SkipQueryPlanCache          = SystemVariable{Name: "skip_query_plan_cache", IsBoolean: true, Default: off}
 	Socket                      = SystemVariable{Name: "socket", Default: off}
@@ -83,6 +86,9 @@ var (
 		Names,
 		SessionUUID,
 		SessionEnableSystemSettings,
+		QueryRetry,
+		QueryMemoryLimit,
+		Priority,
 		ReadAfterWriteGTID,
 		ReadAfterWriteTimeOut,
 		SessionTrackGTIDs,
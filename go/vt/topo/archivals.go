@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ArchivalsPath is the directory, under a keyspace, that holds the
+// archivals of its tables.
+const ArchivalsPath = "archivals"
+
+// Archival moves the rows of a table that match a predicate to an archive
+// table or to the backup storage, in batches. The archivals are run by
+// vtctld.
+type Archival struct {
+	// Name is the name of the archival, unique in its keyspace.
+	Name string `json:"name"`
+	// Table is the table whose rows are archived.
+	Table string `json:"table"`
+	// Where is the predicate of the rows to archive, as a SQL expression
+	// evaluated by MySQL, e.g. "created_at < now() - interval 90 day".
+	Where string `json:"where"`
+	// TargetKeyspace and TargetTable are the table the rows are copied to.
+	// TargetKeyspace defaults to the keyspace of the archival.
+	TargetKeyspace string `json:"target_keyspace,omitempty"`
+	TargetTable    string `json:"target_table,omitempty"`
+	// TargetDir is the directory of the backup storage the rows are
+	// written to as CSV files, instead of a table.
+	TargetDir string `json:"target_dir,omitempty"`
+	// BatchSize is the number of rows archived at a time from each shard.
+	BatchSize int `json:"batch_size"`
+	// MaxTPS is the maximum number of batches archived per second. If 0,
+	// the batches are not throttled.
+	MaxTPS int64 `json:"max_tps,omitempty"`
+	// Paused stops the archival until it is resumed.
+	Paused bool `json:"paused,omitempty"`
+
+	// ArchivedRows is the number of rows archived so far.
+	ArchivedRows int64 `json:"archived_rows"`
+	// LastBatchTime is the time of the last archived batch, in RFC 3339
+	// format.
+	LastBatchTime string `json:"last_batch_time,omitempty"`
+	// LastError is the error that stopped the last run of the archival,
+	// which is retried at the next run.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Validate checks that the archival is valid.
+func (a *Archival) Validate() error {
+	if a.Name == "" || strings.Contains(a.Name, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid archival name: %q", a.Name)
+	}
+	if a.Table == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the table of archival %s is required", a.Name)
+	}
+	if _, err := sqlparser.ParseExpr(a.Where); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid predicate %q of archival %s: %v", a.Where, a.Name, err)
+	}
+	if (a.TargetTable == "") == (a.TargetDir == "") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "archival %s needs either a target table or a target dir", a.Name)
+	}
+	if a.TargetKeyspace != "" && a.TargetTable == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the target keyspace of archival %s needs a target table", a.Name)
+	}
+	if a.BatchSize < 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid batch size: %d, expected a positive integer", a.BatchSize)
+	}
+	if a.MaxTPS < 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid max TPS: %d, expected a positive integer", a.MaxTPS)
+	}
+	return nil
+}
+
+// GetArchival returns an archival of a keyspace.
+func (ts *Server) GetArchival(ctx context.Context, keyspace, name string) (*Archival, error) {
+	archival, _, err := ts.getArchival(ctx, keyspace, name)
+	return archival, err
+}
+
+func (ts *Server) getArchival(ctx context.Context, keyspace, name string) (*Archival, Version, error) {
+	data, version, err := ts.globalCell.Get(ctx, archivalPath(keyspace, name))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	archival := &Archival{}
+	if err := json.Unmarshal(data, archival); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad archival data: %q", data)
+	}
+	return archival, version, nil
+}
+
+// GetArchivals returns the archivals of a keyspace, sorted by name.
+func (ts *Server) GetArchivals(ctx context.Context, keyspace string) ([]*Archival, error) {
+	entries, err := ts.globalCell.ListDir(ctx, path.Join(KeyspacesPath, keyspace, ArchivalsPath), false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	archivals := make([]*Archival, 0, len(entries))
+	for _, entry := range entries {
+		archival, err := ts.GetArchival(ctx, keyspace, entry.Name)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// It was deleted since the listing.
+				continue
+			}
+			return nil, err
+		}
+		archivals = append(archivals, archival)
+	}
+	return archivals, nil
+}
+
+// CreateArchival validates and saves a new archival of a keyspace.
+func (ts *Server) CreateArchival(ctx context.Context, keyspace string, archival *Archival) error {
+	if err := archival.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(archival, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Create(ctx, archivalPath(keyspace, archival.Name), data)
+	return err
+}
+
+// UpdateArchivalFields reads an archival, calls update on it, and saves it
+// if update returns no error. It retries if the archival was changed in
+// the meantime, so update may be called more than once.
+func (ts *Server) UpdateArchivalFields(ctx context.Context, keyspace, name string, update func(*Archival) error) (*Archival, error) {
+	for {
+		archival, version, err := ts.getArchival(ctx, keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := update(archival); err != nil {
+			return nil, err
+		}
+		if err := archival.Validate(); err != nil {
+			return nil, err
+		}
+
+		data, err := json.MarshalIndent(archival, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		_, err = ts.globalCell.Update(ctx, archivalPath(keyspace, name), data, version)
+		if IsErrType(err, BadVersion) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return archival, nil
+	}
+}
+
+// DeleteArchival removes an archival of a keyspace.
+func (ts *Server) DeleteArchival(ctx context.Context, keyspace, name string) error {
+	return ts.globalCell.Delete(ctx, archivalPath(keyspace, name), nil)
+}
+
+func archivalPath(keyspace, name string) string {
+	return path.Join(KeyspacesPath, keyspace, ArchivalsPath, name)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ComponentVersionsPath is the directory of the global cell that holds the
+// versions of the running components of the cluster.
+const ComponentVersionsPath = "component_versions"
+
+// ComponentVersion is the version of a running vtgate, vttablet, vtctld or
+// vtorc, which the component records at startup and refreshes periodically,
+// so that the version skew of the cluster can be checked.
+type ComponentVersion struct {
+	// Component is the kind of the component, e.g. "vtgate".
+	Component string `json:"component"`
+	// ID identifies the component among the ones of its kind, e.g. the alias
+	// of a tablet or the host:port of a vtgate.
+	ID string `json:"id"`
+	// Cell is the cell of the component, if it has one.
+	Cell string `json:"cell,omitempty"`
+	// Version is the Vitess version of the component, e.g. "15.0.0".
+	Version string `json:"version"`
+	// BuildGitRev is the git revision the component was built from.
+	BuildGitRev string `json:"build_git_rev,omitempty"`
+	// UpdatedAt is the time the version was last recorded, in RFC 3339
+	// format.
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Validate checks that the component version is valid.
+func (cv *ComponentVersion) Validate() error {
+	if cv.Component == "" || strings.Contains(cv.Component, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid component: %q", cv.Component)
+	}
+	if cv.ID == "" || strings.Contains(cv.ID, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s ID: %q", cv.Component, cv.ID)
+	}
+	if cv.Version == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the version of %s %s is required", cv.Component, cv.ID)
+	}
+	return nil
+}
+
+// SaveComponentVersion records the version of a component, replacing the
+// version it recorded before.
+func (ts *Server) SaveComponentVersion(ctx context.Context, cv *ComponentVersion) error {
+	if err := cv.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cv, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Update(ctx, componentVersionPath(cv.Component, cv.ID), data, nil)
+	return err
+}
+
+// GetComponentVersions returns the versions recorded by all the components,
+// sorted by component and ID.
+func (ts *Server) GetComponentVersions(ctx context.Context) ([]*ComponentVersion, error) {
+	components, err := ts.globalCell.ListDir(ctx, ComponentVersionsPath, false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var versions []*ComponentVersion
+	for _, component := range components {
+		entries, err := ts.globalCell.ListDir(ctx, path.Join(ComponentVersionsPath, component.Name), false /*full*/)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			data, _, err := ts.globalCell.Get(ctx, componentVersionPath(component.Name, entry.Name))
+			if err != nil {
+				if IsErrType(err, NoNode) {
+					// The component stopped since the listing.
+					continue
+				}
+				return nil, err
+			}
+			cv := &ComponentVersion{}
+			if err := json.Unmarshal(data, cv); err != nil {
+				return nil, vterrors.Wrapf(err, "bad component version data: %q", data)
+			}
+			versions = append(versions, cv)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Component != versions[j].Component {
+			return versions[i].Component < versions[j].Component
+		}
+		return versions[i].ID < versions[j].ID
+	})
+	return versions, nil
+}
+
+// DeleteComponentVersion removes the version of a component, e.g. when it
+// shuts down.
+func (ts *Server) DeleteComponentVersion(ctx context.Context, component, id string) error {
+	return ts.globalCell.Delete(ctx, componentVersionPath(component, id), nil)
+}
+
+func componentVersionPath(component, id string) string {
+	return path.Join(ComponentVersionsPath, component, id)
+}
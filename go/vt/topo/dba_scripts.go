@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// DBAScriptsPath is the directory, under a keyspace, that holds the DBA
+// scripts run on its tablets.
+const DBAScriptsPath = "dba_scripts"
+
+// The states of a DBA script.
+const (
+	// DBAScriptPending is the state of a script that waits for its approval.
+	DBAScriptPending = "pending"
+	// DBAScriptRunning is the state of a script that runs on its tablets.
+	DBAScriptRunning = "running"
+	// DBAScriptDone is the state of a script that ran on all its tablets.
+	DBAScriptDone = "done"
+	// DBAScriptFailed is the state of a script that failed on a tablet.
+	DBAScriptFailed = "failed"
+)
+
+// DBAScript is a SQL script submitted by an operator to run as the DBA user
+// on a set of tablets of a keyspace. The scripts are kept as the audit log
+// of the DBA scripts of the keyspace.
+type DBAScript struct {
+	// Name is the name of the script, unique in its keyspace.
+	Name string `json:"name"`
+	// SQL holds the statements of the script.
+	SQL string `json:"sql"`
+	// TabletAliases, TabletTypes and Tags select the tablets of the keyspace
+	// that run the script. A tablet is selected if it matches all of them,
+	// and an empty selector matches all the tablets.
+	TabletAliases []string          `json:"tablet_aliases,omitempty"`
+	TabletTypes   []string          `json:"tablet_types,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	// Concurrency is the maximum number of tablets that run the script at
+	// the same time.
+	Concurrency int `json:"concurrency"`
+	// SubmittedBy is the operator that submitted the script.
+	SubmittedBy string `json:"submitted_by"`
+	// RequireApproval holds the script until an operator other than the
+	// submitter approves it.
+	RequireApproval bool   `json:"require_approval,omitempty"`
+	ApprovedBy      string `json:"approved_by,omitempty"`
+	// State is the state of the script.
+	State string `json:"state"`
+	// Audit records the actions on the script, in order.
+	Audit []*DBAScriptAuditEntry `json:"audit"`
+}
+
+// DBAScriptAuditEntry is an action on a DBA script.
+type DBAScriptAuditEntry struct {
+	// Time is the time of the action, in RFC 3339 format.
+	Time string `json:"time"`
+	// User is the operator that took the action, if any.
+	User string `json:"user,omitempty"`
+	// Action is the action, e.g. "submitted", "approved" or "ran".
+	Action string `json:"action"`
+	// Detail describes the action, e.g. the tablet that ran the script.
+	Detail string `json:"detail,omitempty"`
+}
+
+// AddAuditEntry records an action on the script.
+func (s *DBAScript) AddAuditEntry(user, action, detail string) {
+	s.Audit = append(s.Audit, &DBAScriptAuditEntry{
+		Time:   time.Now().UTC().Format(time.RFC3339),
+		User:   user,
+		Action: action,
+		Detail: detail,
+	})
+}
+
+// Validate checks that the DBA script is valid.
+func (s *DBAScript) Validate() error {
+	if s.Name == "" || strings.Contains(s.Name, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid DBA script name: %q", s.Name)
+	}
+	if strings.TrimSpace(s.SQL) == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the SQL of DBA script %s is required", s.Name)
+	}
+	if s.Concurrency < 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid concurrency: %d, expected a positive integer", s.Concurrency)
+	}
+	if s.SubmittedBy == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the submitter of DBA script %s is required", s.Name)
+	}
+	if s.ApprovedBy != "" && s.ApprovedBy == s.SubmittedBy {
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "DBA script %s must be approved by another user than its submitter %s", s.Name, s.SubmittedBy)
+	}
+	switch s.State {
+	case DBAScriptPending, DBAScriptRunning, DBAScriptDone, DBAScriptFailed:
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid state of DBA script %s: %q", s.Name, s.State)
+	}
+	return nil
+}
+
+// GetDBAScript returns a DBA script of a keyspace.
+func (ts *Server) GetDBAScript(ctx context.Context, keyspace, name string) (*DBAScript, error) {
+	script, _, err := ts.getDBAScript(ctx, keyspace, name)
+	return script, err
+}
+
+func (ts *Server) getDBAScript(ctx context.Context, keyspace, name string) (*DBAScript, Version, error) {
+	data, version, err := ts.globalCell.Get(ctx, dbaScriptPath(keyspace, name))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	script := &DBAScript{}
+	if err := json.Unmarshal(data, script); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad DBA script data: %q", data)
+	}
+	return script, version, nil
+}
+
+// GetDBAScripts returns the DBA scripts of a keyspace, sorted by name.
+func (ts *Server) GetDBAScripts(ctx context.Context, keyspace string) ([]*DBAScript, error) {
+	entries, err := ts.globalCell.ListDir(ctx, path.Join(KeyspacesPath, keyspace, DBAScriptsPath), false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	scripts := make([]*DBAScript, 0, len(entries))
+	for _, entry := range entries {
+		script, err := ts.GetDBAScript(ctx, keyspace, entry.Name)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// It was deleted since the listing.
+				continue
+			}
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// CreateDBAScript validates and saves a new DBA script of a keyspace.
+func (ts *Server) CreateDBAScript(ctx context.Context, keyspace string, script *DBAScript) error {
+	if err := script.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(script, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Create(ctx, dbaScriptPath(keyspace, script.Name), data)
+	return err
+}
+
+// UpdateDBAScriptFields reads a DBA script, calls update on it, and saves
+// it if update returns no error. It retries if the script was changed in
+// the meantime, so update may be called more than once.
+func (ts *Server) UpdateDBAScriptFields(ctx context.Context, keyspace, name string, update func(*DBAScript) error) (*DBAScript, error) {
+	for {
+		script, version, err := ts.getDBAScript(ctx, keyspace, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := update(script); err != nil {
+			return nil, err
+		}
+		if err := script.Validate(); err != nil {
+			return nil, err
+		}
+
+		data, err := json.MarshalIndent(script, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		_, err = ts.globalCell.Update(ctx, dbaScriptPath(keyspace, name), data, version)
+		if IsErrType(err, BadVersion) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return script, nil
+	}
+}
+
+// DeleteDBAScript removes a DBA script of a keyspace.
+func (ts *Server) DeleteDBAScript(ctx context.Context, keyspace, name string) error {
+	return ts.globalCell.Delete(ctx, dbaScriptPath(keyspace, name), nil)
+}
+
+func dbaScriptPath(keyspace, name string) string {
+	return path.Join(KeyspacesPath, keyspace, DBAScriptsPath, name)
+}
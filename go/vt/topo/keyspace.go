@@ -314,6 +314,40 @@ func (ts *Server) DeleteKeyspace(ctx context.Context, keyspace string) error {
 		return err
 	}
 
+	if err := ts.DeleteKeyspaceSettings(ctx, keyspace); err != nil && !IsErrType(err, NoNode) {
+		return err
+	}
+
+	archivals, err := ts.GetArchivals(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, archival := range archivals {
+		if err := ts.DeleteArchival(ctx, keyspace, archival.Name); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+
+	policies, err := ts.GetPartitionPolicies(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		if err := ts.DeletePartitionPolicy(ctx, keyspace, policy.Table); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+
+	scripts, err := ts.GetDBAScripts(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, script := range scripts {
+		if err := ts.DeleteDBAScript(ctx, keyspace, script.Name); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+
 	event.Dispatch(&events.KeyspaceChange{
 		KeyspaceName: keyspace,
 		Keyspace:     nil,
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/featuregate"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// KeyspaceSettingsFile is the file, under a keyspace, that holds the
+// settings vtgates apply to the queries they route to it, and the settings
+// its tablets apply to its tables.
+const KeyspaceSettingsFile = "Settings"
+
+// KeyspaceSettings are per-keyspace settings that vtgates watch and apply
+// to the queries they route to the keyspace, and that its tablets apply to
+// the garbage collection of its dropped tables.
+type KeyspaceSettings struct {
+	// NoScatter makes vtgates reject queries that would scatter across
+	// the shards of the keyspace, unless they carry the ALLOW_SCATTER
+	// directive.
+	NoScatter bool `json:"no_scatter,omitempty"`
+	// DefaultWorkload is the workload (OLTP, OLAP or DBA) of new MySQL
+	// protocol sessions that connect to the keyspace.
+	DefaultWorkload string `json:"default_workload,omitempty"`
+	// ShedPercent is the percentage, between 0 and 100, of the queries of
+	// ShedPriority or a lower priority that vtgates reject, to protect the
+	// keyspace while it is overloaded.
+	ShedPercent int `json:"shed_percent,omitempty"`
+	// ShedPriority is the highest priority of the queries that are shed,
+	// between 1 and 100. Priorities range from 0, the highest, to 100, the
+	// lowest, so that queries of priority 0 are never shed. If it is not set,
+	// only the queries of the lowest priority are shed.
+	ShedPriority int `json:"shed_priority,omitempty"`
+	// TableGCLifecycle is the comma separated list of states (hold, purge,
+	// evac, drop) that the tables dropped in the keyspace go through before
+	// they are gone, like --table_gc_lifecycle of the tablets, which it
+	// overrides.
+	TableGCLifecycle string `json:"table_gc_lifecycle,omitempty"`
+	// TableGCHold is how long the tables dropped by Online DDL, and the
+	// tables left behind by its migrations, are held before they are purged,
+	// e.g. "72h". It overrides --retain_online_ddl_tables of the tablets.
+	// While a dropped table is held, it can be restored.
+	TableGCHold string `json:"table_gc_hold,omitempty"`
+	// FeatureGates turns feature gates on and off in the keyspace, by name.
+	// They override the --feature_gates of the vtgates and the tablets.
+	FeatureGates map[string]bool `json:"feature_gates,omitempty"`
+	// AvailabilityObjective is the objective of the availability of the
+	// queries vtgates route to the keyspace: the fraction of them that do
+	// not fail with a server error, e.g. 0.999. It overrides the
+	// --slo_availability_objective of the vtgates.
+	AvailabilityObjective float64 `json:"availability_objective,omitempty"`
+	// LatencyObjective is the objective of the latency of the queries
+	// vtgates route to the keyspace: the fraction of them that complete
+	// within LatencyThreshold, e.g. 0.99. It overrides the
+	// --slo_latency_objective of the vtgates.
+	LatencyObjective float64 `json:"latency_objective,omitempty"`
+	// LatencyThreshold is the latency of the queries that the latency
+	// objective counts as fast enough, e.g. "100ms". It overrides the
+	// --slo_latency_threshold of the vtgates.
+	LatencyThreshold string `json:"latency_threshold,omitempty"`
+}
+
+// lowestPriority is the lowest priority of a query.
+const lowestPriority = 100
+
+// Validate checks that the settings are valid.
+func (s *KeyspaceSettings) Validate() error {
+	if s.DefaultWorkload != "" {
+		if _, ok := querypb.ExecuteOptions_Workload_value[strings.ToUpper(s.DefaultWorkload)]; !ok {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid default workload: %s", s.DefaultWorkload)
+		}
+	}
+	if s.ShedPercent < 0 || s.ShedPercent > 100 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shed percent: %d, expected an integer between 0 and 100", s.ShedPercent)
+	}
+	if s.ShedPriority < 0 || s.ShedPriority > lowestPriority {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shed priority: %d, expected an integer between 1 and %d", s.ShedPriority, lowestPriority)
+	}
+	if s.TableGCLifecycle != "" {
+		if _, err := schema.ParseGCLifecycle(s.TableGCLifecycle); err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid table GC lifecycle %q: %v", s.TableGCLifecycle, err)
+		}
+	}
+	if s.TableGCHold != "" {
+		hold, err := time.ParseDuration(s.TableGCHold)
+		if err != nil || hold <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid table GC hold: %s, expected a positive duration", s.TableGCHold)
+		}
+	}
+	if s.AvailabilityObjective < 0 || s.AvailabilityObjective >= 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid availability objective: %v, expected a fraction between 0 and 1, e.g. 0.999", s.AvailabilityObjective)
+	}
+	if s.LatencyObjective < 0 || s.LatencyObjective >= 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid latency objective: %v, expected a fraction between 0 and 1, e.g. 0.99", s.LatencyObjective)
+	}
+	if s.LatencyThreshold != "" {
+		threshold, err := time.ParseDuration(s.LatencyThreshold)
+		if err != nil || threshold <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid latency threshold: %s, expected a positive duration", s.LatencyThreshold)
+		}
+	}
+	for name := range s.FeatureGates {
+		if featuregate.Lookup(name) == nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown feature gate: %s", name)
+		}
+	}
+	return nil
+}
+
+// TableGCHoldDuration returns the table GC hold of the settings, or 0 if none
+// is set.
+func (s *KeyspaceSettings) TableGCHoldDuration() time.Duration {
+	hold, _ := time.ParseDuration(s.TableGCHold)
+	return hold
+}
+
+// LatencyThresholdDuration returns the latency threshold of the settings, or
+// 0 if none is set.
+func (s *KeyspaceSettings) LatencyThresholdDuration() time.Duration {
+	threshold, _ := time.ParseDuration(s.LatencyThreshold)
+	return threshold
+}
+
+// Sheds returns true if a share of the queries of the given priority are
+// shed.
+func (s *KeyspaceSettings) Sheds(priority int) bool {
+	if s.ShedPercent == 0 {
+		return false
+	}
+	shedPriority := s.ShedPriority
+	if shedPriority == 0 {
+		shedPriority = lowestPriority
+	}
+	return priority >= shedPriority
+}
+
+// Workload returns the default workload of the settings, or UNSPECIFIED if
+// none is set.
+func (s *KeyspaceSettings) Workload() querypb.ExecuteOptions_Workload {
+	return querypb.ExecuteOptions_Workload(querypb.ExecuteOptions_Workload_value[strings.ToUpper(s.DefaultWorkload)])
+}
+
+// GetKeyspaceSettings returns the settings of a keyspace. It returns empty
+// settings if none were saved.
+func (ts *Server) GetKeyspaceSettings(ctx context.Context, keyspace string) (*KeyspaceSettings, error) {
+	data, _, err := ts.globalCell.Get(ctx, keyspaceSettingsPath(keyspace))
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return &KeyspaceSettings{}, nil
+		}
+		return nil, err
+	}
+
+	settings := &KeyspaceSettings{}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, vterrors.Wrapf(err, "bad keyspace settings data: %q", data)
+	}
+	return settings, nil
+}
+
+// SaveKeyspaceSettings validates and saves the settings of a keyspace.
+func (ts *Server) SaveKeyspaceSettings(ctx context.Context, keyspace string, settings *KeyspaceSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Update(ctx, keyspaceSettingsPath(keyspace), data, nil)
+	return err
+}
+
+// DeleteKeyspaceSettings removes the settings of a keyspace.
+func (ts *Server) DeleteKeyspaceSettings(ctx context.Context, keyspace string) error {
+	return ts.globalCell.Delete(ctx, keyspaceSettingsPath(keyspace), nil)
+}
+
+func keyspaceSettingsPath(keyspace string) string {
+	return path.Join(KeyspacesPath, keyspace, KeyspaceSettingsFile)
+}
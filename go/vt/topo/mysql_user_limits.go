@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// MySQLUserLimitsFile is the file, in the global cell, that holds the
+// limits vtgates apply to the connections and the queries of the MySQL
+// users.
+const MySQLUserLimitsFile = "MySQLUserLimits"
+
+// MySQLUserLimits are the limits vtgates apply to a MySQL user. They take
+// precedence over the limits of the auth server of the vtgates. Zero means
+// the limit of the auth server applies.
+type MySQLUserLimits struct {
+	// MaxConnections is the maximum number of MySQL connections of the
+	// user to each vtgate.
+	MaxConnections int `json:"max_connections,omitempty"`
+	// MaxConcurrentQueries is the maximum number of queries of the user
+	// that each vtgate executes at the same time.
+	MaxConcurrentQueries int `json:"max_concurrent_queries,omitempty"`
+}
+
+// Validate checks that the limits are valid.
+func (l *MySQLUserLimits) Validate() error {
+	if l.MaxConnections < 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid max connections: %d, expected a positive integer", l.MaxConnections)
+	}
+	if l.MaxConcurrentQueries < 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid max concurrent queries: %d, expected a positive integer", l.MaxConcurrentQueries)
+	}
+	return nil
+}
+
+// GetMySQLUserLimits returns the limits of all the MySQL users that have
+// some, by user.
+func (ts *Server) GetMySQLUserLimits(ctx context.Context) (map[string]*MySQLUserLimits, error) {
+	limits, _, err := ts.getMySQLUserLimits(ctx)
+	return limits, err
+}
+
+func (ts *Server) getMySQLUserLimits(ctx context.Context) (map[string]*MySQLUserLimits, Version, error) {
+	data, version, err := ts.globalCell.Get(ctx, MySQLUserLimitsFile)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return map[string]*MySQLUserLimits{}, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	limits := make(map[string]*MySQLUserLimits)
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad MySQL user limits data: %q", data)
+	}
+	return limits, version, nil
+}
+
+// SaveMySQLUserLimits validates and saves the limits of a MySQL user. Empty
+// limits remove the limits of the user.
+func (ts *Server) SaveMySQLUserLimits(ctx context.Context, user string, userLimits *MySQLUserLimits) error {
+	if user == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the user is required")
+	}
+	if err := userLimits.Validate(); err != nil {
+		return err
+	}
+
+	limits, version, err := ts.getMySQLUserLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if *userLimits == (MySQLUserLimits{}) {
+		delete(limits, user)
+	} else {
+		limits[user] = userLimits
+	}
+
+	data, err := json.MarshalIndent(limits, "", "  ")
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		_, err = ts.globalCell.Create(ctx, MySQLUserLimitsFile, data)
+	} else {
+		_, err = ts.globalCell.Update(ctx, MySQLUserLimitsFile, data, version)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// PartitionPoliciesPath is the directory, under a keyspace, that holds the
+// partition policies of its tables.
+const PartitionPoliciesPath = "partition_policies"
+
+// The intervals of the partitions of a partition policy.
+const (
+	PartitionIntervalDay   = "day"
+	PartitionIntervalWeek  = "week"
+	PartitionIntervalMonth = "month"
+)
+
+// PartitionPolicy declares how the RANGE partitions of a time-partitioned
+// table are rotated. The primary tablets create the partitions of the
+// coming intervals ahead of time, and drop the partitions whose rows are
+// older than the retention, through Online DDL.
+type PartitionPolicy struct {
+	// Table is the partitioned table.
+	Table string `json:"table"`
+	// Interval is the range of time of each partition: day, week or month.
+	Interval string `json:"interval"`
+	// Precreate is the number of partitions kept ahead of the current
+	// interval.
+	Precreate int `json:"precreate"`
+	// Retention is the number of past intervals whose partitions are kept.
+	// If 0, no partition is dropped.
+	Retention int `json:"retention,omitempty"`
+}
+
+// Validate checks that the partition policy is valid.
+func (p *PartitionPolicy) Validate() error {
+	if p.Table == "" || strings.Contains(p.Table, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid partition policy table: %q", p.Table)
+	}
+	switch p.Interval {
+	case PartitionIntervalDay, PartitionIntervalWeek, PartitionIntervalMonth:
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid partition interval of table %s: %q, expected day, week or month", p.Table, p.Interval)
+	}
+	if p.Precreate < 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid number of precreated partitions of table %s: %d, expected a positive integer", p.Table, p.Precreate)
+	}
+	if p.Retention < 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid partition retention of table %s: %d, expected a positive integer or 0", p.Table, p.Retention)
+	}
+	return nil
+}
+
+// GetPartitionPolicy returns the partition policy of a table.
+func (ts *Server) GetPartitionPolicy(ctx context.Context, keyspace, table string) (*PartitionPolicy, error) {
+	data, _, err := ts.globalCell.Get(ctx, partitionPolicyPath(keyspace, table))
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &PartitionPolicy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, vterrors.Wrapf(err, "bad partition policy data: %q", data)
+	}
+	return policy, nil
+}
+
+// GetPartitionPolicies returns the partition policies of the tables of a
+// keyspace, sorted by table.
+func (ts *Server) GetPartitionPolicies(ctx context.Context, keyspace string) ([]*PartitionPolicy, error) {
+	entries, err := ts.globalCell.ListDir(ctx, path.Join(KeyspacesPath, keyspace, PartitionPoliciesPath), false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	policies := make([]*PartitionPolicy, 0, len(entries))
+	for _, entry := range entries {
+		policy, err := ts.GetPartitionPolicy(ctx, keyspace, entry.Name)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// It was deleted since the listing.
+				continue
+			}
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SavePartitionPolicy validates and saves the partition policy of a table,
+// replacing its current policy if any.
+func (ts *Server) SavePartitionPolicy(ctx context.Context, keyspace string, policy *PartitionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Update(ctx, partitionPolicyPath(keyspace, policy.Table), data, nil)
+	return err
+}
+
+// DeletePartitionPolicy removes the partition policy of a table. The
+// partitions of the table are kept as they are.
+func (ts *Server) DeletePartitionPolicy(ctx context.Context, keyspace, table string) error {
+	return ts.globalCell.Delete(ctx, partitionPolicyPath(keyspace, table), nil)
+}
+
+func partitionPolicyPath(keyspace, table string) string {
+	return path.Join(KeyspacesPath, keyspace, PartitionPoliciesPath, table)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestArchivals(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	archivals, err := ts.GetArchivals(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, archivals)
+
+	err = ts.CreateArchival(ctx, "ks", &topo.Archival{Name: "old_orders", Table: "orders", Where: "created_at <", TargetTable: "orders_archive", BatchSize: 100})
+	assert.ErrorContains(t, err, `invalid predicate "created_at <" of archival old_orders`)
+	err = ts.CreateArchival(ctx, "ks", &topo.Archival{Name: "old_orders", Table: "orders", Where: "id < 10", BatchSize: 100})
+	assert.EqualError(t, err, "archival old_orders needs either a target table or a target dir")
+	err = ts.CreateArchival(ctx, "ks", &topo.Archival{Name: "old_orders", Table: "orders", Where: "id < 10", TargetKeyspace: "archive", TargetDir: "orders", BatchSize: 100})
+	assert.EqualError(t, err, "the target keyspace of archival old_orders needs a target table")
+	err = ts.CreateArchival(ctx, "ks", &topo.Archival{Name: "old_orders", Table: "orders", Where: "id < 10", TargetDir: "orders"})
+	assert.EqualError(t, err, "invalid batch size: 0, expected a positive integer")
+
+	want := &topo.Archival{
+		Name:           "old_orders",
+		Table:          "orders",
+		Where:          "created_at < now() - interval 90 day",
+		TargetKeyspace: "archive",
+		TargetTable:    "orders",
+		BatchSize:      100,
+	}
+	require.NoError(t, ts.CreateArchival(ctx, "ks", want))
+	err = ts.CreateArchival(ctx, "ks", want)
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), err)
+	require.NoError(t, ts.CreateArchival(ctx, "ks", &topo.Archival{Name: "events", Table: "events", Where: "id < 1000", TargetDir: "events", BatchSize: 10}))
+
+	archivals, err = ts.GetArchivals(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, archivals, 2)
+	assert.Equal(t, "events", archivals[0].Name)
+	assert.Equal(t, want, archivals[1])
+
+	archival, err := ts.UpdateArchivalFields(ctx, "ks", "old_orders", func(a *topo.Archival) error {
+		a.Paused = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, archival.Paused)
+	_, err = ts.UpdateArchivalFields(ctx, "ks", "old_orders", func(a *topo.Archival) error {
+		a.BatchSize = -1
+		return nil
+	})
+	assert.EqualError(t, err, "invalid batch size: -1, expected a positive integer")
+	_, err = ts.UpdateArchivalFields(ctx, "ks", "old_orders", func(a *topo.Archival) error {
+		return errors.New("no change")
+	})
+	assert.EqualError(t, err, "no change")
+	archival, err = ts.GetArchival(ctx, "ks", "old_orders")
+	require.NoError(t, err)
+	assert.True(t, archival.Paused)
+	assert.Equal(t, 100, archival.BatchSize)
+
+	require.NoError(t, ts.DeleteArchival(ctx, "ks", "events"))
+	_, err = ts.GetArchival(ctx, "ks", "events")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	archivals, err = ts.GetArchivals(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, archivals)
+}
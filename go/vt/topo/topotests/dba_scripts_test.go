@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestDBAScripts(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	scripts, err := ts.GetDBAScripts(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, scripts)
+
+	err = ts.CreateDBAScript(ctx, "ks", &topo.DBAScript{Name: "s1", SQL: " ", Concurrency: 1, SubmittedBy: "alice", State: topo.DBAScriptPending})
+	assert.EqualError(t, err, "the SQL of DBA script s1 is required")
+	err = ts.CreateDBAScript(ctx, "ks", &topo.DBAScript{Name: "s1", SQL: "optimize table t", Concurrency: 1, State: topo.DBAScriptPending})
+	assert.EqualError(t, err, "the submitter of DBA script s1 is required")
+
+	script := &topo.DBAScript{
+		Name:            "s1",
+		SQL:             "optimize table t",
+		TabletTypes:     []string{"replica"},
+		Concurrency:     1,
+		SubmittedBy:     "alice",
+		RequireApproval: true,
+		State:           topo.DBAScriptPending,
+	}
+	script.AddAuditEntry("alice", "submitted", "")
+	require.NoError(t, ts.CreateDBAScript(ctx, "ks", script))
+	err = ts.CreateDBAScript(ctx, "ks", script)
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), err)
+
+	// The submitter cannot approve their own script.
+	_, err = ts.UpdateDBAScriptFields(ctx, "ks", "s1", func(s *topo.DBAScript) error {
+		s.ApprovedBy = "alice"
+		return nil
+	})
+	assert.EqualError(t, err, "DBA script s1 must be approved by another user than its submitter alice")
+
+	updated, err := ts.UpdateDBAScriptFields(ctx, "ks", "s1", func(s *topo.DBAScript) error {
+		s.ApprovedBy = "bob"
+		s.State = topo.DBAScriptRunning
+		s.AddAuditEntry("bob", "approved", "")
+		return nil
+	})
+	require.NoError(t, err)
+	got, err := ts.GetDBAScript(ctx, "ks", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+	require.Len(t, got.Audit, 2)
+	assert.Equal(t, "approved", got.Audit[1].Action)
+
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	scripts, err = ts.GetDBAScripts(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, scripts)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestKeyspaceSettings(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	settings, err := ts.GetKeyspaceSettings(ctx, "ks")
+	require.NoError(t, err)
+	assert.Equal(t, &topo.KeyspaceSettings{}, settings)
+
+	err = ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{DefaultWorkload: "batch"})
+	assert.EqualError(t, err, "invalid default workload: batch")
+
+	err = ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{ShedPercent: 101})
+	assert.EqualError(t, err, "invalid shed percent: 101, expected an integer between 0 and 100")
+	err = ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{ShedPercent: 50, ShedPriority: -1})
+	assert.EqualError(t, err, "invalid shed priority: -1, expected an integer between 1 and 100")
+
+	want := &topo.KeyspaceSettings{NoScatter: true, DefaultWorkload: "OLAP", ShedPercent: 20, ShedPriority: 80}
+	require.NoError(t, ts.SaveKeyspaceSettings(ctx, "ks", want))
+	settings, err = ts.GetKeyspaceSettings(ctx, "ks")
+	require.NoError(t, err)
+	assert.Equal(t, want, settings)
+	assert.Equal(t, querypb.ExecuteOptions_OLAP, settings.Workload())
+	assert.True(t, settings.Sheds(100))
+	assert.True(t, settings.Sheds(80))
+	assert.False(t, settings.Sheds(50))
+
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	settings, err = ts.GetKeyspaceSettings(ctx, "ks")
+	require.NoError(t, err)
+	assert.Equal(t, &topo.KeyspaceSettings{}, settings)
+}
+
+func TestKeyspaceSettingsTableGC(t *testing.T) {
+	settings := &topo.KeyspaceSettings{TableGCLifecycle: "hold,bury"}
+	assert.EqualError(t, settings.Validate(), `invalid table GC lifecycle "hold,bury": Unknown GC state: BURY`)
+	settings = &topo.KeyspaceSettings{TableGCHold: "-1h"}
+	assert.EqualError(t, settings.Validate(), "invalid table GC hold: -1h, expected a positive duration")
+	settings = &topo.KeyspaceSettings{TableGCHold: "a week"}
+	assert.EqualError(t, settings.Validate(), "invalid table GC hold: a week, expected a positive duration")
+
+	settings = &topo.KeyspaceSettings{TableGCLifecycle: "hold,drop", TableGCHold: "72h"}
+	assert.NoError(t, settings.Validate())
+	assert.Equal(t, 72*time.Hour, settings.TableGCHoldDuration())
+	assert.Zero(t, (&topo.KeyspaceSettings{}).TableGCHoldDuration())
+}
+
+func TestKeyspaceSettingsSheds(t *testing.T) {
+	// Only the queries of the lowest priority are shed by default.
+	settings := &topo.KeyspaceSettings{ShedPercent: 10}
+	assert.True(t, settings.Sheds(100))
+	assert.False(t, settings.Sheds(99))
+
+	// Nothing is shed without a percentage.
+	settings = &topo.KeyspaceSettings{ShedPriority: 1}
+	assert.False(t, settings.Sheds(100))
+
+	// The queries of the highest priority are never shed.
+	settings = &topo.KeyspaceSettings{ShedPercent: 100, ShedPriority: 1}
+	assert.True(t, settings.Sheds(1))
+	assert.False(t, settings.Sheds(0))
+}
+
+func TestKeyspaceSettingsFeatureGates(t *testing.T) {
+	settings := &topo.KeyspaceSettings{FeatureGates: map[string]bool{"NoSuchGate": true}}
+	assert.EqualError(t, settings.Validate(), "unknown feature gate: NoSuchGate")
+
+	settings = &topo.KeyspaceSettings{FeatureGates: map[string]bool{"ParallelVPlayer": false}}
+	assert.NoError(t, settings.Validate())
+}
+
+func TestKeyspaceSettingsSLO(t *testing.T) {
+	settings := &topo.KeyspaceSettings{AvailabilityObjective: 1}
+	assert.EqualError(t, settings.Validate(), "invalid availability objective: 1, expected a fraction between 0 and 1, e.g. 0.999")
+	settings = &topo.KeyspaceSettings{LatencyObjective: -0.5}
+	assert.EqualError(t, settings.Validate(), "invalid latency objective: -0.5, expected a fraction between 0 and 1, e.g. 0.99")
+	settings = &topo.KeyspaceSettings{LatencyThreshold: "0s"}
+	assert.EqualError(t, settings.Validate(), "invalid latency threshold: 0s, expected a positive duration")
+
+	settings = &topo.KeyspaceSettings{AvailabilityObjective: 0.999, LatencyObjective: 0.99, LatencyThreshold: "50ms"}
+	assert.NoError(t, settings.Validate())
+	assert.Equal(t, 50*time.Millisecond, settings.LatencyThresholdDuration())
+	assert.Zero(t, (&topo.KeyspaceSettings{}).LatencyThresholdDuration())
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestMySQLUserLimits(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+
+	limits, err := ts.GetMySQLUserLimits(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, limits)
+
+	err = ts.SaveMySQLUserLimits(ctx, "app", &topo.MySQLUserLimits{MaxConnections: -1})
+	assert.EqualError(t, err, "invalid max connections: -1, expected a positive integer")
+	err = ts.SaveMySQLUserLimits(ctx, "", &topo.MySQLUserLimits{MaxConnections: 1})
+	assert.EqualError(t, err, "the user is required")
+
+	require.NoError(t, ts.SaveMySQLUserLimits(ctx, "app", &topo.MySQLUserLimits{MaxConnections: 100, MaxConcurrentQueries: 10}))
+	require.NoError(t, ts.SaveMySQLUserLimits(ctx, "batch", &topo.MySQLUserLimits{MaxConcurrentQueries: 2}))
+	limits, err = ts.GetMySQLUserLimits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*topo.MySQLUserLimits{
+		"app":   {MaxConnections: 100, MaxConcurrentQueries: 10},
+		"batch": {MaxConcurrentQueries: 2},
+	}, limits)
+
+	// Empty limits remove the limits of the user.
+	require.NoError(t, ts.SaveMySQLUserLimits(ctx, "app", &topo.MySQLUserLimits{}))
+	limits, err = ts.GetMySQLUserLimits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*topo.MySQLUserLimits{"batch": {MaxConcurrentQueries: 2}}, limits)
+}
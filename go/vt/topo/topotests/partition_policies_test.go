@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestPartitionPolicies(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	policies, err := ts.GetPartitionPolicies(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+
+	err = ts.SavePartitionPolicy(ctx, "ks", &topo.PartitionPolicy{Table: "events", Interval: "year", Precreate: 3})
+	assert.EqualError(t, err, `invalid partition interval of table events: "year", expected day, week or month`)
+	err = ts.SavePartitionPolicy(ctx, "ks", &topo.PartitionPolicy{Table: "events", Interval: "day"})
+	assert.EqualError(t, err, "invalid number of precreated partitions of table events: 0, expected a positive integer")
+	err = ts.SavePartitionPolicy(ctx, "ks", &topo.PartitionPolicy{Table: "events", Interval: "day", Precreate: 3, Retention: -1})
+	assert.EqualError(t, err, "invalid partition retention of table events: -1, expected a positive integer or 0")
+
+	require.NoError(t, ts.SavePartitionPolicy(ctx, "ks", &topo.PartitionPolicy{Table: "logs", Interval: "day", Precreate: 3, Retention: 30}))
+	require.NoError(t, ts.SavePartitionPolicy(ctx, "ks", &topo.PartitionPolicy{Table: "events", Interval: "month", Precreate: 2}))
+	// Saving a policy replaces the current one.
+	want := &topo.PartitionPolicy{Table: "logs", Interval: "week", Precreate: 2, Retention: 8}
+	require.NoError(t, ts.SavePartitionPolicy(ctx, "ks", want))
+
+	policies, err = ts.GetPartitionPolicies(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "events", policies[0].Table)
+	assert.Equal(t, want, policies[1])
+
+	require.NoError(t, ts.DeletePartitionPolicy(ctx, "ks", "events"))
+	_, err = ts.GetPartitionPolicy(ctx, "ks", "events")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	policies, err = ts.GetPartitionPolicies(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
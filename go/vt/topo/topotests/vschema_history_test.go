@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestVSchemaHistory(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+
+	history, err := ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	v1 := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
+		},
+	}
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", v1))
+
+	v2 := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t2": {},
+		},
+	}
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", v2))
+
+	history, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.EqualValues(t, 1, history[0].Version)
+	assert.Equal(t, "initial version", history[0].Summary)
+	assert.EqualValues(t, 2, history[1].Version)
+	assert.Equal(t, "added tables t2; removed tables t1", history[1].Summary)
+	assert.Nil(t, history[1].VSchema)
+
+	vv, err := ts.GetVSchemaVersion(ctx, "ks", 1)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(v1, vv.VSchema))
+
+	vs, err := ts.RollbackVSchema(ctx, "ks", 1)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(v1, vs))
+
+	current, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(v1, current))
+
+	history, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "rollback to version 1", history[2].Summary)
+
+	_, err = ts.RollbackVSchema(ctx, "ks", 10)
+	assert.Error(t, err)
+
+	require.NoError(t, ts.DeleteVSchema(ctx, "ks"))
+	history, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestVSchemaHistoryConcurrentSaves(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+
+	const saves = 10
+	var wg sync.WaitGroup
+	for i := 0; i < saves; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			vs := &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					fmt.Sprintf("t%d", i): {},
+				},
+			}
+			assert.NoError(t, ts.SaveVSchema(ctx, "ks", vs))
+		}(i)
+	}
+	wg.Wait()
+
+	// Every save got its own version.
+	history, err := ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, history, saves)
+	for i, vv := range history {
+		assert.EqualValues(t, i+1, vv.Version)
+	}
+
+	// Deleting the vschema restarts its history.
+	require.NoError(t, ts.DeleteVSchema(ctx, "ks"))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{}))
+	history, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.EqualValues(t, 1, history[0].Version)
+}
+
+func TestSummarizeVSchemaChange(t *testing.T) {
+	previous := &vschemapb.Keyspace{
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
+			"t2": {},
+		},
+	}
+	vs := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "xxhash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}}},
+			"t3": {},
+		},
+	}
+
+	assert.Equal(t, "sharded changed to true; added tables t3; removed tables t2; changed tables t1; changed vindexes hash", topo.SummarizeVSchemaChange(previous, vs))
+	assert.Equal(t, "no changes", topo.SummarizeVSchemaChange(vs, vs))
+}
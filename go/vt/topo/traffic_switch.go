@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file contains the utility methods to manage the TrafficSwitches
+// object of a cell. Workflows use it to tell the vtgates of the cell that
+// writes are being switched away from some shards, so that they can buffer
+// the queries to these shards until the switch is over.
+
+// TrafficSwitchesFile is the file, at the root of each cell, that lists the
+// traffic switches of the keyspaces.
+const TrafficSwitchesFile = "TrafficSwitches"
+
+// TrafficSwitch describes a traffic switch of a keyspace's writes.
+type TrafficSwitch struct {
+	// Workflow is the name of the workflow switching the traffic.
+	Workflow string `json:"workflow"`
+	// Shards are the source shards whose writes are switched.
+	Shards []string `json:"shards"`
+	// MovesTables is true if tables are switched to another keyspace
+	// (MoveTables), and false if shards are switched to other shards of
+	// the same keyspace (Reshard).
+	MovesTables bool `json:"moves_tables,omitempty"`
+	// Started is when the switch started.
+	Started time.Time `json:"started"`
+	// Done is true once the switch is over, whether or not it succeeded.
+	Done bool `json:"done,omitempty"`
+	// Switched is true if the switch succeeded, and the writes are now
+	// served by the target of the workflow.
+	Switched bool `json:"switched,omitempty"`
+}
+
+// TrafficSwitches maps keyspace names to their last traffic switch.
+type TrafficSwitches map[string]*TrafficSwitch
+
+// WatchTrafficSwitchesData is returned / streamed by WatchTrafficSwitches.
+// The WatchTrafficSwitches API guarantees exactly one of Value or Err will be set.
+type WatchTrafficSwitchesData struct {
+	Value TrafficSwitches
+	Err   error
+}
+
+// GetTrafficSwitches returns the traffic switches of a cell. It returns an
+// empty map if no traffic switch was ever recorded in the cell.
+func (ts *Server) GetTrafficSwitches(ctx context.Context, cell string) (TrafficSwitches, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, _, err := conn.Get(ctx, TrafficSwitchesFile)
+	switch {
+	case err == nil:
+		return unpackTrafficSwitches(contents)
+	case IsErrType(err, NoNode):
+		return TrafficSwitches{}, nil
+	default:
+		return nil, err
+	}
+}
+
+// UpdateTrafficSwitch records the traffic switch of a keyspace in the given
+// cells.
+func (ts *Server) UpdateTrafficSwitch(ctx context.Context, cells []string, keyspace string, sw *TrafficSwitch) error {
+	for _, cell := range cells {
+		if err := ts.updateTrafficSwitches(ctx, cell, func(switches TrafficSwitches) {
+			switches[keyspace] = sw
+		}); err != nil {
+			return vterrors.Wrapf(err, "cannot update traffic switch of keyspace %s in cell %s", keyspace, cell)
+		}
+	}
+	return nil
+}
+
+// updateTrafficSwitches does a read-modify-write of the traffic switches of
+// a cell, retrying if they were concurrently modified.
+func (ts *Server) updateTrafficSwitches(ctx context.Context, cell string, update func(TrafficSwitches)) error {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+
+	for {
+		switches := TrafficSwitches{}
+		contents, version, err := conn.Get(ctx, TrafficSwitchesFile)
+		switch {
+		case err == nil:
+			if switches, err = unpackTrafficSwitches(contents); err != nil {
+				return err
+			}
+		case IsErrType(err, NoNode):
+			version = nil
+		default:
+			return err
+		}
+
+		update(switches)
+
+		contents, err = json.MarshalIndent(switches, "", "  ")
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			_, err = conn.Create(ctx, TrafficSwitchesFile, contents)
+			if !IsErrType(err, NodeExists) {
+				return err
+			}
+			continue
+		}
+		if _, err = conn.Update(ctx, TrafficSwitchesFile, contents, version); !IsErrType(err, BadVersion) {
+			// This includes the 'err=nil' case.
+			return err
+		}
+	}
+}
+
+// WatchTrafficSwitches will set a watch on the TrafficSwitches object of a
+// cell. It has the same contract as Conn.Watch, but it also unpacks the
+// contents into a TrafficSwitches object.
+func (ts *Server) WatchTrafficSwitches(ctx context.Context, cell string) (*WatchTrafficSwitchesData, <-chan *WatchTrafficSwitchesData, CancelFunc) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return &WatchTrafficSwitchesData{Err: err}, nil, nil
+	}
+
+	current, wdChannel, cancel := conn.Watch(ctx, TrafficSwitchesFile)
+	if current.Err != nil {
+		return &WatchTrafficSwitchesData{Err: current.Err}, nil, nil
+	}
+	value, err := unpackTrafficSwitches(current.Contents)
+	if err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return &WatchTrafficSwitchesData{Err: err}, nil, nil
+	}
+
+	changes := make(chan *WatchTrafficSwitchesData, 10)
+
+	// The background routine reads any event from the watch channel,
+	// translates it, and sends it to the caller.
+	// If cancel() is called, the underlying Watch() code will
+	// send an ErrInterrupted and then close the channel. We'll
+	// just propagate that back to our caller.
+	go func() {
+		defer close(changes)
+
+		for wd := range wdChannel {
+			if wd.Err != nil {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchTrafficSwitchesData{Err: wd.Err}
+				return
+			}
+
+			value, err := unpackTrafficSwitches(wd.Contents)
+			if err != nil {
+				cancel()
+				for range wdChannel {
+				}
+				changes <- &WatchTrafficSwitchesData{Err: err}
+				return
+			}
+
+			changes <- &WatchTrafficSwitchesData{Value: value}
+		}
+	}()
+
+	return &WatchTrafficSwitchesData{Value: value}, changes, cancel
+}
+
+func unpackTrafficSwitches(contents []byte) (TrafficSwitches, error) {
+	switches := TrafficSwitches{}
+	if err := json.Unmarshal(contents, &switches); err != nil {
+		return nil, vterrors.Wrapf(err, "bad traffic switches data: %q", contents)
+	}
+	return switches, nil
+}
@@ -32,7 +32,12 @@ import (
 
 // SaveVSchema first validates the VSchema, then saves it.
 // If the VSchema is empty, just remove it.
+// The saved VSchema is also recorded in the keyspace's vschema history.
 func (ts *Server) SaveVSchema(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace) error {
+	return ts.saveVSchema(ctx, keyspace, vschema, "")
+}
+
+func (ts *Server) saveVSchema(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace, summary string) error {
 	err := vindexes.ValidateKeyspace(vschema)
 	if err != nil {
 		return err
@@ -44,18 +49,30 @@ func (ts *Server) SaveVSchema(ctx context.Context, keyspace string, vschema *vsc
 		return err
 	}
 
+	previous, err := ts.GetVSchema(ctx, keyspace)
+	if err != nil && !IsErrType(err, NoNode) {
+		log.Warningf("failed to read previous vschema for keyspace %s: %v", keyspace, err)
+	}
+
 	_, err = ts.globalCell.Update(ctx, nodePath, data, nil)
 	if err != nil {
 		log.Errorf("failed to update vschema for keyspace %s: %v", keyspace, err)
-	} else {
-		log.Infof("successfully updated vschema for keyspace %s: %+v", keyspace, vschema)
+		return err
 	}
-	return err
+	log.Infof("successfully updated vschema for keyspace %s: %+v", keyspace, vschema)
+
+	if err := ts.recordVSchemaVersion(ctx, keyspace, previous, vschema, summary); err != nil {
+		log.Warningf("failed to record vschema history for keyspace %s: %v", keyspace, err)
+	}
+	return nil
 }
 
 // DeleteVSchema delete the keyspace if it exists
 func (ts *Server) DeleteVSchema(ctx context.Context, keyspace string) error {
 	log.Infof("deleting vschema for keyspace %s", keyspace)
+	if err := ts.deleteVSchemaHistory(ctx, keyspace); err != nil {
+		log.Warningf("failed to delete vschema history for keyspace %s: %v", keyspace, err)
+	}
 	nodePath := path.Join(KeyspacesPath, keyspace, VSchemaFile)
 	return ts.globalCell.Delete(ctx, nodePath, nil)
 }
@@ -0,0 +1,335 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// VSchemaHistoryPath is the directory, under a keyspace, that holds the
+	// previous versions of its vschema.
+	VSchemaHistoryPath = "VSchemaHistory"
+	// VSchemaHistoryVersionFile is the file, under a keyspace, that holds the
+	// last version number allocated in its vschema history.
+	VSchemaHistoryVersionFile = "VSchemaHistoryVersion"
+)
+
+var vschemaHistorySize = flag.Int("vschema_history_size", 20, "number of previous vschema versions kept in the topo for each keyspace, 0 disables vschema history")
+
+// VSchemaVersion is a saved version of a keyspace's vschema, along with
+// who saved it, when, and what changed compared to the version before it.
+type VSchemaVersion struct {
+	Version   int64               `json:"version"`
+	Author    string              `json:"author,omitempty"`
+	Timestamp time.Time           `json:"timestamp"`
+	Summary   string              `json:"summary,omitempty"`
+	VSchema   *vschemapb.Keyspace `json:"-"`
+}
+
+// vschemaVersionRecord is how a VSchemaVersion is stored in the topo.
+type vschemaVersionRecord struct {
+	Version   int64     `json:"version"`
+	Author    string    `json:"author,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Summary   string    `json:"summary,omitempty"`
+	VSchema   []byte    `json:"vschema"`
+}
+
+// GetVSchemaHistory returns the saved versions of a keyspace's vschema,
+// oldest first. The VSchema field of the returned versions is not set; use
+// GetVSchemaVersion to read it.
+func (ts *Server) GetVSchemaHistory(ctx context.Context, keyspace string) ([]*VSchemaVersion, error) {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*VSchemaVersion, 0, len(versions))
+	for _, version := range versions {
+		vv, err := ts.GetVSchemaVersion(ctx, keyspace, version)
+		if err != nil {
+			return nil, err
+		}
+		vv.VSchema = nil
+		result = append(result, vv)
+	}
+	return result, nil
+}
+
+// GetVSchemaVersion returns one saved version of a keyspace's vschema.
+func (ts *Server) GetVSchemaVersion(ctx context.Context, keyspace string, version int64) (*VSchemaVersion, error) {
+	data, _, err := ts.globalCell.Get(ctx, vschemaVersionPath(keyspace, version))
+	if err != nil {
+		return nil, err
+	}
+
+	var record vschemaVersionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, vterrors.Wrapf(err, "bad vschema version data: %q", data)
+	}
+	vs := &vschemapb.Keyspace{}
+	if err := proto.Unmarshal(record.VSchema, vs); err != nil {
+		return nil, vterrors.Wrapf(err, "bad vschema data in version %d", version)
+	}
+
+	return &VSchemaVersion{
+		Version:   record.Version,
+		Author:    record.Author,
+		Timestamp: record.Timestamp,
+		Summary:   record.Summary,
+		VSchema:   vs,
+	}, nil
+}
+
+// RollbackVSchema saves a previous version of a keyspace's vschema as its
+// current vschema. The rollback is itself recorded as a new version.
+func (ts *Server) RollbackVSchema(ctx context.Context, keyspace string, version int64) (*vschemapb.Keyspace, error) {
+	vv, err := ts.GetVSchemaVersion(ctx, keyspace, version)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "vschema version %d of keyspace %s does not exist", version, keyspace)
+		}
+		return nil, err
+	}
+
+	if err := ts.saveVSchema(ctx, keyspace, vv.VSchema, fmt.Sprintf("rollback to version %d", version)); err != nil {
+		return nil, err
+	}
+	return vv.VSchema, nil
+}
+
+// deleteVSchemaHistory removes all saved versions of a keyspace's vschema.
+func (ts *Server) deleteVSchemaHistory(ctx context.Context, keyspace string) error {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := ts.globalCell.Delete(ctx, vschemaVersionPath(keyspace, version), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+	if err := ts.globalCell.Delete(ctx, path.Join(KeyspacesPath, keyspace, VSchemaHistoryVersionFile), nil); err != nil && !IsErrType(err, NoNode) {
+		return err
+	}
+	return nil
+}
+
+// recordVSchemaVersion saves a new version of a keyspace's vschema in its
+// history, and prunes the history down to --vschema_history_size versions.
+// If summary is empty, it is computed from the differences with the
+// previous version.
+func (ts *Server) recordVSchemaVersion(ctx context.Context, keyspace string, previous, vs *vschemapb.Keyspace, summary string) error {
+	if *vschemaHistorySize <= 0 {
+		return nil
+	}
+
+	next, err := ts.allocateVSchemaVersion(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	if summary == "" {
+		summary = SummarizeVSchemaChange(previous, vs)
+	}
+	data, err := proto.Marshal(vs)
+	if err != nil {
+		return err
+	}
+	record, err := json.Marshal(&vschemaVersionRecord{
+		Version:   next,
+		Author:    vschemaAuthor(ctx),
+		Timestamp: time.Now().UTC(),
+		Summary:   summary,
+		VSchema:   data,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := ts.globalCell.Create(ctx, vschemaVersionPath(keyspace, next), record); err != nil {
+		return err
+	}
+
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for len(versions) > *vschemaHistorySize {
+		if err := ts.globalCell.Delete(ctx, vschemaVersionPath(keyspace, versions[0]), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// allocateVSchemaVersion returns the next version number of a keyspace's
+// vschema history. The last allocated number is updated with a
+// compare-and-set, so concurrent writers never get the same version.
+func (ts *Server) allocateVSchemaVersion(ctx context.Context, keyspace string) (int64, error) {
+	nodePath := path.Join(KeyspacesPath, keyspace, VSchemaHistoryVersionFile)
+	for {
+		data, version, err := ts.globalCell.Get(ctx, nodePath)
+		if IsErrType(err, NoNode) {
+			if _, err := ts.globalCell.Create(ctx, nodePath, []byte("1")); err != nil {
+				if IsErrType(err, NodeExists) {
+					continue
+				}
+				return 0, err
+			}
+			return 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		last, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return 0, vterrors.Wrapf(err, "bad vschema history version: %q", data)
+		}
+		next := last + 1
+		if _, err := ts.globalCell.Update(ctx, nodePath, []byte(strconv.FormatInt(next, 10)), version); err != nil {
+			if IsErrType(err, BadVersion) {
+				continue
+			}
+			return 0, err
+		}
+		return next, nil
+	}
+}
+
+// listVSchemaVersions returns the version numbers saved for a keyspace, in
+// ascending order.
+func (ts *Server) listVSchemaVersions(ctx context.Context, keyspace string) ([]int64, error) {
+	entries, err := ts.globalCell.ListDir(ctx, path.Join(KeyspacesPath, keyspace, VSchemaHistoryPath), false)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		version, err := strconv.ParseInt(entry.Name, 10, 64)
+		if err != nil {
+			log.Warningf("ignoring unexpected entry %s in vschema history of keyspace %s", entry.Name, keyspace)
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+func vschemaVersionPath(keyspace string, version int64) string {
+	return path.Join(KeyspacesPath, keyspace, VSchemaHistoryPath, strconv.FormatInt(version, 10))
+}
+
+// vschemaAuthor returns the caller that is saving a vschema, if known.
+func vschemaAuthor(ctx context.Context) string {
+	if ef := callerid.EffectiveCallerIDFromContext(ctx); ef.GetPrincipal() != "" {
+		return ef.GetPrincipal()
+	}
+	return callerid.ImmediateCallerIDFromContext(ctx).GetUsername()
+}
+
+// SummarizeVSchemaChange describes the differences between two versions of a
+// keyspace's vschema, e.g. "added tables t1; changed vindexes hash".
+func SummarizeVSchemaChange(previous, vs *vschemapb.Keyspace) string {
+	if previous == nil {
+		return "initial version"
+	}
+
+	var parts []string
+	if previous.Sharded != vs.Sharded {
+		parts = append(parts, fmt.Sprintf("sharded changed to %v", vs.Sharded))
+	}
+
+	tablesBefore := make(map[string]proto.Message, len(previous.Tables))
+	for name, table := range previous.Tables {
+		tablesBefore[name] = table
+	}
+	tablesAfter := make(map[string]proto.Message, len(vs.Tables))
+	for name, table := range vs.Tables {
+		tablesAfter[name] = table
+	}
+	parts = append(parts, summarizeMapChange("tables", tablesBefore, tablesAfter)...)
+
+	vindexesBefore := make(map[string]proto.Message, len(previous.Vindexes))
+	for name, vindex := range previous.Vindexes {
+		vindexesBefore[name] = vindex
+	}
+	vindexesAfter := make(map[string]proto.Message, len(vs.Vindexes))
+	for name, vindex := range vs.Vindexes {
+		vindexesAfter[name] = vindex
+	}
+	parts = append(parts, summarizeMapChange("vindexes", vindexesBefore, vindexesAfter)...)
+
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func summarizeMapChange(what string, before, after map[string]proto.Message) []string {
+	var added, removed, changed []string
+	for name, value := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case !proto.Equal(old, value):
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	var parts []string
+	for _, change := range []struct {
+		verb  string
+		names []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(change.names) > 0 {
+			sort.Strings(change.names)
+			parts = append(parts, fmt.Sprintf("%s %s %s", change.verb, what, strings.Join(change.names, ", ")))
+		}
+	}
+	return parts
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// The kinds of components that record their version in the topo.
+const (
+	ComponentVtctld   = "vtctld"
+	ComponentVtorc    = "vtorc"
+	ComponentVttablet = "vttablet"
+	ComponentVtgate   = "vtgate"
+)
+
+const (
+	// DefaultMaxMajorVersionSkew is the supported difference between the
+	// major versions of the components of a cluster, i.e. a cluster can be
+	// upgraded one major version at a time.
+	DefaultMaxMajorVersionSkew = 1
+	// DefaultComponentVersionStaleAfter is the age after which the version
+	// recorded by a component that did not refresh it is ignored, as the
+	// component likely stopped without removing it.
+	DefaultComponentVersionStaleAfter = time.Hour
+)
+
+// ComponentVersionRefreshInterval is the interval at which the components
+// refresh the version they recorded, see RegisterComponentVersion.
+var ComponentVersionRefreshInterval = 10 * time.Minute
+
+// upgradeTiers are the kinds of components in the order they are upgraded:
+// a component must not run a newer major version than the components of the
+// tiers before its own.
+var upgradeTiers = [][]string{
+	{ComponentVtctld, ComponentVtorc},
+	{ComponentVttablet},
+	{ComponentVtgate},
+}
+
+// VersionSkewReport is the result of CheckVersionSkew.
+type VersionSkewReport struct {
+	// Problems are the version skews that exceed the supported bounds.
+	Problems []string
+	// Warnings are the versions that were not checked, because they cannot
+	// be parsed or are stale.
+	Warnings []string
+}
+
+// componentMajor is a component with the major version it runs.
+type componentMajor struct {
+	*topo.ComponentVersion
+	major int
+}
+
+func (c componentMajor) String() string {
+	return fmt.Sprintf("%s %s running version %s", c.Component, c.ID, c.Version)
+}
+
+// CheckVersionSkew checks that the major versions of the components of a
+// cluster are at most maxMajorSkew apart, and that no component runs a newer
+// major version than a component that must be upgraded before it: the vtctlds
+// and the vtorcs first, then the vttablets, then the vtgates. The versions
+// older than staleAfter, if set, are ignored.
+func CheckVersionSkew(versions []*topo.ComponentVersion, maxMajorSkew int, staleAfter time.Duration, now time.Time) *VersionSkewReport {
+	report := &VersionSkewReport{}
+
+	var components []componentMajor
+	for _, cv := range versions {
+		if staleAfter > 0 {
+			updatedAt, err := time.Parse(time.RFC3339, cv.UpdatedAt)
+			if err == nil && now.Sub(updatedAt) > staleAfter {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("ignoring the version of %s %s, which was last recorded at %s", cv.Component, cv.ID, cv.UpdatedAt))
+				continue
+			}
+		}
+		major, err := parseMajorVersion(cv.Version)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("ignoring the version of %s %s: %v", cv.Component, cv.ID, err))
+			continue
+		}
+		components = append(components, componentMajor{ComponentVersion: cv, major: major})
+	}
+	if len(components) == 0 {
+		return report
+	}
+
+	oldest, newest := components[0], components[0]
+	for _, c := range components[1:] {
+		if c.major < oldest.major {
+			oldest = c
+		}
+		if c.major > newest.major {
+			newest = c
+		}
+	}
+	if newest.major-oldest.major > maxMajorSkew {
+		report.Problems = append(report.Problems, fmt.Sprintf("%v and %v are %d major versions apart, more than the supported %d", oldest, newest, newest.major-oldest.major, maxMajorSkew))
+	}
+
+	// The oldest component of each tier must not be older than the newest
+	// component of the tiers after it.
+	tierOf := map[string]int{}
+	for i, tier := range upgradeTiers {
+		for _, component := range tier {
+			tierOf[component] = i
+		}
+	}
+	for i := range upgradeTiers {
+		var tierOldest *componentMajor
+		for j := range components {
+			if tier, ok := tierOf[components[j].Component]; ok && tier == i && (tierOldest == nil || components[j].major < tierOldest.major) {
+				tierOldest = &components[j]
+			}
+		}
+		if tierOldest == nil {
+			continue
+		}
+		for _, c := range components {
+			if tier, ok := tierOf[c.Component]; ok && tier > i && c.major > tierOldest.major {
+				report.Problems = append(report.Problems, fmt.Sprintf("%v is newer than %v: the %ss must be upgraded before the %ss", c, *tierOldest, tierOldest.Component, c.Component))
+			}
+		}
+	}
+	return report
+}
+
+// parseMajorVersion returns the major version of a Vitess version, e.g. 15
+// for "15.0.0-SNAPSHOT".
+func parseMajorVersion(version string) (int, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q", version)
+	}
+	return n, nil
+}
+
+// RegisterComponentVersion records the version of the running component in
+// the topo, refreshes it every ComponentVersionRefreshInterval, and warns
+// about the version skew of the cluster it joins. The returned function stops
+// the refresh and removes the version, e.g. when the component shuts down.
+func RegisterComponentVersion(ctx context.Context, ts *topo.Server, component, id, cell string) (stop func()) {
+	buildInfo := servenv.AppVersion.ToStringMap()
+	cv := &topo.ComponentVersion{
+		Component:   component,
+		ID:          id,
+		Cell:        cell,
+		Version:     buildInfo["version"],
+		BuildGitRev: buildInfo["build_git_rev"],
+	}
+	save := func() error {
+		cv.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+		defer cancel()
+		return ts.SaveComponentVersion(ctx, cv)
+	}
+
+	if err := save(); err != nil {
+		log.Warningf("Failed to record the version of %s %s in the topo: %v", component, id, err)
+	} else {
+		warnVersionSkew(ctx, ts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ComponentVersionRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := save(); err != nil {
+					log.Warningf("Failed to refresh the version of %s %s in the topo: %v", component, id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+		defer cancel()
+		if err := ts.DeleteComponentVersion(ctx, component, id); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Failed to remove the version of %s %s from the topo: %v", component, id, err)
+		}
+	}
+}
+
+func warnVersionSkew(ctx context.Context, ts *topo.Server) {
+	ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+	versions, err := ts.GetComponentVersions(ctx)
+	if err != nil {
+		log.Warningf("Failed to read the versions of the components to check the version skew: %v", err)
+		return
+	}
+	report := CheckVersionSkew(versions, DefaultMaxMajorVersionSkew, DefaultComponentVersionStaleAfter, time.Now())
+	for _, problem := range report.Problems {
+		log.Warningf("Unsupported version skew in the cluster: %s", problem)
+	}
+}
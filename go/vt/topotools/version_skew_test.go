@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestCheckVersionSkew(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Minute).Format(time.RFC3339)
+	cv := func(component, id, version string) *topo.ComponentVersion {
+		return &topo.ComponentVersion{Component: component, ID: id, Version: version, UpdatedAt: recent}
+	}
+
+	tests := []struct {
+		name         string
+		versions     []*topo.ComponentVersion
+		wantProblems []string
+		wantWarnings []string
+	}{
+		{
+			name: "same versions",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtctld, "vtctld-0:15000", "15.0.0"),
+				cv(ComponentVttablet, "zone1-0000000100", "15.0.0-SNAPSHOT"),
+				cv(ComponentVtgate, "vtgate-0:15001", "15.0.1"),
+			},
+		},
+		{
+			name: "upgrade in progress in the right order",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtctld, "vtctld-0:15000", "16.0.0"),
+				cv(ComponentVtorc, "vtorc-0:3000", "16.0.0"),
+				cv(ComponentVttablet, "zone1-0000000100", "16.0.0"),
+				cv(ComponentVttablet, "zone1-0000000101", "15.0.0"),
+				cv(ComponentVtgate, "vtgate-0:15001", "15.0.0"),
+			},
+		},
+		{
+			name: "too many major versions apart",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtctld, "vtctld-0:15000", "16.0.0"),
+				cv(ComponentVttablet, "zone1-0000000100", "14.0.2"),
+			},
+			wantProblems: []string{
+				"vttablet zone1-0000000100 running version 14.0.2 and vtctld vtctld-0:15000 running version 16.0.0 are 2 major versions apart, more than the supported 1",
+			},
+		},
+		{
+			name: "vtgate upgraded before the vttablets",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtctld, "vtctld-0:15000", "16.0.0"),
+				cv(ComponentVttablet, "zone1-0000000100", "15.0.0"),
+				cv(ComponentVtgate, "vtgate-0:15001", "16.0.0"),
+			},
+			wantProblems: []string{
+				"vtgate vtgate-0:15001 running version 16.0.0 is newer than vttablet zone1-0000000100 running version 15.0.0: the vttablets must be upgraded before the vtgates",
+			},
+		},
+		{
+			name: "vttablet upgraded before vtorc",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtorc, "vtorc-0:3000", "15.0.0"),
+				cv(ComponentVttablet, "zone1-0000000100", "16.0.0"),
+			},
+			wantProblems: []string{
+				"vttablet zone1-0000000100 running version 16.0.0 is newer than vtorc vtorc-0:3000 running version 15.0.0: the vtorcs must be upgraded before the vttablets",
+			},
+		},
+		{
+			name: "stale and unparseable versions are ignored",
+			versions: []*topo.ComponentVersion{
+				cv(ComponentVtctld, "vtctld-0:15000", "15.0.0"),
+				{Component: ComponentVtgate, ID: "vtgate-0:15001", Version: "17.0.0", UpdatedAt: now.Add(-2 * time.Hour).Format(time.RFC3339)},
+				cv(ComponentVttablet, "zone1-0000000100", "unknown"),
+			},
+			wantWarnings: []string{
+				"ignoring the version of vtgate vtgate-0:15001, which was last recorded at 2022-10-01T10:00:00Z",
+				`ignoring the version of vttablet zone1-0000000100: invalid version "unknown"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckVersionSkew(tt.versions, DefaultMaxMajorVersionSkew, time.Hour, now)
+			assert.Equal(t, tt.wantProblems, report.Problems)
+			assert.Equal(t, tt.wantWarnings, report.Warnings)
+		})
+	}
+}
+
+func TestRegisterComponentVersion(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+
+	stop := RegisterComponentVersion(ctx, ts, ComponentVtgate, "vtgate-0:15001", "zone1")
+	versions, err := ts.GetComponentVersions(ctx)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, ComponentVtgate, versions[0].Component)
+	assert.Equal(t, "vtgate-0:15001", versions[0].ID)
+	assert.Equal(t, "zone1", versions[0].Cell)
+	assert.NotEmpty(t, versions[0].Version)
+	assert.NotEmpty(t, versions[0].UpdatedAt)
+
+	stop()
+	versions, err = ts.GetComponentVersions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
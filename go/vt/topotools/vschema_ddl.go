@@ -23,6 +23,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
@@ -201,17 +202,22 @@ func ApplyVSchemaDDL(ksName string, ks *vschemapb.Keyspace, alterVschema *sqlpar
 		}
 		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "vindex %s not defined in table %s.%s", name, ksName, tableName)
 
-	case sqlparser.AddSequenceDDLAction:
-		if ks.Sharded {
-			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "add sequence table: unsupported on sharded keyspace %s", ksName)
-		}
-
+	case sqlparser.AddSequenceDDLAction, sqlparser.CreateSequenceDDLAction:
 		name := alterVschema.Table.Name.String()
-		if _, ok := ks.Tables[name]; ok {
+		if table, ok := ks.Tables[name]; ok {
+			if alterVschema.IfNotExists && table.Type == "sequence" {
+				return ks, nil
+			}
 			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "vschema already contains sequence %s in keyspace %s", name, ksName)
 		}
 
-		ks.Tables[name] = &vschemapb.Table{Type: "sequence"}
+		table := &vschemapb.Table{Type: "sequence"}
+		if ks.Sharded {
+			// The sequence tables of sharded keyspaces interleave the
+			// blocks of values of their shards.
+			table.Columns = []*vschemapb.Column{{Name: "stride", Type: querypb.Type_INT64}}
+		}
+		ks.Tables[name] = table
 
 		return ks, nil
 
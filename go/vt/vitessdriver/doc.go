@@ -78,6 +78,24 @@ contains more details on the VSchema:
 https://github.com/vitessio/vitess/blob/main/doc/V3VindexDesign.md
 
 
+Streaming
+
+Connections opened with OpenForStreaming, or with the Streaming setting of
+Configuration, run their queries with the OLAP workload, and return their rows
+as vtgate streams them. The rows are received when they are read, so a slow
+reader slows down the stream, and closing the rows ends it.
+
+OpenVStream opens a stream of the change events of the keyspaces, with the
+same Configuration as the queries:
+
+  vs, err := vitessdriver.OpenVStream(ctx, config, vgtid, filter, nil)
+  defer vs.Close()
+  for {
+    events, err := vs.Recv()
+    ...
+  }
+
+
 Isolation levels
 
 The Vitess isolation model is different from the one exposed by a traditional database.
@@ -152,6 +152,9 @@ type Configuration struct {
 
 	// Streaming is true when streaming RPCs are used.
 	// Recommended for large results.
+	// The queries of a streaming connection run with the OLAP workload, and
+	// their rows are fetched from vtgate as they are read, so that a slow
+	// reader slows down the stream instead of buffering the whole result.
 	// Default: false
 	Streaming bool
 
@@ -222,11 +225,19 @@ func (c *conn) dial() error {
 		}
 		c.session = c.conn.SessionFromPb(sessionFromToken)
 	} else {
-		c.session = c.conn.Session(c.Target, nil)
+		c.session = c.conn.Session(c.Target, c.executeOptions())
 	}
 	return nil
 }
 
+// executeOptions returns the options of the session of the connection.
+func (c *conn) executeOptions() *querypb.ExecuteOptions {
+	if !c.Streaming {
+		return nil
+	}
+	return &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_OLAP}
+}
+
 func (c *conn) Ping(ctx context.Context) error {
 	if c.Streaming {
 		return errors.New("Ping not allowed for streaming connections")
@@ -464,11 +475,7 @@ func (c *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
 	}
 
 	if c.Streaming {
-		stream, err := c.session.StreamExecute(ctx, query, bindVars)
-		if err != nil {
-			return nil, err
-		}
-		return newStreamingRows(stream, c.convert), nil
+		return c.streamExecute(ctx, query, bindVars)
 	}
 
 	qr, err := c.session.Execute(ctx, query, bindVars)
@@ -490,11 +497,7 @@ func (c *conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 	}
 
 	if c.Streaming {
-		stream, err := c.session.StreamExecute(ctx, query, bv)
-		if err != nil {
-			return nil, err
-		}
-		return newStreamingRows(stream, c.convert), nil
+		return c.streamExecute(ctx, query, bv)
 	}
 
 	qr, err := c.session.Execute(ctx, query, bv)
@@ -504,6 +507,19 @@ func (c *conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 	return newRows(qr, c.convert), nil
 }
 
+// streamExecute starts a streaming query. The stream is canceled when the
+// returned rows are closed, which lets vtgate stop sending the rows that
+// were not read.
+func (c *conn) streamExecute(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable) (driver.Rows, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.session.StreamExecute(ctx, query, bindVars)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return newStreamingRows(stream, cancel, c.convert), nil
+}
+
 type stmt struct {
 	c     *conn
 	query string
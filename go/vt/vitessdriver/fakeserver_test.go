@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -71,7 +72,7 @@ func (f *fakeVTGateService) Execute(ctx context.Context, session *vtgatepb.Sessi
 }
 
 // ExecuteBatch is part of the VTGateService interface
-func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sql []string, bindVariables []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sql []string, bindVariables []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if len(sql) == 1 {
 		execCase, ok := execMap[sql[0]]
 		if !ok {
@@ -105,6 +106,11 @@ func (f *fakeVTGateService) StreamExecute(ctx context.Context, session *vtgatepb
 	if !ok {
 		return fmt.Errorf("no match for: %s", sql)
 	}
+	if session.GetOptions().GetWorkload() != querypb.ExecuteOptions_OLAP {
+		return fmt.Errorf("streaming request without the OLAP workload: %+v", session)
+	}
+	session = proto.Clone(session).(*vtgatepb.Session)
+	session.Options = nil
 	query := &queryExecute{
 		SQL:           sql,
 		BindVariables: bindVariables,
@@ -157,6 +163,16 @@ func (f *fakeVTGateService) CloseSession(ctx context.Context, session *vtgatepb.
 	return nil
 }
 
+// CheckoutSession is part of the VTGateService interface
+func (f *fakeVTGateService) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	return "", nil
+}
+
+// CheckinSession is part of the VTGateService interface
+func (f *fakeVTGateService) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	return nil, nil
+}
+
 // ResolveTransaction is part of the VTGateService interface
 func (f *fakeVTGateService) ResolveTransaction(ctx context.Context, dtid string) error {
 	if dtid != dtid2 {
@@ -165,8 +181,15 @@ func (f *fakeVTGateService) ResolveTransaction(ctx context.Context, dtid string)
 	return nil
 }
 
+// VStream is part of the VTGateService interface
 func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func([]*binlogdatapb.VEvent) error) error {
-	return nil
+	if tabletType != topodatapb.TabletType_RDONLY {
+		return fmt.Errorf("VStream: unexpected tablet type %v", tabletType)
+	}
+	return send([]*binlogdatapb.VEvent{{
+		Type:  binlogdatapb.VEventType_VGTID,
+		Vgtid: vgtid,
+	}})
 }
 
 // HandlePanic is part of the VTGateService interface
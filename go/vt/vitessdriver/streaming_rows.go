@@ -17,6 +17,7 @@ limitations under the License.
 package vitessdriver
 
 import (
+	"context"
 	"database/sql/driver"
 	"errors"
 
@@ -26,9 +27,11 @@ import (
 )
 
 // streamingRows creates a database/sql/driver compliant Row iterator
-// for a streaming query.
+// for a streaming query. The packets of the stream are received only when
+// the rows they contain are read.
 type streamingRows struct {
 	stream  sqltypes.ResultStream
+	cancel  context.CancelFunc
 	failed  error
 	fields  []*querypb.Field
 	qr      *sqltypes.Result
@@ -37,9 +40,11 @@ type streamingRows struct {
 }
 
 // newStreamingRows creates a new streamingRows from stream.
-func newStreamingRows(stream sqltypes.ResultStream, conv *converter) driver.Rows {
+// cancel, if not nil, is called to end the stream when the rows are closed.
+func newStreamingRows(stream sqltypes.ResultStream, cancel context.CancelFunc, conv *converter) driver.Rows {
 	return &streamingRows{
 		stream:  stream,
+		cancel:  cancel,
 		convert: conv,
 	}
 }
@@ -60,6 +65,9 @@ func (ri *streamingRows) Columns() []string {
 }
 
 func (ri *streamingRows) Close() error {
+	if ri.cancel != nil {
+		ri.cancel()
+	}
 	return nil
 }
 
@@ -86,7 +86,7 @@ func TestStreamingRows(t *testing.T) {
 	c <- &packet2
 	c <- &packet3
 	close(c)
-	ri := newStreamingRows(&adapter{c: c, err: io.EOF}, &converter{})
+	ri := newStreamingRows(&adapter{c: c, err: io.EOF}, nil, &converter{})
 	wantCols := []string{
 		"field1",
 		"field2",
@@ -134,7 +134,7 @@ func TestStreamingRowsReversed(t *testing.T) {
 	c <- &packet2
 	c <- &packet3
 	close(c)
-	ri := newStreamingRows(&adapter{c: c, err: io.EOF}, &converter{})
+	ri := newStreamingRows(&adapter{c: c, err: io.EOF}, nil, &converter{})
 	defer ri.Close()
 
 	wantRow := []driver.Value{
@@ -165,7 +165,7 @@ func TestStreamingRowsReversed(t *testing.T) {
 func TestStreamingRowsError(t *testing.T) {
 	c := make(chan *sqltypes.Result)
 	close(c)
-	ri := newStreamingRows(&adapter{c: c, err: errors.New("error before fields")}, &converter{})
+	ri := newStreamingRows(&adapter{c: c, err: errors.New("error before fields")}, nil, &converter{})
 
 	gotCols := ri.Columns()
 	if gotCols != nil {
@@ -182,7 +182,7 @@ func TestStreamingRowsError(t *testing.T) {
 	c = make(chan *sqltypes.Result, 1)
 	c <- &packet1
 	close(c)
-	ri = newStreamingRows(&adapter{c: c, err: errors.New("error after fields")}, &converter{})
+	ri = newStreamingRows(&adapter{c: c, err: errors.New("error after fields")}, nil, &converter{})
 	wantCols := []string{
 		"field1",
 		"field2",
@@ -209,7 +209,7 @@ func TestStreamingRowsError(t *testing.T) {
 	c <- &packet1
 	c <- &packet2
 	close(c)
-	ri = newStreamingRows(&adapter{c: c, err: errors.New("error after rows")}, &converter{})
+	ri = newStreamingRows(&adapter{c: c, err: errors.New("error after rows")}, nil, &converter{})
 	gotRow = make([]driver.Value, 3)
 	err = ri.Next(gotRow)
 	require.NoError(t, err)
@@ -223,7 +223,7 @@ func TestStreamingRowsError(t *testing.T) {
 	c = make(chan *sqltypes.Result, 1)
 	c <- &packet2
 	close(c)
-	ri = newStreamingRows(&adapter{c: c, err: io.EOF}, &converter{})
+	ri = newStreamingRows(&adapter{c: c, err: io.EOF}, nil, &converter{})
 	gotRow = make([]driver.Value, 3)
 	err = ri.Next(gotRow)
 	wantErr = "first packet did not return fields"
@@ -232,3 +232,20 @@ func TestStreamingRowsError(t *testing.T) {
 	}
 	_ = ri.Close()
 }
+
+func TestStreamingRowsClose(t *testing.T) {
+	c := make(chan *sqltypes.Result, 2)
+	c <- &packet1
+	c <- &packet2
+	canceled := false
+	ri := newStreamingRows(&adapter{c: c, err: io.EOF}, func() { canceled = true }, &converter{})
+
+	gotRow := make([]driver.Value, 3)
+	err := ri.Next(gotRow)
+	require.NoError(t, err)
+	require.False(t, canceled)
+
+	// Closing the rows before the end of the stream cancels it.
+	_ = ri.Close()
+	require.True(t, canceled)
+}
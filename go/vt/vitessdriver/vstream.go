@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessdriver
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtgate/grpcvtgateconn"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// VStream is a stream of change events opened with OpenVStream.
+type VStream struct {
+	conn   *vtgateconn.VTGateConn
+	reader vtgateconn.VStreamReader
+	cancel context.CancelFunc
+}
+
+// OpenVStream opens a VStream to the vtgate of a Configuration, so that a
+// service can use the same settings for its queries and its change streams.
+//
+// The events are streamed from the tablets of the type of the Configuration
+// target, primary if it has none, starting at the positions of vgtid. The
+// stream must be closed with Close.
+func OpenVStream(ctx context.Context, c Configuration, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (*VStream, error) {
+	c.setDefaults()
+
+	_, tabletType, _, err := topoproto.ParseDestination(c.Target, topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.GRPCDialOptions) != 0 {
+		vtgateconn.RegisterDialer(c.Protocol, grpcvtgateconn.DialWithOpts(context.TODO(), c.GRPCDialOptions...))
+	}
+	conn, err := vtgateconn.DialProtocol(ctx, c.Protocol, c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	reader, err := conn.VStream(ctx, tabletType, vgtid, filter, flags)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, err
+	}
+	return &VStream{
+		conn:   conn,
+		reader: reader,
+		cancel: cancel,
+	}, nil
+}
+
+// Recv returns the next events of the stream.
+// It returns io.EOF if the stream ended.
+func (vs *VStream) Recv() ([]*binlogdatapb.VEvent, error) {
+	return vs.reader.Recv()
+}
+
+// Close ends the stream and closes its connection to vtgate.
+func (vs *VStream) Close() error {
+	vs.cancel()
+	vs.conn.Close()
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessdriver
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestVStream(t *testing.T) {
+	ctx := context.Background()
+	vgtid := &binlogdatapb.VGtid{
+		ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "ks",
+			Shard:    "-80",
+			Gtid:     "current",
+		}},
+	}
+	config := Configuration{
+		Protocol: "grpc",
+		Address:  testAddress,
+		Target:   "ks@rdonly",
+	}
+
+	vs, err := OpenVStream(ctx, config, vgtid, nil, nil)
+	require.NoError(t, err)
+	defer vs.Close()
+
+	events, err := vs.Recv()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, binlogdatapb.VEventType_VGTID, events[0].Type)
+	assert.True(t, proto.Equal(vgtid, events[0].Vgtid), "got %v, want %v", events[0].Vgtid, vgtid)
+
+	_, err = vs.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// The tablet type comes from the target.
+	config.Target = "ks@replica"
+	vs, err = OpenVStream(ctx, config, vgtid, nil, nil)
+	require.NoError(t, err)
+	defer vs.Close()
+	_, err = vs.Recv()
+	assert.ErrorContains(t, err, "unexpected tablet type REPLICA")
+}
@@ -48,6 +48,24 @@ func GetTablet(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(tablet, err)
 }
 
+// GetTabletVersionInfo implements the http wrapper for
+// /tablet/{tablet}/version[?cluster=[&cluster=]].
+func GetTabletVersionInfo(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+
+	alias, err := vars.GetTabletAlias("tablet")
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	result, err := api.server.GetTabletVersionInfo(ctx, &vtadminpb.GetTabletVersionInfoRequest{
+		Alias:      alias,
+		ClusterIds: r.URL.Query()["cluster"],
+	})
+
+	return NewJSONResponse(result, err)
+}
+
 func DeleteTablet(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := r.Vars()
 
@@ -89,6 +89,10 @@ type VtctldClient struct {
 		Response *vtctldatapb.GetVSchemaResponse
 		Error    error
 	}
+	GetVersionInfoResults map[string]struct {
+		Response *vtctldatapb.GetVersionInfoResponse
+		Error    error
+	}
 	GetWorkflowsResults map[string]struct {
 		Response *vtctldatapb.GetWorkflowsResponse
 		Error    error
@@ -412,6 +416,20 @@ func (fake *VtctldClient) GetVSchema(ctx context.Context, req *vtctldatapb.GetVS
 	return nil, fmt.Errorf("%w: no result set for keyspace %s", assert.AnError, req.Keyspace)
 }
 
+// GetVersionInfo is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) GetVersionInfo(ctx context.Context, req *vtctldatapb.GetVersionInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionInfoResponse, error) {
+	if fake.GetVersionInfoResults == nil {
+		return nil, fmt.Errorf("%w: GetVersionInfoResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(req.TabletAlias)
+	if result, ok := fake.GetVersionInfoResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no result set for %s", assert.AnError, key)
+}
+
 // GetWorkflows is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetWorkflowsResponse, error) {
 	if fake.GetWorkflowsResults == nil {
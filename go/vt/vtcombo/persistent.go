@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcombo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+// This file implements the persistence of the topology of vtcombo, which
+// otherwise lives in memory, so that it can restart with the keyspaces,
+// vschemas and routing rules it had when it stopped. The data of the
+// keyspaces is kept by MySQL.
+//
+// The persistent data directory contains:
+//   - topology.json: the vttest topology, with the keyspaces created at
+//     runtime and the current routing rules.
+//   - vschema/<keyspace>.json: the vschema of each keyspace.
+
+const (
+	persistentTopologyFile = "topology.json"
+	persistentVSchemaDir   = "vschema"
+)
+
+// LoadPersistentTopology reads the topology saved in dir into tpb. It
+// returns false if no topology was saved yet.
+func LoadPersistentTopology(dir string, tpb *vttestpb.VTTestTopology) (bool, error) {
+	data, err := os.ReadFile(path.Join(dir, persistentTopologyFile))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := protojson.Unmarshal(data, tpb); err != nil {
+		return false, fmt.Errorf("cannot parse %v: %v", persistentTopologyFile, err)
+	}
+	return true, nil
+}
+
+// RestorePersistentVSchemas saves the vschemas kept in dir into the topo,
+// and rebuilds the SrvVSchema of the cells.
+func RestorePersistentVSchemas(ctx context.Context, ts *topo.Server, dir string, cells []string) error {
+	files, err := filepath.Glob(path.Join(dir, persistentVSchemaDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var vschema vschemapb.Keyspace
+		if err := protojson.Unmarshal(data, &vschema); err != nil {
+			return fmt.Errorf("cannot parse %v: %v", file, err)
+		}
+		keyspace := strings.TrimSuffix(path.Base(file), ".json")
+		if err := ts.SaveVSchema(ctx, keyspace, &vschema); err != nil {
+			return fmt.Errorf("SaveVSchema(%v) failed: %v", keyspace, err)
+		}
+	}
+	return ts.RebuildSrvVSchema(ctx, cells)
+}
+
+// SavePersistentState saves tpb, with the current routing rules, and the
+// vschemas of its keyspaces into dir.
+func SavePersistentState(ctx context.Context, ts *topo.Server, tpb *vttestpb.VTTestTopology, dir string) error {
+	vschemaDir := path.Join(dir, persistentVSchemaDir)
+	if err := os.MkdirAll(vschemaDir, 0755); err != nil {
+		return err
+	}
+
+	saved := proto.Clone(tpb).(*vttestpb.VTTestTopology)
+	rr, err := ts.GetRoutingRules(ctx)
+	if err != nil {
+		return err
+	}
+	saved.RoutingRules = rr
+
+	// Remove the vschemas of the keyspaces that were deleted.
+	keyspaces := make(map[string]bool)
+	for _, kpb := range tpb.Keyspaces {
+		keyspaces[kpb.Name] = true
+	}
+	files, err := filepath.Glob(path.Join(vschemaDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !keyspaces[strings.TrimSuffix(path.Base(file), ".json")] {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, kpb := range tpb.Keyspaces {
+		vschema, err := ts.GetVSchema(ctx, kpb.Name)
+		if topo.IsErrType(err, topo.NoNode) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := writePersistentFile(path.Join(vschemaDir, kpb.Name+".json"), vschema); err != nil {
+			return err
+		}
+	}
+	return writePersistentFile(path.Join(dir, persistentTopologyFile), saved)
+}
+
+// writePersistentFile writes m to file, replacing it atomically so that a
+// crash does not leave a truncated file behind.
+func writePersistentFile(file string, m proto.Message) error {
+	data, err := protojson.MarshalOptions{Multiline: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// WatchPersistentState calls save every time the SrvVSchema of cell changes,
+// which happens when a vschema or the routing rules change, until ctx is
+// done.
+func WatchPersistentState(ctx context.Context, ts *topo.Server, cell string, save func()) {
+	current, changes, _ := ts.WatchSrvVSchema(ctx, cell)
+	if current.Err != nil {
+		log.Errorf("Cannot watch the SrvVSchema of %v, the vschema changes will only be persisted on shutdown: %v", cell, current.Err)
+		return
+	}
+	for change := range changes {
+		if change.Err != nil {
+			if ctx.Err() == nil {
+				log.Errorf("Stopped watching the SrvVSchema of %v, the vschema changes will only be persisted on shutdown: %v", cell, change.Err)
+			}
+			return
+		}
+		save()
+	}
+}
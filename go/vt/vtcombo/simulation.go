@@ -0,0 +1,441 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcombo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+// This file implements the operations that let the tests of an application
+// simulate, at runtime, the events its clients have to survive in
+// production: tablet failures, reparents and reshardings.
+
+// copyBatchSize is the number of rows inserted by each statement when a
+// resharding copies the rows of a table.
+const copyBatchSize = 100
+
+// findTablet returns the tablet of the given alias.
+func findTablet(alias *topodatapb.TabletAlias) (*comboTablet, error) {
+	t, ok := tabletMap[alias.Uid]
+	if !ok || t.alias.Cell != alias.Cell {
+		return nil, fmt.Errorf("tablet %v not found", topoproto.TabletAliasString(alias))
+	}
+	return t, nil
+}
+
+// shardTablets returns the tablets of a shard, ordered by uid.
+func shardTablets(keyspace, shard string) []*comboTablet {
+	var tablets []*comboTablet
+	for _, t := range tabletMap {
+		if t.keyspace == keyspace && t.shard == shard {
+			tablets = append(tablets, t)
+		}
+	}
+	sort.Slice(tablets, func(i, j int) bool {
+		return tablets[i].uid < tablets[j].uid
+	})
+	return tablets
+}
+
+// setServing changes whether the query service of a tablet serves, keeping
+// its tablet type.
+func setServing(t *comboTablet, serving bool, reason string) error {
+	tablet := t.tm.Tablet()
+	terTime := logutil.ProtoToTime(tablet.PrimaryTermStartTime)
+	return t.qsc.SetServingType(tablet.Type, terTime, serving, reason)
+}
+
+// FailTablet makes a tablet stop serving queries, as if it had failed. Its
+// health stream reports it as not serving, so vtgate stops sending it
+// queries, and the queries it receives fail.
+func FailTablet(alias *topodatapb.TabletAlias) error {
+	t, err := findTablet(alias)
+	if err != nil {
+		return err
+	}
+	return setServing(t, false, "simulated tablet failure")
+}
+
+// RecoverTablet makes a tablet that failed with FailTablet serve queries
+// again.
+func RecoverTablet(alias *topodatapb.TabletAlias) error {
+	t, err := findTablet(alias)
+	if err != nil {
+		return err
+	}
+	return setServing(t, true, "")
+}
+
+// ReparentShard simulates a planned reparent of a shard: it demotes its
+// primary to a replica, and promotes newPrimary, or the first replica of the
+// cell of the primary if newPrimary is nil. As all the tablets of a shard
+// share the same database, no data has to be moved.
+func ReparentShard(ctx context.Context, ts *topo.Server, keyspace, shard string, newPrimary *topodatapb.TabletAlias) error {
+	var oldPrimary, candidate *comboTablet
+	tablets := shardTablets(keyspace, shard)
+	for _, t := range tablets {
+		if t.tm.Tablet().Type == topodatapb.TabletType_PRIMARY {
+			oldPrimary = t
+		}
+	}
+	if oldPrimary == nil {
+		return fmt.Errorf("shard %v/%v has no primary", keyspace, shard)
+	}
+
+	if newPrimary != nil {
+		t, err := findTablet(newPrimary)
+		if err != nil {
+			return err
+		}
+		if t.keyspace != keyspace || t.shard != shard {
+			return fmt.Errorf("tablet %v is not in shard %v/%v", topoproto.TabletAliasString(newPrimary), keyspace, shard)
+		}
+		if t.tm.Tablet().Type != topodatapb.TabletType_REPLICA {
+			return fmt.Errorf("tablet %v is a %v, only a replica can be promoted", topoproto.TabletAliasString(newPrimary), t.tm.Tablet().Type)
+		}
+		candidate = t
+	} else {
+		for _, t := range tablets {
+			if t.alias.Cell == oldPrimary.alias.Cell && t.tm.Tablet().Type == topodatapb.TabletType_REPLICA {
+				candidate = t
+				break
+			}
+		}
+		if candidate == nil {
+			return fmt.Errorf("shard %v/%v has no replica to promote", keyspace, shard)
+		}
+	}
+
+	if err := oldPrimary.tm.ChangeType(ctx, topodatapb.TabletType_REPLICA /* semi-sync */, false); err != nil {
+		return fmt.Errorf("cannot demote %v: %v", topoproto.TabletAliasString(oldPrimary.alias), err)
+	}
+	oldPrimary.tabletType = topodatapb.TabletType_REPLICA
+	if err := candidate.tm.ChangeType(ctx, topodatapb.TabletType_PRIMARY /* semi-sync */, false); err != nil {
+		return fmt.Errorf("cannot promote %v: %v", topoproto.TabletAliasString(candidate.alias), err)
+	}
+	candidate.tabletType = topodatapb.TabletType_PRIMARY
+
+	// The shard sync of the new primary eventually updates the shard record,
+	// but we do it right away so that the reparent is visible on return.
+	_, err := ts.UpdateShardFields(ctx, keyspace, shard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = candidate.alias
+		si.PrimaryTermStartTime = candidate.tm.Tablet().PrimaryTermStartTime
+		return nil
+	})
+	return err
+}
+
+// Reshard simulates the resharding of a keyspace into the given shards. It
+// creates the new shards and their tablets, and copies the rows of the old
+// shards into them according to the vschema of the keyspace, while the
+// primaries of the old shards reject the queries that write. It then makes
+// the new shards serve the keyspace, and deletes the old ones along with
+// their tablets and databases.
+//
+// The rows of the tables that do not have a primary vindex, like reference
+// tables, are copied from the first old shard into every new shard.
+// It returns the next free tablet uid.
+func Reshard(
+	ctx context.Context,
+	ts *topo.Server,
+	tpb *vttestpb.VTTestTopology,
+	mysqld mysqlctl.MysqlDaemon,
+	dbcfgs *dbconfigs.DBConfigs,
+	keyspace string,
+	shards []string,
+	uid uint32,
+) (uint32, error) {
+	var kpb *vttestpb.Keyspace
+	for _, ks := range tpb.Keyspaces {
+		if ks.Name == keyspace {
+			kpb = ks
+		}
+	}
+	if kpb == nil {
+		return 0, fmt.Errorf("keyspace %v not found", keyspace)
+	}
+	if kpb.ServedFrom != "" {
+		return 0, fmt.Errorf("keyspace %v is served from %v and has no shards", keyspace, kpb.ServedFrom)
+	}
+
+	// The new shards must cover the whole keyspace, and not reuse the name
+	// of an old shard, as each shard keeps its database.
+	newShards := make([]*vttestpb.Shard, 0, len(shards))
+	partition := &topodatapb.SrvKeyspace_KeyspacePartition{ServedType: topodatapb.TabletType_PRIMARY}
+	for _, name := range shards {
+		name, kr, err := topo.ValidateShardName(name)
+		if err != nil {
+			return 0, err
+		}
+		for _, spb := range kpb.Shards {
+			if spb.Name == name {
+				return 0, fmt.Errorf("shard %v/%v already exists", keyspace, name)
+			}
+		}
+		newShards = append(newShards, &vttestpb.Shard{Name: name})
+		partition.ShardReferences = append(partition.ShardReferences, &topodatapb.ShardReference{Name: name, KeyRange: kr})
+	}
+	if err := topo.OrderAndCheckPartitions("", &topodatapb.SrvKeyspace{Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{partition}}); err != nil {
+		return 0, err
+	}
+
+	vschema, err := ts.GetVSchema(ctx, keyspace)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		vschema = &vschemapb.Keyspace{}
+	case err != nil:
+		return 0, err
+	}
+	kschema, err := vindexes.BuildKeyspaceSchema(vschema, keyspace)
+	if err != nil {
+		return 0, err
+	}
+	if !kschema.Keyspace.Sharded && len(newShards) > 1 {
+		return 0, fmt.Errorf("keyspace %v is not sharded in its vschema", keyspace)
+	}
+
+	// Reject the writes on the old shards while their rows are copied.
+	oldShards := kpb.Shards
+	var primaries []*comboTablet
+	for _, spb := range oldShards {
+		for _, t := range shardTablets(keyspace, spb.Name) {
+			if t.tm.Tablet().Type == topodatapb.TabletType_PRIMARY {
+				t.qsc.FreezeWrites(time.Time{})
+				primaries = append(primaries, t)
+			}
+		}
+	}
+	cutOver := false
+	defer func() {
+		if !cutOver {
+			for _, t := range primaries {
+				t.qsc.UnfreezeWrites()
+			}
+		}
+	}()
+
+	for _, spb := range newShards {
+		if err := ts.CreateShard(ctx, keyspace, spb.Name); err != nil {
+			return 0, fmt.Errorf("CreateShard(%v:%v) failed: %v", keyspace, spb.Name, err)
+		}
+		uid, err = createShardTablets(ctx, ts, tpb, mysqld, dbcfgs, kpb, spb, true, uid)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	conn, err := mysqld.GetDbaConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err := copyShards(conn, kschema, keyspace, oldShards, newShards, partition.ShardReferences); err != nil {
+		return 0, err
+	}
+
+	// Make the new shards serve the keyspace instead of the old ones.
+	cutOver = true
+	for _, spb := range oldShards {
+		if _, err := ts.UpdateShardFields(ctx, keyspace, spb.Name, func(si *topo.ShardInfo) error {
+			si.IsPrimaryServing = false
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+	}
+	for _, spb := range newShards {
+		if _, err := ts.UpdateShardFields(ctx, keyspace, spb.Name, func(si *topo.ShardInfo) error {
+			si.IsPrimaryServing = true
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := topotools.RebuildKeyspace(ctx, logutil.NewConsoleLogger(), ts, keyspace, tpb.Cells, false); err != nil {
+		return 0, fmt.Errorf("cannot rebuild %v: %v", keyspace, err)
+	}
+
+	for _, spb := range oldShards {
+		for _, t := range shardTablets(keyspace, spb.Name) {
+			if err := deleteTablet(ctx, ts, t); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := conn.ExecuteFetch(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", shardDbName(keyspace, spb)), 1, false); err != nil {
+			return 0, err
+		}
+		if err := ts.DeleteShard(ctx, keyspace, spb.Name); err != nil {
+			return 0, err
+		}
+	}
+	kpb.Shards = newShards
+	return uid, nil
+}
+
+// copyShards copies the tables of the old shards of a keyspace, and their
+// rows, into its new shards.
+func copyShards(conn *dbconnpool.DBConnection, kschema *vindexes.KeyspaceSchema, keyspace string, oldShards, newShards []*vttestpb.Shard, refs []*topodatapb.ShardReference) error {
+	if len(oldShards) == 0 {
+		return nil
+	}
+	qr, err := conn.ExecuteFetch(fmt.Sprintf("select table_name from information_schema.tables where table_schema = %s and table_type = 'BASE TABLE'", sqltypes.EncodeStringSQL(shardDbName(keyspace, oldShards[0]))), 10000, false)
+	if err != nil {
+		return err
+	}
+	for _, row := range qr.Rows {
+		table := row[0].ToString()
+		create, err := conn.ExecuteFetch(fmt.Sprintf("show create table %s.%s", sqlparser.String(sqlparser.NewIdentifierCS(shardDbName(keyspace, oldShards[0]))), sqlparser.String(sqlparser.NewIdentifierCS(table))), 1, false)
+		if err != nil {
+			return err
+		}
+		for _, spb := range newShards {
+			if _, err := conn.ExecuteFetch(fmt.Sprintf("use %s", sqlparser.String(sqlparser.NewIdentifierCS(shardDbName(keyspace, spb)))), 1, false); err != nil {
+				return err
+			}
+			if _, err := conn.ExecuteFetch(create.Rows[0][1].ToString(), 1, false); err != nil {
+				return err
+			}
+		}
+
+		var vindex *vindexes.ColumnVindex
+		if t := kschema.Tables[table]; t != nil && len(t.ColumnVindexes) > 0 {
+			vindex = t.ColumnVindexes[0]
+			if vindex.Vindex.NeedsVCursor() {
+				return fmt.Errorf("the primary vindex %v of table %v cannot be used to reshard", vindex.Name, table)
+			}
+		}
+		sources := oldShards
+		if vindex == nil {
+			sources = oldShards[:1]
+		}
+		for _, spb := range sources {
+			if err := copyRows(conn, keyspace, table, vindex, spb, newShards, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyRows copies the rows of a table of an old shard into the new shards.
+// If the table has no primary vindex, its rows are copied into every new
+// shard.
+func copyRows(conn *dbconnpool.DBConnection, keyspace, table string, vindex *vindexes.ColumnVindex, source *vttestpb.Shard, newShards []*vttestpb.Shard, refs []*topodatapb.ShardReference) error {
+	tableName := sqlparser.String(sqlparser.NewIdentifierCS(table))
+	qr, err := conn.ExecuteFetch(fmt.Sprintf("select * from %s.%s", sqlparser.String(sqlparser.NewIdentifierCS(shardDbName(keyspace, source))), tableName), 1<<30, true)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) == 0 {
+		return nil
+	}
+
+	rowsPerShard := make(map[string][]sqltypes.Row)
+	if vindex == nil {
+		for _, spb := range newShards {
+			rowsPerShard[spb.Name] = qr.Rows
+		}
+	} else {
+		var cols []int
+		for _, col := range vindex.Columns {
+			idx := -1
+			for i, f := range qr.Fields {
+				if col.EqualString(f.Name) {
+					idx = i
+				}
+			}
+			if idx == -1 {
+				return fmt.Errorf("column %v of the primary vindex of table %v not found", col.String(), table)
+			}
+			cols = append(cols, idx)
+		}
+		rowsColValues := make([][]sqltypes.Value, 0, len(qr.Rows))
+		for _, row := range qr.Rows {
+			values := make([]sqltypes.Value, 0, len(cols))
+			for _, idx := range cols {
+				values = append(values, row[idx])
+			}
+			rowsColValues = append(rowsColValues, values)
+		}
+		dests, err := vindexes.Map(vindex.Vindex, nil, rowsColValues)
+		if err != nil {
+			return err
+		}
+		for i, dest := range dests {
+			ksid, ok := dest.(key.DestinationKeyspaceID)
+			if !ok {
+				return fmt.Errorf("row %v of table %v does not map to a keyspace id", qr.Rows[i], table)
+			}
+			for _, ref := range refs {
+				if key.KeyRangeContains(ref.KeyRange, ksid) {
+					rowsPerShard[ref.Name] = append(rowsPerShard[ref.Name], qr.Rows[i])
+					break
+				}
+			}
+		}
+	}
+
+	for _, spb := range newShards {
+		rows := rowsPerShard[spb.Name]
+		for len(rows) > 0 {
+			batch := rows
+			if len(batch) > copyBatchSize {
+				batch = batch[:copyBatchSize]
+			}
+			rows = rows[len(batch):]
+
+			buf := sqlparser.NewTrackedBuffer(nil)
+			buf.Myprintf("insert into %v.%v values ", sqlparser.NewIdentifierCS(shardDbName(keyspace, spb)), sqlparser.NewIdentifierCS(table))
+			for i, row := range batch {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteByte('(')
+				for j, value := range row {
+					if j > 0 {
+						buf.WriteString(", ")
+					}
+					value.EncodeSQL(buf)
+				}
+				buf.WriteByte(')')
+			}
+			if _, err := conn.ExecuteFetch(buf.String(), 0, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -226,14 +226,9 @@ func DeleteKs(
 	mysqld mysqlctl.MysqlDaemon,
 	tpb *vttestpb.VTTestTopology,
 ) error {
-	for key, tablet := range tabletMap {
+	for _, tablet := range tabletMap {
 		if tablet.keyspace == ksName {
-			delete(tabletMap, key)
-			tablet.tm.Stop()
-			tablet.tm.Close()
-			tablet.qsc.SchemaEngine().Close()
-			err := ts.DeleteTablet(ctx, tablet.alias)
-			if err != nil {
+			if err := deleteTablet(ctx, ts, tablet); err != nil {
 				return err
 			}
 		}
@@ -278,6 +273,15 @@ func DeleteKs(
 	return nil
 }
 
+// deleteTablet stops a tablet, and removes it from the map and the topo.
+func deleteTablet(ctx context.Context, ts *topo.Server, tablet *comboTablet) error {
+	delete(tabletMap, tablet.uid)
+	tablet.tm.Stop()
+	tablet.tm.Close()
+	tablet.qsc.SchemaEngine().Close()
+	return ts.DeleteTablet(ctx, tablet.alias)
+}
+
 // CreateKs creates keyspace, shards and tablets with mysql database
 func CreateKs(
 	ctx context.Context,
@@ -322,66 +326,13 @@ func CreateKs(
 
 		// iterate through the shards
 		for _, spb := range kpb.Shards {
-			shard := spb.Name
-			if err := ts.CreateShard(ctx, keyspace, shard); err != nil {
-				return 0, fmt.Errorf("CreateShard(%v:%v) failed: %v", keyspace, shard, err)
+			if err := ts.CreateShard(ctx, keyspace, spb.Name); err != nil {
+				return 0, fmt.Errorf("CreateShard(%v:%v) failed: %v", keyspace, spb.Name, err)
 			}
-
-			for _, cell := range tpb.Cells {
-				dbname := spb.DbNameOverride
-				if dbname == "" {
-					dbname = fmt.Sprintf("vt_%v_%v", keyspace, shard)
-				}
-
-				replicas := int(kpb.ReplicaCount)
-				if replicas == 0 {
-					// 2 replicas in order to ensure the primary cell has a primary and a replica
-					replicas = 2
-				}
-				rdonlys := int(kpb.RdonlyCount)
-				if rdonlys == 0 {
-					rdonlys = 1
-				}
-
-				if ensureDatabase {
-					// Create Database if not exist
-					conn, err := mysqld.GetDbaConnection(context.TODO())
-					if err != nil {
-						return 0, fmt.Errorf("GetConnection failed: %v", err)
-					}
-					defer conn.Close()
-
-					_, err = conn.ExecuteFetch("CREATE DATABASE IF NOT EXISTS `"+dbname+"`", 1, false)
-					if err != nil {
-						return 0, fmt.Errorf("error ensuring database exists: %v", err)
-					}
-
-				}
-				if cell == tpb.Cells[0] {
-					replicas--
-
-					// create the primary
-					if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_PRIMARY, mysqld, dbcfgs.Clone()); err != nil {
-						return 0, err
-					}
-					uid++
-				}
-
-				for i := 0; i < replicas; i++ {
-					// create a replica tablet
-					if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_REPLICA, mysqld, dbcfgs.Clone()); err != nil {
-						return 0, err
-					}
-					uid++
-				}
-
-				for i := 0; i < rdonlys; i++ {
-					// create a rdonly tablet
-					if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_RDONLY, mysqld, dbcfgs.Clone()); err != nil {
-						return 0, err
-					}
-					uid++
-				}
+			var err error
+			uid, err = createShardTablets(ctx, ts, tpb, mysqld, dbcfgs, kpb, spb, ensureDatabase, uid)
+			if err != nil {
+				return 0, err
 			}
 		}
 	}
@@ -412,6 +363,84 @@ func CreateKs(
 	return uid, nil
 }
 
+// shardDbName returns the name of the database of a shard.
+func shardDbName(keyspace string, spb *vttestpb.Shard) string {
+	if spb.DbNameOverride != "" {
+		return spb.DbNameOverride
+	}
+	return fmt.Sprintf("vt_%v_%v", keyspace, spb.Name)
+}
+
+// createShardTablets creates the tablets of a shard in every cell, the first
+// cell having its primary, and returns the next free tablet uid.
+func createShardTablets(
+	ctx context.Context,
+	ts *topo.Server,
+	tpb *vttestpb.VTTestTopology,
+	mysqld mysqlctl.MysqlDaemon,
+	dbcfgs *dbconfigs.DBConfigs,
+	kpb *vttestpb.Keyspace,
+	spb *vttestpb.Shard,
+	ensureDatabase bool,
+	uid uint32,
+) (uint32, error) {
+	keyspace := kpb.Name
+	shard := spb.Name
+	dbname := shardDbName(keyspace, spb)
+	for _, cell := range tpb.Cells {
+		replicas := int(kpb.ReplicaCount)
+		if replicas == 0 {
+			// 2 replicas in order to ensure the primary cell has a primary and a replica
+			replicas = 2
+		}
+		rdonlys := int(kpb.RdonlyCount)
+		if rdonlys == 0 {
+			rdonlys = 1
+		}
+
+		if ensureDatabase {
+			// Create Database if not exist
+			conn, err := mysqld.GetDbaConnection(context.TODO())
+			if err != nil {
+				return 0, fmt.Errorf("GetConnection failed: %v", err)
+			}
+			defer conn.Close()
+
+			_, err = conn.ExecuteFetch("CREATE DATABASE IF NOT EXISTS `"+dbname+"`", 1, false)
+			if err != nil {
+				return 0, fmt.Errorf("error ensuring database exists: %v", err)
+			}
+
+		}
+		if cell == tpb.Cells[0] {
+			replicas--
+
+			// create the primary
+			if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_PRIMARY, mysqld, dbcfgs.Clone()); err != nil {
+				return 0, err
+			}
+			uid++
+		}
+
+		for i := 0; i < replicas; i++ {
+			// create a replica tablet
+			if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_REPLICA, mysqld, dbcfgs.Clone()); err != nil {
+				return 0, err
+			}
+			uid++
+		}
+
+		for i := 0; i < rdonlys; i++ {
+			// create a rdonly tablet
+			if err := CreateTablet(ctx, ts, cell, uid, keyspace, shard, dbname, topodatapb.TabletType_RDONLY, mysqld, dbcfgs.Clone()); err != nil {
+				return 0, err
+			}
+			uid++
+		}
+	}
+	return uid, nil
+}
+
 //
 // TabletConn implementation
 //
@@ -758,6 +787,14 @@ func (itmc *internalTabletManagerClient) GetSchema(
 	return t.tm.GetSchema(ctx, request)
 }
 
+func (itmc *internalTabletManagerClient) GetSchemaVersion(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.GetSchemaVersion(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	t, ok := tabletMap[tablet.Alias.Uid]
 	if !ok {
@@ -766,6 +803,14 @@ func (itmc *internalTabletManagerClient) GetPermissions(ctx context.Context, tab
 	return t.tm.GetPermissions(ctx)
 }
 
+func (itmc *internalTabletManagerClient) GetThrottlerStatus(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.GetThrottlerStatus(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) SetReadOnly(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return fmt.Errorf("not implemented in vtcombo")
 }
@@ -783,6 +828,22 @@ func (itmc *internalTabletManagerClient) ChangeType(ctx context.Context, tablet
 	return nil
 }
 
+func (itmc *internalTabletManagerClient) DrainTablet(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.DrainTablet(ctx, request)
+}
+
+func (itmc *internalTabletManagerClient) FreezeWrites(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.FreezeWrites(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) Sleep(ctx context.Context, tablet *topodatapb.Tablet, duration time.Duration) error {
 	t, ok := tabletMap[tablet.Alias.Uid]
 	if !ok {
@@ -813,6 +874,14 @@ func (itmc *internalTabletManagerClient) RunHealthCheck(ctx context.Context, tab
 	return nil
 }
 
+func (itmc *internalTabletManagerClient) UpdateThrottlerConfig(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.UpdateThrottlerConfig(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) ReloadSchema(ctx context.Context, tablet *topodatapb.Tablet, waitPosition string) error {
 	t, ok := tabletMap[tablet.Alias.Uid]
 	if !ok {
@@ -853,6 +922,14 @@ func (itmc *internalTabletManagerClient) ExecuteFetchAsApp(context.Context, *top
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) ExplainQuery(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
+func (itmc *internalTabletManagerClient) GetHotRows(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
 func (itmc *internalTabletManagerClient) PrimaryStatus(context.Context, *topodatapb.Tablet) (*replicationdatapb.PrimaryStatus, error) {
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
@@ -924,6 +1001,10 @@ func (itmc *internalTabletManagerClient) ReplicationStatus(context.Context, *top
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) GetReplicationLagHistory(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
 func (itmc *internalTabletManagerClient) FullStatus(context.Context, *topodatapb.Tablet) (*replicationdatapb.FullStatus, error) {
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
@@ -0,0 +1,473 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package archive moves the rows of a table that match the predicate of an
+// archival to an archive table, or to the backup storage, in batches.
+//
+// Each batch is selected in the order of the primary key on a shard
+// primary of the table. It is copied to the archive table, routed to its
+// shards with its sharding vindex, or written as a CSV file to the backup
+// storage, in the layout read by ImportData. The copy is then verified, by
+// counting the rows of the batch in the archive table or by reading the
+// file back, and only then are the rows deleted from the table.
+//
+// The rows are copied with REPLACE, so that a batch that was copied but not
+// deleted, e.g. because vtctld restarted, is copied again without
+// duplicates.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/throttler"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/importdata"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// dataFile is the name of the file of each backup, as read by
+	// ImportData.
+	dataFile = "data"
+	// nullValue is the representation of NULL in the CSV files.
+	nullValue = `\N`
+	// backupTimeFormat is the format of the time in the names of the
+	// backups, which sorts them in the order they were written.
+	backupTimeFormat = "20060102-150405.000000000"
+)
+
+// ExecFunc runs a query on the primary of a shard of a keyspace, and
+// returns at most maxRows rows.
+type ExecFunc func(ctx context.Context, keyspace, shard, query string, maxRows int) (*sqltypes.Result, error)
+
+// ProgressFunc is called after each batch with its number of rows. The
+// archival stops if it returns false or an error.
+type ProgressFunc func(rows int) (bool, error)
+
+// Archiver archives the rows of an archival.
+type Archiver struct {
+	keyspace  string
+	archival  *topo.Archival
+	pkColumns []sqlparser.IdentifierCI
+	exec      ExecFunc
+
+	// target and router are the archive table and the router of its rows,
+	// if the rows are archived to a table.
+	target *importdata.Target
+	router *importdata.Router
+	// bs is the backup storage, if the rows are archived to files.
+	bs backupstorage.BackupStorage
+
+	throttler *throttler.Throttler
+	now       func() time.Time
+}
+
+// NewArchiver returns the archiver of an archival of keyspace, whose table
+// has the given primary key columns. target is the archive table, and bs
+// the backup storage if the archival has a target dir. exec runs the
+// queries on the shard primaries of both the table and the archive table.
+// The archiver must be closed.
+func NewArchiver(keyspace string, archival *topo.Archival, pkColumns []string, target *importdata.Target, bs backupstorage.BackupStorage, exec ExecFunc) (*Archiver, error) {
+	if err := archival.Validate(); err != nil {
+		return nil, err
+	}
+	if len(pkColumns) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s has no primary key, which archivals need", archival.Table)
+	}
+
+	a := &Archiver{
+		keyspace: keyspace,
+		archival: archival,
+		exec:     exec,
+		now:      time.Now,
+	}
+	for _, col := range pkColumns {
+		a.pkColumns = append(a.pkColumns, sqlparser.NewIdentifierCI(col))
+	}
+
+	if archival.TargetTable != "" {
+		if target == nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "archival %s has no target table", archival.Name)
+		}
+		r, err := importdata.NewRouter(target)
+		if err != nil {
+			return nil, err
+		}
+		a.target, a.router = target, r
+	} else {
+		if bs == nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "archival %s has no backup storage", archival.Name)
+		}
+		a.bs = bs
+	}
+
+	if archival.MaxTPS > 0 {
+		t, err := throttler.NewThrottler(fmt.Sprintf("Archival-%s.%s", keyspace, archival.Name), "transactions", 1, archival.MaxTPS, throttler.ReplicationLagModuleDisabled)
+		if err != nil {
+			return nil, err
+		}
+		a.throttler = t
+	}
+	return a, nil
+}
+
+// Close releases the resources of the archiver.
+func (a *Archiver) Close() {
+	if a.throttler != nil {
+		a.throttler.Close()
+	}
+}
+
+// Run archives a batch from each of the shards of the table in turn, until
+// no row of any shard matches the predicate, or progress stops it.
+func (a *Archiver) Run(ctx context.Context, shards []string, progress ProgressFunc) error {
+	for {
+		total := 0
+		for _, shard := range shards {
+			n, err := a.ArchiveBatch(ctx, shard)
+			if err != nil {
+				return vterrors.Wrapf(err, "cannot archive the rows of shard %s/%s", a.keyspace, shard)
+			}
+			if n == 0 {
+				continue
+			}
+			total += n
+			ok, err := progress(n)
+			if err != nil || !ok {
+				return err
+			}
+		}
+		if total == 0 {
+			return nil
+		}
+	}
+}
+
+// ArchiveBatch archives the next batch of rows of a shard of the table, and
+// returns its number of rows, which is 0 if no row matches the predicate.
+func (a *Archiver) ArchiveBatch(ctx context.Context, shard string) (int, error) {
+	if err := a.throttle(ctx); err != nil {
+		return 0, err
+	}
+
+	qr, err := a.exec(ctx, a.keyspace, shard, a.selectQuery(), a.archival.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) == 0 {
+		return 0, nil
+	}
+	pkIndexes, err := a.pkIndexes(qr.Fields)
+	if err != nil {
+		return 0, err
+	}
+
+	if a.router != nil {
+		err = a.copyToTable(ctx, qr, pkIndexes)
+	} else {
+		err = a.copyToStorage(ctx, shard, qr)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The predicate is checked again, so that the rows that were changed
+	// since they were copied are kept.
+	deleteQuery := fmt.Sprintf("delete from %s where %s and (%s)", sqlparser.String(sqlparser.NewIdentifierCS(a.archival.Table)), a.pkPredicate(qr.Rows, pkIndexes), a.archival.Where)
+	if _, err := a.exec(ctx, a.keyspace, shard, deleteQuery, 0); err != nil {
+		return 0, vterrors.Wrapf(err, "cannot delete the archived rows")
+	}
+	return len(qr.Rows), nil
+}
+
+func (a *Archiver) throttle(ctx context.Context) error {
+	if a.throttler == nil {
+		return nil
+	}
+	for {
+		backoff := a.throttler.Throttle(0)
+		if backoff == throttler.NotThrottled {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// selectQuery returns the query of the next batch of rows.
+func (a *Archiver) selectQuery() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "select * from %s where (%s) order by ", sqlparser.String(sqlparser.NewIdentifierCS(a.archival.Table)), a.archival.Where)
+	for i, col := range a.pkColumns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(sqlparser.String(col))
+	}
+	fmt.Fprintf(buf, " limit %d", a.archival.BatchSize)
+	return buf.String()
+}
+
+// pkIndexes returns the indexes of the primary key columns in fields.
+func (a *Archiver) pkIndexes(fields []*querypb.Field) ([]int, error) {
+	indexes := make([]int, len(a.pkColumns))
+	for i, col := range a.pkColumns {
+		indexes[i] = -1
+		for j, field := range fields {
+			if col.EqualString(field.Name) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "the rows of table %s have no primary key column %s", a.archival.Table, col.String())
+		}
+	}
+	return indexes, nil
+}
+
+// pkPredicate returns the predicate matching the primary keys of rows.
+func (a *Archiver) pkPredicate(rows []sqltypes.Row, pkIndexes []int) string {
+	buf := &bytes.Buffer{}
+	if len(a.pkColumns) == 1 {
+		buf.WriteString(sqlparser.String(a.pkColumns[0]))
+	} else {
+		buf.WriteByte('(')
+		for i, col := range a.pkColumns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(sqlparser.String(col))
+		}
+		buf.WriteByte(')')
+	}
+	buf.WriteString(" in (")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if len(pkIndexes) > 1 {
+			buf.WriteByte('(')
+		}
+		for j, idx := range pkIndexes {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			row[idx].EncodeSQL(buf)
+		}
+		if len(pkIndexes) > 1 {
+			buf.WriteByte(')')
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// copyToTable copies the rows to the shards of the archive table, and
+// checks that they all are there.
+func (a *Archiver) copyToTable(ctx context.Context, qr *sqltypes.Result, pkIndexes []int) error {
+	columns := make([]string, len(qr.Fields))
+	for i, field := range qr.Fields {
+		columns[i] = field.Name
+	}
+	vindexCol, err := a.router.ColumnIndex(columns)
+	if err != nil {
+		return err
+	}
+
+	byShard := make(map[string][]sqltypes.Row)
+	for _, row := range qr.Rows {
+		shard, err := a.router.Shard(row, vindexCol)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot route row %v to a shard of %s", row, a.target.Keyspace)
+		}
+		byShard[shard] = append(byShard[shard], row)
+	}
+	shards := make([]string, 0, len(byShard))
+	for shard := range byShard {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+
+	table := sqlparser.String(sqlparser.NewIdentifierCS(a.target.Table))
+	for _, shard := range shards {
+		if _, err := a.exec(ctx, a.target.Keyspace, shard, buildReplace(table, columns, byShard[shard]), 0); err != nil {
+			return vterrors.Wrapf(err, "cannot copy the rows to %s/%s", a.target.Keyspace, shard)
+		}
+	}
+
+	var count int64
+	for _, shard := range shards {
+		query := fmt.Sprintf("select count(*) from %s where %s", table, a.pkPredicate(byShard[shard], pkIndexes))
+		qr, err := a.exec(ctx, a.target.Keyspace, shard, query, 1)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot verify the rows copied to %s/%s", a.target.Keyspace, shard)
+		}
+		if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result of %s: %v", query, qr.Rows)
+		}
+		n, err := qr.Rows[0][0].ToInt64()
+		if err != nil {
+			return err
+		}
+		count += n
+	}
+	if count != int64(len(qr.Rows)) {
+		return vterrors.Errorf(vtrpcpb.Code_DATA_LOSS, "the archive table %s.%s holds %d of the %d copied rows", a.target.Keyspace, a.target.Table, count, len(qr.Rows))
+	}
+	return nil
+}
+
+// buildReplace returns the statement replacing rows into table.
+func buildReplace(table string, columns []string, rows []sqltypes.Row) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "replace into %s(", table)
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(col)))
+	}
+	buf.WriteString(") values ")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteByte('(')
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			v.EncodeSQL(buf)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// copyToStorage writes the rows as a CSV file to a new backup of the target
+// dir, and reads it back to check that it holds all of them.
+func (a *Archiver) copyToStorage(ctx context.Context, shard string, qr *sqltypes.Result) error {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	record := make([]string, len(qr.Fields))
+	for i, field := range qr.Fields {
+		record[i] = field.Name
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+	for _, row := range qr.Rows {
+		for i, v := range row {
+			if v.IsNull() {
+				record[i] = nullValue
+			} else {
+				record[i] = v.ToString()
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	dir := a.archival.TargetDir
+	name := fmt.Sprintf("%s_%s", a.now().UTC().Format(backupTimeFormat), shard)
+	bh, err := a.bs.StartBackup(ctx, dir, name)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot write %s/%s", dir, name)
+	}
+	if err := writeFile(ctx, bh, buf.Bytes()); err != nil {
+		if abortErr := bh.AbortBackup(ctx); abortErr != nil {
+			return vterrors.Wrapf(err, "cannot write %s/%s, and cannot abort it: %v", dir, name, abortErr)
+		}
+		return vterrors.Wrapf(err, "cannot write %s/%s", dir, name)
+	}
+
+	count, err := a.countStoredRows(ctx, dir, name)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot verify %s/%s", dir, name)
+	}
+	if count != len(qr.Rows) {
+		return vterrors.Errorf(vtrpcpb.Code_DATA_LOSS, "%s/%s holds %d of the %d written rows", dir, name, count, len(qr.Rows))
+	}
+	return nil
+}
+
+func writeFile(ctx context.Context, bh backupstorage.BackupHandle, data []byte) error {
+	w, err := bh.AddFile(ctx, dataFile, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return bh.EndBackup(ctx)
+}
+
+// countStoredRows returns the number of rows of the file of a backup.
+func (a *Archiver) countStoredRows(ctx context.Context, dir, name string) (int, error) {
+	backups, err := a.bs.ListBackups(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, bh := range backups {
+		if bh.Name() != name {
+			continue
+		}
+		rc, err := bh.ReadFile(ctx, dataFile)
+		if err != nil {
+			return 0, err
+		}
+		defer rc.Close()
+
+		r := csv.NewReader(rc)
+		count := -1 // The header line holds no row.
+		for {
+			_, err := r.Read()
+			if err == io.EOF {
+				return count, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			count++
+		}
+	}
+	return 0, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "the backup is not listed")
+}
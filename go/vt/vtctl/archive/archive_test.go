@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/importdata"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// fakeShards answers the queries of an archiver. The selects of the table
+// return the given batches in turn, and the counts of the archive table
+// return the number of rows of the last replace, less the missing ones.
+type fakeShards struct {
+	batches map[string][]*sqltypes.Result
+	copied  map[string]int64
+	missing map[string]int64
+	queries map[string][]string
+}
+
+func newFakeShards() *fakeShards {
+	return &fakeShards{
+		batches: make(map[string][]*sqltypes.Result),
+		copied:  make(map[string]int64),
+		missing: make(map[string]int64),
+		queries: make(map[string][]string),
+	}
+}
+
+func (f *fakeShards) exec(ctx context.Context, keyspace, shard, query string, maxRows int) (*sqltypes.Result, error) {
+	target := keyspace + "/" + shard
+	f.queries[target] = append(f.queries[target], query)
+	switch {
+	case strings.HasPrefix(query, "select * "):
+		batches := f.batches[target]
+		if len(batches) == 0 {
+			return &sqltypes.Result{}, nil
+		}
+		f.batches[target] = batches[1:]
+		return batches[0], nil
+	case strings.HasPrefix(query, "replace into "):
+		f.copied[target] = int64(strings.Count(query, "), (") + 1)
+	case strings.HasPrefix(query, "select count(*) "):
+		count := f.copied[target] - f.missing[target]
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), sqltypes.NewInt64(count).ToString()), nil
+	}
+	return &sqltypes.Result{}, nil
+}
+
+func newTestTarget(t *testing.T) *importdata.Target {
+	krs, err := key.ParseShardingSpec("-80-")
+	require.NoError(t, err)
+	return &importdata.Target{
+		Keyspace: "archive",
+		Table:    "orders",
+		VSchema: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"orders": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+			},
+		},
+		Shards: map[string]*topodatapb.Shard{
+			"-80": {KeyRange: krs[0]},
+			"80-": {KeyRange: krs[1]},
+		},
+	}
+}
+
+var testFields = sqltypes.MakeTestFields("id|created_at|note", "int64|datetime|varchar")
+
+func TestArchiveToTable(t *testing.T) {
+	archival := &topo.Archival{
+		Name:           "old_orders",
+		Table:          "orders",
+		Where:          "created_at < now() - interval 90 day",
+		TargetKeyspace: "archive",
+		TargetTable:    "orders",
+		BatchSize:      3,
+	}
+	f := newFakeShards()
+	f.batches["ks/0"] = []*sqltypes.Result{
+		sqltypes.MakeTestResult(testFields, "1|2022-01-01 00:00:00|a", "2|2022-01-02 00:00:00|null", "4|2022-01-03 00:00:00|c"),
+		sqltypes.MakeTestResult(testFields, "5|2022-01-04 00:00:00|e"),
+	}
+
+	a, err := NewArchiver("ks", archival, []string{"id"}, newTestTarget(t), nil, f.exec)
+	require.NoError(t, err)
+	defer a.Close()
+
+	var progress []int
+	err = a.Run(context.Background(), []string{"0"}, func(rows int) (bool, error) {
+		progress = append(progress, rows)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 1}, progress)
+	assert.Equal(t, []string{
+		"select * from orders where (created_at < now() - interval 90 day) order by id limit 3",
+		"delete from orders where id in (1, 2, 4) and (created_at < now() - interval 90 day)",
+		"select * from orders where (created_at < now() - interval 90 day) order by id limit 3",
+		"delete from orders where id in (5) and (created_at < now() - interval 90 day)",
+		"select * from orders where (created_at < now() - interval 90 day) order by id limit 3",
+	}, f.queries["ks/0"])
+	assert.Equal(t, []string{
+		"replace into orders(id, created_at, note) values (1, '2022-01-01 00:00:00', 'a'), (2, '2022-01-02 00:00:00', null)",
+		"select count(*) from orders where id in (1, 2)",
+		"replace into orders(id, created_at, note) values (5, '2022-01-04 00:00:00', 'e')",
+		"select count(*) from orders where id in (5)",
+	}, f.queries["archive/-80"])
+	assert.Equal(t, []string{
+		"replace into orders(id, created_at, note) values (4, '2022-01-03 00:00:00', 'c')",
+		"select count(*) from orders where id in (4)",
+	}, f.queries["archive/80-"])
+}
+
+func TestArchiveVerification(t *testing.T) {
+	archival := &topo.Archival{Name: "old_orders", Table: "orders", Where: "id < 10", TargetKeyspace: "archive", TargetTable: "orders", BatchSize: 10}
+	f := newFakeShards()
+	f.batches["ks/-80"] = []*sqltypes.Result{sqltypes.MakeTestResult(testFields, "1|2022-01-01 00:00:00|a", "2|2022-01-02 00:00:00|b")}
+	f.missing["archive/-80"] = 1
+
+	a, err := NewArchiver("ks", archival, []string{"id"}, newTestTarget(t), nil, f.exec)
+	require.NoError(t, err)
+	defer a.Close()
+
+	err = a.Run(context.Background(), []string{"-80", "80-"}, func(rows int) (bool, error) { return true, nil })
+	assert.EqualError(t, err, "cannot archive the rows of shard ks/-80: the archive table archive.orders holds 1 of the 2 copied rows")
+	assert.Equal(t, vtrpcpb.Code_DATA_LOSS, vterrors.Code(err))
+	assert.Len(t, f.queries["ks/-80"], 1)
+	assert.Empty(t, f.queries["ks/80-"])
+}
+
+func TestArchiveToStorage(t *testing.T) {
+	savedRoot := *filebackupstorage.FileBackupStorageRoot
+	t.Cleanup(func() { *filebackupstorage.FileBackupStorageRoot = savedRoot })
+	*filebackupstorage.FileBackupStorageRoot = t.TempDir()
+	bs := backupstorage.BackupStorageMap["file"]
+
+	archival := &topo.Archival{Name: "old_events", Table: "events", Where: "day < '2022-01-01'", TargetDir: "archive/events", BatchSize: 2}
+	f := newFakeShards()
+	fields := sqltypes.MakeTestFields("day|seq|payload", "date|int64|varbinary")
+	f.batches["ks/-80"] = []*sqltypes.Result{sqltypes.MakeTestResult(fields, "2021-12-30|1|a,b", "2021-12-30|2|null")}
+	f.batches["ks/80-"] = []*sqltypes.Result{sqltypes.MakeTestResult(fields, "2021-12-31|1|c")}
+
+	a, err := NewArchiver("ks", archival, []string{"day", "seq"}, nil, bs, f.exec)
+	require.NoError(t, err)
+	defer a.Close()
+	a.now = func() time.Time { return time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC) }
+
+	total := 0
+	err = a.Run(context.Background(), []string{"-80", "80-"}, func(rows int) (bool, error) {
+		total += rows
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "delete from events where (`day`, seq) in (('2021-12-30', 1), ('2021-12-30', 2)) and (day < '2022-01-01')", f.queries["ks/-80"][1])
+	assert.Equal(t, "delete from events where (`day`, seq) in (('2021-12-31', 1)) and (day < '2022-01-01')", f.queries["ks/80-"][1])
+
+	backups, err := bs.ListBackups(context.Background(), "archive/events")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "20220901-120000.000000000_-80", backups[0].Name())
+	assert.Equal(t, "20220901-120000.000000000_80-", backups[1].Name())
+	rc, err := backups[0].ReadFile(context.Background(), dataFile)
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "day,seq,payload\n2021-12-30,1,\"a,b\"\n2021-12-30,2,\\N\n", string(data))
+}
+
+func TestNewArchiver(t *testing.T) {
+	archival := &topo.Archival{Name: "old_orders", Table: "orders", Where: "id < 10", TargetTable: "orders_archive", BatchSize: 10}
+	_, err := NewArchiver("ks", archival, nil, newTestTarget(t), nil, nil)
+	assert.EqualError(t, err, "table orders has no primary key, which archivals need")
+
+	archival.BatchSize = 0
+	_, err = NewArchiver("ks", archival, []string{"id"}, newTestTarget(t), nil, nil)
+	assert.EqualError(t, err, "invalid batch size: 0, expected a positive integer")
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package audit records the commands received by vtctld, with their caller,
+time and result, in an audit log. Only the commands that change the cluster
+are recorded by default, and the SQL literals and passwords of their
+arguments are redacted. The most recent entries are kept in memory, and all
+of them are written in the background to the sinks of the log, e.g. a file,
+syslog or the global topo.
+*/
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// DefaultLimit is the number of entries returned by Log.Recent when the
+// limit is 0.
+const DefaultLimit = 100
+
+// sinkQueueSize is the number of entries waiting to be written to the sinks
+// beyond which the new entries are dropped, rather than slowing down the
+// commands.
+const sinkQueueSize = 1000
+
+var (
+	sinkErrors    = stats.NewCountersWithSingleLabel("AuditLogSinkErrors", "Number of the entries of the vtctld audit log that failed to be written to a sink, by sink", "Sink")
+	droppedWrites = stats.NewCounter("AuditLogDroppedEntries", "Number of the entries of the vtctld audit log that were not written to the sinks because too many were waiting")
+)
+
+// readOnlyPrefixes are the prefixes of the names of the commands that do
+// not change the cluster.
+var readOnlyPrefixes = []string{"Get", "Find", "List", "Show", "Validate", "Ping", "Help", "ShardReplicationPositions", "GenerateShardRanges"}
+
+// IsReadOnly returns whether a command, e.g. GetKeyspace or vtctl.Reshard,
+// does not change the cluster.
+func IsReadOnly(command string) bool {
+	command = strings.TrimPrefix(command, "vtctl.")
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sink receives the entries of an audit log.
+type Sink interface {
+	// Write records an entry.
+	Write(ctx context.Context, entry *vtctldatapb.AuditLogEntry) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Reader is implemented by the sinks that can read their most recent
+// entries back, e.g. because they are shared by all the vtctlds of the
+// cluster.
+type Reader interface {
+	// Read returns the most recent entries of the sink, latest first, and
+	// only the ones of command if it is set.
+	Read(ctx context.Context, limit int, command string) ([]*vtctldatapb.AuditLogEntry, error)
+}
+
+// SinkFactory creates a sink from its argument, the part of its item of
+// --audit_log_sinks after the colon, if any.
+type SinkFactory func(ts *topo.Server, arg string) (Sink, error)
+
+var (
+	sinkFactoriesMu sync.Mutex
+	sinkFactories   = make(map[string]SinkFactory)
+)
+
+// RegisterSink registers a sink by name.
+func RegisterSink(name string, factory SinkFactory) {
+	sinkFactoriesMu.Lock()
+	defer sinkFactoriesMu.Unlock()
+
+	if _, ok := sinkFactories[name]; ok {
+		log.Fatalf("audit log sink named %v already exists", name)
+	}
+	sinkFactories[name] = factory
+}
+
+type namedSink struct {
+	name string
+	Sink
+}
+
+// Log is an audit log. A nil *Log records nothing.
+type Log struct {
+	sinks    []namedSink
+	readOnly bool
+
+	// queue holds the entries waiting to be written to the sinks, pending
+	// counts them, and done is closed once the queue is drained after
+	// Close.
+	queue   chan *vtctldatapb.AuditLogEntry
+	pending sync.WaitGroup
+	done    chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	// recent is a ring of the most recent entries, next is the index of the
+	// next entry in it, and full is set once it wrapped around.
+	recent []*vtctldatapb.AuditLogEntry
+	next   int
+	full   bool
+}
+
+// NewLog returns an audit log that keeps its size most recent entries in
+// memory, and writes all of them to the sinks of a comma-separated list of
+// name[:arg] items. The read-only commands, see IsReadOnly, are only
+// recorded if readOnly is set.
+func NewLog(ts *topo.Server, size int, sinks string, readOnly bool) (*Log, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("the size of the audit log must be positive: %d", size)
+	}
+	l := &Log{recent: make([]*vtctldatapb.AuditLogEntry, size), readOnly: readOnly}
+	for _, item := range strings.Split(sinks, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(item, ":")
+		sinkFactoriesMu.Lock()
+		factory, ok := sinkFactories[name]
+		sinkFactoriesMu.Unlock()
+		if !ok {
+			l.Close()
+			return nil, fmt.Errorf("no audit log sink named %v registered", name)
+		}
+		sink, err := factory(ts, arg)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to create audit log sink %v: %v", item, err)
+		}
+		l.sinks = append(l.sinks, namedSink{name: name, Sink: sink})
+	}
+	if len(l.sinks) > 0 {
+		l.queue = make(chan *vtctldatapb.AuditLogEntry, sinkQueueSize)
+		l.done = make(chan struct{})
+		go l.writeSinks()
+	}
+	return l, nil
+}
+
+// Record adds an entry to the log, unless it is the one of a read-only
+// command and the log does not record them. The server of the entry is set
+// to the address of this vtctld. The entry is written to the sinks in the
+// background, so that they do not slow down the command, and their errors
+// are logged.
+func (l *Log) Record(ctx context.Context, entry *vtctldatapb.AuditLogEntry) {
+	if l == nil || (!l.readOnly && IsReadOnly(entry.Command)) {
+		return
+	}
+	entry.Server = servenv.ListeningURL.Host
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recent[l.next] = entry
+	l.next = (l.next + 1) % len(l.recent)
+	if l.next == 0 {
+		l.full = true
+	}
+
+	if l.queue == nil || l.closed {
+		return
+	}
+	l.pending.Add(1)
+	select {
+	case l.queue <- entry:
+	default:
+		l.pending.Done()
+		droppedWrites.Add(1)
+		log.Warningf("cannot write the audit log entry of %v to the sinks: %d entries are already waiting", entry.Command, sinkQueueSize)
+	}
+}
+
+// writeSinks writes the entries of the queue to the sinks, until it is
+// closed.
+func (l *Log) writeSinks() {
+	defer close(l.done)
+	for entry := range l.queue {
+		for _, sink := range l.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+			if err := sink.Write(ctx, entry); err != nil {
+				sinkErrors.Add(sink.name, 1)
+				log.Warningf("cannot write the audit log entry of %v to sink %v: %v", entry.Command, sink.name, err)
+			}
+			cancel()
+		}
+		l.pending.Done()
+	}
+}
+
+// Recent returns the most recent entries of the log, latest first, and only
+// the ones of command if it is set. They are read from the first sink that
+// implements Reader, if any, or else from memory.
+func (l *Log) Recent(ctx context.Context, limit int, command string) ([]*vtctldatapb.AuditLogEntry, error) {
+	if l == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the audit log is not enabled")
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	for _, sink := range l.sinks {
+		if reader, ok := sink.Sink.(Reader); ok {
+			return reader.Read(ctx, limit, command)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var entries []*vtctldatapb.AuditLogEntry
+	n := l.next
+	if l.full {
+		n = len(l.recent)
+	}
+	for i := 1; i <= n && len(entries) < limit; i++ {
+		entry := l.recent[(l.next-i+len(l.recent))%len(l.recent)]
+		if command == "" || entry.Command == command {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Close writes the entries waiting for the sinks, and closes them.
+func (l *Log) Close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	closed := l.closed
+	l.closed = true
+	l.mu.Unlock()
+	if closed {
+		return
+	}
+	if l.queue != nil {
+		close(l.queue)
+		<-l.done
+	}
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			log.Warningf("cannot close audit log sink %v: %v", sink.name, err)
+		}
+	}
+}
+
+// NewEntry returns the entry of a command that started at start and
+// returned err. The SQL literals and passwords of its arguments are
+// redacted, see RedactSQL.
+func NewEntry(command string, args []string, caller, remoteAddress string, start time.Time, err error) *vtctldatapb.AuditLogEntry {
+	var redacted []string
+	for _, arg := range args {
+		redacted = append(redacted, RedactSQL(arg))
+	}
+	entry := &vtctldatapb.AuditLogEntry{
+		Command:       command,
+		Args:          redacted,
+		Caller:        caller,
+		RemoteAddress: remoteAddress,
+		StartTime:     protoutil.TimeToProto(start),
+		EndTime:       protoutil.TimeToProto(time.Now()),
+		Status:        vterrors.Code(err).String(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+var (
+	defaultLogMu sync.Mutex
+	defaultLog   *Log
+)
+
+// SetDefault sets the audit log of the vtctld servers, see Service.
+func SetDefault(l *Log) {
+	defaultLogMu.Lock()
+	defer defaultLogMu.Unlock()
+	defaultLog = l
+}
+
+// Default returns the audit log of the vtctld servers, nil if it is not
+// enabled.
+func Default() *Log {
+	defaultLogMu.Lock()
+	defer defaultLogMu.Unlock()
+	return defaultLog
+}
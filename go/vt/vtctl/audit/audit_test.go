@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func commands(entries []*vtctldatapb.AuditLogEntry) []string {
+	var commands []string
+	for _, entry := range entries {
+		commands = append(commands, entry.Command)
+	}
+	return commands
+}
+
+func TestLogRecent(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewLog(nil, 3, "", true)
+	require.NoError(t, err)
+
+	entries, err := l.Recent(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for _, command := range []string{"GetKeyspace", "GetTablets", "GetKeyspace", "PingTablet"} {
+		l.Record(ctx, NewEntry(command, nil, "", "", time.Now(), nil))
+	}
+	// The oldest entry is dropped.
+	entries, err = l.Recent(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PingTablet", "GetKeyspace", "GetTablets"}, commands(entries))
+
+	entries, err = l.Recent(ctx, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PingTablet"}, commands(entries))
+
+	entries, err = l.Recent(ctx, 0, "GetKeyspace")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GetKeyspace"}, commands(entries))
+
+	var nilLog *Log
+	nilLog.Record(ctx, NewEntry("GetKeyspace", nil, "", "", time.Now(), nil))
+	_, err = nilLog.Recent(ctx, 0, "")
+	assert.EqualError(t, err, "the audit log is not enabled")
+
+	// Only the commands that change the cluster are recorded by default.
+	l, err = NewLog(nil, 3, "", false)
+	require.NoError(t, err)
+	for _, command := range []string{"GetKeyspace", "vtctl.ListAllTablets", "PlannedReparentShard", "vtctl.Reshard", "ValidateSchemaKeyspace"} {
+		l.Record(ctx, NewEntry(command, nil, "", "", time.Now(), nil))
+	}
+	entries, err = l.Recent(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vtctl.Reshard", "PlannedReparentShard"}, commands(entries))
+
+	_, err = NewLog(nil, 3, "unknown", false)
+	assert.EqualError(t, err, "no audit log sink named unknown registered")
+	_, err = NewLog(nil, 3, "file", false)
+	assert.ErrorContains(t, err, "the file sink needs a path")
+}
+
+func TestNewEntry(t *testing.T) {
+	start := time.Now()
+	entry := NewEntry("GetKeyspace", []string{"ks"}, "alice", "10.0.0.1:1234", start, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "node doesn't exist"))
+	assert.Equal(t, "NOT_FOUND", entry.Status)
+	assert.Equal(t, "node doesn't exist", entry.Error)
+	assert.Equal(t, start.Unix(), entry.StartTime.Seconds)
+	assert.GreaterOrEqual(t, entry.EndTime.Seconds, entry.StartTime.Seconds)
+
+	assert.Equal(t, "OK", NewEntry("GetKeyspace", nil, "", "", start, nil).Status)
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := NewLog(nil, 10, "file:"+path, false)
+	require.NoError(t, err)
+	l.Record(ctx, NewEntry("CreateKeyspace", []string{`{"name":"ks"}`}, "alice", "", time.Now(), nil))
+	l.Record(ctx, NewEntry("vtctl.Reshard", []string{"create"}, "bob", "", time.Now(), nil))
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	entry := &vtctldatapb.AuditLogEntry{}
+	require.NoError(t, protojson.Unmarshal([]byte(lines[1]), entry))
+	assert.Equal(t, "vtctl.Reshard", entry.Command)
+	assert.Equal(t, "bob", entry.Caller)
+}
+
+func TestTopoSink(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	defer ts.Close()
+
+	l, err := NewLog(ts, 1, "topo:audit", false)
+	require.NoError(t, err)
+	defer l.Close()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		l.Record(ctx, NewEntry(fmt.Sprintf("Command%d", i), nil, "", "", start.Add(time.Duration(i)*time.Second), nil))
+	}
+	// The entries are written in the background.
+	l.pending.Wait()
+	// The entries are read from the topo, rather than from memory.
+	entries, err := l.Recent(ctx, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Command2", "Command1", "Command0"}, commands(entries))
+	entries, err = l.Recent(ctx, 0, "Command1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Command1"}, commands(entries))
+
+	// Other vtctlds read the same entries.
+	other, err := NewLog(ts, 1, "topo:audit", false)
+	require.NoError(t, err)
+	defer other.Close()
+	entries, err = other.Recent(ctx, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Command2", "Command1"}, commands(entries))
+
+	// The oldest entries are pruned.
+	defer func(max int) { TopoMaxEntries = max }(TopoMaxEntries)
+	TopoMaxEntries = 5
+	for i := 3; i < 20; i++ {
+		l.Record(ctx, NewEntry(fmt.Sprintf("Command%d", i), nil, "", "", start.Add(time.Duration(i)*time.Second), nil))
+	}
+	l.pending.Wait()
+	require.NoError(t, l.sinks[0].Sink.(*topoSink).prune(ctx))
+	entries, err = l.Recent(ctx, 1000, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, "Command15", entries[4].Command)
+}
+
+type fakeVtctldServer struct {
+	vtctlservicepb.UnimplementedVtctldServer
+}
+
+func (s *fakeVtctldServer) GetKeyspace(ctx context.Context, req *vtctldatapb.GetKeyspaceRequest) (*vtctldatapb.GetKeyspaceResponse, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "keyspace %v not found", req.Keyspace)
+}
+
+type fakeVtctlServer struct {
+	vtctlservicepb.UnimplementedVtctlServer
+}
+
+func (s *fakeVtctlServer) ExecuteVtctlCommand(req *vtctldatapb.ExecuteVtctlCommandRequest, stream vtctlservicepb.Vtctl_ExecuteVtctlCommandServer) error {
+	return nil
+}
+
+// fakeServerStream receives its request.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *vtctldatapb.ExecuteVtctlCommandRequest
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func TestRedactSQL(t *testing.T) {
+	for _, tcase := range []struct {
+		arg, want string
+	}{
+		{arg: "ks.wf", want: "ks.wf"},
+		{arg: "--source_shards", want: "--source_shards"},
+		{arg: "-80", want: "-80"},
+		{arg: "update t set name = 'alice' where id = 1", want: "update t set `name` = :redacted1 where id = :redacted2"},
+		{arg: "--sql=insert into t(id) values (1)", want: "--sql=insert into t(id) values (:redacted1)"},
+		{arg: "delete from t where id = 1;delete from u where id = 2", want: "delete from t where id = :redacted1;delete from u where id = :redacted1"},
+		{arg: "CREATE USER 'bob'@'%' IDENTIFIED BY 'secret'", want: "CREATE USER 'bob'@'%' IDENTIFIED BY '****'"},
+		{arg: `alter user bob identified with mysql_native_password by "secret"`, want: `alter user bob identified with mysql_native_password by '****'`},
+	} {
+		t.Run(tcase.arg, func(t *testing.T) {
+			assert.Equal(t, tcase.want, RedactSQL(tcase.arg))
+		})
+	}
+}
+
+func TestService(t *testing.T) {
+	l, err := NewLog(nil, 10, "", true)
+	require.NoError(t, err)
+	// The username set by the client is not trusted, only the one
+	// authenticated by the interceptors.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", "mallory"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+
+	authenticate := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(servenv.NewAuthenticatedUserContext(ctx, "alice"), req)
+	}
+	reject := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNAUTHENTICATED, "bad password")
+	}
+
+	desc := l.Service(&vtctlservicepb.Vtctld_ServiceDesc)
+	for _, method := range desc.Methods {
+		if method.MethodName != "GetKeyspace" {
+			continue
+		}
+		_, err := method.Handler(&fakeVtctldServer{}, ctx, func(in any) error {
+			in.(*vtctldatapb.GetKeyspaceRequest).Keyspace = "ks"
+			return nil
+		}, authenticate)
+		require.Error(t, err)
+	}
+	for _, method := range desc.Methods {
+		if method.MethodName != "ExecuteFetchAsDBA" {
+			continue
+		}
+		_, err := method.Handler(&fakeVtctldServer{}, ctx, func(in any) error {
+			in.(*vtctldatapb.ExecuteFetchAsDBARequest).Query = "create user bob identified by 'secret'"
+			return nil
+		}, reject)
+		require.Error(t, err)
+	}
+
+	desc = l.Service(&vtctlservicepb.Vtctl_ServiceDesc)
+	require.Len(t, desc.Streams, 1)
+	err = desc.Streams[0].Handler(&fakeVtctlServer{}, &fakeServerStream{
+		ctx: ctx,
+		req: &vtctldatapb.ExecuteVtctlCommandRequest{Args: []string{"Reshard", "--source_shards", "0", "create", "ks.wf"}},
+	})
+	require.NoError(t, err)
+
+	entries, err := l.Recent(context.Background(), 0, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "vtctl.Reshard", entries[0].Command)
+	assert.Equal(t, []string{"--source_shards", "0", "create", "ks.wf"}, entries[0].Args)
+	assert.Equal(t, "OK", entries[0].Status)
+	assert.Empty(t, entries[0].Caller)
+
+	// The password is redacted, and the call that failed to authenticate
+	// has no caller.
+	assert.Equal(t, "ExecuteFetchAsDBA", entries[1].Command)
+	require.Len(t, entries[1].Args, 1)
+	assert.JSONEq(t, `{"query":"create user bob identified by '****'"}`, entries[1].Args[0])
+	assert.Empty(t, entries[1].Caller)
+	assert.Equal(t, "UNAUTHENTICATED", entries[1].Status)
+
+	assert.Equal(t, "GetKeyspace", entries[2].Command)
+	require.Len(t, entries[2].Args, 1)
+	assert.JSONEq(t, `{"keyspace":"ks"}`, entries[2].Args[0])
+	assert.Equal(t, "alice", entries[2].Caller)
+	assert.Equal(t, "10.0.0.1:1234", entries[2].RemoteAddress)
+	assert.Equal(t, "NOT_FOUND", entries[2].Status)
+	assert.Equal(t, "keyspace ks not found", entries[2].Error)
+
+	var nilLog *Log
+	assert.Same(t, &vtctlservicepb.Vtctld_ServiceDesc, nilLog.Service(&vtctlservicepb.Vtctld_ServiceDesc))
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// identifiedBy matches the passwords of the IDENTIFIED BY clauses, e.g. of
+// CREATE USER, which the parser does not support.
+var identifiedBy = regexp.MustCompile(`(?i)(\bidentified\s+(?:with\s+\S+\s+)?(?:by|as)\s+)('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*")`)
+
+// RedactSQL returns an argument of a command with the literals of its SQL
+// statements, if any, replaced by bind variables, and the passwords of its
+// IDENTIFIED BY clauses by '****'. The value of a --flag=value argument is
+// redacted the same way.
+func RedactSQL(arg string) string {
+	if strings.HasPrefix(arg, "-") {
+		if flag, value, ok := strings.Cut(arg, "="); ok {
+			return flag + "=" + RedactSQL(value)
+		}
+		return arg
+	}
+	pieces, err := sqlparser.SplitStatementToPieces(arg)
+	if err != nil || len(pieces) == 0 {
+		pieces = []string{arg}
+	}
+	changed := false
+	for i, piece := range pieces {
+		redacted := piece
+		if sql, err := sqlparser.RedactSQLQuery(piece); err == nil {
+			redacted = sql
+		}
+		redacted = identifiedBy.ReplaceAllString(redacted, "${1}'****'")
+		if redacted != piece {
+			pieces[i] = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return arg
+	}
+	return strings.Join(pieces, ";")
+}
+
+// redactMessage redacts the string fields of a request, and of its nested
+// messages, with RedactSQL.
+func redactMessage(m protoreflect.Message) {
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+	for _, fd := range fields {
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				switch fd.Kind() {
+				case protoreflect.StringKind:
+					list.Set(i, protoreflect.ValueOfString(RedactSQL(list.Get(i).String())))
+				case protoreflect.MessageKind, protoreflect.GroupKind:
+					redactMessage(list.Get(i).Message())
+				}
+			}
+		case fd.IsMap():
+			mv := v.Map()
+			mv.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				switch fd.MapValue().Kind() {
+				case protoreflect.StringKind:
+					mv.Set(k, protoreflect.ValueOfString(RedactSQL(v.String())))
+				case protoreflect.MessageKind, protoreflect.GroupKind:
+					redactMessage(v.Message())
+				}
+				return true
+			})
+		case fd.Kind() == protoreflect.StringKind:
+			m.Set(fd, protoreflect.ValueOfString(RedactSQL(v.String())))
+		case fd.Kind() == protoreflect.MessageKind, fd.Kind() == protoreflect.GroupKind:
+			redactMessage(v.Message())
+		}
+	}
+}
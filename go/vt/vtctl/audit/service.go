@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/servenv"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// Service returns a copy of the description of a gRPC service, whose
+// handlers record the calls to the methods of the service in the log. It
+// returns desc itself if l is nil.
+//
+// The handlers are wrapped, rather than the calls intercepted, so that the
+// audit log only applies to the vtctld services, and does not have to be
+// listed in --grpc_server_interceptors.
+func (l *Log) Service(desc *grpc.ServiceDesc) *grpc.ServiceDesc {
+	if l == nil {
+		return desc
+	}
+	wrapped := *desc
+	wrapped.Methods = make([]grpc.MethodDesc, len(desc.Methods))
+	for i, method := range desc.Methods {
+		name, handler := method.MethodName, method.Handler
+		wrapped.Methods[i] = grpc.MethodDesc{
+			MethodName: name,
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				start := time.Now()
+				var req any
+				// The caller is the one of the context the interceptors,
+				// e.g. the one of --grpc_auth_mode, pass to the method,
+				// rather than the one of the incoming call.
+				callCtx := ctx
+				if interceptor != nil {
+					interceptor = auditedInterceptor(interceptor, &callCtx)
+				}
+				resp, err := handler(srv, ctx, func(in any) error {
+					req = in
+					return dec(in)
+				}, interceptor)
+				l.Record(callCtx, newCallEntry(callCtx, name, req, start, err))
+				return resp, err
+			},
+		}
+	}
+	wrapped.Streams = make([]grpc.StreamDesc, len(desc.Streams))
+	for i, stream := range desc.Streams {
+		name, handler := stream.StreamName, stream.Handler
+		wrapped.Streams[i] = stream
+		wrapped.Streams[i].Handler = func(srv any, stream grpc.ServerStream) error {
+			// The stream handlers run after the interceptors, so the
+			// context of the stream is the authenticated one.
+			start := time.Now()
+			as := &auditedStream{ServerStream: stream}
+			err := handler(srv, as)
+			l.Record(stream.Context(), newCallEntry(stream.Context(), name, as.req, start, err))
+			return err
+		}
+	}
+	return &wrapped
+}
+
+// auditedInterceptor returns an interceptor that calls interceptor, and
+// stores in ctx the context it passes to the method, if it does.
+func auditedInterceptor(interceptor grpc.UnaryServerInterceptor, ctx *context.Context) grpc.UnaryServerInterceptor {
+	return func(outer context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return interceptor(outer, req, info, func(inner context.Context, req any) (any, error) {
+			*ctx = inner
+			return handler(inner, req)
+		})
+	}
+}
+
+// auditedStream records the first message received by a stream, which is
+// the request of the server-streaming methods.
+type auditedStream struct {
+	grpc.ServerStream
+	req any
+}
+
+func (s *auditedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+// newCallEntry returns the entry of a call to a method of a vtctld service.
+// The commands run by ExecuteVtctlCommand are recorded as vtctl.<command>,
+// with their arguments.
+func newCallEntry(ctx context.Context, method string, req any, start time.Time, err error) *vtctldatapb.AuditLogEntry {
+	command := method
+	var args []string
+	switch req := req.(type) {
+	case *vtctldatapb.ExecuteVtctlCommandRequest:
+		if len(req.Args) > 0 {
+			command = "vtctl." + req.Args[0]
+			args = req.Args[1:]
+		}
+	case proto.Message:
+		// The request is redacted before it is marshaled, since the SQL
+		// would not parse once escaped in JSON.
+		req = proto.Clone(req)
+		redactMessage(req.ProtoReflect())
+		if data, err := protojson.Marshal(req); err == nil {
+			args = []string{string(data)}
+		}
+	}
+
+	var remoteAddress string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddress = p.Addr.String()
+	}
+	return NewEntry(command, args, callerFromContext(ctx), remoteAddress, start, err)
+}
+
+// callerFromContext returns the identity of the caller of a gRPC call: the
+// user authenticated by the auth plugin of the server, or else the user of
+// its client certificate. The caller IDs set by the client are not trusted.
+func callerFromContext(ctx context.Context) string {
+	if user, ok := servenv.AuthenticatedUserFromContext(ctx); ok {
+		return user
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			return servenv.CertificateUser(tlsInfo.State.PeerCertificates[0])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/syslog"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// The sinks of this file are registered as:
+//
+//	file:<path>        appends the entries to a file, one JSON object per line
+//	syslog[:<tag>]     sends the entries to the local syslog, in JSON, tagged
+//	                   with <tag>, "vtctld" by default
+//	topo[:<dir>]       writes the entries to files of the directory of the
+//	                   global topo, "audit_log" by default, which are shared
+//	                   by all the vtctlds of the cluster
+
+// TopoMaxEntries is the number of entries the topo sink keeps, the oldest
+// ones are deleted.
+var TopoMaxEntries = 10000
+
+// topoPruneInterval is the time between two deletions of the oldest entries
+// of the topo sink, which list its directory.
+const topoPruneInterval = 10 * time.Minute
+
+func init() {
+	RegisterSink("file", newFileSink)
+	RegisterSink("syslog", newSyslogSink)
+	RegisterSink("topo", newTopoSink)
+}
+
+func marshalEntry(entry *vtctldatapb.AuditLogEntry) ([]byte, error) {
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(entry)
+}
+
+// fileSink appends the entries to a file.
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileSink(ts *topo.Server, arg string) (Sink, error) {
+	if arg == "" {
+		return nil, errors.New("the file sink needs a path, file:<path>")
+	}
+	file, err := os.OpenFile(arg, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) Write(ctx context.Context, entry *vtctldatapb.AuditLogEntry) error {
+	data, err := marshalEntry(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// syslogSink sends the entries to the local syslog.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func newSyslogSink(ts *topo.Server, arg string) (Sink, error) {
+	tag := arg
+	if tag == "" {
+		tag = "vtctld"
+	}
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(ctx context.Context, entry *vtctldatapb.AuditLogEntry) error {
+	data, err := marshalEntry(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Error != "" {
+		return s.w.Warning(string(data))
+	}
+	return s.w.Info(string(data))
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
+
+// topoSink writes each entry to a file of a directory of the global topo,
+// named by its start time, so that the entries of all the vtctlds of the
+// cluster can be read back in order. Its oldest entries are deleted every
+// topoPruneInterval, rather than on write, since that lists the directory.
+type topoSink struct {
+	ts  *topo.Server
+	dir string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newTopoSink(ts *topo.Server, arg string) (Sink, error) {
+	if ts == nil {
+		return nil, errors.New("the topo sink needs a topo server")
+	}
+	dir := arg
+	if dir == "" {
+		dir = "audit_log"
+	}
+	s := &topoSink{ts: ts, dir: dir, stop: make(chan struct{}), done: make(chan struct{})}
+	go s.pruneLoop()
+	return s, nil
+}
+
+// pruneLoop deletes the oldest entries every topoPruneInterval, until the
+// sink is closed.
+func (s *topoSink) pruneLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(topoPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+		if err := s.prune(ctx); err != nil {
+			log.Warningf("cannot delete the oldest entries of the audit log in %v: %v", s.dir, err)
+		}
+		cancel()
+	}
+}
+
+func (s *topoSink) Write(ctx context.Context, entry *vtctldatapb.AuditLogEntry) error {
+	conn, err := s.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	data, err := marshalEntry(entry)
+	if err != nil {
+		return err
+	}
+	// The name sorts by start time, and the server avoids the collisions
+	// between vtctlds.
+	name := fmt.Sprintf("%020d-%s", protoutil.TimeFromProto(entry.StartTime).UnixNano(), strings.ReplaceAll(entry.Server, "/", "_"))
+	_, err = conn.Create(ctx, path.Join(s.dir, name), data)
+	return err
+}
+
+// names returns the names of the entries of the directory, oldest first.
+func (s *topoSink) names(ctx context.Context, conn topo.Conn) ([]string, error) {
+	entries, err := conn.ListDir(ctx, s.dir, false /*full*/)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// prune deletes the oldest entries beyond TopoMaxEntries.
+func (s *topoSink) prune(ctx context.Context) error {
+	conn, err := s.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	names, err := s.names(ctx, conn)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(names)-TopoMaxEntries; i++ {
+		if err := conn.Delete(ctx, path.Join(s.dir, names[i]), nil); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read implements Reader.
+func (s *topoSink) Read(ctx context.Context, limit int, command string) ([]*vtctldatapb.AuditLogEntry, error) {
+	conn, err := s.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.names(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*vtctldatapb.AuditLogEntry
+	for i := len(names) - 1; i >= 0 && len(entries) < limit; i-- {
+		data, _, err := conn.Get(ctx, path.Join(s.dir, names[i]))
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				// It was pruned since the listing.
+				continue
+			}
+			return nil, err
+		}
+		entry := &vtctldatapb.AuditLogEntry{}
+		if err := protojson.Unmarshal(data, entry); err != nil {
+			return nil, fmt.Errorf("bad audit log entry %v: %v", names[i], err)
+		}
+		if command == "" || entry.Command == command {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *topoSink) Close() error {
+	close(s.stop)
+	<-s.done
+	return nil
+}
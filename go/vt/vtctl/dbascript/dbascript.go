@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dbascript runs the SQL scripts submitted by operators as the DBA
+// user on a set of tablets of a keyspace.
+//
+// The tablets are selected by alias, type and tags. Each tablet runs the
+// statements of the script in order, and stops at the first statement that
+// fails. A limited number of tablets run the script at the same time, and
+// the result of each statement is captured for each tablet.
+package dbascript
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ExecFunc runs a statement as the DBA user on a tablet.
+type ExecFunc func(ctx context.Context, tablet *topodatapb.Tablet, query string) (*sqltypes.Result, error)
+
+// StatementResult is the result of a statement of a script on a tablet.
+type StatementResult struct {
+	SQL          string     `json:"sql"`
+	RowsAffected uint64     `json:"rows_affected"`
+	Fields       []string   `json:"fields,omitempty"`
+	Rows         [][]string `json:"rows,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// TabletResult is the output of a script on a tablet.
+type TabletResult struct {
+	Tablet     string             `json:"tablet"`
+	Statements []*StatementResult `json:"statements"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Statements splits a script into its statements.
+func Statements(sql string) ([]string, error) {
+	pieces, err := sqlparser.SplitStatementToPieces(sql)
+	if err != nil {
+		return nil, err
+	}
+	var statements []string
+	for _, piece := range pieces {
+		if piece = strings.TrimSpace(piece); piece != "" {
+			statements = append(statements, piece)
+		}
+	}
+	if len(statements) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the script has no statement")
+	}
+	return statements, nil
+}
+
+// SelectTablets returns the tablets that match the selectors of a script,
+// sorted by alias.
+func SelectTablets(script *topo.DBAScript, tablets []*topodatapb.Tablet) ([]*topodatapb.Tablet, error) {
+	aliases := make(map[string]bool, len(script.TabletAliases))
+	for _, alias := range script.TabletAliases {
+		parsed, err := topoproto.ParseTabletAlias(alias)
+		if err != nil {
+			return nil, err
+		}
+		aliases[topoproto.TabletAliasString(parsed)] = true
+	}
+	types := make(map[topodatapb.TabletType]bool, len(script.TabletTypes))
+	for _, tabletType := range script.TabletTypes {
+		parsed, err := topoproto.ParseTabletType(tabletType)
+		if err != nil {
+			return nil, err
+		}
+		types[parsed] = true
+	}
+
+	var selected []*topodatapb.Tablet
+	for _, tablet := range tablets {
+		if len(aliases) > 0 && !aliases[topoproto.TabletAliasString(tablet.Alias)] {
+			continue
+		}
+		if len(types) > 0 && !types[tablet.Type] {
+			continue
+		}
+		if !hasTags(tablet, script.Tags) {
+			continue
+		}
+		selected = append(selected, tablet)
+	}
+	if len(selected) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tablet matches DBA script %s", script.Name)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return topoproto.TabletAliasString(selected[i].Alias) < topoproto.TabletAliasString(selected[j].Alias)
+	})
+	return selected, nil
+}
+
+func hasTags(tablet *topodatapb.Tablet, tags map[string]string) bool {
+	for key, value := range tags {
+		if tablet.Tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Run runs the statements on the tablets, at most concurrency tablets at a
+// time, and returns the output of each tablet, in the order of the tablets.
+func Run(ctx context.Context, tablets []*topodatapb.Tablet, statements []string, concurrency int, exec ExecFunc) []*TabletResult {
+	results := make([]*TabletResult, len(tablets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, tablet := range tablets {
+		wg.Add(1)
+		go func(i int, tablet *topodatapb.Tablet) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = runOnTablet(ctx, tablet, statements, exec)
+		}(i, tablet)
+	}
+	wg.Wait()
+	return results
+}
+
+func runOnTablet(ctx context.Context, tablet *topodatapb.Tablet, statements []string, exec ExecFunc) *TabletResult {
+	result := &TabletResult{Tablet: topoproto.TabletAliasString(tablet.Alias)}
+	for _, statement := range statements {
+		if err := ctx.Err(); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		qr, err := exec(ctx, tablet, statement)
+		stmtResult := &StatementResult{SQL: statement}
+		result.Statements = append(result.Statements, stmtResult)
+		if err != nil {
+			stmtResult.Error = err.Error()
+			result.Error = fmt.Sprintf("statement %d failed: %v", len(result.Statements), err)
+			return result
+		}
+		stmtResult.RowsAffected = qr.RowsAffected
+		for _, field := range qr.Fields {
+			stmtResult.Fields = append(stmtResult.Fields, field.Name)
+		}
+		for _, row := range qr.Rows {
+			values := make([]string, len(row))
+			for i, value := range row {
+				values[i] = value.ToString()
+			}
+			stmtResult.Rows = append(stmtResult.Rows, values)
+		}
+	}
+	return result
+}
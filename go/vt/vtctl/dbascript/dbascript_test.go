@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbascript
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStatements(t *testing.T) {
+	statements, err := Statements("create table t (id int);\n insert into t values (1), (2) ;\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create table t (id int)", "insert into t values (1), (2)"}, statements)
+
+	_, err = Statements(" ; ")
+	assert.EqualError(t, err, "the script has no statement")
+}
+
+func TestSelectTablets(t *testing.T) {
+	tablets := []*topodatapb.Tablet{{
+		Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+		Type:  topodatapb.TabletType_REPLICA,
+		Tags:  map[string]string{"az": "b"},
+	}, {
+		Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Type:  topodatapb.TabletType_PRIMARY,
+		Tags:  map[string]string{"az": "a"},
+	}, {
+		Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Type:  topodatapb.TabletType_REPLICA,
+		Tags:  map[string]string{"az": "a"},
+	}}
+	tcases := []struct {
+		name    string
+		script  *topo.DBAScript
+		aliases []string
+		err     string
+	}{{
+		name:    "all",
+		script:  &topo.DBAScript{Name: "s"},
+		aliases: []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000102"},
+	}, {
+		name:    "aliases",
+		script:  &topo.DBAScript{Name: "s", TabletAliases: []string{"zone1-102", "zone1-100"}},
+		aliases: []string{"zone1-0000000100", "zone1-0000000102"},
+	}, {
+		name:    "types and tags",
+		script:  &topo.DBAScript{Name: "s", TabletTypes: []string{"replica"}, Tags: map[string]string{"az": "a"}},
+		aliases: []string{"zone1-0000000101"},
+	}, {
+		name:   "no match",
+		script: &topo.DBAScript{Name: "s", TabletTypes: []string{"rdonly"}},
+		err:    "no tablet matches DBA script s",
+	}, {
+		name:   "bad type",
+		script: &topo.DBAScript{Name: "s", TabletTypes: []string{"leader"}},
+		err:    "unknown TabletType leader",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			selected, err := SelectTablets(tcase.script, tablets)
+			if tcase.err != "" {
+				assert.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			var aliases []string
+			for _, tablet := range selected {
+				aliases = append(aliases, topoproto.TabletAliasString(tablet.Alias))
+			}
+			assert.Equal(t, tcase.aliases, aliases)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tablets := []*topodatapb.Tablet{
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 102}},
+	}
+	var (
+		mu              sync.Mutex
+		running, maxRun int
+	)
+	exec := func(ctx context.Context, tablet *topodatapb.Tablet, query string) (*sqltypes.Result, error) {
+		mu.Lock()
+		running++
+		if running > maxRun {
+			maxRun = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		if tablet.Alias.Uid == 101 && query == "delete from t" {
+			return nil, fmt.Errorf("table t is locked")
+		}
+		if query == "select count(*) from t" {
+			return sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "2"), nil
+		}
+		return &sqltypes.Result{RowsAffected: 2}, nil
+	}
+
+	results := Run(context.Background(), tablets, []string{"select count(*) from t", "delete from t", "optimize table t"}, 2, exec)
+	require.Len(t, results, 3)
+	assert.LessOrEqual(t, maxRun, 2)
+
+	assert.Equal(t, "zone1-0000000100", results[0].Tablet)
+	assert.Empty(t, results[0].Error)
+	require.Len(t, results[0].Statements, 3)
+	assert.Equal(t, []string{"count(*)"}, results[0].Statements[0].Fields)
+	assert.Equal(t, [][]string{{"2"}}, results[0].Statements[0].Rows)
+	assert.EqualValues(t, 2, results[0].Statements[1].RowsAffected)
+
+	assert.Equal(t, "zone1-0000000101", results[1].Tablet)
+	assert.Equal(t, "statement 2 failed: table t is locked", results[1].Error)
+	require.Len(t, results[1].Statements, 2)
+	assert.Equal(t, "table t is locked", results[1].Statements[1].Error)
+
+	assert.Empty(t, results[2].Error)
+	assert.Len(t, results[2].Statements, 3)
+}
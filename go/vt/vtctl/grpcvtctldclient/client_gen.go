@@ -47,6 +47,24 @@ func (client *gRPCVtctldClient) AddCellsAlias(ctx context.Context, in *vtctldata
 	return client.c.AddCellsAlias(ctx, in, opts...)
 }
 
+// AnalyzeIndexUsage is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) AnalyzeIndexUsage(ctx context.Context, in *vtctldatapb.AnalyzeIndexUsageRequest, opts ...grpc.CallOption) (*vtctldatapb.AnalyzeIndexUsageResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.AnalyzeIndexUsage(ctx, in, opts...)
+}
+
+// AnalyzeShardDistribution is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) AnalyzeShardDistribution(ctx context.Context, in *vtctldatapb.AnalyzeShardDistributionRequest, opts ...grpc.CallOption) (*vtctldatapb.AnalyzeShardDistributionResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.AnalyzeShardDistribution(ctx, in, opts...)
+}
+
 // ApplyRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ApplyRoutingRules(ctx context.Context, in *vtctldatapb.ApplyRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyRoutingRulesResponse, error) {
 	if client.c == nil {
@@ -74,6 +92,15 @@ func (client *gRPCVtctldClient) ApplyVSchema(ctx context.Context, in *vtctldatap
 	return client.c.ApplyVSchema(ctx, in, opts...)
 }
 
+// ApproveDBAScript is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ApproveDBAScript(ctx context.Context, in *vtctldatapb.ApproveDBAScriptRequest, opts ...grpc.CallOption) (*vtctldatapb.ApproveDBAScriptResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ApproveDBAScript(ctx, in, opts...)
+}
+
 // Backup is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) Backup(ctx context.Context, in *vtctldatapb.BackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupClient, error) {
 	if client.c == nil {
@@ -101,6 +128,15 @@ func (client *gRPCVtctldClient) ChangeTabletType(ctx context.Context, in *vtctld
 	return client.c.ChangeTabletType(ctx, in, opts...)
 }
 
+// CreateArchival is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) CreateArchival(ctx context.Context, in *vtctldatapb.CreateArchivalRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateArchivalResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.CreateArchival(ctx, in, opts...)
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CreateKeyspace(ctx context.Context, in *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	if client.c == nil {
@@ -119,6 +155,15 @@ func (client *gRPCVtctldClient) CreateShard(ctx context.Context, in *vtctldatapb
 	return client.c.CreateShard(ctx, in, opts...)
 }
 
+// DeleteArchival is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DeleteArchival(ctx context.Context, in *vtctldatapb.DeleteArchivalRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteArchivalResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DeleteArchival(ctx, in, opts...)
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) DeleteCellInfo(ctx context.Context, in *vtctldatapb.DeleteCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteCellInfoResponse, error) {
 	if client.c == nil {
@@ -146,6 +191,15 @@ func (client *gRPCVtctldClient) DeleteKeyspace(ctx context.Context, in *vtctldat
 	return client.c.DeleteKeyspace(ctx, in, opts...)
 }
 
+// DeletePartitionPolicy is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DeletePartitionPolicy(ctx context.Context, in *vtctldatapb.DeletePartitionPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.DeletePartitionPolicyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DeletePartitionPolicy(ctx, in, opts...)
+}
+
 // DeleteShards is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) DeleteShards(ctx context.Context, in *vtctldatapb.DeleteShardsRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteShardsResponse, error) {
 	if client.c == nil {
@@ -173,6 +227,15 @@ func (client *gRPCVtctldClient) DeleteTablets(ctx context.Context, in *vtctldata
 	return client.c.DeleteTablets(ctx, in, opts...)
 }
 
+// DrainTablet is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DrainTablet(ctx context.Context, in *vtctldatapb.DrainTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.DrainTabletResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DrainTablet(ctx, in, opts...)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	if client.c == nil {
@@ -182,6 +245,15 @@ func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *
 	return client.c.EmergencyReparentShard(ctx, in, opts...)
 }
 
+// ExecDBA is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExecDBA(ctx context.Context, in *vtctldatapb.ExecDBARequest, opts ...grpc.CallOption) (*vtctldatapb.ExecDBAResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExecDBA(ctx, in, opts...)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	if client.c == nil {
@@ -209,6 +281,15 @@ func (client *gRPCVtctldClient) ExecuteHook(ctx context.Context, in *vtctldatapb
 	return client.c.ExecuteHook(ctx, in, opts...)
 }
 
+// ExplainQuery is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExplainQuery(ctx context.Context, in *vtctldatapb.ExplainQueryRequest, opts ...grpc.CallOption) (*vtctldatapb.ExplainQueryResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExplainQuery(ctx, in, opts...)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	if client.c == nil {
@@ -218,6 +299,24 @@ func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in
 	return client.c.FindAllShardsInKeyspace(ctx, in, opts...)
 }
 
+// GetArchivals is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetArchivals(ctx context.Context, in *vtctldatapb.GetArchivalsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetArchivalsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetArchivals(ctx, in, opts...)
+}
+
+// GetAuditLog is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetAuditLog(ctx context.Context, in *vtctldatapb.GetAuditLogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetAuditLogResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetAuditLog(ctx, in, opts...)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	if client.c == nil {
@@ -254,6 +353,15 @@ func (client *gRPCVtctldClient) GetCellsAliases(ctx context.Context, in *vtctlda
 	return client.c.GetCellsAliases(ctx, in, opts...)
 }
 
+// GetDBAScripts is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetDBAScripts(ctx context.Context, in *vtctldatapb.GetDBAScriptsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDBAScriptsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetDBAScripts(ctx, in, opts...)
+}
+
 // GetKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatapb.GetKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceResponse, error) {
 	if client.c == nil {
@@ -263,6 +371,15 @@ func (client *gRPCVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatapb
 	return client.c.GetKeyspace(ctx, in, opts...)
 }
 
+// GetKeyspaceSettings is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetKeyspaceSettings(ctx context.Context, in *vtctldatapb.GetKeyspaceSettingsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceSettingsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetKeyspaceSettings(ctx, in, opts...)
+}
+
 // GetKeyspaces is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetKeyspaces(ctx context.Context, in *vtctldatapb.GetKeyspacesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspacesResponse, error) {
 	if client.c == nil {
@@ -272,6 +389,24 @@ func (client *gRPCVtctldClient) GetKeyspaces(ctx context.Context, in *vtctldatap
 	return client.c.GetKeyspaces(ctx, in, opts...)
 }
 
+// GetMySQLUserLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetMySQLUserLimits(ctx context.Context, in *vtctldatapb.GetMySQLUserLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetMySQLUserLimitsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetMySQLUserLimits(ctx, in, opts...)
+}
+
+// GetPartitionPolicies is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetPartitionPolicies(ctx context.Context, in *vtctldatapb.GetPartitionPoliciesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPartitionPoliciesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetPartitionPolicies(ctx, in, opts...)
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetPermissions(ctx context.Context, in *vtctldatapb.GetPermissionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPermissionsResponse, error) {
 	if client.c == nil {
@@ -317,6 +452,15 @@ func (client *gRPCVtctldClient) GetSrvKeyspaceNames(ctx context.Context, in *vtc
 	return client.c.GetSrvKeyspaceNames(ctx, in, opts...)
 }
 
+// GetSrvKeyspacePartitions is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSrvKeyspacePartitions(ctx context.Context, in *vtctldatapb.GetSrvKeyspacePartitionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspacePartitionsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSrvKeyspacePartitions(ctx, in, opts...)
+}
+
 // GetSrvKeyspaces is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetSrvKeyspaces(ctx context.Context, in *vtctldatapb.GetSrvKeyspacesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspacesResponse, error) {
 	if client.c == nil {
@@ -344,6 +488,24 @@ func (client *gRPCVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctldat
 	return client.c.GetSrvVSchemas(ctx, in, opts...)
 }
 
+// GetTableSizes is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetTableSizes(ctx context.Context, in *vtctldatapb.GetTableSizesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTableSizesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetTableSizes(ctx, in, opts...)
+}
+
+// GetThrottlerStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetThrottlerStatus(ctx context.Context, in *vtctldatapb.GetThrottlerStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetThrottlerStatusResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetThrottlerStatus(ctx, in, opts...)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	if client.c == nil {
@@ -371,6 +533,15 @@ func (client *gRPCVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVSchema(ctx, in, opts...)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVSchemaHistory(ctx, in, opts...)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	if client.c == nil {
@@ -380,6 +551,15 @@ func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVersion(ctx, in, opts...)
 }
 
+// GetVersionInfo is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVersionInfo(ctx context.Context, in *vtctldatapb.GetVersionInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionInfoResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVersionInfo(ctx, in, opts...)
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetWorkflows(ctx context.Context, in *vtctldatapb.GetWorkflowsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetWorkflowsResponse, error) {
 	if client.c == nil {
@@ -515,6 +695,15 @@ func (client *gRPCVtctldClient) ReparentTablet(ctx context.Context, in *vtctldat
 	return client.c.ReparentTablet(ctx, in, opts...)
 }
 
+// RestoreDroppedTable is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RestoreDroppedTable(ctx context.Context, in *vtctldatapb.RestoreDroppedTableRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreDroppedTableResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RestoreDroppedTable(ctx, in, opts...)
+}
+
 // RestoreFromBackup is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctldatapb.RestoreFromBackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RestoreFromBackupClient, error) {
 	if client.c == nil {
@@ -524,6 +713,24 @@ func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctl
 	return client.c.RestoreFromBackup(ctx, in, opts...)
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RollbackVSchema(ctx, in, opts...)
+}
+
+// RollingRestart is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RollingRestart(ctx context.Context, in *vtctldatapb.RollingRestartRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RollingRestartClient, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RollingRestart(ctx, in, opts...)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	if client.c == nil {
@@ -533,6 +740,15 @@ func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldat
 	return client.c.RunHealthCheck(ctx, in, opts...)
 }
 
+// SetArchivalPaused is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetArchivalPaused(ctx context.Context, in *vtctldatapb.SetArchivalPausedRequest, opts ...grpc.CallOption) (*vtctldatapb.SetArchivalPausedResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetArchivalPaused(ctx, in, opts...)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	if client.c == nil {
@@ -551,6 +767,33 @@ func (client *gRPCVtctldClient) SetKeyspaceServedFrom(ctx context.Context, in *v
 	return client.c.SetKeyspaceServedFrom(ctx, in, opts...)
 }
 
+// SetKeyspaceSettings is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetKeyspaceSettings(ctx context.Context, in *vtctldatapb.SetKeyspaceSettingsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceSettingsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetKeyspaceSettings(ctx, in, opts...)
+}
+
+// SetMySQLUserLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetMySQLUserLimits(ctx context.Context, in *vtctldatapb.SetMySQLUserLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetMySQLUserLimitsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetMySQLUserLimits(ctx, in, opts...)
+}
+
+// SetPartitionPolicy is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetPartitionPolicy(ctx context.Context, in *vtctldatapb.SetPartitionPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetPartitionPolicyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetPartitionPolicy(ctx, in, opts...)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	if client.c == nil {
@@ -560,6 +803,15 @@ func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in
 	return client.c.SetShardIsPrimaryServing(ctx, in, opts...)
 }
 
+// SetShardReadOnly is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetShardReadOnly(ctx context.Context, in *vtctldatapb.SetShardReadOnlyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardReadOnlyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetShardReadOnly(ctx, in, opts...)
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardTabletControl(ctx context.Context, in *vtctldatapb.SetShardTabletControlRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardTabletControlResponse, error) {
 	if client.c == nil {
@@ -569,6 +821,15 @@ func (client *gRPCVtctldClient) SetShardTabletControl(ctx context.Context, in *v
 	return client.c.SetShardTabletControl(ctx, in, opts...)
 }
 
+// SetSrvKeyspacePartition is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetSrvKeyspacePartition(ctx context.Context, in *vtctldatapb.SetSrvKeyspacePartitionRequest, opts ...grpc.CallOption) (*vtctldatapb.SetSrvKeyspacePartitionResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetSrvKeyspacePartition(ctx, in, opts...)
+}
+
 // SetWritable is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetWritable(ctx context.Context, in *vtctldatapb.SetWritableRequest, opts ...grpc.CallOption) (*vtctldatapb.SetWritableResponse, error) {
 	if client.c == nil {
@@ -686,6 +947,24 @@ func (client *gRPCVtctldClient) UpdateCellsAlias(ctx context.Context, in *vtctld
 	return client.c.UpdateCellsAlias(ctx, in, opts...)
 }
 
+// UpdateSequence is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) UpdateSequence(ctx context.Context, in *vtctldatapb.UpdateSequenceRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateSequenceResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.UpdateSequence(ctx, in, opts...)
+}
+
+// UpdateThrottlerConfig is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) UpdateThrottlerConfig(ctx context.Context, in *vtctldatapb.UpdateThrottlerConfigRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateThrottlerConfigResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.UpdateThrottlerConfig(ctx, in, opts...)
+}
+
 // Validate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) Validate(ctx context.Context, in *vtctldatapb.ValidateRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateResponse, error) {
 	if client.c == nil {
@@ -739,3 +1018,48 @@ func (client *gRPCVtctldClient) ValidateVersionKeyspace(ctx context.Context, in
 
 	return client.c.ValidateVersionKeyspace(ctx, in, opts...)
 }
+
+// ValidateVersionSkew is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ValidateVersionSkew(ctx context.Context, in *vtctldatapb.ValidateVersionSkewRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVersionSkewResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ValidateVersionSkew(ctx, in, opts...)
+}
+
+// GetFeatureGates is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetFeatureGates(ctx context.Context, in *vtctldatapb.GetFeatureGatesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFeatureGatesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetFeatureGates(ctx, in, opts...)
+}
+
+// SetFeatureGate is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetFeatureGate(ctx context.Context, in *vtctldatapb.SetFeatureGateRequest, opts ...grpc.CallOption) (*vtctldatapb.SetFeatureGateResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetFeatureGate(ctx, in, opts...)
+}
+
+// GetRuntimeFlags is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetRuntimeFlags(ctx context.Context, in *vtctldatapb.GetRuntimeFlagsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetRuntimeFlagsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetRuntimeFlags(ctx, in, opts...)
+}
+
+// SetRuntimeFlag is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetRuntimeFlag(ctx context.Context, in *vtctldatapb.SetRuntimeFlagRequest, opts ...grpc.CallOption) (*vtctldatapb.SetRuntimeFlagResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetRuntimeFlag(ctx, in, opts...)
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"path/filepath"
 	"sort"
@@ -30,6 +31,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -37,12 +39,17 @@ import (
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/featuregate"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/runtimeflags"
+	"vitess.io/vitess/go/vt/runtimeflags/grpcruntimeflagsclient"
 	"vitess.io/vitess/go/vt/schema"
 
 	"vitess.io/vitess/go/vt/schemamanager"
@@ -53,15 +60,21 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 	"vitess.io/vitess/go/vt/mysqlctl/mysqlctlproto"
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/servenv/grpcversionclient"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/topotools/events"
+	"vitess.io/vitess/go/vt/vtctl/audit"
+	"vitess.io/vitess/go/vt/vtctl/dbascript"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -70,6 +83,7 @@ import (
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	versiondatapb "vitess.io/vitess/go/vt/proto/versiondata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
@@ -78,8 +92,53 @@ import (
 
 const (
 	initShardPrimaryOperation = "InitShardPrimary"
+
+	// defaultMaxIndexSelectivity is the selectivity under which AnalyzeIndexUsage
+	// reports a non-unique index as a low-selectivity index by default.
+	defaultMaxIndexSelectivity = 0.01
+	// maxIndexUsageRows is the maximum number of indexes AnalyzeIndexUsage reads
+	// from a tablet.
+	maxIndexUsageRows = 100000
+
+	// sqlIndexUsage reads the statistics of the indexes of the tables of a
+	// database, and the rows read through them since mysqld started.
+	sqlIndexUsage = `select s.table_name as table_name, s.index_name as index_name, min(s.non_unique) as non_unique,
+	max(s.cardinality) as cardinality, max(t.table_rows) as table_rows, coalesce(max(u.count_read), 0) as count_read,
+	@@global.performance_schema as performance_schema_enabled
+from information_schema.statistics as s
+	join information_schema.tables as t on t.table_schema = s.table_schema and t.table_name = s.table_name
+	left join performance_schema.table_io_waits_summary_by_index_usage as u
+		on u.object_schema = s.table_schema and u.object_name = s.table_name and u.index_name = s.index_name
+where s.table_schema = %s
+group by s.table_name, s.index_name`
+
+	// defaultShardDistributionSampleSize is the number of rows that
+	// AnalyzeShardDistribution samples from each table on each shard by default.
+	defaultShardDistributionSampleSize = 10000
+
+	// sqlTableStats reads the estimated number of rows and size of tables of
+	// a database.
+	sqlTableStats = `select table_name as table_name, table_rows as table_rows, data_length + index_length as table_size
+from information_schema.tables
+where table_schema = %s and table_type = 'BASE TABLE' and table_name in (%s)`
+
+	// sqlDroppedTable reads the table that the last completed Online DDL drop
+	// of a table, optionally of a given migration, renamed it to, and whether
+	// the dropped table and the renamed table exist.
+	sqlDroppedTable = `select m.migration_uuid as migration_uuid, trim(both ',' from m.artifacts) as hold_table,
+	(select count(*) from information_schema.tables where table_schema = m.mysql_schema and table_name = m.mysql_table) as table_exists,
+	(select count(*) from information_schema.tables where table_schema = m.mysql_schema and table_name = trim(both ',' from m.artifacts)) as hold_table_exists
+from _vt.schema_migrations as m
+where m.mysql_schema = %s and m.mysql_table = %s and m.ddl_action = 'drop' and m.migration_status = 'complete'
+	and (%s = '' or m.migration_uuid = %s)
+order by m.id desc
+limit 1`
 )
 
+// drainTabletPollInterval is how often DrainTablet checks whether the queries
+// and transactions in flight on a tablet finished.
+var drainTabletPollInterval = time.Second
+
 // VtctldServer implements the Vtctld RPC service protocol.
 type VtctldServer struct {
 	vtctlservicepb.UnimplementedVtctldServer
@@ -140,6 +199,190 @@ func (s *VtctldServer) AddCellsAlias(ctx context.Context, req *vtctldatapb.AddCe
 	return &vtctldatapb.AddCellsAliasResponse{}, nil
 }
 
+// AnalyzeIndexUsage is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) AnalyzeIndexUsage(ctx context.Context, req *vtctldatapb.AnalyzeIndexUsageRequest) (*vtctldatapb.AnalyzeIndexUsageResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.AnalyzeIndexUsage")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("max_selectivity", req.MaxSelectivity)
+
+	if req.Keyspace == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace must be non-empty")
+	}
+	maxSelectivity := req.MaxSelectivity
+	if maxSelectivity == 0 {
+		maxSelectivity = defaultMaxIndexSelectivity
+	}
+	if maxSelectivity < 0 || maxSelectivity > 1 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "max selectivity must be between 0 and 1, got %v", req.MaxSelectivity)
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, fmt.Errorf("GetShardNames(%v) failed: %w", req.Keyspace, err)
+	}
+
+	var (
+		m       sync.Mutex
+		wg      sync.WaitGroup
+		indexes = map[string]*vtctldatapb.IndexUsage{}
+		errs    = map[string]string{}
+	)
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, req.Keyspace, shard)
+		if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+			return nil, fmt.Errorf("GetTabletMapForShard(%v, %v) failed: %w", req.Keyspace, shard, err)
+		}
+
+		for alias, ti := range tabletMap {
+			wg.Add(1)
+			go func(alias string, tablet *topodatapb.Tablet) {
+				defer wg.Done()
+
+				usages, err := s.tabletIndexUsage(ctx, tablet)
+
+				m.Lock()
+				defer m.Unlock()
+				if err != nil {
+					errs[alias] = err.Error()
+					return
+				}
+				mergeIndexUsage(indexes, usages, tablet.Type == topodatapb.TabletType_PRIMARY)
+			}(alias, ti.Tablet)
+		}
+	}
+	wg.Wait()
+
+	resp := &vtctldatapb.AnalyzeIndexUsageResponse{
+		Indexes: make([]*vtctldatapb.IndexUsage, 0, len(indexes)),
+		Errors:  errs,
+	}
+	for _, usage := range indexes {
+		if usage.TableRows > 0 {
+			usage.Selectivity = float64(usage.Cardinality) / float64(usage.TableRows)
+		}
+		usage.Unused = usage.Reads == 0 && usage.Index != "PRIMARY"
+		usage.LowSelectivity = !usage.Unique && usage.TableRows > 0 && usage.Selectivity < maxSelectivity
+		resp.Indexes = append(resp.Indexes, usage)
+	}
+	sort.Slice(resp.Indexes, func(i, j int) bool {
+		if resp.Indexes[i].Table != resp.Indexes[j].Table {
+			return resp.Indexes[i].Table < resp.Indexes[j].Table
+		}
+		return resp.Indexes[i].Index < resp.Indexes[j].Index
+	})
+
+	return resp, nil
+}
+
+// AnalyzeShardDistribution is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) AnalyzeShardDistribution(ctx context.Context, req *vtctldatapb.AnalyzeShardDistributionRequest) (*vtctldatapb.AnalyzeShardDistributionResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.AnalyzeShardDistribution")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("proposed_shards", req.ProposedShards)
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+	span.Annotate("sample_size", req.SampleSize)
+
+	if req.Keyspace == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace must be non-empty")
+	}
+	if req.ProposedShards <= 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "proposed shards must be positive, got %d", req.ProposedShards)
+	}
+	sampleSize := req.SampleSize
+	if sampleSize == 0 {
+		sampleSize = defaultShardDistributionSampleSize
+	}
+	if sampleSize < 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "sample size must be positive, got %d", req.SampleSize)
+	}
+
+	names, err := key.GenerateShardRanges(int(req.ProposedShards))
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "invalid proposed shards")
+	}
+	keyRanges := make([]*topodatapb.KeyRange, len(names))
+	for i, name := range names {
+		start, end, _ := strings.Cut(name, "-")
+		if keyRanges[i], err = key.ParseKeyRangeParts(start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	primaryVindexes, err := s.shardDistributionVindexes(ctx, req.Keyspace, req.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, fmt.Errorf("GetShardNames(%v) failed: %w", req.Keyspace, err)
+	}
+
+	var (
+		m    sync.Mutex
+		wg   sync.WaitGroup
+		rows = make([]float64, len(names))
+		size = make([]float64, len(names))
+		resp = &vtctldatapb.AnalyzeShardDistributionResponse{
+			Shards: make([]*vtctldatapb.ShardDistribution, len(names)),
+			Errors: map[string]string{},
+		}
+	)
+	for i, name := range names {
+		resp.Shards[i] = &vtctldatapb.ShardDistribution{
+			Shard:                name,
+			EstimatedRowsByTable: map[string]uint64{},
+		}
+	}
+	tablesRows := make([]map[string]float64, len(names))
+	for i := range tablesRows {
+		tablesRows[i] = map[string]float64{}
+	}
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			samples, err := s.sampleShardDistribution(ctx, req.Keyspace, shard, primaryVindexes, sampleSize, keyRanges)
+
+			m.Lock()
+			defer m.Unlock()
+			if err != nil {
+				resp.Errors[shard] = err.Error()
+				return
+			}
+			for _, sample := range samples {
+				resp.Shards[sample.shard].SampledRows++
+				rows[sample.shard] += sample.rows
+				size[sample.shard] += sample.size
+				tablesRows[sample.shard][sample.table] += sample.rows
+			}
+		}(shard)
+	}
+	wg.Wait()
+
+	var totalRows float64
+	for i := range resp.Shards {
+		totalRows += rows[i]
+	}
+	for i, shard := range resp.Shards {
+		shard.EstimatedRows = uint64(math.Round(rows[i]))
+		shard.EstimatedSize = uint64(math.Round(size[i]))
+		for table, tableRows := range tablesRows[i] {
+			shard.EstimatedRowsByTable[table] = uint64(math.Round(tableRows))
+		}
+		if totalRows > 0 {
+			shard.Skew = rows[i] / (totalRows / float64(len(resp.Shards)))
+		}
+	}
+
+	return resp, nil
+}
+
 // ApplyRoutingRules is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ApplyRoutingRules(ctx context.Context, req *vtctldatapb.ApplyRoutingRulesRequest) (*vtctldatapb.ApplyRoutingRulesResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ApplyRoutingRules")
@@ -253,6 +496,7 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 	span.Annotate("cells", strings.Join(req.Cells, ","))
 	span.Annotate("skip_rebuild", req.SkipRebuild)
 	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("validate_against_schema", req.ValidateAgainstSchema)
 
 	if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
 		if topo.IsErrType(err, topo.NoNode) {
@@ -296,6 +540,12 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 		vs = req.VSchema
 	}
 
+	if req.ValidateAgainstSchema {
+		if err := schematools.ValidateVSchemaAgainstKeyspace(ctx, s.ts, s.tmc, req.Keyspace, vs); err != nil {
+			return nil, err
+		}
+	}
+
 	if req.DryRun { // we return what was passed in and parsed, rather than current
 		return &vtctldatapb.ApplyVSchemaResponse{VSchema: vs}, nil
 	}
@@ -316,6 +566,55 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 	return &vtctldatapb.ApplyVSchemaResponse{VSchema: updatedVS}, nil
 }
 
+// ApproveDBAScript is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ApproveDBAScript(ctx context.Context, req *vtctldatapb.ApproveDBAScriptRequest) (*vtctldatapb.ApproveDBAScriptResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ApproveDBAScript")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	user, ok := servenv.AuthenticatedUserFromContext(ctx)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_PERMISSION_DENIED, "approving a DBA script requires an authenticated caller, see --grpc_auth_mode")
+	}
+	span.Annotate("user", user)
+
+	script, err := s.ts.GetDBAScript(ctx, req.Keyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := dbascript.Statements(script.SQL)
+	if err != nil {
+		return nil, err
+	}
+	tablets, err := s.dbaScriptTablets(ctx, req.Keyspace, script)
+	if err != nil {
+		return nil, err
+	}
+	script, err = s.ts.UpdateDBAScriptFields(ctx, req.Keyspace, req.Name, func(updated *topo.DBAScript) error {
+		if updated.State != topo.DBAScriptPending {
+			return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "DBA script %s is %s, not pending approval", updated.Name, updated.State)
+		}
+		updated.ApprovedBy = user
+		updated.State = topo.DBAScriptRunning
+		updated.AddAuditEntry(user, "approved", "")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	script, results, err := s.runDBAScript(ctx, req.Keyspace, script, statements, tablets)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.ApproveDBAScriptResponse{
+		Script:  dbaScriptToProto(script),
+		Results: results,
+	}, nil
+}
+
 // Backup is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) Backup(req *vtctldatapb.BackupRequest, stream vtctlservicepb.Vtctld_BackupServer) error {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.Backup")
@@ -332,8 +631,9 @@ func (s *VtctldServer) Backup(req *vtctldatapb.BackupRequest, stream vtctlservic
 
 	span.Annotate("keyspace", ti.Keyspace)
 	span.Annotate("shard", ti.Shard)
+	span.Annotate("detach", req.Detach)
 
-	return s.backupTablet(ctx, ti.Tablet, int(req.Concurrency), req.AllowPrimary, stream)
+	return s.backupTablet(ctx, ti.Tablet, int(req.Concurrency), req.AllowPrimary, req.Detach, stream)
 }
 
 // BackupShard is part of the vtctlservicepb.VtctldServer interface.
@@ -385,13 +685,42 @@ func (s *VtctldServer) BackupShard(req *vtctldatapb.BackupShardRequest, stream v
 	}
 
 	span.Annotate("tablet_alias", topoproto.TabletAliasString(backupTablet.Alias))
+	span.Annotate("detach", req.Detach)
 
-	return s.backupTablet(ctx, backupTablet, int(req.Concurrency), req.AllowPrimary, stream)
+	return s.backupTablet(ctx, backupTablet, int(req.Concurrency), req.AllowPrimary, req.Detach, stream)
 }
 
-func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tablet, concurrency int, allowPrimary bool, stream interface {
+type backupStream interface {
 	Send(resp *vtctldatapb.BackupResponse) error
-}) error {
+}
+
+func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tablet, concurrency int, allowPrimary bool, detach bool, stream backupStream) error {
+	if !detach {
+		return s.runBackup(ctx, tablet, concurrency, allowPrimary, stream)
+	}
+
+	// A detached backup outlives the request, so it runs with its own
+	// context, and only its start is sent to the client.
+	go func() {
+		if err := s.runBackup(context.Background(), tablet, concurrency, allowPrimary, nil); err != nil {
+			log.Errorf("detached backup of tablet %v failed: %v", topoproto.TabletAliasString(tablet.Alias), err)
+		}
+	}()
+	return stream.Send(&vtctldatapb.BackupResponse{
+		TabletAlias: tablet.Alias,
+		Keyspace:    tablet.Keyspace,
+		Shard:       tablet.Shard,
+		Event: &logutilpb.Event{
+			Time:  logutil.TimeToProto(time.Now()),
+			Level: logutilpb.Level_INFO,
+			Value: fmt.Sprintf("backup of tablet %v started in the background", topoproto.TabletAliasString(tablet.Alias)),
+		},
+	})
+}
+
+// runBackup takes a backup on a tablet, and sends its events to the stream,
+// if any.
+func (s *VtctldServer) runBackup(ctx context.Context, tablet *topodatapb.Tablet, concurrency int, allowPrimary bool, stream backupStream) error {
 	logStream, err := s.tmc.Backup(ctx, tablet, concurrency, allowPrimary)
 	if err != nil {
 		return err
@@ -403,6 +732,9 @@ func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tabl
 		switch err {
 		case nil:
 			logutil.LogEvent(logger, event)
+			if stream == nil {
+				continue
+			}
 			resp := &vtctldatapb.BackupResponse{
 				TabletAlias: tablet.Alias,
 				Keyspace:    tablet.Keyspace,
@@ -524,6 +856,66 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	}, nil
 }
 
+// CreateArchival is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CreateArchival(ctx context.Context, req *vtctldatapb.CreateArchivalRequest) (*vtctldatapb.CreateArchivalResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.CreateArchival")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	if req.Archival == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "archival is required")
+	}
+	span.Annotate("name", req.Archival.Name)
+
+	if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+	if req.Archival.TargetKeyspace != "" {
+		if _, err := s.ts.GetKeyspace(ctx, req.Archival.TargetKeyspace); err != nil {
+			return nil, err
+		}
+	}
+
+	// The progress of the archival is kept by vtctld, so it is not taken
+	// from the request.
+	archival := &topo.Archival{
+		Name:           req.Archival.Name,
+		Table:          req.Archival.Table,
+		Where:          req.Archival.Where,
+		TargetKeyspace: req.Archival.TargetKeyspace,
+		TargetTable:    req.Archival.TargetTable,
+		TargetDir:      req.Archival.TargetDir,
+		BatchSize:      int(req.Archival.BatchSize),
+		MaxTPS:         req.Archival.MaxTps,
+		Paused:         req.Archival.Paused,
+	}
+	if err := s.ts.CreateArchival(ctx, req.Keyspace, archival); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.CreateArchivalResponse{
+		Archival: archivalToProto(archival),
+	}, nil
+}
+
+func archivalToProto(archival *topo.Archival) *vtctldatapb.Archival {
+	return &vtctldatapb.Archival{
+		Name:           archival.Name,
+		Table:          archival.Table,
+		Where:          archival.Where,
+		TargetKeyspace: archival.TargetKeyspace,
+		TargetTable:    archival.TargetTable,
+		TargetDir:      archival.TargetDir,
+		BatchSize:      int64(archival.BatchSize),
+		MaxTps:         archival.MaxTPS,
+		Paused:         archival.Paused,
+		ArchivedRows:   archival.ArchivedRows,
+		LastBatchTime:  archival.LastBatchTime,
+		LastError:      archival.LastError,
+	}
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CreateKeyspace")
@@ -680,6 +1072,21 @@ func (s *VtctldServer) CreateShard(ctx context.Context, req *vtctldatapb.CreateS
 	}, nil
 }
 
+// DeleteArchival is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DeleteArchival(ctx context.Context, req *vtctldatapb.DeleteArchivalRequest) (*vtctldatapb.DeleteArchivalResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeleteArchival")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	if err := s.ts.DeleteArchival(ctx, req.Keyspace, req.Name); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.DeleteArchivalResponse{}, nil
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) DeleteCellInfo(ctx context.Context, req *vtctldatapb.DeleteCellInfoRequest) (*vtctldatapb.DeleteCellInfoResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeleteCellInfo")
@@ -798,6 +1205,21 @@ func (s *VtctldServer) DeleteKeyspace(ctx context.Context, req *vtctldatapb.Dele
 	return &vtctldatapb.DeleteKeyspaceResponse{}, nil
 }
 
+// DeletePartitionPolicy is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DeletePartitionPolicy(ctx context.Context, req *vtctldatapb.DeletePartitionPolicyRequest) (*vtctldatapb.DeletePartitionPolicyResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeletePartitionPolicy")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+
+	if err := s.ts.DeletePartitionPolicy(ctx, req.Keyspace, req.Table); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.DeletePartitionPolicyResponse{}, nil
+}
+
 // DeleteShards is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) DeleteShards(ctx context.Context, req *vtctldatapb.DeleteShardsRequest) (*vtctldatapb.DeleteShardsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeleteShards")
@@ -850,6 +1272,108 @@ func (s *VtctldServer) DeleteTablets(ctx context.Context, req *vtctldatapb.Delet
 	return &vtctldatapb.DeleteTabletsResponse{}, nil
 }
 
+// DrainTablet is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DrainTablet(ctx context.Context, req *vtctldatapb.DrainTabletRequest) (*vtctldatapb.DrainTabletResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DrainTablet")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("undo", req.Undo)
+
+	if req.TabletAlias == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "DrainTablet requires a tablet alias")
+	}
+
+	waitTimeout, ok, err := protoutil.DurationFromProto(req.WaitTimeout)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "unable to parse WaitTimeout into a valid duration")
+	} else if !ok {
+		waitTimeout = time.Second * 30
+	}
+
+	span.Annotate("wait_timeout", waitTimeout.String())
+
+	getCtx, getCancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer getCancel()
+
+	tablet, err := s.ts.GetTablet(getCtx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Undo {
+		resp, err := s.tmc.DrainTablet(ctx, tablet.Tablet, &tabletmanagerdatapb.DrainTabletRequest{Undo: true})
+		if err != nil {
+			return nil, err
+		}
+
+		return &vtctldatapb.DrainTabletResponse{
+			Tablet:           tablet.Tablet,
+			InFlightQueries:  resp.InFlightQueries,
+			OpenTransactions: resp.OpenTransactions,
+		}, nil
+	}
+
+	switch tablet.Type {
+	case topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+	default:
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "cannot drain tablet %v of type %v, only REPLICA and RDONLY tablets can be drained", topoproto.TabletAliasString(req.TabletAlias), tablet.Type)
+	}
+
+	resp, err := s.waitForTabletDrained(ctx, tablet.Tablet, waitTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Tablet %v is idle, changing its type to DRAINED", topoproto.TabletAliasString(req.TabletAlias))
+
+	changeCtx, changeCancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer changeCancel()
+
+	if err := s.tmc.ChangeType(changeCtx, tablet.Tablet, topodatapb.TabletType_DRAINED, false); err != nil {
+		return nil, err
+	}
+
+	drained, err := s.ts.GetTablet(changeCtx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.DrainTabletResponse{
+		Tablet:           drained.Tablet,
+		InFlightQueries:  resp.InFlightQueries,
+		OpenTransactions: resp.OpenTransactions,
+	}, nil
+}
+
+// waitForTabletDrained marks a tablet as draining, and waits until it has no
+// queries and transactions in flight.
+func (s *VtctldServer) waitForTabletDrained(ctx context.Context, tablet *topodatapb.Tablet, waitTimeout time.Duration) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	waitCtx, waitCancel := context.WithTimeout(ctx, waitTimeout)
+	defer waitCancel()
+
+	for polls := 0; ; polls++ {
+		resp, err := s.tmc.DrainTablet(waitCtx, tablet, &tabletmanagerdatapb.DrainTabletRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		// vtgates learn that the tablet is draining from its health stream,
+		// and may still send it queries right after it started draining, so
+		// the tablet is idle only if it was draining since the last poll.
+		if polls > 0 && resp.InFlightQueries == 0 && resp.OpenTransactions == 0 {
+			return resp, nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return nil, vterrors.Errorf(vtrpc.Code_DEADLINE_EXCEEDED, "tablet %v is still draining after %v, with %d queries and %d transactions in flight; it can be undrained with --undo",
+				topoproto.TabletAliasString(tablet.Alias), waitTimeout, resp.InFlightQueries, resp.OpenTransactions)
+		case <-time.After(drainTabletPollInterval):
+		}
+	}
+}
+
 // EmergencyReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldatapb.EmergencyReparentShardRequest) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.EmergencyReparentShard")
@@ -915,25 +1439,217 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 	return resp, err
 }
 
-// ExecuteFetchAsApp is part of the vtctlservicepb.VtctldServer interface.
-func (s *VtctldServer) ExecuteFetchAsApp(ctx context.Context, req *vtctldatapb.ExecuteFetchAsAppRequest) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
-	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExecuteFetchAsApp")
+// ExecDBA is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExecDBA(ctx context.Context, req *vtctldatapb.ExecDBARequest) (*vtctldatapb.ExecDBAResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExecDBA")
 	defer span.Finish()
 
-	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
-	span.Annotate("max_rows", req.MaxRows)
-	span.Annotate("use_pool", req.UsePool)
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("require_approval", req.RequireApproval)
 
-	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
-	if err != nil {
-		return nil, err
+	// The submitter is recorded in the audit log of the script, and may not
+	// approve it, so it must not be chosen by the client.
+	user, ok := servenv.AuthenticatedUserFromContext(ctx)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_PERMISSION_DENIED, "submitting a DBA script requires an authenticated caller, see --grpc_auth_mode")
 	}
+	span.Annotate("user", user)
 
-	qr, err := s.tmc.ExecuteFetchAsApp(ctx, ti.Tablet, req.UsePool, []byte(req.Query), int(req.MaxRows))
-	if err != nil {
-		return nil, err
+	script := &topo.DBAScript{
+		Name:            req.Name,
+		SQL:             req.Sql,
+		Tags:            req.Tags,
+		Concurrency:     int(req.Concurrency),
+		SubmittedBy:     user,
+		RequireApproval: req.RequireApproval,
+		State:           topo.DBAScriptPending,
 	}
-
+	if script.Name == "" {
+		script.Name = time.Now().UTC().Format("20060102150405")
+	}
+	if script.Concurrency == 0 {
+		script.Concurrency = 1
+	}
+	for _, alias := range req.TabletAliases {
+		script.TabletAliases = append(script.TabletAliases, topoproto.TabletAliasString(alias))
+	}
+	for _, tabletType := range req.TabletTypes {
+		script.TabletTypes = append(script.TabletTypes, topoproto.TabletTypeLString(tabletType))
+	}
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	statements, err := dbascript.Statements(script.SQL)
+	if err != nil {
+		return nil, err
+	}
+	tablets, err := s.dbaScriptTablets(ctx, req.Keyspace, script)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		resp := &vtctldatapb.ExecDBAResponse{
+			Script:     dbaScriptToProto(script),
+			Statements: statements,
+		}
+		for _, tablet := range tablets {
+			resp.Tablets = append(resp.Tablets, tablet.Alias)
+		}
+		return resp, nil
+	}
+
+	script.AddAuditEntry(user, "submitted", "")
+	if !script.RequireApproval {
+		script.State = topo.DBAScriptRunning
+	}
+	if err := s.ts.CreateDBAScript(ctx, req.Keyspace, script); err != nil {
+		return nil, err
+	}
+	if script.RequireApproval {
+		return &vtctldatapb.ExecDBAResponse{
+			Script:     dbaScriptToProto(script),
+			Statements: statements,
+		}, nil
+	}
+
+	script, results, err := s.runDBAScript(ctx, req.Keyspace, script, statements, tablets)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.ExecDBAResponse{
+		Script:  dbaScriptToProto(script),
+		Results: results,
+	}, nil
+}
+
+// dbaScriptTablets returns the tablets of a keyspace that run a DBA script.
+func (s *VtctldServer) dbaScriptTablets(ctx context.Context, keyspace string, script *topo.DBAScript) ([]*topodatapb.Tablet, error) {
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	var tablets []*topodatapb.Tablet
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		for _, tabletInfo := range tabletMap {
+			tablets = append(tablets, tabletInfo.Tablet)
+		}
+	}
+	return dbascript.SelectTablets(script, tablets)
+}
+
+// runDBAScript runs a DBA script on its tablets, and records the outcome on
+// each tablet in its audit log. A script that fails on a tablet is recorded
+// as failed, which is not an error of the call.
+func (s *VtctldServer) runDBAScript(ctx context.Context, keyspace string, script *topo.DBAScript, statements []string, tablets []*topodatapb.Tablet) (*topo.DBAScript, []*vtctldatapb.DBAScriptTabletResult, error) {
+	results := dbascript.Run(ctx, tablets, statements, script.Concurrency, func(ctx context.Context, tablet *topodatapb.Tablet, query string) (*sqltypes.Result, error) {
+		qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), 10000, false /* disableBinlogs */, true /* reloadSchema */)
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.Proto3ToResult(qr), nil
+	})
+
+	script, err := s.ts.UpdateDBAScriptFields(ctx, keyspace, script.Name, func(updated *topo.DBAScript) error {
+		updated.State = topo.DBAScriptDone
+		for _, result := range results {
+			if result.Error != "" {
+				updated.State = topo.DBAScriptFailed
+				updated.AddAuditEntry("", "failed", fmt.Sprintf("%s: %s", result.Tablet, result.Error))
+				continue
+			}
+			updated.AddAuditEntry("", "ran", fmt.Sprintf("%s: %d statements", result.Tablet, len(result.Statements)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	protoResults := make([]*vtctldatapb.DBAScriptTabletResult, len(results))
+	for i, result := range results {
+		protoResults[i] = dbaScriptTabletResultToProto(tablets[i].Alias, result)
+	}
+	return script, protoResults, nil
+}
+
+func dbaScriptToProto(script *topo.DBAScript) *vtctldatapb.DBAScript {
+	pb := &vtctldatapb.DBAScript{
+		Name:            script.Name,
+		Sql:             script.SQL,
+		Tags:            script.Tags,
+		Concurrency:     int32(script.Concurrency),
+		SubmittedBy:     script.SubmittedBy,
+		RequireApproval: script.RequireApproval,
+		ApprovedBy:      script.ApprovedBy,
+		State:           script.State,
+	}
+	// The selectors were parsed when the script was submitted.
+	for _, alias := range script.TabletAliases {
+		if parsed, err := topoproto.ParseTabletAlias(alias); err == nil {
+			pb.TabletAliases = append(pb.TabletAliases, parsed)
+		}
+	}
+	for _, tabletType := range script.TabletTypes {
+		if parsed, err := topoproto.ParseTabletType(tabletType); err == nil {
+			pb.TabletTypes = append(pb.TabletTypes, parsed)
+		}
+	}
+	for _, entry := range script.Audit {
+		pb.Audit = append(pb.Audit, &vtctldatapb.DBAScriptAuditEntry{
+			Time:   entry.Time,
+			User:   entry.User,
+			Action: entry.Action,
+			Detail: entry.Detail,
+		})
+	}
+	return pb
+}
+
+func dbaScriptTabletResultToProto(alias *topodatapb.TabletAlias, result *dbascript.TabletResult) *vtctldatapb.DBAScriptTabletResult {
+	pb := &vtctldatapb.DBAScriptTabletResult{
+		TabletAlias: alias,
+		Error:       result.Error,
+	}
+	for _, stmt := range result.Statements {
+		stmtPb := &vtctldatapb.DBAScriptStatementResult{
+			Sql:          stmt.SQL,
+			RowsAffected: stmt.RowsAffected,
+			Fields:       stmt.Fields,
+			Error:        stmt.Error,
+		}
+		for _, row := range stmt.Rows {
+			stmtPb.Rows = append(stmtPb.Rows, &vtctldatapb.DBAScriptStatementResult_Row{Values: row})
+		}
+		pb.Statements = append(pb.Statements, stmtPb)
+	}
+	return pb
+}
+
+// ExecuteFetchAsApp is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExecuteFetchAsApp(ctx context.Context, req *vtctldatapb.ExecuteFetchAsAppRequest) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExecuteFetchAsApp")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("max_rows", req.MaxRows)
+	span.Annotate("use_pool", req.UsePool)
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err := s.tmc.ExecuteFetchAsApp(ctx, ti.Tablet, req.UsePool, []byte(req.Query), int(req.MaxRows))
+	if err != nil {
+		return nil, err
+	}
+
 	return &vtctldatapb.ExecuteFetchAsAppResponse{Result: qr}, nil
 }
 
@@ -995,6 +1711,33 @@ func (s *VtctldServer) ExecuteHook(ctx context.Context, req *vtctldatapb.Execute
 	}}, nil
 }
 
+// ExplainQuery is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExplainQuery(ctx context.Context, req *vtctldatapb.ExplainQueryRequest) (*vtctldatapb.ExplainQueryResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExplainQuery")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+
+	if req.ExplainQueryRequest == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "ExplainQueryRequest cannot be nil")
+	}
+
+	span.Annotate("analyze", req.ExplainQueryRequest.Analyze)
+	span.Annotate("format", req.ExplainQueryRequest.Format)
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.tmc.ExplainQuery(ctx, ti.Tablet, req.ExplainQueryRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ExplainQueryResponse{ExplainQueryResponse: resp}, nil
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.FindAllShardsInKeyspace")
@@ -1021,6 +1764,45 @@ func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctlda
 	}, nil
 }
 
+// GetArchivals is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetArchivals(ctx context.Context, req *vtctldatapb.GetArchivalsRequest) (*vtctldatapb.GetArchivalsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetArchivals")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	archivals, err := s.ts.GetArchivals(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.GetArchivalsResponse{
+		Archivals: make([]*vtctldatapb.Archival, 0, len(archivals)),
+	}
+	for _, archival := range archivals {
+		resp.Archivals = append(resp.Archivals, archivalToProto(archival))
+	}
+
+	return resp, nil
+}
+
+// GetAuditLog is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetAuditLog(ctx context.Context, req *vtctldatapb.GetAuditLogRequest) (*vtctldatapb.GetAuditLogResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetAuditLog")
+	defer span.Finish()
+
+	span.Annotate("limit", req.Limit)
+	span.Annotate("command", req.Command)
+
+	entries, err := audit.Default().Recent(ctx, int(req.Limit), req.Command)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.GetAuditLogResponse{
+		Entries: entries,
+	}, nil
+}
+
 // GetBackups is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) GetBackups(ctx context.Context, req *vtctldatapb.GetBackupsRequest) (*vtctldatapb.GetBackupsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetBackups")
@@ -1069,10 +1851,17 @@ func (s *VtctldServer) GetBackups(ctx context.Context, req *vtctldatapb.GetBacku
 		bi.Keyspace = req.Keyspace
 		bi.Shard = req.Shard
 
-		if req.Detailed {
-			if i >= backupsToSkipDetails { // nolint:staticcheck
-				// (TODO:@ajm188) Update backupengine/backupstorage implementations
-				// to get Status info for backups.
+		if req.Detailed && i >= backupsToSkipDetails {
+			// A backup has a MANIFEST once it is complete, so a backup
+			// without one is either in progress or was interrupted.
+			if manifest, err := mysqlctl.GetBackupManifest(ctx, bh); err == nil {
+				bi.Engine = manifest.BackupMethod
+				if bi.Engine == "" {
+					bi.Engine = "builtin"
+				}
+				bi.Status = mysqlctlpb.BackupInfo_COMPLETE
+			} else {
+				bi.Status = mysqlctlpb.BackupInfo_INCOMPLETE
 			}
 		}
 
@@ -1133,6 +1922,82 @@ func (s *VtctldServer) GetCellsAliases(ctx context.Context, req *vtctldatapb.Get
 	return &vtctldatapb.GetCellsAliasesResponse{Aliases: aliases}, nil
 }
 
+// GetDBAScripts is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetDBAScripts(ctx context.Context, req *vtctldatapb.GetDBAScriptsRequest) (*vtctldatapb.GetDBAScriptsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetDBAScripts")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	scripts, err := s.ts.GetDBAScripts(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.GetDBAScriptsResponse{}
+	for _, script := range scripts {
+		resp.Scripts = append(resp.Scripts, dbaScriptToProto(script))
+	}
+	return resp, nil
+}
+
+// GetFeatureGates is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetFeatureGates(ctx context.Context, req *vtctldatapb.GetFeatureGatesRequest) (*vtctldatapb.GetFeatureGatesResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetFeatureGates")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	var keyspaces []string
+	if req.Keyspace != "" {
+		if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+			return nil, err
+		}
+		keyspaces = []string{req.Keyspace}
+	} else {
+		var err error
+		keyspaces, err = s.ts.GetKeyspaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	overrides := make(map[string]map[string]bool, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		settings, err := s.ts.GetKeyspaceSettings(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		overrides[keyspace] = settings.FeatureGates
+	}
+
+	resp := &vtctldatapb.GetFeatureGatesResponse{}
+	for _, gate := range featuregate.Gates() {
+		resp.FeatureGates = append(resp.FeatureGates, featureGateToProto(gate, overrides))
+	}
+	return resp, nil
+}
+
+// featureGateToProto returns a gate with its overrides, which are keyed by
+// keyspace.
+func featureGateToProto(gate *featuregate.Gate, overrides map[string]map[string]bool) *vtctldatapb.FeatureGate {
+	fg := &vtctldatapb.FeatureGate{
+		Name:             gate.Name(),
+		Description:      gate.Description(),
+		Stage:            string(gate.Stage()),
+		EnabledByDefault: gate.EnabledByDefault(),
+	}
+	for keyspace, gates := range overrides {
+		if enabled, ok := gates[gate.Name()]; ok {
+			if fg.KeyspaceOverrides == nil {
+				fg.KeyspaceOverrides = map[string]bool{}
+			}
+			fg.KeyspaceOverrides[keyspace] = enabled
+		}
+	}
+	return fg
+}
+
 // GetKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetKeyspace(ctx context.Context, req *vtctldatapb.GetKeyspaceRequest) (*vtctldatapb.GetKeyspaceResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspace")
@@ -1153,6 +2018,42 @@ func (s *VtctldServer) GetKeyspace(ctx context.Context, req *vtctldatapb.GetKeys
 	}, nil
 }
 
+// GetKeyspaceSettings is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetKeyspaceSettings(ctx context.Context, req *vtctldatapb.GetKeyspaceSettingsRequest) (*vtctldatapb.GetKeyspaceSettingsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaceSettings")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.ts.GetKeyspaceSettings(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetKeyspaceSettingsResponse{
+		Settings: keyspaceSettingsToProto(settings),
+	}, nil
+}
+
+func keyspaceSettingsToProto(settings *topo.KeyspaceSettings) *vtctldatapb.KeyspaceSettings {
+	return &vtctldatapb.KeyspaceSettings{
+		NoScatter:             settings.NoScatter,
+		DefaultWorkload:       settings.DefaultWorkload,
+		ShedPercent:           int32(settings.ShedPercent),
+		ShedPriority:          int32(settings.ShedPriority),
+		TableGcLifecycle:      settings.TableGCLifecycle,
+		TableGcHold:           settings.TableGCHold,
+		FeatureGates:          settings.FeatureGates,
+		AvailabilityObjective: settings.AvailabilityObjective,
+		LatencyObjective:      settings.LatencyObjective,
+		LatencyThreshold:      settings.LatencyThreshold,
+	}
+}
+
 // GetKeyspaces is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKeyspacesRequest) (*vtctldatapb.GetKeyspacesResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaces")
@@ -1177,6 +2078,74 @@ func (s *VtctldServer) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKey
 	return &vtctldatapb.GetKeyspacesResponse{Keyspaces: keyspaces}, nil
 }
 
+// GetMySQLUserLimits is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetMySQLUserLimits(ctx context.Context, req *vtctldatapb.GetMySQLUserLimitsRequest) (*vtctldatapb.GetMySQLUserLimitsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetMySQLUserLimits")
+	defer span.Finish()
+
+	span.Annotate("user", req.User)
+
+	limits, err := s.ts.GetMySQLUserLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.GetMySQLUserLimitsResponse{
+		Limits: make(map[string]*vtctldatapb.MySQLUserLimits, len(limits)),
+	}
+	if req.User != "" {
+		userLimits := limits[req.User]
+		if userLimits == nil {
+			userLimits = &topo.MySQLUserLimits{}
+		}
+		resp.Limits[req.User] = mysqlUserLimitsToProto(userLimits)
+		return resp, nil
+	}
+	for user, userLimits := range limits {
+		resp.Limits[user] = mysqlUserLimitsToProto(userLimits)
+	}
+
+	return resp, nil
+}
+
+func mysqlUserLimitsToProto(limits *topo.MySQLUserLimits) *vtctldatapb.MySQLUserLimits {
+	return &vtctldatapb.MySQLUserLimits{
+		MaxConnections:       int32(limits.MaxConnections),
+		MaxConcurrentQueries: int32(limits.MaxConcurrentQueries),
+	}
+}
+
+// GetPartitionPolicies is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetPartitionPolicies(ctx context.Context, req *vtctldatapb.GetPartitionPoliciesRequest) (*vtctldatapb.GetPartitionPoliciesResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetPartitionPolicies")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	policies, err := s.ts.GetPartitionPolicies(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.GetPartitionPoliciesResponse{
+		PartitionPolicies: make([]*vtctldatapb.PartitionPolicy, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		resp.PartitionPolicies = append(resp.PartitionPolicies, partitionPolicyToProto(policy))
+	}
+
+	return resp, nil
+}
+
+func partitionPolicyToProto(policy *topo.PartitionPolicy) *vtctldatapb.PartitionPolicy {
+	return &vtctldatapb.PartitionPolicy{
+		Table:     policy.Table,
+		Interval:  policy.Interval,
+		Precreate: int64(policy.Precreate),
+		Retention: int64(policy.Retention),
+	}
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetPermissions(ctx context.Context, req *vtctldatapb.GetPermissionsRequest) (*vtctldatapb.GetPermissionsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetPermissions")
@@ -1213,37 +2182,142 @@ func (s *VtctldServer) GetRoutingRules(ctx context.Context, req *vtctldatapb.Get
 	}, nil
 }
 
-// GetSchema is part of the vtctlservicepb.VtctldServer interface.
-func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchemaRequest) (*vtctldatapb.GetSchemaResponse, error) {
-	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchema")
+// GetRuntimeFlags is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetRuntimeFlags(ctx context.Context, req *vtctldatapb.GetRuntimeFlagsRequest) (*vtctldatapb.GetRuntimeFlagsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetRuntimeFlags")
 	defer span.Finish()
 
 	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
-	span.Annotate("tables", strings.Join(req.Tables, ","))
-	span.Annotate("exclude_tables", strings.Join(req.ExcludeTables, ","))
-	span.Annotate("include_views", req.IncludeViews)
-	span.Annotate("table_names_only", req.TableNamesOnly)
-	span.Annotate("table_sizes_only", req.TableSizesOnly)
-	span.Annotate("table_schema_only", req.TableSchemaOnly)
+	span.Annotate("address", req.Address)
 
-	r := &tabletmanagerdatapb.GetSchemaRequest{Tables: req.Tables, ExcludeTables: req.ExcludeTables, IncludeViews: req.IncludeViews, TableSchemaOnly: req.TableSchemaOnly}
-	sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, req.TabletAlias, r)
+	client, err := s.dialRuntimeFlags(ctx, req.TabletAlias, req.Address)
 	if err != nil {
 		return nil, err
 	}
+	defer client.Close()
 
-	if req.TableNamesOnly {
-		nameTds := make([]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
-
-		for i, td := range sd.TableDefinitions {
-			nameTds[i] = &tabletmanagerdatapb.TableDefinition{
-				Name: td.Name,
-			}
-		}
+	resp, err := client.GetRuntimeFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		sd.TableDefinitions = nameTds
-	} else if req.TableSizesOnly {
-		sizeTds := make([]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
+	return &vtctldatapb.GetRuntimeFlagsResponse{
+		Flags:   resp.Flags,
+		Changes: resp.Changes,
+	}, nil
+}
+
+// dialRuntimeFlags dials the RuntimeFlags service of the given tablet, or of
+// the vtgate or vttablet at the given address.
+func (s *VtctldServer) dialRuntimeFlags(ctx context.Context, alias *topodatapb.TabletAlias, address string) (*grpcruntimeflagsclient.Client, error) {
+	address, err := s.componentAddress(ctx, alias, address)
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "must set a tablet alias or an address")
+	}
+
+	ok, err := hasCapability(ctx, address, runtimeflags.Capability)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "%s does not serve the runtime flags", address)
+	}
+
+	return grpcruntimeflagsclient.New(address)
+}
+
+// componentAddress returns the gRPC address of the given tablet, or the given
+// address. It returns an empty address if neither is set.
+func (s *VtctldServer) componentAddress(ctx context.Context, alias *topodatapb.TabletAlias, address string) (string, error) {
+	switch {
+	case alias != nil && address != "":
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot set both a tablet alias and an address")
+	case alias != nil:
+		ti, err := s.ts.GetTablet(ctx, alias)
+		if err != nil {
+			return "", err
+		}
+		grpcPort, ok := ti.PortMap["grpc"]
+		if !ok {
+			return "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "tablet %v has no grpc port", topoproto.TabletAliasString(alias))
+		}
+		return netutil.JoinHostPort(ti.Hostname, grpcPort), nil
+	}
+	return address, nil
+}
+
+// getVersionInfo returns the version info of the component at the given
+// address.
+func getVersionInfo(ctx context.Context, address string) (*versiondatapb.VersionInfo, error) {
+	client, err := grpcversionclient.New(address)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	return client.GetVersionInfo(ctx)
+}
+
+// hasCapability returns whether the component at the given address reports
+// the given capability. The components which predate the Version service have
+// none.
+func hasCapability(ctx context.Context, address string, capability string) (bool, error) {
+	versionInfo, err := getVersionInfo(ctx, address)
+	if err != nil {
+		if vterrors.Code(err) == vtrpc.Code_UNIMPLEMENTED {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, c := range versionInfo.Capabilities {
+		if c == capability {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetSchema is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchemaRequest) (*vtctldatapb.GetSchemaResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchema")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+	span.Annotate("exclude_tables", strings.Join(req.ExcludeTables, ","))
+	span.Annotate("include_views", req.IncludeViews)
+	span.Annotate("table_names_only", req.TableNamesOnly)
+	span.Annotate("table_sizes_only", req.TableSizesOnly)
+	span.Annotate("table_schema_only", req.TableSchemaOnly)
+
+	r := &tabletmanagerdatapb.GetSchemaRequest{
+		Tables:          req.Tables,
+		ExcludeTables:   req.ExcludeTables,
+		IncludeViews:    req.IncludeViews,
+		TableSchemaOnly: req.TableSchemaOnly,
+		// The names and sizes of the tables don't need their schemas to be read.
+		TableSizesOnly: req.TableNamesOnly || req.TableSizesOnly,
+	}
+	sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, req.TabletAlias, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TableNamesOnly {
+		nameTds := make([]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
+
+		for i, td := range sd.TableDefinitions {
+			nameTds[i] = &tabletmanagerdatapb.TableDefinition{
+				Name: td.Name,
+			}
+		}
+
+		sd.TableDefinitions = nameTds
+	} else if req.TableSizesOnly {
+		sizeTds := make([]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
 
 		for i, td := range sd.TableDefinitions {
 			sizeTds[i] = &tabletmanagerdatapb.TableDefinition{
@@ -1319,6 +2393,99 @@ func (s *VtctldServer) GetSrvKeyspaceNames(ctx context.Context, req *vtctldatapb
 	}, nil
 }
 
+// GetSrvKeyspacePartitions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSrvKeyspacePartitions(ctx context.Context, req *vtctldatapb.GetSrvKeyspacePartitionsRequest) (*vtctldatapb.GetSrvKeyspacePartitionsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvKeyspacePartitions")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	cells := req.Cells
+
+	if len(cells) == 0 {
+		var err error
+
+		cells, err = s.ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	span.Annotate("cells", strings.Join(cells, ","))
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make(map[string]*vtctldatapb.SrvKeyspacePartitions, len(cells))
+
+	for _, cell := range cells {
+		srvKeyspace, err := s.ts.GetSrvKeyspace(ctx, cell, req.Keyspace)
+		if err != nil {
+			if !topo.IsErrType(err, topo.NoNode) {
+				return nil, err
+			}
+
+			log.Warningf("no srvkeyspace for keyspace %s in cell %s", req.Keyspace, cell)
+
+			continue
+		}
+
+		cellPartitions := &vtctldatapb.SrvKeyspacePartitions{
+			Partitions: srvKeyspace.Partitions,
+		}
+
+		servedTypes := sets.NewString()
+		for _, partition := range srvKeyspace.Partitions {
+			if servedTypes.Has(partition.ServedType.String()) {
+				cellPartitions.Errors = append(cellPartitions.Errors, fmt.Sprintf("more than one keyspace partition for %v in cell %v", partition.ServedType, cell))
+			}
+
+			servedTypes.Insert(partition.ServedType.String())
+
+			if err := checkSrvKeyspacePartition(cell, partition, shards); err != nil {
+				cellPartitions.Errors = append(cellPartitions.Errors, err.Error())
+			}
+		}
+
+		partitions[cell] = cellPartitions
+	}
+
+	return &vtctldatapb.GetSrvKeyspacePartitionsResponse{
+		Partitions: partitions,
+	}, nil
+}
+
+// checkSrvKeyspacePartition checks that the shards of a keyspace partition
+// exist with the key ranges the partition has for them, and that their key
+// ranges cover the whole key space without overlapping.
+func checkSrvKeyspacePartition(cell string, partition *topodatapb.SrvKeyspace_KeyspacePartition, shards map[string]*topo.ShardInfo) error {
+	if len(partition.ShardReferences) == 0 {
+		return fmt.Errorf("keyspace partition for %v in cell %v has no shards", partition.ServedType, cell)
+	}
+
+	for _, shardReference := range partition.ShardReferences {
+		si, ok := shards[shardReference.Name]
+		if !ok {
+			return fmt.Errorf("keyspace partition for %v in cell %v has shard %v, which does not exist", partition.ServedType, cell, shardReference.Name)
+		}
+
+		if !key.KeyRangeEqual(shardReference.KeyRange, si.KeyRange) {
+			return fmt.Errorf("keyspace partition for %v in cell %v has KeyRange %v for shard %v, whose KeyRange is %v",
+				partition.ServedType, cell, key.KeyRangeString(shardReference.KeyRange), shardReference.Name, key.KeyRangeString(si.KeyRange))
+		}
+	}
+
+	// OrderAndCheckPartitions sorts the shards of the partitions it checks, so
+	// it is given a copy of the partition.
+	return topo.OrderAndCheckPartitions(cell, &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{
+			proto.Clone(partition).(*topodatapb.SrvKeyspace_KeyspacePartition),
+		},
+	})
+}
+
 // GetSrvKeyspaces is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetSrvKeyspaces(ctx context.Context, req *vtctldatapb.GetSrvKeyspacesRequest) (*vtctldatapb.GetSrvKeyspacesResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvKeyspaces")
@@ -1420,6 +2587,111 @@ func (s *VtctldServer) GetSrvVSchemas(ctx context.Context, req *vtctldatapb.GetS
 	}, nil
 }
 
+// GetTableSizes is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetTableSizes(ctx context.Context, req *vtctldatapb.GetTableSizesRequest) (*vtctldatapb.GetTableSizesResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTableSizes")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+	span.Annotate("exclude_tables", strings.Join(req.ExcludeTables, ","))
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m    sync.Mutex
+		wg   sync.WaitGroup
+		rec  concurrency.AllErrorRecorder
+		resp = &vtctldatapb.GetTableSizesResponse{
+			TableSizes: map[string]*vtctldatapb.TableSize{},
+		}
+	)
+
+	for _, shard := range shards {
+		wg.Add(1)
+
+		go func(shard string) {
+			defer wg.Done()
+
+			si, err := s.ts.GetShard(ctx, req.Keyspace, shard)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			if !si.HasPrimary() {
+				rec.RecordError(vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %v/%v has no primary", req.Keyspace, shard))
+				return
+			}
+
+			sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, si.PrimaryAlias, &tabletmanagerdatapb.GetSchemaRequest{
+				Tables:         req.Tables,
+				ExcludeTables:  req.ExcludeTables,
+				TableSizesOnly: true,
+			})
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+
+			for _, td := range sd.TableDefinitions {
+				size, ok := resp.TableSizes[td.Name]
+				if !ok {
+					size = &vtctldatapb.TableSize{
+						ByShard: map[string]*vtctldatapb.ShardTableSize{},
+					}
+					resp.TableSizes[td.Name] = size
+				}
+
+				size.RowCount += td.RowCount
+				size.DataLength += td.DataLength
+				size.ByShard[shard] = &vtctldatapb.ShardTableSize{
+					RowCount:   td.RowCount,
+					DataLength: td.DataLength,
+				}
+			}
+		}(shard)
+	}
+
+	wg.Wait()
+
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	return resp, nil
+}
+
+// GetThrottlerStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetThrottlerStatus(ctx context.Context, req *vtctldatapb.GetThrottlerStatusRequest) (*vtctldatapb.GetThrottlerStatusResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetThrottlerStatus")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	statuses, errs, err := s.fanOutThrottlers(ctx, req.Keyspace, func(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.ThrottlerStatus, error) {
+		resp, err := s.tmc.GetThrottlerStatus(ctx, tablet, &tabletmanagerdatapb.GetThrottlerStatusRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Status, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetThrottlerStatusResponse{
+		Statuses:         statuses,
+		Errors:           errs,
+		DivergentTablets: divergentThrottlers(statuses),
+	}, nil
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetTablet(ctx context.Context, req *vtctldatapb.GetTabletRequest) (*vtctldatapb.GetTabletResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTablet")
@@ -1629,6 +2901,34 @@ func (s *VtctldServer) GetVersion(ctx context.Context, req *vtctldatapb.GetVersi
 	return &vtctldatapb.GetVersionResponse{Version: version}, err
 }
 
+// GetVersionInfo is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVersionInfo(ctx context.Context, req *vtctldatapb.GetVersionInfoRequest) (*vtctldatapb.GetVersionInfoResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVersionInfo")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("address", req.Address)
+
+	address, err := s.componentAddress(ctx, req.TabletAlias, req.Address)
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		return &vtctldatapb.GetVersionInfoResponse{
+			VersionInfo: servenv.VersionInfo(),
+		}, nil
+	}
+
+	versionInfo, err := getVersionInfo(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetVersionInfoResponse{
+		VersionInfo: versionInfo,
+	}, nil
+}
+
 // GetVSchema is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSchemaRequest) (*vtctldatapb.GetVSchemaResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVSchema")
@@ -1646,6 +2946,48 @@ func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSche
 	}, nil
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVSchemaHistory(ctx context.Context, req *vtctldatapb.GetVSchemaHistoryRequest) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVSchemaHistory")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("version", req.Version)
+
+	if req.Version != 0 {
+		vv, err := s.ts.GetVSchemaVersion(ctx, req.Keyspace, req.Version)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "vschema version %d of keyspace %s does not exist", req.Version, req.Keyspace)
+			}
+			return nil, err
+		}
+
+		return &vtctldatapb.GetVSchemaHistoryResponse{
+			VSchema: vv.VSchema,
+		}, nil
+	}
+
+	history, err := s.ts.GetVSchemaHistory(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.GetVSchemaHistoryResponse{
+		Versions: make([]*vtctldatapb.VSchemaVersion, 0, len(history)),
+	}
+	for _, vv := range history {
+		resp.Versions = append(resp.Versions, &vtctldatapb.VSchemaVersion{
+			Version:   vv.Version,
+			Author:    vv.Author,
+			Timestamp: protoutil.TimeToProto(vv.Timestamp),
+			Summary:   vv.Summary,
+		})
+	}
+
+	return resp, nil
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (*vtctldatapb.GetWorkflowsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetWorkflows")
@@ -2339,33 +3681,92 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
-func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) error {
-	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
+// RestoreDroppedTable is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RestoreDroppedTable(ctx context.Context, req *vtctldatapb.RestoreDroppedTableRequest) (*vtctldatapb.RestoreDroppedTableResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RestoreDroppedTable")
 	defer span.Finish()
 
-	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
-	backupTime := protoutil.TimeFromProto(req.BackupTime)
-	if !backupTime.IsZero() {
-		span.Annotate("backup_timestamp", backupTime.Format(mysqlctl.BackupTimestampFormat))
-	}
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+	span.Annotate("uuid", req.Uuid)
 
-	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
-	if err != nil {
-		return err
+	if req.Table == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "table must be set")
 	}
 
-	span.Annotate("keyspace", ti.Keyspace)
-	span.Annotate("shard", ti.Shard)
-
-	logStream, err := s.tmc.RestoreFromBackup(ctx, ti.Tablet, protoutil.TimeFromProto(req.BackupTime))
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	logger := logutil.NewConsoleLogger()
-
-	for {
-		event, err := logStream.Recv()
+	// The held tables of all the shards are found before any of them is
+	// renamed, so that the table is restored either on every shard or on none.
+	primaries := make(map[string]*topodatapb.Tablet, len(shards))
+	holdTables := make(map[string]string, len(shards))
+	for _, shard := range shards {
+		si, err := s.ts.GetShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		if !si.HasPrimary() {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %v/%v has no primary", req.Keyspace, shard)
+		}
+		ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, err
+		}
+
+		holdTable, err := s.droppedTableHoldTable(ctx, ti.Tablet, req.Table, req.Uuid)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "shard %v/%v", req.Keyspace, shard)
+		}
+		primaries[shard] = ti.Tablet
+		holdTables[shard] = holdTable
+	}
+
+	resp := &vtctldatapb.RestoreDroppedTableResponse{
+		RestoredFrom: make(map[string]string, len(shards)),
+	}
+	for _, shard := range shards {
+		tablet := primaries[shard]
+		dbName := sqlescape.EscapeID(topoproto.TabletDbName(tablet))
+		query := fmt.Sprintf("RENAME TABLE %s.%s TO %s.%s", dbName, sqlescape.EscapeID(holdTables[shard]), dbName, sqlescape.EscapeID(req.Table))
+		if _, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), 1, false, true); err != nil {
+			return nil, vterrors.Wrapf(err, "failed to restore %v on shard %v/%v from %v, the shards restored so far are %v", req.Table, req.Keyspace, shard, holdTables[shard], resp.RestoredFrom)
+		}
+		resp.RestoredFrom[shard] = holdTables[shard]
+	}
+
+	return resp, nil
+}
+
+func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) error {
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	backupTime := protoutil.TimeFromProto(req.BackupTime)
+	if !backupTime.IsZero() {
+		span.Annotate("backup_timestamp", backupTime.Format(mysqlctl.BackupTimestampFormat))
+	}
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate("keyspace", ti.Keyspace)
+	span.Annotate("shard", ti.Shard)
+
+	logStream, err := s.tmc.RestoreFromBackup(ctx, ti.Tablet, protoutil.TimeFromProto(req.BackupTime))
+	if err != nil {
+		return err
+	}
+
+	logger := logutil.NewConsoleLogger()
+
+	for {
+		event, err := logStream.Recv()
 		switch err {
 		case nil:
 			logutil.LogEvent(logger, event)
@@ -2401,6 +3802,389 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	}
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RollbackVSchema(ctx context.Context, req *vtctldatapb.RollbackVSchemaRequest) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RollbackVSchema")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("to_version", req.ToVersion)
+	span.Annotate("skip_rebuild", req.SkipRebuild)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+
+	if req.ToVersion <= 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid vschema version: %d", req.ToVersion)
+	}
+
+	vs, err := s.ts.RollbackVSchema(ctx, req.Keyspace, req.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.SkipRebuild {
+		if err := s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
+			return nil, vterrors.Wrapf(err, "RebuildSrvVSchema")
+		}
+	}
+
+	return &vtctldatapb.RollbackVSchemaResponse{
+		VSchema: vs,
+	}, nil
+}
+
+// RollingRestart is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RollingRestart(req *vtctldatapb.RollingRestartRequest, stream vtctlservicepb.Vtctld_RollingRestartServer) error {
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RollingRestart")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+	span.Annotate("component", req.Component)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("concurrency", req.Concurrency)
+	span.Annotate("restart_hook", req.RestartHook)
+
+	if req.Keyspace == "" {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "RollingRestart requires a keyspace")
+	}
+
+	opts := &rollingRestartOptions{
+		cells:       req.Cells,
+		restartHook: req.RestartHook,
+	}
+
+	switch req.Component {
+	case "", "vttablet":
+		opts.component = "vttablet"
+	default:
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot restart component %s, only vttablet can be restarted", req.Component)
+	}
+
+	if opts.restartHook == "" {
+		opts.restartHook = "restart_tablet"
+	}
+
+	var (
+		ok  bool
+		err error
+	)
+
+	opts.drainTimeout, ok, err = protoutil.DurationFromProto(req.DrainTimeout)
+	if err != nil {
+		return vterrors.Wrapf(err, "unable to parse DrainTimeout into a valid duration")
+	} else if !ok {
+		opts.drainTimeout = time.Second * 30
+	}
+
+	opts.healthyTimeout, ok, err = protoutil.DurationFromProto(req.HealthyTimeout)
+	if err != nil {
+		return vterrors.Wrapf(err, "unable to parse HealthyTimeout into a valid duration")
+	} else if !ok {
+		opts.healthyTimeout = time.Minute * 5
+	}
+
+	opts.waitReplicasTimeout, ok, err = protoutil.DurationFromProto(req.WaitReplicasTimeout)
+	if err != nil {
+		return vterrors.Wrapf(err, "unable to parse WaitReplicasTimeout into a valid duration")
+	} else if !ok {
+		opts.waitReplicasTimeout = time.Second * 30
+	}
+
+	shards := req.Shards
+	if len(shards) == 0 {
+		shards, err = s.ts.GetShardNames(ctx, req.Keyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	parallelism := int(req.Concurrency)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		sendMu  sync.Mutex
+		aborted sync2.AtomicBool
+		rec     concurrency.FirstErrorRecorder
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallelism)
+	)
+
+	send := func(resp *vtctldatapb.RollingRestartResponse) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+
+		if err := stream.Send(resp); err != nil {
+			log.Errorf("failed to send stream response %+v: %v", resp, err)
+		}
+	}
+
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := s.rollingRestartShard(ctx, req.Keyspace, shard, opts, &aborted, send); err != nil {
+				// Tablets that are being restarted in other shards are not
+				// interrupted, but no other tablet is restarted.
+				aborted.Set(true)
+				rec.RecordError(err)
+			}
+		}(shard)
+	}
+
+	wg.Wait()
+
+	return rec.Error()
+}
+
+// rollingRestartOptions are the options of a RollingRestart, with their
+// defaults applied.
+type rollingRestartOptions struct {
+	component           string
+	cells               []string
+	restartHook         string
+	drainTimeout        time.Duration
+	healthyTimeout      time.Duration
+	waitReplicasTimeout time.Duration
+}
+
+// rollingRestartPollInterval is how often RollingRestart checks whether a
+// restarted tablet is healthy.
+var rollingRestartPollInterval = time.Second
+
+// runRestartHook runs the hook that restarts a tablet. It is a variable so
+// that tests can replace it.
+var runRestartHook = func(ctx context.Context, name string, params []string) *hk.HookResult {
+	return hk.NewHook(name, params).ExecuteContext(ctx)
+}
+
+// rollingRestartShard restarts the tablets of a shard one at a time, the
+// replicas first, and then the primary after reparenting the shard away from
+// it. It stops at the first tablet that fails to restart, or when aborted is
+// set because a tablet failed to restart in another shard.
+func (s *VtctldServer) rollingRestartShard(ctx context.Context, keyspace string, shard string, opts *rollingRestartOptions, aborted *sync2.AtomicBool, send func(resp *vtctldatapb.RollingRestartResponse)) error {
+	tabletMap, err := s.ts.GetTabletMapForShardByCell(ctx, keyspace, shard, opts.cells)
+	if err != nil {
+		return vterrors.Wrapf(err, "failed to get the tablets of shard %s/%s", keyspace, shard)
+	}
+
+	var (
+		primary *topodatapb.Tablet
+		tablets = make([]*topodatapb.Tablet, 0, len(tabletMap))
+	)
+
+	for _, tablet := range tabletMap {
+		switch tablet.Type {
+		case topodatapb.TabletType_PRIMARY:
+			primary = tablet.Tablet
+			continue
+		case topodatapb.TabletType_BACKUP, topodatapb.TabletType_RESTORE:
+			return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "cannot restart the tablets of shard %s/%s while tablet %v is of type %v",
+				keyspace, shard, topoproto.TabletAliasString(tablet.Alias), tablet.Type)
+		}
+
+		tablets = append(tablets, tablet.Tablet)
+	}
+
+	sort.Slice(tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(tablets[i].Alias) < topoproto.TabletAliasString(tablets[j].Alias)
+	})
+
+	if primary != nil {
+		tablets = append(tablets, primary)
+	}
+
+	for _, tablet := range tablets {
+		if aborted.Get() {
+			return vterrors.Errorf(vtrpc.Code_ABORTED, "rolling restart of shard %s/%s aborted because a tablet failed to restart in another shard", keyspace, shard)
+		}
+
+		alias := tablet.Alias
+		logger := logutil.NewCallbackLogger(func(e *logutilpb.Event) {
+			send(&vtctldatapb.RollingRestartResponse{
+				TabletAlias: alias,
+				Keyspace:    keyspace,
+				Shard:       shard,
+				Event:       e,
+			})
+		})
+
+		if err := s.restartTablet(ctx, tablet, opts, logger); err != nil {
+			logger.Errorf("Failed to restart tablet %v: %v", topoproto.TabletAliasString(alias), err)
+			return vterrors.Wrapf(err, "failed to restart tablet %v", topoproto.TabletAliasString(alias))
+		}
+	}
+
+	return nil
+}
+
+// restartTablet restarts a tablet with the restart hook, and waits for it to
+// be healthy. A primary is first reparented away from, and is restarted as a
+// replica; the shard is not reparented back to it. Serving tablets are drained
+// before they are restarted, and undrained if they could not be restarted.
+func (s *VtctldServer) restartTablet(ctx context.Context, tablet *topodatapb.Tablet, opts *rollingRestartOptions, logger logutil.Logger) error {
+	alias := topoproto.TabletAliasString(tablet.Alias)
+
+	if tablet.Type == topodatapb.TabletType_PRIMARY {
+		logger.Infof("Reparenting shard %s/%s away from primary %v", tablet.Keyspace, tablet.Shard, alias)
+
+		_, err := reparentutil.NewPlannedReparenter(s.ts, s.tmc, logger).ReparentShard(ctx,
+			tablet.Keyspace,
+			tablet.Shard,
+			reparentutil.PlannedReparentOptions{
+				AvoidPrimaryAlias:   tablet.Alias,
+				WaitReplicasTimeout: opts.waitReplicasTimeout,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		getCtx, getCancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+		defer getCancel()
+
+		ti, err := s.ts.GetTablet(getCtx, tablet.Alias)
+		if err != nil {
+			return err
+		}
+
+		tablet = ti.Tablet
+	}
+
+	drained := false
+	switch tablet.Type {
+	case topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+		logger.Infof("Draining tablet %v", alias)
+
+		if _, err := s.waitForTabletDrained(ctx, tablet, opts.drainTimeout); err != nil {
+			s.undrainTablet(tablet, logger)
+			return err
+		}
+
+		drained = true
+	}
+
+	logger.Infof("Restarting %s of tablet %v with hook %s", opts.component, alias, opts.restartHook)
+
+	hr := runRestartHook(ctx, opts.restartHook, []string{
+		"--tablet_alias=" + alias,
+		"--component=" + opts.component,
+		"--keyspace=" + tablet.Keyspace,
+		"--shard=" + tablet.Shard,
+		"--hostname=" + tablet.Hostname,
+	})
+	if hr.ExitStatus != hk.HOOK_SUCCESS {
+		if drained {
+			s.undrainTablet(tablet, logger)
+		}
+
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "restart hook %s failed: %v", opts.restartHook, hr.String())
+	}
+
+	logger.Infof("Waiting for tablet %v to be healthy", alias)
+
+	if err := s.waitForTabletHealthy(ctx, tablet.Alias, opts.healthyTimeout); err != nil {
+		return err
+	}
+
+	logger.Infof("Tablet %v is healthy", alias)
+
+	return nil
+}
+
+// undrainTablet undoes the drain of a tablet that could not be restarted, so
+// that it serves queries again. It is best effort, and runs even if the
+// context of the restart is done.
+func (s *VtctldServer) undrainTablet(tablet *topodatapb.Tablet, logger logutil.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+	defer cancel()
+
+	if _, err := s.tmc.DrainTablet(ctx, tablet, &tabletmanagerdatapb.DrainTabletRequest{Undo: true}); err != nil {
+		logger.Warningf("Failed to undrain tablet %v: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+}
+
+// waitForTabletHealthy waits until the health stream of a restarted tablet
+// reports it healthy and not draining, and serving if it is of a serving type.
+// A restart loses the drain of a tablet, so a drained tablet that reports it
+// is not draining anymore was restarted.
+func (s *VtctldServer) waitForTabletHealthy(ctx context.Context, alias *topodatapb.TabletAlias, timeout time.Duration) error {
+	waitCtx, waitCancel := context.WithTimeout(ctx, timeout)
+	defer waitCancel()
+
+	var lastErr error
+	for {
+		// The tablet is read again on every attempt, since its address may
+		// have changed when it restarted.
+		err := s.checkTabletHealthy(waitCtx, alias)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+
+		select {
+		case <-waitCtx.Done():
+			return vterrors.Errorf(vtrpc.Code_DEADLINE_EXCEEDED, "tablet %v is not healthy after %v: %v", topoproto.TabletAliasString(alias), timeout, lastErr)
+		case <-time.After(rollingRestartPollInterval):
+		}
+	}
+}
+
+// checkTabletHealthy returns nil if the first health record of a tablet is
+// healthy, or the reason it is not.
+func (s *VtctldServer) checkTabletHealthy(ctx context.Context, alias *topodatapb.TabletAlias) error {
+	ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+
+	tablet, err := s.ts.GetTablet(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	conn, err := tabletconn.GetDialer()(tablet.Tablet, grpcclient.FailFast(false))
+	if err != nil {
+		return fmt.Errorf("cannot connect to tablet: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	var healthy bool
+	err = conn.StreamHealth(ctx, func(shr *querypb.StreamHealthResponse) error {
+		if shr.RealtimeStats != nil && shr.RealtimeStats.HealthError != "" {
+			return fmt.Errorf("tablet is unhealthy: %s", shr.RealtimeStats.HealthError)
+		}
+
+		if shr.Draining {
+			return fmt.Errorf("tablet is still draining, it was not restarted")
+		}
+
+		if shr.Target != nil && !shr.Serving {
+			switch shr.Target.TabletType {
+			case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+				return fmt.Errorf("tablet of type %v is not serving", shr.Target.TabletType)
+			}
+		}
+
+		healthy = true
+		return io.EOF
+	})
+
+	if healthy {
+		return nil
+	}
+
+	if err == nil {
+		err = fmt.Errorf("health stream ended")
+	}
+
+	return err
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunHealthCheckRequest) (*vtctldatapb.RunHealthCheckResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RunHealthCheck")
@@ -2421,6 +4205,77 @@ func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunH
 	return &vtctldatapb.RunHealthCheckResponse{}, nil
 }
 
+// SetArchivalPaused is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetArchivalPaused(ctx context.Context, req *vtctldatapb.SetArchivalPausedRequest) (*vtctldatapb.SetArchivalPausedResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetArchivalPaused")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("paused", req.Paused)
+
+	archival, err := s.ts.UpdateArchivalFields(ctx, req.Keyspace, req.Name, func(a *topo.Archival) error {
+		a.Paused = req.Paused
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetArchivalPausedResponse{
+		Archival: archivalToProto(archival),
+	}, nil
+}
+
+// SetFeatureGate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetFeatureGate(ctx context.Context, req *vtctldatapb.SetFeatureGateRequest) (resp *vtctldatapb.SetFeatureGateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetFeatureGate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("enabled", req.Enabled)
+	span.Annotate("clear", req.Clear)
+
+	gate := featuregate.Lookup(req.Name)
+	if gate == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown feature gate: %s", req.Name)
+	}
+
+	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetFeatureGate")
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	defer unlock(&err)
+
+	settings, err := s.ts.GetKeyspaceSettings(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if req.Clear {
+		delete(settings.FeatureGates, gate.Name())
+		if len(settings.FeatureGates) == 0 {
+			settings.FeatureGates = nil
+		}
+	} else {
+		if settings.FeatureGates == nil {
+			settings.FeatureGates = map[string]bool{}
+		}
+		settings.FeatureGates[gate.Name()] = req.Enabled
+	}
+	if err = s.ts.SaveKeyspaceSettings(ctx, req.Keyspace, settings); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetFeatureGateResponse{
+		FeatureGate: featureGateToProto(gate, map[string]map[string]bool{req.Keyspace: settings.FeatureGates}),
+	}, nil
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtctldatapb.SetKeyspaceDurabilityPolicyRequest) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceDurabilityPolicy")
@@ -2498,6 +4353,115 @@ func (s *VtctldServer) SetKeyspaceServedFrom(ctx context.Context, req *vtctldata
 	}, nil
 }
 
+// SetKeyspaceSettings is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetKeyspaceSettings(ctx context.Context, req *vtctldatapb.SetKeyspaceSettingsRequest) (resp *vtctldatapb.SetKeyspaceSettingsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceSettings")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("fields", strings.Join(req.Fields, ","))
+
+	if len(req.Fields) == 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "no keyspace settings to change")
+	}
+
+	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetKeyspaceSettings")
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	defer unlock(&err)
+
+	settings, err := s.ts.GetKeyspaceSettings(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	values := req.Settings
+	if values == nil {
+		values = &vtctldatapb.KeyspaceSettings{}
+	}
+	for _, field := range req.Fields {
+		switch field {
+		case "no_scatter":
+			settings.NoScatter = values.NoScatter
+		case "default_workload":
+			settings.DefaultWorkload = strings.ToUpper(values.DefaultWorkload)
+		case "shed_percent":
+			settings.ShedPercent = int(values.ShedPercent)
+		case "shed_priority":
+			settings.ShedPriority = int(values.ShedPriority)
+		case "table_gc_lifecycle":
+			settings.TableGCLifecycle = strings.ToLower(values.TableGcLifecycle)
+		case "table_gc_hold":
+			settings.TableGCHold = values.TableGcHold
+		case "availability_objective":
+			settings.AvailabilityObjective = values.AvailabilityObjective
+		case "latency_objective":
+			settings.LatencyObjective = values.LatencyObjective
+		case "latency_threshold":
+			settings.LatencyThreshold = values.LatencyThreshold
+		default:
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown keyspace setting: %s", field)
+		}
+	}
+	if err = s.ts.SaveKeyspaceSettings(ctx, req.Keyspace, settings); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetKeyspaceSettingsResponse{
+		Settings: keyspaceSettingsToProto(settings),
+	}, nil
+}
+
+// SetMySQLUserLimits is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetMySQLUserLimits(ctx context.Context, req *vtctldatapb.SetMySQLUserLimitsRequest) (*vtctldatapb.SetMySQLUserLimitsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetMySQLUserLimits")
+	defer span.Finish()
+
+	span.Annotate("user", req.User)
+	span.Annotate("fields", strings.Join(req.Fields, ","))
+
+	if req.User == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "the user is required")
+	}
+	if len(req.Fields) == 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "no MySQL user limits to change")
+	}
+
+	limits, err := s.ts.GetMySQLUserLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userLimits := limits[req.User]
+	if userLimits == nil {
+		userLimits = &topo.MySQLUserLimits{}
+	}
+	values := req.Limits
+	if values == nil {
+		values = &vtctldatapb.MySQLUserLimits{}
+	}
+	for _, field := range req.Fields {
+		switch field {
+		case "max_connections":
+			userLimits.MaxConnections = int(values.MaxConnections)
+		case "max_concurrent_queries":
+			userLimits.MaxConcurrentQueries = int(values.MaxConcurrentQueries)
+		default:
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown MySQL user limit: %s", field)
+		}
+	}
+	if err := s.ts.SaveMySQLUserLimits(ctx, req.User, userLimits); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetMySQLUserLimitsResponse{
+		Limits: mysqlUserLimitsToProto(userLimits),
+	}, nil
+}
+
 // SetKeyspaceShardingInfo is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetKeyspaceShardingInfo(ctx context.Context, req *vtctldatapb.SetKeyspaceShardingInfoRequest) (*vtctldatapb.SetKeyspaceShardingInfoResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceShardingInfo")
@@ -2513,6 +4477,71 @@ func (s *VtctldServer) SetKeyspaceShardingInfo(ctx context.Context, req *vtctlda
 	}, nil
 }
 
+// SetPartitionPolicy is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetPartitionPolicy(ctx context.Context, req *vtctldatapb.SetPartitionPolicyRequest) (*vtctldatapb.SetPartitionPolicyResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetPartitionPolicy")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	if req.PartitionPolicy == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "partition policy is required")
+	}
+	span.Annotate("table", req.PartitionPolicy.Table)
+
+	if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	policy := &topo.PartitionPolicy{
+		Table:     req.PartitionPolicy.Table,
+		Interval:  req.PartitionPolicy.Interval,
+		Precreate: int(req.PartitionPolicy.Precreate),
+		Retention: int(req.PartitionPolicy.Retention),
+	}
+	if err := s.ts.SavePartitionPolicy(ctx, req.Keyspace, policy); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetPartitionPolicyResponse{
+		PartitionPolicy: partitionPolicyToProto(policy),
+	}, nil
+}
+
+// SetRuntimeFlag is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetRuntimeFlag(ctx context.Context, req *vtctldatapb.SetRuntimeFlagRequest) (*vtctldatapb.SetRuntimeFlagResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetRuntimeFlag")
+	defer span.Finish()
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("address", req.Address)
+	span.Annotate("name", req.Name)
+	span.Annotate("value", req.Value)
+
+	client, err := s.dialRuntimeFlags(ctx, req.TabletAlias, req.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	// The component records vtctld as the caller, and its authenticated client
+	// as the one it acts for.
+	changedBy, ok := servenv.AuthenticatedUserFromContext(ctx)
+	if !ok {
+		if p, ok := peer.FromContext(ctx); ok {
+			changedBy = p.Addr.String()
+		}
+	}
+	change, err := client.SetRuntimeFlag(ctx, req.Name, req.Value, changedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetRuntimeFlagResponse{
+		Change: change,
+	}, nil
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctldatapb.SetShardIsPrimaryServingRequest) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardIsPrimaryServing")
@@ -2543,6 +4572,73 @@ func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctld
 	}, nil
 }
 
+// SetShardReadOnly is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetShardReadOnly(ctx context.Context, req *vtctldatapb.SetShardReadOnlyRequest) (*vtctldatapb.SetShardReadOnlyResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardReadOnly")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("undo", req.Undo)
+
+	if req.Keyspace == "" || req.Shard == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "SetShardReadOnly requires a keyspace and a shard")
+	}
+
+	duration, ok, err := protoutil.DurationFromProto(req.Duration)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "unable to parse Duration into a valid duration")
+	}
+	if ok {
+		if req.Undo {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot set a duration to make shard %v/%v writable again", req.Keyspace, req.Shard)
+		}
+		if duration <= 0 {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid duration %v, it must be positive", duration)
+		}
+		span.Annotate("duration", duration.String())
+	}
+
+	getCtx, getCancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer getCancel()
+
+	si, err := s.ts.GetShard(getCtx, req.Keyspace, req.Shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %v/%v has no primary", req.Keyspace, req.Shard)
+	}
+
+	primary, err := s.ts.GetTablet(getCtx, si.PrimaryAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	freezeCtx, freezeCancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer freezeCancel()
+
+	resp, err := s.tmc.FreezeWrites(freezeCtx, primary.Tablet, &tabletmanagerdatapb.FreezeWritesRequest{
+		Duration: req.Duration,
+		Undo:     req.Undo,
+	})
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to freeze the writes of primary %v", topoproto.TabletAliasString(si.PrimaryAlias))
+	}
+
+	if resp.Frozen {
+		log.Infof("Shard %v/%v is read-only on primary %v, expire time: %v", req.Keyspace, req.Shard, topoproto.TabletAliasString(si.PrimaryAlias), protoutil.TimeFromProto(resp.ExpireTime))
+	} else {
+		log.Infof("Shard %v/%v is writable on primary %v", req.Keyspace, req.Shard, topoproto.TabletAliasString(si.PrimaryAlias))
+	}
+
+	return &vtctldatapb.SetShardReadOnlyResponse{
+		Primary:    si.PrimaryAlias,
+		ReadOnly:   resp.Frozen,
+		ExpireTime: resp.ExpireTime,
+	}, nil
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldatapb.SetShardTabletControlRequest) (*vtctldatapb.SetShardTabletControlResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardTabletControl")
@@ -2561,35 +4657,163 @@ func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldata
 		return nil, lockErr
 	}
 
-	var err error
-	defer unlock(&err)
+	var err error
+	defer unlock(&err)
+
+	si, err := s.ts.UpdateShardFields(ctx, req.Keyspace, req.Shard, func(si *topo.ShardInfo) error {
+		return si.UpdateSourceDeniedTables(ctx, req.TabletType, req.Cells, req.Remove, req.DeniedTables)
+	})
+
+	switch {
+	case topo.IsErrType(err, topo.NoUpdateNeeded):
+		// ok, fallthrough to DisableQueryService
+	case err != nil:
+		return nil, err
+	}
+
+	if si == nil { // occurs only when UpdateShardFields above returns NoUpdateNeeded
+		si, err = s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !req.Remove && len(req.DeniedTables) == 0 {
+		err = s.ts.UpdateDisableQueryService(ctx, req.Keyspace, []*topo.ShardInfo{si}, req.TabletType, req.Cells, req.DisableQueryService)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vtctldatapb.SetShardTabletControlResponse{
+		Shard: si.Shard,
+	}, nil
+}
+
+// SetSrvKeyspacePartition is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetSrvKeyspacePartition(ctx context.Context, req *vtctldatapb.SetSrvKeyspacePartitionRequest) (*vtctldatapb.SetSrvKeyspacePartitionResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetSrvKeyspacePartition")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("tablet_type", topoproto.TabletTypeLString(req.TabletType))
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+	span.Annotate("dry_run", req.DryRun)
+
+	switch {
+	case req.Keyspace == "":
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "SetSrvKeyspacePartition requires a keyspace")
+	case req.TabletType == topodatapb.TabletType_UNKNOWN:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "SetSrvKeyspacePartition requires a tablet type")
+	case len(req.Shards) == 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "SetSrvKeyspacePartition requires at least one shard")
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetSrvKeyspacePartition")
+	if lockErr != nil {
+		return nil, lockErr
+	}
+
+	var err error
+	defer unlock(&err)
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	partition := &topodatapb.SrvKeyspace_KeyspacePartition{
+		ServedType: req.TabletType,
+	}
+
+	shardNames := sets.NewString()
+	for _, name := range req.Shards {
+		if shardNames.Has(name) {
+			err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "shard %s is listed more than once", name)
+			return nil, err
+		}
+
+		shardNames.Insert(name)
+
+		si, ok := shards[name]
+		if !ok {
+			err = vterrors.Errorf(vtrpc.Code_NOT_FOUND, "shard %s/%s does not exist", req.Keyspace, name)
+			return nil, err
+		}
+
+		partition.ShardReferences = append(partition.ShardReferences, &topodatapb.ShardReference{
+			Name:     si.ShardName(),
+			KeyRange: si.KeyRange,
+		})
+	}
+
+	cells := req.Cells
+	if len(cells) == 0 {
+		cells, err = s.ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srvKeyspaces := make(map[string]*topodatapb.SrvKeyspace, len(cells))
+
+	// The SrvKeyspaces of all the cells are updated only if the partition is
+	// valid in all of them.
+	for _, cell := range cells {
+		srvKeyspace, getErr := s.ts.GetSrvKeyspace(ctx, cell, req.Keyspace)
+		if getErr != nil {
+			if topo.IsErrType(getErr, topo.NoNode) && len(req.Cells) == 0 {
+				log.Warningf("no srvkeyspace for keyspace %s in cell %s", req.Keyspace, cell)
+				continue
+			}
+
+			err = getErr
+			return nil, err
+		}
+
+		cellPartition := proto.Clone(partition).(*topodatapb.SrvKeyspace_KeyspacePartition)
+		if checkErr := checkSrvKeyspacePartition(cell, cellPartition, shards); checkErr != nil {
+			err = vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "%v", checkErr)
+			return nil, err
+		}
+
+		topoproto.ShardReferenceArray(cellPartition.ShardReferences).Sort()
+
+		partitions := make([]*topodatapb.SrvKeyspace_KeyspacePartition, 0, len(srvKeyspace.Partitions)+1)
+		for _, existing := range srvKeyspace.Partitions {
+			if existing.ServedType != req.TabletType {
+				partitions = append(partitions, existing)
+				continue
+			}
+
+			// The tablet controls of the shards that stay in the partition
+			// are kept.
+			for _, tabletControl := range existing.ShardTabletControls {
+				if shardNames.Has(tabletControl.Name) {
+					cellPartition.ShardTabletControls = append(cellPartition.ShardTabletControls, tabletControl)
+				}
+			}
+		}
 
-	si, err := s.ts.UpdateShardFields(ctx, req.Keyspace, req.Shard, func(si *topo.ShardInfo) error {
-		return si.UpdateSourceDeniedTables(ctx, req.TabletType, req.Cells, req.Remove, req.DeniedTables)
-	})
+		srvKeyspace.Partitions = append(partitions, cellPartition)
+		srvKeyspaces[cell] = srvKeyspace
+	}
 
-	switch {
-	case topo.IsErrType(err, topo.NoUpdateNeeded):
-		// ok, fallthrough to DisableQueryService
-	case err != nil:
+	if len(srvKeyspaces) == 0 {
+		err = vterrors.Errorf(vtrpc.Code_NOT_FOUND, "no SrvKeyspace for keyspace %s in any cell, it can be built with RebuildKeyspaceGraph", req.Keyspace)
 		return nil, err
 	}
 
-	if si == nil { // occurs only when UpdateShardFields above returns NoUpdateNeeded
-		si, err = s.ts.GetShard(ctx, req.Keyspace, req.Shard)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if !req.Remove && len(req.DeniedTables) == 0 {
-		err = s.ts.UpdateDisableQueryService(ctx, req.Keyspace, []*topo.ShardInfo{si}, req.TabletType, req.Cells, req.DisableQueryService)
-		if err != nil {
-			return nil, err
+	if !req.DryRun {
+		for cell, srvKeyspace := range srvKeyspaces {
+			if err = s.ts.UpdateSrvKeyspace(ctx, cell, req.Keyspace, srvKeyspace); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return &vtctldatapb.SetShardTabletControlResponse{
-		Shard: si.Shard,
+	return &vtctldatapb.SetSrvKeyspacePartitionResponse{
+		SrvKeyspaces: srvKeyspaces,
 	}, nil
 }
 
@@ -3184,6 +5408,184 @@ func (s *VtctldServer) UpdateCellsAlias(ctx context.Context, req *vtctldatapb.Up
 	}, nil
 }
 
+// UpdateSequence is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) UpdateSequence(ctx context.Context, req *vtctldatapb.UpdateSequenceRequest) (*vtctldatapb.UpdateSequenceResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.UpdateSequence")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+	span.Annotate("cache", req.Cache)
+	span.Annotate("next_value", req.NextValue)
+	span.Annotate("increment", req.Increment)
+
+	switch {
+	case req.Table == "":
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "sequence table is required")
+	case req.Cache < 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cache must be positive, got %d", req.Cache)
+	case req.NextValue < 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "next value must be positive, got %d", req.NextValue)
+	case req.Increment < 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "increment must be positive, got %d", req.Increment)
+	case req.NextValue != 0 && req.Increment != 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot set both the next value and the increment of a sequence")
+	case req.Cache == 0 && req.NextValue == 0 && req.Increment == 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "must set the cache, the next value or the increment of the sequence")
+	}
+
+	table := sqlparser.String(sqlparser.NewIdentifierCS(req.Table))
+	var sets []string
+	if req.Cache != 0 {
+		sets = append(sets, fmt.Sprintf("cache = %d", req.Cache))
+	}
+	if req.NextValue != 0 {
+		// The next value never moves backwards, so that the values that were
+		// already handed out are not reused.
+		sets = append(sets, fmt.Sprintf("next_id = greatest(next_id, %d)", req.NextValue))
+	}
+	if req.Increment != 0 {
+		sets = append(sets, fmt.Sprintf("next_id = next_id + %d", req.Increment))
+	}
+	update := fmt.Sprintf("update %s set %s where id = 0", table, strings.Join(sets, ", "))
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+
+	resp := &vtctldatapb.UpdateSequenceResponse{}
+	for _, shard := range shards {
+		si, err := s.ts.GetShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		if !si.HasPrimary() {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %v/%v has no primary", req.Keyspace, shard)
+		}
+
+		tablet, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := updateSequenceOnPrimary(ctx, tablet.Tablet, table, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update sequence %s on %s/%s: %w", table, req.Keyspace, shard, err)
+		}
+		resp.States = append(resp.States, state)
+	}
+
+	return resp, nil
+}
+
+// updateSequenceOnPrimary runs the update of a sequence table in a transaction
+// on a shard primary. The row of the sequence is locked first, so the update
+// is serialized with the tablets allocating blocks of values from it.
+func updateSequenceOnPrimary(ctx context.Context, tablet *topodatapb.Tablet, table string, update string) (*vtctldatapb.UpdateSequenceResponse_ShardState, error) {
+	conn, err := tabletconn.GetDialer()(tablet, grpcclient.FailFast(false))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to tablet: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	target := &querypb.Target{
+		Keyspace:   tablet.Keyspace,
+		Shard:      tablet.Shard,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	}
+	query := fmt.Sprintf("select next_id, cache from %s where id = 0 for update", table)
+
+	qr, txID, _, err := conn.BeginExecute(ctx, target, nil, query, nil, 0, nil)
+	if txID != 0 {
+		defer func() {
+			if txID != 0 {
+				_, _ = conn.Rollback(ctx, target, txID)
+			}
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 {
+		return nil, fmt.Errorf("unexpected rows in sequence table %s: %d", table, len(qr.Rows))
+	}
+
+	if _, err := conn.Execute(ctx, target, update, nil, txID, 0, nil); err != nil {
+		return nil, err
+	}
+	qr, err = conn.Execute(ctx, target, query, nil, txID, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 {
+		return nil, fmt.Errorf("unexpected rows in sequence table %s: %d", table, len(qr.Rows))
+	}
+
+	nextID, err := qr.Rows[0][0].ToInt64()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := qr.Rows[0][1].ToInt64()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Commit(ctx, target, txID); err != nil {
+		return nil, err
+	}
+	txID = 0
+
+	return &vtctldatapb.UpdateSequenceResponse_ShardState{
+		Shard:       tablet.Shard,
+		TabletAlias: tablet.Alias,
+		NextId:      nextID,
+		Cache:       cache,
+	}, nil
+}
+
+// UpdateThrottlerConfig is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldatapb.UpdateThrottlerConfigRequest) (*vtctldatapb.UpdateThrottlerConfigResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.UpdateThrottlerConfig")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("enable", req.Enable)
+	span.Annotate("disable", req.Disable)
+	span.Annotate("threshold", req.Threshold)
+
+	switch {
+	case req.Enable && req.Disable:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot both enable and disable the throttler")
+	case req.Threshold < 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid throttler threshold: %v", req.Threshold)
+	case !req.Enable && !req.Disable && req.Threshold == 0:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "must enable or disable the throttler, or set its threshold")
+	}
+
+	statuses, errs, err := s.fanOutThrottlers(ctx, req.Keyspace, func(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.ThrottlerStatus, error) {
+		resp, err := s.tmc.UpdateThrottlerConfig(ctx, tablet, &tabletmanagerdatapb.UpdateThrottlerConfigRequest{
+			Enable:    req.Enable,
+			Disable:   req.Disable,
+			Threshold: req.Threshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Status, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.UpdateThrottlerConfigResponse{
+		Statuses:         statuses,
+		Errors:           errs,
+		DivergentTablets: divergentThrottlers(statuses),
+	}, nil
+}
+
 // Validate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) Validate(ctx context.Context, req *vtctldatapb.ValidateRequest) (*vtctldatapb.ValidateResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.Validate")
@@ -3776,6 +6178,42 @@ func (s *VtctldServer) ValidateVersionKeyspace(ctx context.Context, req *vtctlda
 	return &resp, nil
 }
 
+// ValidateVersionSkew is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ValidateVersionSkew(ctx context.Context, req *vtctldatapb.ValidateVersionSkewRequest) (*vtctldatapb.ValidateVersionSkewResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ValidateVersionSkew")
+	defer span.Finish()
+
+	maxMajorSkew := int(req.MaxMajorSkew)
+	if maxMajorSkew <= 0 {
+		maxMajorSkew = topotools.DefaultMaxMajorVersionSkew
+	}
+	staleAfter, ok, err := protoutil.DurationFromProto(req.StaleAfter)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "unable to parse StaleAfter into a valid duration")
+	} else if !ok {
+		staleAfter = topotools.DefaultComponentVersionStaleAfter
+	}
+
+	span.Annotate("max_major_skew", maxMajorSkew)
+	span.Annotate("stale_after", staleAfter.String())
+
+	versions, err := s.ts.GetComponentVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := topotools.CheckVersionSkew(versions, maxMajorSkew, staleAfter, time.Now())
+	resp := &vtctldatapb.ValidateVersionSkewResponse{
+		Results:  report.Problems,
+		Warnings: report.Warnings,
+		Versions: make(map[string]string, len(versions)),
+	}
+	for _, cv := range versions {
+		resp.Versions[cv.Component+"/"+cv.ID] = cv.Version
+	}
+	return resp, nil
+}
+
 // ValidateVSchema compares the schema of each primary tablet in "keyspace/shards..." to the vschema and errs if there are differences
 func (s *VtctldServer) ValidateVSchema(ctx context.Context, req *vtctldatapb.ValidateVSchemaRequest) (*vtctldatapb.ValidateVSchemaResponse, error) {
 	keyspace := req.Keyspace
@@ -3856,9 +6294,348 @@ func (s *VtctldServer) ValidateVSchema(ctx context.Context, req *vtctldatapb.Val
 	return &resp, nil
 }
 
-// StartServer registers a VtctldServer for RPCs on the given gRPC server.
+// fanOutThrottlers calls f concurrently on every tablet of a keyspace, and
+// returns the throttler statuses of the tablets, and the errors of the
+// tablets that failed, both keyed by tablet alias.
+func (s *VtctldServer) fanOutThrottlers(ctx context.Context, keyspace string, f func(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.ThrottlerStatus, error)) (map[string]*tabletmanagerdatapb.ThrottlerStatus, map[string]string, error) {
+	if keyspace == "" {
+		return nil, nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace must be non-empty")
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetShardNames(%v) failed: %w", keyspace, err)
+	}
+
+	var (
+		m        sync.Mutex
+		wg       sync.WaitGroup
+		statuses = map[string]*tabletmanagerdatapb.ThrottlerStatus{}
+		errs     = map[string]string{}
+	)
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+		if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+			return nil, nil, fmt.Errorf("GetTabletMapForShard(%v, %v) failed: %w", keyspace, shard, err)
+		}
+
+		for alias, ti := range tabletMap {
+			wg.Add(1)
+			go func(alias string, tablet *topodatapb.Tablet) {
+				defer wg.Done()
+
+				status, err := f(ctx, tablet)
+
+				m.Lock()
+				defer m.Unlock()
+				if err != nil {
+					errs[alias] = err.Error()
+					return
+				}
+				statuses[alias] = status
+			}(alias, ti.Tablet)
+		}
+	}
+	wg.Wait()
+
+	return statuses, errs, nil
+}
+
+// divergentThrottlers returns the aliases of the tablets whose throttler is
+// not enabled or disabled like the throttlers of most tablets, or doesn't
+// have their threshold.
+func divergentThrottlers(statuses map[string]*tabletmanagerdatapb.ThrottlerStatus) []string {
+	type throttlerConfig struct {
+		enabled   bool
+		threshold float64
+	}
+
+	aliases := make([]string, 0, len(statuses))
+	for alias := range statuses {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	// The configuration of the most tablets wins. Ties go to the configuration
+	// that was counted first in the order of the aliases, so that the result
+	// doesn't depend on the order of the map.
+	var (
+		counts   = map[throttlerConfig]int{}
+		majority throttlerConfig
+	)
+	for _, alias := range aliases {
+		config := throttlerConfig{statuses[alias].Enabled, statuses[alias].Threshold}
+		counts[config]++
+		if counts[config] > counts[majority] {
+			majority = config
+		}
+	}
+
+	var divergent []string
+	for _, alias := range aliases {
+		if config := (throttlerConfig{statuses[alias].Enabled, statuses[alias].Threshold}); config != majority {
+			divergent = append(divergent, alias)
+		}
+	}
+	return divergent
+}
+
+// tabletIndexUsage reads the statistics of the indexes of the tables of the
+// database of a tablet.
+func (s *VtctldServer) tabletIndexUsage(ctx context.Context, tablet *topodatapb.Tablet) ([]*vtctldatapb.IndexUsage, error) {
+	query := fmt.Sprintf(sqlIndexUsage, sqltypes.EncodeStringSQL(topoproto.TabletDbName(tablet)))
+	p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), maxIndexUsageRows, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	qr := sqltypes.Proto3ToResult(p3qr)
+	usages := make([]*vtctldatapb.IndexUsage, 0, len(qr.Rows))
+	for _, row := range qr.Named().Rows {
+		// Without performance_schema, no index would seem to be read.
+		if !row.AsBool("performance_schema_enabled", false) {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "performance_schema is disabled on the mysqld of tablet %v", topoproto.TabletAliasString(tablet.Alias))
+		}
+		usages = append(usages, &vtctldatapb.IndexUsage{
+			Table:       row.AsString("table_name", ""),
+			Index:       row.AsString("index_name", ""),
+			Unique:      row.AsInt64("non_unique", 1) == 0,
+			Reads:       row.AsUint64("count_read", 0),
+			Cardinality: row.AsUint64("cardinality", 0),
+			TableRows:   row.AsUint64("table_rows", 0),
+		})
+	}
+	return usages, nil
+}
+
+// shardDistributionVindexes returns the primary vindexes of the tables of a
+// keyspace that AnalyzeShardDistribution samples, keyed by table name.
+func (s *VtctldServer) shardDistributionVindexes(ctx context.Context, keyspace string, tables []string) (map[string]*vindexes.ColumnVindex, error) {
+	vs, err := s.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, fmt.Errorf("GetVSchema(%v) failed: %w", keyspace, err)
+	}
+	// The keyspace may not be sharded yet: its VSchema is built as the VSchema
+	// of a sharded keyspace, without the tables that have no vindexes yet, so
+	// that the vindexes of its tables are built.
+	vs = proto.Clone(vs).(*vschemapb.Keyspace)
+	if !vs.Sharded {
+		vs.Sharded = true
+		for name, table := range vs.Tables {
+			if len(table.ColumnVindexes) == 0 {
+				delete(vs.Tables, name)
+			}
+		}
+	}
+	ksSchema, err := vindexes.BuildKeyspaceSchema(vs, keyspace)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "invalid VSchema of keyspace %v", keyspace)
+	}
+
+	primaryVindexes := map[string]*vindexes.ColumnVindex{}
+	if len(tables) == 0 {
+		for name, table := range ksSchema.Tables {
+			if len(table.ColumnVindexes) > 0 && !table.ColumnVindexes[0].Vindex.NeedsVCursor() {
+				primaryVindexes[name] = table.ColumnVindexes[0]
+			}
+		}
+		if len(primaryVindexes) == 0 {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no table of the VSchema of keyspace %v has a primary vindex that computes keyspace ids from the column values", keyspace)
+		}
+	}
+	for _, name := range tables {
+		table, ok := ksSchema.Tables[name]
+		if !ok || len(table.ColumnVindexes) == 0 {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "table %v has no primary vindex in the VSchema of keyspace %v", name, keyspace)
+		}
+		primaryVindexes[name] = table.ColumnVindexes[0]
+	}
+
+	for name, cv := range primaryVindexes {
+		if cv.Vindex.NeedsVCursor() {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the primary vindex %v of table %v looks up keyspace ids, only vindexes that compute them from the column values are supported", cv.Name, name)
+		}
+	}
+	return primaryVindexes, nil
+}
+
+// shardDistributionSample is a sampled row of a table, with the number of
+// rows and bytes of the table that it stands for, and the proposed shard its
+// keyspace id falls into.
+type shardDistributionSample struct {
+	table string
+	shard int
+	rows  float64
+	size  float64
+}
+
+// sampleShardDistribution samples the rows of the tables on an rdonly tablet
+// of a shard, or a replica if the shard has no rdonly tablet, and maps them to
+// the proposed shards by the keyspace ids that their primary vindexes compute.
+func (s *VtctldServer) sampleShardDistribution(ctx context.Context, keyspace string, shard string, primaryVindexes map[string]*vindexes.ColumnVindex, sampleSize int64, keyRanges []*topodatapb.KeyRange) ([]shardDistributionSample, error) {
+	tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+		return nil, fmt.Errorf("GetTabletMapForShard(%v, %v) failed: %w", keyspace, shard, err)
+	}
+	aliases := make([]string, 0, len(tabletMap))
+	for alias := range tabletMap {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	var tablet *topodatapb.Tablet
+	for _, alias := range aliases {
+		switch t := tabletMap[alias].Tablet; t.Type {
+		case topodatapb.TabletType_RDONLY:
+			if tablet == nil || tablet.Type != topodatapb.TabletType_RDONLY {
+				tablet = t
+			}
+		case topodatapb.TabletType_REPLICA:
+			if tablet == nil {
+				tablet = t
+			}
+		}
+	}
+	if tablet == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no rdonly or replica tablet to sample shard %v/%v on", keyspace, shard)
+	}
+
+	tables := make([]string, 0, len(primaryVindexes))
+	for table := range primaryVindexes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	dbName := topoproto.TabletDbName(tablet)
+	tableNames := make([]string, 0, len(tables))
+	for _, table := range tables {
+		tableNames = append(tableNames, sqltypes.EncodeStringSQL(table))
+	}
+	query := fmt.Sprintf(sqlTableStats, sqltypes.EncodeStringSQL(dbName), strings.Join(tableNames, ", "))
+	p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), len(tables), false, false)
+	if err != nil {
+		return nil, err
+	}
+	type tableStats struct {
+		rows, size uint64
+	}
+	stats := map[string]tableStats{}
+	for _, row := range sqltypes.Proto3ToResult(p3qr).Named().Rows {
+		stats[row.AsString("table_name", "")] = tableStats{
+			rows: row.AsUint64("table_rows", 0),
+			size: row.AsUint64("table_size", 0),
+		}
+	}
+
+	var samples []shardDistributionSample
+	for _, table := range tables {
+		tableStats, ok := stats[table]
+		if !ok || tableStats.rows == 0 {
+			continue
+		}
+
+		cv := primaryVindexes[table]
+		columns := make([]string, 0, len(cv.Columns))
+		for _, col := range cv.Columns {
+			columns = append(columns, sqlescape.EscapeID(col.String()))
+		}
+		query := fmt.Sprintf("select %s from %s.%s", strings.Join(columns, ", "), sqlescape.EscapeID(dbName), sqlescape.EscapeID(table))
+		if fraction := float64(sampleSize) / float64(tableStats.rows); fraction < 1 {
+			// Rows are sampled across the whole table, rather than from
+			// its first pages.
+			query += fmt.Sprintf(" where rand() < %v", fraction)
+		}
+		query += fmt.Sprintf(" limit %d", sampleSize)
+		p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), int(sampleSize), false, false)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to sample table %v on tablet %v", table, topoproto.TabletAliasString(tablet.Alias))
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		if len(qr.Rows) == 0 {
+			continue
+		}
+
+		destinations, err := vindexes.Map(cv.Vindex, nil, qr.Rows)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to compute the keyspace ids of table %v", table)
+		}
+		rows := float64(tableStats.rows) / float64(len(qr.Rows))
+		size := float64(tableStats.size) / float64(len(qr.Rows))
+		for _, destination := range destinations {
+			ksid, ok := destination.(key.DestinationKeyspaceID)
+			if !ok {
+				// The vindex columns of the row are NULL.
+				continue
+			}
+			for i, kr := range keyRanges {
+				if key.KeyRangeContains(kr, ksid) {
+					samples = append(samples, shardDistributionSample{table: table, shard: i, rows: rows, size: size})
+					break
+				}
+			}
+		}
+	}
+	return samples, nil
+}
+
+// droppedTableHoldTable returns the table that the table garbage collector of
+// a primary holds a table that Online DDL dropped in, if it can be restored.
+func (s *VtctldServer) droppedTableHoldTable(ctx context.Context, tablet *topodatapb.Tablet, table string, uuid string) (string, error) {
+	query := fmt.Sprintf(sqlDroppedTable,
+		sqltypes.EncodeStringSQL(topoproto.TabletDbName(tablet)),
+		sqltypes.EncodeStringSQL(table),
+		sqltypes.EncodeStringSQL(uuid),
+		sqltypes.EncodeStringSQL(uuid),
+	)
+	p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, []byte(query), 1, false, false)
+	if err != nil {
+		return "", err
+	}
+
+	row := sqltypes.Proto3ToResult(p3qr).Named().Row()
+	if row == nil {
+		return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "no completed Online DDL migration dropped table %v", table)
+	}
+	holdTable := row.AsString("hold_table", "")
+	if row.AsInt64("table_exists", 0) != 0 {
+		return "", vterrors.Errorf(vtrpc.Code_ALREADY_EXISTS, "table %v exists", table)
+	}
+	if holdTable == "" || row.AsInt64("hold_table_exists", 0) == 0 {
+		return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "table %v, dropped by migration %v, was already purged", table, row.AsString("migration_uuid", ""))
+	}
+	if isGC, state, _, _, err := schema.AnalyzeGCTableName(holdTable); err != nil || !isGC || state != schema.HoldTableGCState {
+		return "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "table %v, dropped by migration %v, is no longer held in %v", table, row.AsString("migration_uuid", ""), holdTable)
+	}
+	return holdTable, nil
+}
+
+// mergeIndexUsage merges the statistics of the indexes of a tablet into the
+// indexes of a keyspace, keyed by table and index name. The reads of all the
+// tablets add up, but only the primaries add up the cardinality and rows of
+// their shard, as their replicas have the same rows.
+func mergeIndexUsage(indexes map[string]*vtctldatapb.IndexUsage, usages []*vtctldatapb.IndexUsage, isPrimary bool) {
+	for _, usage := range usages {
+		key := usage.Table + "." + usage.Index
+		merged, ok := indexes[key]
+		if !ok {
+			merged = &vtctldatapb.IndexUsage{
+				Table:  usage.Table,
+				Index:  usage.Index,
+				Unique: usage.Unique,
+			}
+			indexes[key] = merged
+		}
+		merged.Reads += usage.Reads
+		if isPrimary {
+			merged.Cardinality += usage.Cardinality
+			merged.TableRows += usage.TableRows
+		}
+	}
+}
+
+// StartServer registers a VtctldServer for RPCs on the given gRPC server. The
+// calls are recorded in the default audit log, if any.
 func StartServer(s *grpc.Server, ts *topo.Server) {
-	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(ts))
+	s.RegisterService(audit.Default().Service(&vtctlservicepb.Vtctld_ServiceDesc), NewVtctldServer(ts))
 }
 
 // Helper function to get version of a tablet from its debug vars
@@ -21,26 +21,37 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/featuregate"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/runtimeflags/grpcruntimeflagsserver"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -52,6 +63,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
 
@@ -69,6 +81,27 @@ func init() {
 	tmclient.RegisterTabletManagerClientFactory("grpcvtctldserver.test", func() tmclient.TabletManagerClient {
 		return nil
 	})
+
+	// The tablet connections of TestRollingRestart and TestUpdateSequence,
+	// which set the tablet protocol.
+	tabletconn.RegisterDialer("grpcvtctldserver.test", func(tablet *topodatapb.Tablet, failFast grpcclient.FailFast) (queryservice.QueryService, error) {
+		sequenceQueryServicesMu.Lock()
+		qs, ok := sequenceQueryServices[topoproto.TabletAliasString(tablet.Alias)]
+		sequenceQueryServicesMu.Unlock()
+		if ok {
+			return qs, nil
+		}
+
+		rollingRestartHealthMu.Lock()
+		defer rollingRestartHealthMu.Unlock()
+
+		health, ok := rollingRestartHealth[topoproto.TabletAliasString(tablet.Alias)]
+		if !ok {
+			return nil, fmt.Errorf("no health for tablet %v", topoproto.TabletAliasString(tablet.Alias))
+		}
+
+		return &rollingRestartQueryService{health: health}, nil
+	})
 }
 
 func TestAddCellInfo(t *testing.T) {
@@ -213,6 +246,343 @@ func TestAddCellsAlias(t *testing.T) {
 	}
 }
 
+func TestAnalyzeIndexUsage(t *testing.T) {
+	t.Parallel()
+
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 201},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+	}
+	indexUsage := func(rows ...string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"table_name|index_name|non_unique|cardinality|table_rows|count_read|performance_schema_enabled",
+				"varchar|varchar|int64|uint64|uint64|uint64|int64",
+			),
+			rows...,
+		))
+	}
+	tmc := &testutil.TabletManagerClient{
+		ExecuteFetchAsDbaResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: indexUsage(
+					"t1|PRIMARY|0|1000|1000|50|1",
+					"t1|idx_name|1|900|1000|0|1",
+					"t1|idx_status|1|2|1000|0|1",
+				),
+			},
+			"zone1-0000000101": {
+				Response: indexUsage(
+					"t1|PRIMARY|0|1000|1000|10|1",
+					"t1|idx_name|1|900|1000|7|1",
+					"t1|idx_status|1|2|1000|0|1",
+				),
+			},
+			"zone1-0000000200": {
+				Response: indexUsage(
+					"t1|PRIMARY|0|3000|3000|20|1",
+					"t1|idx_name|1|2800|3000|0|1",
+					"t1|idx_status|1|3|3000|0|1",
+				),
+			},
+			"zone1-0000000201": {
+				Response: indexUsage(
+					"t1|PRIMARY|0|3000|3000|0|0",
+				),
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.AnalyzeIndexUsageRequest
+		expected  *vtctldatapb.AnalyzeIndexUsageResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.AnalyzeIndexUsageRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: &vtctldatapb.AnalyzeIndexUsageResponse{
+				Indexes: []*vtctldatapb.IndexUsage{
+					{Table: "t1", Index: "PRIMARY", Unique: true, Reads: 80, Cardinality: 4000, TableRows: 4000, Selectivity: 1},
+					{Table: "t1", Index: "idx_name", Reads: 7, Cardinality: 3700, TableRows: 4000, Selectivity: 0.925},
+					{Table: "t1", Index: "idx_status", Reads: 0, Cardinality: 5, TableRows: 4000, Selectivity: 0.00125, Unused: true, LowSelectivity: true},
+				},
+				Errors: map[string]string{
+					"zone1-0000000201": "performance_schema is disabled on the mysqld of tablet zone1-0000000201",
+				},
+			},
+		},
+		{
+			name: "max selectivity",
+			req: &vtctldatapb.AnalyzeIndexUsageRequest{
+				Keyspace:       "testkeyspace",
+				MaxSelectivity: 0.001,
+			},
+			expected: &vtctldatapb.AnalyzeIndexUsageResponse{
+				Indexes: []*vtctldatapb.IndexUsage{
+					{Table: "t1", Index: "PRIMARY", Unique: true, Reads: 80, Cardinality: 4000, TableRows: 4000, Selectivity: 1},
+					{Table: "t1", Index: "idx_name", Reads: 7, Cardinality: 3700, TableRows: 4000, Selectivity: 0.925},
+					{Table: "t1", Index: "idx_status", Reads: 0, Cardinality: 5, TableRows: 4000, Selectivity: 0.00125, Unused: true},
+				},
+				Errors: map[string]string{
+					"zone1-0000000201": "performance_schema is disabled on the mysqld of tablet zone1-0000000201",
+				},
+			},
+		},
+		{
+			name:      "no keyspace",
+			req:       &vtctldatapb.AnalyzeIndexUsageRequest{},
+			shouldErr: true,
+		},
+		{
+			name: "invalid max selectivity",
+			req: &vtctldatapb.AnalyzeIndexUsageRequest{
+				Keyspace:       "testkeyspace",
+				MaxSelectivity: 1.5,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			req: &vtctldatapb.AnalyzeIndexUsageRequest{
+				Keyspace: "otherkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, nil, tablets...)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.AnalyzeIndexUsage(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestAnalyzeShardDistribution(t *testing.T) {
+	t.Parallel()
+
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "testkeyspace",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+			Keyspace: "testkeyspace",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_RDONLY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "otherkeyspace",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	}
+	ids := func(rows ...string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), rows...))
+	}
+	tmc := &testutil.TabletManagerClient{
+		ExecuteFetchAsDbaQueryResults: map[string]map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000102": {
+				fmt.Sprintf(sqlTableStats, "'vt_testkeyspace'", "'t1'"): {
+					Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+						sqltypes.MakeTestFields("table_name|table_rows|table_size", "varchar|uint64|uint64"),
+						"t1|4|400",
+					)),
+				},
+				// The keyspace ids of 1, 2 and 3 fall into -80, of 4 into 80-.
+				"select `id` from `vt_testkeyspace`.`t1` limit 10000": {
+					Response: ids("1", "2", "3", "4"),
+				},
+				"select `id` from `vt_testkeyspace`.`t1` where rand() < 0.5 limit 2": {
+					Response: ids("1", "4"),
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.AnalyzeShardDistributionRequest
+		expected  *vtctldatapb.AnalyzeShardDistributionResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace:       "testkeyspace",
+				ProposedShards: 2,
+			},
+			expected: &vtctldatapb.AnalyzeShardDistributionResponse{
+				Shards: []*vtctldatapb.ShardDistribution{
+					{Shard: "-80", SampledRows: 3, EstimatedRows: 3, EstimatedSize: 300, Skew: 1.5, EstimatedRowsByTable: map[string]uint64{"t1": 3}},
+					{Shard: "80-", SampledRows: 1, EstimatedRows: 1, EstimatedSize: 100, Skew: 0.5, EstimatedRowsByTable: map[string]uint64{"t1": 1}},
+				},
+				Errors: map[string]string{},
+			},
+		},
+		{
+			name: "sampled",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace:       "testkeyspace",
+				ProposedShards: 2,
+				Tables:         []string{"t1"},
+				SampleSize:     2,
+			},
+			expected: &vtctldatapb.AnalyzeShardDistributionResponse{
+				Shards: []*vtctldatapb.ShardDistribution{
+					{Shard: "-80", SampledRows: 1, EstimatedRows: 2, EstimatedSize: 200, Skew: 1, EstimatedRowsByTable: map[string]uint64{"t1": 2}},
+					{Shard: "80-", SampledRows: 1, EstimatedRows: 2, EstimatedSize: 200, Skew: 1, EstimatedRowsByTable: map[string]uint64{"t1": 2}},
+				},
+				Errors: map[string]string{},
+			},
+		},
+		{
+			name: "no tablet to sample",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace:       "otherkeyspace",
+				ProposedShards: 1,
+			},
+			expected: &vtctldatapb.AnalyzeShardDistributionResponse{
+				Shards: []*vtctldatapb.ShardDistribution{
+					{Shard: "-", EstimatedRowsByTable: map[string]uint64{}},
+				},
+				Errors: map[string]string{
+					"0": "no rdonly or replica tablet to sample shard otherkeyspace/0 on",
+				},
+			},
+		},
+		{
+			name: "no primary vindex",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace:       "testkeyspace",
+				ProposedShards: 2,
+				Tables:         []string{"t2"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "lookup vindex",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace:       "testkeyspace",
+				ProposedShards: 2,
+				Tables:         []string{"t3"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no proposed shards",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no keyspace",
+			req: &vtctldatapb.AnalyzeShardDistributionRequest{
+				ProposedShards: 2,
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, nil, tablets...)
+	require.NoError(t, ts.SaveVSchema(ctx, "testkeyspace", &vschemapb.Keyspace{
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"t3_lookup": {
+				Type:   "lookup_unique",
+				Params: map[string]string{"table": "t3_lookup", "from": "name", "to": "keyspace_id"},
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+			"t2": {},
+			"t3": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "name", Name: "t3_lookup"}}},
+		},
+	}))
+	require.NoError(t, ts.SaveVSchema(ctx, "otherkeyspace", &vschemapb.Keyspace{
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+		},
+	}))
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.AnalyzeShardDistribution(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestApplyRoutingRules(t *testing.T) {
 	t.Parallel()
 
@@ -484,6 +854,65 @@ func TestApplyVSchema(t *testing.T) {
 	}
 }
 
+func TestApplyVSchemaValidateAgainstSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+						{Name: "t1", Columns: []string{"id"}},
+					},
+				},
+			},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	})
+
+	_, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace: "testkeyspace",
+		VSchema: &vschemapb.Keyspace{
+			Tables: map[string]*vschemapb.Table{
+				"t1": {},
+				"t2": {},
+			},
+		},
+		ValidateAgainstSchema: true,
+	})
+	assert.ErrorContains(t, err, "testkeyspace/0: table t2 does not exist")
+	_, err = ts.GetVSchema(ctx, "testkeyspace")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "the vschema was saved: %v", err)
+
+	vs := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
+		},
+	}
+	resp, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace:              "testkeyspace",
+		VSchema:               vs,
+		ValidateAgainstSchema: true,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, vs, resp.VSchema)
+}
+
 func TestBackup(t *testing.T) {
 	ctx := context.Background()
 	tests := []struct {
@@ -533,6 +962,46 @@ func TestBackup(t *testing.T) {
 				assert.Equal(t, 3, len(responses), "expected 3 messages from backupclient stream")
 			},
 		},
+		{
+			name: "detach",
+			ts:   memorytopo.NewServer("zone1"),
+			tmc: &testutil.TabletManagerClient{
+				Backups: map[string]struct {
+					Events        []*logutilpb.Event
+					EventInterval time.Duration
+					EventJitter   time.Duration
+					ErrorAfter    time.Duration
+				}{
+					"zone1-0000000100": {
+						Events: []*logutilpb.Event{{}, {}, {}},
+					},
+				},
+				SetReplicationSourceResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			tablet: &topodatapb.Tablet{
+				Alias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				Type:     topodatapb.TabletType_REPLICA,
+				Keyspace: "ks",
+				Shard:    "-",
+			},
+			req: &vtctldatapb.BackupRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				Detach: true,
+			},
+			assertion: func(t *testing.T, responses []*vtctldatapb.BackupResponse, err error) {
+				assert.ErrorIs(t, err, io.EOF, "expected Recv loop to end with io.EOF")
+				require.Equal(t, 1, len(responses), "expected only the start of the detached backup")
+				assert.Equal(t, "backup of tablet zone1-0000000100 started in the background", responses[0].Event.Value)
+			},
+		},
 		{
 			name: "cannot backup primary",
 			ts:   memorytopo.NewServer("zone1"),
@@ -3328,12 +3797,197 @@ func TestDeleteTablets(t *testing.T) {
 	}
 }
 
-func TestEmergencyReparentShard(t *testing.T) {
+func TestDrainTablet(t *testing.T) {
+	drainTabletPollInterval = time.Millisecond
 	t.Parallel()
 
-	tests := []struct {
-		name    string
-		ts      *topo.Server
+	type drainTabletResult = struct {
+		Response *tabletmanagerdatapb.DrainTabletResponse
+		Error    error
+	}
+
+	replica := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_REPLICA,
+	}
+
+	tests := []struct {
+		name         string
+		tablet       *topodatapb.Tablet
+		results      []drainTabletResult
+		req          *vtctldatapb.DrainTabletRequest
+		expected     *vtctldatapb.DrainTabletResponse
+		expectedType topodatapb.TabletType
+		shouldErr    bool
+	}{
+		{
+			name:   "success",
+			tablet: replica,
+			results: []drainTabletResult{
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true, InFlightQueries: 2}},
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true, InFlightQueries: 1, OpenTransactions: 1}},
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true}},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+			},
+			expected: &vtctldatapb.DrainTabletResponse{
+				Tablet: &topodatapb.Tablet{
+					Alias:    replica.Alias,
+					Keyspace: "ks",
+					Shard:    "0",
+					Type:     topodatapb.TabletType_DRAINED,
+				},
+			},
+			expectedType: topodatapb.TabletType_DRAINED,
+		},
+		{
+			name:   "idle tablet",
+			tablet: replica,
+			results: []drainTabletResult{
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true}},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+			},
+			expected: &vtctldatapb.DrainTabletResponse{
+				Tablet: &topodatapb.Tablet{
+					Alias:    replica.Alias,
+					Keyspace: "ks",
+					Shard:    "0",
+					Type:     topodatapb.TabletType_DRAINED,
+				},
+			},
+			expectedType: topodatapb.TabletType_DRAINED,
+		},
+		{
+			name:   "undo",
+			tablet: replica,
+			results: []drainTabletResult{
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{InFlightQueries: 3}},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+				Undo:        true,
+			},
+			expected: &vtctldatapb.DrainTabletResponse{
+				Tablet:          replica,
+				InFlightQueries: 3,
+			},
+			expectedType: topodatapb.TabletType_REPLICA,
+		},
+		{
+			name:   "timeout",
+			tablet: replica,
+			results: []drainTabletResult{
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true, InFlightQueries: 1}},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+				WaitTimeout: protoutil.DurationToProto(10 * time.Millisecond),
+			},
+			expectedType: topodatapb.TabletType_REPLICA,
+			shouldErr:    true,
+		},
+		{
+			name:   "tabletmanager failure",
+			tablet: replica,
+			results: []drainTabletResult{
+				{Error: assert.AnError},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+			},
+			expectedType: topodatapb.TabletType_REPLICA,
+			shouldErr:    true,
+		},
+		{
+			name: "primary tablet",
+			tablet: &topodatapb.Tablet{
+				Alias:    replica.Alias,
+				Keyspace: "ks",
+				Shard:    "0",
+				Type:     topodatapb.TabletType_PRIMARY,
+			},
+			results: []drainTabletResult{
+				{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true}},
+			},
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: replica.Alias,
+			},
+			expectedType: topodatapb.TabletType_PRIMARY,
+			shouldErr:    true,
+		},
+		{
+			name:   "tablet not found",
+			tablet: replica,
+			req: &vtctldatapb.DrainTabletRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  404,
+				},
+			},
+			expectedType: topodatapb.TabletType_REPLICA,
+			shouldErr:    true,
+		},
+		{
+			name:         "no tablet alias",
+			tablet:       replica,
+			req:          &vtctldatapb.DrainTabletRequest{},
+			expectedType: topodatapb.TabletType_REPLICA,
+			shouldErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			tmc := &testutil.TabletManagerClient{
+				TopoServer: ts,
+				DrainTabletResults: map[string][]struct {
+					Response *tabletmanagerdatapb.DrainTabletResponse
+					Error    error
+				}{
+					"zone1-0000000100": tt.results,
+				},
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			testutil.AddTablets(ctx, t, ts, nil, tt.tablet)
+
+			resp, err := vtctld.DrainTablet(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				utils.MustMatch(t, tt.expected, resp)
+			}
+
+			tablet, err := ts.GetTablet(ctx, tt.tablet.Alias)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedType, tablet.Type)
+		})
+	}
+}
+
+func TestEmergencyReparentShard(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ts      *topo.Server
 		tmc     tmclient.TabletManagerClient
 		tablets []*topodatapb.Tablet
 
@@ -3969,6 +4623,124 @@ func TestExecuteHook(t *testing.T) {
 	}
 }
 
+func TestExplainQuery(t *testing.T) {
+	t.Parallel()
+
+	tablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+	}
+	tmc := &testutil.TabletManagerClient{
+		ExplainQueryResults: map[string]struct {
+			Response *tabletmanagerdatapb.ExplainQueryResponse
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: &tabletmanagerdatapb.ExplainQueryResponse{
+					Query: "explain select 1 from dual",
+					Result: &querypb.QueryResult{
+						RowsAffected: 1,
+					},
+				},
+			},
+			"zone1-0000000101": {
+				Error: assert.AnError,
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.ExplainQueryRequest
+		expected  *vtctldatapb.ExplainQueryResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.ExplainQueryRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				ExplainQueryRequest: &tabletmanagerdatapb.ExplainQueryRequest{
+					Query: "select 1 from dual",
+				},
+			},
+			expected: &vtctldatapb.ExplainQueryResponse{
+				ExplainQueryResponse: &tabletmanagerdatapb.ExplainQueryResponse{
+					Query: "explain select 1 from dual",
+					Result: &querypb.QueryResult{
+						RowsAffected: 1,
+					},
+				},
+			},
+		},
+		{
+			name: "nil request",
+			req: &vtctldatapb.ExplainQueryRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet not found",
+			req: &vtctldatapb.ExplainQueryRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  404,
+				},
+				ExplainQueryRequest: &tabletmanagerdatapb.ExplainQueryRequest{
+					Query: "select 1 from dual",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet error",
+			req: &vtctldatapb.ExplainQueryRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  101,
+				},
+				ExplainQueryRequest: &tabletmanagerdatapb.ExplainQueryRequest{
+					Query: "select 1 from dual",
+				},
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, nil, tablet, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  101,
+		},
+	})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.ExplainQuery(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestFindAllShardsInKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -4097,6 +4869,25 @@ func TestGetBackups(t *testing.T) {
 		utils.MustMatch(t, expected, resp)
 	})
 
+	t.Run("detailed", func(t *testing.T) {
+		testutil.BackupStorage.Backups["ks3/-"] = []string{"backup1", "backup2"}
+		testutil.BackupStorage.Manifests = map[string]string{
+			"ks3/-/backup1": `{"BackupMethod": "xtrabackup"}`,
+		}
+		defer func() { testutil.BackupStorage.Manifests = nil }()
+
+		resp, err := vtctld.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
+			Keyspace: "ks3",
+			Shard:    "-",
+			Detailed: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Backups, 2)
+		assert.Equal(t, "xtrabackup", resp.Backups[0].Engine)
+		assert.Equal(t, mysqlctlpb.BackupInfo_COMPLETE, resp.Backups[0].Status)
+		assert.Equal(t, mysqlctlpb.BackupInfo_INCOMPLETE, resp.Backups[1].Status)
+	})
+
 	t.Run("limiting", func(t *testing.T) {
 		unlimited, err := vtctld.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
 			Keyspace: "testkeyspace",
@@ -4142,82 +4933,449 @@ func TestGetKeyspace(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestGetCellInfoNames(t *testing.T) {
+func TestKeyspaceSettings(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
-	ts := memorytopo.NewServer("cell1", "cell2", "cell3")
+	ts := memorytopo.NewServer("cell1")
 	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 		return NewVtctldServer(ts)
 	})
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}})
+	require.NoError(t, ts.SaveKeyspaceSettings(ctx, "ks1", &topo.KeyspaceSettings{
+		ShedPercent:  10,
+		FeatureGates: map[string]bool{"ParallelVPlayer": false},
+	}))
 
-	resp, err := vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
-	assert.NoError(t, err)
-	assert.ElementsMatch(t, []string{"cell1", "cell2", "cell3"}, resp.Names)
-
-	ts = memorytopo.NewServer()
-	vtctld = testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
-		return NewVtctldServer(ts)
+	get, err := vtctld.GetKeyspaceSettings(ctx, &vtctldatapb.GetKeyspaceSettingsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.KeyspaceSettings{
+		ShedPercent:  10,
+		FeatureGates: map[string]bool{"ParallelVPlayer": false},
+	}, get.Settings)
+
+	// Only the named settings are changed.
+	set, err := vtctld.SetKeyspaceSettings(ctx, &vtctldatapb.SetKeyspaceSettingsRequest{
+		Keyspace: "ks1",
+		Settings: &vtctldatapb.KeyspaceSettings{
+			NoScatter:       true,
+			DefaultWorkload: "olap",
+			ShedPercent:     50,
+		},
+		Fields: []string{"no_scatter", "default_workload"},
 	})
-
-	resp, err = vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
-	assert.NoError(t, err)
-	assert.Empty(t, resp.Names)
-
-	ts, topofactory := memorytopo.NewServerAndFactory("cell1")
-	vtctld = testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
-		return NewVtctldServer(ts)
+	require.NoError(t, err)
+	want := &vtctldatapb.KeyspaceSettings{
+		NoScatter:       true,
+		DefaultWorkload: "OLAP",
+		ShedPercent:     10,
+		FeatureGates:    map[string]bool{"ParallelVPlayer": false},
+	}
+	utils.MustMatch(t, want, set.Settings)
+	get, err = vtctld.GetKeyspaceSettings(ctx, &vtctldatapb.GetKeyspaceSettingsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, get.Settings)
+
+	_, err = vtctld.SetKeyspaceSettings(ctx, &vtctldatapb.SetKeyspaceSettingsRequest{Keyspace: "ks1"})
+	assert.ErrorContains(t, err, "no keyspace settings to change")
+	_, err = vtctld.SetKeyspaceSettings(ctx, &vtctldatapb.SetKeyspaceSettingsRequest{Keyspace: "ks1", Fields: []string{"feature_gates"}})
+	assert.ErrorContains(t, err, "unknown keyspace setting: feature_gates")
+	_, err = vtctld.SetKeyspaceSettings(ctx, &vtctldatapb.SetKeyspaceSettingsRequest{
+		Keyspace: "ks1",
+		Settings: &vtctldatapb.KeyspaceSettings{ShedPercent: 200},
+		Fields:   []string{"shed_percent"},
 	})
-
-	topofactory.SetError(assert.AnError)
-	_, err = vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
+	assert.ErrorContains(t, err, "invalid shed percent: 200")
+	_, err = vtctld.SetKeyspaceSettings(ctx, &vtctldatapb.SetKeyspaceSettingsRequest{Keyspace: "notfound", Fields: []string{"no_scatter"}})
+	assert.Error(t, err)
+	_, err = vtctld.GetKeyspaceSettings(ctx, &vtctldatapb.GetKeyspaceSettingsRequest{Keyspace: "notfound"})
 	assert.Error(t, err)
 }
 
-func TestGetCellInfo(t *testing.T) {
+func TestMySQLUserLimits(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
-	ts := memorytopo.NewServer()
+	ts := memorytopo.NewServer("cell1")
 	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 		return NewVtctldServer(ts)
 	})
 
-	expected := &topodatapb.CellInfo{
-		ServerAddress: "example.com",
-		Root:          "vitess",
-	}
-	input := proto.Clone(expected).(*topodatapb.CellInfo)
-	require.NoError(t, ts.CreateCellInfo(ctx, "cell1", input))
+	get, err := vtctld.GetMySQLUserLimits(ctx, &vtctldatapb.GetMySQLUserLimitsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, get.Limits)
 
-	resp, err := vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{Cell: "cell1"})
-	assert.NoError(t, err)
-	utils.MustMatch(t, expected, resp.CellInfo)
+	set, err := vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{
+		User:   "app",
+		Limits: &vtctldatapb.MySQLUserLimits{MaxConnections: 10, MaxConcurrentQueries: 5},
+		Fields: []string{"max_connections", "max_concurrent_queries"},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.MySQLUserLimits{MaxConnections: 10, MaxConcurrentQueries: 5}, set.Limits)
 
-	_, err = vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{Cell: "does_not_exist"})
-	assert.Error(t, err)
+	// Only the named limits are changed.
+	set, err = vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{
+		User:   "app",
+		Limits: &vtctldatapb.MySQLUserLimits{MaxConnections: 20},
+		Fields: []string{"max_connections"},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.MySQLUserLimits{MaxConnections: 20, MaxConcurrentQueries: 5}, set.Limits)
 
-	_, err = vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{})
-	assert.Error(t, err)
+	get, err = vtctld.GetMySQLUserLimits(ctx, &vtctldatapb.GetMySQLUserLimitsRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]*vtctldatapb.MySQLUserLimits{"app": {MaxConnections: 20, MaxConcurrentQueries: 5}}, get.Limits)
+	get, err = vtctld.GetMySQLUserLimits(ctx, &vtctldatapb.GetMySQLUserLimitsRequest{User: "other"})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]*vtctldatapb.MySQLUserLimits{"other": {}}, get.Limits)
+
+	_, err = vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{User: "app"})
+	assert.ErrorContains(t, err, "no MySQL user limits to change")
+	_, err = vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{Fields: []string{"max_connections"}})
+	assert.ErrorContains(t, err, "the user is required")
+	_, err = vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{User: "app", Fields: []string{"max_rows"}})
+	assert.ErrorContains(t, err, "unknown MySQL user limit: max_rows")
+	_, err = vtctld.SetMySQLUserLimits(ctx, &vtctldatapb.SetMySQLUserLimitsRequest{
+		User:   "app",
+		Limits: &vtctldatapb.MySQLUserLimits{MaxConnections: -1},
+		Fields: []string{"max_connections"},
+	})
+	assert.ErrorContains(t, err, "invalid max connections: -1")
 }
 
-func TestGetCellsAliases(t *testing.T) {
+func TestArchivals(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
-	ts := memorytopo.NewServer("c11", "c12", "c13", "c21", "c22")
+	ts := memorytopo.NewServer("cell1")
 	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 		return NewVtctldServer(ts)
 	})
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}}, &vtctldatapb.Keyspace{Name: "archive", Keyspace: &topodatapb.Keyspace{}})
 
-	alias1 := &topodatapb.CellsAlias{
-		Cells: []string{"c11", "c12", "c13"},
-	}
-	alias2 := &topodatapb.CellsAlias{
-		Cells: []string{"c21", "c22"},
+	get, err := vtctld.GetArchivals(ctx, &vtctldatapb.GetArchivalsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	assert.Empty(t, get.Archivals)
+
+	create, err := vtctld.CreateArchival(ctx, &vtctldatapb.CreateArchivalRequest{
+		Keyspace: "ks1",
+		Archival: &vtctldatapb.Archival{
+			Name:           "old_orders",
+			Table:          "orders",
+			Where:          "created_at < now() - interval 90 day",
+			TargetKeyspace: "archive",
+			TargetTable:    "orders",
+			BatchSize:      100,
+			// The progress is kept by vtctld.
+			ArchivedRows: 10,
+		},
+	})
+	require.NoError(t, err)
+	want := &vtctldatapb.Archival{
+		Name:           "old_orders",
+		Table:          "orders",
+		Where:          "created_at < now() - interval 90 day",
+		TargetKeyspace: "archive",
+		TargetTable:    "orders",
+		BatchSize:      100,
 	}
+	utils.MustMatch(t, want, create.Archival)
 
-	for i, alias := range []*topodatapb.CellsAlias{alias1, alias2} {
+	paused, err := vtctld.SetArchivalPaused(ctx, &vtctldatapb.SetArchivalPausedRequest{Keyspace: "ks1", Name: "old_orders", Paused: true})
+	require.NoError(t, err)
+	want.Paused = true
+	utils.MustMatch(t, want, paused.Archival)
+
+	get, err = vtctld.GetArchivals(ctx, &vtctldatapb.GetArchivalsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtctldatapb.Archival{want}, get.Archivals)
+
+	_, err = vtctld.CreateArchival(ctx, &vtctldatapb.CreateArchivalRequest{Keyspace: "ks1"})
+	assert.ErrorContains(t, err, "archival is required")
+	_, err = vtctld.CreateArchival(ctx, &vtctldatapb.CreateArchivalRequest{
+		Keyspace: "ks1",
+		Archival: &vtctldatapb.Archival{Name: "a", Table: "t", Where: "id < 10", TargetKeyspace: "notfound", TargetTable: "t", BatchSize: 1},
+	})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected a NoNode error for the target keyspace, got %v", err)
+	_, err = vtctld.CreateArchival(ctx, &vtctldatapb.CreateArchivalRequest{
+		Keyspace: "ks1",
+		Archival: &vtctldatapb.Archival{Name: "a", Table: "t", Where: "id < 10", TargetTable: "t"},
+	})
+	assert.ErrorContains(t, err, "invalid batch size: 0")
+
+	_, err = vtctld.DeleteArchival(ctx, &vtctldatapb.DeleteArchivalRequest{Keyspace: "ks1", Name: "old_orders"})
+	require.NoError(t, err)
+	get, err = vtctld.GetArchivals(ctx, &vtctldatapb.GetArchivalsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	assert.Empty(t, get.Archivals)
+	_, err = vtctld.SetArchivalPaused(ctx, &vtctldatapb.SetArchivalPausedRequest{Keyspace: "ks1", Name: "old_orders"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected a NoNode error for a deleted archival, got %v", err)
+}
+
+func TestPartitionPolicies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}})
+
+	get, err := vtctld.GetPartitionPolicies(ctx, &vtctldatapb.GetPartitionPoliciesRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	assert.Empty(t, get.PartitionPolicies)
+
+	policy := &vtctldatapb.PartitionPolicy{Table: "events", Interval: "day", Precreate: 7, Retention: 30}
+	set, err := vtctld.SetPartitionPolicy(ctx, &vtctldatapb.SetPartitionPolicyRequest{Keyspace: "ks1", PartitionPolicy: policy})
+	require.NoError(t, err)
+	utils.MustMatch(t, policy, set.PartitionPolicy)
+
+	// The policy of a table is replaced.
+	policy = &vtctldatapb.PartitionPolicy{Table: "events", Interval: "month", Precreate: 2}
+	_, err = vtctld.SetPartitionPolicy(ctx, &vtctldatapb.SetPartitionPolicyRequest{Keyspace: "ks1", PartitionPolicy: policy})
+	require.NoError(t, err)
+	get, err = vtctld.GetPartitionPolicies(ctx, &vtctldatapb.GetPartitionPoliciesRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtctldatapb.PartitionPolicy{policy}, get.PartitionPolicies)
+
+	_, err = vtctld.SetPartitionPolicy(ctx, &vtctldatapb.SetPartitionPolicyRequest{Keyspace: "ks1"})
+	assert.ErrorContains(t, err, "partition policy is required")
+	_, err = vtctld.SetPartitionPolicy(ctx, &vtctldatapb.SetPartitionPolicyRequest{
+		Keyspace:        "ks1",
+		PartitionPolicy: &vtctldatapb.PartitionPolicy{Table: "events", Interval: "year", Precreate: 1},
+	})
+	assert.ErrorContains(t, err, `invalid partition interval of table events: "year"`)
+	_, err = vtctld.SetPartitionPolicy(ctx, &vtctldatapb.SetPartitionPolicyRequest{Keyspace: "notfound", PartitionPolicy: policy})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected a NoNode error for the keyspace, got %v", err)
+
+	_, err = vtctld.DeletePartitionPolicy(ctx, &vtctldatapb.DeletePartitionPolicyRequest{Keyspace: "ks1", Table: "events"})
+	require.NoError(t, err)
+	get, err = vtctld.GetPartitionPolicies(ctx, &vtctldatapb.GetPartitionPoliciesRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	assert.Empty(t, get.PartitionPolicies)
+}
+
+func TestDBAScripts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	tmc := &testutil.TabletManagerClient{
+		ExecuteFetchAsDbaResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000101": {
+				Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "1")),
+			},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "ks1", Shard: "-", Type: topodatapb.TabletType_PRIMARY},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}, Keyspace: "ks1", Shard: "-", Type: topodatapb.TabletType_REPLICA},
+	)
+	replica := &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}
+
+	req := &vtctldatapb.ExecDBARequest{
+		Keyspace:        "ks1",
+		Name:            "s1",
+		Sql:             "select 1 from dual; select 2 from dual",
+		TabletTypes:     []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+		RequireApproval: true,
+	}
+	_, err := vtctld.ExecDBA(ctx, req)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "an unauthenticated caller must not submit a script: %v", err)
+
+	alice := servenv.NewAuthenticatedUserContext(ctx, "alice")
+	bob := servenv.NewAuthenticatedUserContext(ctx, "bob")
+
+	dryRun := proto.Clone(req).(*vtctldatapb.ExecDBARequest)
+	dryRun.DryRun = true
+	resp, err := vtctld.ExecDBA(alice, dryRun)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 1 from dual", "select 2 from dual"}, resp.Statements)
+	utils.MustMatch(t, []*topodatapb.TabletAlias{replica}, resp.Tablets)
+
+	resp, err = vtctld.ExecDBA(alice, req)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", resp.Script.SubmittedBy)
+	assert.Equal(t, topo.DBAScriptPending, resp.Script.State)
+	assert.Empty(t, resp.Results)
+
+	_, err = vtctld.ApproveDBAScript(ctx, &vtctldatapb.ApproveDBAScriptRequest{Keyspace: "ks1", Name: "s1"})
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "an unauthenticated caller must not approve a script: %v", err)
+	_, err = vtctld.ApproveDBAScript(alice, &vtctldatapb.ApproveDBAScriptRequest{Keyspace: "ks1", Name: "s1"})
+	assert.ErrorContains(t, err, "must be approved by another user than its submitter alice")
+
+	approve, err := vtctld.ApproveDBAScript(bob, &vtctldatapb.ApproveDBAScriptRequest{Keyspace: "ks1", Name: "s1"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", approve.Script.ApprovedBy)
+	assert.Equal(t, topo.DBAScriptDone, approve.Script.State)
+	require.Len(t, approve.Results, 1)
+	utils.MustMatch(t, replica, approve.Results[0].TabletAlias)
+	require.Len(t, approve.Results[0].Statements, 2)
+	utils.MustMatch(t, &vtctldatapb.DBAScriptStatementResult{
+		Sql:    "select 1 from dual",
+		Fields: []string{"a"},
+		Rows:   []*vtctldatapb.DBAScriptStatementResult_Row{{Values: []string{"1"}}},
+	}, approve.Results[0].Statements[0])
+
+	_, err = vtctld.ApproveDBAScript(bob, &vtctldatapb.ApproveDBAScriptRequest{Keyspace: "ks1", Name: "s1"})
+	assert.ErrorContains(t, err, "not pending approval")
+
+	get, err := vtctld.GetDBAScripts(ctx, &vtctldatapb.GetDBAScriptsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	require.Len(t, get.Scripts, 1)
+	var actions []string
+	for _, entry := range get.Scripts[0].Audit {
+		actions = append(actions, entry.User+":"+entry.Action)
+	}
+	assert.Equal(t, []string{"alice:submitted", "bob:approved", ":ran"}, actions)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_REPLICA}, get.Scripts[0].TabletTypes)
+}
+
+func TestFeatureGates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}}, &vtctldatapb.Keyspace{Name: "ks2", Keyspace: &topodatapb.Keyspace{}})
+
+	resp, err := vtctld.GetFeatureGates(ctx, &vtctldatapb.GetFeatureGatesRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.FeatureGates)
+	for _, fg := range resp.FeatureGates {
+		assert.Empty(t, fg.KeyspaceOverrides, fg.Name)
+	}
+
+	_, err = vtctld.SetFeatureGate(ctx, &vtctldatapb.SetFeatureGateRequest{Keyspace: "ks1", Name: "NoSuchGate", Enabled: true})
+	assert.EqualError(t, err, "unknown feature gate: NoSuchGate")
+	_, err = vtctld.SetFeatureGate(ctx, &vtctldatapb.SetFeatureGateRequest{Keyspace: "notfound", Name: "ParallelVPlayer"})
+	assert.Error(t, err)
+
+	set, err := vtctld.SetFeatureGate(ctx, &vtctldatapb.SetFeatureGateRequest{Keyspace: "ks1", Name: "ParallelVPlayer", Enabled: false})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.FeatureGate{
+		Name:              "ParallelVPlayer",
+		Description:       featuregate.ParallelVPlayer.Description(),
+		Stage:             "beta",
+		EnabledByDefault:  true,
+		KeyspaceOverrides: map[string]bool{"ks1": false},
+	}, set.FeatureGate)
+	_, err = vtctld.SetFeatureGate(ctx, &vtctldatapb.SetFeatureGateRequest{Keyspace: "ks2", Name: "ParallelVPlayer", Enabled: true})
+	require.NoError(t, err)
+
+	settings, err := ts.GetKeyspaceSettings(ctx, "ks1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"ParallelVPlayer": false}, settings.FeatureGates)
+
+	getOverrides := func(keyspace string) map[string]bool {
+		resp, err := vtctld.GetFeatureGates(ctx, &vtctldatapb.GetFeatureGatesRequest{Keyspace: keyspace})
+		require.NoError(t, err)
+		for _, fg := range resp.FeatureGates {
+			if fg.Name == "ParallelVPlayer" {
+				return fg.KeyspaceOverrides
+			}
+		}
+		require.FailNow(t, "missing the ParallelVPlayer feature gate")
+		return nil
+	}
+	assert.Equal(t, map[string]bool{"ks1": false, "ks2": true}, getOverrides(""))
+	assert.Equal(t, map[string]bool{"ks2": true}, getOverrides("ks2"))
+
+	// Clearing the override of a keyspace leaves the other keyspaces alone.
+	set, err = vtctld.SetFeatureGate(ctx, &vtctldatapb.SetFeatureGateRequest{Keyspace: "ks1", Name: "ParallelVPlayer", Clear: true})
+	require.NoError(t, err)
+	assert.Empty(t, set.FeatureGate.KeyspaceOverrides)
+	assert.Equal(t, map[string]bool{"ks2": true}, getOverrides(""))
+	settings, err = ts.GetKeyspaceSettings(ctx, "ks1")
+	require.NoError(t, err)
+	assert.Nil(t, settings.FeatureGates)
+}
+
+func TestGetCellInfoNames(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1", "cell2", "cell3")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cell1", "cell2", "cell3"}, resp.Names)
+
+	ts = memorytopo.NewServer()
+	vtctld = testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err = vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Names)
+
+	ts, topofactory := memorytopo.NewServerAndFactory("cell1")
+	vtctld = testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	topofactory.SetError(assert.AnError)
+	_, err = vtctld.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
+	assert.Error(t, err)
+}
+
+func TestGetCellInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	expected := &topodatapb.CellInfo{
+		ServerAddress: "example.com",
+		Root:          "vitess",
+	}
+	input := proto.Clone(expected).(*topodatapb.CellInfo)
+	require.NoError(t, ts.CreateCellInfo(ctx, "cell1", input))
+
+	resp, err := vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{Cell: "cell1"})
+	assert.NoError(t, err)
+	utils.MustMatch(t, expected, resp.CellInfo)
+
+	_, err = vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{Cell: "does_not_exist"})
+	assert.Error(t, err)
+
+	_, err = vtctld.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{})
+	assert.Error(t, err)
+}
+
+func TestGetCellsAliases(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("c11", "c12", "c13", "c21", "c22")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	alias1 := &topodatapb.CellsAlias{
+		Cells: []string{"c11", "c12", "c13"},
+	}
+	alias2 := &topodatapb.CellsAlias{
+		Cells: []string{"c21", "c22"},
+	}
+
+	for i, alias := range []*topodatapb.CellsAlias{alias1, alias2} {
 		input := proto.Clone(alias).(*topodatapb.CellsAlias)
 		name := fmt.Sprintf("a%d", i+1)
 		require.NoError(t, ts.CreateCellsAlias(ctx, name, input), "cannot create cells alias %d (idx = %d) = %+v", i+1, i, input)
@@ -4502,6 +5660,82 @@ func TestGetRoutingRules(t *testing.T) {
 	}
 }
 
+func TestRuntimeFlags(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	grpcruntimeflagsserver.RegisterServer(server)
+	servenv.RegisterVersionServer(server)
+	go server.Serve(listener)
+	defer server.Stop()
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	// A component of an older release has no Version service, and thus no
+	// runtime flags.
+	oldListener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	oldServer := grpc.NewServer()
+	go oldServer.Serve(oldListener)
+	defer oldServer.Stop()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname: "localhost",
+		PortMap:  map[string]int32{"grpc": port},
+	}, nil)
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+	}, nil)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.GetRuntimeFlags(ctx, &vtctldatapb.GetRuntimeFlagsRequest{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+	})
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, f := range resp.Flags {
+		values[f.Name] = f.Value
+	}
+	require.Contains(t, values, "v")
+
+	// The verbosity of the logs keeps its value, to not change the logs of the
+	// other tests.
+	setResp, err := vtctld.SetRuntimeFlag(servenv.NewAuthenticatedUserContext(ctx, "alice"), &vtctldatapb.SetRuntimeFlagRequest{
+		Address: listener.Addr().String(),
+		Name:    "v",
+		Value:   values["v"],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v", setResp.Change.Name)
+	// The component runs without an auth plugin, so vtctld is recorded by
+	// its address.
+	assert.Regexp(t, `^127\.0\.0\.1:\d+ on behalf of alice$`, setResp.Change.ChangedBy)
+
+	_, err = vtctld.SetRuntimeFlag(ctx, &vtctldatapb.SetRuntimeFlagRequest{
+		Address: listener.Addr().String(),
+		Name:    "no_such_flag",
+		Value:   "1",
+	})
+	assert.ErrorContains(t, err, "flag no_such_flag cannot be changed at runtime")
+
+	_, err = vtctld.GetRuntimeFlags(ctx, &vtctldatapb.GetRuntimeFlagsRequest{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+	})
+	assert.ErrorContains(t, err, "tablet zone1-0000000101 has no grpc port")
+	_, err = vtctld.GetRuntimeFlags(ctx, &vtctldatapb.GetRuntimeFlagsRequest{})
+	assert.ErrorContains(t, err, "must set a tablet alias or an address")
+	_, err = vtctld.GetRuntimeFlags(ctx, &vtctldatapb.GetRuntimeFlagsRequest{
+		Address: oldListener.Addr().String(),
+	})
+	assert.ErrorContains(t, err, "does not serve the runtime flags")
+}
+
 func TestGetSchema(t *testing.T) {
 	ctx := context.Background()
 	ts := memorytopo.NewServer("zone1")
@@ -4922,81 +6156,231 @@ func TestGetSrvKeyspaceNames(t *testing.T) {
 	}
 }
 
-func TestGetSrvKeyspaces(t *testing.T) {
+func TestGetSrvKeyspacePartitions(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
+	shardReference := func(name string, start, end []byte) *topodatapb.ShardReference {
+		return &topodatapb.ShardReference{
+			Name:     name,
+			KeyRange: &topodatapb.KeyRange{Start: start, End: end},
+		}
+	}
+
 	tests := []struct {
-		name         string
-		cells        []string
-		srvKeyspaces []*testutil.SrvKeyspace
-		topoErr      error
-		req          *vtctldatapb.GetSrvKeyspacesRequest
-		expected     *vtctldatapb.GetSrvKeyspacesResponse
-		shouldErr    bool
+		name      string
+		shards    []string
+		partition []*topodatapb.SrvKeyspace_KeyspacePartition
+		// expectedErrors are substrings of the errors expected in zone1.
+		expectedErrors []string
 	}{
 		{
-			name:  "success",
-			cells: []string{"zone1", "zone2"},
-			srvKeyspaces: []*testutil.SrvKeyspace{
-				{
-					Cell:        "zone1",
-					Keyspace:    "testkeyspace",
-					SrvKeyspace: &topodatapb.SrvKeyspace{},
-				},
+			name:   "valid",
+			shards: []string{"-80", "80-"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
 				{
-					Cell:        "zone2",
-					Keyspace:    "testkeyspace",
-					SrvKeyspace: &topodatapb.SrvKeyspace{},
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("80-", []byte{0x80}, nil),
+						shardReference("-80", nil, []byte{0x80}),
+					},
 				},
 			},
-			req: &vtctldatapb.GetSrvKeyspacesRequest{
-				Keyspace: "testkeyspace",
-			},
-			expected: &vtctldatapb.GetSrvKeyspacesResponse{
-				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
-					"zone1": {},
-					"zone2": {},
+		},
+		{
+			name:   "gap",
+			shards: []string{"-80", "80-"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
+				{
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("-80", nil, []byte{0x80}),
+					},
 				},
 			},
-			shouldErr: false,
+			expectedErrors: []string{"does not end with max key"},
 		},
 		{
-			name:  "filtering by cell",
-			cells: []string{"zone1", "zone2"},
-			srvKeyspaces: []*testutil.SrvKeyspace{
+			name:   "overlap",
+			shards: []string{"-", "-80", "80-"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
 				{
-					Cell:        "zone1",
-					Keyspace:    "testkeyspace",
-					SrvKeyspace: &topodatapb.SrvKeyspace{},
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("-", nil, nil),
+						shardReference("80-", []byte{0x80}, nil),
+					},
 				},
+			},
+			expectedErrors: []string{"non-contiguous KeyRange values"},
+		},
+		{
+			name:   "missing shard",
+			shards: []string{"-80"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
 				{
-					Cell:        "zone2",
-					Keyspace:    "testkeyspace",
-					SrvKeyspace: &topodatapb.SrvKeyspace{},
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("-80", nil, []byte{0x80}),
+						shardReference("80-", []byte{0x80}, nil),
+					},
 				},
 			},
-			req: &vtctldatapb.GetSrvKeyspacesRequest{
-				Keyspace: "testkeyspace",
-				Cells:    []string{"zone1"},
-			},
-			expected: &vtctldatapb.GetSrvKeyspacesResponse{
-				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
-					"zone1": {},
+			expectedErrors: []string{"has shard 80-, which does not exist"},
+		},
+		{
+			name:   "key range mismatch",
+			shards: []string{"-80", "80-"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
+				{
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("-80", nil, []byte{0x40}),
+						shardReference("80-", []byte{0x40}, nil),
+					},
 				},
 			},
-			shouldErr: false,
+			expectedErrors: []string{"has KeyRange -40 for shard -80, whose KeyRange is -80"},
 		},
 		{
-			name:  "no srvkeyspace for single cell",
-			cells: []string{"zone1", "zone2"},
-			srvKeyspaces: []*testutil.SrvKeyspace{
+			name:   "duplicate served type",
+			shards: []string{"-80", "80-"},
+			partition: []*topodatapb.SrvKeyspace_KeyspacePartition{
 				{
-					Cell:        "zone1",
-					Keyspace:    "testkeyspace",
-					SrvKeyspace: &topodatapb.SrvKeyspace{},
+					ServedType: topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{
+						shardReference("-80", nil, []byte{0x80}),
+						shardReference("80-", []byte{0x80}, nil),
+					},
+				},
+				{
+					ServedType: topodatapb.TabletType_PRIMARY,
 				},
 			},
-			req: &vtctldatapb.GetSrvKeyspacesRequest{
+			expectedErrors: []string{"more than one keyspace partition for PRIMARY", "keyspace partition for PRIMARY in cell zone1 has no shards"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := memorytopo.NewServer("zone1", "zone2")
+			for _, shard := range tt.shards {
+				testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+					Keyspace: "testkeyspace",
+					Name:     shard,
+				})
+			}
+			testutil.AddSrvKeyspaces(t, ts, &testutil.SrvKeyspace{
+				Cell:     "zone1",
+				Keyspace: "testkeyspace",
+				SrvKeyspace: &topodatapb.SrvKeyspace{
+					Partitions: tt.partition,
+				},
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.GetSrvKeyspacePartitions(ctx, &vtctldatapb.GetSrvKeyspacePartitionsRequest{
+				Keyspace: "testkeyspace",
+			})
+			require.NoError(t, err)
+
+			// zone2 has no SrvKeyspace.
+			require.Len(t, resp.Partitions, 1)
+			require.Contains(t, resp.Partitions, "zone1")
+
+			partitions := resp.Partitions["zone1"]
+			utils.MustMatch(t, tt.partition, partitions.Partitions)
+			require.Len(t, partitions.Errors, len(tt.expectedErrors), "errors: %v", partitions.Errors)
+			for i, expected := range tt.expectedErrors {
+				assert.Contains(t, partitions.Errors[i], expected)
+			}
+		})
+	}
+}
+
+func TestGetSrvKeyspaces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cells        []string
+		srvKeyspaces []*testutil.SrvKeyspace
+		topoErr      error
+		req          *vtctldatapb.GetSrvKeyspacesRequest
+		expected     *vtctldatapb.GetSrvKeyspacesResponse
+		shouldErr    bool
+	}{
+		{
+			name:  "success",
+			cells: []string{"zone1", "zone2"},
+			srvKeyspaces: []*testutil.SrvKeyspace{
+				{
+					Cell:        "zone1",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: &topodatapb.SrvKeyspace{},
+				},
+				{
+					Cell:        "zone2",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: &topodatapb.SrvKeyspace{},
+				},
+			},
+			req: &vtctldatapb.GetSrvKeyspacesRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: &vtctldatapb.GetSrvKeyspacesResponse{
+				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
+					"zone1": {},
+					"zone2": {},
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name:  "filtering by cell",
+			cells: []string{"zone1", "zone2"},
+			srvKeyspaces: []*testutil.SrvKeyspace{
+				{
+					Cell:        "zone1",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: &topodatapb.SrvKeyspace{},
+				},
+				{
+					Cell:        "zone2",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: &topodatapb.SrvKeyspace{},
+				},
+			},
+			req: &vtctldatapb.GetSrvKeyspacesRequest{
+				Keyspace: "testkeyspace",
+				Cells:    []string{"zone1"},
+			},
+			expected: &vtctldatapb.GetSrvKeyspacesResponse{
+				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
+					"zone1": {},
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name:  "no srvkeyspace for single cell",
+			cells: []string{"zone1", "zone2"},
+			srvKeyspaces: []*testutil.SrvKeyspace{
+				{
+					Cell:        "zone1",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: &topodatapb.SrvKeyspace{},
+				},
+			},
+			req: &vtctldatapb.GetSrvKeyspacesRequest{
 				Keyspace: "testkeyspace",
 			},
 			expected: &vtctldatapb.GetSrvKeyspacesResponse{
@@ -5347,140 +6731,369 @@ func TestGetSrvVSchemas(t *testing.T) {
 	}
 }
 
-func TestGetTablet(t *testing.T) {
+func TestGetTableSizes(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
-	ts := memorytopo.NewServer("cell1")
-	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
-		return NewVtctldServer(ts)
-	})
-
-	tablet := &topodatapb.Tablet{
-		Alias: &topodatapb.TabletAlias{
-			Cell: "cell1",
-			Uid:  100,
-		},
-		Hostname: "localhost",
-		Keyspace: "testkeyspace",
-		Shard:    "-",
-		Type:     topodatapb.TabletType_REPLICA,
-	}
-
-	testutil.AddTablet(ctx, t, ts, tablet, nil)
-
-	resp, err := vtctld.GetTablet(ctx, &vtctldatapb.GetTabletRequest{
-		TabletAlias: &topodatapb.TabletAlias{
-			Cell: "cell1",
-			Uid:  100,
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
 		},
-	})
-	assert.NoError(t, err)
-	utils.MustMatch(t, resp.Tablet, tablet)
-
-	// not found
-	_, err = vtctld.GetTablet(ctx, &vtctldatapb.GetTabletRequest{
-		TabletAlias: &topodatapb.TabletAlias{
-			Cell: "cell1",
-			Uid:  101,
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_REPLICA,
 		},
-	})
-	assert.Error(t, err)
-}
-
-func TestGetTablets(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name      string
-		cells     []string
-		tablets   []*topodatapb.Tablet
-		req       *vtctldatapb.GetTabletsRequest
-		expected  []*topodatapb.Tablet
-		shouldErr bool
-	}{
 		{
-			name:      "no tablets",
-			cells:     []string{"cell1"},
-			tablets:   []*topodatapb.Tablet{},
-			req:       &vtctldatapb.GetTabletsRequest{},
-			expected:  []*topodatapb.Tablet{},
-			shouldErr: false,
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
 		},
 		{
-			name:  "keyspace and shard filter",
-			cells: []string{"cell1"},
-			tablets: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  100,
-					},
-					Keyspace: "ks1",
-					Shard:    "-80",
-				},
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  101,
-					},
-					Keyspace: "ks1",
-					Shard:    "80-",
-				},
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  102,
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 300},
+			Keyspace: "noprimary",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+						{Name: "t1", DataLength: 100, RowCount: 10},
+						{Name: "t2", DataLength: 50, RowCount: 5},
 					},
-					Keyspace: "ks2",
-					Shard:    "-",
 				},
 			},
-			req: &vtctldatapb.GetTabletsRequest{
-				Keyspace: "ks1",
-				Shard:    "80-",
-			},
-			expected: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  101,
+			"zone1-0000000200": {
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+						{Name: "t1", DataLength: 200, RowCount: 20},
 					},
-					Keyspace: "ks1",
-					Shard:    "80-",
 				},
 			},
-			shouldErr: false,
 		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.GetTableSizesRequest
+		expected  *vtctldatapb.GetTableSizesResponse
+		shouldErr bool
+	}{
 		{
-			name:  "keyspace filter",
-			cells: []string{"cell1"},
-			tablets: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  100,
-					},
-					Keyspace: "ks1",
-				},
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  101,
+			name: "ok",
+			req: &vtctldatapb.GetTableSizesRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: &vtctldatapb.GetTableSizesResponse{
+				TableSizes: map[string]*vtctldatapb.TableSize{
+					"t1": {
+						RowCount:   30,
+						DataLength: 300,
+						ByShard: map[string]*vtctldatapb.ShardTableSize{
+							"-80": {RowCount: 10, DataLength: 100},
+							"80-": {RowCount: 20, DataLength: 200},
+						},
 					},
-					Keyspace: "ks1",
-				},
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "cell1",
-						Uid:  102,
+					"t2": {
+						RowCount:   5,
+						DataLength: 50,
+						ByShard: map[string]*vtctldatapb.ShardTableSize{
+							"-80": {RowCount: 5, DataLength: 50},
+						},
 					},
-					Keyspace: "otherkeyspace",
 				},
 			},
-			req: &vtctldatapb.GetTabletsRequest{
-				Keyspace: "ks1",
-			},
+		},
+		{
+			name: "shard without primary",
+			req: &vtctldatapb.GetTableSizesRequest{
+				Keyspace: "noprimary",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			req: &vtctldatapb.GetTableSizesRequest{
+				Keyspace: "otherkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, tablets...)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.GetTableSizes(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestGetThrottlerStatus(t *testing.T) {
+	t.Parallel()
+
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 201},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+		},
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetThrottlerStatusResults: map[string]struct {
+			Response *tabletmanagerdatapb.GetThrottlerStatusResponse
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: &tabletmanagerdatapb.GetThrottlerStatusResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsLeader: true, IsOpen: true, Threshold: 1},
+				},
+			},
+			"zone1-0000000101": {
+				Response: &tabletmanagerdatapb.GetThrottlerStatusResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsOpen: true, Threshold: 1},
+				},
+			},
+			"zone1-0000000200": {
+				Response: &tabletmanagerdatapb.GetThrottlerStatusResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsLeader: true, IsOpen: true, Threshold: 5},
+				},
+			},
+			"zone1-0000000201": {
+				Error: assert.AnError,
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.GetThrottlerStatusRequest
+		expected  *vtctldatapb.GetThrottlerStatusResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.GetThrottlerStatusRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: &vtctldatapb.GetThrottlerStatusResponse{
+				Statuses: map[string]*tabletmanagerdatapb.ThrottlerStatus{
+					"zone1-0000000100": {Enabled: true, IsLeader: true, IsOpen: true, Threshold: 1},
+					"zone1-0000000101": {Enabled: true, IsOpen: true, Threshold: 1},
+					"zone1-0000000200": {Enabled: true, IsLeader: true, IsOpen: true, Threshold: 5},
+				},
+				Errors: map[string]string{
+					"zone1-0000000201": assert.AnError.Error(),
+				},
+				DivergentTablets: []string{"zone1-0000000200"},
+			},
+		},
+		{
+			name:      "no keyspace",
+			req:       &vtctldatapb.GetThrottlerStatusRequest{},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			req: &vtctldatapb.GetThrottlerStatusRequest{
+				Keyspace: "otherkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, nil, tablets...)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.GetThrottlerStatus(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestGetTablet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	tablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  100,
+		},
+		Hostname: "localhost",
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	}
+
+	testutil.AddTablet(ctx, t, ts, tablet, nil)
+
+	resp, err := vtctld.GetTablet(ctx, &vtctldatapb.GetTabletRequest{
+		TabletAlias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  100,
+		},
+	})
+	assert.NoError(t, err)
+	utils.MustMatch(t, resp.Tablet, tablet)
+
+	// not found
+	_, err = vtctld.GetTablet(ctx, &vtctldatapb.GetTabletRequest{
+		TabletAlias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  101,
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetTablets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cells     []string
+		tablets   []*topodatapb.Tablet
+		req       *vtctldatapb.GetTabletsRequest
+		expected  []*topodatapb.Tablet
+		shouldErr bool
+	}{
+		{
+			name:      "no tablets",
+			cells:     []string{"cell1"},
+			tablets:   []*topodatapb.Tablet{},
+			req:       &vtctldatapb.GetTabletsRequest{},
+			expected:  []*topodatapb.Tablet{},
+			shouldErr: false,
+		},
+		{
+			name:  "keyspace and shard filter",
+			cells: []string{"cell1"},
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  100,
+					},
+					Keyspace: "ks1",
+					Shard:    "-80",
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  101,
+					},
+					Keyspace: "ks1",
+					Shard:    "80-",
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  102,
+					},
+					Keyspace: "ks2",
+					Shard:    "-",
+				},
+			},
+			req: &vtctldatapb.GetTabletsRequest{
+				Keyspace: "ks1",
+				Shard:    "80-",
+			},
+			expected: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  101,
+					},
+					Keyspace: "ks1",
+					Shard:    "80-",
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name:  "keyspace filter",
+			cells: []string{"cell1"},
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  100,
+					},
+					Keyspace: "ks1",
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  101,
+					},
+					Keyspace: "ks1",
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  102,
+					},
+					Keyspace: "otherkeyspace",
+				},
+			},
+			req: &vtctldatapb.GetTabletsRequest{
+				Keyspace: "ks1",
+			},
 			expected: []*topodatapb.Tablet{
 				{
 					Alias: &topodatapb.TabletAlias{
@@ -5899,21 +7512,129 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
-func TestPingTablet(t *testing.T) {
+func TestVSchemaHistory(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 	ts := memorytopo.NewServer("zone1")
-	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
-		Alias: &topodatapb.TabletAlias{
-			Cell: "zone1",
-			Uid:  100,
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	v1 := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
 		},
+	}
+	require.NoError(t, ts.SaveVSchema(ctx, "testkeyspace", v1))
+	require.NoError(t, ts.SaveVSchema(ctx, "testkeyspace", &vschemapb.Keyspace{}))
+
+	resp, err := vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
 		Keyspace: "testkeyspace",
-		Shard:    "-",
-	}, nil)
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Versions, 2)
+	assert.EqualValues(t, 1, resp.Versions[0].Version)
+	assert.Equal(t, "initial version", resp.Versions[0].Summary)
+	assert.NotNil(t, resp.Versions[0].Timestamp)
+	assert.EqualValues(t, 2, resp.Versions[1].Version)
+	assert.Equal(t, "removed tables t1", resp.Versions[1].Summary)
+	assert.Nil(t, resp.VSchema)
+
+	resp, err = vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: "testkeyspace",
+		Version:  1,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, resp.VSchema)
 
-	tests := []struct {
+	_, err = vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: "testkeyspace",
+		Version:  10,
+	})
+	assert.ErrorContains(t, err, "vschema version 10 of keyspace testkeyspace does not exist")
+
+	_, err = vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace: "testkeyspace",
+	})
+	assert.ErrorContains(t, err, "invalid vschema version")
+
+	rollback, err := vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace:  "testkeyspace",
+		ToVersion: 1,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, rollback.VSchema)
+
+	current, err := ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, current)
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, srvVSchema.Keyspaces["testkeyspace"])
+
+	resp, err = vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: "testkeyspace",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Versions, 3)
+	assert.Equal(t, "rollback to version 1", resp.Versions[2].Summary)
+}
+
+func TestGetVersionInfo(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	servenv.RegisterVersionServer(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname: "localhost",
+		PortMap:  map[string]int32{"grpc": int32(listener.Addr().(*net.TCPAddr).Port)},
+	}, nil)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.GetVersionInfo(ctx, &vtctldatapb.GetVersionInfoRequest{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, servenv.VersionInfo(), resp.VersionInfo)
+
+	// Without a tablet or an address, vtctld returns its own version info.
+	resp, err = vtctld.GetVersionInfo(ctx, &vtctldatapb.GetVersionInfoRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, servenv.VersionInfo(), resp.VersionInfo)
+
+	_, err = vtctld.GetVersionInfo(ctx, &vtctldatapb.GetVersionInfoRequest{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Address:     listener.Addr().String(),
+	})
+	assert.ErrorContains(t, err, "cannot set both a tablet alias and an address")
+}
+
+func TestPingTablet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+	}, nil)
+
+	tests := []struct {
 		name      string
 		tmc       testutil.TabletManagerClient
 		req       *vtctldatapb.PingTabletRequest
@@ -8059,6 +9780,163 @@ func TestReparentTablet(t *testing.T) {
 	}
 }
 
+func TestRestoreDroppedTable(t *testing.T) {
+	t.Parallel()
+
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 300},
+			Keyspace: "purgedkeyspace",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 400},
+			Keyspace: "existingkeyspace",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 500},
+			Keyspace: "nodropkeyspace",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 600},
+			Keyspace: "purgingkeyspace",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	}
+	droppedTable := func(rows ...string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"migration_uuid|hold_table|table_exists|hold_table_exists",
+				"varchar|varchar|int64|int64",
+			),
+			rows...,
+		))
+	}
+	tmc := &testutil.TabletManagerClient{
+		ExecuteFetchAsDbaResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: droppedTable("6ace8bcef73211ea87e9f875a4d24e90|_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|0|1"),
+			},
+			"zone1-0000000200": {
+				Response: droppedTable("6ace8bcef73211ea87e9f875a4d24e90|_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120411|0|1"),
+			},
+			"zone1-0000000300": {
+				Response: droppedTable("6ace8bcef73211ea87e9f875a4d24e90|_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|0|0"),
+			},
+			"zone1-0000000400": {
+				Response: droppedTable("6ace8bcef73211ea87e9f875a4d24e90|_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|1|1"),
+			},
+			"zone1-0000000500": {
+				Response: droppedTable(),
+			},
+			"zone1-0000000600": {
+				Response: droppedTable("6ace8bcef73211ea87e9f875a4d24e90|_vt_PURGE_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|0|1"),
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.RestoreDroppedTableRequest
+		expected  *vtctldatapb.RestoreDroppedTableResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "testkeyspace",
+				Table:    "t1",
+			},
+			expected: &vtctldatapb.RestoreDroppedTableResponse{
+				RestoredFrom: map[string]string{
+					"-80": "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410",
+					"80-": "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120411",
+				},
+			},
+		},
+		{
+			name: "no table",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "already purged",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "purgedkeyspace",
+				Table:    "t1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "table exists",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "existingkeyspace",
+				Table:    "t1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "never dropped",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "nodropkeyspace",
+				Table:    "t1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no longer held",
+			req: &vtctldatapb.RestoreDroppedTableRequest{
+				Keyspace: "purgingkeyspace",
+				Table:    "t1",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, tablets...)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.RestoreDroppedTable(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestRestoreFromBackup(t *testing.T) {
 	ctx := context.Background()
 	tests := []struct {
@@ -8198,86 +10076,387 @@ func TestRestoreFromBackup(t *testing.T) {
 	}
 }
 
-func TestRunHealthCheck(t *testing.T) {
+// rollingRestartHealth are the health records of the tablets of
+// TestRollingRestart, keyed by tablet alias.
+var (
+	rollingRestartHealthMu sync.Mutex
+	rollingRestartHealth   map[string]*querypb.StreamHealthResponse
+)
+
+// rollingRestartQueryService is a fake query service whose health stream
+// sends the health record of its tablet, until the callback returns an error.
+type rollingRestartQueryService struct {
+	queryservice.QueryService
+	health *querypb.StreamHealthResponse
+}
+
+func (qs *rollingRestartQueryService) StreamHealth(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error {
+	for {
+		if err := callback(qs.health); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+func (qs *rollingRestartQueryService) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestRollingRestart(t *testing.T) {
+	drainTabletPollInterval = time.Millisecond
+	rollingRestartPollInterval = time.Millisecond
+	*tabletconn.TabletProtocol = "grpcvtctldserver.test"
+
 	t.Parallel()
 
 	ctx := context.Background()
+
+	type drainTabletResult = struct {
+		Response *tabletmanagerdatapb.DrainTabletResponse
+		Error    error
+	}
+
+	newTablet := func(uid uint32, shard string, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+		return &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  uid,
+			},
+			Hostname: fmt.Sprintf("host-%d", uid),
+			Keyspace: "ks",
+			Shard:    shard,
+			Type:     tabletType,
+		}
+	}
+
+	drained := []drainTabletResult{
+		{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true, InFlightQueries: 1}},
+		{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true}},
+	}
+	serving := &querypb.StreamHealthResponse{
+		Target:  &querypb.Target{TabletType: topodatapb.TabletType_REPLICA},
+		Serving: true,
+	}
+	timeouts := func(req *vtctldatapb.RollingRestartRequest) *vtctldatapb.RollingRestartRequest {
+		req.DrainTimeout = protoutil.DurationToProto(time.Millisecond * 50)
+		req.HealthyTimeout = protoutil.DurationToProto(time.Millisecond * 50)
+		req.WaitReplicasTimeout = protoutil.DurationToProto(time.Millisecond * 10)
+		return req
+	}
+
 	tests := []struct {
-		name      string
-		tablets   []*topodatapb.Tablet
-		tmc       testutil.TabletManagerClient
-		req       *vtctldatapb.RunHealthCheckRequest
-		shouldErr bool
+		name         string
+		tablets      []*topodatapb.Tablet
+		drainResults map[string][]drainTabletResult
+		health       map[string]*querypb.StreamHealthResponse
+		hookStatus   map[string]int
+		req          *vtctldatapb.RollingRestartRequest
+		// expectedHooks are the tablets restarted by the hook, in order.
+		expectedHooks []string
+		shouldErr     bool
 	}{
 		{
 			name: "ok",
 			tablets: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "zone1",
-						Uid:  100,
-					},
-				},
+				newTablet(101, "-", topodatapb.TabletType_RDONLY),
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
+				newTablet(102, "-", topodatapb.TabletType_SPARE),
 			},
-			tmc: testutil.TabletManagerClient{
-				RunHealthCheckResults: map[string]error{
-					"zone1-0000000100": nil,
-				},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": drained,
+				"zone1-0000000101": drained,
 			},
-			req: &vtctldatapb.RunHealthCheckRequest{
-				TabletAlias: &topodatapb.TabletAlias{
-					Cell: "zone1",
-					Uid:  100,
-				},
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000100": serving,
+				"zone1-0000000101": serving,
+				"zone1-0000000102": {Target: &querypb.Target{TabletType: topodatapb.TabletType_SPARE}},
 			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
+			expectedHooks: []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000102"},
 		},
 		{
-			name: "no tablet",
+			name: "restricted to shards",
 			tablets: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "zone1",
-						Uid:  404,
-					},
-				},
+				newTablet(100, "-80", topodatapb.TabletType_REPLICA),
+				newTablet(200, "80-", topodatapb.TabletType_REPLICA),
 			},
-			tmc: testutil.TabletManagerClient{
-				RunHealthCheckResults: map[string]error{
-					"zone1-0000000100": nil,
-				},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": drained,
+				"zone1-0000000200": drained,
 			},
-			req: &vtctldatapb.RunHealthCheckRequest{
-				TabletAlias: &topodatapb.TabletAlias{
-					Cell: "zone1",
-					Uid:  100,
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000100": serving,
+				"zone1-0000000200": serving,
+			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+				Shards:   []string{"80-"},
+			}),
+			expectedHooks: []string{"zone1-0000000200"},
+		},
+		{
+			name: "hook failure aborts",
+			tablets: []*topodatapb.Tablet{
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
+				newTablet(101, "-", topodatapb.TabletType_REPLICA),
+			},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": drained,
+				"zone1-0000000101": drained,
+			},
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000100": serving,
+				"zone1-0000000101": serving,
+			},
+			hookStatus: map[string]int{
+				"zone1-0000000100": 1,
+			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
+			expectedHooks: []string{"zone1-0000000100"},
+			shouldErr:     true,
+		},
+		{
+			name: "drain timeout",
+			tablets: []*topodatapb.Tablet{
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
+			},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": {
+					{Response: &tabletmanagerdatapb.DrainTabletResponse{Draining: true, InFlightQueries: 1}},
 				},
 			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
 			shouldErr: true,
 		},
 		{
-			name: "tmc call failed",
+			name: "tablet not restarted",
 			tablets: []*topodatapb.Tablet{
-				{
-					Alias: &topodatapb.TabletAlias{
-						Cell: "zone1",
-						Uid:  100,
-					},
-				},
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
 			},
-			tmc: testutil.TabletManagerClient{
-				RunHealthCheckResults: map[string]error{
-					"zone1-0000000100": assert.AnError,
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": drained,
+			},
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000100": {
+					Target:   &querypb.Target{TabletType: topodatapb.TabletType_REPLICA},
+					Draining: true,
 				},
 			},
-			req: &vtctldatapb.RunHealthCheckRequest{
-				TabletAlias: &topodatapb.TabletAlias{
-					Cell: "zone1",
-					Uid:  100,
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
+			expectedHooks: []string{"zone1-0000000100"},
+			shouldErr:     true,
+		},
+		{
+			name: "tablet not serving",
+			tablets: []*topodatapb.Tablet{
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
+			},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000100": drained,
+			},
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000100": {
+					Target: &querypb.Target{TabletType: topodatapb.TabletType_REPLICA},
+					RealtimeStats: &querypb.RealtimeStats{
+						HealthError: "replication is not running",
+					},
 				},
 			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
+			expectedHooks: []string{"zone1-0000000100"},
+			shouldErr:     true,
+		},
+		{
+			name: "tablet taking a backup",
+			tablets: []*topodatapb.Tablet{
+				newTablet(100, "-", topodatapb.TabletType_REPLICA),
+				newTablet(101, "-", topodatapb.TabletType_BACKUP),
+			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
 			shouldErr: true,
 		},
-	}
+		{
+			// The primary is restarted last, and not at all if the shard
+			// cannot be reparented away from it.
+			name: "primary reparent failure",
+			tablets: []*topodatapb.Tablet{
+				newTablet(100, "-", topodatapb.TabletType_PRIMARY),
+				newTablet(101, "-", topodatapb.TabletType_REPLICA),
+			},
+			drainResults: map[string][]drainTabletResult{
+				"zone1-0000000101": drained,
+			},
+			health: map[string]*querypb.StreamHealthResponse{
+				"zone1-0000000101": serving,
+			},
+			req: timeouts(&vtctldatapb.RollingRestartRequest{
+				Keyspace: "ks",
+			}),
+			expectedHooks: []string{"zone1-0000000101"},
+			shouldErr:     true,
+		},
+		{
+			name: "unsupported component",
+			req: &vtctldatapb.RollingRestartRequest{
+				Keyspace:  "ks",
+				Component: "vtgate",
+			},
+			shouldErr: true,
+		},
+		{
+			name:      "missing keyspace",
+			req:       &vtctldatapb.RollingRestartRequest{},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, tt.tablets...)
+
+			rollingRestartHealthMu.Lock()
+			rollingRestartHealth = tt.health
+			rollingRestartHealthMu.Unlock()
+
+			var hooks []string
+			runRestartHook = func(ctx context.Context, name string, params []string) *hk.HookResult {
+				assert.Equal(t, "restart_tablet", name)
+				require.NotEmpty(t, params)
+
+				alias := strings.TrimPrefix(params[0], "--tablet_alias=")
+				hooks = append(hooks, alias)
+				return &hk.HookResult{ExitStatus: tt.hookStatus[alias]}
+			}
+
+			tmc := &testutil.TabletManagerClient{
+				DrainTabletResults: tt.drainResults,
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			client := localvtctldclient.New(vtctld)
+
+			stream, err := client.RollingRestart(ctx, tt.req)
+			require.NoError(t, err)
+
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+			}
+
+			if tt.shouldErr {
+				assert.NotErrorIs(t, err, io.EOF)
+			} else {
+				assert.ErrorIs(t, err, io.EOF)
+			}
+
+			assert.Equal(t, tt.expectedHooks, hooks)
+		})
+	}
+}
+
+func TestRunHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		tablets   []*topodatapb.Tablet
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.RunHealthCheckRequest
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				RunHealthCheckResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			req: &vtctldatapb.RunHealthCheckRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+		},
+		{
+			name: "no tablet",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  404,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				RunHealthCheckResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			req: &vtctldatapb.RunHealthCheckRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tmc call failed",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				RunHealthCheckResults: map[string]error{
+					"zone1-0000000100": assert.AnError,
+				},
+			},
+			req: &vtctldatapb.RunHealthCheckRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+	}
 
 	for _, tt := range tests {
 		tt := tt
@@ -8564,6 +10743,209 @@ func TestSetShardIsPrimaryServing(t *testing.T) {
 	}
 }
 
+func TestSetShardReadOnly(t *testing.T) {
+	t.Parallel()
+
+	primary := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}
+	replica := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  101,
+		},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	}
+
+	tests := []struct {
+		name      string
+		tablets   []*topodatapb.Tablet
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.SetShardReadOnlyRequest
+		expected  *vtctldatapb.SetShardReadOnlyResponse
+		shouldErr bool
+	}{
+		{
+			name:    "read-only with a duration",
+			tablets: []*topodatapb.Tablet{primary, replica},
+			tmc: &testutil.TabletManagerClient{
+				FreezeWritesResults: map[string]struct {
+					Response *tabletmanagerdatapb.FreezeWritesResponse
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: &tabletmanagerdatapb.FreezeWritesResponse{
+							Frozen:     true,
+							ExpireTime: &vttime.Time{Seconds: 1000},
+						},
+					},
+				},
+			},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+				Duration: protoutil.DurationToProto(time.Minute),
+			},
+			expected: &vtctldatapb.SetShardReadOnlyResponse{
+				Primary:    primary.Alias,
+				ReadOnly:   true,
+				ExpireTime: &vttime.Time{Seconds: 1000},
+			},
+		},
+		{
+			name:    "read-only until undone",
+			tablets: []*topodatapb.Tablet{primary, replica},
+			tmc: &testutil.TabletManagerClient{
+				FreezeWritesResults: map[string]struct {
+					Response *tabletmanagerdatapb.FreezeWritesResponse
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: &tabletmanagerdatapb.FreezeWritesResponse{
+							Frozen: true,
+						},
+					},
+				},
+			},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+			},
+			expected: &vtctldatapb.SetShardReadOnlyResponse{
+				Primary:  primary.Alias,
+				ReadOnly: true,
+			},
+		},
+		{
+			name:    "undo",
+			tablets: []*topodatapb.Tablet{primary, replica},
+			tmc: &testutil.TabletManagerClient{
+				FreezeWritesResults: map[string]struct {
+					Response *tabletmanagerdatapb.FreezeWritesResponse
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: &tabletmanagerdatapb.FreezeWritesResponse{},
+					},
+				},
+			},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+				Undo:     true,
+			},
+			expected: &vtctldatapb.SetShardReadOnlyResponse{
+				Primary: primary.Alias,
+			},
+		},
+		{
+			name:    "tabletmanager failure",
+			tablets: []*topodatapb.Tablet{primary, replica},
+			tmc: &testutil.TabletManagerClient{
+				FreezeWritesResults: map[string]struct {
+					Response *tabletmanagerdatapb.FreezeWritesResponse
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+			},
+			shouldErr: true,
+		},
+		{
+			name:    "shard without a primary",
+			tablets: []*topodatapb.Tablet{replica},
+			tmc:     &testutil.TabletManagerClient{},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+			},
+			shouldErr: true,
+		},
+		{
+			name:    "shard not found",
+			tablets: []*topodatapb.Tablet{primary},
+			tmc:     &testutil.TabletManagerClient{},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-80",
+			},
+			shouldErr: true,
+		},
+		{
+			name:    "duration with undo",
+			tablets: []*topodatapb.Tablet{primary},
+			tmc:     &testutil.TabletManagerClient{},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+				Duration: protoutil.DurationToProto(time.Minute),
+				Undo:     true,
+			},
+			shouldErr: true,
+		},
+		{
+			name:    "negative duration",
+			tablets: []*topodatapb.Tablet{primary},
+			tmc:     &testutil.TabletManagerClient{},
+			req: &vtctldatapb.SetShardReadOnlyRequest{
+				Keyspace: "ks",
+				Shard:    "-",
+				Duration: protoutil.DurationToProto(-time.Minute),
+			},
+			shouldErr: true,
+		},
+		{
+			name:      "no shard",
+			tablets:   []*topodatapb.Tablet{primary},
+			tmc:       &testutil.TabletManagerClient{},
+			req:       &vtctldatapb.SetShardReadOnlyRequest{Keyspace: "ks"},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			tt.tmc.TopoServer = ts
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, tt.tablets...)
+
+			resp, err := vtctld.SetShardReadOnly(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestSetShardTabletControl(t *testing.T) {
 	t.Parallel()
 
@@ -8758,58 +11140,282 @@ func TestSetShardTabletControl(t *testing.T) {
 			},
 		},
 		{
-			name: "keyspace lock error",
-			setup: func(t *testing.T, tt *testcase) {
-				var cancel func()
-				tt.ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
-				tt.ts = memorytopo.NewServer("zone1")
-				testutil.AddShards(tt.ctx, t, tt.ts, &vtctldatapb.Shard{
-					Keyspace: "testkeyspace",
-					Name:     "-",
-					Shard:    &topodatapb.Shard{},
-				})
-
-				_, unlock, err := tt.ts.LockKeyspace(tt.ctx, "testkeyspace", "test lock")
-				require.NoError(t, err)
-				tt.teardown = func(t *testing.T, tt *testcase) {
-					var err error
-					unlock(&err)
-					assert.NoError(t, err)
-					cancel()
-				}
+			name: "keyspace lock error",
+			setup: func(t *testing.T, tt *testcase) {
+				var cancel func()
+				tt.ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+				tt.ts = memorytopo.NewServer("zone1")
+				testutil.AddShards(tt.ctx, t, tt.ts, &vtctldatapb.Shard{
+					Keyspace: "testkeyspace",
+					Name:     "-",
+					Shard:    &topodatapb.Shard{},
+				})
+
+				_, unlock, err := tt.ts.LockKeyspace(tt.ctx, "testkeyspace", "test lock")
+				require.NoError(t, err)
+				tt.teardown = func(t *testing.T, tt *testcase) {
+					var err error
+					unlock(&err)
+					assert.NoError(t, err)
+					cancel()
+				}
+			},
+			req: &vtctldatapb.SetShardTabletControlRequest{
+				Keyspace:     "testkeyspace",
+				Shard:        "-",
+				DeniedTables: []string{"t1"},
+				TabletType:   topodatapb.TabletType_REPLICA,
+			},
+			shouldErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.setup != nil {
+				tt.setup(t, tt)
+			}
+			if tt.teardown != nil {
+				defer tt.teardown(t, tt)
+			}
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, tt.ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			resp, err := vtctld.SetShardTabletControl(tt.ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestSetSrvKeyspacePartition(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		minus80 = &topodatapb.ShardReference{Name: "-80", KeyRange: &topodatapb.KeyRange{End: []byte{0x80}}}
+		plus80  = &topodatapb.ShardReference{Name: "80-", KeyRange: &topodatapb.KeyRange{Start: []byte{0x80}}}
+		whole   = &topodatapb.ShardReference{Name: "-", KeyRange: &topodatapb.KeyRange{}}
+	)
+
+	// The SrvKeyspace of zone1 has a PRIMARY partition that still serves from
+	// shard - after the shard was split.
+	srvKeyspace := func() *topodatapb.SrvKeyspace {
+		return &topodatapb.SrvKeyspace{
+			Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{
+				{
+					ServedType:      topodatapb.TabletType_PRIMARY,
+					ShardReferences: []*topodatapb.ShardReference{whole},
+					ShardTabletControls: []*topodatapb.ShardTabletControl{
+						{Name: "-", KeyRange: &topodatapb.KeyRange{}, QueryServiceDisabled: true},
+						{Name: "-80", KeyRange: minus80.KeyRange, QueryServiceDisabled: true},
+					},
+				},
+				{
+					ServedType:      topodatapb.TabletType_REPLICA,
+					ShardReferences: []*topodatapb.ShardReference{minus80, plus80},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		noSrvKeyspace bool
+		req           *vtctldatapb.SetSrvKeyspacePartitionRequest
+		expected      *vtctldatapb.SetSrvKeyspacePartitionResponse
+		shouldErr     bool
+	}{
+		{
+			name: "success",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"80-", "-80"},
+			},
+			expected: &vtctldatapb.SetSrvKeyspacePartitionResponse{
+				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
+					"zone1": {
+						Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{
+							{
+								ServedType:      topodatapb.TabletType_REPLICA,
+								ShardReferences: []*topodatapb.ShardReference{minus80, plus80},
+							},
+							{
+								ServedType:      topodatapb.TabletType_PRIMARY,
+								ShardReferences: []*topodatapb.ShardReference{minus80, plus80},
+								ShardTabletControls: []*topodatapb.ShardTabletControl{
+									{Name: "-80", KeyRange: minus80.KeyRange, QueryServiceDisabled: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "new served type",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				Cells:      []string{"zone1"},
+				TabletType: topodatapb.TabletType_RDONLY,
+				Shards:     []string{"-"},
+			},
+			expected: &vtctldatapb.SetSrvKeyspacePartitionResponse{
+				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
+					"zone1": {
+						Partitions: append(srvKeyspace().Partitions, &topodatapb.SrvKeyspace_KeyspacePartition{
+							ServedType:      topodatapb.TabletType_RDONLY,
+							ShardReferences: []*topodatapb.ShardReference{whole},
+						}),
+					},
+				},
+			},
+		},
+		{
+			name: "dry run",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_REPLICA,
+				Shards:     []string{"-"},
+				DryRun:     true,
+			},
+			expected: &vtctldatapb.SetSrvKeyspacePartitionResponse{
+				SrvKeyspaces: map[string]*topodatapb.SrvKeyspace{
+					"zone1": {
+						Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{
+							srvKeyspace().Partitions[0],
+							{
+								ServedType:      topodatapb.TabletType_REPLICA,
+								ShardReferences: []*topodatapb.ShardReference{whole},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "key space not covered",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-80"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "overlapping shards",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-", "80-"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "shard listed twice",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-80", "-80", "80-"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "missing shard",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-40", "40-"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "cell without SrvKeyspace",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				Cells:      []string{"zone2"},
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-80", "80-"},
+			},
+			shouldErr: true,
+		},
+		{
+			name:          "no SrvKeyspace",
+			noSrvKeyspace: true,
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace:   "testkeyspace",
+				TabletType: topodatapb.TabletType_PRIMARY,
+				Shards:     []string{"-80", "80-"},
 			},
-			req: &vtctldatapb.SetShardTabletControlRequest{
-				Keyspace:     "testkeyspace",
-				Shard:        "-",
-				DeniedTables: []string{"t1"},
-				TabletType:   topodatapb.TabletType_REPLICA,
+			shouldErr: true,
+		},
+		{
+			name: "missing tablet type",
+			req: &vtctldatapb.SetSrvKeyspacePartitionRequest{
+				Keyspace: "testkeyspace",
+				Shards:   []string{"-80", "80-"},
 			},
 			shouldErr: true,
 		},
 	}
+
 	for _, tt := range tests {
 		tt := tt
+
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if tt.setup != nil {
-				tt.setup(t, tt)
+			ts := memorytopo.NewServer("zone1", "zone2")
+			for _, shard := range []string{"-", "-80", "80-"} {
+				testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+					Keyspace: "testkeyspace",
+					Name:     shard,
+				})
 			}
-			if tt.teardown != nil {
-				defer tt.teardown(t, tt)
+			if !tt.noSrvKeyspace {
+				testutil.AddSrvKeyspaces(t, ts, &testutil.SrvKeyspace{
+					Cell:        "zone1",
+					Keyspace:    "testkeyspace",
+					SrvKeyspace: srvKeyspace(),
+				})
 			}
 
-			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, tt.ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(ts)
 			})
-			resp, err := vtctld.SetShardTabletControl(tt.ctx, tt.req)
+
+			resp, err := vtctld.SetSrvKeyspacePartition(ctx, tt.req)
 			if tt.shouldErr {
 				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				utils.MustMatch(t, tt.expected, resp)
+			}
+
+			if tt.noSrvKeyspace {
 				return
 			}
 
-			assert.NoError(t, err)
-			utils.MustMatch(t, tt.expected, resp)
+			// The SrvKeyspace is only updated by successful requests that are
+			// not dry runs.
+			expected := srvKeyspace()
+			if !tt.shouldErr && !tt.req.DryRun {
+				expected = tt.expected.SrvKeyspaces["zone1"]
+			}
+
+			srvKeyspace, err := ts.GetSrvKeyspace(ctx, "zone1", "testkeyspace")
+			require.NoError(t, err)
+			utils.MustMatch(t, expected, srvKeyspace)
 		})
 	}
 }
@@ -10698,6 +13304,356 @@ func TestUpdateCellsAlias(t *testing.T) {
 	}
 }
 
+// sequenceQueryServices are the query services of the shard primaries of
+// TestUpdateSequence, keyed by tablet alias.
+var (
+	sequenceQueryServicesMu sync.Mutex
+	sequenceQueryServices   map[string]*sequenceQueryService
+)
+
+// sequenceQueryService is a fake query service that serves the row of a
+// sequence table, which has the after values once the update ran.
+type sequenceQueryService struct {
+	queryservice.QueryService
+
+	before    []int64
+	after     []int64
+	updateErr error
+
+	mu         sync.Mutex
+	queries    []string
+	updated    bool
+	committed  bool
+	rolledBack bool
+}
+
+func (qs *sequenceQueryService) row() *sqltypes.Result {
+	values := qs.before
+	if qs.updated {
+		values = qs.after
+	}
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("next_id|cache", "int64|int64"), fmt.Sprintf("%d|%d", values[0], values[1]))
+}
+
+func (qs *sequenceQueryService) BeginExecute(ctx context.Context, target *querypb.Target, preQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, int64, *topodatapb.TabletAlias, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.queries = append(qs.queries, "begin", sql)
+	return qs.row(), 1, nil, nil
+}
+
+func (qs *sequenceQueryService) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	if transactionID != 1 {
+		return nil, fmt.Errorf("query outside of the transaction: %s", sql)
+	}
+	qs.queries = append(qs.queries, sql)
+	if strings.HasPrefix(sql, "update") {
+		if qs.updateErr != nil {
+			return nil, qs.updateErr
+		}
+		qs.updated = true
+		return &sqltypes.Result{RowsAffected: 1}, nil
+	}
+	return qs.row(), nil
+}
+
+func (qs *sequenceQueryService) Commit(ctx context.Context, target *querypb.Target, transactionID int64) (int64, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.committed = true
+	return 0, nil
+}
+
+func (qs *sequenceQueryService) Rollback(ctx context.Context, target *querypb.Target, transactionID int64) (int64, error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.rolledBack = true
+	return 0, nil
+}
+
+func (qs *sequenceQueryService) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestUpdateSequence(t *testing.T) {
+	*tabletconn.TabletProtocol = "grpcvtctldserver.test"
+	defer func() {
+		sequenceQueryServicesMu.Lock()
+		defer sequenceQueryServicesMu.Unlock()
+		sequenceQueryServices = nil
+	}()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	for _, tablet := range []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	} {
+		testutil.AddTablet(ctx, t, ts, tablet, &testutil.AddTabletOptions{
+			AlsoSetShardPrimary: true,
+		})
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.UpdateSequenceRequest
+		updateErr error
+		expected  *vtctldatapb.UpdateSequenceResponse
+		queries   []string
+		shouldErr bool
+	}{
+		{
+			name: "increment",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace:  "testkeyspace",
+				Table:     "seq",
+				Increment: 1000,
+			},
+			expected: &vtctldatapb.UpdateSequenceResponse{
+				States: []*vtctldatapb.UpdateSequenceResponse_ShardState{
+					{Shard: "-80", TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, NextId: 1100, Cache: 10},
+					{Shard: "80-", TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, NextId: 1100, Cache: 10},
+				},
+			},
+			queries: []string{
+				"begin",
+				"select next_id, cache from seq where id = 0 for update",
+				"update seq set next_id = next_id + 1000 where id = 0",
+				"select next_id, cache from seq where id = 0 for update",
+			},
+		},
+		{
+			name: "cache and next value",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace:  "testkeyspace",
+				Table:     "seq",
+				Cache:     10,
+				NextValue: 1100,
+			},
+			expected: &vtctldatapb.UpdateSequenceResponse{
+				States: []*vtctldatapb.UpdateSequenceResponse_ShardState{
+					{Shard: "-80", TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, NextId: 1100, Cache: 10},
+					{Shard: "80-", TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, NextId: 1100, Cache: 10},
+				},
+			},
+			queries: []string{
+				"begin",
+				"select next_id, cache from seq where id = 0 for update",
+				"update seq set cache = 10, next_id = greatest(next_id, 1100) where id = 0",
+				"select next_id, cache from seq where id = 0 for update",
+			},
+		},
+		{
+			name: "update fails",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace:  "testkeyspace",
+				Table:     "seq",
+				Increment: 1000,
+			},
+			updateErr: assert.AnError,
+			shouldErr: true,
+		},
+		{
+			name: "next value and increment",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace:  "testkeyspace",
+				Table:     "seq",
+				NextValue: 1100,
+				Increment: 1000,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "negative cache",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace: "testkeyspace",
+				Table:    "seq",
+				Cache:    -1,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "nothing to update",
+			req: &vtctldatapb.UpdateSequenceRequest{
+				Keyspace: "testkeyspace",
+				Table:    "seq",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services := map[string]*sequenceQueryService{
+				"zone1-0000000100": {before: []int64{100, 5}, after: []int64{1100, 10}, updateErr: tt.updateErr},
+				"zone1-0000000200": {before: []int64{100, 5}, after: []int64{1100, 10}, updateErr: tt.updateErr},
+			}
+			sequenceQueryServicesMu.Lock()
+			sequenceQueryServices = services
+			sequenceQueryServicesMu.Unlock()
+
+			resp, err := vtctld.UpdateSequence(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				for alias, qs := range services {
+					assert.False(t, qs.committed, "transaction on %s was committed", alias)
+					assert.Equal(t, len(qs.queries) > 0, qs.rolledBack, "transaction on %s was not rolled back", alias)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+			for alias, qs := range services {
+				assert.Equal(t, tt.queries, qs.queries, "queries on %s", alias)
+				assert.True(t, qs.committed, "transaction on %s was not committed", alias)
+				assert.False(t, qs.rolledBack, "transaction on %s was rolled back", alias)
+			}
+		})
+	}
+}
+
+func TestUpdateThrottlerConfig(t *testing.T) {
+	t.Parallel()
+
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+		},
+		{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 201},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+		},
+	}
+	tmc := &testutil.TabletManagerClient{
+		UpdateThrottlerConfigResults: map[string]struct {
+			Response *tabletmanagerdatapb.UpdateThrottlerConfigResponse
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: &tabletmanagerdatapb.UpdateThrottlerConfigResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsOpen: true, Threshold: 2},
+				},
+			},
+			"zone1-0000000101": {
+				Response: &tabletmanagerdatapb.UpdateThrottlerConfigResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsOpen: true, Threshold: 2},
+				},
+			},
+			"zone1-0000000200": {
+				Response: &tabletmanagerdatapb.UpdateThrottlerConfigResponse{
+					Status: &tabletmanagerdatapb.ThrottlerStatus{Enabled: true, IsOpen: true, Threshold: 2},
+				},
+			},
+			"zone1-0000000201": {
+				Error: assert.AnError,
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.UpdateThrottlerConfigRequest
+		expected  *vtctldatapb.UpdateThrottlerConfigResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				Keyspace:  "testkeyspace",
+				Enable:    true,
+				Threshold: 2,
+			},
+			expected: &vtctldatapb.UpdateThrottlerConfigResponse{
+				Statuses: map[string]*tabletmanagerdatapb.ThrottlerStatus{
+					"zone1-0000000100": {Enabled: true, IsOpen: true, Threshold: 2},
+					"zone1-0000000101": {Enabled: true, IsOpen: true, Threshold: 2},
+					"zone1-0000000200": {Enabled: true, IsOpen: true, Threshold: 2},
+				},
+				Errors: map[string]string{
+					"zone1-0000000201": assert.AnError.Error(),
+				},
+			},
+		},
+		{
+			name: "enable and disable",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				Keyspace: "testkeyspace",
+				Enable:   true,
+				Disable:  true,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "negative threshold",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				Keyspace:  "testkeyspace",
+				Threshold: -1,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "nothing to update",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, nil, tablets...)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := vtctld.UpdateThrottlerConfig(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	t.Parallel()
 
@@ -11159,6 +14115,53 @@ func TestValidateVersionKeyspace(t *testing.T) {
 	}
 }
 
+func TestValidateVersionSkew(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.ValidateVersionSkew(ctx, &vtctldatapb.ValidateVersionSkewRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	assert.Empty(t, resp.Versions)
+
+	now := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	for _, cv := range []*topo.ComponentVersion{
+		{Component: "vtctld", ID: "vtctld-0:15000", Version: "16.0.0", UpdatedAt: now},
+		{Component: "vttablet", ID: "zone1-0000000100", Cell: "zone1", Version: "14.0.0", UpdatedAt: now},
+		{Component: "vtgate", ID: "vtgate-0:15001", Cell: "zone1", Version: "15.0.0", UpdatedAt: now},
+	} {
+		require.NoError(t, ts.SaveComponentVersion(ctx, cv))
+	}
+
+	resp, err = vtctld.ValidateVersionSkew(ctx, &vtctldatapb.ValidateVersionSkewRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"vttablet zone1-0000000100 running version 14.0.0 and vtctld vtctld-0:15000 running version 16.0.0 are 2 major versions apart, more than the supported 1",
+		"vtgate vtgate-0:15001 running version 15.0.0 is newer than vttablet zone1-0000000100 running version 14.0.0: the vttablets must be upgraded before the vtgates",
+	}, resp.Results)
+	assert.Equal(t, map[string]string{
+		"vtctld/vtctld-0:15000":     "16.0.0",
+		"vttablet/zone1-0000000100": "14.0.0",
+		"vtgate/vtgate-0:15001":     "15.0.0",
+	}, resp.Versions)
+
+	resp, err = vtctld.ValidateVersionSkew(ctx, &vtctldatapb.ValidateVersionSkewRequest{MaxMajorSkew: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"vtgate vtgate-0:15001 running version 15.0.0 is newer than vttablet zone1-0000000100 running version 14.0.0: the vttablets must be upgraded before the vtgates",
+	}, resp.Results)
+
+	resp, err = vtctld.ValidateVersionSkew(ctx, &vtctldatapb.ValidateVersionSkewRequest{StaleAfter: protoutil.DurationToProto(time.Second)})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	assert.Len(t, resp.Warnings, 3)
+}
+
 func TestValidateShard(t *testing.T) {
 	t.Parallel()
 
@@ -19,7 +19,9 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 )
@@ -32,6 +34,9 @@ type backupStorage struct {
 	Backups map[string][]string
 	// ListBackupsError is returned from ListBackups when it is non-nil.
 	ListBackupsError error
+	// Manifests is a mapping of backup, as "directory/name", to the contents
+	// of its MANIFEST file. The backups without one have no MANIFEST.
+	Manifests map[string]string
 }
 
 // ListBackups is part of the backupstorage.BackupStorage interface.
@@ -45,7 +50,7 @@ func (bs *backupStorage) ListBackups(ctx context.Context, dir string) ([]backups
 	for k, v := range bs.Backups {
 		if k == dir {
 			for _, name := range v {
-				handles = append(handles, &backupHandle{directory: k, name: name})
+				handles = append(handles, &backupHandle{directory: k, name: name, manifest: bs.Manifests[k+"/"+name]})
 			}
 		}
 	}
@@ -87,11 +92,21 @@ type backupHandle struct {
 
 	directory string
 	name      string
+	manifest  string
 }
 
 func (bh *backupHandle) Directory() string { return bh.directory }
 func (bh *backupHandle) Name() string      { return bh.name }
 
+// ReadFile is part of the backupstorage.BackupHandle interface. Only the
+// MANIFEST file can be read.
+func (bh *backupHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	if filename != "MANIFEST" || bh.manifest == "" {
+		return nil, fmt.Errorf("no file %s in backup %s/%s", filename, bh.directory, bh.name)
+	}
+	return io.NopCloser(strings.NewReader(bh.manifest)), nil
+}
+
 // handlesByName implements the sort interface for backup handles by Name().
 type handlesByName []backupstorage.BackupHandle
 
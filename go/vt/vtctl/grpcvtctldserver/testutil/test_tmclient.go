@@ -149,6 +149,12 @@ type TabletManagerClient struct {
 		Status *replicationdatapb.PrimaryStatus
 		Error  error
 	}
+	// keyed by tablet alias. The results are returned in order, and the last
+	// one is returned by all the calls after it.
+	DrainTabletResults map[string][]struct {
+		Response *tabletmanagerdatapb.DrainTabletResponse
+		Error    error
+	}
 	// keyed by tablet alias.
 	ExecuteFetchAsAppDelays map[string]time.Duration
 	// keyed by tablet alias.
@@ -163,6 +169,22 @@ type TabletManagerClient struct {
 		Response *querypb.QueryResult
 		Error    error
 	}
+	// keyed by tablet alias, then by query. The results of a query take
+	// precedence over ExecuteFetchAsDbaResults.
+	ExecuteFetchAsDbaQueryResults map[string]map[string]struct {
+		Response *querypb.QueryResult
+		Error    error
+	}
+	// keyed by tablet alias.
+	ExplainQueryResults map[string]struct {
+		Response *tabletmanagerdatapb.ExplainQueryResponse
+		Error    error
+	}
+	// keyed by tablet alias.
+	GetHotRowsResults map[string]struct {
+		Response *tabletmanagerdatapb.GetHotRowsResponse
+		Error    error
+	}
 	// keyed by tablet alias.
 	ExecuteHookDelays map[string]time.Duration
 	// keyed by tablet alias.
@@ -171,6 +193,11 @@ type TabletManagerClient struct {
 		Error    error
 	}
 	// keyed by tablet alias.
+	FreezeWritesResults map[string]struct {
+		Response *tabletmanagerdatapb.FreezeWritesResponse
+		Error    error
+	}
+	// keyed by tablet alias.
 	GetPermissionsDelays map[string]time.Duration
 	// keyed by tablet alias.
 	GetPermissionsResults map[string]struct {
@@ -183,6 +210,11 @@ type TabletManagerClient struct {
 		Error    error
 	}
 	// keyed by tablet alias.
+	GetReplicationLagHistoryResults map[string]struct {
+		Response *tabletmanagerdatapb.GetReplicationLagHistoryResponse
+		Error    error
+	}
+	// keyed by tablet alias.
 	GetSchemaDelays map[string]time.Duration
 	// keyed by tablet alias.
 	GetSchemaResults map[string]struct {
@@ -190,6 +222,11 @@ type TabletManagerClient struct {
 		Error  error
 	}
 	// keyed by tablet alias.
+	GetThrottlerStatusResults map[string]struct {
+		Response *tabletmanagerdatapb.GetThrottlerStatusResponse
+		Error    error
+	}
+	// keyed by tablet alias.
 	InitPrimaryDelays map[string]time.Duration
 	// keyed by tablet alias. injects a sleep to the end of the function
 	// regardless of parent context timeout or error result.
@@ -283,6 +320,11 @@ type TabletManagerClient struct {
 	UndoDemotePrimaryDelays map[string]time.Duration
 	// keyed by tablet alias
 	UndoDemotePrimaryResults map[string]error
+	// keyed by tablet alias.
+	UpdateThrottlerConfigResults map[string]struct {
+		Response *tabletmanagerdatapb.UpdateThrottlerConfigResponse
+		Error    error
+	}
 	// tablet alias => duration
 	VReplicationExecDelays map[string]time.Duration
 	// tablet alias => query string => result
@@ -442,6 +484,26 @@ func (fake *TabletManagerClient) DemotePrimary(ctx context.Context, tablet *topo
 	return nil, assert.AnError
 }
 
+// DrainTablet is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) DrainTablet(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	if fake.DrainTabletResults == nil {
+		return nil, fmt.Errorf("%w: no DrainTablet results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	results, ok := fake.DrainTabletResults[key]
+	if !ok || len(results) == 0 {
+		return nil, fmt.Errorf("%w: no DrainTablet result set for tablet %s", assert.AnError, key)
+	}
+
+	result := results[0]
+	if len(results) > 1 {
+		fake.DrainTabletResults[key] = results[1:]
+	}
+
+	return result.Response, result.Error
+}
+
 // ExecuteFetchAsApp is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, query []byte, maxRows int) (*querypb.QueryResult, error) {
 	if fake.ExecuteFetchAsAppResults == nil {
@@ -466,9 +528,37 @@ func (fake *TabletManagerClient) ExecuteFetchAsApp(ctx context.Context, tablet *
 	return nil, fmt.Errorf("%w: no ExecuteFetchAsApp result set for tablet %s", assert.AnError, key)
 }
 
+// ExplainQuery is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) ExplainQuery(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	if fake.ExplainQueryResults == nil {
+		return nil, fmt.Errorf("%w: no ExplainQuery results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.ExplainQueryResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no ExplainQuery result set for tablet %s", assert.AnError, key)
+}
+
+// GetHotRows is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) GetHotRows(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	if fake.GetHotRowsResults == nil {
+		return nil, fmt.Errorf("%w: no GetHotRows results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.GetHotRowsResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no GetHotRows result set for tablet %s", assert.AnError, key)
+}
+
 // ExecuteFetchAsDba is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, query []byte, maxRows int, disableBinlogs bool, reloadSchema bool) (*querypb.QueryResult, error) {
-	if fake.ExecuteFetchAsDbaResults == nil {
+	if fake.ExecuteFetchAsDbaResults == nil && fake.ExecuteFetchAsDbaQueryResults == nil {
 		return nil, fmt.Errorf("%w: no ExecuteFetchAsDba results on fake TabletManagerClient", assert.AnError)
 	}
 
@@ -483,6 +573,9 @@ func (fake *TabletManagerClient) ExecuteFetchAsDba(ctx context.Context, tablet *
 			}
 		}
 	}
+	if result, ok := fake.ExecuteFetchAsDbaQueryResults[key][string(query)]; ok {
+		return result.Response, result.Error
+	}
 	if result, ok := fake.ExecuteFetchAsDbaResults[key]; ok {
 		return result.Response, result.Error
 	}
@@ -514,6 +607,20 @@ func (fake *TabletManagerClient) ExecuteHook(ctx context.Context, tablet *topoda
 	return nil, fmt.Errorf("%w: no ExecuteHook result set for tablet %s", assert.AnError, key)
 }
 
+// FreezeWrites is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) FreezeWrites(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	if fake.FreezeWritesResults == nil {
+		return nil, fmt.Errorf("%w: no FreezeWrites results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.FreezeWritesResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no FreezeWrites result set for tablet %s", assert.AnError, key)
+}
+
 // GetPermission is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	if fake.GetPermissionsResults == nil {
@@ -558,6 +665,20 @@ func (fake *TabletManagerClient) GetReplicas(ctx context.Context, tablet *topoda
 	return nil, fmt.Errorf("no result set for %v", key)
 }
 
+// GetReplicationLagHistory is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) GetReplicationLagHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	if fake.GetReplicationLagHistoryResults == nil {
+		return nil, fmt.Errorf("%w: no GetReplicationLagHistory results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.GetReplicationLagHistoryResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no GetReplicationLagHistory result set for tablet %s", assert.AnError, key)
+}
+
 // GetSchema is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) GetSchema(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error) {
 	if fake.GetSchemaResults == nil {
@@ -588,6 +709,20 @@ func (fake *TabletManagerClient) GetSchema(ctx context.Context, tablet *topodata
 	return nil, fmt.Errorf("%w: no schemas for %s", assert.AnError, key)
 }
 
+// GetThrottlerStatus is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) GetThrottlerStatus(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	if fake.GetThrottlerStatusResults == nil {
+		return nil, fmt.Errorf("%w: no GetThrottlerStatus results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.GetThrottlerStatusResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no GetThrottlerStatus result set for tablet %s", assert.AnError, key)
+}
+
 // InitPrimary is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) InitPrimary(ctx context.Context, tablet *topodatapb.Tablet, semiSync bool) (string, error) {
 	if fake.InitPrimaryResults == nil {
@@ -1224,6 +1359,20 @@ func (fake *TabletManagerClient) UndoDemotePrimary(ctx context.Context, tablet *
 	return assert.AnError
 }
 
+// UpdateThrottlerConfig is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) UpdateThrottlerConfig(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	if fake.UpdateThrottlerConfigResults == nil {
+		return nil, fmt.Errorf("%w: no UpdateThrottlerConfig results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.UpdateThrottlerConfigResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no UpdateThrottlerConfig result set for tablet %s", assert.AnError, key)
+}
+
 // VReplicationExec is part of the tmclient.TabletManagerCLient interface.
 func (fake *TabletManagerClient) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
 	if fake.VReplicationExecResults == nil {
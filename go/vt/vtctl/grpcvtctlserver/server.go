@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vtctl"
+	"vitess.io/vitess/go/vt/vtctl/audit"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/wrangler"
 
@@ -78,7 +79,8 @@ func (s *VtctlServer) ExecuteVtctlCommand(args *vtctldatapb.ExecuteVtctlCommandR
 	return vtctl.RunCommand(stream.Context(), wr, args.Args)
 }
 
-// StartServer registers the VtctlServer for RPCs
+// StartServer registers the VtctlServer for RPCs. The commands are recorded
+// in the default audit log, if any.
 func StartServer(s *grpc.Server, ts *topo.Server) {
-	vtctlservicepb.RegisterVtctlServer(s, NewVtctlServer(ts))
+	s.RegisterService(audit.Default().Service(&vtctlservicepb.Vtctl_ServiceDesc), NewVtctlServer(ts))
 }
@@ -0,0 +1,575 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importdata loads the rows of CSV and Parquet files from the
+// backup storage into the shards of a keyspace.
+//
+// The files are read from a directory of the backup storage, where each
+// backup holds one file named "data". This is the layout written by
+// SELECT ... INTO OUTFILE S3, so its exports in the CSV format can be
+// imported as they are.
+// The backups are imported in the order of their names.
+//
+// The rows are routed to their shard with the primary vindex of the table,
+// and inserted on the shard primaries in chunks. The progress is saved in a
+// checkpoint file after each chunk, so that an interrupted import can be
+// resumed. Rows that cannot be imported can be written to a failed rows
+// file instead of stopping the import.
+package importdata
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/throttler"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// FormatCSV is the format of CSV files. NULL is written as \N.
+	FormatCSV = "csv"
+	// FormatParquet is the format of Parquet files.
+	FormatParquet = "parquet"
+
+	// dataFile is the name of the file of each backup.
+	dataFile = "data"
+	// manifestBackup is the backup of the manifest of the exports, which
+	// holds no rows.
+	manifestBackup = "manifest"
+)
+
+// rowErrors are the MySQL errors caused by the values of a row. A row that
+// fails with any other error stops the import.
+var rowErrors = map[int]bool{
+	mysql.ERDupEntry:                    true,
+	mysql.ERBadNullError:                true,
+	mysql.ERNoDefaultForField:           true,
+	mysql.ERTruncatedWrongValue:         true,
+	mysql.ERTruncatedWrongValueForField: true,
+	mysql.ERDataTooLong:                 true,
+	mysql.ERWarnDataTruncated:           true,
+	mysql.ERDataOutOfRange:              true,
+}
+
+// Config is the configuration of an import.
+type Config struct {
+	// Dir is the directory of the backup storage holding the files.
+	Dir string
+	// Format is the format of the files, FormatCSV or FormatParquet.
+	Format string
+	// Columns are the names of the columns of CSV files that have no header
+	// line. If empty, the names are read from the first line of each file.
+	Columns []string
+	// BatchSize is the number of rows of each chunk.
+	BatchSize int
+	// MaxTPS is the maximum number of inserts per second. If 0, the inserts
+	// are not throttled.
+	MaxTPS int64
+	// CheckpointFile is the local file where the progress is saved. If it
+	// exists, the import resumes from it. If empty, the progress is not
+	// saved.
+	CheckpointFile string
+	// FailedRowsFile is the local CSV file where the rows that cannot be
+	// imported are appended, with the file, row number and error. If empty,
+	// the first row that cannot be imported stops the import.
+	FailedRowsFile string
+	// MaxFailedRows is the number of failed rows past which the import
+	// stops.
+	MaxFailedRows int64
+}
+
+// Target is the table the rows are imported into.
+type Target struct {
+	Keyspace string
+	Table    string
+	// VSchema is the vschema of the keyspace.
+	VSchema *vschemapb.Keyspace
+	// Shards are the serving shards of the keyspace, by name.
+	Shards map[string]*topodatapb.Shard
+	// Fields are the columns of the table.
+	Fields []*querypb.Field
+}
+
+// InsertFunc runs an insert statement on the primary of a shard.
+type InsertFunc func(ctx context.Context, shard string, query string) error
+
+// Stats are the number of rows handled by an import, including the ones
+// handled before it was resumed.
+type Stats struct {
+	ImportedRows int64 `json:"imported_rows"`
+	FailedRows   int64 `json:"failed_rows"`
+}
+
+// checkpoint is the progress of an import. The backups before File were
+// imported, as well as the first Rows rows of File.
+type checkpoint struct {
+	File string `json:"file"`
+	Rows int64  `json:"rows"`
+	Stats
+}
+
+type importer struct {
+	cfg    *Config
+	target *Target
+	insert InsertFunc
+	router *Router
+
+	// fieldTypes are the types of the columns of the table, by name.
+	fieldTypes map[string]querypb.Type
+	throttler  *throttler.Throttler
+
+	checkpoint checkpoint
+	failedRows *csv.Writer
+}
+
+// pendingRow is a row of a chunk that is not inserted yet.
+type pendingRow struct {
+	num    int64
+	raw    [][]byte
+	values []sqltypes.Value
+}
+
+// Run imports the files of cfg.Dir from bs into the table of target. insert
+// runs the inserts on the shard primaries.
+func Run(ctx context.Context, bs backupstorage.BackupStorage, target *Target, cfg *Config, insert InsertFunc) (*Stats, error) {
+	if cfg.Format != FormatCSV && cfg.Format != FormatParquet {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown format %q, must be %s or %s", cfg.Format, FormatCSV, FormatParquet)
+	}
+	if cfg.Format == FormatParquet && len(cfg.Columns) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the columns of Parquet files are read from their schema and cannot be given")
+	}
+	if cfg.BatchSize < 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the batch size must be positive, got %d", cfg.BatchSize)
+	}
+
+	r, err := NewRouter(target)
+	if err != nil {
+		return nil, err
+	}
+	imp := &importer{
+		cfg:        cfg,
+		target:     target,
+		insert:     insert,
+		router:     r,
+		fieldTypes: make(map[string]querypb.Type, len(target.Fields)),
+	}
+	for _, field := range target.Fields {
+		imp.fieldTypes[field.Name] = field.Type
+	}
+
+	if err := imp.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxTPS > 0 {
+		t, err := throttler.NewThrottler("ImportData", "transactions", 1, cfg.MaxTPS, throttler.ReplicationLagModuleDisabled)
+		if err != nil {
+			return nil, err
+		}
+		defer t.Close()
+		imp.throttler = t
+	}
+
+	if cfg.FailedRowsFile != "" {
+		f, err := os.OpenFile(cfg.FailedRowsFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		imp.failedRows = csv.NewWriter(f)
+	}
+
+	backups, err := bs.ListBackups(ctx, cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, bh := range backups {
+		if bh.Name() == manifestBackup || bh.Name() < imp.checkpoint.File {
+			continue
+		}
+		if err := imp.importFile(ctx, bh); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot import %s/%s", cfg.Dir, bh.Name())
+		}
+	}
+
+	return &imp.checkpoint.Stats, nil
+}
+
+// importFile imports the rows of the data file of bh, after the ones saved
+// in the checkpoint.
+func (imp *importer) importFile(ctx context.Context, bh backupstorage.BackupHandle) error {
+	rc, err := bh.ReadFile(ctx, dataFile)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var src source
+	switch imp.cfg.Format {
+	case FormatCSV:
+		src, err = newCSVSource(rc, imp.cfg.Columns)
+	case FormatParquet:
+		src, err = newParquetSource(ctx, rc)
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := src.columns()
+	types := make([]querypb.Type, len(columns))
+	for i, col := range columns {
+		typ, ok := imp.fieldTypes[col]
+		if !ok {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s has no column %s", imp.target.Table, col)
+		}
+		types[i] = typ
+	}
+	vindexCol, err := imp.router.ColumnIndex(columns)
+	if err != nil {
+		return err
+	}
+
+	var skip int64
+	if bh.Name() == imp.checkpoint.File {
+		skip = imp.checkpoint.Rows
+	} else {
+		imp.checkpoint.File, imp.checkpoint.Rows = bh.Name(), 0
+	}
+
+	var num int64
+	for {
+		// Read the next chunk.
+		var chunk []*pendingRow
+		for len(chunk) < imp.cfg.BatchSize {
+			raw, err := src.next()
+			if err == io.EOF {
+				break
+			}
+			num++
+			if err != nil {
+				return vterrors.Wrapf(err, "row %d", num)
+			}
+			if num <= skip {
+				continue
+			}
+			chunk = append(chunk, &pendingRow{num: num, raw: raw})
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		// Route the rows to their shard.
+		byShard := make(map[string][]*pendingRow)
+		for _, row := range chunk {
+			shard, err := imp.route(row, types, vindexCol)
+			if err != nil {
+				if err := imp.fail(row, err); err != nil {
+					return err
+				}
+				continue
+			}
+			byShard[shard] = append(byShard[shard], row)
+		}
+
+		shards := make([]string, 0, len(byShard))
+		for shard := range byShard {
+			shards = append(shards, shard)
+		}
+		sort.Strings(shards)
+		for _, shard := range shards {
+			if err := imp.insertRows(ctx, shard, columns, byShard[shard]); err != nil {
+				return err
+			}
+		}
+
+		imp.checkpoint.Rows = chunk[len(chunk)-1].num
+		if err := imp.saveCheckpoint(); err != nil {
+			return err
+		}
+	}
+}
+
+// route converts the values of row to the types of the columns, and
+// returns the shard of the row.
+func (imp *importer) route(row *pendingRow, types []querypb.Type, vindexCol int) (string, error) {
+	row.values = make([]sqltypes.Value, len(row.raw))
+	for i, raw := range row.raw {
+		if raw == nil {
+			row.values[i] = sqltypes.NULL
+			continue
+		}
+		v, err := sqltypes.NewValue(types[i], raw)
+		if err != nil {
+			return "", err
+		}
+		row.values[i] = v
+	}
+	return imp.router.Shard(row.values, vindexCol)
+}
+
+// insertRows inserts rows on shard. If the insert fails because of the
+// values of a row, the rows are inserted one at a time to find the ones
+// that fail.
+func (imp *importer) insertRows(ctx context.Context, shard string, columns []string, rows []*pendingRow) error {
+	err := imp.runInsert(ctx, shard, columns, rows)
+	if err == nil {
+		imp.checkpoint.ImportedRows += int64(len(rows))
+		return nil
+	}
+	if !isRowError(err) {
+		return err
+	}
+	if len(rows) == 1 {
+		return imp.fail(rows[0], err)
+	}
+
+	for _, row := range rows {
+		if err := imp.insertRows(ctx, shard, columns, []*pendingRow{row}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *importer) runInsert(ctx context.Context, shard string, columns []string, rows []*pendingRow) error {
+	if imp.throttler != nil {
+		for {
+			backoff := imp.throttler.Throttle(0)
+			if backoff == throttler.NotThrottled {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+	}
+
+	return imp.insert(ctx, shard, buildInsert(imp.target.Table, columns, rows))
+}
+
+// buildInsert returns the statement inserting rows into table.
+func buildInsert(table string, columns []string, rows []*pendingRow) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "insert into %s(", sqlparser.String(sqlparser.NewIdentifierCS(table)))
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(col)))
+	}
+	buf.WriteString(") values ")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteByte('(')
+		for j, v := range row.values {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			v.EncodeSQL(buf)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// isRowError returns true if err is caused by the values of the inserted
+// rows.
+func isRowError(err error) bool {
+	sqlErr, ok := mysql.NewSQLErrorFromError(err).(*mysql.SQLError)
+	return ok && rowErrors[sqlErr.Number()]
+}
+
+// fail records a row that cannot be imported. It returns an error if the
+// import must stop.
+func (imp *importer) fail(row *pendingRow, rowErr error) error {
+	if imp.failedRows == nil {
+		return vterrors.Wrapf(rowErr, "cannot import row %d", row.num)
+	}
+
+	imp.checkpoint.FailedRows++
+	record := []string{imp.checkpoint.File, strconv.FormatInt(row.num, 10), rowErr.Error()}
+	for _, raw := range row.raw {
+		if raw == nil {
+			record = append(record, nullValue)
+		} else {
+			record = append(record, string(raw))
+		}
+	}
+	if err := imp.failedRows.Write(record); err != nil {
+		return err
+	}
+	imp.failedRows.Flush()
+	if err := imp.failedRows.Error(); err != nil {
+		return err
+	}
+
+	if imp.checkpoint.FailedRows > imp.cfg.MaxFailedRows {
+		return vterrors.Errorf(vtrpcpb.Code_ABORTED, "more than %d rows cannot be imported, the last one is row %d: %v", imp.cfg.MaxFailedRows, row.num, rowErr)
+	}
+	return nil
+}
+
+func (imp *importer) loadCheckpoint() error {
+	if imp.cfg.CheckpointFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(imp.cfg.CheckpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &imp.checkpoint); err != nil {
+		return vterrors.Wrapf(err, "cannot parse the checkpoint file %s", imp.cfg.CheckpointFile)
+	}
+	log.Infof("Resuming the import after row %d of %s", imp.checkpoint.Rows, imp.checkpoint.File)
+	return nil
+}
+
+// saveCheckpoint replaces the checkpoint file, so that it is never left
+// half written.
+func (imp *importer) saveCheckpoint() error {
+	if imp.cfg.CheckpointFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(&imp.checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp := imp.cfg.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, imp.cfg.CheckpointFile)
+}
+
+// Router maps rows to the shard holding their keyspace id.
+type Router struct {
+	// vindex and column are the sharding vindex of the table and its
+	// column. vindex is nil in unsharded keyspaces.
+	vindex vindexes.SingleColumn
+	column string
+	shards []routerShard
+}
+
+type routerShard struct {
+	name     string
+	keyRange *topodatapb.KeyRange
+}
+
+// NewRouter returns the router of the rows of the table of target. Only
+// target.Keyspace, Table, VSchema and Shards are used.
+func NewRouter(target *Target) (*Router, error) {
+	if len(target.Shards) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no serving shards", target.Keyspace)
+	}
+	r := &Router{}
+	for name, shard := range target.Shards {
+		r.shards = append(r.shards, routerShard{name: name, keyRange: shard.KeyRange})
+	}
+	sort.Slice(r.shards, func(i, j int) bool { return r.shards[i].name < r.shards[j].name })
+
+	if target.VSchema == nil || !target.VSchema.Sharded {
+		if len(r.shards) != 1 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsharded keyspace %s has %d serving shards", target.Keyspace, len(r.shards))
+		}
+		return r, nil
+	}
+
+	ks, err := vindexes.BuildKeyspaceSchema(target.VSchema, target.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := ks.Tables[target.Table]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no vschema definition for table %s", target.Table)
+	}
+	// The inserts bypass vtgate, which maintains the lookup vindexes.
+	if len(table.Owned) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s owns lookup vindexes, which are not populated by imports", target.Table)
+	}
+	colVindex, err := vindexes.FindVindexForSharding(target.Table, table.ColumnVindexes)
+	if err != nil {
+		return nil, err
+	}
+	if colVindex.Vindex.NeedsVCursor() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the vindex %s of table %s needs to query the database, which imports do not support", colVindex.Name, target.Table)
+	}
+	r.vindex = colVindex.Vindex.(vindexes.SingleColumn)
+	r.column = colVindex.Columns[0].String()
+	return r, nil
+}
+
+// ColumnIndex returns the index of the vindex column in columns.
+func (r *Router) ColumnIndex(columns []string) (int, error) {
+	if r.vindex == nil {
+		return -1, nil
+	}
+	for i, col := range columns {
+		if sqlparser.NewIdentifierCI(col).EqualString(r.column) {
+			return i, nil
+		}
+	}
+	return -1, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the rows have no column %s, which is the column of the sharding vindex", r.column)
+}
+
+// Shard returns the shard of a row, whose vindex column is at index
+// vindexCol.
+func (r *Router) Shard(row []sqltypes.Value, vindexCol int) (string, error) {
+	if r.vindex == nil {
+		return r.shards[0].name, nil
+	}
+
+	destinations, err := r.vindex.Map(nil, []sqltypes.Value{row[vindexCol]})
+	if err != nil {
+		return "", err
+	}
+	ksid, ok := destinations[0].(key.DestinationKeyspaceID)
+	if !ok || len(ksid) == 0 {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not map %v to a keyspace id", row[vindexCol])
+	}
+	for _, shard := range r.shards {
+		if key.KeyRangeContains(shard.keyRange, ksid) {
+			return shard.name, nil
+		}
+	}
+	return "", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no serving shard holds keyspace id %v", ksid)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func newTestStorage(t *testing.T) backupstorage.BackupStorage {
+	savedRoot := *filebackupstorage.FileBackupStorageRoot
+	t.Cleanup(func() { *filebackupstorage.FileBackupStorageRoot = savedRoot })
+	*filebackupstorage.FileBackupStorageRoot = t.TempDir()
+	return backupstorage.BackupStorageMap["file"]
+}
+
+func writeTestFile(t *testing.T, bs backupstorage.BackupStorage, dir, name string, data []byte) {
+	ctx := context.Background()
+	bh, err := bs.StartBackup(ctx, dir, name)
+	require.NoError(t, err)
+	w, err := bh.AddFile(ctx, dataFile, int64(len(data)))
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+}
+
+func newTestTarget(t *testing.T) *Target {
+	krs, err := key.ParseShardingSpec("-80-")
+	require.NoError(t, err)
+	shards := map[string]*topodatapb.Shard{
+		"-80": {KeyRange: krs[0]},
+		"80-": {KeyRange: krs[1]},
+	}
+	return &Target{
+		Keyspace: "ks",
+		Table:    "t1",
+		VSchema: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+			},
+		},
+		Shards: shards,
+		Fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "name", Type: querypb.Type_VARCHAR},
+		},
+	}
+}
+
+// testInserts records the inserts by shard. The inserts of the value 'dup'
+// fail as duplicates.
+type testInserts map[string][]string
+
+func (ti testInserts) insert(ctx context.Context, shard string, query string) error {
+	if strings.Contains(query, "'dup'") {
+		return mysql.NewSQLError(mysql.ERDupEntry, mysql.SSConstraintViolation, "Duplicate entry")
+	}
+	ti[shard] = append(ti[shard], query)
+	return nil
+}
+
+func TestRunCSV(t *testing.T) {
+	bs := newTestStorage(t)
+	writeTestFile(t, bs, "exports/t1", "part-00001", []byte("id,name\n1,a\n2,\\N\n3,c\n"))
+	writeTestFile(t, bs, "exports/t1", "part-00002", []byte("id,name\n4,d\n"))
+	writeTestFile(t, bs, "exports/t1", "manifest", []byte("{}"))
+
+	inserts := testInserts{}
+	stats, err := Run(context.Background(), bs, newTestTarget(t), &Config{
+		Dir:       "exports/t1",
+		Format:    FormatCSV,
+		BatchSize: 2,
+	}, inserts.insert)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{ImportedRows: 4}, stats)
+	assert.Equal(t, testInserts{
+		"-80": {
+			"insert into t1(id, `name`) values (1, 'a'), (2, null)",
+			"insert into t1(id, `name`) values (3, 'c')",
+		},
+		"80-": {
+			"insert into t1(id, `name`) values (4, 'd')",
+		},
+	}, inserts)
+}
+
+func TestRunCheckpointAndFailedRows(t *testing.T) {
+	bs := newTestStorage(t)
+	writeTestFile(t, bs, "import", "a", []byte("1,a\n2,dup\nx,c\n3,c\n"))
+	writeTestFile(t, bs, "import", "b", []byte("4,d\n"))
+	dir := t.TempDir()
+	cfg := &Config{
+		Dir:            "import",
+		Format:         FormatCSV,
+		Columns:        []string{"id", "name"},
+		BatchSize:      3,
+		CheckpointFile: path.Join(dir, "checkpoint"),
+		FailedRowsFile: path.Join(dir, "failed.csv"),
+		MaxFailedRows:  10,
+	}
+
+	// The first chunk of a is imported, except its failed rows.
+	require.NoError(t, os.WriteFile(cfg.CheckpointFile, []byte(`{"file":"a","rows":0}`), 0644))
+	inserts := testInserts{}
+	stats, err := Run(context.Background(), bs, newTestTarget(t), cfg, inserts.insert)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{ImportedRows: 3, FailedRows: 2}, stats)
+	assert.Equal(t, testInserts{
+		"-80": {"insert into t1(id, `name`) values (1, 'a')", "insert into t1(id, `name`) values (3, 'c')"},
+		"80-": {"insert into t1(id, `name`) values (4, 'd')"},
+	}, inserts)
+
+	data, err := os.ReadFile(cfg.FailedRowsFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	// The rows that cannot be routed fail before the inserts of their chunk.
+	assert.True(t, strings.HasPrefix(lines[0], "a,3,"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[0], ",x,c"), lines[0])
+	assert.Equal(t, "a,2,Duplicate entry (errno 1062) (sqlstate 23000),2,dup", lines[1])
+
+	data, err = os.ReadFile(cfg.CheckpointFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"file":"b","rows":1,"imported_rows":3,"failed_rows":2}`, string(data))
+
+	// The import is resumed after the checkpoint.
+	writeTestFile(t, bs, "import", "c", []byte("5,e\n"))
+	inserts = testInserts{}
+	stats, err = Run(context.Background(), bs, newTestTarget(t), cfg, inserts.insert)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{ImportedRows: 4, FailedRows: 2}, stats)
+	assert.Equal(t, testInserts{"-80": {"insert into t1(id, `name`) values (5, 'e')"}}, inserts)
+
+	// Without a failed rows file, a failed row stops the import.
+	writeTestFile(t, bs, "import", "d", []byte("6,dup\n"))
+	cfg.FailedRowsFile = ""
+	_, err = Run(context.Background(), bs, newTestTarget(t), cfg, inserts.insert)
+	assert.ErrorContains(t, err, "cannot import row 1")
+
+	// Errors that are not caused by the rows stop the import.
+	writeTestFile(t, bs, "import", "e", []byte("7,g\n"))
+	_, err = Run(context.Background(), bs, newTestTarget(t), cfg, func(ctx context.Context, shard string, query string) error {
+		return errors.New("tablet is unreachable")
+	})
+	assert.ErrorContains(t, err, "tablet is unreachable")
+}
+
+func TestRunParquet(t *testing.T) {
+	bs := newTestStorage(t)
+
+	schema, err := parquetschema.ParseSchemaDefinition(`message t1 {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	w := goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(schema))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1), "name": []byte("a")}))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(2)}))
+	require.NoError(t, w.Close())
+	writeTestFile(t, bs, "import", "a", buf.Bytes())
+
+	inserts := testInserts{}
+	stats, err := Run(context.Background(), bs, newTestTarget(t), &Config{
+		Dir:       "import",
+		Format:    FormatParquet,
+		BatchSize: 10,
+	}, inserts.insert)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{ImportedRows: 2}, stats)
+	assert.Equal(t, testInserts{
+		"-80": {"insert into t1(id, `name`) values (1, 'a'), (2, null)"},
+	}, inserts)
+}
+
+func TestNewRouter(t *testing.T) {
+	target := newTestTarget(t)
+	r, err := NewRouter(target)
+	require.NoError(t, err)
+	_, err = r.ColumnIndex([]string{"name"})
+	assert.ErrorContains(t, err, "no column id")
+
+	target.VSchema.Vindexes["lookup"] = &vschemapb.Vindex{
+		Type:   "lookup_unique",
+		Params: map[string]string{"table": "lkp", "from": "name", "to": "keyspace_id"},
+		Owner:  "t1",
+	}
+	target.VSchema.Tables["t1"].ColumnVindexes = append(target.VSchema.Tables["t1"].ColumnVindexes, &vschemapb.ColumnVindex{Column: "name", Name: "lookup"})
+	_, err = NewRouter(target)
+	assert.ErrorContains(t, err, "owns lookup vindexes")
+
+	// Unsharded keyspaces have a single shard.
+	r, err = NewRouter(&Target{
+		Keyspace: "uks",
+		Shards:   map[string]*topodatapb.Shard{"0": {KeyRange: &topodatapb.KeyRange{}}},
+	})
+	require.NoError(t, err)
+	shard, err := r.Shard(nil, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "0", shard)
+}
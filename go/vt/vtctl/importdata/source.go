@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importdata
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// nullValue is the representation of NULL in CSV files. It is the one
+// used by SELECT ... INTO OUTFILE.
+const nullValue = `\N`
+
+// source reads the rows of a data file.
+type source interface {
+	// columns returns the names of the columns of the rows.
+	columns() []string
+
+	// next returns the values of the next row, where nil is NULL. It
+	// returns io.EOF after the last row.
+	next() ([][]byte, error)
+}
+
+// csvSource reads a CSV file. The column names are read from its first
+// line, unless they are given.
+type csvSource struct {
+	reader *csv.Reader
+	names  []string
+}
+
+func newCSVSource(r io.Reader, names []string) (*csvSource, error) {
+	reader := csv.NewReader(r)
+	if len(names) == 0 {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the file has no header line with the column names")
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = header
+	}
+	reader.FieldsPerRecord = len(names)
+	return &csvSource{reader: reader, names: names}, nil
+}
+
+func (s *csvSource) columns() []string {
+	return s.names
+}
+
+func (s *csvSource) next() ([][]byte, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([][]byte, len(record))
+	for i, field := range record {
+		if field != nullValue {
+			row[i] = []byte(field)
+		}
+	}
+	return row, nil
+}
+
+// parquetSource reads a Parquet file. Parquet files are read from their
+// footer, so the whole file is held in memory.
+type parquetSource struct {
+	reader *goparquet.FileReader
+	cols   []*goparquet.Column
+	names  []string
+}
+
+func newParquetSource(ctx context.Context, r io.Reader) (*parquetSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := goparquet.NewFileReaderWithContext(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read the Parquet file")
+	}
+
+	cols := reader.Columns()
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		if !col.DataColumn() || col.MaxRepetitionLevel() > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported nested or repeated Parquet column %s", col.Name())
+		}
+		names[i] = col.Name()
+	}
+	return &parquetSource{reader: reader, cols: cols, names: names}, nil
+}
+
+func (s *parquetSource) columns() []string {
+	return s.names
+}
+
+func (s *parquetSource) next() ([][]byte, error) {
+	values, err := s.reader.NextRow()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([][]byte, len(s.cols))
+	for i, col := range s.cols {
+		v, err := parquetValue(col.Element(), values[col.Name()])
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "column %s", s.names[i])
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+// parquetValue returns the value v of a column described by elem in the
+// text format of MySQL, or nil if it is null.
+func parquetValue(elem *parquet.SchemaElement, v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if !supportedParquetType(elem) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported annotation of Parquet type %v", elem.GetType())
+	}
+
+	logical := elem.GetLogicalType()
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return []byte("1"), nil
+		}
+		return []byte("0"), nil
+	case int32:
+		switch {
+		case logical != nil && logical.IsSetDATE() || elem.IsSetConvertedType() && elem.GetConvertedType() == parquet.ConvertedType_DATE:
+			return []byte(time.Unix(int64(v)*24*60*60, 0).UTC().Format("2006-01-02")), nil
+		case isUnsigned(elem):
+			return strconv.AppendUint(nil, uint64(uint32(v)), 10), nil
+		}
+		return strconv.AppendInt(nil, int64(v), 10), nil
+	case int64:
+		if unit := timestampUnit(elem); unit != 0 {
+			return []byte(time.Unix(0, 0).Add(time.Duration(v) * unit).UTC().Format("2006-01-02 15:04:05.999999")), nil
+		}
+		if isUnsigned(elem) {
+			return strconv.AppendUint(nil, uint64(v), 10), nil
+		}
+		return strconv.AppendInt(nil, v, 10), nil
+	case [12]byte:
+		return []byte(goparquet.Int96ToTime(v).UTC().Format("2006-01-02 15:04:05.999999")), nil
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case []byte:
+		return append([]byte{}, v...), nil
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported Parquet type %v", elem.GetType())
+}
+
+// supportedParquetType returns false for the annotated types whose values
+// are not converted to the text format of MySQL, like decimals.
+func supportedParquetType(elem *parquet.SchemaElement) bool {
+	if logical := elem.GetLogicalType(); logical != nil {
+		return logical.IsSetSTRING() || logical.IsSetENUM() || logical.IsSetJSON() || logical.IsSetINTEGER() || logical.IsSetDATE() || logical.IsSetTIMESTAMP()
+	}
+	if !elem.IsSetConvertedType() {
+		return true
+	}
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_DECIMAL, parquet.ConvertedType_TIME_MILLIS, parquet.ConvertedType_TIME_MICROS, parquet.ConvertedType_INTERVAL:
+		return false
+	}
+	return true
+}
+
+// isUnsigned returns true if the integers of a column are unsigned.
+func isUnsigned(elem *parquet.SchemaElement) bool {
+	if logical := elem.GetLogicalType(); logical != nil && logical.IsSetINTEGER() {
+		return !logical.INTEGER.IsSigned
+	}
+	if !elem.IsSetConvertedType() {
+		return false
+	}
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16, parquet.ConvertedType_UINT_32, parquet.ConvertedType_UINT_64:
+		return true
+	}
+	return false
+}
+
+// timestampUnit returns the unit of the timestamps of a column, or 0 if
+// it does not hold timestamps.
+func timestampUnit(elem *parquet.SchemaElement) time.Duration {
+	if logical := elem.GetLogicalType(); logical != nil && logical.IsSetTIMESTAMP() {
+		switch unit := logical.TIMESTAMP.Unit; {
+		case unit.IsSetMILLIS():
+			return time.Millisecond
+		case unit.IsSetMICROS():
+			return time.Microsecond
+		case unit.IsSetNANOS():
+			return time.Nanosecond
+		}
+	}
+	if !elem.IsSetConvertedType() {
+		return 0
+	}
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_TIMESTAMP_MILLIS:
+		return time.Millisecond
+	case parquet.ConvertedType_TIMESTAMP_MICROS:
+		return time.Microsecond
+	}
+	return 0
+}
@@ -39,6 +39,16 @@ func (client *localVtctldClient) AddCellsAlias(ctx context.Context, in *vtctldat
 	return client.s.AddCellsAlias(ctx, in)
 }
 
+// AnalyzeIndexUsage is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) AnalyzeIndexUsage(ctx context.Context, in *vtctldatapb.AnalyzeIndexUsageRequest, opts ...grpc.CallOption) (*vtctldatapb.AnalyzeIndexUsageResponse, error) {
+	return client.s.AnalyzeIndexUsage(ctx, in)
+}
+
+// AnalyzeShardDistribution is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) AnalyzeShardDistribution(ctx context.Context, in *vtctldatapb.AnalyzeShardDistributionRequest, opts ...grpc.CallOption) (*vtctldatapb.AnalyzeShardDistributionResponse, error) {
+	return client.s.AnalyzeShardDistribution(ctx, in)
+}
+
 // ApplyRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ApplyRoutingRules(ctx context.Context, in *vtctldatapb.ApplyRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyRoutingRulesResponse, error) {
 	return client.s.ApplyRoutingRules(ctx, in)
@@ -91,6 +101,11 @@ func (stream *backupStreamAdapter) Send(msg *vtctldatapb.BackupResponse) error {
 	}
 }
 
+// ApproveDBAScript is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ApproveDBAScript(ctx context.Context, in *vtctldatapb.ApproveDBAScriptRequest, opts ...grpc.CallOption) (*vtctldatapb.ApproveDBAScriptResponse, error) {
+	return client.s.ApproveDBAScript(ctx, in)
+}
+
 // Backup is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) Backup(ctx context.Context, in *vtctldatapb.BackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupClient, error) {
 	stream := &backupStreamAdapter{
@@ -161,6 +176,11 @@ func (client *localVtctldClient) ChangeTabletType(ctx context.Context, in *vtctl
 	return client.s.ChangeTabletType(ctx, in)
 }
 
+// CreateArchival is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) CreateArchival(ctx context.Context, in *vtctldatapb.CreateArchivalRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateArchivalResponse, error) {
+	return client.s.CreateArchival(ctx, in)
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CreateKeyspace(ctx context.Context, in *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	return client.s.CreateKeyspace(ctx, in)
@@ -171,6 +191,11 @@ func (client *localVtctldClient) CreateShard(ctx context.Context, in *vtctldatap
 	return client.s.CreateShard(ctx, in)
 }
 
+// DeleteArchival is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DeleteArchival(ctx context.Context, in *vtctldatapb.DeleteArchivalRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteArchivalResponse, error) {
+	return client.s.DeleteArchival(ctx, in)
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) DeleteCellInfo(ctx context.Context, in *vtctldatapb.DeleteCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteCellInfoResponse, error) {
 	return client.s.DeleteCellInfo(ctx, in)
@@ -186,6 +211,11 @@ func (client *localVtctldClient) DeleteKeyspace(ctx context.Context, in *vtctlda
 	return client.s.DeleteKeyspace(ctx, in)
 }
 
+// DeletePartitionPolicy is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DeletePartitionPolicy(ctx context.Context, in *vtctldatapb.DeletePartitionPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.DeletePartitionPolicyResponse, error) {
+	return client.s.DeletePartitionPolicy(ctx, in)
+}
+
 // DeleteShards is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) DeleteShards(ctx context.Context, in *vtctldatapb.DeleteShardsRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteShardsResponse, error) {
 	return client.s.DeleteShards(ctx, in)
@@ -201,11 +231,21 @@ func (client *localVtctldClient) DeleteTablets(ctx context.Context, in *vtctldat
 	return client.s.DeleteTablets(ctx, in)
 }
 
+// DrainTablet is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DrainTablet(ctx context.Context, in *vtctldatapb.DrainTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.DrainTabletResponse, error) {
+	return client.s.DrainTablet(ctx, in)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	return client.s.EmergencyReparentShard(ctx, in)
 }
 
+// ExecDBA is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExecDBA(ctx context.Context, in *vtctldatapb.ExecDBARequest, opts ...grpc.CallOption) (*vtctldatapb.ExecDBAResponse, error) {
+	return client.s.ExecDBA(ctx, in)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	return client.s.ExecuteFetchAsApp(ctx, in)
@@ -221,11 +261,26 @@ func (client *localVtctldClient) ExecuteHook(ctx context.Context, in *vtctldatap
 	return client.s.ExecuteHook(ctx, in)
 }
 
+// ExplainQuery is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExplainQuery(ctx context.Context, in *vtctldatapb.ExplainQueryRequest, opts ...grpc.CallOption) (*vtctldatapb.ExplainQueryResponse, error) {
+	return client.s.ExplainQuery(ctx, in)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	return client.s.FindAllShardsInKeyspace(ctx, in)
 }
 
+// GetArchivals is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetArchivals(ctx context.Context, in *vtctldatapb.GetArchivalsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetArchivalsResponse, error) {
+	return client.s.GetArchivals(ctx, in)
+}
+
+// GetAuditLog is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetAuditLog(ctx context.Context, in *vtctldatapb.GetAuditLogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetAuditLogResponse, error) {
+	return client.s.GetAuditLog(ctx, in)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	return client.s.GetBackups(ctx, in)
@@ -246,16 +301,36 @@ func (client *localVtctldClient) GetCellsAliases(ctx context.Context, in *vtctld
 	return client.s.GetCellsAliases(ctx, in)
 }
 
+// GetDBAScripts is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetDBAScripts(ctx context.Context, in *vtctldatapb.GetDBAScriptsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDBAScriptsResponse, error) {
+	return client.s.GetDBAScripts(ctx, in)
+}
+
 // GetKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatapb.GetKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceResponse, error) {
 	return client.s.GetKeyspace(ctx, in)
 }
 
+// GetKeyspaceSettings is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetKeyspaceSettings(ctx context.Context, in *vtctldatapb.GetKeyspaceSettingsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceSettingsResponse, error) {
+	return client.s.GetKeyspaceSettings(ctx, in)
+}
+
 // GetKeyspaces is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetKeyspaces(ctx context.Context, in *vtctldatapb.GetKeyspacesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspacesResponse, error) {
 	return client.s.GetKeyspaces(ctx, in)
 }
 
+// GetMySQLUserLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetMySQLUserLimits(ctx context.Context, in *vtctldatapb.GetMySQLUserLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetMySQLUserLimitsResponse, error) {
+	return client.s.GetMySQLUserLimits(ctx, in)
+}
+
+// GetPartitionPolicies is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetPartitionPolicies(ctx context.Context, in *vtctldatapb.GetPartitionPoliciesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPartitionPoliciesResponse, error) {
+	return client.s.GetPartitionPolicies(ctx, in)
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetPermissions(ctx context.Context, in *vtctldatapb.GetPermissionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPermissionsResponse, error) {
 	return client.s.GetPermissions(ctx, in)
@@ -281,6 +356,11 @@ func (client *localVtctldClient) GetSrvKeyspaceNames(ctx context.Context, in *vt
 	return client.s.GetSrvKeyspaceNames(ctx, in)
 }
 
+// GetSrvKeyspacePartitions is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSrvKeyspacePartitions(ctx context.Context, in *vtctldatapb.GetSrvKeyspacePartitionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspacePartitionsResponse, error) {
+	return client.s.GetSrvKeyspacePartitions(ctx, in)
+}
+
 // GetSrvKeyspaces is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetSrvKeyspaces(ctx context.Context, in *vtctldatapb.GetSrvKeyspacesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspacesResponse, error) {
 	return client.s.GetSrvKeyspaces(ctx, in)
@@ -296,6 +376,16 @@ func (client *localVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctlda
 	return client.s.GetSrvVSchemas(ctx, in)
 }
 
+// GetTableSizes is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetTableSizes(ctx context.Context, in *vtctldatapb.GetTableSizesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTableSizesResponse, error) {
+	return client.s.GetTableSizes(ctx, in)
+}
+
+// GetThrottlerStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetThrottlerStatus(ctx context.Context, in *vtctldatapb.GetThrottlerStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetThrottlerStatusResponse, error) {
+	return client.s.GetThrottlerStatus(ctx, in)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	return client.s.GetTablet(ctx, in)
@@ -311,11 +401,21 @@ func (client *localVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb
 	return client.s.GetVSchema(ctx, in)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	return client.s.GetVSchemaHistory(ctx, in)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	return client.s.GetVersion(ctx, in)
 }
 
+// GetVersionInfo is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVersionInfo(ctx context.Context, in *vtctldatapb.GetVersionInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionInfoResponse, error) {
+	return client.s.GetVersionInfo(ctx, in)
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetWorkflows(ctx context.Context, in *vtctldatapb.GetWorkflowsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetWorkflowsResponse, error) {
 	return client.s.GetWorkflows(ctx, in)
@@ -391,6 +491,11 @@ func (client *localVtctldClient) ReparentTablet(ctx context.Context, in *vtctlda
 	return client.s.ReparentTablet(ctx, in)
 }
 
+// RestoreDroppedTable is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RestoreDroppedTable(ctx context.Context, in *vtctldatapb.RestoreDroppedTableRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreDroppedTableResponse, error) {
+	return client.s.RestoreDroppedTable(ctx, in)
+}
+
 type restoreFromBackupStreamAdapter struct {
 	*grpcshim.BidiStream
 	ch chan *vtctldatapb.RestoreFromBackupResponse
@@ -442,11 +547,72 @@ func (client *localVtctldClient) RestoreFromBackup(ctx context.Context, in *vtct
 	return stream, nil
 }
 
+type rollingRestartStreamAdapter struct {
+	*grpcshim.BidiStream
+	ch chan *vtctldatapb.RollingRestartResponse
+}
+
+func (stream *rollingRestartStreamAdapter) Recv() (*vtctldatapb.RollingRestartResponse, error) {
+	select {
+	case <-stream.Context().Done():
+		return nil, stream.Context().Err()
+	case <-stream.Closed():
+		// Stream has been closed for future sends. If there are messages that
+		// have already been sent, receive them until there are no more. After
+		// all sent messages have been received, Recv will return the CloseErr.
+		select {
+		case msg := <-stream.ch:
+			return msg, nil
+		default:
+			return nil, stream.CloseErr()
+		}
+	case err := <-stream.ErrCh:
+		return nil, err
+	case msg := <-stream.ch:
+		return msg, nil
+	}
+}
+
+func (stream *rollingRestartStreamAdapter) Send(msg *vtctldatapb.RollingRestartResponse) error {
+	select {
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-stream.Closed():
+		return grpcshim.ErrStreamClosed
+	case stream.ch <- msg:
+		return nil
+	}
+}
+
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	return client.s.RollbackVSchema(ctx, in)
+}
+
+// RollingRestart is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RollingRestart(ctx context.Context, in *vtctldatapb.RollingRestartRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RollingRestartClient, error) {
+	stream := &rollingRestartStreamAdapter{
+		BidiStream: grpcshim.NewBidiStream(ctx),
+		ch:         make(chan *vtctldatapb.RollingRestartResponse, 1),
+	}
+	go func() {
+		err := client.s.RollingRestart(in, stream)
+		stream.CloseWithError(err)
+	}()
+
+	return stream, nil
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	return client.s.RunHealthCheck(ctx, in)
 }
 
+// SetArchivalPaused is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetArchivalPaused(ctx context.Context, in *vtctldatapb.SetArchivalPausedRequest, opts ...grpc.CallOption) (*vtctldatapb.SetArchivalPausedResponse, error) {
+	return client.s.SetArchivalPaused(ctx, in)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	return client.s.SetKeyspaceDurabilityPolicy(ctx, in)
@@ -457,16 +623,41 @@ func (client *localVtctldClient) SetKeyspaceServedFrom(ctx context.Context, in *
 	return client.s.SetKeyspaceServedFrom(ctx, in)
 }
 
+// SetKeyspaceSettings is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetKeyspaceSettings(ctx context.Context, in *vtctldatapb.SetKeyspaceSettingsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceSettingsResponse, error) {
+	return client.s.SetKeyspaceSettings(ctx, in)
+}
+
+// SetMySQLUserLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetMySQLUserLimits(ctx context.Context, in *vtctldatapb.SetMySQLUserLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetMySQLUserLimitsResponse, error) {
+	return client.s.SetMySQLUserLimits(ctx, in)
+}
+
+// SetPartitionPolicy is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetPartitionPolicy(ctx context.Context, in *vtctldatapb.SetPartitionPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetPartitionPolicyResponse, error) {
+	return client.s.SetPartitionPolicy(ctx, in)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	return client.s.SetShardIsPrimaryServing(ctx, in)
 }
 
+// SetShardReadOnly is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetShardReadOnly(ctx context.Context, in *vtctldatapb.SetShardReadOnlyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardReadOnlyResponse, error) {
+	return client.s.SetShardReadOnly(ctx, in)
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardTabletControl(ctx context.Context, in *vtctldatapb.SetShardTabletControlRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardTabletControlResponse, error) {
 	return client.s.SetShardTabletControl(ctx, in)
 }
 
+// SetSrvKeyspacePartition is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetSrvKeyspacePartition(ctx context.Context, in *vtctldatapb.SetSrvKeyspacePartitionRequest, opts ...grpc.CallOption) (*vtctldatapb.SetSrvKeyspacePartitionResponse, error) {
+	return client.s.SetSrvKeyspacePartition(ctx, in)
+}
+
 // SetWritable is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetWritable(ctx context.Context, in *vtctldatapb.SetWritableRequest, opts ...grpc.CallOption) (*vtctldatapb.SetWritableResponse, error) {
 	return client.s.SetWritable(ctx, in)
@@ -532,6 +723,16 @@ func (client *localVtctldClient) UpdateCellsAlias(ctx context.Context, in *vtctl
 	return client.s.UpdateCellsAlias(ctx, in)
 }
 
+// UpdateSequence is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) UpdateSequence(ctx context.Context, in *vtctldatapb.UpdateSequenceRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateSequenceResponse, error) {
+	return client.s.UpdateSequence(ctx, in)
+}
+
+// UpdateThrottlerConfig is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) UpdateThrottlerConfig(ctx context.Context, in *vtctldatapb.UpdateThrottlerConfigRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateThrottlerConfigResponse, error) {
+	return client.s.UpdateThrottlerConfig(ctx, in)
+}
+
 // Validate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) Validate(ctx context.Context, in *vtctldatapb.ValidateRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateResponse, error) {
 	return client.s.Validate(ctx, in)
@@ -561,3 +762,28 @@ func (client *localVtctldClient) ValidateVSchema(ctx context.Context, in *vtctld
 func (client *localVtctldClient) ValidateVersionKeyspace(ctx context.Context, in *vtctldatapb.ValidateVersionKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVersionKeyspaceResponse, error) {
 	return client.s.ValidateVersionKeyspace(ctx, in)
 }
+
+// ValidateVersionSkew is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ValidateVersionSkew(ctx context.Context, in *vtctldatapb.ValidateVersionSkewRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVersionSkewResponse, error) {
+	return client.s.ValidateVersionSkew(ctx, in)
+}
+
+// GetFeatureGates is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetFeatureGates(ctx context.Context, in *vtctldatapb.GetFeatureGatesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFeatureGatesResponse, error) {
+	return client.s.GetFeatureGates(ctx, in)
+}
+
+// SetFeatureGate is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetFeatureGate(ctx context.Context, in *vtctldatapb.SetFeatureGateRequest, opts ...grpc.CallOption) (*vtctldatapb.SetFeatureGateResponse, error) {
+	return client.s.SetFeatureGate(ctx, in)
+}
+
+// GetRuntimeFlags is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetRuntimeFlags(ctx context.Context, in *vtctldatapb.GetRuntimeFlagsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetRuntimeFlagsResponse, error) {
+	return client.s.GetRuntimeFlags(ctx, in)
+}
+
+// SetRuntimeFlag is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetRuntimeFlag(ctx context.Context, in *vtctldatapb.SetRuntimeFlagRequest, opts ...grpc.CallOption) (*vtctldatapb.SetRuntimeFlagResponse, error) {
+	return client.s.SetRuntimeFlag(ctx, in)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"strings"
+
+	"vitess.io/vitess/go/vt/schemadiff"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+// ToSchemadiffSchema converts the schema of a tablet to a schemadiff.Schema.
+// The database name placeholder is stripped from the definitions of the views,
+// so that they reference the tables of their own database.
+func ToSchemadiffSchema(sd *tabletmanagerdatapb.SchemaDefinition) (*schemadiff.Schema, error) {
+	queries := make([]string, 0, len(sd.TableDefinitions))
+	for _, td := range sd.TableDefinitions {
+		queries = append(queries, strings.ReplaceAll(td.Schema, "{{.DatabaseName}}.", ""))
+	}
+
+	return schemadiff.NewSchemaFromQueries(queries)
+}
+
+// GenerateDDL returns the DDL statements that converge the current schema to
+// the desired schema, in the order in which they must be applied. It returns
+// no statements if the schemas already match.
+func GenerateDDL(current *schemadiff.Schema, desired *schemadiff.Schema) ([]string, error) {
+	diffs, err := current.Diff(desired, &schemadiff.DiffHints{})
+	if err != nil {
+		return nil, err
+	}
+
+	var ddls []string
+	for _, diff := range diffs {
+		for _, d := range schemadiff.AllSubsequent(diff) {
+			ddls = append(ddls, d.StatementString())
+		}
+	}
+
+	return ddls, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schemadiff"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestGenerateDDL(t *testing.T) {
+	current, err := ToSchemadiffSchema(&tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			{Name: "t1", Schema: "CREATE TABLE `t1` (`id` int NOT NULL, PRIMARY KEY (`id`))"},
+			{Name: "t2", Schema: "CREATE TABLE `t2` (`id` int NOT NULL, PRIMARY KEY (`id`))"},
+			{Name: "v1", Schema: "CREATE VIEW {{.DatabaseName}}.`v1` AS select `id` from {{.DatabaseName}}.`t2`", Type: "VIEW"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		desired string
+		want    []string
+	}{
+		{
+			name: "same schema",
+			desired: "CREATE TABLE `t1` (`id` int NOT NULL, PRIMARY KEY (`id`));" +
+				"CREATE TABLE `t2` (`id` int NOT NULL, PRIMARY KEY (`id`));" +
+				"CREATE VIEW `v1` AS select `id` from `t2`",
+		},
+		{
+			name: "drop, alter and create",
+			desired: "CREATE TABLE `t1` (`id` int NOT NULL, `name` varchar(64), PRIMARY KEY (`id`));" +
+				"CREATE TABLE `t3` (`id` int NOT NULL, PRIMARY KEY (`id`))",
+			want: []string{
+				"drop table t2",
+				"drop view v1",
+				"alter table t1 add column `name` varchar(64)",
+				"create table t3 (\n\tid int not null,\n\tPRIMARY KEY (id)\n)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			desired, err := schemadiff.NewSchemaFromSQL(tt.desired)
+			require.NoError(t, err)
+
+			ddls, err := GenerateDDL(current, desired)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ddls)
+		})
+	}
+}
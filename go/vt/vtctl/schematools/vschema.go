@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ValidateVSchema cross-checks a keyspace's vschema against the schema of one
+// of its shards. It returns a description of every table, vindex column,
+// auto-increment column, sequence or authoritative column list in the vschema
+// that does not match the schema. A nil result means the vschema is valid.
+//
+// Sequences qualified with another keyspace are not checked, since their
+// tables live outside this schema.
+func ValidateVSchema(vs *vschemapb.Keyspace, sd *tabletmanagerdatapb.SchemaDefinition) []string {
+	tables := make(map[string]map[string]bool, len(sd.TableDefinitions))
+	for _, td := range sd.TableDefinitions {
+		columns := make(map[string]bool, len(td.Columns))
+		for _, col := range td.Columns {
+			columns[strings.ToLower(col)] = true
+		}
+		tables[td.Name] = columns
+	}
+
+	names := make([]string, 0, len(vs.Tables))
+	for name := range vs.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		table := vs.Tables[name]
+		columns, ok := tables[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("table %s does not exist", name))
+			continue
+		}
+
+		checkColumn := func(what, col string) {
+			if col != "" && !columns[strings.ToLower(col)] {
+				problems = append(problems, fmt.Sprintf("%s column %s does not exist in table %s", what, col, name))
+			}
+		}
+
+		if table.Type == "sequence" {
+			checkColumn("sequence", "next_id")
+			checkColumn("sequence", "cache")
+		}
+
+		for _, cv := range table.ColumnVindexes {
+			checkColumn(fmt.Sprintf("vindex %s", cv.Name), cv.Column)
+			for _, col := range cv.Columns {
+				checkColumn(fmt.Sprintf("vindex %s", cv.Name), col)
+			}
+		}
+
+		if ai := table.AutoIncrement; ai != nil {
+			checkColumn("auto_increment", ai.Column)
+			if ai.Sequence != "" && !strings.Contains(ai.Sequence, ".") {
+				if seq, ok := vs.Tables[ai.Sequence]; !ok || seq.Type != "sequence" {
+					problems = append(problems, fmt.Sprintf("auto_increment sequence %s of table %s is not a sequence table in this keyspace", ai.Sequence, name))
+				}
+			}
+		}
+
+		listed := make(map[string]bool, len(table.Columns))
+		for _, col := range table.Columns {
+			listed[strings.ToLower(col.Name)] = true
+			checkColumn("listed", col.Name)
+		}
+		if table.ColumnListAuthoritative {
+			var missing []string
+			for col := range columns {
+				if !listed[col] {
+					missing = append(missing, col)
+				}
+			}
+			if len(missing) > 0 {
+				sort.Strings(missing)
+				problems = append(problems, fmt.Sprintf("authoritative column list of table %s is missing columns %s", name, strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	return problems
+}
+
+// ValidateVSchemaAgainstKeyspace fetches the schema from the primary of every
+// shard in the keyspace and validates the vschema against each of them.
+func ValidateVSchemaAgainstKeyspace(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, keyspace string, vs *vschemapb.Keyspace) error {
+	shards, err := ts.FindAllShardsInKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	shardNames := make([]string, 0, len(shards))
+	for name := range shards {
+		shardNames = append(shardNames, name)
+	}
+	sort.Strings(shardNames)
+
+	var problems []string
+	for _, shard := range shardNames {
+		si := shards[shard]
+		if !si.HasPrimary() {
+			return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %s/%s has no primary", keyspace, shard)
+		}
+
+		sd, err := GetSchema(ctx, ts, tmc, si.PrimaryAlias, &tabletmanagerdatapb.GetSchemaRequest{IncludeViews: true})
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range ValidateVSchema(vs, sd) {
+			problems = append(problems, fmt.Sprintf("%s/%s: %s", keyspace, shard, problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "vschema does not match the schema:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
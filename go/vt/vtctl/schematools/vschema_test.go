@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestValidateVSchema(t *testing.T) {
+	sd := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			{Name: "t1", Columns: []string{"id", "name", "Email"}},
+			{Name: "t1_seq", Columns: []string{"id", "next_id", "cache"}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		vs   *vschemapb.Keyspace
+		want []string
+	}{
+		{
+			name: "valid",
+			vs: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}},
+						AutoIncrement:  &vschemapb.AutoIncrement{Column: "id", Sequence: "t1_seq"},
+						Columns:        []*vschemapb.Column{{Name: "id"}, {Name: "name"}, {Name: "email"}},
+
+						ColumnListAuthoritative: true,
+					},
+					"t1_seq": {Type: "sequence"},
+				},
+			},
+		},
+		{
+			name: "qualified sequence is not checked",
+			vs: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"t1": {AutoIncrement: &vschemapb.AutoIncrement{Column: "id", Sequence: "other.t1_seq"}},
+				},
+			},
+		},
+		{
+			name: "missing table",
+			vs: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"t2": {},
+				},
+			},
+			want: []string{"table t2 does not exist"},
+		},
+		{
+			name: "missing columns",
+			vs: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "lookup", Columns: []string{"id", "region"}}},
+						AutoIncrement:  &vschemapb.AutoIncrement{Column: "uid", Sequence: "t1_seq"},
+						Columns:        []*vschemapb.Column{{Name: "id"}, {Name: "nickname"}},
+
+						ColumnListAuthoritative: true,
+					},
+				},
+			},
+			want: []string{
+				"vindex lookup column region does not exist in table t1",
+				"auto_increment column uid does not exist in table t1",
+				"auto_increment sequence t1_seq of table t1 is not a sequence table in this keyspace",
+				"listed column nickname does not exist in table t1",
+				"authoritative column list of table t1 is missing columns email, name",
+			},
+		},
+		{
+			name: "sequence without sequence columns",
+			vs: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"t1": {Type: "sequence"},
+				},
+			},
+			want: []string{
+				"sequence column next_id does not exist in table t1",
+				"sequence column cache does not exist in table t1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidateVSchema(tt.vs, sd))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// Redacted replaces the sensitive values of the files of a support bundle.
+const Redacted = "<redacted>"
+
+var (
+	// sensitiveKey matches the names of the flags, the variables and the
+	// fields whose values are secrets.
+	sensitiveKey = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|credential|private[_-]?key|api[_-]?key)`)
+
+	// sensitiveAssignment matches the secrets assigned in text, e.g.
+	// "--db_app_password=xyz", "password: xyz" or "secret_key=xyz".
+	sensitiveAssignment = regexp.MustCompile(`(?i)((?:passw(?:or)?d|secret|token|credentials?|private[_-]?key|api[_-]?key)[\w-]*["']?\s*[=:]\s*["']?)[^\s"'&,;<]+`)
+)
+
+// SanitizeText redacts the values assigned to the sensitive names of a text,
+// like a log or a status page.
+func SanitizeText(data []byte) []byte {
+	return sensitiveAssignment.ReplaceAll(data, []byte("${1}"+Redacted))
+}
+
+// SanitizeJSON redacts the values of the sensitive keys of a JSON document,
+// like /debug/vars, and the secrets assigned in its strings, like the flags of
+// the command line. A document that is not valid JSON is sanitized as text.
+func SanitizeJSON(data []byte) []byte {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return SanitizeText(data)
+	}
+	sanitized, err := json.MarshalIndent(sanitizeValue(doc), "", "  ")
+	if err != nil {
+		return SanitizeText(data)
+	}
+	return sanitized
+}
+
+func sanitizeValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, v := range value {
+			if _, isString := v.(string); isString && sensitiveKey.MatchString(key) {
+				value[key] = Redacted
+				continue
+			}
+			value[key] = sanitizeValue(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = sanitizeValue(v)
+		}
+		return value
+	case string:
+		return string(SanitizeText([]byte(value)))
+	default:
+		return value
+	}
+}
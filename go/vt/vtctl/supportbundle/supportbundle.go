@@ -0,0 +1,401 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package supportbundle collects the state of a Vitess cluster into a single
+archive to share with support or with the maintainers.
+
+The archive is a gzipped tarball that holds:
+
+	bundle.json                        when and how the bundle was collected, and the errors met
+	versions.json                      the build versions of the tablets and the vtgates
+	topo/cells/<cell>.json             the cells
+	topo/cells_aliases.json            the cells aliases
+	topo/routing_rules.json            the routing rules
+	topo/tablets.json                  the tablets
+	topo/keyspaces/<keyspace>/*.json   the keyspace, its shards, its vschema and its SrvKeyspaces
+	workflows/<keyspace>.json          the VReplication workflows of the keyspace
+	tablets/<alias>/*                  the /debug/vars, /debug/status and recent logs of a tablet
+	vtgates/<address>/*                the same for a vtgate
+
+The values of the flags, the variables and the fields that look like secrets
+are redacted, see SanitizeJSON and SanitizeText. A part of the cluster that
+cannot be collected is recorded in the errors of bundle.json, and does not fail
+the collection.
+*/
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/netutil"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// Options configures the collection of a support bundle.
+type Options struct {
+	// Keyspaces restricts the keyspaces, and the tablets, of the bundle. All
+	// the keyspaces are collected if empty.
+	Keyspaces []string
+	// VtgateAddresses are the host:port of the HTTP servers of the vtgates
+	// to collect, which are not recorded in the topo.
+	VtgateAddresses []string
+	// LogLines is the number of recent log lines collected from each
+	// tablet and vtgate. Zero skips the logs.
+	LogLines int
+	// Concurrency is the maximum number of tablets and vtgates whose pages
+	// are fetched at the same time.
+	Concurrency int
+	// HTTPTimeout bounds each fetch of a page of a tablet or a vtgate.
+	HTTPTimeout time.Duration
+	// ClientVersion is the version of the program that collects the bundle,
+	// recorded in bundle.json.
+	ClientVersion string
+}
+
+// Summary is the content of bundle.json.
+type Summary struct {
+	CollectedAt     time.Time `json:"collected_at"`
+	ClientVersion   string    `json:"client_version"`
+	Keyspaces       []string  `json:"keyspaces"`
+	Tablets         int       `json:"tablets"`
+	VtgateAddresses []string  `json:"vtgate_addresses"`
+	Errors          []string  `json:"errors"`
+}
+
+// Version is the build version of a component, from its /debug/vars.
+type Version struct {
+	BuildGitRev    string `json:"BuildGitRev"`
+	BuildGitBranch string `json:"BuildGitBranch"`
+	BuildTimestamp int64  `json:"BuildTimestamp"`
+	BuildHost      string `json:"BuildHost"`
+	GoVersion      string `json:"GoVersion"`
+}
+
+// Collect collects the support bundle of the cluster of a vtctld into w, and
+// returns its summary.
+func Collect(ctx context.Context, client vtctldclient.VtctldClient, w io.Writer, opts Options) (*Summary, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	gz := gzip.NewWriter(w)
+	c := &collector{
+		client:     client,
+		httpClient: &http.Client{Timeout: opts.HTTPTimeout},
+		opts:       opts,
+		tw:         tar.NewWriter(gz),
+		now:        time.Now().UTC(),
+		versions:   map[string]*Version{},
+	}
+
+	if err := c.collect(ctx); err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		CollectedAt:     c.now,
+		ClientVersion:   opts.ClientVersion,
+		Keyspaces:       c.keyspaces,
+		Tablets:         c.tablets,
+		VtgateAddresses: opts.VtgateAddresses,
+		Errors:          c.errors,
+	}
+	if err := c.writeJSON("versions.json", c.versions); err != nil {
+		return nil, err
+	}
+	if err := c.writeJSON("bundle.json", summary); err != nil {
+		return nil, err
+	}
+	if err := c.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+type collector struct {
+	client     vtctldclient.VtctldClient
+	httpClient *http.Client
+	opts       Options
+	now        time.Time
+
+	// mu protects the archive and the fields below it, which are written
+	// by the goroutines that fetch the pages of the tablets and the vtgates.
+	mu        sync.Mutex
+	tw        *tar.Writer
+	errors    []string
+	keyspaces []string
+	tablets   int
+	versions  map[string]*Version
+}
+
+func (c *collector) collect(ctx context.Context) error {
+	keyspaces, err := c.collectTopo(ctx)
+	if err != nil {
+		return err
+	}
+	c.keyspaces = keyspaces
+
+	for _, keyspace := range keyspaces {
+		if err := c.collectKeyspace(ctx, keyspace); err != nil {
+			return err
+		}
+	}
+
+	tablets, err := c.collectTablets(ctx, keyspaces)
+	if err != nil {
+		return err
+	}
+	c.tablets = len(tablets)
+
+	type component struct {
+		dir  string
+		addr string
+	}
+	var components []component
+	for _, tablet := range tablets {
+		components = append(components, component{
+			dir:  "tablets/" + topoproto.TabletAliasString(tablet.Alias),
+			addr: netutil.JoinHostPort(tablet.Hostname, tablet.PortMap["vt"]),
+		})
+	}
+	for _, addr := range c.opts.VtgateAddresses {
+		components = append(components, component{dir: "vtgates/" + addr, addr: addr})
+	}
+
+	sem := make(chan struct{}, c.opts.Concurrency)
+	var wg sync.WaitGroup
+	errs := make(chan error, len(components))
+	for _, comp := range components {
+		wg.Add(1)
+		go func(comp component) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs <- c.collectDebugPages(ctx, comp.dir, comp.addr)
+		}(comp)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectTopo collects the cells and the cluster-wide rules, and returns the
+// keyspaces of the bundle.
+func (c *collector) collectTopo(ctx context.Context) ([]string, error) {
+	cells, err := c.client.GetCellInfoNames(ctx, &vtctldatapb.GetCellInfoNamesRequest{})
+	if err != nil {
+		c.recordError("cannot list the cells: %v", err)
+	} else {
+		for _, cell := range cells.Names {
+			resp, err := c.client.GetCellInfo(ctx, &vtctldatapb.GetCellInfoRequest{Cell: cell})
+			if err := c.writeProto(fmt.Sprintf("topo/cells/%s.json", cell), resp, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	aliases, err := c.client.GetCellsAliases(ctx, &vtctldatapb.GetCellsAliasesRequest{})
+	if err := c.writeProto("topo/cells_aliases.json", aliases, err); err != nil {
+		return nil, err
+	}
+	rules, err := c.client.GetRoutingRules(ctx, &vtctldatapb.GetRoutingRulesRequest{})
+	if err := c.writeProto("topo/routing_rules.json", rules, err); err != nil {
+		return nil, err
+	}
+
+	if len(c.opts.Keyspaces) > 0 {
+		keyspaces := append([]string(nil), c.opts.Keyspaces...)
+		sort.Strings(keyspaces)
+		return keyspaces, nil
+	}
+	resp, err := c.client.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
+	if err != nil {
+		// Without the keyspaces, the bundle would only hold the cells.
+		return nil, fmt.Errorf("cannot list the keyspaces: %w", err)
+	}
+	keyspaces := make([]string, 0, len(resp.Keyspaces))
+	for _, keyspace := range resp.Keyspaces {
+		keyspaces = append(keyspaces, keyspace.Name)
+	}
+	sort.Strings(keyspaces)
+	return keyspaces, nil
+}
+
+func (c *collector) collectKeyspace(ctx context.Context, keyspace string) error {
+	dir := "topo/keyspaces/" + keyspace
+
+	ks, err := c.client.GetKeyspace(ctx, &vtctldatapb.GetKeyspaceRequest{Keyspace: keyspace})
+	if err := c.writeProto(dir+"/keyspace.json", ks, err); err != nil {
+		return err
+	}
+	shards, err := c.client.FindAllShardsInKeyspace(ctx, &vtctldatapb.FindAllShardsInKeyspaceRequest{Keyspace: keyspace})
+	if err := c.writeProto(dir+"/shards.json", shards, err); err != nil {
+		return err
+	}
+	vschema, err := c.client.GetVSchema(ctx, &vtctldatapb.GetVSchemaRequest{Keyspace: keyspace})
+	if err := c.writeProto(dir+"/vschema.json", vschema, err); err != nil {
+		return err
+	}
+	srvKeyspaces, err := c.client.GetSrvKeyspaces(ctx, &vtctldatapb.GetSrvKeyspacesRequest{Keyspace: keyspace})
+	if err := c.writeProto(dir+"/srv_keyspaces.json", srvKeyspaces, err); err != nil {
+		return err
+	}
+	workflows, err := c.client.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+	return c.writeProto(fmt.Sprintf("workflows/%s.json", keyspace), workflows, err)
+}
+
+func (c *collector) collectTablets(ctx context.Context, keyspaces []string) ([]*topodatapb.Tablet, error) {
+	var tablets []*topodatapb.Tablet
+	for _, keyspace := range keyspaces {
+		resp, err := c.client.GetTablets(ctx, &vtctldatapb.GetTabletsRequest{Keyspace: keyspace})
+		if err != nil {
+			c.recordError("cannot list the tablets of keyspace %s: %v", keyspace, err)
+			continue
+		}
+		tablets = append(tablets, resp.Tablets...)
+	}
+	sort.Slice(tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(tablets[i].Alias) < topoproto.TabletAliasString(tablets[j].Alias)
+	})
+	return tablets, c.writeProto("topo/tablets.json", &vtctldatapb.GetTabletsResponse{Tablets: tablets}, nil)
+}
+
+// collectDebugPages collects the /debug/vars, /debug/status and recent logs
+// of a tablet or a vtgate, whose HTTP server listens on addr.
+func (c *collector) collectDebugPages(ctx context.Context, dir string, addr string) error {
+	if vars, err := c.fetch(ctx, addr, "/debug/vars"); err != nil {
+		c.recordError("cannot fetch the /debug/vars of %s: %v", dir, err)
+	} else {
+		version := &Version{}
+		if err := json.Unmarshal(vars, version); err != nil {
+			c.recordError("cannot read the version of %s: %v", dir, err)
+		} else {
+			c.mu.Lock()
+			c.versions[dir] = version
+			c.mu.Unlock()
+		}
+		if err := c.writeFile(dir+"/vars.json", SanitizeJSON(vars)); err != nil {
+			return err
+		}
+	}
+
+	if status, err := c.fetch(ctx, addr, "/debug/status"); err != nil {
+		c.recordError("cannot fetch the /debug/status of %s: %v", dir, err)
+	} else if err := c.writeFile(dir+"/status.html", SanitizeText(status)); err != nil {
+		return err
+	}
+
+	if c.opts.LogLines == 0 {
+		return nil
+	}
+	path := "/debug/logs?" + url.Values{"lines": []string{fmt.Sprint(c.opts.LogLines)}}.Encode()
+	if logs, err := c.fetch(ctx, addr, path); err != nil {
+		c.recordError("cannot fetch the logs of %s: %v", dir, err)
+	} else if err := c.writeFile(dir+"/INFO.log", SanitizeText(logs)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *collector) fetch(ctx context.Context, addr string, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// writeProto writes the JSON of the response of a vtctld RPC, or records the
+// error of the RPC.
+func (c *collector) writeProto(name string, resp proto.Message, rpcErr error) error {
+	if rpcErr != nil {
+		c.recordError("cannot collect %s: %v", name, rpcErr)
+		return nil
+	}
+	data, err := json2.MarshalIndentPB(resp, "  ")
+	if err != nil {
+		return err
+	}
+	return c.writeFile(name, SanitizeJSON(data))
+}
+
+func (c *collector) writeJSON(name string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return c.writeFile(name, data)
+}
+
+func (c *collector) writeFile(name string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: c.now,
+	}); err != nil {
+		return err
+	}
+	_, err := c.tw.Write(data)
+	return err
+}
+
+func (c *collector) recordError(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors = append(c.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "--db_app_password=s3cr3t --db_app_user=vt_app",
+			want: "--db_app_password=<redacted> --db_app_user=vt_app",
+		},
+		{
+			in:   `{"password": "s3cr3t", "user": "root"}`,
+			want: `{"password": "<redacted>", "user": "root"}`,
+		},
+		{
+			in:   "connecting with token: abc.def and keyspace: commerce",
+			want: "connecting with token: <redacted> and keyspace: commerce",
+		},
+		{
+			in:   "no secret in this line",
+			want: "no secret in this line",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(SanitizeText([]byte(tt.in))), tt.in)
+	}
+}
+
+func TestSanitizeJSON(t *testing.T) {
+	in := `{
+		"cmdline": ["/vt/bin/vttablet", "--db_dba_password=s3cr3t", "--keyspace=commerce"],
+		"AwsSecretAccessKey": "s3cr3t",
+		"TokenRefreshes": 3,
+		"Keyspace": "commerce",
+		"Nested": {"api_key": "s3cr3t", "shard": "-80"}
+	}`
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(SanitizeJSON([]byte(in)), &got))
+	assert.Equal(t, []any{"/vt/bin/vttablet", "--db_dba_password=<redacted>", "--keyspace=commerce"}, got["cmdline"])
+	assert.Equal(t, Redacted, got["AwsSecretAccessKey"])
+	assert.Equal(t, float64(3), got["TokenRefreshes"])
+	assert.Equal(t, "commerce", got["Keyspace"])
+	assert.Equal(t, map[string]any{"api_key": Redacted, "shard": "-80"}, got["Nested"])
+
+	assert.Equal(t, "password=<redacted>", string(SanitizeJSON([]byte("password=s3cr3t"))))
+}
+
+func TestCollect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/vars", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"BuildGitRev": "abc123", "GoVersion": "go1.18", "cmdline": ["vttablet", "--db_app_password=s3cr3t"]}`)
+	})
+	mux.HandleFunc("/debug/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>healthy</html>")
+	})
+	mux.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.FormValue("lines"))
+		fmt.Fprint(w, "I1017 connected\nI1017 login with password=s3cr3t\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "commerce",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+		Hostname: host,
+		PortMap:  map[string]int32{"vt": int32(port)},
+	}, &testutil.AddTabletOptions{AlsoSetShardPrimary: true})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &testutil.TabletManagerClient{}, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return grpcvtctldserver.NewVtctldServer(ts)
+	})
+
+	var buf bytes.Buffer
+	summary, err := Collect(ctx, localvtctldclient.New(vtctld), &buf, Options{
+		VtgateAddresses: []string{"127.0.0.1:1"},
+		LogLines:        2,
+		Concurrency:     2,
+		HTTPTimeout:     time.Second,
+		ClientVersion:   "test",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commerce"}, summary.Keyspaces)
+	assert.Equal(t, 1, summary.Tablets)
+	// The vtgate is unreachable, which is recorded without failing the
+	// collection.
+	assert.Contains(t, fmt.Sprint(summary.Errors), "vtgates/127.0.0.1:1")
+
+	files := readBundle(t, &buf)
+	for _, name := range []string{
+		"bundle.json",
+		"versions.json",
+		"topo/cells/zone1.json",
+		"topo/cells_aliases.json",
+		"topo/routing_rules.json",
+		"topo/tablets.json",
+		"topo/keyspaces/commerce/keyspace.json",
+		"topo/keyspaces/commerce/shards.json",
+		"topo/keyspaces/commerce/srv_keyspaces.json",
+		"tablets/zone1-0000000100/vars.json",
+		"tablets/zone1-0000000100/status.html",
+		"tablets/zone1-0000000100/INFO.log",
+	} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "vtgates/127.0.0.1:1/vars.json")
+	for name, data := range files {
+		assert.NotContains(t, data, "s3cr3t", name)
+	}
+	assert.Equal(t, "I1017 connected\nI1017 login with password=<redacted>\n", files["tablets/zone1-0000000100/INFO.log"])
+
+	var versions map[string]*Version
+	require.NoError(t, json.Unmarshal([]byte(files["versions.json"]), &versions))
+	assert.Equal(t, map[string]*Version{
+		"tablets/zone1-0000000100": {BuildGitRev: "abc123", GoVersion: "go1.18"},
+	}, versions)
+}
+
+func readBundle(t *testing.T, r io.Reader) map[string]string {
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+}
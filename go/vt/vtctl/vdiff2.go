@@ -60,6 +60,8 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.Fl
 	resumable := subFlags.Bool("resumable", false, "Should this vdiff retry in case of recoverable errors, not yet implemented")
 	checksum := subFlags.Bool("checksum", false, "Use row-level checksums to compare, not yet implemented")
 	samplePct := subFlags.Int64("sample_pct", 100, "How many rows to sample, not yet implemented")
+	autoRetry := subFlags.Bool("auto_retry", true, "Should this vdiff automatically retry and continue in case of transient errors, e.g. when a source or target tablet restarts or is reparented")
+	maxRetries := subFlags.Int64("max_retries", 3, "Maximum number of automatic retries of the vdiff with --auto_retry")
 
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -93,6 +95,9 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.Fl
 	if *maxRows <= 0 {
 		return fmt.Errorf("invalid --limit value (%d), maximum number of rows to compare needs to be greater than 0", *maxRows)
 	}
+	if *maxRetries < 0 {
+		return fmt.Errorf("invalid --max_retries value (%d), it cannot be negative", *maxRetries)
+	}
 
 	options := &tabletmanagerdatapb.VDiffOptions{
 		PickerOptions: &tabletmanagerdatapb.VDiffPickerOptions{
@@ -108,6 +113,8 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.Fl
 			SamplePct:             *samplePct,
 			TimeoutSeconds:        int64(timeout.Seconds()),
 			MaxExtraRowsToCompare: *maxExtraRowsToCompare,
+			AutoRetry:             *autoRetry,
+			MaxRetries:            *maxRetries,
 		},
 		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{
 			OnlyPKS:    *onlyPks,
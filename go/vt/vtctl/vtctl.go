@@ -115,6 +115,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/wrangler"
@@ -427,6 +428,18 @@ var commands = []commandGroup{
 				params: "",
 				help:   "Outputs a sorted list of all keyspaces.",
 			},
+			{
+				name:   "GetKeyspaceSettings",
+				method: commandGetKeyspaceSettings,
+				params: "<keyspace>",
+				help:   "Outputs a JSON structure that contains the settings vtgates apply to the keyspace.",
+			},
+			{
+				name:   "SetKeyspaceSettings",
+				method: commandSetKeyspaceSettings,
+				params: "[--no_scatter=<true|false>] [--default_workload=<OLTP|OLAP|DBA>] [--shed_percent=<percent>] [--shed_priority=<priority>] [--table_gc_lifecycle=<states>] [--table_gc_hold=<duration>] [--availability_objective=<fraction>] [--latency_objective=<fraction>] [--latency_threshold=<duration>] <keyspace>",
+				help:   "Changes the settings vtgates and tablets apply to the keyspace. Only the settings passed as flags are changed. Vtgates pick up the new settings within --keyspace_settings_refresh_interval, and tablets before they next collect dropped tables.",
+			},
 			{
 				name:       "SetKeyspaceShardingInfo",
 				method:     commandSetKeyspaceShardingInfo,
@@ -456,13 +469,13 @@ var commands = []commandGroup{
 			{
 				name:   "Reshard",
 				method: commandReshard,
-				params: "[--source_shards=<source_shards>] [--target_shards=<target_shards>] [--cells=<cells>] [--tablet_types=<source_tablet_types>]  [--skip_schema_copy] <action> 'action must be one of the following: Create, Complete, Cancel, SwitchTraffic, ReverseTrafffic, Show, or Progress' <keyspace.workflow>",
+				params: "[--source_shards=<source_shards>] [--target_shards=<target_shards>] [--cells=<cells>] [--tablet_types=<source_tablet_types>]  [--skip_schema_copy] [--start_at=<time>] [--start_at_time_zone=<time_zone>] <action> 'action must be one of the following: Create, Complete, Cancel, SwitchTraffic, ReverseTrafffic, Show, or Progress' <keyspace.workflow>",
 				help:   "Start a Resharding process. Example: Reshard --cells='zone1,alias1' --tablet_types='PRIMARY,REPLICA,RDONLY'  ks.workflow001 '0' '-80,80-'",
 			},
 			{
 				name:   "MoveTables",
 				method: commandMoveTables,
-				params: "[--source=<sourceKs>] [--tables=<tableSpecs>] [--cells=<cells>] [--tablet_types=<source_tablet_types>] [--all] [--exclude=<tables>] [--auto_start] [--stop_after_copy] <action> 'action must be one of the following: Create, Complete, Cancel, SwitchTraffic, ReverseTrafffic, Show, or Progress' <targetKs.workflow>",
+				params: "[--source=<sourceKs>] [--tables=<tableSpecs>] [--cells=<cells>] [--tablet_types=<source_tablet_types>] [--all] [--exclude=<tables>] [--auto_start] [--stop_after_copy] [--start_at=<time>] [--start_at_time_zone=<time_zone>] <action> 'action must be one of the following: Create, Complete, Cancel, SwitchTraffic, ReverseTrafffic, Show, or Progress' <targetKs.workflow>",
 				help:   `Move table(s) to another keyspace, table_specs is a list of tables or the tables section of the vschema for the target keyspace. Example: '{"t1":{"column_vindexes": [{"column": "id1", "name": "hash"}]}, "t2":{"column_vindexes": [{"column": "id2", "name": "hash"}]}}'.  In the case of an unsharded target keyspace the vschema for each table may be empty. Example: '{"t1":{}, "t2":{}}'.`,
 			},
 			{
@@ -492,8 +505,14 @@ var commands = []commandGroup{
 			{
 				name:   "Materialize",
 				method: commandMaterialize,
-				params: `[--cells=<cells>] [--tablet_types=<source_tablet_types>] <json_spec>, example : '{"workflow": "aaa", "source_keyspace": "source", "target_keyspace": "target", "table_settings": [{"target_table": "customer", "source_expression": "select * from customer", "create_ddl": "copy"}]}'`,
-				help:   "Performs materialization based on the json spec. Is used directly to form VReplication rules, with an optional step to copy table structure/DDL.",
+				params: `[--cells=<cells>] [--tablet_types=<source_tablet_types>] [--external_cluster=<cluster_name>] <json_spec>, example : '{"workflow": "aaa", "source_keyspace": "source", "target_keyspace": "target", "table_settings": [{"target_table": "customer", "source_expression": "select * from customer", "create_ddl": "copy"}]}'`,
+				help:   "Performs materialization based on the json spec. Is used directly to form VReplication rules, with an optional step to copy table structure/DDL. The source keyspace can be in another Vitess cluster mounted with Mount, given with --external_cluster or the external_cluster of the json spec.",
+			},
+			{
+				name:   "Follower",
+				method: commandFollower,
+				params: "[--source=<source_keyspace>] [--external_cluster=<cluster_name>] [--cells=<cells>] [--tablet_types=<source_tablet_types>] [--auto_start] [--max_lag=<duration>] <action> 'action must be one of the following: Create, Status, Promote' <keyspace>",
+				help:   "Manages a read-only follower keyspace, which continuously replicates all the tables of its source keyspace, including the ones created later on, possibly from another Vitess cluster mounted with Mount. Create copies the schema and creates the follower workflow, Status shows its streams and their lag, and fails if they are not running or lag more than --max_lag, and Promote stops the replication and makes the keyspace writable.",
 			},
 			{
 				name:       "SplitClone",
@@ -574,6 +593,72 @@ var commands = []commandGroup{
 				params: "[--topo_type=etcd2|consul|zookeeper] [--topo_server=topo_url] [--topo_root=root_topo_node> [--unmount] [--list] [--show]  [<cluster_name>]",
 				help:   "Add/Remove/Display/List external cluster(s) to this vitess cluster",
 			},
+			{
+				name:   "CreateArchival",
+				method: commandCreateArchival,
+				params: "--table=<table> --where=<predicate> [--target_keyspace=<keyspace>] [--target_table=<table>] [--target_dir=<dir>] [--batch_size=1000] [--max_tps=0] [--paused] <keyspace.archival>",
+				help:   "Creates an archival, which vtctld runs every --archival_check_interval to move the rows of the table that match the predicate to the target table, or to CSV files in the target dir of the backup storage. The rows are copied in batches, verified, and then deleted. Example: CreateArchival --table=orders --where='created_at < now() - interval 90 day' --target_keyspace=archive --target_table=orders commerce.old_orders",
+			},
+			{
+				name:   "GetArchivals",
+				method: commandGetArchivals,
+				params: "<keyspace>",
+				help:   "Outputs a JSON structure that contains the archivals of the keyspace, with their progress.",
+			},
+			{
+				name:   "PauseArchival",
+				method: commandPauseArchival,
+				params: "<keyspace.archival>",
+				help:   "Pauses an archival. vtctld stops it after the batch it is archiving.",
+			},
+			{
+				name:   "ResumeArchival",
+				method: commandResumeArchival,
+				params: "<keyspace.archival>",
+				help:   "Resumes a paused archival at the next check of vtctld.",
+			},
+			{
+				name:   "DeleteArchival",
+				method: commandDeleteArchival,
+				params: "<keyspace.archival>",
+				help:   "Deletes an archival. The rows it archived are kept.",
+			},
+			{
+				name:   "SetPartitionPolicy",
+				method: commandSetPartitionPolicy,
+				params: "--interval=day|week|month --precreate=<partitions> [--retention=<intervals>] <keyspace.table>",
+				help:   "Sets the partition policy of a RANGE partitioned table. Every --partition_management_interval, the primary tablets submit the Online DDL migrations that add the partitions of the next --precreate intervals, and drop the partitions whose rows are older than --retention intervals. Example: SetPartitionPolicy --interval=day --precreate=7 --retention=30 commerce.events",
+			},
+			{
+				name:   "GetPartitionPolicies",
+				method: commandGetPartitionPolicies,
+				params: "<keyspace>",
+				help:   "Outputs a JSON structure that contains the partition policies of the tables of the keyspace.",
+			},
+			{
+				name:   "DeletePartitionPolicy",
+				method: commandDeletePartitionPolicy,
+				params: "<keyspace.table>",
+				help:   "Deletes the partition policy of a table. The partitions of the table are kept.",
+			},
+			{
+				name:   "ExecDBA",
+				method: commandExecDBA,
+				params: "--sql=<sql> [--name=<name>] [--tablet_aliases=<aliases>] [--tablet_types=<types>] [--tags=<key:value,...>] [--concurrency=1] [--dry_run] [--require_approval] <keyspace>",
+				help:   "Runs a SQL script as the DBA user on the tablets of the keyspace that match all the selectors, and outputs the result of each statement on each tablet. Each tablet runs the statements in order, and stops at the first one that fails. The script is submitted by the caller authenticated by the gRPC auth plugin of vtctld, see --grpc_auth_mode. With --require_approval, the script only runs once another caller approves it with ApproveDBAScript. The scripts and their audit log are kept in the topo, see GetDBAScripts.",
+			},
+			{
+				name:   "ApproveDBAScript",
+				method: commandApproveDBAScript,
+				params: "<keyspace.script>",
+				help:   "Approves a DBA script submitted with --require_approval by another caller, and runs it. The approver is the caller authenticated by the gRPC auth plugin of vtctld.",
+			},
+			{
+				name:   "GetDBAScripts",
+				method: commandGetDBAScripts,
+				params: "<keyspace>",
+				help:   "Outputs a JSON structure that contains the DBA scripts of the keyspace, with their audit log.",
+			},
 		},
 	},
 	{
@@ -602,6 +687,18 @@ var commands = []commandGroup{
 				params: "[--num_shards 2]",
 				help:   "Generates shard ranges assuming a keyspace with N shards.",
 			},
+			{
+				name:   "GetMySQLUserLimits",
+				method: commandGetMySQLUserLimits,
+				params: "[<user>]",
+				help:   "Outputs a JSON structure that contains the limits vtgates apply to the connections and the queries of the given MySQL user, or of all the MySQL users that have some.",
+			},
+			{
+				name:   "SetMySQLUserLimits",
+				method: commandSetMySQLUserLimits,
+				params: "[--max_connections=<count>] [--max_concurrent_queries=<count>] <user>",
+				help:   "Changes the limits vtgates apply to the connections and the queries of the MySQL user. Only the limits passed as flags are changed, and 0 removes a limit. Vtgates pick up the new limits within --mysql_user_limits_refresh_interval. The limits take precedence over the limits of the auth server of the vtgates.",
+			},
 			{
 				name:   "Panic",
 				method: commandPanic,
@@ -737,9 +834,21 @@ var commands = []commandGroup{
 			{
 				name:   "ApplyVSchema",
 				method: commandApplyVSchema,
-				params: "{--vschema=<vschema> || --vschema_file=<vschema file> || --sql=<sql> || --sql_file=<sql file>} [--cells=c1,c2,...] [--skip_rebuild] [--dry-run] <keyspace>",
+				params: "{--vschema=<vschema> || --vschema_file=<vschema file> || --sql=<sql> || --sql_file=<sql file>} [--cells=c1,c2,...] [--skip_rebuild] [--dry-run] [--validate_against_schema] <keyspace>",
 				help:   "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 			},
+			{
+				name:   "GetVSchemaHistory",
+				method: commandGetVSchemaHistory,
+				params: "[--version=<version>] <keyspace>",
+				help:   "Lists the saved versions of the VTGate routing schema of the provided keyspace, or displays one version if --version is set.",
+			},
+			{
+				name:   "RollbackVSchema",
+				method: commandRollbackVSchema,
+				params: "--to_version=<version> [--cells=c1,c2,...] [--skip_rebuild] <keyspace>",
+				help:   "Restores a saved version of the VTGate routing schema of the provided keyspace. Shows the result after application.",
+			},
 			{
 				name:   "GetRoutingRules",
 				method: commandGetRoutingRules,
@@ -2089,6 +2198,361 @@ func commandGetKeyspace(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 	return printJSON(wr.Logger(), keyspaceInfo.Keyspace.Keyspace)
 }
 
+func commandGetKeyspaceSettings(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the GetKeyspaceSettings command")
+	}
+
+	settings, err := wr.TopoServer().GetKeyspaceSettings(ctx, subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), settings)
+}
+
+func commandSetKeyspaceSettings(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	noScatter := subFlags.Bool("no_scatter", false, "If true, vtgates reject queries that scatter across the shards of the keyspace, unless they use the ALLOW_SCATTER directive.")
+	defaultWorkload := subFlags.String("default_workload", "", "The workload (OLTP, OLAP or DBA) of new MySQL protocol sessions that connect to the keyspace. Empty uses the vtgate default.")
+	shedPercent := subFlags.Int("shed_percent", 0, "The percentage of the queries of --shed_priority or a lower priority that vtgates reject, to protect the keyspace while it is overloaded. 0 stops shedding queries.")
+	shedPriority := subFlags.Int("shed_priority", 0, "The highest priority, between 1 and 100, of the queries that are shed. 0 sheds only the queries of the lowest priority, 100.")
+	tableGCLifecycle := subFlags.String("table_gc_lifecycle", "", "The comma separated states (hold, purge, evac, drop) that the tables dropped in the keyspace go through. Empty uses the --table_gc_lifecycle of the tablets.")
+	tableGCHold := subFlags.String("table_gc_hold", "", "How long the tables dropped by Online DDL are held, and can be restored, before they are purged, e.g. 72h. Empty uses the --retain_online_ddl_tables of the tablets.")
+	availabilityObjective := subFlags.Float64("availability_objective", 0, "The fraction of the queries routed to the keyspace that must not fail with a server error, e.g. 0.999. 0 uses the --slo_availability_objective of the vtgates.")
+	latencyObjective := subFlags.Float64("latency_objective", 0, "The fraction of the queries routed to the keyspace that must complete within --latency_threshold, e.g. 0.99. 0 uses the --slo_latency_objective of the vtgates.")
+	latencyThreshold := subFlags.String("latency_threshold", "", "The latency of the queries that --latency_objective counts as fast enough, e.g. 100ms. Empty uses the --slo_latency_threshold of the vtgates.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the SetKeyspaceSettings command")
+	}
+	keyspace := subFlags.Arg(0)
+
+	if _, err := wr.TopoServer().GetKeyspace(ctx, keyspace); err != nil {
+		return err
+	}
+	settings, err := wr.TopoServer().GetKeyspaceSettings(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	subFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "no_scatter":
+			settings.NoScatter = *noScatter
+		case "default_workload":
+			settings.DefaultWorkload = strings.ToUpper(*defaultWorkload)
+		case "shed_percent":
+			settings.ShedPercent = *shedPercent
+		case "shed_priority":
+			settings.ShedPriority = *shedPriority
+		case "table_gc_lifecycle":
+			settings.TableGCLifecycle = strings.ToLower(*tableGCLifecycle)
+		case "table_gc_hold":
+			settings.TableGCHold = *tableGCHold
+		case "availability_objective":
+			settings.AvailabilityObjective = *availabilityObjective
+		case "latency_objective":
+			settings.LatencyObjective = *latencyObjective
+		case "latency_threshold":
+			settings.LatencyThreshold = *latencyThreshold
+		}
+	})
+
+	if err := wr.TopoServer().SaveKeyspaceSettings(ctx, keyspace, settings); err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), settings)
+}
+
+func commandCreateArchival(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	table := subFlags.String("table", "", "The table whose rows are archived.")
+	where := subFlags.String("where", "", "The predicate of the rows to archive, as a SQL expression evaluated by MySQL, e.g. 'created_at < now() - interval 90 day'.")
+	targetKeyspace := subFlags.String("target_keyspace", "", "The keyspace of the target table. Defaults to the keyspace of the archival.")
+	targetTable := subFlags.String("target_table", "", "The table the rows are copied to. It must have the columns and the primary key of the table.")
+	targetDir := subFlags.String("target_dir", "", "The directory of the backup storage the rows are written to as CSV files, which ImportData can load, instead of a target table.")
+	batchSize := subFlags.Int("batch_size", 1000, "The number of rows archived at a time from each shard.")
+	maxTPS := subFlags.Int64("max_tps", 0, "The maximum number of batches archived per second. 0 does not throttle the batches.")
+	paused := subFlags.Bool("paused", false, "Creates the archival paused, so that it only runs once it is resumed.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.archival> argument is required for the CreateArchival command")
+	}
+	keyspace, name, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if _, err := wr.TopoServer().GetKeyspace(ctx, keyspace); err != nil {
+		return err
+	}
+	if *targetKeyspace != "" {
+		if _, err := wr.TopoServer().GetKeyspace(ctx, *targetKeyspace); err != nil {
+			return err
+		}
+	}
+	archival := &topo.Archival{
+		Name:           name,
+		Table:          *table,
+		Where:          *where,
+		TargetKeyspace: *targetKeyspace,
+		TargetTable:    *targetTable,
+		TargetDir:      *targetDir,
+		BatchSize:      *batchSize,
+		MaxTPS:         *maxTPS,
+		Paused:         *paused,
+	}
+	if err := wr.TopoServer().CreateArchival(ctx, keyspace, archival); err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), archival)
+}
+
+func commandGetArchivals(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the GetArchivals command")
+	}
+
+	archivals, err := wr.TopoServer().GetArchivals(ctx, subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if archivals == nil {
+		archivals = []*topo.Archival{}
+	}
+	return printJSON(wr.Logger(), archivals)
+}
+
+func commandPauseArchival(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	return setArchivalPaused(ctx, wr, subFlags, args, "PauseArchival", true)
+}
+
+func commandResumeArchival(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	return setArchivalPaused(ctx, wr, subFlags, args, "ResumeArchival", false)
+}
+
+func setArchivalPaused(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string, command string, paused bool) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.archival> argument is required for the %s command", command)
+	}
+	keyspace, name, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	archival, err := wr.TopoServer().UpdateArchivalFields(ctx, keyspace, name, func(a *topo.Archival) error {
+		a.Paused = paused
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), archival)
+}
+
+func commandDeleteArchival(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.archival> argument is required for the DeleteArchival command")
+	}
+	keyspace, name, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return wr.TopoServer().DeleteArchival(ctx, keyspace, name)
+}
+
+func commandSetPartitionPolicy(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	interval := subFlags.String("interval", "", "The range of time of each partition: day, week or month.")
+	precreate := subFlags.Int("precreate", 0, "The number of partitions kept ahead of the current interval.")
+	retention := subFlags.Int("retention", 0, "The number of past intervals whose partitions are kept. 0 drops no partition.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.table> argument is required for the SetPartitionPolicy command")
+	}
+	keyspace, table, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if _, err := wr.TopoServer().GetKeyspace(ctx, keyspace); err != nil {
+		return err
+	}
+	policy := &topo.PartitionPolicy{
+		Table:     table,
+		Interval:  *interval,
+		Precreate: *precreate,
+		Retention: *retention,
+	}
+	if err := wr.TopoServer().SavePartitionPolicy(ctx, keyspace, policy); err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), policy)
+}
+
+func commandGetPartitionPolicies(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the GetPartitionPolicies command")
+	}
+
+	policies, err := wr.TopoServer().GetPartitionPolicies(ctx, subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if policies == nil {
+		policies = []*topo.PartitionPolicy{}
+	}
+	return printJSON(wr.Logger(), policies)
+}
+
+func commandDeletePartitionPolicy(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.table> argument is required for the DeletePartitionPolicy command")
+	}
+	keyspace, table, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return wr.TopoServer().DeletePartitionPolicy(ctx, keyspace, table)
+}
+
+func commandExecDBA(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	sql := subFlags.String("sql", "", "The SQL script, as statements separated by semicolons.")
+	name := subFlags.String("name", "", "The name of the script, unique in the keyspace. Defaults to the submission time.")
+	concurrency := subFlags.Int("concurrency", 1, "The maximum number of tablets that run the script at the same time.")
+	dryRun := subFlags.Bool("dry_run", false, "Outputs the statements and the tablets that would run them, without running or recording the script.")
+	requireApproval := subFlags.Bool("require_approval", false, "Records the script as pending, so that it only runs once another user approves it with ApproveDBAScript.")
+	var tabletAliasStrs, tabletTypeStrs flagutil.StringListValue
+	subFlags.Var(&tabletAliasStrs, "tablet_aliases", "A comma-separated list of the aliases of the tablets that run the script.")
+	subFlags.Var(&tabletTypeStrs, "tablet_types", "A comma-separated list of the types of the tablets that run the script.")
+	var tags flagutil.StringMapValue
+	subFlags.Var(&tags, "tags", "A comma-separated list of key:value pairs of the tags of the tablets that run the script.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the ExecDBA command")
+	}
+
+	tabletAliases := make([]*topodatapb.TabletAlias, 0, len(tabletAliasStrs))
+	for _, alias := range tabletAliasStrs {
+		tabletAlias, err := topoproto.ParseTabletAlias(alias)
+		if err != nil {
+			return err
+		}
+		tabletAliases = append(tabletAliases, tabletAlias)
+	}
+	tabletTypes := make([]topodatapb.TabletType, 0, len(tabletTypeStrs))
+	for _, tabletTypeStr := range tabletTypeStrs {
+		tabletType, err := topoproto.ParseTabletType(tabletTypeStr)
+		if err != nil {
+			return err
+		}
+		tabletTypes = append(tabletTypes, tabletType)
+	}
+
+	resp, err := wr.VtctldServer().ExecDBA(ctx, &vtctldatapb.ExecDBARequest{
+		Keyspace:        subFlags.Arg(0),
+		Name:            *name,
+		Sql:             *sql,
+		TabletAliases:   tabletAliases,
+		TabletTypes:     tabletTypes,
+		Tags:            tags,
+		Concurrency:     int32(*concurrency),
+		DryRun:          *dryRun,
+		RequireApproval: *requireApproval,
+	})
+	if err != nil {
+		return err
+	}
+	if err := printJSON(wr.Logger(), resp); err != nil {
+		return err
+	}
+	return dbaScriptError(resp.Script, resp.Results)
+}
+
+func commandApproveDBAScript(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace.script> argument is required for the ApproveDBAScript command")
+	}
+	keyspace, name, err := splitKeyspaceWorkflow(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	resp, err := wr.VtctldServer().ApproveDBAScript(ctx, &vtctldatapb.ApproveDBAScriptRequest{
+		Keyspace: keyspace,
+		Name:     name,
+	})
+	if err != nil {
+		return err
+	}
+	if err := printJSON(wr.Logger(), resp); err != nil {
+		return err
+	}
+	return dbaScriptError(resp.Script, resp.Results)
+}
+
+func commandGetDBAScripts(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the GetDBAScripts command")
+	}
+
+	resp, err := wr.VtctldServer().GetDBAScripts(ctx, &vtctldatapb.GetDBAScriptsRequest{
+		Keyspace: subFlags.Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), resp)
+}
+
+// dbaScriptError returns an error if a DBA script failed on some of its
+// tablets.
+func dbaScriptError(script *vtctldatapb.DBAScript, results []*vtctldatapb.DBAScriptTabletResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("DBA script %s failed on %d of %d tablets", script.Name, failed, len(results))
+	}
+	return nil
+}
+
 func commandGetKeyspaces(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	resp, err := wr.VtctldServer().GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
 	if err != nil {
@@ -2266,7 +2730,7 @@ func commandMoveTables(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 	target := subFlags.Arg(1)
 	tableSpecs := subFlags.Arg(2)
 	return wr.MoveTables(ctx, *workflow, source, target, tableSpecs, *cells, *tabletTypes, *allTables,
-		*excludes, *autoStart, *stopAfterCopy, "", *dropForeignKeys, "")
+		*excludes, *autoStart, *stopAfterCopy, "", *dropForeignKeys, "", false)
 }
 
 // VReplicationWorkflowAction defines subcommands passed to vtctl for movetables or reshard
@@ -2316,6 +2780,8 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 	autoStart := subFlags.Bool("auto_start", true, "If false, streams will start in the Stopped state and will need to be explicitly started")
 	stopAfterCopy := subFlags.Bool("stop_after_copy", false, "Streams will be stopped once the copy phase is completed")
 	maxReplicationLagAllowed := subFlags.Duration("max_replication_lag_allowed", defaultMaxReplicationLagAllowed, "Allow traffic to be switched only if vreplication lag is below this (in seconds)")
+	startAt := subFlags.String("start_at", "", "MoveTables and Reshard only. Create the streams in the Stopped state, and have vtctld start them at this time, e.g. to copy the tables in an off-peak window. The time is either in RFC 3339 format, e.g. 2022-09-01T02:00:00-04:00, or of the form 'YYYY-MM-DD HH:MM[:SS]' in --start_at_time_zone. --start_at is only supported for Create.")
+	startAtTimeZone := subFlags.String("start_at_time_zone", "", "MoveTables and Reshard only. IANA time zone of the --start_at times without an offset, e.g. America/New_York. Defaults to UTC.")
 
 	// MoveTables and Migrate params
 	tables := subFlags.String("tables", "", "MoveTables only. A table spec or a list of tables. Either table_specs or --all needs to be specified.")
@@ -2329,6 +2795,7 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 
 	// MoveTables-only params
 	renameTables := subFlags.Bool("rename_tables", false, "MoveTables only. Rename tables instead of dropping them. --rename_tables is only supported for Complete.")
+	translateSourceSchema := subFlags.Bool("translate_source_schema", false, "MoveTables only. Translate the definitions of the source tables, such as those of an external database, into definitions that the target supports when creating the target tables, and report the changes: non-InnoDB engines become InnoDB, and the foreign keys and the options that depend on the source host, such as DATA DIRECTORY and TABLESPACE, are removed. --translate_source_schema is only supported for Create.")
 
 	// Reshard params
 	sourceShards := subFlags.String("source_shards", "", "Reshard only. Source shards")
@@ -2414,6 +2881,19 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 		}
 	}
 
+	if *startAt != "" && action != vReplicationWorkflowActionCreate {
+		return fmt.Errorf("--start_at is only supported for Create, not for %s", originalAction)
+	}
+	if *translateSourceSchema {
+		if action != vReplicationWorkflowActionCreate {
+			return fmt.Errorf("--translate_source_schema is only supported for Create, not for %s", originalAction)
+		}
+		if workflowType == wrangler.ReshardWorkflow {
+			return fmt.Errorf("--translate_source_schema is not supported for Reshard")
+		}
+	}
+
+	var scheduledStart time.Time
 	switch action {
 	case vReplicationWorkflowActionCreate:
 		switch workflowType {
@@ -2451,6 +2931,7 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 			vrwp.Timeout = *timeout
 			vrwp.ExternalCluster = externalClusterName
 			vrwp.SourceTimeZone = *sourceTimeZone
+			vrwp.TranslateSourceSchema = *translateSourceSchema
 		case wrangler.ReshardWorkflow:
 			if *sourceShards == "" || *targetShards == "" {
 				return fmt.Errorf("source and target shards are not specified")
@@ -2464,6 +2945,20 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 		}
 		vrwp.Cells = *cells
 		vrwp.TabletTypes = *tabletTypes
+		if *startAt != "" {
+			if workflowType == wrangler.MigrateWorkflow {
+				return fmt.Errorf("--start_at is only supported for MoveTables and Reshard")
+			}
+			scheduledStart, err = workflow.ParseStartAt(*startAt, *startAtTimeZone)
+			if err != nil {
+				return err
+			}
+			if !scheduledStart.After(time.Now()) {
+				return fmt.Errorf("--start_at %s is in the past", *startAt)
+			}
+			// The streams are started by vtctld at the scheduled time.
+			vrwp.AutoStart = false
+		}
 	case vReplicationWorkflowActionSwitchTraffic, vReplicationWorkflowActionReverseTraffic:
 		vrwp.Cells = *cells
 		if userPassedFlag(subFlags, "tablet_types") {
@@ -2555,6 +3050,13 @@ func commandVRWorkflow(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 		if err != nil {
 			return err
 		}
+		if !scheduledStart.IsZero() {
+			if err := wr.ScheduleWorkflow(ctx, target, workflowName, scheduledStart); err != nil {
+				return err
+			}
+			wr.Logger().Printf("Workflow has been created in Stopped state, and will be started by vtctld at %s\n", scheduledStart.UTC().Format(time.RFC3339))
+			break
+		}
 		if !*autoStart {
 			wr.Logger().Printf("Workflow has been created in Stopped state\n")
 			break
@@ -2683,6 +3185,7 @@ func commandExternalizeVindex(ctx context.Context, wr *wrangler.Wrangler, subFla
 func commandMaterialize(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	cells := subFlags.String("cells", "", "Source cells to replicate from.")
 	tabletTypes := subFlags.String("tablet_types", "", "Source tablet types to replicate from.")
+	externalCluster := subFlags.String("external_cluster", "", "Name of the mounted Vitess cluster the source keyspace is in. Overrides the external_cluster of the json spec.")
 	if err := subFlags.Parse(args); err != nil {
 		return err
 	}
@@ -2695,9 +3198,48 @@ func commandMaterialize(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 	}
 	ms.Cell = *cells
 	ms.TabletTypes = *tabletTypes
+	if *externalCluster != "" {
+		ms.ExternalCluster = *externalCluster
+	}
 	return wr.Materialize(ctx, ms)
 }
 
+func commandFollower(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	sourceKeyspace := subFlags.String("source", "", "Source keyspace")
+	externalCluster := subFlags.String("external_cluster", "", "Name of the mounted Vitess cluster the source keyspace is in")
+	cells := subFlags.String("cells", "", "Source cells to replicate from.")
+	tabletTypes := subFlags.String("tablet_types", "", "Source tablet types to replicate from.")
+	autoStart := subFlags.Bool("auto_start", true, "If false, streams will start in the Stopped state and will need to be explicitly started")
+	maxLag := subFlags.Duration("max_lag", 0, "Status fails if the follower keyspace lags more than this behind its source. 0 disables the check.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("two arguments are required: action, keyspace")
+	}
+	action := strings.ToLower(subFlags.Arg(0))
+	keyspace := subFlags.Arg(1)
+	switch action {
+	case "create":
+		if *sourceKeyspace == "" {
+			return fmt.Errorf("source keyspace is not specified")
+		}
+		return wr.CreateFollower(ctx, *sourceKeyspace, keyspace, *externalCluster, *cells, *tabletTypes, *autoStart)
+	case "status":
+		status, err := wr.FollowerStatus(ctx, keyspace, *maxLag)
+		if status != nil {
+			if err := printJSON(wr.Logger(), status); err != nil {
+				return err
+			}
+		}
+		return err
+	case "promote":
+		return wr.PromoteFollower(ctx, keyspace)
+	default:
+		return fmt.Errorf("invalid action %s: it must be one of Create, Status, Promote", subFlags.Arg(0))
+	}
+}
+
 func commandSplitClone(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	wr.Logger().Printf("*** This is a legacy sharding command that will soon be removed! Please use VReplication instead: https://vitess.io/docs/reference/vreplication/ ***\n")
 	if err := subFlags.Parse(args); err != nil {
@@ -3615,6 +4157,73 @@ func commandGetVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *fla
 	return nil
 }
 
+func commandGetVSchemaHistory(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	version := subFlags.Int64("version", 0, "If set, display this version of the vschema instead of the list of versions.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the GetVSchemaHistory command")
+	}
+	keyspace := subFlags.Arg(0)
+
+	if *version != 0 {
+		vv, err := wr.TopoServer().GetVSchemaVersion(ctx, keyspace, *version)
+		if err != nil {
+			return err
+		}
+		b, err := json2.MarshalIndentPB(vv.VSchema, "  ")
+		if err != nil {
+			return err
+		}
+		wr.Logger().Printf("%s\n", b)
+		return nil
+	}
+
+	history, err := wr.TopoServer().GetVSchemaHistory(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), history)
+}
+
+func commandRollbackVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	version := subFlags.Int64("to_version", 0, "The version of the vschema to restore, as listed by GetVSchemaHistory.")
+	skipRebuild := subFlags.Bool("skip_rebuild", false, "If set, do not rebuild the SrvSchema objects.")
+	var cells flagutil.StringListValue
+	subFlags.Var(&cells, "cells", "If specified, limits the rebuild to the cells, after upload. Ignored if --skip_rebuild is set.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the RollbackVSchema command")
+	}
+	if *version <= 0 {
+		return fmt.Errorf("--to_version is required for the RollbackVSchema command")
+	}
+	keyspace := subFlags.Arg(0)
+
+	vs, err := wr.TopoServer().RollbackVSchema(ctx, keyspace, *version)
+	if err != nil {
+		return err
+	}
+
+	b, err := json2.MarshalIndentPB(vs, "  ")
+	if err != nil {
+		wr.Logger().Errorf2(err, "Failed to marshal VSchema for display")
+	} else {
+		wr.Logger().Printf("Restored VSchema object:\n%s\n", b)
+	}
+
+	if *skipRebuild {
+		wr.Logger().Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
+		return nil
+	}
+	return wr.TopoServer().RebuildSrvVSchema(ctx, cells)
+}
+
 func commandGetRoutingRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -3658,6 +4267,7 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 	sqlFile := subFlags.String("sql_file", "", "A vschema ddl SQL statement (e.g. `add vindex`, `alter table t add vindex hash(id)`, etc)")
 	dryRun := subFlags.Bool("dry-run", false, "If set, do not save the altered vschema, simply echo to console.")
 	skipRebuild := subFlags.Bool("skip_rebuild", false, "If set, do not rebuild the SrvSchema objects.")
+	validateAgainstSchema := subFlags.Bool("validate_against_schema", false, "If set, reject the vschema if it references tables, vindex columns, sequences or authoritative columns that do not exist on the shard primaries.")
 	var cells flagutil.StringListValue
 	subFlags.Var(&cells, "cells", "If specified, limits the rebuild to the cells, after upload. Ignored if --skip_rebuild is set.")
 
@@ -3742,6 +4352,12 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 		wr.Logger().Printf("New VSchema object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", b)
 	}
 
+	if *validateAgainstSchema {
+		if err := schematools.ValidateVSchemaAgainstKeyspace(ctx, wr.TopoServer(), wr.TabletManagerClient(), keyspace, vs); err != nil {
+			return err
+		}
+	}
+
 	if *dryRun {
 		wr.Logger().Printf("Dry run: Skipping update of VSchema\n")
 		return nil
@@ -4135,6 +4751,64 @@ func commandGenerateShardRanges(ctx context.Context, wr *wrangler.Wrangler, subF
 	return printJSON(wr.Logger(), shardRanges)
 }
 
+func commandGetMySQLUserLimits(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() > 1 {
+		return fmt.Errorf("the GetMySQLUserLimits command takes at most one <user> argument")
+	}
+
+	limits, err := wr.TopoServer().GetMySQLUserLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if subFlags.NArg() == 1 {
+		userLimits := limits[subFlags.Arg(0)]
+		if userLimits == nil {
+			userLimits = &topo.MySQLUserLimits{}
+		}
+		return printJSON(wr.Logger(), userLimits)
+	}
+	return printJSON(wr.Logger(), limits)
+}
+
+func commandSetMySQLUserLimits(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	maxConnections := subFlags.Int("max_connections", 0, "The maximum number of MySQL connections of the user to each vtgate. 0 applies the limit of the auth server of the vtgates.")
+	maxConcurrentQueries := subFlags.Int("max_concurrent_queries", 0, "The maximum number of queries of the user that each vtgate executes at the same time. 0 applies the limit of the auth server of the vtgates.")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <user> argument is required for the SetMySQLUserLimits command")
+	}
+	user := subFlags.Arg(0)
+
+	limits, err := wr.TopoServer().GetMySQLUserLimits(ctx)
+	if err != nil {
+		return err
+	}
+	userLimits := limits[user]
+	if userLimits == nil {
+		userLimits = &topo.MySQLUserLimits{}
+	}
+
+	subFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "max_connections":
+			userLimits.MaxConnections = *maxConnections
+		case "max_concurrent_queries":
+			userLimits.MaxConcurrentQueries = *maxConcurrentQueries
+		}
+	})
+
+	if err := wr.TopoServer().SaveMySQLUserLimits(ctx, user, userLimits); err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), userLimits)
+}
+
 func commandPanic(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	panic(fmt.Errorf("this command panics on purpose"))
 }
@@ -18,42 +18,162 @@ package vtctlclient
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"context"
 
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 var (
 	defaultTimeout = time.Hour
+
+	retryCount   = flag.Int("retry_count", 0, "number of times a read-only command is retried, on a new connection, when the vtctld is unavailable, e.g. because it restarted")
+	retryBackoff = flag.Duration("retry_backoff", time.Second, "time to wait before the first retry of a read-only command, doubled at each retry")
 )
 
+// readOnlyCommandPrefixes are the prefixes of the names of the vtctl commands
+// which do not change anything, and can be run again when they fail.
+var readOnlyCommandPrefixes = []string{"Get", "List", "Find", "Show", "Validate"}
+
+// readOnlyCommands are the other read-only vtctl commands.
+var readOnlyCommands = map[string]bool{
+	"Ping":                      true,
+	"ShardReplicationPositions": true,
+	"Help":                      true,
+}
+
+// isReadOnlyCommand returns true if the command of args can be retried.
+func isReadOnlyCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if readOnlyCommands[args[0]] {
+		return true
+	}
+	for _, prefix := range readOnlyCommandPrefixes {
+		if strings.HasPrefix(args[0], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError returns true if the vtctld could not be reached, or the
+// connection to it was lost, rather than the command failed.
+func isTransientError(err error) bool {
+	return vterrors.Code(vterrors.FromGRPC(err)) == vtrpcpb.Code_UNAVAILABLE
+}
+
 // RunCommandAndWait executes a single command on a given vtctld and blocks until the command did return or timed out.
 // Output from vtctld is streamed as logutilpb.Event messages which
 // have to be consumed by the caller who has to specify a "recv" function.
+//
+// A read-only command is retried up to --retry_count times, on a new
+// connection and with an exponential backoff starting at --retry_backoff,
+// when the vtctld is unavailable. It is not retried once it has printed
+// output, which would be printed again. The retries are reported to recv as
+// warning events.
 func RunCommandAndWait(ctx context.Context, server string, args []string, recv func(*logutilpb.Event)) error {
 	if recv == nil {
 		return errors.New("no function closure for Event stream specified")
 	}
-	// create the client
+	conn, err := Dial(server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.RunCommandAndWait(ctx, args, recv)
+}
+
+// Connection is a connection to a vtctld, to execute several commands
+// without the overhead of a new connection for each of them.
+type Connection struct {
+	server string
+	client VtctlClient
+}
+
+// Dial connects to a vtctld.
+func Dial(server string) (*Connection, error) {
 	client, err := New(server)
 	if err != nil {
-		return fmt.Errorf("cannot dial to server %v: %v", server, err)
+		return nil, fmt.Errorf("cannot dial to server %v: %v", server, err)
 	}
-	defer client.Close()
+	return &Connection{server: server, client: client}, nil
+}
+
+// Close closes the connection.
+func (conn *Connection) Close() {
+	conn.client.Close()
+}
+
+// reconnect replaces the client of the connection with a new one.
+func (conn *Connection) reconnect() error {
+	client, err := New(conn.server)
+	if err != nil {
+		return fmt.Errorf("cannot dial to server %v: %v", conn.server, err)
+	}
+	conn.client.Close()
+	conn.client = client
+	return nil
+}
+
+// RunCommandAndWait executes a command on the connection, like the
+// RunCommandAndWait function. The connection is replaced by a new one before
+// each retry.
+func (conn *Connection) RunCommandAndWait(ctx context.Context, args []string, recv func(*logutilpb.Event)) error {
+	if recv == nil {
+		return errors.New("no function closure for Event stream specified")
+	}
+
+	retries := 0
+	if isReadOnlyCommand(args) {
+		retries = *retryCount
+	}
+	backoff := *retryBackoff
+	for attempt := 0; ; attempt++ {
+		printed, transient, err := conn.runCommandAndWait(ctx, args, recv)
+		if err == nil || attempt >= retries || printed || !transient {
+			return err
+		}
+
+		recv(&logutilpb.Event{
+			Time:  logutil.TimeToProto(time.Now()),
+			Level: logutilpb.Level_WARNING,
+			Value: fmt.Sprintf("%v, retrying %v in %v (retry %d/%d)", err, args[0], backoff, attempt+1, retries),
+		})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if err := conn.reconnect(); err != nil {
+			return err
+		}
+	}
+}
 
+// runCommandAndWait executes a command once. It returns whether the command
+// printed output, and whether its error is transient.
+func (conn *Connection) runCommandAndWait(ctx context.Context, args []string, recv func(*logutilpb.Event)) (printed, transient bool, err error) {
 	// run the command ( get the timeout from the context )
 	timeout := defaultTimeout
 	deadline, ok := ctx.Deadline()
 	if ok {
 		timeout = time.Until(deadline)
 	}
-	stream, err := client.ExecuteVtctlCommand(ctx, args, timeout)
+	stream, err := conn.client.ExecuteVtctlCommand(ctx, args, timeout)
 	if err != nil {
-		return fmt.Errorf("cannot execute remote command: %v", err)
+		return false, isTransientError(err), fmt.Errorf("cannot execute remote command: %v", err)
 	}
 
 	// stream the result
@@ -61,11 +181,14 @@ func RunCommandAndWait(ctx context.Context, server string, args []string, recv f
 		e, err := stream.Recv()
 		switch err {
 		case nil:
+			if e.Level == logutilpb.Level_CONSOLE {
+				printed = true
+			}
 			recv(e)
 		case io.EOF:
-			return nil
+			return printed, false, nil
 		default:
-			return fmt.Errorf("remote error: %v", err)
+			return printed, isTransientError(err), fmt.Errorf("remote error: %v", err)
 		}
 	}
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctlclient
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/logutil"
+
+	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+)
+
+// fakeStream streams its events, then fails with its error.
+type fakeStream struct {
+	events []*logutilpb.Event
+	err    error
+}
+
+func (s *fakeStream) Recv() (*logutilpb.Event, error) {
+	if len(s.events) > 0 {
+		e := s.events[0]
+		s.events = s.events[1:]
+		return e, nil
+	}
+	return nil, s.err
+}
+
+// fakeClient returns the streams of its attempts in turn.
+type fakeClient struct {
+	attempts *[]*fakeStream
+}
+
+func (c *fakeClient) ExecuteVtctlCommand(ctx context.Context, args []string, actionTimeout time.Duration) (logutil.EventStream, error) {
+	stream := (*c.attempts)[0]
+	*c.attempts = (*c.attempts)[1:]
+	return stream, nil
+}
+
+func (c *fakeClient) Close() {}
+
+var dials int
+
+func setupFakeClient(t *testing.T, count int, attempts ...*fakeStream) *[]*fakeStream {
+	dials = 0
+	RegisterFactory("retrytest", func(addr string) (VtctlClient, error) {
+		dials++
+		return &fakeClient{attempts: &attempts}, nil
+	})
+	protocol, retries, backoff := *vtctlClientProtocol, *retryCount, *retryBackoff
+	*vtctlClientProtocol, *retryCount, *retryBackoff = "retrytest", count, time.Millisecond
+	t.Cleanup(func() {
+		UnregisterFactoryForTest("retrytest")
+		*vtctlClientProtocol, *retryCount, *retryBackoff = protocol, retries, backoff
+	})
+	return &attempts
+}
+
+func unavailable() error {
+	return status.Error(codes.Unavailable, "transport is closing")
+}
+
+func TestRunCommandAndWaitRetries(t *testing.T) {
+	remaining := setupFakeClient(t, 2,
+		&fakeStream{events: []*logutilpb.Event{{Level: logutilpb.Level_INFO, Value: "loading"}}, err: unavailable()},
+		&fakeStream{err: unavailable()},
+		&fakeStream{events: []*logutilpb.Event{{Level: logutilpb.Level_CONSOLE, Value: "{}"}}, err: io.EOF},
+	)
+
+	var events []*logutilpb.Event
+	err := RunCommandAndWait(context.Background(), "server", []string{"GetKeyspace", "ks"}, func(e *logutilpb.Event) {
+		events = append(events, e)
+	})
+	require.NoError(t, err)
+	assert.Empty(t, *remaining)
+	// Each retry is made on a new connection.
+	assert.Equal(t, 3, dials)
+	require.Len(t, events, 4)
+	assert.Equal(t, logutilpb.Level_WARNING, events[1].Level)
+	assert.Contains(t, events[1].Value, "retrying GetKeyspace in 1ms (retry 1/2)")
+	assert.Contains(t, events[2].Value, "retrying GetKeyspace in 2ms (retry 2/2)")
+	assert.Equal(t, "{}", events[3].Value)
+}
+
+func TestRunCommandAndWaitNoRetries(t *testing.T) {
+	recv := func(e *logutilpb.Event) {}
+	tcases := []struct {
+		name     string
+		args     []string
+		attempts []*fakeStream
+	}{{
+		name:     "read-write command",
+		args:     []string{"SetKeyspaceSettings", "ks"},
+		attempts: []*fakeStream{{err: unavailable()}, {err: io.EOF}},
+	}, {
+		name:     "error of the command",
+		args:     []string{"GetKeyspace", "ks"},
+		attempts: []*fakeStream{{err: status.Error(codes.NotFound, "node doesn't exist")}, {err: io.EOF}},
+	}, {
+		name:     "output printed",
+		args:     []string{"ListAllTablets"},
+		attempts: []*fakeStream{{events: []*logutilpb.Event{{Level: logutilpb.Level_CONSOLE}}, err: unavailable()}, {err: io.EOF}},
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			remaining := setupFakeClient(t, 3, tcase.attempts...)
+			err := RunCommandAndWait(context.Background(), "server", tcase.args, recv)
+			require.Error(t, err)
+			assert.Len(t, *remaining, 1)
+		})
+	}
+
+	// Retries stop when all of them failed.
+	remaining := setupFakeClient(t, 1, &fakeStream{err: unavailable()}, &fakeStream{err: unavailable()}, &fakeStream{err: io.EOF})
+	err := RunCommandAndWait(context.Background(), "server", []string{"Ping", "zone1-100"}, recv)
+	assert.ErrorContains(t, err, "transport is closing")
+	assert.Len(t, *remaining, 1)
+}
+
+func TestConnection(t *testing.T) {
+	remaining := setupFakeClient(t, 0, &fakeStream{err: io.EOF}, &fakeStream{err: status.Error(codes.NotFound, "node doesn't exist")}, &fakeStream{err: io.EOF})
+	conn, err := Dial("server")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	recv := func(e *logutilpb.Event) {}
+	require.NoError(t, conn.RunCommandAndWait(context.Background(), []string{"GetKeyspace", "ks1"}, recv))
+	assert.Error(t, conn.RunCommandAndWait(context.Background(), []string{"GetKeyspace", "ks2"}, recv))
+	require.NoError(t, conn.RunCommandAndWait(context.Background(), []string{"GetKeyspace", "ks3"}, recv))
+	assert.Empty(t, *remaining)
+	assert.Equal(t, 1, dials)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// scheduledMessagePrefix starts the message of the stopped vreplication
+// streams of a workflow that is scheduled to start later. The start time
+// follows it, in RFC 3339 format and in UTC.
+const scheduledMessagePrefix = "Scheduled to start at "
+
+// startAtLayouts are the layouts accepted by ParseStartAt for the times
+// without a time zone offset.
+var startAtLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// ScheduledMessage returns the message of the streams of a workflow that is
+// scheduled to start at the given time.
+func ScheduledMessage(startAt time.Time) string {
+	return scheduledMessagePrefix + startAt.UTC().Format(time.RFC3339)
+}
+
+// ScheduledStartTime returns the start time of a stream message returned by
+// ScheduledMessage, and false if the message is not one of them.
+func ScheduledStartTime(message string) (time.Time, bool) {
+	if !strings.HasPrefix(message, scheduledMessagePrefix) {
+		return time.Time{}, false
+	}
+	startAt, err := time.Parse(time.RFC3339, strings.TrimPrefix(message, scheduledMessagePrefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return startAt, true
+}
+
+// ParseStartAt parses the start time of a scheduled workflow. It is either in
+// RFC 3339 format, with its time zone offset, or a local time such as
+// "2022-09-01 02:00" in the given IANA time zone, e.g. "America/New_York".
+// Local times are in UTC if the time zone is empty.
+func ParseStartAt(value, timeZone string) (time.Time, error) {
+	if startAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return startAt, nil
+	}
+	loc := time.UTC
+	if timeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time zone %v: %v", timeZone, err)
+		}
+	}
+	for _, layout := range startAtLayouts {
+		if startAt, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return startAt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start time %v: it must be in RFC 3339 format or of the form YYYY-MM-DD HH:MM[:SS]", value)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStartAt(t *testing.T) {
+	tcases := []struct {
+		value    string
+		timeZone string
+		want     string
+		err      string
+	}{{
+		value: "2022-09-01T02:00:00-04:00",
+		want:  "2022-09-01T06:00:00Z",
+	}, {
+		value:    "2022-09-01T02:00:00Z",
+		timeZone: "Asia/Tokyo",
+		want:     "2022-09-01T02:00:00Z",
+	}, {
+		value: "2022-09-01 02:00",
+		want:  "2022-09-01T02:00:00Z",
+	}, {
+		value:    "2022-09-01 02:00:30",
+		timeZone: "America/New_York",
+		want:     "2022-09-01T06:00:30Z",
+	}, {
+		value:    "2022-12-01T02:00",
+		timeZone: "America/New_York",
+		want:     "2022-12-01T07:00:00Z",
+	}, {
+		value:    "2022-09-01 02:00",
+		timeZone: "Mars/Olympus_Mons",
+		err:      "invalid time zone Mars/Olympus_Mons",
+	}, {
+		value: "tonight",
+		err:   "invalid start time tonight",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.value, func(t *testing.T) {
+			startAt, err := ParseStartAt(tcase.value, tcase.timeZone)
+			if tcase.err != "" {
+				assert.ErrorContains(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, startAt.UTC().Format(time.RFC3339))
+		})
+	}
+}
+
+func TestScheduledMessage(t *testing.T) {
+	startAt := time.Date(2022, 9, 1, 2, 0, 0, 0, time.FixedZone("EDT", -4*3600))
+	message := ScheduledMessage(startAt)
+	assert.Equal(t, "Scheduled to start at 2022-09-01T06:00:00Z", message)
+
+	got, ok := ScheduledStartTime(message)
+	require.True(t, ok)
+	assert.True(t, startAt.Equal(got))
+
+	for _, message := range []string{"", Frozen, "Picked source tablet: zone1-0000000100", "Scheduled to start at noon"} {
+		_, ok := ScheduledStartTime(message)
+		assert.False(t, ok, message)
+	}
+}
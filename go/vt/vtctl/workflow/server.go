@@ -722,7 +722,7 @@ func (s *Server) getWorkflowCopyStates(ctx context.Context, tablet *topo.TabletI
 	span.Annotate("tablet_alias", tablet.AliasString())
 	span.Annotate("vrepl_id", id)
 
-	query := fmt.Sprintf("select table_name, lastpk from _vt.copy_state where vrepl_id = %d", id)
+	query := fmt.Sprintf("select table_name, lastpk, rows_copied, bytes_copied from _vt.copy_state where vrepl_id = %d", id)
 	qr, err := s.tmc.VReplicationExec(ctx, tablet.Tablet, query)
 	if err != nil {
 		return nil, err
@@ -735,10 +735,20 @@ func (s *Server) getWorkflowCopyStates(ctx context.Context, tablet *topo.TabletI
 
 	copyStates := make([]*vtctldatapb.Workflow_Stream_CopyState, len(result.Rows))
 	for i, row := range result.Rows {
+		rowsCopied, err := evalengine.ToInt64(row[2])
+		if err != nil {
+			return nil, err
+		}
+		bytesCopied, err := evalengine.ToInt64(row[3])
+		if err != nil {
+			return nil, err
+		}
 		// These fields are technically varbinary, but this is close enough.
 		copyStates[i] = &vtctldatapb.Workflow_Stream_CopyState{
-			Table:  row[0].ToString(),
-			LastPk: row[1].ToString(),
+			Table:       row[0].ToString(),
+			LastPk:      row[1].ToString(),
+			RowsCopied:  rowsCopied,
+			BytesCopied: bytesCopied,
 		}
 	}
 
@@ -37,9 +37,11 @@ import (
 	"vitess.io/vitess/go/vt/proto/vttime"
 	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/schemamanager"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl"
+	"vitess.io/vitess/go/vt/vtctl/audit"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/workflow"
 	"vitess.io/vitess/go/vt/wrangler"
@@ -592,7 +594,12 @@ func initAPI(ctx context.Context, ts *topo.Server, actions *ActionRepository, he
 		logstream := logutil.NewMemoryLogger()
 
 		wr := wrangler.New(logstream, ts, tmClient)
+		start := time.Now()
 		err := vtctl.RunCommand(r.Context(), wr, args)
+		if len(args) > 0 {
+			caller, _ := servenv.AuthenticatedUserFromHTTP(r)
+			audit.Default().Record(r.Context(), audit.NewEntry("vtctl."+args[0], args[1:], caller, r.RemoteAddr, start, err))
+		}
 		if err != nil {
 			resp.Error = err.Error()
 		}
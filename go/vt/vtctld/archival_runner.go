@@ -0,0 +1,273 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"flag"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/archive"
+	"vitess.io/vitess/go/vt/vtctl/importdata"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var archivalCheckInterval = flag.Duration("archival_check_interval", time.Minute, "Interval at which vtctld runs the archivals created with CreateArchival that are not paused, until no row of their table matches their predicate. 0 disables the archivals.")
+
+var (
+	archivedRows   = stats.NewCountersWithMultiLabels("ArchivedRows", "Number of rows archived by vtctld", []string{"Keyspace", "Archival"})
+	archivalErrors = stats.NewCountersWithMultiLabels("ArchivalErrors", "Number of runs of archivals that failed", []string{"Keyspace", "Archival"})
+)
+
+// archivalRunner periodically runs the archivals that are not paused. Each
+// archival runs in its own goroutine, until no row of its table matches its
+// predicate, or until it is paused or deleted.
+type archivalRunner struct {
+	ts  *topo.Server
+	tmc tmclient.TabletManagerClient
+	// archive runs an archival. It is runArchival, except in tests.
+	archive func(ctx context.Context, keyspace string, archival *topo.Archival) error
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func newArchivalRunner(ts *topo.Server, tmc tmclient.TabletManagerClient) *archivalRunner {
+	r := &archivalRunner{
+		ts:      ts,
+		tmc:     tmc,
+		running: make(map[string]bool),
+	}
+	r.archive = r.runArchival
+	return r
+}
+
+// initArchivalRunner starts the archival runner if it is enabled.
+func initArchivalRunner(ts *topo.Server) {
+	if *archivalCheckInterval <= 0 {
+		return
+	}
+	r := newArchivalRunner(ts, tmclient.NewTabletManagerClient())
+	go r.run(context.Background(), *archivalCheckInterval)
+}
+
+// run starts the archivals at every interval, until ctx is done.
+func (r *archivalRunner) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check starts the archivals of every keyspace that are neither paused nor
+// running already.
+func (r *archivalRunner) check(ctx context.Context) {
+	keyspaces, err := r.ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Failed to get the keyspaces to run their archivals: %v", err)
+		return
+	}
+	for _, keyspace := range keyspaces {
+		archivals, err := r.ts.GetArchivals(ctx, keyspace)
+		if err != nil {
+			log.Warningf("Failed to get the archivals of keyspace %v: %v", keyspace, err)
+			continue
+		}
+		for _, archival := range archivals {
+			if archival.Paused {
+				continue
+			}
+			r.start(ctx, keyspace, archival)
+		}
+	}
+}
+
+func (r *archivalRunner) start(ctx context.Context, keyspace string, archival *topo.Archival) {
+	key := keyspace + "." + archival.Name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[key] {
+		return
+	}
+	r.running[key] = true
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.running, key)
+		}()
+
+		err := r.archive(ctx, keyspace, archival)
+		if err == nil && archival.LastError == "" {
+			return
+		}
+		lastError := ""
+		if err != nil {
+			// It is retried at the next check.
+			log.Errorf("Failed to run archival %v: %v", key, err)
+			archivalErrors.Add([]string{keyspace, archival.Name}, 1)
+			lastError = err.Error()
+		}
+		if _, err := r.ts.UpdateArchivalFields(ctx, keyspace, archival.Name, func(a *topo.Archival) error {
+			a.LastError = lastError
+			return nil
+		}); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Failed to save the last error of archival %v: %v", key, err)
+		}
+	}()
+}
+
+// runArchival archives the rows of an archival, until none matches its
+// predicate, or it is paused or deleted. The progress is saved after each
+// batch.
+func (r *archivalRunner) runArchival(ctx context.Context, keyspace string, archival *topo.Archival) error {
+	primaries := make(map[string]*topodatapb.Tablet)
+	sourceShards, err := r.addPrimaries(ctx, keyspace, primaries)
+	if err != nil {
+		return err
+	}
+	shards := make([]string, 0, len(sourceShards))
+	for name := range sourceShards {
+		shards = append(shards, name)
+	}
+	sort.Strings(shards)
+
+	schema, err := r.tmc.GetSchema(ctx, primaries[topoproto.KeyspaceShardString(keyspace, shards[0])], &tabletmanagerdatapb.GetSchemaRequest{
+		Tables: []string{archival.Table},
+	})
+	if err != nil {
+		return err
+	}
+	if len(schema.TableDefinitions) != 1 {
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s not found in keyspace %s", archival.Table, keyspace)
+	}
+
+	var target *importdata.Target
+	var bs backupstorage.BackupStorage
+	if archival.TargetTable != "" {
+		target = &importdata.Target{
+			Keyspace: archival.TargetKeyspace,
+			Table:    archival.TargetTable,
+		}
+		if target.Keyspace == "" {
+			target.Keyspace = keyspace
+		}
+		target.VSchema, err = r.ts.GetVSchema(ctx, target.Keyspace)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return err
+		}
+		target.Shards, err = r.addPrimaries(ctx, target.Keyspace, primaries)
+		if err != nil {
+			return err
+		}
+	} else {
+		bs, err = backupstorage.GetBackupStorage()
+		if err != nil {
+			return err
+		}
+		defer bs.Close()
+	}
+
+	exec := func(ctx context.Context, keyspace, shard, query string, maxRows int) (*sqltypes.Result, error) {
+		tablet, ok := primaries[topoproto.KeyspaceShardString(keyspace, shard)]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown shard %s/%s", keyspace, shard)
+		}
+		qr, err := r.tmc.ExecuteFetchAsApp(ctx, tablet, true /* usePool */, []byte(query), maxRows)
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.Proto3ToResult(qr), nil
+	}
+
+	a, err := archive.NewArchiver(keyspace, archival, schema.TableDefinitions[0].PrimaryKeyColumns, target, bs, exec)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.Run(ctx, shards, func(rows int) (bool, error) {
+		archivedRows.Add([]string{keyspace, archival.Name}, int64(rows))
+		updated, err := r.ts.UpdateArchivalFields(ctx, keyspace, archival.Name, func(a *topo.Archival) error {
+			a.ArchivedRows += int64(rows)
+			a.LastBatchTime = time.Now().UTC().Format(time.RFC3339)
+			return nil
+		})
+		if topo.IsErrType(err, topo.NoNode) {
+			log.Infof("Archival %v.%v was deleted, stopping it", keyspace, archival.Name)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if updated.Paused {
+			log.Infof("Archival %v.%v was paused, stopping it", keyspace, archival.Name)
+		}
+		return !updated.Paused, nil
+	})
+}
+
+// addPrimaries adds the primary tablets of the serving shards of keyspace
+// to primaries, by keyspace/shard, and returns the serving shards, by name.
+func (r *archivalRunner) addPrimaries(ctx context.Context, keyspace string, primaries map[string]*topodatapb.Tablet) (map[string]*topodatapb.Shard, error) {
+	shards, err := r.ts.FindAllShardsInKeyspace(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	serving := make(map[string]*topodatapb.Shard)
+	for name, si := range shards {
+		if !si.IsPrimaryServing {
+			continue
+		}
+		if si.PrimaryAlias == nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "shard %s/%s has no primary", keyspace, name)
+		}
+		ti, err := r.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, err
+		}
+		primaries[topoproto.KeyspaceShardString(keyspace, name)] = ti.Tablet
+		serving[name] = si.Shard
+	}
+	if len(serving) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no serving shards", keyspace)
+	}
+	return serving, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestArchivalRunner(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	for _, archival := range []*topo.Archival{
+		{Name: "a1", Table: "t1", Where: "id < 10", TargetTable: "t1_archive", BatchSize: 10},
+		{Name: "a2", Table: "t2", Where: "id < 10", TargetDir: "t2", BatchSize: 10, Paused: true},
+		{Name: "a3", Table: "t3", Where: "id < 10", TargetDir: "t3", BatchSize: 10, LastError: "previous error"},
+	} {
+		require.NoError(t, ts.CreateArchival(ctx, "ks", archival))
+	}
+
+	var mu sync.Mutex
+	var archived []string
+	r := newArchivalRunner(ts, nil)
+	r.archive = func(ctx context.Context, keyspace string, archival *topo.Archival) error {
+		mu.Lock()
+		defer mu.Unlock()
+		archived = append(archived, keyspace+"."+archival.Name)
+		if archival.Name == "a1" {
+			return errors.New("tablet is unreachable")
+		}
+		return nil
+	}
+	r.check(ctx)
+	r.wg.Wait()
+
+	sort.Strings(archived)
+	assert.Equal(t, []string{"ks.a1", "ks.a3"}, archived)
+	assert.Equal(t, int64(1), archivalErrors.Counts()["ks.a1"])
+
+	a1, err := ts.GetArchival(ctx, "ks", "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "tablet is unreachable", a1.LastError)
+	// The error of the previous run is cleared.
+	a3, err := ts.GetArchival(ctx, "ks", "a3")
+	require.NoError(t, err)
+	assert.Empty(t, a3.LastError)
+
+	// An archival that is still running is not started again.
+	r.running["ks.a1"] = true
+	archived = nil
+	r.check(ctx)
+	r.wg.Wait()
+	assert.Equal(t, []string{"ks.a3"}, archived)
+}
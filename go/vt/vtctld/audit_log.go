@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"flag"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/audit"
+)
+
+var (
+	auditLogSize           = flag.Int("audit_log_size", 1000, "The number of the most recent commands recorded by the audit log of vtctld that it keeps in memory, for GetAuditLog. 0 disables the audit log")
+	auditLogSinks          = flag.String("audit_log_sinks", "", "Comma-separated list of the sinks the audit log of vtctld writes all the commands it records to: file:<path> appends them to a file, syslog[:<tag>] sends them to the local syslog, and topo[:<dir>] writes them to a directory of the global topo, audit_log by default, shared by all the vtctlds. GetAuditLog reads the topo sink, if any")
+	auditLogTopoMaxEntries = flag.Int("audit_log_topo_max_entries", 10000, "The number of entries the topo sink of the audit log keeps, the oldest ones are deleted")
+	auditLogReadOnly       = flag.Bool("audit_log_read_only_commands", false, "Whether the audit log of vtctld also records the read-only commands, e.g. GetKeyspace, rather than only the ones that change the cluster")
+)
+
+// initAuditLog creates the audit log of the commands received by the gRPC
+// servers and the API of vtctld.
+func initAuditLog(ts *topo.Server) error {
+	if *auditLogSize <= 0 {
+		return nil
+	}
+	audit.TopoMaxEntries = *auditLogTopoMaxEntries
+	auditLog, err := audit.NewLog(ts, *auditLogSize, *auditLogSinks, *auditLogReadOnly)
+	if err != nil {
+		log.Errorf("cannot create the audit log: %v", err)
+		return err
+	}
+	audit.SetDefault(auditLog)
+	servenv.OnClose(auditLog.Close)
+	return nil
+}
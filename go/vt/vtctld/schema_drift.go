@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var schemaDriftCheckInterval = flag.Duration("schema_drift_check_interval", 0, "If set, vtctld compares the schemas of all the shards of every keyspace at this interval, and reports the shards whose schema drifted in /api/schema_drift/ and in the SchemaDriftShards metric. Disabled by default.")
+
+var (
+	schemaDriftShards    = stats.NewGaugesWithSingleLabel("SchemaDriftShards", "Number of shards whose schema differs from the other shards of their keyspace", "Keyspace")
+	schemaDriftLastCheck = stats.NewGauge("SchemaDriftLastCheckTimestamp", "Unix timestamp of the last schema drift check")
+)
+
+// KeyspaceSchemaDrift is the schema drift of the shards of a keyspace.
+type KeyspaceSchemaDrift struct {
+	// Shards are the shards whose schema differs from the schema of the
+	// reference shard of the keyspace, with their differences.
+	Shards map[string][]string `json:"shards,omitempty"`
+	// Error is set when the schemas of the keyspace could not be compared.
+	Error string `json:"error,omitempty"`
+}
+
+// SchemaDriftReport is the result of the last schema drift check.
+type SchemaDriftReport struct {
+	LastCheck time.Time                       `json:"last_check"`
+	Keyspaces map[string]*KeyspaceSchemaDrift `json:"keyspaces"`
+}
+
+// schemaValidator compares the schemas of the shards of a keyspace. It is
+// implemented by grpcvtctldserver.VtctldServer.
+type schemaValidator interface {
+	ValidateSchemaKeyspace(ctx context.Context, req *vtctldatapb.ValidateSchemaKeyspaceRequest) (*vtctldatapb.ValidateSchemaKeyspaceResponse, error)
+}
+
+// schemaDriftDetector periodically compares the schemas of the shards of
+// every keyspace, so that a shard that missed a migration is noticed.
+type schemaDriftDetector struct {
+	ts        *topo.Server
+	validator schemaValidator
+
+	mu     sync.Mutex
+	report *SchemaDriftReport
+}
+
+func newSchemaDriftDetector(ts *topo.Server, validator schemaValidator) *schemaDriftDetector {
+	return &schemaDriftDetector{
+		ts:        ts,
+		validator: validator,
+	}
+}
+
+// initSchemaDrift starts the schema drift detector if it is enabled, and
+// serves its report.
+func initSchemaDrift(ts *topo.Server) {
+	var detector *schemaDriftDetector
+	if *schemaDriftCheckInterval > 0 {
+		detector = newSchemaDriftDetector(ts, grpcvtctldserver.NewVtctldServer(ts))
+		go detector.run(context.Background(), *schemaDriftCheckInterval)
+	}
+
+	handleCollection("schema_drift", func(r *http.Request) (any, error) {
+		if detector == nil {
+			return nil, errors.New("schema drift detection is disabled, see --schema_drift_check_interval")
+		}
+		report := detector.Report()
+		if report == nil {
+			return nil, errors.New("schemas were not checked for drift yet")
+		}
+		return report, nil
+	})
+}
+
+// run checks the schemas for drift at every interval, until ctx is done.
+func (d *schemaDriftDetector) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.check(ctx, interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns the result of the last check, or nil if the schemas were not
+// checked yet.
+func (d *schemaDriftDetector) Report() *SchemaDriftReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.report
+}
+
+// check compares the schemas of the shards of every keyspace, updates the
+// report and the metrics, and warns about the shards that newly drifted.
+func (d *schemaDriftDetector) check(ctx context.Context, timeout time.Duration) {
+	keyspaces, err := d.ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Failed to get the keyspaces to check for schema drift: %v", err)
+		return
+	}
+
+	report := &SchemaDriftReport{
+		LastCheck: time.Now(),
+		Keyspaces: make(map[string]*KeyspaceSchemaDrift, len(keyspaces)),
+	}
+	for _, keyspace := range keyspaces {
+		report.Keyspaces[keyspace] = d.checkKeyspace(ctx, keyspace, timeout)
+	}
+
+	d.mu.Lock()
+	previous := d.report
+	d.report = report
+	d.mu.Unlock()
+
+	schemaDriftShards.ResetAll()
+	for keyspace, drift := range report.Keyspaces {
+		schemaDriftShards.Set(keyspace, int64(len(drift.Shards)))
+		if drift.Error != "" {
+			log.Warningf("Failed to check the schemas of keyspace %v for drift: %v", keyspace, drift.Error)
+		}
+		for shard, diffs := range drift.Shards {
+			if previous != nil && previous.Keyspaces[keyspace] != nil && previous.Keyspaces[keyspace].Shards[shard] != nil {
+				continue
+			}
+			log.Warningf("The schema of shard %v/%v drifted from the other shards of the keyspace, did it miss a migration? %v", keyspace, shard, strings.Join(diffs, "; "))
+		}
+	}
+	schemaDriftLastCheck.Set(report.LastCheck.Unix())
+}
+
+func (d *schemaDriftDetector) checkKeyspace(ctx context.Context, keyspace string, timeout time.Duration) *KeyspaceSchemaDrift {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	drift := &KeyspaceSchemaDrift{}
+	resp, err := d.validator.ValidateSchemaKeyspace(ctx, &vtctldatapb.ValidateSchemaKeyspaceRequest{
+		Keyspace:      keyspace,
+		SkipNoPrimary: true,
+	})
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+	if len(resp.ResultsByShard) == 0 && len(resp.Results) > 0 {
+		// The shards of the keyspace could not be listed.
+		drift.Error = strings.Join(resp.Results, "; ")
+		return drift
+	}
+	for shard, shardResp := range resp.ResultsByShard {
+		if len(shardResp.Results) == 0 {
+			continue
+		}
+		if drift.Shards == nil {
+			drift.Shards = make(map[string][]string)
+		}
+		drift.Shards[shard] = shardResp.Results
+	}
+	return drift
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+type fakeSchemaValidator struct {
+	responses map[string]*vtctldatapb.ValidateSchemaKeyspaceResponse
+}
+
+func (v *fakeSchemaValidator) ValidateSchemaKeyspace(ctx context.Context, req *vtctldatapb.ValidateSchemaKeyspaceRequest) (*vtctldatapb.ValidateSchemaKeyspaceResponse, error) {
+	if !req.SkipNoPrimary {
+		return nil, errors.New("shards without a primary are not skipped")
+	}
+	resp, ok := v.responses[req.Keyspace]
+	if !ok {
+		return nil, errors.New("no schema")
+	}
+	return resp, nil
+}
+
+func TestSchemaDriftDetector(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	for _, keyspace := range []string{"ks1", "ks2", "ks3"} {
+		require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	}
+
+	validator := &fakeSchemaValidator{
+		responses: map[string]*vtctldatapb.ValidateSchemaKeyspaceResponse{
+			"ks1": {
+				Results: []string{"t1 has an extra column c2 on -80 but not on 80-"},
+				ResultsByShard: map[string]*vtctldatapb.ValidateShardResponse{
+					"-80": {},
+					"80-": {Results: []string{"t1 has an extra column c2 on -80 but not on 80-"}},
+				},
+			},
+			"ks2": {
+				ResultsByShard: map[string]*vtctldatapb.ValidateShardResponse{
+					"0": {},
+				},
+			},
+		},
+	}
+	detector := newSchemaDriftDetector(ts, validator)
+	assert.Nil(t, detector.Report())
+
+	detector.check(ctx, time.Minute)
+	report := detector.Report()
+	require.NotNil(t, report)
+	assert.False(t, report.LastCheck.IsZero())
+	assert.Equal(t, map[string]*KeyspaceSchemaDrift{
+		"ks1": {Shards: map[string][]string{"80-": {"t1 has an extra column c2 on -80 but not on 80-"}}},
+		"ks2": {},
+		"ks3": {Error: "no schema"},
+	}, report.Keyspaces)
+	assert.Equal(t, map[string]int64{"ks1": 1, "ks2": 0, "ks3": 0}, schemaDriftShards.Counts())
+	assert.Equal(t, report.LastCheck.Unix(), schemaDriftLastCheck.Get())
+
+	// Once the shard catches up, the drift is cleared.
+	validator.responses["ks1"] = &vtctldatapb.ValidateSchemaKeyspaceResponse{
+		ResultsByShard: map[string]*vtctldatapb.ValidateShardResponse{
+			"-80": {},
+			"80-": {},
+		},
+	}
+	detector.check(ctx, time.Minute)
+	assert.Equal(t, &KeyspaceSchemaDrift{}, detector.Report().Keyspaces["ks1"])
+	assert.Equal(t, int64(0), schemaDriftShards.Counts()["ks1"])
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topotools"
+)
+
+var versionSkewCheckInterval = flag.Duration("version_skew_check_interval", 10*time.Minute, "If set, vtctld checks at this interval that the versions recorded in the topo by the vtgates, vttablets, vtctlds and vtorcs are compatible, and reports the unsupported skews in /api/version_skew/ and in the VersionSkewProblems metric. 0 disables the check.")
+
+var (
+	versionSkewProblems  = stats.NewGauge("VersionSkewProblems", "Number of unsupported version skews between the components of the cluster")
+	versionSkewLastCheck = stats.NewGauge("VersionSkewLastCheckTimestamp", "Unix timestamp of the last version skew check")
+)
+
+// VersionSkewReport is the result of the last version skew check.
+type VersionSkewReport struct {
+	LastCheck time.Time `json:"last_check"`
+	// Versions maps "<component>/<id>" to the version of each component.
+	Versions map[string]string `json:"versions"`
+	// Problems are the version skews that exceed the supported bounds.
+	Problems []string `json:"problems,omitempty"`
+	// Warnings are the versions that were not checked.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// versionSkewChecker periodically checks the version skew of the components
+// of the cluster, so that an upgrade done in the wrong order is noticed.
+type versionSkewChecker struct {
+	ts *topo.Server
+
+	mu     sync.Mutex
+	report *VersionSkewReport
+}
+
+// initVersionSkew starts the version skew checker if it is enabled, and
+// serves its report.
+func initVersionSkew(ts *topo.Server) {
+	var checker *versionSkewChecker
+	if *versionSkewCheckInterval > 0 {
+		checker = &versionSkewChecker{ts: ts}
+		go checker.run(context.Background(), *versionSkewCheckInterval)
+	}
+
+	handleCollection("version_skew", func(r *http.Request) (any, error) {
+		if checker == nil {
+			return nil, errors.New("version skew checks are disabled, see --version_skew_check_interval")
+		}
+		report := checker.Report()
+		if report == nil {
+			return nil, errors.New("the version skew was not checked yet")
+		}
+		return report, nil
+	})
+}
+
+// run checks the version skew at every interval, until ctx is done.
+func (c *versionSkewChecker) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns the result of the last check, or nil if the version skew was
+// not checked yet.
+func (c *versionSkewChecker) Report() *VersionSkewReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.report
+}
+
+// check checks the version skew, updates the report and the metrics, and
+// warns about the new problems.
+func (c *versionSkewChecker) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+	versions, err := c.ts.GetComponentVersions(ctx)
+	if err != nil {
+		log.Errorf("Failed to get the versions of the components to check the version skew: %v", err)
+		return
+	}
+
+	now := time.Now()
+	skew := topotools.CheckVersionSkew(versions, topotools.DefaultMaxMajorVersionSkew, topotools.DefaultComponentVersionStaleAfter, now)
+	report := &VersionSkewReport{
+		LastCheck: now,
+		Versions:  make(map[string]string, len(versions)),
+		Problems:  skew.Problems,
+		Warnings:  skew.Warnings,
+	}
+	for _, cv := range versions {
+		report.Versions[cv.Component+"/"+cv.ID] = cv.Version
+	}
+
+	c.mu.Lock()
+	previous := c.report
+	c.report = report
+	c.mu.Unlock()
+
+	known := map[string]bool{}
+	if previous != nil {
+		for _, problem := range previous.Problems {
+			known[problem] = true
+		}
+	}
+	for _, problem := range report.Problems {
+		if !known[problem] {
+			log.Warningf("Unsupported version skew in the cluster: %s", problem)
+		}
+	}
+	versionSkewProblems.Set(int64(len(report.Problems)))
+	versionSkewLastCheck.Set(report.LastCheck.Unix())
+}
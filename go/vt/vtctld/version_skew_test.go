@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestVersionSkewChecker(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, cv := range []*topo.ComponentVersion{
+		{Component: "vttablet", ID: "cell1-0000000100", Version: "15.0.0", UpdatedAt: now},
+		{Component: "vtgate", ID: "vtgate-0:15001", Version: "16.0.0", UpdatedAt: now},
+	} {
+		require.NoError(t, ts.SaveComponentVersion(ctx, cv))
+	}
+
+	checker := &versionSkewChecker{ts: ts}
+	assert.Nil(t, checker.Report())
+
+	checker.check(ctx)
+	report := checker.Report()
+	require.NotNil(t, report)
+	assert.Equal(t, map[string]string{
+		"vttablet/cell1-0000000100": "15.0.0",
+		"vtgate/vtgate-0:15001":     "16.0.0",
+	}, report.Versions)
+	assert.Equal(t, []string{
+		"vtgate vtgate-0:15001 running version 16.0.0 is newer than vttablet cell1-0000000100 running version 15.0.0: the vttablets must be upgraded before the vtgates",
+	}, report.Problems)
+	assert.Equal(t, int64(1), versionSkewProblems.Get())
+	assert.Equal(t, report.LastCheck.Unix(), versionSkewLastCheck.Get())
+
+	// Once the vttablet is upgraded, the problem is cleared.
+	require.NoError(t, ts.SaveComponentVersion(ctx, &topo.ComponentVersion{Component: "vttablet", ID: "cell1-0000000100", Version: "16.0.0", UpdatedAt: now}))
+	checker.check(ctx)
+	assert.Empty(t, checker.Report().Problems)
+	assert.Equal(t, int64(0), versionSkewProblems.Get())
+}
@@ -156,6 +156,11 @@ func InitVtctld(ts *topo.Server) error {
 		}
 	}
 
+	// Init the audit log of the commands received by vtctld.
+	if err := initAuditLog(ts); err != nil {
+		return err
+	}
+
 	// Serve the REST API for the vtctld web app.
 	initAPI(context.Background(), ts, actionRepo, healthCheck)
 
@@ -165,6 +170,23 @@ func InitVtctld(ts *topo.Server) error {
 	// Init workflow manager.
 	initWorkflowManager(ts)
 
+	// Init the periodic schema drift check.
+	initSchemaDrift(ts)
+
+	// Init the periodic version skew check.
+	initVersionSkew(ts)
+
+	// Init the webhooks of the workflow state transitions.
+	if err := initWorkflowHooks(ts); err != nil {
+		return err
+	}
+
+	// Init the scheduler of the workflows created with --start_at.
+	initWorkflowScheduler(ts)
+
+	// Init the runner of the archivals created with CreateArchival.
+	initArchivalRunner(ts)
+
 	// Setup reverse proxy for all vttablets through /vttablet/.
 	initVTTabletRedirection(ts)
 
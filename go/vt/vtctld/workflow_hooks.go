@@ -0,0 +1,378 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	workflowHooksConfig        = flag.String("workflow_hooks_config", "", "Path to a JSON file of webhooks that vtctld calls when a workflow copies all its tables, catches up its lag, switches its traffic or fails. Disabled by default.")
+	workflowHooksCheckInterval = flag.Duration("workflow_hooks_check_interval", 30*time.Second, "Interval at which vtctld checks the state of the workflows for the webhooks of --workflow_hooks_config.")
+	workflowHooksLagThreshold  = flag.Duration("workflow_hooks_lag_threshold", 10*time.Second, "Replication lag under which a workflow is considered caught up by the webhooks of --workflow_hooks_config.")
+)
+
+var (
+	workflowHookEvents         = stats.NewCountersWithSingleLabel("WorkflowHookEvents", "Number of workflow state transitions sent to the webhooks", "Event")
+	workflowHookDeliveryErrors = stats.NewCountersWithSingleLabel("WorkflowHookDeliveryErrors", "Number of workflow events that could not be delivered to a webhook after all the retries", "Event")
+)
+
+// The events of the workflow state transitions.
+const (
+	WorkflowEventCopyComplete    = "copy_complete"
+	WorkflowEventLagCaughtUp     = "lag_caught_up"
+	WorkflowEventTrafficSwitched = "traffic_switched"
+	WorkflowEventError           = "error"
+)
+
+// workflowHookRetryDelay is the delay before the first retry of a failed
+// delivery. It doubles with every retry, and can be lowered by tests.
+var workflowHookRetryDelay = time.Second
+
+// WorkflowHook is a webhook of the --workflow_hooks_config file.
+type WorkflowHook struct {
+	// URL receives the events with POST requests.
+	URL string `json:"url"`
+	// Events are the events sent to the hook, all of them if empty.
+	Events []string `json:"events,omitempty"`
+	// Headers are added to the requests, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Template is a text/template of the JSON payload, executed with a
+	// WorkflowEvent. Its json function quotes a value as JSON. The
+	// payload is the JSON encoding of the WorkflowEvent by default.
+	Template string `json:"template,omitempty"`
+	// Retries is the number of retries of a failed delivery. The default
+	// is 3, and a negative value disables the retries.
+	Retries int `json:"retries,omitempty"`
+
+	tmpl *template.Template
+}
+
+// WorkflowEvent is a state transition of a workflow.
+type WorkflowEvent struct {
+	Event    string    `json:"event"`
+	Keyspace string    `json:"keyspace"`
+	Workflow string    `json:"workflow"`
+	Time     time.Time `json:"time"`
+	// Message is the message of the first stream in error, for the error
+	// events.
+	Message string `json:"message,omitempty"`
+	// MaxVReplicationLag is the replication lag of the workflow, in seconds.
+	MaxVReplicationLag int64 `json:"max_v_replication_lag"`
+}
+
+// workflowLister lists the workflows of a keyspace. It is implemented by
+// grpcvtctldserver.VtctldServer.
+type workflowLister interface {
+	GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (*vtctldatapb.GetWorkflowsResponse, error)
+}
+
+// workflowState is what the hooks track of a workflow between two checks.
+type workflowState struct {
+	copying  bool
+	caughtUp bool
+	frozen   bool
+	errored  bool
+}
+
+// workflowHooks periodically checks the state of the workflows, and sends
+// their state transitions to the webhooks.
+type workflowHooks struct {
+	ts           *topo.Server
+	lister       workflowLister
+	hooks        []*WorkflowHook
+	lagThreshold time.Duration
+	client       *http.Client
+
+	// states are the states of the workflows at the last check, by
+	// keyspace and workflow. They are only used by check.
+	states map[string]map[string]workflowState
+	// wg tracks the deliveries in progress.
+	wg sync.WaitGroup
+}
+
+func newWorkflowHooks(ts *topo.Server, lister workflowLister, hooks []*WorkflowHook, lagThreshold time.Duration) *workflowHooks {
+	return &workflowHooks{
+		ts:           ts,
+		lister:       lister,
+		hooks:        hooks,
+		lagThreshold: lagThreshold,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// loadWorkflowHooks reads and validates the hooks of a config file.
+func loadWorkflowHooks(path string) ([]*WorkflowHook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []*WorkflowHook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("cannot parse workflow hooks config %v: %v", path, err)
+	}
+	for i, hook := range hooks {
+		if hook.URL == "" {
+			return nil, fmt.Errorf("workflow hook %d has no url", i)
+		}
+		for _, event := range hook.Events {
+			switch event {
+			case WorkflowEventCopyComplete, WorkflowEventLagCaughtUp, WorkflowEventTrafficSwitched, WorkflowEventError:
+			default:
+				return nil, fmt.Errorf("workflow hook %d has an unknown event: %v", i, event)
+			}
+		}
+		if hook.Template != "" {
+			hook.tmpl, err = template.New("payload").Funcs(template.FuncMap{
+				"json": func(v any) (string, error) {
+					b, err := json.Marshal(v)
+					return string(b), err
+				},
+			}).Parse(hook.Template)
+			if err != nil {
+				return nil, fmt.Errorf("workflow hook %d has an invalid template: %v", i, err)
+			}
+		}
+		if hook.Retries == 0 {
+			hook.Retries = 3
+		}
+	}
+	return hooks, nil
+}
+
+// initWorkflowHooks starts the workflow hooks if they are configured.
+func initWorkflowHooks(ts *topo.Server) error {
+	if *workflowHooksConfig == "" {
+		return nil
+	}
+	hooks, err := loadWorkflowHooks(*workflowHooksConfig)
+	if err != nil {
+		return err
+	}
+	wh := newWorkflowHooks(ts, grpcvtctldserver.NewVtctldServer(ts), hooks, *workflowHooksLagThreshold)
+	go wh.run(context.Background(), *workflowHooksCheckInterval)
+	return nil
+}
+
+// run checks the workflows at every interval, until ctx is done.
+func (wh *workflowHooks) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		wh.check(ctx, interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check compares the states of the workflows with their states at the last
+// check, and sends the transitions to the hooks. The workflows seen for the
+// first time only have their state recorded, so that a restart of vtctld
+// does not send the events again.
+func (wh *workflowHooks) check(ctx context.Context, timeout time.Duration) {
+	keyspaces, err := wh.ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Failed to get the keyspaces to check their workflows: %v", err)
+		return
+	}
+
+	states := make(map[string]map[string]workflowState, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		workflows, err := wh.getWorkflows(ctx, keyspace, timeout)
+		if err != nil {
+			log.Warningf("Failed to get the workflows of keyspace %v: %v", keyspace, err)
+			// Keep the previous states, to not send their events again.
+			states[keyspace] = wh.states[keyspace]
+			continue
+		}
+		states[keyspace] = make(map[string]workflowState, len(workflows))
+		for _, wf := range workflows {
+			state, message := wh.workflowState(wf)
+			states[keyspace][wf.Name] = state
+			previous, ok := wh.states[keyspace][wf.Name]
+			if !ok {
+				continue
+			}
+			event := &WorkflowEvent{
+				Keyspace:           keyspace,
+				Workflow:           wf.Name,
+				Time:               time.Now(),
+				MaxVReplicationLag: wf.MaxVReplicationLag,
+			}
+			if state.errored && !previous.errored {
+				event.Event, event.Message = WorkflowEventError, message
+				wh.send(*event)
+				event.Message = ""
+			}
+			if previous.copying && !state.copying && !state.errored {
+				event.Event = WorkflowEventCopyComplete
+				wh.send(*event)
+			}
+			if state.caughtUp && !previous.caughtUp {
+				event.Event = WorkflowEventLagCaughtUp
+				wh.send(*event)
+			}
+			if state.frozen && !previous.frozen {
+				event.Event = WorkflowEventTrafficSwitched
+				wh.send(*event)
+			}
+		}
+	}
+	wh.states = states
+}
+
+func (wh *workflowHooks) getWorkflows(ctx context.Context, keyspace string, timeout time.Duration) ([]*vtctldatapb.Workflow, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := wh.lister.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Workflows, nil
+}
+
+// workflowState returns the state of a workflow, and the message of its
+// first stream in error.
+func (wh *workflowHooks) workflowState(wf *vtctldatapb.Workflow) (workflowState, string) {
+	var state workflowState
+	var message string
+	streams, frozen := 0, 0
+	for _, shardStream := range wf.ShardStreams {
+		for _, stream := range shardStream.Streams {
+			streams++
+			if len(stream.CopyStates) > 0 {
+				state.copying = true
+			}
+			if stream.Message == workflow.Frozen {
+				frozen++
+			}
+			if stream.State == "Error" || strings.Contains(strings.ToLower(stream.Message), "error") {
+				if !state.errored {
+					message = stream.Message
+				}
+				state.errored = true
+			}
+		}
+	}
+	state.frozen = streams > 0 && frozen == streams
+	state.caughtUp = !state.copying && !state.errored && !state.frozen &&
+		time.Duration(wf.MaxVReplicationLag)*time.Second <= wh.lagThreshold
+	return state, message
+}
+
+// send delivers an event to the hooks that want it, in the background.
+func (wh *workflowHooks) send(event WorkflowEvent) {
+	workflowHookEvents.Add(event.Event, 1)
+	log.Infof("Workflow %v.%v: %v", event.Keyspace, event.Workflow, event.Event)
+	for _, hook := range wh.hooks {
+		if !hook.wants(event.Event) {
+			continue
+		}
+		wh.wg.Add(1)
+		go func(hook *WorkflowHook) {
+			defer wh.wg.Done()
+			if err := wh.deliver(hook, event); err != nil {
+				workflowHookDeliveryErrors.Add(event.Event, 1)
+				log.Errorf("Failed to send the %v event of workflow %v.%v to %v: %v", event.Event, event.Keyspace, event.Workflow, hook.URL, err)
+			}
+		}(hook)
+	}
+}
+
+func (hook *WorkflowHook) wants(event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver posts the payload of an event to a hook, and retries with a
+// doubling delay when it fails.
+func (wh *workflowHooks) deliver(hook *WorkflowHook, event WorkflowEvent) error {
+	var payload []byte
+	if hook.tmpl != nil {
+		var buf bytes.Buffer
+		if err := hook.tmpl.Execute(&buf, event); err != nil {
+			return err
+		}
+		payload = buf.Bytes()
+	} else {
+		var err error
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	delay := workflowHookRetryDelay
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = wh.post(hook, payload); err == nil {
+			return nil
+		}
+		if attempt >= hook.Retries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (wh *workflowHooks) post(hook *WorkflowHook, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status: %v", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+type fakeWorkflowLister struct {
+	workflows map[string][]*vtctldatapb.Workflow
+}
+
+func (l *fakeWorkflowLister) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (*vtctldatapb.GetWorkflowsResponse, error) {
+	return &vtctldatapb.GetWorkflowsResponse{Workflows: l.workflows[req.Keyspace]}, nil
+}
+
+func testWorkflow(name string, lag int64, streams ...*vtctldatapb.Workflow_Stream) *vtctldatapb.Workflow {
+	return &vtctldatapb.Workflow{
+		Name:               name,
+		MaxVReplicationLag: lag,
+		ShardStreams: map[string]*vtctldatapb.Workflow_ShardStream{
+			"-80/zone1-0000000100": {Streams: streams},
+		},
+	}
+}
+
+func TestWorkflowHooks(t *testing.T) {
+	defer func(saved time.Duration) { workflowHookRetryDelay = saved }(workflowHookRetryDelay)
+	workflowHookRetryDelay = time.Millisecond
+
+	var mu sync.Mutex
+	var allEvents []WorkflowEvent
+	var errorPayloads []string
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		switch r.URL.Path {
+		case "/all":
+			var event WorkflowEvent
+			require.NoError(t, json.Unmarshal(body, &event))
+			allEvents = append(allEvents, event)
+		case "/errors":
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			errorPayloads = append(errorPayloads, string(body))
+		}
+	}))
+	defer server.Close()
+
+	configPath := path.Join(t.TempDir(), "hooks.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`[{
+		"url": "`+server.URL+`/all"
+	}, {
+		"url": "`+server.URL+`/errors",
+		"events": ["error"],
+		"headers": {"Authorization": "Bearer token"},
+		"template": "{\"text\": {{json (printf \"%s.%s failed: %s\" .Keyspace .Workflow .Message)}}}"
+	}]`), 0600))
+	hooks, err := loadWorkflowHooks(configPath)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	lister := &fakeWorkflowLister{}
+	wh := newWorkflowHooks(ts, lister, hooks, 10*time.Second)
+	check := func(workflows ...*vtctldatapb.Workflow) {
+		lister.workflows = map[string][]*vtctldatapb.Workflow{"ks": workflows}
+		wh.check(ctx, time.Second)
+		wh.wg.Wait()
+	}
+	copying := &vtctldatapb.Workflow_Stream{State: "Running", CopyStates: []*vtctldatapb.Workflow_Stream_CopyState{{Table: "t1"}}}
+	running := &vtctldatapb.Workflow_Stream{State: "Running"}
+	frozen := &vtctldatapb.Workflow_Stream{State: "Stopped", Message: "FROZEN"}
+	failed := &vtctldatapb.Workflow_Stream{State: "Error", Message: "Duplicate entry '1' for key 'PRIMARY'"}
+
+	// The workflows seen for the first time have no event.
+	check(testWorkflow("wf1", 100, copying), testWorkflow("wf2", 0, running))
+	check(testWorkflow("wf1", 100, running), testWorkflow("wf2", 0, running))
+	check(testWorkflow("wf1", 5, running), testWorkflow("wf2", 0, failed))
+	check(testWorkflow("wf1", 5, frozen), testWorkflow("wf2", 0, failed))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var got []string
+	for _, event := range allEvents {
+		got = append(got, event.Workflow+" "+event.Event)
+		assert.Equal(t, "ks", event.Keyspace)
+	}
+	assert.ElementsMatch(t, []string{
+		"wf1 copy_complete",
+		"wf1 lag_caught_up",
+		"wf2 error",
+		"wf1 traffic_switched",
+	}, got)
+	assert.Equal(t, []string{`{"text": "ks.wf2 failed: Duplicate entry '1' for key 'PRIMARY'"}`}, errorPayloads)
+	assert.Equal(t, int64(1), workflowHookEvents.Counts()[WorkflowEventError])
+}
+
+func TestLoadWorkflowHooks(t *testing.T) {
+	dir := t.TempDir()
+	for _, tcase := range []struct {
+		config string
+		err    string
+	}{{
+		config: `[{"url": "http://localhost/hook", "events": ["copy_complete"]}]`,
+	}, {
+		config: `{"url": "http://localhost/hook"}`,
+		err:    "cannot parse workflow hooks config",
+	}, {
+		config: `[{"events": ["error"]}]`,
+		err:    "workflow hook 0 has no url",
+	}, {
+		config: `[{"url": "http://localhost/hook", "events": ["started"]}]`,
+		err:    "workflow hook 0 has an unknown event: started",
+	}, {
+		config: `[{"url": "http://localhost/hook", "template": "{{.Event"}]`,
+		err:    "workflow hook 0 has an invalid template",
+	}} {
+		configPath := path.Join(dir, "hooks.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(tcase.config), 0600))
+		hooks, err := loadWorkflowHooks(configPath)
+		if tcase.err != "" {
+			assert.ErrorContains(t, err, tcase.err, tcase.config)
+			continue
+		}
+		require.NoError(t, err, tcase.config)
+		require.Len(t, hooks, 1)
+		assert.Equal(t, 3, hooks[0].Retries)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+	"vitess.io/vitess/go/vt/wrangler"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var workflowSchedulerCheckInterval = flag.Duration("workflow_scheduler_check_interval", time.Minute, "Interval at which vtctld starts the workflows created with MoveTables or Reshard --start_at whose start time passed. 0 disables the scheduler.")
+
+var (
+	scheduledWorkflows      = stats.NewGaugesWithSingleLabel("ScheduledWorkflows", "Number of workflows waiting for their scheduled start", "Keyspace")
+	scheduledWorkflowStarts = stats.NewCountersWithSingleLabel("ScheduledWorkflowStarts", "Number of scheduled workflows started by vtctld", "Keyspace")
+)
+
+// workflowStarter starts the streams of a workflow. It is implemented by
+// wrangler.Wrangler.
+type workflowStarter interface {
+	WorkflowAction(ctx context.Context, workflow, keyspace, action string, dryRun bool) (map[*topo.TabletInfo]*sqltypes.Result, error)
+}
+
+// workflowScheduler periodically starts the workflows whose scheduled start
+// time passed.
+type workflowScheduler struct {
+	ts      *topo.Server
+	lister  workflowLister
+	starter workflowStarter
+}
+
+func newWorkflowScheduler(ts *topo.Server, lister workflowLister, starter workflowStarter) *workflowScheduler {
+	return &workflowScheduler{
+		ts:      ts,
+		lister:  lister,
+		starter: starter,
+	}
+}
+
+// initWorkflowScheduler starts the workflow scheduler if it is enabled.
+func initWorkflowScheduler(ts *topo.Server) {
+	if *workflowSchedulerCheckInterval <= 0 {
+		return
+	}
+	wr := wrangler.New(logutil.NewConsoleLogger(), ts, tmclient.NewTabletManagerClient())
+	scheduler := newWorkflowScheduler(ts, grpcvtctldserver.NewVtctldServer(ts), wr)
+	go scheduler.run(context.Background(), *workflowSchedulerCheckInterval)
+}
+
+// run checks the scheduled workflows at every interval, until ctx is done.
+func (s *workflowScheduler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.check(ctx, interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check starts the scheduled workflows of every keyspace whose start time
+// passed, and counts the ones that are still waiting.
+func (s *workflowScheduler) check(ctx context.Context, timeout time.Duration) {
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Failed to get the keyspaces to check their scheduled workflows: %v", err)
+		return
+	}
+	for _, keyspace := range keyspaces {
+		s.checkKeyspace(ctx, keyspace, timeout)
+	}
+}
+
+func (s *workflowScheduler) checkKeyspace(ctx context.Context, keyspace string, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := s.lister.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+	if err != nil {
+		log.Warningf("Failed to get the workflows of keyspace %v: %v", keyspace, err)
+		return
+	}
+	now := time.Now()
+	waiting := 0
+	for _, wf := range resp.Workflows {
+		startAt, ok := scheduledStartTime(wf)
+		if !ok {
+			continue
+		}
+		if startAt.After(now) {
+			waiting++
+			continue
+		}
+		log.Infof("Starting workflow %v.%v, scheduled to start at %v", keyspace, wf.Name, startAt)
+		if _, err := s.starter.WorkflowAction(ctx, wf.Name, keyspace, "start", false); err != nil {
+			// It is retried at the next check.
+			log.Errorf("Failed to start the scheduled workflow %v.%v: %v", keyspace, wf.Name, err)
+			waiting++
+			continue
+		}
+		scheduledWorkflowStarts.Add(keyspace, 1)
+	}
+	scheduledWorkflows.Set(keyspace, int64(waiting))
+}
+
+// scheduledStartTime returns the earliest scheduled start time of the stopped
+// streams of a workflow, and false if none of them is scheduled.
+func scheduledStartTime(wf *vtctldatapb.Workflow) (time.Time, bool) {
+	var earliest time.Time
+	for _, shardStream := range wf.ShardStreams {
+		for _, stream := range shardStream.Streams {
+			if stream.State != "Stopped" {
+				continue
+			}
+			startAt, ok := workflow.ScheduledStartTime(stream.Message)
+			if ok && (earliest.IsZero() || startAt.Before(earliest)) {
+				earliest = startAt
+			}
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
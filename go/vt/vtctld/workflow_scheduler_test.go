@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+type fakeWorkflowStarter struct {
+	started []string
+}
+
+func (s *fakeWorkflowStarter) WorkflowAction(ctx context.Context, workflow, keyspace, action string, dryRun bool) (map[*topo.TabletInfo]*sqltypes.Result, error) {
+	s.started = append(s.started, keyspace+"."+workflow+" "+action)
+	return nil, nil
+}
+
+func TestWorkflowScheduler(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	past := &vtctldatapb.Workflow_Stream{State: "Stopped", Message: workflow.ScheduledMessage(time.Now().Add(-time.Minute))}
+	future := &vtctldatapb.Workflow_Stream{State: "Stopped", Message: workflow.ScheduledMessage(time.Now().Add(time.Hour))}
+	stopped := &vtctldatapb.Workflow_Stream{State: "Stopped"}
+	// A workflow started by hand before its scheduled time.
+	running := &vtctldatapb.Workflow_Stream{State: "Running", Message: past.Message}
+	lister := &fakeWorkflowLister{workflows: map[string][]*vtctldatapb.Workflow{
+		"ks": {
+			testWorkflow("wf1", 0, past),
+			testWorkflow("wf2", 0, future),
+			testWorkflow("wf3", 0, stopped),
+			testWorkflow("wf4", 0, running),
+		},
+	}}
+	starter := &fakeWorkflowStarter{}
+	scheduler := newWorkflowScheduler(ts, lister, starter)
+	scheduler.check(ctx, time.Second)
+
+	assert.Equal(t, []string{"ks.wf1 start"}, starter.started)
+	assert.Equal(t, int64(1), scheduledWorkflows.Counts()["ks"])
+	assert.Equal(t, int64(1), scheduledWorkflowStarts.Counts()["ks"])
+}
+
+func TestScheduledStartTime(t *testing.T) {
+	early := time.Date(2022, 9, 1, 2, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+	wf := &vtctldatapb.Workflow{
+		ShardStreams: map[string]*vtctldatapb.Workflow_ShardStream{
+			"-80/zone1-0000000100": {Streams: []*vtctldatapb.Workflow_Stream{{State: "Stopped", Message: workflow.ScheduledMessage(late)}}},
+			"80-/zone1-0000000200": {Streams: []*vtctldatapb.Workflow_Stream{{State: "Stopped", Message: workflow.ScheduledMessage(early)}}},
+		},
+	}
+	startAt, ok := scheduledStartTime(wf)
+	require.True(t, ok)
+	assert.True(t, early.Equal(startAt))
+
+	_, ok = scheduledStartTime(testWorkflow("wf", 0, &vtctldatapb.Workflow_Stream{State: "Stopped", Message: workflow.Frozen}))
+	assert.False(t, ok)
+}
@@ -51,6 +51,8 @@ const (
 	CantDoThisInTransaction
 	RequiresPrimaryKey
 	OperandColumns
+	RowIsReferenced2
+	NoReferencedRow2
 
 	// not found
 	BadDb
@@ -66,6 +68,7 @@ const (
 
 	// resource exhausted
 	NetPacketTooLarge
+	CapacityExceeded
 
 	// cancelled
 	QueryInterrupted
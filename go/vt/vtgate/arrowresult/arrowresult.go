@@ -0,0 +1,252 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package arrowresult encodes the fields and rows of query results as Arrow
+// IPC streams, for the ARROW result format of the vtgate gRPC API.
+//
+// Each field is a column of the Arrow schema. The integer, float and binary
+// MySQL types, including BIT and GEOMETRY, are encoded as Arrow integers,
+// floats and binaries, and the other types as strings holding their MySQL
+// text representation. The
+// metadata of each column holds its MySQL type under "vitess.type", and
+// the whole query.Field, in base64 of the protobuf encoding, under
+// "vitess.field".
+package arrowresult
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/apache/arrow/go/v7/arrow"
+	"github.com/apache/arrow/go/v7/arrow/array"
+	"github.com/apache/arrow/go/v7/arrow/ipc"
+	"github.com/apache/arrow/go/v7/arrow/memory"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// TypeMetadataKey is the key of the MySQL type in the metadata of the
+	// columns.
+	TypeMetadataKey = "vitess.type"
+	// FieldMetadataKey is the key of the query.Field in the metadata of the
+	// columns.
+	FieldMetadataKey = "vitess.field"
+)
+
+// Encode returns an Arrow IPC stream holding fields and rows. The stream
+// holds only the schema if there are no rows.
+func Encode(fields []*querypb.Field, rows []sqltypes.Row) ([]byte, error) {
+	schema, err := toSchema(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	w := ipc.NewWriter(buf, ipc.WithSchema(schema))
+	if len(rows) > 0 {
+		rec, err := toRecord(schema, fields, rows)
+		if err != nil {
+			return nil, err
+		}
+		defer rec.Release()
+		if err := w.Write(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode returns the fields and rows of an Arrow IPC stream written by
+// Encode. The floats are formatted in their shortest representation, which
+// may differ from the one of MySQL.
+func Decode(data []byte) ([]*querypb.Field, []sqltypes.Row, error) {
+	r, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "cannot read the Arrow result")
+	}
+	defer r.Release()
+
+	fields, err := fromSchema(r.Schema())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []sqltypes.Row
+	for r.Next() {
+		rec := r.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			row := make(sqltypes.Row, len(fields))
+			for j, col := range rec.Columns() {
+				v, err := fromArray(col, i, fields[j].Type)
+				if err != nil {
+					return nil, nil, err
+				}
+				row[j] = v
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "cannot read the Arrow result")
+	}
+	return fields, rows, nil
+}
+
+// arrowType returns the type of the column of a MySQL type.
+func arrowType(typ querypb.Type) arrow.DataType {
+	switch {
+	case sqltypes.IsSigned(typ):
+		return arrow.PrimitiveTypes.Int64
+	case sqltypes.IsUnsigned(typ):
+		return arrow.PrimitiveTypes.Uint64
+	case typ == sqltypes.Float32:
+		return arrow.PrimitiveTypes.Float32
+	case typ == sqltypes.Float64:
+		return arrow.PrimitiveTypes.Float64
+	case sqltypes.IsBinary(typ), typ == sqltypes.Bit, typ == sqltypes.Geometry:
+		return arrow.BinaryTypes.Binary
+	}
+	return arrow.BinaryTypes.String
+}
+
+func toSchema(fields []*querypb.Field) (*arrow.Schema, error) {
+	arrowFields := make([]arrow.Field, len(fields))
+	for i, field := range fields {
+		data, err := proto.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		arrowFields[i] = arrow.Field{
+			Name:     field.Name,
+			Type:     arrowType(field.Type),
+			Nullable: true,
+			Metadata: arrow.NewMetadata(
+				[]string{TypeMetadataKey, FieldMetadataKey},
+				[]string{field.Type.String(), base64.StdEncoding.EncodeToString(data)},
+			),
+		}
+	}
+	return arrow.NewSchema(arrowFields, nil), nil
+}
+
+func fromSchema(schema *arrow.Schema) ([]*querypb.Field, error) {
+	fields := make([]*querypb.Field, len(schema.Fields()))
+	for i, arrowField := range schema.Fields() {
+		idx := arrowField.Metadata.FindKey(FieldMetadataKey)
+		if idx < 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "column %s of the Arrow result has no %s metadata", arrowField.Name, FieldMetadataKey)
+		}
+		data, err := base64.StdEncoding.DecodeString(arrowField.Metadata.Values()[idx])
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot decode the field of column %s", arrowField.Name)
+		}
+		field := &querypb.Field{}
+		if err := proto.Unmarshal(data, field); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot decode the field of column %s", arrowField.Name)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+func toRecord(schema *arrow.Schema, fields []*querypb.Field, rows []sqltypes.Row) (arrow.Record, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	for _, row := range rows {
+		for i, v := range row {
+			if v.IsNull() {
+				b.Field(i).AppendNull()
+				continue
+			}
+			if err := appendValue(b.Field(i), v); err != nil {
+				return nil, vterrors.Wrapf(err, "column %s", fields[i].Name)
+			}
+		}
+	}
+	return b.NewRecord(), nil
+}
+
+func appendValue(b array.Builder, v sqltypes.Value) error {
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		i, err := strconv.ParseInt(v.ToString(), 10, 64)
+		if err != nil {
+			return err
+		}
+		b.Append(i)
+	case *array.Uint64Builder:
+		u, err := strconv.ParseUint(v.ToString(), 10, 64)
+		if err != nil {
+			return err
+		}
+		b.Append(u)
+	case *array.Float32Builder:
+		f, err := strconv.ParseFloat(v.ToString(), 32)
+		if err != nil {
+			return err
+		}
+		b.Append(float32(f))
+	case *array.Float64Builder:
+		f, err := strconv.ParseFloat(v.ToString(), 64)
+		if err != nil {
+			return err
+		}
+		b.Append(f)
+	case *array.BinaryBuilder:
+		b.Append(v.Raw())
+	case *array.StringBuilder:
+		b.Append(v.ToString())
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected Arrow builder %T", b)
+	}
+	return nil
+}
+
+func fromArray(col arrow.Array, i int, typ querypb.Type) (sqltypes.Value, error) {
+	if col.IsNull(i) {
+		return sqltypes.NULL, nil
+	}
+
+	var raw []byte
+	switch col := col.(type) {
+	case *array.Int64:
+		raw = strconv.AppendInt(nil, col.Value(i), 10)
+	case *array.Uint64:
+		raw = strconv.AppendUint(nil, col.Value(i), 10)
+	case *array.Float32:
+		raw = strconv.AppendFloat(nil, float64(col.Value(i)), 'g', -1, 32)
+	case *array.Float64:
+		raw = strconv.AppendFloat(nil, col.Value(i), 'g', -1, 64)
+	case *array.Binary:
+		raw = append([]byte{}, col.Value(i)...)
+	case *array.String:
+		raw = []byte(col.Value(i))
+	default:
+		return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported Arrow type %v", col.DataType())
+	}
+	return sqltypes.MakeTrusted(typ, raw), nil
+}
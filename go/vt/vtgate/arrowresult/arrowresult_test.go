@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package arrowresult
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/v7/arrow"
+	"github.com/apache/arrow/go/v7/arrow/array"
+	"github.com/apache/arrow/go/v7/arrow/ipc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	fields := []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64, Table: "t1", ColumnLength: 20},
+		{Name: "u", Type: sqltypes.Uint32},
+		{Name: "f", Type: sqltypes.Float64},
+		{Name: "d", Type: sqltypes.Decimal},
+		{Name: "name", Type: sqltypes.VarChar, Charset: 255},
+		{Name: "data", Type: sqltypes.Blob},
+		{Name: "created", Type: sqltypes.Datetime},
+	}
+	rows := []sqltypes.Row{
+		{
+			sqltypes.NewInt64(-1),
+			sqltypes.NewUint32(4294967295),
+			sqltypes.NewFloat64(1.5),
+			sqltypes.MakeTrusted(sqltypes.Decimal, []byte("1.10")),
+			sqltypes.NewVarChar("a"),
+			sqltypes.MakeTrusted(sqltypes.Blob, []byte{0, 255}),
+			sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2022-06-01 10:00:00")),
+		},
+		{sqltypes.NewInt64(2), sqltypes.NULL, sqltypes.NULL, sqltypes.NULL, sqltypes.NULL, sqltypes.NULL, sqltypes.NULL},
+	}
+
+	data, err := Encode(fields, rows)
+	require.NoError(t, err)
+	gotFields, gotRows, err := Decode(data)
+	require.NoError(t, err)
+	assert.True(t, sqltypes.FieldsEqual(fields, gotFields), "fields: %v", gotFields)
+	assert.Equal(t, rows, gotRows)
+
+	// The columns have the Arrow type of their MySQL type.
+	r, err := ipc.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer r.Release()
+	wantTypes := []arrow.DataType{
+		arrow.PrimitiveTypes.Int64,
+		arrow.PrimitiveTypes.Uint64,
+		arrow.PrimitiveTypes.Float64,
+		arrow.BinaryTypes.String,
+		arrow.BinaryTypes.String,
+		arrow.BinaryTypes.Binary,
+		arrow.BinaryTypes.String,
+	}
+	for i, field := range r.Schema().Fields() {
+		assert.Equal(t, wantTypes[i], field.Type, field.Name)
+	}
+	assert.Equal(t, "UINT32", r.Schema().Field(1).Metadata.Values()[r.Schema().Field(1).Metadata.FindKey(TypeMetadataKey)])
+	require.True(t, r.Next())
+	assert.Equal(t, []int64{-1, 2}, r.Record().Column(0).(*array.Int64).Int64Values())
+}
+
+func TestEncodeFieldsOnly(t *testing.T) {
+	fields := []*querypb.Field{{Name: "id", Type: sqltypes.Int64}}
+	data, err := Encode(fields, nil)
+	require.NoError(t, err)
+	gotFields, gotRows, err := Decode(data)
+	require.NoError(t, err)
+	assert.True(t, sqltypes.FieldsEqual(fields, gotFields))
+	assert.Empty(t, gotRows)
+}
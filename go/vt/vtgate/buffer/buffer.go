@@ -20,6 +20,10 @@ limitations under the License.
 // becomes unavailable), the buffer will automatically retry buffered requests
 // after the end of the failover was detected.
 //
+// The buffer also stalls the requests to the shards whose writes are switched
+// by a Reshard or MoveTables workflow, from the moment the workflow signals the
+// start of the switch in the topology until it signals its end.
+//
 // Buffering (stalling) requests will increase the number of requests in flight
 // within vtgate and at upstream layers. Therefore, it is important to limit
 // the size of the buffer and the buffering duration (window) per request.
@@ -35,6 +39,7 @@ import (
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 
@@ -43,6 +48,7 @@ import (
 
 var (
 	ShardMissingError    = vterrors.New(vtrpcpb.Code_UNAVAILABLE, "destination shard is missing after a resharding operation")
+	TablesSwitchedError  = vterrors.New(vtrpcpb.Code_UNAVAILABLE, "tables were switched to another keyspace by a MoveTables operation")
 	bufferFullError      = vterrors.New(vtrpcpb.Code_UNAVAILABLE, "primary buffer is full")
 	entryEvictedError    = vterrors.New(vtrpcpb.Code_UNAVAILABLE, "buffer full: request evicted for newer request")
 	contextCanceledError = vterrors.New(vtrpcpb.Code_UNAVAILABLE, "context was canceled before failover finished")
@@ -102,6 +108,11 @@ type Buffer struct {
 	buffers map[string]*shardBuffer
 	// stopped is true after Shutdown() was run.
 	stopped bool
+
+	// trafficSwitches holds the ongoing traffic switches, by keyspace. It is
+	// only accessed by HandleTrafficSwitches, which is not called
+	// concurrently.
+	trafficSwitches map[string]*topo.TrafficSwitch
 }
 
 // New creates a new Buffer object.
@@ -110,6 +121,8 @@ func New(cfg *Config) *Buffer {
 		config:         cfg,
 		bufferSizeSema: sync2.NewSemaphore(cfg.Size, 0),
 		buffers:        make(map[string]*shardBuffer),
+
+		trafficSwitches: make(map[string]*topo.TrafficSwitch),
 	}
 }
 
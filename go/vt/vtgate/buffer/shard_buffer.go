@@ -28,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -110,6 +111,10 @@ type shardBuffer struct {
 	lastReparent time.Time
 	// currentPrimary is tracked to determine when to update "lastReparent".
 	currentPrimary *topodatapb.TabletAlias
+	// trafficSwitch is true while a workflow switches the writes away from
+	// this shard. Buffering then only stops at the end of the switch, and
+	// not when a new primary or a keyspace event is seen.
+	trafficSwitch bool
 	// timeoutThread will be set while a failover is in progress and the object is
 	// in the BUFFERING state.
 	timeoutThread *timeoutThread
@@ -488,6 +493,9 @@ func (sb *shardBuffer) recordKeyspaceEvent(alias *topodatapb.TabletAlias, stillS
 		}
 		sb.currentPrimary = alias
 	}
+	if sb.trafficSwitch {
+		return
+	}
 	if stillServing {
 		sb.stopBufferingLocked(stopFailoverEndDetected, "a primary promotion has been detected")
 	} else {
@@ -523,13 +531,56 @@ func (sb *shardBuffer) recordExternallyReparentedTimestamp(timestamp int64, alia
 		}
 		sb.currentPrimary = alias
 	}
+	if sb.trafficSwitch {
+		return
+	}
 	sb.stopBufferingLocked(stopFailoverEndDetected, "failover end detected")
 }
 
+// startTrafficSwitch starts buffering all requests to the shard, because a
+// workflow started switching its writes away.
+func (sb *shardBuffer) startTrafficSwitch(workflow string) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	sb.trafficSwitch = true
+	switch sb.state {
+	case stateIdle:
+		sb.startBufferingLocked(vterrors.Errorf(vtrpcpb.Code_CLUSTER_EVENT, "workflow %s is switching writes", workflow))
+	case stateDraining:
+		// The previous buffering is not drained yet. Requests will be
+		// buffered again once they fail because of the traffic switch.
+		log.Warningf("NOT starting buffering for shard: %s for the traffic switch of workflow %s because the buffer is still draining",
+			topoproto.KeyspaceShardString(sb.keyspace, sb.shard), workflow)
+	}
+}
+
+// stopTrafficSwitch stops buffering at the end of a traffic switch.
+// If the switch succeeded, buffered requests fail with an error which makes
+// vtgate route them again.
+func (sb *shardBuffer) stopTrafficSwitch(workflow string, switched, movesTables bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	sb.trafficSwitch = false
+	switch {
+	case !switched:
+		sb.stopBufferingLocked(stopTrafficSwitchCanceled, fmt.Sprintf("the traffic switch of workflow %s was canceled", workflow))
+	case movesTables:
+		sb.stopBufferingLocked(stopTablesSwitched, fmt.Sprintf("workflow %s switched the tables to another keyspace", workflow))
+	default:
+		sb.stopBufferingLocked(stopShardsSwitched, fmt.Sprintf("workflow %s switched the writes to the new shards", workflow))
+	}
+}
+
 func (sb *shardBuffer) stopBufferingDueToMaxDuration() {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
 
+	// Let later failovers be detected as usual, even if the end of the
+	// traffic switch was missed.
+	sb.trafficSwitch = false
+
 	sb.stopBufferingLocked(stopMaxFailoverDurationExceeded,
 		fmt.Sprintf("stopping buffering because failover did not finish in time (%v)", sb.buf.config.MaxFailoverDuration))
 }
@@ -572,8 +623,11 @@ func (sb *shardBuffer) stopBufferingLocked(reason stopReason, details string) {
 	log.Infof("%v for shard: %s after: %.1f seconds due to: %v. Draining %d buffered requests now.", msg, topoproto.KeyspaceShardString(sb.keyspace, sb.shard), d.Seconds(), details, len(q))
 
 	var clientEntryError error
-	if reason == stopShardMissing {
+	switch reason {
+	case stopShardMissing, stopShardsSwitched:
 		clientEntryError = ShardMissingError
+	case stopTablesSwitched:
+		clientEntryError = TablesSwitchedError
 	}
 
 	// Start the drain. (Use a new Go routine to release the lock.)
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buffer
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// trafficSwitchesRetryDelay is how long WatchTrafficSwitches waits before
+// watching the traffic switches again, after the watch failed or because no
+// traffic switch was recorded in the cell yet.
+var trafficSwitchesRetryDelay = 5 * time.Second
+
+// WatchTrafficSwitches watches the traffic switches recorded by workflows in
+// the cell, and buffers the requests to the shards whose writes are being
+// switched, until ctx is canceled.
+func (b *Buffer) WatchTrafficSwitches(ctx context.Context, ts *topo.Server, cell string) {
+	go func() {
+		for {
+			current, changes, cancel := ts.WatchTrafficSwitches(ctx, cell)
+			if current.Err != nil {
+				if !topo.IsErrType(current.Err, topo.NoNode) {
+					log.Warningf("failed to watch the traffic switches of cell %s: %v", cell, current.Err)
+				}
+			} else {
+				b.HandleTrafficSwitches(current.Value)
+				for change := range changes {
+					if change.Err != nil {
+						if ctx.Err() == nil {
+							log.Warningf("watch of the traffic switches of cell %s failed: %v", cell, change.Err)
+						}
+						break
+					}
+					b.HandleTrafficSwitches(change.Value)
+				}
+				cancel()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(trafficSwitchesRetryDelay):
+			}
+		}
+	}()
+}
+
+// HandleTrafficSwitches starts buffering the requests to the shards of the
+// traffic switches that started, and stops buffering for the ones that are
+// over.
+func (b *Buffer) HandleTrafficSwitches(switches topo.TrafficSwitches) {
+	for keyspace, sw := range b.trafficSwitches {
+		if current, ok := switches[keyspace]; !ok || !current.Started.Equal(sw.Started) {
+			// The switch we were buffering for was replaced before we saw its
+			// end. Let the buffered requests be retried on the source.
+			b.stopTrafficSwitch(keyspace, sw, false)
+		}
+	}
+
+	for keyspace, sw := range switches {
+		ongoing, ok := b.trafficSwitches[keyspace]
+		switch {
+		case sw.Done && ok && ongoing.Started.Equal(sw.Started):
+			b.stopTrafficSwitch(keyspace, sw, sw.Switched)
+		case !sw.Done && (!ok || !ongoing.Started.Equal(sw.Started)):
+			b.startTrafficSwitch(keyspace, sw)
+		}
+	}
+}
+
+func (b *Buffer) startTrafficSwitch(keyspace string, sw *topo.TrafficSwitch) {
+	log.Infof("workflow %s started switching writes of keyspace %s, shards: %v", sw.Workflow, keyspace, sw.Shards)
+	b.trafficSwitches[keyspace] = sw
+	for _, shard := range sw.Shards {
+		if sb := b.getOrCreateBuffer(keyspace, shard); sb != nil && !sb.disabled() {
+			sb.startTrafficSwitch(sw.Workflow)
+		}
+	}
+}
+
+func (b *Buffer) stopTrafficSwitch(keyspace string, sw *topo.TrafficSwitch, switched bool) {
+	log.Infof("workflow %s finished switching writes of keyspace %s (switched: %v)", sw.Workflow, keyspace, switched)
+	delete(b.trafficSwitches, keyspace)
+	for _, shard := range sw.Shards {
+		if sb := b.getOrCreateBuffer(keyspace, shard); sb != nil && !sb.disabled() {
+			sb.stopTrafficSwitch(sw.Workflow, switched, sw.MovesTables)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	"vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// issueRequestWithoutError simulates a request which did not fail yet, and
+// returns the error of the buffering once it stopped.
+func issueRequestWithoutError(b *Buffer) chan error {
+	bufferingStopped := make(chan error, 1)
+	go func() {
+		retryDone, err := b.WaitForFailoverEnd(context.Background(), keyspace, shard, nil)
+		if retryDone != nil {
+			retryDone()
+		}
+		bufferingStopped <- err
+	}()
+	return bufferingStopped
+}
+
+func TestTrafficSwitch(t *testing.T) {
+	started := time.Now()
+	tests := []struct {
+		name     string
+		end      *topo.TrafficSwitch
+		wantErr  error
+		wantStop stopReason
+	}{
+		{
+			name:     "reshard",
+			end:      &topo.TrafficSwitch{Workflow: "wf", Shards: []string{shard}, Started: started, Done: true, Switched: true},
+			wantErr:  ShardMissingError,
+			wantStop: stopShardsSwitched,
+		},
+		{
+			name:     "move tables",
+			end:      &topo.TrafficSwitch{Workflow: "wf", Shards: []string{shard}, MovesTables: true, Started: started, Done: true, Switched: true},
+			wantErr:  TablesSwitchedError,
+			wantStop: stopTablesSwitched,
+		},
+		{
+			name:     "canceled",
+			end:      &topo.TrafficSwitch{Workflow: "wf", Shards: []string{shard}, Started: started, Done: true},
+			wantStop: stopTrafficSwitchCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetVariables()
+			defer checkVariables(t)
+
+			cfg := NewDefaultConfig()
+			cfg.Enabled = true
+			b := New(cfg)
+			defer b.Shutdown()
+
+			// Buffering starts with the traffic switch, before any request fails.
+			b.HandleTrafficSwitches(topo.TrafficSwitches{
+				keyspace: {Workflow: "wf", Shards: []string{shard}, MovesTables: tt.end.MovesTables, Started: started},
+			})
+			require.NoError(t, waitForState(b, stateBuffering))
+
+			stopped := issueRequestWithoutError(b)
+			require.NoError(t, waitForRequestsInFlight(b, 1))
+
+			// The new primary of the shard does not stop buffering while the
+			// traffic is being switched.
+			b.HandleKeyspaceEvent(&discovery.KeyspaceEvent{
+				Keyspace: keyspace,
+				Shards: []discovery.ShardEvent{{
+					Tablet:  newPrimary.Alias,
+					Target:  &query.Target{Keyspace: keyspace, Shard: shard, TabletType: topodatapb.TabletType_PRIMARY},
+					Serving: true,
+				}},
+			})
+			require.NoError(t, waitForRequestsInFlight(b, 1))
+
+			b.HandleTrafficSwitches(topo.TrafficSwitches{keyspace: tt.end})
+			err := <-stopped
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, vterrors.RootCause(err))
+			}
+			assert.EqualValues(t, 1, stops.Counts()[statsKeyJoined+"."+string(tt.wantStop)])
+			require.NoError(t, waitForState(b, stateIdle))
+
+			// Seeing the same switch again does not start buffering again.
+			b.HandleTrafficSwitches(topo.TrafficSwitches{keyspace: tt.end})
+			assert.Equal(t, stateIdle, b.getOrCreateBuffer(keyspace, shard).testGetState())
+		})
+	}
+}
+
+func TestTrafficSwitchDisabled(t *testing.T) {
+	resetVariables()
+
+	b := New(NewDefaultConfig())
+	defer b.Shutdown()
+
+	b.HandleTrafficSwitches(topo.TrafficSwitches{
+		keyspace: {Workflow: "wf", Shards: []string{shard}, Started: time.Now()},
+	})
+	assert.Equal(t, stateIdle, b.getOrCreateBuffer(keyspace, shard).testGetState())
+}
@@ -105,13 +105,16 @@ var (
 // stopReason is used in "stopsByReason" as "Reason" label.
 type stopReason string
 
-var stopReasons = []stopReason{stopShardMissing, stopFailoverEndDetected, stopMaxFailoverDurationExceeded, stopShutdown}
+var stopReasons = []stopReason{stopShardMissing, stopFailoverEndDetected, stopMaxFailoverDurationExceeded, stopShutdown, stopShardsSwitched, stopTablesSwitched, stopTrafficSwitchCanceled}
 
 const (
 	stopShardMissing                stopReason = "ReshardingComplete"
 	stopFailoverEndDetected         stopReason = "NewPrimarySeen"
 	stopMaxFailoverDurationExceeded stopReason = "MaxDurationExceeded"
 	stopShutdown                    stopReason = "Shutdown"
+	stopShardsSwitched              stopReason = "ShardsSwitched"
+	stopTablesSwitched              stopReason = "TablesSwitched"
+	stopTrafficSwitchCanceled       stopReason = "TrafficSwitchCanceled"
 )
 
 // evictedReason is used in "requestsEvicted" as "Reason" label.
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"flag"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/callinfo"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	mysqlServerIdleTimeout            = flag.Duration("mysql_server_idle_timeout", 0, "Close the MySQL connections that are idle for longer than this, after sending them an ER_CLIENT_INTERACTION_TIMEOUT error, like the wait_timeout of MySQL. Their open transactions are rolled back. 0 disables it")
+	mysqlServerTransactionIdleTimeout = flag.Duration("mysql_server_transaction_idle_timeout", 0, "Roll back the open transactions of the MySQL connections that are idle for longer than this, to release the resources they hold on the tablets. The next command of the connection then fails with an error. 0 disables it")
+
+	mysqlServerReaped = stats.NewCountersWithSingleLabel("MysqlServerReaped", "Number of idle MySQL connections closed and of idle transactions rolled back by vtgate, by cause", "Cause")
+)
+
+const (
+	// reapedIdleConnection and reapedIdleTransaction are the causes of the
+	// MysqlServerReaped metric.
+	reapedIdleConnection  = "IdleConnection"
+	reapedIdleTransaction = "IdleTransaction"
+
+	// maxReapInterval is the maximum interval between two checks of the
+	// idle connections.
+	maxReapInterval = time.Second
+	// reapRollbackTimeout is the timeout of the rollback of an idle
+	// transaction.
+	reapRollbackTimeout = 30 * time.Second
+)
+
+// connActivity is the activity of a MySQL connection, for the reaper.
+type connActivity struct {
+	// busy is set while the connection executes a command.
+	busy bool
+	// reaping is set while the reaper closes the connection or rolls back
+	// its transaction. The commands of the connection wait until it is
+	// done, so that they don't use the session of the connection meanwhile.
+	reaping bool
+	// closed is set once the reaper closed the connection.
+	closed   bool
+	lastUsed time.Time
+	// txReaped is the idle timeout after which the reaper rolled back the
+	// transaction of the connection, until the next command of the
+	// connection reports it, or 0.
+	txReaped time.Duration
+}
+
+// idleConnectionError is the error sent to the connections the reaper
+// closes.
+func idleConnectionError() *mysql.SQLError {
+	return mysql.NewSQLError(mysql.ERClientInteractionTimeout, mysql.SSUnknownSQLState, "The client was disconnected by the server because of inactivity. See --mysql_server_idle_timeout of vtgate for configuring this behavior.")
+}
+
+// startCommand marks the connection as busy, after waiting for the reaper
+// if it is reaping the connection. It returns an error if the reaper closed
+// the connection, or rolled back its transaction since its last command.
+func (vh *vtgateHandler) startCommand(c *mysql.Conn) error {
+	vh.mu.Lock()
+	defer vh.mu.Unlock()
+	activity := vh.connections[c]
+	if activity == nil {
+		return nil
+	}
+	vh.waitForReaper(activity)
+	if activity.closed {
+		return idleConnectionError()
+	}
+	activity.busy = true
+	if reaped := activity.txReaped; reaped != 0 {
+		activity.txReaped = 0
+		return vterrors.Errorf(vtrpcpb.Code_ABORTED, "the transaction was rolled back by vtgate because the connection was idle for more than %v", reaped)
+	}
+	return nil
+}
+
+// waitForReaper waits until the reaper is done with the connection. vh.mu
+// must be held.
+func (vh *vtgateHandler) waitForReaper(activity *connActivity) {
+	for activity.reaping {
+		vh.reaped.Wait()
+	}
+}
+
+// endCommand marks the connection as idle.
+func (vh *vtgateHandler) endCommand(c *mysql.Conn) {
+	vh.mu.Lock()
+	defer vh.mu.Unlock()
+	if activity := vh.connections[c]; activity != nil {
+		activity.busy = false
+		activity.lastUsed = time.Now()
+	}
+}
+
+// startReaper checks the idle connections until the returned function is
+// called, if --mysql_server_idle_timeout or
+// --mysql_server_transaction_idle_timeout is set.
+func (vh *vtgateHandler) startReaper(idleTimeout, txIdleTimeout time.Duration) func() {
+	interval := maxReapInterval
+	for _, timeout := range []time.Duration{idleTimeout, txIdleTimeout} {
+		if timeout > 0 && timeout/2 < interval {
+			interval = timeout / 2
+		}
+	}
+	if idleTimeout <= 0 && txIdleTimeout <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				vh.reap(idleTimeout, txIdleTimeout)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// reap closes the connections idle for more than idleTimeout, and rolls
+// back the transactions of the connections idle for more than
+// txIdleTimeout. The commands of the reaped connections wait until they are
+// reaped.
+func (vh *vtgateHandler) reap(idleTimeout, txIdleTimeout time.Duration) {
+	var idleConns, idleTxConns []*mysql.Conn
+	var idleActivities, idleTxActivities []*connActivity
+	now := time.Now()
+	vh.mu.Lock()
+	for c, activity := range vh.connections {
+		if activity.busy || activity.reaping || activity.closed || activity.lastUsed.IsZero() {
+			continue
+		}
+		idle := now.Sub(activity.lastUsed)
+		switch {
+		case idleTimeout > 0 && idle > idleTimeout:
+			activity.reaping = true
+			idleConns = append(idleConns, c)
+			idleActivities = append(idleActivities, activity)
+		case txIdleTimeout > 0 && idle > txIdleTimeout && vh.session(c).InTransaction:
+			activity.reaping = true
+			idleTxConns = append(idleTxConns, c)
+			idleTxActivities = append(idleTxActivities, activity)
+		}
+	}
+	vh.mu.Unlock()
+
+	for i, c := range idleConns {
+		log.Infof("Closing connection %s of user %s, idle for more than %v", c, c.User, idleTimeout)
+		mysqlServerReaped.Add(reapedIdleConnection, 1)
+		// The transaction of the connection is rolled back once it is closed.
+		c.CloseWithError(idleConnectionError())
+
+		vh.mu.Lock()
+		idleActivities[i].reaping = false
+		idleActivities[i].closed = true
+		vh.reaped.Broadcast()
+		vh.mu.Unlock()
+	}
+
+	for i, c := range idleTxConns {
+		log.Infof("Rolling back the transaction of connection %s of user %s, idle for more than %v", c, c.User, txIdleTimeout)
+		mysqlServerReaped.Add(reapedIdleTransaction, 1)
+		vh.rollbackIdleTransaction(c)
+
+		vh.mu.Lock()
+		idleTxActivities[i].reaping = false
+		idleTxActivities[i].txReaped = txIdleTimeout
+		vh.reaped.Broadcast()
+		vh.mu.Unlock()
+	}
+}
+
+// rollbackIdleTransaction rolls back the transaction of an idle connection.
+func (vh *vtgateHandler) rollbackIdleTransaction(c *mysql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), reapRollbackTimeout)
+	defer cancel()
+	ctx = callinfo.MysqlCallInfo(ctx, c)
+	ef := callerid.NewEffectiveCallerID(
+		c.User,                  /* principal: who */
+		c.RemoteAddr().String(), /* component: running client process */
+		"VTGate MySQL Connector" /* subcomponent: part of the client */)
+	ctx = callerid.NewContext(ctx, ef, c.UserData.Get())
+
+	session := vh.session(c)
+	_, _, err := vh.vtg.Execute(ctx, session, "rollback", nil)
+	if err != nil {
+		log.Warningf("Error rolling back the idle transaction of connection %s: %v", c, err)
+		// Release the connections of the session on the tablets anyway.
+		_ = vh.vtg.CloseSession(ctx, session)
+	}
+	// The connection was counted as busy while it was in a transaction.
+	if !session.InTransaction {
+		atomic.AddInt32(&busyConnections, -1)
+	}
+	fillInTxStatusFlags(c, session)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestConnectionReaper(t *testing.T) {
+	createSandbox(KsTestUnsharded)
+	hcVTGateTest.Reset()
+	sbc := hcVTGateTest.AddTestTablet("aa", "1.1.1.1", 1001, KsTestUnsharded, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+
+	authServer := mysql.NewAuthServerStatic("", `{"user1": [{"Password": "password1"}]}`, 0)
+	vh := newVtgateHandler(rpcVTGate)
+	l, err := mysql.NewListener("tcp", "127.0.0.1:0", authServer, vh, 0, 0, false)
+	require.NoError(t, err)
+	defer l.Close()
+	go l.Accept()
+
+	params := &mysql.ConnParams{
+		Host:   "127.0.0.1",
+		Port:   l.Addr().(*net.TCPAddr).Port,
+		Uname:  "user1",
+		Pass:   "password1",
+		DbName: KsTestUnsharded,
+	}
+	c, err := mysql.Connect(context.Background(), params)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The idle transactions are rolled back, and the next command reports it.
+	_, err = c.ExecuteFetch("begin", 1, false)
+	require.NoError(t, err)
+	_, err = c.ExecuteFetch("select id from t1", 10, false)
+	require.NoError(t, err)
+	reapedTransactions := mysqlServerReaped.Counts()[reapedIdleTransaction]
+	vh.reap(0, time.Hour)
+	assert.EqualValues(t, 0, sbc.RollbackCount.Get())
+	time.Sleep(100 * time.Millisecond)
+	vh.reap(0, 50*time.Millisecond)
+	assert.EqualValues(t, 1, sbc.RollbackCount.Get())
+	assert.Equal(t, reapedTransactions+1, mysqlServerReaped.Counts()[reapedIdleTransaction])
+
+	_, err = c.ExecuteFetch("select id from t1", 10, false)
+	assert.ErrorContains(t, err, "the transaction was rolled back by vtgate because the connection was idle for more than 50ms")
+	_, err = c.ExecuteFetch("select id from t1", 10, false)
+	require.NoError(t, err)
+	assert.Zero(t, c.StatusFlags&mysql.ServerStatusInTrans)
+
+	// The connections without a transaction are not affected.
+	time.Sleep(100 * time.Millisecond)
+	vh.reap(0, 50*time.Millisecond)
+	assert.EqualValues(t, 1, sbc.RollbackCount.Get())
+
+	// The idle connections are closed, after an error is sent to the client.
+	vh.reap(50*time.Millisecond, 0)
+	_, err = c.ExecuteFetch("select id from t1", 10, false)
+	require.Error(t, err)
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, mysql.ERClientInteractionTimeout, sqlErr.Number())
+	assert.Eventually(t, func() bool {
+		return vh.numConnections() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConnectionReaperConcurrentCommands(t *testing.T) {
+	createSandbox(KsTestUnsharded)
+	hcVTGateTest.Reset()
+	hcVTGateTest.AddTestTablet("aa", "1.1.1.1", 1001, KsTestUnsharded, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+
+	authServer := mysql.NewAuthServerStatic("", `{"user1": [{"Password": "password1"}]}`, 0)
+	vh := newVtgateHandler(rpcVTGate)
+	l, err := mysql.NewListener("tcp", "127.0.0.1:0", authServer, vh, 0, 0, false)
+	require.NoError(t, err)
+	defer l.Close()
+	go l.Accept()
+
+	params := &mysql.ConnParams{
+		Host:   "127.0.0.1",
+		Port:   l.Addr().(*net.TCPAddr).Port,
+		Uname:  "user1",
+		Pass:   "password1",
+		DbName: KsTestUnsharded,
+	}
+	c, err := mysql.Connect(context.Background(), params)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The commands that race with the reaper either run before it reaps the
+	// connection, or wait until it is done and report the rollback.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				vh.reap(0, time.Nanosecond)
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		for _, query := range []string{"begin", "select id from t1"} {
+			if _, err := c.ExecuteFetch(query, 10, false); err != nil {
+				assert.ErrorContains(t, err, "the transaction was rolled back by vtgate")
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestConnectionReaperHoldsConnection(t *testing.T) {
+	vh := newVtgateHandler(nil)
+	c := &mysql.Conn{}
+	vh.NewConnection(c)
+	vh.endCommand(c)
+
+	vh.mu.Lock()
+	activity := vh.connections[c]
+	activity.reaping = true
+	vh.mu.Unlock()
+
+	// A command waits while the reaper holds its connection.
+	started := make(chan error)
+	go func() {
+		started <- vh.startCommand(c)
+	}()
+	select {
+	case err := <-started:
+		t.Fatalf("the command started while the reaper held the connection: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	vh.mu.Lock()
+	activity.reaping = false
+	activity.txReaped = time.Minute
+	vh.reaped.Broadcast()
+	vh.mu.Unlock()
+	assert.ErrorContains(t, <-started, "the transaction was rolled back by vtgate because the connection was idle for more than 1m0s")
+
+	// A busy connection is not reaped.
+	vh.reap(time.Nanosecond, time.Nanosecond)
+	vh.mu.Lock()
+	assert.False(t, activity.reaping)
+	assert.False(t, activity.closed)
+	vh.mu.Unlock()
+	vh.endCommand(c)
+
+	// A command of a connection closed by the reaper is refused.
+	vh.mu.Lock()
+	activity.closed = true
+	vh.mu.Unlock()
+	err := vh.startCommand(c)
+	require.Error(t, err)
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, mysql.ERClientInteractionTimeout, sqlErr.Number())
+}
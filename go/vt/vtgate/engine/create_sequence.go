@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ Primitive = (*CreateSequence)(nil)
+
+// CreateSequence creates the backing table of a sequence, seeds its single
+// row and registers it in the vschema. In a sharded keyspace, the table is
+// created on every shard, which allocate interleaved blocks of values.
+// If a step fails, the tables it created are dropped again. CREATE SEQUENCE
+// IF NOT EXISTS drops nothing, its steps are idempotent and it can be rerun
+// to complete a sequence that was partially created.
+type CreateSequence struct {
+	Keyspace          *vindexes.Keyspace
+	TargetDestination key.Destination
+
+	// CreateTable, Seed and DropTable are the queries sent to the
+	// keyspace to create, seed and, on failure, drop the sequence table.
+	CreateTable string
+	Seed        string
+	DropTable   string
+
+	// AlterVschemaDDL is the CREATE SEQUENCE statement, which is applied
+	// to the vschema once the table exists.
+	AlterVschemaDDL *sqlparser.AlterVschema
+
+	// Start and Cache are set for sharded keyspaces, where the Seed
+	// query of every shard is bound to its first value and stride.
+	Start int64
+	Cache int64
+
+	noTxNeeded
+
+	noInputs
+}
+
+func (c *CreateSequence) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType:      "CreateSequence",
+		Keyspace:          c.Keyspace,
+		TargetDestination: c.TargetDestination,
+		Other: map[string]any{
+			"query": sqlparser.String(c.AlterVschemaDDL),
+			"table": c.CreateTable,
+			"seed":  c.Seed,
+		},
+	}
+}
+
+// RouteType implements the Primitive interface
+func (c *CreateSequence) RouteType() string {
+	return "CreateSequence"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (c *CreateSequence) GetKeyspaceName() string {
+	return c.Keyspace.Name
+}
+
+// GetTableName implements the Primitive interface
+func (c *CreateSequence) GetTableName() string {
+	return c.AlterVschemaDDL.Table.Name.String()
+}
+
+// TryExecute implements the Primitive interface
+func (c *CreateSequence) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	rss, _, err := vcursor.ResolveDestinations(c.Keyspace.Name, nil, []key.Destination{c.TargetDestination})
+	if err != nil {
+		return nil, err
+	}
+	if !c.Keyspace.Sharded && len(rss) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "Keyspace does not have exactly one shard: %v", rss)
+	}
+
+	created, err := c.createTable(vcursor, rss)
+	if err != nil {
+		return nil, c.rollback(vcursor, created, err)
+	}
+	if err := c.seed(vcursor, rss); err != nil {
+		return nil, c.rollback(vcursor, created, err)
+	}
+	if err := vcursor.ExecuteVSchema(c.Keyspace.Name, c.AlterVschemaDDL); err != nil {
+		return nil, c.rollback(vcursor, created, err)
+	}
+	return &sqltypes.Result{}, nil
+}
+
+// createTable creates the sequence table one shard at a time, and returns the
+// shards it was created on. A table that already existed on a shard fails the
+// statement, and is not dropped by the rollback.
+func (c *CreateSequence) createTable(vcursor VCursor, rss []*srvtopo.ResolvedShard) ([]*srvtopo.ResolvedShard, error) {
+	created := make([]*srvtopo.ResolvedShard, 0, len(rss))
+	for _, rs := range rss {
+		if err := c.execute(vcursor, []*srvtopo.ResolvedShard{rs}, c.CreateTable, false); err != nil {
+			return created, err
+		}
+		created = append(created, rs)
+	}
+	return created, nil
+}
+
+func (c *CreateSequence) execute(vcursor VCursor, rss []*srvtopo.ResolvedShard, query string, isDML bool) error {
+	queries := make([]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = &querypb.BoundQuery{Sql: query}
+	}
+	_, errs := vcursor.ExecuteMultiShard(rss, queries, isDML, isDML && vcursor.AutocommitApproval())
+	return vterrors.Aggregate(errs)
+}
+
+// seed inserts the row of the sequence table. Shard i of a sharded keyspace
+// starts at Start+i*Cache, and skips the blocks of the other shards.
+func (c *CreateSequence) seed(vcursor VCursor, rss []*srvtopo.ResolvedShard) error {
+	if !c.Keyspace.Sharded {
+		return c.execute(vcursor, rss, c.Seed, true)
+	}
+	queries := make([]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = &querypb.BoundQuery{
+			Sql: c.Seed,
+			BindVariables: map[string]*querypb.BindVariable{
+				"next_id": sqltypes.Int64BindVariable(c.Start + int64(i)*c.Cache),
+				"stride":  sqltypes.Int64BindVariable(int64(len(rss))),
+			},
+		}
+	}
+	_, errs := vcursor.ExecuteMultiShard(rss, queries, true, vcursor.AutocommitApproval())
+	return vterrors.Aggregate(errs)
+}
+
+// rollback drops the sequence table from the shards it was created on after
+// a failed step. It is a no-op for CREATE SEQUENCE IF NOT EXISTS, where the
+// table may have existed before.
+func (c *CreateSequence) rollback(vcursor VCursor, rss []*srvtopo.ResolvedShard, cause error) error {
+	if c.AlterVschemaDDL.IfNotExists || len(rss) == 0 {
+		return cause
+	}
+	if err := c.execute(vcursor, rss, c.DropTable, false); err != nil {
+		return vterrors.Wrapf(cause, "failed to drop sequence table %s after error: %v", c.GetTableName(), err)
+	}
+	return cause
+}
+
+// TryStreamExecute implements the Primitive interface
+func (c *CreateSequence) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := c.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields implements the Primitive interface
+func (c *CreateSequence) GetFields(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.NewErrorf(vtrpcpb.Code_UNIMPLEMENTED, vterrors.UnsupportedPS, "This command is not supported in the prepared statement protocol yet")
+}
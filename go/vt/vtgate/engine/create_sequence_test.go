@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func newTestCreateSequence(t *testing.T, sql string, sharded bool) *CreateSequence {
+	stmt, err := sqlparser.Parse(sql)
+	require.NoError(t, err)
+	ddl := stmt.(*sqlparser.AlterVschema)
+
+	c := &CreateSequence{
+		Keyspace:          &vindexes.Keyspace{Name: "ks", Sharded: sharded},
+		TargetDestination: key.DestinationAnyShard{},
+		CreateTable:       "create table seq",
+		Seed:              "insert into seq",
+		DropTable:         "drop table seq",
+		AlterVschemaDDL:   ddl,
+	}
+	if sharded {
+		c.TargetDestination = key.DestinationAllShards{}
+		c.Start = 1
+		c.Cache = 10
+	}
+	return c
+}
+
+func TestCreateSequence(t *testing.T) {
+	c := newTestCreateSequence(t, "create sequence seq start with 1 cache 10", true)
+
+	vc := &loggingVCursor{shards: []string{"-80", "80-"}}
+	_, err := c.TryExecute(vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-80: create table seq {} false false`,
+		`ExecuteMultiShard ks.80-: create table seq {} false false`,
+		`ExecuteMultiShard ks.-80: insert into seq {next_id: type:INT64 value:"1" stride: type:INT64 value:"2"} ks.80-: insert into seq {next_id: type:INT64 value:"11" stride: type:INT64 value:"2"} true true`,
+		`ExecuteVSchema ks create sequence seq start with 1 cache 10`,
+	})
+}
+
+func TestCreateSequenceRollback(t *testing.T) {
+	c := newTestCreateSequence(t, "create sequence seq start with 1 cache 10", true)
+
+	// The table already exists on the second shard: only the table of the
+	// first shard is dropped.
+	vc := &loggingVCursor{
+		shards:    []string{"-80", "80-"},
+		results:   []*sqltypes.Result{{}, nil, {}},
+		resultErr: errors.New("table seq already exists"),
+	}
+	_, err := c.TryExecute(vc, nil, true)
+	require.EqualError(t, err, "table seq already exists")
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-80: create table seq {} false false`,
+		`ExecuteMultiShard ks.80-: create table seq {} false false`,
+		`ExecuteMultiShard ks.-80: drop table seq {} false false`,
+	})
+
+	// The table is dropped from every shard if the vschema cannot be updated.
+	vc = &loggingVCursor{
+		shards:     []string{"-80", "80-"},
+		vschemaErr: errors.New("vschema error"),
+	}
+	_, err = c.TryExecute(vc, nil, true)
+	require.EqualError(t, err, "vschema error")
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-80: create table seq {} false false`,
+		`ExecuteMultiShard ks.80-: create table seq {} false false`,
+		`ExecuteMultiShard ks.-80: insert into seq {next_id: type:INT64 value:"1" stride: type:INT64 value:"2"} ks.80-: insert into seq {next_id: type:INT64 value:"11" stride: type:INT64 value:"2"} true true`,
+		`ExecuteVSchema ks create sequence seq start with 1 cache 10`,
+		`ExecuteMultiShard ks.-80: drop table seq {} ks.80-: drop table seq {} false false`,
+	})
+}
+
+func TestCreateSequenceIfNotExists(t *testing.T) {
+	c := newTestCreateSequence(t, "create sequence if not exists seq", false)
+
+	// Nothing is dropped, and rerunning the statement completes the sequence.
+	vc := &loggingVCursor{
+		shards:     []string{"0"},
+		vschemaErr: errors.New("vschema error"),
+	}
+	_, err := c.TryExecute(vc, nil, true)
+	require.EqualError(t, err, "vschema error")
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAnyShard()`,
+		`ExecuteMultiShard ks.0: create table seq {} false false`,
+		`ExecuteMultiShard ks.0: insert into seq {} true true`,
+		`ExecuteVSchema ks create sequence if not exists seq`,
+	})
+
+	vc.vschemaErr = nil
+	vc.log = nil
+	_, err = c.TryExecute(vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAnyShard()`,
+		`ExecuteMultiShard ks.0: create table seq {} false false`,
+		`ExecuteMultiShard ks.0: insert into seq {} true true`,
+		`ExecuteVSchema ks create sequence if not exists seq`,
+	})
+}
@@ -45,6 +45,13 @@ type (
 	probeTable struct {
 		seenRows  map[evalengine.HashCode][]sqltypes.Row
 		checkCols []CheckCol
+
+		// rows is the number of rows in seenRows.
+		rows int
+		// exceedsMemory tells whether seenRows holds more rows than allowed,
+		// in which case the rows are spilled to disk.
+		exceedsMemory func(rows int) bool
+		spill         *distinctSpill
 	}
 )
 
@@ -166,6 +173,104 @@ func newProbeTable(checkCols []CheckCol) *probeTable {
 	}
 }
 
+// newBoundedProbeTable returns a probeTable that spills its rows to disk once
+// it holds more rows than the vcursor allows to hold in memory.
+func newBoundedProbeTable(vcursor VCursor, checkCols []CheckCol) *probeTable {
+	pt := newProbeTable(checkCols)
+	pt.exceedsMemory = vcursor.ExceedsMaxMemoryRows
+	return pt
+}
+
+// add adds a row to the probe table, and returns true if the row is seen for
+// the first time and can be returned right away. Once the rows were spilled to
+// disk, the rows are returned by finish instead.
+func (pt *probeTable) add(inputRow sqltypes.Row) (bool, error) {
+	if pt.spill != nil {
+		code, err := pt.hashCodeForRow(inputRow)
+		if err != nil {
+			return false, err
+		}
+		return false, pt.spill.pending[spillPartition(code)].write(inputRow)
+	}
+
+	exists, err := pt.exists(inputRow)
+	if err != nil || exists {
+		return false, err
+	}
+	pt.rows++
+	if pt.exceedsMemory != nil && pt.exceedsMemory(pt.rows) {
+		if err := pt.spillToDisk(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// spillToDisk moves the rows seen so far to a distinctSpill.
+func (pt *probeTable) spillToDisk() error {
+	spill, err := newDistinctSpill()
+	if err != nil {
+		return err
+	}
+	pt.spill = spill
+	for code, rows := range pt.seenRows {
+		for _, row := range rows {
+			if err := spill.seen[spillPartition(code)].write(row); err != nil {
+				return err
+			}
+		}
+	}
+	pt.seenRows = map[evalengine.HashCode][]sqltypes.Row{}
+	pt.rows = 0
+	return nil
+}
+
+// finish deduplicates the rows that were spilled to disk, one partition at
+// a time, and calls emit with the rows of each partition that were not seen
+// before. It is a no-op if the rows were never spilled.
+func (pt *probeTable) finish(emit func(rows []sqltypes.Row) error) error {
+	if pt.spill == nil {
+		return nil
+	}
+	defer pt.close()
+	for i := 0; i < spillPartitions; i++ {
+		partition := newProbeTable(pt.checkCols)
+		err := pt.spill.seen[i].forEach(func(row sqltypes.Row) error {
+			_, err := partition.exists(row)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		var rows []sqltypes.Row
+		err = pt.spill.pending[i].forEach(func(row sqltypes.Row) error {
+			exists, err := partition.exists(row)
+			if err != nil || exists {
+				return err
+			}
+			rows = append(rows, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := emit(rows); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close releases the disk space used by the probe table.
+func (pt *probeTable) close() {
+	if pt.spill != nil {
+		pt.spill.close()
+		pt.spill = nil
+	}
+}
+
 // TryExecute implements the Primitive interface
 func (d *Distinct) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	input, err := vcursor.ExecutePrimitive(d.Source, bindVars, wantfields)
@@ -178,6 +283,8 @@ func (d *Distinct) TryExecute(vcursor VCursor, bindVars map[string]*querypb.Bind
 		InsertID: input.InsertID,
 	}
 
+	// The input is already held in memory, so the probe table is not
+	// spilled to disk, unlike in TryStreamExecute.
 	pt := newProbeTable(d.CheckCols)
 
 	for _, row := range input.Rows {
@@ -197,7 +304,8 @@ func (d *Distinct) TryExecute(vcursor VCursor, bindVars map[string]*querypb.Bind
 
 // TryStreamExecute implements the Primitive interface
 func (d *Distinct) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
-	pt := newProbeTable(d.CheckCols)
+	pt := newBoundedProbeTable(vcursor, d.CheckCols)
+	defer pt.close()
 
 	err := vcursor.StreamExecutePrimitive(d.Source, bindVars, wantfields, func(input *sqltypes.Result) error {
 		result := &sqltypes.Result{
@@ -205,18 +313,23 @@ func (d *Distinct) TryStreamExecute(vcursor VCursor, bindVars map[string]*queryp
 			InsertID: input.InsertID,
 		}
 		for _, row := range input.Rows {
-			exists, err := pt.exists(row)
+			isNew, err := pt.add(row)
 			if err != nil {
 				return err
 			}
-			if !exists {
+			if isNew {
 				result.Rows = append(result.Rows, row)
 			}
 		}
 		return callback(result.Truncate(len(d.CheckCols)))
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	return pt.finish(func(rows []sqltypes.Row) error {
+		return callback((&sqltypes.Result{Rows: rows}).Truncate(len(d.CheckCols)))
+	})
 }
 
 // RouteType implements the Primitive interface
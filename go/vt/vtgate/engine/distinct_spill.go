@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	// spillDir is the directory of the spill files, the temporary directory
+	// of the system if empty.
+	spillDir string
+	// spillMaxDiskBytes is the number of bytes the spill files of all the
+	// queries can hold, 0 for no limit.
+	spillMaxDiskBytes int64
+	spillDiskBytes    sync2.AtomicInt64
+
+	_ = stats.NewGaugeFunc("QuerySpillDiskBytes", "Number of bytes held by the files the queries spilled their rows to", spillDiskBytes.Get)
+)
+
+// SetSpillOptions sets the directory of the files the queries spill their
+// rows to, and the number of bytes these files can hold, 0 for no limit. It
+// must be called before the queries run.
+func SetSpillOptions(dir string, maxDiskBytes int64) {
+	spillDir = dir
+	spillMaxDiskBytes = maxDiskBytes
+}
+
+// spillPartitions is the number of partitions the rows of a Distinct are
+// spilled to, once they exceed the number of rows it can hold in memory.
+const spillPartitions = 16
+
+// distinctSpill holds the rows of a Distinct in temporary files, partitioned
+// by hash code, so that the rows of each partition can be deduplicated on
+// their own. The seen files hold the rows that were already returned, and
+// the pending files the rows that remain to be deduplicated.
+type distinctSpill struct {
+	seen    []*spillFile
+	pending []*spillFile
+}
+
+func newDistinctSpill() (*distinctSpill, error) {
+	ds := &distinctSpill{}
+	for i := 0; i < spillPartitions; i++ {
+		seen, err := newSpillFile()
+		if err != nil {
+			ds.close()
+			return nil, err
+		}
+		ds.seen = append(ds.seen, seen)
+		pending, err := newSpillFile()
+		if err != nil {
+			ds.close()
+			return nil, err
+		}
+		ds.pending = append(ds.pending, pending)
+	}
+	return ds, nil
+}
+
+func spillPartition(code evalengine.HashCode) int {
+	return int(uint64(code) % spillPartitions)
+}
+
+// close removes the temporary files.
+func (ds *distinctSpill) close() {
+	for _, sf := range ds.seen {
+		sf.close()
+	}
+	for _, sf := range ds.pending {
+		sf.close()
+	}
+}
+
+// spillFile is a temporary file of rows.
+type spillFile struct {
+	file *os.File
+	w    *bufio.Writer
+	buf  []byte
+	// size is the number of bytes written to the file, which are counted in
+	// spillDiskBytes until it is closed.
+	size int64
+}
+
+func newSpillFile() (*spillFile, error) {
+	file, err := os.CreateTemp(spillDir, "vtgate-spill-")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// write appends a row to the file. Every value is written as its type,
+// followed by the length of its bytes and the bytes, except for NULL values
+// that are written as their type only.
+func (sf *spillFile) write(row sqltypes.Row) error {
+	sf.buf = appendUvarint(sf.buf[:0], uint64(len(row)))
+	for _, val := range row {
+		sf.buf = appendUvarint(sf.buf, uint64(val.Type()))
+		if val.IsNull() {
+			continue
+		}
+		raw := val.Raw()
+		sf.buf = appendUvarint(sf.buf, uint64(len(raw)))
+		sf.buf = append(sf.buf, raw...)
+	}
+	size := int64(len(sf.buf))
+	if total := spillDiskBytes.Add(size); spillMaxDiskBytes > 0 && total > spillMaxDiskBytes {
+		spillDiskBytes.Add(-size)
+		return vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.CapacityExceeded, "query spill files exceeded %d bytes on disk, see --query_spill_max_disk_bytes", spillMaxDiskBytes)
+	}
+	sf.size += size
+	_, err := sf.w.Write(sf.buf)
+	return err
+}
+
+// forEach calls fn for every row of the file, in order.
+func (sf *spillFile) forEach(fn func(row sqltypes.Row) error) error {
+	sr, err := sf.reader()
+	if err != nil {
+		return err
+	}
+	for {
+		row, err := sr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// reader returns a reader of the rows of the file, from the first one.
+func (sf *spillFile) reader() (*spillReader, error) {
+	if err := sf.w.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err := sf.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &spillReader{r: bufio.NewReader(sf.file)}, nil
+}
+
+// spillReader reads the rows of a spillFile, in order.
+type spillReader struct {
+	r *bufio.Reader
+}
+
+// next returns the next row of the file, or io.EOF after the last one.
+func (sr *spillReader) next() (sqltypes.Row, error) {
+	cols, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return nil, err
+	}
+	row := make(sqltypes.Row, 0, cols)
+	for i := uint64(0); i < cols; i++ {
+		typ, err := binary.ReadUvarint(sr.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if querypb.Type(typ) == sqltypes.Null {
+			row = append(row, sqltypes.NULL)
+			continue
+		}
+		size, err := binary.ReadUvarint(sr.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(sr.r, raw); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		row = append(row, sqltypes.MakeTrusted(querypb.Type(typ), raw))
+	}
+	return row, nil
+}
+
+// unexpectedEOF converts io.EOF within a row to io.ErrUnexpectedEOF, so
+// that a truncated file is not mistaken for its end.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var varint [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(varint[:], x)
+	return append(buf, varint[:n]...)
+}
+
+func (sf *spillFile) close() {
+	if sf == nil {
+		return
+	}
+	sf.file.Close()
+	os.Remove(sf.file.Name())
+	spillDiskBytes.Add(-sf.size)
+	sf.size = 0
+}
@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -162,3 +163,46 @@ func TestWeightStringFallBack(t *testing.T) {
 		Collation: collations.Unknown,
 	}}, distinct.CheckCols, "checkCols should not be updated")
 }
+
+func TestDistinctSpill(t *testing.T) {
+	saveMax := testMaxMemoryRows
+	testMaxMemoryRows = 3
+	defer func() {
+		testMaxMemoryRows = saveMax
+	}()
+
+	checkCols := []CheckCol{{Col: 0, Collation: collations.CollationBinaryID}, {Col: 1, Collation: collations.CollationUtf8mb4ID}}
+	input := r("id|name", "int64|varchar",
+		"1|a", "2|b", "1|a", "3|c", "4|d", "2|b", "5|e", "null|f", "6|F", "null|f", "4|d", "7|g", "1|a", "6|f")
+	expected := []string{"[INT64(1) VARCHAR(\"a\")]", "[INT64(2) VARCHAR(\"b\")]", "[INT64(3) VARCHAR(\"c\")]",
+		"[INT64(4) VARCHAR(\"d\")]", "[INT64(5) VARCHAR(\"e\")]", "[INT64(6) VARCHAR(\"F\")]",
+		"[INT64(7) VARCHAR(\"g\")]", "[NULL VARCHAR(\"f\")]"}
+
+	rowStrings := func(rows []sqltypes.Row) []string {
+		var res []string
+		for _, row := range rows {
+			res = append(res, fmt.Sprintf("%v", row))
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	distinct := &Distinct{
+		Source:    &fakePrimitive{results: []*sqltypes.Result{input}},
+		CheckCols: checkCols,
+	}
+	var rows []sqltypes.Row
+	err := distinct.TryStreamExecute(&noopVCursor{ctx: context.Background()}, nil, true, func(result *sqltypes.Result) error {
+		rows = append(rows, result.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	// the rows of the spilled partitions are returned in a different order
+	utils.MustMatch(t, expected, rowStrings(rows))
+
+	// the rows of TryExecute are already in memory and are not spilled
+	distinct.Source = &fakePrimitive{results: []*sqltypes.Result{input}}
+	qr, err := distinct.TryExecute(&noopVCursor{ctx: context.Background()}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, expected, rowStrings(qr.Rows))
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Primitive = (*DMLBatch)(nil)
+
+const (
+	// DMLBatchAfterName is the bind variable of the last value of the batch
+	// column of the previous batch.
+	DMLBatchAfterName = "__batch_after"
+	// DMLBatchLowerName is the bind variable of the first value of the batch
+	// column of a batch.
+	DMLBatchLowerName = "__batch_lower"
+	// DMLBatchUpperName is the bind variable of the last value of the batch
+	// column of a batch.
+	DMLBatchUpperName = "__batch_upper"
+
+	// DMLBatchThrottlerApp is the name that batched DMLs check the lag
+	// throttler of the primaries with, so that they can be throttled on
+	// their own.
+	DMLBatchThrottlerApp = "vtgate-dml-batch"
+)
+
+var (
+	dmlBatches          = stats.NewCountersWithMultiLabels("DMLBatches", "Batches of the UPDATE and DELETE statements executed in batches, by keyspace and table", []string{"Keyspace", "Table"})
+	dmlBatchRows        = stats.NewCountersWithMultiLabels("DMLBatchRowsAffected", "Rows affected by the batches of the UPDATE and DELETE statements executed in batches, by keyspace and table", []string{"Keyspace", "Table"})
+	dmlBatchThrottledNs = stats.NewCountersWithMultiLabels("DMLBatchThrottledNs", "Time the batches of the UPDATE and DELETE statements executed in batches waited for the lag throttler, by keyspace and table", []string{"Keyspace", "Table"})
+)
+
+// dmlBatchThrottleWait is how long a batch waits before it checks the lag
+// throttler again.
+var dmlBatchThrottleWait = time.Second
+
+// DMLBatch executes an UPDATE or a DELETE in batches of rows, shard by shard,
+// each batch committed on its own, so that a statement that changes many rows
+// doesn't make the replicas lag behind. The batches are ranges of the values
+// of a column: each batch reads the next BatchSize values of the column of the
+// rows that the statement changes, then changes the rows whose value is within
+// the range they span. Before each batch, the lag throttler of the primary is
+// checked.
+type DMLBatch struct {
+	// DML routes the statement to its shards.
+	*DML
+
+	// Column is the column whose values the batches are ranges of.
+	Column string
+	// BatchSize is the number of values of Column of a batch.
+	BatchSize int
+
+	// FirstBoundsQuery selects the values of Column of the first batch.
+	FirstBoundsQuery string
+	// BoundsQuery selects the values of Column of the next batch, after the
+	// value bound to DMLBatchAfterName.
+	BoundsQuery string
+	// BatchQuery is the statement restricted to the rows whose value of
+	// Column is between the values bound to DMLBatchLowerName and
+	// DMLBatchUpperName.
+	BatchQuery string
+
+	noInputs
+	noTxNeeded
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (b *DMLBatch) RouteType() string {
+	return b.Opcode.String()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (b *DMLBatch) GetKeyspaceName() string {
+	return b.Keyspace.Name
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (b *DMLBatch) GetTableName() string {
+	if b.Table != nil {
+		return b.Table.Name.String()
+	}
+	return ""
+}
+
+// TryExecute performs a non-streaming exec.
+func (b *DMLBatch) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	if vcursor.InTransactionAndIsDML() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "a statement executed in batches commits each batch, it can't be executed in a transaction")
+	}
+	if b.QueryTimeout != 0 {
+		cancel := vcursor.SetContextTimeout(time.Duration(b.QueryTimeout) * time.Millisecond)
+		defer cancel()
+	}
+
+	rss, _, err := b.findRoute(vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	if err := allowOnlyPrimary(rss...); err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{}
+	for _, rs := range rss {
+		rowsAffected, err := b.executeShard(vcursor, bindVars, rs)
+		result.RowsAffected += rowsAffected
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "batches on shard %s/%s failed after %d rows affected in total", rs.Target.Keyspace, rs.Target.Shard, result.RowsAffected)
+		}
+	}
+	return result, nil
+}
+
+// executeShard executes the batches of a shard, one after the other, and
+// returns the number of rows they affected.
+func (b *DMLBatch) executeShard(vcursor VCursor, bindVars map[string]*querypb.BindVariable, rs *srvtopo.ResolvedShard) (uint64, error) {
+	labels := []string{b.GetKeyspaceName(), b.GetTableName()}
+	batchBindVars := make(map[string]*querypb.BindVariable, len(bindVars)+3)
+	for k, v := range bindVars {
+		batchBindVars[k] = v
+	}
+
+	var (
+		rowsAffected uint64
+		batches      int
+		boundsQuery  = b.FirstBoundsQuery
+	)
+	for {
+		if err := b.waitForThrottler(vcursor, rs, labels); err != nil {
+			return rowsAffected, err
+		}
+
+		bounds, err := vcursor.ExecuteStandalone(boundsQuery, batchBindVars, rs)
+		if err != nil {
+			return rowsAffected, err
+		}
+		if len(bounds.Rows) == 0 {
+			break
+		}
+		lower, upper := bounds.Rows[0][0], bounds.Rows[len(bounds.Rows)-1][0]
+		batchBindVars[DMLBatchLowerName] = sqltypes.ValueBindVariable(lower)
+		batchBindVars[DMLBatchUpperName] = sqltypes.ValueBindVariable(upper)
+
+		qr, err := vcursor.ExecuteStandalone(b.BatchQuery, batchBindVars, rs)
+		if err != nil {
+			return rowsAffected, err
+		}
+		rowsAffected += qr.RowsAffected
+		batches++
+		dmlBatches.Add(labels, 1)
+		dmlBatchRows.Add(labels, int64(qr.RowsAffected))
+		log.Infof("Batch %d of %s on shard %s/%s changed the rows of %s from %s to %s: %d rows affected, %d in total", batches, b.GetTableName(), rs.Target.Keyspace, rs.Target.Shard, b.Column, lower.String(), upper.String(), qr.RowsAffected, rowsAffected)
+
+		if len(bounds.Rows) < b.BatchSize {
+			break
+		}
+		boundsQuery = b.BoundsQuery
+		batchBindVars[DMLBatchAfterName] = sqltypes.ValueBindVariable(upper)
+	}
+	return rowsAffected, nil
+}
+
+// waitForThrottler waits until the lag throttler of the primary of a shard
+// lets the next batch through. A throttler that can't be checked doesn't hold
+// the batches back.
+func (b *DMLBatch) waitForThrottler(vcursor VCursor, rs *srvtopo.ResolvedShard, labels []string) error {
+	start := time.Now()
+	defer func() {
+		dmlBatchThrottledNs.Add(labels, int64(time.Since(start)))
+	}()
+
+	for {
+		ok, err := vcursor.CheckThrottler(rs, DMLBatchThrottlerApp)
+		if err != nil {
+			log.Warningf("Could not check the throttler of shard %s/%s, executing the next batch of %s: %v", rs.Target.Keyspace, rs.Target.Shard, b.GetTableName(), err)
+			return nil
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-vcursor.Context().Done():
+			return vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "batches of %s throttled on shard %s/%s until the deadline", b.GetTableName(), rs.Target.Keyspace, rs.Target.Shard)
+		case <-time.After(dmlBatchThrottleWait):
+		}
+	}
+}
+
+// TryStreamExecute performs a streaming exec.
+func (b *DMLBatch) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := b.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (b *DMLBatch) GetFields(VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, fmt.Errorf("BUG: unreachable code for %q", b.Query)
+}
+
+func (b *DMLBatch) description() PrimitiveDescription {
+	other := map[string]any{
+		"Table":            b.GetTableName(),
+		"Column":           b.Column,
+		"BatchSize":        b.BatchSize,
+		"FirstBoundsQuery": b.FirstBoundsQuery,
+		"BoundsQuery":      b.BoundsQuery,
+		"BatchQuery":       b.BatchQuery,
+		"QueryTimeout":     b.QueryTimeout,
+	}
+
+	addFieldsIfNotEmpty(b.DML, other)
+
+	return PrimitiveDescription{
+		OperatorType:     "DMLBatch",
+		Keyspace:         b.Keyspace,
+		Variant:          b.Opcode.String(),
+		TargetTabletType: topodatapb.TabletType_PRIMARY,
+		Other:            other,
+	}
+}
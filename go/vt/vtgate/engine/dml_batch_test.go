@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func newTestDMLBatch() *DMLBatch {
+	return &DMLBatch{
+		DML: &DML{
+			RoutingParameters: &RoutingParameters{
+				Opcode: Scatter,
+				Keyspace: &vindexes.Keyspace{
+					Name:    "ks",
+					Sharded: true,
+				},
+			},
+			Query: "dummy_delete",
+			Table: &vindexes.Table{Name: sqlparser.NewIdentifierCS("t")},
+		},
+		Column:           "id",
+		BatchSize:        2,
+		FirstBoundsQuery: "dummy_first_bounds",
+		BoundsQuery:      "dummy_bounds",
+		BatchQuery:       "dummy_batch",
+	}
+}
+
+func TestDMLBatch(t *testing.T) {
+	batch := newTestDMLBatch()
+	ids := sqltypes.MakeTestFields("id", "int64")
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.results = []*sqltypes.Result{
+		// -20: a full batch, then the last one.
+		sqltypes.MakeTestResult(ids, "1", "2"),
+		{RowsAffected: 2},
+		sqltypes.MakeTestResult(ids, "3"),
+		{RowsAffected: 1},
+		// 20-: no rows.
+		sqltypes.MakeTestResult(ids),
+	}
+	qr, err := batch.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, qr.RowsAffected)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`CheckThrottler ks -20 vtgate-dml-batch`,
+		`ExecuteStandalone dummy_first_bounds  ks -20`,
+		`ExecuteStandalone dummy_batch __batch_lower: type:INT64 value:"1" __batch_upper: type:INT64 value:"2" ks -20`,
+		`CheckThrottler ks -20 vtgate-dml-batch`,
+		`ExecuteStandalone dummy_bounds __batch_after: type:INT64 value:"2" __batch_lower: type:INT64 value:"1" __batch_upper: type:INT64 value:"2" ks -20`,
+		`ExecuteStandalone dummy_batch __batch_after: type:INT64 value:"2" __batch_lower: type:INT64 value:"3" __batch_upper: type:INT64 value:"3" ks -20`,
+		`CheckThrottler ks 20- vtgate-dml-batch`,
+		`ExecuteStandalone dummy_first_bounds  ks 20-`,
+	})
+
+	// Failure case
+	vc = newDMLTestVCursor("-20")
+	vc.results = []*sqltypes.Result{
+		sqltypes.MakeTestResult(ids, "1", "2"),
+		{RowsAffected: 2},
+	}
+	vc.resultErr = errors.New("batch_error")
+	_, err = batch.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "batches on shard ks/-20 failed after 2 rows affected in total: batch_error")
+}
+
+func TestDMLBatchThrottled(t *testing.T) {
+	defer func(wait time.Duration) {
+		dmlBatchThrottleWait = wait
+	}(dmlBatchThrottleWait)
+	dmlBatchThrottleWait = time.Millisecond
+
+	batch := newTestDMLBatch()
+	ids := sqltypes.MakeTestFields("id", "int64")
+
+	vc := newDMLTestVCursor("-20")
+	vc.throttledChecks = 2
+	vc.results = []*sqltypes.Result{
+		sqltypes.MakeTestResult(ids, "1"),
+		{RowsAffected: 1},
+	}
+	qr, err := batch.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, qr.RowsAffected)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`CheckThrottler ks -20 vtgate-dml-batch`,
+		`CheckThrottler ks -20 vtgate-dml-batch`,
+		`CheckThrottler ks -20 vtgate-dml-batch`,
+		`ExecuteStandalone dummy_first_bounds  ks -20`,
+		`ExecuteStandalone dummy_batch __batch_lower: type:INT64 value:"1" __batch_upper: type:INT64 value:"1" ks -20`,
+	})
+}
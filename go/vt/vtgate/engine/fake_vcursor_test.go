@@ -53,6 +53,11 @@ var _ SessionActions = (*noopVCursor)(nil)
 type noopVCursor struct {
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	// memoryLimit is the number of bytes the primitives can reserve, 0 for
+	// no limit, and memoryUsed the number of bytes they reserved.
+	memoryLimit int64
+	memoryUsed  int64
 }
 
 func newNoopVCursor(ctx context.Context) *noopVCursor {
@@ -81,6 +86,10 @@ func (t *noopVCursor) ReleaseLock() error {
 	panic("implement me")
 }
 
+func (t *noopVCursor) CheckThrottler(rs *srvtopo.ResolvedShard, appName string) (bool, error) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) CancelContext() {
 	t.cancel()
 }
@@ -111,6 +120,12 @@ func (t *noopVCursor) StreamExecutePrimitive(primitive Primitive, bindVars map[s
 	return primitive.TryStreamExecute(t, bindVars, wantfields, callback)
 }
 
+func (t *noopVCursor) StartPrimitiveTrace() func() map[Primitive]*PrimitiveStats {
+	return func() map[Primitive]*PrimitiveStats {
+		return nil
+	}
+}
+
 func (t *noopVCursor) HasSystemVariables() bool {
 	panic("implement me")
 }
@@ -234,6 +249,14 @@ func (t *noopVCursor) SetSkipQueryPlanCache(bool) error {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SetQueryRetry(bool) error {
+	panic("implement me")
+}
+
+func (t *noopVCursor) SetQueryMemoryLimit(int64) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetSQLSelectLimit(int64) error {
 	panic("implement me")
 }
@@ -242,10 +265,18 @@ func (t *noopVCursor) SetTransactionMode(vtgatepb.TransactionMode) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) TwoPCEnabled() bool {
+	return false
+}
+
 func (t *noopVCursor) SetWorkload(querypb.ExecuteOptions_Workload) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SetPriority(string) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion) {
 	panic("implement me")
 }
@@ -268,6 +299,18 @@ func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
 
+func (t *noopVCursor) ReserveMemory(size int64) error {
+	t.memoryUsed += size
+	if t.memoryLimit > 0 && t.memoryUsed > t.memoryLimit {
+		return QueryMemoryLimitError(t.memoryLimit)
+	}
+	return nil
+}
+
+func (t *noopVCursor) ReleaseMemory(size int64) {
+	t.memoryUsed -= size
+}
+
 func (t *noopVCursor) GetKeyspace() string {
 	return ""
 }
@@ -355,11 +398,25 @@ type loggingVCursor struct {
 	dbDDLPlugin     string
 	ksAvailable     bool
 	inReservedConn  bool
+	shardSession    []*srvtopo.ResolvedShard
 	systemVariables map[string]string
 	disableSetVar   bool
 
 	// map different shards to keyspaces in the test.
 	ksShardMap map[string][]string
+
+	// primitiveStats are returned at the end of a primitive trace.
+	primitiveStats map[Primitive]*PrimitiveStats
+
+	// throttledChecks is the number of throttler checks that throttle,
+	// before the throttler lets writes through.
+	throttledChecks int
+
+	// vschemaErr is returned by ExecuteVSchema.
+	vschemaErr error
+
+	// twoPC is returned by TwoPCEnabled.
+	twoPC bool
 }
 
 type tableRoutes struct {
@@ -374,6 +431,13 @@ func (f *loggingVCursor) StreamExecutePrimitive(primitive Primitive, bindVars ma
 	return primitive.TryStreamExecute(f, bindVars, wantfields, callback)
 }
 
+func (f *loggingVCursor) StartPrimitiveTrace() func() map[Primitive]*PrimitiveStats {
+	f.log = append(f.log, "StartPrimitiveTrace")
+	return func() map[Primitive]*PrimitiveStats {
+		return f.primitiveStats
+	}
+}
+
 func (f *loggingVCursor) KeyspaceAvailable(ks string) bool {
 	return f.ksAvailable
 }
@@ -417,11 +481,12 @@ func (f *loggingVCursor) InReservedConn() bool {
 }
 
 func (f *loggingVCursor) ShardSession() []*srvtopo.ResolvedShard {
-	return nil
+	return f.shardSession
 }
 
-func (f *loggingVCursor) ExecuteVSchema(string, *sqlparser.AlterVschema) error {
-	panic("implement me")
+func (f *loggingVCursor) ExecuteVSchema(keyspace string, vschemaDDL *sqlparser.AlterVschema) error {
+	f.log = append(f.log, fmt.Sprintf("ExecuteVSchema %s %s", keyspace, sqlparser.String(vschemaDDL)))
+	return f.vschemaErr
 }
 
 func (f *loggingVCursor) Session() SessionActions {
@@ -682,6 +747,14 @@ func (f *loggingVCursor) SetSkipQueryPlanCache(bool) error {
 	panic("implement me")
 }
 
+func (f *loggingVCursor) SetQueryRetry(bool) error {
+	panic("implement me")
+}
+
+func (f *loggingVCursor) SetQueryMemoryLimit(int64) {
+	panic("implement me")
+}
+
 func (f *loggingVCursor) SetSQLSelectLimit(int64) error {
 	panic("implement me")
 }
@@ -690,10 +763,18 @@ func (f *loggingVCursor) SetTransactionMode(vtgatepb.TransactionMode) {
 	panic("implement me")
 }
 
+func (f *loggingVCursor) TwoPCEnabled() bool {
+	return f.twoPC
+}
+
 func (f *loggingVCursor) SetWorkload(querypb.ExecuteOptions_Workload) {
 	panic("implement me")
 }
 
+func (f *loggingVCursor) SetPriority(string) {
+	panic("implement me")
+}
+
 func (f *loggingVCursor) SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion) {
 	panic("implement me")
 }
@@ -707,6 +788,15 @@ func (f *loggingVCursor) GetDBDDLPluginName() string {
 	return f.dbDDLPlugin
 }
 
+func (f *loggingVCursor) CheckThrottler(rs *srvtopo.ResolvedShard, appName string) (bool, error) {
+	f.log = append(f.log, fmt.Sprintf("CheckThrottler %s %s %s", rs.Target.Keyspace, rs.Target.Shard, appName))
+	if f.throttledChecks > 0 {
+		f.throttledChecks--
+		return false, nil
+	}
+	return true, nil
+}
+
 func (f *loggingVCursor) nextResult() (*sqltypes.Result, error) {
 	if f.results == nil || f.curResult >= len(f.results) {
 		return &sqltypes.Result{}, f.resultErr
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Primitive = (*FkCascade)(nil)
+
+// FkCascade is a primitive that enforces the foreign keys that reference the
+// rows changed by a DELETE or an UPDATE of a parent table, in a keyspace
+// whose foreign keys are managed by vtgate. Selection selects the referenced
+// values of the rows to change, and the action of every child foreign key is
+// executed on the child rows that reference them, before Parent changes the
+// rows.
+type FkCascade struct {
+	// Selection selects, and locks, the referenced columns of the rows
+	// changed by Parent.
+	Selection Primitive
+	// Children are the actions on the child tables.
+	Children []*FkChild
+	// Parent is the DML of the parent table.
+	Parent Primitive
+	// RequiresTwoPC is set if the child rows that are changed can be on
+	// other shards than the parent rows, which is the case in a sharded
+	// keyspace. The changes are then atomic only if the transaction is
+	// committed with two-phase commit, which is required.
+	RequiresTwoPC bool
+}
+
+// FkChild is the action of a child foreign key on the child rows that
+// reference the rows changed by the DML of the parent table.
+type FkChild struct {
+	// Name is the name of the foreign key.
+	Name string
+	// BvName is the name of the list bind variable of the referenced values.
+	BvName string
+	// Col is the offset of the referenced column in the rows of Selection.
+	Col int
+	// Restrict is set if the foreign key forbids the change of referenced
+	// rows. Exec then selects the child rows, and the DML fails if there are
+	// any.
+	Restrict bool
+	// Exec is the query executed on the child rows.
+	Exec Primitive
+}
+
+// RouteType implements the Primitive interface
+func (fkc *FkCascade) RouteType() string {
+	return "FkCascade"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (fkc *FkCascade) GetKeyspaceName() string {
+	return fkc.Parent.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (fkc *FkCascade) GetTableName() string {
+	return fkc.Parent.GetTableName()
+}
+
+// GetFields implements the Primitive interface
+func (fkc *FkCascade) GetFields(VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unreachable code for %q", fkc.RouteType())
+}
+
+// NeedsTransaction implements the Primitive interface. The child and the
+// parent rows must change atomically.
+func (fkc *FkCascade) NeedsTransaction() bool {
+	return true
+}
+
+// TryExecute implements the Primitive interface
+func (fkc *FkCascade) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if fkc.RequiresTwoPC && !vcursor.Session().TwoPCEnabled() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "foreign key actions that change rows across shards require transaction_mode TWOPC")
+	}
+	selected, err := vcursor.ExecutePrimitive(fkc.Selection, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range fkc.Children {
+		values := referencedValues(selected.Rows, child.Col)
+		if len(values.Values) == 0 {
+			continue
+		}
+		childVars := copyBindVars(bindVars)
+		childVars[child.BvName] = values
+		qr, err := vcursor.ExecutePrimitive(child.Exec, childVars, false)
+		if err != nil {
+			return nil, err
+		}
+		if child.Restrict && len(qr.Rows) > 0 {
+			return nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.RowIsReferenced2, "Cannot delete or update a parent row: a foreign key constraint fails (%s)", child.Name)
+		}
+	}
+	return vcursor.ExecutePrimitive(fkc.Parent, bindVars, wantfields)
+}
+
+// TryStreamExecute implements the Primitive interface
+func (fkc *FkCascade) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := fkc.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// Inputs implements the Primitive interface
+func (fkc *FkCascade) Inputs() []Primitive {
+	inputs := []Primitive{fkc.Selection}
+	for _, child := range fkc.Children {
+		inputs = append(inputs, child.Exec)
+	}
+	return append(inputs, fkc.Parent)
+}
+
+func (fkc *FkCascade) description() PrimitiveDescription {
+	var children []map[string]any
+	for _, child := range fkc.Children {
+		desc := map[string]any{
+			"Name":   child.Name,
+			"BvName": child.BvName,
+			"Col":    child.Col,
+		}
+		if child.Restrict {
+			desc["Restrict"] = true
+		}
+		children = append(children, desc)
+	}
+	other := map[string]any{
+		"Children": children,
+	}
+	if fkc.RequiresTwoPC {
+		other["RequiresTwoPC"] = true
+	}
+	return PrimitiveDescription{
+		OperatorType: fkc.RouteType(),
+		Other:        other,
+	}
+}
+
+// referencedValue is the key of a referenced value: the values are
+// deduplicated by their type and their raw bytes.
+type referencedValue struct {
+	typ querypb.Type
+	raw string
+}
+
+// referencedValues returns the list bind variable of the distinct values of
+// the column of the rows. NULL values reference no row and are skipped.
+func referencedValues(rows []sqltypes.Row, col int) *querypb.BindVariable {
+	bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+	seen := make(map[referencedValue]bool)
+	for _, row := range rows {
+		val := row[col]
+		key := referencedValue{typ: val.Type(), raw: string(val.Raw())}
+		if val.IsNull() || seen[key] {
+			continue
+		}
+		seen[key] = true
+		bv.Values = append(bv.Values, sqltypes.ValueToProto(val))
+	}
+	return bv
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestFkCascadeExecute(t *testing.T) {
+	selection := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|code", "int64|varchar"),
+				"1|a",
+				"2|null",
+				"1|b",
+			),
+		},
+	}
+	byID := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 3}}}
+	byCode := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 2}}}
+	parent := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 2}}}
+	bv := map[string]*querypb.BindVariable{
+		"a": sqltypes.Int64BindVariable(10),
+	}
+
+	fkc := &FkCascade{
+		Selection: selection,
+		Children: []*FkChild{
+			{Name: "fk_id", BvName: "fk_id", Col: 0, Exec: byID},
+			{Name: "fk_code", BvName: "fk_code", Col: 1, Exec: byCode},
+		},
+		Parent: parent,
+	}
+	qr, err := fkc.TryExecute(&noopVCursor{}, bv, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, qr.RowsAffected)
+	selection.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" false`,
+	})
+	byID.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" fk_id: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} false`,
+	})
+	byCode.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" fk_code: type:TUPLE values:{type:VARCHAR value:"a"} values:{type:VARCHAR value:"b"} false`,
+	})
+	parent.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" false`,
+	})
+}
+
+func TestFkCascadeNoReferencedRows(t *testing.T) {
+	selection := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64")),
+		},
+	}
+	child := &fakePrimitive{}
+	parent := &fakePrimitive{results: []*sqltypes.Result{{}}}
+
+	fkc := &FkCascade{
+		Selection: selection,
+		Children:  []*FkChild{{Name: "fk_id", BvName: "fk_id", Exec: child}},
+		Parent:    parent,
+	}
+	_, err := fkc.TryExecute(&noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	child.ExpectLog(t, nil)
+	parent.ExpectLog(t, []string{
+		`Execute  false`,
+	})
+}
+
+func TestFkCascadeRestrict(t *testing.T) {
+	selection := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+		},
+	}
+	child := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1"),
+		},
+	}
+	parent := &fakePrimitive{results: []*sqltypes.Result{{}}}
+
+	fkc := &FkCascade{
+		Selection: selection,
+		Children:  []*FkChild{{Name: "fk_id", BvName: "fk_id", Restrict: true, Exec: child}},
+		Parent:    parent,
+	}
+	_, err := fkc.TryExecute(&noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "Cannot delete or update a parent row: a foreign key constraint fails (fk_id)")
+	require.Equal(t, vterrors.RowIsReferenced2, vterrors.ErrState(err))
+	parent.ExpectLog(t, nil)
+}
+
+func TestFkCascadeRequiresTwoPC(t *testing.T) {
+	newFkCascade := func() (*FkCascade, *fakePrimitive) {
+		parent := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+		return &FkCascade{
+			Selection: &fakePrimitive{
+				results: []*sqltypes.Result{
+					sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+				},
+			},
+			Children: []*FkChild{{
+				Name:   "fk_id",
+				BvName: "fk_id",
+				Exec:   &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}},
+			}},
+			Parent:        parent,
+			RequiresTwoPC: true,
+		}, parent
+	}
+
+	fkc, parent := newFkCascade()
+	_, err := fkc.TryExecute(&loggingVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "foreign key actions that change rows across shards require transaction_mode TWOPC")
+	parent.ExpectLog(t, nil)
+
+	fkc, parent = newFkCascade()
+	qr, err := fkc.TryExecute(&loggingVCursor{twoPC: true}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, qr.RowsAffected)
+	parent.ExpectLog(t, []string{
+		`Execute  false`,
+	})
+}
+
+func TestFkCascadeTypedValues(t *testing.T) {
+	// Values of different types are distinct, even if they print the same.
+	selection := &fakePrimitive{
+		results: []*sqltypes.Result{{
+			Fields: sqltypes.MakeTestFields("id", "varbinary"),
+			Rows: []sqltypes.Row{
+				{sqltypes.NewVarBinary("1")},
+				{sqltypes.NewInt64(1)},
+				{sqltypes.NewVarBinary("1")},
+			},
+		}},
+	}
+	child := &fakePrimitive{results: []*sqltypes.Result{{}}}
+	fkc := &FkCascade{
+		Selection: selection,
+		Children:  []*FkChild{{Name: "fk_id", BvName: "fk_id", Exec: child}},
+		Parent:    &fakePrimitive{results: []*sqltypes.Result{{}}},
+	}
+	_, err := fkc.TryExecute(&noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	child.ExpectLog(t, []string{
+		`Execute fk_id: type:TUPLE values:{type:VARBINARY value:"1"} values:{type:INT64 value:"1"} false`,
+	})
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Primitive = (*FkVerify)(nil)
+
+// FkVerify is a primitive that verifies, before the INSERT or the UPDATE of
+// a child table in a keyspace whose foreign keys are managed by vtgate, that
+// the values written to the columns of its foreign keys reference rows of the
+// parent tables.
+type FkVerify struct {
+	// Verify are the verifications of the foreign keys.
+	Verify []*FkParent
+	// Exec is the DML of the child table.
+	Exec Primitive
+}
+
+// FkParent verifies the values written to the column of a foreign key.
+type FkParent struct {
+	// Name is the name of the foreign key.
+	Name string
+	// BvName is the name of the list bind variable of the written values.
+	BvName string
+	// Values are the values written to the column of the foreign key.
+	Values []evalengine.Expr
+	// Exec selects, and locks, the referenced column of the parent rows
+	// whose value is in the list bound to BvName.
+	Exec Primitive
+}
+
+// RouteType implements the Primitive interface
+func (fkv *FkVerify) RouteType() string {
+	return "FkVerify"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (fkv *FkVerify) GetKeyspaceName() string {
+	return fkv.Exec.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (fkv *FkVerify) GetTableName() string {
+	return fkv.Exec.GetTableName()
+}
+
+// GetFields implements the Primitive interface
+func (fkv *FkVerify) GetFields(VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unreachable code for %q", fkv.RouteType())
+}
+
+// NeedsTransaction implements the Primitive interface. The parent rows must
+// stay locked until the child rows are written.
+func (fkv *FkVerify) NeedsTransaction() bool {
+	return true
+}
+
+// TryExecute implements the Primitive interface
+func (fkv *FkVerify) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	env := evalengine.EnvWithBindVars(bindVars, vcursor.ConnCollation())
+	for _, parent := range fkv.Verify {
+		var values []sqltypes.Value
+		for _, expr := range parent.Values {
+			res, err := env.Evaluate(expr)
+			if err != nil {
+				return nil, err
+			}
+			if val := res.Value(); !val.IsNull() {
+				values = append(values, val)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+		for _, val := range values {
+			bv.Values = append(bv.Values, sqltypes.ValueToProto(val))
+		}
+		parentVars := copyBindVars(bindVars)
+		parentVars[parent.BvName] = bv
+		qr, err := vcursor.ExecutePrimitive(parent.Exec, parentVars, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, val := range values {
+			found, err := containsValue(qr.Rows, val, vcursor)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.NoReferencedRow2, "Cannot add or update a child row: a foreign key constraint fails (%s)", parent.Name)
+			}
+		}
+	}
+	return vcursor.ExecutePrimitive(fkv.Exec, bindVars, wantfields)
+}
+
+func containsValue(rows []sqltypes.Row, val sqltypes.Value, vcursor VCursor) (bool, error) {
+	for _, row := range rows {
+		cmp, err := evalengine.NullsafeCompare(row[0], val, vcursor.ConnCollation())
+		if err != nil {
+			return false, err
+		}
+		if cmp == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (fkv *FkVerify) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := fkv.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// Inputs implements the Primitive interface
+func (fkv *FkVerify) Inputs() []Primitive {
+	var inputs []Primitive
+	for _, parent := range fkv.Verify {
+		inputs = append(inputs, parent.Exec)
+	}
+	return append(inputs, fkv.Exec)
+}
+
+func (fkv *FkVerify) description() PrimitiveDescription {
+	var verify []map[string]any
+	for _, parent := range fkv.Verify {
+		var values []string
+		for _, expr := range parent.Values {
+			values = append(values, evalengine.FormatExpr(expr))
+		}
+		verify = append(verify, map[string]any{
+			"Name":   parent.Name,
+			"BvName": parent.BvName,
+			"Values": values,
+		})
+	}
+	return PrimitiveDescription{
+		OperatorType: fkv.RouteType(),
+		Other: map[string]any{
+			"Verify": verify,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestFkVerifyExecute(t *testing.T) {
+	parentRows := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2"),
+		},
+	}
+	exec := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 3}}}
+	bv := map[string]*querypb.BindVariable{
+		"v": sqltypes.Int64BindVariable(2),
+	}
+
+	fkv := &FkVerify{
+		Verify: []*FkParent{{
+			Name:   "fk_id",
+			BvName: "fk_id",
+			Values: []evalengine.Expr{
+				evalengine.NewLiteralInt(1),
+				evalengine.NewBindVar("v", collations.TypedCollation{}),
+				evalengine.NullExpr,
+			},
+			Exec: parentRows,
+		}},
+		Exec: exec,
+	}
+	qr, err := fkv.TryExecute(&noopVCursor{}, bv, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, qr.RowsAffected)
+	parentRows.ExpectLog(t, []string{
+		`Execute fk_id: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} v: type:INT64 value:"2" false`,
+	})
+	exec.ExpectLog(t, []string{
+		`Execute v: type:INT64 value:"2" false`,
+	})
+}
+
+func TestFkVerifyNoReferencedRow(t *testing.T) {
+	parentRows := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+		},
+	}
+	exec := &fakePrimitive{}
+
+	fkv := &FkVerify{
+		Verify: []*FkParent{{
+			Name:   "fk_id",
+			BvName: "fk_id",
+			Values: []evalengine.Expr{evalengine.NewLiteralInt(1), evalengine.NewLiteralInt(3)},
+			Exec:   parentRows,
+		}},
+		Exec: exec,
+	}
+	_, err := fkv.TryExecute(&noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "Cannot add or update a child row: a foreign key constraint fails (fk_id)")
+	require.Equal(t, vterrors.NoReferencedRow2, vterrors.ErrState(err))
+	exec.ExpectLog(t, nil)
+}
@@ -69,6 +69,11 @@ func (hj *HashJoin) TryExecute(vcursor VCursor, bindVars map[string]*querypb.Bin
 		return nil, err
 	}
 
+	// the probe table holds the LHS result
+	if err := vcursor.ReserveMemory(rowsMemorySize(lresult.Rows)); err != nil {
+		return nil, err
+	}
+
 	// build the probe table from the LHS result
 	probeTable, err := hj.buildProbeTable(lresult)
 	if err != nil {
@@ -104,7 +109,11 @@ func (hj *HashJoin) TryExecute(vcursor VCursor, bindVars map[string]*querypb.Bin
 
 			if cmp == 0 {
 				// we have a match!
-				result.Rows = append(result.Rows, joinRows(currentLHSRow, currentRHSRow, hj.Cols))
+				row := joinRows(currentLHSRow, currentRHSRow, hj.Cols)
+				if err := vcursor.ReserveMemory(rowMemorySize(row)); err != nil {
+					return nil, err
+				}
+				result.Rows = append(result.Rows, row)
 			}
 		}
 	}
@@ -19,6 +19,7 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"strconv"
 	"strings"
 	"time"
@@ -483,6 +484,41 @@ func shouldGenerate(v sqltypes.Value) bool {
 	return false
 }
 
+// next fetches count values from the sequence, and returns the first one.
+// The sequence of a sharded keyspace is served by all of its shards, which
+// are tried in random order until one of them succeeds.
+func (gen *Generate) next(vcursor VCursor, count int64) (int64, error) {
+	var dest key.Destination = key.DestinationAnyShard{}
+	if gen.Keyspace.Sharded {
+		dest = key.DestinationAllShards{}
+	}
+	rss, _, err := vcursor.ResolveDestinations(gen.Keyspace.Name, nil, []key.Destination{dest})
+	if err != nil {
+		return 0, err
+	}
+	if !gen.Keyspace.Sharded && len(rss) != 1 {
+		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
+	}
+	bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(count)}
+	var firstErr error
+	for _, i := range rand.Perm(len(rss)) {
+		qr, err := vcursor.ExecuteStandalone(gen.Query, bindVars, rss[i])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		// If no rows are returned, it's an internal error, and the code
+		// must panic, which will be caught and reported.
+		return evalengine.ToInt64(qr.Rows[0][0])
+	}
+	if firstErr == nil {
+		return 0, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no shard to generate values from sequence in keyspace %s", gen.Keyspace.Name)
+	}
+	return 0, firstErr
+}
+
 // processGenerateFromValues generates new values using a sequence if necessary.
 // If no value was generated, it returns 0. Values are generated only
 // for cases where none are supplied.
@@ -508,21 +544,7 @@ func (ins *Insert) processGenerateFromValues(vcursor VCursor, bindVars map[strin
 
 	// If generation is needed, generate the requested number of values (as one call).
 	if count != 0 {
-		rss, _, err := vcursor.ResolveDestinations(ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
-		if err != nil {
-			return 0, err
-		}
-		if len(rss) != 1 {
-			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
-		}
-		bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(count)}
-		qr, err := vcursor.ExecuteStandalone(ins.Generate.Query, bindVars, rss[0])
-		if err != nil {
-			return 0, err
-		}
-		// If no rows are returned, it's an internal error, and the code
-		// must panic, which will be caught and reported.
-		insertID, err = evalengine.ToInt64(qr.Rows[0][0])
+		insertID, err = ins.Generate.next(vcursor, count)
 		if err != nil {
 			return 0, err
 		}
@@ -566,21 +588,7 @@ func (ins *Insert) processGenerateFromRows(vcursor VCursor, rows []sqltypes.Row)
 	}
 
 	// If generation is needed, generate the requested number of values (as one call).
-	rss, _, err := vcursor.ResolveDestinations(ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
-	if err != nil {
-		return 0, err
-	}
-	if len(rss) != 1 {
-		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
-	}
-	bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(count)}
-	qr, err := vcursor.ExecuteStandalone(ins.Generate.Query, bindVars, rss[0])
-	if err != nil {
-		return 0, err
-	}
-	// If no rows are returned, it's an internal error, and the code
-	// must panic, which will be caught and reported.
-	insertID, err = evalengine.ToInt64(qr.Rows[0][0])
+	insertID, err = ins.Generate.next(vcursor, count)
 	if err != nil {
 		return 0, err
 	}
@@ -24,6 +24,7 @@ import (
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -121,6 +122,62 @@ func TestInsertUnshardedGenerate(t *testing.T) {
 	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 4})
 }
 
+func TestInsertGenerateFromShardedSequence(t *testing.T) {
+	ins := NewQueryInsert(
+		InsertUnsharded,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: false,
+		},
+		"dummy_insert",
+	)
+	ins.Generate = &Generate{
+		Keyspace: &vindexes.Keyspace{
+			Name:    "ks2",
+			Sharded: true,
+		},
+		Query: "dummy_generate",
+		Values: evalengine.NewTupleExpr(
+			evalengine.NullExpr,
+			evalengine.NullExpr,
+		),
+	}
+
+	vc := newDMLTestVCursor("0")
+	vc.ksShardMap = map[string][]string{"ks2": {"-80", "80-"}}
+	// The first shard fails, and the values are generated by the other one.
+	vc.resultErr = errors.New("shard unavailable")
+	vc.results = []*sqltypes.Result{
+		nil,
+		sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"nextval",
+				"int64",
+			),
+			"4",
+		),
+		{InsertID: 1},
+	}
+
+	result, err := ins.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.Len(t, vc.log, 5)
+	assert.Equal(t, `ResolveDestinations ks2 [] Destinations:DestinationAllShards()`, vc.log[0])
+	assert.NotEqual(t, vc.log[1], vc.log[2])
+	assert.Contains(t, vc.log[1], `ExecuteStandalone dummy_generate n: type:INT64 value:"2" ks2`)
+	assert.Contains(t, vc.log[2], `ExecuteStandalone dummy_generate n: type:INT64 value:"2" ks2`)
+	assert.Equal(t, `ExecuteMultiShard ks.0: dummy_insert {__seq0: type:INT64 value:"4" __seq1: type:INT64 value:"5"} true true`, vc.log[4])
+	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 4})
+
+	// All the shards fail.
+	vc = newDMLTestVCursor("0")
+	vc.ksShardMap = map[string][]string{"ks2": {"-80", "80-"}}
+	vc.results = []*sqltypes.Result{nil, nil}
+	vc.resultErr = errors.New("shard unavailable")
+	_, err = ins.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "shard unavailable")
+}
+
 func TestInsertUnshardedGenerate_Zeros(t *testing.T) {
 	ins := NewQueryInsert(
 		InsertUnsharded,
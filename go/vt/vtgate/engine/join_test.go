@@ -20,11 +20,14 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func TestJoinExecute(t *testing.T) {
@@ -203,6 +206,53 @@ func TestJoinExecuteMaxMemoryRows(t *testing.T) {
 	}
 }
 
+func TestJoinExecuteQueryMemoryLimit(t *testing.T) {
+	newJoin := func() *Join {
+		rightFields := sqltypes.MakeTestFields(
+			"col4|col5",
+			"int64|varchar",
+		)
+		return &Join{
+			Opcode: InnerJoin,
+			Left: &fakePrimitive{
+				results: []*sqltypes.Result{
+					sqltypes.MakeTestResult(
+						sqltypes.MakeTestFields(
+							"col1|col2",
+							"int64|varchar",
+						),
+						"1|a",
+						"2|b",
+					),
+				},
+			},
+			Right: &fakePrimitive{
+				results: []*sqltypes.Result{
+					sqltypes.MakeTestResult(rightFields, "4|d", "5|e"),
+					sqltypes.MakeTestResult(rightFields, "6|f"),
+				},
+			},
+			Cols: []int{-1, -2, 1, 2},
+			Vars: map[string]int{
+				"bv": 1,
+			},
+		}
+	}
+	// Each joined row holds 4 bytes of values.
+	resultSize := 3 * (rowMemoryOverhead + 4*valueMemoryOverhead + 4)
+
+	vc := &noopVCursor{memoryLimit: resultSize}
+	_, err := newJoin().TryExecute(vc, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, resultSize, vc.memoryUsed)
+
+	vc = &noopVCursor{memoryLimit: resultSize - 1}
+	_, err = newJoin().TryExecute(vc, nil, true)
+	require.Error(t, err)
+	assert.Equal(t, vterrors.CapacityExceeded, vterrors.ErrState(err))
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+}
+
 func TestJoinExecuteNoResult(t *testing.T) {
 	leftPrim := &fakePrimitive{
 		results: []*sqltypes.Result{
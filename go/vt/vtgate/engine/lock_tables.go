@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ Primitive = (*LockTables)(nil)
+
+// LockTables is the primitive for `LOCK TABLES`, `UNLOCK TABLES` and
+// `FLUSH TABLES ... WITH READ LOCK`. Table locks are held by the MySQL
+// connection that takes them, so the statements are executed on the
+// reserved connection of the session, which stays open until the
+// session is closed.
+type LockTables struct {
+	// Keyspace specifies the keyspace to send the query to.
+	// It is not set to unlock the tables, which are unlocked on all the
+	// connections of the session.
+	Keyspace *vindexes.Keyspace
+
+	// TargetDestination specifies an explicit target destination to send the query to.
+	TargetDestination key.Destination
+
+	// Query specifies the query to be executed.
+	Query string
+
+	// Unlock is true if the query releases the table locks held by the session.
+	Unlock bool
+
+	// QueryTimeout contains the optional timeout (in milliseconds) to wait for the locks.
+	QueryTimeout int
+
+	noInputs
+
+	noTxNeeded
+}
+
+// RouteType is part of the Primitive interface
+func (l *LockTables) RouteType() string {
+	return "LockTables"
+}
+
+// GetKeyspaceName is part of the Primitive interface
+func (l *LockTables) GetKeyspaceName() string {
+	if l.Keyspace == nil {
+		return ""
+	}
+	return l.Keyspace.Name
+}
+
+// GetTableName is part of the Primitive interface
+func (l *LockTables) GetTableName() string {
+	return ""
+}
+
+// TryExecute is part of the Primitive interface
+func (l *LockTables) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	if l.Unlock {
+		return l.unlock(vcursor, bindVars)
+	}
+
+	rss, _, err := vcursor.ResolveDestinations(l.Keyspace.Name, nil, []key.Destination{l.TargetDestination})
+	if err != nil {
+		return nil, err
+	}
+	if len(rss) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "lock tables can be routed to single shard only: %v", rss)
+	}
+
+	if l.QueryTimeout != 0 {
+		cancel := vcursor.SetContextTimeout(time.Duration(l.QueryTimeout) * time.Millisecond)
+		defer cancel()
+	}
+
+	vcursor.Session().NeedsReservedConn()
+	queries := []*querypb.BoundQuery{{
+		Sql:           l.Query,
+		BindVariables: bindVars,
+	}}
+	qr, errs := vcursor.ExecuteMultiShard(rss, queries, false /* rollbackOnError */, false /* canAutocommit */)
+	if err := vterrors.Aggregate(errs); err != nil {
+		return nil, err
+	}
+	return qr, nil
+}
+
+// unlock releases the table locks on every connection of the session. Only
+// the connections that are already open can hold table locks, if there is
+// none it is a no-op as it is in mysql.
+func (l *LockTables) unlock(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	rss := vcursor.Session().ShardSession()
+	if len(rss) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+	queries := make([]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = &querypb.BoundQuery{
+			Sql:           l.Query,
+			BindVariables: bindVars,
+		}
+	}
+	_, errs := vcursor.ExecuteMultiShard(rss, queries, false /* rollbackOnError */, false /* canAutocommit */)
+	if err := vterrors.Aggregate(errs); err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{}, nil
+}
+
+// TryStreamExecute is part of the Primitive interface
+func (l *LockTables) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	qr, err := l.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(qr)
+}
+
+// GetFields is part of the Primitive interface
+func (l *LockTables) GetFields(VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{}, nil
+}
+
+func (l *LockTables) description() PrimitiveDescription {
+	other := map[string]any{
+		"Query": l.Query,
+	}
+	if l.Unlock {
+		other["Unlock"] = true
+	}
+	if l.QueryTimeout != 0 {
+		other["QueryTimeout"] = l.QueryTimeout
+	}
+	return PrimitiveDescription{
+		OperatorType:      "LockTables",
+		Keyspace:          l.Keyspace,
+		TargetDestination: l.TargetDestination,
+		Other:             other,
+	}
+}
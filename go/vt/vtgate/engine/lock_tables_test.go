@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestLockTables(t *testing.T) {
+	lock := &LockTables{
+		Keyspace:          &vindexes.Keyspace{Name: "ks"},
+		TargetDestination: key.DestinationAnyShard{},
+		Query:             "lock tables t read",
+		QueryTimeout:      100,
+	}
+
+	vc := &loggingVCursor{shards: []string{"0"}}
+	_, err := lock.TryExecute(vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAnyShard()`,
+		`Needs Reserved Conn`,
+		`ExecuteMultiShard ks.0: lock tables t read {} false false`,
+	})
+	// The locks are taken on the reserved connection of the session.
+	assert.True(t, vc.InReservedConn())
+
+	vc = &loggingVCursor{shards: []string{"0"}, multiShardErrs: []error{errors.New("lock wait timeout")}}
+	_, err = lock.TryExecute(vc, nil, true)
+	require.EqualError(t, err, "lock wait timeout")
+}
+
+func TestUnlockTables(t *testing.T) {
+	unlock := &LockTables{
+		Query:  "unlock tables",
+		Unlock: true,
+	}
+
+	// Without any connection there is no lock to release.
+	vc := &loggingVCursor{}
+	_, err := unlock.TryExecute(vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, nil)
+
+	// The tables are unlocked on the connections of all the keyspaces.
+	vc = &loggingVCursor{shardSession: []*srvtopo.ResolvedShard{
+		{Target: &querypb.Target{Keyspace: "ks1", Shard: "0"}},
+		{Target: &querypb.Target{Keyspace: "ks2", Shard: "0"}},
+	}}
+	_, err = wrapStreamExecute(unlock, vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ExecuteMultiShard ks1.0: unlock tables {} ks2.0: unlock tables {} false false`,
+	})
+}
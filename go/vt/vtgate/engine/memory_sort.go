@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strings"
 
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -32,7 +33,9 @@ import (
 
 var _ Primitive = (*MemorySort)(nil)
 
-// MemorySort is a primitive that performs in-memory sorting.
+// MemorySort is a primitive that performs in-memory sorting. When streaming,
+// it spills the rows to disk once they exceed the memory budget of the query,
+// and merges them back, see sortSpill.
 type MemorySort struct {
 	UpperLimit evalengine.Expr
 	OrderBy    []OrderByParams
@@ -75,6 +78,9 @@ func (ms *MemorySort) TryExecute(vcursor VCursor, bindVars map[string]*querypb.B
 	if err != nil {
 		return nil, err
 	}
+	if err := vcursor.ReserveMemory(rowsMemorySize(result.Rows)); err != nil {
+		return nil, err
+	}
 	sh := &sortHeap{
 		rows:      result.Rows,
 		comparers: extractSlices(ms.OrderBy),
@@ -107,6 +113,15 @@ func (ms *MemorySort) TryStreamExecute(vcursor VCursor, bindVars map[string]*que
 		comparers: extractSlices(ms.OrderBy),
 		reverse:   true,
 	}
+	// The memory held by the heap is released once it is sent, or spilled.
+	// Once the rows exceed the memory budget of the query, they are spilled
+	// to disk as sorted runs, which are merged at the end.
+	var reserved int64
+	spill := &sortSpill{}
+	defer func() {
+		vcursor.ReleaseMemory(reserved)
+		spill.close()
+	}()
 	err = vcursor.StreamExecutePrimitive(ms.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
 		if len(qr.Fields) != 0 {
 			if err := cb(&sqltypes.Result{Fields: qr.Fields}); err != nil {
@@ -114,11 +129,30 @@ func (ms *MemorySort) TryStreamExecute(vcursor VCursor, bindVars map[string]*que
 			}
 		}
 		for _, row := range qr.Rows {
+			size := rowMemorySize(row)
+			if err := vcursor.ReserveMemory(size); err != nil {
+				vcursor.ReleaseMemory(size)
+				if vterrors.ErrState(err) != vterrors.CapacityExceeded || len(sh.rows) == 0 {
+					return err
+				}
+				if err := spill.spill(sh, count); err != nil {
+					return err
+				}
+				vcursor.ReleaseMemory(reserved)
+				reserved = 0
+				if err := vcursor.ReserveMemory(size); err != nil {
+					vcursor.ReleaseMemory(size)
+					return err
+				}
+			}
+			reserved += size
 			heap.Push(sh, row)
 			// Remove the highest element from the heap if the size is more than the count
 			// This optimization means that the maximum size of the heap is going to be (count + 1)
 			for len(sh.rows) > count {
-				_ = heap.Pop(sh)
+				size := rowMemorySize(heap.Pop(sh).([]sqltypes.Value))
+				vcursor.ReleaseMemory(size)
+				reserved -= size
 			}
 		}
 		if vcursor.ExceedsMaxMemoryRows(len(sh.rows)) {
@@ -132,6 +166,16 @@ func (ms *MemorySort) TryStreamExecute(vcursor VCursor, bindVars map[string]*que
 	if sh.err != nil {
 		return sh.err
 	}
+	if len(spill.runs) > 0 {
+		if err := spill.spill(sh, count); err != nil {
+			return err
+		}
+		vcursor.ReleaseMemory(reserved)
+		reserved = 0
+		return spill.merge(sh.comparers, count, func(rows [][]sqltypes.Value) error {
+			return cb(&sqltypes.Result{Rows: rows})
+		})
+	}
 	// Set ordering to normal for the final ordering.
 	sh.reverse = false
 	sort.Sort(sh)
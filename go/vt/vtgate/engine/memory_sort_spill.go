@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"container/heap"
+	"io"
+	"sort"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// spillMergeBatchSize is the number of rows a MemorySort returns at once
+// when it merges the runs it spilled to disk.
+const spillMergeBatchSize = 1000
+
+// spillMaxRuns is the number of runs a MemorySort keeps open. Once it spills
+// more, its runs are merged into one, so that a large sort with a low memory
+// limit does not run out of file descriptors.
+var spillMaxRuns = 64
+
+// sortSpill holds the sorted runs a streaming MemorySort spilled to
+// temporary files, once its rows exceeded the memory budget of the query.
+type sortSpill struct {
+	runs []*spillFile
+}
+
+// spill sorts the rows of the heap, writes them to a new run, and empties
+// the heap. Only the first count rows of the sort are kept when the runs
+// are merged.
+func (ss *sortSpill) spill(sh *sortHeap, count int) error {
+	reverse := sh.reverse
+	sh.reverse = false
+	sort.Sort(sh)
+	sh.reverse = reverse
+	if sh.err != nil {
+		return sh.err
+	}
+
+	run, err := newSpillFile()
+	if err != nil {
+		return err
+	}
+	ss.runs = append(ss.runs, run)
+	for _, row := range sh.rows {
+		if err := run.write(row); err != nil {
+			return err
+		}
+	}
+	sh.rows = nil
+	if len(ss.runs) >= spillMaxRuns {
+		return ss.compact(sh.comparers, count)
+	}
+	return nil
+}
+
+// compact merges the runs into a single one.
+func (ss *sortSpill) compact(comparers []*comparer, count int) error {
+	merged, err := newSpillFile()
+	if err != nil {
+		return err
+	}
+	err = ss.merge(comparers, count, func(rows [][]sqltypes.Value) error {
+		for _, row := range rows {
+			if err := merged.write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	ss.close()
+	ss.runs = []*spillFile{merged}
+	return err
+}
+
+// merge merges the sorted runs, and calls emit with their first count rows,
+// in order, in batches.
+func (ss *sortSpill) merge(comparers []*comparer, count int, emit func(rows [][]sqltypes.Value) error) error {
+	readers := make([]*spillReader, len(ss.runs))
+	sh := &scatterHeap{
+		rows:      make([]streamRow, 0, len(ss.runs)),
+		comparers: comparers,
+	}
+	// Prime the heap with the first row of each run.
+	for i, run := range ss.runs {
+		sr, err := run.reader()
+		if err != nil {
+			return err
+		}
+		readers[i] = sr
+		row, err := sr.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		sh.rows = append(sh.rows, streamRow{row: row, id: i})
+	}
+	heap.Init(sh)
+	if sh.err != nil {
+		return sh.err
+	}
+
+	var batch [][]sqltypes.Value
+	for len(sh.rows) != 0 && count > 0 {
+		sr := heap.Pop(sh).(streamRow)
+		if sh.err != nil {
+			return sh.err
+		}
+		batch = append(batch, sr.row)
+		count--
+		if len(batch) == spillMergeBatchSize {
+			if err := emit(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+
+		row, err := readers[sr.id].next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		sr.row = row
+		heap.Push(sh, sr)
+		if sh.err != nil {
+			return sh.err
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return emit(batch)
+}
+
+// close removes the temporary files.
+func (ss *sortSpill) close() {
+	for _, run := range ss.runs {
+		run.close()
+	}
+}
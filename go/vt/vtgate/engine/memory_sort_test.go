@@ -17,13 +17,17 @@ limitations under the License.
 package engine
 
 import (
+	"fmt"
+	"os"
 	"testing"
 
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/test/utils"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -612,6 +616,191 @@ func TestMemorySortMaxMemoryRows(t *testing.T) {
 	}
 }
 
+func TestMemorySortQueryMemoryLimit(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	newMemorySort := func() *MemorySort {
+		return &MemorySort{
+			OrderBy: []OrderByParams{{
+				WeightStringCol: -1,
+				Col:             1,
+			}},
+			Input: &fakePrimitive{
+				results: []*sqltypes.Result{sqltypes.MakeTestResult(
+					fields,
+					"a|1",
+					"b|2",
+					"a|1",
+					"c|4",
+					"c|3",
+				)},
+			},
+		}
+	}
+	// Each row holds 2 bytes of values.
+	rowSize := rowMemoryOverhead + 2*valueMemoryOverhead + 2
+
+	vc := &noopVCursor{memoryLimit: 5*rowSize - 1}
+	_, err := newMemorySort().TryExecute(vc, nil, false)
+	require.EqualError(t, err, fmt.Sprintf("query memory limit of %d bytes exceeded, see --query_memory_limit and @@query_memory_limit", 5*rowSize-1))
+	assert.Equal(t, vterrors.CapacityExceeded, vterrors.ErrState(err))
+
+	// The streaming sort only holds the rows of its heap, up to its limit.
+	ms := newMemorySort()
+	ms.UpperLimit = evalengine.NewLiteralInt(2)
+	vc = &noopVCursor{memoryLimit: 3 * rowSize}
+	err = ms.TryStreamExecute(vc, nil, false, func(qr *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Zero(t, vc.memoryUsed)
+
+	// A row that exceeds the limit on its own cannot be spilled.
+	ms.Input.(*fakePrimitive).rewind()
+	vc = &noopVCursor{memoryLimit: rowSize - 1}
+	err = ms.TryStreamExecute(vc, nil, false, func(qr *sqltypes.Result) error {
+		return nil
+	})
+	require.Error(t, err)
+	assert.Zero(t, vc.memoryUsed)
+}
+
+func TestMemorySortSpill(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1",
+			"b|2",
+			"a|1",
+			"c|4",
+			"c|3",
+			"d|null",
+			"e|0",
+			"f|5",
+		)},
+	}
+	ms := &MemorySort{
+		OrderBy: []OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		Input: fp,
+	}
+	rowSize := rowMemoryOverhead + 2*valueMemoryOverhead + 2
+
+	// The rows are spilled to runs of 2 rows, and merged.
+	vc := &noopVCursor{memoryLimit: 2 * rowSize}
+	result, err := wrapStreamExecute(ms, vc, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"d|null",
+		"e|0",
+		"a|1",
+		"a|1",
+		"b|2",
+		"c|3",
+		"c|4",
+		"f|5",
+	), result)
+	assert.Zero(t, vc.memoryUsed)
+
+	// The limit applies to the merged runs.
+	fp.rewind()
+	ms.UpperLimit = evalengine.NewLiteralInt(4)
+	vc = &noopVCursor{memoryLimit: 3 * rowSize}
+	result, err = wrapStreamExecute(ms, vc, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"d|null",
+		"e|0",
+		"a|1",
+		"a|1",
+	), result)
+
+	// The runs are removed once merged.
+	files, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestMemorySortSpillLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	SetSpillOptions(tmpDir, 0)
+	defer SetSpillOptions("", 0)
+	saveMaxRuns := spillMaxRuns
+	spillMaxRuns = 2
+	defer func() { spillMaxRuns = saveMaxRuns }()
+
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1",
+			"b|2",
+			"a|1",
+			"c|4",
+			"c|3",
+			"d|null",
+			"e|0",
+			"f|5",
+		)},
+	}
+	ms := &MemorySort{
+		OrderBy: []OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		Input: fp,
+	}
+	rowSize := rowMemoryOverhead + 2*valueMemoryOverhead + 2
+
+	// Every row is spilled to its own run, and the runs are merged as soon
+	// as there are two of them.
+	vc := &noopVCursor{memoryLimit: rowSize}
+	result, err := wrapStreamExecute(ms, vc, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		fields,
+		"d|null",
+		"e|0",
+		"a|1",
+		"a|1",
+		"b|2",
+		"c|3",
+		"c|4",
+		"f|5",
+	), result)
+	files, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	assert.Zero(t, spillDiskBytes.Get())
+
+	// The spill files of the queries cannot exceed the limit on disk.
+	SetSpillOptions(tmpDir, 10)
+	fp.rewind()
+	vc = &noopVCursor{memoryLimit: 2 * rowSize}
+	_, err = wrapStreamExecute(ms, vc, nil, true)
+	assert.ErrorContains(t, err, "query spill files exceeded 10 bytes on disk")
+	files, err = os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	assert.Zero(t, spillDiskBytes.Get())
+}
+
 func TestMemorySortExecuteNoVarChar(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"c1|c2",
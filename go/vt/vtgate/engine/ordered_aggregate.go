@@ -239,6 +239,9 @@ func (oa *OrderedAggregate) execute(vcursor VCursor, bindVars map[string]*queryp
 	if err != nil {
 		return nil, err
 	}
+	if err := vcursor.ReserveMemory(rowsMemorySize(result.Rows)); err != nil {
+		return nil, err
+	}
 	out := &sqltypes.Result{
 		Fields: convertFields(result.Fields, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine),
 		Rows:   make([][]sqltypes.Value, 0, len(result.Rows)),
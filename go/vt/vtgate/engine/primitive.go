@@ -64,6 +64,15 @@ type (
 		// if the max memory rows override directive is set to true
 		ExceedsMaxMemoryRows(numRows int) bool
 
+		// ReserveMemory accounts for size bytes of intermediate results
+		// held by a primitive. It returns an error if the memory held by the
+		// query exceeds its limit, in which case the query must be aborted.
+		ReserveMemory(size int64) error
+
+		// ReleaseMemory accounts for size bytes of intermediate results
+		// that a primitive reserved and no longer holds.
+		ReleaseMemory(size int64)
+
 		// SetContextTimeout updates the context and sets a timeout.
 		SetContextTimeout(timeout time.Duration) context.CancelFunc
 
@@ -82,6 +91,11 @@ type (
 		ExecutePrimitive(primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error)
 		StreamExecutePrimitive(primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error
 
+		// StartPrimitiveTrace starts collecting the execution statistics of
+		// the primitives executed through this cursor. The returned function
+		// stops the collection and returns the statistics.
+		StartPrimitiveTrace() func() map[Primitive]*PrimitiveStats
+
 		// Shard-level functions.
 		ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error)
 		ExecuteStandalone(query string, bindvars map[string]*querypb.BindVariable, rs *srvtopo.ResolvedShard) (*sqltypes.Result, error)
@@ -130,6 +144,10 @@ type (
 
 		// ReleaseLock releases all the held advisory locks.
 		ReleaseLock() error
+
+		// CheckThrottler returns true if the lag throttler of the primary of
+		// the shard lets the given app write to it.
+		CheckThrottler(rs *srvtopo.ResolvedShard, appName string) (bool, error)
 	}
 
 	//SessionActions gives primitives ability to interact with the session state
@@ -157,7 +175,11 @@ type (
 		SetSkipQueryPlanCache(bool) error
 		SetSQLSelectLimit(int64) error
 		SetTransactionMode(vtgatepb.TransactionMode)
+		// TwoPCEnabled returns true if the transaction of the session is
+		// committed with two-phase commit.
+		TwoPCEnabled() bool
 		SetWorkload(querypb.ExecuteOptions_Workload)
+		SetPriority(string)
 		SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion)
 		SetFoundRows(uint64)
 
@@ -168,6 +190,8 @@ type (
 
 		SetSessionEnableSystemSettings(bool) error
 		GetSessionEnableSystemSettings() bool
+		SetQueryRetry(bool) error
+		SetQueryMemoryLimit(int64)
 
 		GetSystemVariables(func(k string, v string))
 		HasSystemVariables() bool
@@ -200,6 +224,10 @@ type (
 		Instructions Primitive               // Instructions contains the instructions needed to fulfil the query.
 		BindVarNeeds *sqlparser.BindVarNeeds // Stores BindVars needed to be provided as part of expression rewriting
 		Warnings     []*querypb.QueryWarning // Warnings that need to be yielded every time this query runs
+		QueryHints   *sqlparser.QueryHints   // QueryHints are the hints set by the comment directives of the query
+		Target       string                  // Target is the target string of the session the plan was built for, only kept to save the plan cache
+		Query        string                  // Query is the query the plan was built for, as sent by the client, only kept to save the plan cache
+		CacheKey     string                  // CacheKey is the key of the plan in the plan cache
 
 		ExecCount    uint64 // Count of times this plan was executed
 		ExecTime     uint64 // Total execution time
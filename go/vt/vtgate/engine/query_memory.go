@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"unsafe"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The primitives which hold intermediate results in memory, i.e. the joins,
+// the sorts and the aggregations, account for their size with
+// VCursor.ReserveMemory, so that a single query cannot exhaust the memory of
+// vtgate. The sizes are estimates: the bytes of the values, with the overhead
+// of their slices.
+
+var (
+	rowMemoryOverhead   = int64(unsafe.Sizeof(sqltypes.Row{}))
+	valueMemoryOverhead = int64(unsafe.Sizeof(sqltypes.Value{}))
+)
+
+// rowMemorySize returns the estimated number of bytes held by a row.
+func rowMemorySize(row sqltypes.Row) int64 {
+	size := rowMemoryOverhead + int64(len(row))*valueMemoryOverhead
+	for _, val := range row {
+		size += int64(len(val.Raw()))
+	}
+	return size
+}
+
+// rowsMemorySize returns the estimated number of bytes held by rows.
+func rowsMemorySize(rows []sqltypes.Row) int64 {
+	var size int64
+	for _, row := range rows {
+		size += rowMemorySize(row)
+	}
+	return size
+}
+
+// QueryMemoryLimitError returns the error of a query whose intermediate
+// results exceeded its memory limit.
+func QueryMemoryLimitError(limit int64) error {
+	return vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.CapacityExceeded, "query memory limit of %d bytes exceeded, see --query_memory_limit and @@query_memory_limit", limit)
+}
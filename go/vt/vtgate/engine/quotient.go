@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*Quotient)(nil)
+
+// Quotient is a primitive that overwrites a column of its input with the
+// integer quotient of two other columns. It recomputes averages, such as the
+// Avg_row_length of SHOW TABLE STATUS, whose operands were summed across shards.
+type Quotient struct {
+	Col      int
+	Dividend int
+	Divisor  int
+	Input    Primitive
+	noTxNeeded
+}
+
+// RouteType implements the Primitive interface
+func (q *Quotient) RouteType() string {
+	return q.Input.RouteType()
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (q *Quotient) GetKeyspaceName() string {
+	return q.Input.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (q *Quotient) GetTableName() string {
+	return q.Input.GetTableName()
+}
+
+// TryExecute implements the Primitive interface
+func (q *Quotient) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	qr, err := vcursor.ExecutePrimitive(q.Input, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+	q.compute(qr)
+	return qr, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (q *Quotient) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	return vcursor.StreamExecutePrimitive(q.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		q.compute(qr)
+		return callback(qr)
+	})
+}
+
+// compute replaces the column of every row. The quotient is NULL when either
+// operand is NULL or not an unsigned integer, and 0 when the divisor is 0,
+// like MySQL reports it for empty tables.
+func (q *Quotient) compute(qr *sqltypes.Result) {
+	for _, row := range qr.Rows {
+		if q.Col >= len(row) || q.Dividend >= len(row) || q.Divisor >= len(row) {
+			continue
+		}
+		dividend, err := strconv.ParseUint(row[q.Dividend].ToString(), 10, 64)
+		if err != nil || row[q.Dividend].IsNull() {
+			row[q.Col] = sqltypes.NULL
+			continue
+		}
+		divisor, err := strconv.ParseUint(row[q.Divisor].ToString(), 10, 64)
+		if err != nil || row[q.Divisor].IsNull() {
+			row[q.Col] = sqltypes.NULL
+			continue
+		}
+		if divisor == 0 {
+			row[q.Col] = sqltypes.NewUint64(0)
+			continue
+		}
+		row[q.Col] = sqltypes.NewUint64(dividend / divisor)
+	}
+}
+
+// GetFields implements the Primitive interface
+func (q *Quotient) GetFields(vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return q.Input.GetFields(vcursor, bindVars)
+}
+
+// Inputs implements the Primitive interface
+func (q *Quotient) Inputs() []Primitive {
+	return []Primitive{q.Input}
+}
+
+// description implements the Primitive interface
+func (q *Quotient) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType: "Quotient",
+		Other: map[string]any{
+			"Column":   q.Col,
+			"Dividend": q.Dividend,
+			"Divisor":  q.Divisor,
+		},
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestQuotient(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"name|rows|avg|length",
+		"varchar|decimal|uint64|decimal",
+	)
+	newQuotient := func() *Quotient {
+		return &Quotient{
+			Col:      2,
+			Dividend: 3,
+			Divisor:  1,
+			Input: &fakePrimitive{
+				results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+					"a|3|5|100",
+					"b|0|5|16384",
+					"c|null|5|null",
+				)},
+			},
+		}
+	}
+	want := sqltypes.MakeTestResult(fields,
+		"a|3|33|100",
+		"b|0|0|16384",
+		"c|null|null|null",
+	)
+
+	qr, err := newQuotient().TryExecute(&noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "TryExecute", qr, want)
+
+	qr, err = wrapStreamExecute(newQuotient(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "TryStreamExecute", qr, want)
+}
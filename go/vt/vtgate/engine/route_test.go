@@ -630,6 +630,49 @@ func TestMultiEqual(t *testing.T) {
 	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
 }
 
+func TestSelectRange(t *testing.T) {
+	vindex, _ := vindexes.NewNumeric("", nil)
+	sel := NewRoute(
+		Range,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.NewLiteralInt(1),
+		evalengine.NewBindVar("end", collations.TypedCollation{}),
+	}
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-"},
+		shardForKsid: []string{"-20"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	bv := map[string]*querypb.BindVariable{"end": sqltypes.Int64BindVariable(2)}
+	result, err := sel.TryExecute(vc, bv, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(0000000000000001-0000000000000003)`,
+		`ExecuteMultiShard ks.-20: dummy_select {end: type:INT64 value:"2"} false false`,
+	})
+	expectResult(t, "sel.Execute", result, defaultSelectResult)
+
+	// A NULL bound leaves the range open.
+	vc.Rewind()
+	vc.shardForKsid = []string{"-20", "20-"}
+	sel.Values[1] = evalengine.NullExpr
+	result, err = wrapStreamExecute(sel, vc, bv, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(0000000000000001-)`,
+		`StreamExecuteMulti dummy_select ks.-20: {end: type:INT64 value:"2"} ks.20-: {end: type:INT64 value:"2"} `,
+	})
+	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
+}
+
 func TestSelectLike(t *testing.T) {
 	subshard, _ := vindexes.NewCFC("cfc", map[string]string{"hash": "md5", "offsets": "[1,2]"})
 	vindex := subshard.(*vindexes.CFC).PrefixVindex()
@@ -55,9 +55,14 @@ const (
 	MultiEqual
 	// SubShard is for when we are missing one or more columns from a composite vindex
 	SubShard
+	// Range is for routing a query to the shards holding a range of
+	// values of a sequential vindex.
+	// Requires: A Sequential Vindex, and the start and end Values.
+	Range
 	// Scatter is for routing a scattered statement.
 	Scatter
-	// Next is for fetching from a sequence.
+	// Next is for fetching from a sequence. The sequence of a sharded
+	// keyspace is served by any of its shards.
 	Next
 	// DBA is used for routing DBA queries
 	// e.g: Select * from information_schema.tables where schema_name = "a"
@@ -85,6 +90,7 @@ var opName = map[Opcode]string{
 	None:          "None",
 	ByDestination: "ByDestination",
 	SubShard:      "SubShard",
+	Range:         "Range",
 }
 
 // MarshalJSON serializes the Opcode as a JSON string.
@@ -134,7 +140,14 @@ func (rp *RoutingParameters) findRoute(vcursor VCursor, bindVars map[string]*que
 		return nil, nil, nil
 	case DBA:
 		return rp.systemQuery(vcursor, bindVars)
-	case Unsharded, Next:
+	case Unsharded:
+		return rp.unsharded(vcursor, bindVars)
+	case Next:
+		if rp.Keyspace.Sharded {
+			// Every shard of a sharded keyspace serves its own
+			// interleaved blocks of the sequence.
+			return rp.anyShard(vcursor, bindVars)
+		}
 		return rp.unsharded(vcursor, bindVars)
 	case Reference:
 		return rp.anyShard(vcursor, bindVars)
@@ -163,6 +176,8 @@ func (rp *RoutingParameters) findRoute(vcursor VCursor, bindVars map[string]*que
 		default:
 			return rp.multiEqual(vcursor, bindVars)
 		}
+	case Range:
+		return rp.valueRange(vcursor, bindVars)
 	default:
 		// Unreachable.
 		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unsupported opcode: %v", rp.Opcode)
@@ -348,6 +363,29 @@ func (rp *RoutingParameters) equal(vcursor VCursor, bindVars map[string]*querypb
 	return rss, multiBindVars, nil
 }
 
+// valueRange routes to the shards holding the values between Values[0] and
+// Values[1], inclusive. A NULL value leaves that side of the range open.
+func (rp *RoutingParameters) valueRange(vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	vindex, ok := rp.Vindex.(vindexes.Sequential)
+	if !ok {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] vindex %s does not support range routing", rp.Vindex.String())
+	}
+	env := evalengine.EnvWithBindVars(bindVars, vcursor.ConnCollation())
+	start, err := env.Evaluate(rp.Values[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := env.Evaluate(rp.Values[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	destination, err := vindex.RangeMap(vcursor, start.Value(), end.Value())
+	if err != nil {
+		return nil, nil, err
+	}
+	return rp.byDestination(vcursor, bindVars, destination)
+}
+
 func (rp *RoutingParameters) equalMultiCol(vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	env := evalengine.EnvWithBindVars(bindVars, vcursor.ConnCollation())
 	var rowValue []sqltypes.Value
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"vitess.io/vitess/go/vt/sysvars"
@@ -37,6 +38,7 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
@@ -484,6 +486,15 @@ func (svss *SysVarSetAware) Execute(vcursor VCursor, env *evalengine.ExpressionE
 			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "invalid workload: %s", str)
 		}
 		vcursor.Session().SetWorkload(querypb.ExecuteOptions_Workload(out))
+	case sysvars.Priority.Name:
+		priority, err := svss.evalAsInt64(env)
+		if err != nil {
+			return err
+		}
+		if priority < sqlparser.MinPriority || priority > sqlparser.MaxPriority {
+			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "invalid priority: %d, expected an integer between %d and %d", priority, sqlparser.MinPriority, sqlparser.MaxPriority)
+		}
+		vcursor.Session().SetPriority(strconv.FormatInt(priority, 10))
 	case sysvars.DDLStrategy.Name:
 		str, err := svss.evalAsString(env)
 		if err != nil {
@@ -495,6 +506,14 @@ func (svss *SysVarSetAware) Execute(vcursor VCursor, env *evalengine.ExpressionE
 		vcursor.Session().SetDDLStrategy(str)
 	case sysvars.SessionEnableSystemSettings.Name:
 		err = svss.setBoolSysVar(env, vcursor.Session().SetSessionEnableSystemSettings)
+	case sysvars.QueryRetry.Name:
+		err = svss.setBoolSysVar(env, vcursor.Session().SetQueryRetry)
+	case sysvars.QueryMemoryLimit.Name:
+		limit, err := svss.evalAsInt64(env)
+		if err != nil {
+			return err
+		}
+		vcursor.Session().SetQueryMemoryLimit(limit)
 	case sysvars.Charset.Name, sysvars.Names.Name:
 		str, err := svss.evalAsString(env)
 		if err != nil {
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*VExplainAnalyze)(nil)
+
+type (
+	// PrimitiveStats are the execution statistics of a primitive, collected
+	// while executing a VEXPLAIN ANALYZE statement.
+	PrimitiveStats struct {
+		// Calls is the number of times the primitive was executed.
+		Calls int
+		// RowsReturned is the number of rows returned by the primitive, not
+		// the number of rows the shards examined to produce them.
+		RowsReturned int
+		// Time is the time spent in the primitive, including its inputs.
+		Time time.Duration
+		// MemoryBytes is the size of the results returned by the primitive.
+		MemoryBytes int64
+		// Shards are the statistics of the queries sent by the primitive,
+		// by keyspace/shard.
+		Shards map[string]*ShardStats
+	}
+
+	// ShardStats are the execution statistics of the queries sent to a shard
+	// by a primitive.
+	ShardStats struct {
+		Queries      int
+		RowsReturned int
+		Time         time.Duration
+	}
+
+	// VExplainAnalyze executes its input and returns its plan, annotated
+	// with the statistics of the execution of each primitive and of the
+	// queries they sent to each shard.
+	VExplainAnalyze struct {
+		Input Primitive
+	}
+)
+
+var vexplainAnalyzeFields = []*querypb.Field{
+	{Name: "operator", Type: querypb.Type_VARCHAR},
+	{Name: "variant", Type: querypb.Type_VARCHAR},
+	{Name: "keyspace", Type: querypb.Type_VARCHAR},
+	{Name: "shard", Type: querypb.Type_VARCHAR},
+	{Name: "query", Type: querypb.Type_VARCHAR},
+	{Name: "calls", Type: querypb.Type_INT64},
+	{Name: "rows_returned", Type: querypb.Type_INT64},
+	{Name: "time_ms", Type: querypb.Type_FLOAT64},
+	{Name: "memory_bytes", Type: querypb.Type_INT64},
+}
+
+// RouteType implements the Primitive interface
+func (v *VExplainAnalyze) RouteType() string {
+	return v.Input.RouteType()
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (v *VExplainAnalyze) GetKeyspaceName() string {
+	return v.Input.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (v *VExplainAnalyze) GetTableName() string {
+	return v.Input.GetTableName()
+}
+
+// GetFields implements the Primitive interface
+func (v *VExplainAnalyze) GetFields(VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{Fields: vexplainAnalyzeFields}, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (v *VExplainAnalyze) NeedsTransaction() bool {
+	return v.Input.NeedsTransaction()
+}
+
+// TryExecute implements the Primitive interface
+func (v *VExplainAnalyze) TryExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	stopTrace := vcursor.StartPrimitiveTrace()
+	_, err := vcursor.ExecutePrimitive(v.Input, bindVars, true)
+	stats := stopTrace()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{Fields: vexplainAnalyzeFields}
+	v.appendRows(result, v.Input, "", "", stats)
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (v *VExplainAnalyze) TryStreamExecute(vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	result, err := v.TryExecute(vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(result)
+}
+
+// Inputs implements the Primitive interface
+func (v *VExplainAnalyze) Inputs() []Primitive {
+	return []Primitive{v.Input}
+}
+
+func (v *VExplainAnalyze) description() PrimitiveDescription {
+	return PrimitiveDescription{OperatorType: "VExplainAnalyze"}
+}
+
+// appendRows appends the row of a primitive, followed by the rows of the
+// shards it queried and by the rows of its inputs, drawn as a tree.
+func (v *VExplainAnalyze) appendRows(result *sqltypes.Result, p Primitive, header, indent string, stats map[Primitive]*PrimitiveStats) {
+	descr := p.description()
+	var keyspace string
+	if descr.Keyspace != nil {
+		keyspace = descr.Keyspace.Name
+	}
+	var query string
+	if q, ok := descr.Other["Query"].(string); ok {
+		query = q
+	}
+	st := stats[p]
+	if st == nil {
+		st = &PrimitiveStats{}
+	}
+	result.Rows = append(result.Rows, []sqltypes.Value{
+		sqltypes.NewVarChar(header + descr.OperatorType),
+		sqltypes.NewVarChar(descr.Variant),
+		sqltypes.NewVarChar(keyspace),
+		sqltypes.NewVarChar(""),
+		sqltypes.NewVarChar(query),
+		sqltypes.NewInt64(int64(st.Calls)),
+		sqltypes.NewInt64(int64(st.RowsReturned)),
+		sqltypes.NewFloat64(durationMillis(st.Time)),
+		sqltypes.NewInt64(st.MemoryBytes),
+	})
+
+	inputs := p.Inputs()
+	shards := make([]string, 0, len(st.Shards))
+	for shard := range st.Shards {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	for i, shard := range shards {
+		shardHeader := "├─ "
+		if i == len(shards)-1 && len(inputs) == 0 {
+			shardHeader = "└─ "
+		}
+		ss := st.Shards[shard]
+		ks, name := shard, ""
+		if idx := strings.Index(shard, "/"); idx >= 0 {
+			ks, name = shard[:idx], shard[idx+1:]
+		}
+		result.Rows = append(result.Rows, []sqltypes.Value{
+			sqltypes.NewVarChar(indent + shardHeader + "Shard"),
+			sqltypes.NewVarChar(""),
+			sqltypes.NewVarChar(ks),
+			sqltypes.NewVarChar(name),
+			sqltypes.NewVarChar(""),
+			sqltypes.NewInt64(int64(ss.Queries)),
+			sqltypes.NewInt64(int64(ss.RowsReturned)),
+			sqltypes.NewFloat64(durationMillis(ss.Time)),
+			sqltypes.NULL,
+		})
+	}
+
+	for i, input := range inputs {
+		if i == len(inputs)-1 {
+			v.appendRows(result, input, indent+"└─ ", indent+"   ", stats)
+		} else {
+			v.appendRows(result, input, indent+"├─ ", indent+"│  ", stats)
+		}
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestVExplainAnalyze(t *testing.T) {
+	route := NewRoute(Scatter, &vindexes.Keyspace{Name: "ks", Sharded: true}, "dummy_select", "dummy_select_field")
+	limit := &Limit{
+		Count: evalengine.NewLiteralInt(1),
+		Input: route,
+	}
+	vea := &VExplainAnalyze{Input: limit}
+
+	vc := &loggingVCursor{
+		shards:  []string{"-20", "20-"},
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")},
+		primitiveStats: map[Primitive]*PrimitiveStats{
+			limit: {Calls: 1, RowsReturned: 1, Time: 2 * time.Millisecond, MemoryBytes: 50},
+			route: {Calls: 1, RowsReturned: 2, Time: 1500 * time.Microsecond, MemoryBytes: 100, Shards: map[string]*ShardStats{
+				"ks/20-": {Queries: 1, RowsReturned: 1, Time: 500 * time.Microsecond},
+				"ks/-20": {Queries: 1, RowsReturned: 1, Time: time.Millisecond},
+			}},
+		},
+	}
+	result, err := vea.TryExecute(vc, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		"StartPrimitiveTrace",
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-20: dummy_select {__upper_limit: type:INT64 value:"1"} ks.20-: dummy_select {__upper_limit: type:INT64 value:"1"} false false`,
+	})
+	expectResult(t, "vexplain analyze", result, sqltypes.MakeTestResult(
+		vexplainAnalyzeFields,
+		"Limit|||||1|1|2|50",
+		"└─ Route|Scatter|ks||dummy_select|1|2|1.5|100",
+		"   ├─ Shard||ks|-20||1|1|1|null",
+		"   └─ Shard||ks|20-||1|1|0.5|null",
+	))
+
+	// Primitives that were not executed have no statistics.
+	vc.Rewind()
+	vc.primitiveStats = nil
+	result, err = vea.TryExecute(vc, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	expectResult(t, "vexplain analyze", result, sqltypes.MakeTestResult(
+		vexplainAnalyzeFields,
+		"Limit|||||0|0|0|0",
+		"└─ Route|Scatter|ks||dummy_select|0|0|0|0",
+	))
+}
@@ -163,6 +163,9 @@ func (expr *UnaryExpr) simplify(env *ExpressionEnv) error {
 }
 
 func (c *CallExpr) constant() bool {
+	if _, ok := c.F.(volatileBuiltin); ok {
+		return false
+	}
 	return c.Arguments.constant()
 }
 
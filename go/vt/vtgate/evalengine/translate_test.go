@@ -265,6 +265,21 @@ func TestEvaluate(t *testing.T) {
 	}, {
 		expression: "false is not false",
 		expected:   False,
+	}, {
+		expression: "hex(uuid_to_bin('6ccd780c-baba-1026-9564-5b8c656024db'))",
+		expected:   sqltypes.NewVarChar("6CCD780CBABA102695645B8C656024DB"),
+	}, {
+		expression: "hex(uuid_to_bin('6ccd780c-baba-1026-9564-5b8c656024db', 1))",
+		expected:   sqltypes.NewVarChar("1026BABA6CCD780C95645B8C656024DB"),
+	}, {
+		expression: "bin_to_uuid(uuid_to_bin('{6CCD780C-BABA-1026-9564-5B8C656024DB}', 1), 1)",
+		expected:   sqltypes.NewVarChar("6ccd780c-baba-1026-9564-5b8c656024db"),
+	}, {
+		expression: "bin_to_uuid(uuid_to_bin('6ccd780cbaba102695645b8c656024db'))",
+		expected:   sqltypes.NewVarChar("6ccd780c-baba-1026-9564-5b8c656024db"),
+	}, {
+		expression: "uuid_to_bin(null)",
+		expected:   NULL,
 	}}
 
 	for _, test := range tests {
@@ -296,6 +311,44 @@ func TestEvaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluateUUIDErrors(t *testing.T) {
+	for _, expression := range []string{
+		"uuid_to_bin('not a uuid')",
+		"bin_to_uuid('too short')",
+		"uuid_to_bin()",
+		"ulid(1)",
+	} {
+		t.Run(expression, func(t *testing.T) {
+			stmt, err := sqlparser.Parse("select " + expression)
+			require.NoError(t, err)
+			astExpr := stmt.(*sqlparser.Select).SelectExprs[0].(*sqlparser.AliasedExpr).Expr
+			expr, err := TranslateEx(astExpr, LookupDefaultCollation(45), false)
+			require.NoError(t, err)
+			_, err = EmptyExpressionEnv().Evaluate(expr)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEvaluateULID(t *testing.T) {
+	stmt, err := sqlparser.Parse("select ulid()")
+	require.NoError(t, err)
+	astExpr := stmt.(*sqlparser.Select).SelectExprs[0].(*sqlparser.AliasedExpr).Expr
+	expr, err := Translate(astExpr, LookupDefaultCollation(45))
+	require.NoError(t, err)
+	// ULID() must not be simplified into a constant.
+	require.IsType(t, &CallExpr{}, expr)
+
+	env := EmptyExpressionEnv()
+	first, err := env.Evaluate(expr)
+	require.NoError(t, err)
+	second, err := env.Evaluate(expr)
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.VarChar, first.Value().Type())
+	assert.Len(t, first.Value().ToString(), 26)
+	assert.NotEqual(t, first.Value().ToString(), second.Value().ToString())
+}
+
 func TestEvaluateTuple(t *testing.T) {
 	type testCase struct {
 		expression string
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/ulid"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// volatileBuiltin is implemented by the builtins that return a different
+// value at every call, which must not be folded into a constant.
+type volatileBuiltin interface {
+	volatile()
+}
+
+// uuidSwap moves the time-high and time-mid parts of a UUID before its
+// time-low part, so that the binary UUIDs of version 1 are time-ordered.
+func uuidSwap(b []byte) []byte {
+	out := make([]byte, 0, 16)
+	out = append(out, b[6:8]...)
+	out = append(out, b[4:6]...)
+	out = append(out, b[0:4]...)
+	return append(out, b[8:]...)
+}
+
+// uuidUnswap reverts uuidSwap.
+func uuidUnswap(b []byte) []byte {
+	out := make([]byte, 0, 16)
+	out = append(out, b[4:8]...)
+	out = append(out, b[2:4]...)
+	out = append(out, b[0:2]...)
+	return append(out, b[8:]...)
+}
+
+// uuidSwapFlag evaluates the optional swap flag of UUID_TO_BIN and
+// BIN_TO_UUID. It returns false for NULL, like MySQL.
+func uuidSwapFlag(args []EvalResult) bool {
+	if len(args) < 2 || args[1].isNull() {
+		return false
+	}
+	args[1].makeSignedIntegral()
+	return args[1].int64() != 0
+}
+
+type builtinUUIDToBin struct{}
+
+func (builtinUUIDToBin) call(_ *ExpressionEnv, args []EvalResult, result *EvalResult) {
+	if len(args) < 1 || len(args) > 2 {
+		throwArgError("UUID_TO_BIN")
+	}
+	arg := &args[0]
+	if arg.isNull() {
+		result.setNull()
+		return
+	}
+	str := arg.toRawBytes()
+	u, err := uuid.ParseBytes(str)
+	if err != nil {
+		throwEvalError(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Incorrect string value: '%s' for function uuid_to_bin", str))
+	}
+	bin := u[:]
+	if uuidSwapFlag(args) {
+		bin = uuidSwap(bin)
+	}
+	result.setRaw(sqltypes.VarBinary, bin, collationBinary)
+}
+
+func (builtinUUIDToBin) typeof(env *ExpressionEnv, args []Expr) (sqltypes.Type, flag) {
+	if len(args) < 1 || len(args) > 2 {
+		throwArgError("UUID_TO_BIN")
+	}
+	_, f := args[0].typeof(env)
+	return sqltypes.VarBinary, f
+}
+
+type builtinBinToUUID struct{}
+
+func (builtinBinToUUID) call(env *ExpressionEnv, args []EvalResult, result *EvalResult) {
+	if len(args) < 1 || len(args) > 2 {
+		throwArgError("BIN_TO_UUID")
+	}
+	arg := &args[0]
+	if arg.isNull() {
+		result.setNull()
+		return
+	}
+	bin := arg.toRawBytes()
+	if len(bin) != 16 {
+		throwEvalError(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Incorrect string value: '%X' for function bin_to_uuid", bin))
+	}
+	if uuidSwapFlag(args) {
+		bin = uuidUnswap(bin)
+	}
+	u, _ := uuid.FromBytes(bin)
+	result.setString(u.String(), collations.TypedCollation{
+		Collation:    env.DefaultCollation,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	})
+}
+
+func (builtinBinToUUID) typeof(env *ExpressionEnv, args []Expr) (sqltypes.Type, flag) {
+	if len(args) < 1 || len(args) > 2 {
+		throwArgError("BIN_TO_UUID")
+	}
+	_, f := args[0].typeof(env)
+	return sqltypes.VarChar, f
+}
+
+// builtinULID generates a ULID, a time-ordered unique identifier. MySQL does
+// not have this function, so the queries using it must be evaluated by vtgate.
+type builtinULID struct{}
+
+func (builtinULID) volatile() {}
+
+func (builtinULID) call(env *ExpressionEnv, args []EvalResult, result *EvalResult) {
+	if len(args) != 0 {
+		throwArgError("ULID")
+	}
+	id, err := ulid.New(time.Now())
+	if err != nil {
+		throwEvalError(vterrors.Wrapf(err, "failed to generate ULID"))
+	}
+	result.setString(id.String(), collations.TypedCollation{
+		Collation:    env.DefaultCollation,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	})
+}
+
+func (builtinULID) typeof(_ *ExpressionEnv, args []Expr) (sqltypes.Type, flag) {
+	if len(args) != 0 {
+		throwArgError("ULID")
+	}
+	return sqltypes.VarChar, 0
+}
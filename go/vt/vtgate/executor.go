@@ -25,7 +25,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,6 +46,7 @@ import (
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/featuregate"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/servenv"
@@ -51,6 +55,7 @@ import (
 	"vitess.io/vitess/go/vt/sysvars"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -73,6 +78,10 @@ var (
 
 	queriesProcessedByTable = stats.NewCountersWithMultiLabels("QueriesProcessedByTable", "Queries processed at vtgate by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
 	queriesRoutedByTable    = stats.NewCountersWithMultiLabels("QueriesRoutedByTable", "Queries routed from vtgate to vttablet by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
+
+	queriesShed = stats.NewCountersWithMultiLabels("QueriesShed", "Queries rejected by the traffic shedding of their keyspace, by keyspace and priority", []string{"Keyspace", "Priority"})
+
+	plansPinned = stats.NewCountersWithSingleLabel("PlansPinned", "Plans built from a plan pin of the vschema, by the keyspace of the pin", "Keyspace")
 )
 
 const (
@@ -107,6 +116,19 @@ type Executor struct {
 
 	// allowScatter will fail planning if set to false and a plan contains any scatter queries
 	allowScatter bool
+
+	// ksSettings are the per-keyspace settings read from the topo
+	ksSettings *keyspaceSettings
+
+	// retryPolicy decides which statements are retried on transient tablet errors
+	retryPolicy *retryPolicy
+
+	// queryAnomalies reports the queries that deviate from the baseline of their fingerprint
+	queryAnomalies *queryAnomalyDetector
+	// tenantUsage accounts for the usage of each tenant, if enabled
+	tenantUsage *tenantUsageAccountant
+	// slo computes the service level indicators of each keyspace with objectives
+	slo *sloTracker
 }
 
 var executorOnce sync.Once
@@ -114,6 +136,8 @@ var executorOnce sync.Once
 const pathQueryPlans = "/debug/query_plans"
 const pathScatterStats = "/debug/scatter_stats"
 const pathVSchema = "/debug/vschema"
+const pathQueryAnomalies = "/debug/query_anomalies"
+const pathSLO = "/debug/slo"
 
 // NewExecutor creates a new Executor.
 func NewExecutor(
@@ -141,7 +165,12 @@ func NewExecutor(
 		schemaTracker:   schemaTracker,
 		allowScatter:    !noScatter,
 		pv:              pv,
+		ksSettings:      &keyspaceSettings{},
+		retryPolicy:     newRetryPolicyFromFlags(),
+		queryAnomalies:  newQueryAnomalyDetectorFromFlags(),
+		tenantUsage:     newTenantUsageAccountantFromFlags(),
 	}
+	e.slo = newSLOTrackerFromFlags(e.ksSettings.get)
 
 	vschemaacl.Init()
 	// we subscribe to update from the VSchemaManager
@@ -172,9 +201,12 @@ func NewExecutor(
 		stats.NewCounterFunc("QueryPlanCacheMisses", "Query plan cache misses", func() int64 {
 			return e.plans.Misses()
 		})
+		stats.NewGaugesFuncWithMultiLabels("QueryServingSLOBurnRateMilli", "How fast the queries of each keyspace burn the error budget of its service level objectives, in thousandths, by keyspace, indicator (Availability or Latency) and window. 1000 consumes the error budget exactly over the period of the objective", []string{"Keyspace", "SLI", "Window"}, e.slo.burnRates)
 		http.Handle(pathQueryPlans, e)
 		http.Handle(pathScatterStats, e)
 		http.Handle(pathVSchema, e)
+		http.Handle(pathQueryAnomalies, e)
+		http.Handle(pathSLO, e)
 	})
 	return e
 }
@@ -194,16 +226,29 @@ func (e *Executor) Execute(ctx context.Context, method string, safeSession *Safe
 	} else {
 		saveSessionStats(safeSession, stmtType, result.RowsAffected, result.InsertID, len(result.Rows), err)
 	}
-	if result != nil && len(result.Rows) > *warnMemoryRows {
+	if result != nil && int64(len(result.Rows)) > warnMemoryRows.Get() {
 		warnings.Add("ResultsExceeded", 1)
 		piiSafeSQL, err := sqlparser.RedactSQLQuery(sql)
 		if err != nil {
 			piiSafeSQL = logStats.StmtType
 		}
-		log.Warningf("%q exceeds warning threshold of max memory rows: %v", piiSafeSQL, *warnMemoryRows)
+		log.Warningf("%q exceeds warning threshold of max memory rows: %v", piiSafeSQL, warnMemoryRows.Get())
+	}
+
+	if masker := newResultMasker(ctx, e.VSchema()); masker != nil {
+		result = masker.mask(result)
 	}
 
 	logStats.Send()
+	e.queryAnomalies.Record(logStats)
+	e.slo.Record(logStats)
+	if e.tenantUsage != nil {
+		var rowsRead, rowsWritten, bytesReturned uint64
+		if result != nil {
+			rowsRead, rowsWritten, bytesReturned = uint64(len(result.Rows)), result.RowsAffected, resultBytes(result)
+		}
+		e.tenantUsage.Record(logStats, rowsRead, rowsWritten, bytesReturned)
+	}
 	return result, err
 }
 
@@ -212,8 +257,11 @@ type streaminResultReceiver struct {
 	stmtType     sqlparser.StatementType
 	rowsAffected uint64
 	rowsReturned int
-	insertID     uint64
-	callback     func(*sqltypes.Result) error
+	// bytesReturned is only counted for the tenant usage accounting.
+	bytesReturned uint64
+	countBytes    bool
+	insertID      uint64
+	callback      func(*sqltypes.Result) error
 }
 
 func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, qr *sqltypes.Result) error {
@@ -221,6 +269,9 @@ func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, q
 	defer s.mu.Unlock()
 	s.rowsAffected += qr.RowsAffected
 	s.rowsReturned += len(qr.Rows)
+	if s.countBytes {
+		s.bytesReturned += resultBytes(qr)
+	}
 	if qr.InsertID != 0 {
 		s.insertID = qr.InsertID
 	}
@@ -242,8 +293,25 @@ func (e *Executor) StreamExecute(
 	trace.AnnotateSQL(span, sqlparser.Preview(sql))
 	defer span.Finish()
 
+	if export, err := parseSelectIntoS3(sql); err != nil || export != nil {
+		if err != nil {
+			return err
+		}
+		qr, err := e.executeSelectIntoS3(ctx, safeSession, export, bindVars)
+		if err != nil {
+			return err
+		}
+		return callback(qr)
+	}
+
 	logStats := NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
-	srr := &streaminResultReceiver{callback: callback}
+	if masker := newResultMasker(ctx, e.VSchema()); masker != nil {
+		unmasked := callback
+		callback = func(qr *sqltypes.Result) error {
+			return unmasked(masker.mask(qr))
+		}
+	}
+	srr := &streaminResultReceiver{callback: callback, countBytes: e.tenantUsage != nil}
 	var err error
 
 	resultHandler := func(plan *engine.Plan, vc *vcursorImpl, bindVars map[string]*querypb.BindVariable, execStart time.Time) error {
@@ -283,6 +351,18 @@ func (e *Executor) StreamExecute(
 						}
 					}
 				}
+				// The rows left are sent with the batch of the tablet that
+				// yielded them, rather than held until more rows arrive, so
+				// that the rows of a slow stream reach the client as soon as
+				// they are read.
+				if len(result.Rows) > 0 {
+					err := callback(result)
+					seenResults.Set(true)
+					result = &sqltypes.Result{}
+					if err != nil {
+						return err
+					}
+				}
 				return nil
 			}
 		}
@@ -326,16 +406,19 @@ func (e *Executor) StreamExecute(
 
 	logStats.Error = err
 	saveSessionStats(safeSession, srr.stmtType, srr.rowsAffected, srr.insertID, srr.rowsReturned, err)
-	if srr.rowsReturned > *warnMemoryRows {
+	if int64(srr.rowsReturned) > warnMemoryRows.Get() {
 		warnings.Add("ResultsExceeded", 1)
 		piiSafeSQL, err := sqlparser.RedactSQLQuery(sql)
 		if err != nil {
 			piiSafeSQL = logStats.StmtType
 		}
-		log.Warningf("%q exceeds warning threshold of max memory rows: %v", piiSafeSQL, *warnMemoryRows)
+		log.Warningf("%q exceeds warning threshold of max memory rows: %v", piiSafeSQL, warnMemoryRows.Get())
 	}
 
 	logStats.Send()
+	e.queryAnomalies.Record(logStats)
+	e.slo.Record(logStats)
+	e.tenantUsage.Record(logStats, uint64(srr.rowsReturned), srr.rowsAffected, srr.bytesReturned)
 	return err
 
 }
@@ -369,18 +452,46 @@ func saveSessionStats(safeSession *SafeSession, stmtType sqlparser.StatementType
 }
 
 func (e *Executor) execute(ctx context.Context, safeSession *SafeSession, sql string, bindVars map[string]*querypb.BindVariable, logStats *LogStats) (sqlparser.StatementType, *sqltypes.Result, error) {
+	if export, err := parseSelectIntoS3(sql); err != nil || export != nil {
+		if err != nil {
+			return sqlparser.StmtSelect, nil, err
+		}
+		qr, err := e.executeSelectIntoS3(ctx, safeSession, export, bindVars)
+		return sqlparser.StmtSelect, qr, err
+	}
+
 	var err error
 	var qr *sqltypes.Result
 	var stmtType sqlparser.StatementType
-	err = e.newExecute(ctx, safeSession, sql, bindVars, logStats, func(plan *engine.Plan, vc *vcursorImpl, bindVars map[string]*querypb.BindVariable, time time.Time) error {
-		stmtType = plan.Type
-		qr, err = e.executePlan(ctx, safeSession, plan, vc, bindVars, logStats, time)
-		return err
-	}, func(typ sqlparser.StatementType, result *sqltypes.Result) error {
-		stmtType = typ
-		qr = result
-		return nil
-	})
+	var executedPlan *engine.Plan
+	for try, retry := 0, 0; try < MaxBufferingRetries; {
+		executedPlan = nil
+		err = e.newExecute(ctx, safeSession, sql, bindVars, logStats, func(plan *engine.Plan, vc *vcursorImpl, bindVars map[string]*querypb.BindVariable, time time.Time) error {
+			stmtType = plan.Type
+			executedPlan = plan
+			qr, err = e.executePlan(ctx, safeSession, plan, vc, bindVars, logStats, time)
+			return err
+		}, func(typ sqlparser.StatementType, result *sqltypes.Result) error {
+			stmtType = typ
+			qr = result
+			return nil
+		})
+		if err == nil || safeSession.InTransaction() {
+			break
+		}
+		// A query that was buffered while a MoveTables switched its tables to
+		// another keyspace is planned again, to route it to the new keyspace.
+		if vterrors.RootCause(err) == buffer.TablesSwitchedError {
+			try++
+			continue
+		}
+		// An idempotent statement that failed with a transient tablet error is
+		// executed again, as allowed by the retry policy.
+		if !e.retryPolicy.shouldRetry(ctx, safeSession, executedPlan, err, retry) {
+			break
+		}
+		retry++
+	}
 
 	return stmtType, qr, err
 }
@@ -431,12 +542,22 @@ func (e *Executor) addNeededBindVars(bindVarNeeds *sqlparser.BindVarNeeds, bindV
 				v = options.GetWorkload().String()
 			})
 			bindVars[key] = sqltypes.StringBindVariable(v)
+		case sysvars.Priority.Name:
+			var v string
+			ifOptionsExist(session, func(options *querypb.ExecuteOptions) {
+				v = options.GetPriority()
+			})
+			bindVars[key] = sqltypes.StringBindVariable(v)
 		case sysvars.DDLStrategy.Name:
 			bindVars[key] = sqltypes.StringBindVariable(session.DDLStrategy)
 		case sysvars.SessionUUID.Name:
 			bindVars[key] = sqltypes.StringBindVariable(session.SessionUUID)
 		case sysvars.SessionEnableSystemSettings.Name:
 			bindVars[key] = sqltypes.BoolBindVariable(session.EnableSystemSettings)
+		case sysvars.QueryRetry.Name:
+			bindVars[key] = sqltypes.BoolBindVariable(!session.QueryRetryDisabled)
+		case sysvars.QueryMemoryLimit.Name:
+			bindVars[key] = sqltypes.Int64BindVariable(session.QueryMemoryLimit)
 		case sysvars.ReadAfterWriteGTID.Name:
 			var v string
 			ifReadAfterWriteExist(session, func(raw *vtgatepb.ReadAfterWrite) {
@@ -543,6 +664,10 @@ func (e *Executor) Commit(ctx context.Context, safeSession *SafeSession) error {
 	return e.txConn.Commit(ctx, safeSession)
 }
 
+func (e *Executor) twoPC(safeSession *SafeSession) bool {
+	return e.txConn.twoPC(safeSession)
+}
+
 func (e *Executor) handleRollback(ctx context.Context, safeSession *SafeSession, logStats *LogStats) (*sqltypes.Result, error) {
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
@@ -965,6 +1090,16 @@ func (e *Executor) getPlan(vcursor *vcursorImpl, sql string, comments sqlparser.
 		return nil, err
 	}
 	query := sql
+	augmented, err := enforceTenantPredicates(vcursor, stmt, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	if augmented {
+		query = sqlparser.String(stmt)
+	}
+	if err := checkMaskedColumns(vcursor, stmt); err != nil {
+		return nil, err
+	}
 	statement := stmt
 	reservedVars := sqlparser.NewReservedVars("vtg", reserved)
 	bindVarNeeds := &sqlparser.BindVarNeeds{}
@@ -1000,6 +1135,7 @@ func (e *Executor) getPlan(vcursor *vcursorImpl, sql string, comments sqlparser.
 	}
 
 	if logStats != nil {
+		logStats.tenant = sqlparser.TenantDirective(stmt)
 		logStats.SQL = comments.Leading + query + comments.Trailing
 		logStats.BindVariables = sqltypes.CopyBindVariables(bindVars)
 	}
@@ -1014,6 +1150,12 @@ func (e *Executor) getPlan(vcursor *vcursorImpl, sql string, comments sqlparser.
 		return plan.(*engine.Plan), nil
 	}
 
+	// The plan pin is looked up by the query as sent by the application:
+	// its fingerprint ignores the literals and comments of the query anyway.
+	vcursor.planPin = vcursor.vschema.FindPlanPin(sql)
+	if vcursor.planPin != nil {
+		plansPinned.Add(vcursor.planPin.Keyspace, 1)
+	}
 	plan, err := planbuilder.BuildFromStmt(query, statement, reservedVars, vcursor, bindVarNeeds, *enableOnlineDDL, *enableDirectDDL)
 	if err != nil {
 		return nil, err
@@ -1021,10 +1163,15 @@ func (e *Executor) getPlan(vcursor *vcursorImpl, sql string, comments sqlparser.
 
 	plan.Warnings = vcursor.warnings
 	vcursor.warnings = nil
+	if *warmCacheFile != "" {
+		plan.Target = vcursor.safeSession.TargetString
+		plan.Query = sql
+	}
 
-	err = e.checkThatPlanIsValid(stmt, plan)
+	err = e.checkThatPlanIsValid(stmt, plan, vcursor.planPin)
 	// Only cache the plan if it is valid (i.e. does not scatter)
 	if err == nil && qo.cachePlan() && sqlparser.CachePlan(statement) {
+		plan.CacheKey = planKey
 		e.plans.Set(planKey, plan)
 	}
 	return plan, err
@@ -1103,6 +1250,10 @@ func (e *Executor) ServeHTTP(response http.ResponseWriter, request *http.Request
 		returnAsJSON(response, e.VSchema())
 	case pathScatterStats:
 		e.WriteScatterStats(response)
+	case pathQueryAnomalies:
+		returnAsJSON(response, e.queryAnomalies.Anomalies())
+	case pathSLO:
+		returnAsJSON(response, e.slo.Status())
 	default:
 		response.WriteHeader(http.StatusNotFound)
 	}
@@ -1172,11 +1323,11 @@ func buildVarCharRow(values ...string) []sqltypes.Value {
 // if the payload size exceeds the warnPayloadSize.
 
 func isValidPayloadSize(query string) bool {
-	payloadSize := len(query)
-	if *maxPayloadSize > 0 && payloadSize > *maxPayloadSize {
+	payloadSize := int64(len(query))
+	if maxSize := maxPayloadSize.Get(); maxSize > 0 && payloadSize > maxSize {
 		return false
 	}
-	if *warnPayloadSize > 0 && payloadSize > *warnPayloadSize {
+	if warnSize := warnPayloadSize.Get(); warnSize > 0 && payloadSize > warnSize {
 		warnings.Add("WarnPayloadSizeExceeded", 1)
 	}
 	return true
@@ -1335,47 +1486,196 @@ func (e *Executor) startVStream(ctx context.Context, rss []*srvtopo.ResolvedShar
 	return nil
 }
 
-func (e *Executor) checkThatPlanIsValid(stmt sqlparser.Statement, plan *engine.Plan) error {
-	if e.allowScatter || sqlparser.AllowScatterDirective(stmt) {
+func (e *Executor) checkThatPlanIsValid(stmt sqlparser.Statement, plan *engine.Plan, pin *vindexes.PlanPin) error {
+	if plan.Instructions == nil || sqlparser.AllowScatterDirective(stmt) {
 		return nil
 	}
+	noScatter := plan.QueryHints != nil && plan.QueryHints.NoScatter
+	pinnedNoScatter := pin != nil && pin.NoScatter
 	// we go over all the primitives in the plan, searching for a route that is of SelectScatter opcode
+	// on a keyspace where scatters are disallowed
+	var badKeyspace string
 	badPrimitive := engine.Find(func(node engine.Primitive) bool {
 		router, ok := node.(*engine.Route)
-		if !ok {
+		if !ok || router.Opcode != engine.Scatter {
 			return false
 		}
-		return router.Opcode == engine.Scatter
+		if !e.allowScatter || noScatter || pinnedNoScatter {
+			return true
+		}
+		if settings := e.ksSettings.get(router.Keyspace.Name); settings != nil && settings.NoScatter {
+			badKeyspace = router.Keyspace.Name
+			return true
+		}
+		return false
 	}, plan.Instructions)
 
 	if badPrimitive == nil {
 		return nil
 	}
 
+	if badKeyspace != "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "plan includes scatter, which is disallowed for keyspace %s by its keyspace settings", badKeyspace)
+	}
+	if noScatter {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "plan includes scatter, which is disallowed by the %s comment directive", sqlparser.DirectiveNoScatter)
+	}
+	if pinnedNoScatter {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "plan includes scatter, which is disallowed by the plan pin of the query in keyspace %s", pin.Keyspace)
+	}
 	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "plan includes scatter, which is disallowed using the `no_scatter` command line argument")
 }
 
-func getTabletThrottlerStatus(tabletHostPort string) (string, error) {
+// evictScatterPlans removes from the plan cache the plans that scatter
+// on one of the keyspaces, so that they are checked again when rebuilt.
+func (e *Executor) evictScatterPlans(keyspaces map[string]bool) {
+	var keys []string
+	e.plans.ForEach(func(value any) bool {
+		plan := value.(*engine.Plan)
+		if plan.Instructions == nil {
+			return true
+		}
+		scatter := engine.Find(func(node engine.Primitive) bool {
+			router, ok := node.(*engine.Route)
+			return ok && router.Opcode == engine.Scatter && keyspaces[router.Keyspace.Name]
+		}, plan.Instructions)
+		if scatter != nil {
+			keys = append(keys, plan.CacheKey)
+		}
+		return true
+	})
+	for _, key := range keys {
+		e.plans.Delete(key)
+	}
+}
+
+// defaultWorkload returns the default workload set in the settings of the
+// keyspace of a target, or UNSPECIFIED if there is none.
+func (e *Executor) defaultWorkload(target string) querypb.ExecuteOptions_Workload {
+	keyspace, _, _, err := topoproto.ParseDestination(target, defaultTabletType)
+	if err != nil {
+		return querypb.ExecuteOptions_UNSPECIFIED
+	}
+	if settings := e.ksSettings.get(keyspace); settings != nil {
+		return settings.Workload()
+	}
+	return querypb.ExecuteOptions_UNSPECIFIED
+}
+
+// shedRandom returns a random percentage, between 0 and 99, and decides
+// which queries are shed.
+var shedRandom = func() int { return rand.Intn(100) }
+
+// checkTrafficShedding rejects a share of the queries of a low priority that
+// the settings of a keyspace of their plan shed. The queries of transactions
+// that opened connections to tablets are never shed, so that they can finish.
+func (e *Executor) checkTrafficShedding(safeSession *SafeSession, plan *engine.Plan) error {
+	if plan.Instructions == nil || safeSession.isTxOpen() {
+		return nil
+	}
+
+	priority := safeSession.getPriority()
+	var shedKeyspace string
+	var shedPercent int
+	engine.Find(func(node engine.Primitive) bool {
+		settings := e.ksSettings.get(node.GetKeyspaceName())
+		if settings == nil || !settings.Sheds(priority) {
+			return false
+		}
+		shedKeyspace = node.GetKeyspaceName()
+		shedPercent = settings.ShedPercent
+		return true
+	}, plan.Instructions)
+
+	if shedKeyspace == "" || shedRandom() >= shedPercent {
+		return nil
+	}
+
+	queriesShed.Add([]string{shedKeyspace, strconv.Itoa(priority)}, 1)
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query shed by the traffic shedding of keyspace %s, retry later", shedKeyspace)
+}
+
+// checkFeatureGates rejects the plans that use a feature whose gate is
+// disabled in the keyspace they use it in.
+func (e *Executor) checkFeatureGates(plan *engine.Plan) error {
+	if plan.Instructions == nil {
+		return nil
+	}
+
+	var disabledKeyspace string
+	engine.Find(func(node engine.Primitive) bool {
+		if _, ok := node.(*engine.DMLBatch); !ok {
+			return false
+		}
+		var overrides map[string]bool
+		if settings := e.ksSettings.get(node.GetKeyspaceName()); settings != nil {
+			overrides = settings.FeatureGates
+		}
+		if featuregate.DMLBatch.Enabled(overrides) {
+			return false
+		}
+		disabledKeyspace = node.GetKeyspaceName()
+		return true
+	}, plan.Instructions)
+
+	if disabledKeyspace == "" {
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "batched DML is disabled in keyspace %s by the %s feature gate", disabledKeyspace, featuregate.DMLBatch.Name())
+}
+
+// tabletThrottlerCheck is the result of a check of the lag throttler of a
+// tablet.
+type tabletThrottlerCheck struct {
+	StatusCode int
+	Value      float64
+	Threshold  float64
+	Message    string
+}
+
+// checkTabletThrottler checks the lag throttler of a tablet for an app.
+func checkTabletThrottler(tabletHostPort string, appName string) (*tabletThrottlerCheck, error) {
 	client := http.Client{
 		Timeout: 100 * time.Millisecond,
 	}
-	resp, err := client.Get(fmt.Sprintf("http://%s/throttler/check?app=vtgate", tabletHostPort))
+	resp, err := client.Get(fmt.Sprintf("http://%s/throttler/check?app=%s", tabletHostPort, url.QueryEscape(appName)))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var elements struct {
-		StatusCode int
-		Value      float64
-		Threshold  float64
-		Message    string
+	elements := &tabletThrottlerCheck{}
+	if err := json.Unmarshal(body, elements); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(body, &elements)
+	return elements, nil
+}
+
+// checkThrottler returns true if the lag throttler of the primary of a shard
+// lets the given app write to it.
+func (e *Executor) checkThrottler(ctx context.Context, target *querypb.Target, appName string) (bool, error) {
+	primaries := e.scatterConn.gateway.hc.GetHealthyTabletStats(&querypb.Target{
+		Keyspace:   target.Keyspace,
+		Shard:      target.Shard,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	})
+	if len(primaries) == 0 {
+		return false, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy primary for shard %s/%s", target.Keyspace, target.Shard)
+	}
+
+	check, err := checkTabletThrottler(primaries[0].GetTabletHostPort(), appName)
+	if err != nil {
+		return false, err
+	}
+	return check.StatusCode == http.StatusOK, nil
+}
+
+func getTabletThrottlerStatus(tabletHostPort string) (string, error) {
+	elements, err := checkTabletThrottler(tabletHostPort, "vtgate")
 	if err != nil {
 		return "", err
 	}
@@ -2248,3 +2248,32 @@ func TestMultiInternalSavepoint(t *testing.T) {
 	}}
 	assertQueriesWithSavepoint(t, sbc1, wantQ)
 }
+
+func TestDMLReturning(t *testing.T) {
+	executor, sbc1, sbc2, _ := createExecutorEnv()
+
+	_, err := executorExec(executor, "update user set a = 2 where id = 1 returning id, a", nil)
+	require.EqualError(t, err, "RETURNING is not enabled, see the enable_dml_returning flag")
+
+	*enableDMLReturning = true
+	defer func() {
+		*enableDMLReturning = false
+	}()
+
+	returned := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|a", "int64|int64"), "1|2")
+	returned.RowsAffected = 1
+	sbc1.SetResults([]*sqltypes.Result{returned})
+	qr, err := executorExec(executor, "update user set a = 2 where id = 1 returning id, a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, returned.Rows, qr.Rows)
+	assert.EqualValues(t, 1, qr.RowsAffected)
+	wantQueries := []*querypb.BoundQuery{{
+		Sql:           "update `user` set a = 2 where id = 1 returning id, a",
+		BindVariables: map[string]*querypb.BindVariable{},
+	}}
+	assertQueries(t, sbc1, wantQueries)
+	assertQueries(t, sbc2, nil)
+
+	_, err = executorExec(executor, "delete from user where id in (1, 3) returning id", nil)
+	require.EqualError(t, err, "unsupported: RETURNING on a DML that is not routed to a single shard")
+}
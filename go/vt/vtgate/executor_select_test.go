@@ -3041,6 +3041,41 @@ func TestSelectScatterFails(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSelectQueryHints(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+	session := &vtgatepb.Session{
+		TargetString: "@primary",
+		Options:      &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_OLTP, IncludedFields: querypb.ExecuteOptions_ALL},
+	}
+
+	_, err := executorExecSession(executor, "select /*vt+ QUERY_TIMEOUT_MS=1000 WORKLOAD=DBA PRIORITY=5 */ id from user where id = 1", nil, session)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Options, 1)
+	options := sbc1.Options[0]
+	assert.Equal(t, querypb.ExecuteOptions_DBA, options.Workload)
+	assert.Equal(t, "5", options.Priority)
+	assert.EqualValues(t, 1000, options.QueryTimeoutMs)
+	assert.Equal(t, querypb.ExecuteOptions_ALL, options.IncludedFields)
+
+	// The hints only apply to the query they are set on.
+	assert.Equal(t, querypb.ExecuteOptions_OLTP, session.Options.Workload)
+	assert.Empty(t, session.Options.Priority)
+	assert.Zero(t, session.Options.QueryTimeoutMs)
+	_, err = executorExecSession(executor, "select id from user where id = 1", nil, session)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Options, 2)
+	assert.Equal(t, querypb.ExecuteOptions_OLTP, sbc1.Options[1].Workload)
+	assert.Empty(t, sbc1.Options[1].Priority)
+
+	_, err = executorExecSession(executor, "select /*vt+ NO_SCATTER */ id from user", nil, session)
+	require.EqualError(t, err, "plan includes scatter, which is disallowed by the NO_SCATTER comment directive")
+	_, err = executorExecSession(executor, "select /*vt+ NO_SCATTER */ id from user where id = 1", nil, session)
+	require.NoError(t, err)
+
+	_, err = executorExecSession(executor, "select /*vt+ PRIORITY=high */ id from user where id = 1", nil, session)
+	require.EqualError(t, err, "invalid value for PRIORITY: high, expected an integer between 0 and 100")
+}
+
 func TestGen4SelectStraightJoin(t *testing.T) {
 	executor, sbc1, _, _ := createExecutorEnv()
 	executor.normalize = true
@@ -154,6 +154,15 @@ func TestExecutorSet(t *testing.T) {
 	}, {
 		in:  "set workload = 1",
 		err: "incorrect argument type to variable 'workload': INT64",
+	}, {
+		in:  "set priority = 10",
+		out: &vtgatepb.Session{Autocommit: true, Options: &querypb.ExecuteOptions{Priority: "10"}},
+	}, {
+		in:  "set priority = 101",
+		err: "invalid priority: 101, expected an integer between 0 and 100",
+	}, {
+		in:  "set priority = 'high'",
+		err: "incorrect argument type to variable 'priority': VARCHAR",
 	}, {
 		in:  "set transaction_mode = 'twopc', autocommit=1",
 		out: &vtgatepb.Session{Autocommit: true, TransactionMode: vtgatepb.TransactionMode_TWOPC},
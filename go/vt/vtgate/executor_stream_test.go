@@ -113,3 +113,32 @@ func executorStreamMessages(executor *Executor, sql string) (qr *sqltypes.Result
 	}
 	return qr, nil
 }
+
+func TestStreamExecuteSendsTabletBatches(t *testing.T) {
+	cell := "aa"
+	hc := discovery.NewFakeHealthCheck(nil)
+	s := createSandbox("TestExecutor")
+	s.VSchema = executorVSchema
+	getSandbox(KsTestUnsharded).VSchema = unshardedVSchema
+	serv := newSandboxForCells([]string{cell})
+	resolver := newTestResolver(hc, serv, cell)
+	shards := []string{"-20", "20-40", "40-60", "60-80", "80-a0", "a0-c0", "c0-e0", "e0-"}
+	for _, shard := range shards {
+		_ = hc.AddTestTablet(cell, shard, 1, "TestExecutor", shard, topodatapb.TabletType_PRIMARY, true, 1, nil)
+	}
+	const streamSize = 1000
+	executor := NewExecutor(context.Background(), serv, cell, resolver, false, false, streamSize, cache.DefaultConfig, nil, false, querypb.ExecuteOptions_V3)
+
+	// Each shard sends a batch of one row of 4 bytes. The whole stream is
+	// smaller than streamSize, but each batch is sent as soon as the
+	// tablet yields it, rather than held until the end of the stream.
+	var batches []int
+	err := executor.StreamExecute(context.Background(), "TestStreamExecuteSendsTabletBatches", NewSafeSession(primarySession), "select id, value from user", nil, func(qr *sqltypes.Result) error {
+		if len(qr.Rows) > 0 {
+			batches = append(batches, len(qr.Rows))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1, 1, 1, 1, 1, 1, 1}, batches)
+}
@@ -56,9 +56,9 @@ import (
 )
 
 func TestExecutorResultsExceeded(t *testing.T) {
-	save := *warnMemoryRows
-	*warnMemoryRows = 3
-	defer func() { *warnMemoryRows = save }()
+	save := warnMemoryRows.Get()
+	warnMemoryRows.value.Set(3)
+	defer func() { warnMemoryRows.value.Set(save) }()
 
 	executor, _, _, sbclookup := createExecutorEnv()
 	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
@@ -79,9 +79,9 @@ func TestExecutorResultsExceeded(t *testing.T) {
 }
 
 func TestExecutorMaxMemoryRowsExceeded(t *testing.T) {
-	save := *maxMemoryRows
-	*maxMemoryRows = 3
-	defer func() { *maxMemoryRows = save }()
+	save := maxMemoryRows.Get()
+	maxMemoryRows.value.Set(3)
+	defer func() { maxMemoryRows.value.Set(save) }()
 
 	executor, _, _, sbclookup := createExecutorEnv()
 	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
@@ -1663,6 +1663,35 @@ func getPlanCached(t *testing.T, e *Executor, vcursor *vcursorImpl, sql string,
 	return plan, logStats
 }
 
+func TestGetPlanPinned(t *testing.T) {
+	r, _, _, _ := createExecutorEnv()
+	vschema := r.VSchema()
+	pin := func(query string, pin *vindexes.PlanPin) {
+		fingerprint, err := sqlparser.QueryFingerprint(query)
+		require.NoError(t, err)
+		pin.Keyspace, pin.Query = KsTestSharded, query
+		vschema.PlanPins = map[string]*vindexes.PlanPin{fingerprint: pin}
+		r.plans.Clear()
+	}
+	vc, _ := newVCursorImpl(ctx, NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), makeComments(""), r, nil, r.vm, vschema, r.resolver.resolver, nil, false, querypb.ExecuteOptions_Gen4)
+	pinned := plansPinned.Counts()[KsTestSharded]
+
+	pin("select id from user where id = 1 and name = 'foo'", &vindexes.PlanPin{Vindex: "name_user_map"})
+	plan, _ := getPlanCached(t, r, vc, "select id from user where name = 'bar' and id = 2", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	route, ok := plan.Instructions.(*engine.Route)
+	require.True(t, ok, "should be a Route")
+	assert.Equal(t, "name_user_map", route.Vindex.String())
+	assert.EqualValues(t, pinned+1, plansPinned.Counts()[KsTestSharded])
+
+	pin("select id from user", &vindexes.PlanPin{NoScatter: true})
+	_, err := r.getPlan(vc, "select id from user", makeComments(""), map[string]*querypb.BindVariable{}, vc.safeSession, nil)
+	require.EqualError(t, err, "plan includes scatter, which is disallowed by the plan pin of the query in keyspace TestExecutor")
+	_, err = r.getPlan(vc, "select /*vt+ ALLOW_SCATTER */ id from user", makeComments(""), map[string]*querypb.BindVariable{}, vc.safeSession, nil)
+	require.NoError(t, err)
+	_, err = r.getPlan(vc, "select id from user limit 10", makeComments(""), map[string]*querypb.BindVariable{}, vc.safeSession, nil)
+	require.NoError(t, err)
+}
+
 func TestGetPlanCacheUnnormalized(t *testing.T) {
 	r, _, _, _ := createExecutorEnv()
 	emptyvc, _ := newVCursorImpl(ctx, NewSafeSession(&vtgatepb.Session{TargetString: "@unknown"}), makeComments(""), r, nil, r.vm, r.VSchema(), r.resolver.resolver, nil, false, pv)
@@ -1961,13 +1990,13 @@ func TestDebugVSchema(t *testing.T) {
 }
 
 func TestExecutorMaxPayloadSizeExceeded(t *testing.T) {
-	saveMax := *maxPayloadSize
-	saveWarn := *warnPayloadSize
-	*maxPayloadSize = 10
-	*warnPayloadSize = 5
+	saveMax := maxPayloadSize.Get()
+	saveWarn := warnPayloadSize.Get()
+	maxPayloadSize.value.Set(10)
+	warnPayloadSize.value.Set(5)
 	defer func() {
-		*maxPayloadSize = saveMax
-		*warnPayloadSize = saveWarn
+		maxPayloadSize.value.Set(saveMax)
+		warnPayloadSize.value.Set(saveWarn)
 	}()
 
 	executor, _, _, _ := createExecutorEnv()
@@ -1998,7 +2027,7 @@ func TestExecutorMaxPayloadSizeExceeded(t *testing.T) {
 	}
 	assert.Equal(t, warningCount, warnings.Counts()["WarnPayloadSizeExceeded"], "warnings count")
 
-	*maxPayloadSize = 1000
+	maxPayloadSize.value.Set(1000)
 	for _, query := range testMaxPayloadSizeExceeded {
 		_, err := executor.Execute(context.Background(), "TestExecutorMaxPayloadSizeExceeded", session, query, nil)
 		assert.Equal(t, nil, err, "err should be nil")
@@ -2134,6 +2163,54 @@ func TestExecutorExplain(t *testing.T) {
 		fmt.Sprintf("%v", result.Rows), fmt.Sprintf("%v", result.Rows))
 }
 
+func TestExecutorVExplainAnalyze(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+
+	// statsOf returns the operator, calls and returned rows of each row of a
+	// vexplain analyze result, leaving out the timings and sizes.
+	statsOf := func(result *sqltypes.Result) []string {
+		var stats []string
+		for _, row := range result.Rows {
+			stats = append(stats, fmt.Sprintf("%s %s/%s %s/%s", row[0].ToString(), row[2].ToString(), row[3].ToString(), row[5].ToString(), row[6].ToString()))
+		}
+		return stats
+	}
+
+	result, err := executorExec(executor, "vexplain analyze select id from user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Route TestExecutor/ 1/8",
+		"├─ Shard TestExecutor/-20 1/1",
+		"├─ Shard TestExecutor/20-40 1/1",
+		"├─ Shard TestExecutor/40-60 1/1",
+		"├─ Shard TestExecutor/60-80 1/1",
+		"├─ Shard TestExecutor/80-a0 1/1",
+		"├─ Shard TestExecutor/a0-c0 1/1",
+		"├─ Shard TestExecutor/c0-e0 1/1",
+		"└─ Shard TestExecutor/e0- 1/1",
+	}, statsOf(result))
+	assert.Equal(t, "select id from `user`", result.Rows[0][4].ToString())
+	assert.EqualValues(t, 1, sbc1.ExecCount.Get())
+
+	// The right side of the join is executed once per row of the left side.
+	result, err = executorExec(executor, "vexplain analyze select u.id, ue.id from user u, user_extra ue where u.id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Join / 1/8",
+		"├─ Route TestExecutor/ 1/1",
+		"│  └─ Shard TestExecutor/-20 1/1",
+		"└─ Route TestExecutor/ 1/8",
+		"   ├─ Shard TestExecutor/-20 1/1",
+		"   ├─ Shard TestExecutor/20-40 1/1",
+		"   ├─ Shard TestExecutor/40-60 1/1",
+		"   ├─ Shard TestExecutor/60-80 1/1",
+		"   ├─ Shard TestExecutor/80-a0 1/1",
+		"   ├─ Shard TestExecutor/a0-c0 1/1",
+		"   ├─ Shard TestExecutor/c0-e0 1/1",
+		"   └─ Shard TestExecutor/e0- 1/1",
+	}, statsOf(result))
+}
+
 func TestExecutorOtherAdmin(t *testing.T) {
 	executor, sbc1, sbc2, sbclookup := createExecutorEnv()
 
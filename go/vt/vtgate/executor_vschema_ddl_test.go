@@ -354,16 +354,21 @@ func TestExecutorAddSequenceDDL(t *testing.T) {
 		t.Errorf("want table type sequence got %v", table)
 	}
 
-	// Should fail adding a table on a sharded keyspace
+	// Should be able to add a sequence on a sharded keyspace
 	ksSharded := "TestExecutor"
 	session = NewSafeSession(&vtgatepb.Session{TargetString: ksSharded})
 
+	vschemaTables = nil
+	for t := range vschema.Keyspaces[ksSharded].Tables {
+		vschemaTables = append(vschemaTables, t)
+	}
 	stmt = "alter vschema add sequence sequence_table"
 	_, err = executor.Execute(context.Background(), "TestExecute", session, stmt, nil)
-
-	wantErr := "add sequence table: unsupported on sharded keyspace TestExecutor"
-	if err == nil || err.Error() != wantErr {
-		t.Errorf("want error %v got %v", wantErr, err)
+	require.NoError(t, err)
+	_ = waitForVschemaTables(t, ksSharded, append(vschemaTables, "sequence_table"), executor)
+	table = executor.vm.GetCurrentSrvVschema().Keyspaces[ksSharded].Tables["sequence_table"]
+	if table.Type != wantType {
+		t.Errorf("want table type sequence got %v", table)
 	}
 
 	// Should be able to add autoincrement to table in sharded keyspace
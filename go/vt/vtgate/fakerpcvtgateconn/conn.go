@@ -25,6 +25,7 @@ import (
 	"io"
 	"math/rand"
 	"reflect"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
@@ -104,7 +105,7 @@ func (conn *FakeVTGateConn) Execute(ctx context.Context, session *vtgatepb.Sessi
 }
 
 // ExecuteBatch please see vtgateconn.Impl.ExecuteBatch
-func (conn *FakeVTGateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVarsList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (conn *FakeVTGateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVarsList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	panic("not implemented")
 }
 
@@ -182,6 +183,16 @@ func (conn *FakeVTGateConn) CloseSession(ctx context.Context, session *vtgatepb.
 	panic("not implemented")
 }
 
+// CheckoutSession please see vtgateconn.Impl.CheckoutSession
+func (conn *FakeVTGateConn) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	panic("not implemented")
+}
+
+// CheckinSession please see vtgateconn.Impl.CheckinSession
+func (conn *FakeVTGateConn) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	panic("not implemented")
+}
+
 // ResolveTransaction please see vtgateconn.Impl.ResolveTransaction
 func (conn *FakeVTGateConn) ResolveTransaction(ctx context.Context, dtid string) error {
 	return nil
@@ -19,6 +19,7 @@ package grpcvtgateconn
 
 import (
 	"flag"
+	"time"
 
 	"google.golang.org/grpc"
 
@@ -28,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/grpcclient"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/arrowresult"
 	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -43,6 +45,8 @@ var (
 	ca   = flag.String("vtgate_grpc_ca", "", "the server ca to use to validate servers when connecting")
 	crl  = flag.String("vtgate_grpc_crl", "", "the server crl to use to validate server certificates when connecting")
 	name = flag.String("vtgate_grpc_server_name", "", "the server name to use to validate server certificate")
+
+	resultFormat = flag.String("vtgate_grpc_result_format", "rows", "the encoding of the rows of the results requested from vtgate: rows, or arrow to receive them as Arrow IPC streams, which are cheaper to serialize for vtgate")
 )
 
 func init() {
@@ -89,6 +93,7 @@ func (conn *vtgateConn) Execute(ctx context.Context, session *vtgatepb.Session,
 			Sql:           query,
 			BindVariables: bindVars,
 		},
+		ResultFormat: requestedResultFormat(),
 	}
 	response, err := conn.c.Execute(ctx, request)
 	if err != nil {
@@ -97,10 +102,35 @@ func (conn *vtgateConn) Execute(ctx context.Context, session *vtgatepb.Session,
 	if response.Error != nil {
 		return response.Session, nil, vterrors.FromVTRPC(response.Error)
 	}
+	if len(response.ArrowResult) > 0 {
+		qr, err := fromArrowResult(response.Result, response.ArrowResult)
+		return response.Session, qr, err
+	}
 	return response.Session, sqltypes.Proto3ToResult(response.Result), nil
 }
 
-func (conn *vtgateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, queryList []string, bindVarsList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+// requestedResultFormat returns the result format set by the
+// vtgate_grpc_result_format flag.
+func requestedResultFormat() vtgatepb.ResultFormat {
+	if *resultFormat == "arrow" {
+		return vtgatepb.ResultFormat_ARROW
+	}
+	return vtgatepb.ResultFormat_ROWS
+}
+
+// fromArrowResult returns the result of qr, whose fields and rows are in
+// the Arrow IPC stream data.
+func fromArrowResult(qr *querypb.QueryResult, data []byte) (*sqltypes.Result, error) {
+	fields, rows, err := arrowresult.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	result := sqltypes.Proto3ToResult(qr)
+	result.Fields, result.Rows = fields, rows
+	return result, nil
+}
+
+func (conn *vtgateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, queryList []string, bindVarsList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	queries := make([]*querypb.BoundQuery, len(queryList))
 	for i, query := range queryList {
 		bq := &querypb.BoundQuery{Sql: query}
@@ -110,9 +140,10 @@ func (conn *vtgateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Sess
 		queries[i] = bq
 	}
 	request := &vtgatepb.ExecuteBatchRequest{
-		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
-		Session:  session,
-		Queries:  queries,
+		CallerId:    callerid.EffectiveCallerIDFromContext(ctx),
+		Session:     session,
+		Queries:     queries,
+		StopOnError: stopOnError,
 	}
 	response, err := conn.c.ExecuteBatch(ctx, request)
 	if err != nil {
@@ -125,19 +156,32 @@ func (conn *vtgateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Sess
 }
 
 type streamExecuteAdapter struct {
-	recv   func() (*querypb.QueryResult, error)
+	recv   func() (*vtgatepb.StreamExecuteResponse, error)
 	fields []*querypb.Field
 }
 
 func (a *streamExecuteAdapter) Recv() (*sqltypes.Result, error) {
-	qr, err := a.recv()
+	ser, err := a.recv()
 	if err != nil {
 		return nil, vterrors.FromGRPC(err)
 	}
+	if len(ser.ArrowResult) > 0 {
+		qr, err := fromArrowResult(ser.Result, ser.ArrowResult)
+		if err != nil {
+			return nil, err
+		}
+		// Each Arrow result repeats the fields of the first value.
+		if a.fields == nil {
+			a.fields = qr.Fields
+		} else {
+			qr.Fields = nil
+		}
+		return qr, nil
+	}
 	if a.fields == nil {
-		a.fields = qr.Fields
+		a.fields = ser.Result.Fields
 	}
-	return sqltypes.CustomProto3ToResult(a.fields, qr), nil
+	return sqltypes.CustomProto3ToResult(a.fields, ser.Result), nil
 }
 
 func (conn *vtgateConn) StreamExecute(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable) (sqltypes.ResultStream, error) {
@@ -147,20 +191,15 @@ func (conn *vtgateConn) StreamExecute(ctx context.Context, session *vtgatepb.Ses
 			Sql:           query,
 			BindVariables: bindVars,
 		},
-		Session: session,
+		Session:      session,
+		ResultFormat: requestedResultFormat(),
 	}
 	stream, err := conn.c.StreamExecute(ctx, req)
 	if err != nil {
 		return nil, vterrors.FromGRPC(err)
 	}
 	return &streamExecuteAdapter{
-		recv: func() (*querypb.QueryResult, error) {
-			ser, err := stream.Recv()
-			if err != nil {
-				return nil, err
-			}
-			return ser.Result, nil
-		},
+		recv: stream.Recv,
 	}, nil
 }
 
@@ -198,6 +237,37 @@ func (conn *vtgateConn) CloseSession(ctx context.Context, session *vtgatepb.Sess
 	return nil
 }
 
+func (conn *vtgateConn) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	request := &vtgatepb.CheckoutSessionRequest{
+		CallerId:       callerid.EffectiveCallerIDFromContext(ctx),
+		Session:        session,
+		TimeoutSeconds: int64(timeout / time.Second),
+	}
+	response, err := conn.c.CheckoutSession(ctx, request)
+	if err != nil {
+		return "", vterrors.FromGRPC(err)
+	}
+	if response.Error != nil {
+		return "", vterrors.FromVTRPC(response.Error)
+	}
+	return response.Token, nil
+}
+
+func (conn *vtgateConn) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	request := &vtgatepb.CheckinSessionRequest{
+		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		Token:    token,
+	}
+	response, err := conn.c.CheckinSession(ctx, request)
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	if response.Error != nil {
+		return nil, vterrors.FromVTRPC(response.Error)
+	}
+	return response.Session, nil
+}
+
 func (conn *vtgateConn) ResolveTransaction(ctx context.Context, dtid string) error {
 	request := &vtgatepb.ResolveTransactionRequest{
 		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
@@ -64,6 +64,41 @@ func TestGRPCVTGateConn(t *testing.T) {
 	client.Close()
 }
 
+// TestGRPCVTGateConnArrow makes sure the results are the same with the
+// Arrow result format.
+func TestGRPCVTGateConnArrow(t *testing.T) {
+	defer func(saved string) { *resultFormat = saved }(*resultFormat)
+	*resultFormat = "arrow"
+
+	// fake service
+	service := CreateFakeServer(t)
+
+	// listen on a random port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Cannot listen: %v", err)
+	}
+
+	// Create a gRPC server and listen on the port
+	server := grpc.NewServer()
+	grpcvtgateservice.RegisterForTest(server, service)
+	go server.Serve(listener)
+
+	// Create a Go RPC client connecting to the server
+	ctx := context.Background()
+	client, err := dial(ctx, listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	RegisterTestDialProtocol(client)
+
+	// run the test suite
+	RunTests(t, client, service)
+
+	// and clean up
+	client.Close()
+}
+
 // TestGRPCVTGateConnAuth makes sure the grpc with auth plugin works
 func TestGRPCVTGateConnAuth(t *testing.T) {
 	var opts []grpc.ServerOption
@@ -27,6 +27,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -124,7 +125,7 @@ func (f *fakeVTGateService) Execute(ctx context.Context, session *vtgatepb.Sessi
 }
 
 // ExecuteBatch is part of the VTGateService interface
-func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	if f.hasError {
 		return session, nil, errTestVtGateError
 	}
@@ -149,10 +150,16 @@ func (f *fakeVTGateService) ExecuteBatch(ctx context.Context, session *vtgatepb.
 		proto.Reset(session)
 		proto.Merge(session, execCase.outSession)
 	}
-	return session, []sqltypes.QueryResponse{{
+	qrl := []sqltypes.QueryResponse{{
 		QueryResult: execCase.result,
 		QueryError:  nil,
-	}}, nil
+	}}
+	if stopOnError && len(sqlList) > 1 {
+		qrl = append(qrl, sqltypes.QueryResponse{
+			QueryError: vterrors.Errorf(vtrpcpb.Code_ABORTED, "stopped"),
+		})
+	}
+	return session, qrl, nil
 }
 
 // StreamExecute is part of the VTGateService interface
@@ -234,6 +241,16 @@ func (f *fakeVTGateService) CloseSession(ctx context.Context, session *vtgatepb.
 	panic("unimplemented")
 }
 
+// CheckoutSession is part of the VTGateService interface
+func (f *fakeVTGateService) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	panic("unimplemented")
+}
+
+// CheckinSession is part of the VTGateService interface
+func (f *fakeVTGateService) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	panic("unimplemented")
+}
+
 // ResolveTransaction is part of the VTGateService interface
 func (f *fakeVTGateService) ResolveTransaction(ctx context.Context, dtid string) error {
 	if f.hasError {
@@ -402,6 +419,12 @@ func testExecuteBatch(t *testing.T, session *vtgateconn.VTGateSession) {
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("none request: %v, want %v", err, want)
 	}
+
+	qr, err = session.ExecuteBatchStopOnError(ctx, []string{execCase.execQuery.SQL, execCase.execQuery.SQL}, []map[string]*querypb.BindVariable{execCase.execQuery.BindVariables, execCase.execQuery.BindVariables})
+	require.NoError(t, err)
+	require.Len(t, qr, 2)
+	require.NoError(t, qr[0].QueryError)
+	require.Equal(t, vtrpcpb.Code_ABORTED, vterrors.Code(qr[1].QueryError))
 }
 
 func testExecuteBatchError(t *testing.T, session *vtgateconn.VTGateSession, fake *fakeVTGateService) {
@@ -19,6 +19,7 @@ package grpcvtgateservice
 
 import (
 	"flag"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -29,10 +30,11 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
+	"vitess.io/vitess/go/vt/protocompat"
 	"vitess.io/vitess/go/vt/servenv"
-	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate"
+	"vitess.io/vitess/go/vt/vtgate/arrowresult"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -106,22 +108,19 @@ func (vtg *VTGate) Execute(ctx context.Context, request *vtgatepb.ExecuteRequest
 	ctx = withCallerIDContext(ctx, request.CallerId)
 
 	// Handle backward compatibility.
-	session := request.Session
-	if session == nil {
-		session = &vtgatepb.Session{Autocommit: true}
-	}
-	if session.TargetString == "" && request.TabletType != topodatapb.TabletType_UNKNOWN {
-		session.TargetString = request.KeyspaceShard + "@" + topoproto.TabletTypeLString(request.TabletType)
-	}
-	if session.Options == nil {
-		session.Options = request.Options
-	}
+	session := protocompat.VTGateSession(request.Session, request.KeyspaceShard, request.TabletType, request.Options)
 	session, result, err := vtg.server.Execute(ctx, session, request.Query.Sql, request.Query.BindVariables)
-	return &vtgatepb.ExecuteResponse{
+	response = &vtgatepb.ExecuteResponse{
 		Result:  sqltypes.ResultToProto3(result),
 		Session: session,
 		Error:   vterrors.ToVTRPC(err),
-	}, nil
+	}
+	if request.ResultFormat == vtgatepb.ResultFormat_ARROW && result != nil {
+		if response.ArrowResult, err = toArrowResult(response.Result, result.Fields, result); err != nil {
+			response.Result, response.Error = nil, vterrors.ToVTRPC(err)
+		}
+	}
+	return response, nil
 }
 
 // ExecuteBatch is the RPC version of vtgateservice.VTGateService method
@@ -135,17 +134,8 @@ func (vtg *VTGate) ExecuteBatch(ctx context.Context, request *vtgatepb.ExecuteBa
 		bindVars[queryNum] = query.BindVariables
 	}
 	// Handle backward compatibility.
-	session := request.Session
-	if session == nil {
-		session = &vtgatepb.Session{Autocommit: true}
-	}
-	if session.TargetString == "" {
-		session.TargetString = request.KeyspaceShard + "@" + topoproto.TabletTypeLString(request.TabletType)
-	}
-	if session.Options == nil {
-		session.Options = request.Options
-	}
-	session, results, err := vtg.server.ExecuteBatch(ctx, session, sqlQueries, bindVars)
+	session := protocompat.VTGateSession(request.Session, request.KeyspaceShard, request.TabletType, request.Options)
+	session, results, err := vtg.server.ExecuteBatch(ctx, session, sqlQueries, bindVars, request.StopOnError)
 	return &vtgatepb.ExecuteBatchResponse{
 		Results: sqltypes.QueryResponsesToProto3(results),
 		Session: session,
@@ -159,28 +149,45 @@ func (vtg *VTGate) StreamExecute(request *vtgatepb.StreamExecuteRequest, stream
 	ctx := withCallerIDContext(stream.Context(), request.CallerId)
 
 	// Handle backward compatibility.
-	session := request.Session
-	if session == nil {
-		session = &vtgatepb.Session{Autocommit: true}
-	}
-
-	// Do not set target if the tablet_type is not set correctly.
-	if session.TargetString == "" && request.TabletType != topodatapb.TabletType_UNKNOWN {
-		session.TargetString = request.KeyspaceShard + "@" + topoproto.TabletTypeLString(request.TabletType)
-	}
-	if session.Options == nil {
-		session.Options = request.Options
-	}
+	session := protocompat.VTGateSession(request.Session, request.KeyspaceShard, request.TabletType, request.Options)
+	// fields are the fields of the first value, which the Arrow results of
+	// the next values repeat.
+	var fields []*querypb.Field
 	vtgErr := vtg.server.StreamExecute(ctx, session, request.Query.Sql, request.Query.BindVariables, func(value *sqltypes.Result) error {
+		response := &vtgatepb.StreamExecuteResponse{
+			Result: sqltypes.ResultToProto3(value),
+		}
+		if request.ResultFormat == vtgatepb.ResultFormat_ARROW {
+			if fields == nil {
+				fields = value.Fields
+			}
+			var err error
+			if response.ArrowResult, err = toArrowResult(response.Result, fields, value); err != nil {
+				return err
+			}
+		}
 		// Send is not safe to call concurrently, but vtgate
 		// guarantees that it's not.
-		return stream.Send(&vtgatepb.StreamExecuteResponse{
-			Result: sqltypes.ResultToProto3(value),
-		})
+		return stream.Send(response)
 	})
 	return vterrors.ToGRPC(vtgErr)
 }
 
+// toArrowResult returns the Arrow IPC stream of fields and of the rows of
+// result, and removes them from qr, the proto of result. The results
+// without fields, like the ones of DMLs, are left as they are.
+func toArrowResult(qr *querypb.QueryResult, fields []*querypb.Field, result *sqltypes.Result) ([]byte, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	data, err := arrowresult.Encode(fields, result.Rows)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot encode the result in the Arrow format")
+	}
+	qr.Fields, qr.Rows = nil, nil
+	return data, nil
+}
+
 // Prepare is the RPC version of vtgateservice.VTGateService method
 func (vtg *VTGate) Prepare(ctx context.Context, request *vtgatepb.PrepareRequest) (response *vtgatepb.PrepareResponse, err error) {
 	defer vtg.server.HandlePanic(&err)
@@ -214,6 +221,34 @@ func (vtg *VTGate) CloseSession(ctx context.Context, request *vtgatepb.CloseSess
 	}, nil
 }
 
+// CheckoutSession is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) CheckoutSession(ctx context.Context, request *vtgatepb.CheckoutSessionRequest) (response *vtgatepb.CheckoutSessionResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+
+	session := request.Session
+	if session == nil {
+		session = &vtgatepb.Session{Autocommit: true}
+	}
+	token, err := vtg.server.CheckoutSession(ctx, session, time.Duration(request.TimeoutSeconds)*time.Second)
+	return &vtgatepb.CheckoutSessionResponse{
+		Error: vterrors.ToVTRPC(err),
+		Token: token,
+	}, nil
+}
+
+// CheckinSession is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) CheckinSession(ctx context.Context, request *vtgatepb.CheckinSessionRequest) (response *vtgatepb.CheckinSessionResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+
+	session, err := vtg.server.CheckinSession(ctx, request.Token)
+	return &vtgatepb.CheckinSessionResponse{
+		Error:   vterrors.ToVTRPC(err),
+		Session: session,
+	}, nil
+}
+
 // ResolveTransaction is the RPC version of vtgateservice.VTGateService method
 func (vtg *VTGate) ResolveTransaction(ctx context.Context, request *vtgatepb.ResolveTransactionRequest) (response *vtgatepb.ResolveTransactionResponse, err error) {
 	defer vtg.server.HandlePanic(&err)
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var keyspaceSettingsRefreshInterval = flag.Duration("keyspace_settings_refresh_interval", 30*time.Second, "how often vtgate reads the per-keyspace settings from the topo, 0 disables keyspace settings")
+
+// keyspaceSettings holds the settings of the keyspaces in the vschema, as
+// last read from the topo.
+type keyspaceSettings struct {
+	mu       sync.RWMutex
+	settings map[string]*topo.KeyspaceSettings
+
+	cancel context.CancelFunc
+}
+
+// get returns the settings of a keyspace, or nil if it has none.
+func (ks *keyspaceSettings) get(keyspace string) *topo.KeyspaceSettings {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.settings[keyspace]
+}
+
+// set replaces the settings of all keyspaces, and returns the keyspaces
+// whose settings now reject the scatter queries they accepted before.
+func (ks *keyspaceSettings) set(settings map[string]*topo.KeyspaceSettings) map[string]bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	noScatter := make(map[string]bool)
+	for keyspace, s := range settings {
+		if previous := ks.settings[keyspace]; s.NoScatter && (previous == nil || !previous.NoScatter) {
+			noScatter[keyspace] = true
+		}
+	}
+	ks.settings = settings
+	return noScatter
+}
+
+// start reads the keyspace settings from the topo every
+// --keyspace_settings_refresh_interval, until stop is called.
+func (ks *keyspaceSettings) start(e *Executor) {
+	if *keyspaceSettingsRefreshInterval <= 0 {
+		return
+	}
+
+	ts, err := e.serv.GetTopoServer()
+	if err != nil {
+		log.Warningf("keyspace settings are disabled, no topo server available: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ks.cancel = cancel
+	go func() {
+		ticker := time.NewTicker(*keyspaceSettingsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			ks.refresh(ctx, ts, e)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stop stops reading the keyspace settings from the topo.
+func (ks *keyspaceSettings) stop() {
+	if ks.cancel != nil {
+		ks.cancel()
+	}
+}
+
+// refresh reads the settings of every keyspace in the vschema. Plans are
+// checked against the scatter settings when they are built, so the cached
+// plans that scatter on a keyspace are evicted when its settings start to
+// reject scatter queries. The other settings are applied when the queries
+// run, and don't invalidate any plan.
+func (ks *keyspaceSettings) refresh(ctx context.Context, ts *topo.Server, e *Executor) {
+	vschema := e.VSchema()
+	if vschema == nil {
+		return
+	}
+
+	settings := make(map[string]*topo.KeyspaceSettings, len(vschema.Keyspaces))
+	for keyspace := range vschema.Keyspaces {
+		s, err := ts.GetKeyspaceSettings(ctx, keyspace)
+		if err != nil {
+			log.Warningf("failed to read settings of keyspace %s, keeping the previous ones: %v", keyspace, err)
+			s = ks.get(keyspace)
+		}
+		if s != nil && !reflect.DeepEqual(s, &topo.KeyspaceSettings{}) {
+			settings[keyspace] = s
+		}
+	}
+
+	if noScatter := ks.set(settings); len(noScatter) > 0 {
+		log.Infof("scatter queries are now disallowed in keyspaces %v, evicting their plans from the plan cache", noScatter)
+		e.evictScatterPlans(noScatter)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestKeyspaceSettingsNoScatter(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+
+	for _, query := range []string{"select id from user", "select id from user where id = 1", "select id from main1"} {
+		_, err := executorExec(executor, query, nil)
+		require.NoError(t, err)
+	}
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 3)
+
+	// Only the cached plans that scatter on the keyspace are evicted.
+	noScatter := executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {NoScatter: true},
+	})
+	require.Equal(t, map[string]bool{KsTestSharded: true}, noScatter)
+	executor.evictScatterPlans(noScatter)
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 2)
+
+	_, err := executorExec(executor, "select id from user", nil)
+	require.EqualError(t, err, "plan includes scatter, which is disallowed for keyspace TestExecutor by its keyspace settings")
+
+	_, err = executorExec(executor, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+
+	_, err = executorExec(executor, "select /*vt+ ALLOW_SCATTER */ id from user", nil)
+	require.NoError(t, err)
+
+	noScatter = executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {NoScatter: true, DefaultWorkload: "olap"},
+	})
+	assert.Empty(t, noScatter)
+}
+
+func TestKeyspaceSettingsDefaultWorkload(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+
+	executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {DefaultWorkload: "olap"},
+	})
+
+	assert.Equal(t, querypb.ExecuteOptions_OLAP, executor.defaultWorkload(KsTestSharded))
+	assert.Equal(t, querypb.ExecuteOptions_OLAP, executor.defaultWorkload(KsTestSharded+"@replica"))
+	assert.Equal(t, querypb.ExecuteOptions_UNSPECIFIED, executor.defaultWorkload(KsTestUnsharded))
+}
+
+func TestKeyspaceSettingsTrafficShedding(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	defer func(f func() int) { shedRandom = f }(shedRandom)
+	random := 0
+	shedRandom = func() int { return random }
+
+	executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {ShedPercent: 30, ShedPriority: 80},
+	})
+	before := queriesShed.Counts()[KsTestSharded+".90"]
+
+	// The queries of a low priority are shed, with the given probability.
+	_, err := executorExec(executor, "select /*vt+ PRIORITY=90 */ id from user where id = 1", nil)
+	require.EqualError(t, err, "query shed by the traffic shedding of keyspace TestExecutor, retry later")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.Equal(t, before+1, queriesShed.Counts()[KsTestSharded+".90"])
+
+	random = 30
+	_, err = executorExec(executor, "select /*vt+ PRIORITY=90 */ id from user where id = 1", nil)
+	require.NoError(t, err)
+
+	// The queries of a higher priority, or of another keyspace, are not shed.
+	random = 0
+	_, err = executorExec(executor, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	_, err = executorExec(executor, "select /*vt+ PRIORITY=90 */ id from main1", nil)
+	require.NoError(t, err)
+
+	// The priority of the session applies to its queries.
+	session := NewAutocommitSession(&vtgatepb.Session{TargetString: "@primary"})
+	session.GetOrCreateOptions().Priority = "100"
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "select id from user where id = 1", nil)
+	require.Error(t, err)
+
+	// The queries of a transaction that opened connections are not shed.
+	session = NewSafeSession(&vtgatepb.Session{TargetString: "@primary", InTransaction: true})
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	session.GetOrCreateOptions().Priority = "100"
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+}
+
+func TestKeyspaceSettingsFeatureGates(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	query := "delete /*vt+ DML_BATCH_SIZE=10 DML_BATCH_COLUMN=id */ from user_extra where extra = 'foo'"
+
+	executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {FeatureGates: map[string]bool{"DMLBatch": false}},
+	})
+	_, err := executorExec(executor, query, nil)
+	require.EqualError(t, err, "batched DML is disabled in keyspace TestExecutor by the DMLBatch feature gate")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	// The queries that don't use the feature are not affected.
+	_, err = executorExec(executor, "delete from user_extra where extra = 'foo'", nil)
+	require.NoError(t, err)
+
+	executor.ksSettings.set(map[string]*topo.KeyspaceSettings{
+		KsTestSharded: {FeatureGates: map[string]bool{"DMLBatch": true}},
+	})
+	session := NewAutocommitSession(&vtgatepb.Session{TargetString: "@primary"})
+	_, err = executor.Execute(context.Background(), "TestExecute", session, query, nil)
+	require.NoError(t, err)
+}
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 )
@@ -55,6 +56,11 @@ type LogStats struct {
 	CommitTime    time.Duration
 	Error         error
 	SessionUUID   string
+
+	// plan is the plan of the query, if it was planned.
+	plan *engine.Plan
+	// tenant is the TENANT directive of the query, for the usage accounting.
+	tenant string
 }
 
 // NewLogStats constructs a new LogStats with supplied Method and ctx
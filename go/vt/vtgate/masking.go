@@ -0,0 +1,327 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/flagutil"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	maskingRoles       flagutil.StringListValue
+	maskingHashKeyFile = flag.String("masking_hash_key_file", "", "Path to a file holding the secret key of the HMAC-SHA256 of the hash masking policy, of at least 32 bytes, which must be the same on all the vtgates whose hashed values are compared. Without it, the values of the columns with the hash policy are redacted")
+
+	// maskingHashKey is the key of the hash masking policy, nil if it is not
+	// set.
+	maskingHashKey []byte
+
+	maskedResults = stats.NewCountersWithSingleLabel("MaskedResults", "Number of masked columns in the query results sent, by masking policy", "Policy")
+)
+
+const (
+	// maskedValue replaces the values, or the parts of the values, that are
+	// hidden by the masking policies.
+	maskedValue = "****"
+	// maskedColumnLength is the minimum length of the masked columns, which
+	// fits the hex-encoded HMAC-SHA256 of the hash policy in utf8mb4.
+	maskedColumnLength = 4 * 2 * sha256.Size
+	// partialMaskingSuffix is the number of characters kept by the partial
+	// policy.
+	partialMaskingSuffix = 4
+)
+
+// minMaskingHashKeyLength is the minimum length of the key of the hash
+// policy, the size of its HMAC-SHA256.
+const minMaskingHashKeyLength = sha256.Size
+
+func init() {
+	flag.Var(&maskingRoles, "masking_roles", "Comma-separated list of roles, i.e. groups of the MySQL users as returned by the auth server, whose query results have the values of the columns with a masking policy in the vschema masked")
+}
+
+// loadMaskingHashKey loads the key of the hash masking policy from
+// --masking_hash_key_file, if it is set.
+func loadMaskingHashKey() error {
+	if *maskingHashKeyFile == "" {
+		return nil
+	}
+	key, err := os.ReadFile(*maskingHashKeyFile)
+	if err != nil {
+		return err
+	}
+	key = bytes.TrimSpace(key)
+	if len(key) < minMaskingHashKeyLength {
+		return fmt.Errorf("the key of %v must hold at least %d bytes", *maskingHashKeyFile, minMaskingHashKeyLength)
+	}
+	maskingHashKey = key
+	return nil
+}
+
+// newResultMasker returns the masker of the query results of the caller, or
+// nil if they don't need to be masked.
+func newResultMasker(ctx context.Context, vschema *vindexes.VSchema) *resultMasker {
+	if len(maskingRoles) == 0 || vschema == nil || !vschema.HasMaskedColumns() {
+		return nil
+	}
+	im := callerid.ImmediateCallerIDFromContext(ctx)
+	if im == nil {
+		return nil
+	}
+	for _, group := range im.Groups {
+		for _, role := range maskingRoles {
+			if group == role {
+				return &resultMasker{vschema: vschema}
+			}
+		}
+	}
+	return nil
+}
+
+// checkMaskedColumns rejects the statements of the callers whose results are
+// masked that use a masked column other than as a column of the result, since
+// only those are masked: its values would be returned by the expressions
+// computed from it, e.g. CONCAT(email, ''), inferred from the rows returned,
+// e.g. with WHERE ssn LIKE '1%', or copied to other tables. The columns are
+// resolved to their tables by the semantic analysis of the statement.
+func checkMaskedColumns(vcursor *vcursorImpl, stmt sqlparser.Statement) error {
+	if newResultMasker(vcursor.ctx, vcursor.vschema) == nil {
+		return nil
+	}
+	switch stmt.(type) {
+	case sqlparser.SelectStatement, *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+	default:
+		return nil
+	}
+
+	// The analysis rewrites parts of the statement, which is planned as is.
+	stmt = sqlparser.CloneStatement(stmt)
+	semTable, err := semantics.Analyze(stmt, vcursor.keyspace, vcursor)
+	if err != nil {
+		return err
+	}
+	resultColumns := map[*sqlparser.ColName]bool{}
+	var addResultColumns func(sel sqlparser.SelectStatement)
+	addResultColumns = func(sel sqlparser.SelectStatement) {
+		switch sel := sel.(type) {
+		case *sqlparser.Select:
+			for _, expr := range sel.SelectExprs {
+				if aliased, ok := expr.(*sqlparser.AliasedExpr); ok {
+					if col, ok := aliased.Expr.(*sqlparser.ColName); ok {
+						resultColumns[col] = true
+					}
+				}
+			}
+		case *sqlparser.Union:
+			addResultColumns(sel.Left)
+			addResultColumns(sel.Right)
+		}
+	}
+	if sel, ok := stmt.(sqlparser.SelectStatement); ok {
+		addResultColumns(sel)
+	}
+
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		col, ok := node.(*sqlparser.ColName)
+		if !ok || resultColumns[col] {
+			return true, nil
+		}
+		if columnMasking(vcursor.vschema, semTable, col) != "" {
+			return false, vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query uses the masked column %s other than as a column of the result", sqlparser.String(col))
+		}
+		return true, nil
+	}, stmt)
+}
+
+// columnMasking returns the masking policy of a column of a statement. The
+// columns that don't resolve to a table of the vschema, e.g. the columns of
+// derived tables, are masked if any column with the same name is.
+func columnMasking(vschema *vindexes.VSchema, semTable *semantics.SemTable, col *sqlparser.ColName) string {
+	if ti, err := semTable.TableInfoFor(semTable.DirectDeps(col)); err == nil {
+		if table := ti.GetVindexTable(); table != nil {
+			for _, column := range table.Columns {
+				if column.Name.Equal(col.Name) {
+					return column.Masking
+				}
+			}
+			return ""
+		}
+	}
+	return vschema.FindColumnMasking("", "", col.Name.String())
+}
+
+// resultMasker masks the values of the columns with a masking policy in the
+// query results, after their execution. The policies are found from the
+// fields of the results, so a stream of results can be masked as long as
+// its fields come first.
+type resultMasker struct {
+	vschema *vindexes.VSchema
+	// policies are the masking policies of the columns of the last fields,
+	// or nil if none of them is masked.
+	policies []string
+	fields   []*querypb.Field
+}
+
+// mask returns the result with its masked columns masked. The result itself
+// is not modified, since it might be shared.
+func (rm *resultMasker) mask(qr *sqltypes.Result) *sqltypes.Result {
+	if qr == nil {
+		return nil
+	}
+	if len(qr.Fields) > 0 {
+		rm.setFields(qr.Fields)
+	}
+	if rm.policies == nil {
+		return qr
+	}
+
+	masked := *qr
+	if len(qr.Fields) > 0 {
+		masked.Fields = rm.fields
+		for _, policy := range rm.policies {
+			if policy != "" {
+				maskedResults.Add(policy, 1)
+			}
+		}
+	}
+	if len(qr.Rows) > 0 {
+		masked.Rows = make([][]sqltypes.Value, len(qr.Rows))
+		for i, row := range qr.Rows {
+			maskedRow := make([]sqltypes.Value, len(row))
+			copy(maskedRow, row)
+			for j, policy := range rm.policies {
+				if policy != "" && j < len(row) {
+					maskedRow[j] = maskValue(policy, row[j], rm.fields[j].Type)
+				}
+			}
+			masked.Rows[i] = maskedRow
+		}
+	}
+	return &masked
+}
+
+// setFields finds the masking policies of the columns of the fields.
+func (rm *resultMasker) setFields(fields []*querypb.Field) {
+	rm.policies = nil
+	rm.fields = nil
+	for i, field := range fields {
+		table := field.OrgTable
+		if table == "" {
+			table = field.Table
+		}
+		name := field.OrgName
+		if name == "" {
+			name = field.Name
+		}
+		policy := rm.vschema.FindColumnMasking(field.Database, table, name)
+		if policy == "" {
+			continue
+		}
+		if rm.policies == nil {
+			rm.policies = make([]string, len(fields))
+			rm.fields = make([]*querypb.Field, len(fields))
+			copy(rm.fields, fields)
+		}
+		rm.policies[i] = policy
+		rm.fields[i] = maskField(field)
+	}
+}
+
+// maskField returns the field of a masked column, whose values are strings.
+func maskField(field *querypb.Field) *querypb.Field {
+	masked := proto.Clone(field).(*querypb.Field)
+	switch {
+	case sqltypes.IsBinary(field.Type):
+		masked.Type = sqltypes.VarBinary
+	case sqltypes.IsText(field.Type):
+		masked.Type = sqltypes.VarChar
+	default:
+		masked.Type = sqltypes.VarChar
+		masked.Charset = collations.CollationUtf8mb4ID
+	}
+	// The flags of the type and of the values of the column no longer apply,
+	// but those of its keys still do.
+	masked.Flags &^= uint32(querypb.MySqlFlag_NUM_FLAG | querypb.MySqlFlag_UNSIGNED_FLAG | querypb.MySqlFlag_ZEROFILL_FLAG |
+		querypb.MySqlFlag_BLOB_FLAG | querypb.MySqlFlag_ENUM_FLAG | querypb.MySqlFlag_SET_FLAG |
+		querypb.MySqlFlag_TIMESTAMP_FLAG | querypb.MySqlFlag_ON_UPDATE_NOW_FLAG)
+	if masked.Type != sqltypes.VarBinary {
+		masked.Flags &^= uint32(querypb.MySqlFlag_BINARY_FLAG | querypb.MySqlFlag_BINCMP_FLAG)
+	}
+	masked.Decimals = 0
+	if masked.ColumnLength < maskedColumnLength {
+		masked.ColumnLength = maskedColumnLength
+	}
+	return masked
+}
+
+// maskValue masks a value with a masking policy. NULL values are kept.
+func maskValue(policy string, value sqltypes.Value, typ querypb.Type) sqltypes.Value {
+	if value.IsNull() {
+		return value
+	}
+	raw := value.ToString()
+	switch policy {
+	case vindexes.MaskingHash:
+		// The hash is keyed, so that the values with little entropy, e.g.
+		// phone numbers, cannot be found by hashing all their candidates.
+		if maskingHashKey == nil {
+			raw = maskedValue
+			break
+		}
+		mac := hmac.New(sha256.New, maskingHashKey)
+		mac.Write(value.Raw())
+		raw = hex.EncodeToString(mac.Sum(nil))
+	case vindexes.MaskingPartial:
+		runes := []rune(raw)
+		if len(runes) > partialMaskingSuffix {
+			raw = maskedValue + string(runes[len(runes)-partialMaskingSuffix:])
+		} else {
+			raw = maskedValue
+		}
+	case vindexes.MaskingEmail:
+		if at := strings.LastIndexByte(raw, '@'); at > 0 {
+			first, _ := utf8.DecodeRuneInString(raw)
+			raw = string(first) + maskedValue + raw[at:]
+		} else {
+			raw = maskedValue
+		}
+	default:
+		raw = maskedValue
+	}
+	return sqltypes.MakeTrusted(typ, []byte(raw))
+}
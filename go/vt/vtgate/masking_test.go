@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/flagutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestMaskValue(t *testing.T) {
+	defer func(key []byte) {
+		maskingHashKey = key
+	}(maskingHashKey)
+	maskingHashKey = []byte("test key")
+
+	testcases := []struct {
+		policy string
+		value  sqltypes.Value
+		want   string
+	}{
+		{vindexes.MaskingRedact, sqltypes.NewVarChar("secret"), "****"},
+		{vindexes.MaskingRedact, sqltypes.NewInt64(42), "****"},
+		{vindexes.MaskingHash, sqltypes.NewVarChar("secret"), "5125d4101f4acdf466ec68bec1806f37615871b1dbb9221b378a6cbe99429bfc"},
+		{vindexes.MaskingPartial, sqltypes.NewVarChar("4111111111111111"), "****1111"},
+		{vindexes.MaskingPartial, sqltypes.NewVarChar("1111"), "****"},
+		{vindexes.MaskingEmail, sqltypes.NewVarChar("jane.doe@example.com"), "j****@example.com"},
+		{vindexes.MaskingEmail, sqltypes.NewVarChar("éric@example.com"), "é****@example.com"},
+		{vindexes.MaskingEmail, sqltypes.NewVarChar("@example.com"), "****"},
+		{vindexes.MaskingEmail, sqltypes.NewVarChar("not an email"), "****"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.policy+"/"+tc.value.ToString(), func(t *testing.T) {
+			assert.Equal(t, tc.want, maskValue(tc.policy, tc.value, sqltypes.VarChar).ToString())
+		})
+	}
+	assert.True(t, maskValue(vindexes.MaskingRedact, sqltypes.NULL, sqltypes.VarChar).IsNull())
+}
+
+func TestMaskValueHashKey(t *testing.T) {
+	defer func(key []byte, file string) {
+		maskingHashKey, *maskingHashKeyFile = key, file
+	}(maskingHashKey, *maskingHashKeyFile)
+
+	// Without a key, the hashed values are redacted.
+	maskingHashKey = nil
+	assert.Equal(t, "****", maskValue(vindexes.MaskingHash, sqltypes.NewVarChar("555-0100"), sqltypes.VarChar).ToString())
+
+	hash := func(key string) string {
+		dir := t.TempDir()
+		*maskingHashKeyFile = filepath.Join(dir, "key")
+		require.NoError(t, os.WriteFile(*maskingHashKeyFile, []byte(key+"\n"), 0600))
+		require.NoError(t, loadMaskingHashKey())
+		return maskValue(vindexes.MaskingHash, sqltypes.NewVarChar("555-0100"), sqltypes.VarChar).ToString()
+	}
+	key1, key2 := strings.Repeat("a", 32), strings.Repeat("b", 32)
+	// The hash is deterministic for a key, and depends on it.
+	assert.Equal(t, hash(key1), hash(key1))
+	assert.NotEqual(t, hash(key1), hash(key2))
+	assert.Len(t, hash(key1), 64)
+
+	*maskingHashKeyFile = filepath.Join(t.TempDir(), "short")
+	require.NoError(t, os.WriteFile(*maskingHashKeyFile, []byte("short"), 0600))
+	assert.ErrorContains(t, loadMaskingHashKey(), "must hold at least 32 bytes")
+}
+
+func TestExecutorMasking(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+	defer func(roles flagutil.StringListValue) {
+		maskingRoles = roles
+	}(maskingRoles)
+	maskingRoles = flagutil.StringListValue{"analyst"}
+
+	srvVSchema := getSandboxSrvVSchema()
+	srvVSchema.Keyspaces[KsTestSharded].Tables["user"].Columns = []*vschemapb.Column{
+		{Name: "email", Type: sqltypes.VarChar, Masking: vindexes.MaskingEmail},
+		{Name: "ssn", Type: sqltypes.VarChar, Masking: vindexes.MaskingRedact},
+	}
+	executor.SaveVSchema(vindexes.BuildVSchema(srvVSchema), nil)
+
+	fields := []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64, Database: "vt_" + KsTestSharded, Table: "u", OrgTable: "user", OrgName: "id"},
+		{Name: "mail", Type: sqltypes.VarChar, Database: "vt_" + KsTestSharded, Table: "u", OrgTable: "user", OrgName: "email"},
+		{Name: "ssn", Type: sqltypes.Int64, Flags: uint32(querypb.MySqlFlag_NUM_FLAG | querypb.MySqlFlag_NOT_NULL_FLAG | querypb.MySqlFlag_UNIQUE_KEY_FLAG), Database: "vt_" + KsTestSharded, Table: "u", OrgTable: "user", OrgName: "ssn"},
+	}
+	row := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("jane@example.com"), sqltypes.NewInt64(123456789)}
+	query := "select id, email as mail, ssn from user as u where id = 1"
+
+	analyst := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "jane", Groups: []string{"analyst"}})
+	admin := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "john", Groups: []string{"admin"}})
+
+	sbc1.SetResults([]*sqltypes.Result{{Fields: fields, Rows: [][]sqltypes.Value{row}}})
+	qr, err := executor.Execute(analyst, "TestExecutorMasking", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(1) VARCHAR("j****@example.com") VARCHAR("****")]]`, fmt.Sprintf("%v", qr.Rows))
+	assert.Equal(t, sqltypes.VarChar, qr.Fields[2].Type)
+	assert.Equal(t, uint32(querypb.MySqlFlag_NOT_NULL_FLAG|querypb.MySqlFlag_UNIQUE_KEY_FLAG), qr.Fields[2].Flags)
+	// The results of the tablets are not modified.
+	assert.Equal(t, "jane@example.com", row[1].ToString())
+	assert.Equal(t, sqltypes.Int64, fields[2].Type)
+
+	sbc1.SetResults([]*sqltypes.Result{{Fields: fields, Rows: [][]sqltypes.Value{row}}})
+	qr, err = executor.Execute(admin, "TestExecutorMasking", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(1) VARCHAR("jane@example.com") INT64(123456789)]]`, fmt.Sprintf("%v", qr.Rows))
+
+	// The fields without the name of their table are masked by column name.
+	sbc1.SetResults([]*sqltypes.Result{{
+		Fields: sqltypes.MakeTestFields("id|email", "int64|varchar"),
+		Rows:   [][]sqltypes.Value{row[:2]},
+	}})
+	var streamed []*sqltypes.Result
+	err = executor.StreamExecute(analyst, "TestExecutorMasking", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), "select id, email from user where id = 1", nil, func(qr *sqltypes.Result) error {
+		streamed = append(streamed, qr)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, streamed, 2)
+	assert.Equal(t, `[[INT64(1) VARCHAR("j****@example.com")]]`, fmt.Sprintf("%v", streamed[1].Rows))
+}
+
+func TestExecutorMaskedColumnExpressions(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	defer func(roles flagutil.StringListValue) {
+		maskingRoles = roles
+	}(maskingRoles)
+	maskingRoles = flagutil.StringListValue{"analyst"}
+
+	srvVSchema := getSandboxSrvVSchema()
+	srvVSchema.Keyspaces[KsTestSharded].Tables["user"].Columns = []*vschemapb.Column{
+		{Name: "id", Type: sqltypes.Int64},
+		{Name: "email", Type: sqltypes.VarChar, Masking: vindexes.MaskingEmail},
+		{Name: "name", Type: sqltypes.VarChar},
+	}
+	srvVSchema.Keyspaces[KsTestSharded].Tables["user"].ColumnListAuthoritative = true
+	executor.SaveVSchema(vindexes.BuildVSchema(srvVSchema), nil)
+
+	analyst := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "jane", Groups: []string{"analyst"}})
+	admin := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "john", Groups: []string{"admin"}})
+
+	allowed := []string{
+		"select email from user where id = 1",
+		"select u.email as mail, name from user as u where name = 'jane' order by name",
+		"select email from user union all select name from user",
+		"select concat(name, '') from user",
+	}
+	for _, query := range allowed {
+		t.Run(query, func(t *testing.T) {
+			_, err := executor.Execute(analyst, "TestExecutorMaskedColumnExpressions", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), query, nil)
+			require.NoError(t, err)
+		})
+	}
+
+	rejected := []string{
+		"select concat(email, '') from user",
+		"select id from user where email like 'j%'",
+		"select id from user order by email",
+		"select m from (select email as m from user) as t",
+		"select id from user where id in (select id from user where email = 'jane@example.com')",
+		"update user set name = email where id = 1",
+	}
+	for _, query := range rejected {
+		t.Run(query, func(t *testing.T) {
+			_, err := executor.Execute(analyst, "TestExecutorMaskedColumnExpressions", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), query, nil)
+			require.ErrorContains(t, err, "the query uses the masked column email other than as a column of the result")
+			assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+
+			// The results of the other users are not masked.
+			_, err = executor.Execute(admin, "TestExecutorMaskedColumnExpressions", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), query, nil)
+			if err != nil {
+				assert.NotContains(t, err.Error(), "masked column")
+			}
+		})
+	}
+}
@@ -97,6 +97,17 @@ func (e *Executor) newExecute(
 		logStats.Error = err
 		return err
 	}
+	if plan.QueryHints != nil {
+		defer safeSession.applyQueryHints(plan.QueryHints)()
+	}
+	if err := e.checkTrafficShedding(safeSession, plan); err != nil {
+		logStats.Error = err
+		return err
+	}
+	if err := e.checkFeatureGates(plan); err != nil {
+		logStats.Error = err
+		return err
+	}
 
 	if plan.Instructions.NeedsTransaction() {
 		return e.insideTransaction(ctx, safeSession, logStats,
@@ -282,6 +293,7 @@ func (e *Executor) logPlanningFinished(logStats *LogStats, plan *engine.Plan) ti
 	execStart := time.Now()
 	if plan != nil {
 		logStats.StmtType = plan.Type.String()
+		logStats.plan = plan
 	}
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
 	return execStart
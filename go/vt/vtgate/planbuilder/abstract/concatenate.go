@@ -66,6 +66,47 @@ func (c *Concatenate) PushPredicate(expr sqlparser.Expr, semTable *semantics.Sem
 	return c, nil
 }
 
+// pushPredicateOnColumns pushes a predicate on the columns of a derived table over the UNION
+// to all the sources, rewritten with the expressions each SELECT returns for the columns
+func (c *Concatenate) pushPredicateOnColumns(expr sqlparser.Expr, tableInfo semantics.TableInfo, semTable *semantics.SemTable) (LogicalOperator, error) {
+	if c.Limit != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "can't push predicates on concatenate")
+	}
+	newSources := make([]LogicalOperator, 0, len(c.Sources))
+	for index, source := range c.Sources {
+		sel := c.SelectStmts[index]
+		if sel == nil || sel.Limit != nil || len(sel.GroupBy) > 0 || sel.Having != nil || sqlparser.ContainsAggregation(sel.SelectExprs) {
+			// filtering the rows of these SELECTs changes what they return
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "can't push predicates on concatenate")
+		}
+		newExpr, err := semantics.RewriteDerivedUnionExpression(expr, tableInfo, sel)
+		if err != nil {
+			return nil, err
+		}
+		newSrc, err := source.PushPredicate(newExpr, semTable)
+		if err != nil {
+			return nil, err
+		}
+		newSources = append(newSources, newSrc)
+	}
+	c.Sources = newSources
+	return c, nil
+}
+
+// selectsStar returns true if the first SELECT of the UNION is a SELECT *
+func (c *Concatenate) selectsStar() bool {
+	sel := c.SelectStmts[0]
+	if sel == nil {
+		return false
+	}
+	for _, expr := range sel.SelectExprs {
+		if _, isStarExpr := expr.(*sqlparser.StarExpr); isStarExpr {
+			return true
+		}
+	}
+	return false
+}
+
 // UnsolvedPredicates implements the Operator interface
 func (c *Concatenate) UnsolvedPredicates(*semantics.SemTable) []sqlparser.Expr {
 	return nil
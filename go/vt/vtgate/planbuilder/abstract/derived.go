@@ -50,6 +50,15 @@ func (d *Derived) PushPredicate(expr sqlparser.Expr, semTable *semantics.SemTabl
 		return nil, err
 	}
 
+	if concat, isConcat := d.Inner.(*Concatenate); isConcat && !concat.selectsStar() {
+		newSrc, err := concat.pushPredicateOnColumns(expr, tableInfo, semTable)
+		if err != nil {
+			return nil, err
+		}
+		d.Inner = newSrc
+		return d, nil
+	}
+
 	newExpr, err := semantics.RewriteDerivedExpression(expr, tableInfo)
 	if err != nil {
 		return nil, err
@@ -189,10 +189,6 @@ func createOperatorFromUnion(node *sqlparser.Union, semTable *semantics.SemTable
 		return nil, err
 	}
 
-	_, isRHSUnion := node.Right.(*sqlparser.Union)
-	if isRHSUnion {
-		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "nesting of unions at the right-hand side is not yet supported")
-	}
 	opRHS, err := CreateLogicalOperatorFromAST(node.Right, semTable)
 	if err != nil {
 		return nil, err
@@ -63,13 +63,21 @@ func (hp *horizonPlanning) pushAggregation(
 		// we just remove the simpleProjection. We are doing an OA on top anyway, so no need to clean up the output columns
 		return hp.pushAggregation(ctx, plan.input, grouping, aggregations, ignoreOutputOrder)
 
-	case *limit:
-		// if we are seeing a limit, it's because we are building on top of a derived table.
+	case *limit, *concatenateGen4, *distinct:
+		// if we are seeing a limit or a union, it's because we are building on top of a derived table.
+		// the aggregation can't be pushed down, so we aggregate the rows of the derived table at the vtgate level.
 		output = plan
 		pushed = false
 
+		_, isLimit := plan.(*limit)
 		for _, grp := range grouping {
-			offset, wOffset, err := wrapAndPushExpr(ctx, grp.Inner, grp.WeightStrExpr, plan.input)
+			wsExpr := grp.WeightStrExpr
+			if !isLimit {
+				// the columns of a union can't be extended with weight strings,
+				// so the grouping compares the values using their collation
+				wsExpr = nil
+			}
+			offset, wOffset, err := wrapAndPushExpr(ctx, grp.Inner, wsExpr, plan)
 			if err != nil {
 				return nil, nil, nil, false, err
 			}
@@ -93,7 +101,7 @@ func (hp *horizonPlanning) pushAggregation(
 				if len(aggrExpr.GetArgs()) != 1 {
 					return nil, nil, nil, false, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG]: unexpected expression: %v", aggrExpr)
 				}
-				offset, _, err = pushProjection(ctx, &sqlparser.AliasedExpr{Expr: aggrExpr.GetArg() /*As: expr.As*/}, plan.input, true, true, false)
+				offset, _, err = pushProjection(ctx, &sqlparser.AliasedExpr{Expr: aggrExpr.GetArg() /*As: expr.As*/}, plan, true, true, false)
 			}
 
 			if err != nil {
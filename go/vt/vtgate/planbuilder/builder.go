@@ -17,6 +17,7 @@ limitations under the License.
 package planbuilder
 
 import (
+	"fmt"
 	"sort"
 
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
@@ -27,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -75,6 +77,10 @@ func TestBuilder(query string, vschema plancontext.VSchema, keyspace string) (*e
 
 // BuildFromStmt builds a plan based on the AST provided.
 func BuildFromStmt(query string, stmt sqlparser.Statement, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, bindVarNeeds *sqlparser.BindVarNeeds, enableOnlineDDL, enableDirectDDL bool) (*engine.Plan, error) {
+	hints, err := sqlparser.GetQueryHints(stmt)
+	if err != nil {
+		return nil, err
+	}
 	instruction, err := createInstructionFor(query, stmt, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
 	if err != nil {
 		return nil, err
@@ -84,12 +90,20 @@ func BuildFromStmt(query string, stmt sqlparser.Statement, reservedVars *sqlpars
 		Original:     query,
 		Instructions: instruction,
 		BindVarNeeds: bindVarNeeds,
+		QueryHints:   hints,
 	}
 	return plan, nil
 }
 
 func getConfiguredPlanner(vschema plancontext.VSchema, v3planner func(string) stmtPlanner, stmt sqlparser.Statement, query string) (stmtPlanner, error) {
-	planner, ok := getPlannerFromQuery(stmt)
+	planner, ok := getPlannerFromQueryHint(stmt)
+	if pin := vschema.PlanPin(); !ok && pin != nil && pin.Planner != querypb.ExecuteOptions_DEFAULT_PLANNER {
+		// the plan pin of the query overrides the configuration, but not the query
+		planner, ok = pin.Planner, true
+	}
+	if !ok {
+		planner, ok = getPlannerFromQuery(stmt)
+	}
 	if !ok {
 		// if the query doesn't specify the planner, we check what the configuration is
 		planner = vschema.Planner()
@@ -170,15 +184,15 @@ func createInstructionFor(query string, stmt sqlparser.Statement, reservedVars *
 		}
 		return buildRoutePlan(stmt, reservedVars, vschema, configuredPlanner)
 	case *sqlparser.Insert:
-		return buildRoutePlan(stmt, reservedVars, vschema, buildInsertPlan)
+		return buildDMLRoutePlan(stmt, stmt.Returning, reservedVars, vschema, buildInsertPlan)
 	case *sqlparser.Update:
 		configuredPlanner, err := getConfiguredPlanner(vschema, buildUpdatePlan, stmt, query)
 		if err != nil {
 			return nil, err
 		}
-		return buildRoutePlan(stmt, reservedVars, vschema, configuredPlanner)
+		return buildDMLRoutePlan(stmt, stmt.Returning, reservedVars, vschema, configuredPlanner)
 	case *sqlparser.Delete:
-		return buildRoutePlan(stmt, reservedVars, vschema, buildDeletePlan)
+		return buildDMLRoutePlan(stmt, stmt.Returning, reservedVars, vschema, buildDeletePlan)
 	case *sqlparser.Union:
 		configuredPlanner, err := getConfiguredPlanner(vschema, buildUnionPlan, stmt, query)
 		if err != nil {
@@ -196,6 +210,9 @@ func createInstructionFor(query string, stmt sqlparser.Statement, reservedVars *
 	case *sqlparser.ShowThrottledApps:
 		return buildShowThrottledAppsPlan(query, vschema)
 	case *sqlparser.AlterVschema:
+		if stmt.Action == sqlparser.CreateSequenceDDLAction {
+			return buildCreateSequencePlan(stmt, vschema, enableDirectDDL)
+		}
 		return buildVSchemaDDLPlan(stmt, vschema)
 	case *sqlparser.Use:
 		return buildUsePlan(stmt, vschema)
@@ -312,7 +329,73 @@ func buildVSchemaDDLPlan(stmt *sqlparser.AlterVschema, vschema plancontext.VSche
 	}, nil
 }
 
+const (
+	defaultSequenceStart = 1
+	defaultSequenceCache = 1000
+)
+
+// buildCreateSequencePlan builds the plan for CREATE SEQUENCE, which creates
+// and seeds the sequence table and adds it to the vschema in one statement.
+func buildCreateSequencePlan(stmt *sqlparser.AlterVschema, vschema plancontext.VSchema, enableDirectDDL bool) (engine.Primitive, error) {
+	if !enableDirectDDL {
+		return nil, schema.ErrDirectDDLDisabled
+	}
+	dest, keyspace, _, err := vschema.TargetDestination(stmt.Table.Qualifier.String())
+	if err != nil {
+		return nil, err
+	}
+	if keyspace.Sharded && dest != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: create sequence on a shard of sharded keyspace %s", keyspace.Name)
+	}
+	if dest == nil {
+		dest = key.DestinationAnyShard{}
+		if keyspace.Sharded {
+			dest = key.DestinationAllShards{}
+		}
+	}
+
+	start, cache := stmt.SequenceStart, stmt.SequenceCache
+	if start == 0 {
+		start = defaultSequenceStart
+	}
+	if cache == 0 {
+		cache = defaultSequenceCache
+	}
+
+	table := sqlparser.String(stmt.Table.Name)
+	notExists, ignore := "", ""
+	if stmt.IfNotExists {
+		notExists, ignore = "if not exists ", "ignore "
+	}
+	if keyspace.Sharded {
+		// Every shard allocates its own blocks of values, interleaved
+		// with the ones of the other shards. The first block and the
+		// stride of each shard are bound when the table is seeded.
+		return &engine.CreateSequence{
+			Keyspace:          keyspace,
+			TargetDestination: dest,
+			CreateTable:       fmt.Sprintf("create table %s%s (\n\tid int,\n\tnext_id bigint,\n\tcache bigint,\n\tstride bigint,\n\tprimary key (id)\n) comment 'vitess_sequence'", notExists, table),
+			Seed:              fmt.Sprintf("insert %sinto %s(id, next_id, cache, stride) values (0, :next_id, %d, :stride)", ignore, table, cache),
+			DropTable:         fmt.Sprintf("drop table %s", table),
+			AlterVschemaDDL:   stmt,
+			Start:             int64(start),
+			Cache:             int64(cache),
+		}, nil
+	}
+	return &engine.CreateSequence{
+		Keyspace:          keyspace,
+		TargetDestination: dest,
+		CreateTable:       fmt.Sprintf("create table %s%s (\n\tid int,\n\tnext_id bigint,\n\tcache bigint,\n\tprimary key (id)\n) comment 'vitess_sequence'", notExists, table),
+		Seed:              fmt.Sprintf("insert %sinto %s(id, next_id, cache) values (0, %d, %d)", ignore, table, start, cache),
+		DropTable:         fmt.Sprintf("drop table %s", table),
+		AlterVschemaDDL:   stmt,
+	}, nil
+}
+
 func buildFlushPlan(stmt *sqlparser.Flush, vschema plancontext.VSchema) (engine.Primitive, error) {
+	if stmt.WithLock || stmt.ForExport {
+		return buildFlushWithLockPlan(stmt, vschema)
+	}
 	if len(stmt.TableNames) == 0 {
 		return buildFlushOptions(stmt, vschema)
 	}
@@ -42,7 +42,7 @@ func buildPlanForBypass(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, vsc
 		TargetDestination:    vschema.Destination(),
 		Query:                sqlparser.String(stmt),
 		IsDML:                sqlparser.IsDMLStatement(stmt),
-		SingleShardOnly:      false,
+		SingleShardOnly:      hasReturning(stmt),
 		MultishardAutocommit: sqlparser.MultiShardAutocommitDirective(stmt),
 	}, nil
 }
@@ -77,7 +77,7 @@ func (c *concatenateGen4) SupplyCol(col *sqlparser.ColName) (rc *resultColumn, c
 
 // SupplyWeightString implements the logicalPlan interface
 func (c *concatenateGen4) SupplyWeightString(colNumber int, alsoAddToGroupBy bool) (weightcolNumber int, err error) {
-	panic("implement me")
+	return 0, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: weight_string on the results of a union")
 }
 
 // Primitive implements the logicalPlan interface
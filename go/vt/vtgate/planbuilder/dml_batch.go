@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"strconv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// isDMLBatch returns true if the statement has the DML_BATCH_SIZE directive.
+func isDMLBatch(stmt sqlparser.Statement) bool {
+	commented, ok := stmt.(sqlparser.Commented)
+	if !ok {
+		return false
+	}
+	_, ok = commented.GetParsedComments().Directives()[sqlparser.DirectiveDMLBatchSize]
+	return ok
+}
+
+// buildDMLBatchPlan builds the plan of an UPDATE or a DELETE with the
+// DML_BATCH_SIZE directive from its plan, so that it is executed in batches of
+// DML_BATCH_SIZE rows. The batches are ranges of the values of the column of
+// the DML_BATCH_COLUMN directive, by default the first column of the primary
+// vindex of the table. The column should be the primary key, or the first
+// column of an index of the table, as every batch reads a range of it, and it
+// must not be NULL in the rows the statement changes.
+func buildDMLBatchPlan(stmt sqlparser.Statement, plan engine.Primitive) (engine.Primitive, error) {
+	directives := stmt.(sqlparser.Commented).GetParsedComments().Directives()
+	batchSize, err := strconv.Atoi(directives.GetString(sqlparser.DirectiveDMLBatchSize, ""))
+	if err != nil || batchSize <= 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s: %s, expected a positive number of rows", sqlparser.DirectiveDMLBatchSize, directives.GetString(sqlparser.DirectiveDMLBatchSize, ""))
+	}
+
+	var dml *engine.DML
+	switch plan := plan.(type) {
+	case *engine.Update:
+		if len(plan.ChangedVindexValues) > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on an update of vindex columns", sqlparser.DirectiveDMLBatchSize)
+		}
+		dml = plan.DML
+	case *engine.Delete:
+		if plan.OwnedVindexQuery != "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a delete from a table that owns vindexes", sqlparser.DirectiveDMLBatchSize)
+		}
+		dml = plan.DML
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a statement that is not an update or a delete routed by vtgate", sqlparser.DirectiveDMLBatchSize)
+	}
+
+	column := directives.GetString(sqlparser.DirectiveDMLBatchColumn, "")
+	if column == "" {
+		column = primaryKeyColumn(dml.Table)
+		if column == "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s requires %s on a table without a single-column primary key known to the schema tracker", sqlparser.DirectiveDMLBatchSize, sqlparser.DirectiveDMLBatchColumn)
+		}
+	}
+	col := sqlparser.NewColName(column)
+
+	// The queries are built from the query of the plan, whose routed tables
+	// were already substituted.
+	batchStmt, err := sqlparser.Parse(dml.Query)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tableExprs sqlparser.TableExprs
+		where      *sqlparser.Where
+	)
+	switch batchStmt := batchStmt.(type) {
+	case *sqlparser.Update:
+		if batchStmt.OrderBy != nil || batchStmt.Limit != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on an update with ORDER BY or LIMIT", sqlparser.DirectiveDMLBatchSize)
+		}
+		for _, expr := range batchStmt.Exprs {
+			if expr.Name.Name.EqualString(column) {
+				return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on an update of its batch column %s", sqlparser.DirectiveDMLBatchSize, column)
+			}
+		}
+		tableExprs, where = batchStmt.TableExprs, batchStmt.Where
+	case *sqlparser.Delete:
+		if batchStmt.OrderBy != nil || batchStmt.Limit != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a delete with ORDER BY or LIMIT", sqlparser.DirectiveDMLBatchSize)
+		}
+		if len(batchStmt.Targets) > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a multi-table delete", sqlparser.DirectiveDMLBatchSize)
+		}
+		tableExprs, where = batchStmt.TableExprs, batchStmt.Where
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "BUG: unexpected statement type: %T", batchStmt)
+	}
+	if len(tableExprs) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a multi-table statement", sqlparser.DirectiveDMLBatchSize)
+	}
+	if _, ok := tableExprs[0].(*sqlparser.AliasedTableExpr); !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s on a join", sqlparser.DirectiveDMLBatchSize)
+	}
+
+	andWhere := func(predicates ...sqlparser.Expr) *sqlparser.Where {
+		var exprs []sqlparser.Expr
+		if where != nil {
+			exprs = append(exprs, sqlparser.CloneExpr(where.Expr))
+		}
+		return sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(append(exprs, predicates...)...))
+	}
+	boundsQuery := func(where *sqlparser.Where) string {
+		return sqlparser.String(&sqlparser.Select{
+			SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: col}},
+			From:        sqlparser.CloneTableExprs(tableExprs),
+			Where:       where,
+			OrderBy:     sqlparser.OrderBy{&sqlparser.Order{Expr: col, Direction: sqlparser.AscOrder}},
+			Limit:       &sqlparser.Limit{Rowcount: sqlparser.NewIntLiteral(strconv.Itoa(batchSize))},
+		})
+	}
+
+	firstBoundsQuery := boundsQuery(andWhere())
+	nextBoundsQuery := boundsQuery(andWhere(&sqlparser.ComparisonExpr{
+		Operator: sqlparser.GreaterThanOp,
+		Left:     col,
+		Right:    sqlparser.NewArgument(engine.DMLBatchAfterName),
+	}))
+
+	inBatch := andWhere(&sqlparser.BetweenExpr{
+		IsBetween: true,
+		Left:      col,
+		From:      sqlparser.NewArgument(engine.DMLBatchLowerName),
+		To:        sqlparser.NewArgument(engine.DMLBatchUpperName),
+	})
+	switch batchStmt := batchStmt.(type) {
+	case *sqlparser.Update:
+		batchStmt.Where = inBatch
+	case *sqlparser.Delete:
+		batchStmt.Where = inBatch
+	}
+
+	return &engine.DMLBatch{
+		DML:              dml,
+		Column:           column,
+		BatchSize:        batchSize,
+		FirstBoundsQuery: firstBoundsQuery,
+		BoundsQuery:      nextBoundsQuery,
+		BatchQuery:       sqlparser.String(batchStmt),
+	}, nil
+}
+
+// primaryKeyColumn returns the column of the primary key of the table, or ""
+// if the schema tracker does not know the primary key or it has more columns,
+// as their order in the key is not tracked.
+func primaryKeyColumn(table *vindexes.Table) string {
+	if table == nil {
+		return ""
+	}
+	column := ""
+	for _, col := range table.Columns {
+		if !col.PrimaryKey {
+			continue
+		}
+		if column != "" {
+			return ""
+		}
+		column = col.Name.String()
+	}
+	return column
+}
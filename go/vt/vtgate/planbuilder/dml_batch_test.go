@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestDMLBatchPrimaryKeyColumn(t *testing.T) {
+	vschemaWrapper := &vschemaWrapper{
+		v:             loadSchema(t, "schema_test.json", false),
+		sysVarEnabled: true,
+		version:       Gen4,
+	}
+	tbl := vschemaWrapper.v.Keyspaces["user"].Tables["user_extra"]
+	tbl.Columns = []vindexes.Column{
+		{Name: sqlparser.NewIdentifierCI("id"), PrimaryKey: true},
+		{Name: sqlparser.NewIdentifierCI("val")},
+	}
+
+	query := "delete /*vt+ DML_BATCH_SIZE=500 */ from user_extra"
+	plan, err := TestBuilder(query, vschemaWrapper, vschemaWrapper.currentDb())
+	require.NoError(t, err)
+	batch, ok := plan.Instructions.(*engine.DMLBatch)
+	require.True(t, ok, "should be a DMLBatch")
+	assert.Equal(t, "id", batch.Column)
+	assert.Equal(t, "select id from user_extra order by id asc limit 500", batch.FirstBoundsQuery)
+
+	// The order of the columns of a composite primary key is not tracked.
+	tbl.Columns[1].PrimaryKey = true
+	_, err = TestBuilder(query, vschemaWrapper, vschemaWrapper.currentDb())
+	require.EqualError(t, err, "DML_BATCH_SIZE requires DML_BATCH_COLUMN on a table without a single-column primary key known to the schema tracker")
+}
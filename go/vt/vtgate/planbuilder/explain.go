@@ -36,8 +36,11 @@ func buildExplainPlan(stmt sqlparser.Explain, reservedVars *sqlparser.ReservedVa
 	case *sqlparser.ExplainTab:
 		return explainTabPlan(explain, vschema)
 	case *sqlparser.ExplainStmt:
-		if explain.Type == sqlparser.VitessType {
+		switch explain.Type {
+		case sqlparser.VitessType:
 			return buildVitessTypePlan(explain, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
+		case sqlparser.VExplainAnalyzeType:
+			return buildVExplainAnalyzePlan(explain, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
 		}
 		return buildOtherReadAndAdmin(sqlparser.String(explain), vschema)
 	}
@@ -111,6 +114,16 @@ func buildVitessTypePlan(explain *sqlparser.ExplainStmt, reservedVars *sqlparser
 	return engine.NewRowsPrimitive(rows, fields), nil
 }
 
+// buildVExplainAnalyzePlan builds a plan that executes the statement and
+// returns its own plan annotated with execution statistics.
+func buildVExplainAnalyzePlan(explain *sqlparser.ExplainStmt, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, enableOnlineDDL, enableDirectDDL bool) (engine.Primitive, error) {
+	innerInstruction, err := createInstructionFor(sqlparser.String(explain.Statement), explain.Statement, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.VExplainAnalyze{Input: innerInstruction}, nil
+}
+
 func extractQuery(m map[string]any) string {
 	queryObj, ok := m["Query"]
 	if !ok {
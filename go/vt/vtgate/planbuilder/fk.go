@@ -0,0 +1,415 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// fkPlan holds the primitives that enforce the foreign keys of a DML on a
+// table of a keyspace whose foreign keys are managed by vtgate.
+type fkPlan struct {
+	// selection selects the referenced columns of the rows changed by the
+	// DML, if they are referenced by child foreign keys.
+	selection  engine.Primitive
+	selectCols []sqlparser.IdentifierCI
+	children   []*engine.FkChild
+	// sharded is set if the table is in a sharded keyspace, and
+	// requiresTwoPC if a child action then changes rows.
+	sharded       bool
+	requiresTwoPC bool
+
+	// verify verifies the values written to the columns of the foreign
+	// keys of the table.
+	verify []*engine.FkParent
+}
+
+// buildFkPlan builds the primitives that enforce the foreign keys of a DML.
+// It returns nil if the DML does not change a table whose foreign keys are
+// managed by vtgate. The cascaded foreign key, if any, is the one whose
+// action the DML executes, and it is not verified again.
+func buildFkPlan(stmt sqlparser.Statement, cascaded *vindexes.ForeignKey, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) (*fkPlan, error) {
+	var table *vindexes.Table
+	var err error
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert:
+		table = fkTable(stmt.Table, vschema)
+	case *sqlparser.Update:
+		table, err = fkDMLTable(stmt.TableExprs, nil, vschema)
+	case *sqlparser.Delete:
+		table, err = fkDMLTable(stmt.TableExprs, stmt.Targets, vschema)
+	}
+	if err != nil || table == nil {
+		return nil, err
+	}
+	if err := checkFkColumns(table); err != nil {
+		return nil, err
+	}
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert:
+		return buildInsertFkPlan(stmt, table, reservedVars, vschema)
+	case *sqlparser.Update:
+		return buildUpdateFkPlan(stmt, table, cascaded, reservedVars, vschema)
+	case *sqlparser.Delete:
+		return buildDeleteFkPlan(stmt, table, reservedVars, vschema)
+	}
+	return nil, nil
+}
+
+// wrap wraps the plan of the DML in the primitives that enforce the foreign
+// keys: the actions on the child rows run first, then the verification of
+// the parent rows, and the DML last.
+func (fp *fkPlan) wrap(plan engine.Primitive) engine.Primitive {
+	if fp == nil {
+		return plan
+	}
+	if len(fp.verify) > 0 {
+		plan = &engine.FkVerify{Verify: fp.verify, Exec: plan}
+	}
+	if fp.selection != nil {
+		plan = &engine.FkCascade{Selection: fp.selection, Children: fp.children, Parent: plan, RequiresTwoPC: fp.requiresTwoPC}
+	}
+	return plan
+}
+
+// fkTable returns the table if its foreign keys are managed by vtgate.
+func fkTable(name sqlparser.TableName, vschema plancontext.VSchema) *vindexes.Table {
+	table, _, _, _, err := vschema.FindTable(name)
+	if err != nil || table == nil || table.Keyspace == nil || !table.Keyspace.ManagesForeignKeys() {
+		return nil
+	}
+	if len(table.ForeignKeys) == 0 && len(table.ChildForeignKeys) == 0 {
+		return nil
+	}
+	return table
+}
+
+// checkFkColumns returns an error if a foreign key of the table, or
+// referencing it, has more than one column: vtgate only enforces foreign
+// keys of a single column.
+func checkFkColumns(table *vindexes.Table) error {
+	for _, fks := range [][]*vindexes.ForeignKey{table.ForeignKeys, table.ChildForeignKeys} {
+		for _, fk := range fks {
+			if len(fk.Columns) != 1 || len(fk.ParentColumns) != 1 {
+				return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: foreign key %s of table %s with more than one column", fk.Name, fk.Table.String())
+			}
+		}
+	}
+	return nil
+}
+
+// fkDMLTable returns the table changed by an UPDATE or a DELETE if its
+// foreign keys are managed by vtgate. Such a table can only be changed by a
+// single-table DML.
+func fkDMLTable(exprs sqlparser.TableExprs, targets sqlparser.TableNames, vschema plancontext.VSchema) (*vindexes.Table, error) {
+	var tables []*vindexes.Table
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		aliased, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return true, nil
+		}
+		if name, ok := aliased.Expr.(sqlparser.TableName); ok {
+			if table := fkTable(name, vschema); table != nil {
+				tables = append(tables, table)
+			}
+		}
+		return false, nil
+	}, exprs)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	if _, ok := exprs[0].(*sqlparser.AliasedTableExpr); !ok || len(exprs) != 1 || len(targets) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: multi-table DML on table %s with foreign keys managed by vtgate", tables[0].Name.String())
+	}
+	return tables[0], nil
+}
+
+func buildInsertFkPlan(ins *sqlparser.Insert, table *vindexes.Table, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) (*fkPlan, error) {
+	if ins.Action == sqlparser.ReplaceAct && len(table.ChildForeignKeys) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: REPLACE on table %s referenced by foreign keys managed by vtgate", table.Name.String())
+	}
+	for _, expr := range ins.OnDup {
+		if fk := fkOfColumn(table, expr.Name.Name); fk != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: ON DUPLICATE KEY UPDATE of column %s of foreign key %s managed by vtgate", expr.Name.Name.String(), fk.Name)
+		}
+	}
+	if len(table.ForeignKeys) == 0 {
+		return nil, nil
+	}
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: INSERT ... SELECT on table %s with foreign keys managed by vtgate", table.Name.String())
+	}
+	if len(ins.Columns) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: INSERT without a column list on table %s with foreign keys managed by vtgate", table.Name.String())
+	}
+
+	fp := &fkPlan{sharded: table.Keyspace.Sharded}
+	for _, fk := range table.ForeignKeys {
+		offset := ins.Columns.FindColumn(fk.Columns[0])
+		if offset < 0 {
+			continue
+		}
+		var values []sqlparser.Expr
+		for _, row := range rows {
+			if offset >= len(row) {
+				return nil, vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueCountOnRow, "column count doesn't match value count at row 1")
+			}
+			values = append(values, row[offset])
+		}
+		if err := fp.addParent(fk, table.Keyspace.Name, values, reservedVars, vschema); err != nil {
+			return nil, err
+		}
+	}
+	return fp, nil
+}
+
+func buildUpdateFkPlan(upd *sqlparser.Update, table *vindexes.Table, cascaded *vindexes.ForeignKey, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) (*fkPlan, error) {
+	fp := &fkPlan{sharded: table.Keyspace.Sharded}
+	for _, fk := range table.ChildForeignKeys {
+		value := updatedValue(upd.Exprs, fk.ParentColumns[0])
+		if value == nil {
+			continue
+		}
+		if fk.OnUpdate == vschemapb.ForeignKey_CASCADE && !sqlparser.IsValue(value) && !sqlparser.IsNull(value) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: UPDATE CASCADE of foreign key %s to a value that is not a literal", fk.Name)
+		}
+		if err := fp.addChild(fk, table.Keyspace.Name, fk.OnUpdate, sqlparser.CloneExpr(value), reservedVars, vschema); err != nil {
+			return nil, err
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		if fk == cascaded {
+			continue
+		}
+		if value := updatedValue(upd.Exprs, fk.Columns[0]); value != nil {
+			if err := fp.addParent(fk, table.Keyspace.Name, []sqlparser.Expr{value}, reservedVars, vschema); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := fp.planSelection(upd.TableExprs, upd.Where, upd.OrderBy, upd.Limit, reservedVars, vschema); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
+
+func buildDeleteFkPlan(del *sqlparser.Delete, table *vindexes.Table, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) (*fkPlan, error) {
+	fp := &fkPlan{sharded: table.Keyspace.Sharded}
+	for _, fk := range table.ChildForeignKeys {
+		if err := fp.addChild(fk, table.Keyspace.Name, fk.OnDelete, nil, reservedVars, vschema); err != nil {
+			return nil, err
+		}
+	}
+	if err := fp.planSelection(del.TableExprs, del.Where, del.OrderBy, del.Limit, reservedVars, vschema); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
+
+// addChild adds the action of a child foreign key. The action is executed
+// on the child rows that reference the rows changed by the DML. newValue is
+// the value the referenced column is updated to, or nil for a DELETE.
+func (fp *fkPlan) addChild(fk *vindexes.ForeignKey, ks string, action vschemapb.ForeignKey_Action, newValue sqlparser.Expr, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) error {
+	bvName := reservedVars.ReserveColName(sqlparser.NewColName("fkc_" + fk.Columns[0].String()))
+	from := fkTableExprs(ks, fk.Table)
+	where := fkWhere(fk.Columns[0], bvName)
+	col := sqlparser.NewColName(fk.Columns[0].String())
+
+	var stmt sqlparser.Statement
+	restrict := false
+	switch {
+	case action == vschemapb.ForeignKey_CASCADE && newValue == nil:
+		stmt = &sqlparser.Delete{TableExprs: from, Where: where}
+	case action == vschemapb.ForeignKey_CASCADE:
+		stmt = &sqlparser.Update{TableExprs: from, Exprs: sqlparser.UpdateExprs{{Name: col, Expr: newValue}}, Where: where}
+	case action == vschemapb.ForeignKey_SET_NULL:
+		stmt = &sqlparser.Update{TableExprs: from, Exprs: sqlparser.UpdateExprs{{Name: col, Expr: &sqlparser.NullVal{}}}, Where: where}
+	default:
+		restrict = true
+		stmt = &sqlparser.Select{
+			SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: sqlparser.NewIntLiteral("1")}},
+			From:        from,
+			Where:       where,
+			Limit:       &sqlparser.Limit{Rowcount: sqlparser.NewIntLiteral("1")},
+		}
+	}
+	exec, err := planFkStatement(stmt, fk, reservedVars, vschema)
+	if err != nil {
+		return err
+	}
+	if !restrict && fp.sharded {
+		fp.requiresTwoPC = true
+	}
+	fp.children = append(fp.children, &engine.FkChild{
+		Name:     fk.Name,
+		BvName:   bvName,
+		Col:      fp.selectColumn(fk.ParentColumns[0]),
+		Restrict: restrict,
+		Exec:     exec,
+	})
+	return nil
+}
+
+// addParent adds the verification that the values written to the column of
+// a foreign key reference rows of the parent table.
+func (fp *fkPlan) addParent(fk *vindexes.ForeignKey, ks string, values []sqlparser.Expr, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) error {
+	var exprs []evalengine.Expr
+	for _, value := range values {
+		if sqlparser.IsNull(value) {
+			continue
+		}
+		expr, err := evalengine.Translate(value, nil)
+		if err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: value %s of column %s of foreign key %s", sqlparser.String(value), fk.Columns[0].String(), fk.Name)
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	bvName := reservedVars.ReserveColName(sqlparser.NewColName("fkp_" + fk.ParentColumns[0].String()))
+	sel := &sqlparser.Select{
+		SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: sqlparser.NewColName(fk.ParentColumns[0].String())}},
+		From:        fkTableExprs(ks, fk.ParentTable),
+		Where:       fkWhere(fk.ParentColumns[0], bvName),
+		Lock:        sqlparser.ShareModeLock,
+	}
+	exec, err := planFkStatement(sel, nil, reservedVars, vschema)
+	if err != nil {
+		return err
+	}
+	fp.verify = append(fp.verify, &engine.FkParent{
+		Name:   fk.Name,
+		BvName: bvName,
+		Values: exprs,
+		Exec:   exec,
+	})
+	return nil
+}
+
+// selectColumn returns the offset of the referenced column in the rows of
+// the selection.
+func (fp *fkPlan) selectColumn(col sqlparser.IdentifierCI) int {
+	for i, selected := range fp.selectCols {
+		if selected.Equal(col) {
+			return i
+		}
+	}
+	fp.selectCols = append(fp.selectCols, col)
+	return len(fp.selectCols) - 1
+}
+
+// planSelection plans the query that selects, and locks, the referenced
+// columns of the rows changed by the DML.
+func (fp *fkPlan) planSelection(from sqlparser.TableExprs, where *sqlparser.Where, orderBy sqlparser.OrderBy, limit *sqlparser.Limit, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) error {
+	if len(fp.children) == 0 {
+		return nil
+	}
+	sel := &sqlparser.Select{
+		From:    sqlparser.CloneTableExprs(from),
+		Where:   sqlparser.CloneRefOfWhere(where),
+		OrderBy: sqlparser.CloneOrderBy(orderBy),
+		Limit:   sqlparser.CloneRefOfLimit(limit),
+		Lock:    sqlparser.ForUpdateLock,
+	}
+	for _, col := range fp.selectCols {
+		sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(col.String())})
+	}
+	selection, err := planFkStatement(sel, nil, reservedVars, vschema)
+	if err != nil {
+		return err
+	}
+	fp.selection = selection
+	return nil
+}
+
+// planFkStatement plans a query issued to enforce a foreign key. The foreign
+// keys of the tables changed by a child DML are enforced in turn, except for
+// the cascaded one.
+func planFkStatement(stmt sqlparser.Statement, cascaded *vindexes.ForeignKey, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema) (engine.Primitive, error) {
+	query := sqlparser.String(stmt)
+	if _, ok := stmt.(*sqlparser.Select); ok {
+		return createInstructionFor(query, stmt, reservedVars, vschema, false, false)
+	}
+	fp, err := buildFkPlan(stmt, cascaded, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+	var f stmtPlanner = buildDeletePlan
+	if _, ok := stmt.(*sqlparser.Update); ok {
+		f, err = getConfiguredPlanner(vschema, buildUpdatePlan, stmt, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan, err := f(stmt, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+	return fp.wrap(plan), nil
+}
+
+// fkTableExprs returns the table expressions of a table of the keyspace.
+// The tables of a foreign key are in the same keyspace.
+func fkTableExprs(ks string, table sqlparser.IdentifierCS) sqlparser.TableExprs {
+	name := sqlparser.TableName{Name: table, Qualifier: sqlparser.NewIdentifierCS(ks)}
+	return sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: name}}
+}
+
+func fkWhere(col sqlparser.IdentifierCI, bvName string) *sqlparser.Where {
+	return sqlparser.NewWhere(sqlparser.WhereClause, &sqlparser.ComparisonExpr{
+		Operator: sqlparser.InOp,
+		Left:     sqlparser.NewColName(col.String()),
+		Right:    sqlparser.NewListArg(bvName),
+	})
+}
+
+// updatedValue returns the value a column is updated to, or nil if the
+// column is not updated.
+func updatedValue(exprs sqlparser.UpdateExprs, col sqlparser.IdentifierCI) sqlparser.Expr {
+	for _, expr := range exprs {
+		if expr.Name.Name.Equal(col) {
+			return expr.Expr
+		}
+	}
+	return nil
+}
+
+// fkOfColumn returns the foreign key of the table that the column is part
+// of, either as a child or as a referenced column.
+func fkOfColumn(table *vindexes.Table, col sqlparser.IdentifierCI) *vindexes.ForeignKey {
+	for _, fk := range table.ForeignKeys {
+		if fk.Columns[0].Equal(col) {
+			return fk
+		}
+	}
+	for _, fk := range table.ChildForeignKeys {
+		if fk.ParentColumns[0].Equal(col) {
+			return fk
+		}
+	}
+	return nil
+}
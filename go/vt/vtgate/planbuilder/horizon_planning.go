@@ -639,6 +639,12 @@ func (hp *horizonPlanning) planOrderBy(ctx *plancontext.PlanningContext, orderEx
 	case *vindexFunc:
 		// This is evaluated at VTGate only, so weight_string function cannot be used.
 		return hp.createMemorySortPlan(ctx, plan, orderExprs /* useWeightStr */, false)
+	case *concatenateGen4, *distinct:
+		if len(orderExprs) == 0 {
+			return plan, nil
+		}
+		// The columns of a union can't be extended with weight strings, so we sort using the collations.
+		return hp.createMemorySortPlan(ctx, plan, orderExprs /* useWeightStr */, false)
 	case *limit, *semiJoin, *filter, *pulloutSubquery, *projection:
 		inputs := plan.Inputs()
 		if len(inputs) == 0 {
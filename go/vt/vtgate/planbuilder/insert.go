@@ -352,6 +352,9 @@ func generateInsertShardedQuery(node *sqlparser.Insert, eins *engine.Insert, val
 		midBuf.Reset()
 	}
 	suffixBuf.Myprintf("%v", node.OnDup)
+	if node.Returning != nil {
+		suffixBuf.Myprintf(" returning %v", node.Returning)
+	}
 	eins.Suffix = suffixBuf.String()
 }
 
@@ -363,6 +366,9 @@ func generateInsertSelectQuery(node *sqlparser.Insert, eins *engine.Insert) {
 		node.Table, node.Columns)
 	eins.Prefix = prefixBuf.String()
 	suffixBuf.Myprintf("%v", node.OnDup)
+	if node.Returning != nil {
+		suffixBuf.Myprintf(" returning %v", node.Returning)
+	}
 	eins.Suffix = suffixBuf.String()
 }
 
@@ -18,21 +18,125 @@ package planbuilder
 
 import (
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
 // buildLockPlan plans lock tables statement.
-func buildLockPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, _ plancontext.VSchema) (engine.Primitive, error) {
-	log.Warningf("Lock Tables statement is ignored: %v", stmt)
-	return engine.NewRowsPrimitive(make([][]sqltypes.Value, 0), make([]*querypb.Field, 0)), nil
+// Table locks are only supported on unsharded keyspaces, where they are taken on the
+// reserved connection of the session. On sharded keyspaces, or when the keyspace of
+// a table cannot be found, the statement is ignored.
+func buildLockPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, vschema plancontext.VSchema) (engine.Primitive, error) {
+	lock := stmt.(*sqlparser.LockTables)
+
+	var keyspace *vindexes.Keyspace
+	for _, tbl := range lock.Tables {
+		aliased, ok := tbl.Table.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported table expression in lock tables: %s", sqlparser.String(tbl.Table))
+		}
+		tableName, ok := aliased.Expr.(sqlparser.TableName)
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported table expression in lock tables: %s", sqlparser.String(tbl.Table))
+		}
+		ks, err := findLockTableKeyspace(tableName, vschema)
+		if err != nil {
+			// Without a keyspace to send the locks to, the statement is ignored as it
+			// always was.
+			log.Warningf("Lock Tables statement is ignored: %v: %v", stmt, err)
+			vschema.PlannerWarning("lock tables is ignored: " + err.Error())
+			return engine.NewRowsPrimitive(make([][]sqltypes.Value, 0), make([]*querypb.Field, 0)), nil
+		}
+		if keyspace != nil && keyspace.Name != ks.Name {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: lock tables across keyspaces")
+		}
+		keyspace = ks
+		aliased.Expr = sqlparser.TableName{Name: tableName.Name}
+	}
+
+	if keyspace.Sharded {
+		log.Warningf("Lock Tables statement is ignored: %v", stmt)
+		vschema.PlannerWarning("lock tables is ignored on sharded keyspace " + keyspace.Name)
+		return engine.NewRowsPrimitive(make([][]sqltypes.Value, 0), make([]*querypb.Field, 0)), nil
+	}
+
+	return &engine.LockTables{
+		Keyspace:          keyspace,
+		TargetDestination: key.DestinationAnyShard{},
+		Query:             sqlparser.String(lock),
+		QueryTimeout:      int(vschema.LockTablesTimeout().Milliseconds()),
+	}, nil
 }
 
-// buildUnlockPlan plans lock tables statement.
+// buildUnlockPlan plans unlock tables statement.
+// The tables locked by the session can belong to any keyspace, so they are
+// unlocked on all the connections the session holds.
 func buildUnlockPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, _ plancontext.VSchema) (engine.Primitive, error) {
-	log.Warningf("Unlock Tables statement is ignored: %v", stmt)
-	return engine.NewRowsPrimitive(make([][]sqltypes.Value, 0), make([]*querypb.Field, 0)), nil
+	return &engine.LockTables{
+		Query:  sqlparser.String(stmt),
+		Unlock: true,
+	}, nil
+}
+
+// buildFlushWithLockPlan plans `FLUSH TABLES ... WITH READ LOCK` and `FLUSH TABLES ... FOR EXPORT`.
+// Both take table locks that live as long as the connection, so on a single unsharded keyspace
+// they are planned like lock tables. Otherwise, the statement is sent to the shards as any
+// other flush statement, and the locks are released when it completes.
+func buildFlushWithLockPlan(stmt *sqlparser.Flush, vschema plancontext.VSchema) (engine.Primitive, error) {
+	var keyspace *vindexes.Keyspace
+	for _, tab := range stmt.TableNames {
+		ks, err := findLockTableKeyspace(tab, vschema)
+		if err != nil {
+			return nil, err
+		}
+		if keyspace != nil && keyspace.Name != ks.Name {
+			return buildFlushTables(stmt, vschema)
+		}
+		keyspace = ks
+	}
+	var dest key.Destination
+	if keyspace == nil {
+		var err error
+		dest, keyspace, _, err = vschema.TargetDestination("")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if keyspace.Sharded {
+		if len(stmt.TableNames) == 0 {
+			return buildFlushOptions(stmt, vschema)
+		}
+		return buildFlushTables(stmt, vschema)
+	}
+
+	for i, tab := range stmt.TableNames {
+		stmt.TableNames[i] = sqlparser.TableName{Name: tab.Name}
+	}
+	if dest == nil {
+		dest = key.DestinationAnyShard{}
+	}
+	return &engine.LockTables{
+		Keyspace:          keyspace,
+		TargetDestination: dest,
+		Query:             sqlparser.String(stmt),
+		QueryTimeout:      int(vschema.LockTablesTimeout().Milliseconds()),
+	}, nil
+}
+
+func findLockTableKeyspace(tableName sqlparser.TableName, vschema plancontext.VSchema) (*vindexes.Keyspace, error) {
+	table, _, _, _, _, err := vschema.FindTableOrVindex(tableName)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, vindexes.NotFoundError{TableName: tableName.Name.String()}
+	}
+	return table.Keyspace, nil
 }
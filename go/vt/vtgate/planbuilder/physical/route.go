@@ -113,6 +113,8 @@ func (r *Route) Cost() int {
 		return 10
 	case engine.MultiEqual:
 		return 10
+	case engine.Range:
+		return 15
 	case engine.Scatter:
 		return 20
 	}
@@ -146,7 +148,7 @@ func (r *Route) tryImprovingVindex(ctx *plancontext.PlanningContext, expr sqlpar
 
 		// if we didn't open up any new vindex Options, no need to enter here
 		if newVindexFound {
-			r.PickBestAvailableVindex()
+			r.PickBestAvailableVindex(ctx)
 		}
 	}
 	return nil
@@ -213,7 +215,9 @@ func (r *Route) planComparison(ctx *plancontext.PlanningContext, cmp *sqlparser.
 	case sqlparser.LikeOp:
 		found := r.planLikeOp(ctx, cmp)
 		return found, false, nil
-
+	case sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp, sqlparser.LessThanOp, sqlparser.LessEqualOp:
+		found := r.planRangeOp(ctx, cmp)
+		return found, false, nil
 	}
 	return false, false, nil
 }
@@ -430,10 +434,23 @@ func (option *VindexOption) updateWithNewColumn(
 }
 
 // PickBestAvailableVindex goes over the available vindexes for this route and picks the best one available.
-func (r *Route) PickBestAvailableVindex() {
+// The vindex of the plan pin of the query, if any, is picked over the others whenever it is available.
+func (r *Route) PickBestAvailableVindex(ctx *plancontext.PlanningContext) {
+	pinnedVindex := ""
+	if pin := ctx.VSchema.PlanPin(); pin != nil && r.Keyspace != nil && pin.Keyspace == r.Keyspace.Name {
+		pinnedVindex = pin.Vindex
+	}
+	isPinned := func(option *VindexOption) bool {
+		return pinnedVindex != "" && option.FoundVindex.String() == pinnedVindex
+	}
 	for _, v := range r.VindexPreds {
 		option := v.bestOption()
-		if option != nil && (r.Selected == nil || less(option.Cost, r.Selected.Cost)) {
+		if option == nil {
+			continue
+		}
+		if r.Selected == nil ||
+			(isPinned(option) && !isPinned(r.Selected)) ||
+			(isPinned(option) == isPinned(r.Selected) && less(option.Cost, r.Selected.Cost)) {
 			r.Selected = option
 			r.RouteOpCode = option.OpCode
 		}
@@ -553,6 +570,92 @@ func (r *Route) planLikeOp(ctx *plancontext.PlanningContext, node *sqlparser.Com
 
 }
 
+// planRangeOp uses a range comparison on the column of a sequential vindex to
+// route the query to the shards holding the range of values only. The
+// comparison can be on tuples, as used for keyset pagination, in which case
+// the range is the one of the leading column: (a, b) > (1, 2) implies a >= 1.
+// The bounds are always treated as inclusive, which may send the query to one
+// more shard than needed but never misses one.
+func (r *Route) planRangeOp(ctx *plancontext.PlanningContext, node *sqlparser.ComparisonExpr) bool {
+	left, right, operator := node.Left, node.Right, node.Operator
+	if leftTuple, ok := left.(sqlparser.ValTuple); ok {
+		rightTuple, ok := right.(sqlparser.ValTuple)
+		if !ok || len(leftTuple) == 0 || len(leftTuple) != len(rightTuple) {
+			return false
+		}
+		left, right = leftTuple[0], rightTuple[0]
+	}
+
+	column, ok := left.(*sqlparser.ColName)
+	vdValue := right
+	if !ok {
+		column, ok = right.(*sqlparser.ColName)
+		if !ok {
+			return false
+		}
+		vdValue = left
+		switch operator {
+		case sqlparser.GreaterThanOp:
+			operator = sqlparser.LessThanOp
+		case sqlparser.GreaterEqualOp:
+			operator = sqlparser.LessEqualOp
+		case sqlparser.LessThanOp:
+			operator = sqlparser.GreaterThanOp
+		case sqlparser.LessEqualOp:
+			operator = sqlparser.GreaterEqualOp
+		}
+	}
+	val := r.makeEvalEngineExpr(ctx, vdValue)
+	if val == nil {
+		return false
+	}
+	// Values[0] is the start of the range and Values[1] its end.
+	bound := 0
+	if operator == sqlparser.LessThanOp || operator == sqlparser.LessEqualOp {
+		bound = 1
+	}
+
+	newVindexFound := false
+	for _, v := range r.VindexPreds {
+		if !ctx.SemTable.DirectDeps(column).IsSolvedBy(v.TableID) {
+			continue
+		}
+		vindex, ok := v.ColVindex.Vindex.(vindexes.Sequential)
+		if !ok || !column.Name.Equal(v.ColVindex.Columns[0]) {
+			continue
+		}
+		option := rangeOption(v)
+		if option == nil {
+			option = &VindexOption{
+				Values:      []evalengine.Expr{evalengine.NullExpr, evalengine.NullExpr},
+				OpCode:      engine.Range,
+				FoundVindex: vindex,
+				Cost:        costFor(v.ColVindex, engine.Range),
+				Ready:       true,
+			}
+			v.Options = append(v.Options, option)
+		} else if option.Values[bound] != evalengine.NullExpr {
+			// the range is already bounded on this side
+			continue
+		}
+		option.Values[bound] = val
+		option.ValueExprs = append(option.ValueExprs, vdValue)
+		option.Predicates = append(option.Predicates, node)
+		newVindexFound = true
+	}
+	return newVindexFound
+}
+
+// rangeOption returns the Range option of the vindex, if there is one.
+func rangeOption(vpp *VindexPlusPredicates) *VindexOption {
+	for _, option := range vpp.Options {
+		if option.OpCode == engine.Range {
+			return option
+		}
+	}
+	return nil
+}
+
 func (r *Route) planCompositeInOpRecursive(
 	ctx *plancontext.PlanningContext,
 	cmp *sqlparser.ComparisonExpr,
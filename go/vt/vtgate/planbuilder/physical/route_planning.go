@@ -156,7 +156,7 @@ func CreatePhysicalOperator(ctx *plancontext.PlanningContext, opTree abstract.Lo
 			}
 		}
 
-		if r.RouteOpCode == engine.Scatter && op.AST.Limit != nil {
+		if (r.RouteOpCode == engine.Scatter || r.RouteOpCode == engine.Range) && op.AST.Limit != nil {
 			// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
 			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "multi shard update with limit is not supported")
 		}
@@ -656,7 +656,7 @@ func tryMerge(
 			return nil, nil
 		}
 		fallthrough
-	case engine.Scatter, engine.IN:
+	case engine.Scatter, engine.IN, engine.Range:
 		if len(joinPredicates) == 0 {
 			// If we are doing two Scatters, we have to make sure that the
 			// joins are on the correct vindex to allow them to be merged
@@ -675,7 +675,7 @@ func tryMerge(
 		if err != nil {
 			return nil, err
 		}
-		r.PickBestAvailableVindex()
+		r.PickBestAvailableVindex(ctx)
 		return r, nil
 	}
 	return nil, nil
@@ -27,6 +27,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/test/utils"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
@@ -36,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -249,6 +251,34 @@ func TestSysVarSetDisabled(t *testing.T) {
 	testFile(t, "set_sysvar_disabled_cases.txt", makeTestOutput(t), vschemaWrapper)
 }
 
+func TestPlanPin(t *testing.T) {
+	vschema := &vschemaWrapper{
+		v:       loadSchema(t, "schema_test.json", true),
+		version: Gen4,
+	}
+	query := "select id from user where id = 5 and name = 'foo'"
+	routeVindex := func() string {
+		plan, err := TestBuilder(query, vschema, vschema.currentDb())
+		require.NoError(t, err)
+		route, ok := plan.Instructions.(*engine.Route)
+		require.True(t, ok, "should be a Route")
+		return route.Vindex.String()
+	}
+
+	require.Equal(t, "user_index", routeVindex())
+
+	vschema.planPin = &vindexes.PlanPin{Keyspace: "user", Query: query, Vindex: "name_user_map"}
+	require.Equal(t, "name_user_map", routeVindex())
+
+	// The vindexes of the routes of other keyspaces are not pinned.
+	vschema.planPin = &vindexes.PlanPin{Keyspace: "main", Query: query, Vindex: "name_user_map"}
+	require.Equal(t, "user_index", routeVindex())
+
+	// The V3 planner does not honor the pinned vindex.
+	vschema.planPin = &vindexes.PlanPin{Keyspace: "user", Query: query, Planner: V3, Vindex: "name_user_map"}
+	require.Equal(t, "user_index", routeVindex())
+}
+
 func TestOne(t *testing.T) {
 	vschema := &vschemaWrapper{
 		v: loadSchema(t, "schema_test.json", true),
@@ -311,6 +341,15 @@ func TestOLTP(t *testing.T) {
 	testFile(t, "oltp_cases.txt", makeTestOutput(t), vschemaWrapper)
 }
 
+func TestForeignKeys(t *testing.T) {
+	vschemaWrapper := &vschemaWrapper{
+		v:             loadSchema(t, "fk_schema_test.json", true),
+		sysVarEnabled: true,
+	}
+
+	testFile(t, "fk_cases.txt", makeTestOutput(t), vschemaWrapper)
+}
+
 func TestTPCC(t *testing.T) {
 	vschemaWrapper := &vschemaWrapper{
 		v:             loadSchema(t, "tpcc_schema_test.json", true),
@@ -503,6 +542,7 @@ type vschemaWrapper struct {
 	dest          key.Destination
 	sysVarEnabled bool
 	version       plancontext.PlannerVersion
+	planPin       *vindexes.PlanPin
 }
 
 func (vw *vschemaWrapper) GetVSchema() *vindexes.VSchema {
@@ -534,6 +574,18 @@ func (vw *vschemaWrapper) ForeignKeyMode() string {
 	return "allow"
 }
 
+func (vw *vschemaWrapper) DMLReturningEnabled() bool {
+	return true
+}
+
+func (vw *vschemaWrapper) LockTablesTimeout() time.Duration {
+	return 0
+}
+
+func (vw *vschemaWrapper) PlanPin() *vindexes.PlanPin {
+	return vw.planPin
+}
+
 func (vw *vschemaWrapper) AllKeyspace() ([]*vindexes.Keyspace, error) {
 	if vw.keyspace == nil {
 		return nil, errors.New("keyspace not available")
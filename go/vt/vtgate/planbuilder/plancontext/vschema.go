@@ -2,6 +2,7 @@ package plancontext
 
 import (
 	"strings"
+	"time"
 
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 
@@ -53,11 +54,21 @@ type VSchema interface {
 	// ForeignKeyMode returns the foreign_key flag value
 	ForeignKeyMode() string
 
+	// DMLReturningEnabled returns the enable_dml_returning flag value
+	DMLReturningEnabled() bool
+
+	// LockTablesTimeout returns the time to wait for table locks to be acquired
+	LockTablesTimeout() time.Duration
+
 	// GetVSchema returns the latest cached vindexes.VSchema
 	GetVSchema() *vindexes.VSchema
 
 	// GetSrvVschema returns the latest cached vschema.SrvVSchema
 	GetSrvVschema() *vschemapb.SrvVSchema
+
+	// PlanPin returns the plan pin of the query being planned, or nil if it
+	// is not pinned.
+	PlanPin() *vindexes.PlanPin
 }
 
 // PlannerNameToVersion returns the numerical representation of the planner
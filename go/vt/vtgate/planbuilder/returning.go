@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// buildDMLRoutePlan builds the plan of an INSERT, UPDATE or DELETE statement.
+// Its RETURNING clause, if any, is emulated by the vttablet the DML is sent
+// to, so the DML must be routed to a single shard. If the DML changes a table
+// whose foreign keys are managed by vtgate, the plan enforces them. An UPDATE
+// or a DELETE with the DML_BATCH_SIZE directive is executed in batches.
+func buildDMLRoutePlan(stmt sqlparser.Statement, returning sqlparser.SelectExprs, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, f stmtPlanner) (engine.Primitive, error) {
+	if returning != nil && !vschema.DMLReturningEnabled() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not enabled, see the enable_dml_returning flag")
+	}
+	var fp *fkPlan
+	if vschema.Destination() == nil {
+		// The foreign keys are built before the DML is planned, since
+		// planning the DML rewrites its statement.
+		var err error
+		fp, err = buildFkPlan(stmt, nil, reservedVars, vschema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan, err := buildRoutePlan(stmt, reservedVars, vschema, f)
+	if err != nil {
+		return nil, err
+	}
+	if returning != nil && !isSingleShardDML(plan) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: RETURNING on a DML that is not routed to a single shard")
+	}
+	if isDMLBatch(stmt) {
+		if returning != nil || fp != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s with RETURNING or foreign keys managed by vtgate", sqlparser.DirectiveDMLBatchSize)
+		}
+		return buildDMLBatchPlan(stmt, plan)
+	}
+	return fp.wrap(plan), nil
+}
+
+func isSingleShardDML(plan engine.Primitive) bool {
+	switch plan := plan.(type) {
+	case *engine.Insert:
+		switch plan.Opcode {
+		case engine.InsertUnsharded:
+			return plan.Input == nil
+		case engine.InsertSharded:
+			return len(plan.Mid) == 1
+		}
+	case *engine.Update:
+		return isSingleShardRouting(plan.RoutingParameters)
+	case *engine.Delete:
+		return isSingleShardRouting(plan.RoutingParameters)
+	case *engine.Send:
+		// A bypassed DML with a RETURNING clause fails at execution if its
+		// destination resolves to more than one shard.
+		return plan.SingleShardOnly
+	}
+	return false
+}
+
+func isSingleShardRouting(rp *engine.RoutingParameters) bool {
+	switch rp.Opcode {
+	case engine.Unsharded, engine.EqualUnique:
+		return true
+	case engine.Equal:
+		// The V3 planner routes the DMLs on a unique vindex as Equal.
+		return rp.Vindex != nil && rp.Vindex.IsUnique()
+	}
+	return false
+}
+
+func hasReturning(stmt sqlparser.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert:
+		return stmt.Returning != nil
+	case *sqlparser.Update:
+		return stmt.Returning != nil
+	case *sqlparser.Delete:
+		return stmt.Returning != nil
+	}
+	return false
+}
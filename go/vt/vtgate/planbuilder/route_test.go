@@ -29,7 +29,7 @@ import (
 
 /*
 
-This test file only tests the V3 planner. It does not test the Subshard opcode
+This test file only tests the V3 planner. It does not test the Subshard and Range opcodes
 
 For easy reference, opcodes are:
 	Unsharded   	 0
@@ -37,29 +37,33 @@ For easy reference, opcodes are:
 	Equal       	 2
 	IN          	 3
 	MultiEqual  	 4
-	Scatter     	 5
-	Next        	 6
-	DBA         	 7
-	Reference   	 8
-	None        	 9
+	SubShard    	 5
+	Range       	 6
+	Scatter     	 7
+	Next        	 8
+	DBA         	 9
+	Reference   	 10
+	None        	 11
+	ByDestination	 12
 */
 
 func TestJoinCanMerge(t *testing.T) {
 	testcases := [][]bool{
-		{true, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, true, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-
-		{false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
-
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, true, true, false, false},
-		{true, true, true, true, true /*not tested*/, false, true, true, true, true, true, true},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
+		{true, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, true, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+
+		{false, false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
+		{false, false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
+
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, true, true, false, false},
+		{true, true, true, true, true /*not tested*/, false, false, true, true, true, true, true, true},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
 	}
 
 	ks := &vindexes.Keyspace{}
@@ -67,7 +71,8 @@ func TestJoinCanMerge(t *testing.T) {
 		for right, val := range vals {
 			name := fmt.Sprintf("%s:%s", engine.Opcode(left).String(), engine.Opcode(right).String())
 			t.Run(name, func(t *testing.T) {
-				if left == int(engine.SubShard) || right == int(engine.SubShard) {
+				if left == int(engine.SubShard) || right == int(engine.SubShard) ||
+					left == int(engine.Range) || right == int(engine.Range) {
 					t.Skip("not used by v3")
 				}
 
@@ -91,21 +96,22 @@ func TestJoinCanMerge(t *testing.T) {
 
 func TestSubqueryCanMerge(t *testing.T) {
 	testcases := [][]bool{
-		// US    EU    E      IN      ME         subShard        scatter  nxt   dba    ref   none   byD
-		{true, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},   // unsharded
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},  // equalUnique
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // equal
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // in
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // multiEqual
-
-		{false, false, false, false, false, false, false, false, false, false, false, false, false}, // subshard - this whole line is not tested
-
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // scatter
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},  // next
-		{false, false, false, false, false /*not tested*/, false, false, false, true, true, false, false},   // dba
-		{true, true, false, false, false /*not tested*/, false, false, true, true, true, false, false},      // reference
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // none
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false}, // byDestination
+		// US    EU    E      IN      ME         subShard        range  scatter  nxt   dba    ref   none   byD
+		{true, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},   // unsharded
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},  // equalUnique
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // equal
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // in
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // multiEqual
+
+		{false, false, false, false, false, false, false, false, false, false, false, false, false, false}, // subshard - this whole line is not tested
+		{false, false, false, false, false, false, false, false, false, false, false, false, false, false}, // range - this whole line is not tested
+
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // scatter
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},  // next
+		{false, false, false, false, false /*not tested*/, false, false, false, false, true, true, false, false},   // dba
+		{true, true, false, false, false /*not tested*/, false, false, false, true, true, true, false, false},      // reference
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // none
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false}, // byDestination
 	}
 
 	ks := &vindexes.Keyspace{}
@@ -119,7 +125,8 @@ func TestSubqueryCanMerge(t *testing.T) {
 		for right, val := range vals {
 			name := fmt.Sprintf("%s:%s", engine.Opcode(left).String(), engine.Opcode(right).String())
 			t.Run(name, func(t *testing.T) {
-				if left == int(engine.SubShard) || right == int(engine.SubShard) {
+				if left == int(engine.SubShard) || right == int(engine.SubShard) ||
+					left == int(engine.Range) || right == int(engine.Range) {
 					t.Skip("not used by v3")
 				}
 
@@ -132,20 +139,21 @@ func TestSubqueryCanMerge(t *testing.T) {
 
 func TestUnionCanMerge(t *testing.T) {
 	testcases := [][]bool{
-		{true, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-
-		{false, false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
-
-		{false, false, false, false, false /*not tested*/, false, true, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, true, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
-		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false},
+		{true, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+
+		{false, false, false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
+		{false, false, false, false, false, false, false, false, false, false, false, false, false, false}, // this whole line is not tested
+
+		{false, false, false, false, false /*not tested*/, false, false, true, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, true, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, true, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
+		{false, false, false, false, false /*not tested*/, false, false, false, false, false, false, false, false},
 	}
 
 	ks := &vindexes.Keyspace{}
@@ -156,7 +164,8 @@ func TestUnionCanMerge(t *testing.T) {
 		for right, val := range vals {
 			name := fmt.Sprintf("%s:%s", engine.Opcode(left).String(), engine.Opcode(right).String())
 			t.Run(name, func(t *testing.T) {
-				if left == int(engine.SubShard) || right == int(engine.SubShard) {
+				if left == int(engine.SubShard) || right == int(engine.SubShard) ||
+					left == int(engine.Range) || right == int(engine.Range) {
 					t.Skip("not used by v3")
 				}
 
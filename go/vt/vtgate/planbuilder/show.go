@@ -37,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
 const (
@@ -197,6 +198,10 @@ func buildShowTblPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (e
 		ks = table.Keyspace
 	}
 
+	if show.Command == sqlparser.Index && ks.Sharded && isAnyShard(dest) {
+		return buildShowIndexAggregatePlan(show, ks, vschema.ConnCollation())
+	}
+
 	return &engine.Send{
 		Keyspace:          ks,
 		TargetDestination: dest,
@@ -310,16 +315,204 @@ func buildPlanWithDB(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (en
 		IsDML:             false,
 		SingleShardOnly:   true,
 	}
-	if show.Command == sqlparser.Table {
+	switch show.Command {
+	case sqlparser.Table:
 		plan, err = engine.NewRenameField([]string{"Tables_in_" + dbName.String()}, []int{0}, plan)
 		if err != nil {
 			return nil, err
 		}
+	case sqlparser.TableStatus:
+		if keyspace.Sharded && isAnyShard(destination) {
+			return buildTableStatusAggregatePlan(show, keyspace, vschema.ConnCollation())
+		}
 	}
 	return plan, nil
 
 }
 
+// Columns of the `SHOW TABLE STATUS` output.
+var tableStatusColumns = []string{
+	"Name", "Engine", "Version", "Row_format", "Rows", "Avg_row_length", "Data_length", "Max_data_length", "Index_length",
+	"Data_free", "Auto_increment", "Create_time", "Update_time", "Check_time", "Collation", "Checksum", "Create_options", "Comment",
+}
+
+// Column offsets of the `SHOW TABLE STATUS` output that are aggregated across shards.
+const (
+	tableStatusNameCol          = 0
+	tableStatusRowsCol          = 4
+	tableStatusAvgRowLengthCol  = 5
+	tableStatusDataLengthCol    = 6
+	tableStatusIndexLengthCol   = 8
+	tableStatusDataFreeCol      = 9
+	tableStatusAutoIncrementCol = 10
+)
+
+// buildTableStatusAggregatePlan scatters `SHOW TABLE STATUS` to all the shards of a sharded
+// keyspace and merges the per-shard rows, so that the sizes and row counts reported for
+// every table describe the whole keyspace instead of a single random shard. The LIKE or
+// WHERE filter of the statement is applied to the merged rows: applied on the shards,
+// a condition on the row count or sizes would drop the rows of some shards only.
+func buildTableStatusAggregatePlan(show *sqlparser.ShowBasic, keyspace *vindexes.Keyspace, collationID collations.ID) (engine.Primitive, error) {
+	filter := show.Filter
+	show.Filter = nil
+	send := &engine.Send{
+		Keyspace:          keyspace,
+		TargetDestination: key.DestinationAllShards{},
+		Query:             sqlparser.String(show),
+	}
+	sort := &engine.MemorySort{
+		OrderBy: []engine.OrderByParams{{
+			Col:             tableStatusNameCol,
+			WeightStringCol: -1,
+			CollationID:     collationID,
+		}},
+		Input: send,
+	}
+	aggregate := &engine.OrderedAggregate{
+		Aggregates: []*engine.AggregateParams{
+			{Opcode: engine.AggregateSum, Col: tableStatusRowsCol},
+			{Opcode: engine.AggregateSum, Col: tableStatusDataLengthCol},
+			{Opcode: engine.AggregateSum, Col: tableStatusIndexLengthCol},
+			{Opcode: engine.AggregateSum, Col: tableStatusDataFreeCol},
+			{Opcode: engine.AggregateMax, Col: tableStatusAutoIncrementCol},
+		},
+		GroupByKeys: []*engine.GroupByParams{{
+			KeyCol:          tableStatusNameCol,
+			WeightStringCol: -1,
+			CollationID:     collationID,
+		}},
+		Collations: map[int]collations.ID{tableStatusNameCol: collationID},
+		Input:      sort,
+	}
+	// The average row length of the whole table is not any aggregate of the
+	// per-shard averages, so it is derived again from the merged sums.
+	var plan engine.Primitive = &engine.Quotient{
+		Col:      tableStatusAvgRowLengthCol,
+		Dividend: tableStatusDataLengthCol,
+		Divisor:  tableStatusRowsCol,
+		Input:    aggregate,
+	}
+	return buildShowFilterPlan(filter, tableStatusColumns, tableStatusNameCol, collationID, plan)
+}
+
+// Columns of the `SHOW INDEX` output.
+var showIndexColumns = []string{
+	"Table", "Non_unique", "Key_name", "Seq_in_index", "Column_name", "Collation", "Cardinality", "Sub_part",
+	"Packed", "Null", "Index_type", "Comment", "Index_comment", "Visible", "Expression",
+}
+
+// Column offsets of the `SHOW INDEX` output that are aggregated across shards.
+const (
+	showIndexKeyNameCol     = 2
+	showIndexSeqInIndexCol  = 3
+	showIndexCardinalityCol = 6
+)
+
+// buildShowIndexAggregatePlan scatters `SHOW INDEX` of a table of a sharded keyspace to
+// all the shards and merges the per-shard rows of every index column, so that the
+// reported cardinality estimates the whole table instead of a single random shard.
+// As for `SHOW TABLE STATUS`, the WHERE filter is applied to the merged rows.
+func buildShowIndexAggregatePlan(show *sqlparser.ShowBasic, keyspace *vindexes.Keyspace, collationID collations.ID) (engine.Primitive, error) {
+	filter := show.Filter
+	show.Filter = nil
+	send := &engine.Send{
+		Keyspace:          keyspace,
+		TargetDestination: key.DestinationAllShards{},
+		Query:             sqlparser.String(show),
+	}
+	sort := &engine.MemorySort{
+		OrderBy: []engine.OrderByParams{{
+			Col:             showIndexKeyNameCol,
+			WeightStringCol: -1,
+			CollationID:     collationID,
+		}, {
+			Col:             showIndexSeqInIndexCol,
+			WeightStringCol: -1,
+		}},
+		Input: send,
+	}
+	var plan engine.Primitive = &engine.OrderedAggregate{
+		Aggregates: []*engine.AggregateParams{
+			{Opcode: engine.AggregateSum, Col: showIndexCardinalityCol},
+		},
+		GroupByKeys: []*engine.GroupByParams{{
+			KeyCol:          showIndexKeyNameCol,
+			WeightStringCol: -1,
+			CollationID:     collationID,
+		}, {
+			KeyCol:          showIndexSeqInIndexCol,
+			WeightStringCol: -1,
+		}},
+		Collations: map[int]collations.ID{showIndexKeyNameCol: collationID},
+		Input:      sort,
+	}
+	return buildShowFilterPlan(filter, showIndexColumns, -1, collationID, plan)
+}
+
+// buildShowFilterPlan applies the LIKE or WHERE filter of a SHOW statement to the rows
+// of the given plan. A LIKE pattern matches the column at offset likeCol.
+func buildShowFilterPlan(filter *sqlparser.ShowFilter, columns []string, likeCol int, collationID collations.ID, plan engine.Primitive) (engine.Primitive, error) {
+	if filter == nil {
+		return plan, nil
+	}
+
+	var expr sqlparser.Expr
+	switch {
+	case filter.Filter != nil:
+		expr = filter.Filter
+	case likeCol >= 0:
+		expr = &sqlparser.ComparisonExpr{
+			Operator: sqlparser.LikeOp,
+			Left:     sqlparser.NewColName(columns[likeCol]),
+			Right:    sqlparser.NewStrLiteral(filter.Like),
+		}
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "LIKE is not supported by this SHOW statement")
+	}
+	if collationID == collations.Unknown {
+		collationID = collations.Default()
+	}
+	predicate, err := evalengine.Translate(expr, &showColumnLookup{columns: columns, collationID: collationID})
+	if err != nil {
+		return nil, err
+	}
+	return &engine.Filter{
+		Predicate:    predicate,
+		ASTPredicate: expr,
+		Input:        plan,
+	}, nil
+}
+
+// showColumnLookup resolves the columns of a SHOW filter to their offset in the output.
+type showColumnLookup struct {
+	columns     []string
+	collationID collations.ID
+}
+
+var _ evalengine.TranslationLookup = (*showColumnLookup)(nil)
+
+func (s *showColumnLookup) ColumnLookup(col *sqlparser.ColName) (int, error) {
+	for i, column := range s.columns {
+		if col.Name.EqualString(column) {
+			return i, nil
+		}
+	}
+	return 0, vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.BadFieldError, "Unknown column '%s' in 'where clause'", sqlparser.String(col))
+}
+
+func (s *showColumnLookup) CollationForExpr(sqlparser.Expr) collations.ID {
+	return s.collationID
+}
+
+func (s *showColumnLookup) DefaultCollation() collations.ID {
+	return s.collationID
+}
+
+func isAnyShard(dest key.Destination) bool {
+	_, ok := dest.(key.DestinationAnyShard)
+	return ok
+}
+
 func buildVarCharFields(names ...string) []*querypb.Field {
 	fields := make([]*querypb.Field, len(names))
 	for i, v := range names {
@@ -18,6 +18,7 @@ package vtgate
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net"
@@ -73,7 +74,7 @@ var (
 
 	mysqlConnReadTimeout  = flag.Duration("mysql_server_read_timeout", 0, "connection read timeout")
 	mysqlConnWriteTimeout = flag.Duration("mysql_server_write_timeout", 0, "connection write timeout")
-	mysqlQueryTimeout     = flag.Duration("mysql_server_query_timeout", 0, "mysql query timeout")
+	mysqlQueryTimeout     = newDurationFlag("mysql_server_query_timeout", 0, "mysql query timeout")
 
 	mysqlDefaultWorkloadName = flag.String("mysql_default_workload", "OLTP", "Default session workload (OLTP, OLAP, DBA)")
 	mysqlDefaultWorkload     int32
@@ -88,20 +89,62 @@ type vtgateHandler struct {
 	mu sync.Mutex
 
 	vtg         *VTGate
-	connections map[*mysql.Conn]bool
+	connections map[*mysql.Conn]*connActivity
+	// limiter limits the connections and the queries of the users, if set.
+	limiter *userLimiter
+	// stopReaper stops the reaper of the idle connections, if set.
+	stopReaper func()
+	// reaped is signaled, with mu, when the reaper is done with a
+	// connection.
+	reaped *sync.Cond
 }
 
 func newVtgateHandler(vtg *VTGate) *vtgateHandler {
-	return &vtgateHandler{
+	vh := &vtgateHandler{
 		vtg:         vtg,
-		connections: make(map[*mysql.Conn]bool),
+		connections: make(map[*mysql.Conn]*connActivity),
 	}
+	vh.reaped = sync.NewCond(&vh.mu)
+	return vh
 }
 
 func (vh *vtgateHandler) NewConnection(c *mysql.Conn) {
 	vh.mu.Lock()
 	defer vh.mu.Unlock()
-	vh.connections[c] = true
+	vh.connections[c] = &connActivity{}
+}
+
+func (vh *vtgateHandler) ConnectionReady(c *mysql.Conn) {
+	vh.endCommand(c)
+}
+
+// AcquireConnection is part of the mysql.ConnectionLimiter interface.
+func (vh *vtgateHandler) AcquireConnection(c *mysql.Conn) error {
+	if vh.limiter == nil || c.User == "" {
+		return nil
+	}
+	return vh.limiter.acquireConnection(c.User)
+}
+
+// ReleaseConnection is part of the mysql.ConnectionLimiter interface.
+func (vh *vtgateHandler) ReleaseConnection(c *mysql.Conn) {
+	if vh.limiter == nil || c.User == "" {
+		return
+	}
+	vh.limiter.releaseConnection(c.User)
+}
+
+// acquireQuery counts a query of the user of the connection, unless the
+// user reached its maximum number of concurrent queries. The returned
+// function must be called when the query ends.
+func (vh *vtgateHandler) acquireQuery(c *mysql.Conn) (func(), error) {
+	if vh.limiter == nil || c.User == "" {
+		return func() {}, nil
+	}
+	if err := vh.limiter.acquireQuery(c.User); err != nil {
+		return nil, err
+	}
+	return func() { vh.limiter.releaseQuery(c.User) }, nil
 }
 
 func (vh *vtgateHandler) numConnections() int {
@@ -111,6 +154,15 @@ func (vh *vtgateHandler) numConnections() int {
 }
 
 func (vh *vtgateHandler) ComResetConnection(c *mysql.Conn) {
+	// The connection is reset, so the rollback of its transaction by the
+	// reaper is not reported, but a connection closed by the reaper is not
+	// reset.
+	if err := vh.startCommand(c); err != nil && c.IsClosed() {
+		vh.endCommand(c)
+		return
+	}
+	defer vh.endCommand(c)
+
 	ctx := context.Background()
 	session := vh.session(c)
 	if session.InTransaction {
@@ -123,6 +175,14 @@ func (vh *vtgateHandler) ComResetConnection(c *mysql.Conn) {
 }
 
 func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
+	// Wait for the reaper, which may be rolling back the transaction of the
+	// connection.
+	vh.mu.Lock()
+	if activity := vh.connections[c]; activity != nil {
+		vh.waitForReaper(activity)
+	}
+	vh.mu.Unlock()
+
 	// Rollback if there is an ongoing transaction. Ignore error.
 	defer func() {
 		vh.mu.Lock()
@@ -132,8 +192,8 @@ func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 
 	var ctx context.Context
 	var cancel context.CancelFunc
-	if *mysqlQueryTimeout != 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), *mysqlQueryTimeout)
+	if mysqlQueryTimeout.Get() != 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), mysqlQueryTimeout.Get())
 		defer cancel()
 	} else {
 		ctx = context.Background()
@@ -180,10 +240,22 @@ func startSpan(ctx context.Context, query, label string) (trace.Span, context.Co
 }
 
 func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
+	err := vh.startCommand(c)
+	defer vh.endCommand(c)
+	if err != nil {
+		return mysql.NewSQLErrorFromError(err)
+	}
+
+	release, err := vh.acquireQuery(c)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	ctx := context.Background()
 	var cancel context.CancelFunc
-	if *mysqlQueryTimeout != 0 {
-		ctx, cancel = context.WithTimeout(ctx, *mysqlQueryTimeout)
+	if mysqlQueryTimeout.Get() != 0 {
+		ctx, cancel = context.WithTimeout(ctx, mysqlQueryTimeout.Get())
 		defer cancel()
 	}
 
@@ -218,7 +290,7 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 	}()
 
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
-		err := vh.vtg.StreamExecute(ctx, session, query, make(map[string]*querypb.BindVariable), callback)
+		err := vh.vtg.StreamExecute(ctx, session, query, make(map[string]*querypb.BindVariable), flushingCallback(c, callback))
 		return mysql.NewSQLErrorFromError(err)
 	}
 	session, result, err := vh.vtg.Execute(ctx, session, query, make(map[string]*querypb.BindVariable))
@@ -230,6 +302,20 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 	return callback(result)
 }
 
+// flushingCallback sends each batch of rows of an OLAP stream to the
+// client as soon as it is written, rather than after the flush delay of
+// the connection. A client that reads slowly blocks the callback, which in
+// turn holds back the streams from the tablets, so vtgate never buffers
+// more than one batch for it.
+func flushingCallback(c *mysql.Conn, callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	return func(qr *sqltypes.Result) error {
+		if err := callback(qr); err != nil {
+			return err
+		}
+		return c.FlushBuffer()
+	}
+}
+
 func fillInTxStatusFlags(c *mysql.Conn, session *vtgatepb.Session) {
 	if session.InTransaction {
 		c.StatusFlags |= mysql.ServerStatusInTrans
@@ -245,10 +331,16 @@ func fillInTxStatusFlags(c *mysql.Conn, session *vtgatepb.Session) {
 
 // ComPrepare is the handler for command prepare.
 func (vh *vtgateHandler) ComPrepare(c *mysql.Conn, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error) {
+	if err := vh.startCommand(c); err != nil {
+		vh.endCommand(c)
+		return nil, mysql.NewSQLErrorFromError(err)
+	}
+	defer vh.endCommand(c)
+
 	var ctx context.Context
 	var cancel context.CancelFunc
-	if *mysqlQueryTimeout != 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), *mysqlQueryTimeout)
+	if mysqlQueryTimeout.Get() != 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), mysqlQueryTimeout.Get())
 		defer cancel()
 	} else {
 		ctx = context.Background()
@@ -287,10 +379,22 @@ func (vh *vtgateHandler) ComPrepare(c *mysql.Conn, query string, bindVars map[st
 }
 
 func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	err := vh.startCommand(c)
+	defer vh.endCommand(c)
+	if err != nil {
+		return mysql.NewSQLErrorFromError(err)
+	}
+
+	release, err := vh.acquireQuery(c)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	var ctx context.Context
 	var cancel context.CancelFunc
-	if *mysqlQueryTimeout != 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), *mysqlQueryTimeout)
+	if mysqlQueryTimeout.Get() != 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), mysqlQueryTimeout.Get())
 		defer cancel()
 	} else {
 		ctx = context.Background()
@@ -321,7 +425,7 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 	}()
 
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
-		err := vh.vtg.StreamExecute(ctx, session, prepare.PrepareStmt, prepare.BindVars, callback)
+		err := vh.vtg.StreamExecute(ctx, session, prepare.PrepareStmt, prepare.BindVars, flushingCallback(c, callback))
 		return mysql.NewSQLErrorFromError(err)
 	}
 	_, qr, err := vh.vtg.Execute(ctx, session, prepare.PrepareStmt, prepare.BindVars)
@@ -362,6 +466,12 @@ func (vh *vtgateHandler) session(c *mysql.Conn) *vtgatepb.Session {
 		if c.Capabilities&mysql.CapabilityClientFoundRows != 0 {
 			session.Options.ClientFoundRows = true
 		}
+		if vh.vtg != nil && c.SchemaName() != "" {
+			// the keyspace settings can override the default workload
+			if workload := vh.vtg.executor.defaultWorkload(c.SchemaName()); workload != querypb.ExecuteOptions_UNSPECIFIED {
+				session.Options.Workload = workload
+			}
+		}
 		c.ClientData = session
 	}
 	return session
@@ -374,7 +484,21 @@ var vtgateHandle *vtgateHandler
 
 // initTLSConfig inits tls config for the given mysql listener
 func initTLSConfig(mysqlListener *mysql.Listener, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA string, mysqlServerRequireSecureTransport bool, mysqlMinTLSVersion uint16) error {
-	serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+	newServerConfig := func() (*tls.Config, error) {
+		serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+		if err != nil {
+			return nil, err
+		}
+		if *vttls.CertificateReloadInterval > 0 {
+			// Get the current certificates for each client.
+			serverConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+			}
+		}
+		return serverConfig, nil
+	}
+
+	serverConfig, err := newServerConfig()
 	if err != nil {
 		log.Exitf("grpcutils.TLSServerConfig failed: %v", err)
 		return err
@@ -385,7 +509,8 @@ func initTLSConfig(mysqlListener *mysql.Listener, mysqlSslCert, mysqlSslKey, mys
 	signal.Notify(sigChan, syscall.SIGHUP)
 	go func() {
 		for range sigChan {
-			serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+			vttls.ReloadCertificates()
+			serverConfig, err := newServerConfig()
 			if err != nil {
 				log.Errorf("grpcutils.TLSServerConfig failed: %v", err)
 			} else {
@@ -432,6 +557,13 @@ func initMySQLProtocol() {
 	// Create a Listener.
 	var err error
 	vtgateHandle = newVtgateHandler(rpcVTGate)
+	vtgateHandle.stopReaper = vtgateHandle.startReaper(*mysqlServerIdleTimeout, *mysqlServerTransactionIdleTimeout)
+	vtgateHandle.limiter = newUserLimiter(authServer)
+	if ts, err := rpcVTGate.executor.serv.GetTopoServer(); err == nil {
+		vtgateHandle.limiter.start(ts)
+	} else {
+		log.Warningf("the limits of the MySQL users are only read from the auth server, no topo server available: %v", err)
+	}
 	if *mysqlServerPort >= 0 {
 		mysqlListener, err = mysql.NewListener(*mysqlTCPVersion, net.JoinHostPort(*mysqlServerBindAddress, fmt.Sprintf("%v", *mysqlServerPort)), authServer, vtgateHandle, *mysqlConnReadTimeout, *mysqlConnWriteTimeout, *mysqlProxyProtocol)
 		if err != nil {
@@ -516,6 +648,12 @@ func shutdownMysqlProtocolAndDrain() {
 	if sigChan != nil {
 		signal.Stop(sigChan)
 	}
+	if vtgateHandle != nil && vtgateHandle.limiter != nil {
+		vtgateHandle.limiter.stop()
+	}
+	if vtgateHandle != nil && vtgateHandle.stopReaper != nil {
+		vtgateHandle.stopReaper()
+	}
 
 	if atomic.LoadInt32(&busyConnections) > 0 {
 		log.Infof("Waiting for all client connections to be idle (%d active)...", atomic.LoadInt32(&busyConnections))
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// primitiveTracer collects the execution statistics of the primitives
+// executed by a VEXPLAIN ANALYZE statement. Primitives can be executed
+// concurrently, e.g. by Concatenate, so the statistics are protected by mu.
+type primitiveTracer struct {
+	mu    sync.Mutex
+	stats map[engine.Primitive]*engine.PrimitiveStats
+}
+
+func newPrimitiveTracer() *primitiveTracer {
+	return &primitiveTracer{stats: make(map[engine.Primitive]*engine.PrimitiveStats)}
+}
+
+// executePrimitive executes the primitive with a cursor that records the
+// queries it sends to the shards, and records its own statistics.
+func (pt *primitiveTracer) executePrimitive(vc *vcursorImpl, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	startTime := time.Now()
+	qr, err := vc.executePrimitive(&tracingVCursor{vcursorImpl: vc, primitive: primitive}, primitive, bindVars, wantfields)
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	stats := pt.statsLocked(primitive)
+	stats.Calls++
+	stats.Time += time.Since(startTime)
+	if qr != nil {
+		stats.RowsReturned += len(qr.Rows)
+		stats.MemoryBytes += qr.CachedSize(true)
+	}
+	return qr, err
+}
+
+// streamExecutePrimitive is the streaming version of executePrimitive. The
+// memory of a streaming primitive is the sum of the sizes of the results it
+// streamed.
+func (pt *primitiveTracer) streamExecutePrimitive(vc *vcursorImpl, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	startTime := time.Now()
+	err := vc.streamExecutePrimitive(&tracingVCursor{vcursorImpl: vc, primitive: primitive}, primitive, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		pt.mu.Lock()
+		stats := pt.statsLocked(primitive)
+		stats.RowsReturned += len(qr.Rows)
+		stats.MemoryBytes += qr.CachedSize(true)
+		pt.mu.Unlock()
+		return callback(qr)
+	})
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	stats := pt.statsLocked(primitive)
+	stats.Calls++
+	stats.Time += time.Since(startTime)
+	return err
+}
+
+func (pt *primitiveTracer) recordShard(primitive engine.Primitive, target *querypb.Target, rows int, elapsed time.Duration) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	stats := pt.statsLocked(primitive)
+	if stats.Shards == nil {
+		stats.Shards = make(map[string]*engine.ShardStats)
+	}
+	name := topoproto.KeyspaceShardString(target.Keyspace, target.Shard)
+	shard := stats.Shards[name]
+	if shard == nil {
+		shard = &engine.ShardStats{}
+		stats.Shards[name] = shard
+	}
+	shard.Queries++
+	shard.RowsReturned += rows
+	shard.Time += elapsed
+}
+
+func (pt *primitiveTracer) statsLocked(primitive engine.Primitive) *engine.PrimitiveStats {
+	stats := pt.stats[primitive]
+	if stats == nil {
+		stats = &engine.PrimitiveStats{}
+		pt.stats[primitive] = stats
+	}
+	return stats
+}
+
+// tracingVCursor is the cursor given to a primitive executed by a
+// primitiveTracer. It attributes the queries sent to the shards to the
+// primitive.
+type tracingVCursor struct {
+	*vcursorImpl
+	primitive engine.Primitive
+}
+
+var _ engine.VCursor = (*tracingVCursor)(nil)
+
+// ExecuteMultiShard is part of the engine.VCursor interface.
+func (vc *tracingVCursor) ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, autocommit bool) (*sqltypes.Result, []error) {
+	return vc.executeMultiShard(vc.shardContext(), rss, queries, rollbackOnError, autocommit)
+}
+
+// StreamExecuteMulti is part of the engine.VCursor interface.
+func (vc *tracingVCursor) StreamExecuteMulti(query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
+	return vc.streamExecuteMulti(vc.shardContext(), query, rss, bindVars, rollbackOnError, autocommit, callback)
+}
+
+func (vc *tracingVCursor) shardContext() context.Context {
+	tracer := vc.tracer
+	if tracer == nil {
+		return vc.ctx
+	}
+	return withShardObserver(vc.ctx, func(target *querypb.Target, rows int, elapsed time.Duration) {
+		tracer.recordShard(vc.primitive, target, rows, elapsed)
+	})
+}
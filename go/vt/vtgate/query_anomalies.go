@@ -0,0 +1,291 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+var (
+	enableQueryAnomalyDetection = flag.Bool("enable_query_anomaly_detection", false, "If true, vtgate tracks the rate and the latency of the queries of each fingerprint, and reports the queries that deviate sharply from the baseline of their fingerprint, or that start to scatter, in the QueryAnomalies metric, in the logs and at /debug/query_anomalies")
+	queryAnomalyLatencyFactor   = flag.Float64("query_anomaly_latency_factor", 10, "A query is reported as anomalous when it takes this many times as long as the baseline latency of its fingerprint")
+	queryAnomalyQPSFactor       = flag.Float64("query_anomaly_qps_factor", 10, "A fingerprint is reported as anomalous when it is executed this many times as often as its baseline rate, over 10 seconds")
+	queryAnomalyMinSamples      = flag.Int("query_anomaly_min_samples", 100, "The number of executions of a fingerprint before its latency baseline is trusted, and the minimum number of executions over 10 seconds for a rate anomaly")
+	queryAnomalyMaxFingerprints = flag.Int("query_anomaly_max_fingerprints", 10000, "The maximum number of query fingerprints tracked by the anomaly detection. The queries of other fingerprints are not tracked until fingerprints expire after an hour without queries")
+
+	queryFingerprints        = stats.NewGauge("QueryFingerprints", "Number of distinct query fingerprints tracked by the anomaly detection")
+	queryFingerprintsDropped = stats.NewCounter("QueryFingerprintsDropped", "Number of queries not tracked by the anomaly detection because too many fingerprints were tracked")
+	queryAnomalies           = stats.NewCountersWithMultiLabels("QueryAnomalies", "Queries that deviated sharply from the baseline of their fingerprint, by anomaly type, keyspace and table", []string{"Type", "Keyspace", "Table"})
+)
+
+const (
+	// QueryAnomalyLatency is a query that took much longer than the baseline
+	// of its fingerprint.
+	QueryAnomalyLatency = "Latency"
+	// QueryAnomalyQPS is a fingerprint executed much more often than its
+	// baseline.
+	QueryAnomalyQPS = "QPS"
+	// QueryAnomalyScatter is a query that scatters, either because its
+	// fingerprint is new or because its fingerprint did not scatter before.
+	QueryAnomalyScatter = "Scatter"
+
+	// queryAnomalyRateInterval is the interval the rate of a fingerprint is
+	// measured over.
+	queryAnomalyRateInterval = 10 * time.Second
+	// queryAnomalyRateIntervals is the number of intervals before the rate
+	// baseline of a fingerprint is trusted.
+	queryAnomalyRateIntervals = 6
+	// queryAnomalyMinLatency is the latency below which queries are never
+	// reported, however fast their baseline is.
+	queryAnomalyMinLatency = 10 * time.Millisecond
+	// queryAnomalyWarmup is the time after which a new fingerprint that
+	// scatters is reported: before, all the fingerprints are new.
+	queryAnomalyWarmup = 5 * time.Minute
+	// queryFingerprintIdleTimeout is the time after which a fingerprint
+	// without queries is no longer tracked.
+	queryFingerprintIdleTimeout = time.Hour
+	// queryAnomalyHistory is the number of the most recent anomalies that are
+	// kept for /debug/query_anomalies.
+	queryAnomalyHistory = 100
+)
+
+// QueryAnomaly is a query that deviated sharply from the baseline of its
+// fingerprint.
+type QueryAnomaly struct {
+	Time        time.Time
+	Type        string
+	Fingerprint string
+	Keyspace    string
+	Table       string
+	// Description explains how the query deviated from the baseline.
+	Description string
+	// Sample is the query log record of the query, formatted like the query
+	// log, and redacted like it.
+	Sample string
+}
+
+// queryFingerprint is the baseline of the queries of a fingerprint.
+type queryFingerprint struct {
+	lastSeen time.Time
+
+	// samples is the number of queries, and latency the exponentially
+	// weighted moving average of their latency.
+	samples int
+	latency float64
+
+	// rateStart is the start of the current rate interval, and rateCount the
+	// number of queries during it. rate is the exponentially weighted moving
+	// average of the number of queries per interval, over rateIntervals.
+	rateStart     time.Time
+	rateCount     int
+	rate          float64
+	rateIntervals int
+	rateReported  bool
+
+	// plan is the last plan of the fingerprint, and scatter whether it
+	// scatters.
+	plan    *engine.Plan
+	scatter bool
+}
+
+// queryAnomalyDetector tracks the rate and the latency of the queries of each
+// fingerprint, and reports the queries that deviate sharply from them. The
+// fingerprint of a query is its normalized form, as it is planned, so the
+// queries only share their fingerprint if -normalize_queries is set or if
+// they have no literals.
+type queryAnomalyDetector struct {
+	enabled         bool
+	latencyFactor   float64
+	qpsFactor       float64
+	minSamples      int
+	maxFingerprints int
+
+	// now is replaced in tests.
+	now func() time.Time
+	log *logutil.ThrottledLogger
+
+	mu           sync.Mutex
+	start        time.Time
+	lastExpiry   time.Time
+	fingerprints map[string]*queryFingerprint
+	anomalies    []*QueryAnomaly
+}
+
+func newQueryAnomalyDetectorFromFlags() *queryAnomalyDetector {
+	return newQueryAnomalyDetector(*enableQueryAnomalyDetection, *queryAnomalyLatencyFactor, *queryAnomalyQPSFactor, *queryAnomalyMinSamples, *queryAnomalyMaxFingerprints)
+}
+
+func newQueryAnomalyDetector(enabled bool, latencyFactor, qpsFactor float64, minSamples, maxFingerprints int) *queryAnomalyDetector {
+	if minSamples < 1 {
+		minSamples = 1
+	}
+	now := time.Now()
+	return &queryAnomalyDetector{
+		enabled:         enabled,
+		latencyFactor:   latencyFactor,
+		qpsFactor:       qpsFactor,
+		minSamples:      minSamples,
+		maxFingerprints: maxFingerprints,
+		now:             time.Now,
+		log:             logutil.NewThrottledLogger("QueryAnomaly", 5*time.Second),
+		start:           now,
+		lastExpiry:      now,
+		fingerprints:    make(map[string]*queryFingerprint),
+	}
+}
+
+// Record adds a query to the baseline of its fingerprint, after it checked
+// whether the query deviates from it. Only the queries that were planned are
+// tracked, and not the transaction statements.
+func (d *queryAnomalyDetector) Record(logStats *LogStats) {
+	if d == nil || !d.enabled || logStats.plan == nil {
+		return
+	}
+	plan := logStats.plan
+	switch plan.Type {
+	case sqlparser.StmtSelect, sqlparser.StmtInsert, sqlparser.StmtReplace, sqlparser.StmtUpdate, sqlparser.StmtDelete:
+	default:
+		return
+	}
+	fingerprint := plan.Original
+	latency := logStats.TotalTime()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.expire(now)
+
+	fp, ok := d.fingerprints[fingerprint]
+	if !ok {
+		if len(d.fingerprints) >= d.maxFingerprints {
+			queryFingerprintsDropped.Add(1)
+			return
+		}
+		fp = &queryFingerprint{rateStart: now}
+		d.fingerprints[fingerprint] = fp
+		queryFingerprints.Set(int64(len(d.fingerprints)))
+	}
+	fp.lastSeen = now
+
+	// Scatter: the plan only changes when the vschema does.
+	if fp.plan != plan {
+		scatter := engine.Exists(findScatter, plan.Instructions)
+		switch {
+		case !scatter:
+		case !ok && now.Sub(d.start) >= queryAnomalyWarmup:
+			d.report(now, QueryAnomalyScatter, fingerprint, logStats, "a new query fingerprint scatters across all the shards")
+		case ok && fp.plan != nil && !fp.scatter:
+			d.report(now, QueryAnomalyScatter, fingerprint, logStats, "the query fingerprint scatters across all the shards, it did not before")
+		}
+		fp.plan = plan
+		fp.scatter = scatter
+	}
+
+	// Latency.
+	if fp.samples >= d.minSamples && fp.latency > 0 && latency >= queryAnomalyMinLatency && float64(latency) > d.latencyFactor*fp.latency {
+		d.report(now, QueryAnomalyLatency, fingerprint, logStats, fmt.Sprintf("the query took %v, %.1f times the baseline of %v", latency, float64(latency)/fp.latency, time.Duration(fp.latency)))
+	}
+	fp.samples++
+	weight := 1 / float64(fp.samples)
+	if fp.samples > d.minSamples {
+		weight = 1 / float64(d.minSamples)
+	}
+	fp.latency += (float64(latency) - fp.latency) * weight
+
+	// Rate.
+	if elapsed := now.Sub(fp.rateStart); elapsed >= queryAnomalyRateInterval {
+		intervals := int(elapsed / queryAnomalyRateInterval)
+		for i := 0; i < intervals && i < 10*queryAnomalyRateIntervals; i++ {
+			fp.rate += (float64(fp.rateCount) - fp.rate) / queryAnomalyRateIntervals
+			fp.rateCount = 0
+		}
+		fp.rateIntervals += intervals
+		fp.rateStart = fp.rateStart.Add(time.Duration(intervals) * queryAnomalyRateInterval)
+		fp.rateCount = 0
+		fp.rateReported = false
+	}
+	fp.rateCount++
+	if !fp.rateReported && fp.rateIntervals >= queryAnomalyRateIntervals && fp.rateCount >= d.minSamples && float64(fp.rateCount) > d.qpsFactor*fp.rate {
+		fp.rateReported = true
+		d.report(now, QueryAnomalyQPS, fingerprint, logStats, fmt.Sprintf("the query fingerprint was executed %d times in %v, %.1f times the baseline of %.1f", fp.rateCount, queryAnomalyRateInterval, float64(fp.rateCount)/fp.rate, fp.rate))
+	}
+}
+
+// report records an anomaly, with the query log record of the query as a
+// sample. d.mu must be held.
+func (d *queryAnomalyDetector) report(now time.Time, anomalyType, fingerprint string, logStats *LogStats, description string) {
+	queryAnomalies.Add([]string{anomalyType, logStats.Keyspace, logStats.Table}, 1)
+
+	var sample bytes.Buffer
+	if err := logStats.Logf(&sample, nil); err != nil {
+		sample.Reset()
+	}
+	anomaly := &QueryAnomaly{
+		Time:        now,
+		Type:        anomalyType,
+		Fingerprint: fingerprint,
+		Keyspace:    logStats.Keyspace,
+		Table:       logStats.Table,
+		Description: description,
+		Sample:      sample.String(),
+	}
+	d.anomalies = append(d.anomalies, anomaly)
+	if len(d.anomalies) > queryAnomalyHistory {
+		d.anomalies = d.anomalies[len(d.anomalies)-queryAnomalyHistory:]
+	}
+
+	piiSafeSQL, err := sqlparser.RedactSQLQuery(fingerprint)
+	if err != nil {
+		piiSafeSQL = logStats.StmtType
+	}
+	d.log.Warningf("%s anomaly on %s.%s: %s: %q", anomalyType, logStats.Keyspace, logStats.Table, description, piiSafeSQL)
+}
+
+// expire stops tracking the fingerprints without queries for a while. It
+// only looks for them once per timeout. d.mu must be held.
+func (d *queryAnomalyDetector) expire(now time.Time) {
+	if now.Sub(d.lastExpiry) < queryFingerprintIdleTimeout {
+		return
+	}
+	d.lastExpiry = now
+	for fingerprint, fp := range d.fingerprints {
+		if now.Sub(fp.lastSeen) >= queryFingerprintIdleTimeout {
+			delete(d.fingerprints, fingerprint)
+		}
+	}
+	queryFingerprints.Set(int64(len(d.fingerprints)))
+}
+
+// Anomalies returns the most recent anomalies, the oldest first.
+func (d *queryAnomalyDetector) Anomalies() []*QueryAnomaly {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*QueryAnomaly(nil), d.anomalies...)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+type testAnomalyClock struct {
+	now time.Time
+}
+
+func newTestQueryAnomalyDetector(minSamples, maxFingerprints int) (*queryAnomalyDetector, *testAnomalyClock) {
+	d := newQueryAnomalyDetector(true, 10, 10, minSamples, maxFingerprints)
+	clock := &testAnomalyClock{now: time.Unix(1000, 0)}
+	d.now = func() time.Time { return clock.now }
+	d.start = clock.now
+	d.lastExpiry = clock.now
+	return d, clock
+}
+
+func testAnomalyPlan(query string, opcode engine.Opcode) *engine.Plan {
+	return &engine.Plan{
+		Type:         sqlparser.StmtSelect,
+		Original:     query,
+		Instructions: engine.NewRoute(opcode, &vindexes.Keyspace{Name: "ks", Sharded: true}, query, ""),
+	}
+}
+
+func testAnomalyLogStats(plan *engine.Plan, latency time.Duration) *LogStats {
+	logStats := NewLogStats(context.Background(), "Execute", plan.Original, "", nil)
+	logStats.EndTime = logStats.StartTime.Add(latency)
+	logStats.Keyspace = "ks"
+	logStats.Table = "t"
+	logStats.plan = plan
+	return logStats
+}
+
+func TestQueryAnomalyLatency(t *testing.T) {
+	queryAnomalies.ResetAll()
+	d, _ := newTestQueryAnomalyDetector(10, 100)
+	plan := testAnomalyPlan("select * from t where id = :id", engine.EqualUnique)
+
+	for i := 0; i < 10; i++ {
+		d.Record(testAnomalyLogStats(plan, 5*time.Millisecond))
+	}
+	// Slower than the baseline, but not by enough.
+	d.Record(testAnomalyLogStats(plan, 20*time.Millisecond))
+	assert.Empty(t, d.Anomalies())
+
+	d.Record(testAnomalyLogStats(plan, 200*time.Millisecond))
+	anomalies := d.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, QueryAnomalyLatency, anomalies[0].Type)
+	assert.Equal(t, "select * from t where id = :id", anomalies[0].Fingerprint)
+	assert.Equal(t, "ks", anomalies[0].Keyspace)
+	assert.Equal(t, "t", anomalies[0].Table)
+	assert.Contains(t, anomalies[0].Description, "the query took 200ms")
+	assert.Contains(t, anomalies[0].Sample, "select * from t where id = :id")
+	assert.Equal(t, int64(1), queryAnomalies.Counts()["Latency.ks.t"])
+}
+
+func TestQueryAnomalyQPS(t *testing.T) {
+	queryAnomalies.ResetAll()
+	d, clock := newTestQueryAnomalyDetector(10, 100)
+	plan := testAnomalyPlan("select * from t where id = :id", engine.EqualUnique)
+
+	// A baseline of a query per interval.
+	for i := 0; i <= queryAnomalyRateIntervals; i++ {
+		d.Record(testAnomalyLogStats(plan, time.Millisecond))
+		clock.now = clock.now.Add(queryAnomalyRateInterval)
+	}
+	assert.Empty(t, d.Anomalies())
+
+	for i := 0; i < 50; i++ {
+		d.Record(testAnomalyLogStats(plan, time.Millisecond))
+	}
+	// The anomaly is reported once per interval.
+	anomalies := d.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, QueryAnomalyQPS, anomalies[0].Type)
+	assert.Equal(t, int64(1), queryAnomalies.Counts()["QPS.ks.t"])
+}
+
+func TestQueryAnomalyScatter(t *testing.T) {
+	queryAnomalies.ResetAll()
+	d, clock := newTestQueryAnomalyDetector(10, 100)
+
+	// All the fingerprints are new during the warm-up.
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t1", engine.Scatter), time.Millisecond))
+	assert.Empty(t, d.Anomalies())
+
+	clock.now = clock.now.Add(queryAnomalyWarmup)
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t2", engine.Scatter), time.Millisecond))
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t3 where id = :id", engine.EqualUnique), time.Millisecond))
+	// The plan of a fingerprint changes to scatter, e.g. after a vschema change.
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t3 where id = :id", engine.Scatter), time.Millisecond))
+
+	anomalies := d.Anomalies()
+	require.Len(t, anomalies, 2)
+	assert.Equal(t, QueryAnomalyScatter, anomalies[0].Type)
+	assert.Equal(t, "select * from t2", anomalies[0].Fingerprint)
+	assert.Equal(t, "a new query fingerprint scatters across all the shards", anomalies[0].Description)
+	assert.Equal(t, QueryAnomalyScatter, anomalies[1].Type)
+	assert.Equal(t, "select * from t3 where id = :id", anomalies[1].Fingerprint)
+	assert.Equal(t, "the query fingerprint scatters across all the shards, it did not before", anomalies[1].Description)
+	assert.Equal(t, int64(2), queryAnomalies.Counts()["Scatter.ks.t"])
+}
+
+func TestQueryAnomalyFingerprints(t *testing.T) {
+	d, clock := newTestQueryAnomalyDetector(10, 2)
+	dropped := queryFingerprintsDropped.Get()
+
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t1", engine.Scatter), time.Millisecond))
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t2", engine.Scatter), time.Millisecond))
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t3", engine.Scatter), time.Millisecond))
+	assert.Equal(t, int64(2), queryFingerprints.Get())
+	assert.Equal(t, dropped+1, queryFingerprintsDropped.Get())
+
+	// The idle fingerprints expire.
+	clock.now = clock.now.Add(queryFingerprintIdleTimeout)
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t3", engine.Scatter), time.Millisecond))
+	assert.Equal(t, int64(1), queryFingerprints.Get())
+	assert.Equal(t, dropped+1, queryFingerprintsDropped.Get())
+}
+
+func TestQueryAnomalyDisabled(t *testing.T) {
+	d := newQueryAnomalyDetector(false, 10, 10, 1, 100)
+	d.Record(testAnomalyLogStats(testAnomalyPlan("select * from t1", engine.Scatter), time.Second))
+	assert.Empty(t, d.fingerprints)
+
+	var nilDetector *queryAnomalyDetector
+	nilDetector.Record(testAnomalyLogStats(testAnomalyPlan("select * from t1", engine.Scatter), time.Second))
+	assert.Nil(t, nilDetector.Anomalies())
+}
+
+func TestExecutorQueryAnomalies(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	d, clock := newTestQueryAnomalyDetector(10, 100)
+	d.start = clock.now.Add(-queryAnomalyWarmup)
+	executor.queryAnomalies = d
+
+	_, err := executorExec(executor, "select id from user", nil)
+	require.NoError(t, err)
+	_, err = executorExec(executor, "begin", nil)
+	require.NoError(t, err)
+	_, err = executorExec(executor, "rollback", nil)
+	require.NoError(t, err)
+
+	anomalies := d.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, QueryAnomalyScatter, anomalies[0].Type)
+	assert.Equal(t, "select id from user", anomalies[0].Fingerprint)
+	assert.Equal(t, "TestExecutor", anomalies[0].Keyspace)
+	assert.Equal(t, "`user`", anomalies[0].Table)
+	assert.Len(t, d.fingerprints, 1)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"flag"
+	"math/rand"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	queryRetryMaxAttempts    = flag.Int("query_retry_max_attempts", 0, "The maximum number of times an idempotent statement that failed with a transient tablet error is retried, 0 disables the retries. Sessions can opt out with @@query_retry")
+	queryRetryCodes          = flag.String("query_retry_codes", "UNAVAILABLE,FAILED_PRECONDITION,CLUSTER_EVENT", "comma-separated list of the error codes on which idempotent statements are retried")
+	queryRetryInitialBackoff = flag.Duration("query_retry_initial_backoff", 20*time.Millisecond, "The time to wait before the first retry of a statement, doubled after each retry and jittered")
+	queryRetryMaxBackoff     = flag.Duration("query_retry_max_backoff", 500*time.Millisecond, "The maximum time to wait between two retries of a statement")
+
+	queryRetries = stats.NewCountersWithSingleLabel("QueryRetries", "Statements retried after a transient tablet error, by error code", "Code")
+)
+
+// retryPolicy decides which failed statements are executed again.
+// Only the statements that can run twice without a different outcome are
+// retried, outside of transactions and reserved connections:
+//   - SELECTs that neither take named locks nor fetch sequence values.
+//   - UPDATEs and DELETEs routed to a single row by a unique vindex, that
+//     do not change vindex columns and only assign literal values.
+type retryPolicy struct {
+	maxAttempts    int
+	codes          map[vtrpcpb.Code]bool
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newRetryPolicyFromFlags() *retryPolicy {
+	rp := &retryPolicy{
+		maxAttempts:    *queryRetryMaxAttempts,
+		codes:          make(map[vtrpcpb.Code]bool),
+		initialBackoff: *queryRetryInitialBackoff,
+		maxBackoff:     *queryRetryMaxBackoff,
+	}
+	for _, name := range strings.Split(*queryRetryCodes, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		code, ok := vtrpcpb.Code_value[name]
+		if !ok {
+			log.Warningf("ignoring unknown error code in -query_retry_codes: %s", name)
+			continue
+		}
+		rp.codes[vtrpcpb.Code(code)] = true
+	}
+	return rp
+}
+
+// shouldRetry returns true if the statement of the given plan, which failed
+// with err after attempt retries, must be executed again. It waits for the
+// backoff of the retry before returning, and returns false if ctx expires
+// meanwhile.
+func (rp *retryPolicy) shouldRetry(ctx context.Context, safeSession *SafeSession, plan *engine.Plan, err error, attempt int) bool {
+	if rp == nil || attempt >= rp.maxAttempts || plan == nil {
+		return false
+	}
+	code := vterrors.Code(err)
+	if !rp.codes[code] {
+		return false
+	}
+	if safeSession.InTransaction() || safeSession.InReservedConn() || !safeSession.GetQueryRetry() {
+		return false
+	}
+	if !isIdempotent(plan) {
+		return false
+	}
+
+	timer := time.NewTimer(rp.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+	queryRetries.Add(code.String(), 1)
+	return true
+}
+
+// backoff returns the time to wait before the given retry: the initial
+// backoff doubled for each previous retry, capped to the maximum backoff, of
+// which a random half is kept to spread the retries of concurrent statements.
+func (rp *retryPolicy) backoff(attempt int) time.Duration {
+	d := rp.initialBackoff
+	for i := 0; i < attempt && d < rp.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > rp.maxBackoff {
+		d = rp.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isIdempotent returns true if executing the plan twice has the same outcome
+// as executing it once.
+func isIdempotent(plan *engine.Plan) bool {
+	if plan.Instructions == nil {
+		return false
+	}
+	switch plan.Type {
+	case sqlparser.StmtSelect:
+		return !engine.Exists(func(p engine.Primitive) bool {
+			switch p := p.(type) {
+			case *engine.Lock:
+				return true
+			case *engine.Route:
+				return p.Opcode == engine.Next
+			}
+			return false
+		}, plan.Instructions)
+	case sqlparser.StmtUpdate:
+		upd, ok := plan.Instructions.(*engine.Update)
+		if !ok || !singleRowDML(upd.DML) || len(upd.ChangedVindexValues) > 0 {
+			return false
+		}
+		stmt, err := sqlparser.Parse(plan.Original)
+		if err != nil {
+			return false
+		}
+		update, ok := stmt.(*sqlparser.Update)
+		if !ok {
+			return false
+		}
+		for _, expr := range update.Exprs {
+			if !sqlparser.IsValue(expr.Expr) && !sqlparser.IsNull(expr.Expr) {
+				return false
+			}
+		}
+		return true
+	case sqlparser.StmtDelete:
+		del, ok := plan.Instructions.(*engine.Delete)
+		return ok && singleRowDML(del.DML)
+	}
+	return false
+}
+
+// singleRowDML returns true if the DML targets at most one row, through a
+// unique vindex, and does not maintain owned lookup vindexes.
+func singleRowDML(dml *engine.DML) bool {
+	switch dml.Opcode {
+	case engine.Equal, engine.EqualUnique:
+		return dml.Vindex != nil && dml.Vindex.IsUnique() && dml.OwnedVindexQuery == ""
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	rp := &retryPolicy{initialBackoff: 10 * time.Millisecond, maxBackoff: 50 * time.Millisecond}
+	tcases := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 5 * time.Millisecond, max: 10 * time.Millisecond},
+		{attempt: 1, min: 10 * time.Millisecond, max: 20 * time.Millisecond},
+		{attempt: 2, min: 20 * time.Millisecond, max: 40 * time.Millisecond},
+		{attempt: 3, min: 25 * time.Millisecond, max: 50 * time.Millisecond},
+		{attempt: 10, min: 25 * time.Millisecond, max: 50 * time.Millisecond},
+	}
+	for _, tcase := range tcases {
+		for i := 0; i < 20; i++ {
+			d := rp.backoff(tcase.attempt)
+			assert.GreaterOrEqual(t, int64(d), int64(tcase.min), "attempt %d", tcase.attempt)
+			assert.LessOrEqual(t, int64(d), int64(tcase.max), "attempt %d", tcase.attempt)
+		}
+	}
+}
+
+func TestExecutorQueryRetry(t *testing.T) {
+	tcases := []struct {
+		name    string
+		query   string
+		session *vtgatepb.Session
+		retried bool
+	}{{
+		name:    "select",
+		query:   "select id from user where id = 1",
+		retried: true,
+	}, {
+		name:    "select for update",
+		query:   "select id from user where id = 1 for update",
+		retried: true,
+	}, {
+		name:    "update with literal values",
+		query:   "update user_extra set extra = 'a' where user_id = 1",
+		retried: true,
+	}, {
+		name:    "update with expressions",
+		query:   "update user_extra set extra = extra + 1 where user_id = 1",
+		retried: false,
+	}, {
+		name:    "multi-row update",
+		query:   "update user_extra set extra = 'a' where user_id in (1, 2)",
+		retried: false,
+	}, {
+		name:    "delete",
+		query:   "delete from user_extra where user_id = 1",
+		retried: true,
+	}, {
+		name:    "insert",
+		query:   "insert into user_extra(user_id) values (1)",
+		retried: false,
+	}, {
+		name:    "session opted out",
+		query:   "select id from user where id = 1",
+		session: &vtgatepb.Session{TargetString: "@primary", QueryRetryDisabled: true},
+		retried: false,
+	}, {
+		name:    "in transaction",
+		query:   "select id from user where id = 1",
+		session: &vtgatepb.Session{TargetString: "@primary", InTransaction: true, Autocommit: true},
+		retried: false,
+	}}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			executor, sbc1, _, _ := createExecutorEnv()
+			executor.retryPolicy = &retryPolicy{
+				maxAttempts: 2,
+				codes:       map[vtrpcpb.Code]bool{vtrpcpb.Code_UNAVAILABLE: true, vtrpcpb.Code_FAILED_PRECONDITION: true},
+			}
+			session := tcase.session
+			if session == nil {
+				session = &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+			}
+			sbc1.MustFailCodes[vtrpcpb.Code_FAILED_PRECONDITION] = 1
+
+			_, err := executor.Execute(context.Background(), "TestExecutorQueryRetry", NewSafeSession(session), tcase.query, nil)
+			if tcase.retried {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestExecutorQueryRetryMaxAttempts(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+	executor.retryPolicy = &retryPolicy{
+		maxAttempts: 2,
+		codes:       map[vtrpcpb.Code]bool{vtrpcpb.Code_FAILED_PRECONDITION: true, vtrpcpb.Code_UNAVAILABLE: true},
+	}
+	sbc1.MustFailCodes[vtrpcpb.Code_FAILED_PRECONDITION] = 3
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+	_, err := executor.Execute(context.Background(), "TestExecutorQueryRetryMaxAttempts", session, "select id from user where id = 1", nil)
+	require.Error(t, err)
+	assert.EqualValues(t, 0, sbc1.MustFailCodes[vtrpcpb.Code_FAILED_PRECONDITION])
+
+	// The session variable turns the retries back on.
+	_, err = executor.Execute(context.Background(), "TestExecutorQueryRetryMaxAttempts", session, "set @@query_retry = 0", nil)
+	require.NoError(t, err)
+	assert.False(t, session.GetQueryRetry())
+	_, err = executor.Execute(context.Background(), "TestExecutorQueryRetryMaxAttempts", session, "set @@query_retry = 1", nil)
+	require.NoError(t, err)
+	assert.True(t, session.GetQueryRetry())
+}
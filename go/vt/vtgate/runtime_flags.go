@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/runtimeflags"
+)
+
+// The flags of vtgate that can be changed while it runs, through the
+// runtimeflags package. They are read by the queries while they are changed,
+// so their values are atomics, which need no ApplyFunc.
+func init() {
+	for _, name := range []string{
+		"max_memory_rows",
+		"warn_memory_rows",
+		"query_memory_limit",
+		"max_payload_size",
+		"warn_payload_size",
+		"mysql_server_query_timeout",
+	} {
+		runtimeflags.Register(name, nil)
+	}
+}
+
+// intFlag is an int flag whose value is read and written atomically.
+type intFlag struct {
+	value sync2.AtomicInt64
+}
+
+// newIntFlag defines an int flag, like flag.Int64.
+func newIntFlag(name string, value int64, usage string) *intFlag {
+	f := &intFlag{value: sync2.NewAtomicInt64(value)}
+	flag.Var(f, name, usage)
+	return f
+}
+
+// Get returns the value of the flag.
+func (f *intFlag) Get() int64 {
+	return f.value.Get()
+}
+
+// Set is part of the flag.Value interface.
+func (f *intFlag) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	f.value.Set(v)
+	return nil
+}
+
+// String is part of the flag.Value interface.
+func (f *intFlag) String() string {
+	return strconv.FormatInt(f.value.Get(), 10)
+}
+
+// durationFlag is a duration flag whose value is read and written
+// atomically.
+type durationFlag struct {
+	value sync2.AtomicDuration
+}
+
+// newDurationFlag defines a duration flag, like flag.Duration.
+func newDurationFlag(name string, value time.Duration, usage string) *durationFlag {
+	f := &durationFlag{value: sync2.NewAtomicDuration(value)}
+	flag.Var(f, name, usage)
+	return f
+}
+
+// Get returns the value of the flag.
+func (f *durationFlag) Get() time.Duration {
+	return f.value.Get()
+}
+
+// Set is part of the flag.Value interface.
+func (f *durationFlag) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	f.value.Set(v)
+	return nil
+}
+
+// String is part of the flag.Value interface.
+func (f *durationFlag) String() string {
+	return f.value.Get().String()
+}
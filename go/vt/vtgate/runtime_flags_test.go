@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/runtimeflags"
+)
+
+func TestRuntimeFlags(t *testing.T) {
+	saveRows := maxMemoryRows.Get()
+	saveTimeout := mysqlQueryTimeout.Get()
+	defer func() {
+		maxMemoryRows.value.Set(saveRows)
+		mysqlQueryTimeout.value.Set(saveTimeout)
+	}()
+
+	// The queries read the flags while they are changed.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_ = isValidPayloadSize("select 1")
+			_ = maxMemoryRows.Get()
+		}
+	}()
+	_, err := runtimeflags.Set("max_memory_rows", "10", "test")
+	require.NoError(t, err)
+	_, err = runtimeflags.Set("mysql_server_query_timeout", "3s", "test")
+	require.NoError(t, err)
+	<-done
+
+	assert.EqualValues(t, 10, maxMemoryRows.Get())
+	assert.Equal(t, 3*time.Second, mysqlQueryTimeout.Get())
+
+	_, err = runtimeflags.Set("max_memory_rows", "many", "test")
+	assert.Error(t, err)
+	assert.EqualValues(t, 10, maxMemoryRows.Get())
+}
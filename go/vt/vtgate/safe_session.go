@@ -18,12 +18,15 @@ package vtgate
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -307,7 +310,7 @@ func (session *SafeSession) FindAndChangeSessionIfInSingleTxMode(keyspace, shard
 			// If the count of shard session which are non vindex only is greater than 0, then it is a
 			if count > 0 {
 				session.mustRollback = true
-				return 0, 0, nil, vterrors.Errorf(vtrpcpb.Code_ABORTED, "multi-db transaction attempted: %v", session.ShardSessions)
+				return 0, 0, nil, multiDBTransactionError(session.ShardSessions)
 			}
 			// the shard session is now used by non-vindex query as well,
 			// so it is not an exclusive vindex only shard session anymore.
@@ -384,7 +387,7 @@ func (session *SafeSession) AppendOrUpdate(shardSession *vtgatepb.Session_ShardS
 				break
 			}
 			session.mustRollback = true
-			return vterrors.Errorf(vtrpcpb.Code_ABORTED, "multi-db transaction attempted: %v", session.ShardSessions)
+			return multiDBTransactionError(session.ShardSessions)
 		}
 	case vtgatepb.CommitOrder_PRE:
 		newSessions, err := addOrUpdate(shardSession, session.PreSessions)
@@ -417,6 +420,44 @@ func actualNoOfShardSession(sessions []*vtgatepb.Session_ShardSession) int {
 	return actualSS
 }
 
+// multiDBTransactionError returns the error of a transaction that spans more
+// than one shard while the session is in the SINGLE transaction mode. It names
+// the keyspaces and the shards of the transaction, and how to allow it.
+func multiDBTransactionError(sessions []*vtgatepb.Session_ShardSession) error {
+	keyspaces := sessionKeyspaces(sessions)
+	if len(keyspaces) > 1 {
+		crossKeyspaceTransactions.Add([]string{strings.Join(keyspaces, ","), "Rejected"}, 1)
+	}
+	shards := make([]string, 0, len(sessions))
+	for _, ss := range sessions {
+		shards = append(shards, ss.Target.Keyspace+"/"+ss.Target.Shard)
+	}
+	return vterrors.Errorf(vtrpcpb.Code_ABORTED, "multi-db transaction attempted: keyspaces [%s], shards [%s]; the transaction was rolled back, set transaction_mode = 'multi' in the session to allow transactions across shards",
+		strings.Join(keyspaces, " "), strings.Join(shards, " "))
+}
+
+// sessionKeyspaces returns the sorted keyspaces of the shard sessions.
+func sessionKeyspaces(sessions []*vtgatepb.Session_ShardSession) []string {
+	seen := make(map[string]bool, len(sessions))
+	var keyspaces []string
+	for _, ss := range sessions {
+		if !seen[ss.Target.Keyspace] {
+			seen[ss.Target.Keyspace] = true
+			keyspaces = append(keyspaces, ss.Target.Keyspace)
+		}
+	}
+	sort.Strings(keyspaces)
+	return keyspaces
+}
+
+// Keyspaces returns the sorted keyspaces of the shard sessions of the current
+// transaction.
+func (session *SafeSession) Keyspaces() []string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return sessionKeyspaces(session.ShardSessions)
+}
+
 func (session *SafeSession) isSingleDB(txMode vtgatepb.TransactionMode) bool {
 	return session.TransactionMode == vtgatepb.TransactionMode_SINGLE ||
 		(session.TransactionMode == vtgatepb.TransactionMode_UNSPECIFIED && txMode == vtgatepb.TransactionMode_SINGLE)
@@ -658,6 +699,38 @@ func (session *SafeSession) GetSessionEnableSystemSettings() bool {
 	return session.EnableSystemSettings
 }
 
+// SetQueryRetry sets whether the statements of the session can be retried
+// on transient tablet errors.
+func (session *SafeSession) SetQueryRetry(enabled bool) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.QueryRetryDisabled = !enabled
+}
+
+// GetQueryRetry returns whether the statements of the session can be retried
+// on transient tablet errors.
+func (session *SafeSession) GetQueryRetry() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return !session.QueryRetryDisabled
+}
+
+// SetQueryMemoryLimit sets the memory limit of the queries of the session,
+// which lowers --query_memory_limit.
+func (session *SafeSession) SetQueryMemoryLimit(limit int64) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.QueryMemoryLimit = limit
+}
+
+// GetQueryMemoryLimit returns the memory limit of the queries of the
+// session: 0 if it is not set, negative if it is disabled.
+func (session *SafeSession) GetQueryMemoryLimit() int64 {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.QueryMemoryLimit
+}
+
 // SetReadAfterWriteGTID set the ReadAfterWriteGtid setting.
 func (session *SafeSession) SetReadAfterWriteGTID(vtgtid string) {
 	session.mu.Lock()
@@ -712,6 +785,40 @@ func (session *SafeSession) GetOrCreateOptions() *querypb.ExecuteOptions {
 	return session.Session.Options
 }
 
+// applyQueryHints overrides the options of the session with the hints of a
+// query, so that they are sent to vttablet with the queries of its plan. The
+// returned function restores the options of the session. The options are
+// cloned rather than modified, as they may still be referenced by the
+// queries that were sent with them.
+func (session *SafeSession) applyQueryHints(hints *sqlparser.QueryHints) func() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	options := &querypb.ExecuteOptions{}
+	if session.Options != nil {
+		options = proto.Clone(session.Options).(*querypb.ExecuteOptions)
+	}
+	workload, priority, queryTimeoutMs := options.Workload, options.Priority, options.QueryTimeoutMs
+	if hints.Workload != querypb.ExecuteOptions_UNSPECIFIED {
+		options.Workload = hints.Workload
+	}
+	if hints.Priority != "" {
+		options.Priority = hints.Priority
+	}
+	if hints.QueryTimeoutMs != 0 {
+		options.QueryTimeoutMs = hints.QueryTimeoutMs
+	}
+	session.Options = options
+	return func() {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		// The query may have changed other options of the session, e.g.
+		// by creating temporary tables, which must be kept.
+		options := proto.Clone(session.GetOrCreateOptions()).(*querypb.ExecuteOptions)
+		options.Workload, options.Priority, options.QueryTimeoutMs = workload, priority, queryTimeoutMs
+		session.Options = options
+	}
+}
+
 var _ iQueryOption = (*SafeSession)(nil)
 
 func (session *SafeSession) cachePlan() bool {
@@ -736,6 +843,23 @@ func (session *SafeSession) getSelectLimit() int {
 	return int(session.Options.SqlSelectLimit)
 }
 
+// getPriority returns the priority of the queries of the session, or the
+// default priority if none is set.
+func (session *SafeSession) getPriority() int {
+	if session == nil || session.Options == nil {
+		return sqlparser.DefaultPriority
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	priority, err := strconv.Atoi(session.Options.Priority)
+	if err != nil {
+		return sqlparser.DefaultPriority
+	}
+	return priority
+}
+
 // isTxOpen returns true if there is open connection to any of the shard.
 func (session *SafeSession) isTxOpen() bool {
 	session.mu.Lock()
@@ -793,11 +917,7 @@ func (session *SafeSession) AddAdvisoryLock(name string) {
 		session.AdvisoryLock = map[string]int64{name: 1}
 		return
 	}
-	count, exists := session.AdvisoryLock[name]
-	if exists {
-		count++
-	}
-	session.AdvisoryLock[name] = count
+	session.AdvisoryLock[name]++
 }
 
 // RemoveAdvisoryLock removes the advisory lock from the list.
@@ -46,7 +46,8 @@ func TestFailToMultiShardWhenSetToSingleDb(t *testing.T) {
 	err := session.AppendOrUpdate(sess0, vtgatepb.TransactionMode_SINGLE)
 	require.NoError(t, err)
 	err = session.AppendOrUpdate(sess1, vtgatepb.TransactionMode_SINGLE)
-	require.Error(t, err)
+	require.EqualError(t, err, "multi-db transaction attempted: keyspaces [keyspace], shards [keyspace/0 keyspace/1]; the transaction was rolled back, set transaction_mode = 'multi' in the session to allow transactions across shards")
+	require.True(t, session.MustRollback())
 }
 
 func TestPrequeries(t *testing.T) {
@@ -161,6 +161,7 @@ func (stc *ScatterConn) ExecuteMultiShard(
 	if session.InLockSession() && session.TriggerLockHeartBeat() {
 		go stc.runLockQuery(ctx, session)
 	}
+	observe := shardObserverFromContext(ctx)
 
 	allErrors := stc.multiGoTransaction(
 		ctx,
@@ -176,6 +177,7 @@ func (stc *ScatterConn) ExecuteMultiShard(
 				alias   *topodatapb.TabletAlias
 				qs      queryservice.QueryService
 			)
+			startTime := time.Now()
 			transactionID := info.transactionID
 			reservedID := info.reservedID
 
@@ -240,19 +242,22 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			if err != nil {
 				return newInfo, err
 			}
+			if observe != nil && innerqr != nil {
+				observe(rs.Target, len(innerqr.Rows), time.Since(startTime))
+			}
 			mu.Lock()
 			defer mu.Unlock()
 
 			// Don't append more rows if row count is exceeded.
-			if ignoreMaxMemoryRows || len(qr.Rows) <= *maxMemoryRows {
+			if ignoreMaxMemoryRows || int64(len(qr.Rows)) <= maxMemoryRows.Get() {
 				qr.AppendResult(innerqr)
 			}
 			return newInfo, nil
 		},
 	)
 
-	if !ignoreMaxMemoryRows && len(qr.Rows) > *maxMemoryRows {
-		return nil, []error{vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.NetPacketTooLarge, "in-memory row count exceeded allowed limit of %d", *maxMemoryRows)}
+	if !ignoreMaxMemoryRows && int64(len(qr.Rows)) > maxMemoryRows.Get() {
+		return nil, []error{vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.NetPacketTooLarge, "in-memory row count exceeded allowed limit of %d", maxMemoryRows.Get())}
 	}
 
 	return qr, allErrors.GetErrors()
@@ -340,6 +345,7 @@ func (stc *ScatterConn) StreamExecuteMulti(
 	if session.InLockSession() && session.TriggerLockHeartBeat() {
 		go stc.runLockQuery(ctx, session)
 	}
+	observe := shardObserverFromContext(ctx)
 
 	allErrors := stc.multiGoTransaction(
 		ctx,
@@ -354,6 +360,16 @@ func (stc *ScatterConn) StreamExecuteMulti(
 				alias *topodatapb.TabletAlias
 				qs    queryservice.QueryService
 			)
+			startTime := time.Now()
+			rows := 0
+			callback := callback
+			if observe != nil {
+				shardCallback := callback
+				callback = func(reply *sqltypes.Result) error {
+					rows += len(reply.Rows)
+					return shardCallback(reply)
+				}
+			}
 			transactionID := info.transactionID
 			reservedID := info.reservedID
 
@@ -418,6 +434,9 @@ func (stc *ScatterConn) StreamExecuteMulti(
 			if err != nil {
 				return newInfo, err
 			}
+			if observe != nil {
+				observe(rs.Target, rows, time.Since(startTime))
+			}
 
 			return newInfo, nil
 		},
@@ -425,6 +444,24 @@ func (stc *ScatterConn) StreamExecuteMulti(
 	return allErrors.GetErrors()
 }
 
+// shardObserver is notified of the number of rows returned by each shard
+// queried by ExecuteMultiShard and StreamExecuteMulti, and of the time the
+// shard took to return them.
+type shardObserver func(target *querypb.Target, rows int, elapsed time.Duration)
+
+type shardObserverKey struct{}
+
+// withShardObserver returns a context that makes the ScatterConn notify the
+// observer of the queries executed with it.
+func withShardObserver(ctx context.Context, observe shardObserver) context.Context {
+	return context.WithValue(ctx, shardObserverKey{}, observe)
+}
+
+func shardObserverFromContext(ctx context.Context) shardObserver {
+	observe, _ := ctx.Value(shardObserverKey{}).(shardObserver)
+	return observe
+}
+
 // timeTracker is a convenience wrapper used by MessageStream
 // to track how long a stream has been unavailable.
 type timeTracker struct {
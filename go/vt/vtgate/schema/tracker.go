@@ -205,6 +205,9 @@ func (t *Tracker) updateTables(keyspace string, res *sqltypes.Result) {
 
 		cType := sqlparser.ColumnType{Type: colType}
 		col := vindexes.Column{Name: sqlparser.NewIdentifierCI(colName), Type: cType.SQLType(), CollationName: collation}
+		if len(row) > 4 {
+			col.PrimaryKey = row[4].ToString() == "PRI"
+		}
 		cols := t.tables.get(keyspace, tbl)
 
 		t.tables.set(keyspace, tbl, append(cols, col))
@@ -51,8 +51,8 @@ func TestTracking(t *testing.T) {
 		Type:     target.TabletType,
 	}
 	fields := sqltypes.MakeTestFields(
-		"table_name|col_name|col_type|collation_name",
-		"varchar|varchar|varchar|varchar",
+		"table_name|col_name|col_type|collation_name|column_key",
+		"varchar|varchar|varchar|varchar|varchar",
 	)
 
 	type delta struct {
@@ -63,7 +63,7 @@ func TestTracking(t *testing.T) {
 		d0 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"prior|id|int|",
+				"prior|id|int||PRI",
 			),
 			updTbl: []string{"prior"},
 		}
@@ -71,9 +71,9 @@ func TestTracking(t *testing.T) {
 		d1 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t1|id|int|",
-				"t1|name|varchar|utf8_bin",
-				"t2|id|varchar|utf8_bin",
+				"t1|id|int||PRI",
+				"t1|name|varchar|utf8_bin|",
+				"t2|id|varchar|utf8_bin|PRI",
 			),
 			updTbl: []string{"t1", "t2"},
 		}
@@ -81,9 +81,9 @@ func TestTracking(t *testing.T) {
 		d2 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t2|id|varchar|utf8_bin",
-				"t2|name|varchar|utf8_bin",
-				"t3|id|datetime|",
+				"t2|id|varchar|utf8_bin|PRI",
+				"t2|name|varchar|utf8_bin|MUL",
+				"t3|id|datetime||",
 			),
 			updTbl: []string{"prior", "t1", "t2", "t3"},
 		}
@@ -91,7 +91,7 @@ func TestTracking(t *testing.T) {
 		d3 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t4|name|varchar|utf8_bin",
+				"t4|name|varchar|utf8_bin|",
 			),
 			updTbl: []string{"t4"},
 		}
@@ -106,19 +106,19 @@ func TestTracking(t *testing.T) {
 		deltas: []delta{d0, d1},
 		exp: map[string][]vindexes.Column{
 			"t1": {
-				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_INT32},
+				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_INT32, PrimaryKey: true},
 				{Name: sqlparser.NewIdentifierCI("name"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"}},
 			"t2": {
-				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"}},
+				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin", PrimaryKey: true}},
 			"prior": {
-				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_INT32}},
+				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_INT32, PrimaryKey: true}},
 		},
 	}, {
 		tName:  "delete t1 and prior, updated t2 and new t3",
 		deltas: []delta{d0, d1, d2},
 		exp: map[string][]vindexes.Column{
 			"t2": {
-				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"},
+				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin", PrimaryKey: true},
 				{Name: sqlparser.NewIdentifierCI("name"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"}},
 			"t3": {
 				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_DATETIME}},
@@ -128,7 +128,7 @@ func TestTracking(t *testing.T) {
 		deltas: []delta{d0, d1, d2, d3},
 		exp: map[string][]vindexes.Column{
 			"t2": {
-				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"},
+				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin", PrimaryKey: true},
 				{Name: sqlparser.NewIdentifierCI("name"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"}},
 			"t3": {
 				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_DATETIME}},
@@ -0,0 +1,484 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	selectIntoS3Storage   = flag.String("select_into_s3_storage_implementation", "", "The backup storage implementation, like s3 or gcs, to which vtgate exports the results of SELECT ... INTO OUTFILE S3 itself, in chunks. The path of the statement is relative to the root of the storage. By default, these statements are sent to MySQL, which must support them, like Aurora does")
+	selectIntoS3ChunkSize = flag.Int64("select_into_s3_chunk_size", 64*1024*1024, "The size in bytes after which an export of SELECT ... INTO OUTFILE S3 by vtgate starts a new chunk")
+
+	selectIntoS3Rows = stats.NewCounter("SelectIntoS3Rows", "Number of rows exported by SELECT ... INTO OUTFILE S3 to the storage of --select_into_s3_storage_implementation")
+)
+
+const (
+	// An export is a directory of the backup storage. Each of its chunks is
+	// a backup of this directory, with a data file and a rows file, that
+	// is written once the data file is complete. The manifest is written
+	// in its own backup once all the chunks are complete.
+	exportChunkPrefix   = "part-"
+	exportDataFile      = "data"
+	exportRowsFile      = "rows"
+	exportManifestName  = "manifest"
+	exportManifestFile  = "manifest"
+	exportNullValue     = `\N`
+	exportWriteBuffered = 64 * 1024
+)
+
+// selectIntoS3 is a SELECT ... INTO OUTFILE S3 exported by vtgate.
+type selectIntoS3 struct {
+	// query is the SELECT without its INTO clause.
+	query     string
+	dir       string
+	csv       bool
+	header    bool
+	manifest  bool
+	overwrite bool
+	// ordered is set if the rows of the query come in a deterministic
+	// order, which is required to resume an export.
+	ordered bool
+}
+
+// parseSelectIntoS3 returns the export of a SELECT ... INTO OUTFILE S3
+// if vtgate exports them itself, or nil.
+func parseSelectIntoS3(sql string) (*selectIntoS3, error) {
+	if *selectIntoS3Storage == "" || !strings.Contains(strings.ToLower(sql), "outfile") {
+		return nil, nil
+	}
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		// The error is reported by the planner.
+		return nil, nil
+	}
+	var into *sqlparser.SelectInto
+	var orderBy sqlparser.OrderBy
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		into, orderBy = stmt.Into, stmt.OrderBy
+	case *sqlparser.Union:
+		into, orderBy = stmt.Into, stmt.OrderBy
+	default:
+		return nil, nil
+	}
+	if into == nil || into.Type != sqlparser.IntoOutfileS3 {
+		return nil, nil
+	}
+	if into.Charset.Name != "" || into.ExportOption != "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "SELECT ... INTO OUTFILE S3 does not support the CHARACTER SET, FIELDS and LINES options when vtgate exports the results")
+	}
+
+	dir, err := selectIntoS3Dir(into.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(dir, "://") || path.IsAbs(dir) || path.Clean(dir) == "." || strings.HasPrefix(path.Clean(dir), "..") {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the path %q of SELECT ... INTO OUTFILE S3 must be relative to the root of the storage of --select_into_s3_storage_implementation", dir)
+	}
+
+	export := &selectIntoS3{
+		dir:       path.Clean(dir),
+		csv:       strings.HasPrefix(into.FormatOption, " format csv"),
+		header:    strings.HasSuffix(into.FormatOption, " header"),
+		manifest:  into.Manifest == " manifest on",
+		overwrite: into.Overwrite == " overwrite on",
+		ordered:   len(orderBy) > 0,
+	}
+	stmt.(sqlparser.SelectStatement).SetInto(nil)
+	export.query = sqlparser.String(stmt)
+	return export, nil
+}
+
+// selectIntoS3Dir returns the directory of a SELECT ... INTO OUTFILE S3,
+// which must be a string literal.
+func selectIntoS3Dir(fileName string) (string, error) {
+	expr, err := sqlparser.ParseExpr(fileName)
+	if err != nil {
+		return "", err
+	}
+	lit, ok := expr.(*sqlparser.Literal)
+	if !ok || lit.Type != sqlparser.StrVal {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the path of SELECT ... INTO OUTFILE S3 must be a string: %s", fileName)
+	}
+	return lit.Val, nil
+}
+
+// exportChunk is a chunk of an export that has been written.
+type exportChunk struct {
+	name string
+	rows int
+}
+
+// exportManifest is the content of the manifest of an export.
+type exportManifest struct {
+	Entries []exportManifestEntry `json:"entries"`
+}
+
+type exportManifestEntry struct {
+	URL  string `json:"url"`
+	Rows int    `json:"rows"`
+}
+
+// executeSelectIntoS3 streams the results of the query of the export to
+// the storage of --select_into_s3_storage_implementation. A failed export
+// is resumed after its complete chunks by the same statement, if the rows
+// of the query are ordered.
+func (e *Executor) executeSelectIntoS3(ctx context.Context, safeSession *SafeSession, export *selectIntoS3, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	bs, ok := backupstorage.BackupStorageMap[*selectIntoS3Storage]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no registered implementation of backup storage %q for SELECT ... INTO OUTFILE S3", *selectIntoS3Storage)
+	}
+	defer bs.Close()
+
+	chunks, err := export.prepare(ctx, bs)
+	if err != nil {
+		return nil, err
+	}
+	w := &exportWriter{export: export, bs: bs, chunks: chunks}
+	for _, chunk := range chunks {
+		w.skip += chunk.rows
+	}
+	if w.skip > 0 {
+		log.Infof("Resuming the export to %v after %d chunks of %d rows", export.dir, len(chunks), w.skip)
+	}
+
+	err = e.StreamExecute(ctx, "SelectIntoS3", safeSession, export.query, bindVars, func(qr *sqltypes.Result) error {
+		return w.write(ctx, qr)
+	})
+	if err == nil {
+		err = w.finish(ctx)
+	}
+	if err != nil {
+		w.abort(ctx)
+		return nil, err
+	}
+	rows := 0
+	for _, chunk := range w.chunks {
+		rows += chunk.rows
+	}
+	return &sqltypes.Result{RowsAffected: uint64(rows)}, nil
+}
+
+// prepare returns the complete chunks of an earlier attempt of the export,
+// and removes its incomplete chunks. With OVERWRITE ON, it removes all of
+// the export.
+func (export *selectIntoS3) prepare(ctx context.Context, bs backupstorage.BackupStorage) ([]exportChunk, error) {
+	handles, err := bs.ListBackups(ctx, export.dir)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []exportChunk
+	complete := true
+	for _, bh := range handles {
+		name := bh.Name()
+		isChunk := strings.HasPrefix(name, exportChunkPrefix)
+		if !isChunk && name != exportManifestName {
+			continue
+		}
+		if !export.overwrite {
+			if !isChunk {
+				return nil, vterrors.Errorf(vtrpcpb.Code_ALREADY_EXISTS, "the export %v already exists, use OVERWRITE ON to replace it", export.dir)
+			}
+			if complete {
+				if rows, err := readExportRows(ctx, bh); err == nil {
+					chunks = append(chunks, exportChunk{name: name, rows: rows})
+					continue
+				}
+				complete = false
+			}
+		}
+		if err := bs.RemoveBackup(ctx, export.dir, name); err != nil {
+			return nil, err
+		}
+	}
+	if len(chunks) > 0 && !export.ordered {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the export %v was interrupted and can only be resumed if the query has an ORDER BY on a unique key, use OVERWRITE ON to start it over", export.dir)
+	}
+	return chunks, nil
+}
+
+func readExportRows(ctx context.Context, bh backupstorage.BackupHandle) (int, error) {
+	rc, err := bh.ReadFile(ctx, exportRowsFile)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+// exportWriter writes the rows of an export in chunks.
+type exportWriter struct {
+	export *selectIntoS3
+	bs     backupstorage.BackupStorage
+	fields []*querypb.Field
+	// skip is the number of rows that the chunks of an earlier attempt
+	// already hold.
+	skip int
+	// chunks are the complete chunks.
+	chunks []exportChunk
+
+	// The current chunk, if bh is set.
+	bh      backupstorage.BackupHandle
+	wc      io.WriteCloser
+	counter countingWriter
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	rows    int
+}
+
+// countingWriter counts the bytes written to the chunk.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func (w *exportWriter) write(ctx context.Context, qr *sqltypes.Result) error {
+	if len(qr.Fields) > 0 {
+		w.fields = qr.Fields
+	}
+	for _, row := range qr.Rows {
+		if w.skip > 0 {
+			w.skip--
+			continue
+		}
+		if w.bh == nil {
+			if err := w.startChunk(ctx); err != nil {
+				return err
+			}
+		}
+		if err := w.writeRow(row); err != nil {
+			return err
+		}
+		w.rows++
+		selectIntoS3Rows.Add(1)
+		if w.size() >= *selectIntoS3ChunkSize {
+			if err := w.endChunk(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// size returns the size of the current chunk. It does not count the few
+// bytes buffered by the CSV writer.
+func (w *exportWriter) size() int64 {
+	return w.counter.n + int64(w.buf.Buffered())
+}
+
+func (w *exportWriter) startChunk(ctx context.Context) error {
+	name := fmt.Sprintf("%s%05d", exportChunkPrefix, len(w.chunks)+1)
+	bh, err := w.bs.StartBackup(ctx, w.export.dir, name)
+	if err != nil {
+		return err
+	}
+	wc, err := bh.AddFile(ctx, exportDataFile, backupstorage.FileSizeUnknown)
+	if err != nil {
+		_ = bh.AbortBackup(ctx)
+		return err
+	}
+	w.bh, w.wc, w.rows = bh, wc, 0
+	w.counter = countingWriter{w: wc}
+	w.buf = bufio.NewWriterSize(&w.counter, exportWriteBuffered)
+	w.csv = nil
+	if w.export.csv {
+		w.csv = csv.NewWriter(w.buf)
+	}
+	if w.export.header {
+		names := make([]string, len(w.fields))
+		for i, field := range w.fields {
+			names[i] = field.Name
+		}
+		return w.writeRecord(names)
+	}
+	return nil
+}
+
+func (w *exportWriter) writeRow(row []sqltypes.Value) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		switch {
+		case v.IsNull():
+			record[i] = exportNullValue
+		case w.csv != nil:
+			record[i] = v.ToString()
+		default:
+			record[i] = escapeExportText(v.Raw())
+		}
+	}
+	return w.writeRecord(record)
+}
+
+// writeRecord writes a line of the chunk: a CSV record, or fields
+// separated by tabs like the default format of SELECT ... INTO OUTFILE.
+func (w *exportWriter) writeRecord(record []string) error {
+	if w.csv != nil {
+		return w.csv.Write(record)
+	}
+	if _, err := w.buf.WriteString(strings.Join(record, "\t")); err != nil {
+		return err
+	}
+	return w.buf.WriteByte('\n')
+}
+
+// escapeExportText escapes a value like the default format of
+// SELECT ... INTO OUTFILE.
+func escapeExportText(raw []byte) string {
+	if bytes.IndexAny(raw, "\\\t\n\x00") < 0 {
+		return string(raw)
+	}
+	var b strings.Builder
+	for _, c := range raw {
+		switch c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case 0:
+			b.WriteString(`\0`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// endChunk completes the current chunk: its rows file marks it as
+// complete.
+func (w *exportWriter) endChunk(ctx context.Context) error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if err := w.wc.Close(); err != nil {
+		return err
+	}
+	w.wc = nil
+	if err := writeExportFile(ctx, w.bh, exportRowsFile, []byte(strconv.Itoa(w.rows))); err != nil {
+		return err
+	}
+	if err := w.bh.EndBackup(ctx); err != nil {
+		return err
+	}
+	w.chunks = append(w.chunks, exportChunk{name: w.bh.Name(), rows: w.rows})
+	w.bh = nil
+	return nil
+}
+
+// finish completes the last chunk, and writes the manifest if needed.
+// An export without rows has one chunk, which holds the header if any.
+func (w *exportWriter) finish(ctx context.Context) error {
+	if w.bh == nil && len(w.chunks) == 0 {
+		if err := w.startChunk(ctx); err != nil {
+			return err
+		}
+	}
+	if w.bh != nil {
+		if err := w.endChunk(ctx); err != nil {
+			return err
+		}
+	}
+	if !w.export.manifest {
+		return nil
+	}
+
+	var manifest exportManifest
+	for _, chunk := range w.chunks {
+		manifest.Entries = append(manifest.Entries, exportManifestEntry{
+			URL:  path.Join(w.export.dir, chunk.name, exportDataFile),
+			Rows: chunk.rows,
+		})
+	}
+	data, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	bh, err := w.bs.StartBackup(ctx, w.export.dir, exportManifestName)
+	if err != nil {
+		return err
+	}
+	if err := writeExportFile(ctx, bh, exportManifestFile, data); err != nil {
+		_ = bh.AbortBackup(ctx)
+		return err
+	}
+	return bh.EndBackup(ctx)
+}
+
+// abort leaves the current chunk incomplete, so that it is written again
+// when the export is resumed.
+func (w *exportWriter) abort(ctx context.Context) {
+	if w.bh == nil {
+		return
+	}
+	if w.wc != nil {
+		_ = w.wc.Close()
+	}
+	if err := w.bh.EndBackup(ctx); err != nil {
+		log.Warningf("Error ending the incomplete chunk %v of the export to %v: %v", w.bh.Name(), w.export.dir, err)
+	}
+	w.bh = nil
+}
+
+func writeExportFile(ctx context.Context, bh backupstorage.BackupHandle, filename string, data []byte) error {
+	wc, err := bh.AddFile(ctx, filename, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func setSelectIntoS3Storage(t *testing.T, chunkSize int64) string {
+	root := t.TempDir()
+	savedStorage, savedChunkSize, savedRoot := *selectIntoS3Storage, *selectIntoS3ChunkSize, *filebackupstorage.FileBackupStorageRoot
+	t.Cleanup(func() {
+		*selectIntoS3Storage, *selectIntoS3ChunkSize, *filebackupstorage.FileBackupStorageRoot = savedStorage, savedChunkSize, savedRoot
+	})
+	*selectIntoS3Storage, *selectIntoS3ChunkSize, *filebackupstorage.FileBackupStorageRoot = "file", chunkSize, root
+	return root
+}
+
+func TestParseSelectIntoS3(t *testing.T) {
+	// The statements are sent to MySQL unless the storage is set.
+	export, err := parseSelectIntoS3("select * from user into outfile s3 'exports/user'")
+	require.NoError(t, err)
+	assert.Nil(t, export)
+
+	setSelectIntoS3Storage(t, 1)
+	export, err = parseSelectIntoS3("select id from user where id > :id order by id into outfile s3 'exports/user' format csv header manifest on overwrite on")
+	require.NoError(t, err)
+	assert.Equal(t, &selectIntoS3{
+		query:     "select id from `user` where id > :id order by id asc",
+		dir:       "exports/user",
+		csv:       true,
+		header:    true,
+		manifest:  true,
+		overwrite: true,
+		ordered:   true,
+	}, export)
+
+	export, err = parseSelectIntoS3("select id from user into outfile 'exports/user'")
+	require.NoError(t, err)
+	assert.Nil(t, export)
+
+	_, err = parseSelectIntoS3("select id from user into outfile s3 's3://bucket/exports'")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = parseSelectIntoS3("select id from user into outfile s3 'exports/user' fields terminated by ';'")
+	assert.Equal(t, vtrpcpb.Code_UNIMPLEMENTED, vterrors.Code(err))
+}
+
+func TestSelectIntoS3Dir(t *testing.T) {
+	dir, err := selectIntoS3Dir("'exports/user'")
+	require.NoError(t, err)
+	assert.Equal(t, "exports/user", dir)
+
+	for _, fileName := range []string{"exports", "1", "concat('a', 'b')"} {
+		_, err = selectIntoS3Dir(fileName)
+		assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), fileName)
+	}
+}
+
+func TestEscapeExportText(t *testing.T) {
+	assert.Equal(t, "foo", escapeExportText([]byte("foo")))
+	assert.Equal(t, `a\tb\nc\\d\0`, escapeExportText([]byte("a\tb\nc\\d\x00")))
+}
+
+func TestExecuteSelectIntoS3(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	// Each row of the 8 shards is in its own chunk.
+	root := setSelectIntoS3Storage(t, 1)
+	dir := path.Join(root, "exports", "user")
+
+	qr, err := executorExec(executor, "select id, value from user into outfile s3 'exports/user' format csv header manifest on", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, qr.RowsAffected)
+	data, err := os.ReadFile(path.Join(dir, "part-00008", "data"))
+	require.NoError(t, err)
+	assert.Equal(t, "id,value\n1,foo\n", string(data))
+
+	data, err = os.ReadFile(path.Join(dir, "manifest", "manifest"))
+	require.NoError(t, err)
+	var manifest exportManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Entries, 8)
+	assert.Equal(t, exportManifestEntry{URL: "exports/user/part-00001/data", Rows: 1}, manifest.Entries[0])
+
+	_, err = executorExec(executor, "select id, value from user into outfile s3 'exports/user' format csv header manifest on", nil)
+	assert.Equal(t, vtrpcpb.Code_ALREADY_EXISTS, vterrors.Code(err))
+
+	qr, err = executorExec(executor, "select id, value from user into outfile s3 'exports/user' overwrite on", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, qr.RowsAffected)
+	data, err = os.ReadFile(path.Join(dir, "part-00001", "data"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\tfoo\n", string(data))
+	_, err = os.Stat(path.Join(dir, "manifest"))
+	assert.True(t, os.IsNotExist(err))
+
+	// An interrupted export is resumed after its complete chunks, if its
+	// rows are ordered.
+	require.NoError(t, os.Remove(path.Join(dir, "part-00007", "rows")))
+	require.NoError(t, os.RemoveAll(path.Join(dir, "part-00008")))
+	_, err = executorExec(executor, "select id, value from user into outfile s3 'exports/user'", nil)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	qr, err = executorExec(executor, "select id, value from user order by id into outfile s3 'exports/user'", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, qr.RowsAffected)
+	data, err = os.ReadFile(path.Join(dir, "part-00008", "rows"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(data))
+}
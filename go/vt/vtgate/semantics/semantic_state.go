@@ -326,6 +326,48 @@ func RewriteDerivedExpression(expr sqlparser.Expr, vt TableInfo) (sqlparser.Expr
 	return newExpr, nil
 }
 
+// RewriteDerivedUnionExpression rewrites all the ColName instances in the supplied expression with
+// the expressions that one of the SELECTs of the UNION behind the derived table returns at the
+// position of the column
+// SELECT foo FROM (SELECT id+42 as foo FROM user UNION SELECT col FROM music) as t
+// We need `foo` to be translated to `id+42` for the first SELECT and to `col` for the second one
+func RewriteDerivedUnionExpression(expr sqlparser.Expr, vt TableInfo, sel *sqlparser.Select) (sqlparser.Expr, error) {
+	dt, ok := vt.(*DerivedTable)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] %T is not a derived table", vt)
+	}
+	var err error
+	newExpr := sqlparser.CloneExpr(expr)
+	sqlparser.Rewrite(newExpr, func(cursor *sqlparser.Cursor) bool {
+		col, isCol := cursor.Node().(*sqlparser.ColName)
+		if !isCol || err != nil {
+			return err == nil
+		}
+		idx := -1
+		for i, colName := range dt.columnNames {
+			if col.Name.EqualString(colName) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || idx >= len(sel.SelectExprs) {
+			err = vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.BadFieldError, "Unknown column '%s' in 'field list'", col.Name.String())
+			return false
+		}
+		ae, isAliased := sel.SelectExprs[idx].(*sqlparser.AliasedExpr)
+		if !isAliased {
+			err = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: predicate on column '%s' of a UNION with '*'", col.Name.String())
+			return false
+		}
+		cursor.Replace(ae.Expr)
+		return false
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newExpr, nil
+}
+
 // FindSubqueryReference goes over the sub queries and searches for it by value equality instead of reference equality
 func (st *SemTable) FindSubqueryReference(subquery *sqlparser.Subquery) *sqlparser.ExtractedSubquery {
 	for foundSubq, extractedSubquery := range st.SubqueryRef {
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	sessionCheckoutMaxTimeout = flag.Duration("session_checkout_max_timeout", time.Minute, "The maximum time a session can stay checked out with the CheckoutSession API before it is closed, which rolls back its transaction. It is also the timeout of the checkouts that don't specify one")
+
+	sessionCheckoutsCount = stats.NewCountersWithSingleLabel("VtgateSessionCheckouts", "Number of sessions checked out with the CheckoutSession API, by outcome", "Outcome")
+)
+
+const (
+	// checkoutCheckedIn and checkoutExpired are the outcomes of the
+	// VtgateSessionCheckouts metric.
+	checkoutCheckedIn = "CheckedIn"
+	checkoutExpired   = "Expired"
+
+	// checkoutCloseTimeout is the timeout of the closing of an expired
+	// session.
+	checkoutCloseTimeout = 30 * time.Second
+
+	// checkoutTokenSeparator separates the id of the vtgate from the
+	// random part of a checkout token.
+	checkoutTokenSeparator = "."
+)
+
+// sessionCheckouts holds the sessions checked out with CheckoutSession
+// until they are checked in, or until they expire. The sessions live in
+// the memory of this vtgate only, so the tokens start with a random id of
+// the vtgate, which lets a checkin that reaches another vtgate, or this
+// one after a restart, fail with a clear error. The zero value is ready to
+// use.
+type sessionCheckouts struct {
+	mu       sync.Mutex
+	id       string
+	sessions map[string]*checkedOutSession
+}
+
+type checkedOutSession struct {
+	session *vtgatepb.Session
+	timer   *time.Timer
+}
+
+// checkout stores the session under a new token. Once timeout expires,
+// the session is removed and closed with closeSession.
+func (sc *sessionCheckouts) checkout(session *vtgatepb.Session, timeout time.Duration, closeSession func(context.Context, *vtgatepb.Session) error) (string, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.sessions == nil {
+		id, err := newCheckoutToken()
+		if err != nil {
+			return "", err
+		}
+		sc.id = id
+		sc.sessions = make(map[string]*checkedOutSession)
+	}
+	random, err := newCheckoutToken()
+	if err != nil {
+		return "", err
+	}
+	token := sc.id + checkoutTokenSeparator + random
+	sc.sessions[token] = &checkedOutSession{
+		session: session,
+		timer: time.AfterFunc(timeout, func() {
+			sc.expire(token, timeout, closeSession)
+		}),
+	}
+	return token, nil
+}
+
+// checkin removes and returns the session stored under token.
+func (sc *sessionCheckouts) checkin(token string) (*vtgatepb.Session, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if id, _, _ := strings.Cut(token, checkoutTokenSeparator); sc.id == "" || id != sc.id {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "this session was not checked out on this vtgate: checked out sessions live in the memory of the vtgate that checked them out, so the checkin must reach the same vtgate, and they are lost if it restarts")
+	}
+	cs, ok := sc.sessions[token]
+	// If the timer already fired, expire is closing the session.
+	if !ok || !cs.timer.Stop() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no session is checked out with this token: it was already checked in, or its checkout expired")
+	}
+	delete(sc.sessions, token)
+	sessionCheckoutsCount.Add(checkoutCheckedIn, 1)
+	return cs.session, nil
+}
+
+func (sc *sessionCheckouts) expire(token string, timeout time.Duration, closeSession func(context.Context, *vtgatepb.Session) error) {
+	sc.mu.Lock()
+	cs, ok := sc.sessions[token]
+	delete(sc.sessions, token)
+	sc.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	log.Infof("Closing a session that was checked out for more than %v", timeout)
+	sessionCheckoutsCount.Add(checkoutExpired, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), checkoutCloseTimeout)
+	defer cancel()
+	if err := closeSession(ctx, cs.session); err != nil {
+		log.Warningf("Error closing an expired checked out session: %v", err)
+	}
+}
+
+func newCheckoutToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", vterrors.Wrapf(err, "cannot generate a session checkout token")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"flag"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	sloAvailabilityObjective = flag.Float64("slo_availability_objective", 0, "The objective of the availability of the queries of each keyspace: the fraction of them that must not fail with a server error, e.g. 0.999. The availability_objective of the keyspace settings overrides it. 0 disables the availability objective")
+	sloLatencyObjective      = flag.Float64("slo_latency_objective", 0, "The objective of the latency of the queries of each keyspace: the fraction of the successful queries that must complete within --slo_latency_threshold, e.g. 0.99. The latency_objective of the keyspace settings overrides it. 0 disables the latency objective")
+	sloLatencyThreshold      = flag.Duration("slo_latency_threshold", 100*time.Millisecond, "The latency of the queries that --slo_latency_objective counts as fast enough. The latency_threshold of the keyspace settings overrides it")
+
+	sliEvents = stats.NewCountersWithMultiLabels("QueryServingSLIEvents", "Queries counted by the service level indicators of their keyspace, by keyspace, indicator (Availability or Latency) and result (Good or Bad)", []string{"Keyspace", "SLI", "Result"})
+)
+
+const (
+	// SLIAvailability is the indicator of the queries that do not fail with a
+	// server error.
+	SLIAvailability = "Availability"
+	// SLILatency is the indicator of the successful queries that complete
+	// within the latency threshold.
+	SLILatency = "Latency"
+
+	// sloBucketWidth is the time span of the counts the burn rates are
+	// computed from.
+	sloBucketWidth = 30 * time.Second
+)
+
+// sloWindows are the windows the burn rates are computed over. Alerting on
+// the burn rate over a long and a short window, e.g. 1h and 5m, catches fast
+// burns quickly while ignoring the short spikes.
+var sloWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// sloObjectives are the service level objectives of a keyspace.
+type sloObjectives struct {
+	Availability     float64
+	Latency          float64
+	LatencyThreshold time.Duration
+}
+
+// sloBucket counts the queries of a keyspace over sloBucketWidth.
+type sloBucket struct {
+	start time.Time
+	// total is the number of queries, and errors the number of them that
+	// failed with a server error. succeeded is the number of the successful
+	// queries, and slow the number of them that exceeded the latency
+	// threshold.
+	total     int64
+	errors    int64
+	succeeded int64
+	slow      int64
+}
+
+// sloTracker computes the service level indicators of the queries of each
+// keyspace, and how fast they burn the error budget of their objectives. A
+// burn rate of 1 consumes the error budget exactly over the period of the
+// objective, a burn rate of 10 in a tenth of it.
+type sloTracker struct {
+	defaults sloObjectives
+	// settings returns the settings of a keyspace, which override the
+	// default objectives.
+	settings func(keyspace string) *topo.KeyspaceSettings
+
+	// now is replaced in tests.
+	now func() time.Time
+
+	mu sync.Mutex
+	// buckets are the counts of each keyspace over the longest window, as
+	// a ring indexed by the start of the buckets.
+	buckets map[string][]sloBucket
+}
+
+func newSLOTrackerFromFlags(settings func(keyspace string) *topo.KeyspaceSettings) *sloTracker {
+	return newSLOTracker(sloObjectives{
+		Availability:     *sloAvailabilityObjective,
+		Latency:          *sloLatencyObjective,
+		LatencyThreshold: *sloLatencyThreshold,
+	}, settings)
+}
+
+func newSLOTracker(defaults sloObjectives, settings func(keyspace string) *topo.KeyspaceSettings) *sloTracker {
+	return &sloTracker{
+		defaults: defaults,
+		settings: settings,
+		now:      time.Now,
+		buckets:  make(map[string][]sloBucket),
+	}
+}
+
+// objectives returns the objectives of a keyspace.
+func (t *sloTracker) objectives(keyspace string) sloObjectives {
+	objectives := t.defaults
+	if t.settings == nil {
+		return objectives
+	}
+	settings := t.settings(keyspace)
+	if settings == nil {
+		return objectives
+	}
+	if settings.AvailabilityObjective > 0 {
+		objectives.Availability = settings.AvailabilityObjective
+	}
+	if settings.LatencyObjective > 0 {
+		objectives.Latency = settings.LatencyObjective
+	}
+	if threshold := settings.LatencyThresholdDuration(); threshold > 0 {
+		objectives.LatencyThreshold = threshold
+	}
+	return objectives
+}
+
+// isServerError returns true if an error counts against the availability of
+// the keyspace, rather than being caused by the query or the client.
+func isServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNKNOWN,
+		vtrpcpb.Code_DEADLINE_EXCEEDED,
+		vtrpcpb.Code_RESOURCE_EXHAUSTED,
+		vtrpcpb.Code_INTERNAL,
+		vtrpcpb.Code_UNAVAILABLE,
+		vtrpcpb.Code_DATA_LOSS,
+		vtrpcpb.Code_CLUSTER_EVENT:
+		return true
+	}
+	return false
+}
+
+// Record counts a query in the indicators of its keyspace, if the keyspace
+// has objectives. The queries that were not routed to a keyspace, e.g. the
+// transaction statements, are not counted.
+func (t *sloTracker) Record(logStats *LogStats) {
+	if t == nil || logStats.Keyspace == "" {
+		return
+	}
+	t.record(logStats.Keyspace, logStats.TotalTime(), logStats.Error)
+}
+
+func (t *sloTracker) record(keyspace string, latency time.Duration, err error) {
+	objectives := t.objectives(keyspace)
+	if objectives.Availability == 0 && objectives.Latency == 0 {
+		return
+	}
+
+	serverError := isServerError(err)
+	slow := err == nil && latency > objectives.LatencyThreshold
+	if objectives.Availability > 0 {
+		sliEvents.Add([]string{keyspace, SLIAvailability, sliResult(!serverError)}, 1)
+	}
+	if objectives.Latency > 0 && err == nil {
+		sliEvents.Add([]string{keyspace, SLILatency, sliResult(!slow)}, 1)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.bucket(keyspace, t.now())
+	b.total++
+	if serverError {
+		b.errors++
+	}
+	if err == nil {
+		b.succeeded++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+func sliResult(good bool) string {
+	if good {
+		return "Good"
+	}
+	return "Bad"
+}
+
+// bucket returns the bucket of a keyspace at a time, resetting it if it held
+// the counts of an older span. t.mu must be held.
+func (t *sloTracker) bucket(keyspace string, now time.Time) *sloBucket {
+	buckets, ok := t.buckets[keyspace]
+	if !ok {
+		buckets = make([]sloBucket, sloWindows[len(sloWindows)-1].duration/sloBucketWidth)
+		t.buckets[keyspace] = buckets
+	}
+	start := now.Truncate(sloBucketWidth)
+	b := &buckets[int(start.UnixNano()/int64(sloBucketWidth))%len(buckets)]
+	if !b.start.Equal(start) {
+		*b = sloBucket{start: start}
+	}
+	return b
+}
+
+// SLOStatus is the service level of a keyspace over a window.
+type SLOStatus struct {
+	Keyspace  string
+	SLI       string
+	Window    string
+	Objective float64
+	// Total is the number of queries counted by the indicator over the
+	// window, and Bad the number of them that did not meet the objective.
+	Total int64
+	Bad   int64
+	// BurnRate is the ratio of the rate of bad queries to the rate the
+	// objective allows.
+	BurnRate float64
+}
+
+// Status returns the service levels of the keyspaces with objectives, over
+// each window, sorted by keyspace.
+func (t *sloTracker) Status() []*SLOStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keyspaces := make([]string, 0, len(t.buckets))
+	for keyspace := range t.buckets {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	sort.Strings(keyspaces)
+
+	now := t.now()
+	var statuses []*SLOStatus
+	for _, keyspace := range keyspaces {
+		objectives := t.objectives(keyspace)
+		for _, window := range sloWindows {
+			var total, errors, succeeded, slow int64
+			since := now.Add(-window.duration)
+			for _, b := range t.buckets[keyspace] {
+				// A bucket counts if it overlaps the window.
+				if b.start.Add(sloBucketWidth).After(since) && !b.start.After(now) {
+					total += b.total
+					errors += b.errors
+					succeeded += b.succeeded
+					slow += b.slow
+				}
+			}
+			if objectives.Availability > 0 {
+				statuses = append(statuses, newSLOStatus(keyspace, SLIAvailability, window.name, objectives.Availability, total, errors))
+			}
+			if objectives.Latency > 0 {
+				statuses = append(statuses, newSLOStatus(keyspace, SLILatency, window.name, objectives.Latency, succeeded, slow))
+			}
+		}
+	}
+	return statuses
+}
+
+func newSLOStatus(keyspace, sli, window string, objective float64, total, bad int64) *SLOStatus {
+	status := &SLOStatus{
+		Keyspace:  keyspace,
+		SLI:       sli,
+		Window:    window,
+		Objective: objective,
+		Total:     total,
+		Bad:       bad,
+	}
+	if total > 0 {
+		status.BurnRate = float64(bad) / float64(total) / (1 - objective)
+	}
+	return status
+}
+
+// burnRates returns the burn rates of the keyspaces, in thousandths, for the
+// QueryServingSLOBurnRateMilli metric.
+func (t *sloTracker) burnRates() map[string]int64 {
+	rates := make(map[string]int64)
+	for _, status := range t.Status() {
+		rates[strings.Join([]string{status.Keyspace, status.SLI, status.Window}, ".")] = int64(math.Round(status.BurnRate * 1000))
+	}
+	return rates
+}
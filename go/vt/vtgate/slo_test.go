@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestSLOTracker(defaults sloObjectives, settings map[string]*topo.KeyspaceSettings) (*sloTracker, *time.Time) {
+	tracker := newSLOTracker(defaults, func(keyspace string) *topo.KeyspaceSettings {
+		return settings[keyspace]
+	})
+	now := time.Unix(1661000000, 0)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+// findSLOStatus returns the status of an indicator of a keyspace over a window.
+func findSLOStatus(t *testing.T, statuses []*SLOStatus, keyspace, sli, window string) *SLOStatus {
+	t.Helper()
+	for _, status := range statuses {
+		if status.Keyspace == keyspace && status.SLI == sli && status.Window == window {
+			return status
+		}
+	}
+	require.Failf(t, "status not found", "%s %s %s", keyspace, sli, window)
+	return nil
+}
+
+func TestSLOAvailability(t *testing.T) {
+	sliEvents.ResetAll()
+	tracker, _ := newTestSLOTracker(sloObjectives{Availability: 0.99, LatencyThreshold: 100 * time.Millisecond}, nil)
+
+	for i := 0; i < 95; i++ {
+		tracker.record("ks", time.Millisecond, nil)
+	}
+	// Client errors do not count against the availability.
+	tracker.record("ks", time.Millisecond, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "syntax error"))
+	for i := 0; i < 4; i++ {
+		tracker.record("ks", time.Millisecond, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet"))
+	}
+
+	status := findSLOStatus(t, tracker.Status(), "ks", SLIAvailability, "5m")
+	assert.Equal(t, int64(100), status.Total)
+	assert.Equal(t, int64(4), status.Bad)
+	// 4% of bad queries burn the 1% budget 4 times too fast.
+	assert.InDelta(t, 4, status.BurnRate, 0.001)
+	assert.Equal(t, int64(4000), tracker.burnRates()["ks.Availability.5m"])
+	assert.Equal(t, int64(96), sliEvents.Counts()["ks.Availability.Good"])
+	assert.Equal(t, int64(4), sliEvents.Counts()["ks.Availability.Bad"])
+
+	// Without a latency objective, the latency is not reported.
+	for _, status := range tracker.Status() {
+		assert.Equal(t, SLIAvailability, status.SLI)
+	}
+}
+
+func TestSLOLatency(t *testing.T) {
+	sliEvents.ResetAll()
+	tracker, _ := newTestSLOTracker(sloObjectives{Latency: 0.9, LatencyThreshold: 100 * time.Millisecond}, nil)
+
+	for i := 0; i < 8; i++ {
+		tracker.record("ks", 10*time.Millisecond, nil)
+	}
+	tracker.record("ks", 150*time.Millisecond, nil)
+	tracker.record("ks", time.Second, nil)
+	// The latency of the failed queries is not counted.
+	tracker.record("ks", time.Second, errors.New("failed"))
+
+	status := findSLOStatus(t, tracker.Status(), "ks", SLILatency, "1h")
+	assert.Equal(t, int64(10), status.Total)
+	assert.Equal(t, int64(2), status.Bad)
+	assert.InDelta(t, 2, status.BurnRate, 0.001)
+	assert.Equal(t, int64(8), sliEvents.Counts()["ks.Latency.Good"])
+	assert.Equal(t, int64(2), sliEvents.Counts()["ks.Latency.Bad"])
+}
+
+func TestSLOWindows(t *testing.T) {
+	tracker, now := newTestSLOTracker(sloObjectives{Availability: 0.9}, nil)
+	unavailable := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet")
+
+	tracker.record("ks", 0, unavailable)
+	*now = now.Add(10 * time.Minute)
+	tracker.record("ks", 0, nil)
+
+	statuses := tracker.Status()
+	// The failure is out of the 5m window.
+	status := findSLOStatus(t, statuses, "ks", SLIAvailability, "5m")
+	assert.Equal(t, int64(1), status.Total)
+	assert.Zero(t, status.Bad)
+	assert.Zero(t, status.BurnRate)
+	status = findSLOStatus(t, statuses, "ks", SLIAvailability, "30m")
+	assert.Equal(t, int64(2), status.Total)
+	assert.Equal(t, int64(1), status.Bad)
+	assert.InDelta(t, 5, status.BurnRate, 0.001)
+
+	// The counts of the buckets are reset when the ring wraps around.
+	*now = now.Add(6 * time.Hour)
+	tracker.record("ks", 0, nil)
+	status = findSLOStatus(t, tracker.Status(), "ks", SLIAvailability, "6h")
+	assert.Equal(t, int64(1), status.Total)
+	assert.Zero(t, status.Bad)
+}
+
+func TestSLOKeyspaceSettings(t *testing.T) {
+	tracker, _ := newTestSLOTracker(sloObjectives{LatencyThreshold: 100 * time.Millisecond}, map[string]*topo.KeyspaceSettings{
+		"ks1": {AvailabilityObjective: 0.999, LatencyObjective: 0.5, LatencyThreshold: "10ms"},
+	})
+
+	// Keyspaces without objectives are not tracked.
+	tracker.record("ks2", time.Second, nil)
+	tracker.Record(&LogStats{Error: errors.New("no keyspace")})
+
+	tracker.record("ks1", 50*time.Millisecond, nil)
+	tracker.record("ks1", 5*time.Millisecond, nil)
+
+	statuses := tracker.Status()
+	require.Len(t, statuses, 2*len(sloWindows))
+	status := findSLOStatus(t, statuses, "ks1", SLIAvailability, "6h")
+	assert.Equal(t, 0.999, status.Objective)
+	assert.Zero(t, status.Bad)
+	// The latency threshold of the keyspace applies.
+	status = findSLOStatus(t, statuses, "ks1", SLILatency, "6h")
+	assert.Equal(t, int64(1), status.Bad)
+	assert.InDelta(t, 1, status.BurnRate, 0.001)
+}
+
+func TestSLORecordLogStats(t *testing.T) {
+	tracker, _ := newTestSLOTracker(sloObjectives{Availability: 0.99}, nil)
+	logStats := NewLogStats(context.Background(), "Execute", "select 1 from dual", "", nil)
+	logStats.Keyspace = "ks"
+	logStats.EndTime = logStats.StartTime.Add(time.Millisecond)
+	tracker.Record(logStats)
+
+	var nilTracker *sloTracker
+	nilTracker.Record(logStats)
+	assert.Nil(t, nilTracker.Status())
+
+	assert.Equal(t, int64(1), findSLOStatus(t, tracker.Status(), "ks", SLIAvailability, "5m").Total)
+}
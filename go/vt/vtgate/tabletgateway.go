@@ -156,6 +156,15 @@ func (gw *TabletGateway) setupBuffering(ctx context.Context) {
 	default:
 		log.Exitf("unknown buffering implementation for TabletGateway: %q", *bufferImplementation)
 	}
+
+	// buffer the requests to the shards whose writes are switched by a workflow
+	if (cfg.Enabled || cfg.DryRun) && gw.srvTopoServer != nil {
+		if ts, err := gw.srvTopoServer.GetTopoServer(); err == nil && ts != nil {
+			gw.buffer.WatchTrafficSwitches(ctx, ts, gw.localCell)
+		} else {
+			log.Warningf("not buffering during traffic switches, no topo server available: %v", err)
+		}
+	}
 }
 
 // QueryServiceByAlias satisfies the Gateway interface
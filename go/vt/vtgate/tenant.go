@@ -0,0 +1,403 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	tenantGroupPrefix = flag.String("tenant_group_prefix", "tenant:", "Prefix of the groups of the MySQL users, as returned by the auth server, that scope the users to a tenant: a user in the group 'tenant:42' can only access the rows of the tenant 42 of the tables with a tenant column in the vschema. Empty disables the enforcement of the tenant predicates")
+
+	tenantPredicates = stats.NewCountersWithMultiLabels("TenantPredicates", "Number of tables of the queries of tenant-scoped users that lacked the predicate on their tenant column, by keyspace and action", []string{"Keyspace", "Action"})
+)
+
+// tenantOf returns the tenant of the caller, or "" if it isn't scoped to a
+// tenant.
+func tenantOf(ctx context.Context) (string, error) {
+	im := callerid.ImmediateCallerIDFromContext(ctx)
+	if im == nil || *tenantGroupPrefix == "" {
+		return "", nil
+	}
+	tenant := ""
+	for _, group := range im.Groups {
+		if !strings.HasPrefix(group, *tenantGroupPrefix) {
+			continue
+		}
+		if tenant != "" && group != *tenantGroupPrefix+tenant {
+			return "", vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "user %s is scoped to several tenants", im.Username)
+		}
+		tenant = strings.TrimPrefix(group, *tenantGroupPrefix)
+	}
+	return tenant, nil
+}
+
+// tenantEnforcer enforces the predicates on the tenant columns of the tables
+// in the statements of a tenant-scoped user. Each table with a tenant column
+// must be filtered by an equality predicate on its tenant column for the
+// tenant of the user, either in the WHERE clause or in the ON condition of
+// an inner join. Depending on the tenant predicate policy of the keyspace of
+// the table, the statements without it are either rejected or augmented
+// with it.
+type tenantEnforcer struct {
+	vcursor   *vcursorImpl
+	tenant    string
+	bindVars  map[string]*querypb.BindVariable
+	augmented bool
+}
+
+// tenantTable is a table with a tenant column referenced by a statement.
+type tenantTable struct {
+	table *vindexes.Table
+	// qualifier qualifies the columns of the table in the statement.
+	qualifier sqlparser.TableName
+	// conds are the conditions of the ON clauses of the joins that filter
+	// the rows of the table.
+	conds []sqlparser.Expr
+	// join is the outer join the table is on the inner side of, if any: its
+	// ON condition is augmented rather than the WHERE clause, which would
+	// filter the rows of the outer side.
+	join *sqlparser.JoinTableExpr
+}
+
+// enforceTenantPredicates checks that the statement of a tenant-scoped user
+// only accesses the rows of its tenant, and returns true if it augmented the
+// statement.
+func enforceTenantPredicates(vcursor *vcursorImpl, stmt sqlparser.Statement, bindVars map[string]*querypb.BindVariable) (bool, error) {
+	tenant, err := tenantOf(vcursor.ctx)
+	if err != nil || tenant == "" {
+		return false, err
+	}
+
+	// The tables accessed by a stored procedure are not known.
+	if _, ok := stmt.(*sqlparser.CallProc); ok {
+		return false, vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "tenant-scoped users cannot call stored procedures")
+	}
+
+	te := &tenantEnforcer{vcursor: vcursor, tenant: tenant, bindVars: bindVars}
+	// The nodes are augmented once they are all found.
+	var nodes []sqlparser.SQLNode
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.Select, *sqlparser.Update, *sqlparser.Delete, *sqlparser.Insert:
+			nodes = append(nodes, node)
+		}
+		return true, nil
+	}, stmt)
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			err = te.enforce(node.From, &node.Where, nil)
+		case *sqlparser.Update:
+			err = te.enforce(node.TableExprs, &node.Where, node.Exprs)
+		case *sqlparser.Delete:
+			err = te.enforce(node.TableExprs, &node.Where, nil)
+		case *sqlparser.Insert:
+			err = te.enforceInsert(node)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return te.augmented, nil
+}
+
+// enforce checks the predicates on the tenant columns of the tables of a
+// SELECT, UPDATE or DELETE, and the tenant columns updated by setExprs.
+func (te *tenantEnforcer) enforce(from []sqlparser.TableExpr, where **sqlparser.Where, setExprs sqlparser.UpdateExprs) error {
+	var tables []*tenantTable
+	for _, expr := range from {
+		if err := te.addTenantTables(&tables, expr, nil, nil); err != nil {
+			return err
+		}
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	var whereConds []sqlparser.Expr
+	if *where != nil {
+		whereConds = sqlparser.SplitAndExpression(nil, (*where).Expr)
+	}
+	for _, tt := range tables {
+		conds := append(append([]sqlparser.Expr{}, whereConds...), tt.conds...)
+		found, err := te.hasPredicate(tt, conds, len(tables) == 1)
+		if err != nil {
+			return err
+		}
+		if found {
+			continue
+		}
+		lacksPredicate := vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query lacks the equality predicate on the tenant column %s of table %s", tt.table.TenantColumn.String(), tt.table.Name.String())
+		if err := te.augmentable(tt.table, lacksPredicate); err != nil {
+			return err
+		}
+		predicate := &sqlparser.ComparisonExpr{
+			Operator: sqlparser.EqualOp,
+			Left:     sqlparser.NewColNameWithQualifier(tt.table.TenantColumn.String(), tt.qualifier),
+			Right:    te.literal(),
+		}
+		if tt.join != nil {
+			tt.join.Condition.On = sqlparser.AndExpressions(tt.join.Condition.On, predicate)
+		} else if *where == nil {
+			*where = sqlparser.NewWhere(sqlparser.WhereClause, predicate)
+		} else {
+			(*where).Expr = sqlparser.AndExpressions((*where).Expr, predicate)
+		}
+	}
+
+	for _, setExpr := range setExprs {
+		for _, tt := range tables {
+			if te.isTenantColumn(tt, setExpr.Name, true) {
+				if _, ok := te.tenantValue(setExpr.Expr); !ok {
+					return vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query changes the tenant of the rows of table %s", tt.table.Name.String())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// enforceInsert checks the values of the tenant column of the table of an
+// INSERT.
+func (te *tenantEnforcer) enforceInsert(ins *sqlparser.Insert) error {
+	table, err := te.findTable(ins.Table)
+	if err != nil || table == nil || table.TenantColumn.IsEmpty() {
+		return err
+	}
+
+	lacksTenant := vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query lacks the value of the tenant column %s of table %s", table.TenantColumn.String(), table.Name.String())
+	index := ins.Columns.FindColumn(table.TenantColumn)
+	switch rows := ins.Rows.(type) {
+	case sqlparser.Values:
+		if index < 0 {
+			// Without a column list, the index of the tenant column is not
+			// known.
+			if len(ins.Columns) == 0 {
+				return lacksTenant
+			}
+			if err := te.augmentable(table, lacksTenant); err != nil {
+				return err
+			}
+			ins.Columns = append(ins.Columns, table.TenantColumn)
+			for i := range rows {
+				rows[i] = append(rows[i], te.literal())
+			}
+			break
+		}
+		for _, row := range rows {
+			if index >= len(row) {
+				return lacksTenant
+			}
+			if isValue, ok := te.tenantValue(row[index]); !ok {
+				if isValue {
+					return te.otherTenant(table)
+				}
+				return lacksTenant
+			}
+		}
+	case *sqlparser.Select:
+		if index < 0 || index >= len(rows.SelectExprs) {
+			return lacksTenant
+		}
+		expr, ok := rows.SelectExprs[index].(*sqlparser.AliasedExpr)
+		if !ok {
+			return lacksTenant
+		}
+		if _, ok := te.tenantValue(expr.Expr); !ok {
+			return lacksTenant
+		}
+	default:
+		return lacksTenant
+	}
+
+	for _, setExpr := range ins.OnDup {
+		if setExpr.Name.Name.Equal(table.TenantColumn) {
+			if _, ok := te.tenantValue(setExpr.Expr); !ok {
+				return vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query changes the tenant of the rows of table %s", table.Name.String())
+			}
+		}
+	}
+	return nil
+}
+
+// findTable returns the table of the vschema of a table name, or nil if it
+// is a table of a system schema or a vindex. The tables that aren't found are
+// rejected, since their tenant column can't be checked.
+func (te *tenantEnforcer) findTable(name sqlparser.TableName) (*vindexes.Table, error) {
+	if sqlparser.SystemSchema(name.Qualifier.String()) {
+		return nil, nil
+	}
+	table, _, _, _, _, err := te.vcursor.FindTableOrVindex(name)
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// addTenantTables adds the tables with a tenant column of a table expression
+// to tables. conds are the ON conditions that filter the rows of the table
+// expression, and join the outer join it is on the inner side of.
+func (te *tenantEnforcer) addTenantTables(tables *[]*tenantTable, expr sqlparser.TableExpr, conds []sqlparser.Expr, join *sqlparser.JoinTableExpr) error {
+	switch expr := expr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		// Derived tables are checked on their own.
+		name, ok := expr.Expr.(sqlparser.TableName)
+		if !ok {
+			return nil
+		}
+		table, err := te.findTable(name)
+		if err != nil || table == nil || table.TenantColumn.IsEmpty() {
+			return err
+		}
+		qualifier := name
+		if !expr.As.IsEmpty() {
+			qualifier = sqlparser.TableName{Name: expr.As}
+		}
+		*tables = append(*tables, &tenantTable{table: table, qualifier: qualifier, conds: conds, join: join})
+	case *sqlparser.ParenTableExpr:
+		for _, expr := range expr.Exprs {
+			if err := te.addTenantTables(tables, expr, conds, join); err != nil {
+				return err
+			}
+		}
+	case *sqlparser.JoinTableExpr:
+		var on []sqlparser.Expr
+		inner := expr
+		if expr.Condition != nil && expr.Condition.On != nil {
+			on = sqlparser.SplitAndExpression(nil, expr.Condition.On)
+		}
+		if expr.Condition == nil || len(expr.Condition.Using) > 0 {
+			inner = nil
+		}
+		innerConds := append(append([]sqlparser.Expr{}, conds...), on...)
+		leftConds, leftJoin, rightConds, rightJoin := conds, join, conds, join
+		switch expr.Join {
+		case sqlparser.LeftJoinType:
+			rightConds, rightJoin = innerConds, inner
+		case sqlparser.RightJoinType:
+			leftConds, leftJoin = innerConds, inner
+		case sqlparser.NormalJoinType, sqlparser.StraightJoinType:
+			leftConds, rightConds = innerConds, innerConds
+		}
+		if err := te.addTenantTables(tables, expr.LeftExpr, leftConds, leftJoin); err != nil {
+			return err
+		}
+		return te.addTenantTables(tables, expr.RightExpr, rightConds, rightJoin)
+	}
+	return nil
+}
+
+// hasPredicate returns true if one of conds is an equality predicate on the
+// tenant column of the table for the tenant of the user. The columns without
+// qualifier are only those of the table if it is the only table.
+func (te *tenantEnforcer) hasPredicate(tt *tenantTable, conds []sqlparser.Expr, onlyTable bool) (bool, error) {
+	found := false
+	for _, cond := range conds {
+		cmp, ok := cond.(*sqlparser.ComparisonExpr)
+		if !ok || cmp.Operator != sqlparser.EqualOp {
+			continue
+		}
+		value := cmp.Right
+		col, ok := cmp.Left.(*sqlparser.ColName)
+		if !ok {
+			value = cmp.Left
+			if col, ok = cmp.Right.(*sqlparser.ColName); !ok {
+				continue
+			}
+		}
+		if !te.isTenantColumn(tt, col, onlyTable) {
+			continue
+		}
+		isValue, ok := te.tenantValue(value)
+		if ok {
+			found = true
+		} else if isValue {
+			return false, te.otherTenant(tt.table)
+		}
+	}
+	return found, nil
+}
+
+// isTenantColumn returns true if col is the tenant column of the table.
+func (te *tenantEnforcer) isTenantColumn(tt *tenantTable, col *sqlparser.ColName, onlyTable bool) bool {
+	if !col.Name.Equal(tt.table.TenantColumn) {
+		return false
+	}
+	if col.Qualifier.IsEmpty() {
+		return onlyTable
+	}
+	return col.Qualifier.Name.String() == tt.qualifier.Name.String()
+}
+
+// tenantValue returns whether expr is a literal or a bind variable, and
+// whether it is the tenant of the user.
+func (te *tenantEnforcer) tenantValue(expr sqlparser.Expr) (isValue bool, ok bool) {
+	switch expr := expr.(type) {
+	case *sqlparser.Literal:
+		return true, (expr.Type == sqlparser.StrVal || expr.Type == sqlparser.IntVal) && expr.Val == te.tenant
+	case sqlparser.Argument:
+		bv, found := te.bindVars[string(expr)]
+		if !found {
+			return false, false
+		}
+		value, err := sqltypes.BindVariableToValue(bv)
+		if err != nil {
+			return false, false
+		}
+		return true, value.ToString() == te.tenant
+	}
+	return false, false
+}
+
+// literal returns the literal of the tenant of the user.
+func (te *tenantEnforcer) literal() *sqlparser.Literal {
+	if _, err := strconv.ParseInt(te.tenant, 10, 64); err == nil {
+		return sqlparser.NewIntLiteral(te.tenant)
+	}
+	return sqlparser.NewStrLiteral(te.tenant)
+}
+
+// augmentable returns the rejected error if the statements that lack the
+// predicate on the tenant column of the table are rejected.
+func (te *tenantEnforcer) augmentable(table *vindexes.Table, rejected error) error {
+	if !table.Keyspace.AugmentsTenantPredicates() {
+		tenantPredicates.Add([]string{table.Keyspace.Name, "Rejected"}, 1)
+		return rejected
+	}
+	tenantPredicates.Add([]string{table.Keyspace.Name, "Augmented"}, 1)
+	te.augmented = true
+	return nil
+}
+
+func (te *tenantEnforcer) otherTenant(table *vindexes.Table) error {
+	tenantPredicates.Add([]string{table.Keyspace.Name, "Rejected"}, 1)
+	return vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "the query accesses the rows of another tenant in table %s", table.Name.String())
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestTenantPredicates(t *testing.T) {
+	executor, _, _, _ := createExecutorEnv()
+	srvVSchema := getSandboxSrvVSchema()
+	tenantColumn := &vschemapb.Column{Name: "tenant_id", Type: sqltypes.Int64, Tenant: true}
+	sharded := srvVSchema.Keyspaces[KsTestSharded]
+	sharded.TenantPredicate = vschemapb.Keyspace_reject
+	sharded.Tables["user"].Columns = append(sharded.Tables["user"].Columns, tenantColumn)
+	sharded.Tables["user_extra"].Columns = append(sharded.Tables["user_extra"].Columns, tenantColumn)
+	unsharded := srvVSchema.Keyspaces[KsTestUnsharded]
+	unsharded.TenantPredicate = vschemapb.Keyspace_augment
+	unsharded.Tables["simple"].Columns = append(unsharded.Tables["simple"].Columns, tenantColumn)
+	vschema := vindexes.BuildVSchema(srvVSchema)
+	require.NoError(t, vschema.Keyspaces[KsTestSharded].Error)
+
+	enforce := func(ctx context.Context, sql string, bindVars map[string]*querypb.BindVariable) (string, error) {
+		vc, err := newVCursorImpl(ctx, NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), makeComments(""), executor, nil, executor.vm, vschema, executor.resolver.resolver, nil, false, querypb.ExecuteOptions_Gen4)
+		require.NoError(t, err)
+		stmt, err := sqlparser.Parse(sql)
+		require.NoError(t, err)
+		augmented, err := enforceTenantPredicates(vc, stmt, bindVars)
+		if err != nil {
+			return "", err
+		}
+		assert.Equal(t, augmented, sqlparser.String(stmt) != sql, sql)
+		return sqlparser.String(stmt), nil
+	}
+
+	tenant := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "app", Groups: []string{"apps", "tenant:42"}})
+	tcases := []struct {
+		query string
+		want  string
+		err   string
+	}{{
+		query: "select * from `user` where tenant_id = 42 and id = 1",
+	}, {
+		query: "select * from `user` as u join user_extra as e on u.id = e.user_id and e.tenant_id = 42 where u.tenant_id = :tenant",
+	}, {
+		query: "insert into `user`(id, tenant_id) values (1, 42), (2, :tenant) on duplicate key update tenant_id = 42",
+	}, {
+		query: "select * from `user` where id = 1",
+		err:   "the query lacks the equality predicate on the tenant column tenant_id of table user",
+	}, {
+		query: "select * from `user` where tenant_id = 42 or id = 1",
+		err:   "the query lacks the equality predicate on the tenant column tenant_id of table user",
+	}, {
+		query: "select * from `user` where tenant_id = 43",
+		err:   "the query accesses the rows of another tenant in table user",
+	}, {
+		query: "select * from `user` as u join user_extra as e where u.tenant_id = 42 and tenant_id = 42",
+		err:   "the query lacks the equality predicate on the tenant column tenant_id of table user_extra",
+	}, {
+		query: "select * from `user` where tenant_id = 42 and id in (select user_id from user_extra)",
+		err:   "the query lacks the equality predicate on the tenant column tenant_id of table user_extra",
+	}, {
+		query: "update `user` set tenant_id = 43 where tenant_id = 42",
+		err:   "the query changes the tenant of the rows of table user",
+	}, {
+		query: "insert into `user`(id) values (1)",
+		err:   "the query lacks the value of the tenant column tenant_id of table user",
+	}, {
+		query: "insert into `user`(id, tenant_id) values (1, 43)",
+		err:   "the query accesses the rows of another tenant in table user",
+	}, {
+		query: "select * from unknown_table where tenant_id = 42",
+		err:   "table unknown_table not found",
+	}, {
+		query: "insert into unknown_table(id, tenant_id) values (1, 42)",
+		err:   "table unknown_table not found",
+	}, {
+		query: "call proc()",
+		err:   "tenant-scoped users cannot call stored procedures",
+	}, {
+		query: "select * from information_schema.`tables`",
+	}, {
+		query: "select * from simple where id = 1",
+		want:  "select * from `simple` where id = 1 and `simple`.tenant_id = 42",
+	}, {
+		query: "select * from `user` as u left join simple as s on u.id = s.id where u.tenant_id = 42",
+		want:  "select * from `user` as u left join `simple` as s on u.id = s.id and s.tenant_id = 42 where u.tenant_id = 42",
+	}, {
+		query: "delete from simple",
+		want:  "delete from `simple` where `simple`.tenant_id = 42",
+	}, {
+		query: "insert into simple(id) values (1), (2)",
+		want:  "insert into `simple`(id, tenant_id) values (1, 42), (2, 42)",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			got, err := enforce(tenant, tcase.query, map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(42)})
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			want := tcase.want
+			if want == "" {
+				want = tcase.query
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	// The users that aren't scoped to a tenant are not restricted.
+	other := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "admin", Groups: []string{"admins"}})
+	got, err := enforce(other, "select * from `user`", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "select * from `user`", got)
+
+	several := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "app", Groups: []string{"tenant:42", "tenant:43"}})
+	_, err = enforce(several, "select * from `user`", nil)
+	require.EqualError(t, err, "user app is scoped to several tenants")
+}
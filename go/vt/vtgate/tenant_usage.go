@@ -0,0 +1,360 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	tenantUsageSink           = flag.String("tenant_usage_sink", "", "The sink the usage of each tenant is exported to, for chargeback: 'log', 'file:///path/to/usage.jsonl' or an 'http://' or 'https://' URL the usage records are posted to as a JSON array. The tenant of a query is the tenant of its user, see --tenant_group_prefix, or else its TENANT directive, e.g. /*vt+ TENANT=acme */. Empty disables the usage accounting")
+	tenantUsageExportInterval = flag.Duration("tenant_usage_export_interval", time.Minute, "How often the usage of each tenant is exported to --tenant_usage_sink")
+	tenantUsageMaxTenants     = flag.Int("tenant_usage_max_tenants", 10000, "The maximum number of tenants whose usage is accounted for in an export interval. The queries of other tenants are not accounted for until the next interval")
+
+	tenantUsageQueries = stats.NewCountersWithSingleLabel("TenantUsageQueries", "Number of queries accounted for in the usage of the tenants, by tenant", "Tenant")
+	tenantUsageDropped = stats.NewCounter("TenantUsageDropped", "Number of queries not accounted for in the usage of the tenants, because too many tenants were accounted for or because their records could not be exported")
+	tenantUsageExports = stats.NewCountersWithSingleLabel("TenantUsageExports", "Number of exports of the usage records of the tenants, by result", "Result")
+)
+
+const (
+	// tenantUsageHTTPTimeout is the timeout of the requests of the http sink.
+	tenantUsageHTTPTimeout = 30 * time.Second
+	// tenantUsageMaxPending is the number of export intervals whose records
+	// are kept while the sink fails, before they are dropped.
+	tenantUsageMaxPending = 10
+)
+
+// TenantUsage is the usage of a tenant over an export interval.
+type TenantUsage struct {
+	Tenant string
+	Start  time.Time
+	End    time.Time
+	// Queries is the number of queries of the tenant, and Errors the number
+	// of them that failed.
+	Queries uint64
+	Errors  uint64
+	// RowsRead is the number of rows returned to the tenant, and RowsWritten
+	// the number of rows affected by its DMLs.
+	RowsRead    uint64
+	RowsWritten uint64
+	// BytesReturned is the size of the values of the rows returned to the
+	// tenant.
+	BytesReturned uint64
+}
+
+// TenantUsageSink is where the usage records of the tenants are exported.
+type TenantUsageSink interface {
+	// Export exports the usage records of an interval, or of several
+	// intervals if the previous exports failed.
+	Export(ctx context.Context, usage []*TenantUsage) error
+}
+
+// TenantUsageSinkFactory creates a TenantUsageSink from its URL.
+type TenantUsageSinkFactory func(u *url.URL) (TenantUsageSink, error)
+
+var tenantUsageSinks = make(map[string]TenantUsageSinkFactory)
+
+// RegisterTenantUsageSink registers the factory of the sinks of a URL
+// scheme, for --tenant_usage_sink.
+func RegisterTenantUsageSink(scheme string, factory TenantUsageSinkFactory) {
+	if _, ok := tenantUsageSinks[scheme]; ok {
+		log.Fatalf("tenant usage sink %s already registered", scheme)
+	}
+	tenantUsageSinks[scheme] = factory
+}
+
+func init() {
+	RegisterTenantUsageSink("log", func(*url.URL) (TenantUsageSink, error) {
+		return logTenantUsageSink{}, nil
+	})
+	RegisterTenantUsageSink("file", func(u *url.URL) (TenantUsageSink, error) {
+		if u.Path == "" {
+			return nil, fmt.Errorf("the file tenant usage sink needs a path: %s", u)
+		}
+		return &fileTenantUsageSink{path: u.Path}, nil
+	})
+	httpSink := func(u *url.URL) (TenantUsageSink, error) {
+		return &httpTenantUsageSink{url: u.String(), client: &http.Client{Timeout: tenantUsageHTTPTimeout}}, nil
+	}
+	RegisterTenantUsageSink("http", httpSink)
+	RegisterTenantUsageSink("https", httpSink)
+}
+
+// newTenantUsageSink creates the sink of a --tenant_usage_sink value.
+func newTenantUsageSink(sink string) (TenantUsageSink, error) {
+	u, err := url.Parse(sink)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant usage sink %q: %v", sink, err)
+	}
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = u.Path
+	}
+	factory, ok := tenantUsageSinks[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant usage sink %q", sink)
+	}
+	return factory(u)
+}
+
+// logTenantUsageSink logs the usage records.
+type logTenantUsageSink struct{}
+
+func (logTenantUsageSink) Export(ctx context.Context, usage []*TenantUsage) error {
+	for _, u := range usage {
+		log.Infof("Tenant usage: tenant %s from %v to %v: %d queries, %d errors, %d rows read, %d rows written, %d bytes returned",
+			u.Tenant, u.Start.Format(time.RFC3339), u.End.Format(time.RFC3339), u.Queries, u.Errors, u.RowsRead, u.RowsWritten, u.BytesReturned)
+	}
+	return nil
+}
+
+// fileTenantUsageSink appends the usage records to a file, one JSON object
+// per line.
+type fileTenantUsageSink struct {
+	path string
+}
+
+func (s *fileTenantUsageSink) Export(ctx context.Context, usage []*TenantUsage) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, u := range usage {
+		if err := enc.Encode(u); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// httpTenantUsageSink posts the usage records to a URL, as a JSON array.
+type httpTenantUsageSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpTenantUsageSink) Export(ctx context.Context, usage []*TenantUsage) error {
+	body, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("the tenant usage sink returned %s", resp.Status)
+	}
+	return nil
+}
+
+// tenantUsageAccountant aggregates the usage of each tenant over an export
+// interval, and exports the usage records to its sink at the end of each
+// interval. The records that could not be exported are exported with the
+// records of the next intervals.
+type tenantUsageAccountant struct {
+	sink       TenantUsageSink
+	maxTenants int
+
+	// now is replaced in tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	start   time.Time
+	tenants map[string]*TenantUsage
+
+	// exportMu serializes the exports. pending are the records of the
+	// previous intervals that could not be exported.
+	exportMu sync.Mutex
+	pending  [][]*TenantUsage
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTenantUsageAccountantFromFlags returns the accountant configured by the
+// flags, or nil if the usage accounting is disabled.
+func newTenantUsageAccountantFromFlags() *tenantUsageAccountant {
+	if *tenantUsageSink == "" {
+		return nil
+	}
+	sink, err := newTenantUsageSink(*tenantUsageSink)
+	if err != nil {
+		log.Exitf("--tenant_usage_sink: %v", err)
+	}
+	a := newTenantUsageAccountant(sink, *tenantUsageMaxTenants)
+	a.run(*tenantUsageExportInterval)
+	servenv.OnTermSync(a.close)
+	return a
+}
+
+func newTenantUsageAccountant(sink TenantUsageSink, maxTenants int) *tenantUsageAccountant {
+	return &tenantUsageAccountant{
+		sink:       sink,
+		maxTenants: maxTenants,
+		now:        time.Now,
+		start:      time.Now(),
+		tenants:    make(map[string]*TenantUsage),
+		done:       make(chan struct{}),
+	}
+}
+
+// Record accounts for a query in the usage of its tenant. The tenant of the
+// query is the tenant of its user or else its TENANT directive, and the
+// queries without a tenant are not accounted for.
+func (a *tenantUsageAccountant) Record(logStats *LogStats, rowsRead, rowsWritten, bytesReturned uint64) {
+	if a == nil {
+		return
+	}
+	tenant, err := tenantOf(logStats.Ctx)
+	if err != nil || tenant == "" {
+		tenant = logStats.tenant
+	}
+	if tenant == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u, ok := a.tenants[tenant]
+	if !ok {
+		if len(a.tenants) >= a.maxTenants {
+			tenantUsageDropped.Add(1)
+			return
+		}
+		u = &TenantUsage{Tenant: tenant}
+		a.tenants[tenant] = u
+	}
+	u.Queries++
+	if logStats.Error != nil {
+		u.Errors++
+	}
+	u.RowsRead += rowsRead
+	u.RowsWritten += rowsWritten
+	u.BytesReturned += bytesReturned
+	tenantUsageQueries.Add(tenant, 1)
+}
+
+// run exports the usage records at each interval, until close is called.
+func (a *tenantUsageAccountant) run(interval time.Duration) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.export(context.Background())
+			case <-a.done:
+				return
+			}
+		}
+	}()
+}
+
+// close stops the periodic exports, and exports the usage of the current
+// interval.
+func (a *tenantUsageAccountant) close() {
+	close(a.done)
+	a.wg.Wait()
+	a.export(context.Background())
+}
+
+// export ends the current interval, and exports its usage records with the
+// ones of the previous intervals that could not be exported.
+func (a *tenantUsageAccountant) export(ctx context.Context) {
+	a.mu.Lock()
+	now := a.now()
+	usage := make([]*TenantUsage, 0, len(a.tenants))
+	for _, u := range a.tenants {
+		u.Start = a.start
+		u.End = now
+		usage = append(usage, u)
+	}
+	a.start = now
+	a.tenants = make(map[string]*TenantUsage)
+	a.mu.Unlock()
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Tenant < usage[j].Tenant })
+
+	a.exportMu.Lock()
+	defer a.exportMu.Unlock()
+	if len(usage) > 0 {
+		a.pending = append(a.pending, usage)
+	}
+	if len(a.pending) == 0 {
+		return
+	}
+	var records []*TenantUsage
+	for _, p := range a.pending {
+		records = append(records, p...)
+	}
+	if err := a.sink.Export(ctx, records); err != nil {
+		tenantUsageExports.Add("Error", 1)
+		log.Errorf("Cannot export the usage of the tenants: %v", err)
+		if len(a.pending) > tenantUsageMaxPending {
+			for _, u := range a.pending[0] {
+				tenantUsageDropped.Add(int64(u.Queries))
+			}
+			a.pending = a.pending[1:]
+		}
+		return
+	}
+	tenantUsageExports.Add("Success", 1)
+	a.pending = nil
+}
+
+// resultBytes returns the size of the values of the rows of a result.
+func resultBytes(qr *sqltypes.Result) uint64 {
+	if qr == nil {
+		return 0
+	}
+	var n uint64
+	for _, row := range qr.Rows {
+		for _, v := range row {
+			n += uint64(v.Len())
+		}
+	}
+	return n
+}
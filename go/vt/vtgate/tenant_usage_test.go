@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+type fakeTenantUsageSink struct {
+	mu     sync.Mutex
+	err    error
+	usage  [][]*TenantUsage
+	failed int
+}
+
+func (s *fakeTenantUsageSink) Export(ctx context.Context, usage []*TenantUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		s.failed++
+		return s.err
+	}
+	s.usage = append(s.usage, usage)
+	return nil
+}
+
+func TestTenantUsageAccountant(t *testing.T) {
+	sink := &fakeTenantUsageSink{}
+	a := newTenantUsageAccountant(sink, 2)
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	a.start = now
+	a.now = func() time.Time { return now }
+
+	tenantCtx := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "app", Groups: []string{"tenant:42"}})
+	a.Record(&LogStats{Ctx: tenantCtx}, 3, 0, 30)
+	a.Record(&LogStats{Ctx: tenantCtx, tenant: "acme"}, 0, 2, 0)
+	a.Record(&LogStats{Ctx: tenantCtx, Error: errors.New("failed")}, 0, 0, 0)
+	// The TENANT directive is used for the users that aren't scoped to a tenant.
+	a.Record(&LogStats{Ctx: context.Background(), tenant: "acme"}, 1, 0, 5)
+	// The queries without a tenant are not accounted for.
+	a.Record(&LogStats{Ctx: context.Background()}, 1, 0, 5)
+	// Neither are the queries of too many tenants.
+	dropped := tenantUsageDropped.Get()
+	a.Record(&LogStats{Ctx: context.Background(), tenant: "other"}, 1, 0, 5)
+	assert.Equal(t, dropped+1, tenantUsageDropped.Get())
+
+	now = now.Add(time.Minute)
+	a.export(context.Background())
+	require.Len(t, sink.usage, 1)
+	assert.Equal(t, []*TenantUsage{{
+		Tenant:        "42",
+		Start:         now.Add(-time.Minute),
+		End:           now,
+		Queries:       3,
+		Errors:        1,
+		RowsRead:      3,
+		RowsWritten:   2,
+		BytesReturned: 30,
+	}, {
+		Tenant:        "acme",
+		Start:         now.Add(-time.Minute),
+		End:           now,
+		Queries:       1,
+		RowsRead:      1,
+		BytesReturned: 5,
+	}}, sink.usage[0])
+
+	// Nothing is exported for an interval without queries.
+	now = now.Add(time.Minute)
+	a.export(context.Background())
+	require.Len(t, sink.usage, 1)
+
+	// The records that could not be exported are exported with the next ones.
+	sink.err = errors.New("unavailable")
+	a.Record(&LogStats{Ctx: context.Background(), tenant: "acme"}, 1, 0, 5)
+	now = now.Add(time.Minute)
+	a.export(context.Background())
+	assert.Equal(t, 1, sink.failed)
+	sink.err = nil
+	a.Record(&LogStats{Ctx: context.Background(), tenant: "acme"}, 2, 0, 10)
+	now = now.Add(time.Minute)
+	a.export(context.Background())
+	require.Len(t, sink.usage, 2)
+	require.Len(t, sink.usage[1], 2)
+	assert.Equal(t, uint64(1), sink.usage[1][0].RowsRead)
+	assert.Equal(t, now.Add(-time.Minute), sink.usage[1][0].End)
+	assert.Equal(t, uint64(2), sink.usage[1][1].RowsRead)
+	assert.Equal(t, now, sink.usage[1][1].End)
+}
+
+func TestTenantUsageSinks(t *testing.T) {
+	file := path.Join(t.TempDir(), "usage.jsonl")
+	sink, err := newTenantUsageSink("file://" + file)
+	require.NoError(t, err)
+	usage := []*TenantUsage{{Tenant: "42", Queries: 1}, {Tenant: "acme", Queries: 2}}
+	require.NoError(t, sink.Export(context.Background(), usage))
+	require.NoError(t, sink.Export(context.Background(), usage[:1]))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	var got TenantUsage
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &got))
+	assert.Equal(t, *usage[1], got)
+
+	var posted []*TenantUsage
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sink, err = newTenantUsageSink(server.URL + "/usage")
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(context.Background(), usage))
+	assert.Equal(t, usage, posted)
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, sink.Export(context.Background(), usage), "the tenant usage sink returned 503 Service Unavailable")
+
+	sink, err = newTenantUsageSink("log")
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(context.Background(), usage))
+
+	_, err = newTenantUsageSink("kafka://localhost:9092")
+	assert.EqualError(t, err, `unknown tenant usage sink "kafka://localhost:9092"`)
+}
+
+func TestExecutorTenantUsage(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+	sink := &fakeTenantUsageSink{}
+	executor.tenantUsage = newTenantUsageAccountant(sink, 10)
+
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|name", "int64|varchar"), "1|abc", "2|de")})
+	_, err := executor.Execute(context.Background(), "TestExecutorTenantUsage", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), "select /*vt+ TENANT=acme */ id, name from user where id = 1", nil)
+	require.NoError(t, err)
+
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|name", "int64|varchar"), "3|f")})
+	err = executor.StreamExecute(context.Background(), "TestExecutorTenantUsage", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), "select /*vt+ TENANT=acme */ id, name from user where id = 1", nil, func(*sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = executor.Execute(context.Background(), "TestExecutorTenantUsage", NewSafeSession(&vtgatepb.Session{TargetString: "@primary"}), "select id from user where id = 1", nil)
+	require.NoError(t, err)
+
+	executor.tenantUsage.export(context.Background())
+	require.Len(t, sink.usage, 1)
+	require.Len(t, sink.usage[0], 1)
+	usage := sink.usage[0][0]
+	assert.Equal(t, "acme", usage.Tenant)
+	assert.Equal(t, uint64(2), usage.Queries)
+	assert.Equal(t, uint64(3), usage.RowsRead)
+	assert.Equal(t, uint64(9), usage.BytesReturned)
+}
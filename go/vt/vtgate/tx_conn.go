@@ -18,6 +18,7 @@ package vtgate
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -26,6 +27,7 @@ import (
 
 	"context"
 
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/dtids"
 	"vitess.io/vitess/go/vt/log"
@@ -35,6 +37,16 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 )
 
+// transactionsResolved counts the distributed transactions resolved by
+// ResolveTransaction, by the decision found in the metadata manager.
+var transactionsResolved = stats.NewCountersWithSingleLabel("TransactionsResolved", "Distributed transactions resolved, by decision", "Decision")
+
+// crossKeyspaceTransactions counts the transactions that span more than one
+// keyspace, by their sorted keyspaces and by whether they were committed or
+// rejected by the SINGLE transaction mode. The keyspaces that are often
+// written together are candidates for consolidation.
+var crossKeyspaceTransactions = stats.NewCountersWithMultiLabels("CrossKeyspaceTransactions", "Transactions across more than one keyspace, by keyspaces and outcome", []string{"Keyspaces", "Outcome"})
+
 // TxConn is used for executing transactional requests.
 type TxConn struct {
 	tabletGateway *TabletGateway
@@ -61,6 +73,18 @@ func (txc *TxConn) Begin(ctx context.Context, session *SafeSession) error {
 	return nil
 }
 
+// twoPC returns true if the transaction of the session is committed with
+// two-phase commit, as set by the session or by default.
+func (txc *TxConn) twoPC(session *SafeSession) bool {
+	switch session.TransactionMode {
+	case vtgatepb.TransactionMode_TWOPC:
+		return true
+	case vtgatepb.TransactionMode_UNSPECIFIED:
+		return txc.mode == vtgatepb.TransactionMode_TWOPC
+	}
+	return false
+}
+
 // Commit commits the current transaction. The type of commit can be
 // best effort or 2pc depending on the session setting.
 func (txc *TxConn) Commit(ctx context.Context, session *SafeSession) error {
@@ -69,17 +93,17 @@ func (txc *TxConn) Commit(ctx context.Context, session *SafeSession) error {
 		return nil
 	}
 
-	twopc := false
-	switch session.TransactionMode {
-	case vtgatepb.TransactionMode_TWOPC:
-		twopc = true
-	case vtgatepb.TransactionMode_UNSPECIFIED:
-		twopc = txc.mode == vtgatepb.TransactionMode_TWOPC
+	keyspaces := session.Keyspaces()
+	var err error
+	if txc.twoPC(session) {
+		err = txc.commit2PC(ctx, session)
+	} else {
+		err = txc.commitNormal(ctx, session)
 	}
-	if twopc {
-		return txc.commit2PC(ctx, session)
+	if err == nil && len(keyspaces) > 1 {
+		crossKeyspaceTransactions.Add([]string{strings.Join(keyspaces, ","), "Committed"}, 1)
 	}
-	return txc.commitNormal(ctx, session)
+	return err
 }
 
 func (txc *TxConn) queryService(alias *topodatapb.TabletAlias) (queryservice.QueryService, error) {
@@ -308,10 +332,12 @@ func (txc *TxConn) Resolve(ctx context.Context, dtid string) error {
 
 	transaction, err := txc.tabletGateway.ReadTransaction(ctx, mmShard.Target, dtid)
 	if err != nil {
+		transactionsResolved.Add("Error", 1)
 		return err
 	}
 	if transaction == nil || transaction.Dtid == "" {
 		// It was already resolved.
+		transactionsResolved.Add("NotFound", 1)
 		return nil
 	}
 	switch transaction.State {
@@ -320,22 +346,29 @@ func (txc *TxConn) Resolve(ctx context.Context, dtid string) error {
 		// fallthrough to the rollback workflow.
 		qs, err := txc.queryService(mmShard.TabletAlias)
 		if err != nil {
+			transactionsResolved.Add("Error", 1)
 			return err
 		}
 		if err := qs.SetRollback(ctx, mmShard.Target, transaction.Dtid, mmShard.TransactionId); err != nil {
+			transactionsResolved.Add("Error", 1)
 			return err
 		}
 		fallthrough
 	case querypb.TransactionState_ROLLBACK:
 		if err := txc.resumeRollback(ctx, mmShard.Target, transaction); err != nil {
+			transactionsResolved.Add("Error", 1)
 			return err
 		}
+		transactionsResolved.Add("Rollback", 1)
 	case querypb.TransactionState_COMMIT:
 		if err := txc.resumeCommit(ctx, mmShard.Target, transaction); err != nil {
+			transactionsResolved.Add("Error", 1)
 			return err
 		}
+		transactionsResolved.Add("Commit", 1)
 	default:
 		// Should never happen.
+		transactionsResolved.Add("Error", 1)
 		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid state: %v", transaction.State)
 	}
 	return nil
@@ -113,6 +113,40 @@ func TestTxConnCommitSuccess(t *testing.T) {
 	assert.EqualValues(t, 1, sbc1.CommitCount.Get(), "sbc1.CommitCount")
 }
 
+func TestTxConnCommitCrossKeyspace(t *testing.T) {
+	createSandbox("TestTxConnCrossKeyspace1")
+	createSandbox("TestTxConnCrossKeyspace2")
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(hc, new(sandboxTopo), "aa")
+	hc.AddTestTablet("aa", "0", 1, "TestTxConnCrossKeyspace1", "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	hc.AddTestTablet("aa", "1", 1, "TestTxConnCrossKeyspace2", "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	res := srvtopo.NewResolver(&sandboxTopo{}, sc.gateway, "aa")
+	rss1, err := res.ResolveDestination(ctx, "TestTxConnCrossKeyspace1", topodatapb.TabletType_PRIMARY, key.DestinationShard("0"))
+	require.NoError(t, err)
+	rss2, err := res.ResolveDestination(ctx, "TestTxConnCrossKeyspace2", topodatapb.TabletType_PRIMARY, key.DestinationShard("0"))
+	require.NoError(t, err)
+	label := "TestTxConnCrossKeyspace1,TestTxConnCrossKeyspace2"
+
+	// The SINGLE transaction mode rejects the transaction, and names its keyspaces.
+	sc.txConn.mode = vtgatepb.TransactionMode_SINGLE
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	_, errs := sc.ExecuteMultiShard(ctx, rss1, queries, session, false, false)
+	require.Empty(t, errs)
+	_, errs = sc.ExecuteMultiShard(ctx, rss2, queries, session, false, false)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "multi-db transaction attempted: keyspaces [TestTxConnCrossKeyspace1 TestTxConnCrossKeyspace2], shards [TestTxConnCrossKeyspace1/0 TestTxConnCrossKeyspace2/0]; the transaction was rolled back, set transaction_mode = 'multi' in the session to allow transactions across shards")
+	assert.EqualValues(t, 1, crossKeyspaceTransactions.Counts()[label+".Rejected"])
+
+	// The session can allow it.
+	session = NewSafeSession(&vtgatepb.Session{InTransaction: true, TransactionMode: vtgatepb.TransactionMode_MULTI})
+	_, errs = sc.ExecuteMultiShard(ctx, rss1, queries, session, false, false)
+	require.Empty(t, errs)
+	_, errs = sc.ExecuteMultiShard(ctx, rss2, queries, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Commit(ctx, session))
+	assert.EqualValues(t, 1, crossKeyspaceTransactions.Counts()[label+".Committed"])
+}
+
 func TestTxConnReservedCommitSuccess(t *testing.T) {
 	sc, sbc0, sbc1, rss0, _, rss01 := newTestTxConnEnv(t, "TestTxConn")
 	sc.txConn.mode = vtgatepb.TransactionMode_MULTI
@@ -971,12 +1005,28 @@ func TestTxConnResolveOnCommit(t *testing.T) {
 			TabletType: topodatapb.TabletType_PRIMARY,
 		}},
 	}}
+	commits := transactionsResolved.Counts()["Commit"]
 	require.NoError(t,
 		sc.txConn.Resolve(ctx, dtid))
 	assert.EqualValues(t, 0, sbc0.SetRollbackCount.Get(), "sbc0.SetRollbackCount")
 	assert.EqualValues(t, 0, sbc1.RollbackPreparedCount.Get(), "sbc1.RollbackPreparedCount")
 	assert.EqualValues(t, 1, sbc1.CommitPreparedCount.Get(), "sbc1.CommitPreparedCount")
 	assert.EqualValues(t, 1, sbc0.ConcludeTransactionCount.Get(), "sbc0.ConcludeTransactionCount")
+	assert.EqualValues(t, commits+1, transactionsResolved.Counts()["Commit"], "TransactionsResolved")
+}
+
+func TestTxConnResolveNotFound(t *testing.T) {
+	sc, sbc0, sbc1, _, _, _ := newTestTxConnEnv(t, "TestTxConn")
+
+	dtid := "TestTxConn:0:1234"
+	sbc0.ReadTransactionResults = []*querypb.TransactionMetadata{{}}
+	notFound := transactionsResolved.Counts()["NotFound"]
+	require.NoError(t,
+		sc.txConn.Resolve(ctx, dtid))
+	assert.EqualValues(t, 0, sbc1.RollbackPreparedCount.Get(), "sbc1.RollbackPreparedCount")
+	assert.EqualValues(t, 0, sbc1.CommitPreparedCount.Get(), "sbc1.CommitPreparedCount")
+	assert.EqualValues(t, 0, sbc0.ConcludeTransactionCount.Get(), "sbc0.ConcludeTransactionCount")
+	assert.EqualValues(t, notFound+1, transactionsResolved.Counts()["NotFound"], "TransactionsResolved")
 }
 
 func TestTxConnResolveInvalidDTID(t *testing.T) {
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var (
+	mysqlUserLimitsRefreshInterval = flag.Duration("mysql_user_limits_refresh_interval", 30*time.Second, "How often vtgate reads the limits of the MySQL users from the topo, see SetMySQLUserLimits. 0 only applies the limits of the auth server, e.g. MaxConnections and MaxConcurrentQueries in the entries of --mysql_auth_server_static_file")
+
+	userLimitRejections = stats.NewCountersWithMultiLabels("MysqlServerUserLimitRejections", "Number of connections and queries refused because their MySQL user reached its limits, by user and limit", []string{"User", "Limit"})
+)
+
+const (
+	// userLimitConnections and userLimitConcurrentQueries are the limits of
+	// the MysqlServerUserLimitRejections metric.
+	userLimitConnections       = "MaxConnections"
+	userLimitConcurrentQueries = "MaxConcurrentQueries"
+)
+
+// userLimiter limits the connections and the concurrent queries of each
+// MySQL user. The limits of a user come from the topo, or else from the
+// auth server if it provides some.
+type userLimiter struct {
+	authLimits mysql.UserLimitsProvider
+
+	mu         sync.Mutex
+	topoLimits map[string]*topo.MySQLUserLimits
+	// connections and queries are the current numbers of connections and of
+	// queries of each user.
+	connections map[string]int
+	queries     map[string]int
+
+	cancel context.CancelFunc
+}
+
+func newUserLimiter(authServer mysql.AuthServer) *userLimiter {
+	ul := &userLimiter{
+		connections: make(map[string]int),
+		queries:     make(map[string]int),
+	}
+	ul.authLimits, _ = authServer.(mysql.UserLimitsProvider)
+	return ul
+}
+
+// limits returns the limits of a user.
+func (ul *userLimiter) limits(user string) mysql.UserLimits {
+	var limits mysql.UserLimits
+	if ul.authLimits != nil {
+		limits = ul.authLimits.UserLimits(user)
+	}
+	ul.mu.Lock()
+	topoLimits := ul.topoLimits[user]
+	ul.mu.Unlock()
+	if topoLimits != nil {
+		if topoLimits.MaxConnections != 0 {
+			limits.MaxConnections = topoLimits.MaxConnections
+		}
+		if topoLimits.MaxConcurrentQueries != 0 {
+			limits.MaxConcurrentQueries = topoLimits.MaxConcurrentQueries
+		}
+	}
+	return limits
+}
+
+// acquireConnection counts a new connection of a user, unless the user
+// reached its maximum number of connections.
+func (ul *userLimiter) acquireConnection(user string) error {
+	return ul.acquire(user, ul.connections, ul.limits(user).MaxConnections, userLimitConnections)
+}
+
+// releaseConnection is called when a connection acquired by
+// acquireConnection is closed.
+func (ul *userLimiter) releaseConnection(user string) {
+	ul.release(user, ul.connections)
+}
+
+// acquireQuery counts a new query of a user, unless the user reached its
+// maximum number of concurrent queries.
+func (ul *userLimiter) acquireQuery(user string) error {
+	return ul.acquire(user, ul.queries, ul.limits(user).MaxConcurrentQueries, userLimitConcurrentQueries)
+}
+
+// releaseQuery is called when a query acquired by acquireQuery ends.
+func (ul *userLimiter) releaseQuery(user string) {
+	ul.release(user, ul.queries)
+}
+
+func (ul *userLimiter) acquire(user string, counts map[string]int, limit int, limitName string) error {
+	ul.mu.Lock()
+	defer ul.mu.Unlock()
+	if limit > 0 && counts[user] >= limit {
+		userLimitRejections.Add([]string{user, limitName}, 1)
+		if limitName == userLimitConnections {
+			return mysql.NewTooManyUserConnectionsError(user)
+		}
+		return mysql.NewUserLimitReachedError(user, limitName, limit)
+	}
+	counts[user]++
+	return nil
+}
+
+func (ul *userLimiter) release(user string, counts map[string]int) {
+	ul.mu.Lock()
+	defer ul.mu.Unlock()
+	if counts[user] <= 1 {
+		delete(counts, user)
+		return
+	}
+	counts[user]--
+}
+
+// start reads the limits of the users from the topo every
+// --mysql_user_limits_refresh_interval, until stop is called.
+func (ul *userLimiter) start(ts *topo.Server) {
+	if *mysqlUserLimitsRefreshInterval <= 0 || ts == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ul.cancel = cancel
+	go func() {
+		ticker := time.NewTicker(*mysqlUserLimitsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			ul.refresh(ctx, ts)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stop stops reading the limits of the users from the topo.
+func (ul *userLimiter) stop() {
+	if ul.cancel != nil {
+		ul.cancel()
+	}
+}
+
+// refresh reads the limits of the users from the topo. The previous limits
+// are kept if they cannot be read.
+func (ul *userLimiter) refresh(ctx context.Context, ts *topo.Server) {
+	limits, err := ts.GetMySQLUserLimits(ctx)
+	if err != nil {
+		log.Warningf("failed to read the limits of the MySQL users, keeping the previous ones: %v", err)
+		return
+	}
+
+	ul.mu.Lock()
+	defer ul.mu.Unlock()
+	if !reflect.DeepEqual(ul.topoLimits, limits) {
+		log.Infof("the limits of the MySQL users changed: %d users with limits", len(limits))
+		ul.topoLimits = limits
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestUserLimiter(t *testing.T) {
+	authServer := mysql.NewAuthServerStatic("", `{
+		"app": [{"Password": "password1", "MaxConnections": 2, "MaxConcurrentQueries": 1}],
+		"admin": [{"Password": "password2"}]
+	}`, 0)
+	ul := newUserLimiter(authServer)
+
+	require.NoError(t, ul.acquireConnection("app"))
+	require.NoError(t, ul.acquireConnection("app"))
+	err := ul.acquireConnection("app")
+	assert.EqualError(t, err, "User app already has more than 'max_user_connections' active connections (errno 1203) (sqlstate 42000)")
+	ul.releaseConnection("app")
+	require.NoError(t, ul.acquireConnection("app"))
+
+	require.NoError(t, ul.acquireQuery("app"))
+	err = ul.acquireQuery("app")
+	assert.EqualError(t, err, "User 'app' has exceeded the 'MaxConcurrentQueries' resource (current value: 1) (errno 1226) (sqlstate 42000)")
+	ul.releaseQuery("app")
+	require.NoError(t, ul.acquireQuery("app"))
+	ul.releaseQuery("app")
+
+	// The users without limits are not limited.
+	for i := 0; i < 10; i++ {
+		require.NoError(t, ul.acquireConnection("admin"))
+	}
+
+	// The limits in the topo take precedence over the ones of the auth server.
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.SaveMySQLUserLimits(ctx, "app", &topo.MySQLUserLimits{MaxConnections: 3}))
+	require.NoError(t, ts.SaveMySQLUserLimits(ctx, "admin", &topo.MySQLUserLimits{MaxConcurrentQueries: 1}))
+	ul.refresh(ctx, ts)
+	assert.Equal(t, mysql.UserLimits{MaxConnections: 3, MaxConcurrentQueries: 1}, ul.limits("app"))
+	require.NoError(t, ul.acquireConnection("app"))
+	require.Error(t, ul.acquireConnection("app"))
+	require.NoError(t, ul.acquireQuery("admin"))
+	require.Error(t, ul.acquireQuery("admin"))
+}
+
+type limitedTestHandler struct {
+	testHandler
+	limiter *userLimiter
+}
+
+// NewConnection doesn't record the connection, as several are opened
+// concurrently.
+func (th *limitedTestHandler) NewConnection(c *mysql.Conn) {}
+
+func (th *limitedTestHandler) AcquireConnection(c *mysql.Conn) error {
+	return th.limiter.acquireConnection(c.User)
+}
+
+func (th *limitedTestHandler) ReleaseConnection(c *mysql.Conn) {
+	th.limiter.releaseConnection(c.User)
+}
+
+func TestConnectionUserLimits(t *testing.T) {
+	authServer := mysql.NewAuthServerStatic("", `{"user1": [{"Password": "password1", "MaxConnections": 1}]}`, 0)
+	th := &limitedTestHandler{limiter: newUserLimiter(authServer)}
+
+	unixSocket, err := os.CreateTemp("", "mysql_vitess_test.sock")
+	require.NoError(t, err)
+	os.Remove(unixSocket.Name())
+	l, err := newMysqlUnixSocket(unixSocket.Name(), authServer, th)
+	require.NoError(t, err)
+	defer l.Close()
+	go l.Accept()
+
+	params := &mysql.ConnParams{
+		UnixSocket: unixSocket.Name(),
+		Uname:      "user1",
+		Pass:       "password1",
+	}
+	ctx := context.Background()
+	c1, err := mysql.Connect(ctx, params)
+	require.NoError(t, err)
+
+	_, err = mysql.Connect(ctx, params)
+	require.Error(t, err)
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, mysql.ERTooManyUserConnections, sqlErr.Number())
+
+	// The connection is released when it is closed.
+	c1.Close()
+	assert.Eventually(t, func() bool {
+		c2, err := mysql.Connect(ctx, params)
+		if err != nil {
+			return false
+		}
+		c2.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
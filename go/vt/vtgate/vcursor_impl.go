@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
@@ -56,6 +57,8 @@ import (
 )
 
 var _ engine.VCursor = (*vcursorImpl)(nil)
+
+var queryMemoryLimitExceeded = stats.NewCounter("QueryMemoryLimitExceeded", "Number of times the intermediate results of a query exceeded its memory limit, which aborts the query, or spills the rows of its streaming sort to disk")
 var _ plancontext.VSchema = (*vcursorImpl)(nil)
 var _ iExecute = (*Executor)(nil)
 var _ vindexes.VCursor = (*vcursorImpl)(nil)
@@ -67,9 +70,11 @@ type iExecute interface {
 	StreamExecuteMulti(ctx context.Context, query string, rss []*srvtopo.ResolvedShard, vars []map[string]*querypb.BindVariable, session *SafeSession, autocommit bool, callback func(reply *sqltypes.Result) error) []error
 	ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedShard, query *querypb.BoundQuery, session *SafeSession, lockFuncType sqlparser.LockingFuncType) (*sqltypes.Result, error)
 	Commit(ctx context.Context, safeSession *SafeSession) error
+	twoPC(safeSession *SafeSession) bool
 	ExecuteMessageStream(ctx context.Context, rss []*srvtopo.ResolvedShard, name string, callback func(*sqltypes.Result) error) error
 	ExecuteVStream(ctx context.Context, rss []*srvtopo.ResolvedShard, filter *binlogdatapb.Filter, gtid string, callback func(evs []*binlogdatapb.VEvent) error) error
 	ReleaseLock(ctx context.Context, session *SafeSession) error
+	checkThrottler(ctx context.Context, target *querypb.Target, appName string) (bool, error)
 
 	showVitessReplicationStatus(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	showShards(ctx context.Context, filter *sqlparser.ShowFilter, destTabletType topodatapb.TabletType) (*sqltypes.Result, error)
@@ -112,6 +117,16 @@ type vcursorImpl struct {
 
 	warnings []*querypb.QueryWarning // any warnings that are accumulated during the planning phase are stored here
 	pv       plancontext.PlannerVersion
+	// planPin is the plan pin of the query being planned, nil if it is not pinned.
+	planPin *vindexes.PlanPin
+
+	// tracer collects the statistics of the primitives executed by
+	// VEXPLAIN ANALYZE, nil otherwise.
+	tracer *primitiveTracer
+
+	// memoryUsed is the number of bytes of intermediate results held by the
+	// primitives of the query. It is accessed atomically.
+	memoryUsed int64
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -206,13 +221,42 @@ func (vc *vcursorImpl) CancelContext() {
 
 // MaxMemoryRows returns the maxMemoryRows flag value.
 func (vc *vcursorImpl) MaxMemoryRows() int {
-	return *maxMemoryRows
+	return int(maxMemoryRows.Get())
 }
 
 // ExceedsMaxMemoryRows returns a boolean indicating whether the maxMemoryRows value has been exceeded.
 // Returns false if the max memory rows override directive is set to true.
 func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
-	return !vc.ignoreMaxMemoryRows && numRows > *maxMemoryRows
+	return !vc.ignoreMaxMemoryRows && int64(numRows) > maxMemoryRows.Get()
+}
+
+// queryMemoryLimit returns the number of bytes the intermediate results of
+// the query can hold, 0 for no limit. A session can lower --query_memory_limit,
+// or set a limit when the flag sets none, but neither raise nor disable it.
+func (vc *vcursorImpl) queryMemoryLimit() int64 {
+	flagLimit := queryMemoryLimit.Get()
+	switch limit := vc.safeSession.GetQueryMemoryLimit(); {
+	case limit > 0 && (flagLimit <= 0 || limit < flagLimit):
+		return limit
+	case limit < 0 && flagLimit <= 0:
+		return 0
+	}
+	return flagLimit
+}
+
+// ReserveMemory implements the engine.VCursor interface
+func (vc *vcursorImpl) ReserveMemory(size int64) error {
+	used := atomic.AddInt64(&vc.memoryUsed, size)
+	if limit := vc.queryMemoryLimit(); limit > 0 && used > limit {
+		queryMemoryLimitExceeded.Add(1)
+		return engine.QueryMemoryLimitError(limit)
+	}
+	return nil
+}
+
+// ReleaseMemory implements the engine.VCursor interface
+func (vc *vcursorImpl) ReleaseMemory(size int64) {
+	atomic.AddInt64(&vc.memoryUsed, -size)
 }
 
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
@@ -431,8 +475,29 @@ func (vc *vcursorImpl) TargetString() string {
 const MaxBufferingRetries = 3
 
 func (vc *vcursorImpl) ExecutePrimitive(primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vc.tracer != nil {
+		return vc.tracer.executePrimitive(vc, primitive, bindVars, wantfields)
+	}
+	return vc.executePrimitive(vc, primitive, bindVars, wantfields)
+}
+
+// StartPrimitiveTrace is part of the engine.VCursor interface.
+func (vc *vcursorImpl) StartPrimitiveTrace() func() map[engine.Primitive]*engine.PrimitiveStats {
+	tracer := newPrimitiveTracer()
+	vc.tracer = tracer
+	return func() map[engine.Primitive]*engine.PrimitiveStats {
+		vc.tracer = nil
+		tracer.mu.Lock()
+		defer tracer.mu.Unlock()
+		return tracer.stats
+	}
+}
+
+// executePrimitive executes the primitive with the given cursor, which is
+// either vc itself or a cursor that traces the primitive.
+func (vc *vcursorImpl) executePrimitive(cursor engine.VCursor, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	for try := 0; try < MaxBufferingRetries; try++ {
-		res, err := primitive.TryExecute(vc, bindVars, wantfields)
+		res, err := primitive.TryExecute(cursor, bindVars, wantfields)
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
@@ -442,8 +507,15 @@ func (vc *vcursorImpl) ExecutePrimitive(primitive engine.Primitive, bindVars map
 }
 
 func (vc *vcursorImpl) StreamExecutePrimitive(primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	if vc.tracer != nil {
+		return vc.tracer.streamExecutePrimitive(vc, primitive, bindVars, wantfields, callback)
+	}
+	return vc.streamExecutePrimitive(vc, primitive, bindVars, wantfields, callback)
+}
+
+func (vc *vcursorImpl) streamExecutePrimitive(cursor engine.VCursor, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	for try := 0; try < MaxBufferingRetries; try++ {
-		err := primitive.TryStreamExecute(vc, bindVars, wantfields, callback)
+		err := primitive.TryStreamExecute(cursor, bindVars, wantfields, callback)
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
@@ -496,6 +568,10 @@ const txRollback = "Rollback Transaction"
 
 // ExecuteMultiShard is part of the engine.VCursor interface.
 func (vc *vcursorImpl) ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, autocommit bool) (*sqltypes.Result, []error) {
+	return vc.executeMultiShard(vc.ctx, rss, queries, rollbackOnError, autocommit)
+}
+
+func (vc *vcursorImpl) executeMultiShard(ctx context.Context, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, autocommit bool) (*sqltypes.Result, []error) {
 	noOfShards := len(rss)
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
 	err := vc.markSavepoint(rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
@@ -503,7 +579,7 @@ func (vc *vcursorImpl) ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries [
 		return nil, []error{err}
 	}
 
-	qr, errs := vc.executor.ExecuteMultiShard(vc.ctx, rss, commentedShardQueries(queries, vc.marginComments), vc.safeSession, autocommit, vc.ignoreMaxMemoryRows)
+	qr, errs := vc.executor.ExecuteMultiShard(ctx, rss, commentedShardQueries(queries, vc.marginComments), vc.safeSession, autocommit, vc.ignoreMaxMemoryRows)
 	vc.setRollbackOnPartialExecIfRequired(len(errs) != len(rss), rollbackOnError)
 
 	return qr, errs
@@ -511,6 +587,10 @@ func (vc *vcursorImpl) ExecuteMultiShard(rss []*srvtopo.ResolvedShard, queries [
 
 // StreamExecuteMulti is the streaming version of ExecuteMultiShard.
 func (vc *vcursorImpl) StreamExecuteMulti(query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
+	return vc.streamExecuteMulti(vc.ctx, query, rss, bindVars, rollbackOnError, autocommit, callback)
+}
+
+func (vc *vcursorImpl) streamExecuteMulti(ctx context.Context, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
 	noOfShards := len(rss)
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
 	err := vc.markSavepoint(rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
@@ -518,7 +598,7 @@ func (vc *vcursorImpl) StreamExecuteMulti(query string, rss []*srvtopo.ResolvedS
 		return []error{err}
 	}
 
-	errs := vc.executor.StreamExecuteMulti(vc.ctx, vc.marginComments.Leading+query+vc.marginComments.Trailing, rss, bindVars, vc.safeSession, autocommit, callback)
+	errs := vc.executor.StreamExecuteMulti(ctx, vc.marginComments.Leading+query+vc.marginComments.Trailing, rss, bindVars, vc.safeSession, autocommit, callback)
 	vc.setRollbackOnPartialExecIfRequired(len(errs) != len(rss), rollbackOnError)
 
 	return errs
@@ -749,11 +829,21 @@ func (vc *vcursorImpl) SetTransactionMode(mode vtgatepb.TransactionMode) {
 	vc.safeSession.TransactionMode = mode
 }
 
+// TwoPCEnabled implements the SessionActions interface
+func (vc *vcursorImpl) TwoPCEnabled() bool {
+	return vc.executor.twoPC(vc.safeSession)
+}
+
 // SetWorkload implements the SessionActions interface
 func (vc *vcursorImpl) SetWorkload(workload querypb.ExecuteOptions_Workload) {
 	vc.safeSession.GetOrCreateOptions().Workload = workload
 }
 
+// SetPriority implements the SessionActions interface
+func (vc *vcursorImpl) SetPriority(priority string) {
+	vc.safeSession.GetOrCreateOptions().Priority = priority
+}
+
 // SetPlannerVersion implements the SessionActions interface
 func (vc *vcursorImpl) SetPlannerVersion(v plancontext.PlannerVersion) {
 	vc.safeSession.GetOrCreateOptions().PlannerVersion = v
@@ -791,6 +881,17 @@ func (vc *vcursorImpl) GetSessionEnableSystemSettings() bool {
 	return vc.safeSession.GetSessionEnableSystemSettings()
 }
 
+// SetQueryRetry implements the SessionActions interface
+func (vc *vcursorImpl) SetQueryRetry(enabled bool) error {
+	vc.safeSession.SetQueryRetry(enabled)
+	return nil
+}
+
+// SetQueryMemoryLimit implements the SessionActions interface
+func (vc *vcursorImpl) SetQueryMemoryLimit(limit int64) {
+	vc.safeSession.SetQueryMemoryLimit(limit)
+}
+
 // SetReadAfterWriteGTID implements the SessionActions interface
 func (vc *vcursorImpl) SetReadAfterWriteGTID(vtgtid string) {
 	vc.safeSession.SetReadAfterWriteGTID(vtgtid)
@@ -881,6 +982,16 @@ func (vc *vcursorImpl) ForeignKeyMode() string {
 	return strings.ToLower(*foreignKeyMode)
 }
 
+// DMLReturningEnabled implements the VSchema interface
+func (vc *vcursorImpl) DMLReturningEnabled() bool {
+	return *enableDMLReturning
+}
+
+// LockTablesTimeout implements the VSchema interface
+func (vc *vcursorImpl) LockTablesTimeout() time.Duration {
+	return *lockTablesTimeout
+}
+
 // ParseDestinationTarget parses destination target string and sets default keyspace if possible.
 func parseDestinationTarget(targetString string, vschema *vindexes.VSchema) (string, topodatapb.TabletType, key.Destination, error) {
 	destKeyspace, destTabletType, dest, err := topoprotopb.ParseDestination(targetString, defaultTabletType)
@@ -988,6 +1099,11 @@ func (vc *vcursorImpl) GetSrvVschema() *vschemapb.SrvVSchema {
 	return vc.vm.GetCurrentSrvVschema()
 }
 
+// PlanPin implements the VSchema interface
+func (vc *vcursorImpl) PlanPin() *vindexes.PlanPin {
+	return vc.planPin
+}
+
 func (vc *vcursorImpl) SetExec(name string, value string) error {
 	return vc.executor.setVitessMetadata(vc.ctx, name, value)
 }
@@ -999,3 +1115,8 @@ func (vc *vcursorImpl) CanUseSetVar() bool {
 func (vc *vcursorImpl) ReleaseLock() error {
 	return vc.executor.ReleaseLock(vc.ctx, vc.safeSession)
 }
+
+// CheckThrottler is part of the engine.VCursor interface.
+func (vc *vcursorImpl) CheckThrottler(rs *srvtopo.ResolvedShard, appName string) (bool, error) {
+	return vc.executor.checkThrottler(vc.ctx, rs.Target, appName)
+}
@@ -313,3 +313,28 @@ func TestFirstSortedKeyspace(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, ks3Schema.Keyspace, ks)
 }
+
+func TestQueryMemoryLimit(t *testing.T) {
+	save := queryMemoryLimit.Get()
+	defer queryMemoryLimit.value.Set(save)
+
+	tests := []struct {
+		flag, session, want int64
+	}{
+		{flag: 0, session: 0, want: 0},
+		{flag: 0, session: 100, want: 100},
+		{flag: 0, session: -1, want: 0},
+		{flag: 1000, session: 0, want: 1000},
+		{flag: 1000, session: 100, want: 100},
+		// A session can neither raise nor disable the limit of the flag.
+		{flag: 1000, session: 5000, want: 1000},
+		{flag: 1000, session: -1, want: 1000},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("flag %d session %d", tc.flag, tc.session), func(t *testing.T) {
+			queryMemoryLimit.value.Set(tc.flag)
+			vc := &vcursorImpl{safeSession: NewSafeSession(&vtgatepb.Session{QueryMemoryLimit: tc.session})}
+			require.Equal(t, tc.want, vc.queryMemoryLimit())
+		})
+	}
+}
@@ -93,12 +93,14 @@ func (cached *Column) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(64)
+		size += int64(80)
 	}
 	// field Name vitess.io/vitess/go/vt/sqlparser.IdentifierCI
 	size += cached.Name.CachedSize(false)
 	// field CollationName string
 	size += hack.RuntimeAllocSize(int64(len(cached.CollationName)))
+	// field Masking string
+	size += hack.RuntimeAllocSize(int64(len(cached.Masking)))
 	return size
 }
 func (cached *ColumnVindex) CachedSize(alloc bool) int64 {
@@ -150,6 +152,36 @@ func (cached *ConsistentLookupUnique) CachedSize(alloc bool) int64 {
 	size += cached.clCommon.CachedSize(true)
 	return size
 }
+func (cached *ForeignKey) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(112)
+	}
+	// field Name string
+	size += hack.RuntimeAllocSize(int64(len(cached.Name)))
+	// field Table vitess.io/vitess/go/vt/sqlparser.IdentifierCS
+	size += cached.Table.CachedSize(false)
+	// field Columns []vitess.io/vitess/go/vt/sqlparser.IdentifierCI
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(32))
+		for _, elem := range cached.Columns {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field ParentTable vitess.io/vitess/go/vt/sqlparser.IdentifierCS
+	size += cached.ParentTable.CachedSize(false)
+	// field ParentColumns []vitess.io/vitess/go/vt/sqlparser.IdentifierCI
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ParentColumns)) * int64(32))
+		for _, elem := range cached.ParentColumns {
+			size += elem.CachedSize(false)
+		}
+	}
+	return size
+}
 func (cached *Hash) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -168,7 +200,7 @@ func (cached *Keyspace) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(24)
+		size += int64(32)
 	}
 	// field Name string
 	size += hack.RuntimeAllocSize(int64(len(cached.Name)))
@@ -406,7 +438,7 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(176)
+		size += int64(256)
 	}
 	// field Type string
 	size += hack.RuntimeAllocSize(int64(len(cached.Type)))
@@ -439,7 +471,7 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	size += cached.AutoIncrement.CachedSize(true)
 	// field Columns []vitess.io/vitess/go/vt/vtgate/vindexes.Column
 	{
-		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(56))
+		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(80))
 		for _, elem := range cached.Columns {
 			size += elem.CachedSize(false)
 		}
@@ -448,6 +480,34 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	{
 		size += hack.RuntimeAllocSize(int64(cap(cached.Pinned)))
 	}
+	// field ForeignKeys []*vitess.io/vitess/go/vt/vtgate/vindexes.ForeignKey
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ForeignKeys)) * int64(8))
+		for _, elem := range cached.ForeignKeys {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field ChildForeignKeys []*vitess.io/vitess/go/vt/vtgate/vindexes.ForeignKey
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ChildForeignKeys)) * int64(8))
+		for _, elem := range cached.ChildForeignKeys {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field TenantColumn vitess.io/vitess/go/vt/sqlparser.IdentifierCI
+	size += cached.TenantColumn.CachedSize(false)
+	return size
+}
+func (cached *ULID) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
 	return size
 }
 func (cached *UnicodeLooseMD5) CachedSize(alloc bool) int64 {
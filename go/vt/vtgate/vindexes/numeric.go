@@ -20,17 +20,21 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math"
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var (
 	_ SingleColumn = (*Numeric)(nil)
 	_ Reversible   = (*Numeric)(nil)
 	_ Hashing      = (*Numeric)(nil)
+	_ Sequential   = (*Numeric)(nil)
 )
 
 // Numeric defines a bit-pattern mapping of a uint64 to the KeyspaceId.
@@ -91,6 +95,27 @@ func (vind *Numeric) Map(_ VCursor, ids []sqltypes.Value) ([]key.Destination, er
 	return out, nil
 }
 
+// RangeMap maps the ids between start and end, inclusive, to a key range.
+// A bound that is NULL, or that is not an unsigned integer, leaves the range
+// open on that side.
+func (vind *Numeric) RangeMap(_ VCursor, start, end sqltypes.Value) (key.Destination, error) {
+	keyRange := &topodatapb.KeyRange{}
+	if !start.IsNull() {
+		if ksid, err := vind.Hash(start); err == nil {
+			keyRange.Start = ksid
+		}
+	}
+	if !end.IsNull() {
+		// The end of a key range is exclusive.
+		if num, err := evalengine.ToUint64(end); err == nil && num != math.MaxUint64 {
+			var keybytes [8]byte
+			binary.BigEndian.PutUint64(keybytes[:], num+1)
+			keyRange.End = keybytes[:]
+		}
+	}
+	return key.DestinationKeyRange{KeyRange: keyRange}, nil
+}
+
 // ReverseMap returns the associated ids for the ksids.
 func (*Numeric) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
 	var reverseIds = make([]sqltypes.Value, len(ksids))
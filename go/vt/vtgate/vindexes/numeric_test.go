@@ -17,6 +17,7 @@ limitations under the License.
 package vindexes
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -25,6 +26,8 @@ import (
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var numeric SingleColumn
@@ -74,6 +77,38 @@ func TestNumericMap(t *testing.T) {
 	}
 }
 
+func TestNumericRangeMap(t *testing.T) {
+	tcases := []struct {
+		start, end sqltypes.Value
+		want       *topodatapb.KeyRange
+	}{{
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewInt64(2),
+		want: &topodatapb.KeyRange{
+			Start: []byte("\x00\x00\x00\x00\x00\x00\x00\x01"),
+			End:   []byte("\x00\x00\x00\x00\x00\x00\x00\x03"),
+		},
+	}, {
+		start: sqltypes.NewInt64(256),
+		end:   sqltypes.NULL,
+		want:  &topodatapb.KeyRange{Start: []byte("\x00\x00\x00\x00\x00\x00\x01\x00")},
+	}, {
+		start: sqltypes.NULL,
+		end:   sqltypes.NewInt64(255),
+		want:  &topodatapb.KeyRange{End: []byte("\x00\x00\x00\x00\x00\x00\x01\x00")},
+	}, {
+		// Bounds that are not unsigned integers leave the range open.
+		start: sqltypes.NewInt64(-1),
+		end:   sqltypes.NewUint64(math.MaxUint64),
+		want:  &topodatapb.KeyRange{},
+	}}
+	for _, tcase := range tcases {
+		got, err := numeric.(Sequential).RangeMap(nil, tcase.start, tcase.end)
+		require.NoError(t, err)
+		assert.Equal(t, key.DestinationKeyRange{KeyRange: tcase.want}, got, "RangeMap(%v, %v)", tcase.start, tcase.end)
+	}
+}
+
 func TestNumericVerify(t *testing.T) {
 	got, err := numeric.Verify(nil,
 		[]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2)},
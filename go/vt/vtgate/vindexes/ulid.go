@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/ulid"
+	"vitess.io/vitess/go/vt/key"
+)
+
+var (
+	_ SingleColumn = (*ULID)(nil)
+	_ Reversible   = (*ULID)(nil)
+	_ Hashing      = (*ULID)(nil)
+)
+
+// ULID defines a vindex for the columns holding ULIDs, either in their
+// 26-character canonical form, or in their 16-byte binary form.
+// The keyspace id is the ULID with its random part moved before its
+// timestamp, so that the rows are spread over the shards even though the
+// ULIDs are time-ordered.
+// It's Unique, Reversible and Functional. The ids are reverse mapped to
+// their canonical form, or to their binary form if the binary parameter
+// is true.
+type ULID struct {
+	name   string
+	binary bool
+}
+
+// NewULID creates a new ULID.
+func NewULID(name string, m map[string]string) (Vindex, error) {
+	vind := &ULID{name: name}
+	if v, ok := m["binary"]; ok {
+		binary, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("ulid: invalid binary parameter %q: %v", v, err)
+		}
+		vind.binary = binary
+	}
+	return vind, nil
+}
+
+// String returns the name of the vindex.
+func (vind *ULID) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this index as 1.
+func (vind *ULID) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (vind *ULID) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (vind *ULID) NeedsVCursor() bool {
+	return false
+}
+
+// Map can map ids to key.Destination objects.
+func (vind *ULID) Map(_ VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(ids))
+	for _, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(ksid))
+	}
+	return out, nil
+}
+
+// Verify returns true if ids maps to ksids.
+func (vind *ULID) Verify(_ VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+	for i, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bytes.Equal(ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// ReverseMap returns the ids from ksids.
+func (vind *ULID) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
+	reverseIds := make([]sqltypes.Value, 0, len(ksids))
+	for _, keyspaceID := range ksids {
+		if len(keyspaceID) != len(ulid.ULID{}) {
+			return nil, fmt.Errorf("ulid: invalid keyspace id: %x", keyspaceID)
+		}
+		var id ulid.ULID
+		copy(id[:6], keyspaceID[10:])
+		copy(id[6:], keyspaceID[:10])
+		if vind.binary {
+			reverseIds = append(reverseIds, sqltypes.MakeTrusted(sqltypes.VarBinary, id[:]))
+		} else {
+			reverseIds = append(reverseIds, sqltypes.NewVarChar(id.String()))
+		}
+	}
+	return reverseIds, nil
+}
+
+// Hash returns the keyspace id of a ULID: its random part, followed by its
+// timestamp.
+func (vind *ULID) Hash(id sqltypes.Value) ([]byte, error) {
+	var u ulid.ULID
+	var err error
+	if id.IsBinary() || len(id.Raw()) == len(u) {
+		u, err = ulid.FromBytes(id.Raw())
+	} else {
+		u, err = ulid.Parse(id.ToString())
+	}
+	if err != nil {
+		return nil, err
+	}
+	ksid := make([]byte, 0, len(u))
+	ksid = append(ksid, u.Entropy()...)
+	return append(ksid, u[:6]...), nil
+}
+
+func init() {
+	Register("ulid", NewULID)
+}
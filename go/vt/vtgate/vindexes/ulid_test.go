@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/ulid"
+	"vitess.io/vitess/go/vt/key"
+)
+
+var ulidVindex SingleColumn
+
+func init() {
+	vindex, err := CreateVindex("ulid", "ulid", nil)
+	if err != nil {
+		panic(err)
+	}
+	ulidVindex = vindex.(SingleColumn)
+}
+
+func TestULIDInfo(t *testing.T) {
+	assert.Equal(t, 1, ulidVindex.Cost())
+	assert.Equal(t, "ulid", ulidVindex.String())
+	assert.True(t, ulidVindex.IsUnique())
+	assert.False(t, ulidVindex.NeedsVCursor())
+
+	_, err := CreateVindex("ulid", "ulid", map[string]string{"binary": "maybe"})
+	assert.EqualError(t, err, `ulid: invalid binary parameter "maybe": strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
+func TestULIDMap(t *testing.T) {
+	id, err := ulid.Parse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	ksid := append(append([]byte{}, id[6:]...), id[:6]...)
+
+	got, err := ulidVindex.Map(nil, []sqltypes.Value{
+		sqltypes.NewVarChar("01ARZ3NDEKTSV4RRFFQ69G5FAV"),
+		sqltypes.NewVarChar("01arz3ndektsv4rrffq69g5fav"),
+		sqltypes.MakeTrusted(sqltypes.VarBinary, id[:]),
+		sqltypes.NewVarChar("not a ulid"),
+		sqltypes.NULL,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []key.Destination{
+		key.DestinationKeyspaceID(ksid),
+		key.DestinationKeyspaceID(ksid),
+		key.DestinationKeyspaceID(ksid),
+		key.DestinationNone{},
+		key.DestinationNone{},
+	}, got)
+}
+
+func TestULIDSpread(t *testing.T) {
+	// ULIDs generated at the same time are spread over the keyspace ids.
+	now := time.Now()
+	var low, high int
+	for i := 0; i < 100; i++ {
+		id, err := ulid.New(now)
+		require.NoError(t, err)
+		ksid, err := ulidVindex.(Hashing).Hash(sqltypes.NewVarChar(id.String()))
+		require.NoError(t, err)
+		if ksid[0] < 0x80 {
+			low++
+		} else {
+			high++
+		}
+	}
+	assert.NotZero(t, low)
+	assert.NotZero(t, high)
+}
+
+func TestULIDVerify(t *testing.T) {
+	id, err := ulid.Parse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	ksid := append(append([]byte{}, id[6:]...), id[:6]...)
+	got, err := ulidVindex.Verify(nil, []sqltypes.Value{sqltypes.NewVarChar(id.String()), sqltypes.NewVarChar(id.String())}, [][]byte{ksid, []byte("other")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, got)
+
+	_, err = ulidVindex.Verify(nil, []sqltypes.Value{sqltypes.NewVarChar("not a ulid")}, [][]byte{ksid})
+	assert.Error(t, err)
+}
+
+func TestULIDReverseMap(t *testing.T) {
+	id, err := ulid.Parse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	ksid := append(append([]byte{}, id[6:]...), id[:6]...)
+
+	got, err := ulidVindex.(Reversible).ReverseMap(nil, [][]byte{ksid})
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.NewVarChar("01ARZ3NDEKTSV4RRFFQ69G5FAV")}, got)
+
+	binaryVindex, err := CreateVindex("ulid", "ulid", map[string]string{"binary": "true"})
+	require.NoError(t, err)
+	got, err = binaryVindex.(Reversible).ReverseMap(nil, [][]byte{ksid})
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.VarBinary, id[:])}, got)
+
+	_, err = ulidVindex.(Reversible).ReverseMap(nil, [][]byte{[]byte("short")})
+	assert.EqualError(t, err, "ulid: invalid keyspace id: 73686f7274")
+}
@@ -116,6 +116,18 @@ type Prefixable interface {
 	PrefixVindex() SingleColumn
 }
 
+// A Sequential vindex is one that maps ids to keyspace ids in the same
+// order: a range of ids maps to a single range of keyspace ids. It's being
+// used to reduce the fan out for range comparisons, like the ones of keyset
+// pagination.
+type Sequential interface {
+	SingleColumn
+	// RangeMap maps the ids between start and end, inclusive, to a
+	// key.Destination. A NULL start or end leaves the range open on
+	// that side.
+	RangeMap(vcursor VCursor, start, end sqltypes.Value) (key.Destination, error)
+}
+
 // A Lookup vindex is one that needs to lookup
 // a previously stored map to compute the keyspace
 // id from an id. This means that the creation of
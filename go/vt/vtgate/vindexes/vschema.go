@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"vitess.io/vitess/go/sqlescape"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -56,6 +58,30 @@ const (
 	TypeReference = "reference"
 )
 
+// SequenceStrideColumn is the column of the sequence tables of sharded
+// keyspaces that holds the number of shards whose blocks of values are
+// interleaved.
+const SequenceStrideColumn = "stride"
+
+// The following constants represent the masking policies of columns, from
+// the one that reveals the least to the one that reveals the most.
+const (
+	// MaskingRedact replaces the values with a fixed string.
+	MaskingRedact = "redact"
+	// MaskingHash replaces the values with their hex-encoded SHA-256, so
+	// that they can still be compared, grouped and joined.
+	MaskingHash = "hash"
+	// MaskingPartial only keeps the last 4 characters of the values.
+	MaskingPartial = "partial"
+	// MaskingEmail only keeps the first character and the domain of email
+	// addresses.
+	MaskingEmail = "email"
+)
+
+// maskingPolicies are the masking policies, from the one that reveals the
+// least to the one that reveals the most.
+var maskingPolicies = []string{MaskingRedact, MaskingHash, MaskingPartial, MaskingEmail}
+
 // VSchema represents the denormalized version of SrvVSchema,
 // used for building routing plans.
 type VSchema struct {
@@ -63,6 +89,15 @@ type VSchema struct {
 	uniqueTables   map[string]*Table
 	uniqueVindexes map[string]Vindex
 	Keyspaces      map[string]*KeyspaceSchema `json:"keyspaces"`
+
+	// PlanPins are the plan pins of all the keyspaces, by the fingerprint
+	// of their query. A fingerprint pinned by several keyspaces maps to nil.
+	PlanPins map[string]*PlanPin `json:"plan_pins,omitempty"`
+
+	// maskedColumns are the masking policies of the columns of all the
+	// tables, by lowered column name. A column name masked by several tables
+	// maps to the policy that reveals the least.
+	maskedColumns map[string]string
 }
 
 // RoutingRule represents one routing rule.
@@ -96,12 +131,98 @@ type Table struct {
 	Columns                 []Column               `json:"columns,omitempty"`
 	Pinned                  []byte                 `json:"pinned,omitempty"`
 	ColumnListAuthoritative bool                   `json:"column_list_authoritative,omitempty"`
+
+	// ForeignKeys are the foreign keys of the table, and ChildForeignKeys
+	// the foreign keys of other tables that reference it.
+	ForeignKeys      []*ForeignKey `json:"foreign_keys,omitempty"`
+	ChildForeignKeys []*ForeignKey `json:"child_foreign_keys,omitempty"`
+
+	// TenantColumn is the column that holds the tenant of the rows, if any.
+	TenantColumn sqlparser.IdentifierCI `json:"-"`
+}
+
+// PlanPin pins the plan of the queries that share the fingerprint of Query.
+type PlanPin struct {
+	Keyspace  string
+	Query     string
+	Planner   querypb.ExecuteOptions_PlannerVersion
+	Vindex    string
+	NoScatter bool
+}
+
+// MarshalJSON returns a JSON representation of PlanPin.
+func (pin *PlanPin) MarshalJSON() ([]byte, error) {
+	planner := ""
+	if pin.Planner != querypb.ExecuteOptions_DEFAULT_PLANNER {
+		planner = pin.Planner.String()
+	}
+	return json.Marshal(struct {
+		Keyspace  string `json:"keyspace"`
+		Query     string `json:"query"`
+		Planner   string `json:"planner,omitempty"`
+		Vindex    string `json:"vindex,omitempty"`
+		NoScatter bool   `json:"no_scatter,omitempty"`
+	}{
+		Keyspace:  pin.Keyspace,
+		Query:     pin.Query,
+		Planner:   planner,
+		Vindex:    pin.Vindex,
+		NoScatter: pin.NoScatter,
+	})
 }
 
 // Keyspace contains the keyspcae info for each Table.
 type Keyspace struct {
-	Name    string
-	Sharded bool
+	Name            string
+	Sharded         bool
+	ForeignKeyMode  vschemapb.Keyspace_ForeignKeyMode  `json:"-"`
+	TenantPredicate vschemapb.Keyspace_TenantPredicate `json:"-"`
+}
+
+// ManagesForeignKeys returns true if vtgate enforces the foreign keys of the
+// tables of the keyspace.
+func (ks *Keyspace) ManagesForeignKeys() bool {
+	return ks.ForeignKeyMode == vschemapb.Keyspace_managed
+}
+
+// AugmentsTenantPredicates returns true if vtgate adds the missing predicates
+// on the tenant columns of the tables of the keyspace to the queries of the
+// tenant-scoped users, instead of rejecting the queries.
+func (ks *Keyspace) AugmentsTenantPredicates() bool {
+	return ks.TenantPredicate == vschemapb.Keyspace_augment
+}
+
+// ForeignKey is a foreign key of the Table named Table, that references the
+// Table named ParentTable of the same keyspace.
+type ForeignKey struct {
+	Name          string
+	Table         sqlparser.IdentifierCS
+	Columns       []sqlparser.IdentifierCI
+	ParentTable   sqlparser.IdentifierCS
+	ParentColumns []sqlparser.IdentifierCI
+	OnDelete      vschemapb.ForeignKey_Action
+	OnUpdate      vschemapb.ForeignKey_Action
+}
+
+// MarshalJSON returns a JSON representation of ForeignKey.
+func (fk *ForeignKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name          string                   `json:"name,omitempty"`
+		Table         sqlparser.IdentifierCS   `json:"table"`
+		Columns       []sqlparser.IdentifierCI `json:"columns"`
+		ParentTable   sqlparser.IdentifierCS   `json:"parent_table"`
+		ParentColumns []sqlparser.IdentifierCI `json:"parent_columns"`
+		OnDelete      string                   `json:"on_delete"`
+		OnUpdate      string                   `json:"on_update"`
+	}{
+		Name:          fk.Name,
+		Table:         fk.Table,
+		Columns:       fk.Columns,
+		ParentTable:   fk.ParentTable,
+		ParentColumns: fk.ParentColumns,
+		OnDelete:      fk.OnDelete.String(),
+		OnUpdate:      fk.OnUpdate.String(),
+	})
 }
 
 // ColumnVindex contains the index info for each index of a table.
@@ -139,16 +260,27 @@ type Column struct {
 	Name          sqlparser.IdentifierCI `json:"name"`
 	Type          querypb.Type           `json:"type"`
 	CollationName string                 `json:"collation_name"`
+	Masking       string                 `json:"masking,omitempty"`
+	Tenant        bool                   `json:"tenant,omitempty"`
+	// PrimaryKey is true if the schema tracker found the column in the
+	// primary key of the table.
+	PrimaryKey bool `json:"primary_key,omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of Column.
 func (col *Column) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Name string `json:"name"`
-		Type string `json:"type,omitempty"`
+		Name       string `json:"name"`
+		Type       string `json:"type,omitempty"`
+		Masking    string `json:"masking,omitempty"`
+		Tenant     bool   `json:"tenant,omitempty"`
+		PrimaryKey bool   `json:"primary_key,omitempty"`
 	}{
-		Name: col.Name.String(),
-		Type: querypb.Type_name[int32(col.Type)],
+		Name:       col.Name.String(),
+		Type:       querypb.Type_name[int32(col.Type)],
+		Masking:    col.Masking,
+		Tenant:     col.Tenant,
+		PrimaryKey: col.PrimaryKey,
 	})
 }
 
@@ -163,12 +295,26 @@ type KeyspaceSchema struct {
 // MarshalJSON returns a JSON representation of KeyspaceSchema.
 func (ks *KeyspaceSchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Sharded  bool              `json:"sharded,omitempty"`
-		Tables   map[string]*Table `json:"tables,omitempty"`
-		Vindexes map[string]Vindex `json:"vindexes,omitempty"`
-		Error    string            `json:"error,omitempty"`
+		Sharded         bool              `json:"sharded,omitempty"`
+		ForeignKeyMode  string            `json:"foreign_key_mode,omitempty"`
+		TenantPredicate string            `json:"tenant_predicate,omitempty"`
+		Tables          map[string]*Table `json:"tables,omitempty"`
+		Vindexes        map[string]Vindex `json:"vindexes,omitempty"`
+		Error           string            `json:"error,omitempty"`
 	}{
-		Sharded:  ks.Keyspace.Sharded,
+		Sharded: ks.Keyspace.Sharded,
+		ForeignKeyMode: func(ks *KeyspaceSchema) string {
+			if ks.Keyspace.ForeignKeyMode == vschemapb.Keyspace_unspecified {
+				return ""
+			}
+			return ks.Keyspace.ForeignKeyMode.String()
+		}(ks),
+		TenantPredicate: func(ks *KeyspaceSchema) string {
+			if ks.Keyspace.TenantPredicate == vschemapb.Keyspace_unspecified_tenant_predicate {
+				return ""
+			}
+			return ks.Keyspace.TenantPredicate.String()
+		}(ks),
 		Tables:   ks.Tables,
 		Vindexes: ks.Vindexes,
 		Error: func(ks *KeyspaceSchema) string {
@@ -234,8 +380,10 @@ func buildKeyspaces(source *vschemapb.SrvVSchema, vschema *VSchema) {
 	for ksname, ks := range source.Keyspaces {
 		ksvschema := &KeyspaceSchema{
 			Keyspace: &Keyspace{
-				Name:    ksname,
-				Sharded: ks.Sharded,
+				Name:            ksname,
+				Sharded:         ks.Sharded,
+				ForeignKeyMode:  ks.ForeignKeyMode,
+				TenantPredicate: ks.TenantPredicate,
 			},
 			Tables:   make(map[string]*Table),
 			Vindexes: make(map[string]Vindex),
@@ -245,6 +393,18 @@ func buildKeyspaces(source *vschemapb.SrvVSchema, vschema *VSchema) {
 	}
 }
 
+// HasSequenceStride returns true if the columns of a sequence table include
+// the stride column, which the sequence tables of sharded keyspaces need to
+// interleave the blocks of values of their shards.
+func HasSequenceStride(table *vschemapb.Table) bool {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, SequenceStrideColumn) {
+			return true
+		}
+	}
+	return false
+}
+
 func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSchema) error {
 	keyspace := ksvschema.Keyspace
 	for vname, vindexInfo := range ks.Vindexes {
@@ -273,8 +433,12 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 		case "", TypeReference:
 			t.Type = table.Type
 		case TypeSequence:
-			if keyspace.Sharded && table.Pinned == "" {
-				return fmt.Errorf("sequence table has to be in an unsharded keyspace or must be pinned: %s", tname)
+			// The sequence table of a sharded keyspace is on every shard,
+			// where its blocks of values are interleaved, unless it is
+			// pinned to one shard. Without a stride, every shard would
+			// give out the same values.
+			if keyspace.Sharded && table.Pinned == "" && !HasSequenceStride(table) {
+				return fmt.Errorf("sequence table of a sharded keyspace must be pinned or have a %s column: %s", SequenceStrideColumn, tname)
 			}
 			t.Type = table.Type
 		default:
@@ -288,8 +452,8 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 			t.Pinned = decoded
 		}
 
-		// If keyspace is sharded, then any table that's not a reference or pinned must have vindexes.
-		if keyspace.Sharded && t.Type != TypeReference && table.Pinned == "" && len(table.ColumnVindexes) == 0 {
+		// If keyspace is sharded, then any table that's not a reference, a sequence or pinned must have vindexes.
+		if keyspace.Sharded && t.Type != TypeReference && t.Type != TypeSequence && table.Pinned == "" && len(table.ColumnVindexes) == 0 {
 			return fmt.Errorf("missing primary col vindex for table: %s", tname)
 		}
 
@@ -301,7 +465,26 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 				return fmt.Errorf("duplicate column name '%v' for table: %s", name, tname)
 			}
 			colNames[name.Lowered()] = true
-			t.Columns = append(t.Columns, Column{Name: name, Type: col.Type})
+			if col.Masking != "" {
+				if err := vschema.addMaskedColumn(name, col.Masking); err != nil {
+					return fmt.Errorf("%s for column '%v' of table: %s", err, name, tname)
+				}
+			}
+			if col.Tenant {
+				if !t.TenantColumn.IsEmpty() {
+					return fmt.Errorf("multiple tenant columns for table: %s", tname)
+				}
+				t.TenantColumn = name
+			}
+			t.Columns = append(t.Columns, Column{Name: name, Type: col.Type, Masking: col.Masking, Tenant: col.Tenant})
+		}
+		if table.Ttl != nil {
+			if table.Ttl.Column == "" {
+				return fmt.Errorf("missing ttl column for table: %s", tname)
+			}
+			if _, err := TTLRetention(table.Ttl); err != nil {
+				return fmt.Errorf("%v for table: %s", err, tname)
+			}
 		}
 
 		// Initialize ColumnVindexes.
@@ -398,6 +581,159 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 		}
 		ksvschema.Tables[tname] = t
 	}
+	if err := buildForeignKeys(ks, ksvschema); err != nil {
+		return err
+	}
+	return buildPlanPins(ks, vschema, ksvschema)
+}
+
+// buildForeignKeys links the foreign keys of the tables of a keyspace to
+// their parent tables.
+func buildForeignKeys(ks *vschemapb.Keyspace, ksvschema *KeyspaceSchema) error {
+	// The tables are sorted for the child foreign keys of a table to be
+	// in a stable order.
+	tnames := make([]string, 0, len(ks.Tables))
+	for tname := range ks.Tables {
+		tnames = append(tnames, tname)
+	}
+	sort.Strings(tnames)
+	for _, tname := range tnames {
+		t := ksvschema.Tables[tname]
+		for _, fk := range ks.Tables[tname].ForeignKeys {
+			parent := ksvschema.Tables[fk.ParentTable]
+			if parent == nil {
+				return fmt.Errorf("parent table %s of foreign key %s not found for table %s", fk.ParentTable, fk.Name, tname)
+			}
+			if len(fk.Columns) != 1 || len(fk.ParentColumns) != 1 {
+				return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "foreign key %s of table %s must have exactly one column and one parent column", fk.Name, tname)
+			}
+			foreignKey := &ForeignKey{
+				Name:          fk.Name,
+				Table:         t.Name,
+				Columns:       []sqlparser.IdentifierCI{sqlparser.NewIdentifierCI(fk.Columns[0])},
+				ParentTable:   parent.Name,
+				ParentColumns: []sqlparser.IdentifierCI{sqlparser.NewIdentifierCI(fk.ParentColumns[0])},
+				OnDelete:      fk.OnDelete,
+				OnUpdate:      fk.OnUpdate,
+			}
+			t.ForeignKeys = append(t.ForeignKeys, foreignKey)
+			parent.ChildForeignKeys = append(parent.ChildForeignKeys, foreignKey)
+		}
+	}
+	for _, tname := range tnames {
+		if err := checkCascadeCycle(ksvschema, ksvschema.Tables[tname], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCascadeCycle returns an error if a change of the table can cascade
+// back to the table, which vtgate cannot plan.
+func checkCascadeCycle(ksvschema *KeyspaceSchema, t *Table, path []string) error {
+	for _, name := range path {
+		if name == t.Name.String() {
+			return fmt.Errorf("foreign keys cascade in a cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+	}
+	path = append(path, t.Name.String())
+	for _, fk := range t.ChildForeignKeys {
+		if fk.OnDelete == vschemapb.ForeignKey_RESTRICT && fk.OnUpdate == vschemapb.ForeignKey_RESTRICT {
+			continue
+		}
+		if err := checkCascadeCycle(ksvschema, ksvschema.Tables[fk.Table.String()], path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMaskedColumn adds the masking policy of a column to the masked columns
+// of the vschema.
+func (vschema *VSchema) addMaskedColumn(name sqlparser.IdentifierCI, masking string) error {
+	strength := maskingStrength(masking)
+	if strength < 0 {
+		return fmt.Errorf("invalid masking '%s'", masking)
+	}
+	if vschema.maskedColumns == nil {
+		vschema.maskedColumns = make(map[string]string)
+	}
+	if current, ok := vschema.maskedColumns[name.Lowered()]; !ok || strength < maskingStrength(current) {
+		vschema.maskedColumns[name.Lowered()] = masking
+	}
+	return nil
+}
+
+// TTLRetention returns the retention of the rows of a table with a TTL. The
+// retention is either a duration or a number of days, like "30d".
+func TTLRetention(ttl *vschemapb.TTL) (time.Duration, error) {
+	var retention time.Duration
+	if days := strings.TrimSuffix(ttl.Retention, "d"); days != ttl.Retention {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl retention '%s'", ttl.Retention)
+		}
+		retention = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if retention, err = time.ParseDuration(ttl.Retention); err != nil {
+			return 0, fmt.Errorf("invalid ttl retention '%s'", ttl.Retention)
+		}
+	}
+	if retention <= 0 {
+		return 0, fmt.Errorf("invalid ttl retention '%s'", ttl.Retention)
+	}
+	return retention, nil
+}
+
+// maskingStrength returns the index of a masking policy in maskingPolicies,
+// or -1 if it is invalid.
+func maskingStrength(masking string) int {
+	for i, policy := range maskingPolicies {
+		if policy == masking {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildPlanPins adds the plan pins of a keyspace to the vschema, by the
+// fingerprint of their query.
+func buildPlanPins(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSchema) error {
+	fingerprints := make(map[string]bool, len(ks.PlanPins))
+	for _, pin := range ks.PlanPins {
+		fingerprint, err := sqlparser.QueryFingerprint(pin.Query)
+		if err != nil {
+			return fmt.Errorf("invalid query in plan pin %q: %s", pin.Query, err)
+		}
+		if fingerprints[fingerprint] {
+			return fmt.Errorf("duplicate plan pin for query %q", pin.Query)
+		}
+		fingerprints[fingerprint] = true
+		if _, ok := querypb.ExecuteOptions_PlannerVersion_name[int32(pin.Planner)]; !ok {
+			return fmt.Errorf("invalid planner %d in plan pin %q", pin.Planner, pin.Query)
+		}
+		if pin.Vindex != "" {
+			if _, ok := ksvschema.Vindexes[pin.Vindex]; !ok {
+				return fmt.Errorf("vindex %s not found for plan pin %q", pin.Vindex, pin.Query)
+			}
+		}
+
+		if vschema.PlanPins == nil {
+			vschema.PlanPins = make(map[string]*PlanPin)
+		}
+		if _, ok := vschema.PlanPins[fingerprint]; ok {
+			vschema.PlanPins[fingerprint] = nil
+			continue
+		}
+		vschema.PlanPins[fingerprint] = &PlanPin{
+			Keyspace:  ksvschema.Keyspace.Name,
+			Query:     pin.Query,
+			Planner:   pin.Planner,
+			Vindex:    pin.Vindex,
+			NoScatter: pin.NoScatter,
+		}
+	}
 	return nil
 }
 
@@ -651,6 +987,69 @@ func (vschema *VSchema) FindVindex(keyspace, name string) (Vindex, error) {
 	return ks.Vindexes[name], nil
 }
 
+// FindPlanPin returns the plan pin of the fingerprint of a query, or nil if
+// it is not pinned, or pinned by several keyspaces.
+func (vschema *VSchema) FindPlanPin(query string) *PlanPin {
+	if len(vschema.PlanPins) == 0 {
+		return nil
+	}
+	fingerprint, err := sqlparser.QueryFingerprint(query)
+	if err != nil {
+		return nil
+	}
+	return vschema.PlanPins[fingerprint]
+}
+
+// HasMaskedColumns returns true if a column of a table has a masking policy.
+func (vschema *VSchema) HasMaskedColumns() bool {
+	return len(vschema.maskedColumns) > 0
+}
+
+// FindColumnMasking returns the masking policy of a column of a query result,
+// or "" if it is not masked. The column is identified by the database, the
+// table and the name of its field, as returned by MySQL: the database is
+// either the name of the keyspace or the name of its database, e.g. vt_ks.
+// If the table is not known, e.g. because the client didn't ask for all the
+// metadata of the fields, the column is masked if any column with the same
+// name is.
+func (vschema *VSchema) FindColumnMasking(database, table, column string) string {
+	if len(vschema.maskedColumns) == 0 {
+		return ""
+	}
+	name := sqlparser.NewIdentifierCI(column)
+	if table == "" {
+		return vschema.maskedColumns[name.Lowered()]
+	}
+	if ks, ok := vschema.Keyspaces[database]; ok {
+		return ks.findColumnMasking(table, name)
+	}
+	if ks, ok := vschema.Keyspaces[strings.TrimPrefix(database, "vt_")]; ok {
+		return ks.findColumnMasking(table, name)
+	}
+	// The keyspace of the database is unknown, e.g. because its name was
+	// overridden: the column is masked if it is masked in any keyspace.
+	masking := ""
+	for _, ks := range vschema.Keyspaces {
+		if policy := ks.findColumnMasking(table, name); policy != "" && (masking == "" || maskingStrength(policy) < maskingStrength(masking)) {
+			masking = policy
+		}
+	}
+	return masking
+}
+
+func (ks *KeyspaceSchema) findColumnMasking(table string, name sqlparser.IdentifierCI) string {
+	t := ks.Tables[table]
+	if t == nil {
+		return ""
+	}
+	for _, col := range t.Columns {
+		if col.Name.Equal(name) {
+			return col.Masking
+		}
+	}
+	return ""
+}
+
 // ByCost provides the interface needed for ColumnVindexes to
 // be sorted by cost order.
 type ByCost []*ColumnVindex
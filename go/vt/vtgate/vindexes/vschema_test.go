@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/test/utils"
 
@@ -2143,6 +2144,33 @@ func TestBadSequenceName(t *testing.T) {
 	}
 }
 
+func TestShardedSequence(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						Type: "sequence",
+						Columns: []*vschemapb.Column{{
+							Name: "stride",
+							Type: sqltypes.Int64,
+						}},
+					},
+					"t2": {
+						Type:   "sequence",
+						Pinned: "80",
+					},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	require.NoError(t, got.Keyspaces["sharded"].Error)
+	assert.Equal(t, TypeSequence, got.Keyspaces["sharded"].Tables["t1"].Type)
+	assert.Equal(t, TypeSequence, got.Keyspaces["sharded"].Tables["t2"].Type)
+}
+
 func TestBadShardedSequence(t *testing.T) {
 	bad := vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
@@ -2158,12 +2186,321 @@ func TestBadShardedSequence(t *testing.T) {
 	}
 	got := BuildVSchema(&bad)
 	err := got.Keyspaces["sharded"].Error
-	want := "sequence table has to be in an unsharded keyspace or must be pinned: t1"
+	want := "sequence table of a sharded keyspace must be pinned or have a stride column: t1"
 	if err == nil || err.Error() != want {
 		t.Errorf("BuildVSchema: %v, want %v", err, want)
 	}
 }
 
+func TestForeignKeys(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks": {
+				ForeignKeyMode: vschemapb.Keyspace_managed,
+				Tables: map[string]*vschemapb.Table{
+					"parent": {},
+					"child": {
+						ForeignKeys: []*vschemapb.ForeignKey{{
+							Name:          "child_fk",
+							Columns:       []string{"pid"},
+							ParentTable:   "parent",
+							ParentColumns: []string{"id"},
+							OnDelete:      vschemapb.ForeignKey_CASCADE,
+						}},
+					},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	ks := got.Keyspaces["ks"]
+	require.NoError(t, ks.Error)
+	assert.True(t, ks.Keyspace.ManagesForeignKeys())
+
+	child, parent := ks.Tables["child"], ks.Tables["parent"]
+	require.Len(t, child.ForeignKeys, 1)
+	fk := child.ForeignKeys[0]
+	assert.Equal(t, "child_fk", fk.Name)
+	assert.Equal(t, "child", fk.Table.String())
+	assert.Equal(t, "pid", fk.Columns[0].String())
+	assert.Equal(t, "parent", fk.ParentTable.String())
+	assert.Equal(t, "id", fk.ParentColumns[0].String())
+	assert.Equal(t, vschemapb.ForeignKey_CASCADE, fk.OnDelete)
+	assert.Equal(t, vschemapb.ForeignKey_RESTRICT, fk.OnUpdate)
+	assert.Equal(t, []*ForeignKey{fk}, parent.ChildForeignKeys)
+	assert.Empty(t, parent.ForeignKeys)
+	assert.Empty(t, child.ChildForeignKeys)
+}
+
+func TestForeignKeysFail(t *testing.T) {
+	fk := func(name, table string, onDelete vschemapb.ForeignKey_Action) *vschemapb.ForeignKey {
+		return &vschemapb.ForeignKey{
+			Name:          name,
+			Columns:       []string{"pid"},
+			ParentTable:   table,
+			ParentColumns: []string{"id"},
+			OnDelete:      onDelete,
+		}
+	}
+	tcases := []struct {
+		name   string
+		tables map[string]*vschemapb.Table
+		err    string
+	}{{
+		name: "parent not found",
+		tables: map[string]*vschemapb.Table{
+			"t1": {ForeignKeys: []*vschemapb.ForeignKey{fk("fk1", "t2", vschemapb.ForeignKey_RESTRICT)}},
+		},
+		err: "parent table t2 of foreign key fk1 not found for table t1",
+	}, {
+		name: "multiple columns",
+		tables: map[string]*vschemapb.Table{
+			"t1": {ForeignKeys: []*vschemapb.ForeignKey{{
+				Name:          "fk1",
+				Columns:       []string{"a", "b"},
+				ParentTable:   "t2",
+				ParentColumns: []string{"a", "b"},
+			}}},
+			"t2": {},
+		},
+		err: "foreign key fk1 of table t1 must have exactly one column and one parent column",
+	}, {
+		name: "cascade cycle",
+		tables: map[string]*vschemapb.Table{
+			"t1": {ForeignKeys: []*vschemapb.ForeignKey{fk("fk1", "t2", vschemapb.ForeignKey_CASCADE)}},
+			"t2": {ForeignKeys: []*vschemapb.ForeignKey{fk("fk2", "t1", vschemapb.ForeignKey_SET_NULL)}},
+		},
+		err: "foreign keys cascade in a cycle: t1 -> t2 -> t1",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			input := vschemapb.SrvVSchema{
+				Keyspaces: map[string]*vschemapb.Keyspace{
+					"ks": {
+						ForeignKeyMode: vschemapb.Keyspace_managed,
+						Tables:         tcase.tables,
+					},
+				},
+			}
+			got := BuildVSchema(&input)
+			assert.EqualError(t, got.Keyspaces["ks"].Error, tcase.err)
+		})
+	}
+
+	// A cycle of foreign keys that restrict the changes of the referenced
+	// rows is valid.
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks": {
+				ForeignKeyMode: vschemapb.Keyspace_managed,
+				Tables: map[string]*vschemapb.Table{
+					"t1": {ForeignKeys: []*vschemapb.ForeignKey{fk("fk1", "t1", vschemapb.ForeignKey_RESTRICT)}},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	assert.NoError(t, got.Keyspaces["ks"].Error)
+}
+
+func TestTTL(t *testing.T) {
+	retention, err := TTLRetention(&vschemapb.TTL{Column: "created_at", Retention: "30d"})
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, retention)
+	retention, err = TTLRetention(&vschemapb.TTL{Column: "created_at", Retention: "36h"})
+	require.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, retention)
+
+	tcases := []struct {
+		ttl *vschemapb.TTL
+		err string
+	}{{
+		ttl: &vschemapb.TTL{Retention: "30d"},
+		err: "missing ttl column for table: t1",
+	}, {
+		ttl: &vschemapb.TTL{Column: "created_at", Retention: "month"},
+		err: "invalid ttl retention 'month' for table: t1",
+	}, {
+		ttl: &vschemapb.TTL{Column: "created_at", Retention: "xd"},
+		err: "invalid ttl retention 'xd' for table: t1",
+	}, {
+		ttl: &vschemapb.TTL{Column: "created_at", Retention: "0d"},
+		err: "invalid ttl retention '0d' for table: t1",
+	}, {
+		ttl: &vschemapb.TTL{Column: "created_at"},
+		err: "invalid ttl retention '' for table: t1",
+	}}
+	for _, tcase := range tcases {
+		input := vschemapb.SrvVSchema{
+			Keyspaces: map[string]*vschemapb.Keyspace{
+				"ks": {Tables: map[string]*vschemapb.Table{"t1": {Ttl: tcase.ttl}}},
+			},
+		}
+		got := BuildVSchema(&input)
+		assert.EqualError(t, got.Keyspaces["ks"].Error, tcase.err)
+	}
+}
+
+func TestPlanPins(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks1": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				PlanPins: []*vschemapb.PlanPin{{
+					Query:   "select * from t1 where id = 1 and name = 'a'",
+					Planner: querypb.ExecuteOptions_Gen4,
+					Vindex:  "hash",
+				}, {
+					Query:     "select * from t2",
+					NoScatter: true,
+				}},
+			},
+			"ks2": {
+				PlanPins: []*vschemapb.PlanPin{{
+					Query:   "select * from t2",
+					Planner: querypb.ExecuteOptions_V3,
+				}},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	require.NoError(t, got.Keyspaces["ks1"].Error)
+	require.NoError(t, got.Keyspaces["ks2"].Error)
+
+	want := &PlanPin{
+		Keyspace: "ks1",
+		Query:    "select * from t1 where id = 1 and name = 'a'",
+		Planner:  querypb.ExecuteOptions_Gen4,
+		Vindex:   "hash",
+	}
+	assert.Equal(t, want, got.FindPlanPin("select * from t1 where name = 'b' and id = 2"))
+	assert.Equal(t, want, got.FindPlanPin("select /* comment */ * from t1 where id = 3 and name = 'c'"))
+	assert.Nil(t, got.FindPlanPin("select * from t1 where id = 1"))
+	// The queries pinned by several keyspaces are not pinned.
+	assert.Nil(t, got.FindPlanPin("select * from t2"))
+	assert.Nil(t, got.FindPlanPin("not a query"))
+
+	tcases := []struct {
+		name string
+		pin  *vschemapb.PlanPin
+		err  string
+	}{{
+		name: "invalid query",
+		pin:  &vschemapb.PlanPin{Query: "select from"},
+		err:  `invalid query in plan pin "select from": syntax error at position 12 near 'from'`,
+	}, {
+		name: "vindex not found",
+		pin:  &vschemapb.PlanPin{Query: "select * from t1", Vindex: "xxhash"},
+		err:  `vindex xxhash not found for plan pin "select * from t1"`,
+	}, {
+		name: "invalid planner",
+		pin:  &vschemapb.PlanPin{Query: "select * from t1", Planner: 42},
+		err:  `invalid planner 42 in plan pin "select * from t1"`,
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			err := ValidateKeyspace(&vschemapb.Keyspace{PlanPins: []*vschemapb.PlanPin{tcase.pin}})
+			assert.EqualError(t, err, tcase.err)
+		})
+	}
+
+	err := ValidateKeyspace(&vschemapb.Keyspace{
+		PlanPins: []*vschemapb.PlanPin{{Query: "select * from t1 where id = 1"}, {Query: "select * from t1 where id = 2"}},
+	})
+	assert.EqualError(t, err, `duplicate plan pin for query "select * from t1 where id = 2"`)
+}
+
+func TestColumnMasking(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks1": {
+				Tables: map[string]*vschemapb.Table{
+					"users": {
+						Columns: []*vschemapb.Column{
+							{Name: "id", Type: sqltypes.Int64},
+							{Name: "email", Type: sqltypes.VarChar, Masking: MaskingEmail},
+							{Name: "Phone", Type: sqltypes.VarChar, Masking: MaskingPartial},
+						},
+					},
+				},
+			},
+			"ks2": {
+				Tables: map[string]*vschemapb.Table{
+					"users": {
+						Columns: []*vschemapb.Column{
+							{Name: "email", Type: sqltypes.VarChar, Masking: MaskingHash},
+						},
+					},
+					"contacts": {
+						Columns: []*vschemapb.Column{
+							{Name: "phone", Type: sqltypes.VarChar, Masking: MaskingRedact},
+						},
+					},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	require.NoError(t, got.Keyspaces["ks1"].Error)
+	require.NoError(t, got.Keyspaces["ks2"].Error)
+	assert.True(t, got.HasMaskedColumns())
+
+	assert.Equal(t, MaskingEmail, got.FindColumnMasking("ks1", "users", "email"))
+	assert.Equal(t, MaskingEmail, got.FindColumnMasking("vt_ks1", "users", "EMAIL"))
+	assert.Equal(t, MaskingHash, got.FindColumnMasking("ks2", "users", "email"))
+	assert.Equal(t, MaskingPartial, got.FindColumnMasking("ks1", "users", "phone"))
+	assert.Empty(t, got.FindColumnMasking("ks1", "users", "id"))
+	assert.Empty(t, got.FindColumnMasking("ks1", "contacts", "phone"))
+	// The policy that reveals the least is used when the keyspace or the
+	// table is unknown.
+	assert.Equal(t, MaskingHash, got.FindColumnMasking("other_db", "users", "email"))
+	assert.Equal(t, MaskingRedact, got.FindColumnMasking("", "", "phone"))
+	assert.Empty(t, got.FindColumnMasking("", "", "id"))
+
+	assert.False(t, BuildVSchema(&vschemapb.SrvVSchema{}).HasMaskedColumns())
+
+	err := ValidateKeyspace(&vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"users": {Columns: []*vschemapb.Column{{Name: "email", Masking: "scramble"}}},
+		},
+	})
+	assert.EqualError(t, err, "invalid masking 'scramble' for column 'email' of table: users")
+}
+
+func TestTenantColumn(t *testing.T) {
+	ks, err := BuildKeyspaceSchema(&vschemapb.Keyspace{
+		TenantPredicate: vschemapb.Keyspace_augment,
+		Tables: map[string]*vschemapb.Table{
+			"users": {
+				Columns: []*vschemapb.Column{
+					{Name: "id", Type: sqltypes.Int64},
+					{Name: "Tenant_ID", Type: sqltypes.Int64, Tenant: true},
+				},
+			},
+			"countries": {},
+		},
+	}, "ks")
+	require.NoError(t, err)
+	assert.True(t, ks.Keyspace.AugmentsTenantPredicates())
+	assert.Equal(t, "Tenant_ID", ks.Tables["users"].TenantColumn.String())
+	assert.True(t, ks.Tables["countries"].TenantColumn.IsEmpty())
+
+	out, err := json.Marshal(ks)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"tenant_predicate":"augment"`)
+	assert.Contains(t, string(out), `{"name":"Tenant_ID","type":"INT64","tenant":true}`)
+
+	err = ValidateKeyspace(&vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"users": {Columns: []*vschemapb.Column{{Name: "tenant_id", Tenant: true}, {Name: "org_id", Tenant: true}}},
+		},
+	})
+	assert.EqualError(t, err, "multiple tenant columns for table: users")
+}
+
 func TestFindTable(t *testing.T) {
 	input := vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
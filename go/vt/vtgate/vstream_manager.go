@@ -174,12 +174,29 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 	if vgtid == nil || len(vgtid.ShardGtids) == 0 {
 		return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one value with a starting position")
 	}
+	// With a start time, the vgtid only lists the keyspaces and shards to
+	// stream from: each tablet starts at the position of its binlogs at
+	// that time, which it gets from the special "timestamp:<seconds>" value.
+	if flags.StartTime != 0 {
+		if flags.StartTime < 0 {
+			return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid start time: %d", flags.StartTime)
+		}
+		for _, sgtid := range vgtid.ShardGtids {
+			if sgtid.Gtid != "" || len(sgtid.TablePKs) != 0 {
+				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "if a start time is specified, the Gtid values must be empty: %v", vgtid)
+			}
+		}
+		vgtid = proto.Clone(vgtid).(*binlogdatapb.VGtid)
+		for _, sgtid := range vgtid.ShardGtids {
+			sgtid.Gtid = fmt.Sprintf("timestamp:%d", flags.StartTime)
+		}
+	}
 	// To fetch from all keyspaces, the input must contain a single ShardGtid
-	// that has an empty keyspace, and the Gtid must be "current". In the
-	// future, we'll allow the Gtid to be empty which will also support
-	// copying of existing data.
+	// that has an empty keyspace, and the Gtid must be "current" (or empty
+	// with a start time). In the future, we'll allow the Gtid to be empty
+	// which will also support copying of existing data.
 	if len(vgtid.ShardGtids) == 1 && vgtid.ShardGtids[0].Keyspace == "" {
-		if vgtid.ShardGtids[0].Gtid != "current" {
+		if vgtid.ShardGtids[0].Gtid != "current" && flags.StartTime == 0 {
 			return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "for an empty keyspace, the Gtid value must be 'current': %v", vgtid)
 		}
 		keyspaces, err := vsm.toposerv.GetSrvKeyspaceNames(ctx, vsm.cell, false)
@@ -190,7 +207,7 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 		for _, keyspace := range keyspaces {
 			newvgtid.ShardGtids = append(newvgtid.ShardGtids, &binlogdatapb.ShardGtid{
 				Keyspace: keyspace,
-				Gtid:     "current",
+				Gtid:     vgtid.ShardGtids[0].Gtid,
 			})
 		}
 		vgtid = newvgtid
@@ -198,7 +215,7 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 	newvgtid := &binlogdatapb.VGtid{}
 	for _, sgtid := range vgtid.ShardGtids {
 		if sgtid.Shard == "" {
-			if sgtid.Gtid != "current" {
+			if sgtid.Gtid != "current" && flags.StartTime == 0 {
 				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "if shards are unspecified, the Gtid value must be 'current': %v", vgtid)
 			}
 			// TODO(sougou): this should work with the new Migrate workflow
@@ -1015,6 +1015,52 @@ func TestResolveVStreamParams(t *testing.T) {
 		})
 	}
 
+	t.Run("resolveParams StartTime", func(t *testing.T) {
+		flags := &vtgatepb.VStreamFlags{StartTime: 1666000000}
+		input := &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Shard:    "-20",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "20-40",
+			}},
+		}
+		vgtid, _, _, err := vsm.resolveParams(context.Background(), topodatapb.TabletType_REPLICA, input, nil, flags)
+		require.NoError(t, err)
+		assert.Equal(t, &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Shard:    "-20",
+				Gtid:     "timestamp:1666000000",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "20-40",
+				Gtid:     "timestamp:1666000000",
+			}},
+		}, vgtid)
+		assert.Empty(t, input.ShardGtids[0].Gtid)
+
+		vgtid, _, _, err = vsm.resolveParams(context.Background(), topodatapb.TabletType_REPLICA, &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+			}},
+		}, nil, flags)
+		require.NoError(t, err)
+		require.Len(t, vgtid.ShardGtids, 8)
+		for _, sgtid := range vgtid.ShardGtids {
+			assert.Equal(t, "timestamp:1666000000", sgtid.Gtid)
+		}
+
+		_, _, _, err = vsm.resolveParams(context.Background(), topodatapb.TabletType_REPLICA, &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Shard:    "-20",
+				Gtid:     "current",
+			}},
+		}, nil, flags)
+		assert.ErrorContains(t, err, "if a start time is specified, the Gtid values must be empty")
+	})
 }
 
 func TestVStreamIdleHeartbeat(t *testing.T) {
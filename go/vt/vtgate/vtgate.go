@@ -46,6 +46,7 @@ import (
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 
 	vtschema "vitess.io/vitess/go/vt/vtgate/schema"
@@ -66,8 +67,11 @@ var (
 	queryPlanCacheMemory = flag.Int64("gate_query_cache_memory", cache.DefaultConfig.MaxMemoryUsage, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	queryPlanCacheLFU    = flag.Bool("gate_query_cache_lfu", cache.DefaultConfig.LFU, "gate server cache algorithm. when set to true, a new cache algorithm based on a TinyLFU admission policy will be used to improve cache behavior and prevent pollution from sparse queries")
 	_                    = flag.Bool("disable_local_gateway", false, "deprecated: if specified, this process will not route any queries to local tablets in the local cell")
-	maxMemoryRows        = flag.Int("max_memory_rows", 300000, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
-	warnMemoryRows       = flag.Int("warn_memory_rows", 30000, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
+	maxMemoryRows        = newIntFlag("max_memory_rows", 300000, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
+	warnMemoryRows       = newIntFlag("warn_memory_rows", 30000, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
+	queryMemoryLimit     = newIntFlag("query_memory_limit", 0, "Maximum number of bytes that the intermediate results of the joins, sorts and aggregations of a query can hold in memory. A query that exceeds it is aborted. A session can lower it with the @@query_memory_limit session variable, but not raise or disable it. 0 disables the limit")
+	querySpillDir        = flag.String("query_spill_dir", "", "Directory of the temporary files the streaming sorts and the distincts spill their rows to once they exceed the memory limit of their query. Defaults to the temporary directory of the system")
+	querySpillMaxDisk    = flag.Int64("query_spill_max_disk_bytes", 10<<30, "Maximum number of bytes the spill files of all the queries can hold on disk. A query that exceeds it is aborted. 0 disables the limit")
 	defaultDDLStrategy   = flag.String("ddl_strategy", string(schema.DDLStrategyDirect), "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	dbDDLPlugin          = flag.String("dbddl_plugin", "fail", "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
 	noScatter            = flag.Bool("no_scatter", false, "when set to true, the planner will fail instead of producing a plan that includes scatter queries")
@@ -78,8 +82,8 @@ var (
 	HealthCheckRetryDelay = flag.Duration("healthcheck_retry_delay", 2*time.Millisecond, "health check retry delay")
 	// HealthCheckTimeout is the timeout on the RPC call to tablets
 	HealthCheckTimeout = flag.Duration("healthcheck_timeout", time.Minute, "the health check timeout period")
-	maxPayloadSize     = flag.Int("max_payload_size", 0, "The threshold for query payloads in bytes. A payload greater than this threshold will result in a failure to handle the query.")
-	warnPayloadSize    = flag.Int("warn_payload_size", 0, "The warning threshold for query payloads in bytes. A payload greater than this threshold will cause the VtGateWarnings.WarnPayloadSizeExceeded counter to be incremented.")
+	maxPayloadSize     = newIntFlag("max_payload_size", 0, "The threshold for query payloads in bytes. A payload greater than this threshold will result in a failure to handle the query.")
+	warnPayloadSize    = newIntFlag("warn_payload_size", 0, "The warning threshold for query payloads in bytes. A payload greater than this threshold will cause the VtGateWarnings.WarnPayloadSizeExceeded counter to be incremented.")
 
 	// Put set-passthrough under a flag.
 	sysVarSetEnabled = flag.Bool("enable_system_settings", true, "This will enable the system settings to be changed per session at the database connection level")
@@ -87,10 +91,13 @@ var (
 
 	// lockHeartbeatTime is used to set the next heartbeat time.
 	lockHeartbeatTime = flag.Duration("lock_heartbeat_time", 5*time.Second, "If there is lock function used. This will keep the lock connection active by using this heartbeat")
+	lockTablesTimeout = flag.Duration("lock_tables_timeout", 30*time.Second, "The maximum time to wait for LOCK TABLES and FLUSH TABLES WITH READ LOCK to acquire the table locks on unsharded keyspaces")
 	warnShardedOnly   = flag.Bool("warn_sharded_only", false, "If any features that are only available in unsharded mode are used, query execution warnings will be added to the session")
 
 	foreignKeyMode = flag.String("foreign_key_mode", "allow", "This is to provide how to handle foreign key constraint in create/alter table. Valid values are: allow, disallow")
 
+	enableDMLReturning = flag.Bool("enable_dml_returning", false, "Allow the RETURNING clause on INSERT, UPDATE and DELETE statements that are routed to a single shard. The vttablets emulate it with queries in the transaction of the DML")
+
 	// flags to enable/disable online and direct DDL statements
 	enableOnlineDDL = flag.Bool("enable_online_ddl", true, "Allow users to submit, review and control Online DDL")
 	enableDirectDDL = flag.Bool("enable_direct_ddl", true, "Allow users to submit direct DDL statements")
@@ -142,6 +149,9 @@ type VTGate struct {
 	txConn   *TxConn
 	gw       *TabletGateway
 
+	// checkouts holds the sessions checked out with CheckoutSession.
+	checkouts sessionCheckouts
+
 	// stats objects.
 	// TODO(sougou): This needs to be cleaned up. There
 	// are global vars that depend on this member var.
@@ -204,6 +214,10 @@ func Init(
 	if _, err := schema.ParseDDLStrategy(*defaultDDLStrategy); err != nil {
 		log.Fatalf("Invalid value for -ddl_strategy: %v", err.Error())
 	}
+	if err := loadMaskingHashKey(); err != nil {
+		log.Fatalf("Unable to load --masking_hash_key_file: %v", err)
+	}
+	engine.SetSpillOptions(*querySpillDir, *querySpillMaxDisk)
 	tc := NewTxConn(gw, getTxMode())
 	// ScatterConn depends on TxConn to perform forced rollbacks.
 	sc := NewScatterConn("VttabletCall", tc, gw)
@@ -289,11 +303,13 @@ func Init(
 		if st != nil && *enableSchemaChangeSignal {
 			st.Start()
 		}
+		executor.ksSettings.start(executor)
 	})
 	servenv.OnTerm(func() {
 		if st != nil && *enableSchemaChangeSignal {
 			st.Stop()
 		}
+		executor.ksSettings.stop()
 	})
 	rpcVTGate.registerDebugHealthHandler()
 	rpcVTGate.registerDebugEnvHandler()
@@ -304,6 +320,10 @@ func Init(
 
 	initAPI(gw.hc)
 
+	if *warmCacheFile != "" {
+		initWarmCache(executor, gw.hc)
+	}
+
 	return rpcVTGate
 }
 
@@ -407,12 +427,18 @@ func (vtg *VTGate) Execute(ctx context.Context, session *vtgatepb.Session, sql s
 }
 
 // ExecuteBatch executes a batch of queries. This is a V3 function.
-func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+// The queries are executed one after the other in the session, and the
+// error of each query is returned with its result. If stopOnError is set,
+// the queries that follow the first failed query are not executed.
+func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"ExecuteBatch", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
 	defer vtg.timings.Record(statsKey, time.Now())
 
+	if len(bindVariablesList) != 0 && len(bindVariablesList) != len(sqlList) {
+		return session, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%d bind variable sets for %d queries", len(bindVariablesList), len(sqlList))
+	}
 	for _, bindVariables := range bindVariablesList {
 		if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 			return session, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -420,7 +446,12 @@ func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session,
 	}
 
 	qrl := make([]sqltypes.QueryResponse, len(sqlList))
+	failed := -1
 	for i, sql := range sqlList {
+		if failed >= 0 {
+			qrl[i].QueryError = vterrors.Errorf(vtrpcpb.Code_ABORTED, "query %d was not executed because query %d failed", i, failed)
+			continue
+		}
 		var bv map[string]*querypb.BindVariable
 		if len(bindVariablesList) != 0 {
 			bv = bindVariablesList[i]
@@ -430,6 +461,9 @@ func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session,
 			vtg.rowsReturned.Add(statsKey, int64(len(qr.Rows)))
 			vtg.rowsAffected.Add(statsKey, int64(qr.RowsAffected))
 		}
+		if qrl[i].QueryError != nil && stopOnError {
+			failed = i
+		}
 	}
 	return session, qrl, nil
 }
@@ -480,6 +514,27 @@ func (vtg *VTGate) CloseSession(ctx context.Context, session *vtgatepb.Session)
 	return vtg.executor.CloseSession(ctx, NewSafeSession(session))
 }
 
+// CheckoutSession hands the session, with its open transaction, over to
+// vtgate until it is returned by CheckinSession, so that another instance
+// of the application can resume it. If the session is not checked in
+// within timeout, it is closed, which rolls back its transaction. A zero
+// timeout means --session_checkout_max_timeout.
+func (vtg *VTGate) CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error) {
+	switch {
+	case timeout < 0 || timeout > *sessionCheckoutMaxTimeout:
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the checkout timeout must be between 0 and %v, got %v", *sessionCheckoutMaxTimeout, timeout)
+	case timeout == 0:
+		timeout = *sessionCheckoutMaxTimeout
+	}
+	return vtg.checkouts.checkout(session, timeout, vtg.CloseSession)
+}
+
+// CheckinSession returns the session checked out with CheckoutSession
+// under token.
+func (vtg *VTGate) CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error) {
+	return vtg.checkouts.checkin(token)
+}
+
 // ResolveTransaction resolves the specified 2PC transaction.
 func (vtg *VTGate) ResolveTransaction(ctx context.Context, dtid string) error {
 	return formatError(vtg.txConn.Resolve(ctx, dtid))
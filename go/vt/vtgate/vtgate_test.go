@@ -19,6 +19,7 @@ package vtgate
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -167,6 +168,85 @@ func TestVTGateExecuteWithKeyspaceShard(t *testing.T) {
 	require.Contains(t, err.Error(), `no healthy tablet available for 'keyspace:"TestUnsharded" shard:"noshard" tablet_type:PRIMARY`)
 }
 
+func TestVTGateExecuteBatch(t *testing.T) {
+	createSandbox(KsTestUnsharded)
+	hcVTGateTest.Reset()
+	hcVTGateTest.AddTestTablet("aa", "1.1.1.1", 1001, KsTestUnsharded, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	queries := []string{"select id from t1", "select id from", "select id from t1"}
+
+	for _, stopOnError := range []bool{false, true} {
+		session := &vtgatepb.Session{
+			Autocommit:   true,
+			TargetString: "@primary",
+		}
+		_, qrl, err := rpcVTGate.ExecuteBatch(context.Background(), session, queries, nil, stopOnError)
+		require.NoError(t, err)
+		require.Len(t, qrl, 3)
+
+		require.NoError(t, qrl[0].QueryError)
+		assert.Len(t, qrl[0].QueryResult.Rows, 1)
+		require.Error(t, qrl[1].QueryError)
+		assert.Contains(t, qrl[1].QueryError.Error(), "syntax error")
+		if stopOnError {
+			assert.Nil(t, qrl[2].QueryResult)
+			assert.Equal(t, vtrpcpb.Code_ABORTED, vterrors.Code(qrl[2].QueryError))
+			assert.EqualError(t, qrl[2].QueryError, "query 2 was not executed because query 1 failed")
+		} else {
+			require.NoError(t, qrl[2].QueryError)
+			assert.Len(t, qrl[2].QueryResult.Rows, 1)
+		}
+	}
+
+	// Each query has its own bind variables.
+	_, _, err := rpcVTGate.ExecuteBatch(context.Background(), &vtgatepb.Session{TargetString: "@primary"}, queries, []map[string]*querypb.BindVariable{{}}, false)
+	assert.EqualError(t, err, "1 bind variable sets for 3 queries")
+}
+
+func TestVTGateCheckoutSession(t *testing.T) {
+	createSandbox(KsTestUnsharded)
+	hcVTGateTest.Reset()
+	sbc := hcVTGateTest.AddTestTablet("aa", "1.1.1.1", 1001, KsTestUnsharded, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	ctx := context.Background()
+
+	session, _, err := rpcVTGate.Execute(ctx, &vtgatepb.Session{TargetString: "@primary"}, "begin", nil)
+	require.NoError(t, err)
+	session, _, err = rpcVTGate.Execute(ctx, session, "select id from t1", nil)
+	require.NoError(t, err)
+
+	token, err := rpcVTGate.CheckoutSession(ctx, session, 0)
+	require.NoError(t, err)
+	got, err := rpcVTGate.CheckinSession(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(session, got))
+	_, err = rpcVTGate.CheckinSession(ctx, token)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+
+	// A token from another vtgate, or from before a restart, is reported
+	// as such.
+	var other sessionCheckouts
+	otherToken, err := other.checkout(session, time.Minute, rpcVTGate.CloseSession)
+	require.NoError(t, err)
+	_, err = rpcVTGate.CheckinSession(ctx, otherToken)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.ErrorContains(t, err, "this session was not checked out on this vtgate")
+	_, err = other.checkin(otherToken)
+	require.NoError(t, err)
+
+	_, err = rpcVTGate.CheckoutSession(ctx, session, -time.Second)
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = rpcVTGate.CheckoutSession(ctx, session, *sessionCheckoutMaxTimeout+time.Second)
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+
+	// An expired checkout rolls back the transaction of the session.
+	token, err = rpcVTGate.CheckoutSession(ctx, session, time.Millisecond)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return sbc.ReleaseCount.Get() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = rpcVTGate.CheckinSession(ctx, token)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+}
+
 func TestVTGateStreamExecute(t *testing.T) {
 	ks := KsTestUnsharded
 	shard := "0"
@@ -225,7 +305,7 @@ func TestVTGateBindVarError(t *testing.T) {
 	}, {
 		name: "ExecuteBatch",
 		f: func() error {
-			_, _, err := rpcVTGate.ExecuteBatch(ctx, session, []string{""}, []map[string]*querypb.BindVariable{bindVars})
+			_, _, err := rpcVTGate.ExecuteBatch(ctx, session, []string{""}, []map[string]*querypb.BindVariable{bindVars}, false)
 			return err
 		},
 	}, {
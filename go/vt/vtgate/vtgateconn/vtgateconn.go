@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
@@ -68,6 +69,17 @@ func (conn *VTGateConn) SessionFromPb(sn *vtgatepb.Session) *VTGateSession {
 	}
 }
 
+// CheckinSession returns a VTGateSession for the session checked out
+// with the token by VTGateSession.Checkout, possibly from another
+// application instance.
+func (conn *VTGateConn) CheckinSession(ctx context.Context, token string) (*VTGateSession, error) {
+	session, err := conn.impl.CheckinSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return conn.SessionFromPb(session), nil
+}
+
 // ResolveTransaction resolves the 2pc transaction.
 func (conn *VTGateConn) ResolveTransaction(ctx context.Context, dtid string) error {
 	return conn.impl.ResolveTransaction(ctx, dtid)
@@ -111,8 +123,18 @@ func (sn *VTGateSession) Execute(ctx context.Context, query string, bindVars map
 }
 
 // ExecuteBatch executes a list of queries on vtgate within the current transaction.
+// The error of each query is returned in its QueryResponse.
 func (sn *VTGateSession) ExecuteBatch(ctx context.Context, query []string, bindVars []map[string]*querypb.BindVariable) ([]sqltypes.QueryResponse, error) {
-	session, res, errs := sn.impl.ExecuteBatch(ctx, sn.session, query, bindVars)
+	session, res, errs := sn.impl.ExecuteBatch(ctx, sn.session, query, bindVars, false)
+	sn.session = session
+	return res, errs
+}
+
+// ExecuteBatchStopOnError is like ExecuteBatch, but the queries that follow
+// the first failed query are not executed, and their QueryResponse carries
+// an ABORTED error.
+func (sn *VTGateSession) ExecuteBatchStopOnError(ctx context.Context, query []string, bindVars []map[string]*querypb.BindVariable) ([]sqltypes.QueryResponse, error) {
+	session, res, errs := sn.impl.ExecuteBatch(ctx, sn.session, query, bindVars, true)
 	sn.session = session
 	return res, errs
 }
@@ -135,6 +157,17 @@ func (sn *VTGateSession) Prepare(ctx context.Context, query string, bindVars map
 	return fields, err
 }
 
+// Checkout hands the session, with its open transaction, over to vtgate,
+// and returns the token with which VTGateConn.CheckinSession resumes it.
+// The session is closed, which rolls back its transaction, if it is not
+// checked in within timeout. A zero timeout means the maximum timeout of
+// vtgate. The checked out session lives in the memory of the vtgate, so
+// the checkin must reach the same vtgate. The VTGateSession must not be
+// used after Checkout.
+func (sn *VTGateSession) Checkout(ctx context.Context, timeout time.Duration) (string, error) {
+	return sn.impl.CheckoutSession(ctx, sn.session, timeout)
+}
+
 //
 // The rest of this file is for the protocol implementations.
 //
@@ -146,7 +179,7 @@ type Impl interface {
 	Execute(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable) (*vtgatepb.Session, *sqltypes.Result, error)
 
 	// ExecuteBatch executes a non-streaming queries on vtgate. This is a V3 function.
-	ExecuteBatch(ctx context.Context, session *vtgatepb.Session, queryList []string, bindVarsList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error)
+	ExecuteBatch(ctx context.Context, session *vtgatepb.Session, queryList []string, bindVarsList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error)
 
 	// StreamExecute executes a streaming query on vtgate. This is a V3 function.
 	StreamExecute(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable) (sqltypes.ResultStream, error)
@@ -157,6 +190,13 @@ type Impl interface {
 	// CloseSession closes the session provided by rolling back any active transaction.
 	CloseSession(ctx context.Context, session *vtgatepb.Session) error
 
+	// CheckoutSession hands the session over to vtgate, and returns the
+	// token that CheckinSession takes to return it.
+	CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error)
+
+	// CheckinSession returns the session checked out with the token.
+	CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error)
+
 	// ResolveTransaction resolves the specified 2pc transaction.
 	ResolveTransaction(ctx context.Context, dtid string) error
 
@@ -20,6 +20,7 @@ package vtgateservice
 
 import (
 	"context"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 
@@ -34,7 +35,7 @@ import (
 type VTGateService interface {
 	// V3 API
 	Execute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) (*vtgatepb.Session, *sqltypes.Result, error)
-	ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error)
+	ExecuteBatch(ctx context.Context, session *vtgatepb.Session, sqlList []string, bindVariablesList []map[string]*querypb.BindVariable, stopOnError bool) (*vtgatepb.Session, []sqltypes.QueryResponse, error)
 	StreamExecute(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error
 	// Prepare statement support
 	Prepare(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) (*vtgatepb.Session, []*querypb.Field, error)
@@ -44,6 +45,12 @@ type VTGateService interface {
 	// but does not affect the query statistics.
 	CloseSession(ctx context.Context, session *vtgatepb.Session) error
 
+	// CheckoutSession hands the session over to vtgate until it is
+	// returned by CheckinSession with the returned token, or until
+	// the timeout expires and the session is closed.
+	CheckoutSession(ctx context.Context, session *vtgatepb.Session, timeout time.Duration) (string, error)
+	CheckinSession(ctx context.Context, token string) (*vtgatepb.Session, error)
+
 	// 2PC support
 	ResolveTransaction(ctx context.Context, dtid string) error
 
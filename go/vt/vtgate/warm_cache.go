@@ -0,0 +1,320 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/ioutil2"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	warmCacheFile     = flag.String("warm_cache_file", "", "Path of the file where vtgate saves its routing and its most executed query plans on a graceful shutdown, and which it loads them from on startup to warm its caches before serving, as long as the topo versions they were saved with are still current. The file holds the queries as sent by the clients. Empty disables the warm cache")
+	warmCacheMaxPlans = flag.Int("warm_cache_max_plans", 10000, "The maximum number of query plans saved to --warm_cache_file, the most executed first")
+	warmCacheMaxAge   = flag.Duration("warm_cache_max_age", 24*time.Hour, "The age after which --warm_cache_file is ignored on startup")
+	warmCacheTimeout  = flag.Duration("warm_cache_timeout", 30*time.Second, "The maximum time vtgate spends warming its caches on startup, and saving them on shutdown")
+
+	warmCachePlans = stats.NewCountersWithSingleLabel("WarmCachePlans", "Query plans loaded from --warm_cache_file on startup, by result: Warmed, Stale when their keyspace or the vschema changed since they were saved, or Failed", "Result")
+)
+
+// warmCacheVersion is the version of the format of the warm cache file. A
+// file of another version is ignored.
+const warmCacheVersion = 1
+
+// warmCache is what a vtgate saves on a graceful shutdown, so that the next
+// vtgate of the same cell serves its first queries warm. The routing and the
+// plans are only warmed if the topo versions of the SrvKeyspace and the
+// SrvVSchema they were resolved with did not change since.
+type warmCache struct {
+	Version int
+	Cell    string
+	SavedAt time.Time
+	// SrvVSchemaVersion is the topo version of the SrvVSchema of the cell.
+	SrvVSchemaVersion string
+	Keyspaces         []*warmKeyspace
+	Plans             []*warmPlan
+}
+
+// warmKeyspace is the routing of a keyspace: the topo version of its
+// SrvKeyspace in the cell, and the targets that had serving tablets.
+type warmKeyspace struct {
+	Name               string
+	SrvKeyspaceVersion string
+	Targets            []*warmTarget
+}
+
+type warmTarget struct {
+	Shard      string
+	TabletType string
+}
+
+// warmPlan is a cached plan, as the target and the query it is built from.
+type warmPlan struct {
+	Target    string
+	Query     string
+	ExecCount uint64
+}
+
+// initWarmCache warms the caches of the executor and of the health check
+// from --warm_cache_file, and saves them to it on a graceful shutdown. It
+// runs before vtgate serves, so the first queries are routed and planned
+// from the warm caches.
+func initWarmCache(e *Executor, hc discovery.HealthCheck) {
+	ctx, cancel := context.WithTimeout(context.Background(), *warmCacheTimeout)
+	start := time.Now()
+	if err := loadWarmCache(ctx, e, hc, *warmCacheFile); err != nil {
+		log.Warningf("Not warming the caches from %v: %v", *warmCacheFile, err)
+	} else {
+		log.Infof("Warmed the caches from %v in %v", *warmCacheFile, time.Since(start))
+	}
+	cancel()
+
+	servenv.OnTermSync(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), *warmCacheTimeout)
+		defer cancel()
+		if err := saveWarmCache(ctx, e, hc, *warmCacheFile); err != nil {
+			log.Errorf("Cannot save the caches to %v: %v", *warmCacheFile, err)
+		}
+	})
+}
+
+// topoVersion returns the version of a file of the topo, or "" if it cannot
+// be read.
+func topoVersion(ctx context.Context, conn topo.Conn, filePath string) string {
+	_, version, err := conn.Get(ctx, filePath)
+	if err != nil {
+		return ""
+	}
+	return version.String()
+}
+
+func srvKeyspacePath(keyspace string) string {
+	return path.Join(topo.KeyspacesPath, keyspace, topo.SrvKeyspaceFile)
+}
+
+// saveWarmCache saves the routing of the keyspaces of the vschema and the
+// most executed plans of the plan cache to a file.
+func saveWarmCache(ctx context.Context, e *Executor, hc discovery.HealthCheck, filePath string) error {
+	ts, err := e.serv.GetTopoServer()
+	if err != nil {
+		return err
+	}
+	conn, err := ts.ConnForCell(ctx, e.cell)
+	if err != nil {
+		return err
+	}
+
+	wc := &warmCache{
+		Version:           warmCacheVersion,
+		Cell:              e.cell,
+		SavedAt:           time.Now(),
+		SrvVSchemaVersion: topoVersion(ctx, conn, topo.SrvVSchemaFile),
+	}
+
+	keyspaces := make(map[string]*warmKeyspace)
+	if vschema := e.VSchema(); vschema != nil {
+		for name := range vschema.Keyspaces {
+			keyspaces[name] = &warmKeyspace{Name: name}
+		}
+	}
+	// The health check has a status per cell of each target.
+	targets := make(map[discovery.KeyspaceShardTabletType]bool)
+	for _, status := range hc.CacheStatus() {
+		ks, ok := keyspaces[status.Target.Keyspace]
+		if !ok || targets[discovery.KeyFromTarget(status.Target)] {
+			continue
+		}
+		for _, th := range status.TabletsStats {
+			if th.Serving {
+				targets[discovery.KeyFromTarget(status.Target)] = true
+				ks.Targets = append(ks.Targets, &warmTarget{
+					Shard:      status.Target.Shard,
+					TabletType: topoproto.TabletTypeLString(status.Target.TabletType),
+				})
+				break
+			}
+		}
+	}
+	for _, ks := range keyspaces {
+		ks.SrvKeyspaceVersion = topoVersion(ctx, conn, srvKeyspacePath(ks.Name))
+		wc.Keyspaces = append(wc.Keyspaces, ks)
+	}
+	sort.Slice(wc.Keyspaces, func(i, j int) bool {
+		return wc.Keyspaces[i].Name < wc.Keyspaces[j].Name
+	})
+
+	e.plans.ForEach(func(value any) bool {
+		plan := value.(*engine.Plan)
+		if plan.Query != "" {
+			wc.Plans = append(wc.Plans, &warmPlan{
+				Target:    plan.Target,
+				Query:     plan.Query,
+				ExecCount: atomic.LoadUint64(&plan.ExecCount),
+			})
+		}
+		return true
+	})
+	sort.SliceStable(wc.Plans, func(i, j int) bool {
+		return wc.Plans[i].ExecCount > wc.Plans[j].ExecCount
+	})
+	if len(wc.Plans) > *warmCacheMaxPlans {
+		wc.Plans = wc.Plans[:*warmCacheMaxPlans]
+	}
+
+	data, err := json.Marshal(wc)
+	if err != nil {
+		return err
+	}
+	// The file holds queries, which may contain sensitive literals.
+	if err := ioutil2.WriteFileAtomic(filePath, data, 0600); err != nil {
+		return err
+	}
+	log.Infof("Saved the routing of %d keyspaces and %d query plans to %v", len(wc.Keyspaces), len(wc.Plans), filePath)
+	return nil
+}
+
+// loadWarmCache warms the caches from a file saved by saveWarmCache. The
+// SrvKeyspace of the keyspaces whose version did not change is resolved.
+// If the SrvVSchema did not change either, the plans of these keyspaces are
+// built and cached. Then vtgate waits for their targets to have serving
+// tablets.
+func loadWarmCache(ctx context.Context, e *Executor, hc discovery.HealthCheck, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	wc := &warmCache{}
+	if err := json.Unmarshal(data, wc); err != nil {
+		return err
+	}
+	switch {
+	case wc.Version != warmCacheVersion:
+		return fmt.Errorf("the file is of version %d, expected %d", wc.Version, warmCacheVersion)
+	case wc.Cell != e.cell:
+		return fmt.Errorf("the file was saved by a vtgate of cell %v", wc.Cell)
+	case time.Since(wc.SavedAt) > *warmCacheMaxAge:
+		return fmt.Errorf("the file was saved at %v, more than --warm_cache_max_age ago", wc.SavedAt)
+	}
+
+	ts, err := e.serv.GetTopoServer()
+	if err != nil {
+		return err
+	}
+	conn, err := ts.ConnForCell(ctx, e.cell)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]bool)
+	var targets []*querypb.Target
+	for _, ks := range wc.Keyspaces {
+		if version := topoVersion(ctx, conn, srvKeyspacePath(ks.Name)); version == "" || version != ks.SrvKeyspaceVersion {
+			log.Infof("The SrvKeyspace of %v changed since %v was saved, not warming its routing", ks.Name, filePath)
+			continue
+		}
+		current[ks.Name] = true
+		if _, err := e.serv.GetSrvKeyspace(ctx, e.cell, ks.Name); err != nil {
+			log.Warningf("Cannot resolve the SrvKeyspace of %v: %v", ks.Name, err)
+		}
+		for _, t := range ks.Targets {
+			tabletType, err := topoproto.ParseTabletType(t.TabletType)
+			if err != nil {
+				continue
+			}
+			targets = append(targets, &querypb.Target{Keyspace: ks.Name, Shard: t.Shard, TabletType: tabletType})
+		}
+	}
+
+	if version := topoVersion(ctx, conn, topo.SrvVSchemaFile); version == "" || version != wc.SrvVSchemaVersion {
+		warmCachePlans.Add("Stale", int64(len(wc.Plans)))
+		log.Infof("The SrvVSchema changed since %v was saved, not warming the plan cache", filePath)
+	} else {
+		// Plans are checked against the keyspace settings when they are
+		// built, and the plan cache is cleared when they are first read
+		// otherwise.
+		if *keyspaceSettingsRefreshInterval > 0 {
+			e.ksSettings.refresh(ctx, ts, e)
+		}
+		if err := e.warmPlans(ctx, wc.Plans, current); err != nil {
+			return err
+		}
+	}
+
+	// The plans are built first, as waiting for a target without any
+	// serving tablet takes all the time left.
+	if len(targets) > 0 {
+		if err := hc.WaitForAllServingTablets(ctx, targets); err != nil {
+			log.Warningf("Not all the targets vtgate routed to before its restart have serving tablets yet: %v", err)
+		}
+	}
+	return nil
+}
+
+// warmPlans builds and caches the plans whose keyspace is current, if any.
+func (e *Executor) warmPlans(ctx context.Context, plans []*warmPlan, current map[string]bool) error {
+	for _, wp := range plans {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		keyspace, _, _, err := e.ParseDestinationTarget(wp.Target)
+		if err == nil && keyspace != "" && !current[keyspace] {
+			warmCachePlans.Add("Stale", 1)
+			continue
+		}
+		if err := e.warmPlan(ctx, wp); err != nil {
+			warmCachePlans.Add("Failed", 1)
+			continue
+		}
+		warmCachePlans.Add("Warmed", 1)
+	}
+	return nil
+}
+
+// warmPlan builds and caches the plan of a query, as a new session of the
+// target of the plan would.
+func (e *Executor) warmPlan(ctx context.Context, wp *warmPlan) error {
+	safeSession := NewSafeSession(&vtgatepb.Session{TargetString: wp.Target})
+	vcursor, err := newVCursorImpl(ctx, safeSession, sqlparser.MarginComments{}, e, nil, e.vm, e.VSchema(), e.resolver.resolver, e.serv, e.warnShardedOnly, e.pv)
+	if err != nil {
+		return err
+	}
+	defer vcursor.CancelContext()
+	_, err = e.getPlan(vcursor, wp.Query, sqlparser.MarginComments{}, make(map[string]*querypb.BindVariable), safeSession, nil)
+	return err
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/discovery"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestWarmCache(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "warm_cache.json")
+	defer func(file string) { *warmCacheFile = file }(*warmCacheFile)
+	*warmCacheFile = filePath
+
+	executor, _, _, _ := createExecutorEnv()
+	ts, err := executor.serv.GetTopoServer()
+	require.NoError(t, err)
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "aa", &vschemapb.SrvVSchema{}))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "aa", KsTestSharded, &topodatapb.SrvKeyspace{}))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "aa", KsTestUnsharded, &topodatapb.SrvKeyspace{}))
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	hc.AddTestTablet("aa", "1.1.1.1", 1001, KsTestSharded, "-20", topodatapb.TabletType_REPLICA, true, 1, nil)
+	hc.AddTestTablet("aa", "1.1.1.2", 1001, KsTestSharded, "20-40", topodatapb.TabletType_REPLICA, false, 1, nil)
+
+	queries := map[string]string{
+		KsTestSharded:   "select id from user where id = 1",
+		KsTestUnsharded: "select id from main1 where id = 1",
+	}
+	for target, query := range queries {
+		_, err := executor.Execute(ctx, "TestWarmCache", NewSafeSession(&vtgatepb.Session{TargetString: target}), query, nil)
+		require.NoError(t, err)
+	}
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 2)
+
+	require.NoError(t, saveWarmCache(ctx, executor, hc, filePath))
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	wc := &warmCache{}
+	require.NoError(t, json.Unmarshal(data, wc))
+	assert.Equal(t, "aa", wc.Cell)
+	assert.NotEmpty(t, wc.SrvVSchemaVersion)
+	assert.Len(t, wc.Plans, 2)
+	for _, ks := range wc.Keyspaces {
+		if ks.Name == KsTestSharded {
+			assert.NotEmpty(t, ks.SrvKeyspaceVersion)
+			// Only the targets with serving tablets are saved.
+			assert.Equal(t, []*warmTarget{{Shard: "-20", TabletType: "replica"}}, ks.Targets)
+		}
+	}
+
+	executor.plans.Clear()
+	warmed := warmCachePlans.Counts()["Warmed"]
+	require.NoError(t, loadWarmCache(ctx, executor, hc, filePath))
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 2)
+	assert.Equal(t, warmed+2, warmCachePlans.Counts()["Warmed"])
+	// The warmed plans are the ones the sessions look up.
+	hits := executor.plans.Hits()
+	_, err = executor.Execute(ctx, "TestWarmCache", NewSafeSession(&vtgatepb.Session{TargetString: KsTestSharded}), queries[KsTestSharded], nil)
+	require.NoError(t, err)
+	assert.Equal(t, hits+1, executor.plans.Hits())
+
+	// The plans of a keyspace whose SrvKeyspace changed are not warmed.
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "aa", KsTestSharded, &topodatapb.SrvKeyspace{}))
+	executor.plans.Clear()
+	stale := warmCachePlans.Counts()["Stale"]
+	require.NoError(t, loadWarmCache(ctx, executor, hc, filePath))
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 1)
+	assert.Equal(t, stale+1, warmCachePlans.Counts()["Stale"])
+
+	// No plan is warmed if the SrvVSchema changed.
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "aa", &vschemapb.SrvVSchema{}))
+	executor.plans.Clear()
+	require.NoError(t, loadWarmCache(ctx, executor, hc, filePath))
+	executor.plans.Wait()
+	assertCacheSize(t, executor.plans, 0)
+
+	// A missing file is not an error, an old one is.
+	require.NoError(t, loadWarmCache(ctx, executor, hc, filepath.Join(t.TempDir(), "missing.json")))
+	defer func(maxAge time.Duration) { *warmCacheMaxAge = maxAge }(*warmCacheMaxAge)
+	*warmCacheMaxAge = time.Nanosecond
+	assert.ErrorContains(t, loadWarmCache(ctx, executor, hc, filePath), "more than --warm_cache_max_age ago")
+}
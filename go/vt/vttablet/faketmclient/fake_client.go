@@ -92,11 +92,21 @@ func (client *FakeTabletManagerClient) GetSchema(ctx context.Context, tablet *to
 	return client.tmc.GetSchema(ctx, tablet, request)
 }
 
+// GetSchemaVersion is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetSchemaVersion(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	return client.tmc.GetSchemaVersion(ctx, tablet, request)
+}
+
 // GetPermissions is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	return &tabletmanagerdatapb.Permissions{}, nil
 }
 
+// GetThrottlerStatus is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetThrottlerStatus(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	return &tabletmanagerdatapb.GetThrottlerStatusResponse{Status: &tabletmanagerdatapb.ThrottlerStatus{}}, nil
+}
+
 // LockTables is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) LockTables(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return nil
@@ -126,6 +136,16 @@ func (client *FakeTabletManagerClient) ChangeType(ctx context.Context, tablet *t
 	return nil
 }
 
+// DrainTablet is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) DrainTablet(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	return &tabletmanagerdatapb.DrainTabletResponse{Draining: !request.Undo}, nil
+}
+
+// FreezeWrites is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) FreezeWrites(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	return &tabletmanagerdatapb.FreezeWritesResponse{Frozen: !request.Undo}, nil
+}
+
 // RefreshState is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) RefreshState(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return nil
@@ -136,6 +156,11 @@ func (client *FakeTabletManagerClient) RunHealthCheck(ctx context.Context, table
 	return nil
 }
 
+// UpdateThrottlerConfig is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) UpdateThrottlerConfig(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	return &tabletmanagerdatapb.UpdateThrottlerConfigResponse{Status: &tabletmanagerdatapb.ThrottlerStatus{}}, nil
+}
+
 // ReloadSchema is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) ReloadSchema(ctx context.Context, tablet *topodatapb.Tablet, waitPosition string) error {
 	return nil
@@ -171,6 +196,16 @@ func (client *FakeTabletManagerClient) ExecuteFetchAsApp(ctx context.Context, ta
 	return &querypb.QueryResult{}, nil
 }
 
+// ExplainQuery is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) ExplainQuery(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	return &tabletmanagerdatapb.ExplainQueryResponse{Result: &querypb.QueryResult{}}, nil
+}
+
+// GetHotRows is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetHotRows(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	return &tabletmanagerdatapb.GetHotRowsResponse{}, nil
+}
+
 //
 // Replication related methods
 //
@@ -180,6 +215,11 @@ func (client *FakeTabletManagerClient) ReplicationStatus(ctx context.Context, ta
 	return &replicationdatapb.Status{}, nil
 }
 
+// GetReplicationLagHistory is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetReplicationLagHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	return &tabletmanagerdatapb.GetReplicationLagHistoryResponse{}, nil
+}
+
 // FullStatus is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) FullStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.FullStatus, error) {
 	return &replicationdatapb.FullStatus{}, nil
@@ -35,6 +35,7 @@ import (
 	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/protocompat"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
@@ -248,6 +249,16 @@ func (client *Client) GetSchema(ctx context.Context, tablet *topodatapb.Tablet,
 	return response.SchemaDefinition, nil
 }
 
+// GetSchemaVersion is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetSchemaVersion(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.GetSchemaVersion(ctx, request)
+}
+
 // GetPermissions is part of the tmclient.TabletManagerClient interface.
 func (client *Client) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -262,6 +273,16 @@ func (client *Client) GetPermissions(ctx context.Context, tablet *topodatapb.Tab
 	return response.Permissions, nil
 }
 
+// GetThrottlerStatus is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetThrottlerStatus(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.GetThrottlerStatus(ctx, request)
+}
+
 //
 // Various read-write methods
 //
@@ -302,6 +323,26 @@ func (client *Client) ChangeType(ctx context.Context, tablet *topodatapb.Tablet,
 	return err
 }
 
+// DrainTablet is part of the tmclient.TabletManagerClient interface.
+func (client *Client) DrainTablet(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.DrainTablet(ctx, request)
+}
+
+// FreezeWrites is part of the tmclient.TabletManagerClient interface.
+func (client *Client) FreezeWrites(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.FreezeWrites(ctx, request)
+}
+
 // RefreshState is part of the tmclient.TabletManagerClient interface.
 func (client *Client) RefreshState(ctx context.Context, tablet *topodatapb.Tablet) error {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -324,6 +365,16 @@ func (client *Client) RunHealthCheck(ctx context.Context, tablet *topodatapb.Tab
 	return err
 }
 
+// UpdateThrottlerConfig is part of the tmclient.TabletManagerClient interface.
+func (client *Client) UpdateThrottlerConfig(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.UpdateThrottlerConfig(ctx, request)
+}
+
 // ReloadSchema is part of the tmclient.TabletManagerClient interface.
 func (client *Client) ReloadSchema(ctx context.Context, tablet *topodatapb.Tablet, waitPosition string) error {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -510,6 +561,26 @@ func (client *Client) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.
 	return response.Result, nil
 }
 
+// ExplainQuery is part of the tmclient.TabletManagerClient interface.
+func (client *Client) ExplainQuery(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.ExplainQuery(ctx, request)
+}
+
+// GetHotRows is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetHotRows(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.GetHotRows(ctx, request)
+}
+
 //
 // Replication related methods
 //
@@ -528,6 +599,16 @@ func (client *Client) ReplicationStatus(ctx context.Context, tablet *topodatapb.
 	return response.Status, nil
 }
 
+// GetReplicationLagHistory is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetReplicationLagHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.GetReplicationLagHistory(ctx, request)
+}
+
 // FullStatus is part of the tmclient.TabletManagerClient interface.
 func (client *Client) FullStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.FullStatus, error) {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -782,15 +863,7 @@ func (client *Client) DemotePrimary(ctx context.Context, tablet *topodatapb.Tabl
 	if err != nil {
 		return nil, err
 	}
-	status := response.PrimaryStatus
-	if status == nil {
-		// We are assuming this means a response came from an older server.
-		status = &replicationdatapb.PrimaryStatus{
-			Position:     response.DeprecatedPosition, //nolint
-			FilePosition: "",
-		}
-	}
-	return status, nil
+	return protocompat.DemotePrimaryStatus(response), nil
 }
 
 // UndoDemotePrimary is part of the tmclient.TabletManagerClient interface.
@@ -871,10 +944,8 @@ func (client *Client) StopReplicationAndGetStatus(ctx context.Context, tablet *t
 	if err != nil {
 		return nil, nil, err
 	}
-	return response.HybridStatus, &replicationdatapb.StopReplicationStatus{ //nolint
-		Before: response.Status.Before,
-		After:  response.Status.After,
-	}, nil
+	hybridStatus, status = protocompat.StopReplicationStatus(response)
+	return hybridStatus, status, nil
 }
 
 // PromoteReplica is part of the tmclient.TabletManagerClient interface.
@@ -89,6 +89,12 @@ func (s *server) GetSchema(ctx context.Context, request *tabletmanagerdatapb.Get
 	return response, err
 }
 
+func (s *server) GetSchemaVersion(ctx context.Context, request *tabletmanagerdatapb.GetSchemaVersionRequest) (response *tabletmanagerdatapb.GetSchemaVersionResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetSchemaVersion", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.GetSchemaVersion(ctx, request)
+}
+
 func (s *server) GetPermissions(ctx context.Context, request *tabletmanagerdatapb.GetPermissionsRequest) (response *tabletmanagerdatapb.GetPermissionsResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "GetPermissions", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
@@ -100,6 +106,12 @@ func (s *server) GetPermissions(ctx context.Context, request *tabletmanagerdatap
 	return response, err
 }
 
+func (s *server) GetThrottlerStatus(ctx context.Context, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (response *tabletmanagerdatapb.GetThrottlerStatusResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetThrottlerStatus", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.GetThrottlerStatus(ctx, request)
+}
+
 //
 // Various read-write methods
 //
@@ -125,6 +137,18 @@ func (s *server) ChangeType(ctx context.Context, request *tabletmanagerdatapb.Ch
 	return response, s.tm.ChangeType(ctx, request.TabletType, request.GetSemiSync())
 }
 
+func (s *server) DrainTablet(ctx context.Context, request *tabletmanagerdatapb.DrainTabletRequest) (response *tabletmanagerdatapb.DrainTabletResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "DrainTablet", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.DrainTablet(ctx, request)
+}
+
+func (s *server) FreezeWrites(ctx context.Context, request *tabletmanagerdatapb.FreezeWritesRequest) (response *tabletmanagerdatapb.FreezeWritesResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "FreezeWrites", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.FreezeWrites(ctx, request)
+}
+
 func (s *server) RefreshState(ctx context.Context, request *tabletmanagerdatapb.RefreshStateRequest) (response *tabletmanagerdatapb.RefreshStateResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "RefreshState", request, response, true /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
@@ -140,6 +164,12 @@ func (s *server) RunHealthCheck(ctx context.Context, request *tabletmanagerdatap
 	return response, nil
 }
 
+func (s *server) UpdateThrottlerConfig(ctx context.Context, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (response *tabletmanagerdatapb.UpdateThrottlerConfigResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "UpdateThrottlerConfig", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.UpdateThrottlerConfig(ctx, request)
+}
+
 func (s *server) ReloadSchema(ctx context.Context, request *tabletmanagerdatapb.ReloadSchemaRequest) (response *tabletmanagerdatapb.ReloadSchemaResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "ReloadSchema", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
@@ -246,6 +276,26 @@ func (s *server) ExecuteFetchAsApp(ctx context.Context, request *tabletmanagerda
 	return response, nil
 }
 
+func (s *server) ExplainQuery(ctx context.Context, request *tabletmanagerdatapb.ExplainQueryRequest) (response *tabletmanagerdatapb.ExplainQueryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "ExplainQuery", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response, err = s.tm.ExplainQuery(ctx, request)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return response, nil
+}
+
+func (s *server) GetHotRows(ctx context.Context, request *tabletmanagerdatapb.GetHotRowsRequest) (response *tabletmanagerdatapb.GetHotRowsResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetHotRows", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response, err = s.tm.GetHotRows(ctx, request)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return response, nil
+}
+
 //
 // Replication related methods
 //
@@ -261,6 +311,16 @@ func (s *server) ReplicationStatus(ctx context.Context, request *tabletmanagerda
 	return response, err
 }
 
+func (s *server) GetReplicationLagHistory(ctx context.Context, request *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (response *tabletmanagerdatapb.GetReplicationLagHistoryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetReplicationLagHistory", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response, err = s.tm.GetReplicationLagHistory(ctx, request)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return response, nil
+}
+
 func (s *server) FullStatus(ctx context.Context, request *tabletmanagerdatapb.FullStatusRequest) (response *tabletmanagerdatapb.FullStatusResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "FullStatus", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
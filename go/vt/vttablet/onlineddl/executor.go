@@ -173,6 +173,10 @@ type Executor struct {
 	schemaInitialized bool
 
 	initVreplicationDDLOnce sync.Once
+
+	// lastPartitionManagement is the time of the last rotation of the
+	// partitions of the tables with a partition policy.
+	lastPartitionManagement time.Time
 }
 
 type cancellableMigration struct {
@@ -201,9 +205,27 @@ func PTOSCFileName() (fileName string, isOverride bool) {
 	return "/usr/bin/pt-online-schema-change", false
 }
 
+// retainTablesDuration returns how long the tables dropped or left behind by
+// migrations are held before they are purged: the table GC hold of the keyspace
+// settings, or --retain_online_ddl_tables if the keyspace has none.
+func (e *Executor) retainTablesDuration(ctx context.Context) time.Duration {
+	if e.ts != nil {
+		ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+		defer cancel()
+
+		settings, err := e.ts.GetKeyspaceSettings(ctx, e.keyspace)
+		if err != nil {
+			log.Warningf("Failed to read the table GC hold of keyspace %s, using --retain_online_ddl_tables: %v", e.keyspace, err)
+		} else if hold := settings.TableGCHoldDuration(); hold > 0 {
+			return hold
+		}
+	}
+	return *retainOnlineDDLTables
+}
+
 // newGCTableRetainTime returns the time until which a new GC table is to be retained
-func newGCTableRetainTime() time.Time {
-	return time.Now().UTC().Add(*retainOnlineDDLTables)
+func (e *Executor) newGCTableRetainTime(ctx context.Context) time.Time {
+	return time.Now().UTC().Add(e.retainTablesDuration(ctx))
 }
 
 // NewExecutor creates a new gh-ost executor.
@@ -701,7 +723,7 @@ func (e *Executor) cutOverVReplMigration(ctx context.Context, s *VReplStream) er
 	// in that place as possible.
 	var stowawayTableName string
 	if !isVreplicationTestSuite {
-		stowawayTableName, err = schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+		stowawayTableName, err = schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 		if err != nil {
 			return err
 		}
@@ -2221,7 +2243,7 @@ func (e *Executor) evaluateDeclarativeDiff(ctx context.Context, onlineDDL *schem
 		return nil, err
 	}
 	// Is this CREATE TABLE or CREATE VIEW?
-	comparisonTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	comparisonTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2346,7 +2368,7 @@ func (e *Executor) executeDropDDLActionMigration(ctx context.Context, onlineDDL
 	}
 
 	var toTableName string
-	onlineDDL.SQL, toTableName, err = schema.GenerateRenameStatementWithUUID(onlineDDL.Table, schema.HoldTableGCState, onlineDDL.GetGCUUID(), newGCTableRetainTime())
+	onlineDDL.SQL, toTableName, err = schema.GenerateRenameStatementWithUUID(onlineDDL.Table, schema.HoldTableGCState, onlineDDL.GetGCUUID(), e.newGCTableRetainTime(ctx))
 	if err != nil {
 		return failMigration(err)
 	}
@@ -2402,7 +2424,7 @@ func (e *Executor) executeCreateDDLActionMigration(ctx context.Context, onlineDD
 	}
 	// from now on, whether a VIEW or a TABLE, they get the same treatment
 
-	sentryArtifactTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	sentryArtifactTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 	if err != nil {
 		return failMigration(err)
 	}
@@ -2438,7 +2460,7 @@ func (e *Executor) executeCreateDDLActionMigration(ctx context.Context, onlineDD
 // of temporary third table. It returns the name of generated third table, though normally
 // that table should not exist before & after operation, only _during_ operation time.
 func (e *Executor) generateSwapTablesStatement(ctx context.Context, tableName1, tableName2 string) (query string, swapTableName string, err error) {
-	swapTableName, err = schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	swapTableName, err = schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 	if err != nil {
 		return "", swapTableName, err
 	}
@@ -2477,7 +2499,7 @@ func (e *Executor) renameTableIfApplicable(ctx context.Context, fromTableName, t
 }
 
 func (e *Executor) executeAlterViewOnline(ctx context.Context, onlineDDL *schema.OnlineDDL) (err error) {
-	artifactViewName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	artifactViewName, err := schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 	if err != nil {
 		return err
 	}
@@ -2587,7 +2609,7 @@ func (e *Executor) executeSpecialAlterDDLActionMigrationIfApplicable(ctx context
 		}
 	case dropRangePartitionSpecialOperation:
 		dropPartition := func() error {
-			artifactTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+			artifactTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, e.newGCTableRetainTime(ctx))
 			if err != nil {
 				return err
 			}
@@ -3414,7 +3436,7 @@ func (e *Executor) gcArtifacts(ctx context.Context) error {
 		return err
 	}
 	query, err := sqlparser.ParseAndBind(sqlSelectUncollectedArtifacts,
-		sqltypes.Int64BindVariable(int64((*retainOnlineDDLTables).Seconds())),
+		sqltypes.Int64BindVariable(int64(e.retainTablesDuration(ctx).Seconds())),
 	)
 	if err != nil {
 		return err
@@ -3522,6 +3544,9 @@ func (e *Executor) onMigrationCheckTick() {
 	if err := e.gcArtifacts(ctx); err != nil {
 		log.Error(err)
 	}
+	if err := e.managePartitions(ctx); err != nil {
+		log.Error(err)
+	}
 }
 
 func (e *Executor) updateMigrationStartedTimestamp(ctx context.Context, uuid string) error {
@@ -3993,7 +4018,7 @@ func (e *Executor) SubmitMigration(
 	log.Infof("SubmitMigration: request to submit migration %s; action=%s, table=%s", onlineDDL.UUID, actionStr, onlineDDL.Table)
 
 	revertedUUID, _ := onlineDDL.GetRevertUUID() // Empty value if the migration is not actually a REVERT. Safe to ignore error.
-	retainArtifactsSeconds := int64((*retainOnlineDDLTables).Seconds())
+	retainArtifactsSeconds := int64(e.retainTablesDuration(ctx).Seconds())
 	query, err := sqlparser.ParseAndBind(sqlInsertMigration,
 		sqltypes.StringBindVariable(onlineDDL.UUID),
 		sqltypes.StringBindVariable(e.keyspace),
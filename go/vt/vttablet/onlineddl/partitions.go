@@ -0,0 +1,295 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var partitionManagementInterval = flag.Duration("partition_management_interval", time.Hour, "Interval between two rotations of the RANGE partitions of the tables with a partition policy, on the primary. 0 disables the rotation")
+
+const (
+	// partitionManagementContext is the migration context of the partition
+	// rotations submitted by the executor.
+	partitionManagementContext = "partition-management"
+	// partitionManagementOptions runs the rotations as plain ADD PARTITION
+	// and DROP PARTITION statements, without copying the table.
+	partitionManagementOptions = "--fast-range-rotation"
+	// maxAddedPartitions is the maximum number of partitions added to a
+	// table at a time, so that a table whose last partition is long past
+	// catches up over several rotations.
+	maxAddedPartitions = 100
+	// toDaysUnixEpoch is the value of TO_DAYS('1970-01-01').
+	toDaysUnixEpoch = 719528
+)
+
+// managePartitions rotates the RANGE partitions of the tables with a
+// partition policy, by submitting the ALTER TABLE statements that add the
+// future partitions and drop the expired ones as migrations. It runs once
+// every partition management interval, and not while the migrations of the
+// previous rotation are pending.
+func (e *Executor) managePartitions(ctx context.Context) error {
+	if *partitionManagementInterval <= 0 || e.ts == nil {
+		return nil
+	}
+	if time.Since(e.lastPartitionManagement) < *partitionManagementInterval {
+		return nil
+	}
+	e.lastPartitionManagement = time.Now()
+
+	topoCtx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+	policies, err := e.ts.GetPartitionPolicies(topoCtx, e.keyspace)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	pendingUUIDs, err := e.readPendingMigrationsUUIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, uuid := range pendingUUIDs {
+		onlineDDL, _, err := e.readMigration(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if onlineDDL.MigrationContext == partitionManagementContext {
+			return nil
+		}
+	}
+
+	for _, policy := range policies {
+		createTable, err := e.getCreateTableStatement(ctx, policy.Table)
+		if err != nil {
+			log.Errorf("managePartitions: cannot read table %s: %v", policy.Table, err)
+			continue
+		}
+		alters, err := rangePartitionRotation(createTable, policy, time.Now().UTC())
+		if err != nil {
+			log.Errorf("managePartitions: %v", err)
+			continue
+		}
+		for _, alter := range alters {
+			onlineDDL, err := schema.NewOnlineDDL(e.keyspace, policy.Table, alter, schema.NewDDLStrategySetting(schema.DDLStrategyVitess, partitionManagementOptions), partitionManagementContext, "")
+			if err != nil {
+				return err
+			}
+			stmt, err := sqlparser.Parse(onlineDDL.SQL)
+			if err != nil {
+				return err
+			}
+			if _, err := e.SubmitMigration(ctx, stmt); err != nil {
+				return err
+			}
+			log.Infof("managePartitions: submitted migration %s: %s", onlineDDL.UUID, alter)
+		}
+	}
+	return nil
+}
+
+// partitionBoundary converts the boundaries of the RANGE partitions of a
+// table to and from times. The partitions are either RANGE COLUMNS on a
+// DATE or DATETIME column, or RANGE on TO_DAYS() or UNIX_TIMESTAMP() of a
+// column.
+type partitionBoundary int
+
+const (
+	columnsBoundary partitionBoundary = iota
+	toDaysBoundary
+	unixTimestampBoundary
+)
+
+func newPartitionBoundary(part *sqlparser.PartitionOption) (partitionBoundary, error) {
+	if len(part.ColList) == 1 {
+		return columnsBoundary, nil
+	}
+	if fn, ok := part.Expr.(*sqlparser.FuncExpr); ok && len(fn.Exprs) == 1 {
+		switch fn.Name.Lowered() {
+		case "to_days":
+			return toDaysBoundary, nil
+		case "unix_timestamp":
+			return unixTimestampBoundary, nil
+		}
+	}
+	return 0, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported RANGE partitioning, expected RANGE COLUMNS on a date column, or RANGE on TO_DAYS() or UNIX_TIMESTAMP() of a column")
+}
+
+func (b partitionBoundary) toTime(def *sqlparser.PartitionDefinition) (time.Time, error) {
+	if def.Options == nil || def.Options.ValueRange == nil || def.Options.ValueRange.Maxvalue || len(def.Options.ValueRange.Range) != 1 {
+		return time.Time{}, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported boundary of partition %s", def.Name.String())
+	}
+	lit, ok := def.Options.ValueRange.Range[0].(*sqlparser.Literal)
+	if !ok {
+		return time.Time{}, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported boundary of partition %s", def.Name.String())
+	}
+	switch b {
+	case columnsBoundary:
+		for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, lit.Val); err == nil {
+				return t, nil
+			}
+		}
+	case toDaysBoundary:
+		if days, err := strconv.ParseInt(lit.Val, 10, 64); err == nil {
+			return time.Unix((days-toDaysUnixEpoch)*24*3600, 0).UTC(), nil
+		}
+	case unixTimestampBoundary:
+		if seconds, err := strconv.ParseInt(lit.Val, 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC(), nil
+		}
+	}
+	return time.Time{}, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported boundary of partition %s: %s", def.Name.String(), lit.Val)
+}
+
+func (b partitionBoundary) fromTime(t time.Time) sqlparser.Expr {
+	switch b {
+	case toDaysBoundary:
+		return sqlparser.NewIntLiteral(strconv.FormatInt(t.Unix()/(24*3600)+toDaysUnixEpoch, 10))
+	case unixTimestampBoundary:
+		return sqlparser.NewIntLiteral(strconv.FormatInt(t.Unix(), 10))
+	default:
+		return sqlparser.NewStrLiteral(t.Format("2006-01-02"))
+	}
+}
+
+// startOfInterval returns the start of the interval of a policy that holds
+// a time: its day, the Monday of its week, or the first day of its month.
+func startOfInterval(interval string, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case topo.PartitionIntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case topo.PartitionIntervalMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// addIntervals adds n intervals of a policy to a time.
+func addIntervals(interval string, t time.Time, n int) time.Time {
+	switch interval {
+	case topo.PartitionIntervalWeek:
+		return t.AddDate(0, 0, 7*n)
+	case topo.PartitionIntervalMonth:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// partitionName returns the name of the partition of an interval, after
+// the start of the interval.
+func partitionName(interval string, start time.Time) string {
+	if interval == topo.PartitionIntervalMonth {
+		return start.Format("p200601")
+	}
+	return start.Format("p20060102")
+}
+
+// rangePartitionRotation returns the ALTER TABLE statements that rotate the
+// RANGE partitions of a table according to its partition policy: the
+// partitions up to the precreated intervals after the current one are added
+// after the last partition, and the partitions whose rows are all older
+// than the retention are dropped. The last partition of the table is never
+// dropped. The statements are generated by schemadiff, as one statement per
+// added or dropped partition.
+func rangePartitionRotation(createTable *sqlparser.CreateTable, policy *topo.PartitionPolicy, now time.Time) ([]string, error) {
+	part := createTable.TableSpec.PartitionOption
+	if part == nil || part.Type != sqlparser.RangeType || part.SubPartition != nil || len(part.Definitions) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s is not RANGE partitioned", policy.Table)
+	}
+	boundary, err := newPartitionBoundary(part)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "table %s", policy.Table)
+	}
+	boundaries := make([]time.Time, len(part.Definitions))
+	names := make(map[string]bool, len(part.Definitions))
+	for i, def := range part.Definitions {
+		if boundaries[i], err = boundary.toTime(def); err != nil {
+			return nil, vterrors.Wrapf(err, "table %s", policy.Table)
+		}
+		names[def.Name.Lowered()] = true
+	}
+
+	current := startOfInterval(policy.Interval, now)
+	definitions := part.Definitions
+	if policy.Retention > 0 {
+		cutoff := addIntervals(policy.Interval, current, -policy.Retention)
+		for len(definitions) > 1 && !boundaries[0].After(cutoff) {
+			definitions = definitions[1:]
+			boundaries = boundaries[1:]
+		}
+	}
+	definitions = append([]*sqlparser.PartitionDefinition{}, definitions...)
+
+	last := boundaries[len(boundaries)-1]
+	target := addIntervals(policy.Interval, current, policy.Precreate+1)
+	for added := 0; last.Before(target) && added < maxAddedPartitions; added++ {
+		next := addIntervals(policy.Interval, startOfInterval(policy.Interval, last), 1)
+		name := partitionName(policy.Interval, last)
+		if names[strings.ToLower(name)] {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s already has a partition %s", policy.Table, name)
+		}
+		definitions = append(definitions, &sqlparser.PartitionDefinition{
+			Name: sqlparser.NewIdentifierCI(name),
+			Options: &sqlparser.PartitionDefinitionOptions{
+				ValueRange: &sqlparser.PartitionValueRange{
+					Type:  sqlparser.LessThanType,
+					Range: sqlparser.ValTuple{boundary.fromTime(next)},
+				},
+			},
+		})
+		last = next
+	}
+
+	from, err := schemadiff.NewCreateTableEntity(sqlparser.CloneRefOfCreateTable(createTable))
+	if err != nil {
+		return nil, err
+	}
+	desired := sqlparser.CloneRefOfCreateTable(createTable)
+	desired.TableSpec.PartitionOption.Definitions = definitions
+	to, err := schemadiff.NewCreateTableEntity(desired)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := from.Diff(to, &schemadiff.DiffHints{RangeRotationStrategy: schemadiff.RangeRotationDistinctStatements})
+	if err != nil {
+		return nil, err
+	}
+	var alters []string
+	for ; diff != nil && !diff.IsEmpty(); diff = diff.SubsequentDiff() {
+		alters = append(alters, diff.StatementString())
+	}
+	return alters, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+func TestRangePartitionRotation(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2022, 10, 19, 15, 0, 0, 0, time.UTC)
+	tcases := []struct {
+		name   string
+		create string
+		policy *topo.PartitionPolicy
+		alters []string
+		err    string
+	}{{
+		name: "daily range columns",
+		create: "create table t (id int, d date, primary key (id, d)) partition by range columns (d) (" +
+			"partition p20221016 values less than ('2022-10-17') engine InnoDB, " +
+			"partition p20221017 values less than ('2022-10-18') engine InnoDB, " +
+			"partition p20221018 values less than ('2022-10-19') engine InnoDB, " +
+			"partition p20221019 values less than ('2022-10-20') engine InnoDB)",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "day", Precreate: 2, Retention: 2},
+		alters: []string{
+			"alter table t drop partition p20221016",
+			"alter table t add partition (partition p20221020 values less than ('2022-10-21'))",
+			"alter table t add partition (partition p20221021 values less than ('2022-10-22'))",
+		},
+	}, {
+		name: "monthly to_days",
+		create: "create table t (id int, ts datetime, primary key (id, ts)) partition by range (to_days(ts)) (" +
+			"partition p202209 values less than (738794), " +
+			"partition p202210 values less than (738825))",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "month", Precreate: 1},
+		alters: []string{
+			"alter table t add partition (partition p202211 values less than (738855))",
+		},
+	}, {
+		name: "weekly unix_timestamp",
+		create: "create table t (id int, ts timestamp, primary key (id, ts)) partition by range (unix_timestamp(ts)) (" +
+			"partition p20221010 values less than (1665964800))",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "week", Precreate: 1, Retention: 1},
+		alters: []string{
+			"alter table t add partition (partition p20221017 values less than (1666569600))",
+			"alter table t add partition (partition p20221024 values less than (1667174400))",
+		},
+	}, {
+		name: "up to date",
+		create: "create table t (id int, d date, primary key (id, d)) partition by range columns (d) (" +
+			"partition p20221019 values less than ('2022-10-20'), " +
+			"partition p20221020 values less than ('2022-10-21'))",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "day", Precreate: 1, Retention: 30},
+	}, {
+		name: "the last partition is kept",
+		create: "create table t (id int, d date, primary key (id, d)) partition by range columns (d) (" +
+			"partition p20220101 values less than ('2022-01-02'), " +
+			"partition p20220102 values less than ('2022-01-03'))",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "month", Precreate: 1, Retention: 1},
+		alters: []string{
+			"alter table t drop partition p20220101",
+			"alter table t add partition (partition p202201 values less than ('2022-02-01'))",
+			"alter table t add partition (partition p202202 values less than ('2022-03-01'))",
+			"alter table t add partition (partition p202203 values less than ('2022-04-01'))",
+			"alter table t add partition (partition p202204 values less than ('2022-05-01'))",
+			"alter table t add partition (partition p202205 values less than ('2022-06-01'))",
+			"alter table t add partition (partition p202206 values less than ('2022-07-01'))",
+			"alter table t add partition (partition p202207 values less than ('2022-08-01'))",
+			"alter table t add partition (partition p202208 values less than ('2022-09-01'))",
+			"alter table t add partition (partition p202209 values less than ('2022-10-01'))",
+			"alter table t add partition (partition p202210 values less than ('2022-11-01'))",
+			"alter table t add partition (partition p202211 values less than ('2022-12-01'))",
+		},
+	}, {
+		name:   "not partitioned",
+		create: "create table t (id int primary key)",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "day", Precreate: 1},
+		err:    "table t is not RANGE partitioned",
+	}, {
+		name: "maxvalue",
+		create: "create table t (id int, d date, primary key (id, d)) partition by range columns (d) (" +
+			"partition p20221019 values less than ('2022-10-20'), " +
+			"partition pmax values less than maxvalue)",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "day", Precreate: 1},
+		err:    "table t: unsupported boundary of partition pmax",
+	}, {
+		name: "unsupported expression",
+		create: "create table t (id int, ts datetime, primary key (id, ts)) partition by range (year(ts)) (" +
+			"partition p2022 values less than (2023))",
+		policy: &topo.PartitionPolicy{Table: "t", Interval: "day", Precreate: 1},
+		err:    "table t: unsupported RANGE partitioning, expected RANGE COLUMNS on a date column, or RANGE on TO_DAYS() or UNIX_TIMESTAMP() of a column",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			stmt, err := sqlparser.ParseStrictDDL(tcase.create)
+			require.NoError(t, err)
+			alters, err := rangePartitionRotation(stmt.(*sqlparser.CreateTable), tcase.policy, now)
+			if tcase.err != "" {
+				assert.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.alters, alters)
+		})
+	}
+}
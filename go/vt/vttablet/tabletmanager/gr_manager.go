@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+)
+
+const groupMemberOnline = "ONLINE"
+
+var (
+	manageGroupReplication = flag.Bool("manage_group_replication", false, "if set, the mysqld of the tablet is a member of a MySQL Group Replication cluster in single-primary mode, and the tablet follows the primary elections of the group: it becomes the primary of its shard when its mysqld is elected, and steps down when another member is. The replication of the mysqld is left to the group")
+
+	groupPrimaryChanges = stats.NewCountersWithSingleLabel("GroupReplicationPrimaryChanges", "Changes of the tablet type that follow the primary elections of the Group Replication cluster, by new tablet type", "type")
+)
+
+// grManager runs a poller that maps the primary elections of the MySQL
+// Group Replication cluster of the tablet to the primary of its shard.
+// When the group elects the mysqld of the tablet, the tablet becomes the
+// primary, and the shard sync loop records it in the shard record. The
+// previous primary then steps down, either because its own poller sees that
+// its mysqld is no longer the primary of the group, or because the shard
+// record has a more recent primary term.
+type grManager struct {
+	ctx      context.Context
+	tm       *TabletManager
+	ticks    *timer.Timer
+	errorLog *logutil.ThrottledLogger
+}
+
+func newGRManager(ctx context.Context, tm *TabletManager, interval time.Duration) *grManager {
+	return &grManager{
+		ctx:      ctx,
+		tm:       tm,
+		ticks:    timer.NewTimer(interval),
+		errorLog: logutil.NewThrottledLogger("GroupReplication", 60*time.Second),
+	}
+}
+
+// Open starts the poller if the tablet follows Group Replication.
+func (gm *grManager) Open() {
+	if !*manageGroupReplication {
+		return
+	}
+	log.Info("Group Replication Manager: starting")
+	gm.ticks.Start(gm.check)
+}
+
+// Close stops the poller.
+func (gm *grManager) Close() {
+	gm.ticks.Stop()
+}
+
+func (gm *grManager) check() {
+	// Changing the tablet type requires the action lock. If an action is
+	// in progress, the next tick checks the group again.
+	if !gm.tm.tryLock() {
+		return
+	}
+	defer gm.tm.unlock()
+	gm.checkActionLocked()
+}
+
+func (gm *grManager) checkActionLocked() {
+	tabletType := gm.tm.Tablet().Type
+	if tabletType != topodatapb.TabletType_PRIMARY && !topo.IsReplicaType(tabletType) {
+		// Tablets that are backing up, restoring or drained stay as they are.
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(gm.ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+	member, err := gm.tm.MysqlDaemon.GroupMember(ctx)
+	if err != nil {
+		gm.errorLog.Errorf("Failed to read the state of the mysqld in its replication group: %v", err)
+		return
+	}
+	isGroupPrimary := member.State == groupMemberOnline && member.Primary
+
+	var newType topodatapb.TabletType
+	switch {
+	case isGroupPrimary && tabletType != topodatapb.TabletType_PRIMARY:
+		log.Infof("The mysqld was elected primary of its replication group. Promoting the tablet to %v.", topodatapb.TabletType_PRIMARY)
+		newType = topodatapb.TabletType_PRIMARY
+	case !isGroupPrimary && tabletType == topodatapb.TabletType_PRIMARY:
+		newType = gm.tm.baseTabletType
+		if newType == topodatapb.TabletType_PRIMARY {
+			newType = topodatapb.TabletType_REPLICA
+		}
+		log.Warningf("The mysqld is no longer the primary of its replication group (member state %v). Stepping down to %v.", member.State, newType)
+	default:
+		return
+	}
+	// The group manages the read-only state of its members, so the tablet
+	// only changes its type.
+	if err := gm.tm.tmState.ChangeTabletType(ctx, newType, DBActionNone); err != nil {
+		gm.errorLog.Errorf("Failed to change the tablet type to %v: %v", newType, err)
+		return
+	}
+	groupPrimaryChanges.Add(topoproto.TabletTypeLString(newType), 1)
+}
+
+// activeReparentsDisabled returns true if vttablet must not change the
+// replication of its mysqld, either because active reparents are disabled,
+// because the replication is managed by Group Replication, or because the
+// mysqld is not managed by Vitess.
+func (tm *TabletManager) activeReparentsDisabled() bool {
+	return *mysqlctl.DisableActiveReparents || *manageGroupReplication || tm.Unmanaged
+}
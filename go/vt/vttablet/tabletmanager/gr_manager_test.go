@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestGRManagerFollowsGroupPrimary(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	groupPrimaryChanges.ResetAll()
+	tm := newTestTM(t, ts, 100, keyspace, shard)
+	defer tm.Stop()
+	mysqld := tm.MysqlDaemon.(*fakemysqldaemon.FakeMysqlDaemon)
+
+	// A secondary member leaves the tablet a replica.
+	mysqld.CurrentGroupMember = mysql.GroupMember{State: "ONLINE"}
+	tm.grManager.checkActionLocked()
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tm.Tablet().Type)
+
+	// The election of the mysqld promotes the tablet, and the shard record
+	// follows.
+	mysqld.CurrentGroupMember = mysql.GroupMember{State: "ONLINE", Primary: true}
+	tm.grManager.checkActionLocked()
+	ti, err := ts.GetTablet(ctx, tm.tabletAlias)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_PRIMARY, ti.Type)
+	assert.NotNil(t, ti.PrimaryTermStartTime)
+	checkShardRecordWithTimeout(ctx, t, ts, ti.Alias, ti.PrimaryTermStartTime, 1*time.Second)
+
+	// A failure to read the group changes nothing.
+	mysqld.GroupMemberError = errors.New("no group status")
+	tm.grManager.checkActionLocked()
+	assert.Equal(t, topodatapb.TabletType_PRIMARY, tm.Tablet().Type)
+	mysqld.GroupMemberError = nil
+
+	// A member that is no longer online steps down.
+	mysqld.CurrentGroupMember = mysql.GroupMember{State: "UNREACHABLE", Primary: true}
+	tm.grManager.checkActionLocked()
+	checkTabletRecordWithTimeout(ctx, t, ts, tm.tabletAlias, topodatapb.TabletType_REPLICA, nil, 1*time.Second)
+	assert.Equal(t, map[string]int64{"primary": 1, "replica": 1}, groupPrimaryChanges.Counts())
+}
+
+func TestActiveReparentsDisabled(t *testing.T) {
+	defer func(saved bool) { *manageGroupReplication = saved }(*manageGroupReplication)
+
+	tm := &TabletManager{}
+	*manageGroupReplication = false
+	assert.False(t, tm.activeReparentsDisabled())
+	*manageGroupReplication = true
+	assert.True(t, tm.activeReparentsDisabled())
+
+	*manageGroupReplication = false
+	tm.Unmanaged = true
+	assert.True(t, tm.activeReparentsDisabled())
+}
@@ -504,7 +504,7 @@ func (tm *TabletManager) startReplication(ctx context.Context, pos mysql.Positio
 	// contained in the backup. This means that we have everything needed to start
 	// replication at a later time, and since with active parents disabled we do
 	// not ever start replication automatically, we can now safely return.
-	if *mysqlctl.DisableActiveReparents {
+	if tm.activeReparentsDisabled() {
 		return nil
 	}
 
@@ -541,7 +541,7 @@ func (tm *TabletManager) startReplication(ctx context.Context, pos mysql.Positio
 	}
 
 	// Set primary and start replication.
-	if err := tm.MysqlDaemon.SetReplicationSource(ctx, ti.Tablet.MysqlHostname, int(ti.Tablet.MysqlPort), false /* stopReplicationBefore */, !*mysqlctl.DisableActiveReparents /* startReplicationAfter */); err != nil {
+	if err := tm.MysqlDaemon.SetReplicationSource(ctx, ti.Tablet.MysqlHostname, int(ti.Tablet.MysqlPort), false /* stopReplicationBefore */, !tm.activeReparentsDisabled() /* startReplicationAfter */); err != nil {
 		return vterrors.Wrap(err, "MysqlDaemon.SetReplicationSource failed")
 	}
 
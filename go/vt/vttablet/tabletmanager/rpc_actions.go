@@ -24,12 +24,14 @@ import (
 
 	"context"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topotools"
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 // DBAction is used to tell ChangeTabletType whether to call SetReadOnly on change to
@@ -68,6 +70,9 @@ func (tm *TabletManager) GetPermissions(ctx context.Context) (*tabletmanagerdata
 
 // SetReadOnly makes the mysql instance read-only or read-write.
 func (tm *TabletManager) SetReadOnly(ctx context.Context, rdonly bool) error {
+	if err := tm.rejectUnmanaged("SetReadOnly"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -105,6 +110,51 @@ func (tm *TabletManager) changeTypeLocked(ctx context.Context, tabletType topoda
 	return nil
 }
 
+// DrainTablet marks the tablet as draining in its health stream, so that
+// vtgates stop sending it queries, or stops draining it. It returns the
+// queries and transactions that are still in flight, and is called
+// repeatedly to wait for the tablet to become idle.
+func (tm *TabletManager) DrainTablet(ctx context.Context, req *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	if err := tm.QueryServiceControl.SetDraining(!req.Undo); err != nil {
+		return nil, err
+	}
+	queries, transactions := tm.QueryServiceControl.InFlight()
+	return &tabletmanagerdatapb.DrainTabletResponse{
+		Draining:         tm.QueryServiceControl.IsDraining(),
+		InFlightQueries:  queries,
+		OpenTransactions: transactions,
+	}, nil
+}
+
+// FreezeWrites makes the tablet reject the queries that write, for a duration
+// or until it is undone, or execute them again. The tablet does not persist
+// it, and executes the queries that write again when it restarts.
+func (tm *TabletManager) FreezeWrites(ctx context.Context, req *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	if req.Undo {
+		tm.QueryServiceControl.UnfreezeWrites()
+	} else {
+		duration, _, err := protoutil.DurationFromProto(req.Duration)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid duration: %v", err)
+		}
+		if duration < 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid duration: %v", duration)
+		}
+		var until time.Time
+		if duration > 0 {
+			until = time.Now().Add(duration)
+		}
+		tm.QueryServiceControl.FreezeWrites(until)
+	}
+
+	frozen, until := tm.QueryServiceControl.WritesFrozen()
+	resp := &tabletmanagerdatapb.FreezeWritesResponse{Frozen: frozen}
+	if frozen && !until.IsZero() {
+		resp.ExpireTime = protoutil.TimeToProto(until)
+	}
+	return resp, nil
+}
+
 // Sleep sleeps for the duration
 func (tm *TabletManager) Sleep(ctx context.Context, duration time.Duration) {
 	if err := tm.lock(ctx); err != nil {
@@ -42,14 +42,22 @@ type RPCTM interface {
 
 	GetSchema(ctx context.Context, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error)
 
+	GetSchemaVersion(ctx context.Context, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error)
+
 	GetPermissions(ctx context.Context) (*tabletmanagerdatapb.Permissions, error)
 
+	GetThrottlerStatus(ctx context.Context, req *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error)
+
 	// Various read-write methods
 
 	SetReadOnly(ctx context.Context, rdonly bool) error
 
 	ChangeType(ctx context.Context, tabletType topodatapb.TabletType, semiSync bool) error
 
+	DrainTablet(ctx context.Context, req *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error)
+
+	FreezeWrites(ctx context.Context, req *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error)
+
 	Sleep(ctx context.Context, duration time.Duration)
 
 	ExecuteHook(ctx context.Context, hk *hook.Hook) *hook.HookResult
@@ -58,6 +66,8 @@ type RPCTM interface {
 
 	RunHealthCheck(ctx context.Context)
 
+	UpdateThrottlerConfig(ctx context.Context, req *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error)
+
 	ReloadSchema(ctx context.Context, waitPosition string) error
 
 	PreflightSchema(ctx context.Context, changes []string) ([]*tabletmanagerdatapb.SchemaChangeResult, error)
@@ -76,11 +86,17 @@ type RPCTM interface {
 
 	ExecuteFetchAsApp(ctx context.Context, query []byte, maxrows int) (*querypb.QueryResult, error)
 
+	ExplainQuery(ctx context.Context, req *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error)
+
+	GetHotRows(ctx context.Context, req *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error)
+
 	// Replication related methods
 	PrimaryStatus(ctx context.Context) (*replicationdatapb.PrimaryStatus, error)
 
 	ReplicationStatus(ctx context.Context) (*replicationdatapb.Status, error)
 
+	GetReplicationLagHistory(ctx context.Context, req *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error)
+
 	FullStatus(ctx context.Context) (*replicationdatapb.FullStatus, error)
 
 	StopReplication(ctx context.Context) error
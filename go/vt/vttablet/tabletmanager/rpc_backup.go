@@ -37,6 +37,9 @@ const (
 
 // Backup takes a db backup and sends it to the BackupStorage
 func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger logutil.Logger, allowPrimary bool) error {
+	if err := tm.rejectUnmanaged("Backup"); err != nil {
+		return err
+	}
 	if tm.Cnf == nil {
 		return fmt.Errorf("cannot perform backup without my.cnf, please restart vttablet with a my.cnf file specified")
 	}
@@ -153,6 +156,9 @@ func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger log
 // RestoreFromBackup deletes all local data and then restores the data from the latest backup [at
 // or before the backupTime value if specified]
 func (tm *TabletManager) RestoreFromBackup(ctx context.Context, logger logutil.Logger, backupTime time.Time) error {
+	if err := tm.rejectUnmanaged("RestoreFromBackup"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
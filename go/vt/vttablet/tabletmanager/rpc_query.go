@@ -18,15 +18,24 @@ package tabletmanager
 
 import (
 	"context"
+	"flag"
+	"strings"
+	"time"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
+var explainQueryTimeout = flag.Duration("explain_query_timeout", 30*time.Second, "The time after which the query of an ExplainQuery RPC is killed. Requests can only ask for a shorter timeout")
+
 // ExecuteFetchAsDba will execute the given query, possibly disabling binlogs and reload schema.
 func (tm *TabletManager) ExecuteFetchAsDba(ctx context.Context, query []byte, dbName string, maxrows int, disableBinlogs bool, reloadSchema bool) (*querypb.QueryResult, error) {
 	// get a connection
@@ -131,3 +140,106 @@ func (tm *TabletManager) ExecuteQuery(ctx context.Context, query []byte, dbName
 	result, err := tm.QueryServiceControl.QueryService().Execute(ctx, target, string(query), nil, 0, 0, nil)
 	return sqltypes.ResultToProto3(result), err
 }
+
+// ExplainQuery runs EXPLAIN, or EXPLAIN ANALYZE, for the given query in a
+// read-only transaction of the App user, so that the plan of a query can be
+// inspected without direct access to MySQL.
+func (tm *TabletManager) ExplainQuery(ctx context.Context, req *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	query, err := explainStatement(req)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := *explainQueryTimeout
+	requested, ok, err := protoutil.DurationFromProto(req.Timeout)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "invalid timeout")
+	}
+	if ok && requested > 0 && requested < timeout {
+		timeout = requested
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	qr, err := tm.MysqlDaemon.FetchReadOnlyQuery(ctx, query, 10000)
+	if err != nil {
+		return nil, err
+	}
+	return &tabletmanagerdatapb.ExplainQueryResponse{
+		Query:  query,
+		Result: sqltypes.ResultToProto3(qr),
+	}, nil
+}
+
+// explainStatement returns the EXPLAIN statement for the request, with the
+// bind variables substituted. Only the statements that EXPLAIN does not
+// execute are accepted, and only SELECTs without INTO for EXPLAIN ANALYZE.
+func explainStatement(req *tabletmanagerdatapb.ExplainQueryRequest) (string, error) {
+	stmt, err := sqlparser.Parse(req.Query)
+	if err != nil {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot parse query: %v", err)
+	}
+	switch stmt.(type) {
+	case sqlparser.SelectStatement:
+		hasInto := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if into, ok := node.(*sqlparser.SelectInto); ok && into != nil {
+				hasInto = true
+			}
+			return !hasInto, nil
+		}, stmt)
+		if hasInto {
+			return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot explain a SELECT ... INTO")
+		}
+	case *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+		if req.Analyze {
+			return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "EXPLAIN ANALYZE executes the query, it is only allowed for SELECT statements")
+		}
+	default:
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot explain %s statements, only SELECT, INSERT, UPDATE, DELETE and REPLACE", sqlparser.ASTToStatementType(stmt))
+	}
+
+	format := strings.ToUpper(req.Format)
+	switch format {
+	case "", "TRADITIONAL", "JSON", "TREE":
+	default:
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown EXPLAIN format %s, expected TRADITIONAL, JSON or TREE", req.Format)
+	}
+	if req.Analyze && format != "" && format != "TREE" {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "EXPLAIN ANALYZE only supports the TREE format")
+	}
+
+	query, err := sqlparser.NewParsedQuery(stmt).GenerateQuery(req.BindVariables, nil)
+	if err != nil {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot substitute bind variables: %v", err)
+	}
+	switch {
+	case req.Analyze:
+		return "explain analyze " + query, nil
+	case format != "":
+		return "explain format=" + strings.ToLower(format) + " " + query, nil
+	}
+	return "explain " + query, nil
+}
+
+// GetHotRows returns the rows that were accessed the most recently, as tracked
+// by the hot row detection of the tablet.
+func (tm *TabletManager) GetHotRows(ctx context.Context, req *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	detector := tm.QueryServiceControl.HotRows()
+	if !detector.Enabled() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "hot row detection is disabled, see --enable_hot_row_detection")
+	}
+
+	rows := detector.Report(req.Table, int(req.Limit))
+	resp := &tabletmanagerdatapb.GetHotRowsResponse{
+		Rows: make([]*tabletmanagerdatapb.HotRow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, &tabletmanagerdatapb.HotRow{
+			Table: row.Table,
+			Key:   row.Key,
+			Count: row.Count,
+		})
+	}
+	return resp, nil
+}
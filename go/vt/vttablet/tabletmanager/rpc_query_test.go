@@ -28,9 +28,14 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
 
 	"github.com/stretchr/testify/require"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 )
 
 func TestTabletManager_ExecuteFetchAsDba(t *testing.T) {
@@ -58,3 +63,122 @@ func TestTabletManager_ExecuteFetchAsDba(t *testing.T) {
 		require.Contains(t, got, w)
 	}
 }
+
+func TestTabletManager_ExplainQuery(t *testing.T) {
+	ctx := context.Background()
+	db := fakesqldb.New(t)
+	defer db.Close()
+	daemon := fakemysqldaemon.NewFakeMysqlDaemon(db)
+	daemon.FetchReadOnlyQueryMap = map[string]*sqltypes.Result{
+		"explain select * from t where id = 1":                   sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|select_type|table", "int64|varchar|varchar"), "1|SIMPLE|t"),
+		"explain format=json select * from t where id = 1":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("EXPLAIN", "varchar"), "{}"),
+		"explain analyze select * from t where id = 1":           sqltypes.MakeTestResult(sqltypes.MakeTestFields("EXPLAIN", "varchar"), "-> Rows fetched before execution"),
+		"explain delete from t where id in (1, 2)":               sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|select_type|table", "int64|varchar|varchar"), "1|DELETE|t"),
+		"explain select * from t where id = 1 and val = 'a\\'b'": sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|select_type|table", "int64|varchar|varchar"), "1|SIMPLE|t"),
+	}
+	tm := &TabletManager{
+		MysqlDaemon:         daemon,
+		QueryServiceControl: tabletservermock.NewController(),
+	}
+
+	tcases := []struct {
+		name  string
+		req   *tabletmanagerdatapb.ExplainQueryRequest
+		query string
+		err   string
+	}{{
+		name:  "select",
+		req:   &tabletmanagerdatapb.ExplainQueryRequest{Query: "select * from t where id = 1"},
+		query: "explain select * from t where id = 1",
+	}, {
+		name:  "json format",
+		req:   &tabletmanagerdatapb.ExplainQueryRequest{Query: "select * from t where id = 1", Format: "json"},
+		query: "explain format=json select * from t where id = 1",
+	}, {
+		name:  "analyze",
+		req:   &tabletmanagerdatapb.ExplainQueryRequest{Query: "select * from t where id = 1", Analyze: true},
+		query: "explain analyze select * from t where id = 1",
+	}, {
+		name: "bind variables",
+		req: &tabletmanagerdatapb.ExplainQueryRequest{
+			Query: "select * from t where id = :id and val = :val",
+			BindVariables: map[string]*querypb.BindVariable{
+				"id":  sqltypes.Int64BindVariable(1),
+				"val": sqltypes.StringBindVariable("a'b"),
+			},
+		},
+		query: "explain select * from t where id = 1 and val = 'a\\'b'",
+	}, {
+		name:  "delete",
+		req:   &tabletmanagerdatapb.ExplainQueryRequest{Query: "delete from t where id in ::ids", BindVariables: map[string]*querypb.BindVariable{"ids": sqltypes.TestBindVariable([]any{1, 2})}},
+		query: "explain delete from t where id in (1, 2)",
+	}, {
+		name: "analyze delete",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "delete from t where id = 1", Analyze: true},
+		err:  "only allowed for SELECT statements",
+	}, {
+		name: "select into",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "select * from t into outfile 'x'"},
+		err:  "cannot explain a SELECT ... INTO",
+	}, {
+		name: "ddl",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "drop table t"},
+		err:  "cannot explain DDL statements",
+	}, {
+		name: "unknown format",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "select 1 from dual", Format: "xml"},
+		err:  "unknown EXPLAIN format xml",
+	}, {
+		name: "analyze with json format",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "select 1 from dual", Analyze: true, Format: "json"},
+		err:  "EXPLAIN ANALYZE only supports the TREE format",
+	}, {
+		name: "missing bind variable",
+		req:  &tabletmanagerdatapb.ExplainQueryRequest{Query: "select * from t where id = :id"},
+		err:  "cannot substitute bind variables",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			resp, err := tm.ExplainQuery(ctx, tcase.req)
+			if tcase.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.query, resp.Query)
+			require.NotEmpty(t, resp.Result.Rows)
+		})
+	}
+}
+
+func TestTabletManager_GetHotRows(t *testing.T) {
+	ctx := context.Background()
+	qsc := tabletservermock.NewController()
+	tm := &TabletManager{
+		QueryServiceControl: qsc,
+	}
+
+	_, err := tm.GetHotRows(ctx, &tabletmanagerdatapb.GetHotRowsRequest{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hot row detection is disabled")
+
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowDetection.Enable = true
+	qsc.HotRowDetector = hotrows.New(tabletenv.NewEnv(config, "GetHotRowsTest"))
+	qsc.HotRowDetector.Record("t1", "id = 1")
+	qsc.HotRowDetector.Record("t1", "id = 1")
+	qsc.HotRowDetector.Record("t1", "id = 2")
+	qsc.HotRowDetector.Record("t2", "id = 1")
+
+	resp, err := tm.GetHotRows(ctx, &tabletmanagerdatapb.GetHotRowsRequest{Table: "t1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Rows, 1)
+	require.Equal(t, "t1", resp.Rows[0].Table)
+	require.Equal(t, "id = 1", resp.Rows[0].Key)
+	require.Equal(t, int64(2), resp.Rows[0].Count)
+
+	resp, err = tm.GetHotRows(ctx, &tabletmanagerdatapb.GetHotRowsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Rows, 3)
+}
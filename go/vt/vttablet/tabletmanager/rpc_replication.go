@@ -28,6 +28,7 @@ import (
 	"context"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
@@ -35,6 +36,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -52,6 +54,29 @@ func (tm *TabletManager) ReplicationStatus(ctx context.Context) (*replicationdat
 	return mysql.ReplicationStatusToProto(status), nil
 }
 
+// GetReplicationLagHistory returns the samples of the replication lag of the
+// tablet taken after the requested time, oldest first.
+func (tm *TabletManager) GetReplicationLagHistory(ctx context.Context, req *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	history := tm.QueryServiceControl.ReplicationLagHistory()
+	if !history.Enabled() {
+		return nil, vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "replication lag history is disabled, see --replication_lag_history_retention, --heartbeat_enable and --enable_replication_reporter")
+	}
+
+	samples := history.Samples(protoutil.TimeFromProto(req.Since))
+	resp := &tabletmanagerdatapb.GetReplicationLagHistoryResponse{
+		Resolution: protoutil.DurationToProto(history.Resolution()),
+		Samples:    make([]*tabletmanagerdatapb.ReplicationLagSample, 0, len(samples)),
+	}
+	for _, sample := range samples {
+		resp.Samples = append(resp.Samples, &tabletmanagerdatapb.ReplicationLagSample{
+			Time:  protoutil.TimeToProto(sample.Time),
+			Lag:   protoutil.DurationToProto(sample.Lag),
+			Error: sample.Error,
+		})
+	}
+	return resp, nil
+}
+
 // FullStatus returns the full status of MySQL including the replication information, semi-sync information, GTID information among others
 func (tm *TabletManager) FullStatus(ctx context.Context) (*replicationdatapb.FullStatus, error) {
 	// Server ID - "select @@global.server_id"
@@ -184,6 +209,9 @@ func (tm *TabletManager) WaitForPosition(ctx context.Context, pos string) error
 // replication or not (using hook if not).
 func (tm *TabletManager) StopReplication(ctx context.Context) error {
 	log.Infof("StopReplication")
+	if err := tm.rejectUnmanaged("StopReplication"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -237,6 +265,9 @@ func (tm *TabletManager) stopIOThreadLocked(ctx context.Context) error {
 // replication or not (using hook if not).
 func (tm *TabletManager) StopReplicationMinimum(ctx context.Context, position string, waitTime time.Duration) (string, error) {
 	log.Infof("StopReplicationMinimum: position: %v waitTime: %v", position, waitTime)
+	if err := tm.rejectUnmanaged("StopReplicationMinimum"); err != nil {
+		return "", err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return "", err
 	}
@@ -265,6 +296,9 @@ func (tm *TabletManager) StopReplicationMinimum(ctx context.Context, position st
 // replication or not (using hook if not).
 func (tm *TabletManager) StartReplication(ctx context.Context, semiSync bool) error {
 	log.Infof("StartReplication")
+	if err := tm.rejectUnmanaged("StartReplication"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -293,6 +327,9 @@ func (tm *TabletManager) StartReplication(ctx context.Context, semiSync bool) er
 // until and including the transactions in `position`
 func (tm *TabletManager) StartReplicationUntilAfter(ctx context.Context, position string, waitTime time.Duration) error {
 	log.Infof("StartReplicationUntilAfter: position: %v waitTime: %v", position, waitTime)
+	if err := tm.rejectUnmanaged("StartReplicationUntilAfter"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -318,6 +355,9 @@ func (tm *TabletManager) GetReplicas(ctx context.Context) ([]string, error) {
 // All binary and relay logs are flushed. All replication positions are reset.
 func (tm *TabletManager) ResetReplication(ctx context.Context) error {
 	log.Infof("ResetReplication")
+	if err := tm.rejectUnmanaged("ResetReplication"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -330,6 +370,9 @@ func (tm *TabletManager) ResetReplication(ctx context.Context) error {
 // InitPrimary enables writes and returns the replication position.
 func (tm *TabletManager) InitPrimary(ctx context.Context, semiSync bool) (string, error) {
 	log.Infof("InitPrimary")
+	if err := tm.rejectUnmanaged("InitPrimary"); err != nil {
+		return "", err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return "", err
 	}
@@ -407,6 +450,9 @@ func (tm *TabletManager) PopulateReparentJournal(ctx context.Context, timeCreate
 // reparent_journal table entry up to context timeout
 func (tm *TabletManager) InitReplica(ctx context.Context, parent *topodatapb.TabletAlias, position string, timeCreatedNS int64, semiSync bool) error {
 	log.Infof("InitReplica: parent: %v  position: %v", parent, position)
+	if err := tm.rejectUnmanaged("InitReplica"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -471,6 +517,9 @@ func (tm *TabletManager) InitReplica(ctx context.Context, parent *topodatapb.Tab
 // If a step fails in the middle, it will try to undo any changes it made.
 func (tm *TabletManager) DemotePrimary(ctx context.Context) (*replicationdatapb.PrimaryStatus, error) {
 	log.Infof("DemotePrimary")
+	if err := tm.rejectUnmanaged("DemotePrimary"); err != nil {
+		return nil, err
+	}
 	// The public version always reverts on partial failure.
 	return tm.demotePrimary(ctx, true /* revertPartialFailure */)
 }
@@ -585,6 +634,9 @@ func (tm *TabletManager) demotePrimary(ctx context.Context, revertPartialFailure
 // and returns its primary position.
 func (tm *TabletManager) UndoDemotePrimary(ctx context.Context, semiSync bool) error {
 	log.Infof("UndoDemotePrimary")
+	if err := tm.rejectUnmanaged("UndoDemotePrimary"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -632,6 +684,9 @@ func (tm *TabletManager) ReplicaWasPromoted(ctx context.Context) error {
 // ResetReplicationParameters resets the replica replication parameters
 func (tm *TabletManager) ResetReplicationParameters(ctx context.Context) error {
 	log.Infof("ResetReplicationParameters")
+	if err := tm.rejectUnmanaged("ResetReplicationParameters"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -654,6 +709,9 @@ func (tm *TabletManager) ResetReplicationParameters(ctx context.Context) error {
 // reparent_journal table entry up to context timeout
 func (tm *TabletManager) SetReplicationSource(ctx context.Context, parentAlias *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool, semiSync bool) error {
 	log.Infof("SetReplicationSource: parent: %v  position: %v force: %v semiSync: %v", parentAlias, waitPosition, forceStartReplication, semiSync)
+	if err := tm.rejectUnmanaged("SetReplicationSource"); err != nil {
+		return err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -832,6 +890,9 @@ func (tm *TabletManager) ReplicaWasRestarted(ctx context.Context, parent *topoda
 // current status.
 func (tm *TabletManager) StopReplicationAndGetStatus(ctx context.Context, stopReplicationMode replicationdatapb.StopReplicationMode) (StopReplicationAndGetStatusResponse, error) {
 	log.Infof("StopReplicationAndGetStatus: mode: %v", stopReplicationMode)
+	if err := tm.rejectUnmanaged("StopReplicationAndGetStatus"); err != nil {
+		return StopReplicationAndGetStatusResponse{}, err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return StopReplicationAndGetStatusResponse{}, err
 	}
@@ -923,6 +984,9 @@ type StopReplicationAndGetStatusResponse struct {
 // PromoteReplica makes the current tablet the primary
 func (tm *TabletManager) PromoteReplica(ctx context.Context, semiSync bool) (string, error) {
 	log.Infof("PromoteReplica")
+	if err := tm.rejectUnmanaged("PromoteReplica"); err != nil {
+		return "", err
+	}
 	if err := tm.lock(ctx); err != nil {
 		return "", err
 	}
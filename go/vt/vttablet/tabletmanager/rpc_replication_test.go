@@ -29,8 +29,15 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
 )
 
 // TestPromoteReplicaReplicationManagerSuccess checks that the replication manager is not running after running PromoteReplica
@@ -80,6 +87,23 @@ func TestPromoteReplicaReplicationManagerFailure(t *testing.T) {
 	require.True(t, tm.replManager.ticks.Running())
 }
 
+// TestUnmanagedTabletRejectsReplicationActions checks that an unmanaged tablet
+// leaves the replication of its mysqld alone.
+func TestUnmanagedTabletRejectsReplicationActions(t *testing.T) {
+	ctx := context.Background()
+	tm := &TabletManager{Unmanaged: true}
+
+	err := tm.StopReplication(ctx)
+	require.EqualError(t, err, "StopReplication is not supported on an unmanaged tablet")
+	require.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	_, err = tm.PromoteReplica(ctx, false)
+	require.EqualError(t, err, "PromoteReplica is not supported on an unmanaged tablet")
+	err = tm.SetReadOnly(ctx, true)
+	require.EqualError(t, err, "SetReadOnly is not supported on an unmanaged tablet")
+	err = tm.Backup(ctx, 1, nil, false)
+	require.EqualError(t, err, "Backup is not supported on an unmanaged tablet")
+}
+
 func captureStderr(f func()) (string, error) {
 	old := os.Stderr // keep backup of the real stderr
 	r, w, err := os.Pipe()
@@ -193,3 +217,45 @@ func TestTabletManager_fixSemiSync(t *testing.T) {
 		})
 	}
 }
+
+func TestTabletManager_GetReplicationLagHistory(t *testing.T) {
+	ctx := context.Background()
+	qsc := tabletservermock.NewController()
+	tm := &TabletManager{
+		QueryServiceControl: qsc,
+	}
+
+	_, err := tm.GetReplicationLagHistory(ctx, &tabletmanagerdatapb.GetReplicationLagHistoryRequest{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "replication lag history is disabled")
+
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Polling
+	config.ReplicationTracker.LagHistoryResolution = 10 * time.Millisecond
+	qsc.LagHistory = repltracker.NewLagHistory(tabletenv.NewEnv(config, "GetReplicationLagHistoryTest"), func() (time.Duration, error) {
+		return 2 * time.Second, nil
+	})
+	start := time.Now()
+	qsc.LagHistory.Open()
+	require.Eventually(t, func() bool {
+		return len(qsc.LagHistory.Samples(time.Time{})) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	qsc.LagHistory.Close()
+
+	resp, err := tm.GetReplicationLagHistory(ctx, &tabletmanagerdatapb.GetReplicationLagHistoryRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(10000000), resp.Resolution.Nanos)
+	require.GreaterOrEqual(t, len(resp.Samples), 2)
+	for _, sample := range resp.Samples {
+		require.Equal(t, int64(2), sample.Lag.Seconds)
+		require.True(t, protoutil.TimeFromProto(sample.Time).After(start))
+		require.Empty(t, sample.Error)
+	}
+
+	// Only the samples taken after the requested time are returned.
+	resp, err = tm.GetReplicationLagHistory(ctx, &tabletmanagerdatapb.GetReplicationLagHistoryRequest{
+		Since: resp.Samples[0].Time,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Samples, len(qsc.LagHistory.Samples(time.Time{}))-1)
+}
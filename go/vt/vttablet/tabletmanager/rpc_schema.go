@@ -34,6 +34,12 @@ func (tm *TabletManager) GetSchema(ctx context.Context, request *tabletmanagerda
 	return tm.MysqlDaemon.GetSchema(ctx, topoproto.TabletDbName(tm.Tablet()), request)
 }
 
+// GetSchemaVersion returns the version of the schema that was in effect at
+// the requested position or time, as recorded by the schema tracker.
+func (tm *TabletManager) GetSchemaVersion(ctx context.Context, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	return tm.QueryServiceControl.SchemaEngine().GetSchemaVersion(ctx, request.Position, request.Timestamp)
+}
+
 // ReloadSchema will reload the schema
 // This doesn't need the action mutex because periodic schema reloads happen
 // in the background anyway.
@@ -27,6 +27,8 @@ import (
 	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 // This file contains the RPC method helpers for the tablet manager.
@@ -49,6 +51,16 @@ func (tm *TabletManager) tryLock() bool {
 	return tm.actionSema.TryAcquire()
 }
 
+// rejectUnmanaged returns an error if the tablet is unmanaged, for the
+// actions that change the replication, the read-only state or the data of
+// the mysqld. An unmanaged mysqld is administered outside of Vitess.
+func (tm *TabletManager) rejectUnmanaged(action string) error {
+	if !tm.Unmanaged {
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s is not supported on an unmanaged tablet", action)
+}
+
 // unlock is the symmetrical action to lock.
 func (tm *TabletManager) unlock() {
 	tm.actionSema.Release()
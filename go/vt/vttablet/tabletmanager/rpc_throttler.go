@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"sort"
+
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// GetThrottlerStatus returns the status of the throttler of the tablet.
+func (tm *TabletManager) GetThrottlerStatus(ctx context.Context, req *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	throttler, err := tm.lagThrottler()
+	if err != nil {
+		return nil, err
+	}
+	return &tabletmanagerdatapb.GetThrottlerStatusResponse{Status: throttlerStatus(throttler)}, nil
+}
+
+// UpdateThrottlerConfig enables or disables the throttler of the tablet, or
+// changes its threshold. The changes are lost when the tablet restarts.
+func (tm *TabletManager) UpdateThrottlerConfig(ctx context.Context, req *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	if req.Enable && req.Disable {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot both enable and disable the throttler")
+	}
+	if req.Threshold < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid throttler threshold: %v", req.Threshold)
+	}
+	throttler, err := tm.lagThrottler()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Threshold > 0 {
+		if err := throttler.SetThreshold(req.Threshold); err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%v", err)
+		}
+	}
+	if req.Enable || req.Disable {
+		if err := throttler.SetEnabled(req.Enable); err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%v", err)
+		}
+	}
+	return &tabletmanagerdatapb.UpdateThrottlerConfigResponse{Status: throttlerStatus(throttler)}, nil
+}
+
+func (tm *TabletManager) lagThrottler() (*throttle.Throttler, error) {
+	throttler := tm.QueryServiceControl.LagThrottler()
+	if throttler == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "tablet has no throttler")
+	}
+	return throttler, nil
+}
+
+// throttlerStatus converts the status of a throttler to its proto.
+func throttlerStatus(throttler *throttle.Throttler) *tabletmanagerdatapb.ThrottlerStatus {
+	status := throttler.Status()
+	resp := &tabletmanagerdatapb.ThrottlerStatus{
+		Enabled:           status.IsEnabled,
+		IsLeader:          status.IsLeader,
+		IsOpen:            status.IsOpen,
+		Threshold:         status.Threshold,
+		AggregatedMetrics: make(map[string]float64, len(status.AggregatedMetrics)),
+	}
+	for name, metricResult := range status.AggregatedMetrics {
+		if value, err := metricResult.Get(); err == nil {
+			resp.AggregatedMetrics[name] = value
+		}
+	}
+	for _, appThrottle := range throttler.ThrottledApps() {
+		resp.ThrottledApps = append(resp.ThrottledApps, appThrottle.AppName)
+	}
+	sort.Strings(resp.ThrottledApps)
+	return resp
+}
@@ -24,7 +24,6 @@ import (
 
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
-	"vitess.io/vitess/go/vt/mysqlctl"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -202,13 +201,14 @@ func syncShardPrimary(ctx context.Context, ts *topo.Server, tablet *topodatapb.T
 // If active reparents are enabled, we demote our own MySQL to a replica and
 // update our tablet type to REPLICA.
 //
-// If active reparents are disabled, we don't touch our MySQL.
+// If active reparents are disabled, or the replication of our MySQL is managed
+// by Group Replication, we don't touch our MySQL.
 // We just directly update our tablet type to REPLICA.
 func (tm *TabletManager) endPrimaryTerm(ctx context.Context, primaryAlias *topodatapb.TabletAlias) error {
 	primaryAliasStr := topoproto.TabletAliasString(primaryAlias)
 	log.Warningf("Another tablet (%v) has won primary election. Stepping down to %v.", primaryAliasStr, tm.baseTabletType)
 
-	if *mysqlctl.DisableActiveReparents {
+	if tm.activeReparentsDisabled() {
 		// Don't touch anything at the MySQL level. Just update tablet state.
 		log.Infof("Active reparents are disabled; updating tablet state only.")
 		changeTypeCtx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
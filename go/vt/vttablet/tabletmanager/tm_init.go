@@ -140,12 +140,20 @@ type TabletManager struct {
 	// in which case metadata creation/population is skipped.
 	MetadataManager *mysqlctl.MetadataManager
 
+	// Unmanaged is set if the mysqld is administered outside of Vitess, as
+	// for a cloud-managed database. The tablet then never changes the
+	// replication or the read-only state of the mysqld.
+	Unmanaged bool
+
 	// tmState manages the TabletManager state.
 	tmState *tmState
 
 	// replManager manages replication.
 	replManager *replManager
 
+	// grManager follows the primary elections of Group Replication.
+	grManager *grManager
+
 	// tabletAlias is saved away from tablet for read-only access
 	tabletAlias *topodatapb.TabletAlias
 
@@ -335,6 +343,7 @@ func mergeTags(a, b map[string]string) map[string]string {
 func (tm *TabletManager) Start(tablet *topodatapb.Tablet, healthCheckInterval time.Duration) error {
 	tm.DBConfigs.DBName = topoproto.TabletDbName(tablet)
 	tm.replManager = newReplManager(tm.BatchCtx, tm, healthCheckInterval)
+	tm.grManager = newGRManager(tm.BatchCtx, tm, healthCheckInterval)
 	tm.tabletAlias = tablet.Alias
 	tm.tmState = newTMState(tm, tablet)
 	tm.actionSema = sync2.NewSemaphore(1, 0)
@@ -374,7 +383,7 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, healthCheckInterval ti
 	}
 
 	if tm.VREngine != nil {
-		tm.VREngine.InitDBConfig(tm.DBConfigs)
+		tm.VREngine.InitDBConfig(tablet.Keyspace, tm.DBConfigs)
 		servenv.OnTerm(tm.VREngine.Close)
 	}
 
@@ -386,6 +395,7 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, healthCheckInterval ti
 	// The following initializations don't need to be done
 	// in any specific order.
 	tm.startShardSync()
+	tm.grManager.Open()
 	tm.exportStats()
 	orc, err := newOrcClient()
 	if err != nil {
@@ -420,6 +430,7 @@ func (tm *TabletManager) Close() {
 	// running during lame duck.
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.grManager.Close()
 
 	// cleanup initialized fields in the tablet entry
 	f := func(tablet *topodatapb.Tablet) error {
@@ -451,6 +462,7 @@ func (tm *TabletManager) Stop() {
 	// here in addition to in Close() because tests do not call Close().
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.grManager.Close()
 
 	if tm.UpdateStream != nil {
 		tm.UpdateStream.Disable()
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"vitess.io/vitess/go/vt/withddl"
 
@@ -32,7 +33,9 @@ import (
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/log"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 )
 
@@ -50,6 +53,9 @@ const (
 	TimestampFormat            = "2006-01-02 15:04:05"
 )
 
+// retryDelay is the delay before a vdiff is retried after a transient error.
+var retryDelay = 10 * time.Second
+
 type controller struct {
 	id              int64 // id from row in _vt.vdiff
 	uuid            string
@@ -132,8 +138,23 @@ func (ct *controller) run(ctx context.Context) {
 	switch state {
 	case PendingState:
 		log.Infof("Starting vdiff")
-		if err := ct.start(ctx, dbClient); err != nil {
+		for retries := int64(0); ; retries++ {
+			err := ct.start(ctx, dbClient)
+			if err == nil {
+				break
+			}
 			log.Errorf("run() failed: %s", err)
+			if ct.shouldRetry(ctx, err, retries) {
+				// The tablets are picked again and the vdiff continues from the
+				// tables and PK ranges that it already completed.
+				insertVDiffLog(ctx, dbClient, ct.id, fmt.Sprintf("Error: %s, retrying (%d of %d)", err, retries+1, ct.options.CoreOptions.MaxRetries))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(retryDelay):
+				}
+				continue
+			}
 			insertVDiffLog(ctx, dbClient, ct.id, fmt.Sprintf("Error: %s", err))
 			if err := ct.updateState(dbClient, ErrorState); err != nil {
 				return
@@ -220,6 +241,24 @@ func newMigrationSource() *migrationSource {
 	return &migrationSource{shardStreamer: &shardStreamer{}}
 }
 
+// shouldRetry returns true if the vdiff should be retried after the given
+// error, i.e. if it has retries left and the error is transient: a lost
+// MySQL connection, or an UNAVAILABLE, ABORTED or DEADLINE_EXCEEDED error,
+// e.g. when a tablet restarts or is reparented.
+func (ct *controller) shouldRetry(ctx context.Context, err error, retries int64) bool {
+	if ctx.Err() != nil || ct.options.CoreOptions == nil || !ct.options.CoreOptions.AutoRetry || retries >= ct.options.CoreOptions.MaxRetries {
+		return false
+	}
+	if mysql.IsConnErr(err) {
+		return true
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_ABORTED, vtrpcpb.Code_DEADLINE_EXCEEDED:
+		return true
+	}
+	return false
+}
+
 func (ct *controller) validate() error {
 	// todo: check if vreplication workflow has errors, what else?
 	return nil
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestShouldRetry(t *testing.T) {
+	unavailable := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tablet is not serving")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		options *tabletmanagerdatapb.VDiffCoreOptions
+		err     error
+		retries int64
+		want    bool
+	}{{
+		name: "unavailable",
+		err:  unavailable,
+		want: true,
+	}, {
+		name: "aborted",
+		err:  vterrors.Errorf(vtrpcpb.Code_ABORTED, "transaction rolled back"),
+		want: true,
+	}, {
+		name: "deadline exceeded",
+		err:  vterrors.Wrap(context.DeadlineExceeded, "stream failed"),
+		want: true,
+	}, {
+		name: "lost connection",
+		err:  mysql.NewSQLError(mysql.CRServerLost, mysql.SSUnknownSQLState, "lost connection"),
+		want: true,
+	}, {
+		name: "invalid argument",
+		err:  vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown table"),
+	}, {
+		name: "failed precondition",
+		err:  vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "schema mismatch"),
+	}, {
+		name: "internal",
+		err:  vterrors.Errorf(vtrpcpb.Code_INTERNAL, "mismatched row count"),
+	}, {
+		name: "unknown",
+		err:  errors.New("unexpected error"),
+	}, {
+		name: "mysql syntax error",
+		err:  mysql.NewSQLError(mysql.ERSyntaxError, mysql.SSUnknownSQLState, "syntax error"),
+	}, {
+		name:    "no retries left",
+		err:     unavailable,
+		retries: 3,
+	}, {
+		name:    "auto retry disabled",
+		options: &tabletmanagerdatapb.VDiffCoreOptions{MaxRetries: 3},
+		err:     unavailable,
+	}, {
+		name: "canceled",
+		ctx:  canceled,
+		err:  unavailable,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			options := tt.options
+			if options == nil {
+				options = &tabletmanagerdatapb.VDiffCoreOptions{AutoRetry: true, MaxRetries: 3}
+			}
+			ct := &controller{options: &tabletmanagerdatapb.VDiffOptions{CoreOptions: options}}
+			assert.Equal(t, tt.want, ct.shouldRetry(ctx, tt.err, tt.retries), tt.err)
+		})
+	}
+}
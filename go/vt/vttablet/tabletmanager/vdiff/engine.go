@@ -32,10 +32,18 @@ import (
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
+// Capability is the capability of the tablets which run the VDiff2 engine.
+const Capability = "vdiff2"
+
+func init() {
+	servenv.RegisterCapability(Capability)
+}
+
 type Engine struct {
 	mu     sync.Mutex
 	isOpen bool
@@ -33,6 +33,7 @@ import (
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -181,6 +182,10 @@ func (wd *workflowDiffer) diff(ctx context.Context) error {
 		}
 		if len(qr.Rows) == 0 {
 			query = fmt.Sprintf(sqlNewVDiffTable, wd.ct.id, encodeString(td.table.Name), tableRows)
+		} else if VDiffState(strings.ToLower(qr.Named().Row()["state"].ToString())) == CompletedState {
+			// The table was completed before the vdiff was retried.
+			log.Infof("table %s was already diffed", td.table.Name)
+			continue
 		} else {
 			// Update the table rows estimate when resuming
 			query = fmt.Sprintf(sqlUpdateTableRows, tableRows, wd.ct.id, encodeString(td.table.Name))
@@ -265,7 +270,7 @@ func (wd *workflowDiffer) buildPlan(dbClient binlogplayer.DBClient, filter *binl
 		}
 	}
 	if len(wd.tableDiffers) == 0 {
-		return fmt.Errorf("no tables found to diff, %s:%s", optTables, specifiedTables)
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "no tables found to diff, %s:%s", optTables, specifiedTables)
 	}
 	return nil
 }
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/featuregate"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -61,6 +62,8 @@ const (
   table_name varbinary(128),
   lastpk varbinary(2000),
   primary key (vrepl_id, table_name))`
+	alterCopyStateRowsCopied  = "ALTER TABLE _vt.copy_state ADD COLUMN rows_copied BIGINT NOT NULL DEFAULT 0"
+	alterCopyStateBytesCopied = "ALTER TABLE _vt.copy_state ADD COLUMN bytes_copied BIGINT NOT NULL DEFAULT 0"
 )
 
 var withDDL *withddl.WithDDL
@@ -74,7 +77,7 @@ const (
 func init() {
 	allddls := append([]string{}, binlogplayer.CreateVReplicationTable()...)
 	allddls = append(allddls, binlogplayer.AlterVReplicationTable...)
-	allddls = append(allddls, createReshardingJournalTable, createCopyState)
+	allddls = append(allddls, createReshardingJournalTable, createCopyState, alterCopyStateRowsCopied, alterCopyStateBytesCopied)
 	allddls = append(allddls, createVReplicationLogTable)
 	withDDL = withddl.New(allddls)
 
@@ -121,6 +124,9 @@ type Engine struct {
 	dbClientFactoryFiltered func() binlogplayer.DBClient
 	dbClientFactoryDba      func() binlogplayer.DBClient
 	dbName                  string
+	// keyspace is the keyspace of the tablet, whose settings hold the
+	// feature gate overrides of its streams.
+	keyspace string
 
 	journaler map[string]*journalEvent
 	ec        *externalConnector
@@ -151,7 +157,8 @@ func NewEngine(config *tabletenv.TabletConfig, ts *topo.Server, cell string, mys
 }
 
 // InitDBConfig should be invoked after the db name is computed.
-func (vre *Engine) InitDBConfig(dbcfgs *dbconfigs.DBConfigs) {
+func (vre *Engine) InitDBConfig(keyspace string, dbcfgs *dbconfigs.DBConfigs) {
+	vre.keyspace = keyspace
 	// If we're already initilized, it's a test engine. Ignore the call.
 	if vre.dbClientFactoryFiltered != nil && vre.dbClientFactoryDba != nil {
 		return
@@ -165,6 +172,24 @@ func (vre *Engine) InitDBConfig(dbcfgs *dbconfigs.DBConfigs) {
 	vre.dbName = dbcfgs.DBName
 }
 
+// featureGateEnabled returns whether a feature gate is enabled in the
+// keyspace of the tablet, with the overrides of its keyspace settings. The
+// overrides are ignored if they can't be read.
+func (vre *Engine) featureGateEnabled(gate *featuregate.Gate) bool {
+	var overrides map[string]bool
+	if vre.ts != nil && vre.keyspace != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+		defer cancel()
+		settings, err := vre.ts.GetKeyspaceSettings(ctx, vre.keyspace)
+		if err != nil {
+			log.Warningf("Failed to read the feature gates of keyspace %s, using --feature_gates for %s: %v", vre.keyspace, gate.Name(), err)
+		} else {
+			overrides = settings.FeatureGates
+		}
+	}
+	return gate.Enabled(overrides)
+}
+
 // NewTestEngine creates a new Engine for testing.
 func NewTestEngine(ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaemon, dbClientFactoryFiltered func() binlogplayer.DBClient, dbClientFactoryDba func() binlogplayer.DBClient, dbname string, externalConfig map[string]*dbconfigs.DBConfigs) *Engine {
 	vre := &Engine{
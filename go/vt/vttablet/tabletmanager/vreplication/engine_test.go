@@ -32,7 +32,10 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/featuregate"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
 )
 
 func TestEngineOpen(t *testing.T) {
@@ -510,6 +513,8 @@ func TestCreateDBAndTable(t *testing.T) {
 			"ALTER TABLE _vt.vreplication ADD COLUMN workflow_type int NOT NULL DEFAULT 0",
 			"create table if not exists _vt.resharding_journal.*",
 			"create table if not exists _vt.copy_state.*",
+			"ALTER TABLE _vt.copy_state ADD COLUMN rows_copied.*",
+			"ALTER TABLE _vt.copy_state ADD COLUMN bytes_copied.*",
 		}
 		for _, ddl := range ddls {
 			dbClient.ExpectRequestRE(ddl, &sqltypes.Result{}, nil)
@@ -576,3 +581,16 @@ func TestGetDBClient(t *testing.T) {
 	shouldBeFilteredClient := vre.getDBClient(false /*runAsAdmin*/)
 	assert.Equal(t, shouldBeFilteredClient, dbClientFiltered)
 }
+
+func TestEngineFeatureGateEnabled(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	vre := NewTestEngine(ts, "cell1", nil, nil, nil, "db", nil)
+	vre.keyspace = "ks"
+	assert.True(t, vre.featureGateEnabled(featuregate.ParallelVPlayer))
+
+	err := ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{FeatureGates: map[string]bool{"ParallelVPlayer": false}})
+	require.NoError(t, err)
+	assert.False(t, vre.featureGateEnabled(featuregate.ParallelVPlayer))
+	assert.True(t, vre.featureGateEnabled(featuregate.DMLBatch))
+}
@@ -0,0 +1,459 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+var parallelApplyWorkers = flag.Int("vreplication_parallel_apply_workers", 0, "Number of connections the vplayer applies independent transactions of the source on in parallel, as grouped by the logical timestamps of the binlog of the source. Transactions are applied one at a time if lower than 2. Requires MySQL 5.7 or later with GTIDs on the source, and is only used in the replication phase of the streams that have no stop position")
+
+// ParallelApplyCapability is the capability of the tablets whose vplayer can
+// apply the transactions of the source in parallel.
+const ParallelApplyCapability = "parallel-vplayer"
+
+func init() {
+	servenv.RegisterCapability(ParallelApplyCapability)
+}
+
+const (
+	sqlSelectSessionSettings = "select @@session.sql_mode, @@session.time_zone, @@session.foreign_key_checks"
+	sqlSetSessionSettings    = "set @@session.sql_mode = %s, @@session.time_zone = %s, @@session.foreign_key_checks = %s"
+)
+
+// The vplayer applies independent transactions in parallel the way a
+// multi-threaded MySQL replica does with replica_parallel_type=LOGICAL_CLOCK.
+// The source writes in its binlog the sequence_number of every transaction,
+// and the sequence_number of the last transaction committed when it started,
+// its last_committed. Consecutive transactions whose last_committed is lower
+// than the sequence_number of the first of them were running at the same time
+// on the source, so they do not depend on each other and form a commit group.
+//
+// The transactions of a commit group are applied by a pool of workers, each
+// with its own connection. Every worker commits the position of its
+// transaction along with its changes: it adds the GTID of the transaction to
+// the position saved so far. The position saved in _vt.vreplication therefore
+// always contains exactly the transactions that were applied, even if there
+// are gaps in it when the vplayer fails in the middle of a group, and the
+// vstreamer only sends the missing transactions when the stream restarts.
+// The commits of the workers are serialized, while the statements of their
+// transactions are executed in parallel. The next group starts after all the
+// transactions of the previous one are committed.
+//
+// Transactions that can't be applied in parallel, such as DDLs, statement
+// based transactions, transactions without logical timestamps, or the
+// partial transaction at the end of a batch of the relay log, are applied
+// one at a time on the connection of the vreplicator, after the pending
+// group is committed.
+
+// parallelTxn is a transaction of the source that can be applied in
+// parallel with the other transactions of its commit group.
+type parallelTxn struct {
+	rows []parallelRows
+	// pos is the position after the transaction, and gtid is the
+	// GTID of the transaction.
+	pos            mysql.Position
+	gtid           mysql.Mysql56GTIDSet
+	lastCommitted  int64
+	sequenceNumber int64
+	commit         *binlogdatapb.VEvent
+}
+
+// parallelRows are the row changes of a table, with the plan they must be
+// applied with.
+type parallelRows struct {
+	tplan    *TablePlan
+	rowEvent *binlogdatapb.RowEvent
+}
+
+// commitGroup is a group of transactions that can be applied in parallel.
+type commitGroup struct {
+	txns []*parallelTxn
+	// firstSeq and lastSeq are the sequence numbers of the first and last
+	// transactions of the group that have changes.
+	firstSeq, lastSeq int64
+}
+
+// add adds a transaction to the group, and returns false if it depends on
+// a transaction of the group. The sequence numbers restart in every binlog
+// file, so a transaction with a lower sequence number than the previous one
+// starts a new group.
+func (g *commitGroup) add(txn *parallelTxn) bool {
+	if len(txn.rows) == 0 {
+		// Empty transactions only move the position.
+		g.txns = append(g.txns, txn)
+		return true
+	}
+	if g.lastSeq != 0 && (txn.sequenceNumber <= g.lastSeq || txn.lastCommitted >= g.firstSeq) {
+		return false
+	}
+	if g.firstSeq == 0 {
+		g.firstSeq = txn.sequenceNumber
+	}
+	g.lastSeq = txn.sequenceNumber
+	g.txns = append(g.txns, txn)
+	return true
+}
+
+// applyInParallel applies a batch of events of the relay log, and applies
+// its independent transactions in parallel. It returns the lag of the last
+// event that has a timestamp, or -1.
+func (vp *vplayer) applyInParallel(ctx context.Context, items [][]*binlogdatapb.VEvent) (int64, error) {
+	var sbm int64 = -1
+	group := &commitGroup{}
+	// pending are the events of the transaction being read, and txn the
+	// changes collected from them so far.
+	var pending []*binlogdatapb.VEvent
+	txn := &parallelTxn{}
+
+	// applySerially commits the pending group, and applies the pending
+	// events and the given one on the connection of the vreplicator.
+	applySerially := func(event *binlogdatapb.VEvent) error {
+		if err := vp.applyCommitGroup(ctx, group); err != nil {
+			return err
+		}
+		group = &commitGroup{}
+		for _, event := range append(pending, event) {
+			if err := vp.applyEvent(ctx, event, false); err != nil {
+				return err
+			}
+		}
+		switch event.Type {
+		case binlogdatapb.VEventType_COMMIT, binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_OTHER, binlogdatapb.VEventType_JOURNAL:
+			vp.applyingSerially = false
+		default:
+			// The rest of the transaction is applied on the same connection.
+			vp.applyingSerially = true
+		}
+		pending = nil
+		txn = &parallelTxn{}
+		return nil
+	}
+
+	for _, events := range items {
+		for _, event := range events {
+			if event.Timestamp != 0 {
+				sbm = vp.recordTimestamp(event)
+			}
+			if vp.applyingSerially {
+				if err := applySerially(event); err != nil {
+					return sbm, err
+				}
+				continue
+			}
+			switch event.Type {
+			case binlogdatapb.VEventType_BEGIN:
+				pending = append(pending, event)
+			case binlogdatapb.VEventType_FIELD:
+				pending = append(pending, event)
+				tplan, err := vp.replicatorPlan.buildExecutionPlan(event.FieldEvent)
+				if err != nil {
+					return sbm, err
+				}
+				vp.tablePlans[event.FieldEvent.TableName] = tplan
+			case binlogdatapb.VEventType_ROW:
+				pending = append(pending, event)
+				tplan := vp.tablePlans[event.RowEvent.TableName]
+				if tplan == nil {
+					return sbm, fmt.Errorf("unexpected event on table %s", event.RowEvent.TableName)
+				}
+				txn.rows = append(txn.rows, parallelRows{tplan: tplan, rowEvent: event.RowEvent})
+			case binlogdatapb.VEventType_GTID:
+				pending = append(pending, event)
+				if !vp.readTxnPosition(event, group, txn) {
+					// The transaction has no logical timestamps, or the flavor
+					// of the source has no GTID sets.
+					if err := applySerially(event); err != nil {
+						return sbm, err
+					}
+				}
+			case binlogdatapb.VEventType_COMMIT:
+				if txn.gtid == nil {
+					if err := applySerially(event); err != nil {
+						return sbm, err
+					}
+					continue
+				}
+				txn.commit = event
+				if !group.add(txn) {
+					if err := vp.applyCommitGroup(ctx, group); err != nil {
+						return sbm, err
+					}
+					group = &commitGroup{}
+					group.add(txn)
+				}
+				pending = nil
+				txn = &parallelTxn{}
+			case binlogdatapb.VEventType_HEARTBEAT:
+				// Heartbeats don't break the transactions or the groups.
+				if err := vp.applyEvent(ctx, event, false); err != nil {
+					return sbm, err
+				}
+			default:
+				if err := applySerially(event); err != nil {
+					return sbm, err
+				}
+			}
+		}
+	}
+	if err := vp.applyCommitGroup(ctx, group); err != nil {
+		return sbm, err
+	}
+	if len(pending) > 0 {
+		// The last transaction is partial. It is applied on the connection
+		// of the vreplicator, which keeps its transaction open until the
+		// rest of it is received.
+		for _, event := range pending {
+			if err := vp.applyEvent(ctx, event, false); err != nil {
+				return sbm, err
+			}
+		}
+		vp.applyingSerially = true
+	}
+	return sbm, nil
+}
+
+// readTxnPosition records in txn the position and logical timestamps of
+// the GTID event of a transaction, and returns false if the transaction
+// can't be applied in parallel.
+func (vp *vplayer) readTxnPosition(event *binlogdatapb.VEvent, group *commitGroup, txn *parallelTxn) bool {
+	if event.SequenceNumber == 0 {
+		return false
+	}
+	pos, err := binlogplayer.DecodePosition(event.Gtid)
+	if err != nil {
+		return false
+	}
+	prevPos := vp.pos
+	if len(group.txns) > 0 {
+		prevPos = group.txns[len(group.txns)-1].pos
+	}
+	set, ok := pos.GTIDSet.(mysql.Mysql56GTIDSet)
+	if !ok {
+		return false
+	}
+	prevSet, ok := prevPos.GTIDSet.(mysql.Mysql56GTIDSet)
+	if !ok {
+		return false
+	}
+	txn.pos = pos
+	txn.gtid = set.Difference(prevSet)
+	txn.lastCommitted = event.LastCommitted
+	txn.sequenceNumber = event.SequenceNumber
+	return true
+}
+
+// applyCommitGroup applies the transactions of a group in parallel, and
+// returns after all of them are committed.
+func (vp *vplayer) applyCommitGroup(ctx context.Context, group *commitGroup) error {
+	if len(group.txns) == 0 {
+		return nil
+	}
+	var txns []*parallelTxn
+	for _, txn := range group.txns {
+		if len(txn.rows) > 0 {
+			txns = append(txns, txn)
+		}
+	}
+	last := group.txns[len(group.txns)-1]
+	if len(txns) == 0 {
+		// The group only has empty transactions.
+		vp.pos = last.pos
+		vp.unsavedEvent = last.commit
+		return nil
+	}
+
+	numWorkers := vp.parallelWorkers
+	if numWorkers > len(txns) {
+		numWorkers = len(txns)
+	}
+	for len(vp.workers) < numWorkers {
+		worker, err := vp.newApplyWorker()
+		if err != nil {
+			return err
+		}
+		vp.workers = append(vp.workers, worker)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu  sync.Mutex
+		pos = vp.pos.GTIDSet
+		rec concurrency.AllErrorRecorder
+		wg  sync.WaitGroup
+	)
+	ch := make(chan *parallelTxn)
+	for _, worker := range vp.workers[:numWorkers] {
+		wg.Add(1)
+		go func(worker *vdbClient) {
+			defer wg.Done()
+			for txn := range ch {
+				if rec.HasErrors() {
+					continue
+				}
+				err := vp.applyTxn(ctx, worker, txn, func(update func(mysql.GTIDSet) error) error {
+					// The position is updated and committed by one worker at a time.
+					mu.Lock()
+					defer mu.Unlock()
+					newPos := pos.Union(txn.gtid)
+					if err := update(newPos); err != nil {
+						return err
+					}
+					pos = newPos
+					return nil
+				})
+				if err != nil {
+					rec.RecordError(err)
+					cancel()
+				}
+			}
+		}(worker)
+	}
+	for _, txn := range txns {
+		ch <- txn
+	}
+	close(ch)
+	wg.Wait()
+	vp.vr.stats.SetLastPosition(mysql.Position{GTIDSet: pos})
+	if rec.HasErrors() {
+		// The position saved so far contains the transactions that
+		// were committed. The others are sent again when the stream
+		// restarts.
+		return rec.Error()
+	}
+
+	vp.pos = last.pos
+	vp.numAccumulatedHeartbeats = 0
+	vp.timeLastSaved = time.Now()
+	vp.unsavedEvent = nil
+	if last != txns[len(txns)-1] {
+		// The empty transactions at the end of the group are saved later.
+		vp.unsavedEvent = last.commit
+	}
+	return nil
+}
+
+// applyTxn applies the changes of a transaction on the connection of a
+// worker, and commits them along with the position.
+func (vp *vplayer) applyTxn(ctx context.Context, dbClient *vdbClient, txn *parallelTxn, commit func(func(mysql.GTIDSet) error) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer dbClient.Rollback()
+	if err := dbClient.Begin(); err != nil {
+		return err
+	}
+	for _, rows := range txn.rows {
+		for _, change := range rows.rowEvent.RowChanges {
+			_, err := rows.tplan.applyChange(change, func(sql string) (*sqltypes.Result, error) {
+				stats := NewVrLogStats("ROWCHANGE")
+				start := time.Now()
+				qr, err := dbClient.ExecuteWithRetry(ctx, sql)
+				vp.vr.stats.QueryCount.Add(vp.phase, 1)
+				vp.vr.stats.QueryTimings.Record(vp.phase, start)
+				stats.Send(sql)
+				return qr, err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return commit(func(pos mysql.GTIDSet) error {
+		update := binlogplayer.GenerateUpdatePos(vp.vr.id, mysql.Position{GTIDSet: pos}, time.Now().Unix(), txn.commit.Timestamp, vp.vr.stats.CopyRowCount.Get(), *vreplicationStoreCompressedGTID)
+		if _, err := dbClient.Execute(update); err != nil {
+			return fmt.Errorf("error %v updating position", err)
+		}
+		return dbClient.Commit()
+	})
+}
+
+// newApplyWorker opens a connection for a worker, with the same session
+// settings as the connection of the vreplicator.
+func (vp *vplayer) newApplyWorker() (*vdbClient, error) {
+	qr, err := vp.vr.dbClient.Execute(sqlSelectSessionSettings)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 3 {
+		return nil, fmt.Errorf("unexpected result for %s: %v", sqlSelectSessionSettings, qr.Rows)
+	}
+	row := qr.Rows[0]
+
+	dbClient := newVDBClient(vp.vr.vre.dbClientFactoryFiltered(), vp.vr.stats)
+	if err := dbClient.Connect(); err != nil {
+		return nil, vterrors.Wrap(err, "can't connect to database")
+	}
+	for _, query := range []string{
+		fmt.Sprintf(sqlSetSessionSettings, encodeString(row[0].ToString()), encodeString(row[1].ToString()), row[2].ToString()),
+		// Tables may have varying character sets, see controller.runBlp.
+		"set names binary",
+	} {
+		if _, err := dbClient.Execute(query); err != nil {
+			dbClient.Close()
+			return nil, err
+		}
+	}
+	return dbClient, nil
+}
+
+// filterAggregates returns true if a rule of the filter aggregates the rows
+// of the source, as the rules of Materialize workflows can. Independent
+// transactions of the source can then change the same rows of the target,
+// so they must not be applied in parallel.
+func filterAggregates(filter *binlogdatapb.Filter) bool {
+	for _, rule := range filter.GetRules() {
+		if rule.Filter == "" {
+			continue
+		}
+		stmt, err := sqlparser.Parse(rule.Filter)
+		if err != nil {
+			continue
+		}
+		sel, ok := stmt.(*sqlparser.Select)
+		if !ok {
+			continue
+		}
+		if len(sel.GroupBy) > 0 || sqlparser.ContainsAggregation(sel.SelectExprs) {
+			return true
+		}
+	}
+	return false
+}
+
+// closeWorkers closes the connections of the workers.
+func (vp *vplayer) closeWorkers() {
+	for _, worker := range vp.workers {
+		worker.Close()
+	}
+	vp.workers = nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestCommitGroupAdd(t *testing.T) {
+	txn := func(lastCommitted, sequenceNumber int64) *parallelTxn {
+		return &parallelTxn{
+			rows:           []parallelRows{{}},
+			lastCommitted:  lastCommitted,
+			sequenceNumber: sequenceNumber,
+		}
+	}
+	g := &commitGroup{}
+	assert.True(t, g.add(txn(1, 2)))
+	assert.True(t, g.add(txn(1, 3)))
+	// Empty transactions don't depend on the others.
+	assert.True(t, g.add(&parallelTxn{lastCommitted: 3, sequenceNumber: 4}))
+	assert.True(t, g.add(txn(1, 5)))
+	// The transaction started after 2 committed.
+	assert.False(t, g.add(txn(2, 6)))
+	// The sequence numbers restarted in a new binlog file.
+	assert.False(t, g.add(txn(0, 1)))
+	assert.Len(t, g.txns, 4)
+
+	g = &commitGroup{}
+	assert.True(t, g.add(txn(2, 6)))
+	assert.True(t, g.add(txn(5, 7)))
+	assert.False(t, g.add(txn(6, 8)))
+}
+
+func TestReadTxnPosition(t *testing.T) {
+	startPos, err := binlogplayer.DecodePosition("MySQL56/00010203-0405-0607-0809-0a0b0c0d0e0f:1-10")
+	require.NoError(t, err)
+	vp := &vplayer{pos: startPos}
+	group := &commitGroup{}
+
+	txn := &parallelTxn{}
+	event := &binlogdatapb.VEvent{
+		Type:           binlogdatapb.VEventType_GTID,
+		Gtid:           "MySQL56/00010203-0405-0607-0809-0a0b0c0d0e0f:1-11",
+		LastCommitted:  9,
+		SequenceNumber: 11,
+	}
+	require.True(t, vp.readTxnPosition(event, group, txn))
+	assert.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f:11", txn.gtid.String())
+	assert.Equal(t, int64(9), txn.lastCommitted)
+	assert.Equal(t, int64(11), txn.sequenceNumber)
+	group.add(txn)
+
+	// The GTID of the next transaction is relative to the previous one of
+	// the group.
+	txn = &parallelTxn{}
+	event.Gtid = "MySQL56/00010203-0405-0607-0809-0a0b0c0d0e0f:1-12"
+	event.SequenceNumber = 12
+	require.True(t, vp.readTxnPosition(event, group, txn))
+	assert.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f:12", txn.gtid.String())
+
+	// Transactions without logical timestamps are applied one at a time.
+	event.SequenceNumber = 0
+	assert.False(t, vp.readTxnPosition(event, group, &parallelTxn{}))
+
+	// So are the transactions of flavors without GTID sets.
+	vp.pos, err = binlogplayer.DecodePosition("FilePos/binlog.000001:4")
+	require.NoError(t, err)
+	event.Gtid = "FilePos/binlog.000001:100"
+	event.SequenceNumber = 1
+	assert.False(t, vp.readTxnPosition(event, &commitGroup{}, &parallelTxn{}))
+}
+
+func TestFilterAggregates(t *testing.T) {
+	filter := func(queries ...string) *binlogdatapb.Filter {
+		f := &binlogdatapb.Filter{}
+		for _, query := range queries {
+			f.Rules = append(f.Rules, &binlogdatapb.Rule{Match: "t", Filter: query})
+		}
+		return f
+	}
+	assert.False(t, filterAggregates(nil))
+	assert.False(t, filterAggregates(filter("", "select id, val from t1")))
+	// Independent transactions of the source can change the same rows of
+	// an aggregated target.
+	assert.True(t, filterAggregates(filter("select id from t1", "select val1, sum(val2) as sval2, count(*) as rcount from t2 group by val1")))
+	assert.True(t, filterAggregates(filter("select val1 from t2 group by val1")))
+}
@@ -326,6 +326,37 @@ func (st *vrStats) register() {
 			}
 			return result
 		})
+	stats.NewGaugesFuncWithMultiLabels(
+		"VReplicationTableCopyRowCount",
+		"vreplication rows copied in copy phase per stream and table",
+		[]string{"source_keyspace", "source_shard", "workflow", "counts", "table"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64)
+			for _, ct := range st.controllers {
+				for table, count := range ct.blpStats.TableCopyRowCounts.Counts() {
+					result[ct.source.Keyspace+"."+ct.source.Shard+"."+ct.workflow+"."+fmt.Sprintf("%v", ct.id)+"."+table] = count
+				}
+			}
+			return result
+		})
+
+	stats.NewGaugesFuncWithMultiLabels(
+		"VReplicationTableCopyBytes",
+		"vreplication bytes copied in copy phase per stream and table",
+		[]string{"source_keyspace", "source_shard", "workflow", "counts", "table"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64)
+			for _, ct := range st.controllers {
+				for table, count := range ct.blpStats.TableCopyBytes.Counts() {
+					result[ct.source.Keyspace+"."+ct.source.Shard+"."+ct.workflow+"."+fmt.Sprintf("%v", ct.id)+"."+table] = count
+				}
+			}
+			return result
+		})
 	stats.NewCountersFuncWithMultiLabels(
 		"VReplicationErrors",
 		"Errors during vreplication",
@@ -402,6 +433,9 @@ func (st *vrStats) status() *EngineStatus {
 			CopyRowCount:          ct.blpStats.CopyRowCount.Get(),
 			CopyLoopCount:         ct.blpStats.CopyLoopCount.Get(),
 			NoopQueryCounts:       ct.blpStats.NoopQueryCount.Counts(),
+			TableCopyRowCounts:    ct.blpStats.TableCopyRowCounts.Counts(),
+			TableCopyBytes:        ct.blpStats.TableCopyBytes.Counts(),
+			CopyLastPKs:           ct.blpStats.CopyLastPKs(),
 		}
 		i++
 	}
@@ -434,6 +468,9 @@ type ControllerStatus struct {
 	CopyRowCount          int64
 	CopyLoopCount         int64
 	NoopQueryCounts       map[string]int64
+	TableCopyRowCounts    map[string]int64
+	TableCopyBytes        map[string]int64
+	CopyLastPKs           map[string]string
 }
 
 var vreplicationTemplate = `
@@ -161,6 +161,13 @@ func TestVReplicationStats(t *testing.T) {
 	require.Equal(t, int64(100), testStats.status().Controllers[0].CopyLoopCount)
 	require.Equal(t, int64(200), testStats.status().Controllers[0].CopyRowCount)
 
+	blpStats.TableCopyRowCounts.Add("t1", 150)
+	blpStats.TableCopyBytes.Add("t1", 4096)
+	blpStats.SetCopyLastPK("t1", `fields:{name:"id" type:INT64} rows:{lengths:3 values:"150"}`)
+	require.Equal(t, map[string]int64{"t1": 150}, testStats.status().Controllers[0].TableCopyRowCounts)
+	require.Equal(t, map[string]int64{"t1": 4096}, testStats.status().Controllers[0].TableCopyBytes)
+	require.Equal(t, map[string]string{"t1": `fields:{name:"id" type:INT64} rows:{lengths:3 values:"150"}`}, testStats.status().Controllers[0].CopyLastPKs)
+
 	var tm int64 = 1234567890
 	blpStats.RecordHeartbeat(tm)
 	require.Equal(t, tm, blpStats.Heartbeat())
@@ -260,7 +260,8 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			}
 			pkfields = append(pkfields, rows.Pkfields...)
 			buf := sqlparser.NewTrackedBuffer(nil)
-			buf.Myprintf("update _vt.copy_state set lastpk=%a where vrepl_id=%s and table_name=%s", ":lastpk", strconv.Itoa(int(vc.vr.id)), encodeString(tableName))
+			buf.Myprintf("update _vt.copy_state set lastpk=%a, rows_copied=rows_copied+%a, bytes_copied=bytes_copied+%a where vrepl_id=%s and table_name=%s",
+				":lastpk", ":rows_copied", ":bytes_copied", strconv.Itoa(int(vc.vr.id)), encodeString(tableName))
 			updateCopyState = buf.ParsedQuery()
 		}
 		if len(rows.Rows) == 0 {
@@ -275,6 +276,10 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		if err := vc.vr.dbClient.Begin(); err != nil {
 			return err
 		}
+		var rowsCopied, bytesCopied int64
+		for _, row := range rows.Rows {
+			bytesCopied += int64(len(row.Values))
+		}
 		_, err = vc.tablePlan.applyBulkInsert(&sqlbuffer, rows, func(sql string) (*sqltypes.Result, error) {
 			start := time.Now()
 
@@ -284,6 +289,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			}
 			vc.vr.stats.QueryTimings.Record("copy", start)
 			vc.vr.stats.CopyRowCount.Add(int64(qr.RowsAffected))
+			rowsCopied += int64(qr.RowsAffected)
 			vc.vr.stats.QueryCount.Add("copy", 1)
 			return qr, err
 		})
@@ -304,6 +310,8 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 				Type:  sqltypes.VarBinary,
 				Value: buf,
 			},
+			"rows_copied":  sqltypes.Int64BindVariable(rowsCopied),
+			"bytes_copied": sqltypes.Int64BindVariable(bytesCopied),
 		}
 		updateState, err := updateCopyState.GenerateQuery(bv, nil)
 		if err != nil {
@@ -316,6 +324,9 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		if err := vc.vr.dbClient.Commit(); err != nil {
 			return err
 		}
+		vc.vr.stats.TableCopyRowCounts.Add(tableName, rowsCopied)
+		vc.vr.stats.TableCopyBytes.Add(tableName, bytesCopied)
+		vc.vr.stats.SetCopyLastPK(tableName, string(buf))
 		return nil
 	})
 	// If there was a timeout, return without an error.
@@ -31,11 +31,19 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/featuregate"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 )
 
+// errTableCreated is returned by the vplayer after applying the creation of
+// a table that matches the filter, so that the vreplicator restarts it with a
+// plan that replicates the new table.
+var errTableCreated = errors.New("table matching the filter created")
+
 // vplayer replays binlog events by pulling them from a vstreamer.
 type vplayer struct {
 	vr        *vreplicator
@@ -68,6 +76,18 @@ type vplayer struct {
 	phase string
 
 	throttlerAppName string
+	// throttlerClient checks the throttler with a high priority while the
+	// vplayer catches up with a stop position, such as for a cutover.
+	throttlerClient *throttle.Client
+
+	// parallelWorkers is the number of workers independent transactions
+	// are applied by, see applyInParallel. The transactions are applied
+	// one at a time if it is lower than 2.
+	parallelWorkers int
+	workers         []*vdbClient
+	// applyingSerially is set while a transaction is applied on the
+	// connection of the vreplicator by applyInParallel.
+	applyingSerially bool
 }
 
 // newVPlayer creates a new vplayer. Parameters:
@@ -85,6 +105,21 @@ func newVPlayer(vr *vreplicator, settings binlogplayer.VRSettings, copyState map
 		settings.StopPos = pausePos
 		saveStop = false
 	}
+	parallelWorkers := 0
+	if *parallelApplyWorkers > 1 && settings.StopPos.IsZero() && len(copyState) == 0 && vr.vre.featureGateEnabled(featuregate.ParallelVPlayer) {
+		// The parallel workers don't check the stop position, and the
+		// transactions of the catchup of the copy phase are filtered
+		// by the copied rows.
+		parallelWorkers = *parallelApplyWorkers
+		if filterAggregates(vr.source.GetFilter()) {
+			log.Infof("Applying the transactions of the aggregating workflow %s one at a time", vr.WorkflowName)
+			parallelWorkers = 0
+		}
+	}
+	throttlerClient := vr.vre.throttlerClient
+	if saveStop && !settings.StopPos.IsZero() {
+		throttlerClient = throttlerClient.WithPriority(throttle.PriorityHigh)
+	}
 	return &vplayer{
 		vr:               vr,
 		startPos:         settings.StartPos,
@@ -96,6 +131,8 @@ func newVPlayer(vr *vreplicator, settings binlogplayer.VRSettings, copyState map
 		tablePlans:       make(map[string]*TablePlan),
 		phase:            phase,
 		throttlerAppName: vr.throttlerAppName(),
+		throttlerClient:  throttlerClient,
+		parallelWorkers:  parallelWorkers,
 	}
 }
 
@@ -330,6 +367,7 @@ func (vp *vplayer) recordHeartbeat() error {
 // way to handle them.
 func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	defer vp.vr.dbClient.Rollback()
+	defer vp.closeWorkers()
 
 	// If we're not running, set ReplicationLagSeconds to be very high.
 	// TODO(sougou): if we also stored the time of the last event, we
@@ -342,7 +380,7 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 			return ctx.Err()
 		}
 		// check throttler.
-		if !vp.vr.vre.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, vp.throttlerAppName) {
+		if !vp.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, vp.throttlerAppName) {
 			continue
 		}
 
@@ -373,38 +411,50 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 				return nil
 			}
 		}
-		for i, events := range items {
-			for j, event := range events {
-				if event.Timestamp != 0 {
-					vp.lastTimestampNs = event.Timestamp * 1e9
-					vp.timeOffsetNs = time.Now().UnixNano() - event.CurrentTime
-					sbm = event.CurrentTime/1e9 - event.Timestamp
+		if vp.parallelWorkers > 1 {
+			eventsSbm, err := vp.applyInParallel(ctx, items)
+			if eventsSbm >= 0 {
+				sbm = eventsSbm
+			}
+			if err != nil {
+				if err != io.EOF && err != errTableCreated {
+					vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+					log.Errorf("Error applying event: %s", err.Error())
 				}
-				mustSave := false
-				switch event.Type {
-				case binlogdatapb.VEventType_COMMIT:
-					// If we've reached the stop position, we must save the current commit
-					// even if it's empty. So, the next applyEvent is invoked with the
-					// mustSave flag.
-					if !vp.stopPos.IsZero() && vp.pos.AtLeast(vp.stopPos) {
-						mustSave = true
-						break
+				return err
+			}
+		} else {
+			for i, events := range items {
+				for j, event := range events {
+					if event.Timestamp != 0 {
+						sbm = vp.recordTimestamp(event)
 					}
-					// In order to group multiple commits into a single one, we look ahead for
-					// the next commit. If there is one, we skip the current commit, which ends up
-					// applying the next set of events as part of the current transaction. This approach
-					// also handles the case where the last transaction is partial. In that case,
-					// we only group the transactions with commits we've seen so far.
-					if hasAnotherCommit(items, i, j+1) {
-						continue
+					mustSave := false
+					switch event.Type {
+					case binlogdatapb.VEventType_COMMIT:
+						// If we've reached the stop position, we must save the current commit
+						// even if it's empty. So, the next applyEvent is invoked with the
+						// mustSave flag.
+						if !vp.stopPos.IsZero() && vp.pos.AtLeast(vp.stopPos) {
+							mustSave = true
+							break
+						}
+						// In order to group multiple commits into a single one, we look ahead for
+						// the next commit. If there is one, we skip the current commit, which ends up
+						// applying the next set of events as part of the current transaction. This approach
+						// also handles the case where the last transaction is partial. In that case,
+						// we only group the transactions with commits we've seen so far.
+						if hasAnotherCommit(items, i, j+1) {
+							continue
+						}
 					}
-				}
-				if err := vp.applyEvent(ctx, event, mustSave); err != nil {
-					if err != io.EOF {
-						vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
-						log.Errorf("Error applying event: %s", err.Error())
+					if err := vp.applyEvent(ctx, event, mustSave); err != nil {
+						if err != io.EOF && err != errTableCreated {
+							vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+							log.Errorf("Error applying event: %s", err.Error())
+						}
+						return err
 					}
-					return err
 				}
 			}
 		}
@@ -417,6 +467,14 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	}
 }
 
+// recordTimestamp records the timestamp of an event and the clock difference
+// with the source, and returns the lag of the event in seconds.
+func (vp *vplayer) recordTimestamp(event *binlogdatapb.VEvent) int64 {
+	vp.lastTimestampNs = event.Timestamp * 1e9
+	vp.timeOffsetNs = time.Now().UnixNano() - event.CurrentTime
+	return event.CurrentTime/1e9 - event.Timestamp
+}
+
 func hasAnotherCommit(items [][]*binlogdatapb.VEvent, i, j int) bool {
 	for i < len(items) {
 		for j < len(items[i]) {
@@ -572,6 +630,9 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			if posReached {
 				return io.EOF
 			}
+			if vp.createsFilteredTable(event.Statement) {
+				return errTableCreated
+			}
 		case binlogdatapb.OnDDLAction_EXEC_IGNORE:
 			if _, err := vp.vr.dbClient.ExecuteWithRetry(ctx, event.Statement); err != nil {
 				log.Infof("Ignoring error: %v for DDL: %s", err, event.Statement)
@@ -584,6 +645,9 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			if posReached {
 				return io.EOF
 			}
+			if vp.createsFilteredTable(event.Statement) {
+				return errTableCreated
+			}
 		}
 	case binlogdatapb.VEventType_JOURNAL:
 		if vp.vr.dbClient.InTransaction {
@@ -644,3 +708,26 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 
 	return nil
 }
+
+// createsFilteredTable returns true if the DDL creates a table that is not
+// replicated yet, but matches a regular expression of the filter, like the
+// "/.*" rule of the follower keyspaces.
+func (vp *vplayer) createsFilteredTable(ddl string) bool {
+	stmt, err := sqlparser.Parse(ddl)
+	if err != nil {
+		return false
+	}
+	create, ok := stmt.(*sqlparser.CreateTable)
+	if !ok {
+		return false
+	}
+	tableName := create.GetTable().Name.String()
+	if vp.replicatorPlan.TablePlans[tableName] != nil {
+		return false
+	}
+	rule, err := MatchTable(tableName, vp.vr.source.Filter)
+	if err != nil || rule == nil {
+		return false
+	}
+	return strings.HasPrefix(rule.Match, "/") && rule.Filter != ExcludeStr
+}
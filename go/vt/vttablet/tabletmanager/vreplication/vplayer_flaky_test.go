@@ -1382,6 +1382,89 @@ func TestPlayerRowMove(t *testing.T) {
 	validateQueryCountStat(t, "replicate", 5)
 }
 
+func TestPlayerParallelApply(t *testing.T) {
+	defer deleteTablet(addTablet(100))
+	defer func(workers int) { *parallelApplyWorkers = workers }(*parallelApplyWorkers)
+	*parallelApplyWorkers = 4
+
+	execStatements(t, []string{
+		"create table t1(id int, val varbinary(128), primary key(id))",
+		fmt.Sprintf("create table %s.t1(id int, val varbinary(128), primary key(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		fmt.Sprintf("drop table %s.t1", vrepldb),
+	})
+	env.SchemaEngine.Reload(context.Background())
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select * from t1",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	cancel, id := startVReplication(t, bls, "")
+	defer cancel()
+
+	// The transactions are committed concurrently, so that the binlog
+	// groups some of them as independent.
+	const rows = 40
+	var wg sync.WaitGroup
+	for i := 1; i <= rows; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := env.Mysqld.ExecuteSuperQuery(context.Background(), fmt.Sprintf("insert into t1 values(%d, 'aaa%d')", i, i))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	execStatements(t, []string{
+		"update t1 set val='bbb' where id=1",
+		"delete from t1 where id=2",
+	})
+	want, err := binlogplayer.DecodePosition(primaryPosition(t))
+	require.NoError(t, err)
+
+	// The stream catches up with the source, whichever worker applied each
+	// transaction.
+	posQuery := fmt.Sprintf("select pos from _vt.vreplication where id=%d", id)
+	require.Eventually(t, func() bool {
+		qr, err := env.Mysqld.FetchSuperQuery(context.Background(), posQuery)
+		if err != nil || len(qr.Rows) != 1 {
+			return false
+		}
+		pos, err := binlogplayer.DecodePosition(qr.Rows[0][0].ToString())
+		return err == nil && pos.AtLeast(want)
+	}, 10*time.Second, 10*time.Millisecond)
+
+	var wantData [][]string
+	wantData = append(wantData, []string{"1", "bbb"})
+	for i := 3; i <= rows; i++ {
+		wantData = append(wantData, []string{strconv.Itoa(i), fmt.Sprintf("aaa%d", i)})
+	}
+	expectData(t, "t1", wantData)
+
+	// Every transaction was applied exactly once.
+	applied := make(map[string]int)
+	for len(globalDBQueries) > 0 {
+		query := <-globalDBQueries
+		if strings.HasPrefix(query, "insert into t1") || strings.HasPrefix(query, "update t1") || strings.HasPrefix(query, "delete from t1") {
+			applied[query]++
+		}
+	}
+	require.Len(t, applied, rows+2)
+	for query, count := range applied {
+		require.Equal(t, 1, count, query)
+	}
+}
+
 func TestPlayerTypes(t *testing.T) {
 	log.Errorf("TestPlayerTypes: flavor is %s", env.Flavor)
 	enableJSONColumnTesting := false
@@ -1679,6 +1762,55 @@ func TestPlayerDDL(t *testing.T) {
 	cancel()
 }
 
+func TestPlayerDDLCreateTable(t *testing.T) {
+	defer deleteTablet(addTablet(100))
+	execStatements(t, []string{
+		"create table t1(id int, primary key(id))",
+		fmt.Sprintf("create table %s.t1(id int, primary key(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		fmt.Sprintf("drop table %s.t1", vrepldb),
+		"drop table t2",
+		fmt.Sprintf("drop table %s.t2", vrepldb),
+	})
+	env.SchemaEngine.Reload(context.Background())
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/.*",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_EXEC,
+	}
+	cancel, _ := startVReplication(t, bls, "")
+	defer cancel()
+
+	execStatements(t, []string{"insert into t1 values(1)"})
+	expectNontxQueries(t, []string{
+		"insert into t1(id) values (1)",
+	})
+
+	// The rows of the tables created after the start of the workflow
+	// are replicated too.
+	execStatements(t, []string{
+		"create table t2(id int, primary key(id))",
+		"insert into t2 values(1)",
+	})
+	expectNontxQueries(t, []string{
+		"create table t2(id int, primary key(id))",
+		"/update _vt.vreplication set state='Running'",
+		"insert into t2(id) values (1)",
+	})
+	expectData(t, "t2", [][]string{
+		{"1"},
+	})
+}
+
 func TestGTIDCompress(t *testing.T) {
 	ctx := context.Background()
 	defer deleteTablet(addTablet(100))
@@ -222,6 +222,12 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 				return err
 			}
 			if err := newVCopier(vr).copyNext(ctx, settings); err != nil {
+				if err == errTableCreated {
+					if err := vr.rebuildColInfoMap(ctx); err != nil {
+						return err
+					}
+					continue
+				}
 				vr.stats.ErrorCounts.Add([]string{"Copy"}, 1)
 				return err
 			}
@@ -251,11 +257,29 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 				vr.stats.ErrorCounts.Add([]string{"Replicate"}, 1)
 				return err
 			}
-			return newVPlayer(vr, settings, nil, mysql.Position{}, "replicate").play(ctx)
+			err := newVPlayer(vr, settings, nil, mysql.Position{}, "replicate").play(ctx)
+			if err != errTableCreated {
+				return err
+			}
+			if err := vr.rebuildColInfoMap(ctx); err != nil {
+				return err
+			}
 		}
 	}
 }
 
+// rebuildColInfoMap reloads the schema of the target after the vplayer
+// created a table that matches the filter, so that the next vplayer
+// replicates it.
+func (vr *vreplicator) rebuildColInfoMap(ctx context.Context) error {
+	colInfo, err := vr.buildColInfoMap(ctx)
+	if err != nil {
+		return err
+	}
+	vr.colInfoMap = colInfo
+	return nil
+}
+
 // ColumnInfo is used to store charset and collation
 type ColumnInfo struct {
 	Name        string
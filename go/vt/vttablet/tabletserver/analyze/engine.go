@@ -0,0 +1,285 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package analyze keeps the optimizer statistics of the tables of a shard
+// current, by running ANALYZE TABLE in the background on the primary.
+//
+// The primary periodically reads the time the persistent statistics of its
+// tables were last recalculated from mysql.innodb_table_stats, and analyzes
+// the tables whose statistics are older than a maximum age. ANALYZE TABLE is
+// written to the binary log, so that the replicas recalculate the statistics
+// of their tables in turn. The tables are only analyzed within a configured
+// window of the day, and while the throttler lets the background jobs run,
+// so that bulk changes such as the copy phase of vreplication are followed
+// by fresh statistics without loading the shard at peak traffic.
+package analyze
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+)
+
+const throttlerAppName = "analyze-table"
+
+var (
+	checkInterval = flag.Duration("analyze_table_interval", 0, "Interval between two checks of the freshness of the statistics of the tables on the primary. 0 disables the background ANALYZE TABLE")
+	maxStatsAge   = flag.Duration("analyze_table_max_stats_age", 24*time.Hour, "Age of the statistics of a table above which the table is analyzed")
+	tableList     = flag.String("analyze_table_tables", "", "Comma-separated list of the tables to analyze in the background. All the tables are analyzed if empty")
+	window        = flag.String("analyze_table_window", "", "Window of the day, in UTC, in which tables are analyzed in the background, as HH:MM-HH:MM. Tables are analyzed at any time if empty")
+)
+
+var (
+	statsAge       = stats.NewGaugesWithSingleLabel("AnalyzeTableStatsAgeSeconds", "Age of the statistics of the tables on the primary, by table", "Table")
+	tablesAnalyzed = stats.NewCountersWithSingleLabel("AnalyzeTableRuns", "Tables analyzed in the background, by table", "Table")
+	analyzeErrors  = stats.NewCounter("AnalyzeTableErrors", "Errors while analyzing tables in the background")
+	throttledRuns  = stats.NewCounter("AnalyzeTableThrottled", "Rounds of background ANALYZE TABLE interrupted by the throttler")
+)
+
+const (
+	sqlSelectStatsAge = "select table_name, unix_timestamp() - unix_timestamp(last_update) from mysql.innodb_table_stats where database_name = %s"
+	sqlAnalyzeTable   = "analyze table %s.%s"
+)
+
+// Engine analyzes the tables of a primary tablet in the background.
+type Engine struct {
+	env             tabletenv.Env
+	se              *schema.Engine
+	throttlerClient *throttle.Client
+	errorLog        *logutil.ThrottledLogger
+
+	enabled     bool
+	maxStatsAge time.Duration
+	tables      []string
+	window      *dayWindow
+	dbName      string
+	now         func() time.Time
+
+	mu     sync.Mutex
+	isOpen bool
+	pool   *dbconnpool.ConnectionPool
+	ticks  *timer.Timer
+	cancel context.CancelFunc
+}
+
+// NewEngine creates a new Engine.
+func NewEngine(env tabletenv.Env, se *schema.Engine, lagThrottler *throttle.Throttler) *Engine {
+	if *checkInterval <= 0 {
+		return &Engine{}
+	}
+	w, err := parseWindow(*window)
+	if err != nil {
+		log.Exitf("invalid -analyze_table_window: %v", err)
+	}
+	return &Engine{
+		env:             env,
+		se:              se,
+		throttlerClient: throttle.NewBackgroundClient(lagThrottler, throttlerAppName, throttle.ThrottleCheckPrimaryWrite),
+		errorLog:        logutil.NewThrottledLogger("AnalyzeTable", 60*time.Second),
+		enabled:         true,
+		maxStatsAge:     *maxStatsAge,
+		tables:          parseTables(*tableList),
+		window:          w,
+		now:             time.Now,
+		pool:            dbconnpool.NewConnectionPool("AnalyzeTablePool", 1, *mysqlctl.DbaIdleTimeout, *mysqlctl.PoolDynamicHostnameResolution),
+		ticks:           timer.NewTimer(*checkInterval),
+	}
+}
+
+// InitDBConfig initializes the name of the database whose tables are analyzed.
+func (e *Engine) InitDBConfig(dbName string) {
+	e.dbName = dbName
+}
+
+// Open starts analyzing the tables. It is called when the tablet becomes
+// the primary.
+func (e *Engine) Open() {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.isOpen {
+		return
+	}
+
+	log.Info("AnalyzeTable: opening")
+	e.pool.Open(e.env.Config().DB.DbaWithDB())
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	e.ticks.Start(func() { e.analyzeTables(ctx) })
+	e.isOpen = true
+}
+
+// Close stops analyzing the tables.
+func (e *Engine) Close() {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isOpen {
+		return
+	}
+	// The round in progress must be canceled before stopping the ticks,
+	// which waits for it to return.
+	e.cancel()
+	e.ticks.Stop()
+	e.pool.Close()
+	e.isOpen = false
+	statsAge.ResetAll()
+	log.Info("AnalyzeTable: closed")
+}
+
+// analyzeTables records the age of the statistics of the tables, and
+// analyzes the tables whose statistics are too old.
+func (e *Engine) analyzeTables(ctx context.Context) {
+	defer e.env.LogError()
+
+	if !e.window.contains(e.now().UTC()) {
+		return
+	}
+
+	conn, err := e.pool.Get(ctx)
+	if err != nil {
+		e.recordError(err)
+		return
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.ExecuteFetch(fmt.Sprintf(sqlSelectStatsAge, sqltypes.EncodeStringSQL(e.dbName)), 100000, false)
+	if err != nil {
+		e.recordError(err)
+		return
+	}
+	ages := make(map[string]time.Duration, len(qr.Rows))
+	for _, row := range qr.Rows {
+		age, err := row[1].ToInt64()
+		if err != nil {
+			continue
+		}
+		ages[row[0].ToString()] = time.Duration(age) * time.Second
+	}
+
+	for _, name := range e.tablesToAnalyze() {
+		age, ok := ages[name]
+		if ok {
+			statsAge.Set(name, int64(age.Seconds()))
+		}
+		if ok && age < e.maxStatsAge {
+			continue
+		}
+		if !e.throttlerClient.ThrottleCheckOK(ctx, "") {
+			// The next round resumes with the remaining tables.
+			throttledRuns.Add(1)
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			return
+		}
+		if _, err := conn.ExecuteFetch(fmt.Sprintf(sqlAnalyzeTable, sqlescape.EscapeID(e.dbName), sqlescape.EscapeID(name)), 1000, false); err != nil {
+			e.recordError(fmt.Errorf("failed to analyze table %s: %v", name, err))
+			continue
+		}
+		tablesAnalyzed.Add(name, 1)
+		statsAge.Set(name, 0)
+	}
+}
+
+// tablesToAnalyze returns the configured tables, or all the tables of the
+// schema, in order.
+func (e *Engine) tablesToAnalyze() []string {
+	if len(e.tables) > 0 {
+		return e.tables
+	}
+	var names []string
+	for name, table := range e.se.GetSchema() {
+		if name == "dual" || table.Type != schema.NoType {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Engine) recordError(err error) {
+	e.errorLog.Errorf("%v", err)
+	analyzeErrors.Add(1)
+}
+
+func parseTables(list string) []string {
+	var tables []string
+	for _, table := range strings.Split(list, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// dayWindow is a window of the day, that wraps around midnight if its end
+// is before its start. A nil window contains all the times of the day.
+type dayWindow struct {
+	start, end time.Duration
+}
+
+func parseWindow(s string) (*dayWindow, error) {
+	if s == "" {
+		return nil, nil
+	}
+	bounds := strings.Split(s, "-")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("window %q must be formatted as HH:MM-HH:MM", s)
+	}
+	var offsets [2]time.Duration
+	for i, bound := range bounds {
+		t, err := time.Parse("15:04", strings.TrimSpace(bound))
+		if err != nil {
+			return nil, fmt.Errorf("window %q must be formatted as HH:MM-HH:MM: %v", s, err)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return &dayWindow{start: offsets[0], end: offsets[1]}, nil
+}
+
+func (w *dayWindow) contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start <= w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyze
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestAnalyzeTables(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	e := newTestEngine(db)
+	defer e.pool.Close()
+	tablesAnalyzed.ResetAll()
+	statsAge.ResetAll()
+
+	db.AddQuery("select table_name, unix_timestamp() - unix_timestamp(last_update) from mysql.innodb_table_stats where database_name = 'vt_db'",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|age", "varbinary|int64"), "t1|90000", "t2|60"))
+	db.AddQuery("analyze table `vt_db`.`t1`", &sqltypes.Result{})
+	db.AddQuery("analyze table `vt_db`.`t3`", &sqltypes.Result{})
+
+	e.analyzeTables(context.Background())
+	assert.Equal(t, map[string]int64{"t1": 1, "t3": 1}, tablesAnalyzed.Counts())
+	assert.Equal(t, map[string]int64{"t1": 0, "t2": 60, "t3": 0}, statsAge.Counts())
+
+	// Outside of the window, no table is analyzed.
+	e.window = &dayWindow{start: 2 * time.Hour, end: 4 * time.Hour}
+	e.analyzeTables(context.Background())
+	assert.Equal(t, map[string]int64{"t1": 1, "t3": 1}, tablesAnalyzed.Counts())
+
+	e.now = func() time.Time { return time.Date(2022, 1, 1, 3, 0, 0, 0, time.UTC) }
+	e.analyzeTables(context.Background())
+	assert.Equal(t, map[string]int64{"t1": 2, "t3": 2}, tablesAnalyzed.Counts())
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := parseWindow("")
+	require.NoError(t, err)
+	assert.Nil(t, w)
+	assert.True(t, w.contains(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	w, err = parseWindow("01:30-05:00")
+	require.NoError(t, err)
+	assert.Equal(t, &dayWindow{start: 90 * time.Minute, end: 5 * time.Hour}, w)
+	assert.False(t, w.contains(time.Date(2022, 1, 1, 1, 29, 59, 0, time.UTC)))
+	assert.True(t, w.contains(time.Date(2022, 1, 1, 1, 30, 0, 0, time.UTC)))
+	assert.True(t, w.contains(time.Date(2022, 1, 1, 4, 59, 59, 0, time.UTC)))
+	assert.False(t, w.contains(time.Date(2022, 1, 1, 5, 0, 0, 0, time.UTC)))
+
+	w, err = parseWindow("22:00 - 02:00")
+	require.NoError(t, err)
+	assert.True(t, w.contains(time.Date(2022, 1, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, w.contains(time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, w.contains(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	_, err = parseWindow("22:00")
+	assert.EqualError(t, err, `window "22:00" must be formatted as HH:MM-HH:MM`)
+	_, err = parseWindow("22:00-25:00")
+	assert.Error(t, err)
+}
+
+func TestParseTables(t *testing.T) {
+	assert.Nil(t, parseTables(""))
+	assert.Equal(t, []string{"a", "b"}, parseTables(" b, a,,"))
+}
+
+func newTestEngine(db *fakesqldb.DB) *Engine {
+	config := tabletenv.NewDefaultConfig()
+	params, _ := db.ConnParams().MysqlParams()
+	cp := *params
+	dbc := dbconfigs.NewTestDBConfigs(cp, cp, "")
+
+	e := &Engine{
+		env:         tabletenv.NewEnv(config, "AnalyzeTableTest"),
+		errorLog:    logutil.NewThrottledLogger("AnalyzeTableTest", 60*time.Second),
+		enabled:     true,
+		maxStatsAge: 24 * time.Hour,
+		tables:      []string{"t1", "t2", "t3"},
+		dbName:      "vt_db",
+		now:         func() time.Time { return time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC) },
+		pool:        dbconnpool.NewConnectionPool("AnalyzeTableTestPool", 1, time.Minute, 0),
+	}
+	e.pool.Open(dbc.DbaWithDB())
+	return e
+}
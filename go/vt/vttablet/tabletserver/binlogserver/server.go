@@ -0,0 +1,411 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package binlogserver serves the binary logs of the MySQL of a tablet to
+// external MySQL replicas, with the MySQL replication protocol.
+//
+// The replicas connect to vttablet as to a MySQL source with
+// SOURCE_AUTO_POSITION=1. The queries they run before they dump the binary
+// logs, such as the negotiation of the checksums and of the heartbeats, run
+// on a connection of their own to the MySQL of the tablet, as the filtered
+// replication user. The binary logs are then relayed by the vstreamer, which
+// only keeps the rows of the tables and of the keyrange of the flags, so
+// that a replica only receives the data of a shard, or of some tables.
+package binlogserver
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	port        = flag.Int("binlog_server_port", 0, "Port of the binlog server of vttablet, which serves the binary logs of its MySQL to external MySQL replicas with the MySQL replication protocol, filtered by --binlog_server_tables and --binlog_server_keyrange. The replicas must use SOURCE_AUTO_POSITION=1. 0 disables the binlog server")
+	bindAddress = flag.String("binlog_server_bind_address", "", "Address the binlog server binds to")
+	authFile    = flag.String("binlog_server_auth_file", "", "JSON file of the users of the binlog server, in the format of --mysql_auth_server_static_file. Required by the binlog server")
+	tableList   = flag.String("binlog_server_tables", "", "Comma-separated list of the tables whose rows are served by the binlog server, or of /regexps/ of their names. The rows of all the tables are served if empty")
+	keyRange    = flag.String("binlog_server_keyrange", "", "Keyrange of the rows served by the binlog server, such as -80, according to the primary vindexes of the tables in the vschema. All the rows are served if empty")
+)
+
+var (
+	replicasConnected = stats.NewGauge("BinlogServerReplicas", "Number of replicas connected to the binlog server")
+	binlogDumps       = stats.NewCountersWithSingleLabel("BinlogServerDumps", "Binary log dumps of the replicas of the binlog server, by result", "Result")
+)
+
+const (
+	// The replicas negotiate the checksums of the events and the period of
+	// the heartbeats by setting these user variables. The source_ ones are
+	// those of MySQL 8.0.26 and later.
+	sqlSelectReplicaSettings = "select coalesce(@source_binlog_checksum, @master_binlog_checksum), coalesce(@source_heartbeat_period, @master_heartbeat_period)"
+
+	// semiSyncVariablePrefix is the prefix of the variables of the semi-sync
+	// plugins, which the binlog server hides from the replicas because it
+	// does not support semi-sync replication.
+	semiSyncVariablePrefix = "rpl_semi_sync"
+)
+
+// BinlogRelayer relays the binary logs of the tablet. It is implemented by
+// the vstreamer engine.
+type BinlogRelayer interface {
+	RelayBinlogs(ctx context.Context, startPos mysql.Position, filter *binlogdatapb.Filter, heartbeat time.Duration, send func(mysql.BinlogEvent) error) error
+}
+
+// Server is the binlog server of a tablet.
+type Server struct {
+	env    tabletenv.Env
+	relay  BinlogRelayer
+	filter *binlogdatapb.Filter
+
+	mu       sync.Mutex
+	listener *mysql.Listener
+	// conns are the connections to MySQL of the connected replicas.
+	conns  map[*mysql.Conn]*mysql.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewServer creates a new Server.
+func NewServer(env tabletenv.Env, relay BinlogRelayer) *Server {
+	return &Server{
+		env:    env,
+		relay:  relay,
+		filter: buildFilter(*tableList, *keyRange),
+		conns:  make(map[*mysql.Conn]*mysql.Conn),
+	}
+}
+
+// buildFilter returns the filter of the relayed rows. All the columns of the
+// tables are relayed, so that the replicas can apply the row events.
+func buildFilter(tables, keyRange string) *binlogdatapb.Filter {
+	filter := &binlogdatapb.Filter{}
+	for _, table := range strings.Split(tables, ",") {
+		table = strings.TrimSpace(table)
+		switch {
+		case table == "":
+			continue
+		case !strings.HasPrefix(table, "/"):
+			table = "/^" + regexp.QuoteMeta(table) + "$/"
+		}
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: table, Filter: keyRange})
+	}
+	if len(filter.Rules) == 0 {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: "/.*/", Filter: keyRange})
+	}
+	return filter
+}
+
+// Open starts listening for the replicas, if --binlog_server_port is set.
+func (bs *Server) Open() error {
+	if *port == 0 {
+		return nil
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.listener != nil {
+		return nil
+	}
+
+	if *authFile == "" {
+		return fmt.Errorf("--binlog_server_auth_file is required by the binlog server")
+	}
+	if *keyRange != "" {
+		if keyRanges, err := key.ParseShardingSpec(*keyRange); err != nil || len(keyRanges) != 1 {
+			return fmt.Errorf("invalid --binlog_server_keyrange %q", *keyRange)
+		}
+	}
+	authServer := mysql.NewAuthServerStatic(*authFile, "", 0)
+	listener, err := mysql.NewListener("tcp", net.JoinHostPort(*bindAddress, fmt.Sprint(*port)), authServer, &handler{bs: bs}, 0, 0, false)
+	if err != nil {
+		return err
+	}
+	if *servenv.MySQLServerVersion != "" {
+		listener.ServerVersion = *servenv.MySQLServerVersion
+	}
+	log.Infof("Binlog server: listening on %v", listener.Addr())
+	bs.listener = listener
+	bs.ctx, bs.cancel = context.WithCancel(context.Background())
+	go listener.Accept()
+	return nil
+}
+
+// Close stops listening for the replicas, and disconnects them.
+func (bs *Server) Close() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.listener == nil {
+		return
+	}
+	bs.listener.Close()
+	bs.listener = nil
+	bs.cancel()
+	for c := range bs.conns {
+		c.Close()
+	}
+	log.Info("Binlog server: closed")
+}
+
+// handler handles the commands of the replicas.
+type handler struct {
+	mysql.UnimplementedHandler
+	bs *Server
+}
+
+// ConnectionReady connects the authenticated replica to MySQL.
+func (h *handler) ConnectionReady(c *mysql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cp := h.bs.env.Config().DB.FilteredWithDB()
+	conn, err := cp.Connect(ctx)
+	if err != nil {
+		log.Errorf("Binlog server: cannot connect replica %v to MySQL: %v", c, err)
+		c.Close()
+		return
+	}
+	h.bs.mu.Lock()
+	defer h.bs.mu.Unlock()
+	h.bs.conns[c] = conn
+	replicasConnected.Add(1)
+}
+
+// ConnectionClosed closes the connection of the replica to MySQL.
+func (h *handler) ConnectionClosed(c *mysql.Conn) {
+	h.bs.mu.Lock()
+	conn := h.bs.conns[c]
+	delete(h.bs.conns, c)
+	h.bs.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+		replicasConnected.Add(-1)
+	}
+}
+
+func (h *handler) conn(c *mysql.Conn) (*mysql.Conn, error) {
+	h.bs.mu.Lock()
+	defer h.bs.mu.Unlock()
+	conn := h.bs.conns[c]
+	if conn == nil {
+		return nil, mysql.NewSQLError(mysql.CRServerGone, mysql.SSUnknownSQLState, "the binlog server has no connection to MySQL")
+	}
+	return conn, nil
+}
+
+// ComQuery runs the queries that the replicas run before they dump the binary
+// logs on their connection to MySQL.
+func (h *handler) ComQuery(c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
+	if err := checkReplicaQuery(query); err != nil {
+		return err
+	}
+	conn, err := h.conn(c)
+	if err != nil {
+		return err
+	}
+	result, err := conn.ExecuteFetch(query, 10000, true)
+	if err != nil {
+		return err
+	}
+	if isShowVariables(query) {
+		result = hideSemiSyncVariables(result)
+	}
+	return callback(result)
+}
+
+// replicaFunctions are the functions that the queries of the replicas may
+// call.
+var replicaFunctions = map[string]bool{
+	"connection_id":  true,
+	"current_user":   true,
+	"database":       true,
+	"schema":         true,
+	"unix_timestamp": true,
+	"user":           true,
+	"version":        true,
+}
+
+// checkReplicaQuery returns an error if a query is not one of those that the
+// replicas run before they dump the binary logs: SET of user and session
+// variables, SELECT without tables, and SHOW VARIABLES. The values of the SET
+// and the SELECT may only be literals, variables and the functions of
+// replicaFunctions.
+func checkReplicaQuery(query string) error {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	switch stmt := stmt.(type) {
+	case *sqlparser.Set:
+		allowed = true
+		for _, expr := range stmt.Exprs {
+			if expr.Var.Scope != sqlparser.VariableScope && expr.Var.Scope != sqlparser.SessionScope {
+				allowed = false
+			}
+			if !isReplicaExpr(expr.Expr) {
+				allowed = false
+			}
+		}
+	case *sqlparser.Select:
+		allowed = len(stmt.From) == 0 || (len(stmt.From) == 1 && sqlparser.String(stmt.From[0]) == "dual")
+		if stmt.Into != nil || !isReplicaExpr(stmt) {
+			allowed = false
+		}
+	case *sqlparser.Show:
+		allowed = isShowVariables(query)
+	}
+	if !allowed {
+		return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "query not supported by the binlog server: %s", query)
+	}
+	return nil
+}
+
+// isReplicaExpr returns true if the expressions of a node are only
+// literals, variables and calls of the functions of replicaFunctions.
+func isReplicaExpr(node sqlparser.SQLNode) bool {
+	allowed := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		expr, ok := node.(sqlparser.Expr)
+		if !ok {
+			return true, nil
+		}
+		switch expr := expr.(type) {
+		case *sqlparser.Literal, *sqlparser.NullVal, sqlparser.BoolVal, *sqlparser.Variable:
+		case *sqlparser.FuncExpr:
+			if !expr.Qualifier.IsEmpty() || !replicaFunctions[expr.Name.Lowered()] {
+				allowed = false
+			}
+		default:
+			allowed = false
+		}
+		return allowed, nil
+	}, node)
+	return allowed
+}
+
+func isShowVariables(query string) bool {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return false
+	}
+	show, ok := stmt.(*sqlparser.Show)
+	if !ok {
+		return false
+	}
+	basic, ok := show.Internal.(*sqlparser.ShowBasic)
+	return ok && (basic.Command == sqlparser.VariableGlobal || basic.Command == sqlparser.VariableSession)
+}
+
+// hideSemiSyncVariables removes the variables of the semi-sync plugins from
+// the result of a SHOW VARIABLES, so that the replicas don't expect the
+// events to be acknowledged.
+func hideSemiSyncVariables(result *sqltypes.Result) *sqltypes.Result {
+	rows := result.Rows[:0]
+	for _, row := range result.Rows {
+		if len(row) > 0 && strings.HasPrefix(strings.ToLower(row[0].ToString()), semiSyncVariablePrefix) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	result.Rows = rows
+	return result
+}
+
+// ComPrepare is part of the mysql.Handler interface.
+func (h *handler) ComPrepare(c *mysql.Conn, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "prepared statements are not supported by the binlog server")
+}
+
+// ComStmtExecute is part of the mysql.Handler interface.
+func (h *handler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "prepared statements are not supported by the binlog server")
+}
+
+// WarningCount is part of the mysql.Handler interface.
+func (h *handler) WarningCount(c *mysql.Conn) uint16 {
+	return 0
+}
+
+// ComBinlogDumpGTID relays the binary logs to the replica, from the GTIDs
+// it has not executed yet, until it disconnects.
+func (h *handler) ComBinlogDumpGTID(c *mysql.Conn, gtidSet mysql.GTIDSet) error {
+	if gtidSet == nil {
+		binlogDumps.Add("Error", 1)
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the binlog server requires SOURCE_AUTO_POSITION=1")
+	}
+	conn, err := h.conn(c)
+	if err != nil {
+		return err
+	}
+	checksum, heartbeat, err := replicaSettings(conn)
+	if err != nil {
+		binlogDumps.Add("Error", 1)
+		return err
+	}
+
+	h.bs.mu.Lock()
+	ctx := h.bs.ctx
+	h.bs.mu.Unlock()
+	log.Infof("Binlog server: relaying the binary logs to replica %v from %v", c, gtidSet)
+	err = h.bs.relay.RelayBinlogs(ctx, mysql.Position{GTIDSet: gtidSet}, h.bs.filter, heartbeat, func(ev mysql.BinlogEvent) error {
+		return c.WriteBinlogEvent(ev, checksum)
+	})
+	if err != nil {
+		binlogDumps.Add("Error", 1)
+		return err
+	}
+	binlogDumps.Add("Ended", 1)
+	return nil
+}
+
+// replicaSettings returns whether the replica expects checksums, and the
+// period of the heartbeats it expects.
+func replicaSettings(conn *mysql.Conn) (checksum bool, heartbeat time.Duration, err error) {
+	result, err := conn.ExecuteFetch(sqlSelectReplicaSettings, 1, false)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result.Rows) != 1 {
+		return false, 0, fmt.Errorf("unexpected result for %v: %v", sqlSelectReplicaSettings, result)
+	}
+	row := result.Rows[0]
+	checksum = strings.EqualFold(row[0].ToString(), "CRC32")
+	if !row[1].IsNull() {
+		period, err := row[1].ToInt64()
+		if err != nil {
+			return false, 0, err
+		}
+		// The period is in nanoseconds.
+		heartbeat = time.Duration(period)
+	}
+	return checksum, heartbeat, nil
+}
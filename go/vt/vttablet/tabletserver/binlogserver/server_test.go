@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binlogserver
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+type fakeRelayer struct {
+	mu        sync.Mutex
+	startPos  mysql.Position
+	filter    *binlogdatapb.Filter
+	heartbeat time.Duration
+	events    []mysql.BinlogEvent
+}
+
+func (fr *fakeRelayer) RelayBinlogs(ctx context.Context, startPos mysql.Position, filter *binlogdatapb.Filter, heartbeat time.Duration, send func(mysql.BinlogEvent) error) error {
+	fr.mu.Lock()
+	fr.startPos, fr.filter, fr.heartbeat = startPos, filter, heartbeat
+	fr.mu.Unlock()
+	for _, ev := range fr.events {
+		if err := send(ev); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestBinlogServer(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery("use `vt_db`", &sqltypes.Result{})
+	db.AddQuery("set @master_heartbeat_period = 30000000000", &sqltypes.Result{})
+	db.AddQuery("select @@global.server_uuid", sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.server_uuid", "varchar"), "00010203-0405-0607-0809-0a0b0c0d0e0f"))
+	db.AddQuery("show variables like 'rpl_semi_sync%'", sqltypes.MakeTestResult(sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar"), "rpl_semi_sync_master_enabled|ON"))
+	db.AddQuery(sqlSelectReplicaSettings, sqltypes.MakeTestResult(sqltypes.MakeTestFields("checksum|heartbeat", "varchar|int64"), "CRC32|30000000000"))
+
+	authFilePath := path.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(authFilePath, []byte(`{"repl": [{"Password": "secret"}]}`), 0600))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	testPort := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	defer func(savedPort int, savedBindAddress, savedAuthFile, savedKeyRange string) {
+		*port, *bindAddress, *authFile, *keyRange = savedPort, savedBindAddress, savedAuthFile, savedKeyRange
+	}(*port, *bindAddress, *authFile, *keyRange)
+	*port, *bindAddress, *authFile, *keyRange = testPort, "127.0.0.1", authFilePath, "-80"
+
+	f := mysql.NewMySQL56BinlogFormat()
+	xid, err := mysql.BinlogEventWithoutChecksum(f, mysql.NewXIDEvent(f, mysql.NewFakeBinlogStream()))
+	require.NoError(t, err)
+	relayer := &fakeRelayer{events: []mysql.BinlogEvent{xid}}
+	config := tabletenv.NewDefaultConfig()
+	params, _ := db.ConnParams().MysqlParams()
+	config.DB = dbconfigs.NewTestDBConfigs(*params, *params, "vt_db")
+	bs := NewServer(tabletenv.NewEnv(config, "BinlogServerTest"), relayer)
+	bs.filter = buildFilter("t1", "-80")
+	require.NoError(t, bs.Open())
+	defer bs.Close()
+
+	ctx := context.Background()
+	_, err = mysql.Connect(ctx, &mysql.ConnParams{Host: "127.0.0.1", Port: testPort, Uname: "repl", Pass: "wrong"})
+	assert.Error(t, err)
+	replica, err := mysql.Connect(ctx, &mysql.ConnParams{Host: "127.0.0.1", Port: testPort, Uname: "repl", Pass: "secret"})
+	require.NoError(t, err)
+	defer replica.Close()
+
+	// The queries of the replica run on MySQL.
+	_, err = replica.ExecuteFetch("set @master_heartbeat_period = 30000000000", 1, false)
+	require.NoError(t, err)
+	qr, err := replica.ExecuteFetch("select @@global.server_uuid", 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f", qr.Rows[0][0].ToString())
+	qr, err = replica.ExecuteFetch("show variables like 'rpl_semi_sync%'", 10, false)
+	require.NoError(t, err)
+	assert.Empty(t, qr.Rows)
+	_, err = replica.ExecuteFetch("select * from t1", 1, false)
+	assert.ErrorContains(t, err, "query not supported by the binlog server")
+
+	gtidSet, err := mysql.ParsePosition(mysql.Mysql56FlavorID, "00010203-0405-0607-0809-0a0b0c0d0e0f:1-5")
+	require.NoError(t, err)
+	require.NoError(t, replica.WriteComBinlogDumpGTID(1, "", 4, 0, gtidSet.GTIDSet.(mysql.Mysql56GTIDSet).SIDBlock()))
+	data, err := replica.ReadPacket()
+	require.NoError(t, err)
+	require.Equal(t, byte(mysql.OKPacket), data[0])
+	event := data[1:]
+	assert.True(t, mysql.NewMysql56BinlogEvent(event).IsXID())
+	assert.Equal(t, len(xid.Bytes())+4, len(event))
+	assert.Equal(t, crc32.ChecksumIEEE(event[:len(event)-4]), binary.LittleEndian.Uint32(event[len(event)-4:]))
+
+	relayer.mu.Lock()
+	defer relayer.mu.Unlock()
+	assert.True(t, gtidSet.Equal(relayer.startPos))
+	assert.Equal(t, buildFilter("t1", "-80"), relayer.filter)
+	assert.Equal(t, 30*time.Second, relayer.heartbeat)
+}
+
+func TestBuildFilter(t *testing.T) {
+	assert.Equal(t, &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "/.*/"}}}, buildFilter("", ""))
+	assert.Equal(t, &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{
+		{Match: "/^t1$/", Filter: "-80"},
+		{Match: "/^t\\.2$/", Filter: "-80"},
+		{Match: "/^log_/", Filter: "-80"},
+	}}, buildFilter("t1, t.2,,/^log_/", "-80"))
+}
+
+func TestCheckReplicaQuery(t *testing.T) {
+	for _, query := range []string{
+		"SET @master_binlog_checksum = @@global.binlog_checksum",
+		"SET @source_heartbeat_period = 30000001024",
+		"SET @@session.net_read_timeout = 60",
+		"SELECT UNIX_TIMESTAMP()",
+		"SELECT @@GLOBAL.SERVER_ID",
+		"SELECT @master_binlog_checksum FROM dual",
+		"SELECT @@version_comment LIMIT 1",
+		"SELECT VERSION(), DATABASE(), USER()",
+		"SELECT 1",
+		"SHOW VARIABLES LIKE 'SERVER_ID'",
+		"SHOW GLOBAL VARIABLES LIKE 'gtid_mode'",
+	} {
+		assert.NoError(t, checkReplicaQuery(query), query)
+	}
+	for _, query := range []string{
+		"SET GLOBAL read_only = 1",
+		"SELECT * FROM t1",
+		"SELECT (SELECT max(id) FROM t1)",
+		"SELECT SLEEP(100)",
+		"SELECT LOAD_FILE('/etc/passwd')",
+		"SELECT GET_LOCK('l', 100)",
+		"SELECT 1 FROM dual WHERE SLEEP(100)",
+		"SELECT @@GLOBAL.SERVER_ID INTO OUTFILE '/tmp/server_id'",
+		"SELECT mysql.version()",
+		"SET @x = SLEEP(100)",
+		"INSERT INTO t1 VALUES (1)",
+		"SHOW TABLES",
+		"DROP TABLE t1",
+	} {
+		assert.ErrorContains(t, checkReplicaQuery(query), "query not supported by the binlog server", query)
+	}
+}
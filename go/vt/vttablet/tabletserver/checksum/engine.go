@@ -0,0 +1,457 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checksum checks the consistency of the data of replicas with their
+// primary, in the manner of pt-table-checksum.
+//
+// The primary computes the checksums of the rows of its tables, chunk by
+// chunk, with statements that insert the checksums into the
+// _vt.table_checksums table. These statements are written to the binary log
+// in the STATEMENT format, so that the replicas execute them in turn and
+// compute the checksums of their own rows at the same point of the
+// replication stream. The primary then records its own checksums in the same
+// rows, and replicas compare theirs against them.
+package checksum
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/withddl"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const throttlerAppName = "table-checksum"
+
+var (
+	checkInterval         = flag.Duration("table_checksum_interval", 0, "Interval between two rounds of table checksums on the primary, and of checks of the checksums on replicas. 0 disables table checksums")
+	chunkSize             = flag.Int("table_checksum_chunk_size", 1000, "Number of rows whose checksum is computed in a single statement")
+	unhealthyOnDivergence = flag.Bool("table_checksum_unhealthy_on_divergence", false, "If set, replicas whose table checksums diverge from the primary report themselves unhealthy")
+)
+
+var (
+	chunksChecksummed    = stats.NewCountersWithSingleLabel("TableChecksumChunks", "Chunks of rows checksummed on the primary, by table", "Table")
+	checksumErrors       = stats.NewCounter("TableChecksumErrors", "Errors while computing or checking table checksums")
+	divergentChunks      = stats.NewGaugesWithSingleLabel("TableChecksumDivergentChunks", "Chunks of rows whose checksum on this replica diverges from the primary, by table", "Table")
+	lastDivergenceCheckS = stats.NewGauge("TableChecksumLastCheckTimestamp", "Time of the last check of the table checksums on this replica")
+)
+
+const (
+	sqlCreateSidecarDB           = "create database if not exists _vt"
+	sqlCreateTableChecksumsTable = `create table if not exists _vt.table_checksums (
+  table_name varbinary(128) not null,
+  chunk bigint unsigned not null,
+  lower_boundary blob,
+  upper_boundary blob,
+  this_cnt bigint unsigned not null,
+  this_crc varbinary(40) not null,
+  primary_cnt bigint unsigned,
+  primary_crc varbinary(40),
+  ts timestamp not null default current_timestamp on update current_timestamp,
+  primary key (table_name, chunk)
+) engine=InnoDB`
+
+	sqlSetStatementBinlogFormat = "set @@session.binlog_format = 'STATEMENT'"
+	sqlChunkUpperBoundary       = "select %s from %s.%s%s order by %s limit 1 offset %d"
+	sqlReplaceChecksum          = "replace into _vt.table_checksums (table_name, chunk, lower_boundary, upper_boundary, this_cnt, this_crc) " +
+		"select %s, %d, %s, %s, count(*), coalesce(lower(conv(bit_xor(cast(crc32(concat_ws('#', %s)) as unsigned)), 10, 16)), '0') from %s.%s%s"
+	sqlSelectChecksum       = "select this_cnt, this_crc from _vt.table_checksums where table_name = %s and chunk = %d"
+	sqlUpdatePrimaryCRC     = "update _vt.table_checksums set primary_cnt = %d, primary_crc = %s where table_name = %s and chunk = %d"
+	sqlDeleteExtraChunks    = "delete from _vt.table_checksums where table_name = %s and chunk > %d"
+	sqlDeleteDroppedTables  = "delete from _vt.table_checksums where table_name not in (%s)"
+	sqlSelectDivergentCount = "select table_name, count(*) from _vt.table_checksums " +
+		"where primary_cnt is not null and (this_cnt != primary_cnt or this_crc != primary_crc) group by table_name"
+)
+
+var withDDL = withddl.New([]string{
+	sqlCreateSidecarDB,
+	sqlCreateTableChecksumsTable,
+})
+
+// Engine computes the table checksums on primary tablets, and compares them
+// with the checksums of the rows on replica tablets.
+type Engine struct {
+	env             tabletenv.Env
+	se              *schema.Engine
+	throttlerClient *throttle.Client
+	errorLog        *logutil.ThrottledLogger
+
+	enabled   bool
+	chunkSize int
+	dbName    string
+
+	mu        sync.Mutex
+	isOpen    bool
+	isPrimary bool
+	pool      *dbconnpool.ConnectionPool
+	ticks     *timer.Timer
+	cancel    context.CancelFunc
+
+	// divergenceMu protects divergence and lastErr.
+	divergenceMu sync.Mutex
+	divergence   map[string]int64
+	lastErr      error
+}
+
+// NewEngine creates a new Engine.
+func NewEngine(env tabletenv.Env, se *schema.Engine, lagThrottler *throttle.Throttler) *Engine {
+	if *checkInterval <= 0 {
+		return &Engine{}
+	}
+	return &Engine{
+		env:             env,
+		se:              se,
+		throttlerClient: throttle.NewBackgroundClient(lagThrottler, throttlerAppName, throttle.ThrottleCheckPrimaryWrite),
+		errorLog:        logutil.NewThrottledLogger("TableChecksum", 60*time.Second),
+		enabled:         true,
+		chunkSize:       *chunkSize,
+		pool:            dbconnpool.NewConnectionPool("TableChecksumPool", 1, *mysqlctl.DbaIdleTimeout, *mysqlctl.PoolDynamicHostnameResolution),
+		ticks:           timer.NewTimer(*checkInterval),
+	}
+}
+
+// InitDBConfig initializes the name of the database whose tables are checksummed.
+func (e *Engine) InitDBConfig(dbName string) {
+	e.dbName = dbName
+}
+
+// MakePrimary starts computing the table checksums.
+func (e *Engine) MakePrimary() {
+	e.open(true)
+}
+
+// MakeNonPrimary starts checking the table checksums computed by the primary.
+func (e *Engine) MakeNonPrimary() {
+	e.open(false)
+}
+
+func (e *Engine) open(isPrimary bool) {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.isOpen && e.isPrimary == isPrimary {
+		return
+	}
+	e.closeLocked()
+
+	log.Infof("TableChecksum: opening, primary: %v", isPrimary)
+	e.pool.Open(e.env.Config().DB.DbaWithDB())
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	if isPrimary {
+		e.ticks.Start(func() { e.checksumTables(ctx) })
+	} else {
+		e.ticks.Start(func() { e.checkTables(ctx) })
+	}
+	e.isPrimary = isPrimary
+	e.isOpen = true
+}
+
+// Close stops computing or checking the table checksums.
+func (e *Engine) Close() {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closeLocked()
+}
+
+func (e *Engine) closeLocked() {
+	if !e.isOpen {
+		return
+	}
+	// The round in progress must be canceled before stopping the ticks,
+	// which waits for it to return.
+	e.cancel()
+	e.ticks.Stop()
+	e.pool.Close()
+	e.isOpen = false
+
+	e.divergenceMu.Lock()
+	e.divergence = nil
+	e.lastErr = nil
+	e.divergenceMu.Unlock()
+	divergentChunks.ResetAll()
+	log.Info("TableChecksum: closed")
+}
+
+// Divergence returns an error that describes the tables whose checksums on
+// this replica diverge from the primary, or the error of the last check.
+func (e *Engine) Divergence() error {
+	e.divergenceMu.Lock()
+	defer e.divergenceMu.Unlock()
+	if e.lastErr != nil {
+		return e.lastErr
+	}
+	if len(e.divergence) == 0 {
+		return nil
+	}
+	tables := make([]string, 0, len(e.divergence))
+	for table, chunks := range e.divergence {
+		tables = append(tables, fmt.Sprintf("%s (%d chunks)", table, chunks))
+	}
+	sort.Strings(tables)
+	return vterrors.Errorf(vtrpcpb.Code_DATA_LOSS, "table checksums diverge from the primary: %s", strings.Join(tables, ", "))
+}
+
+// HealthError returns the divergence of the tables from the primary if
+// replicas must report it in their health, and nil otherwise. Errors that
+// prevented the checks are not reported.
+func (e *Engine) HealthError() error {
+	if !*unhealthyOnDivergence {
+		return nil
+	}
+	e.divergenceMu.Lock()
+	divergent := len(e.divergence) > 0
+	e.divergenceMu.Unlock()
+	if !divergent {
+		return nil
+	}
+	return e.Divergence()
+}
+
+// checksumTables computes the checksums of all the tables that have a
+// primary key.
+func (e *Engine) checksumTables(ctx context.Context) {
+	defer e.env.LogError()
+
+	conn, err := e.pool.Get(ctx)
+	if err != nil {
+		e.recordError(err)
+		return
+	}
+	defer conn.Recycle()
+	if _, err := conn.ExecuteFetch(sqlSetStatementBinlogFormat, 0, false); err != nil {
+		e.recordError(vterrors.Wrap(err, "failed to use the STATEMENT binlog format"))
+		return
+	}
+
+	tables := e.se.GetSchema()
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checksummed := make([]string, 0, len(names))
+	for _, name := range names {
+		table := tables[name]
+		if len(table.PKColumns) == 0 || name == "dual" {
+			continue
+		}
+		if err := e.checksumTable(ctx, conn, table); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			e.recordError(vterrors.Wrapf(err, "failed to checksum table %s", name))
+			continue
+		}
+		checksummed = append(checksummed, sqltypes.EncodeStringSQL(name))
+	}
+	if len(checksummed) == 0 {
+		return
+	}
+	if _, err := e.exec(ctx, conn, fmt.Sprintf(sqlDeleteDroppedTables, strings.Join(checksummed, ", "))); err != nil {
+		e.recordError(err)
+	}
+}
+
+// checksumTable computes the checksums of the chunks of rows of a table, in
+// the order of its primary key.
+func (e *Engine) checksumTable(ctx context.Context, conn *dbconnpool.PooledDBConnection, table *schema.Table) error {
+	name := table.Name.String()
+	pk := make([]string, 0, len(table.PKColumns))
+	for _, i := range table.PKColumns {
+		pk = append(pk, sqlescape.EscapeID(table.Fields[i].Name))
+	}
+	pkList := strings.Join(pk, ", ")
+	columns := make([]string, 0, len(table.Fields)+1)
+	isNull := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		columns = append(columns, sqlescape.EscapeID(field.Name))
+		isNull = append(isNull, fmt.Sprintf("isnull(%s)", sqlescape.EscapeID(field.Name)))
+	}
+	columns = append(columns, fmt.Sprintf("concat(%s)", strings.Join(isNull, ", ")))
+
+	var lower string
+	for chunk := 0; ; chunk++ {
+		e.throttlerClient.Throttle(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lowerWhere := ""
+		if lower != "" {
+			lowerWhere = fmt.Sprintf(" where (%s) > %s", pkList, lower)
+		}
+		qr, err := e.exec(ctx, conn, fmt.Sprintf(sqlChunkUpperBoundary,
+			pkList, sqlescape.EscapeID(e.dbName), sqlescape.EscapeID(name), lowerWhere, pkList, e.chunkSize-1))
+		if err != nil {
+			return err
+		}
+		var upper string
+		if len(qr.Rows) != 0 {
+			upper = encodeTuple(qr.Rows[0])
+		}
+
+		var where []string
+		if lower != "" {
+			where = append(where, fmt.Sprintf("(%s) > %s", pkList, lower))
+		}
+		if upper != "" {
+			where = append(where, fmt.Sprintf("(%s) <= %s", pkList, upper))
+		}
+		whereClause := ""
+		if len(where) != 0 {
+			whereClause = " where " + strings.Join(where, " and ")
+		}
+		if _, err := e.exec(ctx, conn, fmt.Sprintf(sqlReplaceChecksum,
+			sqltypes.EncodeStringSQL(name), chunk, encodeBoundary(lower), encodeBoundary(upper), strings.Join(columns, ", "),
+			sqlescape.EscapeID(e.dbName), sqlescape.EscapeID(name), whereClause)); err != nil {
+			return err
+		}
+		qr, err = e.exec(ctx, conn, fmt.Sprintf(sqlSelectChecksum, sqltypes.EncodeStringSQL(name), chunk))
+		if err != nil {
+			return err
+		}
+		if len(qr.Rows) != 1 {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected number of rows for the checksum of chunk %d: %d", chunk, len(qr.Rows))
+		}
+		cnt, err := qr.Rows[0][0].ToUint64()
+		if err != nil {
+			return err
+		}
+		if _, err := e.exec(ctx, conn, fmt.Sprintf(sqlUpdatePrimaryCRC,
+			cnt, sqltypes.EncodeStringSQL(qr.Rows[0][1].ToString()), sqltypes.EncodeStringSQL(name), chunk)); err != nil {
+			return err
+		}
+		chunksChecksummed.Add(name, 1)
+
+		if upper == "" {
+			_, err := e.exec(ctx, conn, fmt.Sprintf(sqlDeleteExtraChunks, sqltypes.EncodeStringSQL(name), chunk))
+			return err
+		}
+		lower = upper
+	}
+}
+
+// checkTables reads the chunks whose checksums diverge from the primary.
+func (e *Engine) checkTables(ctx context.Context) {
+	defer e.env.LogError()
+
+	conn, err := e.pool.Get(ctx)
+	if err != nil {
+		e.recordCheckError(err)
+		return
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.ExecuteFetch(sqlSelectDivergentCount, 10000, false)
+	if err != nil {
+		if sqlErr, ok := err.(*mysql.SQLError); !ok || sqlErr.Number() != mysql.ERNoSuchTable {
+			e.recordCheckError(err)
+			return
+		}
+		// The primary did not checksum any table yet.
+		qr = &sqltypes.Result{}
+	}
+	divergence := make(map[string]int64, len(qr.Rows))
+	for _, row := range qr.Rows {
+		chunks, err := row[1].ToInt64()
+		if err != nil {
+			e.recordCheckError(err)
+			return
+		}
+		divergence[row[0].ToString()] = chunks
+	}
+
+	divergentChunks.ResetAll()
+	for table, chunks := range divergence {
+		divergentChunks.Set(table, chunks)
+	}
+	lastDivergenceCheckS.Set(time.Now().Unix())
+
+	e.divergenceMu.Lock()
+	e.divergence = divergence
+	e.lastErr = nil
+	e.divergenceMu.Unlock()
+}
+
+func (e *Engine) exec(ctx context.Context, conn *dbconnpool.PooledDBConnection, query string) (*sqltypes.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return withDDL.Exec(ctx, query, conn.ExecuteFetch, conn.ExecuteFetch)
+}
+
+func (e *Engine) recordError(err error) {
+	e.errorLog.Errorf("%v", err)
+	checksumErrors.Add(1)
+}
+
+// recordCheckError records an error that prevented checking the checksums on
+// a replica, so that it is reported until the next successful check.
+func (e *Engine) recordCheckError(err error) {
+	e.recordError(err)
+	e.divergenceMu.Lock()
+	e.lastErr = err
+	e.divergenceMu.Unlock()
+}
+
+// encodeTuple encodes the values of a row as a tuple of SQL literals.
+func encodeTuple(row []sqltypes.Value) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, v := range row {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		v.EncodeSQLStringBuilder(&b)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// encodeBoundary encodes a chunk boundary for its column, NULL for the
+// boundaries of the first and last chunks.
+func encodeBoundary(boundary string) string {
+	if boundary == "" {
+		return "null"
+	}
+	return sqltypes.EncodeStringSQL(boundary)
+}
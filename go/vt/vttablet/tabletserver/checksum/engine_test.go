@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checksum
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestChecksumTable(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	e := newTestEngine(db)
+	defer e.pool.Close()
+	chunksChecksummed.ResetAll()
+
+	db.AddQuery("select `id` from `vt_db`.`t1` order by `id` limit 1 offset 1", sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "2"))
+	db.AddQuery("replace into _vt.table_checksums (table_name, chunk, lower_boundary, upper_boundary, this_cnt, this_crc) "+
+		"select 't1', 0, null, '(2)', count(*), coalesce(lower(conv(bit_xor(cast(crc32(concat_ws('#', `id`, `val`, concat(isnull(`id`), isnull(`val`)))) as unsigned)), 10, 16)), '0') "+
+		"from `vt_db`.`t1` where (`id`) <= (2)", &sqltypes.Result{})
+	db.AddQuery("select this_cnt, this_crc from _vt.table_checksums where table_name = 't1' and chunk = 0", sqltypes.MakeTestResult(sqltypes.MakeTestFields("this_cnt|this_crc", "uint64|varbinary"), "2|abc"))
+	db.AddQuery("update _vt.table_checksums set primary_cnt = 2, primary_crc = 'abc' where table_name = 't1' and chunk = 0", &sqltypes.Result{})
+
+	db.AddQuery("select `id` from `vt_db`.`t1` where (`id`) > (2) order by `id` limit 1 offset 1", &sqltypes.Result{})
+	db.AddQuery("replace into _vt.table_checksums (table_name, chunk, lower_boundary, upper_boundary, this_cnt, this_crc) "+
+		"select 't1', 1, '(2)', null, count(*), coalesce(lower(conv(bit_xor(cast(crc32(concat_ws('#', `id`, `val`, concat(isnull(`id`), isnull(`val`)))) as unsigned)), 10, 16)), '0') "+
+		"from `vt_db`.`t1` where (`id`) > (2)", &sqltypes.Result{})
+	db.AddQuery("select this_cnt, this_crc from _vt.table_checksums where table_name = 't1' and chunk = 1", sqltypes.MakeTestResult(sqltypes.MakeTestFields("this_cnt|this_crc", "uint64|varbinary"), "1|def"))
+	db.AddQuery("update _vt.table_checksums set primary_cnt = 1, primary_crc = 'def' where table_name = 't1' and chunk = 1", &sqltypes.Result{})
+	db.AddQuery("delete from _vt.table_checksums where table_name = 't1' and chunk > 1", &sqltypes.Result{})
+
+	conn, err := e.pool.Get(context.Background())
+	require.NoError(t, err)
+	defer conn.Recycle()
+	table := &schema.Table{
+		Name: sqlparser.NewIdentifierCS("t1"),
+		Fields: []*querypb.Field{
+			{Name: "id", Type: sqltypes.Int64},
+			{Name: "val", Type: sqltypes.VarChar},
+		},
+		PKColumns: []int{0},
+	}
+	err = e.checksumTable(context.Background(), conn, table)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, chunksChecksummed.Counts()["t1"])
+}
+
+func TestCheckTables(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	e := newTestEngine(db)
+	defer e.pool.Close()
+
+	db.AddRejectedQuery(sqlSelectDivergentCount, mysql.NewSQLError(mysql.ERNoSuchTable, mysql.SSUnknownSQLState, "table doesn't exist"))
+	e.checkTables(context.Background())
+	assert.NoError(t, e.Divergence())
+	db.DeleteRejectedQuery(sqlSelectDivergentCount)
+
+	db.AddQuery(sqlSelectDivergentCount, sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|count(*)", "varbinary|int64"), "t2|1", "t1|3"))
+	e.checkTables(context.Background())
+	assert.EqualError(t, e.Divergence(), "table checksums diverge from the primary: t1 (3 chunks), t2 (1 chunks)")
+	assert.EqualValues(t, 3, divergentChunks.Counts()["t1"])
+	assert.NoError(t, e.HealthError())
+
+	*unhealthyOnDivergence = true
+	defer func() { *unhealthyOnDivergence = false }()
+	assert.Error(t, e.HealthError())
+
+	db.AddQuery(sqlSelectDivergentCount, &sqltypes.Result{})
+	e.checkTables(context.Background())
+	assert.NoError(t, e.Divergence())
+	assert.NoError(t, e.HealthError())
+	assert.Empty(t, divergentChunks.Counts())
+}
+
+func newTestEngine(db *fakesqldb.DB) *Engine {
+	config := tabletenv.NewDefaultConfig()
+	params, _ := db.ConnParams().MysqlParams()
+	cp := *params
+	dbc := dbconfigs.NewTestDBConfigs(cp, cp, "")
+
+	e := &Engine{
+		env:       tabletenv.NewEnv(config, "ChecksumTest"),
+		errorLog:  logutil.NewThrottledLogger("TableChecksumTest", 60*time.Second),
+		enabled:   true,
+		chunkSize: 2,
+		dbName:    "vt_db",
+		pool:      dbconnpool.NewConnectionPool("TableChecksumTestPool", 1, time.Minute, 0),
+	}
+	e.pool.Open(dbc.DbaWithDB())
+	return e
+}
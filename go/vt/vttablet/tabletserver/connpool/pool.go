@@ -64,6 +64,9 @@ type Pool struct {
 	waiterQueueFull    sync2.AtomicInt64
 	dbaPool            *dbconnpool.ConnectionPool
 	appDebugParams     dbconfigs.Connector
+	// waiters orders the callers waiting for a connection by priority,
+	// if priority scheduling is enabled.
+	waiters *waiterQueue
 }
 
 // NewPool creates a new Pool. The name is used
@@ -80,6 +83,9 @@ func NewPool(env tabletenv.Env, name string, cfg tabletenv.ConnPoolConfig) *Pool
 		waiterCap:          int64(cfg.MaxWaiters),
 		dbaPool:            dbconnpool.NewConnectionPool("", 1, idleTimeout, 0),
 	}
+	if config := env.Config(); config != nil && config.EnablePriorityScheduling {
+		cp.waiters = &waiterQueue{}
+	}
 	if name == "" {
 		return cp
 	}
@@ -194,6 +200,16 @@ func (cp *Pool) Get(ctx context.Context) (*DBConn, error) {
 		ctx, cancel = context.WithTimeout(ctx, cp.timeout)
 		defer cancel()
 	}
+	// When the pool is exhausted, the callers wait for their turn by
+	// priority before waiting on the pool.
+	if cp.waiters != nil && (p.Available() == 0 || cp.waiters.len() > 0) {
+		priority := PriorityFromContext(ctx)
+		span.Annotate("priority", priority)
+		if err := cp.waiters.wait(ctx, priority); err != nil {
+			return nil, err
+		}
+		defer cp.waiters.done()
+	}
 	r, err := p.Get(ctx)
 	if err != nil {
 		return nil, err
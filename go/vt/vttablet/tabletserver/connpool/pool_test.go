@@ -110,6 +110,64 @@ func TestConnPoolMaxWaiters(t *testing.T) {
 	wg.Wait()
 }
 
+func TestConnPoolPriorityScheduling(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	config := tabletenv.NewDefaultConfig()
+	config.EnablePriorityScheduling = true
+	connPool := NewPool(tabletenv.NewEnv(config, "PoolTest"), "TestPool", tabletenv.ConnPoolConfig{
+		Size: 1,
+	})
+	connPool.Open(db.ConnParams(), db.ConnParams(), db.ConnParams())
+	defer connPool.Close()
+
+	dbConn, err := connPool.Get(context.Background())
+	require.NoError(t, err)
+
+	// The first waiter waits on the pool, the others are queued by priority.
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	get := func(priority int) {
+		defer wg.Done()
+		conn, err := connPool.Get(WithPriority(context.Background(), priority))
+		if !assert.NoError(t, err) {
+			return
+		}
+		mu.Lock()
+		order = append(order, priority)
+		mu.Unlock()
+		conn.Recycle()
+	}
+	wg.Add(1)
+	go get(90)
+	require.Eventually(t, func() bool {
+		connPool.waiters.mu.Lock()
+		defer connPool.waiters.mu.Unlock()
+		return connPool.waiters.waiting
+	}, 5*time.Second, time.Millisecond)
+	for i, priority := range []int{80, 10, 50} {
+		wg.Add(1)
+		go get(priority)
+		queued := i + 1
+		require.Eventually(t, func() bool {
+			return connPool.waiters.len() == queued
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	// A queued waiter whose context expires leaves the queue.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = connPool.Get(WithPriority(ctx, 0))
+	assert.EqualError(t, err, "resource pool timed out")
+	assert.Equal(t, 3, connPool.waiters.len())
+
+	dbConn.Recycle()
+	wg.Wait()
+	assert.Equal(t, []int{90, 10, 50, 80}, order)
+	assert.Equal(t, 0, connPool.waiters.len())
+}
+
 func TestConnPoolGetEmptyDebugConfig(t *testing.T) {
 	db := fakesqldb.New(t)
 	debugConn := db.ConnParamsWithUname("")
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connpool
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/pools"
+)
+
+// DefaultPriority is the priority of the queries that were not given one.
+// Priorities range from 0, the highest, to 100, the lowest.
+const DefaultPriority = 50
+
+type priorityKey struct{}
+
+// WithPriority returns a context for the queries of the given priority. When
+// priority scheduling is enabled, it decides the order in which the callers
+// waiting for a connection of an exhausted pool get one.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority set in the context by
+// WithPriority, or DefaultPriority.
+func PriorityFromContext(ctx context.Context) int {
+	if priority, ok := ctx.Value(priorityKey{}).(int); ok {
+		return priority
+	}
+	return DefaultPriority
+}
+
+// waiterQueue orders the callers waiting for a connection of an exhausted
+// pool. Only one caller at a time waits on the pool itself: when it got its
+// connection, the queued waiter with the highest priority is next, in order
+// of arrival for equal priorities.
+type waiterQueue struct {
+	mu      sync.Mutex
+	waiters waiterHeap
+	seq     uint64
+	// waiting is set while a caller waits on the pool.
+	waiting bool
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+// wait blocks until it is the turn of the caller to wait on the pool. The
+// caller must call done once it got its connection, or failed to.
+func (wq *waiterQueue) wait(ctx context.Context, priority int) error {
+	wq.mu.Lock()
+	if !wq.waiting {
+		wq.waiting = true
+		wq.mu.Unlock()
+		return nil
+	}
+	w := &waiter{priority: priority, seq: wq.seq, ready: make(chan struct{})}
+	wq.seq++
+	heap.Push(&wq.waiters, w)
+	wq.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+	select {
+	case <-w.ready:
+		// It became our turn while the context expired: pass it on.
+		wq.nextLocked()
+	default:
+		heap.Remove(&wq.waiters, w.index)
+	}
+	return pools.ErrTimeout
+}
+
+// done gives the turn to wait on the pool to the next waiter.
+func (wq *waiterQueue) done() {
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+	wq.nextLocked()
+}
+
+func (wq *waiterQueue) nextLocked() {
+	if len(wq.waiters) == 0 {
+		wq.waiting = false
+		return
+	}
+	w := heap.Pop(&wq.waiters).(*waiter)
+	close(w.ready)
+}
+
+// len returns the number of queued waiters.
+func (wq *waiterQueue) len() int {
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+	return len(wq.waiters)
+}
+
+// waiterHeap implements heap.Interface.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return w
+}
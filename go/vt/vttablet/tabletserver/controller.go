@@ -23,9 +23,12 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/vexec"
 
 	"time"
@@ -58,6 +61,28 @@ type Controller interface {
 	// EnterLameduck causes tabletserver to enter the lameduck state.
 	EnterLameduck()
 
+	// SetDraining marks the tablet as draining in its health stream, or
+	// stops draining it.
+	SetDraining(draining bool) error
+
+	// IsDraining returns true if the tablet is draining.
+	IsDraining() bool
+
+	// FreezeWrites makes the tablet reject the queries that write until the
+	// given time, or until UnfreezeWrites is called if it is zero.
+	FreezeWrites(until time.Time)
+
+	// UnfreezeWrites makes the tablet execute the queries that write again.
+	UnfreezeWrites()
+
+	// WritesFrozen returns true if the tablet rejects the queries that write,
+	// and until when it does.
+	WritesFrozen() (bool, time.Time)
+
+	// InFlight returns the number of queries that the tablet is executing,
+	// and the number of its open transactions and reserved connections.
+	InFlight() (queries int64, transactions int64)
+
 	// IsServing returns true if the query service is running
 	IsServing() bool
 
@@ -93,6 +118,15 @@ type Controller interface {
 
 	// TopoServer returns the topo server.
 	TopoServer() *topo.Server
+
+	// LagThrottler returns the throttler of the tablet.
+	LagThrottler() *throttle.Throttler
+
+	// HotRows returns the hot row detector of the tablet.
+	HotRows() *hotrows.Detector
+
+	// ReplicationLagHistory returns the replication lag history of the tablet.
+	ReplicationLagHistory() *repltracker.LagHistory
 }
 
 // Ensure TabletServer satisfies Controller interface.
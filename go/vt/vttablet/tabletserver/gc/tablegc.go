@@ -107,9 +107,13 @@ type TableGC struct {
 	dropTablesChan         chan string
 	transitionRequestsChan chan *transitionRequest
 	purgeRequestsChan      chan bool
+	lifecycleMutex         sync.Mutex
 	// lifecycleStates indicates what states a GC table goes through. The user can set
-	// this with -table_gc_lifecycle, such that some states can be skipped.
+	// this with -table_gc_lifecycle, or with the table GC lifecycle of the keyspace
+	// settings, such that some states can be skipped.
 	lifecycleStates map[schema.TableGCState]bool
+	// serverSupportsFastDrops skips the PURGE and EVAC states of any lifecycle.
+	serverSupportsFastDrops bool
 }
 
 // Status published some status valus from the collector
@@ -166,7 +170,7 @@ func (collector *TableGC) Open() (err error) {
 		// already open
 		return nil
 	}
-	collector.lifecycleStates, err = schema.ParseGCLifecycle(*gcLifecycle)
+	lifecycleStates, err := schema.ParseGCLifecycle(*gcLifecycle)
 	if err != nil {
 		return fmt.Errorf("Error parsing -table_gc_lifecycle flag: %+v", err)
 	}
@@ -184,19 +188,61 @@ func (collector *TableGC) Open() (err error) {
 		return err
 	}
 	defer conn.Close()
-	serverSupportsFastDrops, err := conn.SupportsCapability(mysql.FastDropTableFlavorCapability)
+	// MySQL 8.0.23 and onwards supports fast DROP TABLE operations. This means we don't have to
+	// go through the purging & evac cycle: once the table has been held for long enough, we can just
+	// move on to dropping it. Dropping a large table in 8.0.23 is expected to take several seconds, and
+	// should not block other queries or place any locks on the buffer pool.
+	collector.serverSupportsFastDrops, err = conn.SupportsCapability(mysql.FastDropTableFlavorCapability)
 	if err != nil {
 		return err
 	}
-	if serverSupportsFastDrops {
-		// MySQL 8.0.23 and onwards supports fast DROP TABLE operations. This means we don't have to
-		// go through the purging & evac cycle: once the table has been held for long enough, we can just
-		// move on to dropping it. Dropping a large table in 8.0.23 is expected to take several seconds, and
-		// should not block other queries or place any locks on the buffer pool.
-		delete(collector.lifecycleStates, schema.PurgeTableGCState)
-		delete(collector.lifecycleStates, schema.EvacTableGCState)
+	collector.setLifecycleStates(lifecycleStates)
+
+	return nil
+}
+
+// setLifecycleStates sets the states GC tables go through, skipping PURGE and EVAC if
+// the server supports fast drops.
+func (collector *TableGC) setLifecycleStates(states map[schema.TableGCState]bool) {
+	if collector.serverSupportsFastDrops {
+		delete(states, schema.PurgeTableGCState)
+		delete(states, schema.EvacTableGCState)
 	}
 
+	collector.lifecycleMutex.Lock()
+	defer collector.lifecycleMutex.Unlock()
+	collector.lifecycleStates = states
+}
+
+// inLifecycle answers 'true' when GC tables go through the given state.
+func (collector *TableGC) inLifecycle(state schema.TableGCState) bool {
+	collector.lifecycleMutex.Lock()
+	defer collector.lifecycleMutex.Unlock()
+	return collector.lifecycleStates[state]
+}
+
+// refreshLifecycle reads the table GC lifecycle of the keyspace settings, which
+// overrides -table_gc_lifecycle.
+func (collector *TableGC) refreshLifecycle(ctx context.Context) error {
+	lifecycle := *gcLifecycle
+	if collector.ts != nil {
+		ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+		defer cancel()
+
+		settings, err := collector.ts.GetKeyspaceSettings(ctx, collector.keyspace)
+		if err != nil {
+			return err
+		}
+		if settings.TableGCLifecycle != "" {
+			lifecycle = settings.TableGCLifecycle
+		}
+	}
+
+	states, err := schema.ParseGCLifecycle(lifecycle)
+	if err != nil {
+		return err
+	}
+	collector.setLifecycleStates(states)
 	return nil
 }
 
@@ -316,7 +362,7 @@ func (collector *TableGC) nextState(fromState schema.TableGCState) *schema.Table
 	default:
 		return nil
 	}
-	if _, ok := collector.lifecycleStates[state]; !ok {
+	if !collector.inLifecycle(state) {
 		return collector.nextState(state)
 	}
 	return &state
@@ -358,7 +404,7 @@ func (collector *TableGC) shouldTransitionTable(tableName string) (shouldTransit
 		// irrelevant table
 		return false, state, uuid, nil
 	}
-	if _, ok := collector.lifecycleStates[state]; ok {
+	if collector.inLifecycle(state) {
 		// this state is in our expected lifecycle. Let's check table's time hint:
 		timeNow := time.Now().UTC()
 		if timeNow.Before(t) {
@@ -386,6 +432,10 @@ func (collector *TableGC) checkTables(ctx context.Context) error {
 
 	log.Infof("TableGC: check tables")
 
+	if err := collector.refreshLifecycle(ctx); err != nil {
+		log.Errorf("TableGC: error reading the table GC lifecycle of keyspace %s, keeping the current lifecycle: %+v", collector.keyspace, err)
+	}
+
 	res, err := conn.Exec(ctx, sqlShowVtTables, math.MaxInt32, true)
 	if err != nil {
 		return err
@@ -17,11 +17,15 @@ limitations under the License.
 package gc
 
 import (
+	"context"
 	"testing"
 
 	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNextTableToPurge(t *testing.T) {
@@ -151,6 +155,33 @@ func TestNextState(t *testing.T) {
 	}
 }
 
+func TestRefreshLifecycle(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	collector := &TableGC{ts: ts, keyspace: "ks"}
+
+	// Without keyspace settings, -table_gc_lifecycle applies.
+	require.NoError(t, collector.refreshLifecycle(ctx))
+	assert.True(t, collector.inLifecycle(schema.HoldTableGCState))
+	assert.True(t, collector.inLifecycle(schema.PurgeTableGCState))
+	assert.True(t, collector.inLifecycle(schema.EvacTableGCState))
+
+	require.NoError(t, ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{TableGCLifecycle: "hold,drop"}))
+	require.NoError(t, collector.refreshLifecycle(ctx))
+	assert.True(t, collector.inLifecycle(schema.HoldTableGCState))
+	assert.False(t, collector.inLifecycle(schema.PurgeTableGCState))
+	assert.False(t, collector.inLifecycle(schema.EvacTableGCState))
+	assert.Equal(t, schema.DropTableGCState, *collector.nextState(schema.HoldTableGCState))
+
+	// Servers that support fast drops skip PURGE and EVAC whatever the lifecycle.
+	collector.serverSupportsFastDrops = true
+	require.NoError(t, ts.SaveKeyspaceSettings(ctx, "ks", &topo.KeyspaceSettings{TableGCLifecycle: "purge,evac,drop"}))
+	require.NoError(t, collector.refreshLifecycle(ctx))
+	assert.False(t, collector.inLifecycle(schema.PurgeTableGCState))
+	assert.False(t, collector.inLifecycle(schema.EvacTableGCState))
+	assert.True(t, collector.inLifecycle(schema.DropTableGCState))
+}
+
 func TestShouldTransitionTable(t *testing.T) {
 	tt := []struct {
 		table            string
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	sqlMysqldPaths = "select @@global.datadir as datadir, @@global.pid_file as pid_file"
+
+	mysqldHealthQueryTimeout = 5 * time.Second
+)
+
+// mysqldHealth collects the stats of mysqld that the health stream reports
+// besides the replication lag: the free space of its disk, its memory, the
+// states of its replication threads and its semi-sync status.
+type mysqldHealth struct {
+	mysqld   mysqlctl.MysqlDaemon
+	procPath string
+
+	mu sync.Mutex
+	// dataDir and pidFile are read from mysqld once. They are empty until
+	// then, and pidFile stays empty if it is not on the host of the tablet.
+	dataDir string
+	pidFile string
+}
+
+func newMysqldHealth(mysqld mysqlctl.MysqlDaemon) *mysqldHealth {
+	return &mysqldHealth{
+		mysqld:   mysqld,
+		procPath: "/proc",
+	}
+}
+
+// collect sets the stats of mysqld in stats. The stats that can't be read are
+// left unset.
+func (mh *mysqldHealth) collect(tabletType topodatapb.TabletType, stats *querypb.RealtimeStats) {
+	if mh == nil || mh.mysqld == nil {
+		return
+	}
+
+	if tabletType == topodatapb.TabletType_PRIMARY {
+		stats.SemiSyncPrimaryStatus, _ = mh.mysqld.SemiSyncStatus()
+		if stats.SemiSyncPrimaryStatus {
+			stats.SemiSyncPrimaryClients = mh.mysqld.SemiSyncClients()
+		}
+	} else {
+		_, stats.SemiSyncReplicaStatus = mh.mysqld.SemiSyncStatus()
+		if status, err := mh.mysqld.ReplicationStatus(); err == nil {
+			stats.ReplicationIoThreadState = int32(status.IOState)
+			stats.ReplicationSqlThreadState = int32(status.SQLState)
+		}
+	}
+
+	dataDir, pidFile := mh.paths()
+	if pidFile == "" {
+		// mysqld does not run on this host, so neither its disk nor its
+		// memory can be read.
+		return
+	}
+	if freePercent, err := diskFreePercent(dataDir); err == nil {
+		stats.DiskFreePercent = freePercent
+	}
+	if memory, err := mh.memoryBytes(pidFile); err == nil {
+		stats.MysqldMemoryBytes = memory
+	}
+}
+
+// paths returns the data directory and the pid file of mysqld, or empty paths
+// if mysqld does not run on the host of the tablet.
+func (mh *mysqldHealth) paths() (string, string) {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	if mh.dataDir != "" {
+		return mh.dataDir, mh.pidFile
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mysqldHealthQueryTimeout)
+	defer cancel()
+	qr, err := mh.mysqld.FetchSuperQuery(ctx, sqlMysqldPaths)
+	if err != nil {
+		return "", ""
+	}
+	row := qr.Named().Row()
+	if row == nil || row["datadir"].ToString() == "" {
+		return "", ""
+	}
+	mh.dataDir = row["datadir"].ToString()
+	pidFile := row["pid_file"].ToString()
+	if pidFile != "" && !filepath.IsAbs(pidFile) {
+		pidFile = filepath.Join(mh.dataDir, pidFile)
+	}
+	if _, err := os.Stat(pidFile); err == nil {
+		mh.pidFile = pidFile
+	} else {
+		log.Infof("The pid file of mysqld %q is not on this host, its disk and memory are not reported in the health stream", pidFile)
+	}
+	return mh.dataDir, mh.pidFile
+}
+
+// memoryBytes returns the resident memory of the mysqld process of a pid file.
+func (mh *mysqldHealth) memoryBytes(pidFile string) (uint64, error) {
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %v", pidFile, err)
+	}
+
+	f, err := os.Open(filepath.Join(mh.procPath, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// The line reads like "VmRSS:	  123456 kB".
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmRSS:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no VmRSS in the status of process %d", pid)
+}
+
+// diskFreePercent returns the percentage of the space of the disk of a
+// directory that is available to mysqld.
+func diskFreePercent(dir string) (float64, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fs); err != nil {
+		return 0, err
+	}
+	if fs.Blocks == 0 {
+		return 0, fmt.Errorf("no blocks in the disk of %s", dir)
+	}
+	return 100 * float64(fs.Bavail) / float64(fs.Blocks), nil
+}
@@ -41,6 +41,7 @@ import (
 	"vitess.io/vitess/go/history"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -78,12 +79,17 @@ type healthStreamer struct {
 	conns                  *connpool.Pool
 	initSuccess            bool
 	signalWhenSchemaChange bool
+
+	mysqldHealth *mysqldHealth
 }
 
 func newHealthStreamer(env tabletenv.Env, alias *topodatapb.TabletAlias) *healthStreamer {
 	var newTimer *timer.Timer
 	var pool *connpool.Pool
-	if env.Config().SignalWhenSchemaChange {
+	// The schema of an unmanaged database can change outside of Vitess, so
+	// its changes are always signaled.
+	signalWhenSchemaChange := env.Config().SignalWhenSchemaChange || env.Config().Unmanaged
+	if signalWhenSchemaChange {
 		reloadTime := env.Config().SignalSchemaChangeReloadIntervalSeconds.Get()
 		newTimer = timer.NewTimer(reloadTime)
 		// We need one connection for the reloader.
@@ -109,13 +115,16 @@ func newHealthStreamer(env tabletenv.Env, alias *topodatapb.TabletAlias) *health
 		history:                history.New(5),
 		ticks:                  newTimer,
 		conns:                  pool,
-		signalWhenSchemaChange: env.Config().SignalWhenSchemaChange,
+		signalWhenSchemaChange: signalWhenSchemaChange,
 	}
 }
 
-func (hs *healthStreamer) InitDBConfig(target *querypb.Target, cp dbconfigs.Connector) {
+func (hs *healthStreamer) InitDBConfig(target *querypb.Target, cp dbconfigs.Connector, mysqld mysqlctl.MysqlDaemon) {
 	hs.state.Target = proto.Clone(target).(*querypb.Target)
 	hs.dbConfig = cp
+	if mysqld != nil {
+		hs.mysqldHealth = newMysqldHealth(mysqld)
+	}
 }
 
 func (hs *healthStreamer) Open() {
@@ -209,9 +218,19 @@ func (hs *healthStreamer) unregister(ch chan *querypb.StreamHealthResponse) {
 }
 
 func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, terTimestamp time.Time, lag time.Duration, err error, serving bool) {
+	// The stats of mysqld are read before locking, so that clients can
+	// register while mysqld is slow to respond.
+	mysqldStats := &querypb.RealtimeStats{}
+	hs.mysqldHealth.collect(tabletType, mysqldStats)
+
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 
+	if tabletType != hs.state.Target.TabletType {
+		// A tablet is drained before its type changes, and is not draining
+		// anymore once it did.
+		hs.state.Draining = false
+	}
 	hs.state.Target.TabletType = tabletType
 	if tabletType == topodatapb.TabletType_PRIMARY {
 		hs.state.TabletExternallyReparentedTimestamp = terTimestamp.Unix()
@@ -228,6 +247,13 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, terTimes
 
 	hs.state.RealtimeStats.FilteredReplicationLagSeconds, hs.state.RealtimeStats.BinlogPlayersCount = blpFunc()
 	hs.state.RealtimeStats.Qps = hs.stats.QPSRates.TotalRate()
+	hs.state.RealtimeStats.DiskFreePercent = mysqldStats.DiskFreePercent
+	hs.state.RealtimeStats.MysqldMemoryBytes = mysqldStats.MysqldMemoryBytes
+	hs.state.RealtimeStats.ReplicationIoThreadState = mysqldStats.ReplicationIoThreadState
+	hs.state.RealtimeStats.ReplicationSqlThreadState = mysqldStats.ReplicationSqlThreadState
+	hs.state.RealtimeStats.SemiSyncPrimaryStatus = mysqldStats.SemiSyncPrimaryStatus
+	hs.state.RealtimeStats.SemiSyncReplicaStatus = mysqldStats.SemiSyncReplicaStatus
+	hs.state.RealtimeStats.SemiSyncPrimaryClients = mysqldStats.SemiSyncPrimaryClients
 
 	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
 
@@ -241,6 +267,27 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, terTimes
 	})
 }
 
+// SetDraining marks the tablet as draining in the health stream, or stops
+// draining it. The change is broadcast right away, so that vtgates stop
+// sending queries to the tablet as soon as possible.
+func (hs *healthStreamer) SetDraining(draining bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.state.Draining == draining {
+		return
+	}
+	hs.state.Draining = draining
+	hs.broadCastToClients(proto.Clone(hs.state).(*querypb.StreamHealthResponse))
+}
+
+// IsDraining returns true if the tablet is draining.
+func (hs *healthStreamer) IsDraining() bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.state.Draining
+}
+
 func (hs *healthStreamer) broadCastToClients(shr *querypb.StreamHealthResponse) {
 	for ch := range hs.clients {
 		select {
@@ -291,6 +338,13 @@ func (hs *healthStreamer) AppendDetails(details []*kv) []*kv {
 			Value: hs.state.RealtimeStats.HealthError,
 		})
 	}
+	if hs.state.Draining {
+		details = append(details, &kv{
+			Key:   "Draining",
+			Class: unhappyClass,
+			Value: "ON",
+		})
+	}
 
 	return details
 }
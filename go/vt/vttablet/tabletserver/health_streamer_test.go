@@ -19,15 +19,19 @@ package tabletserver
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -68,11 +72,11 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	}
 	blpFunc = testBlpFunc
 	hs := newHealthStreamer(env, alias)
-	hs.InitDBConfig(&querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}, config.DB.DbaWithDB())
+	hs.InitDBConfig(&querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}, config.DB.DbaWithDB(), nil)
 	hs.Open()
 	defer hs.Close()
 	target := &querypb.Target{}
-	hs.InitDBConfig(target, db.ConnParams())
+	hs.InitDBConfig(target, db.ConnParams(), nil)
 
 	ch, cancel := testStream(hs)
 	defer cancel()
@@ -152,6 +156,123 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	assert.Equal(t, want, shr)
 }
 
+func TestHealthStreamerMysqldStats(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	config := newConfig(db)
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+
+	dataDir := t.TempDir()
+	procDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "mysqld.pid"), []byte("1234\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(procDir, "1234"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "1234", "status"), []byte("Name:\tmysqld\nVmRSS:\t  2048 kB\n"), 0644))
+
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(db)
+	mysqld.Replicating = true
+	mysqld.SemiSyncReplicaEnabled = true
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		sqlMysqldPaths: sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("datadir|pid_file", "varchar|varchar"),
+			dataDir+"|mysqld.pid",
+		),
+	}
+
+	hs := newHealthStreamer(env, alias)
+	hs.InitDBConfig(&querypb.Target{}, db.ConnParams(), mysqld)
+	hs.mysqldHealth.procPath = procDir
+	hs.Open()
+	defer hs.Close()
+
+	ch, cancel := testStream(hs)
+	defer cancel()
+	<-ch
+
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, true)
+	stats := (<-ch).RealtimeStats
+	assert.Greater(t, stats.DiskFreePercent, 0.0)
+	assert.LessOrEqual(t, stats.DiskFreePercent, 100.0)
+	assert.Equal(t, uint64(2048*1024), stats.MysqldMemoryBytes)
+	assert.Equal(t, int32(mysql.ReplicationStateRunning), stats.ReplicationIoThreadState)
+	assert.Equal(t, int32(mysql.ReplicationStateRunning), stats.ReplicationSqlThreadState)
+	assert.True(t, stats.SemiSyncReplicaStatus)
+	assert.False(t, stats.SemiSyncPrimaryStatus)
+
+	// The replication threads are not reported on a primary.
+	mysqld.SemiSyncPrimaryEnabled = true
+	hs.ChangeState(topodatapb.TabletType_PRIMARY, time.Now(), 0, nil, true)
+	stats = (<-ch).RealtimeStats
+	assert.Zero(t, stats.ReplicationIoThreadState)
+	assert.Zero(t, stats.ReplicationSqlThreadState)
+	assert.True(t, stats.SemiSyncPrimaryStatus)
+	assert.Equal(t, uint64(2048*1024), stats.MysqldMemoryBytes)
+
+	// The disk and the memory of a mysqld on another host are not reported.
+	hs.mysqldHealth = newMysqldHealth(mysqld)
+	mysqld.FetchSuperQueryMap[sqlMysqldPaths] = sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("datadir|pid_file", "varchar|varchar"),
+		"/var/lib/mysql|/var/run/mysqld/other-host.pid",
+	)
+	hs.ChangeState(topodatapb.TabletType_PRIMARY, time.Now(), 0, nil, true)
+	stats = (<-ch).RealtimeStats
+	assert.Zero(t, stats.DiskFreePercent)
+	assert.Zero(t, stats.MysqldMemoryBytes)
+	assert.True(t, stats.SemiSyncPrimaryStatus)
+}
+
+func TestHealthStreamerDraining(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	config := newConfig(db)
+
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.InitDBConfig(&querypb.Target{TabletType: topodatapb.TabletType_REPLICA}, config.DB.DbaWithDB(), nil)
+	hs.Open()
+	defer hs.Close()
+
+	ch, cancel := testStream(hs)
+	defer cancel()
+	<-ch
+
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, true)
+	shr := <-ch
+	assert.False(t, shr.Draining)
+
+	// Draining is broadcast right away.
+	hs.SetDraining(true)
+	shr = <-ch
+	assert.True(t, shr.Draining)
+	assert.True(t, shr.Serving)
+	assert.True(t, hs.IsDraining())
+
+	// The tablet keeps draining while its type doesn't change.
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, true)
+	shr = <-ch
+	assert.True(t, shr.Draining)
+
+	hs.ChangeState(topodatapb.TabletType_DRAINED, time.Time{}, 0, nil, false)
+	shr = <-ch
+	assert.False(t, shr.Draining)
+	assert.False(t, hs.IsDraining())
+
+	hs.SetDraining(true)
+	<-ch
+	hs.SetDraining(false)
+	shr = <-ch
+	assert.False(t, shr.Draining)
+}
+
 func TestReloadSchema(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -186,7 +307,7 @@ func TestReloadSchema(t *testing.T) {
 		"users",
 	))
 
-	hs.InitDBConfig(target, configs.DbaWithDB())
+	hs.InitDBConfig(target, configs.DbaWithDB(), nil)
 	hs.Open()
 	defer hs.Close()
 	var wg sync.WaitGroup
@@ -231,7 +352,7 @@ func TestDoesNotReloadSchema(t *testing.T) {
 	target := &querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
 	configs := config.DB
 
-	hs.InitDBConfig(target, configs.DbaWithDB())
+	hs.InitDBConfig(target, configs.DbaWithDB(), nil)
 	hs.Open()
 	defer hs.Close()
 	var wg sync.WaitGroup
@@ -299,7 +420,7 @@ func TestInitialReloadSchema(t *testing.T) {
 		"users",
 	))
 
-	hs.InitDBConfig(target, configs.DbaWithDB())
+	hs.InitDBConfig(target, configs.DbaWithDB(), nil)
 	hs.Open()
 	defer hs.Close()
 	var wg sync.WaitGroup
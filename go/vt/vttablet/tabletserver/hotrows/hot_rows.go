@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hotrows provides the vttablet hot row detection.
+// See the Detector struct for details.
+package hotrows
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// numIntervals is the number of intervals a window is divided into. The
+// window slides by one interval at a time.
+const numIntervals = 6
+
+// DefaultReportLimit is the number of rows a report lists when no limit is
+// given.
+const DefaultReportLimit = 20
+
+// Row is a row of a table, identified by the values of its primary key, and
+// the number of times it was accessed during the window.
+type Row struct {
+	Table string `json:"table"`
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// Detector tracks the rows of each table that are accessed the most, over a
+// sliding window. A row is identified by the equality predicates on all the
+// columns of the primary key of its table, e.g. "id = 1 and sub_id = 2".
+//
+// The window is divided into intervals, and the counts of an interval are
+// dropped once it falls out of the window. To bound its memory, the detector
+// tracks at most a fixed number of rows per table and interval: when a new row
+// is accessed and there is no room left, the row with the lowest count is
+// replaced and the new row inherits its count (the Space-Saving algorithm).
+// The counts of the rows that are accessed the most are therefore accurate,
+// while the counts of the others may be overestimated.
+type Detector struct {
+	// Immutable fields.
+	enabled  bool
+	interval time.Duration
+	capacity int
+
+	evictions *stats.CountersWithSingleLabel
+
+	// now is replaced in tests.
+	now func() time.Time
+
+	mu        sync.Mutex
+	intervals [numIntervals]*interval
+}
+
+// interval holds the access counts of the rows of each table during an
+// interval of the window.
+type interval struct {
+	start  time.Time
+	tables map[string]map[string]int64
+}
+
+// New returns a Detector object.
+func New(env tabletenv.Env) *Detector {
+	config := env.Config()
+	interval := config.HotRowDetection.Window / numIntervals
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	return &Detector{
+		enabled:  config.HotRowDetection.Enable,
+		interval: interval,
+		capacity: config.HotRowDetection.Capacity,
+		evictions: env.Exporter().NewCountersWithSingleLabel(
+			"HotRowDetectionEvictions",
+			"Number of times a row was no longer tracked by the hot row detection because its table had too many rows accessed",
+			"table_name"),
+		now: time.Now,
+	}
+}
+
+// Enabled returns true if the hot row detection is enabled.
+func (d *Detector) Enabled() bool {
+	return d != nil && d.enabled
+}
+
+// Record counts an access to the row of table identified by key.
+func (d *Detector) Record(table, key string) {
+	if !d.Enabled() || table == "" || key == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	start := d.now().Truncate(d.interval)
+	i := int(start.UnixNano()/int64(d.interval)) % numIntervals
+	iv := d.intervals[i]
+	if iv == nil || !iv.start.Equal(start) {
+		iv = &interval{
+			start:  start,
+			tables: make(map[string]map[string]int64),
+		}
+		d.intervals[i] = iv
+	}
+
+	rows, ok := iv.tables[table]
+	if !ok {
+		rows = make(map[string]int64)
+		iv.tables[table] = rows
+	}
+	if _, ok := rows[key]; !ok && len(rows) >= d.capacity {
+		minKey, minCount := "", int64(-1)
+		for k, count := range rows {
+			if minCount == -1 || count < minCount {
+				minKey, minCount = k, count
+			}
+		}
+		delete(rows, minKey)
+		rows[key] = minCount
+		d.evictions.Add(table, 1)
+	}
+	rows[key]++
+}
+
+// Report returns the rows that were accessed the most during the window, the
+// most accessed first, at most limit of them. If table is not empty, only the
+// rows of table are returned. If limit is not positive, all the rows are
+// returned.
+func (d *Detector) Report(table string, limit int) []Row {
+	if !d.Enabled() {
+		return nil
+	}
+
+	type rowKey struct {
+		table, key string
+	}
+	counts := make(map[rowKey]int64)
+
+	d.mu.Lock()
+	windowStart := d.now().Truncate(d.interval).Add(-d.interval * (numIntervals - 1))
+	for _, iv := range d.intervals {
+		if iv == nil || iv.start.Before(windowStart) {
+			continue
+		}
+		for t, rows := range iv.tables {
+			if table != "" && t != table {
+				continue
+			}
+			for key, count := range rows {
+				counts[rowKey{t, key}] += count
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	report := make([]Row, 0, len(counts))
+	for k, count := range counts {
+		report = append(report, Row{Table: k.table, Key: k.key, Count: count})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		if report[i].Table != report[j].Table {
+			return report[i].Table < report[j].Table
+		}
+		return report[i].Key < report[j].Key
+	})
+	if limit > 0 && len(report) > limit {
+		report = report[:limit]
+	}
+	return report
+}
+
+// ServeHTTP lists the rows that were accessed the most during the window, as
+// JSON. The "table" and "limit" parameters restrict the report to a table and
+// to a number of rows.
+func (d *Detector) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if *streamlog.RedactDebugUIQueries {
+		response.Write([]byte(`
+	<!DOCTYPE html>
+	<html>
+	<body>
+	<h1>Redacted</h1>
+	<p>/debug/hotrows has been redacted for your protection</p>
+	</body>
+	</html>
+		`))
+		return
+	}
+
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+
+	limit := DefaultReportLimit
+	if v := request.FormValue("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			http.Error(response, "invalid limit: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+
+	b, err := json.MarshalIndent(d.Report(request.FormValue("table"), limit), "", " ")
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	response.Write(b)
+}
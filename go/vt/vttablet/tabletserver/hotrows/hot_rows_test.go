@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotrows
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestDetector(name string, capacity int) (*Detector, *time.Time) {
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowDetection.Enable = true
+	config.HotRowDetection.Window = 6 * time.Second
+	config.HotRowDetection.Capacity = capacity
+	d := New(tabletenv.NewEnv(config, name))
+	now := time.Unix(1000, 0)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDetector(t *testing.T) {
+	d, now := newTestDetector("HotRowsTest", 10)
+
+	for i := 0; i < 3; i++ {
+		d.Record("t1", "id = 1")
+	}
+	d.Record("t1", "id = 2")
+	d.Record("t2", "id = 1")
+	d.Record("t2", "id = 1")
+	d.Record("", "id = 1")
+	d.Record("t1", "")
+
+	assert.Equal(t, []Row{
+		{Table: "t1", Key: "id = 1", Count: 3},
+		{Table: "t2", Key: "id = 1", Count: 2},
+		{Table: "t1", Key: "id = 2", Count: 1},
+	}, d.Report("", 0))
+	assert.Equal(t, []Row{
+		{Table: "t1", Key: "id = 1", Count: 3},
+	}, d.Report("t1", 1))
+
+	// The counts of the intervals within the window are added up.
+	*now = now.Add(3 * time.Second)
+	d.Record("t1", "id = 2")
+	d.Record("t1", "id = 2")
+	d.Record("t1", "id = 2")
+	assert.Equal(t, []Row{
+		{Table: "t1", Key: "id = 2", Count: 4},
+		{Table: "t1", Key: "id = 1", Count: 3},
+	}, d.Report("t1", 0))
+
+	// The counts of the intervals that fell out of the window are dropped.
+	*now = now.Add(4 * time.Second)
+	assert.Equal(t, []Row{
+		{Table: "t1", Key: "id = 2", Count: 3},
+	}, d.Report("", 0))
+
+	*now = now.Add(time.Minute)
+	assert.Empty(t, d.Report("", 0))
+}
+
+func TestDetectorCapacity(t *testing.T) {
+	d, _ := newTestDetector("HotRowsCapacityTest", 2)
+
+	for i := 0; i < 5; i++ {
+		d.Record("t1", "id = 1")
+	}
+	d.Record("t1", "id = 2")
+	// id = 2 is replaced and id = 3 inherits its count.
+	d.Record("t1", "id = 3")
+
+	assert.Equal(t, []Row{
+		{Table: "t1", Key: "id = 1", Count: 5},
+		{Table: "t1", Key: "id = 3", Count: 2},
+	}, d.Report("t1", 0))
+	assert.Equal(t, int64(1), d.evictions.Counts()["t1"])
+}
+
+func TestDetectorDisabled(t *testing.T) {
+	d := New(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "HotRowsDisabledTest"))
+	assert.False(t, d.Enabled())
+	d.Record("t1", "id = 1")
+	assert.Nil(t, d.Report("", 0))
+
+	var nilDetector *Detector
+	assert.False(t, nilDetector.Enabled())
+	assert.Nil(t, nilDetector.Report("", 0))
+}
+
+func TestDetectorServeHTTP(t *testing.T) {
+	d, _ := newTestDetector("HotRowsHTTPTest", 10)
+	d.Record("t1", "id = 1")
+	d.Record("t1", "id = 1")
+	d.Record("t1", "id = 2")
+	d.Record("t2", "id = 1")
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/debug/hotrows?table=t1&limit=1", nil)
+	require.NoError(t, err)
+	d.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var rows []Row
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rows))
+	assert.Equal(t, []Row{{Table: "t1", Key: "id = 1", Count: 2}}, rows)
+
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/debug/hotrows?limit=x", nil)
+	require.NoError(t, err)
+	d.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	*streamlog.RedactDebugUIQueries = true
+	defer func() {
+		*streamlog.RedactDebugUIQueries = false
+	}()
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/debug/hotrows", nil)
+	require.NoError(t, err)
+	d.ServeHTTP(rr, req)
+	assert.Contains(t, rr.Body.String(), "redacted")
+}
@@ -93,6 +93,19 @@ type cache struct {
 	// They guard from such messages from being added back prematurely.
 	// The message id is the key.
 	inFlight map[string]bool
+
+	// acked are the most recently acked messages. They guard from
+	// such messages from being resent to reconnecting subscribers
+	// by a poller or a vstream that read them before the ack.
+	// Unlike the other sets, it survives a Clear, but it is only kept
+	// in memory: the acks themselves are persisted in the time_acked
+	// column, and the set only covers the time it takes the poller and
+	// the vstream to see them. A new primary starts with an empty set, so
+	// delivery remains at-least-once across restarts and reparents.
+	// ackedOrder keeps the ids in the order they were acked
+	// so that the oldest ones can be forgotten first.
+	acked      map[string]bool
+	ackedOrder []string
 }
 
 // NewMessagerCache creates a new cache.
@@ -101,6 +114,7 @@ func newCache(size int) *cache {
 		size:     size,
 		inQueue:  make(map[string]*MessageRow),
 		inFlight: make(map[string]bool),
+		acked:    make(map[string]bool),
 	}
 	return mc
 }
@@ -132,7 +146,7 @@ func (mc *cache) Add(mr *MessageRow) bool {
 		return false
 	}
 	id := mr.Row[0].ToString()
-	if mc.inFlight[id] {
+	if mc.inFlight[id] || mc.acked[id] {
 		return true
 	}
 	if _, ok := mc.inQueue[id]; ok {
@@ -186,6 +200,31 @@ func (mc *cache) Discard(ids []string) {
 	}
 }
 
+// Acked discards the specified ids and remembers them as acked,
+// so that they're not added back to the cache. At most size ids are
+// remembered. The ids are shared by all the subscribers of the table:
+// there is no tracking per subscriber group.
+func (mc *cache) Acked(ids []string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for _, id := range ids {
+		if mr := mc.inQueue[id]; mr != nil {
+			mr.defunct = true
+		}
+		delete(mc.inQueue, id)
+		delete(mc.inFlight, id)
+		if mc.acked[id] {
+			continue
+		}
+		mc.acked[id] = true
+		mc.ackedOrder = append(mc.ackedOrder, id)
+	}
+	for len(mc.ackedOrder) > mc.size {
+		delete(mc.acked, mc.ackedOrder[0])
+		mc.ackedOrder = mc.ackedOrder[1:]
+	}
+}
+
 // Size returns the max size of cache.
 func (mc *cache) Size() int {
 	mc.mu.Lock()
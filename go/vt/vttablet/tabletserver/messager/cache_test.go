@@ -214,3 +214,35 @@ func TestMessagerCacheEmpty(t *testing.T) {
 		t.Errorf("Pop(non-empty): nil, want %v", row)
 	}
 }
+
+func TestMessagerCacheAcked(t *testing.T) {
+	mc := newCache(2)
+	newRow := func(id string) *MessageRow {
+		return &MessageRow{Row: []sqltypes.Value{sqltypes.NewVarBinary(id)}}
+	}
+	if !mc.Add(newRow("row01")) {
+		t.Fatal("Add returned false")
+	}
+	mc.Acked([]string{"row01", "row02"})
+	if mr := mc.Pop(); mr != nil {
+		t.Errorf("Pop: %v, want nil", mr)
+	}
+
+	// Acked messages are not added back, even after a Clear.
+	mc.Clear()
+	if !mc.Add(newRow("row02")) {
+		t.Fatal("Add returned false")
+	}
+	if mr := mc.Pop(); mr != nil {
+		t.Errorf("Pop: %v, want nil", mr)
+	}
+
+	// The oldest acked messages are forgotten first.
+	mc.Acked([]string{"row03"})
+	if !mc.Add(newRow("row01")) {
+		t.Fatal("Add returned false")
+	}
+	if mr := mc.Pop(); mr == nil || mr.Row[0].ToString() != "row01" {
+		t.Errorf("Pop: %v, want row01", mr)
+	}
+}
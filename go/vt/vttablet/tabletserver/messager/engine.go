@@ -38,6 +38,7 @@ type TabletService interface {
 	tabletenv.Env
 	PostponeMessages(ctx context.Context, target *querypb.Target, querygen QueryGenerator, ids []string) (count int64, err error)
 	PurgeMessages(ctx context.Context, target *querypb.Target, querygen QueryGenerator, timeCutoff int64) (count int64, err error)
+	DeadLetterMessages(ctx context.Context, target *querypb.Target, querygen QueryGenerator, ids []string) (count int64, err error)
 }
 
 // VStreamer defines  the functions of VStreamer
@@ -116,6 +117,17 @@ func (me *Engine) GetGenerator(name string) (QueryGenerator, error) {
 	return mm, nil
 }
 
+// Acked informs the manager of the requested table that the messages
+// were acked, so that they're not resent to any of its subscribers.
+func (me *Engine) Acked(name string, ids []string) {
+	me.mu.Lock()
+	mm := me.managers[name]
+	me.mu.Unlock()
+	if mm != nil {
+		mm.Acked(ids)
+	}
+}
+
 // Subscribe subscribes to messages from the requested table.
 // The function returns a done channel that will be closed when
 // the subscription ends, which can be initiated by the send function
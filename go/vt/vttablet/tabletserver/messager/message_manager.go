@@ -51,6 +51,7 @@ type QueryGenerator interface {
 	GenerateAckQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePostponeQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePurgeQuery(timeCutoff int64) (string, map[string]*querypb.BindVariable)
+	GenerateDeadLetterQuery(ids []string) (string, map[string]*querypb.BindVariable)
 }
 
 type messageReceiver struct {
@@ -164,6 +165,12 @@ type receiverWithStatus struct {
 // The Purge thread
 // This thread is mostly independent. It wakes up periodically
 // to delete old rows that were successfully acked.
+//
+// Dead letters
+// If the table specifies a maximum number of delivery attempts,
+// messages that are popped from the cache after reaching that number
+// are not sent again. Instead, they're copied to the dead letter table,
+// if there is one, and acked.
 type messageManager struct {
 	tsv TabletService
 	vs  VStreamer
@@ -174,6 +181,7 @@ type messageManager struct {
 	purgeAfter   time.Duration
 	minBackoff   time.Duration
 	maxBackoff   time.Duration
+	maxAttempts  int64
 	batchSize    int
 	pollerTicks  *timer.Timer
 	purgeTicks   *timer.Timer
@@ -234,6 +242,7 @@ type messageManager struct {
 	ackQuery                  *sqlparser.ParsedQuery
 	postponeQuery             *sqlparser.ParsedQuery
 	purgeQuery                *sqlparser.ParsedQuery
+	deadLetterQuery           *sqlparser.ParsedQuery
 }
 
 // newMessageManager creates a new message manager.
@@ -251,6 +260,7 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		purgeAfter:      table.MessageInfo.PurgeAfterDuration,
 		minBackoff:      table.MessageInfo.MinBackoff,
 		maxBackoff:      table.MessageInfo.MaxBackoff,
+		maxAttempts:     int64(table.MessageInfo.MaxAttempts),
 		batchSize:       table.MessageInfo.BatchSize,
 		cache:           newCache(table.MessageInfo.CacheSize),
 		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
@@ -281,6 +291,12 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 
 	mm.postponeQuery = buildPostponeQuery(mm.name, mm.minBackoff, mm.maxBackoff)
 
+	if table.MessageInfo.DeadLetterTable != "" {
+		mm.deadLetterQuery = sqlparser.BuildParsedQuery(
+			"insert into %v select * from %v where id in %a and time_acked is null",
+			sqlparser.NewIdentifierCS(table.MessageInfo.DeadLetterTable), mm.name, "::ids")
+	}
+
 	return mm
 }
 
@@ -504,6 +520,14 @@ func (mm *messageManager) Add(mr *MessageRow) bool {
 	return true
 }
 
+// Acked removes acked messages from the cache, and prevents them from
+// being resent if they're read again before the ack is visible.
+func (mm *messageManager) Acked(ids []string) {
+	mm.cacheManagementMu.Lock()
+	defer mm.cacheManagementMu.Unlock()
+	mm.cache.Acked(ids)
+}
+
 func (mm *messageManager) runSend() {
 	defer func() {
 		mm.tsv.LogError()
@@ -522,6 +546,7 @@ func (mm *messageManager) runSend() {
 		mm.mu.Lock()
 
 		var rows [][]sqltypes.Value
+		var deadLetterIDs []string
 		for {
 			if !mm.isOpen {
 				return
@@ -552,6 +577,10 @@ func (mm *messageManager) runSend() {
 				if mr == nil {
 					break
 				}
+				if mm.maxAttempts > 0 && mr.Epoch >= mm.maxAttempts {
+					deadLetterIDs = append(deadLetterIDs, mr.Row[0].ToString())
+					continue
+				}
 				if mr.Epoch >= 1 {
 					lateCount++
 				}
@@ -559,6 +588,12 @@ func (mm *messageManager) runSend() {
 			}
 			MessageStats.Add([]string{mm.name.String(), "Delayed"}, lateCount)
 
+			if deadLetterIDs != nil {
+				mm.wg.Add(1)
+				go mm.deadLetter(deadLetterIDs) // calls the offsetting mm.wg.Done()
+				deadLetterIDs = nil
+			}
+
 			// If we have rows to send, break out of this loop.
 			if rows != nil {
 				break
@@ -634,6 +669,37 @@ func (mm *messageManager) postpone(tsv TabletService, ackWaitTime time.Duration,
 	}
 }
 
+// deadLetter moves the messages that exceeded the maximum number of
+// delivery attempts out of the delivery queue.
+func (mm *messageManager) deadLetter(ids []string) {
+	defer func() {
+		mm.tsv.LogError()
+		mm.wg.Done()
+	}()
+
+	defer func() {
+		// Hold cacheManagementMu for the same reason as in send.
+		mm.cacheManagementMu.Lock()
+		defer mm.cacheManagementMu.Unlock()
+		mm.cache.Discard(ids)
+	}()
+
+	if !mm.postponeSema.Acquire() {
+		// Unreachable.
+		return
+	}
+	defer mm.postponeSema.Release()
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.ackWaitTime)
+	defer cancel()
+	count, err := mm.tsv.DeadLetterMessages(ctx, nil, mm, ids)
+	if err != nil {
+		MessageStats.Add([]string{mm.name.String(), "DeadLetterFailed"}, 1)
+		log.Errorf("Unable to move messages to the dead letter table: %v", err)
+		return
+	}
+	MessageStats.Add([]string{mm.name.String(), "DeadLettered"}, count)
+}
+
 func (mm *messageManager) startVStream() {
 	if mm.streamCancel != nil {
 		return
@@ -803,6 +869,12 @@ func (mm *messageManager) runPoller() {
 		// Wake up the sender.
 		defer mm.cond.Broadcast()
 	}
+	now := time.Now().UnixNano()
+	oldest := now
+	defer func() {
+		// Lag is how long the oldest pending message has been due.
+		MessageStats.Set([]string{mm.name.String(), "LagSeconds"}, int64(time.Duration(now-oldest).Seconds()))
+	}()
 	for _, row := range qr.Rows {
 		mr, err := BuildMessageRow(row)
 		if err != nil {
@@ -810,6 +882,9 @@ func (mm *messageManager) runPoller() {
 			log.Errorf("Error reading message row: %v", err)
 			continue
 		}
+		if mr.TimeNext != 0 && mr.TimeNext < oldest {
+			oldest = mr.TimeNext
+		}
 		if !mm.cache.Add(mr) {
 			mm.messagesPending = true
 			return
@@ -893,6 +968,27 @@ func (mm *messageManager) GeneratePurgeQuery(timeCutoff int64) (string, map[stri
 	}
 }
 
+// GenerateDeadLetterQuery returns the query and bind vars for copying messages
+// to the dead letter table. It returns an empty query if the table has none.
+func (mm *messageManager) GenerateDeadLetterQuery(ids []string) (string, map[string]*querypb.BindVariable) {
+	if mm.deadLetterQuery == nil {
+		return "", nil
+	}
+	idbvs := &querypb.BindVariable{
+		Type:   querypb.Type_TUPLE,
+		Values: make([]*querypb.Value, 0, len(ids)),
+	}
+	for _, id := range ids {
+		idbvs.Values = append(idbvs.Values, &querypb.Value{
+			Type:  querypb.Type_VARBINARY,
+			Value: []byte(id),
+		})
+	}
+	return mm.deadLetterQuery.Query, map[string]*querypb.BindVariable{
+		"ids": idbvs,
+	}
+}
+
 // BuildMessageRow builds a MessageRow from a db row.
 func BuildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
 	mr := &MessageRow{Row: row[4:]}
@@ -95,6 +95,13 @@ func newMMTableWithBackoff() *schema.Table {
 	}
 }
 
+func newMMTableWithDeadLetter() *schema.Table {
+	table := newMMTable()
+	table.MessageInfo.MaxAttempts = 2
+	table.MessageInfo.DeadLetterTable = "foo_dlq"
+	return table
+}
+
 func newMMRow(id int64) *querypb.Row {
 	return sqltypes.RowToProto3([]sqltypes.Value{
 		sqltypes.NewInt64(1),
@@ -787,6 +794,46 @@ func TestMMGenerate(t *testing.T) {
 	}
 }
 
+func TestMMGenerateDeadLetter(t *testing.T) {
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTable(), sync2.NewSemaphore(1, 0))
+	query, bv := mm.GenerateDeadLetterQuery([]string{"1", "2"})
+	assert.Empty(t, query)
+	assert.Nil(t, bv)
+
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTableWithDeadLetter(), sync2.NewSemaphore(1, 0))
+	query, bv = mm.GenerateDeadLetterQuery([]string{"1", "2"})
+	assert.Equal(t, "insert into foo_dlq select * from foo where id in ::ids and time_acked is null", query)
+	wantbv := map[string]*querypb.BindVariable{
+		"ids": sqltypes.TestBindVariable([]any{[]byte{'1'}, []byte{'2'}}),
+	}
+	utils.MustMatch(t, wantbv, bv, "did not match")
+}
+
+func TestMessageManagerDeadLetter(t *testing.T) {
+	tsv := newFakeTabletServer()
+	mm := newMessageManager(tsv, newFakeVStreamer(), newMMTableWithDeadLetter(), sync2.NewSemaphore(1, 0))
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	ch := make(chan string, 20)
+	tsv.SetChannel(ch)
+
+	// A message that has not exhausted its attempts is sent.
+	mm.Add(&MessageRow{Epoch: 1, Row: []sqltypes.Value{sqltypes.NewVarBinary("1"), sqltypes.NULL}})
+	<-r1.ch
+	assert.Equal(t, "postpone", <-ch)
+
+	// A message that has exhausted its attempts is dead lettered instead.
+	mm.Add(&MessageRow{Epoch: 2, Row: []sqltypes.Value{sqltypes.NewVarBinary("2"), sqltypes.NULL}})
+	assert.Equal(t, "deadletter", <-ch)
+	assert.EqualValues(t, 1, tsv.deadLetterCount.Get())
+	assert.EqualValues(t, 1, r1.count.Get()-1)
+}
+
 func TestMMGenerateWithBackoff(t *testing.T) {
 	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTableWithBackoff(), sync2.NewSemaphore(1, 0))
 	mm.Open()
@@ -824,8 +871,9 @@ func TestMMGenerateWithBackoff(t *testing.T) {
 
 type fakeTabletServer struct {
 	tabletenv.Env
-	postponeCount sync2.AtomicInt64
-	purgeCount    sync2.AtomicInt64
+	postponeCount   sync2.AtomicInt64
+	purgeCount      sync2.AtomicInt64
+	deadLetterCount sync2.AtomicInt64
 
 	mu sync.Mutex
 	ch chan string
@@ -868,6 +916,17 @@ func (fts *fakeTabletServer) PurgeMessages(ctx context.Context, target *querypb.
 	return 0, nil
 }
 
+func (fts *fakeTabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, gen QueryGenerator, ids []string) (count int64, err error) {
+	fts.deadLetterCount.Add(1)
+	fts.mu.Lock()
+	ch := fts.ch
+	fts.mu.Unlock()
+	if ch != nil {
+		ch <- "deadletter"
+	}
+	return int64(len(ids)), nil
+}
+
 type fakeVStreamer struct {
 	streamInvocations sync2.AtomicInt64
 	mu                sync.Mutex
@@ -921,3 +980,48 @@ func (fv *fakeVStreamer) StreamResults(ctx context.Context, query string, send f
 	}
 	return nil
 }
+
+// TestMessageManagerAckedAtLeastOnce tests that acked messages are not
+// resent by the same manager, but that a new manager, as after a restart
+// or a reparent, resends the messages whose ack is not persisted yet.
+func TestMessageManagerAckedAtLeastOnce(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.BatchSize = 2
+	ti.MessageInfo.PollInterval = 20 * time.Second
+	fvs := newFakeVStreamer()
+	// The poller still reads the first message, as its ack is not visible yet.
+	fvs.setPollerResponse([]*binlogdatapb.VStreamResultsResponse{{
+		Fields: testDBFields,
+		Gtid:   "MySQL56/33333333-3333-3333-3333-333333333333:1-100",
+	}, {
+		Rows: []*querypb.Row{
+			newMMRow(1),
+			newMMRow(2),
+		},
+	}})
+
+	receive := func(mm *messageManager) []string {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r1 := newTestReceiver(1)
+		mm.Subscribe(ctx, r1.rcv)
+		<-r1.ch
+		qr := <-r1.ch
+		var ids []string
+		for _, row := range qr.Rows {
+			ids = append(ids, row[0].ToString())
+		}
+		return ids
+	}
+
+	mm := newMessageManager(newFakeTabletServer(), fvs, ti, sync2.NewSemaphore(1, 0))
+	mm.Acked([]string{"1"})
+	mm.Open()
+	assert.Equal(t, []string{"2"}, receive(mm))
+	mm.Close()
+
+	mm = newMessageManager(newFakeTabletServer(), fvs, ti, sync2.NewSemaphore(1, 0))
+	mm.Open()
+	defer mm.Close()
+	assert.ElementsMatch(t, []string{"1", "2"}, receive(mm))
+}
@@ -43,6 +43,7 @@ func analyzeSelect(sel *sqlparser.Select, tables map[string]*schema.Table) (plan
 			plan.PlanID = PlanSelectImpossible
 			return plan, nil
 		}
+		plan.PKPredicate = analyzePKPredicate(sel.Where, plan.Table)
 	}
 
 	// Check if it's a NEXT VALUE statement.
@@ -74,6 +75,19 @@ func analyzeUpdate(upd *sqlparser.Update, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", upd.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.PKPredicate = analyzePKPredicate(upd.Where, plan.Table)
+	}
+
+	// The RETURNING clause is emulated, and not sent to MySQL.
+	if upd.Returning != nil {
+		plan.Returning, err = analyzeUpdateReturning(upd, plan.Table)
+		if err != nil {
+			return nil, err
+		}
+		defer func(returning sqlparser.SelectExprs) {
+			upd.Returning = returning
+		}(upd.Returning)
+		upd.Returning = nil
 	}
 
 	// Situations when we pass-through:
@@ -103,6 +117,18 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", del.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.PKPredicate = analyzePKPredicate(del.Where, plan.Table)
+	}
+
+	if del.Returning != nil {
+		plan.Returning, err = analyzeDeleteReturning(del, plan.Table)
+		if err != nil {
+			return nil, err
+		}
+		defer func(returning sqlparser.SelectExprs) {
+			del.Returning = returning
+		}(del.Returning)
+		del.Returning = nil
 	}
 
 	if PassthroughDMLs || plan.Table == nil || del.Limit != nil {
@@ -118,12 +144,23 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 
 func analyzeInsert(ins *sqlparser.Insert, tables map[string]*schema.Table) (plan *Plan, err error) {
 	plan = &Plan{
-		PlanID:    PlanInsert,
-		FullQuery: GenerateFullQuery(ins),
+		PlanID: PlanInsert,
 	}
 
 	tableName := sqlparser.GetTableName(ins.Table)
 	plan.Table = tables[tableName.String()]
+
+	if ins.Returning != nil {
+		plan.Returning, err = analyzeInsertReturning(ins, plan.Table)
+		if err != nil {
+			return nil, err
+		}
+		defer func(returning sqlparser.SelectExprs) {
+			ins.Returning = returning
+		}(ins.Returning)
+		ins.Returning = nil
+	}
+	plan.FullQuery = GenerateFullQuery(ins)
 	return plan, nil
 }
 
@@ -199,3 +236,49 @@ func lookupSingleTable(tableExpr sqlparser.TableExpr, tables map[string]*schema.
 	}
 	return tables[tableName.String()]
 }
+
+// analyzePKPredicate returns the equality predicates of the where clause on
+// the columns of the primary key of table, in the order of the primary key,
+// e.g. "eid = :v1 and id = 2". It returns nil unless the where clause matches
+// a single row, i.e. it has an equality predicate on each column of the primary
+// key against a value. It is used by the hot row detection to identify the
+// rows that are accessed.
+func analyzePKPredicate(where *sqlparser.Where, table *schema.Table) *sqlparser.ParsedQuery {
+	if table == nil || !table.HasPrimary() {
+		return nil
+	}
+
+	values := make(map[string]sqlparser.Expr)
+	for _, expr := range sqlparser.SplitAndExpression(nil, where.Expr) {
+		comp, ok := expr.(*sqlparser.ComparisonExpr)
+		if !ok || comp.Operator != sqlparser.EqualOp {
+			continue
+		}
+		col, val := comp.Left, comp.Right
+		if _, ok := col.(*sqlparser.ColName); !ok {
+			col, val = val, col
+		}
+		colName, ok := col.(*sqlparser.ColName)
+		if !ok {
+			continue
+		}
+		switch val.(type) {
+		case *sqlparser.Literal, sqlparser.Argument:
+			values[colName.Name.Lowered()] = val
+		}
+	}
+
+	buf := sqlparser.NewTrackedBuffer(nil)
+	for i := range table.PKColumns {
+		pkColumn := sqlparser.NewIdentifierCI(table.GetPKColumn(i).Name)
+		val, ok := values[pkColumn.Lowered()]
+		if !ok {
+			return nil
+		}
+		if i > 0 {
+			buf.WriteString(" and ")
+		}
+		buf.Myprintf("%v = %v", pkColumn, val)
+	}
+	return buf.ParsedQuery()
+}
@@ -57,7 +57,21 @@ func BuildPermissions(stmt sqlparser.Statement) []Permission {
 		for _, t := range node.TableNames {
 			permissions = buildTableNamePermissions(t, tableacl.ADMIN, permissions)
 		}
-	case *sqlparser.OtherAdmin, *sqlparser.CallProc, *sqlparser.Begin, *sqlparser.Commit, *sqlparser.Rollback,
+	case *sqlparser.LockTables:
+		for _, t := range node.Tables {
+			// A write lock keeps every other session from reading the
+			// table, so it needs the same permission as a write.
+			role := tableacl.READER
+			if t.Lock == sqlparser.Write || t.Lock == sqlparser.LowPriorityWrite {
+				role = tableacl.WRITER
+			}
+			if aliased, ok := t.Table.(*sqlparser.AliasedTableExpr); ok {
+				if name, ok := aliased.Expr.(sqlparser.TableName); ok {
+					permissions = buildTableNamePermissions(name, role, permissions)
+				}
+			}
+		}
+	case *sqlparser.OtherAdmin, *sqlparser.UnlockTables, *sqlparser.CallProc, *sqlparser.Begin, *sqlparser.Commit, *sqlparser.Rollback,
 		*sqlparser.Load, *sqlparser.Savepoint, *sqlparser.Release, *sqlparser.SRollback, *sqlparser.Set, *sqlparser.Show,
 		*sqlparser.OtherRead, sqlparser.Explain:
 		// no op
@@ -175,6 +175,21 @@ func TestBuildPermissions(t *testing.T) {
 			TableName: "t2",
 			Role:      tableacl.WRITER,
 		}},
+	}, {
+		input: "lock tables t1 read, t2 as x write, t3 low_priority write",
+		output: []Permission{{
+			TableName: "t1",
+			Role:      tableacl.READER,
+		}, {
+			TableName: "t2",
+			Role:      tableacl.WRITER,
+		}, {
+			TableName: "t3",
+			Role:      tableacl.WRITER,
+		}},
+	}, {
+		input:  "unlock tables",
+		output: nil,
 	}}
 
 	for _, tcase := range tcases {
@@ -175,8 +175,16 @@ type Plan struct {
 	// to serialize e.g. UPDATEs going to the same row.
 	WhereClause *sqlparser.ParsedQuery
 
+	// PKPredicate is set for the single table SELECTs, UPDATEs and DELETEs
+	// that access a single row by its primary key. It is used by the hot row
+	// detection to identify the row.
+	PKPredicate *sqlparser.ParsedQuery
+
 	// FullStmt can be used when the query does not operate on tables
 	FullStmt sqlparser.Statement
+
+	// Returning is set for the DMLs with a RETURNING clause.
+	Returning *ReturningPlan
 }
 
 // TableName returns the table name for the plan.
@@ -265,6 +273,13 @@ func Build(statement sqlparser.Statement, tables map[string]*schema.Table, isRes
 		plan, err = &Plan{PlanID: PlanLoad}, nil
 	case *sqlparser.Flush:
 		plan, err = &Plan{PlanID: PlanFlush, FullQuery: GenerateFullQuery(stmt)}, nil
+	case *sqlparser.LockTables:
+		if !isReservedConn {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s not allowed without a reserved connections", sqlparser.String(stmt))
+		}
+		plan, err = &Plan{PlanID: PlanLockTables, FullQuery: GenerateFullQuery(stmt)}, nil
+	case *sqlparser.UnlockTables:
+		plan, err = &Plan{PlanID: PlanUnlockTables, FullQuery: GenerateFullQuery(stmt)}, nil
 	case *sqlparser.CallProc:
 		plan, err = &Plan{PlanID: PlanCallProc, FullQuery: GenerateFullQuery(stmt)}, nil
 	default:
@@ -48,6 +48,9 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		FullQuery   *sqlparser.ParsedQuery `json:",omitempty"`
 		NextCount   string                 `json:",omitempty"`
 		WhereClause *sqlparser.ParsedQuery `json:",omitempty"`
+
+		ReturningLockQuery *sqlparser.ParsedQuery `json:",omitempty"`
+		ReturningQuery     *sqlparser.ParsedQuery `json:",omitempty"`
 	}{
 		PlanID:      p.PlanID,
 		TableName:   p.TableName(),
@@ -59,6 +62,13 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 	if p.NextCount != nil {
 		mplan.NextCount = evalengine.FormatExpr(p.NextCount)
 	}
+	if p.Returning != nil {
+		mplan.ReturningLockQuery = p.Returning.LockQuery
+		mplan.ReturningQuery = p.Returning.Query
+		if p.Returning.Select != nil {
+			mplan.ReturningQuery = GenerateFullQuery(p.Returning.Select)
+		}
+	}
 	return json.Marshal(&mplan)
 }
 
@@ -266,6 +276,50 @@ func TestMessageStreamingPlan(t *testing.T) {
 	}
 }
 
+func TestPKPredicate(t *testing.T) {
+	testSchema := loadSchema("schema_test.json")
+	tests := []struct {
+		query string
+		want  string
+	}{{
+		query: "select * from a where id = 2 and eid = 1",
+		want:  "eid = 1 and id = 2",
+	}, {
+		query: "select * from a where eid = :a and 2 = id and name = 'x'",
+		want:  "eid = :a and id = 2",
+	}, {
+		query: "update a set name = 'x' where eid = 1 and id = :b",
+		want:  "eid = 1 and id = :b",
+	}, {
+		query: "delete from a where a.eid = 1 and a.id = 2 limit 1",
+		want:  "eid = 1 and id = 2",
+	}, {
+		query: "select * from a where eid = 1",
+	}, {
+		query: "select * from a where eid = 1 or id = 2",
+	}, {
+		query: "select * from a where eid = 1 and id > 2",
+	}, {
+		query: "select * from a where eid = 1 and id = eid",
+	}, {
+		query: "select * from a",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			statement, err := sqlparser.Parse(tt.query)
+			require.NoError(t, err)
+			plan, err := Build(statement, testSchema, false, "dbName")
+			require.NoError(t, err)
+			if tt.want == "" {
+				require.Nil(t, plan.PKPredicate)
+				return
+			}
+			require.NotNil(t, plan.PKPredicate)
+			require.Equal(t, tt.want, plan.PKPredicate.Query)
+		})
+	}
+}
+
 func TestLockPlan(t *testing.T) {
 	testSchema := loadSchema("schema_test.json")
 	for tcase := range iterateExecFile("lock_cases.txt") {
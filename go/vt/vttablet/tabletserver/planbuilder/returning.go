@@ -0,0 +1,293 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ReturningInsertID is the bind variable of the auto-increment id generated
+// by an INSERT.
+const ReturningInsertID = "#returning_insert_id"
+
+// ReturningPlan describes how the RETURNING clause of a DML is emulated.
+// MySQL does not support RETURNING: the returned rows are selected by
+// queries issued in the transaction of the DML.
+type ReturningPlan struct {
+	// LockQuery is executed before the DML, and locks the rows it changes.
+	// For a DELETE, it selects the returned rows. For an UPDATE, it selects
+	// the primary keys of the updated rows.
+	LockQuery *sqlparser.ParsedQuery
+
+	// Query is executed after an INSERT, and selects the returned rows by
+	// their primary keys, or by the generated id that is bound to
+	// ReturningInsertID.
+	Query *sqlparser.ParsedQuery
+
+	// Select selects the returned rows after an UPDATE. Its WHERE clause is
+	// built by ByPrimaryKeys from the rows of LockQuery.
+	Select *sqlparser.Select
+
+	// PKColumns are the primary key columns of the table.
+	PKColumns []sqlparser.IdentifierCI
+
+	// GeneratedID is true for an INSERT of a single row that does not give
+	// its primary key, which is generated by auto-increment.
+	GeneratedID bool
+
+	// KeyArguments are the bind variables that give the primary key values
+	// of an INSERT, mapped to whether their column is auto-increment. Their
+	// values must be neither NULL nor, for an auto-increment column, 0: the
+	// inserted rows would not be found by these values.
+	KeyArguments map[string]bool
+}
+
+// ByPrimaryKeys returns the query that selects the returned rows of an
+// UPDATE, given the primary keys selected by LockQuery.
+func (rp *ReturningPlan) ByPrimaryKeys(rows []sqltypes.Row) (*sqlparser.ParsedQuery, map[string]*querypb.BindVariable) {
+	sel := sqlparser.CloneRefOfSelect(rp.Select)
+	bindVars := make(map[string]*querypb.BindVariable)
+	if len(rows) == 0 {
+		sel.Where = sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.BoolVal(false))
+		return GenerateFullQuery(sel), bindVars
+	}
+
+	var left sqlparser.Expr
+	var tuples sqlparser.ValTuple
+	if len(rp.PKColumns) == 1 {
+		left = sqlparser.NewColName(rp.PKColumns[0].String())
+	} else {
+		var cols sqlparser.ValTuple
+		for _, col := range rp.PKColumns {
+			cols = append(cols, sqlparser.NewColName(col.String()))
+		}
+		left = cols
+	}
+	for i, row := range rows {
+		var tuple sqlparser.ValTuple
+		for j, val := range row {
+			name := fmt.Sprintf("#returning_pk%d_%d", i, j)
+			bindVars[name] = sqltypes.ValueBindVariable(val)
+			tuple = append(tuple, sqlparser.NewArgument(name))
+		}
+		if len(tuple) == 1 {
+			tuples = append(tuples, tuple[0])
+		} else {
+			tuples = append(tuples, tuple)
+		}
+	}
+	sel.Where = sqlparser.NewWhere(sqlparser.WhereClause, &sqlparser.ComparisonExpr{
+		Operator: sqlparser.InOp,
+		Left:     left,
+		Right:    tuples,
+	})
+	return GenerateFullQuery(sel), bindVars
+}
+
+func returningSelect(exprs sqlparser.SelectExprs, from sqlparser.TableExprs, where *sqlparser.Where) *sqlparser.Select {
+	return &sqlparser.Select{
+		SelectExprs: exprs,
+		From:        from,
+		Where:       where,
+	}
+}
+
+func pkColumns(table *schema.Table) ([]sqlparser.IdentifierCI, error) {
+	if !table.HasPrimary() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on table %s: it has no primary key", table.Name.String())
+	}
+	var cols []sqlparser.IdentifierCI
+	for i := range table.PKColumns {
+		cols = append(cols, sqlparser.NewIdentifierCI(table.GetPKColumn(i).Name))
+	}
+	return cols, nil
+}
+
+func analyzeUpdateReturning(upd *sqlparser.Update, table *schema.Table) (*ReturningPlan, error) {
+	if table == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on multi-table UPDATE")
+	}
+	cols, err := pkColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	for _, expr := range upd.Exprs {
+		for _, col := range cols {
+			if expr.Name.Name.Equal(col) {
+				return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on UPDATE of primary key column %s", col.String())
+			}
+		}
+	}
+
+	var pkExprs sqlparser.SelectExprs
+	for _, col := range cols {
+		pkExprs = append(pkExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(col.String())})
+	}
+	lock := returningSelect(pkExprs, upd.TableExprs, upd.Where)
+	lock.OrderBy = upd.OrderBy
+	lock.Limit = upd.Limit
+	lock.Lock = sqlparser.ForUpdateLock
+
+	return &ReturningPlan{
+		LockQuery: GenerateFullQuery(lock),
+		Select:    returningSelect(upd.Returning, upd.TableExprs, nil),
+		PKColumns: cols,
+	}, nil
+}
+
+func analyzeDeleteReturning(del *sqlparser.Delete, table *schema.Table) (*ReturningPlan, error) {
+	if table == nil || len(del.Targets) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on multi-table DELETE")
+	}
+	from := del.TableExprs
+	if del.Partitions != nil {
+		aliased := sqlparser.CloneRefOfAliasedTableExpr(from[0].(*sqlparser.AliasedTableExpr))
+		aliased.Partitions = del.Partitions
+		from = sqlparser.TableExprs{aliased}
+	}
+	lock := returningSelect(del.Returning, from, del.Where)
+	lock.OrderBy = del.OrderBy
+	lock.Limit = del.Limit
+	lock.Lock = sqlparser.ForUpdateLock
+	return &ReturningPlan{LockQuery: GenerateFullQuery(lock)}, nil
+}
+
+// analyzeInsertReturning selects the inserted rows by the values of their
+// primary key columns, or by the auto-increment id generated by an INSERT of
+// a single row that does not give its single primary key column. The values
+// of the primary key columns must be literals or bind variables, so that
+// they select the rows that were inserted.
+func analyzeInsertReturning(ins *sqlparser.Insert, table *schema.Table) (*ReturningPlan, error) {
+	if table == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s not found in schema", sqlparser.String(ins.Table))
+	}
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on INSERT ... SELECT")
+	}
+	cols, err := pkColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	insertCols := ins.Columns
+	if len(insertCols) == 0 {
+		for _, field := range table.Fields {
+			insertCols = append(insertCols, sqlparser.NewIdentifierCI(field.Name))
+		}
+	}
+
+	from := sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: ins.Table}}
+	var offsets []int
+	for _, col := range cols {
+		offset := insertCols.FindColumn(col)
+		if offset < 0 {
+			break
+		}
+		offsets = append(offsets, offset)
+	}
+	switch {
+	case len(offsets) == len(cols):
+		var left sqlparser.Expr
+		if len(cols) == 1 {
+			left = sqlparser.NewColName(cols[0].String())
+		} else {
+			var tuple sqlparser.ValTuple
+			for _, col := range cols {
+				tuple = append(tuple, sqlparser.NewColName(col.String()))
+			}
+			left = tuple
+		}
+		var tuples sqlparser.ValTuple
+		keyArgs := make(map[string]bool)
+		for _, row := range rows {
+			var tuple sqlparser.ValTuple
+			for i, offset := range offsets {
+				if offset >= len(row) {
+					return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "column count doesn't match value count")
+				}
+				autoIncrement := isAutoIncrement(table, cols[i])
+				switch val := row[offset].(type) {
+				case *sqlparser.Literal:
+					if autoIncrement && isZeroLiteral(val) {
+						return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on INSERT of 0 into auto-increment column %s", cols[i].String())
+					}
+				case sqlparser.Argument:
+					keyArgs[string(val)] = autoIncrement
+				default:
+					return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is only supported on INSERT whose primary key values are literals or bind variables: %s", sqlparser.String(val))
+				}
+				tuple = append(tuple, row[offset])
+			}
+			if len(tuple) == 1 {
+				tuples = append(tuples, tuple[0])
+			} else {
+				tuples = append(tuples, tuple)
+			}
+		}
+		where := sqlparser.NewWhere(sqlparser.WhereClause, &sqlparser.ComparisonExpr{
+			Operator: sqlparser.InOp,
+			Left:     left,
+			Right:    tuples,
+		})
+		return &ReturningPlan{
+			Query:        GenerateFullQuery(returningSelect(ins.Returning, from, where)),
+			KeyArguments: keyArgs,
+		}, nil
+	case len(cols) == 1 && len(offsets) == 0 && ins.OnDup == nil:
+		if len(rows) != 1 {
+			// The ids generated for the rows of an INSERT are not
+			// necessarily consecutive, and only the first one is known.
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on INSERT of multiple rows generating an auto-increment primary key")
+		}
+		where := sqlparser.NewWhere(sqlparser.WhereClause, &sqlparser.ComparisonExpr{
+			Operator: sqlparser.EqualOp,
+			Left:     sqlparser.NewColName(cols[0].String()),
+			Right:    sqlparser.NewArgument(ReturningInsertID),
+		})
+		return &ReturningPlan{
+			Query:       GenerateFullQuery(returningSelect(ins.Returning, from, where)),
+			GeneratedID: true,
+		}, nil
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is only supported on INSERT giving all the primary key columns, or generating an auto-increment primary key")
+}
+
+// isAutoIncrement returns true if the column of the table is auto-increment.
+func isAutoIncrement(table *schema.Table, col sqlparser.IdentifierCI) bool {
+	for _, field := range table.Fields {
+		if col.EqualString(field.Name) {
+			return field.Flags&uint32(querypb.MySqlFlag_AUTO_INCREMENT_FLAG) != 0
+		}
+	}
+	return false
+}
+
+// isZeroLiteral returns true if the literal is converted to 0 by MySQL, which
+// then generates the value of an auto-increment column.
+func isZeroLiteral(lit *sqlparser.Literal) bool {
+	val, err := strconv.ParseFloat(lit.Val, 64)
+	return err == nil && val == 0
+}
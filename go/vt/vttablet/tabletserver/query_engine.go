@@ -43,6 +43,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl"
 	tacl "vitess.io/vitess/go/vt/tableacl/acl"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
@@ -133,6 +134,9 @@ type QueryEngine struct {
 	// that we start more than one transaction per hot row (range).
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
+	// hotRows tracks the rows that are accessed the most, to diagnose the
+	// skew of the shards and the contention on their rows.
+	hotRows *hotrows.Detector
 
 	// Vars
 	maxResultSize    sync2.AtomicInt64
@@ -186,6 +190,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		qe.streamConsolidator = NewStreamConsolidator(config.ConsolidatorStreamTotalSize, config.ConsolidatorStreamQuerySize, returnStreamResult)
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.hotRows = hotrows.New(env)
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -231,7 +236,13 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	qe.queryRowsReturned = env.Exporter().NewCountersWithMultiLabels("QueryRowsReturned", "query rows returned", []string{"Table", "Plan"})
 	qe.queryErrorCounts = env.Exporter().NewCountersWithMultiLabels("QueryErrorCounts", "query error counts", []string{"Table", "Plan"})
 
-	env.Exporter().HandleFunc("/debug/hotrows", qe.txSerializer.ServeHTTP)
+	// When the hot row detection is enabled, /debug/hotrows reports the rows
+	// that are accessed the most instead of the hot rows that are serialized.
+	if qe.hotRows.Enabled() {
+		env.Exporter().HandleFunc("/debug/hotrows", qe.hotRows.ServeHTTP)
+	} else {
+		env.Exporter().HandleFunc("/debug/hotrows", qe.txSerializer.ServeHTTP)
+	}
 	env.Exporter().HandleFunc("/debug/tablet_plans", qe.handleHTTPQueryPlans)
 	env.Exporter().HandleFunc("/debug/query_stats", qe.handleHTTPQueryStats)
 	env.Exporter().HandleFunc("/debug/query_rules", qe.handleHTTPQueryRules)
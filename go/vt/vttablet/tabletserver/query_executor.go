@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -129,6 +130,10 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 	if err := qre.checkPermissions(); err != nil {
 		return nil, err
 	}
+	if err := qre.checkWritesFrozen(); err != nil {
+		return nil, err
+	}
+	qre.recordHotRow()
 
 	switch qre.plan.PlanID {
 	case p.PlanNextval:
@@ -172,7 +177,16 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return qre.execOther()
 	case p.PlanSavepoint, p.PlanRelease, p.PlanSRollback:
 		return qre.execOther()
+	case p.PlanUnlockTables:
+		// Table locks are only ever taken on reserved connections,
+		// so there is nothing to release on a pooled one.
+		return &sqltypes.Result{}, nil
 	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanInsertMessage, p.PlanDDL, p.PlanLoad:
+		if qre.plan.Returning != nil {
+			// The rows are returned by the queries that follow the DML,
+			// which need to see its changes in the same transaction.
+			return qre.execAsTransaction(qre.txConnExec)
+		}
 		return qre.execAutocommit(qre.txConnExec)
 	case p.PlanUpdateLimit, p.PlanDeleteLimit:
 		return qre.execAsTransaction(qre.txConnExec)
@@ -235,6 +249,9 @@ func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*s
 }
 
 func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result, error) {
+	if qre.plan.Returning != nil {
+		return qre.execDMLReturning(conn)
+	}
 	switch qre.plan.PlanID {
 	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanSet:
 		return qre.txFetch(conn, true)
@@ -245,6 +262,8 @@ func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result
 		return qre.execDMLLimit(conn)
 	case p.PlanOtherRead, p.PlanOtherAdmin, p.PlanFlush:
 		return qre.execStatefulConn(conn, qre.query, true)
+	case p.PlanLockTables, p.PlanUnlockTables:
+		return qre.execStatefulConn(conn, qre.query, true)
 	case p.PlanSavepoint, p.PlanRelease, p.PlanSRollback:
 		return qre.execStatefulConn(conn, qre.query, true)
 	case p.PlanSelect, p.PlanSelectImpossible, p.PlanShow:
@@ -376,6 +395,47 @@ func (qre *QueryExecutor) MessageStream(callback StreamCallback) error {
 	return nil
 }
 
+// checkWritesFrozen returns an error if the query writes while the writes of
+// the tablet are frozen.
+// recordHotRow records the access to the row of the query for the hot row
+// detection, if the query accesses a single row by its primary key.
+func (qre *QueryExecutor) recordHotRow() {
+	hotRows := qre.tsv.qe.hotRows
+	if !hotRows.Enabled() || qre.plan.PKPredicate == nil {
+		return
+	}
+	key, err := qre.plan.PKPredicate.GenerateQuery(qre.bindVars, nil)
+	if err != nil {
+		return
+	}
+	hotRows.Record(qre.plan.TableName().String(), key)
+}
+
+func (qre *QueryExecutor) checkWritesFrozen() error {
+	// The queries of vreplication and online DDL are not rejected.
+	if tabletenv.IsLocalContext(qre.ctx) {
+		return nil
+	}
+
+	switch qre.plan.PlanID {
+	case p.PlanInsert, p.PlanInsertMessage, p.PlanUpdate, p.PlanUpdateLimit, p.PlanDelete, p.PlanDeleteLimit,
+		p.PlanDDL, p.PlanLoad, p.PlanNextval, p.PlanCallProc:
+	default:
+		return nil
+	}
+	frozen, until := qre.tsv.WritesFrozen()
+	if !frozen {
+		return nil
+	}
+	expiry := "it is made writable again"
+	if !until.IsZero() {
+		expiry = until.UTC().Format(time.RFC3339)
+	}
+	// The errno and sqlstate let vtgate return the error as the MySQL error of
+	// a read-only server.
+	return vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.InnodbReadOnly, "the shard is read-only, writes are rejected until %s (errno %d) (sqlstate %s)", expiry, mysql.ERInnodbReadOnly, mysql.SSUnknownSQLState)
+}
+
 // checkPermissions returns an error if the query does not pass all checks
 // (denied query, table ACL).
 func (qre *QueryExecutor) checkPermissions() error {
@@ -563,7 +623,11 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 	defer t.SequenceInfo.Unlock()
 	if t.SequenceInfo.NextVal == 0 || t.SequenceInfo.NextVal+inc > t.SequenceInfo.LastVal {
 		_, err := qre.execAsTransaction(func(conn *StatefulConnection) (*sqltypes.Result, error) {
-			query := fmt.Sprintf("select next_id, cache from %s where id = 0 for update", sqlparser.String(tableName))
+			columns := "next_id, cache"
+			if t.SequenceInfo.Interleaved {
+				columns = "next_id, cache, stride"
+			}
+			query := fmt.Sprintf("select %s from %s where id = 0 for update", columns, sqlparser.String(tableName))
 			qr, err := qre.execStatefulConn(conn, query, false)
 			if err != nil {
 				return nil, err
@@ -575,6 +639,15 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 			if err != nil {
 				return nil, vterrors.Wrapf(err, "error loading sequence %s", tableName)
 			}
+			if t.SequenceInfo.Interleaved {
+				lastVal, err := qre.allocInterleavedBlock(conn, tableName, nextID, qr.Rows[0], inc)
+				if err != nil {
+					return nil, err
+				}
+				t.SequenceInfo.NextVal = nextID
+				t.SequenceInfo.LastVal = lastVal
+				return nil, nil
+			}
 			// If LastVal does not match next ID, then either:
 			// VTTablet just started, and we're initializing the cache, or
 			// Someone reset the id underneath us.
@@ -593,8 +666,14 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 			if cache < 1 {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid cache value for sequence %s: %d", tableName, cache)
 			}
+			if nextID > math.MaxInt64-cache || t.SequenceInfo.NextVal > math.MaxInt64-inc {
+				return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "sequence %s is exhausted: next value %d with cache %d overflows int64", tableName, nextID, cache)
+			}
 			newLast := nextID + cache
 			for newLast < t.SequenceInfo.NextVal+inc {
+				if newLast > math.MaxInt64-cache {
+					return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "sequence %s is exhausted: cannot allocate %d values after %d", tableName, inc, nextID)
+				}
 				newLast += cache
 			}
 			query = fmt.Sprintf("update %s set next_id = %d where id = 0", sqlparser.String(tableName), newLast)
@@ -604,6 +683,7 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 				return nil, err
 			}
 			t.SequenceInfo.LastVal = newLast
+			qre.recordSequenceUsage(tableName.String(), newLast)
 			return nil, nil
 		})
 		if err != nil {
@@ -620,6 +700,80 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 	}, nil
 }
 
+// allocInterleavedBlock allocates the next block of a sequence whose blocks
+// are interleaved with the ones of the other shards of its keyspace. The
+// values left in the current block are skipped: the new block is the cache
+// values from next_id, and next_id moves past the blocks of the other shards.
+// It returns the end of the new block.
+func (qre *QueryExecutor) allocInterleavedBlock(conn *StatefulConnection, tableName sqlparser.IdentifierCS, nextID int64, row []sqltypes.Value, inc int64) (int64, error) {
+	cache, err := evalengine.ToInt64(row[1])
+	if err != nil {
+		return 0, vterrors.Wrapf(err, "error loading sequence %s", tableName)
+	}
+	stride, err := evalengine.ToInt64(row[2])
+	if err != nil {
+		return 0, vterrors.Wrapf(err, "error loading sequence %s", tableName)
+	}
+	if cache < 1 || stride < 1 {
+		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid cache or stride value for sequence %s: %d, %d", tableName, cache, stride)
+	}
+	if inc > cache {
+		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot allocate %d values from sequence %s with cache %d", inc, tableName, cache)
+	}
+	if err := qre.checkSequenceStride(tableName, stride); err != nil {
+		return 0, err
+	}
+	if cache > math.MaxInt64/stride || nextID > math.MaxInt64-cache*stride {
+		return 0, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "sequence %s is exhausted: next value %d with cache %d and stride %d overflows int64", tableName, nextID, cache, stride)
+	}
+	newNext := nextID + cache*stride
+	query := fmt.Sprintf("update %s set next_id = %d where id = 0", sqlparser.String(tableName), newNext)
+	conn.TxProperties().RecordQuery(query)
+	if _, err := qre.execStatefulConn(conn, query, false); err != nil {
+		return 0, err
+	}
+	qre.recordSequenceUsage(tableName.String(), newNext)
+	return nextID + cache, nil
+}
+
+// checkSequenceStride verifies that the stride of an interleaved sequence
+// matches the number of shards serving writes for the keyspace, otherwise
+// the blocks of the shards would overlap. The check is skipped if the
+// keyspace cannot be read from the topo.
+func (qre *QueryExecutor) checkSequenceStride(tableName sqlparser.IdentifierCS, stride int64) error {
+	target := qre.tsv.sm.Target()
+	if srvTopoServer == nil || target == nil || target.Keyspace == "" {
+		return nil
+	}
+	srvKeyspace, err := srvTopoServer.GetSrvKeyspace(qre.ctx, qre.tsv.alias.GetCell(), target.Keyspace)
+	if err != nil || srvKeyspace == nil {
+		return nil
+	}
+	for _, partition := range srvKeyspace.Partitions {
+		if partition.ServedType != topodatapb.TabletType_PRIMARY {
+			continue
+		}
+		if shards := int64(len(partition.ShardReferences)); shards != stride {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "stride %d of sequence %s does not match the %d shards of keyspace %s", stride, tableName, shards, target.Keyspace)
+		}
+	}
+	return nil
+}
+
+// sequenceWarnThreshold is the fraction of the int64 range past which a
+// sequence is considered close to exhaustion.
+const sequenceWarnThreshold = 0.9
+
+// recordSequenceUsage exports how many values are left in a sequence after
+// a new block has been allocated, and warns if it is running out.
+func (qre *QueryExecutor) recordSequenceUsage(name string, last int64) {
+	remaining := math.MaxInt64 - last
+	qre.tsv.stats.SequenceRemaining.Set(name, remaining)
+	if float64(last) >= sequenceWarnThreshold*math.MaxInt64 {
+		log.Warningf("Sequence %s is approaching exhaustion: %d values remaining", name, remaining)
+	}
+}
+
 // execSelect sends a query to mysql only if another identical query is not running. Otherwise, it waits and
 // reuses the result. If the plan is missing field info, it sends the query to mysql requesting full info.
 func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
@@ -661,6 +815,83 @@ func (qre *QueryExecutor) execDMLLimit(conn *StatefulConnection) (*sqltypes.Resu
 	return result, nil
 }
 
+// execDMLReturning executes a DML with a RETURNING clause. MySQL does not
+// support RETURNING, so the returned rows are selected in the transaction of
+// the DML: before a DELETE, and after an INSERT or an UPDATE.
+func (qre *QueryExecutor) execDMLReturning(conn *StatefulConnection) (*sqltypes.Result, error) {
+	returning := qre.plan.Returning
+	for name, autoIncrement := range returning.KeyArguments {
+		bv, ok := qre.bindVars[name]
+		if !ok {
+			continue
+		}
+		val, err := sqltypes.BindVariableToValue(bv)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
+		}
+		if val.IsNull() || (autoIncrement && val.ToString() == "0") {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "RETURNING is not supported on INSERT of NULL, or of 0 into an auto-increment column, in the primary key")
+		}
+	}
+	var locked *sqltypes.Result
+	if returning.LockQuery != nil {
+		var err error
+		locked, err = qre.execReturningQuery(conn, returning.LockQuery, qre.bindVars)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result *sqltypes.Result
+	var err error
+	switch qre.plan.PlanID {
+	case p.PlanUpdateLimit, p.PlanDeleteLimit:
+		result, err = qre.execDMLLimit(conn)
+	default:
+		result, err = qre.txFetch(conn, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *sqltypes.Result
+	switch qre.plan.PlanID {
+	case p.PlanDelete, p.PlanDeleteLimit:
+		rows = locked
+	case p.PlanUpdate, p.PlanUpdateLimit:
+		query, bindVars := returning.ByPrimaryKeys(locked.Rows)
+		rows, err = qre.execReturningQuery(conn, query, bindVars)
+	default:
+		bindVars := qre.bindVars
+		if returning.GeneratedID {
+			if result.InsertID == 0 {
+				// Nothing was inserted. The query still returns the fields.
+				bindVars[p.ReturningInsertID] = sqltypes.NullBindVariable
+			} else {
+				bindVars[p.ReturningInsertID] = sqltypes.Uint64BindVariable(result.InsertID)
+			}
+		}
+		rows, err = qre.execReturningQuery(conn, returning.Query, bindVars)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{
+		Fields:       rows.Fields,
+		Rows:         rows.Rows,
+		RowsAffected: result.RowsAffected,
+		InsertID:     result.InsertID,
+	}, nil
+}
+
+func (qre *QueryExecutor) execReturningQuery(conn *StatefulConnection, query *sqlparser.ParsedQuery, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	sql, err := query.GenerateQuery(bindVars, nil)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
+	}
+	return qre.execStatefulConn(conn, sql, true)
+}
+
 func (qre *QueryExecutor) verifyRowCount(count, maxrows int64) error {
 	if count > maxrows {
 		callerID := callerid.ImmediateCallerIDFromContext(qre.ctx)
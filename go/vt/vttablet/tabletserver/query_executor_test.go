@@ -19,10 +19,12 @@ package tabletserver
 import (
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 
@@ -37,10 +39,13 @@ import (
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/callinfo/fakecallinfo"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/srvtopo/srvtopotest"
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -63,6 +68,7 @@ func TestQueryExecutorPlans(t *testing.T) {
 	fields := sqltypes.MakeTestFields("a|b", "int64|varchar")
 	fieldResult := sqltypes.MakeTestResult(fields)
 	selectResult := sqltypes.MakeTestResult(fields, "1|aaa")
+	returningResult := sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk|name", "int32|int32"), "5|2")
 	emptyResult := &sqltypes.Result{}
 
 	// The queries are run both in and outside a transaction.
@@ -192,6 +198,48 @@ func TestQueryExecutorPlans(t *testing.T) {
 		resultWant: dmlResult,
 		planWant:   "Delete",
 		logWant:    "delete from test_table",
+	}, {
+		input: "insert into test_table(name) values(2) returning pk, name",
+		dbResponses: []dbResponse{{
+			query:  "insert into test_table(`name`) values (2)",
+			result: &sqltypes.Result{RowsAffected: 1, InsertID: 5},
+		}, {
+			query:  "select pk, `name` from test_table where pk = 5",
+			result: returningResult,
+		}},
+		resultWant: &sqltypes.Result{Fields: returningResult.Fields, Rows: returningResult.Rows, RowsAffected: 1, InsertID: 5},
+		planWant:   "Insert",
+		logWant:    "begin; insert into test_table(`name`) values (2); select pk, `name` from test_table where pk = 5; commit",
+		inTxWant:   "insert into test_table(`name`) values (2); select pk, `name` from test_table where pk = 5",
+	}, {
+		input: "update test_table set name=2 where addr=3 returning pk, name",
+		dbResponses: []dbResponse{{
+			query:  "select pk from test_table where addr = 3 for update",
+			result: sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk", "int32"), "5"),
+		}, {
+			query:  "update test_table set `name` = 2 where addr = 3 limit 10001",
+			result: dmlResult,
+		}, {
+			query:  "select pk, `name` from test_table where pk in (5)",
+			result: returningResult,
+		}},
+		resultWant: &sqltypes.Result{Fields: returningResult.Fields, Rows: returningResult.Rows, RowsAffected: 1},
+		planWant:   "UpdateLimit",
+		logWant:    "begin; select pk from test_table where addr = 3 for update; update test_table set `name` = 2 where addr = 3 limit 10001; select pk, `name` from test_table where pk in (5); commit",
+		inTxWant:   "select pk from test_table where addr = 3 for update; update test_table set `name` = 2 where addr = 3 limit 10001; select pk, `name` from test_table where pk in (5)",
+	}, {
+		input: "delete from test_table where addr=3 returning pk, name",
+		dbResponses: []dbResponse{{
+			query:  "select pk, `name` from test_table where addr = 3 for update",
+			result: returningResult,
+		}, {
+			query:  "delete from test_table where addr = 3 limit 10001",
+			result: dmlResult,
+		}},
+		resultWant: &sqltypes.Result{Fields: returningResult.Fields, Rows: returningResult.Rows, RowsAffected: 1},
+		planWant:   "DeleteLimit",
+		logWant:    "begin; select pk, `name` from test_table where addr = 3 for update; delete from test_table where addr = 3 limit 10001; commit",
+		inTxWant:   "select pk, `name` from test_table where addr = 3 for update; delete from test_table where addr = 3 limit 10001",
 	}, {
 		input: "alter table test_table add zipcode int",
 		dbResponses: []dbResponse{{
@@ -774,6 +822,127 @@ func TestQueryExecutorPlanNextval(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorPlanNextvalExhausted(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery("select next_id, cache from seq where id = 0 for update", &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt64(math.MaxInt64 - 2),
+			sqltypes.NewInt64(3),
+		}},
+	})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	qre := newTestQueryExecutor(ctx, tsv, "select next value from seq", 0)
+	_, err := qre.Execute()
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+}
+
+func TestQueryExecutorPlanNextvalInterleaved(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	selQuery := "select next_id, cache, stride from seq where id = 0 for update"
+	db.AddQuery(selQuery, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt64(4),
+			sqltypes.NewInt64(3),
+			sqltypes.NewInt64(2),
+		}},
+	})
+	db.AddQuery("update seq set next_id = 10 where id = 0", &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.se.GetTable(sqlparser.NewIdentifierCS("seq")).SequenceInfo.Interleaved = true
+
+	nextval := func(query string) int64 {
+		t.Helper()
+		got, err := newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+		require.NoError(t, err)
+		v, err := got.Rows[0][0].ToInt64()
+		require.NoError(t, err)
+		return v
+	}
+	// The block is [4, 7), and the next one of this shard starts after the
+	// block of the other shard.
+	assert.Equal(t, int64(4), nextval("select next 2 values from seq"))
+	assert.Equal(t, int64(6), nextval("select next value from seq"))
+
+	// The block does not have room for 2 more values, so a new block is
+	// allocated.
+	db.AddQuery(selQuery, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt64(10),
+			sqltypes.NewInt64(3),
+			sqltypes.NewInt64(2),
+		}},
+	})
+	db.AddQuery("update seq set next_id = 16 where id = 0", &sqltypes.Result{})
+	assert.Equal(t, int64(10), nextval("select next 2 values from seq"))
+
+	_, err := newTestQueryExecutor(ctx, tsv, "select next 4 values from seq", 0).Execute()
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+}
+
+func TestQueryExecutorPlanNextvalStrideMismatch(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery("select next_id, cache, stride from seq where id = 0 for update", &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt64(4),
+			sqltypes.NewInt64(3),
+			sqltypes.NewInt64(2),
+		}},
+	})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.se.GetTable(sqlparser.NewIdentifierCS("seq")).SequenceInfo.Interleaved = true
+	tsv.sm.target.Keyspace = "ks"
+
+	srv := srvtopotest.NewPassthroughSrvTopoServer()
+	srv.SrvKeyspace = &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+			ServedType: topodatapb.TabletType_PRIMARY,
+			ShardReferences: []*topodatapb.ShardReference{
+				{Name: "-40"},
+				{Name: "40-80"},
+				{Name: "80-"},
+			},
+		}},
+	}
+	oldSrvTopoServer := srvTopoServer
+	srvTopoServer = srv
+	defer func() { srvTopoServer = oldSrvTopoServer }()
+
+	_, err := newTestQueryExecutor(ctx, tsv, "select next value from seq", 0).Execute()
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "stride 2 of sequence seq does not match the 3 shards of keyspace ks")
+}
+
 func TestQueryExecutorMessageStreamACL(t *testing.T) {
 	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int63())
 	tableacl.Register(aclName, &simpleacl.Factory{})
@@ -1169,6 +1338,115 @@ func TestQueryExecutorDenyListQRFail(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorHotRowDetection(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery("select * from test_table where pk = 1 limit 10001", &sqltypes.Result{})
+	db.AddQuery("select * from test_table where pk = 2 limit 10001", &sqltypes.Result{})
+	db.AddQuery("select * from test_table where `name` = 1 limit 10001", &sqltypes.Result{})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableHotRowDetection, db)
+	defer tsv.StopService()
+
+	queries := []struct {
+		query    string
+		bindVars map[string]*querypb.BindVariable
+	}{
+		{query: "select * from test_table where pk = 1"},
+		{query: "select * from test_table where pk = :pk", bindVars: map[string]*querypb.BindVariable{"pk": sqltypes.Int64BindVariable(1)}},
+		{query: "select * from test_table where pk = 2"},
+		// The queries that don't select a row by its primary key are not recorded.
+		{query: "select * from test_table where name = 1"},
+	}
+	for _, q := range queries {
+		qre := newTestQueryExecutor(ctx, tsv, q.query, 0)
+		for k, v := range q.bindVars {
+			qre.bindVars[k] = v
+		}
+		_, err := qre.Execute()
+		require.NoError(t, err, q.query)
+	}
+
+	assert.Equal(t, []hotrows.Row{
+		{Table: "test_table", Key: "pk = 1", Count: 2},
+		{Table: "test_table", Key: "pk = 2", Count: 1},
+	}, tsv.HotRows().Report("", 0))
+}
+
+func TestQueryExecutorReturningKeyArguments(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery("insert into test_table(pk, `name`) values (5, 2)", &sqltypes.Result{RowsAffected: 1})
+	returningResult := sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk", "int32"), "5")
+	db.AddQuery("select pk from test_table where pk in (5)", returningResult)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	query := "insert into test_table(pk, name) values (:pk, 2) returning pk"
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	qre.bindVars["pk"] = sqltypes.Int64BindVariable(5)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, returningResult.Rows, got.Rows)
+
+	// A NULL primary key would not select the inserted row.
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	qre.bindVars["pk"] = sqltypes.NullBindVariable
+	_, err = qre.Execute()
+	assert.ErrorContains(t, err, "RETURNING is not supported on INSERT of NULL")
+}
+
+func TestQueryExecutorWritesFrozen(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	dmlResult := &sqltypes.Result{RowsAffected: 1}
+	db.AddQuery("insert into test_table(a) values (1)", dmlResult)
+	db.AddQuery("select * from t where 1 != 1", &sqltypes.Result{})
+	db.AddQuery("select * from t limit 10001", &sqltypes.Result{})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	tsv.FreezeWrites(time.Time{})
+	frozen, until := tsv.WritesFrozen()
+	assert.True(t, frozen)
+	assert.True(t, until.IsZero())
+
+	// The queries that read are executed, the queries that write are rejected.
+	_, err := newTestQueryExecutor(ctx, tsv, "select * from t", 0).Execute()
+	require.NoError(t, err)
+	_, err = newTestQueryExecutor(ctx, tsv, "insert into test_table(a) values(1)", 0).Execute()
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.Equal(t, vterrors.InnodbReadOnly, vterrors.ErrState(err))
+	// vtgate returns it as the MySQL error of a read-only server.
+	sqlErr := mysql.NewSQLErrorFromError(vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, err.Error())).(*mysql.SQLError)
+	assert.Equal(t, mysql.ERInnodbReadOnly, sqlErr.Number())
+
+	// The queries of vreplication and online DDL are not rejected.
+	_, err = newTestQueryExecutor(tabletenv.LocalContext(), tsv, "insert into test_table(a) values(1)", 0).Execute()
+	require.NoError(t, err)
+
+	tsv.UnfreezeWrites()
+	_, err = newTestQueryExecutor(ctx, tsv, "insert into test_table(a) values(1)", 0).Execute()
+	require.NoError(t, err)
+
+	// The freeze expires.
+	tsv.FreezeWrites(time.Now().Add(time.Hour))
+	_, err = newTestQueryExecutor(ctx, tsv, "insert into test_table(a) values(1)", 0).Execute()
+	assert.Contains(t, err.Error(), "the shard is read-only, writes are rejected until")
+	tsv.FreezeWrites(time.Now().Add(-time.Second))
+	_, err = newTestQueryExecutor(ctx, tsv, "insert into test_table(a) values(1)", 0).Execute()
+	require.NoError(t, err)
+	frozen, _ = tsv.WritesFrozen()
+	assert.False(t, frozen)
+}
+
 func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1230,8 +1508,10 @@ const (
 	smallTxPool
 	noTwopc
 	shortTwopcAge
+	twopcAutoRollback
 	smallResultSize
 	disableOnlineDDL
+	enableHotRowDetection
 )
 
 // newTestQueryExecutor uses a package level variable testTabletServer defined in tabletserver_test.go
@@ -1264,9 +1544,15 @@ func newTestTabletServer(ctx context.Context, flags executorFlags, db *fakesqldb
 	} else {
 		config.TwoPCAbandonAge = 10
 	}
+	if flags&twopcAutoRollback > 0 {
+		config.TwoPCAutoRollbackAge = 0.5
+	}
 	if flags&smallResultSize > 0 {
 		config.Oltp.MaxRows = 2
 	}
+	if flags&enableHotRowDetection > 0 {
+		config.HotRowDetection.Enable = true
+	}
 	dbconfigs := newDBConfigs(db)
 	config.DB = dbconfigs
 	tsv := NewTabletServer("TabletServerTest", config, memorytopo.NewServer(""), &topodatapb.TabletAlias{})
@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repltracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/history"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// LagSample is a sample of the replication lag of the tablet.
+type LagSample struct {
+	Time time.Time
+	Lag  time.Duration
+	// Error is the error the replication tracker reported instead of the lag.
+	Error string
+}
+
+// LagHistory samples the replication lag of a replica at a fixed resolution,
+// and keeps the samples in memory for a retention period, so that the trend
+// of the lag can be seen without an external time series database. It only
+// samples while the tablet is a replica and tracks its replication lag, but
+// keeps its samples when the tablet is promoted.
+type LagHistory struct {
+	// Immutable fields.
+	enabled    bool
+	resolution time.Duration
+	lag        func() (time.Duration, error)
+	samples    *history.History
+
+	// now is replaced in tests.
+	now func() time.Time
+
+	mu     sync.Mutex
+	isOpen bool
+	ticks  *timer.Timer
+}
+
+// NewLagHistory returns a LagHistory which samples the replication lag
+// returned by lag. The ReplTracker of the tablet creates its own.
+func NewLagHistory(env tabletenv.Env, lag func() (time.Duration, error)) *LagHistory {
+	config := env.Config().ReplicationTracker
+	if config.Mode == tabletenv.Disable || config.LagHistoryRetention <= 0 || config.LagHistoryResolution <= 0 {
+		return &LagHistory{}
+	}
+
+	size := int(config.LagHistoryRetention / config.LagHistoryResolution)
+	if size < 1 {
+		size = 1
+	}
+	return &LagHistory{
+		enabled:    true,
+		resolution: config.LagHistoryResolution,
+		lag:        lag,
+		samples:    history.New(size),
+		now:        time.Now,
+		ticks:      timer.NewTimer(config.LagHistoryResolution),
+	}
+}
+
+// Enabled returns true if the replication lag history is enabled.
+func (h *LagHistory) Enabled() bool {
+	return h != nil && h.enabled
+}
+
+// Resolution returns the interval between the samples.
+func (h *LagHistory) Resolution() time.Duration {
+	return h.resolution
+}
+
+// Open starts sampling the replication lag.
+func (h *LagHistory) Open() {
+	if !h.Enabled() {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isOpen {
+		return
+	}
+	log.Info("Replication lag history: opening")
+	h.ticks.Start(h.record)
+	h.isOpen = true
+}
+
+// Close stops sampling the replication lag. The samples are kept.
+func (h *LagHistory) Close() {
+	if !h.Enabled() {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isOpen {
+		return
+	}
+	h.ticks.Stop()
+	h.isOpen = false
+	log.Info("Replication lag history: closed")
+}
+
+// record takes a sample of the replication lag.
+func (h *LagHistory) record() {
+	sample := LagSample{Time: h.now()}
+	lag, err := h.lag()
+	if err != nil {
+		sample.Error = err.Error()
+	} else {
+		sample.Lag = lag
+	}
+	h.samples.Add(sample)
+}
+
+// Samples returns the samples taken after since, oldest first. All the
+// samples are returned if since is zero.
+func (h *LagHistory) Samples(since time.Time) []LagSample {
+	if !h.Enabled() {
+		return nil
+	}
+
+	// The records are the most recent first.
+	records := h.samples.Records()
+	samples := make([]LagSample, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		sample := records[i].(LagSample)
+		if !since.IsZero() && !sample.Time.After(since) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// ServeHTTP lists the samples of the replication lag history as JSON, with
+// the lag in seconds. The "window" parameter, a duration, restricts the list
+// to the most recent samples.
+func (h *LagHistory) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	if !h.Enabled() {
+		http.Error(response, "replication lag history is disabled, see --replication_lag_history_retention, --heartbeat_enable and --enable_replication_reporter", http.StatusNotFound)
+		return
+	}
+
+	var since time.Time
+	if v := request.FormValue("window"); v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil {
+			http.Error(response, "invalid window: "+v, http.StatusBadRequest)
+			return
+		}
+		since = h.now().Add(-window)
+	}
+
+	type jsonSample struct {
+		Time       time.Time `json:"time"`
+		LagSeconds float64   `json:"lag_seconds"`
+		Error      string    `json:"error,omitempty"`
+	}
+	samples := h.Samples(since)
+	report := struct {
+		ResolutionSeconds float64      `json:"resolution_seconds"`
+		Samples           []jsonSample `json:"samples"`
+	}{
+		ResolutionSeconds: h.resolution.Seconds(),
+		Samples:           make([]jsonSample, 0, len(samples)),
+	}
+	for _, sample := range samples {
+		report.Samples = append(report.Samples, jsonSample{
+			Time:       sample.Time,
+			LagSeconds: sample.Lag.Seconds(),
+			Error:      sample.Error,
+		})
+	}
+
+	b, err := json.MarshalIndent(report, "", " ")
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	response.Write(b)
+}
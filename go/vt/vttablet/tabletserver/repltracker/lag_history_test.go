@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repltracker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestLagHistory(t *testing.T, resolution, retention time.Duration, lag func() (time.Duration, error)) *LagHistory {
+	t.Helper()
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Polling
+	config.ReplicationTracker.LagHistoryResolution = resolution
+	config.ReplicationTracker.LagHistoryRetention = retention
+	return NewLagHistory(tabletenv.NewEnv(config, "LagHistoryTest"), lag)
+}
+
+func TestLagHistoryDisabled(t *testing.T) {
+	h := newTestLagHistory(t, 10*time.Second, 0, nil)
+	assert.False(t, h.Enabled())
+	assert.Nil(t, h.Samples(time.Time{}))
+	h.Open()
+	assert.False(t, h.isOpen)
+
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Disable
+	h = NewLagHistory(tabletenv.NewEnv(config, "LagHistoryTest"), nil)
+	assert.False(t, h.Enabled())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/replication_lag_history", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLagHistorySamples(t *testing.T) {
+	var (
+		lag    time.Duration
+		lagErr error
+	)
+	h := newTestLagHistory(t, 10*time.Second, 30*time.Second, func() (time.Duration, error) {
+		return lag, lagErr
+	})
+	require.True(t, h.Enabled())
+	assert.Equal(t, 10*time.Second, h.Resolution())
+
+	start := time.Unix(1661000000, 0)
+	now := start
+	h.now = func() time.Time { return now }
+	record := func(l time.Duration, err error) {
+		lag, lagErr = l, err
+		now = now.Add(10 * time.Second)
+		h.record()
+	}
+
+	record(1*time.Second, nil)
+	record(2*time.Second, nil)
+	want := []LagSample{
+		{Time: start.Add(10 * time.Second), Lag: 1 * time.Second},
+		{Time: start.Add(20 * time.Second), Lag: 2 * time.Second},
+	}
+	assert.Equal(t, want, h.Samples(time.Time{}))
+
+	// The history only keeps the samples of the retention period.
+	record(0, errors.New("replication is not running"))
+	record(4*time.Second, nil)
+	want = []LagSample{
+		{Time: start.Add(20 * time.Second), Lag: 2 * time.Second},
+		{Time: start.Add(30 * time.Second), Error: "replication is not running"},
+		{Time: start.Add(40 * time.Second), Lag: 4 * time.Second},
+	}
+	assert.Equal(t, want, h.Samples(time.Time{}))
+	assert.Equal(t, want[1:], h.Samples(start.Add(20*time.Second)))
+	assert.Empty(t, h.Samples(now))
+}
+
+func TestLagHistoryServeHTTP(t *testing.T) {
+	h := newTestLagHistory(t, 10*time.Second, time.Minute, func() (time.Duration, error) {
+		return 1500 * time.Millisecond, nil
+	})
+	start := time.Unix(1661000000, 0)
+	now := start
+	h.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		now = now.Add(10 * time.Second)
+		h.record()
+	}
+
+	var report struct {
+		ResolutionSeconds float64 `json:"resolution_seconds"`
+		Samples           []struct {
+			Time       time.Time `json:"time"`
+			LagSeconds float64   `json:"lag_seconds"`
+		} `json:"samples"`
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/replication_lag_history?window=15s", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 10.0, report.ResolutionSeconds)
+	require.Len(t, report.Samples, 2)
+	assert.True(t, start.Add(20*time.Second).Equal(report.Samples[0].Time))
+	assert.Equal(t, 1.5, report.Samples[0].LagSeconds)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/replication_lag_history?window=x", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLagHistoryOpenClose(t *testing.T) {
+	sampled := make(chan struct{}, 1)
+	h := newTestLagHistory(t, 10*time.Millisecond, time.Second, func() (time.Duration, error) {
+		select {
+		case sampled <- struct{}{}:
+		default:
+		}
+		return 0, nil
+	})
+	h.Open()
+	h.Open()
+	assert.True(t, h.isOpen)
+	select {
+	case <-sampled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replication lag was not sampled")
+	}
+	h.Close()
+	h.Close()
+	assert.False(t, h.isOpen)
+	assert.NotEmpty(t, h.Samples(time.Time{}))
+}
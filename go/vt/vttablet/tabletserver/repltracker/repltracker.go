@@ -25,6 +25,8 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/heartbeat"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
@@ -53,24 +55,31 @@ var (
 type ReplTracker struct {
 	mode           string
 	forceHeartbeat bool
+	unmanaged      bool
+	mysqld         mysqlctl.MysqlDaemon
 
 	mu        sync.Mutex
 	isPrimary bool
 
-	hw     *heartbeatWriter
-	hr     *heartbeatReader
-	poller *poller
+	hw      *heartbeatWriter
+	hr      *heartbeatReader
+	poller  *poller
+	history *LagHistory
 }
 
 // NewReplTracker creates a new ReplTracker.
 func NewReplTracker(env tabletenv.Env, alias *topodatapb.TabletAlias) *ReplTracker {
-	return &ReplTracker{
+	rt := &ReplTracker{
 		mode:           env.Config().ReplicationTracker.Mode,
 		forceHeartbeat: env.Config().EnableLagThrottler,
+		unmanaged:      env.Config().Unmanaged,
 		hw:             newHeartbeatWriter(env, alias),
 		hr:             newHeartbeatReader(env),
 		poller:         &poller{},
 	}
+	rt.history = NewLagHistory(env, rt.lag)
+	env.Exporter().HandleFunc("/debug/replication_lag_history", rt.history.ServeHTTP)
+	return rt
 }
 
 // HeartbeatWriter returns the heartbeat writer used by this tracker
@@ -78,11 +87,17 @@ func (rt *ReplTracker) HeartbeatWriter() heartbeat.HeartbeatWriter {
 	return rt.hw
 }
 
+// LagHistory returns the replication lag history of this tracker.
+func (rt *ReplTracker) LagHistory() *LagHistory {
+	return rt.history
+}
+
 // InitDBConfig initializes the target name.
 func (rt *ReplTracker) InitDBConfig(target *querypb.Target, mysqld mysqlctl.MysqlDaemon) {
 	rt.hw.InitDBConfig(target)
 	rt.hr.InitDBConfig(target)
 	rt.poller.InitDBConfig(mysqld)
+	rt.mysqld = mysqld
 }
 
 // MakePrimary must be called if the tablet type becomes PRIMARY.
@@ -92,6 +107,7 @@ func (rt *ReplTracker) MakePrimary() {
 	log.Info("Replication Tracker: going into primary mode")
 
 	rt.isPrimary = true
+	rt.history.Close()
 	if rt.mode == tabletenv.Heartbeat {
 		rt.hr.Close()
 		rt.hw.Open()
@@ -119,10 +135,12 @@ func (rt *ReplTracker) MakeNonPrimary() {
 	if rt.forceHeartbeat {
 		rt.hw.Close()
 	}
+	rt.history.Open()
 }
 
 // Close closes ReplTracker.
 func (rt *ReplTracker) Close() {
+	rt.history.Close()
 	rt.hw.Close()
 	rt.hr.Close()
 	log.Info("Replication Tracker: closed")
@@ -133,6 +151,11 @@ func (rt *ReplTracker) Status() (time.Duration, error) {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
+	if rt.unmanaged {
+		if err := rt.checkReadOnly(); err != nil {
+			return 0, err
+		}
+	}
 	switch {
 	case rt.isPrimary || rt.mode == tabletenv.Disable:
 		return 0, nil
@@ -143,6 +166,34 @@ func (rt *ReplTracker) Status() (time.Duration, error) {
 	return rt.poller.Status()
 }
 
+// lag reports the replication lag of a replica for the lag history. It does
+// not lock rt.mu, which is held when the history is closed.
+func (rt *ReplTracker) lag() (time.Duration, error) {
+	if rt.mode == tabletenv.Heartbeat {
+		return rt.hr.Status()
+	}
+	return rt.poller.Status()
+}
+
+// checkReadOnly verifies that the read-only state of an unmanaged mysqld
+// matches the tablet type. Vitess does not change the read-only state of an
+// unmanaged mysqld, so a mismatch means that the database was failed over
+// outside of Vitess: a read-only primary cannot take writes, and a writable
+// replica may have diverged from the primary.
+func (rt *ReplTracker) checkReadOnly() error {
+	readOnly, err := rt.mysqld.IsReadOnly()
+	if err != nil {
+		return err
+	}
+	if rt.isPrimary && readOnly {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the unmanaged mysqld of the primary tablet is read-only")
+	}
+	if !rt.isPrimary && !readOnly {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the unmanaged mysqld of the non-primary tablet is writable")
+	}
+	return nil
+}
+
 // EnableHeartbeat enables or disables writes of heartbeat. This functionality
 // is only used by tests.
 func (rt *ReplTracker) EnableHeartbeat(enable bool) {
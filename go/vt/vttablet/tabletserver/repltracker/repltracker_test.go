@@ -54,10 +54,12 @@ func TestReplTracker(t *testing.T) {
 	assert.Equal(t, tabletenv.Heartbeat, rt.mode)
 	assert.True(t, rt.hw.enabled)
 	assert.True(t, rt.hr.enabled)
+	assert.True(t, rt.LagHistory().Enabled())
 
 	rt.MakePrimary()
 	assert.True(t, rt.hw.isOpen)
 	assert.False(t, rt.hr.isOpen)
+	assert.False(t, rt.history.isOpen)
 	assert.True(t, rt.isPrimary)
 
 	lag, err := rt.Status()
@@ -67,6 +69,7 @@ func TestReplTracker(t *testing.T) {
 	rt.MakeNonPrimary()
 	assert.False(t, rt.hw.isOpen)
 	assert.True(t, rt.hr.isOpen)
+	assert.True(t, rt.history.isOpen)
 	assert.False(t, rt.isPrimary)
 
 	rt.hr.lastKnownLag = 1 * time.Second
@@ -77,6 +80,7 @@ func TestReplTracker(t *testing.T) {
 	rt.Close()
 	assert.False(t, rt.hw.isOpen)
 	assert.False(t, rt.hr.isOpen)
+	assert.False(t, rt.history.isOpen)
 
 	config.ReplicationTracker.Mode = tabletenv.Polling
 	rt = NewReplTracker(env, alias)
@@ -95,3 +99,34 @@ func TestReplTracker(t *testing.T) {
 	_, err = rt.Status()
 	assert.Equal(t, "err", err.Error())
 }
+
+func TestReplTrackerUnmanaged(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.Unmanaged = true
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+
+	rt := NewReplTracker(env, alias)
+	rt.InitDBConfig(&querypb.Target{}, mysqld)
+	defer rt.Close()
+
+	// The read-only state of the mysqld must match the tablet type.
+	rt.MakePrimary()
+	mysqld.ReadOnly = true
+	_, err := rt.Status()
+	assert.EqualError(t, err, "the unmanaged mysqld of the primary tablet is read-only")
+	mysqld.ReadOnly = false
+	_, err = rt.Status()
+	assert.NoError(t, err)
+
+	rt.MakeNonPrimary()
+	_, err = rt.Status()
+	assert.EqualError(t, err, "the unmanaged mysqld of the non-primary tablet is writable")
+	mysqld.ReadOnly = true
+	_, err = rt.Status()
+	assert.NoError(t, err)
+}
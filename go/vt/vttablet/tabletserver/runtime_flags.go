@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/vt/runtimeflags"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// registerRuntimeFlags whitelists the flags of the tablet server that can be
+// changed while it runs, through the runtimeflags package.
+func (tsv *TabletServer) registerRuntimeFlags() {
+	runtimeflags.Register("queryserver-config-pool-size", applyInt(func(val int) error {
+		if val <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the pool size must be positive")
+		}
+		tsv.SetPoolSize(val)
+		return nil
+	}))
+	runtimeflags.Register("queryserver-config-stream-pool-size", applyInt(func(val int) error {
+		if val <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the stream pool size must be positive")
+		}
+		tsv.SetStreamPoolSize(val)
+		return nil
+	}))
+	runtimeflags.Register("queryserver-config-transaction-cap", applyInt(func(val int) error {
+		if val <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the transaction cap must be positive")
+		}
+		tsv.SetTxPoolSize(val)
+		return nil
+	}))
+	runtimeflags.Register("queryserver-config-max-result-size", applyInt(func(val int) error {
+		tsv.SetMaxResultSize(val)
+		return nil
+	}))
+	runtimeflags.Register("queryserver-config-warn-result-size", applyInt(func(val int) error {
+		tsv.SetWarnResultSize(val)
+		return nil
+	}))
+	runtimeflags.Register("queryserver-config-query-timeout", applySeconds(func(val time.Duration) {
+		tsv.QueryTimeout.Set(val)
+	}))
+	runtimeflags.Register("queryserver-config-transaction-timeout", applySeconds(tsv.SetTxTimeout))
+	runtimeflags.Register("throttle_threshold", func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		tsv.SetThrottleMetricThreshold(d.Seconds())
+		return nil
+	})
+}
+
+// applyInt returns a runtimeflags.ApplyFunc for an int flag.
+func applyInt(f func(int) error) runtimeflags.ApplyFunc {
+	return func(value string) error {
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		return f(val)
+	}
+}
+
+// applySeconds returns a runtimeflags.ApplyFunc for a flag of seconds, like the
+// tabletenv.SecondsVar flags.
+func applySeconds(f func(time.Duration)) runtimeflags.ApplyFunc {
+	return func(value string) error {
+		val, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f(time.Duration(val * float64(time.Second)))
+		return nil
+	}
+}
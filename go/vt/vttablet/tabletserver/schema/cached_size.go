@@ -59,7 +59,7 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	}
 	// field SequenceInfo *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.SequenceInfo
 	if cached.SequenceInfo != nil {
-		size += hack.RuntimeAllocSize(int64(24))
+		size += hack.RuntimeAllocSize(int64(32))
 	}
 	// field MessageInfo *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.MessageInfo
 	size += cached.MessageInfo.CachedSize(true)
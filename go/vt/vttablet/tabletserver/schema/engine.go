@@ -135,7 +135,8 @@ func (se *Engine) EnsureConnectionAndDB(tabletType topodatapb.TabletType) error
 		se.dbCreationFailed = false
 		return nil
 	}
-	if tabletType != topodatapb.TabletType_PRIMARY {
+	// The database of an unmanaged tablet is created outside of Vitess.
+	if tabletType != topodatapb.TabletType_PRIMARY || se.env.Config().Unmanaged {
 		return err
 	}
 	if merr, isSQLErr := err.(*mysql.SQLError); !isSQLErr || merr.Num != mysql.ERBadDb {
@@ -43,6 +43,11 @@ func LoadTable(conn *connpool.DBConn, databaseName, tableName string, comment st
 	case strings.Contains(comment, "vitess_sequence"):
 		ta.Type = Sequence
 		ta.SequenceInfo = &SequenceInfo{}
+		for _, field := range ta.Fields {
+			if strings.EqualFold(field.Name, "stride") {
+				ta.SequenceInfo.Interleaved = true
+			}
+		}
 	case strings.Contains(comment, "vitess_message"):
 		if err := loadMessageInfo(ta, comment); err != nil {
 			return nil, err
@@ -104,6 +109,12 @@ func loadMessageInfo(ta *Table, comment string) error {
 
 	ta.MessageInfo.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
 
+	ta.MessageInfo.MaxAttempts, _ = getNum(keyvals, "vt_max_attempts")
+	ta.MessageInfo.DeadLetterTable = strings.TrimSpace(keyvals["vt_dead_letter_table"])
+	if ta.MessageInfo.DeadLetterTable != "" && ta.MessageInfo.MaxAttempts == 0 {
+		return fmt.Errorf("vt_dead_letter_table requires vt_max_attempts to be set for message table: %s", ta.Name.String())
+	}
+
 	// these columns are required for message manager to function properly, but only
 	// id is required to be streamed to subscribers
 	requiredCols := []string{
@@ -79,6 +79,31 @@ func TestLoadTableSequence(t *testing.T) {
 	}
 }
 
+func TestLoadTableInterleavedSequence(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.ClearQueryPattern()
+	db.MockQueriesForTable("test_table", &sqltypes.Result{
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int32,
+		}, {
+			Name: "next_id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "cache",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "stride",
+			Type: sqltypes.Int64,
+		}},
+	})
+	table, err := newTestLoadTable("USER_TABLE", "vitess_sequence", db)
+	require.NoError(t, err)
+	assert.Equal(t, Sequence, table.Type)
+	assert.True(t, table.SequenceInfo.Interleaved)
+}
+
 func TestLoadTableMessage(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -134,6 +159,20 @@ func TestLoadTableMessage(t *testing.T) {
 	want.MessageInfo.MaxBackoff = 100 * time.Second
 	assert.Equal(t, want, table)
 
+	// Test loading max attempts and dead letter table
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_attempts=5,vt_dead_letter_table=test_table_dlq", db)
+	require.NoError(t, err)
+	want.MessageInfo.MaxAttempts = 5
+	want.MessageInfo.DeadLetterTable = "test_table_dlq"
+	assert.Equal(t, want, table)
+
+	// A dead letter table without max attempts is an error
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_dead_letter_table=test_table_dlq", db)
+	require.ErrorContains(t, err, "vt_dead_letter_table requires vt_max_attempts")
+
+	want.MessageInfo.MaxAttempts = 0
+	want.MessageInfo.DeadLetterTable = ""
+
 	//
 	// multiple tests for vt_message_cols
 	//
@@ -67,6 +67,13 @@ type SequenceInfo struct {
 	sync.Mutex
 	NextVal int64
 	LastVal int64
+
+	// Interleaved is set for the sequence tables that have a stride column,
+	// which are the sequences of sharded keyspaces. Each shard allocates
+	// blocks of cache values, interleaved with the blocks of the other
+	// shards: its next block starts cache*stride values after the
+	// previous one.
+	Interleaved bool
 }
 
 // MessageInfo contains info specific to message tables.
@@ -104,6 +111,16 @@ type MessageInfo struct {
 	// MaxBackoff specifies the longest duration message manager
 	// should wait before rescheduling a message
 	MaxBackoff time.Duration
+
+	// MaxAttempts specifies the number of times a message is
+	// delivered before it's moved to the dead letter table.
+	// If 0, messages are redelivered until they're acked.
+	MaxAttempts int
+
+	// DeadLetterTable specifies the table that receives the
+	// messages that exceeded MaxAttempts. If empty, such
+	// messages are acked and left to be purged.
+	DeadLetterTable string
 }
 
 // NewTable creates a new Table.
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -31,10 +32,14 @@ import (
 
 	"vitess.io/vitess/go/vt/sqlparser"
 
+	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/withddl"
 )
@@ -50,12 +55,15 @@ const createSchemaTrackingTable = `CREATE TABLE IF NOT EXISTS _vt.schema_version
 		) ENGINE=InnoDB`
 const alterSchemaTrackingTableDDLBlob = "alter table _vt.schema_version modify column ddl BLOB NOT NULL"
 const alterSchemaTrackingTableSchemaxBlob = "alter table _vt.schema_version modify column schemax LONGBLOB NOT NULL"
+const alterSchemaTrackingTableAddTableDefinitions = "alter table _vt.schema_version add column table_definitions LONGBLOB DEFAULT NULL"
+const getLatestTableDefinitions = "select table_definitions from _vt.schema_version order by id desc limit 1"
 
 var withDDL = withddl.New([]string{
 	createSidecarDB,
 	createSchemaTrackingTable,
 	alterSchemaTrackingTableDDLBlob,
 	alterSchemaTrackingTableSchemaxBlob,
+	alterSchemaTrackingTableAddTableDefinitions,
 })
 
 // VStreamer defines  the functions of VStreamer
@@ -247,7 +255,10 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 	for _, table := range tables {
 		dbSchema.Tables = append(dbSchema.Tables, newMinimalTable(table))
 	}
-	blob, _ := proto.Marshal(dbSchema)
+	blob, err := proto.Marshal(dbSchema)
+	if err != nil {
+		return err
+	}
 
 	conn, err := tr.engine.GetConnection(ctx)
 	if err != nil {
@@ -255,9 +266,18 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 	}
 	defer conn.Recycle()
 
+	sd, err := tr.schemaDefinition(ctx, conn, tables, ddl)
+	if err != nil {
+		return err
+	}
+	definitions, err := proto.Marshal(sd)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf("insert into _vt.schema_version "+
-		"(pos, ddl, schemax, time_updated) "+
-		"values (%v, %v, %v, %d)", encodeString(gtid), encodeString(ddl), encodeString(string(blob)), timestamp)
+		"(pos, ddl, schemax, table_definitions, time_updated) "+
+		"values (%v, %v, %v, %v, %d)", encodeString(gtid), encodeString(ddl), encodeString(string(blob)), encodeString(string(definitions)), timestamp)
 	_, err = withDDL.Exec(ctx, query, conn.Exec, conn.Exec)
 	if err != nil {
 		return err
@@ -265,6 +285,107 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 	return nil
 }
 
+// schemaDefinition returns the full definition of the tables, including their
+// CREATE statements, so that GetSchemaVersion can return more than the
+// columns vstreamer needs. Only the CREATE statements of the tables the ddl
+// touched are read again, the others are copied from the previous version.
+func (tr *Tracker) schemaDefinition(ctx context.Context, conn *connpool.DBConn, tables map[string]*Table, ddl string) (*tabletmanagerdatapb.SchemaDefinition, error) {
+	previous := tr.previousTableDefinitions(ctx, conn, ddl)
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		if name == "dual" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sd := &tabletmanagerdatapb.SchemaDefinition{}
+	for _, name := range names {
+		if td, ok := previous[name]; ok {
+			sd.TableDefinitions = append(sd.TableDefinitions, newTableDefinition(tables[name], td.Schema))
+			continue
+		}
+		qr, err := conn.Exec(ctx, fmt.Sprintf("show create table %s.%s", sqlescape.EscapeID(tr.engine.cp.DBName()), sqlescape.EscapeID(name)), 1, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(qr.Rows) != 1 || len(qr.Rows[0]) < 2 {
+			return nil, fmt.Errorf("unexpected result for show create table %s: %v", name, qr.Rows)
+		}
+		sd.TableDefinitions = append(sd.TableDefinitions, newTableDefinition(tables[name], qr.Rows[0][1].ToString()))
+	}
+	return sd, nil
+}
+
+// previousTableDefinitions returns the table definitions of the latest saved
+// version, without the tables the ddl touched. It returns nil, and all the
+// tables are read again, for the initial version, for a ddl that cannot be
+// parsed, and for renames, which change the foreign keys of other tables.
+func (tr *Tracker) previousTableDefinitions(ctx context.Context, conn *connpool.DBConn, ddl string) map[string]*tabletmanagerdatapb.TableDefinition {
+	if ddl == "" {
+		return nil
+	}
+	stmt, err := sqlparser.Parse(ddl)
+	if err != nil {
+		return nil
+	}
+	ddlStmt, ok := stmt.(sqlparser.DDLStatement)
+	if !ok || isRename(ddlStmt) {
+		return nil
+	}
+
+	qr, err := conn.Exec(ctx, getLatestTableDefinitions, 1, false)
+	if err != nil || len(qr.Rows) != 1 || qr.Rows[0][0].IsNull() {
+		return nil
+	}
+	sd := &tabletmanagerdatapb.SchemaDefinition{}
+	if err := proto.Unmarshal(qr.Rows[0][0].Raw(), sd); err != nil {
+		log.Warningf("Error reading the table definitions of the latest schema version, reading them all again: %v", err)
+		return nil
+	}
+
+	previous := make(map[string]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
+	for _, td := range sd.TableDefinitions {
+		previous[td.Name] = td
+	}
+	for _, table := range ddlStmt.AffectedTables() {
+		delete(previous, table.Name.String())
+	}
+	return previous
+}
+
+func isRename(ddl sqlparser.DDLStatement) bool {
+	switch ddl := ddl.(type) {
+	case *sqlparser.RenameTable:
+		return true
+	case *sqlparser.AlterTable:
+		for _, option := range ddl.AlterOptions {
+			if _, ok := option.(*sqlparser.RenameTableName); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newTableDefinition(st *Table, createStatement string) *tabletmanagerdatapb.TableDefinition {
+	td := &tabletmanagerdatapb.TableDefinition{
+		Name:   st.Name.String(),
+		Schema: createStatement,
+		Type:   tmutils.TableBaseTable,
+		Fields: st.Fields,
+	}
+	for _, field := range st.Fields {
+		td.Columns = append(td.Columns, field.Name)
+	}
+	for _, pk := range st.PKColumns {
+		td.PrimaryKeyColumns = append(td.PrimaryKeyColumns, st.Fields[pk].Name)
+	}
+	return td
+}
+
 func newMinimalTable(st *Table) *binlogdatapb.MinimalTable {
 	table := &binlogdatapb.MinimalTable{
 		Name:   st.Name.String(),
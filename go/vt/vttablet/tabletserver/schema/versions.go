@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	getSchemaVersionBoundaries = "select id, pos, time_updated from _vt.schema_version order by id asc"
+	getSchemaVersionByID       = "select ddl, schemax, table_definitions from _vt.schema_version where id = %d"
+	// getSchemaVersionByIDCompat is used for the rows of a schema_version
+	// table that has not been altered by the tracker yet.
+	getSchemaVersionByIDCompat = "select ddl, schemax from _vt.schema_version where id = %d"
+)
+
+// GetSchemaVersion returns the version of the schema, as saved in
+// _vt.schema_version by the Tracker, that was in effect at the given
+// position. If position is empty, the version in effect at the given
+// timestamp, in seconds since the epoch, is returned. If both are empty, the
+// latest version is returned.
+// The versions are only saved if the tablet tracks schema versions.
+func (se *Engine) GetSchemaVersion(ctx context.Context, position string, timestamp int64) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	var pos mysql.Position
+	if position != "" {
+		var err error
+		if pos, err = mysql.DecodePosition(position); err != nil {
+			return nil, vterrors.Wrapf(err, "invalid position %s", position)
+		}
+	}
+
+	conn, err := se.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.Exec(ctx, getSchemaVersionBoundaries, 10000, false)
+	if err != nil {
+		if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Number() == mysql.ERNoSuchTable {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no schema versions are saved on this tablet, is -track_schema_versions enabled?")
+		}
+		return nil, err
+	}
+
+	idx := -1
+	for i, row := range qr.Rows {
+		switch {
+		case position != "":
+			rowPos, err := mysql.DecodePosition(row[1].ToString())
+			if err != nil {
+				return nil, err
+			}
+			if !pos.AtLeast(rowPos) {
+				continue
+			}
+		case timestamp != 0:
+			timeUpdated, err := evalengine.ToInt64(row[2])
+			if err != nil {
+				return nil, err
+			}
+			if timeUpdated > timestamp {
+				continue
+			}
+		}
+		idx = i
+	}
+	if idx == -1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no schema version found for position %q and timestamp %d", position, timestamp)
+	}
+
+	row := qr.Rows[idx]
+	id, err := evalengine.ToInt64(row[0])
+	if err != nil {
+		return nil, err
+	}
+	timeUpdated, err := evalengine.ToInt64(row[2])
+	if err != nil {
+		return nil, err
+	}
+	resp := &tabletmanagerdatapb.GetSchemaVersionResponse{
+		Id:          id,
+		Position:    row[1].ToString(),
+		TimeUpdated: timeUpdated,
+	}
+	if idx+1 < len(qr.Rows) {
+		resp.EndPosition = qr.Rows[idx+1][1].ToString()
+	}
+
+	qr, err = conn.Exec(ctx, fmt.Sprintf(getSchemaVersionByID, id), 1, false)
+	if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Number() == mysql.ERBadFieldError {
+		qr, err = conn.Exec(ctx, fmt.Sprintf(getSchemaVersionByIDCompat, id), 1, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "schema version %d not found", id)
+	}
+	if resp.Ddl, resp.SchemaDefinition, err = readSchemaVersion(qr.Rows[0]); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// readSchemaVersion returns the ddl and the schema definition of a
+// schema_version row. The full table definitions are only available for the
+// rows saved since they were added to the table; the definitions of older
+// rows are rebuilt from the minimal schema used by vstreamer.
+func readSchemaVersion(row []sqltypes.Value) (string, *tabletmanagerdatapb.SchemaDefinition, error) {
+	ddl := row[0].ToString()
+	sd := &tabletmanagerdatapb.SchemaDefinition{}
+	if len(row) > 2 && !row[2].IsNull() {
+		if err := proto.Unmarshal(row[2].Raw(), sd); err != nil {
+			return "", nil, err
+		}
+		return ddl, sd, nil
+	}
+
+	minimal := &binlogdatapb.MinimalSchema{}
+	if err := proto.Unmarshal(row[1].Raw(), minimal); err != nil {
+		return "", nil, err
+	}
+	for _, table := range minimal.Tables {
+		td := &tabletmanagerdatapb.TableDefinition{
+			Name:   table.Name,
+			Type:   tmutils.TableBaseTable,
+			Fields: table.Fields,
+		}
+		for _, field := range table.Fields {
+			td.Columns = append(td.Columns, field.Name)
+		}
+		for _, pk := range table.PKColumns {
+			if int(pk) < len(table.Fields) {
+				td.PrimaryKeyColumns = append(td.PrimaryKeyColumns, table.Fields[pk].Name)
+			}
+		}
+		sd.TableDefinitions = append(sd.TableDefinitions, td)
+	}
+	return ddl, sd, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestGetSchemaVersion(t *testing.T) {
+	se, db, cancel := getTestSchemaEngine(t)
+	defer cancel()
+	ctx := context.Background()
+
+	gtidPrefix := "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:"
+	db.AddRejectedQuery(getSchemaVersionBoundaries, mysql.NewSQLError(mysql.ERNoSuchTable, mysql.SSUnknownSQLState, "table doesn't exist"))
+	_, err := se.GetSchemaVersion(ctx, "", 0)
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	db.DeleteRejectedQuery(getSchemaVersionBoundaries)
+
+	db.AddQuery(getSchemaVersionBoundaries, sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|pos|time_updated", "int64|varbinary|int64"),
+		"1|"+gtidPrefix+"1-10|100",
+		"2|"+gtidPrefix+"1-20|200",
+	))
+	minimal := getDbSchemaBlob(t, map[string]*binlogdatapb.MinimalTable{
+		"t1": getTable("t1", []string{"id1", "id2"}, []querypb.Type{querypb.Type_INT32, querypb.Type_INT32}, []int64{0}),
+	})
+	// The first version was saved before the table definitions were tracked.
+	db.AddQuery("select ddl, schemax, table_definitions from _vt.schema_version where id = 1", sqltypes.MakeTestResult(sqltypes.MakeTestFields("ddl|schemax|table_definitions", "varbinary|blob|blob"),
+		"|"+minimal+"|null",
+	))
+	definitions, err := proto.Marshal(&tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:   "t1",
+			Schema: "CREATE TABLE `t1` (`id1` int, `id2` varbinary(10), PRIMARY KEY (`id1`))",
+		}},
+	})
+	require.NoError(t, err)
+	db.AddQuery("select ddl, schemax, table_definitions from _vt.schema_version where id = 2", &sqltypes.Result{
+		Fields: sqltypes.MakeTestFields("ddl|schemax|table_definitions", "varbinary|blob|blob"),
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("alter table t1 modify column id2 varbinary(10)"),
+			sqltypes.NewVarBinary(minimal),
+			sqltypes.NewVarBinary(string(definitions)),
+		}},
+	})
+
+	tcases := []struct {
+		name        string
+		position    string
+		timestamp   int64
+		id          int64
+		endPosition string
+		err         string
+	}{{
+		name: "latest",
+		id:   2,
+	}, {
+		name:        "exact position",
+		position:    gtidPrefix + "1-10",
+		id:          1,
+		endPosition: gtidPrefix + "1-20",
+	}, {
+		name:        "position between versions",
+		position:    gtidPrefix + "1-15",
+		id:          1,
+		endPosition: gtidPrefix + "1-20",
+	}, {
+		name:     "position after the last version",
+		position: gtidPrefix + "1-30",
+		id:       2,
+	}, {
+		name:     "position before the first version",
+		position: gtidPrefix + "1-5",
+		err:      "no schema version found",
+	}, {
+		name:        "timestamp",
+		timestamp:   150,
+		id:          1,
+		endPosition: gtidPrefix + "1-20",
+	}, {
+		name:      "timestamp before the first version",
+		timestamp: 50,
+		err:       "no schema version found",
+	}, {
+		name:     "invalid position",
+		position: "foo",
+		err:      "invalid position foo",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			resp, err := se.GetSchemaVersion(ctx, tcase.position, tcase.timestamp)
+			if tcase.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.id, resp.Id)
+			assert.Equal(t, tcase.endPosition, resp.EndPosition)
+		})
+	}
+
+	resp, err := se.GetSchemaVersion(ctx, gtidPrefix+"1-10", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "", resp.Ddl)
+	assert.EqualValues(t, 100, resp.TimeUpdated)
+	require.Len(t, resp.SchemaDefinition.TableDefinitions, 1)
+	assert.Equal(t, []string{"id1", "id2"}, resp.SchemaDefinition.TableDefinitions[0].Columns)
+	assert.Equal(t, []string{"id1"}, resp.SchemaDefinition.TableDefinitions[0].PrimaryKeyColumns)
+
+	resp, err = se.GetSchemaVersion(ctx, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "alter table t1 modify column id2 varbinary(10)", resp.Ddl)
+	require.Len(t, resp.SchemaDefinition.TableDefinitions, 1)
+	assert.Contains(t, resp.SchemaDefinition.TableDefinitions[0].Schema, "CREATE TABLE `t1`")
+}
+
+func TestTrackerSchemaDefinition(t *testing.T) {
+	se, db, cancel := getTestSchemaEngine(t)
+	defer cancel()
+	ctx := context.Background()
+
+	se.SetTableForTests(&Table{
+		Name: sqlparser.NewIdentifierCS("t1"),
+		Fields: []*querypb.Field{
+			{Name: "id", Type: sqltypes.Int64},
+			{Name: "val", Type: sqltypes.VarChar},
+		},
+		PKColumns: []int{0},
+	})
+	db.AddQueryPattern("show create table .*`t1`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Create Table", "varchar|varchar"),
+		"t1|CREATE TABLE `t1` (`id` bigint, `val` varchar(10), PRIMARY KEY (`id`))",
+	))
+
+	tr := NewTracker(se.env, nil, se)
+	conn, err := se.GetConnection(ctx)
+	require.NoError(t, err)
+	defer conn.Recycle()
+	sd, err := tr.schemaDefinition(ctx, conn, se.GetSchema(), "")
+	require.NoError(t, err)
+	require.Len(t, sd.TableDefinitions, 1)
+	td := sd.TableDefinitions[0]
+	assert.Equal(t, "t1", td.Name)
+	assert.Equal(t, "CREATE TABLE `t1` (`id` bigint, `val` varchar(10), PRIMARY KEY (`id`))", td.Schema)
+	assert.Equal(t, []string{"id", "val"}, td.Columns)
+	assert.Equal(t, []string{"id"}, td.PrimaryKeyColumns)
+
+	// Only the tables the ddl touched are read again.
+	se.SetTableForTests(&Table{
+		Name:   sqlparser.NewIdentifierCS("t2"),
+		Fields: []*querypb.Field{{Name: "id", Type: sqltypes.Int64}},
+	})
+	db.AddQueryPattern("show create table .*`t2`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Create Table", "varchar|varchar"),
+		"t2|CREATE TABLE `t2` (`id` bigint)",
+	))
+	previous, err := proto.Marshal(&tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			{Name: "t1", Schema: "CREATE TABLE `t1` (previous)"},
+			{Name: "t2", Schema: "CREATE TABLE `t2` (previous)"},
+		},
+	})
+	require.NoError(t, err)
+	db.AddQuery(getLatestTableDefinitions, sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_definitions", "blob"), string(previous)))
+
+	sd, err = tr.schemaDefinition(ctx, conn, se.GetSchema(), "alter table t2 add column val int")
+	require.NoError(t, err)
+	require.Len(t, sd.TableDefinitions, 2)
+	assert.Equal(t, "CREATE TABLE `t1` (previous)", sd.TableDefinitions[0].Schema)
+	assert.Equal(t, []string{"id", "val"}, sd.TableDefinitions[0].Columns)
+	assert.Equal(t, "CREATE TABLE `t2` (`id` bigint)", sd.TableDefinitions[1].Schema)
+
+	// A rename reads all the tables again.
+	sd, err = tr.schemaDefinition(ctx, conn, se.GetSchema(), "rename table t3 to t2")
+	require.NoError(t, err)
+	require.Len(t, sd.TableDefinitions, 2)
+	assert.Equal(t, "CREATE TABLE `t1` (`id` bigint, `val` varchar(10), PRIMARY KEY (`id`))", sd.TableDefinitions[0].Schema)
+}
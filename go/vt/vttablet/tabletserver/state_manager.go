@@ -93,6 +93,8 @@ type stateManager struct {
 	transitionErr  error
 
 	requests sync.WaitGroup
+	// inFlight counts the requests that are registered in requests.
+	inFlight sync2.AtomicInt64
 
 	// QueryList does not have an Open or Close.
 	statelessql *QueryList
@@ -115,6 +117,9 @@ type stateManager struct {
 	ddle        onlineDDLExecutor
 	throttler   lagThrottler
 	tableGC     tableGarbageCollector
+	checksum    tableChecksummer
+	analyzer    tableAnalyzer
+	ttlPurger   rowPurger
 
 	// hcticks starts on initialiazation and runs forever.
 	hcticks *timer.Timer
@@ -180,6 +185,24 @@ type (
 		Open() error
 		Close()
 	}
+
+	tableChecksummer interface {
+		MakePrimary()
+		MakeNonPrimary()
+		Close()
+		Divergence() error
+		HealthError() error
+	}
+
+	tableAnalyzer interface {
+		Open()
+		Close()
+	}
+
+	rowPurger interface {
+		Open()
+		Close()
+	}
 )
 
 // Init performs the second phase of initialization.
@@ -364,14 +387,22 @@ func (sm *stateManager) StartRequest(ctx context.Context, target *querypb.Target
 		return err
 	}
 	sm.requests.Add(1)
+	sm.inFlight.Add(1)
 	return nil
 }
 
 // EndRequest unregisters the current request (a waitgroup) as done.
 func (sm *stateManager) EndRequest() {
+	sm.inFlight.Add(-1)
 	sm.requests.Done()
 }
 
+// InFlightRequests returns the number of requests that were started
+// and not ended yet.
+func (sm *stateManager) InFlightRequests() int64 {
+	return sm.inFlight.Get()
+}
+
 // VerifyTarget allows requests to be executed even in non-serving state.
 // Such requests will get terminated without wait on shutdown.
 func (sm *stateManager) VerifyTarget(ctx context.Context, target *querypb.Target) error {
@@ -420,7 +451,10 @@ func (sm *stateManager) servePrimary() error {
 	sm.messager.Open()
 	sm.throttler.Open()
 	sm.tableGC.Open()
+	sm.analyzer.Open()
+	sm.ttlPurger.Open()
 	sm.ddle.Open()
+	sm.checksum.MakePrimary()
 	sm.setState(topodatapb.TabletType_PRIMARY, StateServing)
 	return nil
 }
@@ -447,6 +481,8 @@ func (sm *stateManager) serveNonPrimary(wantTabletType topodatapb.TabletType) er
 
 	sm.ddle.Close()
 	sm.tableGC.Close()
+	sm.analyzer.Close()
+	sm.ttlPurger.Close()
 	sm.messager.Close()
 	sm.tracker.Close()
 	sm.se.MakeNonPrimary()
@@ -459,6 +495,7 @@ func (sm *stateManager) serveNonPrimary(wantTabletType topodatapb.TabletType) er
 	sm.rt.MakeNonPrimary()
 	sm.watcher.Open()
 	sm.throttler.Open()
+	sm.checksum.MakeNonPrimary()
 	sm.setState(wantTabletType, StateServing)
 	return nil
 }
@@ -498,11 +535,17 @@ func (sm *stateManager) unserveCommon() {
 	log.Infof("Finished execution of handleShutdownGracePeriod")
 	defer cancel()
 
-	log.Infof("Started online ddl executor close")
+	log.Infof("Started table checksum close")
+	sm.checksum.Close()
+	log.Infof("Finished table checksum close. Started online ddl executor close")
 	sm.ddle.Close()
 	log.Infof("Finished online ddl executor close. Started table garbage collector close")
 	sm.tableGC.Close()
-	log.Infof("Finished table garbage collector close. Started lag throttler close")
+	log.Infof("Finished table garbage collector close. Started table analyzer close")
+	sm.analyzer.Close()
+	log.Infof("Finished table analyzer close. Started ttl purger close")
+	sm.ttlPurger.Close()
+	log.Infof("Finished ttl purger close. Started lag throttler close")
 	sm.throttler.Close()
 	log.Infof("Finished lag throttler close. Started messager close")
 	sm.messager.Close()
@@ -631,10 +674,22 @@ func (sm *stateManager) Broadcast() {
 
 func (sm *stateManager) refreshReplHealthLocked() (time.Duration, error) {
 	if sm.target.TabletType == topodatapb.TabletType_PRIMARY {
+		// The replication tracker only fails on a primary if its mysqld is
+		// unmanaged and read-only.
+		if _, err := sm.rt.Status(); err != nil {
+			if sm.replHealthy {
+				log.Infof("Going unhealthy due to replication error: %v", err)
+			}
+			sm.replHealthy = false
+			return 0, err
+		}
 		sm.replHealthy = true
 		return 0, nil
 	}
 	lag, err := sm.rt.Status()
+	if err == nil {
+		err = sm.checksum.HealthError()
+	}
 	if err != nil {
 		if sm.replHealthy {
 			log.Infof("Going unhealthy due to replication error: %v", err)
@@ -733,6 +788,13 @@ func (sm *stateManager) AppendDetails(details []*kv) []*kv {
 			Value: "ON",
 		})
 	}
+	if err := sm.checksum.Divergence(); err != nil && sm.target.TabletType != topodatapb.TabletType_PRIMARY {
+		details = append(details, &kv{
+			Key:   "Table Checksums",
+			Class: unhappyClass,
+			Value: err.Error(),
+		})
+	}
 	if len(sm.alsoAllow) != 0 {
 		details = append(details, &kv{
 			Key:   "Also Serving",
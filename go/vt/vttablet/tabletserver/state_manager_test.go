@@ -88,7 +88,10 @@ func TestStateManagerServePrimary(t *testing.T) {
 	verifySubcomponent(t, 9, sm.messager, testStateOpen)
 	verifySubcomponent(t, 10, sm.throttler, testStateOpen)
 	verifySubcomponent(t, 11, sm.tableGC, testStateOpen)
-	verifySubcomponent(t, 12, sm.ddle, testStateOpen)
+	verifySubcomponent(t, 12, sm.analyzer, testStateOpen)
+	verifySubcomponent(t, 13, sm.ttlPurger, testStateOpen)
+	verifySubcomponent(t, 14, sm.ddle, testStateOpen)
+	verifySubcomponent(t, 15, sm.checksum, testStatePrimary)
 
 	assert.False(t, sm.se.(*testSchemaEngine).nonPrimary)
 	assert.True(t, sm.se.(*testSchemaEngine).ensureCalled)
@@ -105,18 +108,21 @@ func TestStateManagerServeNonPrimary(t *testing.T) {
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
 	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.messager, testStateClosed)
-	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 3, sm.analyzer, testStateClosed)
+	verifySubcomponent(t, 4, sm.ttlPurger, testStateClosed)
+	verifySubcomponent(t, 5, sm.messager, testStateClosed)
+	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 5, sm.se, testStateOpen)
-	verifySubcomponent(t, 6, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 7, sm.qe, testStateOpen)
-	verifySubcomponent(t, 8, sm.txThrottler, testStateOpen)
-	verifySubcomponent(t, 9, sm.te, testStateNonPrimary)
-	verifySubcomponent(t, 10, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 11, sm.watcher, testStateOpen)
-	verifySubcomponent(t, 12, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 7, sm.se, testStateOpen)
+	verifySubcomponent(t, 8, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 9, sm.qe, testStateOpen)
+	verifySubcomponent(t, 10, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 11, sm.te, testStateNonPrimary)
+	verifySubcomponent(t, 12, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 13, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 14, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 15, sm.checksum, testStateNonPrimary)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
@@ -128,20 +134,23 @@ func TestStateManagerUnservePrimary(t *testing.T) {
 	err := sm.SetServingType(topodatapb.TabletType_PRIMARY, testNow, StateNotServing, "")
 	require.NoError(t, err)
 
-	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
+	verifySubcomponent(t, 1, sm.checksum, testStateClosed)
+	verifySubcomponent(t, 2, sm.ddle, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.analyzer, testStateClosed)
+	verifySubcomponent(t, 5, sm.ttlPurger, testStateClosed)
+	verifySubcomponent(t, 6, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 7, sm.messager, testStateClosed)
+	verifySubcomponent(t, 8, sm.te, testStateClosed)
 
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
-	verifySubcomponent(t, 7, sm.watcher, testStateClosed)
-	verifySubcomponent(t, 8, sm.se, testStateOpen)
-	verifySubcomponent(t, 9, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 10, sm.qe, testStateOpen)
-	verifySubcomponent(t, 11, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 9, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 10, sm.watcher, testStateClosed)
+	verifySubcomponent(t, 11, sm.se, testStateOpen)
+	verifySubcomponent(t, 12, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 13, sm.qe, testStateOpen)
+	verifySubcomponent(t, 14, sm.txThrottler, testStateOpen)
 
-	verifySubcomponent(t, 12, sm.rt, testStatePrimary)
+	verifySubcomponent(t, 15, sm.rt, testStatePrimary)
 
 	assert.Equal(t, topodatapb.TabletType_PRIMARY, sm.target.TabletType)
 	assert.Equal(t, StateNotServing, sm.state)
@@ -153,22 +162,25 @@ func TestStateManagerUnserveNonPrimary(t *testing.T) {
 	err := sm.SetServingType(topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
 	require.NoError(t, err)
 
-	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
+	verifySubcomponent(t, 1, sm.checksum, testStateClosed)
+	verifySubcomponent(t, 2, sm.ddle, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.analyzer, testStateClosed)
+	verifySubcomponent(t, 5, sm.ttlPurger, testStateClosed)
+	verifySubcomponent(t, 6, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 7, sm.messager, testStateClosed)
+	verifySubcomponent(t, 8, sm.te, testStateClosed)
 
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 9, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 7, sm.se, testStateOpen)
-	verifySubcomponent(t, 8, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 9, sm.qe, testStateOpen)
-	verifySubcomponent(t, 10, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 10, sm.se, testStateOpen)
+	verifySubcomponent(t, 11, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 12, sm.qe, testStateOpen)
+	verifySubcomponent(t, 13, sm.txThrottler, testStateOpen)
 
-	verifySubcomponent(t, 11, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 12, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 14, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 15, sm.watcher, testStateOpen)
 
 	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.target.TabletType)
 	assert.Equal(t, StateNotServing, sm.state)
@@ -180,19 +192,22 @@ func TestStateManagerClose(t *testing.T) {
 	err := sm.SetServingType(topodatapb.TabletType_RDONLY, testNow, StateNotConnected, "")
 	require.NoError(t, err)
 
-	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
-
-	verifySubcomponent(t, 7, sm.txThrottler, testStateClosed)
-	verifySubcomponent(t, 8, sm.qe, testStateClosed)
-	verifySubcomponent(t, 9, sm.watcher, testStateClosed)
-	verifySubcomponent(t, 10, sm.vstreamer, testStateClosed)
-	verifySubcomponent(t, 11, sm.rt, testStateClosed)
-	verifySubcomponent(t, 12, sm.se, testStateClosed)
+	verifySubcomponent(t, 1, sm.checksum, testStateClosed)
+	verifySubcomponent(t, 2, sm.ddle, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.analyzer, testStateClosed)
+	verifySubcomponent(t, 5, sm.ttlPurger, testStateClosed)
+	verifySubcomponent(t, 6, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 7, sm.messager, testStateClosed)
+	verifySubcomponent(t, 8, sm.te, testStateClosed)
+	verifySubcomponent(t, 9, sm.tracker, testStateClosed)
+
+	verifySubcomponent(t, 10, sm.txThrottler, testStateClosed)
+	verifySubcomponent(t, 11, sm.qe, testStateClosed)
+	verifySubcomponent(t, 12, sm.watcher, testStateClosed)
+	verifySubcomponent(t, 13, sm.vstreamer, testStateClosed)
+	verifySubcomponent(t, 14, sm.rt, testStateClosed)
+	verifySubcomponent(t, 15, sm.se, testStateClosed)
 
 	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.target.TabletType)
 	assert.Equal(t, StateNotConnected, sm.state)
@@ -296,18 +311,21 @@ func TestStateManagerSetServingTypeNoChange(t *testing.T) {
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
 	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.messager, testStateClosed)
-	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 3, sm.analyzer, testStateClosed)
+	verifySubcomponent(t, 4, sm.ttlPurger, testStateClosed)
+	verifySubcomponent(t, 5, sm.messager, testStateClosed)
+	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 5, sm.se, testStateOpen)
-	verifySubcomponent(t, 6, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 7, sm.qe, testStateOpen)
-	verifySubcomponent(t, 8, sm.txThrottler, testStateOpen)
-	verifySubcomponent(t, 9, sm.te, testStateNonPrimary)
-	verifySubcomponent(t, 10, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 11, sm.watcher, testStateOpen)
-	verifySubcomponent(t, 12, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 7, sm.se, testStateOpen)
+	verifySubcomponent(t, 8, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 9, sm.qe, testStateOpen)
+	verifySubcomponent(t, 10, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 11, sm.te, testStateNonPrimary)
+	verifySubcomponent(t, 12, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 13, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 14, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 15, sm.checksum, testStateNonPrimary)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
@@ -648,6 +666,15 @@ func TestRefreshReplHealthLocked(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, sm.replHealthy)
 
+	// A primary is unhealthy if the replication tracker fails, as it does
+	// for a read-only unmanaged mysqld.
+	rt.err = errors.New("err")
+	lag, err = sm.refreshReplHealthLocked()
+	assert.Equal(t, time.Duration(0), lag)
+	assert.Error(t, err)
+	assert.False(t, sm.replHealthy)
+	rt.err = nil
+
 	sm.target.TabletType = topodatapb.TabletType_REPLICA
 	sm.replHealthy = false
 	lag, err = sm.refreshReplHealthLocked()
@@ -669,6 +696,14 @@ func TestRefreshReplHealthLocked(t *testing.T) {
 	assert.Equal(t, 3*time.Hour, lag)
 	assert.NoError(t, err)
 	assert.False(t, sm.replHealthy)
+
+	// Diverging table checksums make the replica unhealthy when reported in its health.
+	rt.lag = 1 * time.Second
+	sm.checksum.(*testTableChecksum).divergence = errors.New("table checksums diverge from the primary")
+	sm.replHealthy = true
+	_, err = sm.refreshReplHealthLocked()
+	assert.EqualError(t, err, "table checksums diverge from the primary")
+	assert.False(t, sm.replHealthy)
 }
 
 func verifySubcomponent(t *testing.T, order int64, component any, state testState) {
@@ -698,9 +733,12 @@ func newTestStateManager(t *testing.T) *stateManager {
 		ddle:        &testOnlineDDLExecutor{},
 		throttler:   &testLagThrottler{},
 		tableGC:     &testTableGC{},
+		checksum:    &testTableChecksum{},
+		analyzer:    &testTableAnalyzer{},
+		ttlPurger:   &testRowPurger{},
 	}
 	sm.Init(env, &querypb.Target{})
-	sm.hs.InitDBConfig(&querypb.Target{}, fakesqldb.New(t).ConnParams())
+	sm.hs.InitDBConfig(&querypb.Target{}, fakesqldb.New(t).ConnParams(), nil)
 	log.Infof("returning sm: %p", sm)
 	return sm
 }
@@ -917,3 +955,59 @@ func (te *testTableGC) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
+
+type testTableChecksum struct {
+	testOrderState
+	divergence error
+}
+
+func (te *testTableChecksum) MakePrimary() {
+	te.order = order.Add(1)
+	te.state = testStatePrimary
+}
+
+func (te *testTableChecksum) MakeNonPrimary() {
+	te.order = order.Add(1)
+	te.state = testStateNonPrimary
+}
+
+func (te *testTableChecksum) Close() {
+	te.order = order.Add(1)
+	te.state = testStateClosed
+}
+
+func (te *testTableChecksum) Divergence() error {
+	return te.divergence
+}
+
+func (te *testTableChecksum) HealthError() error {
+	return te.divergence
+}
+
+type testTableAnalyzer struct {
+	testOrderState
+}
+
+func (te *testTableAnalyzer) Open() {
+	te.order = order.Add(1)
+	te.state = testStateOpen
+}
+
+func (te *testTableAnalyzer) Close() {
+	te.order = order.Add(1)
+	te.state = testStateClosed
+}
+
+type testRowPurger struct {
+	testOrderState
+}
+
+func (te *testRowPurger) Open() {
+	te.order = order.Add(1)
+	te.state = testStateOpen
+}
+
+func (te *testRowPurger) Close() {
+	te.order = order.Add(1)
+	te.state = testStateClosed
+}
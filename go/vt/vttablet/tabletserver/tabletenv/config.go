@@ -109,6 +109,7 @@ func init() {
 	flag.BoolVar(&currentConfig.QueryCacheLFU, "queryserver-config-query-cache-lfu", defaultConfig.QueryCacheLFU, "query server cache algorithm. when set to true, a new cache algorithm based on a TinyLFU admission policy will be used to improve cache behavior and prevent pollution from sparse queries")
 	SecondsVar(&currentConfig.SchemaReloadIntervalSeconds, "queryserver-config-schema-reload-time", defaultConfig.SchemaReloadIntervalSeconds, "query server schema reload time, how often vttablet reloads schemas from underlying MySQL instance in seconds. vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.")
 	SecondsVar(&currentConfig.SignalSchemaChangeReloadIntervalSeconds, "queryserver-config-schema-change-signal-interval", defaultConfig.SignalSchemaChangeReloadIntervalSeconds, "query server schema change signal interval defines at which interval the query server shall send schema updates to vtgate.")
+	flag.BoolVar(&currentConfig.Unmanaged, "unmanaged", defaultConfig.Unmanaged, "Indicates an unmanaged tablet, whose mysqld is an external database administered outside of Vitess, such as a cloud-managed database. The tablet then requires the connection parameters of the database, reports itself unhealthy if the read-only state of the database does not match the tablet type, always signals schema changes, and rejects the actions that change the replication, the read-only state or the data of the database")
 	flag.BoolVar(&currentConfig.SignalWhenSchemaChange, "queryserver-config-schema-change-signal", defaultConfig.SignalWhenSchemaChange, "query server schema signal, will signal connected vtgates that schema has changed whenever this is detected. VTGates will need to have -schema_change_signal enabled for this to work")
 	SecondsVar(&currentConfig.Oltp.QueryTimeoutSeconds, "queryserver-config-query-timeout", defaultConfig.Oltp.QueryTimeoutSeconds, "query server query timeout (in seconds), this is the query timeout in vttablet side. If a query takes more than this timeout, it will be killed.")
 	SecondsVar(&currentConfig.OltpReadPool.TimeoutSeconds, "queryserver-config-query-pool-timeout", defaultConfig.OltpReadPool.TimeoutSeconds, "query server query pool timeout (in seconds), it is how long vttablet waits for a connection from the query pool. If set to 0 (default) then the overall query timeout is used instead.")
@@ -118,6 +119,7 @@ func init() {
 	flag.IntVar(&currentConfig.OltpReadPool.MaxWaiters, "queryserver-config-query-pool-waiter-cap", defaultConfig.OltpReadPool.MaxWaiters, "query server query pool waiter limit, this is the maximum number of queries that can be queued waiting to get a connection")
 	flag.IntVar(&currentConfig.OlapReadPool.MaxWaiters, "queryserver-config-stream-pool-waiter-cap", defaultConfig.OlapReadPool.MaxWaiters, "query server stream pool waiter limit, this is the maximum number of streaming queries that can be queued waiting to get a connection")
 	flag.IntVar(&currentConfig.TxPool.MaxWaiters, "queryserver-config-txpool-waiter-cap", defaultConfig.TxPool.MaxWaiters, "query server transaction pool waiter limit, this is the maximum number of transactions that can be queued waiting to get a connection")
+	flag.BoolVar(&currentConfig.EnablePriorityScheduling, "queryserver-config-priority-scheduling", defaultConfig.EnablePriorityScheduling, "query server priority scheduling, when enabled the queries waiting for a connection of an exhausted pool are admitted by priority (set with the PRIORITY comment directive or the priority session variable) rather than in order of arrival")
 	// tableacl related configurations.
 	flag.BoolVar(&currentConfig.StrictTableACL, "queryserver-config-strict-table-acl", defaultConfig.StrictTableACL, "only allow queries that pass table acl checks")
 	flag.BoolVar(&currentConfig.EnableTableACLDryRun, "queryserver-config-enable-table-acl-dry-run", defaultConfig.EnableTableACLDryRun, "If this flag is enabled, tabletserver will emit monitoring metrics and let the request pass regardless of table acl check results")
@@ -131,6 +133,7 @@ func init() {
 	flag.BoolVar(&currentConfig.TwoPCEnable, "twopc_enable", defaultConfig.TwoPCEnable, "if the flag is on, 2pc is enabled. Other 2pc flags must be supplied.")
 	flag.StringVar(&currentConfig.TwoPCCoordinatorAddress, "twopc_coordinator_address", defaultConfig.TwoPCCoordinatorAddress, "address of the (VTGate) process(es) that will be used to notify of abandoned transactions.")
 	SecondsVar(&currentConfig.TwoPCAbandonAge, "twopc_abandon_age", defaultConfig.TwoPCAbandonAge, "time in seconds. Any unresolved transaction older than this time will be sent to the coordinator to be resolved.")
+	SecondsVar(&currentConfig.TwoPCAutoRollbackAge, "twopc_auto_rollback_age", defaultConfig.TwoPCAutoRollbackAge, "time in seconds. Any prepared transaction older than this time, for which the metadata manager has no decision, will be rolled back. If set to 0 (default), such transactions are only reported by the Unresolved metric.")
 	flagutil.DualFormatBoolVar(&currentConfig.EnableTxThrottler, "enable_tx_throttler", defaultConfig.EnableTxThrottler, "If true replication-lag-based throttling on transactions will be enabled.")
 	flagutil.DualFormatStringVar(&currentConfig.TxThrottlerConfig, "tx_throttler_config", defaultConfig.TxThrottlerConfig, "The configuration of the transaction throttler as a text formatted throttlerdata.Configuration protocol buffer message")
 	flagutil.DualFormatStringListVar(&currentConfig.TxThrottlerHealthCheckCells, "tx_throttler_healthcheck_cells", defaultConfig.TxThrottlerHealthCheckCells, "A comma-separated list of cells. Only tabletservers running in these cells will be monitored for replication lag by the transaction throttler.")
@@ -141,6 +144,10 @@ func init() {
 	flag.IntVar(&currentConfig.HotRowProtection.MaxGlobalQueueSize, "hot_row_protection_max_global_queue_size", defaultConfig.HotRowProtection.MaxGlobalQueueSize, "Global queue limit across all row (ranges). Useful to prevent that the queue can grow unbounded.")
 	flag.IntVar(&currentConfig.HotRowProtection.MaxConcurrency, "hot_row_protection_concurrent_transactions", defaultConfig.HotRowProtection.MaxConcurrency, "Number of concurrent transactions let through to the txpool/MySQL for the same hot row. Should be > 1 to have enough 'ready' transactions in MySQL and benefit from a pipelining effect.")
 
+	flag.BoolVar(&currentConfig.HotRowDetection.Enable, "enable_hot_row_detection", defaultConfig.HotRowDetection.Enable, "If true, vttablet counts how often queries select, update or delete each row by its primary key, and reports the most accessed rows through the GetHotRows tablet RPC and at /debug/hotrows, in place of the rows queued by the hot row protection.")
+	flag.DurationVar(&currentConfig.HotRowDetection.Window, "hot_row_detection_window", defaultConfig.HotRowDetection.Window, "The sliding window over which the hot row detection counts the accesses to rows.")
+	flag.IntVar(&currentConfig.HotRowDetection.Capacity, "hot_row_detection_capacity", defaultConfig.HotRowDetection.Capacity, "The maximum number of rows of each table that the hot row detection counts the accesses to at a time. The least accessed rows are evicted first.")
+
 	flag.BoolVar(&currentConfig.EnableTransactionLimit, "enable_transaction_limit", defaultConfig.EnableTransactionLimit, "If true, limit on number of transactions open at the same time will be enforced for all users. User trying to open a new transaction after exhausting their limit will receive an error immediately, regardless of whether there are available slots or not.")
 	flag.BoolVar(&currentConfig.EnableTransactionLimitDryRun, "enable_transaction_limit_dry_run", defaultConfig.EnableTransactionLimitDryRun, "If true, limit on number of transactions open at the same time will be tracked for all users, but not enforced.")
 	flag.Float64Var(&currentConfig.TransactionLimitPerUser, "transaction_limit_per_user", defaultConfig.TransactionLimitPerUser, "Maximum number of transactions a single user is allowed to use at any time, represented as fraction of -transaction_cap.")
@@ -153,6 +160,8 @@ func init() {
 	flag.DurationVar(&heartbeatInterval, "heartbeat_interval", 1*time.Second, "How frequently to read and write replication heartbeat.")
 	flag.DurationVar(&heartbeatOnDemandDuration, "heartbeat_on_demand_duration", 0, "If non-zero, heartbeats are only written upon consumer request, and only run for up to given duration following the request. Frequent requests can keep the heartbeat running consistently; when requests are infrequent heartbeat may completely stop between requests")
 	flagutil.DualFormatBoolVar(&currentConfig.EnableLagThrottler, "enable_lag_throttler", defaultConfig.EnableLagThrottler, "If true, vttablet will run a throttler service, and will implicitly enable heartbeats")
+	flag.DurationVar(&currentConfig.ReplicationTracker.LagHistoryResolution, "replication_lag_history_resolution", defaultConfig.ReplicationTracker.LagHistoryResolution, "How frequently a replica samples its replication lag into its replication lag history, which is reported through the GetReplicationLagHistory tablet RPC and at /debug/replication_lag_history. Requires --heartbeat_enable or --enable_replication_reporter.")
+	flag.DurationVar(&currentConfig.ReplicationTracker.LagHistoryRetention, "replication_lag_history_retention", defaultConfig.ReplicationTracker.LagHistoryRetention, "How long the replication lag history keeps the samples of the replication lag. The history is disabled if 0.")
 
 	flag.BoolVar(&currentConfig.EnforceStrictTransTables, "enforce_strict_trans_tables", defaultConfig.EnforceStrictTransTables, "If true, vttablet requires MySQL to run with STRICT_TRANS_TABLES or STRICT_ALL_TABLES on. It is recommended to not turn this flag off. Otherwise MySQL may alter your supplied values before saving them to the database.")
 	flagutil.DualFormatBoolVar(&enableConsolidator, "enable_consolidator", true, "This option enables the query consolidator.")
@@ -275,6 +284,7 @@ type TabletConfig struct {
 	MessagePostponeParallelism              int     `json:"messagePostponeParallelism,omitempty"`
 	CacheResultFields                       bool    `json:"cacheResultFields,omitempty"`
 	SignalWhenSchemaChange                  bool    `json:"signalWhenSchemaChange,omitempty"`
+	Unmanaged                               bool    `json:"unmanaged,omitempty"`
 
 	ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
 
@@ -285,6 +295,7 @@ type TabletConfig struct {
 	TwoPCEnable             bool    `json:"-"`
 	TwoPCCoordinatorAddress string  `json:"-"`
 	TwoPCAbandonAge         Seconds `json:"-"`
+	TwoPCAutoRollbackAge    Seconds `json:"-"`
 
 	EnableTxThrottler           bool     `json:"-"`
 	TxThrottlerConfig           string   `json:"-"`
@@ -297,6 +308,12 @@ type TabletConfig struct {
 	EnforceStrictTransTables bool `json:"-"`
 	EnableOnlineDDL          bool `json:"-"`
 
+	// EnablePriorityScheduling orders the waiters for the connections of
+	// exhausted pools by the priority of their queries.
+	EnablePriorityScheduling bool `json:"-"`
+
+	HotRowDetection HotRowDetectionConfig `json:"-"`
+
 	RowStreamer RowStreamerConfig `json:"rowStreamer,omitempty"`
 }
 
@@ -326,6 +343,13 @@ type HotRowProtectionConfig struct {
 	MaxConcurrency     int    `json:"maxConcurrency,omitempty"`
 }
 
+// HotRowDetectionConfig contains the config for hot row detection.
+type HotRowDetectionConfig struct {
+	Enable   bool
+	Window   time.Duration
+	Capacity int
+}
+
 // HealthcheckConfig contains the config for healthcheck.
 type HealthcheckConfig struct {
 	IntervalSeconds           Seconds `json:"intervalSeconds,omitempty"`
@@ -346,6 +370,10 @@ type ReplicationTrackerConfig struct {
 	Mode                     string  `json:"mode,omitempty"`
 	HeartbeatIntervalSeconds Seconds `json:"heartbeatIntervalSeconds,omitempty"`
 	HeartbeatOnDemandSeconds Seconds `json:"heartbeatOnDemandSeconds,omitempty"`
+	// LagHistoryResolution is the interval between the samples of the
+	// replication lag history, which keeps them for LagHistoryRetention.
+	LagHistoryResolution time.Duration `json:"-"`
+	LagHistoryRetention  time.Duration `json:"-"`
 }
 
 // TransactionLimitConfig captures configuration of transaction pool slots
@@ -403,6 +431,22 @@ func (c *TabletConfig) Verify() error {
 	if v := c.HotRowProtection.MaxConcurrency; v <= 0 {
 		return fmt.Errorf("-hot_row_protection_concurrent_transactions must be > 0 (specified value: %v)", v)
 	}
+	if c.HotRowDetection.Enable {
+		if v := c.HotRowDetection.Window; v <= 0 {
+			return fmt.Errorf("-hot_row_detection_window must be > 0 (specified value: %v)", v)
+		}
+		if v := c.HotRowDetection.Capacity; v <= 0 {
+			return fmt.Errorf("-hot_row_detection_capacity must be > 0 (specified value: %v)", v)
+		}
+	}
+	if c.ReplicationTracker.LagHistoryRetention > 0 {
+		if v := c.ReplicationTracker.LagHistoryResolution; v <= 0 {
+			return fmt.Errorf("-replication_lag_history_resolution must be > 0 (specified value: %v)", v)
+		}
+	}
+	if c.Unmanaged && (c.DB == nil || !c.DB.HasGlobalSettings()) {
+		return errors.New("-unmanaged requires the connection parameters of the external database: -db_host or -db_socket")
+	}
 	return nil
 }
 
@@ -467,6 +511,8 @@ var defaultConfig = TabletConfig{
 	ReplicationTracker: ReplicationTrackerConfig{
 		Mode:                     Disable,
 		HeartbeatIntervalSeconds: 0.25,
+		LagHistoryResolution:     10 * time.Second,
+		LagHistoryRetention:      time.Hour,
 	},
 	HotRowProtection: HotRowProtectionConfig{
 		Mode: Disable,
@@ -507,6 +553,11 @@ var defaultConfig = TabletConfig{
 	EnforceStrictTransTables: true,
 	EnableOnlineDDL:          true,
 
+	HotRowDetection: HotRowDetectionConfig{
+		Window:   time.Minute,
+		Capacity: 1000,
+	},
+
 	RowStreamer: RowStreamerConfig{
 		MaxInnoDBTrxHistLen: 1000000,
 		MaxMySQLReplLagSecs: 43200,
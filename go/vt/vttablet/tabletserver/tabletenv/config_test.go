@@ -212,6 +212,14 @@ func TestFlags(t *testing.T) {
 			MaxGlobalQueueSize: 1000,
 			MaxConcurrency:     5,
 		},
+		HotRowDetection: HotRowDetectionConfig{
+			Window:   time.Minute,
+			Capacity: 1000,
+		},
+		ReplicationTracker: ReplicationTrackerConfig{
+			LagHistoryResolution: 10 * time.Second,
+			LagHistoryRetention:  time.Hour,
+		},
 		StreamBufferSize:                        32768,
 		QueryCacheSize:                          int(cache.DefaultConfig.MaxEntries),
 		QueryCacheMemory:                        cache.DefaultConfig.MaxMemoryUsage,
@@ -58,7 +58,12 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/analyze"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/binlogserver"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/checksum"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
@@ -66,6 +71,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/ttl"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txserializer"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txthrottler"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/vstreamer"
@@ -117,6 +123,10 @@ type TabletServer struct {
 	hs           *healthStreamer
 	lagThrottler *throttle.Throttler
 	tableGC      *gc.TableGC
+	checksum     *checksum.Engine
+	analyzer     *analyze.Engine
+	ttlPurger    *ttl.Engine
+	binlogServer *binlogserver.Server
 
 	// sm manages state transitions.
 	sm                *stateManager
@@ -124,6 +134,12 @@ type TabletServer struct {
 
 	// alias is used for identifying this tabletserver in healthcheck responses.
 	alias *topodatapb.TabletAlias
+
+	// writesFrozen is true while the tablet rejects the queries that write,
+	// until writesFrozenUntil if it is not zero.
+	writesFrozenMu    sync.Mutex
+	writesFrozen      bool
+	writesFrozenUntil time.Time
 }
 
 var _ queryservice.QueryService = (*TabletServer)(nil)
@@ -185,6 +201,10 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 
 	tsv.onlineDDLExecutor = onlineddl.NewExecutor(tsv, alias, topoServer, tsv.lagThrottler, tabletTypeFunc, tsv.onlineDDLExecutorToggleTableBuffer)
 	tsv.tableGC = gc.NewTableGC(tsv, topoServer, tabletTypeFunc, tsv.lagThrottler)
+	tsv.checksum = checksum.NewEngine(tsv, tsv.se, tsv.lagThrottler)
+	tsv.analyzer = analyze.NewEngine(tsv, tsv.se, tsv.lagThrottler)
+	tsv.ttlPurger = ttl.NewEngine(tsv, topoServer, tsv.lagThrottler)
+	tsv.binlogServer = binlogserver.NewServer(tsv, tsv.vstreamer)
 
 	tsv.sm = &stateManager{
 		statelessql: tsv.statelessql,
@@ -203,6 +223,9 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 		ddle:        tsv.onlineDDLExecutor,
 		throttler:   tsv.lagThrottler,
 		tableGC:     tsv.tableGC,
+		checksum:    tsv.checksum,
+		analyzer:    tsv.analyzer,
+		ttlPurger:   tsv.ttlPurger,
 	}
 
 	tsv.exporter.NewGaugeFunc("TabletState", "Tablet server state", func() int64 { return int64(tsv.sm.State()) })
@@ -223,6 +246,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
 	tsv.registerDebugEnvHandler()
+	tsv.registerRuntimeFlags()
 
 	return tsv
 }
@@ -261,11 +285,14 @@ func (tsv *TabletServer) InitDBConfig(target *querypb.Target, dbcfgs *dbconfigs.
 	tsv.rt.InitDBConfig(target, mysqld)
 	tsv.txThrottler.InitDBConfig(target)
 	tsv.vstreamer.InitDBConfig(target.Keyspace, target.Shard)
-	tsv.hs.InitDBConfig(target, tsv.config.DB.DbaWithDB())
+	tsv.hs.InitDBConfig(target, tsv.config.DB.DbaWithDB(), mysqld)
 	tsv.onlineDDLExecutor.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
 	tsv.lagThrottler.InitDBConfig(target.Keyspace, target.Shard)
 	tsv.tableGC.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
-	return nil
+	tsv.checksum.InitDBConfig(dbcfgs.DBName)
+	tsv.analyzer.InitDBConfig(dbcfgs.DBName)
+	tsv.ttlPurger.InitDBConfig(target.Keyspace, dbcfgs.DBName)
+	return tsv.binlogServer.Open()
 }
 
 // Register prepares TabletServer for serving by calling
@@ -384,6 +411,7 @@ func (tsv *TabletServer) StartService(target *querypb.Target, dbcfgs *dbconfigs.
 // should be called before process termination, or if MySQL is unreachable.
 // Under normal circumstances, SetServingType should be called.
 func (tsv *TabletServer) StopService() {
+	tsv.binlogServer.Close()
 	tsv.sm.StopService()
 }
 
@@ -460,6 +488,16 @@ func (tsv *TabletServer) LagThrottler() *throttle.Throttler {
 	return tsv.lagThrottler
 }
 
+// HotRows returns the hot row detector part of TabletServer.
+func (tsv *TabletServer) HotRows() *hotrows.Detector {
+	return tsv.qe.hotRows
+}
+
+// ReplicationLagHistory returns the replication lag history of TabletServer.
+func (tsv *TabletServer) ReplicationLagHistory() *repltracker.LagHistory {
+	return tsv.rt.LagHistory()
+}
+
 // TableGC returns the tableDropper part of TabletServer.
 func (tsv *TabletServer) TableGC() *gc.TableGC {
 	return tsv.tableGC
@@ -1006,6 +1044,7 @@ func (tsv *TabletServer) MessageAck(ctx context.Context, target *querypb.Target,
 	if err != nil {
 		return 0, err
 	}
+	tsv.messager.Acked(name, sids)
 	messager.MessageStats.Add([]string{name, "Acked"}, count)
 	return count, nil
 }
@@ -1028,16 +1067,41 @@ func (tsv *TabletServer) PurgeMessages(ctx context.Context, target *querypb.Targ
 	})
 }
 
-func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, queryGenerator func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
+// DeadLetterMessages copies the list of messages for a given message table to its
+// dead letter table, if it has one, and acks them. Both happen in a single transaction.
+// It returns the number of messages successfully acked.
+func (tsv *TabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, querygen messager.QueryGenerator, ids []string) (count int64, err error) {
+	var queryGenerators []func() (string, map[string]*querypb.BindVariable, error)
+	if query, bv := querygen.GenerateDeadLetterQuery(ids); query != "" {
+		queryGenerators = append(queryGenerators, func() (string, map[string]*querypb.BindVariable, error) {
+			return query, bv, nil
+		})
+	}
+	queryGenerators = append(queryGenerators, func() (string, map[string]*querypb.BindVariable, error) {
+		query, bv := querygen.GenerateAckQuery(ids)
+		return query, bv, nil
+	})
+	return tsv.execDML(ctx, target, queryGenerators...)
+}
+
+// execDML executes the generated queries in a single transaction.
+// It returns the number of rows affected by the last query.
+func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, queryGenerators ...func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
 	if err = tsv.sm.StartRequest(ctx, target, false /* allowOnShutdown */); err != nil {
 		return 0, err
 	}
 	defer tsv.sm.EndRequest()
 	defer tsv.handlePanicAndSendLogStats("ack", nil, nil)
 
-	query, bv, err := queryGenerator()
-	if err != nil {
-		return 0, err
+	queries := make([]string, 0, len(queryGenerators))
+	bindVars := make([]map[string]*querypb.BindVariable, 0, len(queryGenerators))
+	for _, queryGenerator := range queryGenerators {
+		query, bv, err := queryGenerator()
+		if err != nil {
+			return 0, err
+		}
+		queries = append(queries, query)
+		bindVars = append(bindVars, bv)
 	}
 
 	transactionID, _, err := tsv.Begin(ctx, target, nil)
@@ -1051,9 +1115,12 @@ func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, qu
 			tsv.Rollback(ctx, target, transactionID)
 		}
 	}()
-	qr, err := tsv.Execute(ctx, target, query, bv, transactionID, 0, nil)
-	if err != nil {
-		return 0, err
+	var qr *sqltypes.Result
+	for i, query := range queries {
+		qr, err = tsv.Execute(ctx, target, query, bindVars[i], transactionID, 0, nil)
+		if err != nil {
+			return 0, err
+		}
 	}
 	if _, err = tsv.Commit(ctx, target, transactionID); err != nil {
 		transactionID = 0
@@ -1301,6 +1368,7 @@ func (tsv *TabletServer) execRequest(
 	}
 
 	ctx, cancel := withTimeout(ctx, timeout, options)
+	ctx = withPriority(ctx, options)
 	defer func() {
 		cancel()
 		tsv.sm.EndRequest()
@@ -1540,6 +1608,66 @@ func (tsv *TabletServer) ExitLameduck() {
 	tsv.sm.ExitLameduck()
 }
 
+// SetDraining marks the tablet as draining in its health stream, so that
+// vtgates stop sending it new queries, or stops draining it. The tablet keeps
+// executing the queries and transactions in flight. It stops draining when
+// its type changes.
+func (tsv *TabletServer) SetDraining(draining bool) error {
+	if draining && tsv.sm.Target().TabletType == topodatapb.TabletType_PRIMARY {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot drain a primary tablet, reparent its shard instead")
+	}
+	tsv.hs.SetDraining(draining)
+	return nil
+}
+
+// IsDraining returns true if the tablet is draining.
+func (tsv *TabletServer) IsDraining() bool {
+	return tsv.hs.IsDraining()
+}
+
+// FreezeWrites makes the tablet reject the queries that write, while it keeps
+// executing the queries that read, until the given time, or until
+// UnfreezeWrites is called if it is zero. The queries of vreplication and
+// online DDL are not rejected. The tablet executes the queries that write
+// again when it restarts.
+func (tsv *TabletServer) FreezeWrites(until time.Time) {
+	tsv.writesFrozenMu.Lock()
+	defer tsv.writesFrozenMu.Unlock()
+	tsv.writesFrozen = true
+	tsv.writesFrozenUntil = until
+	log.Infof("Writes frozen until %v", until)
+}
+
+// UnfreezeWrites makes the tablet execute the queries that write again.
+func (tsv *TabletServer) UnfreezeWrites() {
+	tsv.writesFrozenMu.Lock()
+	defer tsv.writesFrozenMu.Unlock()
+	if tsv.writesFrozen {
+		log.Infof("Writes unfrozen")
+	}
+	tsv.writesFrozen = false
+	tsv.writesFrozenUntil = time.Time{}
+}
+
+// WritesFrozen returns true if the tablet rejects the queries that write, and
+// until when it does, or a zero time if it does until UnfreezeWrites is called.
+func (tsv *TabletServer) WritesFrozen() (bool, time.Time) {
+	tsv.writesFrozenMu.Lock()
+	defer tsv.writesFrozenMu.Unlock()
+	if tsv.writesFrozen && !tsv.writesFrozenUntil.IsZero() && !time.Now().Before(tsv.writesFrozenUntil) {
+		log.Infof("Writes unfrozen, the freeze expired at %v", tsv.writesFrozenUntil)
+		tsv.writesFrozen = false
+		tsv.writesFrozenUntil = time.Time{}
+	}
+	return tsv.writesFrozen, tsv.writesFrozenUntil
+}
+
+// InFlight returns the number of queries that the tablet is executing, and
+// the number of its open transactions and reserved connections.
+func (tsv *TabletServer) InFlight() (queries int64, transactions int64) {
+	return tsv.sm.InFlightRequests(), tsv.te.txPool.scp.active.Size()
+}
+
 // IsServing returns true if TabletServer is in SERVING state.
 func (tsv *TabletServer) IsServing() bool {
 	return tsv.sm.IsServing()
@@ -1662,8 +1790,14 @@ func (tsv *TabletServer) registerThrottlerCheckHandlers() {
 			if appName == "" {
 				appName = throttle.DefaultAppName
 			}
+			priority, err := throttle.ParsePriority(r.URL.Query().Get("p"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			flags := &throttle.CheckFlags{
-				LowPriority: (r.URL.Query().Get("p") == "low"),
+				Priority:        priority,
+				SkipSelfMetrics: (r.URL.Query().Get("skip_self_metrics") == "true"),
 			}
 			checkResult := tsv.lagThrottler.CheckByType(ctx, appName, remoteAddr, flags, checkType)
 			if checkResult.StatusCode == http.StatusNotFound && flags.OKIfNotExists {
@@ -1917,13 +2051,40 @@ func queryAsString(sql string, bindVariables map[string]*querypb.BindVariable, s
 // withTimeout returns a context based on the specified timeout.
 // If the context is local or if timeout is 0, the
 // original context is returned as is.
+// The timeout of the QUERY_TIMEOUT_MS comment directive, sent by vtgate in
+// the options, can only shorten the specified timeout: the query is given
+// the smaller of the two.
 func withTimeout(ctx context.Context, timeout time.Duration, options *querypb.ExecuteOptions) (context.Context, context.CancelFunc) {
-	if timeout == 0 || options.GetWorkload() == querypb.ExecuteOptions_DBA || tabletenv.IsLocalContext(ctx) {
+	if tabletenv.IsLocalContext(ctx) {
+		return ctx, func() {}
+	}
+	if options.GetWorkload() == querypb.ExecuteOptions_DBA {
+		timeout = 0
+	}
+	if queryTimeoutMs := options.GetQueryTimeoutMs(); queryTimeoutMs > 0 {
+		if directive := time.Duration(queryTimeoutMs) * time.Millisecond; timeout == 0 || directive < timeout {
+			timeout = directive
+		}
+	}
+	if timeout == 0 {
 		return ctx, func() {}
 	}
 	return context.WithTimeout(ctx, timeout)
 }
 
+// withPriority returns a context that carries the priority of the query,
+// which orders the waiters for the connections of exhausted pools.
+func withPriority(ctx context.Context, options *querypb.ExecuteOptions) context.Context {
+	if options.GetPriority() == "" {
+		return ctx
+	}
+	priority, err := strconv.Atoi(options.GetPriority())
+	if err != nil {
+		return ctx
+	}
+	return connpool.WithPriority(ctx, priority)
+}
+
 // skipQueryPlanCache returns true if the query plan should be cached
 func skipQueryPlanCache(options *querypb.ExecuteOptions) bool {
 	if options == nil {
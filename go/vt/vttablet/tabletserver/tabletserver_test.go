@@ -19,6 +19,7 @@ package tabletserver
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"math/rand"
@@ -44,6 +45,7 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/runtimeflags"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
@@ -562,6 +564,57 @@ func TestSmallerTimeout(t *testing.T) {
 	}
 }
 
+func TestWithTimeout(t *testing.T) {
+	testcases := []struct {
+		name     string
+		timeout  time.Duration
+		options  *querypb.ExecuteOptions
+		deadline time.Duration
+	}{{
+		name:     "server timeout",
+		timeout:  time.Hour,
+		deadline: time.Hour,
+	}, {
+		name: "no timeout",
+	}, {
+		name:    "dba workload",
+		timeout: time.Hour,
+		options: &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_DBA},
+	}, {
+		name:     "shorter directive timeout",
+		timeout:  2 * time.Hour,
+		options:  &querypb.ExecuteOptions{QueryTimeoutMs: time.Hour.Milliseconds()},
+		deadline: time.Hour,
+	}, {
+		name:     "longer directive timeout",
+		timeout:  time.Hour,
+		options:  &querypb.ExecuteOptions{QueryTimeoutMs: 3 * time.Hour.Milliseconds()},
+		deadline: time.Hour,
+	}, {
+		name:     "directive timeout without server timeout",
+		options:  &querypb.ExecuteOptions{QueryTimeoutMs: 2 * time.Hour.Milliseconds()},
+		deadline: 2 * time.Hour,
+	}, {
+		name:     "directive timeout of dba workload",
+		timeout:  time.Hour,
+		options:  &querypb.ExecuteOptions{QueryTimeoutMs: 2 * time.Hour.Milliseconds(), Workload: querypb.ExecuteOptions_DBA},
+		deadline: 2 * time.Hour,
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			ctx, cancel := withTimeout(context.Background(), tcase.timeout, tcase.options)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if tcase.deadline == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.InDelta(t, tcase.deadline, time.Until(deadline), float64(time.Minute))
+		})
+	}
+}
+
 func TestTabletServerReserveConnection(t *testing.T) {
 	db, tsv := setupTabletServerTest(t, "")
 	defer tsv.StopService()
@@ -1761,6 +1814,58 @@ func TestConfigChanges(t *testing.T) {
 	}
 }
 
+func TestRuntimeFlags(t *testing.T) {
+	db, tsv := setupTabletServerTest(t, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	set := func(name, value string) {
+		t.Helper()
+		f := flag.Lookup(name)
+		require.NotNil(t, f)
+		oldValue := f.Value.String()
+		_, err := runtimeflags.Set(name, value, "test")
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Value.Set(oldValue) })
+	}
+
+	set("queryserver-config-pool-size", "11")
+	assert.Equal(t, 11, tsv.PoolSize())
+	set("queryserver-config-stream-pool-size", "12")
+	assert.Equal(t, 12, tsv.StreamPoolSize())
+	set("queryserver-config-transaction-cap", "13")
+	assert.Equal(t, 13, tsv.TxPoolSize())
+	set("queryserver-config-max-result-size", "14")
+	assert.Equal(t, 14, tsv.MaxResultSize())
+	set("queryserver-config-warn-result-size", "15")
+	assert.Equal(t, 15, tsv.WarnResultSize())
+	set("queryserver-config-query-timeout", "1.5")
+	assert.Equal(t, 1500*time.Millisecond, tsv.QueryTimeout.Get())
+	set("queryserver-config-transaction-timeout", "16")
+	assert.Equal(t, 16*time.Second, tsv.TxTimeout())
+	set("throttle_threshold", "2s")
+	assert.Equal(t, 2.0, tsv.ThrottleMetricThreshold())
+
+	_, err := runtimeflags.Set("queryserver-config-pool-size", "0", "test")
+	assert.ErrorContains(t, err, "the pool size must be positive")
+	assert.Equal(t, "11", flag.Lookup("queryserver-config-pool-size").Value.String())
+	assert.Equal(t, 11, tsv.PoolSize())
+
+	_, err = runtimeflags.Set("queryserver-config-stream-pool-size", "0", "test")
+	assert.ErrorContains(t, err, "the stream pool size must be positive")
+	assert.Equal(t, 12, tsv.StreamPoolSize())
+	_, err = runtimeflags.Set("queryserver-config-stream-pool-size", "-1", "test")
+	assert.ErrorContains(t, err, "the stream pool size must be positive")
+	assert.Equal(t, 12, tsv.StreamPoolSize())
+
+	_, err = runtimeflags.Set("queryserver-config-transaction-cap", "0", "test")
+	assert.ErrorContains(t, err, "the transaction cap must be positive")
+	assert.Equal(t, 13, tsv.TxPoolSize())
+	_, err = runtimeflags.Set("queryserver-config-transaction-cap", "-1", "test")
+	assert.ErrorContains(t, err, "the transaction cap must be positive")
+	assert.Equal(t, 13, tsv.TxPoolSize())
+}
+
 func TestReserveBeginExecute(t *testing.T) {
 	db, tsv := setupTabletServerTest(t, "")
 	defer tsv.StopService()
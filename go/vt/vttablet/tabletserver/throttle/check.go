@@ -31,8 +31,11 @@ const (
 type CheckFlags struct {
 	ReadCheck         bool
 	OverrideThreshold float64
-	LowPriority       bool
+	Priority          Priority
 	OKIfNotExists     bool
+	// SkipSelfMetrics checks the metric of the store only, without the
+	// self metrics of the tablet.
+	SkipSelfMetrics bool
 }
 
 // StandardCheckFlags have no special hints
@@ -52,17 +55,20 @@ func NewThrottlerCheck(throttler *Throttler) *ThrottlerCheck {
 
 // checkAppMetricResult allows an app to check on a metric
 func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName string, storeType string, storeName string, metricResultFunc base.MetricResultFunc, flags *CheckFlags) (checkResult *CheckResult) {
-	// Handle deprioritized app logic
+	// Handle app priority logic
 	denyApp := false
+	deprioritized := false
 	metricName := fmt.Sprintf("%s/%s", storeType, storeName)
-	if flags.LowPriority {
-		if _, exists := check.throttler.nonLowPriorityAppRequestsThrottled.Get(metricName); exists {
-			// a non-deprioritized app, ie a "normal" app, has recently been throttled.
-			// This is now a deprioritized app. Deny access to this request.
+	if check.throttler.isHigherPriorityThrottled(metricName, flags.Priority) {
+		// an app of a higher priority has recently been throttled. Deny access to this
+		// request, unless the app is due its minimal quota of checks.
+		if !check.throttler.priorityQuotas.grant(appName, *priorityMinQuota) {
 			denyApp = true
+			deprioritized = true
 		}
+	} else {
+		check.throttler.priorityQuotas.reset(appName)
 	}
-	//
 	metricResult, threshold := check.throttler.AppRequestMetricResult(ctx, appName, metricResultFunc, denyApp)
 	if flags.OverrideThreshold > 0 {
 		threshold = flags.OverrideThreshold
@@ -71,6 +77,11 @@ func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName s
 	if appName == "" {
 		return NewCheckResult(http.StatusExpectationFailed, value, threshold, fmt.Errorf("no app indicated"))
 	}
+	metric := check.throttler.storeMetricName()
+	if err == nil && !flags.SkipSelfMetrics {
+		// The app is limited by whichever metric is the closest to its threshold.
+		metric, value, threshold = check.throttler.limitingMetric(value, threshold)
+	}
 
 	var statusCode int
 
@@ -88,15 +99,36 @@ func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName s
 		statusCode = http.StatusTooManyRequests // 429
 		err = base.ErrThresholdExceeded
 
-		if !flags.LowPriority && !flags.ReadCheck && appName != vitessAppName {
-			// low priority requests will henceforth be denied
-			go check.throttler.nonLowPriorityAppRequestsThrottled.SetDefault(metricName, true)
+		if flags.Priority > PriorityLow && !flags.ReadCheck && appName != vitessAppName {
+			// requests of lower priorities will henceforth be denied
+			go check.throttler.markPriorityThrottled(metricName, flags.Priority)
 		}
 	} else {
 		// all good!
 		statusCode = http.StatusOK // 200
 	}
-	return NewCheckResult(statusCode, value, threshold, err)
+	checkResult = NewCheckResult(statusCode, value, threshold, err)
+	if statusCode == http.StatusOK || statusCode == http.StatusTooManyRequests {
+		checkResult.Metric = metric
+	}
+	throttlerAppChecks.Add([]string{appName, flags.Priority.String(), checkDecision(statusCode, deprioritized)}, 1)
+	return checkResult
+}
+
+// checkDecision names the decision of a check in the ThrottlerAppChecks stats.
+func checkDecision(statusCode int, deprioritized bool) string {
+	switch {
+	case deprioritized:
+		return "deprioritized"
+	case statusCode == http.StatusOK:
+		return "ok"
+	case statusCode == http.StatusTooManyRequests:
+		return "throttled"
+	case statusCode == http.StatusExpectationFailed:
+		return "denied"
+	default:
+		return "error"
+	}
 }
 
 // Check is the core function that runs when a user wants to check a metric
@@ -19,6 +19,10 @@ type CheckResult struct {
 	Threshold  float64 `json:"Threshold"`
 	Error      error   `json:"-"`
 	Message    string  `json:"Message"`
+	// Metric is the name of the metric that limits the app, whose Value
+	// and Threshold are reported: the one that is the closest to, or the
+	// farthest above, its threshold.
+	Metric string `json:"Metric,omitempty"`
 }
 
 // NewCheckResult returns a CheckResult
@@ -51,6 +51,7 @@ type Client struct {
 	flags     CheckFlags
 
 	lastSuccessfulThrottle int64
+	lastThrottledMetric    string
 }
 
 // NewProductionClient creates a client suitable for foreground/production jobs, which have normal priority.
@@ -61,7 +62,7 @@ func NewProductionClient(throttler *Throttler, appName string, checkType Throttl
 		appName:   appName,
 		checkType: checkType,
 		flags: CheckFlags{
-			LowPriority: false,
+			Priority: PriorityNormal,
 		},
 	}
 }
@@ -75,11 +76,22 @@ func NewBackgroundClient(throttler *Throttler, appName string, checkType Throttl
 		appName:   appName,
 		checkType: checkType,
 		flags: CheckFlags{
-			LowPriority: true,
+			Priority: PriorityLow,
 		},
 	}
 }
 
+// WithPriority returns a copy of the client that checks the throttler with the given priority.
+func (c *Client) WithPriority(priority Priority) *Client {
+	if c == nil {
+		return nil
+	}
+	client := *c
+	client.flags.Priority = priority
+	client.lastSuccessfulThrottle = 0
+	return &client
+}
+
 // ThrottleCheckOK checks the throttler, and returns 'true' when the throttler is satisfied.
 // It does not sleep.
 // The function caches results for a brief amount of time, hence it's safe and efficient to
@@ -105,6 +117,7 @@ func (c *Client) ThrottleCheckOK(ctx context.Context, overrideAppName string) (t
 	}
 	checkResult := c.throttler.CheckByType(ctx, checkApp, "", &c.flags, c.checkType)
 	if checkResult.StatusCode != http.StatusOK {
+		c.lastThrottledMetric = checkResult.Metric
 		return false
 	}
 	c.lastSuccessfulThrottle = atomic.LoadInt64(&throttleTicks)
@@ -112,6 +125,15 @@ func (c *Client) ThrottleCheckOK(ctx context.Context, overrideAppName string) (t
 
 }
 
+// ThrottledMetric returns the metric that throttled the last check that was not satisfied, if the
+// throttler reported one, e.g. "lag" or "history_list_length".
+func (c *Client) ThrottledMetric() string {
+	if c == nil {
+		return ""
+	}
+	return c.lastThrottledMetric
+}
+
 // ThrottleCheckOKOrWait checks the throttler; if throttler is satisfied, the function returns 'true' mmediately,
 // otherwise it briefly sleeps and returns 'false'.
 // Non-empty appName overrides the default appName.
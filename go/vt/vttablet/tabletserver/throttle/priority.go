@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var priorityMinQuota = flag.Float64("throttle_priority_min_quota", 0.05, "Minimal ratio of the checks of an app that are not denied while an app of a higher priority is throttled, so that apps of a lower priority are never starved. The checks that are let through are still subject to the threshold. 0 denies all their checks")
+
+const (
+	priorityQuotasExpiration = time.Minute
+	priorityQuotasCleanup    = 5 * time.Minute
+)
+
+// throttlerAppChecks counts the decisions of the throttler for the checks of every app.
+var throttlerAppChecks = stats.NewCountersWithMultiLabels("ThrottlerAppChecks", "throttler checks by app, priority and decision", []string{"App", "Priority", "Decision"})
+
+// Priority is the priority tier of an app checking on the throttler. While an
+// app is throttled, the apps of lower priorities are denied: they are throttled
+// before it, and let it use the capacity of the shard first.
+type Priority int
+
+const (
+	// PriorityLow is the priority of background jobs, such as vreplication, table
+	// garbage collection and online DDL migrations.
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of the apps that don't indicate one.
+	PriorityNormal Priority = 0
+	// PriorityHigh is the priority of the jobs that hold back a cutover, such
+	// as vreplication streams catching up with their stop position.
+	PriorityHigh Priority = 1
+)
+
+var priorityNames = map[Priority]string{
+	PriorityLow:    "low",
+	PriorityNormal: "normal",
+	PriorityHigh:   "high",
+}
+
+// String returns the name of the priority, as used in the check API.
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", int(p))
+}
+
+// ParsePriority parses the name of a priority. An empty name is the normal priority.
+func ParsePriority(name string) (Priority, error) {
+	if name == "" {
+		return PriorityNormal, nil
+	}
+	for p, pName := range priorityNames {
+		if pName == name {
+			return p, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown throttler priority: %s", name)
+}
+
+// priorityThrottledKey is the key of priorityAppRequestsThrottled noting that
+// an app of some priority was throttled on a metric.
+func priorityThrottledKey(metricName string, priority Priority) string {
+	return fmt.Sprintf("%s/%s", metricName, priority)
+}
+
+// markPriorityThrottled notes that an app of some priority was just throttled on a metric.
+func (throttler *Throttler) markPriorityThrottled(metricName string, priority Priority) {
+	throttler.priorityAppRequestsThrottled.SetDefault(priorityThrottledKey(metricName, priority), true)
+}
+
+// isHigherPriorityThrottled tells whether an app of a higher priority than the given one
+// was recently throttled on a metric.
+func (throttler *Throttler) isHigherPriorityThrottled(metricName string, priority Priority) bool {
+	for p := priority + 1; p <= PriorityHigh; p++ {
+		if _, exists := throttler.priorityAppRequestsThrottled.Get(priorityThrottledKey(metricName, p)); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityQuota counts the checks of an app since it was first deprioritized, and
+// the checks that were let through.
+type priorityQuota struct {
+	checks  int64
+	granted int64
+}
+
+// priorityQuotas guarantee every app a minimal ratio of checks that are not denied
+// because of its priority.
+type priorityQuotas struct {
+	mu     sync.Mutex
+	quotas *cache.Cache
+}
+
+func newPriorityQuotas() *priorityQuotas {
+	return &priorityQuotas{
+		quotas: cache.New(priorityQuotasExpiration, priorityQuotasCleanup),
+	}
+}
+
+// grant is called when a check of an app would be denied because of its priority, and
+// tells whether it is let through instead, so that the ratio of the checks of the app
+// that were let through is at least minQuota.
+func (q *priorityQuotas) grant(appName string, minQuota float64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	quota := &priorityQuota{}
+	if object, found := q.quotas.Get(appName); found {
+		quota = object.(*priorityQuota)
+	}
+	quota.checks++
+	granted := float64(quota.granted) < minQuota*float64(quota.checks)
+	if granted {
+		quota.granted++
+	}
+	q.quotas.SetDefault(appName, quota)
+	return granted
+}
+
+// reset is called when a check of an app is not deprioritized anymore.
+func (q *priorityQuotas) reset(appName string) {
+	q.quotas.Delete(appName)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		parsed, err := ParsePriority(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	p, err := ParsePriority("")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityNormal, p)
+	_, err = ParsePriority("urgent")
+	assert.EqualError(t, err, "unknown throttler priority: urgent")
+}
+
+func TestCheckPriorities(t *testing.T) {
+	defer func(minQuota float64) { *priorityMinQuota = minQuota }(*priorityMinQuota)
+	*priorityMinQuota = 0.25
+
+	throttler := &Throttler{
+		metricsQuery:                 replicationLagQuery,
+		throttledApps:                cache.New(cache.NoExpiration, 0),
+		priorityAppRequestsThrottled: cache.New(nonDeprioritizedAppMapExpiration, nonDeprioritizedAppMapInterval),
+		priorityQuotas:               newPriorityQuotas(),
+	}
+	check := NewThrottlerCheck(throttler)
+	lag := func() (base.MetricResult, float64) {
+		return base.NewSimpleMetricResult(0.5), 1
+	}
+	ctx := context.Background()
+	checkApp := func(appName string, priority Priority) int {
+		return check.checkAppMetricResult(ctx, appName, "mysql", selfStoreName, lag, &CheckFlags{Priority: priority}).StatusCode
+	}
+	metricName := "mysql/" + selfStoreName
+
+	assert.Equal(t, http.StatusOK, checkApp("backup", PriorityLow))
+	assert.Equal(t, http.StatusOK, checkApp("app", PriorityNormal))
+
+	// While a normal app is throttled, low priority apps are denied, but
+	// for their minimal quota of checks.
+	throttler.markPriorityThrottled(metricName, PriorityNormal)
+	assert.Equal(t, http.StatusOK, checkApp("app", PriorityNormal))
+	assert.Equal(t, http.StatusOK, checkApp("vreplication", PriorityHigh))
+	var statusCodes []int
+	for i := 0; i < 8; i++ {
+		statusCodes = append(statusCodes, checkApp("backup", PriorityLow))
+	}
+	assert.Equal(t, []int{
+		http.StatusOK, http.StatusExpectationFailed, http.StatusExpectationFailed, http.StatusExpectationFailed,
+		http.StatusOK, http.StatusExpectationFailed, http.StatusExpectationFailed, http.StatusExpectationFailed,
+	}, statusCodes)
+
+	// While a high priority app is throttled, normal apps are denied too.
+	throttler.markPriorityThrottled(metricName, PriorityHigh)
+	assert.Equal(t, http.StatusOK, checkApp("vreplication", PriorityHigh))
+	assert.Equal(t, http.StatusOK, checkApp("app", PriorityNormal))
+	assert.Equal(t, http.StatusExpectationFailed, checkApp("app", PriorityNormal))
+
+	// The priorities are noted per metric.
+	assert.False(t, throttler.isHigherPriorityThrottled("mysql/"+shardStoreName, PriorityLow))
+
+	// The quotas of apps restart once they are not deprioritized anymore.
+	throttler.priorityAppRequestsThrottled.Flush()
+	assert.Equal(t, http.StatusOK, checkApp("backup", PriorityLow))
+	_, found := throttler.priorityQuotas.quotas.Get("backup")
+	assert.False(t, found)
+}
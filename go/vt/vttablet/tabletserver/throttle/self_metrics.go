@@ -0,0 +1,255 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/textutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+// The self metrics are metrics of the tablet and of its MySQL server that
+// the throttler checks besides the replication lag, or the custom metric of
+// -throttle_metrics_query. They apply to the checks of the tablet itself,
+// and to the checks of the shard on the primary. Each has its own threshold,
+// and is disabled if its threshold is 0.
+var (
+	historyListLengthThreshold = flag.Float64("throttle_history_list_length_threshold", 0, "Threshold of the InnoDB history list length of the MySQL server of the tablet above which the throttler throttles. 0 disables the metric")
+	threadsRunningThreshold    = flag.Float64("throttle_threads_running_threshold", 0, "Threshold of the Threads_running status of the MySQL server of the tablet above which the throttler throttles. 0 disables the metric")
+	diskIOUtilizationThreshold = flag.Float64("throttle_disk_io_utilization_threshold", 0, "Threshold of the utilization of the disk of the MySQL server of the tablet, in percent of the time the disk is busy, above which the throttler throttles. Requires -throttle_disk_io_device. 0 disables the metric")
+	diskIODevice               = flag.String("throttle_disk_io_device", "", "Name of the block device of the MySQL server of the tablet in /proc/diskstats, such as sda or nvme0n1, whose utilization is checked by -throttle_disk_io_utilization_threshold")
+
+	diskStatsPath = "/proc/diskstats"
+)
+
+// The names of the metrics, as reported in the Metric of the check results.
+const (
+	defaultMetricName           = "lag"
+	customMetricName            = "custom"
+	historyListLengthMetricName = "history_list_length"
+	threadsRunningMetricName    = "threads_running"
+	diskIOUtilizationMetricName = "disk_io_utilization"
+)
+
+const (
+	sqlHistoryListLength = "select `count` as value from information_schema.innodb_metrics where name = 'trx_rseg_history_len'"
+	sqlThreadsRunning    = "show global status like 'Threads_running'"
+)
+
+// selfMetric is a metric of the tablet that the throttler checks.
+type selfMetric struct {
+	name      string
+	threshold float64
+	read      func(ctx context.Context) (float64, error)
+}
+
+// initSelfMetrics sets up the self metrics that are enabled.
+func (throttler *Throttler) initSelfMetrics() {
+	throttler.selfMetricResults = cache.New(aggregatedMetricsExpiration, aggregatedMetricsCleanup)
+	if *historyListLengthThreshold > 0 {
+		throttler.selfMetrics = append(throttler.selfMetrics, &selfMetric{
+			name:      historyListLengthMetricName,
+			threshold: *historyListLengthThreshold,
+			read: func(ctx context.Context) (float64, error) {
+				return throttler.readSelfMetricQuery(ctx, sqlHistoryListLength, "value")
+			},
+		})
+	}
+	if *threadsRunningThreshold > 0 {
+		throttler.selfMetrics = append(throttler.selfMetrics, &selfMetric{
+			name:      threadsRunningMetricName,
+			threshold: *threadsRunningThreshold,
+			read: func(ctx context.Context) (float64, error) {
+				return throttler.readSelfMetricQuery(ctx, sqlThreadsRunning, "Value")
+			},
+		})
+	}
+	if *diskIOUtilizationThreshold > 0 {
+		if *diskIODevice == "" {
+			log.Errorf("Throttler: -throttle_disk_io_utilization_threshold requires -throttle_disk_io_device, the disk IO utilization is not checked")
+		} else {
+			sampler := &diskIOSampler{path: diskStatsPath, device: *diskIODevice}
+			throttler.selfMetrics = append(throttler.selfMetrics, &selfMetric{
+				name:      diskIOUtilizationMetricName,
+				threshold: *diskIOUtilizationThreshold,
+				read: func(ctx context.Context) (float64, error) {
+					return sampler.utilization(time.Now())
+				},
+			})
+		}
+	}
+}
+
+// storeMetricName returns the name of the metric of the stores.
+func (throttler *Throttler) storeMetricName() string {
+	if throttler.metricsQuery == replicationLagQuery {
+		return defaultMetricName
+	}
+	return customMetricName
+}
+
+// collectSelfMetrics reads the self metrics, unless they are still being
+// read since the previous tick.
+func (throttler *Throttler) collectSelfMetrics(ctx context.Context) {
+	if len(throttler.selfMetrics) == 0 {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&throttler.selfMetricsInProgress, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt64(&throttler.selfMetricsInProgress, 0)
+
+	for _, metric := range throttler.selfMetrics {
+		value, err := metric.read(ctx)
+		if err != nil {
+			// The last value expires, so that a metric that can't be read
+			// doesn't hold or release the apps.
+			log.Warningf("Throttler: failed to read the %s metric: %v", metric.name, err)
+			continue
+		}
+		throttler.selfMetricResults.SetDefault(metric.name, base.NewSimpleMetricResult(value))
+		stats.GetOrNewGaugeFloat64(fmt.Sprintf("ThrottlerSelfMetric%s", textutil.SingleWordCamel(metric.name)), fmt.Sprintf("value of the %s self metric", metric.name)).Set(value)
+	}
+}
+
+// readSelfMetricQuery reads a self metric from the MySQL server of the tablet.
+func (throttler *Throttler) readSelfMetricQuery(ctx context.Context, query string, column string) (float64, error) {
+	conn, err := throttler.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.Exec(ctx, query, 1, true)
+	if err != nil {
+		return 0, err
+	}
+	row := qr.Named().Row()
+	if row == nil {
+		return 0, fmt.Errorf("no results for %s", query)
+	}
+	return strconv.ParseFloat(row[column].ToString(), 64)
+}
+
+// selfMetricsSnapshot returns the last values of the self metrics.
+func (throttler *Throttler) selfMetricsSnapshot() map[string]base.MetricResult {
+	snapshot := make(map[string]base.MetricResult)
+	if throttler.selfMetricResults == nil {
+		return snapshot
+	}
+	for name, item := range throttler.selfMetricResults.Items() {
+		metricResult, _ := item.Object.(base.MetricResult)
+		snapshot[name] = metricResult
+	}
+	return snapshot
+}
+
+// limitingMetric returns the metric that is the closest to, or the
+// farthest above, its threshold, among the metric of a store, with its
+// value and threshold, and the self metrics.
+func (throttler *Throttler) limitingMetric(value float64, threshold float64) (string, float64, float64) {
+	name := throttler.storeMetricName()
+	ratio := value / threshold
+	snapshot := throttler.selfMetricsSnapshot()
+	for _, metric := range throttler.selfMetrics {
+		metricResult, ok := snapshot[metric.name]
+		if !ok {
+			continue
+		}
+		metricValue, err := metricResult.Get()
+		if err != nil {
+			continue
+		}
+		if metricRatio := metricValue / metric.threshold; metricRatio > ratio {
+			name, value, threshold, ratio = metric.name, metricValue, metric.threshold, metricRatio
+		}
+	}
+	return name, value, threshold
+}
+
+// diskIOSampler computes the utilization of a disk from the time it spent
+// doing IOs between two samples of /proc/diskstats, like iostat does.
+type diskIOSampler struct {
+	path   string
+	device string
+
+	mu         sync.Mutex
+	lastTicks  int64
+	lastSample time.Time
+}
+
+// utilization returns the percentage of time the disk was busy since the
+// previous call.
+func (s *diskIOSampler) utilization(now time.Time) (float64, error) {
+	ticks, err := readDiskIOTicks(s.path, s.device)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastTicks, lastSample := s.lastTicks, s.lastSample
+	s.lastTicks, s.lastSample = ticks, now
+	if lastSample.IsZero() {
+		return 0, fmt.Errorf("no previous sample of the disk IO of %s", s.device)
+	}
+	elapsed := now.Sub(lastSample).Milliseconds()
+	if elapsed <= 0 || ticks < lastTicks {
+		return 0, fmt.Errorf("invalid sample of the disk IO of %s", s.device)
+	}
+	utilization := 100 * float64(ticks-lastTicks) / float64(elapsed)
+	if utilization > 100 {
+		utilization = 100
+	}
+	return utilization, nil
+}
+
+// readDiskIOTicks returns the number of milliseconds a device spent doing
+// IOs, from the 13th field of its line in /proc/diskstats.
+func readDiskIOTicks(path string, device string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 || fields[2] != device {
+			continue
+		}
+		return strconv.ParseInt(fields[12], 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("device %s not found in %s", device, path)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+func TestCheckSelfMetrics(t *testing.T) {
+	throttler := &Throttler{
+		metricsQuery:                 replicationLagQuery,
+		throttledApps:                cache.New(cache.NoExpiration, 0),
+		priorityAppRequestsThrottled: cache.New(nonDeprioritizedAppMapExpiration, nonDeprioritizedAppMapInterval),
+		priorityQuotas:               newPriorityQuotas(),
+		selfMetricResults:            cache.New(aggregatedMetricsExpiration, aggregatedMetricsCleanup),
+		selfMetrics: []*selfMetric{
+			{name: historyListLengthMetricName, threshold: 1000},
+			{name: threadsRunningMetricName, threshold: 100},
+		},
+	}
+	check := NewThrottlerCheck(throttler)
+	lag := func() (base.MetricResult, float64) {
+		return base.NewSimpleMetricResult(0.5), 1
+	}
+	ctx := context.Background()
+	flags := &CheckFlags{ReadCheck: true}
+
+	// The self metrics that were not collected don't limit the app.
+	checkResult := check.checkAppMetricResult(ctx, "app", "mysql", selfStoreName, lag, flags)
+	assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+	assert.Equal(t, defaultMetricName, checkResult.Metric)
+	assert.Equal(t, 0.5, checkResult.Value)
+
+	// The metric closest to its threshold is reported.
+	throttler.selfMetricResults.SetDefault(historyListLengthMetricName, base.NewSimpleMetricResult(800))
+	throttler.selfMetricResults.SetDefault(threadsRunningMetricName, base.NewSimpleMetricResult(10))
+	checkResult = check.checkAppMetricResult(ctx, "app", "mysql", selfStoreName, lag, flags)
+	assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+	assert.Equal(t, historyListLengthMetricName, checkResult.Metric)
+	assert.Equal(t, 800.0, checkResult.Value)
+	assert.Equal(t, 1000.0, checkResult.Threshold)
+
+	throttler.selfMetricResults.SetDefault(threadsRunningMetricName, base.NewSimpleMetricResult(300))
+	checkResult = check.checkAppMetricResult(ctx, "app", "mysql", selfStoreName, lag, flags)
+	assert.Equal(t, http.StatusTooManyRequests, checkResult.StatusCode)
+	assert.Equal(t, threadsRunningMetricName, checkResult.Metric)
+	assert.Equal(t, 300.0, checkResult.Value)
+	assert.Equal(t, 100.0, checkResult.Threshold)
+
+	checkResult = check.checkAppMetricResult(ctx, "app", "mysql", selfStoreName, lag, &CheckFlags{ReadCheck: true, SkipSelfMetrics: true})
+	assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+	assert.Equal(t, defaultMetricName, checkResult.Metric)
+
+	// Denied apps are not limited by a metric.
+	throttler.ThrottleApp("denied", time.Now().Add(time.Hour), 1)
+	checkResult = check.checkAppMetricResult(ctx, "denied", "mysql", selfStoreName, lag, flags)
+	assert.Equal(t, http.StatusExpectationFailed, checkResult.StatusCode)
+	assert.Empty(t, checkResult.Metric)
+}
+
+func TestDiskIOSampler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diskstats")
+	writeDiskStats := func(ioTicks string) {
+		content := "   8       0 sda 100 0 200 300 400 0 500 600 0 " + ioTicks + " 700\n" +
+			" 259       0 nvme0n1 1 0 2 3 4 0 5 6 0 7 8\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	sampler := &diskIOSampler{path: path, device: "sda"}
+	start := time.Now()
+
+	writeDiskStats("1000")
+	_, err := sampler.utilization(start)
+	assert.Error(t, err, "the first sample has no utilization")
+
+	writeDiskStats("1250")
+	utilization, err := sampler.utilization(start.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, utilization)
+
+	writeDiskStats("3250")
+	utilization, err = sampler.utilization(start.Add(2 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, utilization)
+
+	_, err = readDiskIOTicks(path, "sdb")
+	assert.EqualError(t, err, "device sdb not found in "+path)
+}
@@ -71,6 +71,9 @@ var (
 	replicationLagQuery = `select unix_timestamp(now(6))-max(ts/1000000000) as replication_lag from _vt.heartbeat`
 
 	ErrThrottlerNotReady = errors.New("throttler not enabled/ready")
+	// ErrThrottlerNotRunning is returned when the throttler is updated on a tablet
+	// that does not run it.
+	ErrThrottlerNotRunning = errors.New("throttler is not running, see --enable_lag_throttler")
 )
 
 // ThrottleCheckType allows a client to indicate what type of check it wants to issue. See available types below.
@@ -97,6 +100,9 @@ type Throttler struct {
 	isEnabled bool
 	isLeader  int64
 	isOpen    int64
+	// checksDisabled is set when the checks of a running throttler are
+	// disabled at runtime, see SetEnabled.
+	checksDisabled int64
 
 	env             tabletenv.Env
 	pool            *connpool.Pool
@@ -128,8 +134,15 @@ type Throttler struct {
 	throttledAppsMutex sync.Mutex
 	tickers            [](*timer.SuspendableTicker)
 
-	nonLowPriorityAppRequestsThrottled *cache.Cache
-	httpClient                         *http.Client
+	// priorityAppRequestsThrottled notes the priorities of the apps that were recently
+	// throttled on every metric, see isHigherPriorityThrottled.
+	priorityAppRequestsThrottled *cache.Cache
+	priorityQuotas               *priorityQuotas
+	httpClient                   *http.Client
+
+	selfMetrics           []*selfMetric
+	selfMetricResults     *cache.Cache
+	selfMetricsInProgress int64
 }
 
 // ThrottlerStatus published some status values from the throttler
@@ -137,12 +150,15 @@ type ThrottlerStatus struct {
 	Keyspace string
 	Shard    string
 
+	IsEnabled bool
 	IsLeader  bool
 	IsOpen    bool
 	IsDormant bool
+	Threshold float64
 
 	AggregatedMetrics map[string]base.MetricResult
 	MetricsHealth     base.MetricHealthMap
+	SelfMetrics       map[string]base.MetricResult
 }
 
 // NewThrottler creates a Throttler
@@ -179,13 +195,15 @@ func NewThrottler(env tabletenv.Env, ts *topo.Server, heartbeatWriter heartbeat.
 		throttler.metricsHealth = cache.New(cache.NoExpiration, 0)
 
 		throttler.tickers = [](*timer.SuspendableTicker){}
-		throttler.nonLowPriorityAppRequestsThrottled = cache.New(nonDeprioritizedAppMapExpiration, nonDeprioritizedAppMapInterval)
+		throttler.priorityAppRequestsThrottled = cache.New(nonDeprioritizedAppMapExpiration, nonDeprioritizedAppMapInterval)
+		throttler.priorityQuotas = newPriorityQuotas()
 
 		throttler.httpClient = base.SetupHTTPClient(2 * mysqlCollectInterval)
 		throttler.initThrottleTabletTypes()
 		throttler.ThrottleApp("always-throttled-app", time.Now().Add(time.Hour*24*365*10), defaultThrottleRatio)
 		throttler.check = NewThrottlerCheck(throttler)
 		throttler.initConfig()
+		throttler.initSelfMetrics()
 		throttler.check.SelfChecks(context.Background())
 	} else {
 		// Create an empty cache, just so that it isn't nil
@@ -203,6 +221,40 @@ func (throttler *Throttler) CheckIsReady() error {
 	return ErrThrottlerNotReady
 }
 
+// IsEnabled tells whether the throttler runs and enforces its checks.
+func (throttler *Throttler) IsEnabled() bool {
+	return throttler.isEnabled && atomic.LoadInt64(&throttler.checksDisabled) == 0
+}
+
+// SetEnabled enables or disables the checks of the throttler, until the tablet restarts.
+// A disabled throttler keeps collecting its metrics, and responds to all checks with
+// HTTP 200 OK. The throttler must run, see --enable_lag_throttler.
+func (throttler *Throttler) SetEnabled(enabled bool) error {
+	if !throttler.isEnabled {
+		return ErrThrottlerNotRunning
+	}
+	if enabled {
+		atomic.StoreInt64(&throttler.checksDisabled, 0)
+	} else {
+		atomic.StoreInt64(&throttler.checksDisabled, 1)
+	}
+	log.Infof("Throttler: checks enabled: %v", enabled)
+	return nil
+}
+
+// SetThreshold changes the threshold of the metric of the throttler, until the tablet restarts.
+func (throttler *Throttler) SetThreshold(threshold float64) error {
+	if !throttler.isEnabled {
+		return ErrThrottlerNotRunning
+	}
+	if threshold <= 0 {
+		return fmt.Errorf("invalid throttler threshold: %v", threshold)
+	}
+	throttler.MetricsThreshold.Set(threshold)
+	log.Infof("Throttler: threshold set to %v", threshold)
+	return nil
+}
+
 // initThrottleTabletTypes reads the user supplied throttle_tablet_types and sets these
 // for the duration of this tablet's lifetime
 func (throttler *Throttler) initThrottleTabletTypes() {
@@ -433,6 +485,7 @@ func (throttler *Throttler) Operate(ctx context.Context) {
 					// frequent
 					if !throttler.isDormant() {
 						throttler.collectMySQLMetrics(ctx)
+						go throttler.collectSelfMetrics(ctx)
 					}
 				}
 			}
@@ -442,6 +495,7 @@ func (throttler *Throttler) Operate(ctx context.Context) {
 					// infrequent
 					if throttler.isDormant() {
 						throttler.collectMySQLMetrics(ctx)
+						go throttler.collectSelfMetrics(ctx)
 					}
 				}
 			}
@@ -485,7 +539,8 @@ func (throttler *Throttler) generateTabletHTTPProbeFunction(ctx context.Context,
 		mySQLThrottleMetric.ClusterName = clusterName
 		mySQLThrottleMetric.Key = probe.Key
 
-		tabletCheckSelfURL := fmt.Sprintf("http://%s:%d/throttler/check-self?app=vitess", probe.TabletHost, probe.TabletPort)
+		// The shard is checked against the metric of the replicas, and the self metrics of the primary only.
+		tabletCheckSelfURL := fmt.Sprintf("http://%s:%d/throttler/check-self?app=vitess&skip_self_metrics=true", probe.TabletHost, probe.TabletPort)
 		resp, err := throttler.httpClient.Get(tabletCheckSelfURL)
 		if err != nil {
 			mySQLThrottleMetric.Err = err
@@ -809,7 +864,7 @@ func (throttler *Throttler) AppRequestMetricResult(ctx context.Context, appName
 
 // checkStore checks the aggregated value of given MySQL store
 func (throttler *Throttler) checkStore(ctx context.Context, appName string, storeName string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
-	if !throttler.env.Config().EnableLagThrottler {
+	if !throttler.env.Config().EnableLagThrottler || atomic.LoadInt64(&throttler.checksDisabled) > 0 {
 		return okMetricCheckResult
 	}
 	return throttler.check.Check(ctx, appName, "mysql", storeName, remoteAddr, flags)
@@ -843,15 +898,25 @@ func (throttler *Throttler) CheckByType(ctx context.Context, appName string, rem
 
 // Status exports a status breakdown
 func (throttler *Throttler) Status() *ThrottlerStatus {
+	if !throttler.isEnabled {
+		// The throttler does not run, and has no metrics.
+		return &ThrottlerStatus{
+			Keyspace: throttler.keyspace,
+			Shard:    throttler.shard,
+		}
+	}
 	return &ThrottlerStatus{
 		Keyspace: throttler.keyspace,
 		Shard:    throttler.shard,
 
+		IsEnabled: throttler.IsEnabled(),
 		IsLeader:  (atomic.LoadInt64(&throttler.isLeader) > 0),
 		IsOpen:    (atomic.LoadInt64(&throttler.isOpen) > 0),
 		IsDormant: throttler.isDormant(),
+		Threshold: throttler.MetricsThreshold.Get(),
 
 		AggregatedMetrics: throttler.aggregatedMetricsSnapshot(),
 		MetricsHealth:     throttler.metricsHealthSnapshot(),
+		SelfMetrics:       throttler.selfMetricsSnapshot(),
 	}
 }
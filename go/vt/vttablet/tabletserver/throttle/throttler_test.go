@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"testing"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sync2"
+)
+
+func TestUpdateThrottler(t *testing.T) {
+	throttler := &Throttler{
+		isEnabled:         true,
+		MetricsThreshold:  sync2.NewAtomicFloat64(1),
+		aggregatedMetrics: cache.New(aggregatedMetricsExpiration, aggregatedMetricsCleanup),
+		metricsHealth:     cache.New(cache.NoExpiration, 0),
+	}
+	assert.True(t, throttler.IsEnabled())
+
+	require.NoError(t, throttler.SetEnabled(false))
+	assert.False(t, throttler.IsEnabled())
+	assert.False(t, throttler.Status().IsEnabled)
+	require.NoError(t, throttler.SetEnabled(true))
+	assert.True(t, throttler.Status().IsEnabled)
+
+	require.NoError(t, throttler.SetThreshold(2.5))
+	assert.Equal(t, 2.5, throttler.Status().Threshold)
+	assert.EqualError(t, throttler.SetThreshold(0), "invalid throttler threshold: 0")
+	assert.Equal(t, 2.5, throttler.MetricsThreshold.Get())
+
+	// A throttler that does not run can't be updated, and reports no status.
+	throttler = &Throttler{keyspace: "ks", shard: "-80"}
+	assert.Equal(t, ErrThrottlerNotRunning, throttler.SetEnabled(true))
+	assert.Equal(t, ErrThrottlerNotRunning, throttler.SetThreshold(1))
+	assert.False(t, throttler.IsEnabled())
+	assert.Equal(t, &ThrottlerStatus{Keyspace: "ks", Shard: "-80"}, throttler.Status())
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ttl expires the rows of the tables that declare a TTL in the
+// vschema of their keyspace, by deleting them in the background on the
+// primary.
+//
+// The primary periodically reads the vschema of its keyspace, and deletes
+// the rows of each table with a TTL whose TTL column is older than the
+// retention of the table, in batches. The deletes are written to the binary
+// log, so that the replicas expire the same rows. Batches are only deleted
+// while the throttler lets the background jobs run, and each round deletes
+// a limited number of rows, so that a backlog of expired rows is caught up
+// over several rounds. A table is skipped if its TTL column is not the first
+// column of an index, which would make each batch scan the table, or if its
+// retention is below a safety minimum.
+package ttl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+const throttlerAppName = "ttl-purge"
+
+var (
+	purgeInterval    = flag.Duration("ttl_purge_interval", time.Minute, "Interval between two rounds of deletion of the expired rows of the tables with a TTL in the vschema, on the primary. 0 disables the expiration of the rows")
+	purgeBatchSize   = flag.Int("ttl_purge_batch_size", 1000, "Number of expired rows deleted at a time from a table with a TTL")
+	maxRowsPerRound  = flag.Int64("ttl_purge_max_rows_per_round", 1000000, "Maximum number of expired rows deleted in a round. The remaining rows are deleted in the next rounds")
+	minRetentionFlag = flag.Duration("ttl_min_retention", time.Hour, "Minimum retention of a table with a TTL. The rows of the tables with a shorter retention are not expired")
+)
+
+var (
+	purgedRows    = stats.NewCountersWithSingleLabel("TTLPurgedRows", "Expired rows deleted on the primary, by table", "Table")
+	purgeErrors   = stats.NewCountersWithSingleLabel("TTLPurgeErrors", "Errors while deleting the expired rows, by table", "Table")
+	throttledRuns = stats.NewCounter("TTLPurgeThrottled", "Rounds of deletion of the expired rows interrupted by the throttler")
+	cappedRuns    = stats.NewCounter("TTLPurgeCapped", "Rounds of deletion of the expired rows that reached the maximum number of rows per round")
+)
+
+const (
+	sqlSelectLeadingIndexColumn = "select 1 from information_schema.statistics where table_schema = %s and table_name = %s and column_name = %s and seq_in_index = 1 limit 1"
+	sqlDeleteExpiredRows        = "delete from %s.%s where %s < now() - interval %d second limit %d"
+)
+
+// Engine deletes the expired rows of the tables of a primary tablet in the
+// background.
+type Engine struct {
+	env             tabletenv.Env
+	ts              *topo.Server
+	throttlerClient *throttle.Client
+	errorLog        *logutil.ThrottledLogger
+
+	enabled         bool
+	batchSize       int
+	maxRowsPerRound int64
+	minRetention    time.Duration
+	keyspace        string
+	dbName          string
+
+	mu     sync.Mutex
+	isOpen bool
+	pool   *dbconnpool.ConnectionPool
+	ticks  *timer.Timer
+	cancel context.CancelFunc
+}
+
+// NewEngine creates a new Engine. The engine is disabled if there is no
+// topo server to read the vschema from.
+func NewEngine(env tabletenv.Env, ts *topo.Server, lagThrottler *throttle.Throttler) *Engine {
+	if *purgeInterval <= 0 || ts == nil {
+		return &Engine{}
+	}
+	if *purgeBatchSize < 1 {
+		log.Exitf("invalid -ttl_purge_batch_size: %d, expected a positive integer", *purgeBatchSize)
+	}
+	return &Engine{
+		env:             env,
+		ts:              ts,
+		throttlerClient: throttle.NewBackgroundClient(lagThrottler, throttlerAppName, throttle.ThrottleCheckPrimaryWrite),
+		errorLog:        logutil.NewThrottledLogger("TTLPurge", 60*time.Second),
+		enabled:         true,
+		batchSize:       *purgeBatchSize,
+		maxRowsPerRound: *maxRowsPerRound,
+		minRetention:    *minRetentionFlag,
+		pool:            dbconnpool.NewConnectionPool("TTLPurgePool", 1, *mysqlctl.DbaIdleTimeout, *mysqlctl.PoolDynamicHostnameResolution),
+		ticks:           timer.NewTimer(*purgeInterval),
+	}
+}
+
+// InitDBConfig initializes the keyspace whose vschema declares the TTLs,
+// and the name of the database whose rows are expired.
+func (e *Engine) InitDBConfig(keyspace, dbName string) {
+	e.keyspace = keyspace
+	e.dbName = dbName
+}
+
+// Open starts expiring the rows. It is called when the tablet becomes the
+// primary.
+func (e *Engine) Open() {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.isOpen {
+		return
+	}
+
+	log.Info("TTLPurge: opening")
+	e.pool.Open(e.env.Config().DB.DbaWithDB())
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	e.ticks.Start(func() { e.purge(ctx) })
+	e.isOpen = true
+}
+
+// Close stops expiring the rows.
+func (e *Engine) Close() {
+	if !e.enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isOpen {
+		return
+	}
+	// The round in progress must be canceled before stopping the ticks,
+	// which waits for it to return.
+	e.cancel()
+	e.ticks.Stop()
+	e.pool.Close()
+	e.isOpen = false
+	log.Info("TTLPurge: closed")
+}
+
+// purge deletes the expired rows of the tables with a TTL, until there is
+// none left or the maximum number of rows of the round is reached.
+func (e *Engine) purge(ctx context.Context) {
+	defer e.env.LogError()
+
+	tables, err := e.tablesWithTTL(ctx)
+	if err != nil {
+		e.recordError("", err)
+		return
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	conn, err := e.pool.Get(ctx)
+	if err != nil {
+		e.recordError("", err)
+		return
+	}
+	defer conn.Recycle()
+
+	remaining := e.maxRowsPerRound
+	for _, name := range sortedNames(tables) {
+		ttl := tables[name]
+		retention, err := vindexes.TTLRetention(ttl)
+		if err != nil {
+			e.recordError(name, err)
+			continue
+		}
+		if retention < e.minRetention {
+			e.recordError(name, fmt.Errorf("the ttl retention %v of table %s is below the minimum of %v, its rows are not expired", retention, name, e.minRetention))
+			continue
+		}
+		qr, err := conn.ExecuteFetch(fmt.Sprintf(sqlSelectLeadingIndexColumn, sqltypes.EncodeStringSQL(e.dbName), sqltypes.EncodeStringSQL(name), sqltypes.EncodeStringSQL(ttl.Column)), 1, false)
+		if err != nil {
+			e.recordError(name, err)
+			continue
+		}
+		if len(qr.Rows) == 0 {
+			e.recordError(name, fmt.Errorf("the ttl column %s of table %s is not the first column of an index, its rows are not expired", ttl.Column, name))
+			continue
+		}
+
+		for {
+			if remaining <= 0 {
+				// The next round resumes with the remaining rows.
+				cappedRuns.Add(1)
+				return
+			}
+			if !e.throttlerClient.ThrottleCheckOK(ctx, "") {
+				throttledRuns.Add(1)
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			limit := int64(e.batchSize)
+			if limit > remaining {
+				limit = remaining
+			}
+			qr, err := conn.ExecuteFetch(fmt.Sprintf(sqlDeleteExpiredRows, sqlescape.EscapeID(e.dbName), sqlescape.EscapeID(name), sqlescape.EscapeID(ttl.Column), int64(retention.Seconds()), limit), 0, false)
+			if err != nil {
+				e.recordError(name, fmt.Errorf("failed to delete the expired rows of table %s: %v", name, err))
+				break
+			}
+			purgedRows.Add(name, int64(qr.RowsAffected))
+			remaining -= int64(qr.RowsAffected)
+			if int64(qr.RowsAffected) < limit {
+				break
+			}
+		}
+	}
+}
+
+// tablesWithTTL returns the TTLs of the tables of the keyspace, by table.
+func (e *Engine) tablesWithTTL(ctx context.Context) (map[string]*vschemapb.TTL, error) {
+	ctx, cancel := context.WithTimeout(ctx, *topo.RemoteOperationTimeout)
+	defer cancel()
+
+	vschema, err := e.ts.GetVSchema(ctx, e.keyspace)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	tables := make(map[string]*vschemapb.TTL)
+	for name, table := range vschema.Tables {
+		if table.Ttl != nil {
+			tables[name] = table.Ttl
+		}
+	}
+	return tables, nil
+}
+
+func (e *Engine) recordError(table string, err error) {
+	e.errorLog.Errorf("%v", err)
+	purgeErrors.Add(table, 1)
+}
+
+func sortedNames(tables map[string]*vschemapb.TTL) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestPurge(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	e := newTestEngine(db)
+	defer e.pool.Close()
+	purgedRows.ResetAll()
+	purgeErrors.ResetAll()
+	cappedRuns.Reset()
+
+	// Without a vschema, there is nothing to expire.
+	e.purge(context.Background())
+	assert.Empty(t, purgedRows.Counts())
+	assert.Empty(t, purgeErrors.Counts())
+
+	require.NoError(t, e.ts.SaveVSchema(context.Background(), "ks", &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"events":   {Ttl: &vschemapb.TTL{Column: "created_at", Retention: "30d"}},
+			"sessions": {Ttl: &vschemapb.TTL{Column: "seen_at", Retention: "2h"}},
+			"caches":   {Ttl: &vschemapb.TTL{Column: "cached_at", Retention: "10m"}},
+			"logs":     {Ttl: &vschemapb.TTL{Column: "ts", Retention: "1d"}},
+			"users":    {},
+		},
+	}))
+	indexed := sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")
+	db.AddQuery("select 1 from information_schema.statistics where table_schema = 'vt_db' and table_name = 'events' and column_name = 'created_at' and seq_in_index = 1 limit 1", indexed)
+	db.AddQuery("select 1 from information_schema.statistics where table_schema = 'vt_db' and table_name = 'sessions' and column_name = 'seen_at' and seq_in_index = 1 limit 1", indexed)
+	db.AddQuery("select 1 from information_schema.statistics where table_schema = 'vt_db' and table_name = 'logs' and column_name = 'ts' and seq_in_index = 1 limit 1", &sqltypes.Result{})
+	db.AddQuery("delete from `vt_db`.`events` where `created_at` < now() - interval 2592000 second limit 10", &sqltypes.Result{RowsAffected: 4})
+	db.AddQuery("delete from `vt_db`.`sessions` where `seen_at` < now() - interval 7200 second limit 10", &sqltypes.Result{RowsAffected: 10})
+	db.AddQuery("delete from `vt_db`.`sessions` where `seen_at` < now() - interval 7200 second limit 6", &sqltypes.Result{RowsAffected: 6})
+
+	// The deletion of the expired rows of sessions stops at the maximum
+	// number of rows of the round.
+	e.purge(context.Background())
+	assert.Equal(t, map[string]int64{"events": 4, "sessions": 26}, purgedRows.Counts())
+	// logs is not indexed by its ttl column and the retention of caches is
+	// below the minimum.
+	assert.Equal(t, map[string]int64{"caches": 1, "logs": 1}, purgeErrors.Counts())
+	assert.Equal(t, int64(1), cappedRuns.Get())
+	assert.Equal(t, 2, db.GetQueryCalledNum("delete from `vt_db`.`sessions` where `seen_at` < now() - interval 7200 second limit 10"))
+	assert.Equal(t, 1, db.GetQueryCalledNum("delete from `vt_db`.`sessions` where `seen_at` < now() - interval 7200 second limit 6"))
+}
+
+func newTestEngine(db *fakesqldb.DB) *Engine {
+	config := tabletenv.NewDefaultConfig()
+	params, _ := db.ConnParams().MysqlParams()
+	cp := *params
+	dbc := dbconfigs.NewTestDBConfigs(cp, cp, "")
+
+	e := &Engine{
+		env:             tabletenv.NewEnv(config, "TTLPurgeTest"),
+		ts:              memorytopo.NewServer("cell1"),
+		errorLog:        logutil.NewThrottledLogger("TTLPurgeTest", 60*time.Second),
+		enabled:         true,
+		batchSize:       10,
+		maxRowsPerRound: 30,
+		minRetention:    time.Hour,
+		keyspace:        "ks",
+		dbName:          "vt_db",
+		pool:            dbconnpool.NewConnectionPool("TTLPurgeTestPool", 1, time.Minute, 0),
+	}
+	e.pool.Open(dbc.DbaWithDB())
+	return e
+}
@@ -108,6 +108,7 @@ type TwoPC struct {
 	deleteRedoStmt      *sqlparser.ParsedQuery
 	readAllRedo         string
 	countUnresolvedRedo *sqlparser.ParsedQuery
+	readUnresolvedRedo  *sqlparser.ParsedQuery
 
 	insertTransaction   *sqlparser.ParsedQuery
 	insertParticipants  *sqlparser.ParsedQuery
@@ -143,6 +144,9 @@ func NewTwoPC(readPool *connpool.Pool) *TwoPC {
 	tpc.countUnresolvedRedo = sqlparser.BuildParsedQuery(
 		"select count(*) from %s.redo_state where time_created < %a",
 		dbname, ":time_created")
+	tpc.readUnresolvedRedo = sqlparser.BuildParsedQuery(
+		"select dtid, time_created from %s.redo_state where state = %a and time_created < %a",
+		dbname, ":state", ":time_created")
 
 	tpc.insertTransaction = sqlparser.BuildParsedQuery(
 		"insert into %s.dt_state(dtid, state, time_created) values (%a, %a, %a)",
@@ -326,6 +330,34 @@ func (tpc *TwoPC) CountUnresolvedRedo(ctx context.Context, unresolvedTime time.T
 	return v, nil
 }
 
+// ReadUnresolvedRedo returns the prepared transactions that are still
+// unresolved, and their associated prepare time.
+func (tpc *TwoPC) ReadUnresolvedRedo(ctx context.Context, unresolvedTime time.Time) (map[string]time.Time, error) {
+	conn, err := tpc.readPool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	bindVars := map[string]*querypb.BindVariable{
+		"state":        sqltypes.Int64BindVariable(RedoStatePrepared),
+		"time_created": sqltypes.Int64BindVariable(unresolvedTime.UnixNano()),
+	}
+	qr, err := tpc.read(ctx, conn, tpc.readUnresolvedRedo, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	txs := make(map[string]time.Time, len(qr.Rows))
+	for _, row := range qr.Rows {
+		t, err := evalengine.ToInt64(row[1])
+		if err != nil {
+			return nil, err
+		}
+		txs[row[0].ToString()] = time.Unix(0, t)
+	}
+	return txs, nil
+}
+
 // CreateTransaction saves the metadata of a 2pc transaction as Prepared.
 func (tpc *TwoPC) CreateTransaction(ctx context.Context, conn *StatefulConnection, dtid string, participants []*querypb.Target) error {
 	bindVars := map[string]*querypb.BindVariable{
@@ -27,6 +27,7 @@ import (
 
 	"context"
 
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/concurrency"
@@ -83,8 +84,13 @@ type TxEngine struct {
 	shutdownGracePeriod time.Duration
 	coordinatorAddress  string
 	abandonAge          time.Duration
+	autoRollbackAge     time.Duration
 	ticks               *timer.Timer
 
+	// autoRollbacks counts the prepared transactions rolled back by the
+	// watchdog because the metadata manager had no decision for them.
+	autoRollbacks *stats.Counter
+
 	// reservedConnStats keeps statistics about reserved connections
 	reservedConnStats *servenv.TimingsWrapper
 
@@ -101,6 +107,7 @@ func NewTxEngine(env tabletenv.Env) *TxEngine {
 		env:                 env,
 		shutdownGracePeriod: config.GracePeriods.ShutdownSeconds.Get(),
 		reservedConnStats:   env.Exporter().NewTimings("ReservedConnections", "Reserved connections stats", "operation"),
+		autoRollbacks:       env.Exporter().NewCounter("TwopcAutoRollbacks", "Prepared transactions rolled back because the metadata manager had no decision for them"),
 	}
 	limiter := txlimiter.New(env)
 	te.txPool = NewTxPool(env, limiter)
@@ -117,6 +124,7 @@ func NewTxEngine(env tabletenv.Env) *TxEngine {
 	}
 	te.coordinatorAddress = config.TwoPCCoordinatorAddress
 	te.abandonAge = config.TwoPCAbandonAge.Get()
+	te.autoRollbackAge = config.TwoPCAutoRollbackAge.Get()
 	te.ticks = timer.NewTimer(te.abandonAge / 2)
 
 	// Set the prepared pool capacity to something lower than
@@ -450,7 +458,9 @@ func (te *TxEngine) rollbackPrepared() {
 }
 
 // startWatchdog starts the watchdog goroutine, which looks for abandoned
-// transactions and calls the notifier on them.
+// transactions and calls the notifier on them. If auto-rollback is enabled,
+// it also looks for the prepared transactions that remain unresolved, and
+// rolls them back if the metadata manager has no decision for them.
 func (te *TxEngine) startWatchdog() {
 	te.ticks.Start(func() {
 		ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), te.abandonAge/4)
@@ -472,7 +482,19 @@ func (te *TxEngine) startWatchdog() {
 			log.Errorf("Error reading transactions for 2pc watchdog: %v", err)
 			return
 		}
-		if len(txs) == 0 {
+		te.env.Stats().Unresolved.Set("Transactions", int64(len(txs)))
+
+		// Resolve lingering prepares, which are rolled back below if the
+		// coordinator could not resolve them.
+		var prepares map[string]time.Time
+		if te.autoRollbackAge > 0 {
+			prepares, err = te.twoPC.ReadUnresolvedRedo(ctx, time.Now().Add(-te.autoRollbackAge))
+			if err != nil {
+				te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+				log.Errorf("Error reading unresolved prepares for 2pc watchdog: %v", err)
+			}
+		}
+		if len(txs) == 0 && len(prepares) == 0 {
 			return
 		}
 
@@ -484,21 +506,71 @@ func (te *TxEngine) startWatchdog() {
 		}
 		defer coordConn.Close()
 
-		var wg sync.WaitGroup
-		for tx := range txs {
-			wg.Add(1)
-			go func(dtid string) {
-				defer wg.Done()
-				if err := coordConn.ResolveTransaction(ctx, dtid); err != nil {
-					te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
-					log.Errorf("Error notifying for dtid %s: %v", dtid, err)
-				}
-			}(tx)
-		}
-		wg.Wait()
+		te.resolveTransactions(ctx, coordConn, txs)
+		te.rollbackUndecided(ctx, te.resolveTransactions(ctx, coordConn, prepares))
 	})
 }
 
+// resolveTransactions asks the coordinator to resolve the transactions, and
+// returns the ones it resolved without error.
+func (te *TxEngine) resolveTransactions(ctx context.Context, coordConn *vtgateconn.VTGateConn, txs map[string]time.Time) []string {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		resolved []string
+	)
+	for tx := range txs {
+		wg.Add(1)
+		go func(dtid string) {
+			defer wg.Done()
+			if err := coordConn.ResolveTransaction(ctx, dtid); err != nil {
+				te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+				log.Errorf("Error notifying for dtid %s: %v", dtid, err)
+				return
+			}
+			mu.Lock()
+			resolved = append(resolved, dtid)
+			mu.Unlock()
+		}(tx)
+	}
+	wg.Wait()
+	return resolved
+}
+
+// rollbackUndecided rolls back the prepared transactions that the
+// coordinator resolved without error, but that are still prepared. The
+// coordinator resolves a transaction by applying the decision of its
+// metadata manager to all its participants, so these transactions have no
+// decision and would otherwise never be resolved.
+func (te *TxEngine) rollbackUndecided(ctx context.Context, dtids []string) {
+	if len(dtids) == 0 {
+		return
+	}
+	prepares, err := te.twoPC.ReadUnresolvedRedo(ctx, time.Now().Add(-te.autoRollbackAge))
+	if err != nil {
+		te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+		log.Errorf("Error reading unresolved prepares for 2pc watchdog: %v", err)
+		return
+	}
+	for _, dtid := range dtids {
+		if _, ok := prepares[dtid]; !ok {
+			continue
+		}
+		txe := &TxExecutor{
+			ctx:      ctx,
+			logStats: tabletenv.NewLogStats(ctx, "AutoRollbackPrepared"),
+			te:       te,
+		}
+		if err := txe.RollbackPrepared(dtid, 0); err != nil {
+			te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+			log.Errorf("Error rolling back undecided prepared transaction %s: %v", dtid, err)
+			continue
+		}
+		te.autoRollbacks.Add(1)
+		log.Warningf("Rolled back prepared transaction %s, prepared at %v, which has no decision in the metadata manager", dtid, prepares[dtid])
+	}
+}
+
 // stopWatchdog stops the watchdog goroutine.
 func (te *TxEngine) stopWatchdog() {
 	te.ticks.Stop()
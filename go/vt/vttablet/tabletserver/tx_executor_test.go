@@ -443,6 +443,43 @@ func TestExecutorResolveTransaction(t *testing.T) {
 	}
 }
 
+type resolvedVTGateConn struct {
+	fakerpcvtgateconn.FakeVTGateConn
+}
+
+func (conn *resolvedVTGateConn) ResolveTransaction(ctx context.Context, dtid string) error {
+	return nil
+}
+
+func TestExecutorAutoRollbackPrepared(t *testing.T) {
+	protocol := "autoRollbackTest"
+	var save string
+	save, *vtgateconn.VtgateProtocol = *vtgateconn.VtgateProtocol, protocol
+	defer func() { *vtgateconn.VtgateProtocol = save }()
+
+	// The coordinator resolves the transactions without error, as when the
+	// metadata manager has no record of them.
+	vtgateconn.RegisterDialer(protocol, func(context.Context, string) (vtgateconn.Impl, error) {
+		return &resolvedVTGateConn{}, nil
+	})
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern("select dtid, time_created from _vt\\.dt_state where time_created.*", &sqltypes.Result{})
+	db.AddQueryPattern(
+		"select dtid, time_created from _vt\\.redo_state where state = 1 and time_created.*",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("dtid|time_created", "varbinary|int64"), "bb|1"),
+	)
+	db.AddQuery("delete from _vt.redo_state where dtid = 'bb'", &sqltypes.Result{})
+	db.AddQuery("delete from _vt.redo_statement where dtid = 'bb'", &sqltypes.Result{})
+	tsv := newTestTabletServer(ctx, smallTxPool|shortTwopcAge|twopcAutoRollback, db)
+	defer tsv.StopService()
+
+	require.Eventually(t, func() bool {
+		return tsv.te.autoRollbacks.Get() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotZero(t, db.GetQueryCalledNum("delete from _vt.redo_state where dtid = 'bb'"))
+}
+
 func TestNoTwopc(t *testing.T) {
 	txe, tsv, db := newNoTwopcExecutor(t)
 	defer db.Close()
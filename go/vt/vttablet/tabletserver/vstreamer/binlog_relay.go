@@ -0,0 +1,475 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/binlog"
+	"vitess.io/vitess/go/vt/log"
+	vtschema "vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// RelayBinlogs relays the binlog events of the tables of filter from
+// startPos to send, as they are in the binary logs, e.g. for a MySQL replica
+// of the binlog server of vttablet. Only the rows of the tables that match
+// the filter are relayed: the filter rules must select all the columns of
+// the tables, e.g. with a keyrange or an empty filter. The events are sent
+// without checksum, and a HEARTBEAT_EVENT is sent after heartbeat without
+// events if heartbeat is set.
+func (vse *Engine) RelayBinlogs(ctx context.Context, startPos mysql.Position, filter *binlogdatapb.Filter, heartbeat time.Duration, send func(mysql.BinlogEvent) error) error {
+	vse.watcherOnce.Do(vse.setWatch)
+
+	relay, idx, err := func() (*binlogRelay, int, error) {
+		vse.mu.Lock()
+		defer vse.mu.Unlock()
+		if !vse.isOpen {
+			return nil, 0, errors.New("VStreamer is not open")
+		}
+		relay := newBinlogRelay(ctx, vse, startPos, filter, heartbeat, send)
+		idx := vse.streamIdx
+		vse.binlogRelays[idx] = relay
+		vse.streamIdx++
+		// Now that we've added the relay, increment wg.
+		// This must be done before releasing the lock.
+		vse.wg.Add(1)
+		return relay, idx, nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	// Remove the relay from the map and decrement wg when it ends.
+	defer func() {
+		vse.mu.Lock()
+		defer vse.mu.Unlock()
+		delete(vse.binlogRelays, idx)
+		vse.wg.Done()
+	}()
+
+	return relay.Stream()
+}
+
+// binlogRelay relays the binlog events of the tablet, see RelayBinlogs. It
+// filters the rows with the plans of a vstreamer.
+type binlogRelay struct {
+	*vstreamer
+
+	sendEvent func(mysql.BinlogEvent) error
+	heartbeat time.Duration
+	lastSent  time.Time
+
+	// rotate is the fake ROTATE_EVENT that precedes the first
+	// FORMAT_DESCRIPTION_EVENT, relayed once the format is known.
+	rotate mysql.BinlogEvent
+	// serverID, logFile and logPos are those of the last relayed event,
+	// for the heartbeats.
+	serverID uint32
+	logFile  string
+	logPos   uint32
+
+	// inTransaction is set between the BEGIN of a transaction and its end.
+	inTransaction bool
+	// tableMaps are the TABLE_MAP_EVENTs of the relayed tables of the current
+	// statement, relayed before its first relayed rows event. lastRows is
+	// the last relayed rows event of the statement, which is held until the
+	// next one, so that the end of the statement can be flagged on it if
+	// the rows events that follow are filtered out.
+	tableMaps []mysql.BinlogEvent
+	lastRows  mysql.BinlogEvent
+}
+
+func newBinlogRelay(ctx context.Context, vse *Engine, startPos mysql.Position, filter *binlogdatapb.Filter, heartbeat time.Duration, send func(mysql.BinlogEvent) error) *binlogRelay {
+	vs := newVStreamer(ctx, vse.env.Config().DB.FilteredWithDB(), vse.se, mysql.EncodePosition(startPos), "", filter, vse.lvschema, nil, "", vse)
+	vs.pos = startPos
+	return &binlogRelay{
+		vstreamer: vs,
+		sendEvent: send,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream relays the binlog events until the context is done or an error
+// occurs.
+func (br *binlogRelay) Stream() error {
+	br.vse.vstreamersCreated.Add(1)
+	log.Infof("Starting binlog relay from %v", br.pos)
+	if err := br.se.Open(); err != nil {
+		return wrapError(err, br.pos, br.vse)
+	}
+
+	conn, err := binlog.NewBinlogConnection(br.cp)
+	if err != nil {
+		return wrapError(err, br.pos, br.vse)
+	}
+	defer conn.Close()
+
+	events, err := conn.StartBinlogDumpFromPosition(br.ctx, br.pos)
+	if err != nil {
+		return wrapError(err, br.pos, br.vse)
+	}
+	err = br.relayEvents(br.ctx, events)
+	return wrapError(err, br.pos, br.vse)
+}
+
+func (br *binlogRelay) relayEvents(ctx context.Context, events <-chan mysql.BinlogEvent) error {
+	var heartbeats <-chan time.Time
+	if br.heartbeat > 0 {
+		ticker := time.NewTicker(br.heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+	br.lastSent = time.Now()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return nil
+				default:
+				}
+				return fmt.Errorf("unexpected server EOF")
+			}
+			if err := br.relayEvent(ev); err != nil {
+				return err
+			}
+		case br.vschema = <-br.vevents:
+			if err := br.rebuildPlans(); err != nil {
+				return err
+			}
+		case <-heartbeats:
+			if br.format.IsZero() || time.Since(br.lastSent) < br.heartbeat {
+				continue
+			}
+			if err := br.send(mysql.NewHeartbeatEvent(br.format, br.serverID, br.logFile, br.logPos)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// send sends an event without checksum.
+func (br *binlogRelay) send(ev mysql.BinlogEvent) error {
+	if pos := ev.NextPosition(); pos != 0 {
+		br.logPos = pos
+	}
+	br.lastSent = time.Now()
+	if err := br.sendEvent(ev); err != nil {
+		br.vse.errorCounts.Add("Send", 1)
+		return fmt.Errorf("error sending event: %v", err)
+	}
+	return nil
+}
+
+// relayEvent relays an event from the binlog, or a part of it.
+func (br *binlogRelay) relayEvent(ev mysql.BinlogEvent) error {
+	if !ev.IsValid() {
+		return fmt.Errorf("can't parse binlog event: invalid data: %#v", ev)
+	}
+	br.serverID = ev.ServerID()
+
+	// A FORMAT_DESCRIPTION_EVENT may come again, e.g. on log rotate.
+	if ev.IsFormatDescription() {
+		format, err := ev.Format()
+		if err != nil {
+			return fmt.Errorf("can't parse FORMAT_DESCRIPTION_EVENT: %v, event data: %#v", err, ev)
+		}
+		br.format = format
+		fde, err := mysql.BinlogEventWithoutChecksum(br.format, ev)
+		if err != nil {
+			return err
+		}
+		if br.rotate != nil {
+			// The ROTATE_EVENT sent before the FORMAT_DESCRIPTION_EVENT
+			// has no checksum.
+			rotate := br.rotate
+			br.rotate = nil
+			br.logFile, _ = mysql.RotateEventLogFile(br.format, rotate)
+			if err := br.send(rotate); err != nil {
+				return err
+			}
+		}
+		return br.send(fde)
+	}
+
+	if br.format.IsZero() {
+		// The only thing that should come before the FORMAT_DESCRIPTION_EVENT
+		// is a fake ROTATE_EVENT, which tells the name of the current log file.
+		if ev.IsRotate() {
+			br.rotate = ev
+			return nil
+		}
+		return fmt.Errorf("got a real event before FORMAT_DESCRIPTION_EVENT: %#v", ev)
+	}
+
+	ev, err := mysql.BinlogEventWithoutChecksum(br.format, ev)
+	if err != nil {
+		return fmt.Errorf("can't strip checksum from binlog event: %v, event data: %#v", err, ev)
+	}
+
+	switch {
+	case ev.IsRotate():
+		var logPos uint64
+		br.logFile, logPos = mysql.RotateEventLogFile(br.format, ev)
+		if err := br.send(ev); err != nil {
+			return err
+		}
+		// The position of the replica is the one of the new log file.
+		br.logPos = uint32(logPos)
+		return nil
+	case ev.IsGTID():
+		gtid, hasBegin, err := ev.GTID(br.format)
+		if err != nil {
+			return fmt.Errorf("can't get GTID from binlog event: %v, event data: %#v", err, ev)
+		}
+		br.pos = mysql.AppendGTID(br.pos, gtid)
+		br.inTransaction = hasBegin
+		return br.send(ev)
+	case ev.IsXID():
+		if err := br.endStatement(); err != nil {
+			return err
+		}
+		br.inTransaction = false
+		return br.send(ev)
+	case ev.IsQuery():
+		return br.relayQuery(ev)
+	case ev.IsTableMap():
+		return br.relayTableMap(ev)
+	case ev.IsWriteRows() || ev.IsDeleteRows() || ev.IsUpdateRows():
+		return br.relayRows(ev)
+	case mysql.IsRowsQuery(ev):
+		// The statements of the rows events would tell about the rows
+		// that are filtered out.
+		return nil
+	case ev.IsCompressed():
+		return fmt.Errorf("the binlog relay does not handle binlog compression")
+	}
+	return br.send(ev)
+}
+
+// relayQuery relays the query events of the transactions, and the DDLs of
+// the relayed tables. The other statements are replaced with an empty
+// transaction outside of a transaction, and skipped inside one.
+func (br *binlogRelay) relayQuery(ev mysql.BinlogEvent) error {
+	q, err := ev.Query(br.format)
+	if err != nil {
+		return fmt.Errorf("can't get query from binlog event: %v, event data: %#v", err, ev)
+	}
+	switch cat := sqlparser.Preview(q.SQL); cat {
+	case sqlparser.StmtBegin:
+		br.inTransaction = true
+		return br.send(ev)
+	case sqlparser.StmtCommit, sqlparser.StmtRollback:
+		if err := br.endStatement(); err != nil {
+			return err
+		}
+		br.inTransaction = false
+		return br.send(ev)
+	case sqlparser.StmtInsert, sqlparser.StmtUpdate, sqlparser.StmtDelete, sqlparser.StmtReplace:
+		if mustSendStmt(q, br.cp.DBName()) {
+			return fmt.Errorf("statement-based replication is not supported by the binlog relay: %q", q.SQL)
+		}
+	case sqlparser.StmtDDL:
+		if schema.MustReloadSchemaOnDDL(q.SQL, br.cp.DBName()) {
+			br.se.ReloadAt(context.Background(), br.pos)
+		}
+		if mustSendDDL(q, br.cp.DBName(), br.filter) {
+			return br.send(ev)
+		}
+	}
+	if br.inTransaction {
+		return nil
+	}
+	// The GTID of the statement must still be applied by the replica.
+	for _, sql := range []string{"BEGIN", "COMMIT"} {
+		stmt, err := mysql.RewriteQueryEvent(br.format, ev, sql)
+		if err != nil {
+			return err
+		}
+		if err := br.send(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relayTableMap holds the TABLE_MAP_EVENTs of the relayed tables, until the
+// first relayed rows event of the statement.
+func (br *binlogRelay) relayTableMap(ev mysql.BinlogEvent) error {
+	id := ev.TableID(br.format)
+	tm, err := ev.TableMap(br.format)
+	if err != nil {
+		return err
+	}
+
+	plan, ok := br.plans[id]
+	if ok && plan != nil && plan.Table.Name != tm.Name {
+		log.Infof("table map changed: id %d for %s has changed to %s", id, plan.Table.Name, tm.Name)
+		ok = false
+	}
+	if !ok {
+		switch {
+		case tm.Database != "" && tm.Database != br.cp.DBName():
+			br.plans[id] = nil
+		case vtschema.IsInternalOperationTableName(tm.Name):
+			br.plans[id] = nil
+		case !ruleMatches(tm.Name, br.filter):
+			delete(br.plans, id)
+		default:
+			if _, err := br.buildTablePlan(id, tm); err != nil {
+				br.vse.errorCounts.Add("TablePlan", 1)
+				return err
+			}
+		}
+	}
+	if br.plans[id] != nil {
+		br.tableMaps = append(br.tableMaps, ev)
+	}
+	return nil
+}
+
+// relayedRows are consecutive rows of a rows event, relayed as is, or as
+// the insert or the delete of the rows of an update.
+type relayedRows struct {
+	kind relayedRowsKind
+	rows []mysql.Row
+}
+
+type relayedRowsKind int
+
+const (
+	relayedAsIs relayedRowsKind = iota
+	// relayedAsWrite are the rows of an update that move into the keyrange
+	// of the filter, which the replica does not have yet.
+	relayedAsWrite
+	// relayedAsDelete are the rows of an update that move out of the
+	// keyrange of the filter, which the replica must not keep.
+	relayedAsDelete
+)
+
+// relayRows relays the rows of a rows event that match the filter. The rows
+// of an update that only match the filter before or after it are relayed as
+// a delete of their before image, or as an insert of their after image.
+func (br *binlogRelay) relayRows(ev mysql.BinlogEvent) error {
+	var relayed []mysql.BinlogEvent
+	if plan := br.plans[ev.TableID(br.format)]; plan != nil {
+		rows, err := ev.Rows(br.format, plan.TableMap)
+		if err != nil {
+			return err
+		}
+		var groups []relayedRows
+		for _, row := range rows.Rows {
+			beforeOK, _, err := br.extractRowAndFilter(plan, row.Identify, rows.IdentifyColumns, row.NullIdentifyColumns)
+			if err != nil {
+				return err
+			}
+			afterOK, _, err := br.extractRowAndFilter(plan, row.Data, rows.DataColumns, row.NullColumns)
+			if err != nil {
+				return err
+			}
+			kind := relayedAsIs
+			switch {
+			case !beforeOK && !afterOK:
+				continue
+			case ev.IsUpdateRows() && !beforeOK:
+				kind = relayedAsWrite
+			case ev.IsUpdateRows() && !afterOK:
+				kind = relayedAsDelete
+			}
+			if len(groups) == 0 || groups[len(groups)-1].kind != kind {
+				groups = append(groups, relayedRows{kind: kind})
+			}
+			groups[len(groups)-1].rows = append(groups[len(groups)-1].rows, row)
+		}
+
+		if len(groups) == 1 && groups[0].kind == relayedAsIs && len(groups[0].rows) == len(rows.Rows) {
+			relayed = []mysql.BinlogEvent{ev}
+		} else {
+			// The end of the statement is flagged on its last relayed
+			// event, see endStatement.
+			flags := rows.Flags
+			if len(groups) > 1 {
+				flags &^= mysql.RowsEventStmtEndFlag
+			}
+			for _, group := range groups {
+				rows.Flags, rows.Rows = flags, group.rows
+				var event mysql.BinlogEvent
+				switch group.kind {
+				case relayedAsIs:
+					event, err = mysql.RewriteRowsEvent(br.format, ev, rows)
+				case relayedAsWrite:
+					event, err = mysql.UpdateRowsEventAs(br.format, ev, rows, true)
+				case relayedAsDelete:
+					event, err = mysql.UpdateRowsEventAs(br.format, ev, rows, false)
+				}
+				if err != nil {
+					return err
+				}
+				relayed = append(relayed, event)
+			}
+		}
+	}
+
+	for _, event := range relayed {
+		if br.lastRows != nil {
+			if err := br.send(br.lastRows); err != nil {
+				return err
+			}
+		} else {
+			for _, tableMap := range br.tableMaps {
+				if err := br.send(tableMap); err != nil {
+					return err
+				}
+			}
+		}
+		br.lastRows = event
+	}
+	if mysql.RowsEventFlags(br.format, ev)&mysql.RowsEventStmtEndFlag != 0 {
+		return br.endStatement()
+	}
+	return nil
+}
+
+// endStatement relays the last rows event of the statement, flagged as the
+// end of the statement.
+func (br *binlogRelay) endStatement() error {
+	lastRows := br.lastRows
+	br.tableMaps, br.lastRows = nil, nil
+	if lastRows == nil {
+		return nil
+	}
+	flags := mysql.RowsEventFlags(br.format, lastRows)
+	if flags&mysql.RowsEventStmtEndFlag == 0 {
+		var err error
+		if lastRows, err = mysql.SetRowsEventFlags(br.format, lastRows, flags|mysql.RowsEventStmtEndFlag); err != nil {
+			return err
+		}
+	}
+	return br.send(lastRows)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestRelayBinlogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	// Needed for this test to run if run standalone
+	engine.watcherOnce.Do(engine.setWatch)
+
+	execStatements(t, []string{
+		"create table t1(id1 int, id2 int, val varbinary(128), primary key(id1))",
+		"create table t2(id int, val varbinary(128), primary key(id))",
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		"drop table t2",
+	})
+	engine.se.Reload(context.Background())
+
+	setVSchema(t, shardedVSchema)
+	defer env.SetVSchema("{}")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pos, err := mysql.DecodePosition(primaryPosition(t))
+	require.NoError(t, err)
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "/^t1$/",
+			Filter: "-80",
+		}},
+	}
+	events := make(chan mysql.BinlogEvent, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.RelayBinlogs(ctx, pos, filter, 10*time.Millisecond, func(ev mysql.BinlogEvent) error {
+			events <- ev
+			return nil
+		})
+	}()
+
+	// 1 and 3 are in shard -80, 4 and 6 are in shard 80-.
+	execStatements(t, []string{
+		"begin",
+		"insert into t1 values (1, 4, 'aaa'), (4, 1, 'bbb')",
+		"insert into t2 values (1, 'ccc')",
+		"update t1 set val = 'ddd'",
+		"insert into t1 values (6, 1, 'eee')",
+		"commit",
+	})
+
+	got := readRelayedTransaction(t, events, done, true)
+	assert.Equal(t, []string{
+		"gtid",
+		"BEGIN",
+		"table_map t1",
+		"write [1,4,aaa] stmt_end=true",
+		"table_map t1",
+		"update [1,4,aaa 1,4,ddd] stmt_end=true",
+		"xid",
+	}, got)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRelayBinlogsKeyRangeMoves(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	// Needed for this test to run if run standalone
+	engine.watcherOnce.Do(engine.setWatch)
+
+	execStatements(t, []string{
+		"create table t1(id1 int, id2 int, val varbinary(128), primary key(id1))",
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+	})
+	engine.se.Reload(context.Background())
+
+	setVSchema(t, shardedVSchema)
+	defer env.SetVSchema("{}")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pos, err := mysql.DecodePosition(primaryPosition(t))
+	require.NoError(t, err)
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "/^t1$/",
+			Filter: "-80",
+		}},
+	}
+	events := make(chan mysql.BinlogEvent, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.RelayBinlogs(ctx, pos, filter, 0, func(ev mysql.BinlogEvent) error {
+			events <- ev
+			return nil
+		})
+	}()
+
+	// 1 and 3 are in shard -80, 4 and 6 are in shard 80-. The rows that move
+	// into the keyrange are inserted, and the ones that move out of it are
+	// deleted, since the replica only has the rows of the keyrange.
+	execStatements(t, []string{
+		"begin",
+		"insert into t1 values (4, 1, 'aaa'), (1, 4, 'bbb')",
+		"update t1 set id1 = 3 where id1 = 4",
+		"update t1 set id1 = 6 where id1 = 1",
+		"update t1 set id1 = if(id1 = 3, 4, 1)",
+		"commit",
+	})
+	got := readRelayedTransaction(t, events, done, false)
+	assert.Equal(t, []string{
+		"gtid",
+		"BEGIN",
+		"table_map t1",
+		"write [1,4,bbb] stmt_end=true",
+		"table_map t1",
+		"write [3,1,aaa] stmt_end=true",
+		"table_map t1",
+		"delete [1,4,bbb] stmt_end=true",
+		"table_map t1",
+		"delete [3,1,aaa] stmt_end=false",
+		"write [1,4,bbb] stmt_end=true",
+		"xid",
+	}, got)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+// readRelayedTransaction returns a description of the events of the next
+// transaction relayed to events, and waits for a heartbeat after it if
+// heartbeat is set.
+func readRelayedTransaction(t *testing.T, events <-chan mysql.BinlogEvent, done <-chan error, heartbeat bool) []string {
+	t.Helper()
+	var format mysql.BinlogFormat
+	var tableMap *mysql.TableMap
+	var got []string
+	var err error
+	heartbeats := 0
+	for !(len(got) > 0 && got[len(got)-1] == "xid" && (!heartbeat || heartbeats > 0)) {
+		var ev mysql.BinlogEvent
+		select {
+		case ev = <-events:
+		case err := <-done:
+			require.FailNow(t, "the relay ended", "%v", err)
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out", "got %v", got)
+		}
+		require.True(t, ev.IsValid())
+		switch {
+		case ev.IsFormatDescription():
+			format, err = ev.Format()
+			require.NoError(t, err)
+			assert.Equal(t, byte(mysql.BinlogChecksumAlgOff), format.ChecksumAlgorithm)
+		case ev.IsGTID():
+			got = []string{"gtid"}
+		case ev.IsQuery():
+			q, err := ev.Query(format)
+			require.NoError(t, err)
+			got = append(got, q.SQL)
+		case ev.IsTableMap():
+			tableMap, err = ev.TableMap(format)
+			require.NoError(t, err)
+			got = append(got, "table_map "+tableMap.Name)
+		case ev.IsWriteRows() || ev.IsUpdateRows() || ev.IsDeleteRows():
+			rows, err := ev.Rows(format, tableMap)
+			require.NoError(t, err)
+			kind := "write"
+			var values []string
+			for i := range rows.Rows {
+				if ev.IsUpdateRows() || ev.IsDeleteRows() {
+					before, err := rows.StringIdentifiesForTests(tableMap, i)
+					require.NoError(t, err)
+					values = append(values, strings.Join(before, ","))
+				}
+				if ev.IsWriteRows() || ev.IsUpdateRows() {
+					after, err := rows.StringValuesForTests(tableMap, i)
+					require.NoError(t, err)
+					values = append(values, strings.Join(after, ","))
+				}
+			}
+			switch {
+			case ev.IsUpdateRows():
+				kind = "update"
+			case ev.IsDeleteRows():
+				kind = "delete"
+			}
+			got = append(got, fmt.Sprintf("%s %v stmt_end=%v", kind, values, rows.Flags&mysql.RowsEventStmtEndFlag != 0))
+		case ev.IsXID():
+			got = append(got, "xid")
+			heartbeats = 0
+		case mysql.IsHeartbeat(ev):
+			heartbeats++
+		}
+	}
+	return got
+}
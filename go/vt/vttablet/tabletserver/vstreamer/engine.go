@@ -72,6 +72,7 @@ type Engine struct {
 	streamers       map[int]*uvstreamer
 	rowStreamers    map[int]*rowStreamer
 	resultStreamers map[int]*resultStreamer
+	binlogRelays    map[int]*binlogRelay
 
 	// watcherOnce is used for initializing vschema
 	// and setting up the vschema watch. It's guaranteed that
@@ -115,6 +116,7 @@ func NewEngine(env tabletenv.Env, ts srvtopo.Server, se *schema.Engine, lagThrot
 		streamers:       make(map[int]*uvstreamer),
 		rowStreamers:    make(map[int]*rowStreamer),
 		resultStreamers: make(map[int]*resultStreamer),
+		binlogRelays:    make(map[int]*binlogRelay),
 
 		lvschema: &localVSchema{vschema: &vindexes.VSchema{}},
 
@@ -182,6 +184,9 @@ func (vse *Engine) Close() {
 		for _, s := range vse.resultStreamers {
 			s.Cancel()
 		}
+		for _, s := range vse.binlogRelays {
+			s.Cancel()
+		}
 		vse.isOpen = false
 	}()
 
@@ -198,7 +203,9 @@ func (vse *Engine) vschema() *vindexes.VSchema {
 }
 
 // Stream starts a new stream.
-// This streams events from the binary logs
+// This streams events from the binary logs. Besides a position, startPos can be
+// "current" or "timestamp:<unix seconds>" to start at the current position or at
+// the first transaction committed at or after the given time.
 func (vse *Engine) Stream(ctx context.Context, startPos string, tablePKs []*binlogdatapb.TableLastPK, filter *binlogdatapb.Filter, send func([]*binlogdatapb.VEvent) error) error {
 	// Ensure vschema is initialized and the watcher is started.
 	// Starting of the watcher has to be delayed till the first call to Stream
@@ -380,6 +387,9 @@ func (vse *Engine) setWatch() {
 		for _, s := range vse.streamers {
 			s.SetVSchema(vse.lvschema)
 		}
+		for _, s := range vse.binlogRelays {
+			s.SetVSchema(vse.lvschema)
+		}
 		vse.vschemaUpdates.Add(1)
 		return true
 	})
@@ -29,6 +29,9 @@ var defaultPacketSize = flag.Int("vstream_packet_size", 250000, "Suggested packe
 // useDynamicPacketSize controls whether to use dynamic packet size adjustments to increase performance while streaming
 var useDynamicPacketSize = flag.Bool("vstream_dynamic_packet_size", true, "Enable dynamic packet sizing for VReplication. This will adjust the packet size during replication to improve performance.")
 
+// maxBatchLatency is how long the vstreamer can hold committed transactions to batch them with the next ones
+var maxBatchLatency = flag.Duration("vstream_max_batch_latency", 0, "Maximum time a VReplication streamer holds the transactions it has read, once committed, to send them in the same packet as the next transactions, up to the packet size. This reduces the number of packets of streams with many small transactions, at the cost of this latency. The transactions are sent as soon as they are committed if 0")
+
 // PacketSizer is a controller that adjusts the size of the packets being sent by the vstreamer at runtime
 type PacketSizer interface {
 	ShouldSend(byteCount int) bool
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// rowEncoder encodes the row images of a binlog row event into the rows of
+// a ROW VEvent. The values of all the images of the event are appended to a
+// single buffer, and so are their lengths. The rows then slice these buffers,
+// and the rows and row changes are allocated in bulk, so that encoding an
+// event takes a handful of allocations whatever its number of rows, instead
+// of a few per row. The buffers are handed over to the event, so a new
+// encoder is used for every event.
+type rowEncoder struct {
+	values  []byte
+	lengths []int64
+	// ends holds, for every encoded image, the end of its lengths and of
+	// its values in the buffers. Each image starts where the previous ends.
+	ends []imageEnd
+}
+
+type imageEnd struct {
+	lengths, values int
+}
+
+// rowImages holds the indexes of the encoded before and after images of a
+// row change, or -1 if the change has no such image.
+type rowImages struct {
+	before, after int
+}
+
+func newRowEncoder(rows mysql.Rows) *rowEncoder {
+	// The binary size of the images is a good estimate of the size of their
+	// values, and the buffer grows if the estimate is short.
+	size := 0
+	for _, row := range rows.Rows {
+		size += len(row.Identify) + len(row.Data)
+	}
+	return &rowEncoder{
+		values: make([]byte, 0, size),
+		ends:   make([]imageEnd, 0, 2*len(rows.Rows)),
+	}
+}
+
+// add encodes the values of an image, and returns its index.
+func (enc *rowEncoder) add(row []sqltypes.Value) int {
+	for _, v := range row {
+		if v.IsNull() {
+			enc.lengths = append(enc.lengths, -1)
+			continue
+		}
+		enc.lengths = append(enc.lengths, int64(v.Len()))
+		enc.values = append(enc.values, v.Raw()...)
+	}
+	enc.ends = append(enc.ends, imageEnd{lengths: len(enc.lengths), values: len(enc.values)})
+	return len(enc.ends) - 1
+}
+
+// rowChanges returns the row changes of the given images. It must be called
+// once all the images are added, since the buffers are final only then.
+func (enc *rowEncoder) rowChanges(changes []rowImages) []*binlogdatapb.RowChange {
+	rows := make([]querypb.Row, len(enc.ends))
+	var start imageEnd
+	for i, end := range enc.ends {
+		// The capacity of every row is capped, so that appending to a row
+		// cannot overwrite the next one.
+		rows[i].Lengths = enc.lengths[start.lengths:end.lengths:end.lengths]
+		rows[i].Values = enc.values[start.values:end.values:end.values]
+		start = end
+	}
+
+	rowChanges := make([]binlogdatapb.RowChange, len(changes))
+	result := make([]*binlogdatapb.RowChange, len(changes))
+	for i, images := range changes {
+		if images.before >= 0 {
+			rowChanges[i].Before = &rows[images.before]
+		}
+		if images.after >= 0 {
+			rowChanges[i].After = &rows[images.after]
+		}
+		result[i] = &rowChanges[i]
+	}
+	return result
+}
+
+// growValues returns a slice of n values that reuses the given slice if it
+// is large enough.
+func growValues(values []sqltypes.Value, n int) []sqltypes.Value {
+	if cap(values) < n {
+		return make([]sqltypes.Value, n)
+	}
+	return values[:n]
+}
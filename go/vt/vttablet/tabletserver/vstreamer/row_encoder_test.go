@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestRowEncoder(t *testing.T) {
+	enc := newRowEncoder(mysql.Rows{Rows: []mysql.Row{{Data: []byte("abc")}, {Identify: []byte("abc"), Data: []byte("abc")}}})
+
+	insert := rowImages{before: -1, after: enc.add([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarBinary("abc")})}
+	update := rowImages{
+		before: enc.add([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL}),
+		after:  enc.add([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarBinary("defghijklmnop")}),
+	}
+	rowChanges := enc.rowChanges([]rowImages{insert, update})
+
+	want := []*binlogdatapb.RowChange{{
+		After: sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarBinary("abc")}),
+	}, {
+		Before: sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL}),
+		After:  sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarBinary("defghijklmnop")}),
+	}}
+	utils.MustMatch(t, want, rowChanges)
+
+	// Appending to a row does not overwrite the next one.
+	rowChanges[0].After.Values = append(rowChanges[0].After.Values, 'x')
+	assert.Equal(t, "2", string(rowChanges[1].Before.Values))
+}
+
+func TestGrowValues(t *testing.T) {
+	values := growValues(nil, 3)
+	require.Len(t, values, 3)
+	values[0] = sqltypes.NewInt64(1)
+
+	// A large enough slice is reused.
+	reused := growValues(values, 2)
+	require.Len(t, reused, 2)
+	assert.Equal(t, &values[0], &reused[0])
+
+	assert.Len(t, growValues(values, 4), 4)
+}
@@ -22,6 +22,7 @@ import (
 	"io"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/binlog"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -41,6 +43,11 @@ import (
 
 var uvstreamerTestMode = false // Only used for testing
 
+// timestampPositionPrefix prefixes the start positions that are unix
+// timestamps, e.g. "timestamp:1666000000": the stream then starts with the
+// first transaction committed at or after that time.
+const timestampPositionPrefix = "timestamp:"
+
 type tablePlan struct {
 	tablePK *binlogdatapb.TableLastPK
 	rule    *binlogdatapb.Rule
@@ -324,6 +331,19 @@ func (uvs *uvstreamer) setStreamStartPosition() error {
 		}
 		return nil
 	}
+	if strings.HasPrefix(uvs.startPos, timestampPositionPrefix) {
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(uvs.startPos, timestampPositionPrefix), 10, 64)
+		if err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not decode start timestamp: %v", uvs.startPos)
+		}
+		pos, err := uvs.positionAtTimestamp(timestamp, curPos)
+		if err != nil {
+			return vterrors.Wrapf(err, "could not find the position at timestamp %d", timestamp)
+		}
+		uvs.pos = pos
+		// Like for "current", the client gets the resolved position right away.
+		return uvs.sendEventsForCurrentPos()
+	}
 	pos, err := mysql.DecodePosition(uvs.startPos)
 	if err != nil {
 		return vterrors.Wrap(err, "could not decode position")
@@ -345,6 +365,78 @@ func (uvs *uvstreamer) currentPosition() (mysql.Position, error) {
 	return conn.PrimaryPosition()
 }
 
+// positionAtTimestamp returns the position before the first transaction
+// committed at or after timestamp, or curPos if there is none. It scans the
+// binlogs from the last file that starts before timestamp, beginning with the
+// GTID set of its PREVIOUS_GTIDS_EVENT.
+func (uvs *uvstreamer) positionAtTimestamp(timestamp int64, curPos mysql.Position) (mysql.Position, error) {
+	ctx, cancel := context.WithCancel(uvs.ctx)
+	defer cancel()
+	conn, err := binlog.NewBinlogConnection(uvs.cp)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	defer conn.Close()
+	events, err := conn.StartBinlogDumpFromBinlogBeforeTimestamp(ctx, timestamp)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+
+	var format mysql.BinlogFormat
+	var pos mysql.Position
+	for {
+		var ev mysql.BinlogEvent
+		var ok bool
+		select {
+		case ev, ok = <-events:
+			if !ok {
+				return mysql.Position{}, fmt.Errorf("binlog stream ended before reaching timestamp %d", timestamp)
+			}
+		case <-ctx.Done():
+			return mysql.Position{}, ctx.Err()
+		}
+		if !ev.IsValid() {
+			return mysql.Position{}, fmt.Errorf("can't parse binlog event, invalid data: %#v", ev)
+		}
+		if ev.IsFormatDescription() {
+			if format, err = ev.Format(); err != nil {
+				return mysql.Position{}, fmt.Errorf("can't parse FORMAT_DESCRIPTION_EVENT: %v, event data: %#v", err, ev)
+			}
+			continue
+		}
+		if format.IsZero() {
+			continue
+		}
+		if ev, _, err = ev.StripChecksum(format); err != nil {
+			return mysql.Position{}, fmt.Errorf("can't strip checksum from binlog event: %v, event data: %#v", err, ev)
+		}
+		switch {
+		case ev.IsPreviousGTIDs():
+			if pos, err = ev.PreviousGTIDs(format); err != nil {
+				return mysql.Position{}, fmt.Errorf("can't parse PREVIOUS_GTIDS_EVENT: %v, event data: %#v", err, ev)
+			}
+		case ev.IsGTID():
+			if pos.IsZero() {
+				return mysql.Position{}, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "streaming from a timestamp requires binlogs with a PREVIOUS_GTIDS_EVENT")
+			}
+			if int64(ev.Timestamp()) >= timestamp {
+				return pos, nil
+			}
+			gtid, _, err := ev.GTID(format)
+			if err != nil {
+				return mysql.Position{}, fmt.Errorf("can't get GTID from binlog event: %v, event data: %#v", err, ev)
+			}
+			pos = mysql.AppendGTID(pos, gtid)
+		default:
+			continue
+		}
+		if pos.AtLeast(curPos) {
+			// All the transactions were committed before timestamp.
+			return curPos, nil
+		}
+	}
+}
+
 func (uvs *uvstreamer) init() error {
 	if uvs.startPos != "" {
 		if err := uvs.setStreamStartPosition(); err != nil {
@@ -81,9 +81,23 @@ type vstreamer struct {
 	format  mysql.BinlogFormat
 	pos     mysql.Position
 	stopPos string
+	// lastCommitted and sequenceNumber are the logical timestamps of the
+	// current transaction, if the source writes them.
+	lastCommitted, sequenceNumber int64
 
 	phase string
 	vse   *Engine
+
+	// maxBatchLatency is how long committed transactions can be held to be
+	// sent in the same packet as the next ones. They are sent right away
+	// if it is zero.
+	maxBatchLatency time.Duration
+
+	// rowValues, filteredValues and rowCharsets are scratch space for
+	// extractRowAndFilter, reused from one row to the next.
+	rowValues      []sqltypes.Value
+	filteredValues []sqltypes.Value
+	rowCharsets    []collations.ID
 }
 
 // streamerPlan extends the original plan to also include
@@ -114,6 +128,12 @@ type streamerPlan struct {
 // send: callback function to send events.
 func newVStreamer(ctx context.Context, cp dbconfigs.Connector, se *schema.Engine, startPos string, stopPos string, filter *binlogdatapb.Filter, vschema *localVSchema, send func([]*binlogdatapb.VEvent) error, phase string, vse *Engine) *vstreamer {
 	ctx, cancel := context.WithCancel(ctx)
+	var batchLatency time.Duration
+	if stopPos == "" {
+		// A stream that stops at a position sends its transactions as they
+		// come, so that its consumer sees the stop position in the last packet.
+		batchLatency = *maxBatchLatency
+	}
 	return &vstreamer{
 		ctx:      ctx,
 		cancel:   cancel,
@@ -128,6 +148,8 @@ func newVStreamer(ctx context.Context, cp dbconfigs.Connector, se *schema.Engine
 		plans:    make(map[uint64]*streamerPlan),
 		phase:    phase,
 		vse:      vse,
+
+		maxBatchLatency: batchLatency,
 	}
 }
 
@@ -188,10 +210,27 @@ func (vs *vstreamer) replicate(ctx context.Context) error {
 // parseEvents parses and sends events.
 func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.BinlogEvent) error {
 	// bufferAndTransmit uses bufferedEvents and curSize to buffer events.
+	// heldCommits is set while bufferedEvents contains committed transactions
+	// that wait for the next ones, until batchTimer fires.
 	var (
 		bufferedEvents []*binlogdatapb.VEvent
 		curSize        int
+		heldCommits    bool
 	)
+	batchTimer := time.NewTimer(time.Hour)
+	batchTimer.Stop()
+	defer batchTimer.Stop()
+
+	flush := func() error {
+		if heldCommits && !batchTimer.Stop() {
+			<-batchTimer.C
+		}
+		vevents := bufferedEvents
+		bufferedEvents = nil
+		curSize = 0
+		heldCommits = false
+		return vs.send(vevents)
+	}
 
 	// Only the following patterns are possible:
 	// BEGIN->ROWs or Statements->GTID->COMMIT. In the case of large transactions, this can be broken into chunks.
@@ -206,6 +245,9 @@ func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.Binlog
 	// If a new row event causes the packet size to be exceeded,
 	// all existing rows are sent without the new row.
 	// If a single row exceeds the packet size, it will be in its own packet.
+	// If maxBatchLatency is set, a COMMIT is held along with its transaction,
+	// so that the next transactions are sent in the same packet, until the
+	// packet size is reached or the latency has elapsed.
 	bufferAndTransmit := func(vevent *binlogdatapb.VEvent) error {
 		vevent.Keyspace = vs.vse.keyspace
 		vevent.Shard = vs.vse.shard
@@ -217,25 +259,31 @@ func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.Binlog
 			// A JOURNAL event is always preceded by a BEGIN and followed by a COMMIT.
 			// So, we don't have to send it right away.
 			bufferedEvents = append(bufferedEvents, vevent)
-		case binlogdatapb.VEventType_COMMIT, binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_OTHER,
+		case binlogdatapb.VEventType_COMMIT:
+			bufferedEvents = append(bufferedEvents, vevent)
+			if vs.maxBatchLatency > 0 && curSize < *defaultPacketSize {
+				if !heldCommits {
+					batchTimer.Reset(vs.maxBatchLatency)
+					heldCommits = true
+				}
+				return nil
+			}
+			return flush()
+		case binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_OTHER,
 			binlogdatapb.VEventType_HEARTBEAT, binlogdatapb.VEventType_VERSION:
-			// COMMIT, DDL, OTHER and HEARTBEAT must be immediately sent.
+			// DDL, OTHER and HEARTBEAT must be immediately sent.
 			// Although unlikely, it's possible to get a HEARTBEAT in the middle
 			// of a transaction. If so, we still send the partial transaction along
 			// with the heartbeat.
 			bufferedEvents = append(bufferedEvents, vevent)
-			vevents := bufferedEvents
-			bufferedEvents = nil
-			curSize = 0
-			return vs.send(vevents)
+			return flush()
 		case binlogdatapb.VEventType_INSERT, binlogdatapb.VEventType_DELETE, binlogdatapb.VEventType_UPDATE, binlogdatapb.VEventType_REPLACE:
 			newSize := len(vevent.GetDml())
 			if curSize+newSize > *defaultPacketSize {
 				vs.vse.vstreamerNumPackets.Add(1)
-				vevents := bufferedEvents
-				bufferedEvents = []*binlogdatapb.VEvent{vevent}
-				curSize = newSize
-				return vs.send(vevents)
+				if err := flush(); err != nil {
+					return err
+				}
 			}
 			curSize += newSize
 			bufferedEvents = append(bufferedEvents, vevent)
@@ -253,10 +301,9 @@ func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.Binlog
 			}
 			if curSize+newSize > *defaultPacketSize {
 				vs.vse.vstreamerNumPackets.Add(1)
-				vevents := bufferedEvents
-				bufferedEvents = []*binlogdatapb.VEvent{vevent}
-				curSize = newSize
-				return vs.send(vevents)
+				if err := flush(); err != nil {
+					return err
+				}
 			}
 			curSize += newSize
 			bufferedEvents = append(bufferedEvents, vevent)
@@ -346,6 +393,15 @@ func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.Binlog
 			vschemaUpdateCount.Add(1)
 		case <-ctx.Done():
 			return nil
+		case <-batchTimer.C:
+			heldCommits = false
+			if err := flush(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				vs.vse.errorCounts.Add("Send", 1)
+				return fmt.Errorf("error sending event: %v", err)
+			}
 		case <-timer.C:
 			now := time.Now().UnixNano()
 			if err := bufferAndTransmit(&binlogdatapb.VEvent{
@@ -412,10 +468,16 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 			})
 		}
 		vs.pos = mysql.AppendGTID(vs.pos, gtid)
+		vs.lastCommitted, vs.sequenceNumber = 0, 0
+		if lc, ok := ev.(mysql.LogicalClockEvent); ok {
+			vs.lastCommitted, vs.sequenceNumber, _ = lc.LogicalTimestamps(vs.format)
+		}
 	case ev.IsXID():
 		vevents = append(vevents, &binlogdatapb.VEvent{
-			Type: binlogdatapb.VEventType_GTID,
-			Gtid: mysql.EncodePosition(vs.pos),
+			Type:           binlogdatapb.VEventType_GTID,
+			Gtid:           mysql.EncodePosition(vs.pos),
+			LastCommitted:  vs.lastCommitted,
+			SequenceNumber: vs.sequenceNumber,
 		}, &binlogdatapb.VEvent{
 			Type: binlogdatapb.VEventType_COMMIT,
 		})
@@ -839,34 +901,37 @@ nextrow:
 }
 
 func (vs *vstreamer) processRowEvent(vevents []*binlogdatapb.VEvent, plan *streamerPlan, rows mysql.Rows) ([]*binlogdatapb.VEvent, error) {
-	rowChanges := make([]*binlogdatapb.RowChange, 0, len(rows.Rows))
+	enc := newRowEncoder(rows)
+	changes := make([]rowImages, 0, len(rows.Rows))
 	for _, row := range rows.Rows {
+		// The filtered values are only valid until the next call to
+		// extractRowAndFilter, so each image is encoded right away.
+		images := rowImages{before: -1, after: -1}
 		beforeOK, beforeValues, err := vs.extractRowAndFilter(plan, row.Identify, rows.IdentifyColumns, row.NullIdentifyColumns)
 		if err != nil {
 			return nil, err
 		}
+		if beforeOK {
+			images.before = enc.add(beforeValues)
+		}
 		afterOK, afterValues, err := vs.extractRowAndFilter(plan, row.Data, rows.DataColumns, row.NullColumns)
 		if err != nil {
 			return nil, err
 		}
+		if afterOK {
+			images.after = enc.add(afterValues)
+		}
 		if !beforeOK && !afterOK {
 			continue
 		}
-		rowChange := &binlogdatapb.RowChange{}
-		if beforeOK {
-			rowChange.Before = sqltypes.RowToProto3(beforeValues)
-		}
-		if afterOK {
-			rowChange.After = sqltypes.RowToProto3(afterValues)
-		}
-		rowChanges = append(rowChanges, rowChange)
+		changes = append(changes, images)
 	}
-	if len(rowChanges) != 0 {
+	if len(changes) != 0 {
 		vevents = append(vevents, &binlogdatapb.VEvent{
 			Type: binlogdatapb.VEventType_ROW,
 			RowEvent: &binlogdatapb.RowEvent{
 				TableName:  plan.Table.Name,
-				RowChanges: rowChanges,
+				RowChanges: enc.rowChanges(changes),
 				Keyspace:   vs.vse.keyspace,
 				Shard:      vs.vse.shard,
 			},
@@ -901,8 +966,14 @@ func (vs *vstreamer) extractRowAndFilter(plan *streamerPlan, data []byte, dataCo
 	if len(data) == 0 {
 		return false, nil, nil
 	}
-	values := make([]sqltypes.Value, dataColumns.Count())
-	charsets := make([]collations.ID, len(values))
+	values := growValues(vs.rowValues, dataColumns.Count())
+	vs.rowValues = values
+	charsets := vs.rowCharsets[:0]
+	if cap(charsets) < len(values) {
+		charsets = make([]collations.ID, len(values))
+	}
+	charsets = charsets[:len(values)]
+	vs.rowCharsets = charsets
 	valueIndex := 0
 	pos := 0
 	for colNum := 0; colNum < dataColumns.Count(); colNum++ {
@@ -910,6 +981,7 @@ func (vs *vstreamer) extractRowAndFilter(plan *streamerPlan, data []byte, dataCo
 			return false, nil, fmt.Errorf("partial row image encountered: ensure binlog_row_image is set to 'full'")
 		}
 		if nullColumns.Bit(valueIndex) {
+			values[colNum] = sqltypes.NULL
 			valueIndex++
 			continue
 		}
@@ -925,7 +997,8 @@ func (vs *vstreamer) extractRowAndFilter(plan *streamerPlan, data []byte, dataCo
 		values[colNum] = value
 		valueIndex++
 	}
-	filtered := make([]sqltypes.Value, len(plan.ColExprs))
+	filtered := growValues(vs.filteredValues, len(plan.ColExprs))
+	vs.filteredValues = filtered
 	ok, err := plan.filter(values, filtered, charsets)
 	return ok, filtered, err
 }
@@ -1502,6 +1502,57 @@ func TestBuffering(t *testing.T) {
 	runCases(t, nil, testcases, "", nil)
 }
 
+func TestBatching(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	defer func(saved time.Duration) { *maxBatchLatency = saved }(*maxBatchLatency)
+	*maxBatchLatency = 1 * time.Second
+
+	execStatement(t, "create table batch_test(id int, val varbinary(128), primary key(id))")
+	defer execStatement(t, "drop table batch_test")
+	engine.se.Reload(context.Background())
+
+	testcases := []testcase{{
+		// Transactions committed within the latency are sent in one packet.
+		input: []string{
+			"begin",
+			"insert into batch_test values (1, '123')",
+			"commit",
+			"begin",
+			"insert into batch_test values (2, '456')",
+			"commit",
+		},
+		output: [][]string{{
+			`begin`,
+			`type:FIELD field_event:{table_name:"batch_test" fields:{name:"id" type:INT32 table:"batch_test" org_table:"batch_test" database:"vttest" org_name:"id" column_length:11 charset:63 column_type:"int(11)"} fields:{name:"val" type:VARBINARY table:"batch_test" org_table:"batch_test" database:"vttest" org_name:"val" column_length:128 charset:63 column_type:"varbinary(128)"}}`,
+			`type:ROW row_event:{table_name:"batch_test" row_changes:{after:{lengths:1 lengths:3 values:"1123"}}}`,
+			`gtid`,
+			`commit`,
+			`begin`,
+			`type:ROW row_event:{table_name:"batch_test" row_changes:{after:{lengths:1 lengths:3 values:"2456"}}}`,
+			`gtid`,
+			`commit`,
+		}},
+	}, {
+		// A DDL sends the held transactions along with it.
+		input: []string{
+			"insert into batch_test values (3, '789')",
+			"alter table batch_test change val val varchar(128)",
+		},
+		output: [][]string{{
+			`begin`,
+			`type:ROW row_event:{table_name:"batch_test" row_changes:{after:{lengths:1 lengths:3 values:"3789"}}}`,
+			`gtid`,
+			`commit`,
+			`gtid`,
+			`type:DDL statement:"alter table batch_test change val val varchar(128)"`,
+		}},
+	}}
+	runCases(t, nil, testcases, "", nil)
+}
+
 func TestBestEffortNameInFieldEvent(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -1984,6 +2035,56 @@ func TestNoFutureGTID(t *testing.T) {
 	}
 }
 
+func TestStreamFromTimestamp(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	execStatements(t, []string{
+		"create table stream1(id int, val varbinary(128), primary key(id))",
+		"insert into stream1 values (1, 'aaa')",
+	})
+	defer execStatements(t, []string{
+		"drop table stream1",
+	})
+	engine.se.Reload(context.Background())
+
+	// The timestamps of the binlog events are in seconds.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	startTime := time.Now().Unix()
+	execStatements(t, []string{
+		"insert into stream1 values (2, 'bbb')",
+	})
+	pos := primaryPosition(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wg, ch := startStream(ctx, t, nil, fmt.Sprintf("timestamp:%d", startTime), nil)
+	defer wg.Wait()
+	// The stream starts with the position before the second insert.
+	expectLog(ctx, t, "start pos", ch, [][]string{{`gtid`, `type:OTHER`}})
+	expectLog(ctx, t, "insert", ch, [][]string{{
+		`begin`,
+		`type:FIELD field_event:{table_name:"stream1" fields:{name:"id" type:INT32 table:"stream1" org_table:"stream1" database:"vttest" org_name:"id" column_length:11 charset:63 column_type:"int(11)"} fields:{name:"val" type:VARBINARY table:"stream1" org_table:"stream1" database:"vttest" org_name:"val" column_length:128 charset:63 column_type:"varbinary(128)"}}`,
+		`type:ROW row_event:{table_name:"stream1" row_changes:{after:{lengths:1 lengths:3 values:"2bbb"}}}`,
+		`gtid`,
+		`commit`,
+	}})
+	cancel()
+	wg.Wait()
+
+	// A timestamp in the future starts the stream at the current position.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	wg, ch = startStream(ctx, t, nil, fmt.Sprintf("timestamp:%d", time.Now().Add(time.Hour).Unix()), nil)
+	defer wg.Wait()
+	evs := <-ch
+	require.NotEmpty(t, evs)
+	assert.Equal(t, binlogdatapb.VEventType_GTID, evs[0].Type)
+	assert.Equal(t, pos, evs[0].Gtid)
+	cancel()
+}
+
 func TestFilteredMultipleWhere(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -31,9 +31,12 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/hotrows"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/vexec"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -82,6 +85,12 @@ type Controller struct {
 	// TS is the return value for TopoServer.
 	TS *topo.Server
 
+	// HotRowDetector is the return value for HotRows.
+	HotRowDetector *hotrows.Detector
+
+	// LagHistory is the return value for ReplicationLagHistory.
+	LagHistory *repltracker.LagHistory
+
 	// mu protects the next fields in this structure. They are
 	// accessed by both the methods in this interface, and the
 	// background health check.
@@ -93,6 +102,17 @@ type Controller struct {
 	// isInLameduck is a state variable.
 	isInLameduck bool
 
+	// isDraining is a state variable.
+	isDraining bool
+
+	// writesFrozen and writesFrozenUntil are state variables.
+	writesFrozen      bool
+	writesFrozenUntil time.Time
+
+	// InFlightQueries and InFlightTransactions are the return values of InFlight.
+	InFlightQueries      int64
+	InFlightTransactions int64
+
 	// queryRulesMap has the latest query rules.
 	queryRulesMap map[string]*rules.Rules
 }
@@ -226,6 +246,72 @@ func (tqsc *Controller) TopoServer() *topo.Server {
 	return tqsc.TS
 }
 
+// LagThrottler is part of the tabletserver.Controller interface.
+func (tqsc *Controller) LagThrottler() *throttle.Throttler {
+	return nil
+}
+
+// HotRows is part of the tabletserver.Controller interface.
+func (tqsc *Controller) HotRows() *hotrows.Detector {
+	return tqsc.HotRowDetector
+}
+
+// ReplicationLagHistory is part of the tabletserver.Controller interface.
+func (tqsc *Controller) ReplicationLagHistory() *repltracker.LagHistory {
+	return tqsc.LagHistory
+}
+
+// SetDraining is part of the tabletserver.Controller interface.
+func (tqsc *Controller) SetDraining(draining bool) error {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	tqsc.isDraining = draining
+	return nil
+}
+
+// IsDraining is part of the tabletserver.Controller interface.
+func (tqsc *Controller) IsDraining() bool {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	return tqsc.isDraining
+}
+
+// FreezeWrites is part of the tabletserver.Controller interface.
+func (tqsc *Controller) FreezeWrites(until time.Time) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	tqsc.writesFrozen = true
+	tqsc.writesFrozenUntil = until
+}
+
+// UnfreezeWrites is part of the tabletserver.Controller interface.
+func (tqsc *Controller) UnfreezeWrites() {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	tqsc.writesFrozen = false
+	tqsc.writesFrozenUntil = time.Time{}
+}
+
+// WritesFrozen is part of the tabletserver.Controller interface.
+func (tqsc *Controller) WritesFrozen() (bool, time.Time) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	return tqsc.writesFrozen, tqsc.writesFrozenUntil
+}
+
+// InFlight is part of the tabletserver.Controller interface.
+func (tqsc *Controller) InFlight() (int64, int64) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	return tqsc.InFlightQueries, tqsc.InFlightTransactions
+}
+
 // EnterLameduck implements tabletserver.Controller.
 func (tqsc *Controller) EnterLameduck() {
 	tqsc.mu.Lock()
@@ -49,9 +49,16 @@ type TabletManagerClient interface {
 	// GetSchema asks the remote tablet for its database schema
 	GetSchema(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error)
 
+	// GetSchemaVersion asks the remote tablet for the version of its schema
+	// that was in effect at a given position or time
+	GetSchemaVersion(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error)
+
 	// GetPermissions asks the remote tablet for its permissions list
 	GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error)
 
+	// GetThrottlerStatus asks the remote tablet for the status of its throttler
+	GetThrottlerStatus(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error)
+
 	//
 	// Various read-write methods
 	//
@@ -65,6 +72,14 @@ type TabletManagerClient interface {
 	// ChangeType asks the remote tablet to change its type
 	ChangeType(ctx context.Context, tablet *topodatapb.Tablet, dbType topodatapb.TabletType, semiSync bool) error
 
+	// DrainTablet asks the remote tablet to tell vtgates to stop sending it
+	// queries, and returns the queries and transactions still in flight
+	DrainTablet(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error)
+
+	// FreezeWrites asks the remote tablet to reject the queries that write,
+	// or to execute them again
+	FreezeWrites(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error)
+
 	// Sleep will sleep for a duration (used for tests)
 	Sleep(ctx context.Context, tablet *topodatapb.Tablet, duration time.Duration) error
 
@@ -77,6 +92,10 @@ type TabletManagerClient interface {
 	// RunHealthCheck asks the remote tablet to run a health check cycle
 	RunHealthCheck(ctx context.Context, tablet *topodatapb.Tablet) error
 
+	// UpdateThrottlerConfig asks the remote tablet to enable or disable its
+	// throttler, or to change its threshold, until it restarts
+	UpdateThrottlerConfig(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error)
+
 	// ReloadSchema asks the remote tablet to reload its schema
 	ReloadSchema(ctx context.Context, tablet *topodatapb.Tablet, waitPosition string) error
 
@@ -105,6 +124,14 @@ type TabletManagerClient interface {
 	// query faster. Close() should close the pool in that case.
 	ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, query []byte, maxRows int) (*querypb.QueryResult, error)
 
+	// ExplainQuery runs EXPLAIN or EXPLAIN ANALYZE for a query remotely,
+	// in a read-only transaction of the App user
+	ExplainQuery(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error)
+
+	// GetHotRows returns the rows that the tablet accessed the most recently,
+	// as tracked by its hot row detection.
+	GetHotRows(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error)
+
 	//
 	// Replication related methods
 	//
@@ -115,6 +142,10 @@ type TabletManagerClient interface {
 	// ReplicationStatus returns the tablet's mysql replication status.
 	ReplicationStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.Status, error)
 
+	// GetReplicationLagHistory returns the samples of the replication lag
+	// of the tablet, as kept by its replication lag history.
+	GetReplicationLagHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error)
+
 	// FullStatus returns the tablet's mysql replication status.
 	FullStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.FullStatus, error)
 
@@ -42,6 +42,7 @@ import (
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 // fakeRPCTM implements tabletmanager.RPCTM and fills in all
@@ -301,6 +302,34 @@ func tmRPCTestGetSchemaPanic(ctx context.Context, t *testing.T, client tmclient.
 	expectHandleRPCPanic(t, "GetSchema", false /*verbose*/, err)
 }
 
+var testGetSchemaVersionReq = &tabletmanagerdatapb.GetSchemaVersionRequest{Position: "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:1-10"}
+var testGetSchemaVersionReply = &tabletmanagerdatapb.GetSchemaVersionResponse{
+	Id:               2,
+	Position:         "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:1-8",
+	EndPosition:      "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:1-12",
+	TimeUpdated:      1234,
+	Ddl:              "create table_name2",
+	SchemaDefinition: testGetSchemaReply,
+}
+
+func (fra *fakeRPCTM) GetSchemaVersion(ctx context.Context, request *tabletmanagerdatapb.GetSchemaVersionRequest) (*tabletmanagerdatapb.GetSchemaVersionResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "GetSchemaVersion request", request, testGetSchemaVersionReq)
+	return testGetSchemaVersionReply, nil
+}
+
+func tmRPCTestGetSchemaVersion(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.GetSchemaVersion(ctx, tablet, testGetSchemaVersionReq)
+	compareError(t, "GetSchemaVersion", err, result, testGetSchemaVersionReply)
+}
+
+func tmRPCTestGetSchemaVersionPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetSchemaVersion(ctx, tablet, testGetSchemaVersionReq)
+	expectHandleRPCPanic(t, "GetSchemaVersion", false /*verbose*/, err)
+}
+
 var testGetPermissionsReply = &tabletmanagerdatapb.Permissions{
 	UserPermissions: []*tabletmanagerdatapb.UserPermission{
 		{
@@ -343,6 +372,35 @@ func tmRPCTestGetPermissionsPanic(ctx context.Context, t *testing.T, client tmcl
 	expectHandleRPCPanic(t, "GetPermissions", false /*verbose*/, err)
 }
 
+var testThrottlerStatus = &tabletmanagerdatapb.ThrottlerStatus{
+	Enabled:   true,
+	IsLeader:  true,
+	IsOpen:    true,
+	Threshold: 1,
+	AggregatedMetrics: map[string]float64{
+		"mysql/self":  0.5,
+		"mysql/shard": 0.8,
+	},
+	ThrottledApps: []string{"online-ddl"},
+}
+
+func (fra *fakeRPCTM) GetThrottlerStatus(ctx context.Context, req *tabletmanagerdatapb.GetThrottlerStatusRequest) (*tabletmanagerdatapb.GetThrottlerStatusResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	return &tabletmanagerdatapb.GetThrottlerStatusResponse{Status: testThrottlerStatus}, nil
+}
+
+func tmRPCTestGetThrottlerStatus(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.GetThrottlerStatus(ctx, tablet, &tabletmanagerdatapb.GetThrottlerStatusRequest{})
+	compareError(t, "GetThrottlerStatus", err, resp, &tabletmanagerdatapb.GetThrottlerStatusResponse{Status: testThrottlerStatus})
+}
+
+func tmRPCTestGetThrottlerStatusPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetThrottlerStatus(ctx, tablet, &tabletmanagerdatapb.GetThrottlerStatusRequest{})
+	expectHandleRPCPanic(t, "GetThrottlerStatus", false /*verbose*/, err)
+}
+
 //
 // Various read-write methods
 //
@@ -401,6 +459,58 @@ func tmRPCTestChangeTypePanic(ctx context.Context, t *testing.T, client tmclient
 	expectHandleRPCPanic(t, "ChangeType", true /*verbose*/, err)
 }
 
+var testDrainTabletRequest = &tabletmanagerdatapb.DrainTabletRequest{Undo: true}
+
+var testDrainTabletResponse = &tabletmanagerdatapb.DrainTabletResponse{
+	InFlightQueries:  3,
+	OpenTransactions: 1,
+}
+
+func (fra *fakeRPCTM) DrainTablet(ctx context.Context, req *tabletmanagerdatapb.DrainTabletRequest) (*tabletmanagerdatapb.DrainTabletResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "DrainTablet request", req, testDrainTabletRequest)
+	return testDrainTabletResponse, nil
+}
+
+func tmRPCTestDrainTablet(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.DrainTablet(ctx, tablet, testDrainTabletRequest)
+	compareError(t, "DrainTablet", err, resp, testDrainTabletResponse)
+}
+
+func tmRPCTestDrainTabletPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.DrainTablet(ctx, tablet, testDrainTabletRequest)
+	expectHandleRPCPanic(t, "DrainTablet", true /*verbose*/, err)
+}
+
+var testFreezeWritesRequest = &tabletmanagerdatapb.FreezeWritesRequest{
+	Duration: &vttimepb.Duration{Seconds: 60},
+}
+
+var testFreezeWritesResponse = &tabletmanagerdatapb.FreezeWritesResponse{
+	Frozen:     true,
+	ExpireTime: &vttimepb.Time{Seconds: 1234},
+}
+
+func (fra *fakeRPCTM) FreezeWrites(ctx context.Context, req *tabletmanagerdatapb.FreezeWritesRequest) (*tabletmanagerdatapb.FreezeWritesResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "FreezeWrites request", req, testFreezeWritesRequest)
+	return testFreezeWritesResponse, nil
+}
+
+func tmRPCTestFreezeWrites(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.FreezeWrites(ctx, tablet, testFreezeWritesRequest)
+	compareError(t, "FreezeWrites", err, resp, testFreezeWritesResponse)
+}
+
+func tmRPCTestFreezeWritesPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.FreezeWrites(ctx, tablet, testFreezeWritesRequest)
+	expectHandleRPCPanic(t, "FreezeWrites", true /*verbose*/, err)
+}
+
 var testSleepDuration = time.Minute
 
 func (fra *fakeRPCTM) Sleep(ctx context.Context, duration time.Duration) {
@@ -500,6 +610,29 @@ func tmRPCTestRunHealthCheckPanic(ctx context.Context, t *testing.T, client tmcl
 	expectHandleRPCPanic(t, "RunHealthCheck", false /*verbose*/, err)
 }
 
+var testUpdateThrottlerConfigRequest = &tabletmanagerdatapb.UpdateThrottlerConfigRequest{
+	Enable:    true,
+	Threshold: 1,
+}
+
+func (fra *fakeRPCTM) UpdateThrottlerConfig(ctx context.Context, req *tabletmanagerdatapb.UpdateThrottlerConfigRequest) (*tabletmanagerdatapb.UpdateThrottlerConfigResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "UpdateThrottlerConfig request", req, testUpdateThrottlerConfigRequest)
+	return &tabletmanagerdatapb.UpdateThrottlerConfigResponse{Status: testThrottlerStatus}, nil
+}
+
+func tmRPCTestUpdateThrottlerConfig(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.UpdateThrottlerConfig(ctx, tablet, testUpdateThrottlerConfigRequest)
+	compareError(t, "UpdateThrottlerConfig", err, resp, &tabletmanagerdatapb.UpdateThrottlerConfigResponse{Status: testThrottlerStatus})
+}
+
+func tmRPCTestUpdateThrottlerConfigPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.UpdateThrottlerConfig(ctx, tablet, testUpdateThrottlerConfigRequest)
+	expectHandleRPCPanic(t, "UpdateThrottlerConfig", true /*verbose*/, err)
+}
+
 var testReloadSchemaCalled = false
 
 func (fra *fakeRPCTM) ReloadSchema(ctx context.Context, waitPosition string) error {
@@ -686,6 +819,65 @@ func tmRPCTestExecuteFetchPanic(ctx context.Context, t *testing.T, client tmclie
 	expectHandleRPCPanic(t, "ExecuteFetchAsAllPrivs", false /*verbose*/, err)
 }
 
+var testExplainQueryRequest = &tabletmanagerdatapb.ExplainQueryRequest{
+	Query:         "select * from t where id = :id",
+	BindVariables: map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)},
+	Analyze:       true,
+}
+
+var testExplainQueryResponse = &tabletmanagerdatapb.ExplainQueryResponse{
+	Query:  "explain analyze select * from t where id = 1",
+	Result: testExecuteFetchResult,
+}
+
+func (fra *fakeRPCTM) ExplainQuery(ctx context.Context, req *tabletmanagerdatapb.ExplainQueryRequest) (*tabletmanagerdatapb.ExplainQueryResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "ExplainQuery request", req, testExplainQueryRequest)
+	return testExplainQueryResponse, nil
+}
+
+func tmRPCTestExplainQuery(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.ExplainQuery(ctx, tablet, testExplainQueryRequest)
+	compareError(t, "ExplainQuery", err, resp, testExplainQueryResponse)
+}
+
+func tmRPCTestExplainQueryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.ExplainQuery(ctx, tablet, testExplainQueryRequest)
+	expectHandleRPCPanic(t, "ExplainQuery", false /*verbose*/, err)
+}
+
+var testGetHotRowsRequest = &tabletmanagerdatapb.GetHotRowsRequest{
+	Table: "t",
+	Limit: 10,
+}
+
+var testGetHotRowsResponse = &tabletmanagerdatapb.GetHotRowsResponse{
+	Rows: []*tabletmanagerdatapb.HotRow{
+		{Table: "t", Key: "id = 1", Count: 42},
+		{Table: "t", Key: "id = 2", Count: 7},
+	},
+}
+
+func (fra *fakeRPCTM) GetHotRows(ctx context.Context, req *tabletmanagerdatapb.GetHotRowsRequest) (*tabletmanagerdatapb.GetHotRowsResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "GetHotRows request", req, testGetHotRowsRequest)
+	return testGetHotRowsResponse, nil
+}
+
+func tmRPCTestGetHotRows(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.GetHotRows(ctx, tablet, testGetHotRowsRequest)
+	compareError(t, "GetHotRows", err, resp, testGetHotRowsResponse)
+}
+
+func tmRPCTestGetHotRowsPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetHotRows(ctx, tablet, testGetHotRowsRequest)
+	expectHandleRPCPanic(t, "GetHotRows", false /*verbose*/, err)
+}
+
 //
 // Replication related methods
 //
@@ -730,6 +922,36 @@ func tmRPCTestReplicationStatusPanic(ctx context.Context, t *testing.T, client t
 	expectHandleRPCPanic(t, "ReplicationStatus", false /*verbose*/, err)
 }
 
+var testGetReplicationLagHistoryRequest = &tabletmanagerdatapb.GetReplicationLagHistoryRequest{
+	Since: &vttimepb.Time{Seconds: 1661000000},
+}
+
+var testGetReplicationLagHistoryResponse = &tabletmanagerdatapb.GetReplicationLagHistoryResponse{
+	Resolution: &vttimepb.Duration{Seconds: 10},
+	Samples: []*tabletmanagerdatapb.ReplicationLagSample{
+		{Time: &vttimepb.Time{Seconds: 1661000010}, Lag: &vttimepb.Duration{Seconds: 2}},
+		{Time: &vttimepb.Time{Seconds: 1661000020}, Error: "replication is not running"},
+	},
+}
+
+func (fra *fakeRPCTM) GetReplicationLagHistory(ctx context.Context, req *tabletmanagerdatapb.GetReplicationLagHistoryRequest) (*tabletmanagerdatapb.GetReplicationLagHistoryResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "GetReplicationLagHistory request", req, testGetReplicationLagHistoryRequest)
+	return testGetReplicationLagHistoryResponse, nil
+}
+
+func tmRPCTestGetReplicationLagHistory(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.GetReplicationLagHistory(ctx, tablet, testGetReplicationLagHistoryRequest)
+	compareError(t, "GetReplicationLagHistory", err, resp, testGetReplicationLagHistoryResponse)
+}
+
+func tmRPCTestGetReplicationLagHistoryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetReplicationLagHistory(ctx, tablet, testGetReplicationLagHistoryRequest)
+	expectHandleRPCPanic(t, "GetReplicationLagHistory", false /*verbose*/, err)
+}
+
 func (fra *fakeRPCTM) FullStatus(ctx context.Context) (*replicationdatapb.FullStatus, error) {
 	if fra.panics {
 		panic(fmt.Errorf("test-triggered panic"))
@@ -1284,24 +1506,32 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Various read-only methods
 	tmRPCTestPing(ctx, t, client, tablet)
 	tmRPCTestGetSchema(ctx, t, client, tablet)
+	tmRPCTestGetSchemaVersion(ctx, t, client, tablet)
 	tmRPCTestGetPermissions(ctx, t, client, tablet)
+	tmRPCTestGetThrottlerStatus(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnly(ctx, t, client, tablet)
 	tmRPCTestChangeType(ctx, t, client, tablet)
+	tmRPCTestDrainTablet(ctx, t, client, tablet)
+	tmRPCTestFreezeWrites(ctx, t, client, tablet)
 	tmRPCTestSleep(ctx, t, client, tablet)
 	tmRPCTestExecuteHook(ctx, t, client, tablet)
 	tmRPCTestRefreshState(ctx, t, client, tablet)
 	tmRPCTestRunHealthCheck(ctx, t, client, tablet)
+	tmRPCTestUpdateThrottlerConfig(ctx, t, client, tablet)
 	tmRPCTestReloadSchema(ctx, t, client, tablet)
 	tmRPCTestPreflightSchema(ctx, t, client, tablet)
 	tmRPCTestApplySchema(ctx, t, client, tablet)
 	tmRPCTestExecuteFetch(ctx, t, client, tablet)
+	tmRPCTestExplainQuery(ctx, t, client, tablet)
+	tmRPCTestGetHotRows(ctx, t, client, tablet)
 
 	// Replication related methods
 	tmRPCTestPrimaryPosition(ctx, t, client, tablet)
 
 	tmRPCTestReplicationStatus(ctx, t, client, tablet)
+	tmRPCTestGetReplicationLagHistory(ctx, t, client, tablet)
 	tmRPCTestFullStatus(ctx, t, client, tablet)
 	tmRPCTestPrimaryPosition(ctx, t, client, tablet)
 	tmRPCTestStopReplication(ctx, t, client, tablet)
@@ -1341,23 +1571,31 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Various read-only methods
 	tmRPCTestPingPanic(ctx, t, client, tablet)
 	tmRPCTestGetSchemaPanic(ctx, t, client, tablet)
+	tmRPCTestGetSchemaVersionPanic(ctx, t, client, tablet)
 	tmRPCTestGetPermissionsPanic(ctx, t, client, tablet)
+	tmRPCTestGetThrottlerStatusPanic(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnlyPanic(ctx, t, client, tablet)
 	tmRPCTestChangeTypePanic(ctx, t, client, tablet)
+	tmRPCTestDrainTabletPanic(ctx, t, client, tablet)
+	tmRPCTestFreezeWritesPanic(ctx, t, client, tablet)
 	tmRPCTestSleepPanic(ctx, t, client, tablet)
 	tmRPCTestExecuteHookPanic(ctx, t, client, tablet)
 	tmRPCTestRefreshStatePanic(ctx, t, client, tablet)
 	tmRPCTestRunHealthCheckPanic(ctx, t, client, tablet)
+	tmRPCTestUpdateThrottlerConfigPanic(ctx, t, client, tablet)
 	tmRPCTestReloadSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestPreflightSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestApplySchemaPanic(ctx, t, client, tablet)
 	tmRPCTestExecuteFetchPanic(ctx, t, client, tablet)
+	tmRPCTestExplainQueryPanic(ctx, t, client, tablet)
+	tmRPCTestGetHotRowsPanic(ctx, t, client, tablet)
 
 	// Replication related methods
 	tmRPCTestPrimaryPositionPanic(ctx, t, client, tablet)
 	tmRPCTestReplicationStatusPanic(ctx, t, client, tablet)
+	tmRPCTestGetReplicationLagHistoryPanic(ctx, t, client, tablet)
 	tmRPCTestFullStatusPanic(ctx, t, client, tablet)
 	tmRPCTestStopReplicationPanic(ctx, t, client, tablet)
 	tmRPCTestStopReplicationMinimumPanic(ctx, t, client, tablet)
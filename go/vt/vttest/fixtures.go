@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// This file implements the fixtures of a local cluster: the data it is
+// seeded with, and the snapshots of its data that can seed it again.
+//
+// A fixtures directory has a subdir for each keyspace. The .sql files of a
+// keyspace dir are executed, and the .csv files replace the rows of the
+// table named after them. A .csv file starts with a header of the column
+// names, and its NULL values are written \N.
+
+const (
+	// csvNull is how a NULL value is written in a .csv file.
+	csvNull = `\N`
+
+	// fixturesBatchSize is the number of rows inserted by each statement
+	// when a .csv file is loaded.
+	fixturesBatchSize = 500
+
+	// snapshotMaxRows is the maximum number of rows of a table that can be
+	// exported.
+	snapshotMaxRows = 10000000
+)
+
+// fixturesConn returns a connection to a keyspace, through vtgate, so that
+// the rows go to the right shards. If OnlyMySQL is set, the connection is to
+// the single database of the cluster.
+func (db *LocalCluster) fixturesConn(keyspace string) (*mysql.Conn, error) {
+	params := db.mysql.Params(db.DbName())
+	if !db.OnlyMySQL {
+		host := "localhost"
+		if db.MySQLBindHost != "" {
+			host = db.MySQLBindHost
+		}
+		params = mysql.ConnParams{
+			Host:   host,
+			Port:   db.Env.PortForProtocol("vtcombo_mysql_port", ""),
+			DbName: keyspace,
+		}
+	}
+	return mysql.Connect(context.Background(), &params)
+}
+
+// LoadFixtures loads the fixtures of dir into the keyspaces of the cluster.
+// The files of a keyspace are loaded in the order of their names, and the
+// keyspaces that do not have a subdir are skipped.
+func (db *LocalCluster) LoadFixtures(dir string) error {
+	if !isDir(dir) {
+		return fmt.Errorf("LoadFixtures(): fixtures dir %s does not exist", dir)
+	}
+
+	for _, kpb := range db.Topology.Keyspaces {
+		if kpb.ServedFrom != "" {
+			continue
+		}
+		keyspaceDir := path.Join(dir, kpb.Name)
+		if !isDir(keyspaceDir) {
+			continue
+		}
+		files, err := filepath.Glob(path.Join(keyspaceDir, "*.*"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+
+		if err := db.loadKeyspaceFixtures(kpb.Name, keyspaceDir, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *LocalCluster) loadKeyspaceFixtures(keyspace, keyspaceDir string, files []string) error {
+	conn, err := db.fixturesConn(keyspace)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, file := range files {
+		switch path.Ext(file) {
+		case ".sql":
+			log.Infof("Loading fixtures %s into %s", file, keyspace)
+			cmds, err := LoadSQLFile(file, keyspaceDir)
+			if err != nil {
+				return err
+			}
+			for _, cmd := range cmds {
+				if _, err := conn.ExecuteFetch(cmd, 0, false); err != nil {
+					return fmt.Errorf("%s: %v", file, err)
+				}
+			}
+		case ".csv":
+			log.Infof("Loading fixtures %s into %s", file, keyspace)
+			if err := loadCSV(conn, file); err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadCSV replaces the rows of a table with the rows of a .csv file.
+func loadCSV(conn *mysql.Conn, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	table := strings.TrimSuffix(path.Base(file), ".csv")
+	qr, err := conn.ExecuteFetch(fmt.Sprintf("select * from %s limit 0", sqlparser.String(sqlparser.NewIdentifierCS(table))), 0, true)
+	if err != nil {
+		return err
+	}
+	types := make(map[string]querypb.Type, len(qr.Fields))
+	for _, field := range qr.Fields {
+		types[strings.ToLower(field.Name)] = field.Type
+	}
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("cannot read the header: %v", err)
+	}
+	columns := make([]querypb.Type, 0, len(header))
+	for _, name := range header {
+		typ, ok := types[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("table %s has no column %s", table, name)
+		}
+		columns = append(columns, typ)
+	}
+
+	if _, err := conn.ExecuteFetch(fmt.Sprintf("delete from %s", sqlparser.String(sqlparser.NewIdentifierCS(table))), 0, false); err != nil {
+		return err
+	}
+	for {
+		var records [][]string
+		for len(records) < fixturesBatchSize {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		insert, err := csvInsert(table, header, columns, records)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.ExecuteFetch(insert, 0, false); err != nil {
+			return err
+		}
+	}
+}
+
+// csvInsert returns the statement that inserts the records of a .csv file
+// into table, the values being encoded according to the types of the columns.
+func csvInsert(table string, header []string, columns []querypb.Type, records [][]string) (string, error) {
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("insert into %v(", sqlparser.NewIdentifierCS(table))
+	for i, name := range header {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.Myprintf("%v", sqlparser.NewIdentifierCI(name))
+	}
+	buf.WriteString(") values ")
+	for i, record := range records {
+		if len(record) != len(columns) {
+			return "", fmt.Errorf("record %v has %d values, expected %d", record, len(record), len(columns))
+		}
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteByte('(')
+		for j, raw := range record {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			if raw == csvNull {
+				sqltypes.NULL.EncodeSQL(buf)
+				continue
+			}
+			value, err := sqltypes.NewValue(columns[j], []byte(raw))
+			if err != nil {
+				return "", fmt.Errorf("invalid value for column %s: %v", header[j], err)
+			}
+			value.EncodeSQL(buf)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String(), nil
+}
+
+// ExportSnapshot writes the rows of every table of the cluster into a .csv
+// file of dir, which can then be loaded again with LoadFixtures, for example
+// by the next run of a test suite.
+func (db *LocalCluster) ExportSnapshot(dir string) error {
+	for _, kpb := range db.Topology.Keyspaces {
+		if kpb.ServedFrom != "" {
+			continue
+		}
+		if err := db.exportKeyspace(kpb.Name, path.Join(dir, kpb.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *LocalCluster) exportKeyspace(keyspace, keyspaceDir string) error {
+	conn, err := db.fixturesConn(keyspace)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tables, err := conn.ExecuteFetch("show full tables", 10000, false)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(keyspaceDir, 0755); err != nil {
+		return err
+	}
+	for _, row := range tables.Rows {
+		if row[1].ToString() != "BASE TABLE" {
+			continue
+		}
+		table := row[0].ToString()
+		qr, err := conn.ExecuteFetch(fmt.Sprintf("select * from %s", sqlparser.String(sqlparser.NewIdentifierCS(table))), snapshotMaxRows, true)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(path.Join(keyspaceDir, table+".csv"))
+		if err != nil {
+			return err
+		}
+		err = writeCSV(f, qr)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the rows of a result in the .csv format of the fixtures.
+func writeCSV(w io.Writer, qr *sqltypes.Result) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(qr.Fields))
+	for _, field := range qr.Fields {
+		header = append(header, field.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(qr.Fields))
+	for _, row := range qr.Rows {
+		for i, value := range row {
+			if value.IsNull() {
+				record[i] = csvNull
+			} else {
+				record[i] = value.ToString()
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestCSVInsert(t *testing.T) {
+	header := []string{"id", "name", "order"}
+	columns := []querypb.Type{sqltypes.Int64, sqltypes.VarChar, sqltypes.Int32}
+
+	got, err := csvInsert("customer", header, columns, [][]string{
+		{"1", "gopherson", `\N`},
+		{"2", "o'brien", "3"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "insert into customer(id, `name`, `order`) values (1, 'gopherson', null), (2, 'o\\'brien', 3)", got)
+
+	_, err = csvInsert("customer", header, columns, [][]string{{"one", "gopherson", "1"}})
+	assert.ErrorContains(t, err, "invalid value for column id")
+
+	_, err = csvInsert("customer", header, columns, [][]string{{"1", "gopherson"}})
+	assert.ErrorContains(t, err, "has 2 values, expected 3")
+}
+
+func TestWriteCSV(t *testing.T) {
+	qr := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("id|name|age", "int64|varchar|int16"),
+		"1|gopherson|null",
+		"2|o'brien, jr|42",
+	)
+	var buf bytes.Buffer
+	require.NoError(t, writeCSV(&buf, qr))
+	assert.Equal(t, "id,name,age\n1,gopherson,\\N\n2,\"o'brien, jr\",42\n", buf.String())
+}
@@ -73,6 +73,14 @@ type Config struct {
 	// as the VSchema for the V3 API
 	SchemaDir string
 
+	// FixturesDir is the directory of the data the cluster is seeded with,
+	// after the schema files are executed. Within this dir, there should be
+	// a subdir for each keyspace. Within each keyspace dir, the .sql files
+	// are executed, and the rows of the .csv files replace the rows of the
+	// table named after them. A snapshot written by ExportSnapshot can be
+	// used as fixtures.
+	FixturesDir string
+
 	// DefaultSchemaDir is the default directory for initial schema files.
 	// If no schema is found in SchemaDir, default to this location.
 	DefaultSchemaDir string
@@ -101,11 +109,13 @@ type Config struct {
 	OnlyMySQL bool
 
 	// PersistentMode can be set so that MySQL data directory is not cleaned up
-	// when LocalCluster.TearDown() is called. This is useful for running
+	// when LocalCluster.TearDown() is called, and vtcombo keeps its keyspaces,
+	// vschemas and routing rules across restarts. This is useful for running
 	// vttestserver as a database container in local developer environments. Note
-	// that db and vschema migration files (-schema_dir option) and seeding of
-	// random data (-initialize_with_random_data option) will only run during
-	// cluster startup if the data directory does not already exist.
+	// that db and vschema migration files (-schema_dir option), seeding of
+	// random data (-initialize_with_random_data option) and fixtures
+	// (-fixtures_dir option) will only run during cluster startup if the data
+	// directory does not already exist.
 	PersistentMode bool
 
 	// MySQL protocol bind address.
@@ -309,6 +319,8 @@ func (db *LocalCluster) Setup() error {
 	if db.PersistentMode && dirExist(db.mysql.TabletDir()) {
 		initializing = false
 	}
+	// vtcombo restores the vschemas it saved in persistent mode.
+	vschemaRestored := db.PersistentMode && fileExist(path.Join(vtcomboPersistentDataDir(db.Env), "topology.json"))
 
 	if initializing {
 		log.Infof("Initializing MySQL Manager (%T)...", db.mysql)
@@ -359,11 +371,20 @@ func (db *LocalCluster) Setup() error {
 				return err
 			}
 		}
-	} else {
+
+		if db.FixturesDir != "" {
+			log.Info("Loading fixtures...")
+			if err := db.LoadFixtures(db.FixturesDir); err != nil {
+				return err
+			}
+		}
+	} else if !vschemaRestored {
 		log.Info("Mysql data directory exists in persistent mode. Will only execute vschema migrations during startup")
 		if err := db.loadSchema(false); err != nil {
 			return err
 		}
+	} else {
+		log.Info("Mysql data directory exists in persistent mode, and vtcombo restored its vschemas. Skipping migrations")
 	}
 
 	return nil
@@ -593,6 +614,11 @@ func (db *LocalCluster) reloadSchemaKeyspace(keyspace string) error {
 	return err
 }
 
+func fileExist(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
 func dirExist(dir string) bool {
 	exist := true
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+// This file implements the client of the API vtcombo exposes to simulate
+// tablet failures, reparents and reshardings while the cluster runs, so
+// that the tests of an application can check how its clients recover.
+
+// FailTablet makes a tablet stop serving queries, as if it had failed.
+// The alias is in the cell-uid form, e.g. "test-0000000001".
+func (db *LocalCluster) FailTablet(alias string) error {
+	return db.simulate("tablet/fail", url.Values{"alias": {alias}})
+}
+
+// RecoverTablet makes a tablet that failed with FailTablet serve queries
+// again.
+func (db *LocalCluster) RecoverTablet(alias string) error {
+	return db.simulate("tablet/recover", url.Values{"alias": {alias}})
+}
+
+// ReparentShard demotes the primary of a shard, and promotes newPrimary, or
+// another replica of the shard if newPrimary is empty.
+func (db *LocalCluster) ReparentShard(keyspace, shard, newPrimary string) error {
+	params := url.Values{"keyspace": {keyspace}, "shard": {shard}}
+	if newPrimary != "" {
+		params.Set("new_primary", newPrimary)
+	}
+	return db.simulate("reparent", params)
+}
+
+// Reshard moves the rows of a keyspace into the given shards, which must
+// cover the whole keyspace, and replaces its shards with them.
+func (db *LocalCluster) Reshard(keyspace string, shards []string) error {
+	if err := db.simulate("reshard", url.Values{"keyspace": {keyspace}, "shards": {strings.Join(shards, ",")}}); err != nil {
+		return err
+	}
+	for _, kpb := range db.Topology.Keyspaces {
+		if kpb.Name == keyspace {
+			kpb.Shards = kpb.Shards[:0]
+			for _, shard := range shards {
+				kpb.Shards = append(kpb.Shards, &vttestpb.Shard{Name: shard})
+			}
+		}
+	}
+	return nil
+}
+
+func (db *LocalCluster) simulate(action string, params url.Values) error {
+	resp, err := http.PostForm(fmt.Sprintf("http://%s/vttest/%s", db.vt.Address(), action), params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s failed: %s", action, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
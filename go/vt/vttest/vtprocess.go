@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
 	"syscall"
 	"time"
@@ -189,6 +190,12 @@ var QueryServerArgs = []string{
 	"--queryserver-config-txpool-timeout", "300",
 }
 
+// vtcomboPersistentDataDir returns the directory where vtcombo saves its
+// topology in persistent mode.
+func vtcomboPersistentDataDir(environment Environment) string {
+	return path.Join(environment.Directory(), "vtcombo")
+}
+
 // VtcomboProcess returns a VtProcess handle for a local `vtcombo` service,
 // configured with the given Config.
 // The process must be manually started by calling WaitStart()
@@ -241,6 +248,9 @@ func VtcomboProcess(environment Environment, args *Config, mysql MySQLManager) (
 	if args.SchemaDir != "" {
 		vt.ExtraArgs = append(vt.ExtraArgs, []string{"--schema_dir", args.SchemaDir}...)
 	}
+	if args.PersistentMode {
+		vt.ExtraArgs = append(vt.ExtraArgs, []string{"--persistent_data_dir", vtcomboPersistentDataDir(environment)}...)
+	}
 	if args.TransactionMode != "" {
 		vt.ExtraArgs = append(vt.ExtraArgs, []string{"--transaction_mode", args.TransactionMode}...)
 	}
@@ -67,6 +67,16 @@ func verifyPeerCertificateAgainstCRL(crl string) (verifyPeerCertificateFunc, err
 }
 
 func loadCRLSet(crl string) ([]*pkix.CertificateList, error) {
+	result, err := loadCached(tlsCertificatesIdentifier("crl", crl), []string{crl}, func() (any, error) {
+		return doLoadCRLSet(crl)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*pkix.CertificateList), nil
+}
+
+func doLoadCRLSet(crl string) ([]*pkix.CertificateList, error) {
 	body, err := os.ReadFile(crl)
 	if err != nil {
 		return nil, err
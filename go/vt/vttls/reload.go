@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"os"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	// CertificateReloadInterval is how often the loaded certificates, keys,
+	// CAs and CRLs are checked for changes. Zero disables the checks.
+	CertificateReloadInterval = flag.Duration("tls_certificate_reload_interval", 0, "How often the TLS certificates, keys, CAs and CRLs files are checked for changes, and reloaded if they changed, for the new connections of all the servers and clients. Zero disables the checks, the files are then only reloaded on SIGHUP by the servers that support it")
+
+	certificateExpiry = stats.NewGaugesWithSingleLabel(
+		"TLSCertificateExpiry",
+		"Unix time at which the first of the certificates of each loaded TLS file expires",
+		"File")
+	certificateReloads = stats.NewCountersWithSingleLabel(
+		"TLSCertificateReloads",
+		"Number of times the TLS files were reloaded because they changed",
+		"File")
+	certificateReloadErrors = stats.NewCountersWithSingleLabel(
+		"TLSCertificateReloadErrors",
+		"Number of times the TLS files changed but could not be reloaded. The previous version of the files is still used",
+		"File")
+)
+
+// cachedFiles is a value loaded from a set of files, e.g. a certificate and
+// its key, along with the contents of the files it was loaded from, so it
+// can be reloaded when they change.
+type cachedFiles struct {
+	value    any
+	contents [][]byte
+	checked  time.Time
+	stale    bool
+}
+
+var (
+	filesMu    sync.Mutex
+	filesCache = make(map[string]*cachedFiles)
+)
+
+// ReloadCertificates makes the next configs check the loaded files for
+// changes, regardless of --tls_certificate_reload_interval.
+func ReloadCertificates() {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+
+	for _, entry := range filesCache {
+		entry.stale = true
+	}
+}
+
+// loadCached returns the value loaded from files with load, caching it under
+// key. Once the reload interval elapsed, the files are read again, and the
+// value is reloaded if they changed. If the reload fails, e.g. because a
+// certificate was rotated but not its key yet, the previous value is kept.
+func loadCached(key string, files []string, load func() (any, error)) (any, error) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+
+	now := time.Now()
+	entry, ok := filesCache[key]
+	if ok {
+		if !entry.stale && (*CertificateReloadInterval <= 0 || now.Sub(entry.checked) < *CertificateReloadInterval) {
+			return entry.value, nil
+		}
+		entry.checked = now
+		entry.stale = false
+
+		contents, err := readFiles(files)
+		if err == nil && equalContents(contents, entry.contents) {
+			return entry.value, nil
+		}
+		var value any
+		if err == nil {
+			value, err = load()
+		}
+		if err != nil {
+			log.Warningf("Failed to reload the TLS files %v, the previous version is still used: %v", files, err)
+			for _, file := range files {
+				certificateReloadErrors.Add(file, 1)
+			}
+			return entry.value, nil
+		}
+
+		log.Infof("Reloaded the TLS files %v", files)
+		for _, file := range files {
+			certificateReloads.Add(file, 1)
+		}
+		entry.value = value
+		entry.contents = contents
+		recordCertificateExpiry(files, contents)
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	// The contents are only compared on reload, so a failure to read them
+	// here only triggers a reload on the next check.
+	contents, _ := readFiles(files)
+	filesCache[key] = &cachedFiles{
+		value:    value,
+		contents: contents,
+		checked:  now,
+	}
+	recordCertificateExpiry(files, contents)
+	return value, nil
+}
+
+func readFiles(files []string) ([][]byte, error) {
+	contents := make([][]byte, 0, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, b)
+	}
+	return contents, nil
+}
+
+func equalContents(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordCertificateExpiry exports the expiry of the first certificate to
+// expire of each of the files that contain certificates.
+func recordCertificateExpiry(files []string, contents [][]byte) {
+	if len(files) != len(contents) {
+		return
+	}
+	for i, file := range files {
+		var expiry time.Time
+		rest := contents[i]
+		for len(rest) > 0 {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+			if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+				expiry = cert.NotAfter
+			}
+		}
+		if !expiry.IsZero() {
+			certificateExpiry.Set(file, expiry.Unix())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/tlstest"
+)
+
+func copyFile(t *testing.T, from, to string) {
+	b, err := os.ReadFile(from)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(to, b, 0600))
+}
+
+func serverCommonName(t *testing.T, cert, key string) string {
+	config, err := ServerConfig(cert, key, "", "", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertificateReload(t *testing.T) {
+	defer func(interval time.Duration) {
+		*CertificateReloadInterval = interval
+	}(*CertificateReloadInterval)
+	*CertificateReloadInterval = 0
+
+	root := t.TempDir()
+	first := tlstest.CreateClientServerCertPairs(root)
+	second := tlstest.CreateClientServerCertPairs(root)
+
+	dir := t.TempDir()
+	cert := path.Join(dir, "server-cert.pem")
+	key := path.Join(dir, "server-key.pem")
+	copyFile(t, first.ServerCert, cert)
+	copyFile(t, first.ServerKey, key)
+	assert.Equal(t, first.ServerName, serverCommonName(t, cert, key))
+	assert.Greater(t, certificateExpiry.Counts()[cert], time.Now().Unix())
+
+	// The files are not checked for changes without a reload interval.
+	copyFile(t, second.ServerCert, cert)
+	copyFile(t, second.ServerKey, key)
+	assert.Equal(t, first.ServerName, serverCommonName(t, cert, key))
+
+	// Unless a reload is requested, e.g. on SIGHUP.
+	ReloadCertificates()
+	assert.Equal(t, second.ServerName, serverCommonName(t, cert, key))
+	assert.Equal(t, int64(1), certificateReloads.Counts()[cert])
+	assert.Equal(t, int64(1), certificateReloads.Counts()[key])
+
+	// The previous certificate is kept if the new files can't be loaded,
+	// e.g. if the certificate was rotated but not the key yet.
+	*CertificateReloadInterval = time.Nanosecond
+	copyFile(t, first.ServerCert, cert)
+	assert.Equal(t, second.ServerName, serverCommonName(t, cert, key))
+	assert.Equal(t, int64(1), certificateReloadErrors.Counts()[cert])
+
+	copyFile(t, first.ServerKey, key)
+	assert.Equal(t, first.ServerName, serverCommonName(t, cert, key))
+	assert.Equal(t, int64(2), certificateReloads.Counts()[cert])
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// FollowerWorkflow is the name of the workflow that replicates a keyspace
+// into its follower keyspace.
+const FollowerWorkflow = "follower"
+
+// follower replicates all the tables of a source keyspace, possibly in
+// another Vitess cluster, into a target keyspace with the same shards.
+type follower struct {
+	wr                *Wrangler
+	sourceKeyspace    string
+	targetKeyspace    string
+	externalCluster   string
+	cell, tabletTypes string
+	// sourceShards maps the name of each target shard to its source shard.
+	sourceShards    map[string]*topo.ShardInfo
+	targetShards    []*topo.ShardInfo
+	targetPrimaries map[string]*topo.TabletInfo
+	sourcePrimary   *topo.TabletInfo
+}
+
+// CreateFollower makes targetKeyspace a read-only follower of sourceKeyspace:
+// the schema of the source keyspace is copied to the target keyspace, whose
+// shards must match the ones of the source keyspace, and a workflow named
+// FollowerWorkflow continuously replicates all the tables of the source
+// keyspace, including the ones created later on. The primaries of the target
+// keyspace don't serve queries, so the follower keyspace is only readable
+// from its replica and rdonly tablets, until it is promoted with
+// PromoteFollower. If externalCluster is set, the source keyspace is in that
+// mounted Vitess cluster.
+func (wr *Wrangler) CreateFollower(ctx context.Context, sourceKeyspace, targetKeyspace, externalCluster, cell, tabletTypes string, autoStart bool) error {
+	if sourceKeyspace == targetKeyspace && externalCluster == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a keyspace cannot follow itself: %s", sourceKeyspace)
+	}
+	if externalCluster != "" {
+		externalTopo, err := wr.ts.OpenExternalVitessClusterServer(ctx, externalCluster)
+		if err != nil {
+			return err
+		}
+		wr.sourceTs = externalTopo
+		log.Infof("Successfully opened external topo: %+v", externalTopo)
+	}
+	if err := wr.validateNewWorkflow(ctx, targetKeyspace, FollowerWorkflow); err != nil {
+		return err
+	}
+	f, err := wr.buildFollower(ctx, sourceKeyspace, targetKeyspace, externalCluster, cell, tabletTypes)
+	if err != nil {
+		return err
+	}
+	if err := f.copySchema(ctx); err != nil {
+		return vterrors.Wrap(err, "copySchema")
+	}
+	if err := f.createStreams(ctx); err != nil {
+		return vterrors.Wrap(err, "createStreams")
+	}
+	if err := wr.setFollowerServing(ctx, targetKeyspace, f.targetShards, false); err != nil {
+		return vterrors.Wrap(err, "setFollowerServing")
+	}
+	if !autoStart {
+		wr.Logger().Infof("Follower workflow of keyspace %s has been created in the stopped state", targetKeyspace)
+		return nil
+	}
+	_, err = wr.WorkflowAction(ctx, FollowerWorkflow, targetKeyspace, "start", false)
+	return err
+}
+
+// FollowerStatus returns the state and the replication lag of the streams
+// replicating the source keyspace into the follower keyspace targetKeyspace.
+// If maxLag is not zero, it also returns an error if a stream is not running,
+// or if the follower keyspace lags more than maxLag behind its source.
+func (wr *Wrangler) FollowerStatus(ctx context.Context, targetKeyspace string, maxLag time.Duration) (*ReplicationStatusResult, error) {
+	status, err := wr.ShowWorkflow(ctx, FollowerWorkflow, targetKeyspace)
+	if err != nil || maxLag == 0 {
+		return status, err
+	}
+	for shard, shardStatus := range status.ShardStatuses {
+		for _, stream := range shardStatus.PrimaryReplicationStatuses {
+			if stream.State != "Running" {
+				return status, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "stream %d of follower keyspace %s on %s is %s: %s", stream.ID, targetKeyspace, shard, stream.State, stream.Message)
+			}
+		}
+	}
+	if lag := time.Duration(status.MaxVReplicationTransactionLag) * time.Second; lag > maxLag {
+		return status, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "follower keyspace %s lags %v behind its source, more than %v", targetKeyspace, lag, maxLag)
+	}
+	return status, nil
+}
+
+// PromoteFollower stops the replication of the source keyspace into the
+// follower keyspace targetKeyspace, and makes its primaries serve queries
+// again, so that it can be written to. The data that was not replicated
+// yet is lost, so the source keyspace should not be written to anymore.
+func (wr *Wrangler) PromoteFollower(ctx context.Context, targetKeyspace string) error {
+	if _, err := wr.ShowWorkflow(ctx, FollowerWorkflow, targetKeyspace); err != nil {
+		return err
+	}
+	if _, err := wr.WorkflowAction(ctx, FollowerWorkflow, targetKeyspace, "delete", false); err != nil {
+		return err
+	}
+	shards, err := wr.ts.FindAllShardsInKeyspace(ctx, targetKeyspace)
+	if err != nil {
+		return err
+	}
+	targetShards := make([]*topo.ShardInfo, 0, len(shards))
+	for _, si := range shards {
+		targetShards = append(targetShards, si)
+	}
+	return wr.setFollowerServing(ctx, targetKeyspace, targetShards, true)
+}
+
+// setFollowerServing enables or disables the query service of the primaries
+// of a follower keyspace, and refreshes them.
+func (wr *Wrangler) setFollowerServing(ctx context.Context, keyspace string, shards []*topo.ShardInfo, serving bool) (err error) {
+	ctx, unlock, lockErr := wr.ts.LockKeyspace(ctx, keyspace, "Follower")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	if err := wr.ts.UpdateDisableQueryService(ctx, keyspace, shards, topodatapb.TabletType_PRIMARY, nil, !serving); err != nil {
+		return err
+	}
+	return wr.refreshPrimaryTablets(ctx, shards)
+}
+
+func (wr *Wrangler) buildFollower(ctx context.Context, sourceKeyspace, targetKeyspace, externalCluster, cell, tabletTypes string) (*follower, error) {
+	f := &follower{
+		wr:              wr,
+		sourceKeyspace:  sourceKeyspace,
+		targetKeyspace:  targetKeyspace,
+		externalCluster: externalCluster,
+		cell:            cell,
+		tabletTypes:     tabletTypes,
+		sourceShards:    make(map[string]*topo.ShardInfo),
+		targetPrimaries: make(map[string]*topo.TabletInfo),
+	}
+	sourceShards, err := wr.sourceTs.GetServingShards(ctx, sourceKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	f.targetShards, err = wr.ts.GetServingShards(ctx, targetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(sourceShards) != len(f.targetShards) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has %d shards, but its source keyspace %s has %d: they must have the same shards", targetKeyspace, len(f.targetShards), sourceKeyspace, len(sourceShards))
+	}
+	for _, target := range f.targetShards {
+		var source *topo.ShardInfo
+		for _, si := range sourceShards {
+			if key.KeyRangeEqual(si.KeyRange, target.KeyRange) {
+				source = si
+				break
+			}
+		}
+		if source == nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "shard %s/%s has no matching shard in source keyspace %s", targetKeyspace, target.ShardName(), sourceKeyspace)
+		}
+		f.sourceShards[target.ShardName()] = source
+		if target.PrimaryAlias == nil {
+			return nil, fmt.Errorf("target shard %v has no primary", target.ShardName())
+		}
+		primary, err := wr.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "GetTablet(%v) failed", target.PrimaryAlias)
+		}
+		f.targetPrimaries[target.ShardName()] = primary
+	}
+	if sourceShards[0].PrimaryAlias == nil {
+		return nil, fmt.Errorf("source shard must have a primary for copying schema: %v", sourceShards[0].ShardName())
+	}
+	f.sourcePrimary, err = wr.sourceTs.GetTablet(ctx, sourceShards[0].PrimaryAlias)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "GetTablet(%v) failed", sourceShards[0].PrimaryAlias)
+	}
+	return f, nil
+}
+
+// copySchema creates the tables of the source keyspace that don't exist yet
+// in the shards of the target keyspace.
+func (f *follower) copySchema(ctx context.Context) error {
+	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: []string{"/.*/"}}
+	sourceSchema, err := f.wr.tmc.GetSchema(ctx, f.sourcePrimary.Tablet, req)
+	if err != nil {
+		return err
+	}
+	return f.forAllTargets(func(target *topo.ShardInfo) error {
+		targetPrimary := f.targetPrimaries[target.ShardName()]
+		targetSchema, err := f.wr.tmc.GetSchema(ctx, targetPrimary.Tablet, req)
+		if err != nil {
+			return err
+		}
+		hasTargetTable := make(map[string]bool, len(targetSchema.TableDefinitions))
+		for _, td := range targetSchema.TableDefinitions {
+			hasTargetTable[td.Name] = true
+		}
+		var applyDDLs []string
+		for _, td := range sourceSchema.TableDefinitions {
+			if hasTargetTable[td.Name] {
+				continue
+			}
+			applyDDLs = append(applyDDLs, td.Schema)
+		}
+		if len(applyDDLs) == 0 {
+			return nil
+		}
+		_, err = f.wr.tmc.ApplySchema(ctx, targetPrimary.Tablet, &tmutils.SchemaChange{
+			SQL:              strings.Join(applyDDLs, ";\n"),
+			Force:            false,
+			AllowReplication: true,
+			SQLMode:          vreplication.SQLMode,
+		})
+		return err
+	})
+}
+
+// createStreams creates, in the stopped state, a stream from each source
+// shard to the matching target shard. The streams replicate all the tables
+// and apply the DDLs of the source, so that the tables created later on are
+// replicated too.
+func (f *follower) createStreams(ctx context.Context) error {
+	return f.forAllTargets(func(target *topo.ShardInfo) error {
+		targetPrimary := f.targetPrimaries[target.ShardName()]
+		ig := vreplication.NewInsertGenerator(binlogplayer.BlpStopped, targetPrimary.DbName())
+		bls := &binlogdatapb.BinlogSource{
+			Keyspace: f.sourceKeyspace,
+			Shard:    f.sourceShards[target.ShardName()].ShardName(),
+			Filter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: "/.*"}},
+			},
+			OnDdl:           binlogdatapb.OnDDLAction_EXEC,
+			ExternalCluster: f.externalCluster,
+		}
+		ig.AddRow(FollowerWorkflow, bls, "", f.cell, f.tabletTypes)
+		query := ig.String()
+		if _, err := f.wr.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query); err != nil {
+			return vterrors.Wrapf(err, "VReplicationExec(%v, %s)", targetPrimary.Tablet, query)
+		}
+		return nil
+	})
+}
+
+func (f *follower) forAllTargets(fn func(*topo.ShardInfo) error) error {
+	var wg sync.WaitGroup
+	allErrors := &concurrency.AllErrorRecorder{}
+	for _, target := range f.targetShards {
+		wg.Add(1)
+		go func(target *topo.ShardInfo) {
+			defer wg.Done()
+
+			if err := fn(target); err != nil {
+				allErrors.RecordError(err)
+			}
+		}(target)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
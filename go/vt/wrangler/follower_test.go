@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topotools"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestCreateFollower(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       FollowerWorkflow,
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"-80", "80-"}, []string{"-80", "80-"})
+	defer env.close()
+
+	ctx := context.Background()
+	require.NoError(t, topotools.RebuildKeyspace(ctx, logutil.NewConsoleLogger(), env.topoServ, "targetks", []string{env.cell}, false))
+	for _, table := range []string{"sourceks.t1", "sourceks.t2", "targetks.t2"} {
+		env.tmc.schema[table] = &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+				Name:   table[len(table)-2:],
+				Schema: "create table " + table[len(table)-2:] + " (id int primary key)",
+			}},
+		}
+	}
+
+	for _, tabletID := range []int{200, 210} {
+		shard := "-80"
+		if tabletID == 210 {
+			shard = "80-"
+		}
+		env.tmc.expectVRQuery(tabletID, mzSelectFrozenQuery, &sqltypes.Result{})
+		// Only t1 is missing in the target keyspace.
+		env.tmc.expectVRQuery(tabletID, "create table t1 (id int primary key)", &sqltypes.Result{})
+		env.tmc.expectVRQuery(
+			tabletID,
+			insertPrefix+
+				`\('follower', 'keyspace:\\"sourceks\\" shard:\\"`+shard+`\\" filter:{rules:{match:\\"/\.\*\\"}} on_ddl:EXEC', '', [0-9]*, [0-9]*, 'zone1', 'replica', [0-9]*, 0, 'Stopped', 'vt_targetks'\)`+eol,
+			&sqltypes.Result{},
+		)
+	}
+
+	err := env.wr.CreateFollower(ctx, "sourceks", "targetks", "", "zone1", "replica", false)
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+
+	srvKeyspace, err := env.topoServ.GetSrvKeyspace(ctx, env.cell, "targetks")
+	require.NoError(t, err)
+	// Only the primaries don't serve queries.
+	var disabled []string
+	for _, partition := range srvKeyspace.Partitions {
+		for _, shardTabletControl := range partition.ShardTabletControls {
+			if shardTabletControl.QueryServiceDisabled {
+				disabled = append(disabled, partition.ServedType.String()+" "+shardTabletControl.Name)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"PRIMARY -80", "PRIMARY 80-"}, disabled)
+}
+
+func TestCreateFollowerShardMismatch(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       FollowerWorkflow,
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"-80", "80-"})
+	defer env.close()
+	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(210, mzSelectFrozenQuery, &sqltypes.Result{})
+
+	err := env.wr.CreateFollower(context.Background(), "sourceks", "targetks", "", "", "", false)
+	require.EqualError(t, err, "keyspace targetks has 2 shards, but its source keyspace sourceks has 1: they must have the same shards")
+}
@@ -68,6 +68,7 @@ const (
 	createDDLAsCopy                = "copy"
 	createDDLAsCopyDropConstraint  = "copy:drop_constraint"
 	createDDLAsCopyDropForeignKeys = "copy:drop_foreign_keys"
+	createDDLAsCopyTranslate       = "copy:translate"
 )
 
 // addTablesToVSchema adds tables to an (unsharded) vschema. Depending on copyAttributes It will also add any sequence info
@@ -121,7 +122,7 @@ func shouldInclude(table string, excludes []string) bool {
 // MoveTables initiates moving table(s) over to another keyspace
 func (wr *Wrangler) MoveTables(ctx context.Context, workflow, sourceKeyspace, targetKeyspace, tableSpecs,
 	cell, tabletTypes string, allTables bool, excludeTables string, autoStart, stopAfterCopy bool,
-	externalCluster string, dropForeignKeys bool, sourceTimeZone string) error {
+	externalCluster string, dropForeignKeys bool, sourceTimeZone string, translateSourceSchema bool) error {
 	//FIXME validate tableSpecs, allTables, excludeTables
 	var tables []string
 	var externalTopo *topo.Server
@@ -256,6 +257,10 @@ func (wr *Wrangler) MoveTables(ctx context.Context, workflow, sourceKeyspace, ta
 	if dropForeignKeys {
 		createDDLMode = createDDLAsCopyDropForeignKeys
 	}
+	if translateSourceSchema {
+		// The translation also removes the foreign keys.
+		createDDLMode = createDDLAsCopyTranslate
+	}
 
 	for _, table := range tables {
 		buf := sqlparser.NewTrackedBuffer(nil)
@@ -918,7 +923,17 @@ func (wr *Wrangler) prepareMaterializerStreams(ctx context.Context, ms *vtctldat
 }
 
 // Materialize performs the steps needed to materialize a list of tables based on the materialization specs.
+// If ms.ExternalCluster is set, the source keyspace is in that Vitess cluster, which must have been mounted
+// with MountExternalVitessCluster, and the target tables are kept up to date with its data.
 func (wr *Wrangler) Materialize(ctx context.Context, ms *vtctldatapb.MaterializeSettings) error {
+	if ms.ExternalCluster != "" {
+		externalTopo, err := wr.ts.OpenExternalVitessClusterServer(ctx, ms.ExternalCluster)
+		if err != nil {
+			return err
+		}
+		wr.sourceTs = externalTopo
+		log.Infof("Successfully opened external topo: %+v", externalTopo)
+	}
 	mz, err := wr.prepareMaterializerStreams(ctx, ms)
 	if err != nil {
 		return err
@@ -1026,6 +1041,9 @@ func (mz *materializer) deploySchema(ctx context.Context) error {
 				//we copy schemas from primaries on the source keyspace
 				//and we have found use cases where user just has a replica (no primary) in the source keyspace
 				sourceDDLs, err = mz.getSourceTableDDLs(ctx)
+				if err == nil {
+					err = mz.translateSourceTableDDLs(sourceDDLs)
+				}
 			}
 			mu.Unlock()
 			if err != nil {
@@ -1034,7 +1052,7 @@ func (mz *materializer) deploySchema(ctx context.Context) error {
 			}
 
 			createDDL := ts.CreateDdl
-			if createDDL == createDDLAsCopy || createDDL == createDDLAsCopyDropConstraint || createDDL == createDDLAsCopyDropForeignKeys {
+			if createDDL == createDDLAsCopy || createDDL == createDDLAsCopyDropConstraint || createDDL == createDDLAsCopyDropForeignKeys || createDDL == createDDLAsCopyTranslate {
 				if ts.SourceExpression != "" {
 					// Check for table if non-empty SourceExpression.
 					sourceTableName, err := sqlparser.TableFromStatement(ts.SourceExpression)
@@ -1093,6 +1111,81 @@ func (mz *materializer) deploySchema(ctx context.Context) error {
 	})
 }
 
+// translateSourceTableDDLs translates in place the definitions of the source
+// tables that are created as translated copies, and reports the changes. The
+// definitions are translated once for all the target shards.
+func (mz *materializer) translateSourceTableDDLs(sourceDDLs map[string]string) error {
+	for _, ts := range mz.ms.TableSettings {
+		if ts.CreateDdl != createDDLAsCopyTranslate {
+			continue
+		}
+		ddl, ok := sourceDDLs[ts.TargetTable]
+		if !ok {
+			continue
+		}
+		translatedDDL, changes, err := translateTableDDL(ddl)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot translate the definition of table %s", ts.TargetTable)
+		}
+		for _, change := range changes {
+			mz.wr.Logger().Warningf("Table %s is created on the target with a different definition than on the source: %s", ts.TargetTable, change)
+		}
+		sourceDDLs[ts.TargetTable] = translatedDDL
+	}
+	return nil
+}
+
+// translateTableDDL translates the definition of a table of a source that is
+// not managed by Vitess, such as a cloud-managed database, into a definition
+// that can be created in the target keyspace. The storage engine becomes
+// InnoDB, the foreign keys are removed, and so are the options that depend on
+// the host of the source, such as its directories and tablespaces. It returns
+// the translated definition along with a description of every change. Views
+// are left as they are.
+func translateTableDDL(ddl string) (string, []string, error) {
+	stmt, err := sqlparser.ParseStrictDDL(ddl)
+	if err != nil {
+		return "", nil, err
+	}
+	create, ok := stmt.(*sqlparser.CreateTable)
+	if !ok || create.TableSpec == nil {
+		return ddl, nil, nil
+	}
+
+	var changes []string
+	spec := create.TableSpec
+	var constraints []*sqlparser.ConstraintDefinition
+	for _, constraint := range spec.Constraints {
+		if _, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition); ok {
+			changes = append(changes, fmt.Sprintf("foreign key %s removed", constraint.Name.String()))
+			continue
+		}
+		constraints = append(constraints, constraint)
+	}
+	spec.Constraints = constraints
+
+	var options sqlparser.TableOptions
+	for _, option := range spec.Options {
+		switch strings.ToLower(option.Name) {
+		case "engine":
+			if !strings.EqualFold(option.String, "InnoDB") {
+				changes = append(changes, fmt.Sprintf("engine %s replaced by InnoDB", option.String))
+				option.String = "InnoDB"
+			}
+		case "data directory", "index directory", "tablespace", "encryption", "connection", "union", "insert_method":
+			changes = append(changes, fmt.Sprintf("option %s removed", strings.ToUpper(option.Name)))
+			continue
+		}
+		options = append(options, option)
+	}
+	spec.Options = options
+
+	if len(changes) == 0 {
+		return ddl, nil, nil
+	}
+	return sqlparser.String(create), changes, nil
+}
+
 func stripTableForeignKeys(ddl string) (string, error) {
 
 	ast, err := sqlparser.ParseStrictDDL(ddl)
@@ -285,3 +285,7 @@ func (tmc *testMaterializerTMClient) ApplySchema(ctx context.Context, tablet *to
 
 	return nil, nil
 }
+
+func (tmc *testMaterializerTMClient) RefreshState(ctx context.Context, tablet *topodatapb.Tablet) error {
+	return nil
+}
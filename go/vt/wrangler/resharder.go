@@ -297,7 +297,10 @@ func (rs *resharder) copySchema(ctx context.Context) error {
 func (rs *resharder) createStreams(ctx context.Context) error {
 	var excludeRules []*binlogdatapb.Rule
 	for tableName, table := range rs.vschema.Tables {
-		if table.Type == vindexes.TypeReference {
+		// The blocks of a sharded sequence are interleaved based on the
+		// number of shards, so its tables are reseeded when the writes are
+		// switched instead of being copied.
+		if table.Type == vindexes.TypeReference || table.Type == vindexes.TypeSequence {
 			excludeRules = append(excludeRules, &binlogdatapb.Rule{
 				Match:  tableName,
 				Filter: "exclude",
@@ -29,6 +29,7 @@ import (
 
 	"vitess.io/vitess/go/sqltypes"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -241,6 +242,107 @@ func TestResharderOneRefTable(t *testing.T) {
 	env.tmc.verifyQueries(t)
 }
 
+func TestResharderOneSequenceTable(t *testing.T) {
+	env := newTestResharderEnv(t, []string{"0"}, []string{"-80", "80-"})
+	defer env.close()
+
+	schm := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:              "t1_seq",
+			Columns:           []string{"id", "next_id", "cache", "stride"},
+			PrimaryKeyColumns: []string{"id"},
+			Fields:            sqltypes.MakeTestFields("id|next_id|cache|stride", "int64|int64|int64|int64"),
+		}},
+	}
+	env.tmc.schema = schm
+
+	vs := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1_seq": {
+				Type: vindexes.TypeSequence,
+			},
+		},
+	}
+	if err := env.wr.ts.SaveVSchema(context.Background(), env.keyspace, vs); err != nil {
+		t.Fatal(err)
+	}
+
+	env.expectValidation()
+	env.expectNoRefStream()
+
+	env.tmc.expectVRQuery(
+		200,
+		insertPrefix+
+			`\('resharderTest', 'keyspace:\\"ks\\" shard:\\"0\\" filter:{rules:{match:\\"t1_seq\\" filter:\\"exclude\\"} rules:{match:\\"/.*\\" filter:\\"-80\\"}}', '', [0-9]*, [0-9]*, '', '', [0-9]*, 0, 'Stopped', 'vt_ks'\)`+
+			eol,
+		&sqltypes.Result{},
+	)
+	env.tmc.expectVRQuery(
+		210,
+		insertPrefix+
+			`\('resharderTest', 'keyspace:\\"ks\\" shard:\\"0\\" filter:{rules:{match:\\"t1_seq\\" filter:\\"exclude\\"} rules:{match:\\"/.*\\" filter:\\"80-\\"}}', '', [0-9]*, [0-9]*, '', '', [0-9]*, 0, 'Stopped', 'vt_ks'\)`+
+			eol,
+		&sqltypes.Result{},
+	)
+
+	env.tmc.expectVRQuery(200, "update _vt.vreplication set state='Running' where db_name='vt_ks'", &sqltypes.Result{})
+	env.tmc.expectVRQuery(210, "update _vt.vreplication set state='Running' where db_name='vt_ks'", &sqltypes.Result{})
+
+	err := env.wr.Reshard(context.Background(), env.keyspace, env.workflow, env.sources, env.targets, true, "", "", true, false)
+	assert.NoError(t, err)
+	env.tmc.verifyQueries(t)
+}
+
+// TestResharderShardedSequenceTable tests that the tables of an interleaved
+// sequence are not copied when resharding a sharded keyspace.
+func TestResharderShardedSequenceTable(t *testing.T) {
+	env := newTestResharderEnv(t, []string{"-80", "80-"}, []string{"-40", "40-80", "80-c0", "c0-"})
+	defer env.close()
+
+	schm := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:              "t1_seq",
+			Columns:           []string{"id", "next_id", "cache", "stride"},
+			PrimaryKeyColumns: []string{"id"},
+			Fields:            sqltypes.MakeTestFields("id|next_id|cache|stride", "int64|int64|int64|int64"),
+		}},
+	}
+	env.tmc.schema = schm
+
+	vs := &vschemapb.Keyspace{
+		Sharded: true,
+		Tables: map[string]*vschemapb.Table{
+			"t1_seq": {
+				Type:    vindexes.TypeSequence,
+				Columns: []*vschemapb.Column{{Name: "stride", Type: querypb.Type_INT64}},
+			},
+		},
+	}
+	if err := env.wr.ts.SaveVSchema(context.Background(), env.keyspace, vs); err != nil {
+		t.Fatal(err)
+	}
+
+	env.expectValidation()
+	env.expectNoRefStream()
+
+	for i, shards := range [][2]string{{"-80", "-40"}, {"-80", "40-80"}, {"80-", "80-c0"}, {"80-", "c0-"}} {
+		env.tmc.expectVRQuery(
+			200+i*10,
+			insertPrefix+
+				`\('resharderTest', 'keyspace:\\"ks\\" shard:\\"`+shards[0]+`\\" filter:{rules:{match:\\"t1_seq\\" filter:\\"exclude\\"} rules:{match:\\"/.*\\" filter:\\"`+shards[1]+`\\"}}', '', [0-9]*, [0-9]*, '', '', [0-9]*, 0, 'Stopped', 'vt_ks'\)`+
+				eol,
+			&sqltypes.Result{},
+		)
+	}
+	for i := range env.targets {
+		env.tmc.expectVRQuery(200+i*10, "update _vt.vreplication set state='Running' where db_name='vt_ks'", &sqltypes.Result{})
+	}
+
+	err := env.wr.Reshard(context.Background(), env.keyspace, env.workflow, env.sources, env.targets, true, "", "", true, false)
+	assert.NoError(t, err)
+	env.tmc.verifyQueries(t)
+}
+
 // TestReshardStopFlags tests the flags -stop_started and -stop_after_copy
 func TestReshardStopFlags(t *testing.T) {
 	env := newTestResharderEnv(t, []string{"0"}, []string{"-80", "80-"})
@@ -186,6 +186,15 @@ func (dr *switcherDryRun) waitForCatchup(ctx context.Context, filteredReplicatio
 	return nil
 }
 
+func (dr *switcherDryRun) reseedSequences(ctx context.Context) error {
+	names, _, err := dr.ts.sequenceTables(ctx)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	dr.drLog.Log(fmt.Sprintf("Reseed sequences [%s] on the target shards of keyspace %s", strings.Join(names, ","), dr.ts.TargetKeyspaceName()))
+	return nil
+}
+
 func (dr *switcherDryRun) stopSourceWrites(ctx context.Context) error {
 	logs := make([]string, 0)
 	for _, source := range dr.ts.Sources() {
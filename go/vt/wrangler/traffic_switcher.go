@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/discovery"
 
 	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/key"
@@ -40,6 +41,7 @@ import (
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
@@ -485,6 +487,15 @@ func (wr *Wrangler) SwitchWrites(ctx context.Context, targetKeyspace, workflowNa
 		defer targetUnlock(&err)
 	}
 
+	// Let the vtgates buffer the queries to the source shards while their
+	// writes are switched.
+	if !dryRun && !cancel {
+		endTrafficSwitch := ts.signalTrafficSwitch(ctx)
+		defer func() {
+			endTrafficSwitch(err == nil)
+		}()
+	}
+
 	// If no journals exist, sourceWorkflows will be initialized by sm.MigrateStreams.
 	journalsExist, sourceWorkflows, err := ts.checkJournals(ctx)
 	if err != nil {
@@ -546,6 +557,13 @@ func (wr *Wrangler) SwitchWrites(ctx context.Context, targetKeyspace, workflowNa
 			return 0, nil, err
 		}
 
+		ts.Logger().Infof("Reseeding sequences")
+		if err := sw.reseedSequences(ctx); err != nil {
+			ts.Logger().Errorf("reseedSequences failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
 		ts.Logger().Infof("Migrating streams")
 		if err := sw.migrateStreams(ctx, sm); err != nil {
 			ts.Logger().Errorf("migrateStreams failed: %v", err)
@@ -1109,6 +1127,103 @@ func (ts *trafficSwitcher) waitForCatchup(ctx context.Context, filteredReplicati
 	})
 }
 
+// sequenceTables returns the sequence tables of the keyspace being resharded,
+// sorted by name. They are not copied by the resharding streams.
+func (ts *trafficSwitcher) sequenceTables(ctx context.Context) ([]string, map[string]*vschemapb.Table, error) {
+	if ts.MigrationType() != binlogdatapb.MigrationType_SHARDS {
+		return nil, nil, nil
+	}
+	vs, err := ts.TopoServer().GetVSchema(ctx, ts.TargetKeyspaceName())
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	for name, table := range vs.Tables {
+		if table.Type == vindexes.TypeSequence {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, vs.Tables, nil
+}
+
+// reseedSequences initializes the sequence tables of the target shards after
+// a reshard. The next value of each sequence is the highest one of the source
+// shards, so that no value is handed out twice. If the sequence interleaves
+// the blocks of its shards, each target shard starts at its own block and the
+// stride is set to the number of target shards.
+func (ts *trafficSwitcher) reseedSequences(ctx context.Context) error {
+	names, tables, err := ts.sequenceTables(ctx)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	var targets []*workflow.MigrationTarget
+	for _, target := range ts.Targets() {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return key.KeyRangeStartSmaller(targets[i].GetShard().KeyRange, targets[j].GetShard().KeyRange)
+	})
+	for _, name := range names {
+		escaped := sqlescape.EscapeID(name)
+		var mu sync.Mutex
+		var nextID, cache int64
+		found := false
+		err := ts.ForAllSources(func(source *workflow.MigrationSource) error {
+			query := fmt.Sprintf("select next_id, cache from %s where id = 0", escaped)
+			p3qr, err := ts.wr.ExecuteFetchAsDba(ctx, source.GetPrimary().Alias, query, 1, false, false)
+			if err != nil {
+				return vterrors.Wrapf(err, "reading sequence %s on %s/%s", name, ts.SourceKeyspaceName(), source.GetShard().ShardName())
+			}
+			qr := sqltypes.Proto3ToResult(p3qr)
+			if len(qr.Rows) == 0 {
+				return nil
+			}
+			sourceNext, err := evalengine.ToInt64(qr.Rows[0][0])
+			if err != nil {
+				return vterrors.Wrapf(err, "reading sequence %s on %s/%s", name, ts.SourceKeyspaceName(), source.GetShard().ShardName())
+			}
+			sourceCache, err := evalengine.ToInt64(qr.Rows[0][1])
+			if err != nil {
+				return vterrors.Wrapf(err, "reading sequence %s on %s/%s", name, ts.SourceKeyspaceName(), source.GetShard().ShardName())
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !found || sourceNext > nextID {
+				nextID = sourceNext
+			}
+			if sourceCache > cache {
+				cache = sourceCache
+			}
+			found = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !found {
+			ts.Logger().Warningf("Sequence %s has no row on the source shards, not reseeding it", name)
+			continue
+		}
+		interleaved := vindexes.HasSequenceStride(tables[name])
+		for i, target := range targets {
+			var query string
+			if interleaved {
+				query = fmt.Sprintf("insert into %s(id, next_id, cache, stride) values (0, %d, %d, %d) on duplicate key update next_id = values(next_id), cache = values(cache), stride = values(stride)",
+					escaped, nextID+int64(i)*cache, cache, len(targets))
+			} else {
+				query = fmt.Sprintf("insert into %s(id, next_id, cache) values (0, %d, %d) on duplicate key update next_id = values(next_id), cache = values(cache)",
+					escaped, nextID, cache)
+			}
+			if _, err := ts.wr.ExecuteFetchAsDba(ctx, target.GetPrimary().Alias, query, 1, false, false); err != nil {
+				return vterrors.Wrapf(err, "reseeding sequence %s on %s/%s", name, ts.TargetKeyspaceName(), target.GetShard().ShardName())
+			}
+			ts.Logger().Infof("Reseeded sequence %s on %s/%s", name, ts.TargetKeyspaceName(), target.GetShard().ShardName())
+		}
+	}
+	return nil
+}
+
 func (ts *trafficSwitcher) cancelMigration(ctx context.Context, sm *workflow.StreamMigrator) {
 	var err error
 	if ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
@@ -1393,6 +1508,47 @@ func (ts *trafficSwitcher) changeShardsAccess(ctx context.Context, keyspace stri
 	return ts.wr.refreshPrimaryTablets(ctx, shards)
 }
 
+// signalTrafficSwitch records, in every cell, that the writes of the source
+// shards are being switched, so that vtgates buffer the queries to these
+// shards until the switch is over. The returned function records the end of
+// the switch. Buffering is best effort: failures are logged, and do not stop
+// the switch.
+func (ts *trafficSwitcher) signalTrafficSwitch(ctx context.Context) func(switched bool) {
+	cells, err := ts.TopoServer().GetCellInfoNames(ctx)
+	if err != nil {
+		ts.Logger().Warningf("Cannot signal the traffic switch to vtgates, queries will not be buffered: %v", err)
+		return func(bool) {}
+	}
+
+	sw := &topo.TrafficSwitch{
+		Workflow:    ts.WorkflowName(),
+		MovesTables: ts.MigrationType() == binlogdatapb.MigrationType_TABLES,
+		Started:     time.Now().UTC(),
+	}
+	for _, si := range ts.SourceShards() {
+		sw.Shards = append(sw.Shards, si.ShardName())
+	}
+	sort.Strings(sw.Shards)
+
+	if err := ts.TopoServer().UpdateTrafficSwitch(ctx, cells, ts.SourceKeyspaceName(), sw); err != nil {
+		ts.Logger().Warningf("Cannot signal the traffic switch to vtgates, queries may not be buffered: %v", err)
+	}
+
+	return func(switched bool) {
+		// The switch may have failed because ctx expired, so the end is
+		// recorded with a new context.
+		ctx, cancel := context.WithTimeout(context.Background(), *topo.RemoteOperationTimeout)
+		defer cancel()
+
+		done := *sw
+		done.Done = true
+		done.Switched = switched
+		if err := ts.TopoServer().UpdateTrafficSwitch(ctx, cells, ts.SourceKeyspaceName(), &done); err != nil {
+			ts.Logger().Warningf("Cannot signal the end of the traffic switch to vtgates, queries will be buffered until --buffer_max_failover_duration: %v", err)
+		}
+	}
+}
+
 func (ts *trafficSwitcher) SourceShards() []*topo.ShardInfo {
 	shards := make([]*topo.ShardInfo, 0, len(ts.Sources()))
 	for _, source := range ts.Sources() {
@@ -46,7 +46,7 @@ import (
 const (
 	streamInfoQuery    = "select id, source, message, cell, tablet_types from _vt.vreplication where workflow='%s' and db_name='vt_%s'"
 	streamExtInfoQuery = "select id, source, pos, stop_pos, max_replication_lag, state, db_name, time_updated, transaction_timestamp, time_heartbeat, message, tags from _vt.vreplication where db_name = 'vt_%s' and workflow = '%s'"
-	copyStateQuery     = "select table_name, lastpk from _vt.copy_state where vrepl_id = %d"
+	copyStateQuery     = "select table_name, lastpk, rows_copied, bytes_copied from _vt.copy_state where vrepl_id = %d"
 )
 
 var (
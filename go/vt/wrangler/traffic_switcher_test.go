@@ -26,15 +26,19 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 )
 
 var (
@@ -770,6 +774,40 @@ func TestShardMigrateMainflow(t *testing.T) {
 	verifyQueries(t, tme.allDBClients)
 }
 
+func TestShardMigrateReseedSequences(t *testing.T) {
+	ctx := context.Background()
+	tme := newTestShardMigrater(ctx, t, []string{"-40", "40-"}, []string{"-80", "80-"})
+	defer tme.stopTablets(t)
+
+	vs, err := tme.ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	vs.Tables["t1_seq"] = &vschemapb.Table{
+		Type:    vindexes.TypeSequence,
+		Columns: []*vschemapb.Column{{Name: "stride", Type: querypb.Type_INT64}},
+	}
+	vs.Tables["t2_seq"] = &vschemapb.Table{
+		Type:   vindexes.TypeSequence,
+		Pinned: "80",
+	}
+	require.NoError(t, tme.ts.SaveVSchema(ctx, "ks", vs))
+
+	ts, err := tme.wr.buildTrafficSwitcher(ctx, "ks", "test")
+	require.NoError(t, err)
+
+	tme.tmeDB.AddQuery("USE `vt_ks`", &sqltypes.Result{})
+	seqResult := sqltypes.MakeTestResult(sqltypes.MakeTestFields("next_id|cache", "int64|int64"), "1000|100")
+	tme.tmeDB.AddQuery("select next_id, cache from `t1_seq` where id = 0", seqResult)
+	tme.tmeDB.AddQuery("select next_id, cache from `t2_seq` where id = 0", seqResult)
+	tme.tmeDB.AddQuery("insert into `t1_seq`(id, next_id, cache, stride) values (0, 1000, 100, 2) on duplicate key update next_id = values(next_id), cache = values(cache), stride = values(stride)", &sqltypes.Result{})
+	tme.tmeDB.AddQuery("insert into `t1_seq`(id, next_id, cache, stride) values (0, 1100, 100, 2) on duplicate key update next_id = values(next_id), cache = values(cache), stride = values(stride)", &sqltypes.Result{})
+	tme.tmeDB.AddQuery("insert into `t2_seq`(id, next_id, cache) values (0, 1000, 100) on duplicate key update next_id = values(next_id), cache = values(cache)", &sqltypes.Result{})
+	require.NoError(t, ts.reseedSequences(ctx))
+	assert.Equal(t, 2, tme.tmeDB.GetQueryCalledNum("select next_id, cache from `t1_seq` where id = 0"))
+	assert.Equal(t, 1, tme.tmeDB.GetQueryCalledNum("insert into `t1_seq`(id, next_id, cache, stride) values (0, 1000, 100, 2) on duplicate key update next_id = values(next_id), cache = values(cache), stride = values(stride)"))
+	assert.Equal(t, 1, tme.tmeDB.GetQueryCalledNum("insert into `t1_seq`(id, next_id, cache, stride) values (0, 1100, 100, 2) on duplicate key update next_id = values(next_id), cache = values(cache), stride = values(stride)"))
+	assert.Equal(t, 2, tme.tmeDB.GetQueryCalledNum("insert into `t2_seq`(id, next_id, cache) values (0, 1000, 100) on duplicate key update next_id = values(next_id), cache = values(cache)"))
+}
+
 func TestTableMigrateOneToManyKeepNoArtifacts(t *testing.T) {
 	testTableMigrateOneToMany(t, false, false)
 }
@@ -337,6 +337,14 @@ func (wr *Wrangler) WorkflowTagAction(ctx context.Context, keyspace string, work
 	return wr.convertQueryResultToSQLTypesResult(results), err
 }
 
+// ScheduleWorkflow schedules the stopped streams of a workflow to be started
+// by vtctld at the given time.
+func (wr *Wrangler) ScheduleWorkflow(ctx context.Context, keyspace, workflow string, startAt time.Time) error {
+	query := fmt.Sprintf("update _vt.vreplication set message = %s where state = 'Stopped'", encodeString(workflow2.ScheduledMessage(startAt)))
+	_, err := wr.runVexec(ctx, workflow, keyspace, query, false)
+	return err
+}
+
 // ReplicationStatusResult represents the result of trying to get the replication status for a given workflow.
 type ReplicationStatusResult struct {
 	// Workflow represents the name of the workflow relevant to the related replication statuses.
@@ -381,8 +389,10 @@ type ShardReplicationStatus struct {
 }
 
 type copyState struct {
-	Table  string
-	LastPK string
+	Table       string
+	LastPK      string
+	RowsCopied  int64
+	BytesCopied int64
 }
 
 // ReplicationStatus includes data from the _vt.vreplication table, along with other useful relevant data.
@@ -681,7 +691,7 @@ func (wr *Wrangler) printWorkflowList(keyspace string, workflows []string) {
 
 func (wr *Wrangler) getCopyState(ctx context.Context, tablet *topo.TabletInfo, id int64) ([]copyState, error) {
 	var cs []copyState
-	query := fmt.Sprintf("select table_name, lastpk from _vt.copy_state where vrepl_id = %d", id)
+	query := fmt.Sprintf("select table_name, lastpk, rows_copied, bytes_copied from _vt.copy_state where vrepl_id = %d", id)
 	qr, err := wr.VReplicationExec(ctx, tablet.Alias, query)
 	if err != nil {
 		return nil, err
@@ -693,9 +703,19 @@ func (wr *Wrangler) getCopyState(ctx context.Context, tablet *topo.TabletInfo, i
 			// These fields are varbinary, but close enough
 			table := row[0].ToString()
 			lastPK := row[1].ToString()
+			rowsCopied, err := row[2].ToInt64()
+			if err != nil {
+				return nil, err
+			}
+			bytesCopied, err := row[3].ToInt64()
+			if err != nil {
+				return nil, err
+			}
 			copyState := copyState{
-				Table:  table,
-				LastPK: lastPK,
+				Table:       table,
+				LastPK:      lastPK,
+				RowsCopied:  rowsCopied,
+				BytesCopied: bytesCopied,
 			}
 			cs = append(cs, copyState)
 		}
@@ -243,7 +243,9 @@ func TestWorkflowListStreams(t *testing.T) {
 					"CopyState": [
 						{
 							"Table": "t1",
-							"LastPK": "pk1"
+							"LastPK": "pk1",
+							"RowsCopied": 10,
+							"BytesCopied": 200
 						}
 					]
 				}
@@ -280,7 +282,9 @@ func TestWorkflowListStreams(t *testing.T) {
 					"CopyState": [
 						{
 							"Table": "t1",
-							"LastPK": "pk1"
+							"LastPK": "pk1",
+							"RowsCopied": 10,
+							"BytesCopied": 200
 						}
 					]
 				}
@@ -340,6 +344,20 @@ will be run on the following streams in keyspace target for workflow wrWorkflow:
 	require.Equal(t, dryRunResult, logger.String())
 }
 
+func TestScheduleWorkflow(t *testing.T) {
+	ctx := context.Background()
+	env := newWranglerTestEnv([]string{"0"}, []string{"-80", "80-"}, "", nil, 0)
+	defer env.close()
+	wr := New(logutil.NewMemoryLogger(), env.topoServ, env.tmc)
+
+	startAt := time.Date(2022, 9, 1, 2, 0, 0, 0, time.UTC)
+	query := "update _vt.vreplication set message = 'Scheduled to start at 2022-09-01T02:00:00Z' where state = 'Stopped' and db_name = 'vt_target' and workflow = 'wrWorkflow'"
+	for _, id := range []int{200, 210} {
+		env.tmc.setVRResults(env.tablets[id].tablet, query, &sqltypes.Result{RowsAffected: 1})
+	}
+	require.NoError(t, wr.ScheduleWorkflow(ctx, "target", "wrWorkflow", startAt))
+}
+
 func TestWorkflowListAll(t *testing.T) {
 	ctx := context.Background()
 	keyspace := "target"
@@ -56,6 +56,7 @@ type VReplicationWorkflowParams struct {
 	SourceKeyspace, Tables  string
 	AllTables, RenameTables bool
 	SourceTimeZone          string
+	TranslateSourceSchema   bool
 
 	// Reshard specific
 	SourceShards, TargetShards                []string
@@ -408,7 +409,7 @@ func (vrw *VReplicationWorkflow) initMoveTables() error {
 	return vrw.wr.MoveTables(vrw.ctx, vrw.params.Workflow, vrw.params.SourceKeyspace, vrw.params.TargetKeyspace,
 		vrw.params.Tables, vrw.params.Cells, vrw.params.TabletTypes, vrw.params.AllTables, vrw.params.ExcludeTables,
 		vrw.params.AutoStart, vrw.params.StopAfterCopy, vrw.params.ExternalCluster, vrw.params.DropConstraints,
-		vrw.params.SourceTimeZone)
+		vrw.params.SourceTimeZone, vrw.params.TranslateSourceSchema)
 }
 
 func (vrw *VReplicationWorkflow) initReshard() error {
@@ -106,9 +106,9 @@ func expectCanSwitchQueries(t *testing.T, tme *testMigraterEnv, keyspace, state
 		"int64|varchar|int64|int64|int64|varchar|varchar|int64|int64|int64|varchar|varchar"),
 		row)
 	copyStateResult := sqltypes.MakeTestResult(sqltypes.MakeTestFields(
-		"table|lastpk",
-		"varchar|varchar"),
-		"t1|pk1",
+		"table|lastpk|rows_copied|bytes_copied",
+		"varchar|varchar|int64|int64"),
+		"t1|pk1|10|200",
 	)
 
 	for _, db := range tme.dbTargetClients {